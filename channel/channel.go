@@ -9,6 +9,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -17,6 +18,9 @@ type OnChainData struct {
 	Holdings  types.Funds
 	Outcome   outcome.Exit
 	StateHash common.Hash
+	// FinalizesAt is the time, in unix seconds, at which the challenge registered for the channel finalizes. It is zero
+	// while the channel is not challenged.
+	FinalizesAt uint64 `json:",omitempty"`
 }
 
 type OffChainData struct {
@@ -34,6 +38,10 @@ type Channel struct {
 	OffChain OffChainData
 
 	LastChainUpdate ChainUpdateData
+
+	// Schemes verifies the signatures added to the channel, with crypto.EthereumScheme if nil. It is not persisted: it
+	// is set by the engine which holds the channel.
+	Schemes *crypto.Schemes
 }
 
 type ChainUpdateData struct {
@@ -141,6 +149,11 @@ func (c *Channel) Clone() *Channel {
 	}
 	d.FixedPart = c.FixedPart.Clone()
 	d.OnChain.Holdings = c.OnChain.Holdings
+	d.OnChain.Outcome = c.OnChain.Outcome.Clone()
+	d.OnChain.StateHash = c.OnChain.StateHash
+	d.OnChain.FinalizesAt = c.OnChain.FinalizesAt
+	d.LastChainUpdate = c.LastChainUpdate
+	d.Schemes = c.Schemes
 	return d
 }
 
@@ -227,6 +240,14 @@ func (c Channel) LatestSupportedState() (state.State, error) {
 	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum].State(), nil
 }
 
+// LatestSupportedSignedState returns the latest supported state, together with the signatures which support it.
+func (c Channel) LatestSupportedSignedState() (state.SignedState, error) {
+	if c.OffChain.LatestSupportedStateTurnNum == MaxTurnNum {
+		return state.SignedState{}, errors.New(`no state is yet supported`)
+	}
+	return c.OffChain.SignedStateForTurnNum[c.OffChain.LatestSupportedStateTurnNum].Clone(), nil
+}
+
 // LatestSignedState fetches the state with the largest turn number signed by at least one participant.
 func (c Channel) LatestSignedState() (state.SignedState, error) {
 	if len(c.OffChain.SignedStateForTurnNum) == 0 {
@@ -241,6 +262,22 @@ func (c Channel) LatestSignedState() (state.SignedState, error) {
 	return c.OffChain.SignedStateForTurnNum[latestTurn], nil
 }
 
+// LatestSignedByMe fetches the state with the largest turn number which the calling client has signed.
+func (c Channel) LatestSignedByMe() (state.SignedState, error) {
+	found := false
+	latestTurn := uint64(0)
+	for k, ss := range c.OffChain.SignedStateForTurnNum {
+		if ss.HasSignatureForParticipant(c.MyIndex) && (!found || k > latestTurn) {
+			found = true
+			latestTurn = k
+		}
+	}
+	if !found {
+		return state.SignedState{}, errors.New("no states are signed by me")
+	}
+	return c.OffChain.SignedStateForTurnNum[latestTurn].Clone(), nil
+}
+
 // Total() returns the total allocated of each asset allocated by the pre fund setup state of the Channel.
 func (c Channel) Total() types.Funds {
 	return c.PreFundState().Outcome.TotalAllocated()
@@ -264,7 +301,7 @@ func (c Channel) Affords(
 // AddStateWithSignature constructs a SignedState from the passed state and signature, and calls s.AddSignedState with it.
 func (c *Channel) AddStateWithSignature(s state.State, sig state.Signature) bool {
 	ss := state.NewSignedState(s)
-	if err := ss.AddSignature(sig); err != nil {
+	if err := ss.AddSignatureWith(c.Schemes, sig); err != nil {
 		return false
 	} else {
 		return c.AddSignedState(ss)
@@ -290,7 +327,7 @@ func (c *Channel) AddSignedState(ss state.SignedState) bool {
 	if signedState, ok := c.OffChain.SignedStateForTurnNum[s.TurnNum]; !ok {
 		c.OffChain.SignedStateForTurnNum[s.TurnNum] = ss
 	} else {
-		err := signedState.Merge(ss)
+		err := signedState.MergeWith(c.Schemes, ss)
 		if err != nil {
 			return false
 		}
@@ -304,24 +341,45 @@ func (c *Channel) AddSignedState(ss state.SignedState) bool {
 	return true
 }
 
+// ValidateProposedState checks that the outcome of a state proposed by a peer is well formed, and conserves the funds of
+// the latest supported state (or, if there is none, the prefund state), so that the state is safe to sign.
+// The error returned wraps outcome.ErrMalformedOutcome.
+func (c *Channel) ValidateProposedState(s state.State) error {
+	if err := s.Outcome.Validate(); err != nil {
+		return fmt.Errorf("state with turn number %d of channel %s: %w", s.TurnNum, c.Id, err)
+	}
+	prior, err := c.LatestSupportedState()
+	if err != nil {
+		ss, ok := c.OffChain.SignedStateForTurnNum[PreFundTurnNum]
+		if !ok {
+			return nil
+		}
+		prior = ss.State()
+	}
+	if err := s.Outcome.ValidateTransition(prior.Outcome); err != nil {
+		return fmt.Errorf("state with turn number %d of channel %s: %w", s.TurnNum, c.Id, err)
+	}
+	return nil
+}
+
 // SignAndAddPrefund signs and adds the prefund state for the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddPrefund(sk *[]byte) (state.SignedState, error) {
-	return c.SignAndAddState(c.PreFundState(), sk)
+func (c *Channel) SignAndAddPrefund(signer state.Signer) (state.SignedState, error) {
+	return c.SignAndAddState(c.PreFundState(), signer)
 }
 
 // SignAndAddPrefund signs and adds the postfund state for the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddPostfund(sk *[]byte) (state.SignedState, error) {
-	return c.SignAndAddState(c.PostFundState(), sk)
+func (c *Channel) SignAndAddPostfund(signer state.Signer) (state.SignedState, error) {
+	return c.SignAndAddState(c.PostFundState(), signer)
 }
 
 // SignAndAddState signs and adds the state to the channel, returning a state.SignedState suitable for sending to peers.
-func (c *Channel) SignAndAddState(s state.State, sk *[]byte) (state.SignedState, error) {
-	sig, err := s.Sign(*sk)
+func (c *Channel) SignAndAddState(s state.State, signer state.Signer) (state.SignedState, error) {
+	sig, err := signer.SignState(s)
 	if err != nil {
 		return state.SignedState{}, fmt.Errorf("could not sign prefund %w", err)
 	}
 	ss := state.NewSignedState(s)
-	err = ss.AddSignature(sig)
+	err = ss.AddSignatureWith(c.Schemes, sig)
 	if err != nil {
 		return state.SignedState{}, fmt.Errorf("could not add own signature %w", err)
 	}
@@ -353,11 +411,14 @@ func (c *Channel) UpdateWithChainEvent(event chainservice.Event) (*Channel, erro
 		}
 		c.OnChain.StateHash = h
 		c.OnChain.Outcome = e.Outcome()
-		ss, err := e.SignedState(c.FixedPart)
+		ss, err := e.SignedState(c.FixedPart, c.Schemes)
 		if err != nil {
 			return nil, err
 		}
 		c.AddSignedState(ss)
+		c.OnChain.FinalizesAt = e.FinalizesAt()
+	case chainservice.ChallengeClearedEvent:
+		c.OnChain.FinalizesAt = 0
 	default:
 		return &Channel{}, fmt.Errorf("channel %+v cannot handle event %+v", c, event)
 	}
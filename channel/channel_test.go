@@ -287,12 +287,15 @@ func TestChannel(t *testing.T) {
 		}
 	}
 	testUpdateWithChallengeRegisteredEvent := func(t *testing.T) {
-		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB})
+		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB}, 1700000000)
 
 		_, err := c.UpdateWithChainEvent(event)
 		if err != nil {
 			t.Fatal(err)
 		}
+		if c.OnChain.FinalizesAt != 1700000000 {
+			t.Fatalf("expected the challenge to finalize at 1700000000, got %d", c.OnChain.FinalizesAt)
+		}
 		want := state.TestState.Outcome
 		got := c.OnChain.Outcome
 
@@ -312,8 +315,18 @@ func TestChannel(t *testing.T) {
 		}
 	}
 
+	testUpdateWithChallengeClearedEvent := func(t *testing.T) {
+		_, err := c.UpdateWithChainEvent(chainservice.NewChallengeClearedEvent(c.ChannelId(), 100000, 0, state.TestState.TurnNum+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.OnChain.FinalizesAt != 0 {
+			t.Fatalf("expected the cleared challenge to be forgotten, got it finalizing at %d", c.OnChain.FinalizesAt)
+		}
+	}
+
 	testUpdateWithChainEventRejected := func(t *testing.T) {
-		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB})
+		event := chainservice.NewChallengeRegisteredEvent(c.ChannelId(), 99999, 0, state.TestState.VariablePart(), []state.Signature{sigA, sigB}, 0)
 		_, err := c.UpdateWithChainEvent(event)
 		if err == nil {
 			t.Fatal("chain event should be rejected when blockNum/txIndex is not higher than last update")
@@ -334,6 +347,7 @@ func TestChannel(t *testing.T) {
 	t.Run(`TestAddStateWithSignature`, testAddStateWithSignature)
 	t.Run(`TestAddSignedState`, testAddSignedState)
 	t.Run(`TestUpdateWithChallengeRegisteredEvent`, testUpdateWithChallengeRegisteredEvent)
+	t.Run(`TestUpdateWithChallengeClearedEvent`, testUpdateWithChallengeClearedEvent)
 	t.Run(`TestUpdateWithChainEventRejected`, testUpdateWithChainEventRejected)
 }
 
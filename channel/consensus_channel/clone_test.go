@@ -21,7 +21,7 @@ func TestClone(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	cc, err := newConsensusChannel(fp(), Leader, 0, outcome, sigs)
+	cc, err := newConsensusChannel(fp(), Leader, 0, outcome, sigs, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"fmt"
 	"math/big"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
@@ -25,6 +27,8 @@ const (
 	ErrDuplicateGuarantee = types.ConstError("duplicate guarantee detected")
 	ErrGuaranteeNotFound  = types.ConstError("guarantee not found")
 	ErrInvalidAmount      = types.ConstError("left amount is greater than the guarantee amount")
+	ErrNegativeAmount     = types.ConstError("negative or missing amount")
+	ErrMalformedGuarantee = types.ConstError("guarantee is not between the ledger's participants")
 )
 
 const (
@@ -48,16 +52,22 @@ type ConsensusChannel struct {
 
 	// a queue of proposed changes which can be applied to the current state, ordered by TurnNum.
 	proposalQueue []SignedProposal
+
+	// Schemes verifies the signatures on the channel's states, with crypto.EthereumScheme if nil. It is not persisted:
+	// it is set by the engine which holds the channel.
+	Schemes *crypto.Schemes
 }
 
 // newConsensusChannel constructs a new consensus channel, validating its input by
 // checking that the signatures are as expected for the given fp, initialTurnNum and outcome.
+// The signatures are verified with schemes, which then verifies the signatures of the channel's later states.
 func newConsensusChannel(
 	fp state.FixedPart,
 	myIndex ledgerIndex,
 	initialTurnNum uint64,
 	outcome LedgerOutcome,
 	signatures [2]state.Signature,
+	schemes *crypto.Schemes,
 ) (ConsensusChannel, error) {
 	err := fp.Validate()
 	if err != nil {
@@ -68,20 +78,14 @@ func newConsensusChannel(
 
 	vars := Vars{TurnNum: initialTurnNum, Outcome: outcome.clone()}
 
-	leaderAddr, err := vars.AsState(fp).RecoverSigner(signatures[Leader])
+	err = vars.AsState(fp).VerifySignatureWith(schemes, signatures[Leader], fp.Participants[Leader])
 	if err != nil {
-		return ConsensusChannel{}, fmt.Errorf("could not verify sig: %w", err)
-	}
-	if leaderAddr != fp.Participants[Leader] {
-		return ConsensusChannel{}, fmt.Errorf("leader did not sign initial state: %v, %v", leaderAddr, fp.Participants[Leader])
+		return ConsensusChannel{}, fmt.Errorf("leader did not sign initial state: %v: %w", fp.Participants[Leader], err)
 	}
 
-	followerAddr, err := vars.AsState(fp).RecoverSigner(signatures[Follower])
+	err = vars.AsState(fp).VerifySignatureWith(schemes, signatures[Follower], fp.Participants[Follower])
 	if err != nil {
-		return ConsensusChannel{}, fmt.Errorf("could not verify sig: %w", err)
-	}
-	if followerAddr != fp.Participants[Follower] {
-		return ConsensusChannel{}, fmt.Errorf("follower did not sign initial state: %v, %v", followerAddr, fp.Participants[Leader])
+		return ConsensusChannel{}, fmt.Errorf("follower did not sign initial state: %v: %w", fp.Participants[Follower], err)
 	}
 
 	current := SignedVars{
@@ -95,6 +99,7 @@ func newConsensusChannel(
 		MyIndex:       myIndex,
 		proposalQueue: make([]SignedProposal, 0),
 		current:       current,
+		Schemes:       schemes,
 	}, nil
 }
 
@@ -217,22 +222,28 @@ func (c *ConsensusChannel) FundingTargets() []types.Destination {
 	return c.current.Outcome.FundingTargets()
 }
 
+// ExpiredGuarantees returns the guarantees in the consensus state which have expired by now.
+func (c *ConsensusChannel) ExpiredGuarantees(now time.Time) []Guarantee {
+	return c.current.Outcome.ExpiredGuarantees(now)
+}
+
 // sign constructs a state.State from the given vars, using the ConsensusChannel's constant
-// values. It signs the resulting state using sk.
-func (c *ConsensusChannel) sign(vars Vars, sk []byte) (state.Signature, error) {
-	signer := crypto.GetAddressFromSecretKeyBytes(sk)
-	if c.fp.Participants[c.MyIndex] != signer {
-		return state.Signature{}, fmt.Errorf("attempting to sign from wrong address: %s", signer)
+// values. It signs the resulting state using signer, which must sign for our participant.
+func (c *ConsensusChannel) sign(vars Vars, signer state.Signer) (state.Signature, error) {
+	sig, err := signer.SignState(vars.AsState(c.fp))
+	if err != nil {
+		return state.Signature{}, err
 	}
-
-	state := vars.AsState(c.fp)
-	return state.Sign(sk)
+	if err := c.verifySigner(vars, sig, c.fp.Participants[c.MyIndex]); err != nil {
+		return state.Signature{}, fmt.Errorf("attempting to sign from wrong address: %w", err)
+	}
+	return sig, nil
 }
 
-// recoverSigner returns the signer of the vars using the given signature.
-func (c *ConsensusChannel) recoverSigner(vars Vars, sig state.Signature) (common.Address, error) {
+// verifySigner returns nil if the given signature is the signer's signature on the vars.
+func (c *ConsensusChannel) verifySigner(vars Vars, sig state.Signature, signer common.Address) error {
 	state := vars.AsState(c.fp)
-	return state.RecoverSigner(sig)
+	return state.VerifySignatureWith(c.Schemes, sig, signer)
 }
 
 // ConsensusVars returns the vars of the consensus state
@@ -321,6 +332,10 @@ type Guarantee struct {
 	target types.Destination
 	left   types.Destination
 	right  types.Destination
+	// expiry is the unix time (in seconds) after which the guarantee may be reclaimed, if the target has not been
+	// defunded. It is off-chain metadata, agreed when the guarantee is added, and is not part of the on-chain outcome.
+	// Zero means the guarantee never expires.
+	expiry uint64
 }
 
 // Clone returns a deep copy of the receiver.
@@ -330,6 +345,7 @@ func (g *Guarantee) Clone() Guarantee {
 		target: g.target,
 		left:   g.left,
 		right:  g.right,
+		expiry: g.expiry,
 	}
 }
 
@@ -338,16 +354,32 @@ func (g Guarantee) Target() types.Destination {
 	return g.target
 }
 
-// NewGuarantee constructs a new guarantee.
+// Expiry returns the unix time (in seconds) at which the guarantee expires, or zero if it never expires.
+func (g Guarantee) Expiry() uint64 {
+	return g.expiry
+}
+
+// WithExpiry returns a copy of the guarantee which expires at the given unix time (in seconds). Zero means never.
+func (g Guarantee) WithExpiry(expiry uint64) Guarantee {
+	g.expiry = expiry
+	return g
+}
+
+// HasExpired returns true if the guarantee has an expiry, and it has passed by now.
+func (g Guarantee) HasExpired(now time.Time) bool {
+	return g.expiry != 0 && uint64(now.Unix()) >= g.expiry
+}
+
+// NewGuarantee constructs a new guarantee, which never expires.
 func NewGuarantee(amount *big.Int, target types.Destination, left types.Destination, right types.Destination) Guarantee {
-	return Guarantee{amount, target, left, right}
+	return Guarantee{amount: amount, target: target, left: left, right: right}
 }
 
 func (g Guarantee) equal(g2 Guarantee) bool {
 	if !types.Equal(g.amount, g2.amount) {
 		return false
 	}
-	return g.target == g2.target && g.left == g2.left && g.right == g2.right
+	return g.target == g2.target && g.left == g2.left && g.right == g2.right && g.expiry == g2.expiry
 }
 
 // AsAllocation converts a Balance struct into the on-chain outcome.Allocation type
@@ -417,7 +449,7 @@ func (o *LedgerOutcome) IncludesTarget(target types.Destination) bool {
 	return found
 }
 
-// includes returns true when the receiver includes g in its list of guarantees.
+// includes returns true when the receiver includes g in its list of guarantees, whatever their expiries.
 func (o *LedgerOutcome) includes(g Guarantee) bool {
 	existing, found := o.guarantees[g.target]
 	if !found {
@@ -503,6 +535,19 @@ func (o LedgerOutcome) FundingTargets() []types.Destination {
 	return targets
 }
 
+// ExpiredGuarantees returns the guarantees of the LedgerOutcome which have expired by now.
+func (o LedgerOutcome) ExpiredGuarantees(now time.Time) []Guarantee {
+	expired := []Guarantee{}
+
+	for _, g := range o.guarantees {
+		if g.HasExpired(now) {
+			expired = append(expired, g)
+		}
+	}
+
+	return expired
+}
+
 // Vars stores the turn number and outcome for a state in a consensus channel.
 type Vars struct {
 	TurnNum uint64
@@ -737,8 +782,9 @@ func (vars *Vars) HandleProposal(p Proposal) error {
 //
 // An error is returned if:
 //   - the turn number is not incremented
-//   - the balances are incorrectly adjusted, or the deposits are too large
+//   - the balances are incorrectly adjusted, or the deposits are too large or negative
 //   - the guarantee is already included in vars.Outcome
+//   - the guarantee's left and right are not the ledger's participants
 //
 // If an error is returned, the original vars is not mutated.
 func (vars *Vars) Add(p Add) error {
@@ -750,6 +796,15 @@ func (vars *Vars) Add(p Add) error {
 		return ErrDuplicateGuarantee
 	}
 
+	if p.amount == nil || p.LeftDeposit == nil || p.amount.Sign() < 0 || p.LeftDeposit.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+
+	participants := []types.Destination{o.leader.destination, o.follower.destination}
+	if p.Guarantee.left == p.Guarantee.right || !slices.Contains(participants, p.Guarantee.left) || !slices.Contains(participants, p.Guarantee.right) {
+		return ErrMalformedGuarantee
+	}
+
 	var left, right Balance
 
 	if o.leader.destination == p.Guarantee.left {
@@ -802,7 +857,7 @@ func (vars *Vars) Add(p Add) error {
 // An error is returned if:
 //   - the turn number is not incremented
 //   - a guarantee is not found for the target
-//   - the amounts are too large for the guarantee amount
+//   - the amounts are too large for the guarantee amount, or negative
 //
 // If an error is returned, the original vars is not mutated.
 func (vars *Vars) Remove(p Remove) error {
@@ -815,6 +870,10 @@ func (vars *Vars) Remove(p Remove) error {
 		return ErrGuaranteeNotFound
 	}
 
+	if p.LeftAmount == nil || p.LeftAmount.Sign() < 0 {
+		return ErrNegativeAmount
+	}
+
 	if p.LeftAmount.Cmp(guarantee.amount) > 0 {
 		return ErrInvalidAmount
 	}
@@ -893,6 +952,7 @@ func (c *ConsensusChannel) Clone() *ConsensusChannel {
 	d := ConsensusChannel{
 		MyIndex: c.MyIndex, fp: c.fp.Clone(),
 		Id: c.Id, OnChainFunding: c.OnChainFunding.Clone(), current: c.current.clone(), proposalQueue: clonedProposalQueue,
+		Schemes: c.Schemes,
 	}
 	return &d
 }
@@ -902,7 +962,7 @@ func (cc *ConsensusChannel) SupportedSignedState() state.SignedState {
 	s := cc.ConsensusVars().AsState(cc.fp)
 	sigs := cc.current.Signatures
 	ss := state.NewSignedState(s)
-	_ = ss.AddSignature(sigs[0])
-	_ = ss.AddSignature(sigs[1])
+	_ = ss.AddSignatureWith(cc.Schemes, sigs[0])
+	_ = ss.AddSignatureWith(cc.Schemes, sigs[1])
 	return ss
 }
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 
@@ -156,12 +157,12 @@ func TestConsensusChannel(t *testing.T) {
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
 	testConsensusChannelFunctionality := func(t *testing.T) {
-		channel, err := newConsensusChannel(fp(), Leader, 0, outcome(), sigs)
+		channel, err := newConsensusChannel(fp(), Leader, 0, outcome(), sigs, nil)
 		if err != nil {
 			t.Fatalf("unable to construct a new consensus channel: %v", err)
 		}
 
-		_, err = channel.sign(initialVars, bob.PrivateKey)
+		_, err = channel.sign(initialVars, state.KeySigner(bob.PrivateKey))
 		if err == nil {
 			t.Fatalf("channel should check that signer is participant")
 		}
@@ -180,7 +181,7 @@ func TestConsensusChannel(t *testing.T) {
 
 		ivansSig, _ := initialVars.AsState(fp()).Sign(ivan.PrivateKey)
 		wrongSigs := [2]state.Signature{sigs[1], ivansSig}
-		_, err = newConsensusChannel(fp(), Leader, 0, outcome(), wrongSigs)
+		_, err = newConsensusChannel(fp(), Leader, 0, outcome(), wrongSigs, nil)
 		if err == nil {
 			t.Fatalf("channel should check that signers are participants")
 		}
@@ -204,3 +205,35 @@ func TestConsensusChannel(t *testing.T) {
 	t.Run(`TestApplyingRemoveProposalToVars`, testApplyingRemoveProposalToVars)
 	t.Run(`TestConsensusChannelFunctionality`, testConsensusChannelFunctionality)
 }
+
+func TestExpiredGuarantees(t *testing.T) {
+	now := time.Unix(1000, 0)
+	expiring := guarantee(5, types.Destination{2}, alice, bob).WithExpiry(1000)
+	unexpired := guarantee(5, types.Destination{3}, alice, bob).WithExpiry(1001)
+	neverExpiring := guarantee(5, types.Destination{4}, alice, bob)
+
+	o := makeOutcome(allocation(alice, aBal), allocation(bob, bBal), expiring, unexpired, neverExpiring)
+	expired := o.ExpiredGuarantees(now)
+	if len(expired) != 1 || !expired[0].equal(expiring) {
+		t.Fatalf("expected only %+v to have expired, got %+v", expiring, expired)
+	}
+
+	// The expiry is off-chain metadata, so it does not change the state
+	if fingerprint(Vars{Outcome: o}) != fingerprint(Vars{Outcome: makeOutcome(allocation(alice, aBal), allocation(bob, bBal),
+		expiring.WithExpiry(0), unexpired.WithExpiry(0), neverExpiring)}) {
+		t.Fatal("expected the guarantee expiry not to affect the state")
+	}
+
+	// But it is carried through serialization
+	data, err := expiring.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g Guarantee
+	if err := g.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if !g.equal(expiring) {
+		t.Fatalf("expected %+v after a round trip, got %+v", expiring, g)
+	}
+}
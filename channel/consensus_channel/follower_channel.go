@@ -1,9 +1,11 @@
 package consensus_channel
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -15,17 +17,18 @@ const (
 	ErrNonMatchingProposals        = types.ConstError("expected proposal does not match first proposal in the queue")
 	ErrInvalidProposalSignature    = types.ConstError("invalid signature for proposal")
 	ErrInvalidTurnNum              = types.ConstError("the proposal turn number is not the next turn number")
+	ErrNoWithdrawal                = types.ConstError("no withdrawal of the expected proposal found in the queue")
 )
 
-// NewFollowerChannel constructs a new FollowerChannel
-func NewFollowerChannel(fp state.FixedPart, turnNum uint64, outcome LedgerOutcome, signatures [2]state.Signature) (ConsensusChannel, error) {
-	return newConsensusChannel(fp, Follower, turnNum, outcome, signatures)
+// NewFollowerChannel constructs a new FollowerChannel, whose signatures are verified with schemes
+func NewFollowerChannel(fp state.FixedPart, turnNum uint64, outcome LedgerOutcome, signatures [2]state.Signature, schemes *crypto.Schemes) (ConsensusChannel, error) {
+	return newConsensusChannel(fp, Follower, turnNum, outcome, signatures, schemes)
 }
 
 // SignNextProposal is called by the follower and inspects whether the
 // expected proposal matches the first proposal in the queue. If so,
 // the proposal is removed from the queue and integrated into the channel state.
-func (c *ConsensusChannel) SignNextProposal(expectedProposal Proposal, sk []byte) (SignedProposal, error) {
+func (c *ConsensusChannel) SignNextProposal(expectedProposal Proposal, signer state.Signer) (SignedProposal, error) {
 	if c.MyIndex != Follower {
 		return SignedProposal{}, ErrNotFollower
 	}
@@ -54,7 +57,7 @@ func (c *ConsensusChannel) SignNextProposal(expectedProposal Proposal, sk []byte
 		return SignedProposal{}, err
 	}
 
-	signature, err := c.sign(vars, sk)
+	signature, err := c.sign(vars, signer)
 	if err != nil {
 		return SignedProposal{}, fmt.Errorf("unable to sign state update: %f", err)
 	}
@@ -69,6 +72,59 @@ func (c *ConsensusChannel) SignNextProposal(expectedProposal Proposal, sk []byte
 	return SignedProposal{signature, signed.Proposal, vars.TurnNum}, nil
 }
 
+// SignWithdrawal is called by the follower to countersign the Leader's withdrawal of a guarantee: a Remove proposal
+// which returns each participant's deposit for the guarantee added by expectedAdd. If expectedAdd has not yet been
+// countersigned, it must be first in the queue, immediately followed by the Remove.
+// The withdrawn proposals are removed from the queue, and the resulting state becomes the channel's consensus state.
+func (c *ConsensusChannel) SignWithdrawal(expectedAdd Proposal, signer state.Signer) (SignedProposal, error) {
+	if c.MyIndex != Follower {
+		return SignedProposal{}, ErrNotFollower
+	}
+
+	if err := c.validateProposalID(expectedAdd); err != nil {
+		return SignedProposal{}, err
+	}
+
+	if expectedAdd.Type() != AddProposal {
+		return SignedProposal{}, ErrUnsupportedExpectedProposal
+	}
+
+	expectedRemove := NewRemoveProposal(c.Id, expectedAdd.Target(), expectedAdd.ToAdd.LeftDeposit)
+
+	// vars are cloned and modified instead of modified in place to simplify recovering from error
+	vars := Vars{
+		TurnNum: c.current.TurnNum,
+		Outcome: c.current.Outcome.clone(),
+	}
+	i := 0
+	if len(c.proposalQueue) > 0 && c.proposalQueue[0].Proposal.Equal(&expectedAdd) {
+		if err := vars.HandleProposal(c.proposalQueue[0].Proposal); err != nil {
+			return SignedProposal{}, err
+		}
+		i++
+	}
+	if len(c.proposalQueue) <= i || !c.proposalQueue[i].Proposal.Equal(&expectedRemove) {
+		return SignedProposal{}, ErrNoWithdrawal
+	}
+	if err := vars.HandleProposal(c.proposalQueue[i].Proposal); err != nil {
+		return SignedProposal{}, err
+	}
+
+	signature, err := c.sign(vars, signer)
+	if err != nil {
+		return SignedProposal{}, fmt.Errorf("unable to sign state update: %w", err)
+	}
+
+	signed := c.proposalQueue[i]
+	c.current = SignedVars{
+		Vars:       vars,
+		Signatures: [2]state.Signature{signed.Signature, signature},
+	}
+	c.proposalQueue = c.proposalQueue[i+1:]
+
+	return SignedProposal{signature, signed.Proposal, vars.TurnNum}, nil
+}
+
 // followerReceive is called by the follower to validate a proposal from the leader and add it to the proposal queue
 func (c *ConsensusChannel) followerReceive(p SignedProposal) error {
 	if c.MyIndex != Follower {
@@ -95,13 +151,13 @@ func (c *ConsensusChannel) followerReceive(p SignedProposal) error {
 	}
 
 	// Validate the signature
-	signer, err := c.recoverSigner(vars, p.Signature)
-	if err != nil {
-		return fmt.Errorf("receive could not recover signature: %w", err)
-	}
-	if signer != c.Leader() {
+	err = c.verifySigner(vars, p.Signature, c.Leader())
+	if errors.Is(err, crypto.ErrInvalidSignature) {
 		return ErrInvalidProposalSignature
 	}
+	if err != nil {
+		return fmt.Errorf("receive could not verify signature: %w", err)
+	}
 
 	// Update the proposal queue
 	c.proposalQueue = append(c.proposalQueue, p)
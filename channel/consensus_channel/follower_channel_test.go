@@ -19,7 +19,7 @@ func TestReceive(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	channel, err := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs)
+	channel, err := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs, nil)
 	if err != nil {
 		t.Fatal("unable to construct channel")
 	}
@@ -86,7 +86,7 @@ func TestFollowerChannel(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	channel, err := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs)
+	channel, err := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs, nil)
 	if err != nil {
 		t.Fatal("unable to construct channel")
 	}
@@ -94,7 +94,7 @@ func TestFollowerChannel(t *testing.T) {
 	amountAdded := uint64(5)
 	proposal := Proposal{LedgerID: channel.Id, ToAdd: add(amountAdded, targetChannel, alice, bob)}
 
-	_, err = channel.SignNextProposal(proposal, bob.PrivateKey)
+	_, err = channel.SignNextProposal(proposal, state.KeySigner(bob.PrivateKey))
 	if !errors.Is(ErrNoProposals, err) {
 		t.Fatalf("expected %v, but got %v", ErrNoProposals, err)
 	}
@@ -107,12 +107,12 @@ func TestFollowerChannel(t *testing.T) {
 	channel.proposalQueue = []SignedProposal{signedProposal}
 	proposal2 := Proposal{LedgerID: channel.Id, ToAdd: add(amountAdded+1, targetChannel, alice, bob)}
 
-	_, err = channel.SignNextProposal(proposal2, bob.PrivateKey)
+	_, err = channel.SignNextProposal(proposal2, state.KeySigner(bob.PrivateKey))
 	if !errors.Is(ErrNonMatchingProposals, err) {
 		t.Fatalf("expected %v, but got %v", ErrNonMatchingProposals, err)
 	}
 
-	withMySig, err := channel.SignNextProposal(proposal, bob.PrivateKey)
+	withMySig, err := channel.SignNextProposal(proposal, state.KeySigner(bob.PrivateKey))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -138,15 +138,72 @@ func TestFollowerChannel(t *testing.T) {
 	}
 }
 
+func TestSignWithdrawal(t *testing.T) {
+	initialVars := Vars{Outcome: ledgerOutcome(), TurnNum: 0}
+	aliceSig, _ := initialVars.AsState(fp()).Sign(alice.PrivateKey)
+	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
+	sigs := [2]state.Signature{aliceSig, bobsSig}
+
+	leader, err := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower, err := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addProposal := Proposal{LedgerID: leader.Id, ToAdd: add(5, targetChannel, alice, bob)}
+
+	// The follower has nothing to countersign until the leader withdraws its proposal
+	proposedAdd, err := leader.Propose(addProposal, state.KeySigner(alice.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := follower.Receive(proposedAdd); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := follower.SignWithdrawal(addProposal, state.KeySigner(bob.PrivateKey)); !errors.Is(err, ErrNoWithdrawal) {
+		t.Fatalf("expected %v, but got %v", ErrNoWithdrawal, err)
+	}
+
+	withdrawal := NewRemoveProposal(leader.Id, targetChannel, addProposal.ToAdd.LeftDeposit)
+	proposedWithdrawal, err := leader.Propose(withdrawal, state.KeySigner(alice.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := follower.Receive(proposedWithdrawal); err != nil {
+		t.Fatal(err)
+	}
+
+	countersigned, err := follower.SignWithdrawal(addProposal, state.KeySigner(bob.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if follower.ConsensusTurnNum() != 2 || len(follower.proposalQueue) != 0 {
+		t.Fatalf("expected the follower to reach consensus on turn 2 with an empty queue, got turn %d and %d queued proposals", follower.ConsensusTurnNum(), len(follower.proposalQueue))
+	}
+	if consensus := follower.ConsensusVars(); !consensus.equals(Vars{Outcome: ledgerOutcome(), TurnNum: 2}) {
+		t.Fatalf("expected the withdrawal to restore the original outcome, got %+v", consensus.Outcome)
+	}
+
+	if err := leader.Receive(countersigned); err != nil {
+		t.Fatal(err)
+	}
+	if leader.ConsensusTurnNum() != 2 || len(leader.proposalQueue) != 0 {
+		t.Fatalf("expected the leader to reach consensus on turn 2 with an empty queue, got turn %d and %d queued proposals", leader.ConsensusTurnNum(), len(leader.proposalQueue))
+	}
+}
+
 func TestRestrictedLeaderMethods(t *testing.T) {
 	initialVars := Vars{Outcome: ledgerOutcome(), TurnNum: 0}
 	aliceSig, _ := initialVars.AsState(fp()).Sign(alice.PrivateKey)
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	channel, _ := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs)
+	channel, _ := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs, nil)
 
-	if _, err := channel.Propose(Proposal{ToAdd: Add{}}, alice.PrivateKey); err != ErrNotLeader {
+	if _, err := channel.Propose(Proposal{ToAdd: Add{}}, state.KeySigner(alice.PrivateKey)); err != ErrNotLeader {
 		t.Errorf("Expected error when calling Propose() as a follower, but found none")
 	}
 
@@ -161,10 +218,10 @@ func TestFollowerIncorrectlyAddressedProposals(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	leaderCh, _ := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs)
-	followerCh, _ := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs)
+	leaderCh, _ := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs, nil)
+	followerCh, _ := NewFollowerChannel(fp(), 0, ledgerOutcome(), sigs, nil)
 
-	someProposal, _ := leaderCh.Propose(Proposal{ToAdd: add(1, types.Destination{}, alice, bob)}, alice.PrivateKey)
+	someProposal, _ := leaderCh.Propose(Proposal{ToAdd: add(1, types.Destination{}, alice, bob)}, state.KeySigner(alice.PrivateKey))
 	someProposal.Proposal.LedgerID = types.Destination{} // alter the ChannelID so that it doesn't match
 
 	err := followerCh.Receive(someProposal)
@@ -173,7 +230,7 @@ func TestFollowerIncorrectlyAddressedProposals(t *testing.T) {
 		t.Fatalf("expected error receiving proposal with incorrect ChannelID, but found none")
 	}
 
-	_, err = followerCh.SignNextProposal(someProposal.Proposal, bob.PrivateKey)
+	_, err = followerCh.SignNextProposal(someProposal.Proposal, state.KeySigner(bob.PrivateKey))
 
 	if err != ErrIncorrectChannelID {
 		t.Fatalf("expected error receiving proposal with incorrect ChannelID, but found none")
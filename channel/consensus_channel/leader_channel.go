@@ -1,9 +1,11 @@
 package consensus_channel
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -13,15 +15,15 @@ const (
 	ErrWrongSigner            = types.ConstError("proposal incorrectly signed")
 )
 
-// NewLeaderChannel constructs a new LeaderChannel
-func NewLeaderChannel(fp state.FixedPart, turnNum uint64, outcome LedgerOutcome, signatures [2]state.Signature) (ConsensusChannel, error) {
-	return newConsensusChannel(fp, Leader, turnNum, outcome, signatures)
+// NewLeaderChannel constructs a new LeaderChannel, whose signatures are verified with schemes
+func NewLeaderChannel(fp state.FixedPart, turnNum uint64, outcome LedgerOutcome, signatures [2]state.Signature, schemes *crypto.Schemes) (ConsensusChannel, error) {
+	return newConsensusChannel(fp, Leader, turnNum, outcome, signatures, schemes)
 }
 
 // Propose is called by the Leader and receives a proposal to add or remove a guarantee,
 // and generates and stores a SignedProposal in the queue, returning the
 // resulting SignedProposal
-func (c *ConsensusChannel) Propose(proposal Proposal, sk []byte) (SignedProposal, error) {
+func (c *ConsensusChannel) Propose(proposal Proposal, signer state.Signer) (SignedProposal, error) {
 	if c.MyIndex != Leader {
 		return SignedProposal{}, ErrNotLeader
 	}
@@ -39,7 +41,7 @@ func (c *ConsensusChannel) Propose(proposal Proposal, sk []byte) (SignedProposal
 		return SignedProposal{}, fmt.Errorf("propose could not add new state vars: %w", err)
 	}
 
-	signature, err := c.sign(vars, sk)
+	signature, err := c.sign(vars, signer)
 	if err != nil {
 		return SignedProposal{}, fmt.Errorf("unable to sign state update: %f", err)
 	}
@@ -95,14 +97,13 @@ func (c *ConsensusChannel) leaderReceive(countersigned SignedProposal) error {
 		}
 
 		if consensusCandidate.TurnNum == consensusTurnNum {
-			signer, err := consensusCandidate.AsState(c.fp).RecoverSigner(countersigned.Signature)
-			if err != nil {
-				return fmt.Errorf("unable to recover signer: %w", err)
-			}
-
-			if signer != c.fp.Participants[Follower] {
+			err := consensusCandidate.AsState(c.fp).VerifySignatureWith(c.Schemes, countersigned.Signature, c.fp.Participants[Follower])
+			if errors.Is(err, crypto.ErrInvalidSignature) {
 				return ErrWrongSigner
 			}
+			if err != nil {
+				return fmt.Errorf("unable to verify signer: %w", err)
+			}
 
 			mySig := ourP.Signature
 			c.current = SignedVars{
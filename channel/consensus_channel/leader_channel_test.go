@@ -20,7 +20,7 @@ func TestNewLeaderChannel(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	channel, err := NewLeaderChannel(fp(), 0, o.clone(), sigs)
+	channel, err := NewLeaderChannel(fp(), 0, o.clone(), sigs, nil)
 	if err != nil {
 		t.Fatal("unable to construct channel")
 	}
@@ -119,7 +119,7 @@ func TestLeaderChannel(t *testing.T) {
 			latest, _ := channel.latestProposedVars()
 			latestTurnNum := latest.TurnNum
 
-			sp, err := channel.Propose(proposal, alice.PrivateKey)
+			sp, err := channel.Propose(proposal, state.KeySigner(alice.PrivateKey))
 			if err != nil {
 				if expectedErr == nil {
 					t.Fatalf("unexpected error: %v", err)
@@ -465,9 +465,9 @@ func TestRestrictedFollowerMethods(t *testing.T) {
 	bobsSig, _ := initialVars.AsState(fp()).Sign(bob.PrivateKey)
 	sigs := [2]state.Signature{aliceSig, bobsSig}
 
-	channel, _ := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs)
+	channel, _ := NewLeaderChannel(fp(), 0, ledgerOutcome(), sigs, nil)
 
-	if _, err := channel.SignNextProposal(Proposal{}, alice.PrivateKey); err != ErrNotFollower {
+	if _, err := channel.SignNextProposal(Proposal{}, state.KeySigner(alice.PrivateKey)); err != ErrNotFollower {
 		t.Errorf("Expected error when calling SignNextProposal as a leader, but found none")
 	}
 
@@ -138,12 +138,13 @@ type jsonGuarantee struct {
 	Target types.Destination
 	Left   types.Destination
 	Right  types.Destination
+	Expiry uint64 `json:",omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the Guarantee
 func (g Guarantee) MarshalJSON() ([]byte, error) {
 	jsonG := jsonGuarantee{
-		g.amount, g.target, g.left, g.right,
+		g.amount, g.target, g.left, g.right, g.expiry,
 	}
 	return json.Marshal(jsonG)
 }
@@ -161,6 +162,7 @@ func (g *Guarantee) UnmarshalJSON(data []byte) error {
 	g.target = jsonG.Target
 	g.left = jsonG.Left
 	g.right = jsonG.Right
+	g.expiry = jsonG.Expiry
 
 	return nil
 }
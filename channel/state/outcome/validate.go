@@ -0,0 +1,96 @@
+package outcome
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrMalformedOutcome is wrapped by the errors returned by Validate and ValidateTransition.
+const ErrMalformedOutcome = types.ConstError("malformed outcome")
+
+// Validate checks that the outcome is well formed, so that it is safe to sign. Each asset must appear once and, for
+// each asset:
+//   - every allocation has a known type, and a non-negative amount
+//   - no destination is allocated to twice
+//   - guarantees follow the normal allocations, ordered by their target (as in the outcome of a ledger channel)
+//   - each guarantee's metadata decodes, naming distinct left and right destinations which are allocated to
+func (e Exit) Validate() error {
+	assets := make(map[types.Address]struct{}, len(e))
+	for _, sae := range e {
+		if _, ok := assets[sae.Asset]; ok {
+			return fmt.Errorf("%w: asset %s appears more than once", ErrMalformedOutcome, sae.Asset)
+		}
+		assets[sae.Asset] = struct{}{}
+		if err := sae.validate(); err != nil {
+			return fmt.Errorf("%w: asset %s: %s", ErrMalformedOutcome, sae.Asset, err)
+		}
+	}
+	return nil
+}
+
+func (sae SingleAssetExit) validate() error {
+	destinations := make(map[types.Destination]struct{}, len(sae.Allocations))
+	var lastGuarantee *types.Destination
+	for i, a := range sae.Allocations {
+		if a.Amount == nil || a.Amount.Sign() < 0 {
+			return fmt.Errorf("allocation %d has a negative or missing amount", i)
+		}
+		if _, ok := destinations[a.Destination]; ok {
+			return fmt.Errorf("destination %s is allocated to more than once", a.Destination)
+		}
+		destinations[a.Destination] = struct{}{}
+
+		switch a.AllocationType {
+		case NormalAllocationType:
+			if lastGuarantee != nil {
+				return fmt.Errorf("allocation %d follows a guarantee", i)
+			}
+		case GuaranteeAllocationType:
+			if lastGuarantee != nil && bytes.Compare(lastGuarantee[:], a.Destination[:]) > 0 {
+				return fmt.Errorf("guarantee %d is out of order", i)
+			}
+			lastGuarantee = &sae.Allocations[i].Destination
+		default:
+			return fmt.Errorf("allocation %d has unknown type %d", i, a.AllocationType)
+		}
+	}
+
+	// Guarantees are checked once every destination is known, as they refer to the normal allocations
+	for i, a := range sae.Allocations {
+		if a.AllocationType != GuaranteeAllocationType {
+			continue
+		}
+		m, err := DecodeIntoGuaranteeMetadata(a.Metadata)
+		if err != nil {
+			return fmt.Errorf("guarantee %d has undecodable metadata: %w", i, err)
+		}
+		if m.Left == m.Right {
+			return fmt.Errorf("guarantee %d has the same left and right destination", i)
+		}
+		for _, d := range []types.Destination{m.Left, m.Right} {
+			if _, ok := destinations[d]; !ok {
+				return fmt.Errorf("guarantee %d refers to %s, which is not allocated to", i, d)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateTransition checks that the outcome conserves the funds of the prior outcome: it must hold the same assets,
+// in the same order, with the same total allocated to each.
+func (e Exit) ValidateTransition(prior Exit) error {
+	if len(e) != len(prior) {
+		return fmt.Errorf("%w: holds %d assets, rather than %d", ErrMalformedOutcome, len(e), len(prior))
+	}
+	for i, sae := range e {
+		if sae.Asset != prior[i].Asset {
+			return fmt.Errorf("%w: holds asset %s in place of %s", ErrMalformedOutcome, sae.Asset, prior[i].Asset)
+		}
+		if total, priorTotal := sae.TotalAllocated(), prior[i].TotalAllocated(); total.Cmp(priorTotal) != 0 {
+			return fmt.Errorf("%w: allocates %s of asset %s, rather than %s", ErrMalformedOutcome, total, sae.Asset, priorTotal)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package outcome
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestExitValidate(t *testing.T) {
+	left, right := guaranteeMetadata.Left, guaranteeMetadata.Right
+	target := types.Destination{0x0c}
+	laterTarget := types.Destination{0x0d}
+	token := common.HexToAddress("0x0e")
+
+	normal := func(dest types.Destination, amount int64) Allocation {
+		return Allocation{Destination: dest, Amount: big.NewInt(amount)}
+	}
+	guarantee := func(dest types.Destination, m GuaranteeMetadata) Allocation {
+		encoded, _ := m.Encode()
+		return Allocation{Destination: dest, Amount: big.NewInt(1), AllocationType: GuaranteeAllocationType, Metadata: encoded}
+	}
+	exit := func(allocations ...Allocation) Exit {
+		return Exit{{Asset: types.Address{}, Allocations: allocations}}
+	}
+
+	valid := []Exit{
+		{},
+		exit(normal(left, 5), normal(right, 0)),
+		exit(normal(left, 5), normal(right, 5), guarantee(target, guaranteeMetadata), guarantee(laterTarget, guaranteeMetadata)),
+		append(exit(normal(left, 5)), SingleAssetExit{Asset: token, Allocations: Allocations{normal(left, 5)}}),
+	}
+	for i, e := range valid {
+		if err := e.Validate(); err != nil {
+			t.Errorf("expected outcome %d to be valid, got %v", i, err)
+		}
+	}
+
+	malformed := map[string]Exit{
+		"duplicate asset":        append(exit(normal(left, 5)), exit(normal(right, 5))...),
+		"negative amount":        exit(normal(left, -1)),
+		"missing amount":         exit(Allocation{Destination: left}),
+		"duplicate destination":  exit(normal(left, 5), normal(left, 5)),
+		"unknown type":           exit(Allocation{Destination: left, Amount: big.NewInt(1), AllocationType: 2}),
+		"normal after guarantee": exit(normal(left, 5), guarantee(target, guaranteeMetadata), normal(right, 5)),
+		"unordered guarantees":   exit(normal(left, 5), normal(right, 5), guarantee(laterTarget, guaranteeMetadata), guarantee(target, guaranteeMetadata)),
+		"undecodable metadata":   exit(normal(left, 5), normal(right, 5), Allocation{Destination: target, Amount: big.NewInt(1), AllocationType: GuaranteeAllocationType}),
+		"guarantee to itself":    exit(normal(left, 5), guarantee(target, GuaranteeMetadata{Left: left, Right: left})),
+		"guarantee to stranger":  exit(normal(left, 5), guarantee(target, guaranteeMetadata)),
+	}
+	for name, e := range malformed {
+		if err := e.Validate(); !errors.Is(err, ErrMalformedOutcome) {
+			t.Errorf("%s: expected ErrMalformedOutcome, got %v", name, err)
+		}
+	}
+}
+
+func TestExitValidateTransition(t *testing.T) {
+	prior := Exit{{Asset: types.Address{}, Allocations: Allocations{
+		{Destination: guaranteeMetadata.Left, Amount: big.NewInt(5)},
+		{Destination: guaranteeMetadata.Right, Amount: big.NewInt(5)},
+	}}}
+
+	redistributed := prior.Clone()
+	redistributed[0].Allocations[0].Amount = big.NewInt(2)
+	redistributed[0].Allocations[1].Amount = big.NewInt(8)
+	if err := redistributed.ValidateTransition(prior); err != nil {
+		t.Errorf("expected a redistribution of the funds to be valid, got %v", err)
+	}
+
+	inflated := prior.Clone()
+	inflated[0].Allocations[0].Amount = big.NewInt(6)
+	otherAsset := prior.Clone()
+	otherAsset[0].Asset = common.HexToAddress("0x0e")
+	for name, e := range map[string]Exit{"inflated": inflated, "other asset": otherAsset, "no assets": {}} {
+		if err := e.ValidateTransition(prior); !errors.Is(err, ErrMalformedOutcome) {
+			t.Errorf("%s: expected ErrMalformedOutcome, got %v", name, err)
+		}
+	}
+}
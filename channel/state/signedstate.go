@@ -20,30 +20,53 @@ func NewSignedState(s State) SignedState {
 	return SignedState{s, make(map[uint]Signature, len(s.Participants))}
 }
 
-// AddSignature adds a participant's signature to the SignedState.
+// AddSignature adds a participant's signature to the SignedState, recovering its signer with the default signature
+// scheme.
 //
 // An error is returned if
 //   - the signer is not a participant, or
 //   - OR the signature was already stored
 func (ss SignedState) AddSignature(sig Signature) error {
-	signer, err := ss.state.RecoverSigner(sig)
+	return ss.AddSignatureWith(nil, sig)
+}
+
+// AddSignatureWith adds a participant's signature to the SignedState, as AddSignature does, verifying the signatures
+// of participants with a scheme of their own in schemes with that scheme.
+func (ss SignedState) AddSignatureWith(schemes *crypto.Schemes, sig Signature) error {
+	i, err := ss.signerIndex(schemes, sig)
 	if err != nil {
-		return fmt.Errorf("AddSignature failed to recover signer %w", err)
+		return err
 	}
+	if _, found := ss.sigs[i]; found {
+		return errors.New("signature already exists for participant")
+	}
+	ss.sigs[i] = sig
+	return nil
+}
 
+// signerIndex returns the index of the participant who made the signature. The signer is recovered with the default
+// signature scheme, unless it is a participant with a scheme of its own, which may not allow recovery.
+func (ss SignedState) signerIndex(schemes *crypto.Schemes, sig Signature) (uint, error) {
 	for i, p := range ss.state.Participants {
-		if p == signer {
-			_, found := ss.sigs[uint(i)]
-			if found {
-				return errors.New("signature already exists for participant")
-			} else {
-				ss.sigs[uint(i)] = sig
-				return nil
-			}
+		if schemes.HasSignerScheme(p) && ss.state.VerifySignatureWith(schemes, sig, p) == nil {
+			return uint(i), nil
+		}
+	}
 
+	stateHash, err := ss.state.Hash()
+	if err != nil {
+		return 0, fmt.Errorf("AddSignature failed to recover signer %w", err)
+	}
+	signer, err := schemes.RecoverSigner(stateHash[:], sig)
+	if err != nil {
+		return 0, fmt.Errorf("AddSignature failed to recover signer %w", err)
+	}
+	for i, p := range ss.state.Participants {
+		if p == signer && !schemes.HasSignerScheme(p) {
+			return uint(i), nil
 		}
 	}
-	return errors.New("signature does not match any participant")
+	return 0, errors.New("signature does not match any participant")
 }
 
 // State returns the State part of the SignedState.
@@ -90,6 +113,11 @@ func (ss SignedState) GetParticipantSignature(participantIndex uint) (crypto.Sig
 
 // Merge checks the passed SignedState's state and the receiver's state for equality, and adds each signature from the former to the latter.
 func (ss SignedState) Merge(ss2 SignedState) error {
+	return ss.MergeWith(nil, ss2)
+}
+
+// MergeWith merges the passed SignedState into the receiver, as Merge does, adding its signatures with AddSignatureWith.
+func (ss SignedState) MergeWith(schemes *crypto.Schemes, ss2 SignedState) error {
 	if !ss.state.Equal(ss2.state) {
 		return errors.New(`cannot merge signed states with distinct state hashes`)
 	}
@@ -100,7 +128,7 @@ func (ss SignedState) Merge(ss2 SignedState) error {
 				return errors.New(`cannot merge signed states with conflicting signatures`)
 			}
 		} else { // otherwise add the signature
-			err := ss.AddSignature(sig)
+			err := ss.AddSignatureWith(schemes, sig)
 			if err != nil {
 				return err
 			}
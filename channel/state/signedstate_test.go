@@ -8,6 +8,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
+	nc "github.com/statechannels/go-nitro/crypto"
 )
 
 func TestMergeWithDuplicateSignatures(t *testing.T) {
@@ -142,3 +143,52 @@ func TestSignatureGetters(t *testing.T) {
 		t.Errorf("incorrect Signatures, got %v, wanted %v", gotSigs, expectedSigs)
 	}
 }
+
+// stubScheme accepts only the signature it was constructed with.
+type stubScheme struct{ sig Signature }
+
+func (s stubScheme) Sign(message []byte, secretKey []byte) (Signature, error) { return s.sig, nil }
+
+func (s stubScheme) Verify(message []byte, sig Signature, signer common.Address) error {
+	if !sig.Equal(s.sig) {
+		return nc.ErrInvalidSignature
+	}
+	return nil
+}
+
+func TestAddSignatureWithSignerScheme(t *testing.T) {
+	bob := TestState.Participants[1]
+	bobSig := Signature{R: common.Hex2Bytes(`0101010101010101010101010101010101010101010101010101010101010101`), S: common.Hex2Bytes(`0202020202020202020202020202020202020202020202020202020202020202`), V: 27}
+	schemes := nc.NewSchemes(nc.EthereumScheme{})
+	schemes.SetSignerScheme(bob, stubScheme{bobSig})
+
+	ss := NewSignedState(TestState)
+	sigA, _ := TestState.Sign(common.Hex2Bytes(`caab404f975b4620747174a75f08d98b4e5a7053b691b41bcfc0d839d48b7634`))
+	if err := ss.AddSignatureWith(schemes, sigA); err != nil {
+		t.Fatal(err)
+	}
+	if err := ss.AddSignatureWith(schemes, bobSig); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ss.GetParticipantSignature(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(bobSig) {
+		t.Errorf("incorrect GetParticipantSignature, got %v, wanted %v", got, bobSig)
+	}
+
+	// bob's own ecdsa signature is no longer accepted
+	sigB, _ := TestState.Sign(common.Hex2Bytes(`62ecd49c4ccb41a70ad46532aed63cf815de15864bc415c87d507afd6a5e8da2`))
+	if err := NewSignedState(TestState).AddSignatureWith(schemes, sigB); err == nil {
+		t.Error("expected a signature not made with bob's scheme to be rejected")
+	}
+
+	// Schemes are held by each registry, so bob's scheme does not change how other registries verify his signatures
+	if err := NewSignedState(TestState).AddSignature(sigB); err != nil {
+		t.Errorf("expected bob's ecdsa signature to be accepted without his scheme: %v", err)
+	}
+	if err := NewSignedState(TestState).AddSignatureWith(nc.NewSchemes(nc.EthereumScheme{}), bobSig); err == nil {
+		t.Error("expected a signature made with bob's scheme to be rejected by another registry")
+	}
+}
@@ -0,0 +1,31 @@
+package state
+
+import (
+	nc "github.com/statechannels/go-nitro/crypto"
+)
+
+// Signer signs states for a participant, and the challenges which register them on chain. The secret key it signs
+// with may be held outside the process, such as by a remote signing service.
+type Signer interface {
+	// SignState returns the participant's signature on the state.
+	SignState(s State) (Signature, error)
+	// SignChallenge returns the participant's signature on the challenge message of the state.
+	SignChallenge(s State) (Signature, error)
+}
+
+// KeySigner signs with a secret key held in memory.
+type KeySigner []byte
+
+// SignState signs the state with the key.
+func (sk KeySigner) SignState(s State) (Signature, error) {
+	return s.Sign(sk)
+}
+
+// SignChallenge signs the challenge message of the state with the key.
+func (sk KeySigner) SignChallenge(s State) (Signature, error) {
+	hash, err := s.ChallengeHash()
+	if err != nil {
+		return Signature{}, err
+	}
+	return nc.SignEthereumMessage(hash[:], sk)
+}
@@ -97,8 +97,11 @@ func (fp FixedPart) ChannelId() types.Destination {
 
 // encodes the state into a []bytes value
 func (s State) encode() (types.Bytes, error) {
-	ChannelId := s.ChannelId()
+	return encodeState(s.ChannelId(), s.VariablePart())
+}
 
+// encodeState encodes the variable part of a state of the channel as the adjudicator does when hashing it
+func encodeState(channelId types.Destination, vp VariablePart) (types.Bytes, error) {
 	return ethAbi.Arguments{
 		{Type: abi.Destination}, // channel id (includes ChainID, Participants, ChannelNonce)
 		{Type: abi.Bytes},       // app data
@@ -106,41 +109,84 @@ func (s State) encode() (types.Bytes, error) {
 		{Type: abi.Uint256},     // turnNum
 		{Type: abi.Bool},        // isFinal
 	}.Pack(
-		ChannelId,
-		[]byte(s.AppData), // Note: even though s.AppData is types.bytes, which is an alias for []byte], Pack will not accept types.bytes
-		s.Outcome,
-		big.NewInt(int64(s.TurnNum)),
-		s.IsFinal,
+		channelId,
+		[]byte(vp.AppData), // Note: even though vp.AppData is types.bytes, which is an alias for []byte], Pack will not accept types.bytes
+		vp.Outcome,
+		big.NewInt(int64(vp.TurnNum)),
+		vp.IsFinal,
 	)
 }
 
 // Hash returns the keccak256 hash of the State
 func (s State) Hash() (types.Bytes32, error) {
-	encoded, err := s.encode()
+	return ComputeStateHash(s.ChannelId(), s.VariablePart())
+}
+
+// ComputeChannelId returns the id of the channel with the given fixed part, derived as the adjudicator derives it. It
+// allows systems outside of go-nitro to correlate on chain events with channels.
+func ComputeChannelId(participants []types.Address, channelNonce uint64, appDefinition types.Address, challengeDuration uint32) types.Destination {
+	return FixedPart{participants, channelNonce, appDefinition, challengeDuration}.ChannelId()
+}
+
+// ComputeStateHash returns the hash of the state of the channel with the given variable part, derived as the
+// adjudicator derives it. It is the hash which participants sign, and which is recorded on chain by a challenge.
+func ComputeStateHash(channelId types.Destination, vp VariablePart) (types.Bytes32, error) {
+	encoded, err := encodeState(channelId, vp)
 	if err != nil {
 		return types.Bytes32{}, fmt.Errorf("failed to encode state: %w", err)
 	}
 	return crypto.Keccak256Hash(encoded), nil
 }
 
-// Sign generates an ECDSA signature on the state using the supplied private key
-// The state hash is prepended with \x19Ethereum Signed Message:\n32 and then rehashed
-// to create a digest to sign
+// ChallengeHash returns the hash of the message which a participant signs to register a challenge with the state on
+// chain, which proves that the challenger is a participant.
+func (s State) ChallengeHash() (types.Bytes32, error) {
+	digest, err := s.Hash()
+	if err != nil {
+		return types.Bytes32{}, err
+	}
+
+	encoded, err := ethAbi.Arguments{{Type: abi.Bytes32}, {Type: abi.String}}.Pack(digest, "forceMove")
+	if err != nil {
+		return types.Bytes32{}, err
+	}
+
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Sign generates a signature on the state using the supplied private key, with the default signature scheme.
+// With the default nc.EthereumScheme, this is an ECDSA signature: the state hash is prepended with
+// \x19Ethereum Signed Message:\n32 and then rehashed to create a digest to sign
 func (s State) Sign(secretKey []byte) (Signature, error) {
 	hash, error := s.Hash()
 	if error != nil {
 		return Signature{}, error
 	}
-	return nc.SignEthereumMessage(hash.Bytes(), secretKey)
+	return nc.Sign(hash.Bytes(), secretKey)
 }
 
-// RecoverSigner computes the Ethereum address which generated Signature sig on State state
+// RecoverSigner computes the Ethereum address which generated Signature sig on State state, with the default
+// signature scheme
 func (s State) RecoverSigner(sig Signature) (types.Address, error) {
 	stateHash, error := s.Hash()
 	if error != nil {
 		return types.Address{}, error
 	}
-	return nc.RecoverEthereumMessageSigner(stateHash[:], sig)
+	return nc.RecoverSigner(stateHash[:], sig)
+}
+
+// VerifySignature returns nil if sig is the signer's signature on the state, with the default signature scheme
+func (s State) VerifySignature(sig Signature, signer types.Address) error {
+	return s.VerifySignatureWith(nil, sig, signer)
+}
+
+// VerifySignatureWith returns nil if sig is the signer's signature on the state, with the signer's scheme in schemes
+func (s State) VerifySignatureWith(schemes *nc.Schemes, sig Signature, signer types.Address) error {
+	stateHash, error := s.Hash()
+	if error != nil {
+		return error
+	}
+	return schemes.Verify(stateHash[:], sig, signer)
 }
 
 // equalParticipants returns true if the given arrays contain equal addresses (in the same order).
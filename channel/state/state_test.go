@@ -61,6 +61,14 @@ func TestHash(t *testing.T) {
 	checkErrorAndTestForEqualBytes(t, err, "state hash", got.Bytes(), want.Bytes())
 }
 
+func TestComputeFromComponents(t *testing.T) {
+	channelId := ComputeChannelId(TestState.Participants, TestState.ChannelNonce, TestState.AppDefinition, TestState.ChallengeDuration)
+	checkErrorAndTestForEqualBytes(t, nil, "channelId", channelId.Bytes(), correctChannelId.Bytes())
+
+	hash, err := ComputeStateHash(channelId, TestState.VariablePart())
+	checkErrorAndTestForEqualBytes(t, err, "state hash", hash.Bytes(), correctStateHash.Bytes())
+}
+
 func TestSign(t *testing.T) {
 	want_r, want_s, want_v := correctSignature.R, correctSignature.S, correctSignature.V
 	got, error := TestState.Sign(signerPrivateKey)
@@ -19,6 +19,7 @@ const (
 	NITRO_ENDPOINT       = "nitroendpoint"
 	LOG_FILE             = "create-single-channel.log"
 	AMOUNT               = "amount"
+	ASSET                = "asset"
 )
 
 func main() {
@@ -39,9 +40,14 @@ func main() {
 		&cli.Uint64Flag{
 			Name:    AMOUNT,
 			Value:   5_000_000,
-			Usage:   "Specifies the amount of wei to deposit into the ledger channel.",
+			Usage:   "Specifies the amount of the asset, in its smallest unit, to deposit into the ledger channel.",
 			Aliases: []string{"a"},
 		},
+		&cli.StringFlag{
+			Name:  ASSET,
+			Usage: "Specifies the address of the ERC20 token to deposit. The chain's native token is deposited if it is not given.",
+			Value: "0x0000000000000000000000000000000000000000",
+		},
 	}
 
 	app := &cli.App{
@@ -59,7 +65,7 @@ func main() {
 			}
 			defer client.Close()
 
-			err = utils.CreateLedgerChannel(client, common.HexToAddress(cCtx.String(COUNTERPARTY_ADDRESS)), cCtx.Uint64(AMOUNT))
+			err = utils.CreateLedgerChannel(client, common.HexToAddress(cCtx.String(COUNTERPARTY_ADDRESS)), common.HexToAddress(cCtx.String(ASSET)), cCtx.Uint64(AMOUNT))
 			if err != nil {
 				return err
 			}
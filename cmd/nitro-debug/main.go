@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	NITRO_ENDPOINT = "nitroendpoint"
+	OUTPUT         = "output"
+	LOG_FILE       = "nitro-debug.log"
+)
+
+func main() {
+	logging.SetupDefaultFileLogger(LOG_FILE, slog.LevelDebug)
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    NITRO_ENDPOINT,
+			Usage:   "Specifies the endpoint of the Nitro RPC server to connect to. This should be in the form 'host:port/api/v1'",
+			Value:   "localhost:4005/api/v1",
+			Aliases: []string{"n"},
+		},
+		&cli.StringFlag{
+			Name:    OUTPUT,
+			Usage:   "Specifies the file to write the diagnostic bundle to. Defaults to nitro-debug-<timestamp>.zip",
+			Aliases: []string{"o"},
+		},
+	}
+
+	app := &cli.App{
+		Name:  "nitro-debug",
+		Usage: "Collects a diagnostic bundle from a running nitro node, for inclusion in support requests",
+		Flags: flags,
+		Action: func(cCtx *cli.Context) error {
+			clientConnection, err := http.NewHttpTransportAsClient(cCtx.String(NITRO_ENDPOINT), 10*time.Millisecond)
+			if err != nil {
+				return err
+			}
+			client, err := rpc.NewRpcClient(clientConnection)
+			if err != nil {
+				return err
+			}
+			defer client.Close()
+
+			bundle, err := client.GetDebugBundle()
+			if err != nil {
+				return err
+			}
+
+			output := cCtx.String(OUTPUT)
+			if output == "" {
+				output = fmt.Sprintf("nitro-debug-%s.zip", time.Now().Format("20060102-150405"))
+			}
+			if err := os.WriteFile(output, bundle, 0o600); err != nil {
+				return err
+			}
+			fmt.Printf("Wrote diagnostic bundle to %s\n", output)
+			return nil
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
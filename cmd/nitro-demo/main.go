@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"math/big"
+	"os"
+
+	"github.com/statechannels/go-nitro/cmd/utils"
+	"github.com/statechannels/go-nitro/demo"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	WITH_HUB = "withhub"
+	RPC_PORT = "rpcport"
+	PAYMENTS = "payments"
+	AMOUNT   = "amount"
+	SERVE    = "serve"
+	LOG_FILE = "nitro-demo.log"
+)
+
+func main() {
+	logging.SetupDefaultFileLogger(LOG_FILE, slog.LevelDebug)
+	flags := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  WITH_HUB,
+			Usage: "Specifies whether to route payments through a hub, rather than over a ledger channel between the payer and payee.",
+			Value: true,
+		},
+		&cli.IntFlag{
+			Name:  RPC_PORT,
+			Usage: "Specifies the port on which the payer's rpc server listens. The payee and hub listen on the following ports. 0 disables rpc.",
+			Value: 4005,
+		},
+		&cli.IntFlag{
+			Name:  PAYMENTS,
+			Usage: "Specifies the number of payments the payer makes.",
+			Value: 10,
+		},
+		&cli.Uint64Flag{
+			Name:  AMOUNT,
+			Usage: "Specifies the amount of wei paid in each payment.",
+			Value: 100,
+		},
+		&cli.BoolFlag{
+			Name:  SERVE,
+			Usage: "Specifies whether to keep the nodes running once the scripted flow completes, until interrupted.",
+		},
+	}
+
+	app := &cli.App{
+		Name:  "nitro-demo",
+		Usage: "Runs a payer, a payee and a hub in one process, on a simulated chain, and scripts funding, paying and defunding a payment channel",
+		Flags: flags,
+		Action: func(cCtx *cli.Context) error {
+			nw, err := demo.Start(demo.Config{WithHub: cCtx.Bool(WITH_HUB), RpcPort: cCtx.Int(RPC_PORT)})
+			if err != nil {
+				return err
+			}
+			defer nw.Close()
+
+			result, err := nw.Run(context.Background(), cCtx.Int(PAYMENTS), new(big.Int).SetUint64(cCtx.Uint64(AMOUNT)))
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Funded ledger channels %v and payment channel %s, paid %s wei, and defunded them\n", result.LedgerChannels, result.PaymentChannel, result.Paid)
+
+			if cCtx.Bool(SERVE) {
+				utils.WaitForKillSignal()
+			}
+			return nil
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	PK                   = "pk"
+	DURABLE_STORE_FOLDER = "durablestorefolder"
+	REPAIR               = "repair"
+	STORE_PASSPHRASE     = "storepassphrase"
+	STORE_KEY_COMMAND    = "storekeycommand"
+)
+
+func main() {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     PK,
+			Usage:    "Specifies the private key used by the nitro node whose store is checked.",
+			Required: true,
+			EnvVars:  []string{"SC_PK"},
+		},
+		&cli.StringFlag{
+			Name:  DURABLE_STORE_FOLDER,
+			Usage: "Specifies the folder for the durable store data storage, as given to the nitro node.",
+			Value: "./data/nitro-store",
+		},
+		&cli.BoolFlag{
+			Name:  REPAIR,
+			Usage: "Quarantines corrupt records, and removes references to objectives which do not exist.",
+		},
+		&cli.StringFlag{
+			Name:    STORE_PASSPHRASE,
+			Usage:   "Specifies the passphrase the durable store is encrypted with, as given to the nitro node.",
+			EnvVars: []string{"STORE_PASSPHRASE"},
+		},
+		&cli.StringFlag{
+			Name:    STORE_KEY_COMMAND,
+			Usage:   "Specifies the command printing the key the durable store is encrypted with, as given to the nitro node.",
+			EnvVars: []string{"STORE_KEY_COMMAND"},
+		},
+	}
+
+	app := &cli.App{
+		Name:  "nitro-fsck",
+		Usage: "Checks the integrity of the durable store of a nitro node, which must not be running, and optionally repairs it",
+		Flags: flags,
+		Action: func(cCtx *cli.Context) error {
+			pk := common.Hex2Bytes(cCtx.String(PK))
+			me := crypto.GetAddressFromSecretKeyBytes(pk)
+			dataFolder := filepath.Join(cCtx.String(DURABLE_STORE_FOLDER), me.String())
+			if _, err := os.Stat(dataFolder); err != nil {
+				return fmt.Errorf("no durable store found for %s: %w", me, err)
+			}
+
+			keys, err := store.NewStoreKeySource(cCtx.String(STORE_PASSPHRASE), cCtx.String(STORE_KEY_COMMAND))
+			if err != nil {
+				return err
+			}
+			repair := cCtx.Bool(REPAIR)
+			report, err := store.CheckDurableStore(pk, dataFolder, repair, keys)
+			if err != nil {
+				return err
+			}
+			for _, p := range report.Problems {
+				line := fmt.Sprintf("%s/%s: %s", p.Table, p.Key, p.Problem)
+				if p.Repair != "" {
+					line += " (" + p.Repair + ")"
+				}
+				fmt.Println(line)
+			}
+			fmt.Printf("Checked %d records, found %d problems\n", report.Checked, len(report.Problems))
+			if len(report.Problems) > 0 && !repair {
+				return cli.Exit("", 1)
+			}
+			return nil
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
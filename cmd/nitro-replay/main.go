@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/tidwall/buntdb"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	PK                   = "pk"
+	EVENT_LOG            = "eventlog"
+	DURABLE_STORE_FOLDER = "durablestorefolder"
+	UNTIL                = "until"
+	VERBOSE              = "verbose"
+	STORE_PASSPHRASE     = "storepassphrase"
+	STORE_KEY_COMMAND    = "storekeycommand"
+)
+
+func main() {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:     PK,
+			Usage:    "Specifies the private key used by the nitro node which recorded the event log.",
+			Required: true,
+			EnvVars:  []string{"SC_PK"},
+		},
+		&cli.StringFlag{
+			Name:     EVENT_LOG,
+			Usage:    "Specifies the event log recorded by the nitro node.",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  DURABLE_STORE_FOLDER,
+			Usage: "Specifies a folder in which to keep the reconstructed store, for inspection once the replay stops. Without it, the store is kept in memory.",
+		},
+		&cli.StringFlag{
+			Name:    STORE_PASSPHRASE,
+			Usage:   "Specifies the store passphrase of the nitro node, which decrypts its event log if it is encrypted. The reconstructed durable store is encrypted with it.",
+			EnvVars: []string{"STORE_PASSPHRASE"},
+		},
+		&cli.StringFlag{
+			Name:    STORE_KEY_COMMAND,
+			Usage:   "Specifies the store key command of the nitro node, which decrypts its event log if it is encrypted. The reconstructed durable store is encrypted with it.",
+			EnvVars: []string{"STORE_KEY_COMMAND"},
+		},
+		&cli.Uint64Flag{
+			Name:  UNTIL,
+			Usage: "Stops the replay once the entry with the given sequence number has been handled.",
+		},
+		&cli.BoolFlag{
+			Name:    VERBOSE,
+			Usage:   "Prints every entry as it is replayed, rather than only those which complete or fail objectives.",
+			Aliases: []string{"v"},
+		},
+	}
+
+	app := &cli.App{
+		Name:  "nitro-replay",
+		Usage: "Reconstructs the state of a nitro node step by step from its event log, against a fresh store",
+		Flags: flags,
+		Action: func(cCtx *cli.Context) error {
+			pk := common.Hex2Bytes(cCtx.String(PK))
+			keys, err := store.NewStoreKeySource(cCtx.String(STORE_PASSPHRASE), cCtx.String(STORE_KEY_COMMAND))
+			if err != nil {
+				return err
+			}
+
+			var s store.Store
+			if folder := cCtx.String(DURABLE_STORE_FOLDER); folder != "" {
+				me := crypto.GetAddressFromSecretKeyBytes(pk)
+				dataFolder := filepath.Join(folder, me.String())
+				if _, err := os.Stat(dataFolder); err == nil {
+					return fmt.Errorf("a store already exists in %s: the replay needs a fresh one", dataFolder)
+				}
+				durable, err := store.NewEncryptedDurableStore(pk, dataFolder, buntdb.Config{}, keys)
+				if err != nil {
+					return err
+				}
+				s = durable
+			} else {
+				s = store.NewMemStore(pk)
+			}
+			defer s.Close()
+
+			eventLog, err := os.Open(cCtx.String(EVENT_LOG))
+			if err != nil {
+				return err
+			}
+			defer eventLog.Close()
+
+			until := cCtx.Uint64(UNTIL)
+			stopAt := cCtx.IsSet(UNTIL)
+			verbose := cCtx.Bool(VERBOSE)
+			steps := 0
+			err = engine.Replay(eventLog, keys, s, &engine.PermissivePolicy{}, func(step engine.ReplayStep) bool {
+				steps++
+				if verbose || step.Err != nil || len(step.Event.CompletedObjectives) > 0 || len(step.Event.FailedObjectives) > 0 {
+					fmt.Printf("%d %s %s\n", step.Entry.Seq, step.Entry.Time.Format("15:04:05.000"), step.Entry.Kind)
+				}
+				for _, o := range step.Event.CompletedObjectives {
+					fmt.Printf("\tcompleted %s\n", o.Id())
+				}
+				for _, id := range step.Event.FailedObjectives {
+					fmt.Printf("\tfailed %s\n", id)
+				}
+				if step.Err != nil {
+					fmt.Printf("\terror: %v\n", step.Err)
+				}
+				return !stopAt || step.Entry.Seq < until
+			})
+			if err != nil {
+				return err
+			}
+
+			ledgers, err := s.GetAllConsensusChannels()
+			if err != nil {
+				return err
+			}
+			channels, err := s.GetChannelsByParticipant(*s.GetAddress())
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Replayed %d entries, reconstructing %d ledger channels and %d other channels\n", steps, len(ledgers), len(channels))
+			return nil
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -1,22 +1,44 @@
 package main
 
 import (
+	"fmt"
 	"log"
 	"log/slog"
+	"math/big"
 	"os"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/cmd/utils"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/paymentproxy"
+	"github.com/statechannels/go-nitro/rpc"
 	"github.com/urfave/cli/v2"
 )
 
 const (
 	NITRO_ENDPOINT  = "nitroendpoint"
+	NITRO_API_KEY   = "nitroapikey"
 	PROXY_ADDRESS   = "proxyaddress"
 	DESTINATION_URL = "destinationurl"
 	COST_PER_BYTE   = "costperbyte"
 
+	PRICE_ORACLE_URL       = "priceoracleurl"
+	PRICE_ORACLE_CACHE_TTL = "priceoraclecachettl"
+
+	PREPAID_TOP_UP      = "prepaidtopup"
+	PREPAID_LOW_BALANCE = "prepaidlowbalance"
+
+	SESSION_REQUESTS           = "sessionrequests"
+	SESSION_LIFETIME           = "sessionlifetime"
+	SESSION_KEY                = "sessionkey"
+	SESSION_RECONCILE_INTERVAL = "sessionreconcileinterval"
+
+	STREAM_CHUNK_SIZE      = "streamchunksize"
+	STREAM_PAYMENT_TIMEOUT = "streampaymenttimeout"
+
+	VOUCHER_SOURCES = "vouchersources"
+
 	TLS_CERT_FILEPATH = "tlscertfilepath"
 	TLS_KEY_FILEPATH  = "tlskeyfilepath"
 )
@@ -39,6 +61,12 @@ func main() {
 				Value:   "localhost:4007/api/v1",
 				Aliases: []string{"n"},
 			},
+			&cli.StringFlag{
+				Name:    NITRO_API_KEY,
+				Usage:   "Specifies the api key to present to the Nitro RPC server, if it requires one. A key with the read scope suffices to receive vouchers.",
+				Value:   "",
+				EnvVars: []string{"NITRO_API_KEY"},
+			},
 			&cli.StringFlag{
 				Name:    DESTINATION_URL,
 				Usage:   "Specifies the destination URL to forward requests to. It should be a fully qualified URL, including the protocol (e.g. http://localhost:8081)",
@@ -51,6 +79,61 @@ func main() {
 				Value:   1,
 				Aliases: []string{"c"},
 			},
+			&cli.StringFlag{
+				Name:  PRICE_ORACLE_URL,
+				Usage: "Specifies the URL of an HTTP price oracle to compute the amount charged for each request. If the oracle fails, the cost per byte is charged instead.",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:  PRICE_ORACLE_CACHE_TTL,
+				Usage: "Specifies how long prices from the price oracle are reused for identical requests",
+				Value: time.Minute,
+			},
+			&cli.Uint64Flag{
+				Name:  PREPAID_TOP_UP,
+				Usage: "Enables prepaid mode, in which clients top up a prepaid balance with occasional vouchers and requests presenting the token issued with each top up are debited from it. Specifies the amount of wei to invoice clients for when their balance is low. If 0, every request must be paid with a voucher.",
+				Value: 0,
+			},
+			&cli.Uint64Flag{
+				Name:  PREPAID_LOW_BALANCE,
+				Usage: "Specifies the prepaid balance, in wei, below which clients are invoiced for a top up",
+				Value: 0,
+			},
+			&cli.IntFlag{
+				Name:  SESSION_REQUESTS,
+				Usage: "Enables session tokens, which clients may present in place of a voucher after paying with one. Specifies the number of requests a token covers. If 0, every request must be paid with a voucher.",
+				Value: 0,
+			},
+			&cli.DurationFlag{
+				Name:  SESSION_LIFETIME,
+				Usage: "Specifies how long a session token may be presented for",
+				Value: time.Minute,
+			},
+			&cli.StringFlag{
+				Name:  SESSION_KEY,
+				Usage: "Specifies the hex encoded key which signs session tokens. If not specified, a random key is used, and tokens do not survive a restart.",
+				Value: "",
+			},
+			&cli.DurationFlag{
+				Name:  SESSION_RECONCILE_INTERVAL,
+				Usage: "Specifies how often the amount owed for requests made with session tokens is checked against each channel's remaining funds",
+				Value: 10 * time.Second,
+			},
+			&cli.Uint64Flag{
+				Name:  STREAM_CHUNK_SIZE,
+				Usage: "Enables streaming, which lets clients pay for a response as it is streamed by adding the stream query param to a request. Specifies the number of bytes paid for at a time. If 0, responses are paid for up front.",
+				Value: 0,
+			},
+			&cli.DurationFlag{
+				Name:  STREAM_PAYMENT_TIMEOUT,
+				Usage: "Specifies how long a stream is paused waiting to be paid for before it is aborted",
+				Value: 30 * time.Second,
+			},
+			&cli.StringFlag{
+				Name:  VOUCHER_SOURCES,
+				Usage: "Specifies where in a request vouchers are accepted from, as a comma separated list of query (the channelId, amount and signature query params) and header (the X-Nitro-Voucher header)",
+				Value: "query,header",
+			},
 			&cli.StringFlag{
 				Name:  TLS_CERT_FILEPATH,
 				Usage: "Filepath to the TLS certificate. If not specified, TLS will not be used.",
@@ -75,7 +158,44 @@ func main() {
 				c.Uint64(COST_PER_BYTE),
 				c.String(TLS_CERT_FILEPATH),
 				c.String(TLS_KEY_FILEPATH),
+				rpc.WithApiKey(c.String(NITRO_API_KEY)),
 			)
+			if oracleUrl := c.String(PRICE_ORACLE_URL); oracleUrl != "" {
+				oracle := &paymentproxy.HTTPPriceOracle{Url: oracleUrl}
+				fallback := paymentproxy.PerBytePrice(c.Uint64(COST_PER_BYTE))
+				proxy.SetPriceOracle(paymentproxy.NewCachedPriceOracle(oracle, c.Duration(PRICE_ORACLE_CACHE_TTL), fallback))
+			}
+			sources, err := paymentproxy.ParseVoucherSources(c.String(VOUCHER_SOURCES))
+			if err != nil {
+				return err
+			}
+			proxy.SetVoucherSources(sources)
+			if topUp := c.Uint64(PREPAID_TOP_UP); topUp > 0 {
+				proxy.EnablePrepaidAccounts(new(big.Int).SetUint64(c.Uint64(PREPAID_LOW_BALANCE)), new(big.Int).SetUint64(topUp))
+			}
+
+			if requests := c.Int(SESSION_REQUESTS); requests > 0 {
+				var key []byte
+				if hexKey := c.String(SESSION_KEY); hexKey != "" {
+					var err error
+					if key, err = hexutil.Decode(hexKey); err != nil {
+						return fmt.Errorf("invalid session key: %w", err)
+					}
+				}
+				proxy.EnableSessions(paymentproxy.SessionConfig{
+					Key:               key,
+					MaxRequests:       requests,
+					Lifetime:          c.Duration(SESSION_LIFETIME),
+					ReconcileInterval: c.Duration(SESSION_RECONCILE_INTERVAL),
+				})
+			}
+
+			if chunkSize := c.Uint64(STREAM_CHUNK_SIZE); chunkSize > 0 {
+				proxy.EnableStreaming(paymentproxy.StreamConfig{
+					ChunkSize:      chunkSize,
+					PaymentTimeout: c.Duration(STREAM_PAYMENT_TIMEOUT),
+				})
+			}
 
 			return proxy.Start()
 		},
@@ -14,7 +14,6 @@ import (
 
 	"github.com/statechannels/go-nitro/internal/testdata"
 	"github.com/statechannels/go-nitro/rpc"
-	"github.com/statechannels/go-nitro/types"
 )
 
 // WaitForKillSignal blocks until we receive a kill or interrupt signal
@@ -40,12 +39,11 @@ func StopCommands(cmds ...*exec.Cmd) {
 	}
 }
 
-func CreateLedgerChannel(client rpc.RpcClientApi, counterPartyAddress common.Address, ledgerChannelDeposit uint64) error {
+func CreateLedgerChannel(client rpc.RpcClientApi, counterPartyAddress common.Address, asset common.Address, ledgerChannelDeposit uint64) error {
 	clientAddress, err := client.Address()
 	if err != nil {
 		return err
 	}
-	asset := types.Address{}
 	outcome := testdata.Outcomes.Create(clientAddress, counterPartyAddress, ledgerChannelDeposit, ledgerChannelDeposit, asset)
 	response, err := client.CreateLedgerChannel(counterPartyAddress, 0, outcome)
 	if err != nil {
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/statechannels/go-nitro/conformance"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	VECTORS  = "vectors"
+	GENERATE = "generate"
+)
+
+func main() {
+	flags := []cli.Flag{
+		&cli.StringFlag{
+			Name:    VECTORS,
+			Usage:   "Specifies a file of test vectors to verify, e.g. one produced by another implementation. Defaults to the canonical vectors.",
+			Aliases: []string{"v"},
+		},
+		&cli.StringFlag{
+			Name:  GENERATE,
+			Usage: "Writes freshly generated test vectors to the specified file, instead of verifying vectors.",
+		},
+	}
+
+	app := &cli.App{
+		Name:  "verify-conformance",
+		Usage: "Checks that go-nitro reproduces the channel ids, hashes and signatures of a set of conformance test vectors",
+		Flags: flags,
+		Action: func(cCtx *cli.Context) error {
+			if output := cCtx.String(GENERATE); output != "" {
+				vectors, err := conformance.Generate()
+				if err != nil {
+					return err
+				}
+				data, err := json.MarshalIndent(vectors, "", "  ")
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+					return err
+				}
+				fmt.Printf("Wrote %d state and %d voucher vectors to %s\n", len(vectors.States), len(vectors.Vouchers), output)
+				return nil
+			}
+
+			vectors, err := conformance.Canonical()
+			if input := cCtx.String(VECTORS); input != "" {
+				var data []byte
+				data, err = os.ReadFile(input)
+				if err != nil {
+					return err
+				}
+				err = json.Unmarshal(data, &vectors)
+			}
+			if err != nil {
+				return err
+			}
+
+			if err := conformance.Verify(vectors); err != nil {
+				return err
+			}
+			fmt.Printf("Verified %d state and %d voucher vectors\n", len(vectors.States), len(vectors.Vouchers))
+			return nil
+		},
+	}
+	if err := app.Run(os.Args); err != nil {
+		log.Fatal(err)
+	}
+}
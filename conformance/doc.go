@@ -0,0 +1,14 @@
+// Package conformance contains canonical test vectors for the hashing and signing of states and vouchers, and a
+// verifier for them.
+//
+// The vectors are published as vectors.json, using the same JSON encoding of states and vouchers as go-nitro's
+// messages (so amounts are JSON numbers which may exceed 2^53). Implementations in other languages should check that
+// they reproduce every channel id, hash and signature in the file. Since signatures are deterministic (RFC 6979), each
+// signature is reproducible from the signer's private key, which is included in the vectors.
+//
+// Conversely, vectors generated by another implementation from the same inputs may be checked against go-nitro with
+// `go run ./cmd/verify-conformance --vectors <file>`.
+//
+// vectors.json is regenerated with `go run ./cmd/verify-conformance --generate conformance/vectors.json`, which should
+// only be needed when vectors are added: a change to an existing vector is a breaking change to the protocol.
+package conformance // import "github.com/statechannels/go-nitro/conformance"
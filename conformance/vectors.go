@@ -0,0 +1,286 @@
+package conformance
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrMismatch is returned when a vector does not match the value computed by this implementation.
+const ErrMismatch = types.ConstError("conformance vector mismatch")
+
+//go:embed vectors.json
+var canonicalVectors []byte
+
+type (
+	// Vectors is a set of test vectors, as published in vectors.json.
+	Vectors struct {
+		States   []StateVector
+		Vouchers []VoucherVector
+	}
+
+	// StateVector is a state with its channel id, hash and the signature of each participant.
+	StateVector struct {
+		Description string
+		State       state.State
+		ChannelId   types.Destination
+		StateHash   types.Bytes32
+		Signatures  []SignatureVector
+	}
+
+	// VoucherVector is a voucher, including its payer's signature, with its hash and the payer's private key.
+	VoucherVector struct {
+		Description string
+		Voucher     payments.Voucher
+		VoucherHash types.Bytes32
+		Signer      types.Address
+		PrivateKey  hexutil.Bytes
+	}
+
+	// SignatureVector is a signature with the address and private key of its signer.
+	SignatureVector struct {
+		Signer     types.Address
+		PrivateKey hexutil.Bytes
+		Signature  state.Signature
+	}
+)
+
+// Canonical returns the vectors published in vectors.json.
+func Canonical() (Vectors, error) {
+	var v Vectors
+	err := json.Unmarshal(canonicalVectors, &v)
+	return v, err
+}
+
+// signers are the actors who sign the vectors, keyed by address.
+var signers = map[types.Address]ta.Actor{
+	ta.Alice.Address(): ta.Alice,
+	ta.Bob.Address():   ta.Bob,
+	ta.Irene.Address(): ta.Irene,
+}
+
+// Generate computes the vectors from their inputs. Its result should always equal Canonical().
+func Generate() (Vectors, error) {
+	v := Vectors{}
+	for _, input := range stateInputs() {
+		sv := StateVector{Description: input.description, State: input.state, ChannelId: input.state.ChannelId()}
+
+		hash, err := input.state.Hash()
+		if err != nil {
+			return Vectors{}, fmt.Errorf("%s: %w", input.description, err)
+		}
+		sv.StateHash = hash
+
+		for _, p := range input.state.Participants {
+			sig, err := input.state.Sign(signers[p].PrivateKey)
+			if err != nil {
+				return Vectors{}, fmt.Errorf("%s: %w", input.description, err)
+			}
+			sv.Signatures = append(sv.Signatures, SignatureVector{p, signers[p].PrivateKey, sig})
+		}
+		v.States = append(v.States, sv)
+	}
+
+	for _, input := range voucherInputs() {
+		voucher := input.voucher
+		if err := voucher.Sign(input.signer.PrivateKey); err != nil {
+			return Vectors{}, fmt.Errorf("%s: %w", input.description, err)
+		}
+		hash, err := voucher.Hash()
+		if err != nil {
+			return Vectors{}, fmt.Errorf("%s: %w", input.description, err)
+		}
+		v.Vouchers = append(v.Vouchers, VoucherVector{
+			Description: input.description,
+			Voucher:     voucher,
+			VoucherHash: hash,
+			Signer:      input.signer.Address(),
+			PrivateKey:  input.signer.PrivateKey,
+		})
+	}
+	return v, nil
+}
+
+// Verify checks that this implementation reproduces every channel id, hash and signature in v, and that every
+// signature recovers to its signer. It returns all of the mismatches found, each wrapping ErrMismatch.
+func Verify(v Vectors) error {
+	errs := []error{}
+	mismatch := func(description string, field string, expected any, got any) {
+		errs = append(errs, fmt.Errorf("%w: %s: expected %s %v, computed %v", ErrMismatch, description, field, expected, got))
+	}
+
+	for _, sv := range v.States {
+		if id := sv.State.ChannelId(); id != sv.ChannelId {
+			mismatch(sv.Description, "channel id", sv.ChannelId, id)
+		}
+		hash, err := sv.State.Hash()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sv.Description, err))
+			continue
+		}
+		if hash != sv.StateHash {
+			mismatch(sv.Description, "state hash", sv.StateHash, hash)
+		}
+		for _, s := range sv.Signatures {
+			errs = append(errs, verifySignature(sv.Description, s, sv.State.Sign, sv.State.RecoverSigner)...)
+		}
+	}
+
+	for _, vv := range v.Vouchers {
+		voucher := vv.Voucher
+		hash, err := voucher.Hash()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", vv.Description, err))
+			continue
+		}
+		if hash != vv.VoucherHash {
+			mismatch(vv.Description, "voucher hash", vv.VoucherHash, hash)
+		}
+		sign := func(pk []byte) (state.Signature, error) {
+			unsigned := payments.Voucher{ChannelId: voucher.ChannelId, Amount: voucher.Amount}
+			err := unsigned.Sign(pk)
+			return unsigned.Signature, err
+		}
+		recoverSigner := func(sig state.Signature) (types.Address, error) {
+			signed := payments.Voucher{ChannelId: voucher.ChannelId, Amount: voucher.Amount, Signature: sig}
+			return signed.RecoverSigner()
+		}
+		s := SignatureVector{vv.Signer, vv.PrivateKey, voucher.Signature}
+		errs = append(errs, verifySignature(vv.Description, s, sign, recoverSigner)...)
+	}
+	return errors.Join(errs...)
+}
+
+// verifySignature checks that signing with the vector's private key reproduces its signature, and that the signature
+// recovers to its signer.
+func verifySignature(description string, s SignatureVector, sign func([]byte) (state.Signature, error), recoverSigner func(state.Signature) (types.Address, error)) []error {
+	errs := []error{}
+	if address := crypto.GetAddressFromSecretKeyBytes(s.PrivateKey); address != s.Signer {
+		errs = append(errs, fmt.Errorf("%w: %s: private key belongs to %s, not signer %s", ErrMismatch, description, address, s.Signer))
+	}
+	computed, err := sign(s.PrivateKey)
+	if err != nil {
+		return append(errs, fmt.Errorf("%s: %w", description, err))
+	}
+	if !computed.Equal(s.Signature) {
+		errs = append(errs, fmt.Errorf("%w: %s: expected signature by %s %s, computed %s", ErrMismatch, description, s.Signer, s.Signature.ToHexString(), computed.ToHexString()))
+	}
+	signer, err := recoverSigner(s.Signature)
+	if err != nil {
+		return append(errs, fmt.Errorf("%s: %w", description, err))
+	}
+	if signer != s.Signer {
+		errs = append(errs, fmt.Errorf("%w: %s: expected signature to recover to %s, recovered %s", ErrMismatch, description, s.Signer, signer))
+	}
+	return errs
+}
+
+type stateInput struct {
+	description string
+	state       state.State
+}
+
+type voucherInput struct {
+	description string
+	voucher     payments.Voucher
+	signer      ta.Actor
+}
+
+var (
+	consensusApp      = common.HexToAddress("0x5e29E5Ab8EF33F050c7cc10B5a0456D975C5F88d")
+	virtualPaymentApp = common.HexToAddress("0x9eD274314f0fB37837346C425D3cF28d89ca9599")
+	token             = common.HexToAddress("0x1c57E5a8bA1Df3C8A64f4d1E7Ea0F0c6a15D6b23")
+)
+
+// stateInputs are the states for which vectors are generated. Existing inputs must never be changed.
+func stateInputs() []stateInput {
+	ledgerOutcome := outcome.Exit{{
+		Asset: types.Address{},
+		Allocations: outcome.Allocations{
+			{Destination: ta.Alice.Destination(), Amount: big.NewInt(10)},
+			{Destination: ta.Irene.Destination(), Amount: big.NewInt(10)},
+		},
+	}}
+	ledger := state.State{
+		Participants:      []types.Address{ta.Alice.Address(), ta.Irene.Address()},
+		ChannelNonce:      1,
+		AppDefinition:     consensusApp,
+		ChallengeDuration: 3600,
+		AppData:           types.Bytes{},
+		Outcome:           ledgerOutcome,
+		TurnNum:           0,
+	}
+
+	virtualOutcome := outcome.Exit{{
+		Asset: types.Address{},
+		Allocations: outcome.Allocations{
+			{Destination: ta.Alice.Destination(), Amount: big.NewInt(6)},
+			{Destination: ta.Bob.Destination(), Amount: big.NewInt(0)},
+		},
+	}}
+	virtual := state.State{
+		Participants:      []types.Address{ta.Alice.Address(), ta.Irene.Address(), ta.Bob.Address()},
+		ChannelNonce:      0xdeadbeefcafe,
+		AppDefinition:     virtualPaymentApp,
+		ChallengeDuration: 60,
+		AppData:           types.Bytes{},
+		Outcome:           virtualOutcome,
+		TurnNum:           1,
+	}
+
+	guaranteed, err := ledgerOutcome[0].Allocations.DivertToGuarantee(ta.Alice.Destination(), ta.Bob.Destination(), big.NewInt(6), big.NewInt(0), virtual.ChannelId())
+	if err != nil {
+		panic(err)
+	}
+	ledgerWithGuarantee := ledger.Clone()
+	ledgerWithGuarantee.Outcome[0].Allocations = guaranteed
+	ledgerWithGuarantee.TurnNum = 2
+
+	tokenLedger := ledger.Clone()
+	tokenLedger.ChannelNonce = 2
+	tokenLedger.Outcome = outcome.Exit{{
+		Asset: token,
+		Allocations: outcome.Allocations{
+			{Destination: ta.Alice.Destination(), Amount: new(big.Int).Lsh(big.NewInt(1), 100)},
+			{Destination: ta.Irene.Destination(), Amount: big.NewInt(1)},
+		},
+	}}
+
+	withAppData := virtual.Clone()
+	withAppData.AppData = types.Bytes{0x01, 0x02, 0x03, 0xff}
+	withAppData.TurnNum = 7
+
+	final := ledger.Clone()
+	final.TurnNum = 3
+	final.IsFinal = true
+
+	return []stateInput{
+		{"prefund state of a ledger channel", ledger},
+		{"ledger state funding a virtual channel with a guarantee", ledgerWithGuarantee},
+		{"ledger state holding a token amount larger than 64 bits", tokenLedger},
+		{"postfund state of a virtual payment channel", virtual},
+		{"state with app data", withAppData},
+		{"final state of a ledger channel", final},
+	}
+}
+
+// voucherInputs are the vouchers for which vectors are generated. Existing inputs must never be changed.
+func voucherInputs() []voucherInput {
+	channelId := stateInputs()[3].state.ChannelId()
+	return []voucherInput{
+		{"voucher for no payment", payments.Voucher{ChannelId: channelId, Amount: big.NewInt(0)}, ta.Alice},
+		{"voucher for a single unit", payments.Voucher{ChannelId: channelId, Amount: big.NewInt(1)}, ta.Alice},
+		{"voucher for an amount larger than 64 bits", payments.Voucher{ChannelId: channelId, Amount: new(big.Int).Lsh(big.NewInt(3), 70)}, ta.Alice},
+	}
+}
@@ -0,0 +1,47 @@
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestCanonicalVectors(t *testing.T) {
+	canonical, err := Canonical()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Verify(canonical); err != nil {
+		t.Fatal(err)
+	}
+
+	generated, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := json.Marshal(canonical)
+	got, _ := json.Marshal(generated)
+	if !bytes.Equal(want, got) {
+		t.Fatal("generated vectors differ from vectors.json: existing vectors must not change")
+	}
+}
+
+func TestVerifyDetectsMismatch(t *testing.T) {
+	vectors, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vectors.States[0].State.TurnNum++
+	vectors.Vouchers[0].Voucher.Amount = big.NewInt(1000)
+
+	err = Verify(vectors)
+	if !errors.Is(err, ErrMismatch) {
+		t.Fatalf("expected %v, got %v", ErrMismatch, err)
+	}
+	// The hashes no longer match, and each of the three signatures is neither reproduced nor recovers to its signer
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 8 {
+		t.Fatalf("expected 8 mismatches, got %d: %v", n, err)
+	}
+}
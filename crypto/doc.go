@@ -1,2 +1,3 @@
 // Package crypto contains types and functions for creating Ethereum private keys and accounts, and creating/recovering signatures made with such keys.
+// Signatures on states, vouchers and snapshots are made and checked through pluggable signature schemes, which default to Ethereum signed messages.
 package crypto // import "github.com/statechannels/go-nitro/crypto"
@@ -0,0 +1,143 @@
+package crypto
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrInvalidSignature is returned when a signature was not made by the expected signer.
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// SignatureScheme signs messages, and verifies the signatures on them.
+type SignatureScheme interface {
+	// Sign returns a signature on the message. The interpretation of the secret key is up to the scheme: it may be the
+	// key itself, or a handle to a key held elsewhere (for instance, by a hardware wallet).
+	Sign(message []byte, secretKey []byte) (Signature, error)
+	// Verify returns nil if sig is a valid signature by signer on the message, and ErrInvalidSignature (or another
+	// error, if it cannot tell) otherwise.
+	Verify(message []byte, sig Signature, signer types.Address) error
+}
+
+// RecoveringScheme is a SignatureScheme whose signatures identify their signer.
+type RecoveringScheme interface {
+	SignatureScheme
+	// RecoverSigner returns the address of the signer of the message.
+	RecoverSigner(message []byte, sig Signature) (types.Address, error)
+}
+
+// EthereumScheme is the default SignatureScheme. It signs messages with secp256k1 keys, prefixed as Ethereum signed
+// messages, as the adjudicator expects.
+type EthereumScheme struct{}
+
+func (EthereumScheme) Sign(message []byte, secretKey []byte) (Signature, error) {
+	return SignEthereumMessage(message, secretKey)
+}
+
+func (EthereumScheme) RecoverSigner(message []byte, sig Signature) (types.Address, error) {
+	return RecoverEthereumMessageSigner(message, sig)
+}
+
+func (s EthereumScheme) Verify(message []byte, sig Signature, signer types.Address) error {
+	recovered, err := s.RecoverSigner(message, sig)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Schemes is a registry of the signature schemes used to make and check signatures: a default scheme, and the schemes
+// of particular signers, such as smart contract wallets. Each engine holds a registry of its own.
+//
+// A nil *Schemes uses EthereumScheme for every signer.
+type Schemes struct {
+	defaultScheme RecoveringScheme
+
+	mu sync.RWMutex
+	// signerSchemes holds the schemes used to verify the signatures of particular signers
+	signerSchemes map[types.Address]SignatureScheme
+}
+
+// NewSchemes returns a registry which makes signatures, and verifies the signatures of any signer without a scheme of
+// its own, with the default scheme.
+func NewSchemes(defaultScheme RecoveringScheme) *Schemes {
+	return &Schemes{defaultScheme: defaultScheme, signerSchemes: map[types.Address]SignatureScheme{}}
+}
+
+// Default returns the scheme used to make signatures, and to verify the signatures of signers without a scheme of
+// their own.
+func (r *Schemes) Default() RecoveringScheme {
+	if r == nil {
+		return EthereumScheme{}
+	}
+	return r.defaultScheme
+}
+
+// SetSignerScheme sets the scheme used to verify the signatures of the signer. A nil scheme restores the default.
+func (r *Schemes) SetSignerScheme(signer types.Address, s SignatureScheme) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if s == nil {
+		delete(r.signerSchemes, signer)
+		return
+	}
+	r.signerSchemes[signer] = s
+}
+
+// signerScheme returns the scheme of the signer, and true, if it has one of its own.
+func (r *Schemes) signerScheme(signer types.Address) (SignatureScheme, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	s, ok := r.signerSchemes[signer]
+	return s, ok
+}
+
+// HasSignerScheme returns true if the signatures of the signer are verified with a scheme of its own.
+func (r *Schemes) HasSignerScheme(signer types.Address) bool {
+	_, ok := r.signerScheme(signer)
+	return ok
+}
+
+// Sign signs the message with the secret key, using the default scheme.
+func (r *Schemes) Sign(message []byte, secretKey []byte) (Signature, error) {
+	return r.Default().Sign(message, secretKey)
+}
+
+// RecoverSigner returns the address of the signer of the message, using the default scheme. Signers with a scheme of
+// their own may not be recoverable, so their signatures should be checked with Verify.
+func (r *Schemes) RecoverSigner(message []byte, sig Signature) (common.Address, error) {
+	return r.Default().RecoverSigner(message, sig)
+}
+
+// Verify returns nil if sig is a valid signature by signer on the message, using the signer's scheme if it has one,
+// and the default scheme otherwise.
+func (r *Schemes) Verify(message []byte, sig Signature, signer types.Address) error {
+	if s, ok := r.signerScheme(signer); ok {
+		return s.Verify(message, sig, signer)
+	}
+	return r.Default().Verify(message, sig, signer)
+}
+
+// Sign signs the message with the secret key, using EthereumScheme.
+func Sign(message []byte, secretKey []byte) (Signature, error) {
+	return EthereumScheme{}.Sign(message, secretKey)
+}
+
+// RecoverSigner returns the address of the signer of the message, using EthereumScheme.
+func RecoverSigner(message []byte, sig Signature) (common.Address, error) {
+	return EthereumScheme{}.RecoverSigner(message, sig)
+}
+
+// Verify returns nil if sig is a valid signature by signer on the message, using EthereumScheme.
+func Verify(message []byte, sig Signature, signer types.Address) error {
+	return EthereumScheme{}.Verify(message, sig, signer)
+}
@@ -51,6 +51,26 @@ func RecoverEthereumMessageSigner(message []byte, signature Signature) (common.A
 	}
 
 	digest := computeEthereumSignedMessageDigest(message)
+	return recoverDigestSigner(digest, sig)
+}
+
+// EthereumSignedMessageDigest returns the digest which SignEthereumMessage signs: the keccak256 hash of the message
+// prefixed with "\x19Ethereum Signed Message:\n" + len(message). It is the hash an ERC-1271 wallet is asked to verify.
+func EthereumSignedMessageDigest(message []byte) common.Hash {
+	return common.BytesToHash(computeEthereumSignedMessageDigest(message))
+}
+
+// RecoverDigestSigner recovers the address which signed the digest, which should already be prefixed and hashed.
+func RecoverDigestSigner(digest common.Hash, signature Signature) (common.Address, error) {
+	sig := signature
+	if int(sig.V) >= 27 {
+		sig.V = byte(int(sig.V - 27))
+	}
+	return recoverDigestSigner(digest[:], sig)
+}
+
+// recoverDigestSigner recovers the address which signed the digest. The signature's V must be 0 or 1.
+func recoverDigestSigner(digest []byte, sig Signature) (common.Address, error) {
 	pubKey, error := secp256k1.RecoverPubkey(digest, joinSignature(sig))
 	if error != nil {
 		return types.Address{}, error
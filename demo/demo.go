@@ -0,0 +1,251 @@
+// Package demo runs a small nitro network within a single process: a payer and a payee, and optionally a hub which
+// routes payments between them. The nodes share a simulated chain and pass messages in memory, so nothing external is
+// needed to run it. Each node may also be served over rpc on a local port, for clients and the UI to connect to.
+//
+// A Network may be scripted through the nodes it exposes, or with Run, which funds channels, makes payments and then
+// defunds the channels. Run serves as both an example of using the node API and as a smoke test of the whole stack.
+package demo // import "github.com/statechannels/go-nitro/demo"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	"github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	nitroRpc "github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Config configures a Network.
+type Config struct {
+	// WithHub routes payments through a hub, over virtual channels funded by ledger channels with it. Otherwise the payer
+	// funds payment channels with a ledger channel directly with the payee.
+	WithHub bool
+	// RpcPort, if non-zero, serves the payer over http rpc on this port, the payee on the next, and the hub on the one after.
+	RpcPort int
+	// Deposit is the amount each participant deposits into each ledger channel. It defaults to DEFAULT_DEPOSIT.
+	Deposit *big.Int
+}
+
+// DEFAULT_DEPOSIT is the amount, in wei, each participant deposits into each ledger channel by default.
+var DEFAULT_DEPOSIT = big.NewInt(1_000_000)
+
+// Network is a payer, a payee and optionally a hub, running in this process.
+type Network struct {
+	Payer *node.Node
+	Payee *node.Node
+	Hub   *node.Node // nil unless the network was started with a hub
+
+	config     Config
+	sim        chainservice.SimulatedChain
+	rpcServers []*nitroRpc.RpcServer
+}
+
+// Start starts the nodes of a network configured by config.
+func Start(config Config) (*Network, error) {
+	if config.Deposit == nil {
+		config.Deposit = DEFAULT_DEPOSIT
+	}
+	actors := []ta.Actor{ta.Alice, ta.Bob}
+	if config.WithHub {
+		actors = append(actors, ta.Irene)
+	}
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(uint64(len(actors)))
+	if err != nil {
+		return nil, err
+	}
+	nw := &Network{config: config, sim: sim}
+	broker := messageservice.NewBroker()
+
+	for i, actor := range actors {
+		chain, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[i])
+		if err != nil {
+			return nil, errors.Join(err, nw.Close())
+		}
+		msg := messageservice.NewTestMessageService(actor.Address(), broker, 0)
+		n := node.New(node.WithMessageService(msg), node.WithChainService(chain), node.WithStore(store.NewMemStore(actor.PrivateKey)), node.WithPolicy(&engine.PermissivePolicy{}))
+		switch i {
+		case 0:
+			nw.Payer = &n
+		case 1:
+			nw.Payee = &n
+		case 2:
+			nw.Hub = &n
+		}
+
+		if config.RpcPort != 0 {
+			server, err := rpc.InitializeRpcServer(&n, config.RpcPort+i, netaddr.DualStack, transport.Http, nil, nil)
+			if err != nil {
+				return nil, errors.Join(err, nw.Close())
+			}
+			nw.rpcServers = append(nw.rpcServers, server)
+			slog.Info("Serving demo node", "address", actor.Address(), "rpcPort", config.RpcPort+i)
+		}
+	}
+	return nw, nil
+}
+
+// Result describes the channels used, and the payments made, by Run.
+type Result struct {
+	LedgerChannels []types.Destination
+	PaymentChannel types.Destination
+	Paid           *big.Int // the total the payee was paid
+}
+
+// Run scripts the life of a payment channel: it opens ledger channels, opens a payment channel from the payer to the
+// payee, makes the payments of amount, waiting for the payee to receive them, then closes the payment channel and the
+// ledger channels. The payment channel is funded with the deposit, so the payments must not total more than it.
+func (nw *Network) Run(ctx context.Context, payments int, amount *big.Int) (Result, error) {
+	result := Result{Paid: big.NewInt(0)}
+	payer, payee := nw.Payer, nw.Payee
+
+	// Fund the ledger channels
+	type ledger struct {
+		left, right *node.Node
+		id          types.Destination
+	}
+	ledgers := []*ledger{{left: payer, right: payee}}
+	intermediaries := []types.Address{}
+	if nw.Hub != nil {
+		ledgers = []*ledger{{left: payer, right: nw.Hub}, {left: nw.Hub, right: payee}}
+		intermediaries = append(intermediaries, *nw.Hub.Address)
+	}
+	for _, l := range ledgers {
+		o := nw.outcome(*l.left.Address, *l.right.Address, nw.config.Deposit)
+		response, err := l.left.CreateLedgerChannelContext(ctx, *l.right.Address, 0, o)
+		if err != nil {
+			return result, fmt.Errorf("could not create ledger channel: %w", err)
+		}
+		if err := waitForObjective(ctx, response.Id, l.left, l.right); err != nil {
+			return result, err
+		}
+		l.id = response.ChannelId
+		result.LedgerChannels = append(result.LedgerChannels, l.id)
+	}
+	slog.Info("Demo ledger channels funded", "channels", result.LedgerChannels)
+
+	// Fund the payment channel, then pay through it
+	o := nw.outcome(*payer.Address, *payee.Address, big.NewInt(0))
+	paymentChannel, err := payer.CreatePaymentChannelContext(ctx, intermediaries, *payee.Address, 0, o)
+	if err != nil {
+		return result, fmt.Errorf("could not create payment channel: %w", err)
+	}
+	if err := waitForObjective(ctx, paymentChannel.Id, nw.nodes()...); err != nil {
+		return result, err
+	}
+	result.PaymentChannel = paymentChannel.ChannelId
+	slog.Info("Demo payment channel funded", "channel", result.PaymentChannel)
+
+	for i := 0; i < payments; i++ {
+		if err := payer.PayContext(ctx, result.PaymentChannel, amount); err != nil {
+			return result, fmt.Errorf("could not pay: %w", err)
+		}
+		result.Paid.Add(result.Paid, amount)
+	}
+	if err := waitForPaid(ctx, payee, result.PaymentChannel, result.Paid); err != nil {
+		return result, err
+	}
+	slog.Info("Demo payments received", "paid", result.Paid)
+
+	// Defund the payment channel, then the ledger channels
+	closePayment, err := payer.ClosePaymentChannelContext(ctx, result.PaymentChannel)
+	if err != nil {
+		return result, fmt.Errorf("could not close payment channel: %w", err)
+	}
+	if err := waitForObjective(ctx, closePayment, nw.nodes()...); err != nil {
+		return result, err
+	}
+	for _, l := range ledgers {
+		closeLedger, err := l.left.CloseLedgerChannelContext(ctx, l.id)
+		if err != nil {
+			return result, fmt.Errorf("could not close ledger channel: %w", err)
+		}
+		if err := waitForObjective(ctx, closeLedger, l.left, l.right); err != nil {
+			return result, err
+		}
+	}
+	slog.Info("Demo channels defunded")
+	return result, nil
+}
+
+// outcome allocates the deposit to from, and theirs to to, in the chain's native token.
+func (nw *Network) outcome(from, to types.Address, theirs *big.Int) outcome.Exit {
+	return outcome.Exit{outcome.SingleAssetExit{
+		Asset: types.Address{},
+		Allocations: outcome.Allocations{
+			{Destination: types.AddressToDestination(from), Amount: nw.config.Deposit},
+			{Destination: types.AddressToDestination(to), Amount: theirs},
+		},
+	}}
+}
+
+func (nw *Network) nodes() []*node.Node {
+	nodes := []*node.Node{nw.Payer, nw.Payee}
+	if nw.Hub != nil {
+		nodes = append(nodes, nw.Hub)
+	}
+	return nodes
+}
+
+// Close stops the nodes, and any rpc servers serving them.
+func (nw *Network) Close() error {
+	var errs []error
+	for i, n := range nw.nodes() {
+		if n == nil {
+			continue
+		}
+		if i < len(nw.rpcServers) {
+			// Closing an rpc server closes its node
+			errs = append(errs, nw.rpcServers[i].Close())
+		} else {
+			errs = append(errs, n.Close())
+		}
+	}
+	errs = append(errs, nw.sim.Close())
+	return errors.Join(errs...)
+}
+
+// waitForObjective waits for each of the nodes to complete the objective.
+func waitForObjective(ctx context.Context, id protocols.ObjectiveId, nodes ...*node.Node) error {
+	for _, n := range nodes {
+		if err := n.WaitForObjective(ctx, id); err != nil {
+			return fmt.Errorf("objective %s did not complete: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// paidPollInterval is how often waitForPaid checks the payments received by the payee.
+const paidPollInterval = 10 * time.Millisecond
+
+// waitForPaid waits until the payee has been paid total through the payment channel.
+func waitForPaid(ctx context.Context, payee *node.Node, channelId types.Destination, total *big.Int) error {
+	for {
+		info, err := payee.GetPaymentChannel(channelId)
+		if err != nil {
+			return err
+		}
+		if info.Balance.PaidSoFar.ToInt().Cmp(total) >= 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("payments did not arrive: %w", ctx.Err())
+		case <-time.After(paidPollInterval):
+		}
+	}
+}
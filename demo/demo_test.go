@@ -0,0 +1,45 @@
+package demo
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	for _, withHub := range []bool{false, true} {
+		name := "direct"
+		if withHub {
+			name = "hub"
+		}
+		t.Run(name, func(t *testing.T) {
+			nw, err := Start(Config{WithHub: withHub})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				if err := nw.Close(); err != nil {
+					t.Error(err)
+				}
+			}()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			result, err := nw.Run(ctx, 5, big.NewInt(10))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Paid.Cmp(big.NewInt(50)) != 0 {
+				t.Errorf("expected 50 to be paid, got %s", result.Paid)
+			}
+			wantLedgers := 1
+			if withHub {
+				wantLedgers = 2
+			}
+			if len(result.LedgerChannels) != wantLedgers {
+				t.Errorf("expected %d ledger channels, got %d", wantLedgers, len(result.LedgerChannels))
+			}
+		})
+	}
+}
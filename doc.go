@@ -42,5 +42,14 @@
 //	  -d '{"jsonrpc":"2.0","id":1,"method":"get_address","params":{}}' \
 //	  http://localhost:4005/api/v1
 //
+// Each version of the rpc api is served at its own path, such as /api/v2. A client may offer the versions it speaks
+// with the negotiate_version method, to learn the latest version the node also serves.
+//
+// If the node is started with -rpcapikeys, each client must present an api key, whose scope limits the methods it
+// may call: a "read" key may only query the node, a "sign" key may also make payments and fund channels, and an
+// "admin" key may also operate the node (with set_config, set_log_level, set_message_faults, get_debug_bundle,
+// rebind_rpc_server, block_peer, unblock_peer and close_all_channels) and manage its api keys. Without api keys,
+// any client which can reach the rpc port may call every method.
+//
 // but see  [github.com/statechannels/go-nitro/rpc] or https://github.com/statechannels/go-nitro/tree/main/packages/nitro-rpc-client for an RPC client to do so programmatically.
 package main
@@ -0,0 +1,214 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lmittmann/tint"
+)
+
+// Modules which may be logged at their own level.
+const (
+	ENGINE_MODULE         = "engine"
+	CHAINSERVICE_MODULE   = "chainservice"
+	MESSAGESERVICE_MODULE = "messageservice"
+	RPC_MODULE            = "rpc"
+	CLUSTER_MODULE        = "cluster"
+	STORE_MODULE          = "store"
+)
+
+// Modules lists the modules which may be logged at their own level.
+var Modules = []string{ENGINE_MODULE, CHAINSERVICE_MODULE, MESSAGESERVICE_MODULE, RPC_MODULE, CLUSTER_MODULE, STORE_MODULE}
+
+const (
+	ConsoleFormat = "console"
+	JsonFormat    = "json"
+)
+
+// Config describes how and where logs are written.
+type Config struct {
+	// Format is either ConsoleFormat (the default) or JsonFormat
+	Format string
+	// Level is the level used by any module without a level of its own
+	Level slog.Level
+	// ModuleLevels overrides Level for individual modules, keyed by module name
+	ModuleLevels map[string]slog.Level
+	// File is the path of the file to write logs to. If empty, logs are written to stdout.
+	File string
+	// MaxSizeMB is the size a log file may grow to before it is rotated. Zero disables rotation.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated log files which are kept.
+	MaxBackups int
+}
+
+var (
+	// rootLevel is the level of the default logger, which applies to any module without a level of its own.
+	rootLevel = new(slog.LevelVar)
+
+	moduleLevelsMu sync.RWMutex
+	moduleLevels   = map[string]*slog.LevelVar{}
+)
+
+// SetupLogger sets up the default logger according to the given config.
+func SetupLogger(cfg Config) error {
+	var w io.Writer = os.Stdout
+	if cfg.File != "" {
+		rw, err := newRotatingWriter(cfg.File, int64(cfg.MaxSizeMB)*1024*1024, cfg.MaxBackups)
+		if err != nil {
+			return err
+		}
+		w = rw
+	}
+	w = io.MultiWriter(w, recentLogs)
+
+	rootLevel.Set(cfg.Level)
+	for module, level := range cfg.ModuleLevels {
+		if err := SetLevel(module, level); err != nil {
+			return err
+		}
+	}
+
+	var h slog.Handler
+	switch cfg.Format {
+	case "", ConsoleFormat:
+		h = tint.NewHandler(w, &tint.Options{Level: rootLevel, TimeFormat: time.Kitchen})
+	case JsonFormat:
+		h = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: rootLevel})
+	default:
+		return fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+	slog.SetDefault(slog.New(h))
+	return nil
+}
+
+// NewFileLogger returns a json logger writing to the given file, rotated as described by maxSizeMB and maxBackups.
+// It is independent of the default logger: its records are neither filtered by log level nor included in RecentLogs.
+func NewFileLogger(file string, maxSizeMB int, maxBackups int) (*slog.Logger, error) {
+	rw, err := newRotatingWriter(file, int64(maxSizeMB)*1024*1024, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(slog.NewJSONHandler(rw, &slog.HandlerOptions{Level: LevelTrace})), nil
+}
+
+// ModuleLogger returns a logger, derived from the default logger, whose level can be set independently via SetLevel.
+func ModuleLogger(module string) *slog.Logger {
+	return slog.New(&moduleHandler{module: module, inner: slog.Default().Handler()})
+}
+
+// SetLevel sets the level of the given module. An empty module name sets the level of the default logger.
+func SetLevel(module string, level slog.Level) error {
+	if module == "" {
+		rootLevel.Set(level)
+		return nil
+	}
+	if !isModule(module) {
+		return fmt.Errorf("unknown log module %q", module)
+	}
+
+	moduleLevelsMu.Lock()
+	defer moduleLevelsMu.Unlock()
+	lv, ok := moduleLevels[module]
+	if !ok {
+		lv = new(slog.LevelVar)
+		moduleLevels[module] = lv
+	}
+	lv.Set(level)
+	return nil
+}
+
+// Levels returns the current level of each module, keyed by module name.
+// The level of the default logger is keyed by the empty string.
+func Levels() map[string]slog.Level {
+	levels := map[string]slog.Level{"": rootLevel.Level()}
+	for _, module := range Modules {
+		levels[module] = levelFor(module)
+	}
+	return levels
+}
+
+// ParseLevel parses a level name such as "debug" or "warn". "trace" is accepted for LevelTrace.
+func ParseLevel(s string) (slog.Level, error) {
+	if strings.EqualFold(s, "trace") {
+		return LevelTrace, nil
+	}
+	var l slog.Level
+	err := l.UnmarshalText([]byte(s))
+	return l, err
+}
+
+// ParseModuleLevels parses a comma separated list of module=level pairs, eg "engine=debug,rpc=warn".
+func ParseModuleLevels(s string) (map[string]slog.Level, error) {
+	levels := map[string]slog.Level{}
+	if s == "" {
+		return levels, nil
+	}
+	for _, pair := range strings.Split(s, ",") {
+		module, levelString, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return nil, fmt.Errorf("expected module=level, got %q", pair)
+		}
+		if !isModule(module) {
+			return nil, fmt.Errorf("unknown log module %q", module)
+		}
+		level, err := ParseLevel(levelString)
+		if err != nil {
+			return nil, err
+		}
+		levels[module] = level
+	}
+	return levels, nil
+}
+
+func isModule(module string) bool {
+	for _, m := range Modules {
+		if m == module {
+			return true
+		}
+	}
+	return false
+}
+
+// levelFor returns the level of the given module, falling back to the level of the default logger.
+func levelFor(module string) slog.Level {
+	moduleLevelsMu.RLock()
+	defer moduleLevelsMu.RUnlock()
+	if lv, ok := moduleLevels[module]; ok {
+		return lv.Level()
+	}
+	return rootLevel.Level()
+}
+
+// moduleHandler filters records according to the level of its module before passing them on to the inner handler.
+type moduleHandler struct {
+	module string
+	inner  slog.Handler
+}
+
+func (h *moduleHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	moduleLevelsMu.RLock()
+	lv, ok := moduleLevels[h.module]
+	moduleLevelsMu.RUnlock()
+	if !ok {
+		return h.inner.Enabled(ctx, level)
+	}
+	return level >= lv.Level()
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.inner.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &moduleHandler{module: h.module, inner: h.inner.WithAttrs(attrs)}
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	return &moduleHandler{module: h.module, inner: h.inner.WithGroup(name)}
+}
@@ -0,0 +1,112 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestModuleLevels(t *testing.T) {
+	buf := &bytes.Buffer{}
+	original := slog.Default()
+	defer slog.SetDefault(original)
+	slog.SetDefault(slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: LevelTrace})))
+
+	engineLogger := ModuleLogger(ENGINE_MODULE)
+	rpcLogger := ModuleLogger(RPC_MODULE)
+
+	if err := SetLevel(ENGINE_MODULE, slog.LevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		moduleLevelsMu.Lock()
+		delete(moduleLevels, ENGINE_MODULE)
+		moduleLevelsMu.Unlock()
+	}()
+
+	engineLogger.Info("engine-info")
+	engineLogger.Warn("engine-warn")
+	rpcLogger.Debug("rpc-debug")
+
+	out := buf.String()
+	if strings.Contains(out, "engine-info") {
+		t.Errorf("expected engine info log to be filtered out")
+	}
+	if !strings.Contains(out, "engine-warn") || !strings.Contains(out, "rpc-debug") {
+		t.Errorf("expected engine warn and rpc debug logs to be written, got %s", out)
+	}
+
+	if err := SetLevel("unknown", slog.LevelInfo); err == nil {
+		t.Errorf("expected an error setting the level of an unknown module")
+	}
+}
+
+func TestParseModuleLevels(t *testing.T) {
+	levels, err := ParseModuleLevels("engine=trace, rpc=warn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if levels[ENGINE_MODULE] != LevelTrace || levels[RPC_MODULE] != slog.LevelWarn {
+		t.Errorf("unexpected levels %v", levels)
+	}
+
+	if _, err := ParseModuleLevels("engine"); err == nil {
+		t.Errorf("expected an error for a pair without a level")
+	}
+}
+
+func TestRotatingWriter(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "nitro.log")
+	w, err := newRotatingWriter(filename, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := w.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]string{
+		filename:        "fourth\n",
+		filename + ".1": "third\n",
+		filename + ".2": "second\n",
+	}
+	for name, want := range expected {
+		got, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("%s: expected %q, got %q", name, want, got)
+		}
+	}
+	if _, err := os.Stat(filename + ".3"); !os.IsNotExist(err) {
+		t.Errorf("expected at most 2 backups to be kept")
+	}
+}
+
+func TestRecentLogWriter(t *testing.T) {
+	w := &recentLogWriter{}
+	for i := 0; i < recentLogsCapacity+2; i++ {
+		if _, err := fmt.Fprintf(w, "record %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	recentLogs, w = w, recentLogs
+	defer func() { recentLogs = w }()
+
+	records := RecentLogs()
+	if len(records) != recentLogsCapacity {
+		t.Fatalf("expected %d records, got %d", recentLogsCapacity, len(records))
+	}
+	if records[0] != "record 2" || records[len(records)-1] != fmt.Sprintf("record %d", recentLogsCapacity+1) {
+		t.Errorf("expected the oldest records to be dropped, got %q ... %q", records[0], records[len(records)-1])
+	}
+}
@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+)
+
+// CORRELATION_ID_LOG_KEY is the key of the correlation id logged by each step of a flow, such as funding a channel or
+// making a payment, from the rpc request which started it to its handling by the peers the flow involves.
+const CORRELATION_ID_LOG_KEY = "correlation-id"
+
+type correlationIdKey struct{}
+
+// NewCorrelationId returns a random correlation id.
+func NewCorrelationId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ContextWithCorrelationId returns a copy of ctx carrying the correlation id.
+func ContextWithCorrelationId(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, id)
+}
+
+// CorrelationId returns the correlation id carried by ctx, or the empty string if it carries none.
+func CorrelationId(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIdKey{}).(string)
+	return id
+}
+
+// WithCorrelationIdAttribute returns a logging attribute for the given correlation id
+func WithCorrelationIdAttribute(id string) slog.Attr {
+	return slog.String(CORRELATION_ID_LOG_KEY, id)
+}
+
+// LoggerWithCorrelationId returns a logger with the correlation id attribute set, or logger itself if id is empty.
+func LoggerWithCorrelationId(logger *slog.Logger, id string) *slog.Logger {
+	if id == "" {
+		return logger
+	}
+	return logger.With(WithCorrelationIdAttribute(id))
+}
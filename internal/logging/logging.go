@@ -66,8 +66,9 @@ func SetupDefaultFileLogger(filename string, level slog.Level) {
 
 // SetupDefaultLogger sets up a default logger that writes to the specified writer
 func SetupDefaultLogger(w io.Writer, level slog.Level) {
-	h := tint.NewHandler(w, &tint.Options{
-		Level:      level,
+	rootLevel.Set(level)
+	h := tint.NewHandler(io.MultiWriter(w, recentLogs), &tint.Options{
+		Level:      rootLevel,
 		TimeFormat: time.Kitchen,
 	})
 	slog.SetDefault(slog.New(h))
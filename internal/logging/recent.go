@@ -0,0 +1,43 @@
+package logging
+
+import (
+	"strings"
+	"sync"
+)
+
+// recentLogsCapacity is the number of log records kept in memory for diagnostic bundles.
+const recentLogsCapacity = 1000
+
+// recentLogWriter keeps the most recent log records written to it in a ring buffer.
+type recentLogWriter struct {
+	mu      sync.Mutex
+	records []string
+	next    int
+}
+
+var recentLogs = &recentLogWriter{}
+
+func (w *recentLogWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	record := strings.TrimRight(string(p), "\n")
+	if len(w.records) < recentLogsCapacity {
+		w.records = append(w.records, record)
+	} else {
+		w.records[w.next] = record
+	}
+	w.next = (w.next + 1) % recentLogsCapacity
+	return len(p), nil
+}
+
+// RecentLogs returns the most recent log records written by the default logger, oldest first.
+func RecentLogs() []string {
+	recentLogs.mu.Lock()
+	defer recentLogs.mu.Unlock()
+
+	if len(recentLogs.records) < recentLogsCapacity {
+		return append([]string{}, recentLogs.records...)
+	}
+	return append(append([]string{}, recentLogs.records[recentLogs.next:]...), recentLogs.records[:recentLogs.next]...)
+}
@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rotatingWriter is an io.Writer which writes to a file, rotating it once it reaches maxSize bytes.
+// Rotated files are named <file>.1 (the most recent) through <file>.<maxBackups>.
+type rotatingWriter struct {
+	mu         sync.Mutex
+	filename   string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// newRotatingWriter opens (or creates) filename for appending. A maxSize of zero disables rotation.
+func newRotatingWriter(filename string, maxSize int64, maxBackups int) (*rotatingWriter, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	w := &rotatingWriter{filename: filename, maxSize: maxSize, maxBackups: maxBackups}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *rotatingWriter) open() error {
+	f, err := os.OpenFile(w.filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o666)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	return nil
+}
+
+func (w *rotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.maxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// rotate closes the current file, shifts existing backups along by one and opens a fresh file.
+func (w *rotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+
+	if w.maxBackups <= 0 {
+		if err := os.Remove(w.filename); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return w.open()
+	}
+
+	os.Remove(backupName(w.filename, w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(backupName(w.filename, i), backupName(w.filename, i+1))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := os.Rename(w.filename, backupName(w.filename, 1)); err != nil {
+		return err
+	}
+	return w.open()
+}
+
+func backupName(filename string, i int) string {
+	return fmt.Sprintf("%s.%d", filename, i)
+}
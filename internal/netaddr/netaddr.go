@@ -0,0 +1,79 @@
+// Package netaddr configures which IP versions the message service and the rpc transports listen on.
+package netaddr
+
+import (
+	"fmt"
+	"net"
+)
+
+// Family is the IP versions a service listens on, and which it prefers when it can use either.
+type Family string
+
+const (
+	DualStack     Family = "dual"      // IPv4 and IPv6, preferring IPv4. It is the default.
+	DualStackIPv6 Family = "dual-ipv6" // IPv4 and IPv6, preferring IPv6
+	IPv4          Family = "ipv4"      // IPv4 only
+	IPv6          Family = "ipv6"      // IPv6 only
+)
+
+// ParseFamily returns the family named by s. The empty string is DualStack.
+func ParseFamily(s string) (Family, error) {
+	switch f := Family(s); f {
+	case "":
+		return DualStack, nil
+	case DualStack, DualStackIPv6, IPv4, IPv6:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown address family %q: it must be one of %s, %s, %s or %s", s, DualStack, DualStackIPv6, IPv4, IPv6)
+	}
+}
+
+// Network returns the network to pass to net.Listen to listen on the family's IP versions.
+func (f Family) Network() string {
+	switch f {
+	case IPv4:
+		return "tcp4"
+	case IPv6:
+		return "tcp6"
+	default:
+		return "tcp"
+	}
+}
+
+// HasIPv4 returns whether the family includes IPv4.
+func (f Family) HasIPv4() bool {
+	return f != IPv6
+}
+
+// HasIPv6 returns whether the family includes IPv6.
+func (f Family) HasIPv6() bool {
+	return f != IPv4
+}
+
+// PrefersIPv6 returns whether IPv6 addresses are preferred to IPv4 addresses.
+func (f Family) PrefersIPv6() bool {
+	return f == IPv6 || f == DualStackIPv6
+}
+
+// Includes returns whether ip is of one of the family's IP versions.
+func (f Family) Includes(ip net.IP) bool {
+	if ip.To4() != nil {
+		return f.HasIPv4()
+	}
+	return ip.To16() != nil && f.HasIPv6()
+}
+
+// Loopback returns the loopback address of the preferred IP version, to reach a service of the family listening on
+// every interface.
+func (f Family) Loopback() net.IP {
+	if f.PrefersIPv6() {
+		return net.IPv6loopback
+	}
+	return net.IPv4(127, 0, 0, 1)
+}
+
+// LoopbackAddress returns the host:port at which a service of the family listening on port on every interface can be
+// reached from the same machine. IPv6 hosts are bracketed, as urls require.
+func (f Family) LoopbackAddress(port string) string {
+	return net.JoinHostPort(f.Loopback().String(), port)
+}
@@ -0,0 +1,55 @@
+package netaddr
+
+import (
+	"net"
+	"testing"
+)
+
+func TestParseFamily(t *testing.T) {
+	for s, want := range map[string]Family{"": DualStack, "dual": DualStack, "dual-ipv6": DualStackIPv6, "ipv4": IPv4, "ipv6": IPv6} {
+		got, err := ParseFamily(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("expected %q to parse as %s, got %s", s, want, got)
+		}
+	}
+	if _, err := ParseFamily("ipv5"); err == nil {
+		t.Error("expected an unknown family to be rejected")
+	}
+}
+
+func TestFamily(t *testing.T) {
+	ip4 := net.ParseIP("203.0.113.7")
+	ip6 := net.ParseIP("2001:db8::7")
+
+	testCases := []struct {
+		family          Family
+		network         string
+		includes4       bool
+		includes6       bool
+		loopbackAddress string
+	}{
+		{DualStack, "tcp", true, true, "127.0.0.1:4005"},
+		{DualStackIPv6, "tcp", true, true, "[::1]:4005"},
+		{IPv4, "tcp4", true, false, "127.0.0.1:4005"},
+		{IPv6, "tcp6", false, true, "[::1]:4005"},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.family), func(t *testing.T) {
+			if got := tc.family.Network(); got != tc.network {
+				t.Errorf("expected network %s, got %s", tc.network, got)
+			}
+			if got := tc.family.Includes(ip4); got != tc.includes4 {
+				t.Errorf("expected Includes(%s) to be %t", ip4, tc.includes4)
+			}
+			if got := tc.family.Includes(ip6); got != tc.includes6 {
+				t.Errorf("expected Includes(%s) to be %t", ip6, tc.includes6)
+			}
+			if got := tc.family.LoopbackAddress("4005"); got != tc.loopbackAddress {
+				t.Errorf("expected loopback address %s, got %s", tc.loopbackAddress, got)
+			}
+		})
+	}
+}
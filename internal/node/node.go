@@ -3,16 +3,20 @@ package node
 import (
 	"fmt"
 	"log/slog"
+	"math/big"
 
 	"github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	"github.com/statechannels/go-nitro/node/engine/store"
 
 	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
 )
 
-func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts, messageOpts p2pms.MessageOpts) (*node.Node, *store.Store, *p2pms.P2PMessageService, chainservice.ChainService, error) {
+// InitializeNode constructs a node and its subsystems. If faultInjection is set, faults may be injected into the node's
+// messages (see node.SetMessageFaults), for testing. Further options, such as node.WithEventLog, are passed to the node.
+func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts, messageOpts p2pms.MessageOpts, policymaker engine.PolicyMaker, faultInjection bool, opts ...node.Option) (*node.Node, *store.Store, *p2pms.P2PMessageService, chainservice.ChainService, error) {
 	ourStore, err := store.NewStore(storeOpts)
 	if err != nil {
 		return nil, nil, nil, nil, err
@@ -21,6 +25,19 @@ func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts,
 	slog.Info("Initializing message service on port " + fmt.Sprint(messageOpts.Port) + "...")
 	messageOpts.SCAddr = *ourStore.GetAddress()
 	messageService := p2pms.NewMessageService(messageOpts)
+	var ms messageservice.MessageService = messageService
+	if faultInjection {
+		slog.Warn("Message fault injection is enabled")
+		ms = messageservice.NewFaultyMessageService(messageService)
+	}
+
+	if chainOpts.VirtualOnly {
+		slog.Info("Initializing virtual-only chain service...")
+		ourChain := chainservice.NewVirtualOnlyChainService(new(big.Int).SetUint64(chainOpts.ChainId), chainOpts.CaAddress, chainOpts.VpaAddress)
+		opts = append(opts, node.WithMessageService(ms), node.WithChainService(ourChain), node.WithStore(ourStore), node.WithPolicy(policymaker))
+		node := node.New(opts...)
+		return &node, &ourStore, messageService, ourChain, nil
+	}
 
 	// Compare chainOpts.ChainStartBlock to lastBlockNum seen in store. The larger of the two
 	// gets passed as an argument when creating NewEthChainService
@@ -38,12 +55,13 @@ func InitializeNode(chainOpts chainservice.ChainOpts, storeOpts store.StoreOpts,
 		return nil, nil, nil, nil, err
 	}
 
-	node := node.New(
-		messageService,
-		ourChain,
-		ourStore,
-		&engine.PermissivePolicy{},
+	opts = append(opts,
+		node.WithMessageService(ms),
+		node.WithChainService(ourChain),
+		node.WithStore(ourStore),
+		node.WithPolicy(policymaker),
 	)
+	node := node.New(opts...)
 
 	return &node, &ourStore, messageService, ourChain, nil
 }
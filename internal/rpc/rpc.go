@@ -5,29 +5,43 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/statechannels/go-nitro/internal/netaddr"
 	"github.com/statechannels/go-nitro/node"
 	"github.com/statechannels/go-nitro/rpc"
 	"github.com/statechannels/go-nitro/rpc/transport"
+	grpcTransport "github.com/statechannels/go-nitro/rpc/transport/grpc"
 	httpTransport "github.com/statechannels/go-nitro/rpc/transport/http"
 	"github.com/statechannels/go-nitro/rpc/transport/nats"
 )
 
-func InitializeRpcServer(node *node.Node, rpcPort int, useNats bool, cert *tls.Certificate) (*rpc.RpcServer, error) {
-	var transport transport.Responder
+// InitializeRpcServer starts an rpc server for the node, over the given type of transport, listening on the IP versions
+// of the family. Http and gRPC transports serve TLS with tlsConfig, unless it is nil. If accessLog is not nil, requests
+// are access logged as it describes.
+func InitializeRpcServer(node *node.Node, rpcPort int, family netaddr.Family, transportType transport.TransportType, tlsConfig *tls.Config, accessLog *rpc.AccessLogConfig, opts ...rpc.ServerOption) (*rpc.RpcServer, error) {
+	var responder transport.Responder
 	var err error
 
-	if useNats {
+	switch transportType {
+	case transport.Nats:
 		slog.Info("Initializing NATS RPC transport...")
-		transport, err = nats.NewNatsTransportAsServer(rpcPort)
-	} else {
+		responder, err = nats.NewNatsTransportAsServerWithFamily(rpcPort, family)
+	case transport.Http:
 		slog.Info("Initializing Http RPC transport...")
-		transport, err = httpTransport.NewHttpTransportAsServer(fmt.Sprint(rpcPort), cert)
+		responder, err = httpTransport.NewHttpTransportAsServerWithTLSConfig(fmt.Sprint(rpcPort), tlsConfig, family)
+	case transport.Grpc:
+		slog.Info("Initializing gRPC RPC transport...")
+		responder, err = grpcTransport.NewGrpcTransportAsServerWithTLSConfig(fmt.Sprint(rpcPort), tlsConfig, family)
+	default:
+		err = fmt.Errorf("unknown transport type %s", transportType)
 	}
 	if err != nil {
 		return nil, err
 	}
+	if accessLog != nil {
+		responder = rpc.NewAccessLogResponder(responder, *accessLog)
+	}
 
-	rpcServer, err := rpc.NewRpcServer(node, transport)
+	rpcServer, err := rpc.NewRpcServer(node, responder, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -142,6 +142,7 @@ func createTestLedger(leader, follower testactors.Actor) TestLedger {
 		0,
 		*outcome,
 		sigs,
+		nil,
 	)
 	if err != nil {
 		panic(fmt.Sprintf("error creating leader channel in testLedger: %v", err))
@@ -151,6 +152,7 @@ func createTestLedger(leader, follower testactors.Actor) TestLedger {
 		0,
 		*outcome,
 		sigs,
+		nil,
 	)
 	if err != nil {
 		panic(fmt.Sprintf("error creating follwer channel in testLedger: %v", err))
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+func TestDebugConfigRedactsSecrets(t *testing.T) {
+	secrets := []string{
+		PK, CHAIN_PK, CHAIN_URL, CHAIN_AUTH_TOKEN, SIGNER_URL, SIGNER_TOKEN, MSG_PK, POSTGRES_DSN, STORE_PASSPHRASE,
+		STORE_KEY_COMMAND, PRICE_FEED_URL, ALERT_WEBHOOK_URL, ALERT_PAGERDUTY_KEY, RPC_API_KEYS, RPC_TOKEN_SECRET,
+		FAUCET_URL, REPLICATION_SECRET,
+	}
+	// A flag added without being considered for publicFlags is treated as secret
+	const unlisted = "someflagaddedlater"
+
+	flags := []cli.Flag{}
+	args := []string{"go-nitro"}
+	for _, name := range append(secrets, unlisted, LOG_LEVEL) {
+		flags = append(flags, &cli.StringFlag{Name: name})
+		args = append(args, "--"+name, "secret")
+	}
+
+	var config map[string]string
+	app := &cli.App{Flags: flags, Action: func(cCtx *cli.Context) error {
+		config = debugConfig(cCtx)
+		return nil
+	}}
+	if err := app.Run(args); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range append(secrets, unlisted) {
+		if config[name] != "<redacted>" {
+			t.Errorf("expected %s to be redacted, got %q", name, config[name])
+		}
+	}
+	if config[LOG_LEVEL] != "secret" {
+		t.Errorf("expected %s to be included, got %q", LOG_LEVEL, config[LOG_LEVEL])
+	}
+}
@@ -0,0 +1,242 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/alerts"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// DEFAULT_ALERT_CHECK_INTERVAL is how often the node checks for abnormal conditions, when alerts are enabled, by default.
+const DEFAULT_ALERT_CHECK_INTERVAL = 30 * time.Second
+
+// AlertOptions configure EnableAlerts.
+type AlertOptions struct {
+	// Sinks deliver the alerts
+	Sinks []alerts.Sink
+	// RepeatInterval is how long repeats of an alert for the same condition are suppressed for. It defaults to
+	// alerts.DEFAULT_REPEAT_INTERVAL.
+	RepeatInterval time.Duration
+	// CheckInterval is how often the node checks for conditions which are not reported as they happen, such as stuck
+	// objectives. It defaults to DEFAULT_ALERT_CHECK_INTERVAL.
+	CheckInterval time.Duration
+	// StuckObjectiveThreshold is how long an objective may run before it is alerted as stuck. Zero disables the alert.
+	StuckObjectiveThreshold time.Duration
+	// MaxLedgerExposure is the most of an asset which may be locked in the guarantees of a ledger channel before it is
+	// alerted. Nil disables the alert.
+	MaxLedgerExposure *big.Int
+}
+
+// alerting raises alerts about abnormal conditions, once EnableAlerts has been called.
+type alerting struct {
+	mu      sync.Mutex
+	alerter *alerts.Alerter // nil until alerts are enabled
+	opts    AlertOptions
+	// running holds when each objective running at the last check was first seen running
+	running map[protocols.ObjectiveId]time.Time
+	chain   chainservice.ConnectionMonitor // nil unless the chain service reports its connection
+	logger  *slog.Logger
+}
+
+// raise raises the alert, if alerts are enabled, logging any failure to deliver it.
+func (al *alerting) raise(ctx context.Context, a alerts.Alert) {
+	al.mu.Lock()
+	alerter := al.alerter
+	al.mu.Unlock()
+	if alerter == nil {
+		return
+	}
+	if _, err := alerter.Raise(ctx, a); err != nil {
+		al.logger.Error("Could not deliver alert", "kind", a.Kind, "subject", a.Subject, "error", err)
+	}
+}
+
+// resolve forgets that the condition was alerted, so that it is alerted again as soon as it recurs.
+func (al *alerting) resolve(kind alerts.Kind, subject string) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	if al.alerter != nil {
+		al.alerter.Resolve(kind, subject)
+	}
+}
+
+// storeWriteFailed raises an alert for a failed store mutation. It is raised synchronously, since the engine stops
+// when it cannot write to the store.
+func (al *alerting) storeWriteFailed(op store.MutationOp, err error) {
+	al.raise(context.Background(), alerts.Alert{
+		Kind:     alerts.StoreWriteFailed,
+		Severity: alerts.Critical,
+		Summary:  fmt.Sprintf("Could not write to the store (%s): %v", op, err),
+		Subject:  string(op),
+	})
+}
+
+// reportStoreErrors returns a store which makes its mutations to s, alerting those which fail.
+func reportStoreErrors(s store.Store, al *alerting) store.Store {
+	return store.NewErrorReportingStore(s, al.storeWriteFailed)
+}
+
+// EnableAlerts raises alerts to the sinks when a counterparty challenges one of our channels, or the store cannot be
+// written to, and checks periodically for objectives which are stuck, a lost connection to the chain, and ledger
+// channels whose guarantees lock more than the limit.
+func (n *Node) EnableAlerts(opts AlertOptions) {
+	if opts.CheckInterval == 0 {
+		opts.CheckInterval = DEFAULT_ALERT_CHECK_INTERVAL
+	}
+	al := n.alerting
+	al.mu.Lock()
+	al.alerter = alerts.NewAlerter(opts.RepeatInterval, opts.Sinks...)
+	al.opts = opts
+	al.running = map[protocols.ObjectiveId]time.Time{}
+	al.mu.Unlock()
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ticker := time.NewTicker(opts.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				n.checkAlerts(now)
+			case <-n.stopBackgroundTasks:
+				return
+			}
+		}
+	}()
+}
+
+// alertChallenges raises alerts for the channels which a counterparty has challenged, without blocking the caller.
+func (n *Node) alertChallenges(ids []types.Destination) {
+	if len(ids) == 0 {
+		return
+	}
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		for _, id := range ids {
+			n.alerting.raise(context.Background(), alerts.Alert{
+				Kind:     alerts.ChallengeDetected,
+				Severity: alerts.Critical,
+				Summary:  "A counterparty has challenged channel " + id.String() + " on chain",
+				Subject:  id.String(),
+			})
+		}
+	}()
+}
+
+// checkAlerts checks for the conditions which are not reported as they happen.
+func (n *Node) checkAlerts(now time.Time) {
+	al := n.alerting
+	ctx := context.Background()
+
+	if al.chain != nil {
+		if al.chain.Connected() {
+			al.resolve(alerts.ChainDisconnected, "")
+		} else {
+			al.raise(ctx, alerts.Alert{Kind: alerts.ChainDisconnected, Severity: alerts.Critical, Summary: "The chain service has lost its connection to the chain"})
+		}
+	}
+
+	if al.opts.StuckObjectiveThreshold > 0 {
+		stuck, err := n.stuckObjectives(now)
+		if err != nil {
+			n.logger.Error("Could not check for stuck objectives", "error", err)
+		}
+		for id, since := range stuck {
+			al.raise(ctx, alerts.Alert{
+				Kind:     alerts.ObjectiveStuck,
+				Severity: alerts.Warning,
+				Summary:  fmt.Sprintf("Objective %s has been running since %s", id, since.Format(time.RFC3339)),
+				Subject:  string(id),
+			})
+		}
+	}
+
+	if al.opts.MaxLedgerExposure != nil {
+		exposures, err := n.ledgerExposures()
+		if err != nil {
+			n.logger.Error("Could not check ledger exposure", "error", err)
+		}
+		for id, exposure := range exposures {
+			for asset, locked := range exposure {
+				if locked.Cmp(al.opts.MaxLedgerExposure) <= 0 {
+					continue
+				}
+				al.raise(ctx, alerts.Alert{
+					Kind:     alerts.LedgerExposure,
+					Severity: alerts.Warning,
+					Summary:  fmt.Sprintf("Ledger channel %s locks %s of asset %s in guarantees, over the limit of %s", id, locked, asset, al.opts.MaxLedgerExposure),
+					Subject:  id.String(),
+					Details:  map[string]string{"asset": asset.String(), "locked": locked.String()},
+				})
+			}
+		}
+	}
+}
+
+// stuckObjectives returns the approved objectives which have been seen running for longer than the threshold, with
+// when they were first seen running.
+func (n *Node) stuckObjectives(now time.Time) (map[protocols.ObjectiveId]time.Time, error) {
+	chs, err := n.store.GetChannelsByParticipant(*n.Address)
+	if err != nil {
+		return nil, err
+	}
+	al := n.alerting
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	running := map[protocols.ObjectiveId]time.Time{}
+	stuck := map[protocols.ObjectiveId]time.Time{}
+	for _, c := range chs {
+		o, ok := n.store.GetObjectiveByChannelId(c.Id)
+		if !ok || o.GetStatus() != protocols.Approved {
+			continue
+		}
+		since, seen := al.running[o.Id()]
+		if !seen {
+			since = now
+		}
+		running[o.Id()] = since
+		if now.Sub(since) > al.opts.StuckObjectiveThreshold {
+			stuck[o.Id()] = since
+		}
+	}
+	al.running = running
+	return stuck, nil
+}
+
+// ledgerExposures returns the amount of each asset locked in the guarantees of each ledger channel.
+func (n *Node) ledgerExposures() (map[types.Destination]types.Funds, error) {
+	ledgers, err := n.store.GetAllConsensusChannels()
+	if err != nil {
+		return nil, err
+	}
+	exposures := map[types.Destination]types.Funds{}
+	for _, ledger := range ledgers {
+		exposure := types.Funds{}
+		vars := ledger.ConsensusVars()
+		for _, exit := range vars.Outcome.AsOutcome() {
+			for _, a := range exit.Allocations {
+				if a.AllocationType != outcome.GuaranteeAllocationType {
+					continue
+				}
+				if exposure[exit.Asset] == nil {
+					exposure[exit.Asset] = big.NewInt(0)
+				}
+				exposure[exit.Asset].Add(exposure[exit.Asset], a.Amount)
+			}
+		}
+		exposures[ledger.Id] = exposure
+	}
+	return exposures, nil
+}
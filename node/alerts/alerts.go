@@ -0,0 +1,230 @@
+// Package alerts raises alerts about abnormal conditions of a node, such as a challenge registered against one of its
+// channels, to pluggable sinks: the log, a webhook, or a PagerDuty-compatible events api.
+package alerts // import "github.com/statechannels/go-nitro/node/alerts"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Kind identifies the condition an alert is raised for.
+type Kind string
+
+const (
+	ChallengeDetected Kind = "challenge_detected" // A counterparty registered a challenge against one of our channels
+	ObjectiveStuck    Kind = "objective_stuck"    // An objective has been running for longer than expected
+	ChainDisconnected Kind = "chain_disconnected" // The chain service lost its connection to the chain
+	StoreWriteFailed  Kind = "store_write_failed" // The store could not persist an update
+	LedgerExposure    Kind = "ledger_exposure"    // The funds locked in a ledger channel's guarantees exceed the limit
+)
+
+// Severity is how urgently an alert needs attention.
+type Severity string
+
+const (
+	Warning  Severity = "warning"
+	Critical Severity = "critical"
+)
+
+// Alert describes an abnormal condition.
+type Alert struct {
+	Kind     Kind      `json:"kind"`
+	Severity Severity  `json:"severity"`
+	Summary  string    `json:"summary"`
+	Subject  string    `json:"subject,omitempty"` // What the alert is about, such as a channel or objective id
+	Details  any       `json:"details,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// key identifies the condition the alert reports, so that repeats of it can be suppressed.
+func (a Alert) key() string {
+	return string(a.Kind) + ":" + a.Subject
+}
+
+// Sink delivers alerts.
+type Sink interface {
+	Send(ctx context.Context, a Alert) error
+}
+
+// DEFAULT_SEND_TIMEOUT is how long an Alerter waits for a sink to deliver an alert.
+const DEFAULT_SEND_TIMEOUT = 10 * time.Second
+
+// DEFAULT_REPEAT_INTERVAL is how long an Alerter suppresses repeats of an alert for, by default.
+const DEFAULT_REPEAT_INTERVAL = time.Hour
+
+// Alerter raises alerts to each of its sinks. Repeats of an alert for the same condition are suppressed for the
+// repeat interval, so that a condition which persists does not flood the sinks.
+type Alerter struct {
+	sinks          []Sink
+	repeatInterval time.Duration
+
+	mu     sync.Mutex
+	raised map[string]time.Time
+}
+
+// NewAlerter returns an Alerter raising alerts to the sinks. A repeatInterval of zero uses DEFAULT_REPEAT_INTERVAL.
+func NewAlerter(repeatInterval time.Duration, sinks ...Sink) *Alerter {
+	if repeatInterval == 0 {
+		repeatInterval = DEFAULT_REPEAT_INTERVAL
+	}
+	return &Alerter{sinks: sinks, repeatInterval: repeatInterval, raised: map[string]time.Time{}}
+}
+
+// Raise sends the alert to each sink concurrently, waiting for them to deliver it. It returns false, without sending
+// the alert, if the same condition was alerted within the repeat interval.
+func (al *Alerter) Raise(ctx context.Context, a Alert) (bool, error) {
+	if a.Time.IsZero() {
+		a.Time = time.Now()
+	}
+	al.mu.Lock()
+	if last, ok := al.raised[a.key()]; ok && a.Time.Sub(last) < al.repeatInterval {
+		al.mu.Unlock()
+		return false, nil
+	}
+	al.raised[a.key()] = a.Time
+	al.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, DEFAULT_SEND_TIMEOUT)
+	defer cancel()
+	errs := make([]error, len(al.sinks))
+	wg := sync.WaitGroup{}
+	for i, sink := range al.sinks {
+		wg.Add(1)
+		go func(i int, sink Sink) {
+			defer wg.Done()
+			errs[i] = sink.Send(ctx, a)
+		}(i, sink)
+	}
+	wg.Wait()
+	return true, errors.Join(errs...)
+}
+
+// Resolve forgets that the condition of the kind was alerted for the subject, so that it is alerted again as soon as
+// it recurs.
+func (al *Alerter) Resolve(kind Kind, subject string) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+	delete(al.raised, Alert{Kind: kind, Subject: subject}.key())
+}
+
+// LogSink writes alerts to a logger: critical alerts at error level, others at warn level.
+type LogSink struct {
+	Logger *slog.Logger // Defaults to slog.Default()
+}
+
+// Send logs the alert.
+func (ls LogSink) Send(ctx context.Context, a Alert) error {
+	logger := ls.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	level := slog.LevelWarn
+	if a.Severity == Critical {
+		level = slog.LevelError
+	}
+	logger.Log(ctx, level, "Alert: "+a.Summary, "kind", a.Kind, "subject", a.Subject, "details", a.Details)
+	return nil
+}
+
+// WebhookSink delivers alerts with a POST of the alert, as json, to a url. Any 2xx response is taken to mean the
+// alert was delivered.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink returns a sink posting alerts to url.
+func NewWebhookSink(url string) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{}}
+}
+
+// Send posts the alert to the webhook.
+func (ws *WebhookSink) Send(ctx context.Context, a Alert) error {
+	return postJSON(ctx, ws.client, ws.url, a)
+}
+
+// DEFAULT_PAGERDUTY_URL is the url of the PagerDuty Events API v2.
+const DEFAULT_PAGERDUTY_URL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutySink triggers incidents through the PagerDuty Events API v2, or another events api compatible with it.
+// Alerts for the same condition share a dedup key, so that the api groups them into one incident.
+type PagerDutySink struct {
+	url        string
+	routingKey string
+	source     string
+	client     *http.Client
+}
+
+// NewPagerDutySink returns a sink triggering incidents with the routing key of a PagerDuty integration. Incidents are
+// attributed to source, such as the address of the node. An empty url uses DEFAULT_PAGERDUTY_URL.
+func NewPagerDutySink(url, routingKey, source string) *PagerDutySink {
+	if url == "" {
+		url = DEFAULT_PAGERDUTY_URL
+	}
+	return &PagerDutySink{url: url, routingKey: routingKey, source: source, client: &http.Client{}}
+}
+
+type pagerDutyPayload struct {
+	Summary       string    `json:"summary"`
+	Source        string    `json:"source"`
+	Severity      Severity  `json:"severity"`
+	Timestamp     time.Time `json:"timestamp"`
+	Class         Kind      `json:"class"`
+	CustomDetails any       `json:"custom_details,omitempty"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+// Send triggers an incident for the alert.
+func (ps *PagerDutySink) Send(ctx context.Context, a Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey:  ps.routingKey,
+		EventAction: "trigger",
+		DedupKey:    ps.source + ":" + a.key(),
+		Payload: pagerDutyPayload{
+			Summary:       a.Summary,
+			Source:        ps.source,
+			Severity:      a.Severity,
+			Timestamp:     a.Time,
+			Class:         a.Kind,
+			CustomDetails: a.Details,
+		},
+	}
+	return postJSON(ctx, ps.client, ps.url, event)
+}
+
+// postJSON posts body, as json, to url, and returns an error unless the response is 2xx.
+func postJSON(ctx context.Context, client *http.Client, url string, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("alert was refused (%s): %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (rs *recordingSink) Send(_ context.Context, a Alert) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.alerts = append(rs.alerts, a)
+	return nil
+}
+
+func TestAlerterSuppressesRepeats(t *testing.T) {
+	sink := &recordingSink{}
+	al := NewAlerter(time.Hour, sink)
+	a := Alert{Kind: ObjectiveStuck, Severity: Warning, Summary: "stuck", Subject: "DirectFunding-0x1"}
+
+	for i, want := range []bool{true, false} {
+		raised, err := al.Raise(context.Background(), a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if raised != want {
+			t.Errorf("raise %d: expected raised to be %v", i, want)
+		}
+	}
+	other := a
+	other.Subject = "DirectFunding-0x2"
+	if raised, _ := al.Raise(context.Background(), other); !raised {
+		t.Error("expected an alert for another subject to be raised")
+	}
+	al.Resolve(a.Kind, a.Subject)
+	if raised, _ := al.Raise(context.Background(), a); !raised {
+		t.Error("expected a resolved alert to be raised again")
+	}
+	if len(sink.alerts) != 3 {
+		t.Errorf("expected 3 alerts to be sent, got %d", len(sink.alerts))
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	a := Alert{Kind: ChallengeDetected, Severity: Critical, Summary: "challenged", Subject: "0x1", Time: time.Now().UTC()}
+	if err := NewWebhookSink(server.URL).Send(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if received.Kind != a.Kind || received.Subject != a.Subject || !received.Time.Equal(a.Time) {
+		t.Errorf("expected %+v to be posted, got %+v", a, received)
+	}
+}
+
+func TestPagerDutySink(t *testing.T) {
+	var received pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		if received.RoutingKey != "key" {
+			http.Error(w, "invalid routing key", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	a := Alert{Kind: ChainDisconnected, Severity: Critical, Summary: "disconnected", Time: time.Now()}
+	if err := NewPagerDutySink(server.URL, "key", "node").Send(context.Background(), a); err != nil {
+		t.Fatal(err)
+	}
+	if received.EventAction != "trigger" || received.Payload.Summary != a.Summary || received.Payload.Severity != Critical {
+		t.Errorf("unexpected event %+v", received)
+	}
+	if received.DedupKey != "node:chain_disconnected:" {
+		t.Errorf("unexpected dedup key %s", received.DedupKey)
+	}
+	if err := NewPagerDutySink(server.URL, "wrong", "node").Send(context.Background(), a); err == nil {
+		t.Error("expected the refusal of the event to be reported")
+	}
+}
@@ -0,0 +1,36 @@
+package node
+
+import (
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// BlockPeer blocks the peer by its address or, if peerId is not empty, by the peer id its message service knows it by
+// (such as a libp2p peer id). Messages from a blocked peer are dropped, and channels with it are refused, until it is
+// unblocked. The blocklist is kept in the node's store, so that it outlasts a restart.
+func (n *Node) BlockPeer(address types.Address, peerId string, reason string) (store.BlockedPeer, error) {
+	blocked, err := n.engine.Blocklist().Block(address, peerId, reason)
+	if err != nil {
+		return store.BlockedPeer{}, err
+	}
+	n.logger.Info("Blocked peer", "peer", blocked.Key(), "reason", reason)
+	return blocked, nil
+}
+
+// UnblockPeer unblocks the peer blocked by its address or, if peerId is not empty, by its peer id. It returns false if
+// the peer was not blocked.
+func (n *Node) UnblockPeer(address types.Address, peerId string) (bool, error) {
+	unblocked, err := n.engine.Blocklist().Unblock(address, peerId)
+	if err != nil {
+		return false, err
+	}
+	if unblocked {
+		n.logger.Info("Unblocked peer", "peer", store.BlockedPeer{Address: address, PeerId: peerId}.Key())
+	}
+	return unblocked, nil
+}
+
+// BlockedPeers returns the peers which have been blocked, in the order they were blocked.
+func (n *Node) BlockedPeers() []store.BlockedPeer {
+	return n.engine.Blocklist().Peers()
+}
@@ -0,0 +1,165 @@
+package node
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// capacityKey identifies an asset of a ledger channel.
+type capacityKey struct {
+	ledger types.Destination
+	asset  types.Address
+}
+
+// capacityEvents reports when our free capacity in a ledger channel crosses a threshold, once EnableCapacityEvents has
+// been called.
+type capacityEvents struct {
+	mu         sync.Mutex
+	thresholds []float64 // ascending; nil until capacity events are enabled
+	// crossed holds, for each asset of each ledger channel, how many thresholds our free capacity was at or above when
+	// last seen
+	crossed map[capacityKey]int
+	events  chan query.LedgerCapacityInfo
+}
+
+// EnableCapacityEvents reports, on CapacityEvents, when our free capacity in a ledger channel crosses one of the
+// thresholds, so that applications can top up or rebalance channels before payments through them start failing. Our
+// free capacity is the fraction of the channel's funds of an asset which we may commit to new payment channels: our
+// balance, which is not locked in guarantees. Each threshold is a fraction between 0 and 1, exclusive.
+//
+// Capacity is checked whenever a ledger channel is updated. Ledger channels whose capacity is already below a
+// threshold are reported as soon as capacity events are enabled.
+func (n *Node) EnableCapacityEvents(thresholds ...float64) error {
+	if len(thresholds) == 0 {
+		return fmt.Errorf("%w: no capacity thresholds", ErrInvalidConfig)
+	}
+	for _, t := range thresholds {
+		if t <= 0 || t >= 1 {
+			return fmt.Errorf("%w: capacity threshold %v is not between 0 and 1", ErrInvalidConfig, t)
+		}
+	}
+	thresholds = slices.Clone(thresholds)
+	slices.Sort(thresholds)
+
+	ce := n.capacityEvents
+	ce.mu.Lock()
+	ce.thresholds = slices.Compact(thresholds)
+	ce.crossed = map[capacityKey]int{}
+	ce.mu.Unlock()
+
+	ledgers, err := n.store.GetAllConsensusChannels()
+	if err != nil {
+		return err
+	}
+	for _, ledger := range ledgers {
+		n.checkCapacity(ledger.Id)
+	}
+	return nil
+}
+
+// CapacityEvents returns a chan that receives an event whenever our free capacity in a ledger channel crosses one of
+// the thresholds given to EnableCapacityEvents. Events are dropped if the chan is not drained. Not suitable for
+// multiple subscribers.
+func (n *Node) CapacityEvents() <-chan query.LedgerCapacityInfo {
+	return n.capacityEvents.events
+}
+
+// checkCapacity reports the thresholds which our free capacity in the ledger channel has crossed since it was last
+// checked.
+func (n *Node) checkCapacity(id types.Destination) {
+	ce := n.capacityEvents
+	ce.mu.Lock()
+	defer ce.mu.Unlock()
+	if ce.thresholds == nil {
+		return
+	}
+
+	ledger, err := n.store.GetConsensusChannelById(id)
+	if err != nil {
+		// The channel has been closed, or is not a ledger channel
+		for key := range ce.crossed {
+			if key.ledger == id {
+				delete(ce.crossed, key)
+			}
+		}
+		return
+	}
+
+	for asset, c := range ledgerCapacity(ledger, *n.Address) {
+		if c.total.Sign() == 0 {
+			continue
+		}
+		free, _ := new(big.Rat).SetFrac(c.free, c.total).Float64()
+		crossed := 0
+		for crossed < len(ce.thresholds) && free >= ce.thresholds[crossed] {
+			crossed++
+		}
+		key := capacityKey{id, asset}
+		last, seen := ce.crossed[key]
+		if !seen {
+			// A channel seen for the first time is reported only if it is below a threshold
+			last = len(ce.thresholds)
+		}
+		ce.crossed[key] = crossed
+
+		info := query.LedgerCapacityInfo{
+			ID:     id,
+			Asset:  asset,
+			Free:   (*hexutil.Big)(c.free),
+			Locked: (*hexutil.Big)(c.locked),
+			Total:  (*hexutil.Big)(c.total),
+		}
+		for i := crossed; i < last; i++ {
+			info.Threshold, info.Below = ce.thresholds[i], true
+			n.logger.Warn("Free capacity of ledger channel fell below threshold", "channel", id, "asset", asset, "free", free, "threshold", info.Threshold)
+			ce.notify(info)
+		}
+		for i := last; i < crossed; i++ {
+			info.Threshold, info.Below = ce.thresholds[i], false
+			n.logger.Info("Free capacity of ledger channel rose above threshold", "channel", id, "asset", asset, "free", free, "threshold", info.Threshold)
+			ce.notify(info)
+		}
+	}
+}
+
+// notify sends the event, dropping it if the chan is full.
+func (ce *capacityEvents) notify(info query.LedgerCapacityInfo) {
+	select {
+	case ce.events <- info:
+	default:
+	}
+}
+
+// capacity is how the funds of an asset of a ledger channel are split.
+type capacity struct {
+	free, locked, total *big.Int
+}
+
+// ledgerCapacity returns how the funds of each asset of the ledger channel are split between our balance, which is
+// free, and guarantees.
+func ledgerCapacity(ledger *consensus_channel.ConsensusChannel, me types.Address) map[types.Address]capacity {
+	capacities := map[types.Address]capacity{}
+	vars := ledger.ConsensusVars()
+	for _, exit := range vars.Outcome.AsOutcome() {
+		c := capacity{free: big.NewInt(0), locked: big.NewInt(0), total: big.NewInt(0)}
+		for _, a := range exit.Allocations {
+			c.total.Add(c.total, a.Amount)
+			switch {
+			case a.AllocationType == outcome.GuaranteeAllocationType:
+				c.locked.Add(c.locked, a.Amount)
+			case a.Destination == types.AddressToDestination(me):
+				c.free.Add(c.free, a.Amount)
+			}
+		}
+		capacities[exit.Asset] = c
+	}
+	return capacities
+}
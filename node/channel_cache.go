@@ -0,0 +1,164 @@
+package node
+
+import (
+	"container/list"
+	"maps"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ChannelCacheStats reports how well the payment channel cache has served lookups.
+type ChannelCacheStats struct {
+	Size    int // The most channels the cache holds. Zero means the cache is disabled.
+	Entries int
+	Hits    uint64
+	Misses  uint64
+	HitRate float64 // Hits as a fraction of all lookups, or zero if there have been none
+}
+
+// cachedChannel is the info of a payment channel held by the channelCache.
+type cachedChannel struct {
+	id      types.Destination
+	info    query.PaymentChannelInfo
+	expires time.Time
+}
+
+// channelCache holds the info of recently read payment channels, so that channels which are looked up frequently (by
+// the reverse proxy, say, which looks up a channel for each request it serves) are not read from the store and the
+// voucher manager each time. A channel's info is invalidated whenever the engine or the node updates the channel, and
+// expires after the ttl regardless.
+type channelCache struct {
+	mu      sync.Mutex
+	size    int // Zero disables the cache
+	ttl     time.Duration
+	entries map[types.Destination]*list.Element
+	lru     *list.List // Of *cachedChannel, most recently used first
+	// generation is incremented by every invalidation, so that info loaded while a channel was updated is not cached
+	generation uint64
+	hits       uint64
+	misses     uint64
+}
+
+func newChannelCache() *channelCache {
+	return &channelCache{entries: map[types.Destination]*list.Element{}, lru: list.New()}
+}
+
+// get returns the channel's info from the cache, or from load (caching it) if the cache does not hold it.
+func (c *channelCache) get(id types.Destination, load func() (query.PaymentChannelInfo, error)) (query.PaymentChannelInfo, error) {
+	c.mu.Lock()
+	if c.size == 0 {
+		c.mu.Unlock()
+		return load()
+	}
+	if e, ok := c.entries[id]; ok {
+		cached := e.Value.(*cachedChannel)
+		if time.Now().Before(cached.expires) {
+			c.lru.MoveToFront(e)
+			c.hits++
+			c.mu.Unlock()
+			return cloneChannelInfo(cached.info), nil
+		}
+		c.remove(e)
+	}
+	c.misses++
+	generation := c.generation
+	c.mu.Unlock()
+
+	info, err := load()
+	if err != nil {
+		return info, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.size > 0 && c.generation == generation {
+		c.entries[id] = c.lru.PushFront(&cachedChannel{id: id, info: cloneChannelInfo(info), expires: time.Now().Add(c.ttl)})
+		for c.lru.Len() > c.size {
+			c.remove(c.lru.Back())
+		}
+	}
+	return info, nil
+}
+
+// remove removes the entry. It must be called with c.mu held.
+func (c *channelCache) remove(e *list.Element) {
+	c.lru.Remove(e)
+	delete(c.entries, e.Value.(*cachedChannel).id)
+}
+
+// invalidate removes the channels' info from the cache.
+func (c *channelCache) invalidate(ids ...types.Destination) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, id := range ids {
+		if e, ok := c.entries[id]; ok {
+			c.remove(e)
+		}
+	}
+}
+
+// invalidateUpdated removes the info of the channels updated by the engine event from the cache.
+func (c *channelCache) invalidateUpdated(update engine.EngineEvent) {
+	ids := []types.Destination{}
+	for _, info := range update.PaymentChannelUpdates {
+		ids = append(ids, info.ID)
+	}
+	for _, voucher := range update.ReceivedVouchers {
+		ids = append(ids, voucher.ChannelId)
+	}
+	for _, o := range update.CompletedObjectives {
+		ids = append(ids, o.OwnsChannel())
+	}
+	if len(ids) > 0 {
+		c.invalidate(ids...)
+	}
+}
+
+func (c *channelCache) stats() ChannelCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := ChannelCacheStats{Size: c.size, Entries: c.lru.Len(), Hits: c.hits, Misses: c.misses}
+	if lookups := c.hits + c.misses; lookups > 0 {
+		stats.HitRate = float64(c.hits) / float64(lookups)
+	}
+	return stats
+}
+
+// cloneChannelInfo returns a copy of the info which shares no memory with it, so that cached info cannot be modified
+// by callers.
+func cloneChannelInfo(info query.PaymentChannelInfo) query.PaymentChannelInfo {
+	cloneBig := func(b *hexutil.Big) *hexutil.Big {
+		if b == nil {
+			return nil
+		}
+		return (*hexutil.Big)(new(big.Int).Set(b.ToInt()))
+	}
+	info.Balance.PaidSoFar = cloneBig(info.Balance.PaidSoFar)
+	info.Balance.RemainingFunds = cloneBig(info.Balance.RemainingFunds)
+	info.Tags = maps.Clone(info.Tags)
+	return info
+}
+
+// EnableChannelCache caches the info of up to size payment channels, for up to ttl each, so that GetPaymentChannel
+// need not read the store and the voucher manager for channels looked up frequently. Cached info is invalidated
+// whenever a channel is updated. A size of zero disables the cache.
+func (n *Node) EnableChannelCache(size int, ttl time.Duration) {
+	c := n.channelCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size, c.ttl = max(size, 0), ttl
+	c.entries = map[types.Destination]*list.Element{}
+	c.lru.Init()
+}
+
+// ChannelCacheStats reports how well the payment channel cache has served lookups since the node started.
+func (n *Node) ChannelCacheStats() ChannelCacheStats {
+	return n.channelCache.stats()
+}
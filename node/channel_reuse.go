@@ -0,0 +1,116 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// channelReuse serializes calls to GetOrCreatePaymentChannel, and remembers the payment channels they are creating, so
+// that concurrent calls for the same counterparty and asset reuse a channel rather than each creating one.
+type channelReuse struct {
+	mu      sync.Mutex
+	pending map[reuseKey]pendingChannel
+}
+
+type reuseKey struct {
+	counterparty types.Address
+	asset        types.Address
+}
+
+// pendingChannel is a payment channel which GetOrCreatePaymentChannel is creating.
+type pendingChannel struct {
+	response virtualfund.ObjectiveResponse
+	capacity *big.Int // the funds we will be able to pay through the channel
+}
+
+// FindPaymentChannel returns the open payment channel through which we pay counterparty in asset, with at least
+// minRemaining funds left to pay. If there are several, the one with the most remaining funds is returned.
+// ok is false if there is none.
+func (n *Node) FindPaymentChannel(counterparty, asset types.Address, minRemaining *big.Int) (info query.PaymentChannelInfo, ok bool, err error) {
+	channels, err := n.store.GetChannelsByAppDefinition(n.engine.GetVirtualPaymentAppAddress())
+	if err != nil {
+		return query.PaymentChannelInfo{}, false, err
+	}
+	for _, c := range channels {
+		if c.Participants[0] != *n.Address || c.Participants[len(c.Participants)-1] != counterparty {
+			continue
+		}
+		candidate, err := n.GetPaymentChannel(c.Id)
+		if err != nil {
+			return query.PaymentChannelInfo{}, false, err
+		}
+		remaining := candidate.Balance.RemainingFunds.ToInt()
+		if candidate.Status != query.Open || candidate.Balance.AssetAddress != asset || remaining.Cmp(minRemaining) < 0 {
+			continue
+		}
+		if ok {
+			// Prefer the most remaining funds, breaking ties by id so that the choice is deterministic
+			switch best := info.Balance.RemainingFunds.ToInt(); remaining.Cmp(best) {
+			case -1:
+				continue
+			case 0:
+				if bytes.Compare(candidate.ID[:], info.ID[:]) > 0 {
+					continue
+				}
+			}
+		}
+		info, ok = candidate, true
+	}
+	return info, ok, nil
+}
+
+// GetOrCreatePaymentChannel returns a payment channel through which we can pay CounterParty at least the amount which
+// Outcome allocates to us, in the asset of Outcome. An open payment channel with enough remaining funds is reused (see
+// FindPaymentChannel), as is one which an earlier call is still creating; otherwise a payment channel is created with
+// the given Intermediaries, ChallengeDuration and Outcome, as by CreatePaymentChannelContext.
+//
+// created reports whether the channel was created by this call. The response's Id is the objective creating the
+// channel, which should be waited on if it is not empty; it is empty if an open channel was reused.
+// Calls are serialized, so concurrent calls for the same counterparty and asset create at most one channel.
+func (n *Node) GetOrCreatePaymentChannel(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (response virtualfund.ObjectiveResponse, created bool, err error) {
+	if len(Outcome) != 1 {
+		return virtualfund.ObjectiveResponse{}, false, fmt.Errorf("a payment channel holds a single asset, but the outcome holds %d", len(Outcome))
+	}
+	asset := Outcome[0].Asset
+	capacity := Outcome[0].TotalAllocatedFor(types.AddressToDestination(*n.Address))
+
+	cr := n.channelReuse
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+
+	info, ok, err := n.FindPaymentChannel(CounterParty, asset, capacity)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, false, err
+	}
+	if ok {
+		return virtualfund.ObjectiveResponse{ChannelId: info.ID}, false, nil
+	}
+
+	key := reuseKey{CounterParty, asset}
+	if p, ok := cr.pending[key]; ok {
+		status, err := query.GetObjectiveStatus(p.response.Id, n.store)
+		finished := err == nil && (status == protocols.Completed || status == protocols.Rejected)
+		if !finished && p.capacity.Cmp(capacity) >= 0 {
+			return p.response, false, nil
+		}
+		if finished {
+			delete(cr.pending, key)
+		}
+	}
+
+	response, err = n.CreatePaymentChannelContext(ctx, Intermediaries, CounterParty, ChallengeDuration, Outcome)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, false, err
+	}
+	cr.pending[key] = pendingChannel{response, capacity}
+	return response, true, nil
+}
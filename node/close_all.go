@@ -0,0 +1,254 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// DefaultCloseAllTimeout is how long CloseAllChannels waits for each stage of cooperative closes, unless told otherwise.
+const DefaultCloseAllTimeout = time.Minute
+
+// CloseAllOptions configures CloseAllChannels.
+type CloseAllOptions struct {
+	// Timeout is how long to wait for the payment channels, and then the ledger channels, to close cooperatively.
+	// Zero means DefaultCloseAllTimeout.
+	Timeout time.Duration
+	// Challenge makes ledger channels which are not closed cooperatively within the Timeout, such as those with an
+	// unresponsive peer, be challenged on chain with their latest supported state.
+	Challenge bool
+}
+
+// windDown tracks the progress of CloseAllChannels.
+type windDown struct {
+	mu   sync.Mutex
+	info *query.CloseAllInfo // nil until CloseAllChannels is first called
+	// wasDraining records whether the node was draining before CloseAllChannels, so that it is left so once it finishes
+	wasDraining bool
+}
+
+// update applies change to the progress of the ith channel.
+func (wd *windDown) update(i int, change func(*query.ChannelCloseInfo)) {
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	change(&wd.info.Channels[i])
+}
+
+// snapshot returns a copy of the progress, which it is safe to read while the wind-down continues.
+// It must be called with wd.mu held.
+func (wd *windDown) snapshot() query.CloseAllInfo {
+	if wd.info == nil {
+		return query.CloseAllInfo{}
+	}
+	info := *wd.info
+	info.Channels = slices.Clone(info.Channels)
+	return info
+}
+
+// CloseAllChannels winds the node down in an emergency, by closing every payment and ledger channel which is open.
+// The payment channels are closed first, so that the ledger channels funding them are free to be closed once they
+// are. Channels are closed cooperatively, waiting up to the Timeout of opts for each stage; if opts.Challenge is set,
+// ledger channels which are not closed in time are challenged on chain instead. The node drains (see SetDraining) until
+// the wind-down finishes.
+//
+// The wind-down continues in the background. CloseAllChannels returns its initial progress, listing the channels to
+// be closed; CloseAllProgress reports how far it has got. ErrCloseAllInProgress is returned if a wind-down is already
+// under way.
+func (n *Node) CloseAllChannels(opts CloseAllOptions) (query.CloseAllInfo, error) {
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultCloseAllTimeout
+	}
+	wd := n.windDown
+	wd.mu.Lock()
+	defer wd.mu.Unlock()
+	if wd.info != nil && wd.info.Finished.IsZero() {
+		return query.CloseAllInfo{}, fmt.Errorf("%w: started at %s", ErrCloseAllInProgress, wd.info.Started)
+	}
+
+	channels, err := n.openChannels()
+	if err != nil {
+		return query.CloseAllInfo{}, err
+	}
+	wd.info = &query.CloseAllInfo{Started: time.Now(), Channels: channels}
+	wd.wasDraining = n.engine.Draining()
+	n.engine.SetDraining(true)
+	n.logger.Warn("Closing all channels", "channels", len(channels), "challenge", opts.Challenge)
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopBackgroundTasks:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		n.closeAll(ctx, opts)
+	}()
+	return wd.snapshot(), nil
+}
+
+// CloseAllProgress returns the progress of the latest CloseAllChannels, which is the zero value if it has not been called.
+func (n *Node) CloseAllProgress() query.CloseAllInfo {
+	n.windDown.mu.Lock()
+	defer n.windDown.mu.Unlock()
+	return n.windDown.snapshot()
+}
+
+// SetDraining marks the node as draining, or no longer draining. While it drains, exits (defunds, challenges and
+// withdrawals) are prioritized over funding if its policy's engine.ExitPriority says so: new channels are refused, with
+// ErrFundingDeferred, so that the node can wind down. CloseAllChannels drains the node while it runs.
+func (n *Node) SetDraining(draining bool) {
+	n.engine.SetDraining(draining)
+}
+
+// Draining returns true if the node is draining.
+func (n *Node) Draining() bool {
+	return n.engine.Draining()
+}
+
+// openChannels lists the open payment channels, followed by the open ledger channels, each pending a close.
+func (n *Node) openChannels() ([]query.ChannelCloseInfo, error) {
+	channels := []query.ChannelCloseInfo{}
+	paymentChannels, err := n.store.GetChannelsByAppDefinition(n.engine.GetVirtualPaymentAppAddress())
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range paymentChannels {
+		info, err := n.GetPaymentChannel(c.Id)
+		if err != nil {
+			return nil, err
+		}
+		if info.Status == query.Open {
+			channels = append(channels, query.ChannelCloseInfo{ID: c.Id, Status: query.ClosePending})
+		}
+	}
+
+	ledgers, err := n.GetAllLedgerChannels()
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range ledgers {
+		// A payment channel is listed among the ledger channels if they share an app definition, as they may in tests
+		isPaymentChannel := slices.ContainsFunc(channels, func(c query.ChannelCloseInfo) bool { return c.ID == l.ID })
+		if l.Status == query.Open && !isPaymentChannel {
+			channels = append(channels, query.ChannelCloseInfo{ID: l.ID, Ledger: true, Status: query.ClosePending})
+		}
+	}
+	return channels, nil
+}
+
+// closeAll closes the channels listed by CloseAllChannels: first the payment channels, then the ledger channels.
+func (n *Node) closeAll(ctx context.Context, opts CloseAllOptions) {
+	wd := n.windDown
+	wd.mu.Lock()
+	channels := slices.Clone(wd.info.Channels)
+	wd.mu.Unlock()
+
+	for _, ledgers := range []bool{false, true} {
+		stage := []int{}
+		for i, c := range channels {
+			if c.Ledger == ledgers {
+				stage = append(stage, i)
+			}
+		}
+		n.closeStage(ctx, stage, channels, opts)
+	}
+
+	wd.mu.Lock()
+	wd.info.Finished = time.Now()
+	n.engine.SetDraining(wd.wasDraining)
+	wd.mu.Unlock()
+	n.logger.Warn("Finished closing all channels")
+}
+
+// closeStage starts a cooperative close of each of the given channels, then waits up to opts.Timeout for them all to
+// complete. Ledger channels which fail to close are challenged, if opts.Challenge is set.
+func (n *Node) closeStage(ctx context.Context, stage []int, channels []query.ChannelCloseInfo, opts CloseAllOptions) {
+	started := map[int]protocols.ObjectiveId{}
+	for _, i := range stage {
+		c := channels[i]
+		var id protocols.ObjectiveId
+		var err error
+		if c.Ledger {
+			id, err = n.CloseLedgerChannelContext(ctx, c.ID)
+		} else {
+			id, err = n.ClosePaymentChannelContext(ctx, c.ID)
+		}
+		if err != nil {
+			n.closeFailed(ctx, i, c, err, opts)
+			continue
+		}
+		started[i] = id
+		n.windDown.update(i, func(info *query.ChannelCloseInfo) {
+			info.ObjectiveId = id
+			info.Status = query.CloseStarted
+		})
+	}
+
+	// The closes share a deadline, so they are waited on in turn
+	waitCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	for _, i := range stage {
+		id, ok := started[i]
+		if !ok {
+			continue
+		}
+		if err := n.WaitForObjective(waitCtx, id); err != nil {
+			n.closeFailed(ctx, i, channels[i], err, opts)
+			continue
+		}
+		n.windDown.update(i, func(info *query.ChannelCloseInfo) { info.Status = query.CloseComplete })
+	}
+}
+
+// closeFailed records that the ith channel could not be closed cooperatively, challenging it instead if it is a ledger
+// channel and opts.Challenge is set.
+func (n *Node) closeFailed(ctx context.Context, i int, c query.ChannelCloseInfo, err error, opts CloseAllOptions) {
+	n.logger.Error("Could not close channel cooperatively", "channel", c.ID, "error", err)
+	if c.Ledger && opts.Challenge {
+		challengeErr := n.ChallengeLedgerChannel(ctx, c.ID)
+		if challengeErr == nil {
+			n.windDown.update(i, func(info *query.ChannelCloseInfo) {
+				info.Status = query.CloseChallenged
+				info.Error = err.Error()
+			})
+			return
+		}
+		err = fmt.Errorf("%w, and could not challenge it: %w", err, challengeErr)
+	}
+	n.windDown.update(i, func(info *query.ChannelCloseInfo) {
+		info.Status = query.CloseFailed
+		info.Error = err.Error()
+	})
+}
+
+// ChallengeLedgerChannel registers a challenge for the ledger channel with the given id on chain, with its latest
+// supported state. It returns once the challenge transaction has been submitted.
+func (n *Node) ChallengeLedgerChannel(ctx context.Context, ledgerId types.Destination) error {
+	if !n.channelExists(ledgerId) {
+		return channelNotFound(ledgerId)
+	}
+	result := make(chan error, 1)
+	select {
+	case n.engine.ChallengeRequestsFromAPI <- engine.NewAPIRequest(ctx, engine.ChallengeRequest{LedgerId: ledgerId, Result: result}):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
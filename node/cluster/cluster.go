@@ -0,0 +1,158 @@
+// Package cluster runs go-nitro nodes as an active/standby pair, to keep a hub available when the active node's
+// process or host fails.
+//
+// The members of a cluster run with the same keys, so that whichever is active presents the same nitro address to its
+// peers. They either share a durable store, or each keeps its own, to which the active member replicates its store
+// mutations (see store.ReplicatingStore). Each RPC endpoint should be reached through an address (such as a floating IP, or a
+// load balancer with health checks) which moves to the active member. Only the member holding the leadership lease
+// opens the store and serves; the standby polls the lease, and takes over once it expires or is released.
+package cluster // import "github.com/statechannels/go-nitro/node/cluster"
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+)
+
+// Config configures a Member of a cluster.
+type Config struct {
+	// Lease is the leadership lease shared by the members
+	Lease Lease
+	// Holder identifies the member; it must be unique within the cluster
+	Holder string
+	// TTL is how long the lease lasts without being renewed: how long a standby waits to take over from a failed member
+	TTL time.Duration
+	// RenewInterval is how often the active member renews the lease, and the standby polls it. It defaults to a third of the TTL.
+	RenewInterval time.Duration
+}
+
+// Member is a member of a cluster, which is either active or standing by.
+type Member struct {
+	config Config
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	stop   chan struct{}
+	wg     sync.WaitGroup
+	active bool
+}
+
+// NewMember returns a Member configured by config.
+func NewMember(config Config) (*Member, error) {
+	if config.Lease == nil || config.Holder == "" {
+		return nil, errors.New("a cluster member requires a lease and a holder")
+	}
+	if config.TTL <= 0 {
+		return nil, errors.New("the lease ttl must be positive")
+	}
+	if config.RenewInterval == 0 {
+		config.RenewInterval = config.TTL / 3
+	}
+	if config.RenewInterval <= 0 || config.RenewInterval >= config.TTL {
+		return nil, errors.New("the renew interval must be positive, and shorter than the lease ttl")
+	}
+	return &Member{
+		config: config,
+		logger: logging.ModuleLogger(logging.CLUSTER_MODULE).With("holder", config.Holder),
+	}, nil
+}
+
+// AwaitLeadership blocks while the member stands by, until it acquires the lease or ctx is done. The lease is then
+// renewed in the background until Resign is called. The returned channel is closed if the lease is lost, after which
+// the member must stop serving, since another member may take over.
+func (m *Member) AwaitLeadership(ctx context.Context) (lost <-chan struct{}, err error) {
+	m.logger.Info("Standing by for the leadership lease")
+	for {
+		acquired, err := m.config.Lease.TryAcquire(m.config.Holder, m.config.TTL)
+		if err != nil && !errors.Is(err, ErrLeaseContended) {
+			m.logger.Warn("Could not poll the leadership lease", "error", err)
+		}
+		if acquired {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(m.config.RenewInterval):
+		}
+	}
+	m.logger.Info("Acquired the leadership lease: becoming active")
+
+	lostChan, stop := make(chan struct{}), make(chan struct{})
+	m.mu.Lock()
+	m.active = true
+	m.stop = stop
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go m.renew(stop, lostChan)
+	return lostChan, nil
+}
+
+// renew renews the lease until stop is closed, closing lost if it cannot be renewed before it expires.
+func (m *Member) renew(stop <-chan struct{}, lost chan<- struct{}) {
+	defer m.wg.Done()
+	renewed := time.Now()
+	ticker := time.NewTicker(m.config.RenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		acquired, err := m.config.Lease.TryAcquire(m.config.Holder, m.config.TTL)
+		switch {
+		case acquired:
+			renewed = time.Now()
+			continue
+		case err == nil:
+			m.logger.Error("The leadership lease was taken by another member")
+		case time.Since(renewed)+m.config.RenewInterval < m.config.TTL:
+			// Retry while the lease has time to run
+			m.logger.Warn("Could not renew the leadership lease", "error", err)
+			continue
+		default:
+			m.logger.Error("Could not renew the leadership lease before it expired", "error", err)
+		}
+
+		m.mu.Lock()
+		m.active = false
+		m.mu.Unlock()
+		close(lost)
+		return
+	}
+}
+
+// IsActive returns true if the member holds the leadership lease.
+func (m *Member) IsActive() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.active
+}
+
+// Resign stops renewing the lease and releases it, so that the standby can take over immediately.
+func (m *Member) Resign() error {
+	m.mu.Lock()
+	stop := m.stop
+	m.stop = nil
+	wasActive := m.active
+	m.active = false
+	m.mu.Unlock()
+
+	if stop == nil {
+		return nil
+	}
+	close(stop)
+	m.wg.Wait()
+	if !wasActive {
+		return nil
+	}
+	m.logger.Info("Releasing the leadership lease")
+	return m.config.Lease.Release(m.config.Holder)
+}
@@ -0,0 +1,176 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileLease(t *testing.T) {
+	lease := NewFileLease(filepath.Join(t.TempDir(), "leader.lease"))
+
+	acquired, err := lease.TryAcquire("a", time.Minute)
+	if err != nil || !acquired {
+		t.Fatalf("expected a to acquire the free lease, got %v, %v", acquired, err)
+	}
+	if acquired, _ := lease.TryAcquire("b", time.Minute); acquired {
+		t.Fatal("expected b not to acquire a lease held by a")
+	}
+	if acquired, _ := lease.TryAcquire("a", time.Millisecond); !acquired {
+		t.Fatal("expected a to renew its lease")
+	}
+	time.Sleep(5 * time.Millisecond)
+	if acquired, _ := lease.TryAcquire("b", time.Minute); !acquired {
+		t.Fatal("expected b to acquire the expired lease")
+	}
+	holder, err := lease.Holder()
+	if err != nil || holder != "b" {
+		t.Fatalf("expected b to hold the lease, got %q, %v", holder, err)
+	}
+
+	// Releasing a lease held by another member has no effect
+	if err := lease.Release("a"); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _ := lease.Holder(); holder != "b" {
+		t.Fatalf("expected b to hold the lease, got %q", holder)
+	}
+	if err := lease.Release("b"); err != nil {
+		t.Fatal(err)
+	}
+	if holder, _ := lease.Holder(); holder != "" {
+		t.Fatalf("expected the lease to be free, got %q", holder)
+	}
+}
+
+func TestFailover(t *testing.T) {
+	lease := NewFileLease(filepath.Join(t.TempDir(), "leader.lease"))
+	newMember := func(holder string) *Member {
+		m, err := NewMember(Config{Lease: lease, Holder: holder, TTL: 300 * time.Millisecond, RenewInterval: 50 * time.Millisecond})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m
+	}
+	active, standby := newMember("active"), newMember("standby")
+
+	if _, err := active.AwaitLeadership(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The standby waits while the active member renews its lease
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err := standby.AwaitLeadership(ctx); err == nil {
+		t.Fatal("expected the standby to wait while the lease is renewed")
+	}
+
+	// Once the active member resigns, the standby takes over
+	promoted := make(chan error)
+	go func() {
+		_, err := standby.AwaitLeadership(context.Background())
+		promoted <- err
+	}()
+	if err := active.Resign(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-promoted:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the standby to take over")
+	}
+	if !standby.IsActive() || active.IsActive() {
+		t.Fatal("expected only the standby to be active")
+	}
+
+	// A member whose lease is taken over stops being active
+	lost, err := active.AwaitLeadership(ctx)
+	if err == nil {
+		t.Fatal("expected the former member to stand by")
+	}
+	if lost != nil {
+		t.Fatal("expected no lease")
+	}
+	if err := standby.Resign(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLeaseLost(t *testing.T) {
+	lease := NewFileLease(filepath.Join(t.TempDir(), "leader.lease"))
+	m, err := NewMember(Config{Lease: lease, Holder: "a", TTL: 300 * time.Millisecond, RenewInterval: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost, err := m.AwaitLeadership(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := m.Resign(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Another member takes the lease, as though our renewals had stalled
+	token, err := lease.lock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = lease.write(token, leaseRecord{Holder: "b", Expires: time.Now().Add(time.Minute)})
+	lease.unlock(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("expected the lease to be lost")
+	}
+	if m.IsActive() {
+		t.Fatal("expected the member to be inactive")
+	}
+}
+
+// TestStaleLockTakeover checks that when several members find a stale lock file left by a crashed process, only one of
+// them takes the lease.
+func TestStaleLockTakeover(t *testing.T) {
+	lease := NewFileLease(filepath.Join(t.TempDir(), "leader.lease"))
+	if err := os.WriteFile(lease.path+".lock", []byte("crashed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	stale := time.Now().Add(-2 * lockStaleAfter)
+	if err := os.Chtimes(lease.path+".lock", stale, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	const members = 8
+	var acquired atomic.Int64
+	wg := sync.WaitGroup{}
+	for i := 0; i < members; i++ {
+		wg.Add(1)
+		go func(holder string) {
+			defer wg.Done()
+			ok, err := lease.TryAcquire(holder, time.Minute)
+			if err != nil && !errors.Is(err, ErrLeaseContended) {
+				t.Error(err)
+			}
+			if ok {
+				acquired.Add(1)
+			}
+		}(fmt.Sprint(i))
+	}
+	wg.Wait()
+	if acquired.Load() != 1 {
+		t.Fatalf("expected exactly one member to take the lease, got %d", acquired.Load())
+	}
+}
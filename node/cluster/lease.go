@@ -0,0 +1,247 @@
+package cluster
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Lease is a leadership lease, held by at most one member of a cluster at a time.
+type Lease interface {
+	// TryAcquire takes the lease for holder, or renews it if holder already holds it, until ttl from now. It returns
+	// false if another holder's lease has not yet expired.
+	TryAcquire(holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease, if holder holds it, so that a standby may take over without waiting for it to expire.
+	Release(holder string) error
+}
+
+// ErrLeaseContended is returned when the lease could not be checked because another member was updating it.
+var ErrLeaseContended = errors.New("lease is being updated by another member")
+
+// leaseRecord is the content of a FileLease.
+type leaseRecord struct {
+	Holder  string
+	Expires time.Time
+}
+
+// lockStaleAfter is how long a FileLease lock file may exist before it is presumed to have been left by a crashed process.
+const lockStaleAfter = 5 * time.Second
+
+// lockWait is how long a FileLease waits for another member to finish updating the lease before giving up.
+const lockWait = 100 * time.Millisecond
+
+// FileLease is a Lease recorded in a file, which should be on the storage shared by the members of the cluster (for
+// instance, alongside their durable store). Updates to the file are serialized with a lock file, so clocks of the
+// members should be roughly in sync, to within a small fraction of the lease's ttl. The lock file holds a token unique
+// to the update, and an update is only made while the lock file still holds its token, so that a member whose stale
+// lock was taken over does not overwrite the lease.
+type FileLease struct {
+	path string
+}
+
+// NewFileLease returns a FileLease recorded in the file at path.
+func NewFileLease(path string) *FileLease {
+	return &FileLease{path: path}
+}
+
+// TryAcquire takes or renews the lease for holder.
+func (fl *FileLease) TryAcquire(holder string, ttl time.Duration) (bool, error) {
+	token, err := fl.lock()
+	if err != nil {
+		return false, err
+	}
+	defer fl.unlock(token)
+
+	current, err := fl.read()
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	if current.Holder != "" && current.Holder != holder && now.Before(current.Expires) {
+		return false, nil
+	}
+	if err := fl.write(token, leaseRecord{Holder: holder, Expires: now.Add(ttl)}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release gives up the lease, if holder holds it.
+func (fl *FileLease) Release(holder string) error {
+	token, err := fl.lock()
+	if err != nil {
+		return err
+	}
+	defer fl.unlock(token)
+
+	current, err := fl.read()
+	if err != nil {
+		return err
+	}
+	if current.Holder != holder {
+		return nil
+	}
+	return fl.write(token, leaseRecord{})
+}
+
+// Holder returns the current holder of the lease, or "" if it is not held.
+func (fl *FileLease) Holder() (string, error) {
+	current, err := fl.read()
+	if err != nil {
+		return "", err
+	}
+	if time.Now().After(current.Expires) {
+		return "", nil
+	}
+	return current.Holder, nil
+}
+
+// lock creates the lock file, holding a new token which it returns, waiting up to lockWait for another member's lock to
+// be removed. It returns ErrLeaseContended if the lock file still exists and is not stale.
+func (fl *FileLease) lock() (string, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return "", err
+	}
+	deadline := time.Now().Add(lockWait)
+	for {
+		locked, err := fl.tryLock(token)
+		if err != nil || locked {
+			return token, err
+		}
+		if time.Now().After(deadline) {
+			return "", ErrLeaseContended
+		}
+		time.Sleep(lockWait / 20)
+	}
+}
+
+// tryLock creates the lock file holding token, returning false if it already exists and is not stale. A stale lock file
+// is taken over by moving it aside, which only one member can do, and checking that the file moved is the stale one
+// rather than a fresh lock created by another member taking it over first.
+func (fl *FileLease) tryLock(token string) (bool, error) {
+	lockPath := fl.path + ".lock"
+	f, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err == nil {
+		_, err = f.WriteString(token)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(lockPath)
+			return false, err
+		}
+		return true, nil
+	}
+	if !errors.Is(err, os.ErrExist) {
+		return false, err
+	}
+	stale, err := os.Stat(lockPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if time.Since(stale.ModTime()) < lockStaleAfter {
+		return false, nil
+	}
+
+	aside := lockPath + "." + token
+	if err := os.Rename(lockPath, aside); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	moved, err := os.Stat(aside)
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(stale, moved) || !moved.ModTime().Equal(stale.ModTime()) {
+		// Another member took the stale lock over first: put its lock back, unless yet another member has since locked
+		err := os.Link(aside, lockPath)
+		_ = os.Remove(aside)
+		if err != nil && !errors.Is(err, os.ErrExist) {
+			return false, err
+		}
+		return false, nil
+	}
+	if err := os.Remove(aside); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// holds returns nil if the lock file holds token, or ErrLeaseContended if another member has taken the lock over.
+func (fl *FileLease) holds(token string) error {
+	data, err := os.ReadFile(fl.path + ".lock")
+	if errors.Is(err, os.ErrNotExist) || (err == nil && !bytes.Equal(data, []byte(token))) {
+		return ErrLeaseContended
+	}
+	return err
+}
+
+// unlock removes the lock file, if it still holds token.
+func (fl *FileLease) unlock(token string) {
+	if fl.holds(token) == nil {
+		_ = os.Remove(fl.path + ".lock")
+	}
+}
+
+func newLockToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+func (fl *FileLease) read() (leaseRecord, error) {
+	data, err := os.ReadFile(fl.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return leaseRecord{}, nil
+	}
+	if err != nil {
+		return leaseRecord{}, err
+	}
+	record := leaseRecord{}
+	if len(data) == 0 {
+		return record, nil
+	}
+	if err := json.Unmarshal(data, &record); err != nil {
+		return leaseRecord{}, fmt.Errorf("could not read lease %s: %w", fl.path, err)
+	}
+	return record, nil
+}
+
+// write replaces the lease file atomically, so that it is never read half written, provided the lock file still holds
+// token.
+func (fl *FileLease) write(token string, record leaseRecord) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(fl.path), filepath.Base(fl.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := fl.holds(token); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), fl.path)
+}
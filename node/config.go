@@ -0,0 +1,234 @@
+package node
+
+import (
+	"fmt"
+	"log/slog"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node/engine"
+)
+
+// Names of the settings which may be changed while the node runs. They match the names of the command line flags which
+// set them at startup. Log levels are set with LogLevelSetting, or with LogLevelSetting + "." + module for a module.
+const (
+	LogLevelSetting                = "loglevel"
+	DepositSafetyDepthSetting      = "depositsafetydepth"
+	CountersignatureTimeoutSetting = "countersignaturetimeout"
+	MaxObjectivesPerPeerSetting    = "maxobjectivesperpeer"
+	MaxObjectivesSetting           = "maxobjectives"
+	QueueExcessObjectivesSetting   = "queueexcessobjectives"
+	AutoDefundSetting              = "autodefund"
+	AutoDefundThresholdSetting     = "autodefundthreshold"
+	PrioritizeExitsDrainingSetting = "prioritizeexitsdraining"
+	PrioritizeExitsPressureSetting = "prioritizeexitspressure"
+)
+
+// configChange stages the changes made by SetConfig, so that none is applied unless all are valid.
+type configChange struct {
+	caps   engine.PolicyCaps
+	levels map[string]slog.Level
+}
+
+// setting is a setting which may be changed while the node runs.
+type setting struct {
+	policy bool // whether the setting is a cap of the policy, which can only be changed if the policy is adjustable
+	get    func(caps engine.PolicyCaps) string
+	set    func(change *configChange, value string) error
+}
+
+// settings returns the settings of the node, keyed by name.
+func (n *Node) settings() map[string]setting {
+	settings := map[string]setting{
+		LogLevelSetting: logLevelSetting(""),
+	}
+	for _, module := range logging.Modules {
+		settings[LogLevelSetting+"."+module] = logLevelSetting(module)
+	}
+	if n.policy == nil {
+		return settings
+	}
+
+	settings[DepositSafetyDepthSetting] = setting{
+		policy: true,
+		get:    func(caps engine.PolicyCaps) string { return strconv.FormatUint(caps.DepositSafetyDepth, 10) },
+		set: func(change *configChange, value string) (err error) {
+			change.caps.DepositSafetyDepth, err = strconv.ParseUint(value, 10, 64)
+			return err
+		},
+	}
+	settings[CountersignatureTimeoutSetting] = setting{
+		policy: true,
+		get:    func(caps engine.PolicyCaps) string { return caps.CountersignatureTimeout.String() },
+		set: func(change *configChange, value string) error {
+			timeout, err := time.ParseDuration(value)
+			if err != nil {
+				return err
+			}
+			if timeout < 0 {
+				return fmt.Errorf("%s is negative", value)
+			}
+			change.caps.CountersignatureTimeout = timeout
+			return nil
+		},
+	}
+	settings[MaxObjectivesPerPeerSetting] = limitSetting(func(caps *engine.PolicyCaps) *int { return &caps.MaxObjectivesPerPeer })
+	settings[MaxObjectivesSetting] = limitSetting(func(caps *engine.PolicyCaps) *int { return &caps.MaxObjectives })
+	settings[QueueExcessObjectivesSetting] = flagSetting(func(caps *engine.PolicyCaps) *bool { return &caps.QueueExcessObjectives })
+	settings[AutoDefundSetting] = flagSetting(func(caps *engine.PolicyCaps) *bool { return &caps.AutoDefund })
+	settings[AutoDefundThresholdSetting] = setting{
+		policy: true,
+		get: func(caps engine.PolicyCaps) string {
+			if caps.AutoDefundAt == nil {
+				return "0"
+			}
+			return caps.AutoDefundAt.String()
+		},
+		set: func(change *configChange, value string) error {
+			threshold, ok := new(big.Int).SetString(value, 10)
+			if !ok || threshold.Sign() < 0 {
+				return fmt.Errorf("%s is not a non-negative integer", value)
+			}
+			change.caps.AutoDefundAt = threshold
+			return nil
+		},
+	}
+	settings[PrioritizeExitsDrainingSetting] = flagSetting(func(caps *engine.PolicyCaps) *bool { return &caps.PrioritizeExitsDraining })
+	settings[PrioritizeExitsPressureSetting] = flagSetting(func(caps *engine.PolicyCaps) *bool { return &caps.PrioritizeExitsPressure })
+	return settings
+}
+
+func logLevelSetting(module string) setting {
+	return setting{
+		get: func(engine.PolicyCaps) string {
+			return logging.Levels()[module].String()
+		},
+		set: func(change *configChange, value string) error {
+			level, err := logging.ParseLevel(value)
+			if err != nil {
+				return err
+			}
+			change.levels[module] = level
+			return nil
+		},
+	}
+}
+
+// limitSetting is a cap of the policy which is a count. Zero means no limit.
+func limitSetting(field func(caps *engine.PolicyCaps) *int) setting {
+	return setting{
+		policy: true,
+		get:    func(caps engine.PolicyCaps) string { return strconv.Itoa(*field(&caps)) },
+		set: func(change *configChange, value string) error {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				return err
+			}
+			if limit < 0 {
+				return fmt.Errorf("%s is negative", value)
+			}
+			*field(&change.caps) = limit
+			return nil
+		},
+	}
+}
+
+// flagSetting is a cap of the policy which is switched on or off.
+func flagSetting(field func(caps *engine.PolicyCaps) *bool) setting {
+	return setting{
+		policy: true,
+		get:    func(caps engine.PolicyCaps) string { return strconv.FormatBool(*field(&caps)) },
+		set: func(change *configChange, value string) (err error) {
+			*field(&change.caps), err = strconv.ParseBool(value)
+			return err
+		},
+	}
+}
+
+// Config returns the current value of each setting which may be changed while the node runs, keyed by name. The caps
+// of the policy are only included if the node's policy maker is an engine.AdjustablePolicy.
+func (n *Node) Config() map[string]string {
+	var caps engine.PolicyCaps
+	if n.policy != nil {
+		caps = n.policy.Caps()
+	}
+	config := map[string]string{}
+	for name, s := range n.settings() {
+		config[name] = s.get(caps)
+	}
+	return config
+}
+
+// SetConfig changes settings while the node runs, without restarting it. Either every setting is changed or, if any
+// name or value is invalid, none is. The changes are recorded in the store, and reapplied when the node restarts,
+// taking precedence over the settings it is started with.
+//
+// Each change is logged, with its previous value, so that there is a record of who changed what: callers should also
+// log the identity of whoever requested the change.
+func (n *Node) SetConfig(values map[string]string) (map[string]string, error) {
+	if err := n.applyConfig(values); err != nil {
+		return nil, err
+	}
+	for name, value := range values {
+		if err := n.store.SetConfigValue(name, value); err != nil {
+			return nil, err
+		}
+	}
+	return n.Config(), nil
+}
+
+// applyConfig validates and applies the settings, logging each change.
+func (n *Node) applyConfig(values map[string]string) error {
+	n.configMu.Lock()
+	defer n.configMu.Unlock()
+
+	settings := n.settings()
+	change := configChange{levels: map[string]slog.Level{}}
+	if n.policy != nil {
+		change.caps = n.policy.Caps()
+	}
+	previous := n.Config()
+	policyChanged := false
+	for name, value := range values {
+		s, ok := settings[name]
+		if !ok {
+			return fmt.Errorf("%w: unknown setting %q", ErrInvalidConfig, name)
+		}
+		if err := s.set(&change, strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrInvalidConfig, name, err)
+		}
+		policyChanged = policyChanged || s.policy
+	}
+
+	if policyChanged {
+		n.policy.SetCaps(change.caps)
+	}
+	for module, level := range change.levels {
+		if err := logging.SetLevel(module, level); err != nil {
+			return err
+		}
+	}
+	current := n.Config()
+	for name := range values {
+		n.logger.Info("Configuration changed", "setting", name, "from", previous[name], "to", current[name])
+	}
+	return nil
+}
+
+// restoreConfig reapplies the settings recorded in the store by SetConfig. Settings which are no longer valid (for
+// instance, the caps of a policy which is no longer adjustable) are skipped.
+func (n *Node) restoreConfig() error {
+	values, err := n.store.GetConfigValues()
+	if err != nil {
+		return err
+	}
+	for name, value := range values {
+		if err := n.applyConfig(map[string]string{name: value}); err != nil {
+			n.logger.Warn("Could not restore a configuration change", "setting", name, "error", err)
+		}
+	}
+	return nil
+}
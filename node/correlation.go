@@ -0,0 +1,14 @@
+package node
+
+import (
+	"context"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+)
+
+// ContextWithCorrelationId returns a copy of ctx carrying the correlation id. The objectives and payments requested
+// with the context log the id, and send it to the peers involved to log too, so that the flow they start can be
+// followed through the logs of every node. The rpc server gives each request its own id.
+func ContextWithCorrelationId(ctx context.Context, id string) context.Context {
+	return logging.ContextWithCorrelationId(ctx, id)
+}
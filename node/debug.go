@@ -0,0 +1,169 @@
+package node
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// DebugInfo describes the node in a debug bundle.
+type DebugInfo struct {
+	Version      string
+	Address      string
+	ChainId      string
+	LastBlockNum uint64
+	Generated    time.Time
+}
+
+// DebugChannels summarises the node's channels in a debug bundle.
+type DebugChannels struct {
+	LedgerChannels  []query.LedgerChannelInfo
+	PaymentChannels []query.PaymentChannelInfo
+}
+
+// DebugObjective describes an in-progress objective in a debug bundle.
+type DebugObjective struct {
+	Id        protocols.ObjectiveId
+	Status    protocols.ObjectiveStatus
+	Objective json.RawMessage
+}
+
+// SetDebugConfig records the configuration the node was started with, for inclusion in debug bundles.
+// Secrets must be redacted by the caller.
+func (n *Node) SetDebugConfig(config map[string]string) {
+	n.debugConfig = config
+}
+
+// WriteDebugBundle writes a zip archive to w which collects diagnostic information for support requests:
+// the node version, its configuration, summaries of its channels, the state of in-progress objectives,
+// the depths of the engine's queues, recent chain events, objective concurrency, payment latencies (with the timings
+// of recent payments, if EnablePaymentTimings was called) and recent logs.
+func (n *Node) WriteDebugBundle(w io.Writer) error {
+	lastBlockNum, err := n.store.GetLastBlockNumSeen()
+	if err != nil {
+		return err
+	}
+	info := DebugInfo{
+		Version:      n.Version(),
+		Address:      n.Address.String(),
+		ChainId:      n.chainId.String(),
+		LastBlockNum: lastBlockNum,
+		Generated:    time.Now(),
+	}
+
+	channels, err := n.debugChannels()
+	if err != nil {
+		return err
+	}
+
+	objectives, err := n.debugObjectives()
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(w)
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"info.json", info},
+		{"config.json", n.debugConfig},
+		{"channels.json", channels},
+		{"objectives.json", objectives},
+		{"queues.json", n.engine.QueueDepths()},
+		{"chain_events.json", n.engine.RecentChainEvents()},
+		{"concurrency.json", n.engine.ConcurrencyStats()},
+		{"peers.json", n.engine.PeerStats()},
+		{"channel_cache.json", n.ChannelCacheStats()},
+		{"payment_latency.json", n.PaymentLatencies()},
+		{"payment_timings.json", n.RecentPaymentTimings()},
+	}
+	for _, f := range files {
+		fw, err := zw.Create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(fw)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := zw.Create("logs.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(fw, strings.Join(logging.RecentLogs(), "\n")); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// SetMessageFaults injects the faults into the messages the node sends and receives, for testing the robustness of the
+// protocols. It returns ErrNoFaultInjection unless the node's message service is a messageservice.FaultInjector.
+func (n *Node) SetMessageFaults(faults messageservice.Faults) error {
+	if n.faultInjector == nil {
+		return ErrNoFaultInjection
+	}
+	return n.faultInjector.SetFaults(faults)
+}
+
+// MessageFaults returns the faults injected into the messages the node sends and receives.
+// It returns ErrNoFaultInjection unless the node's message service is a messageservice.FaultInjector.
+func (n *Node) MessageFaults() (messageservice.Faults, error) {
+	if n.faultInjector == nil {
+		return messageservice.Faults{}, ErrNoFaultInjection
+	}
+	return n.faultInjector.Faults(), nil
+}
+
+// debugChannels returns every ledger channel, and every payment channel funded by one of them.
+func (n *Node) debugChannels() (DebugChannels, error) {
+	ledgers, err := n.GetAllLedgerChannels()
+	if err != nil {
+		return DebugChannels{}, err
+	}
+	channels := DebugChannels{LedgerChannels: ledgers, PaymentChannels: []query.PaymentChannelInfo{}}
+	for _, l := range ledgers {
+		payChs, err := n.GetPaymentChannelsByLedger(l.ID)
+		if err != nil {
+			return DebugChannels{}, err
+		}
+		channels.PaymentChannels = append(channels.PaymentChannels, payChs...)
+	}
+	return channels, nil
+}
+
+// debugObjectives returns the objectives which currently own one of our channels.
+func (n *Node) debugObjectives() ([]DebugObjective, error) {
+	chs, err := n.store.GetChannelsByParticipant(*n.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	objectives := []DebugObjective{}
+	seen := map[protocols.ObjectiveId]bool{}
+	for _, c := range chs {
+		o, ok := n.store.GetObjectiveByChannelId(c.Id)
+		if !ok || seen[o.Id()] {
+			continue
+		}
+		seen[o.Id()] = true
+
+		raw, err := o.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		objectives = append(objectives, DebugObjective{Id: o.Id(), Status: o.GetStatus(), Objective: raw})
+	}
+	return objectives, nil
+}
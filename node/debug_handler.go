@@ -0,0 +1,173 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// RuntimeStats describes the Go runtime of the node.
+type RuntimeStats struct {
+	GoVersion    string
+	Goroutines   int
+	GOMAXPROCS   int
+	HeapAlloc    uint64
+	HeapObjects  uint64
+	Sys          uint64
+	NumGC        uint32
+	LastGC       time.Time
+	PauseTotalNs uint64
+}
+
+// EngineSnapshot describes the engine's queues, the objectives it is running and its peers, for investigating an
+// engine which has stopped making progress.
+type EngineSnapshot struct {
+	Generated   time.Time
+	Queues      map[string]int
+	Concurrency engine.ConcurrencyStats
+	Peers       map[types.Address]engine.PeerStats
+}
+
+// NewDebugHandler returns an http.Handler serving diagnostics of the node to clients presenting a bearer token which
+// authorize accepts, such as an admin key of the rpc server. It serves:
+//
+//   - /debug/pprof/, the runtime/pprof profiles by name (such as /debug/pprof/heap), and a CPU profile at
+//     /debug/pprof/profile?seconds=N
+//   - /debug/goroutines, a dump of the stack of every goroutine
+//   - /debug/runtime, the RuntimeStats of the node
+//   - /debug/engine, an EngineSnapshot
+//
+// The handler is meant for an admin port which is not exposed publicly. net/http/pprof is not used, since importing it
+// would serve the profiles without auth on http.DefaultServeMux.
+func NewDebugHandler(n *Node, authorize func(token string) error) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", serveProfile)
+	mux.Handle("/debug/pprof/profile", &cpuProfiler{})
+	mux.HandleFunc("/debug/goroutines", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_ = pprof.Lookup("goroutine").WriteTo(w, 2)
+	})
+	mux.HandleFunc("/debug/runtime", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJSON(w, readRuntimeStats())
+	})
+	mux.HandleFunc("/debug/engine", func(w http.ResponseWriter, r *http.Request) {
+		writeDebugJSON(w, EngineSnapshot{
+			Generated:   time.Now(),
+			Queues:      n.engine.QueueDepths(),
+			Concurrency: n.engine.ConcurrencyStats(),
+			Peers:       n.engine.PeerStats(),
+		})
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || bearer == "" || authorize(bearer) != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// serveProfile serves the profile named by the path, in the binary format read by go tool pprof, or as text if the
+// debug query parameter is set. The names of the profiles are listed at /debug/pprof/.
+func serveProfile(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(r.URL.Path, "/debug/pprof/")
+	if name == "" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, p := range pprof.Profiles() {
+			fmt.Fprintf(w, "%s\t%d\n", p.Name(), p.Count())
+		}
+		return
+	}
+	p := pprof.Lookup(name)
+	if p == nil {
+		http.Error(w, "unknown profile", http.StatusNotFound)
+		return
+	}
+	debug, _ := strconv.Atoi(r.URL.Query().Get("debug"))
+	if debug == 0 {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	} else {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	}
+	_ = p.WriteTo(w, debug)
+}
+
+// MaxCPUProfileSeconds is the longest CPU profile the debug handler takes.
+const MaxCPUProfileSeconds = 60
+
+// cpuProfiler profiles the CPU for the number of seconds in the query (30 by default, and at most
+// MaxCPUProfileSeconds), and serves the profile. Only one profile is taken at a time: a request made while another is
+// being taken is refused, rather than waiting for it.
+type cpuProfiler struct {
+	running atomic.Bool
+}
+
+func (c *cpuProfiler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	seconds := 30
+	if raw := r.URL.Query().Get("seconds"); raw != "" {
+		var err error
+		seconds, err = strconv.Atoi(raw)
+		if err != nil || seconds <= 0 || seconds > MaxCPUProfileSeconds {
+			http.Error(w, fmt.Sprintf("seconds must be between 1 and %d", MaxCPUProfileSeconds), http.StatusBadRequest)
+			return
+		}
+	}
+	if !c.running.CompareAndSwap(false, true) {
+		http.Error(w, "a CPU profile is already being taken", http.StatusConflict)
+		return
+	}
+	defer c.running.Store(false)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+	if err := pprof.StartCPUProfile(w); err != nil {
+		http.Error(w, fmt.Sprintf("could not profile the CPU: %v", err), http.StatusInternalServerError)
+		return
+	}
+	select {
+	case <-time.After(time.Duration(seconds) * time.Second):
+	case <-r.Context().Done():
+	}
+	pprof.StopCPUProfile()
+}
+
+// readRuntimeStats reads the RuntimeStats of the process.
+func readRuntimeStats() RuntimeStats {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	stats := RuntimeStats{
+		GoVersion:    runtime.Version(),
+		Goroutines:   runtime.NumGoroutine(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		HeapAlloc:    m.HeapAlloc,
+		HeapObjects:  m.HeapObjects,
+		Sys:          m.Sys,
+		NumGC:        m.NumGC,
+		PauseTotalNs: m.PauseTotalNs,
+	}
+	if m.LastGC != 0 {
+		stats.LastGC = time.Unix(0, int64(m.LastGC))
+	}
+	return stats
+}
+
+func writeDebugJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,198 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// DefaultHubMinBackoff is how long the node waits before retrying to open a ledger channel with its default hub,
+	// after the first failure. The wait doubles with each failure, up to DefaultHubMaxBackoff.
+	DefaultHubMinBackoff = time.Second
+	// DefaultHubMaxBackoff is the longest the node waits between attempts to open a ledger channel with its default hub.
+	DefaultHubMaxBackoff = 5 * time.Minute
+)
+
+// DefaultHubOptions configure EnableDefaultHub.
+type DefaultHubOptions struct {
+	// Hub is the intermediary to keep a ledger channel with
+	Hub types.Address
+	// Funding describes how the ledger channel is funded
+	Funding LedgerFundingTemplate
+	// Lazy defers opening the ledger channel until a payment channel is first created through the hub, rather than
+	// opening it when the default hub is enabled.
+	Lazy bool
+	// Timeout is how long each attempt to open the channel is given to fund it. Zero means DefaultBatchChannelTimeout.
+	Timeout time.Duration
+}
+
+// defaultHub tracks the ledger channel kept with the default hub.
+type defaultHub struct {
+	opts  DefaultHubOptions
+	start sync.Once
+	ready chan struct{} // closed once the ledger channel is funded
+
+	mu        sync.Mutex
+	channelId types.Destination
+	lastErr   error // the error of the latest failed attempt, until the channel is funded
+}
+
+// EnableDefaultHub makes the node keep a ledger channel with a hub, so that applications can pay through the hub
+// without managing channels themselves. Unless opts.Lazy is set, the channel is opened at once; otherwise, it is
+// opened when a payment channel is first created through the hub. Failed attempts are retried with exponential
+// backoff, between DefaultHubMinBackoff and DefaultHubMaxBackoff, until the channel is funded or the node is closed.
+//
+// Once enabled, payment channels created without intermediaries, with counterparties we have no ledger channel with,
+// are routed through the hub. Creating a payment channel through the hub waits for its ledger channel to be funded.
+func (n *Node) EnableDefaultHub(opts DefaultHubOptions) error {
+	if (opts.Hub == types.Address{}) || opts.Hub == *n.Address {
+		return fmt.Errorf("%w: the default hub must be another node", ErrInvalidConfig)
+	}
+	if opts.Funding.MyDeposit == nil || opts.Funding.TheirDeposit == nil || opts.Funding.MyDeposit.Sign() < 0 || opts.Funding.TheirDeposit.Sign() < 0 {
+		return fmt.Errorf("%w: deposits with the default hub must not be negative", ErrInvalidConfig)
+	}
+	if err := validateChannelTags(opts.Funding.Tags); err != nil {
+		return err
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultBatchChannelTimeout
+	}
+	n.defaultHub = &defaultHub{opts: opts, ready: make(chan struct{})}
+	if !opts.Lazy {
+		n.startDefaultHub()
+	}
+	return nil
+}
+
+// DefaultHubLedger waits until the ledger channel with the default hub is funded, opening it if it has not been
+// opened, and returns it. It returns ErrNoDefaultHub if EnableDefaultHub has not been called.
+func (n *Node) DefaultHubLedger(ctx context.Context) (query.LedgerChannelInfo, error) {
+	if err := n.awaitDefaultHub(ctx); err != nil {
+		return query.LedgerChannelInfo{}, err
+	}
+	dh := n.defaultHub
+	dh.mu.Lock()
+	defer dh.mu.Unlock()
+	return n.GetLedgerChannel(dh.channelId)
+}
+
+// awaitDefaultHub opens the ledger channel with the default hub, if it has not been opened, and waits until it is
+// funded.
+func (n *Node) awaitDefaultHub(ctx context.Context) error {
+	dh := n.defaultHub
+	if dh == nil {
+		return ErrNoDefaultHub
+	}
+	n.startDefaultHub()
+	select {
+	case <-dh.ready:
+		return nil
+	case <-ctx.Done():
+		dh.mu.Lock()
+		defer dh.mu.Unlock()
+		if dh.lastErr != nil {
+			return fmt.Errorf("ledger channel with the default hub %s is not funded: %w (latest attempt: %w)", dh.opts.Hub, ctx.Err(), dh.lastErr)
+		}
+		return fmt.Errorf("ledger channel with the default hub %s is not funded: %w", dh.opts.Hub, ctx.Err())
+	case <-n.stopBackgroundTasks:
+		return fmt.Errorf("ledger channel with the default hub %s is not funded: the node is closed", dh.opts.Hub)
+	}
+}
+
+// routeThroughDefaultHub returns the intermediaries of a payment channel with the counterparty. A channel without
+// intermediaries, with a counterparty we have no ledger channel with, is routed through the default hub, if there is
+// one. If the channel is funded through the default hub, it waits for the ledger channel with the hub to be funded.
+func (n *Node) routeThroughDefaultHub(ctx context.Context, intermediaries []types.Address, counterparty types.Address) ([]types.Address, error) {
+	dh := n.defaultHub
+	if dh == nil {
+		return intermediaries, nil
+	}
+	if len(intermediaries) == 0 && counterparty != dh.opts.Hub {
+		if _, ok := n.store.GetConsensusChannel(counterparty); !ok {
+			intermediaries = []types.Address{dh.opts.Hub}
+		}
+	}
+	if (len(intermediaries) == 0 && counterparty == dh.opts.Hub) || (len(intermediaries) > 0 && intermediaries[0] == dh.opts.Hub) {
+		if err := n.awaitDefaultHub(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return intermediaries, nil
+}
+
+// startDefaultHub starts keeping the ledger channel with the default hub, unless it has been started.
+func (n *Node) startDefaultHub() {
+	dh := n.defaultHub
+	dh.start.Do(func() {
+		n.backgroundTasksWg.Add(1)
+		go func() {
+			defer n.backgroundTasksWg.Done()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				select {
+				case <-n.stopBackgroundTasks:
+					cancel()
+				case <-ctx.Done():
+				}
+			}()
+			n.keepDefaultHubLedger(ctx, dh)
+		}()
+	})
+}
+
+// keepDefaultHubLedger opens the ledger channel with the default hub, retrying with exponential backoff until it is
+// funded or ctx is done.
+func (n *Node) keepDefaultHubLedger(ctx context.Context, dh *defaultHub) {
+	backoff := DefaultHubMinBackoff
+	for {
+		channelId, err := n.openDefaultHubLedger(ctx, dh.opts)
+		dh.mu.Lock()
+		if err == nil {
+			dh.channelId, dh.lastErr = channelId, nil
+			dh.mu.Unlock()
+			close(dh.ready)
+			n.logger.Info("Ledger channel with the default hub is funded", "hub", dh.opts.Hub, "channel", channelId)
+			return
+		}
+		dh.lastErr = err
+		dh.mu.Unlock()
+		if ctx.Err() != nil {
+			return
+		}
+		n.logger.Warn("Could not open a ledger channel with the default hub, retrying", "hub", dh.opts.Hub, "error", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(2*backoff, DefaultHubMaxBackoff)
+	}
+}
+
+// openDefaultHubLedger returns the id of our ledger channel with the hub, opening it and waiting for it to be funded if
+// we have none.
+func (n *Node) openDefaultHubLedger(ctx context.Context, opts DefaultHubOptions) (types.Destination, error) {
+	if ledger, ok := n.store.GetConsensusChannel(opts.Hub); ok {
+		return ledger.Id, nil
+	}
+	o := opts.Funding.outcome(*n.Address, opts.Hub)
+	// A ledger channel which is still being funded, such as by an attempt which timed out, fails with
+	// ErrLedgerChannelExists, and is found by a later attempt once it is funded
+	response, err := n.CreateLedgerChannelWithOptions(ctx, opts.Hub, opts.Funding.ChallengeDuration, o, CreateChannelOptions{Tags: opts.Funding.Tags})
+	if err != nil {
+		return types.Destination{}, err
+	}
+	n.logger.Info("Opening a ledger channel with the default hub", "hub", opts.Hub, "channel", response.ChannelId)
+	waitCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	if err := n.WaitForObjective(waitCtx, response.Id); err != nil {
+		return types.Destination{}, err
+	}
+	return response.ChannelId, nil
+}
@@ -0,0 +1,135 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// requestKey identifies equivalent requests to create a channel.
+type requestKey struct {
+	kind         string
+	counterparty types.Address
+	asset        types.Address
+	outcomeHash  types.Bytes32
+	tags         string // The channel's tags, canonically encoded
+}
+
+func newRequestKey(kind string, counterparty types.Address, o outcome.Exit, tags map[string]string) (requestKey, error) {
+	hash, err := o.Hash()
+	if err != nil {
+		return requestKey{}, err
+	}
+	// Maps are encoded with sorted keys, so equal tags are always encoded the same
+	encodedTags, err := json.Marshal(tags)
+	if err != nil {
+		return requestKey{}, err
+	}
+	key := requestKey{kind: kind, counterparty: counterparty, outcomeHash: hash, tags: string(encodedTags)}
+	if len(o) > 0 {
+		key.asset = o[0].Asset
+	}
+	return key, nil
+}
+
+// request is a request to create a channel which later equivalent requests are answered with.
+type request struct {
+	submitted   chan struct{} // Closed once the request has been submitted to the engine (or failed to be)
+	objectiveId protocols.ObjectiveId
+	response    any
+	err         error
+	at          time.Time
+}
+
+// duplicateRequests remembers recent requests to create channels, so that equivalent requests can be detected.
+type duplicateRequests struct {
+	mu       sync.Mutex
+	window   time.Duration // Zero disables detection
+	requests map[requestKey]*request
+}
+
+// live returns true if later requests equivalent to r should be answered with r's response: either r is still being
+// submitted, its objective is in progress, or its objective completed within the window.
+func (d *duplicateRequests) live(n *Node, r *request) bool {
+	select {
+	case <-r.submitted:
+	default:
+		return true
+	}
+	if r.err != nil {
+		return false
+	}
+	if o, err := n.store.GetObjectiveById(r.objectiveId); err == nil {
+		switch o.GetStatus() {
+		case protocols.Rejected:
+			return false
+		case protocols.Unapproved, protocols.Approved:
+			return true
+		}
+	}
+	return time.Since(r.at) < d.window
+}
+
+// prune forgets requests which are no longer live. It must be called with d.mu held.
+func (d *duplicateRequests) prune(n *Node) {
+	for key, r := range d.requests {
+		if !d.live(n, r) {
+			delete(d.requests, key)
+		}
+	}
+}
+
+// createOnce calls create, unless an equivalent request is live (see duplicateRequests.live), in which case the
+// response to that request is returned instead. If an equivalent request is still being submitted, createOnce waits
+// for it. Detection is skipped if force is true or it is not enabled.
+func createOnce[R any](ctx context.Context, n *Node, key requestKey, force bool, create func() (R, protocols.ObjectiveId, error)) (R, error) {
+	d := n.duplicateRequests
+	for {
+		d.mu.Lock()
+		if d.window == 0 || force {
+			d.mu.Unlock()
+			response, _, err := create()
+			return response, err
+		}
+		d.prune(n)
+		existing, ok := d.requests[key]
+		if !ok {
+			r := &request{submitted: make(chan struct{})}
+			d.requests[key] = r
+			d.mu.Unlock()
+
+			response, objectiveId, err := create()
+			r.response, r.objectiveId, r.err, r.at = response, objectiveId, err, time.Now()
+			close(r.submitted)
+			return response, err
+		}
+		d.mu.Unlock()
+
+		select {
+		case <-existing.submitted:
+		case <-ctx.Done():
+			var zero R
+			return zero, ctx.Err()
+		}
+		if existing.err == nil {
+			return existing.response.(R), nil
+		}
+		// The equivalent request failed, so this one is made in its place
+	}
+}
+
+// EnableDuplicateRequestDetection makes requests to create a ledger or payment channel return the response to an
+// equivalent earlier request (to the same counterparty, with the same outcome and tags) instead of creating another
+// channel, while the earlier request's objective is in progress or for window after it was made. This lets
+// applications retry requests safely. Requests which must create a new channel regardless can be made with
+// CreateLedgerChannelForced and CreatePaymentChannelForced.
+func (n *Node) EnableDuplicateRequestDetection(window time.Duration) {
+	n.duplicateRequests.mu.Lock()
+	defer n.duplicateRequests.mu.Unlock()
+	n.duplicateRequests.window = window
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrAssetNotAllowed is wrapped by the AssetNotAllowedError for a channel holding an asset our AssetPolicy does not allow.
+const ErrAssetNotAllowed = types.ConstError("asset not allowed")
+
+// AssetNotAllowedError reports that the outcome of a channel holds an asset our AssetPolicy does not allow.
+type AssetNotAllowedError struct {
+	ChannelId types.Destination
+	Asset     types.Address
+}
+
+func (e *AssetNotAllowedError) Error() string {
+	return fmt.Sprintf("%s: channel %s holds asset %s", ErrAssetNotAllowed, e.ChannelId, e.Asset)
+}
+
+func (e *AssetNotAllowedError) Unwrap() error {
+	return ErrAssetNotAllowed
+}
+
+// CheckOutcomeAssets returns an *AssetNotAllowedError if the outcome of the channel holds an asset which the policy
+// maker does not allow (see AssetPolicy).
+func (e *Engine) CheckOutcomeAssets(channelId types.Destination, o outcome.Exit) error {
+	ap, ok := e.policymaker.(AssetPolicy)
+	if !ok {
+		return nil
+	}
+	for _, sae := range o {
+		if !ap.AllowsAsset(sae.Asset) {
+			return &AssetNotAllowedError{ChannelId: channelId, Asset: sae.Asset}
+		}
+	}
+	return nil
+}
+
+// checkObjectiveAssets returns an *AssetNotAllowedError if the objective funds a channel whose outcome holds an asset
+// which the policy maker does not allow. Objectives which fund no new channel are not checked.
+func (e *Engine) checkObjectiveAssets(o protocols.Objective) error {
+	switch o := o.(type) {
+	case *directfund.Objective:
+		return e.CheckOutcomeAssets(o.C.Id, o.C.PreFundState().Outcome)
+	case *virtualfund.Objective:
+		return e.CheckOutcomeAssets(o.V.Id, o.V.PreFundState().Outcome)
+	default:
+		return nil
+	}
+}
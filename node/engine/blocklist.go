@@ -0,0 +1,159 @@
+package engine
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrPeerBlocked is returned when a channel is requested with a peer which has been blocked.
+const ErrPeerBlocked = types.ConstError("peer is blocked")
+
+const (
+	// BlockedMessagesMetric is the name of the counter of messages dropped because their sender is blocked, labelled by
+	// "source": "engine", or "message_service" for messages dropped by a message service as they were received.
+	BlockedMessagesMetric = "blocked_messages_dropped"
+	// BlockedObjectivesMetric is the name of the counter of objectives refused because a participant is blocked.
+	BlockedObjectivesMetric = "blocked_objectives_refused"
+)
+
+// Blocklist holds the peers which have been blocked. Messages from blocked peers are dropped, and objectives for
+// channels with blocked participants are refused. The blocklist is kept in the store, so that peers stay blocked across
+// restarts, and may be read and changed from outside the engine's run loop.
+type Blocklist struct {
+	mu      sync.RWMutex
+	peers   map[string]store.BlockedPeer
+	store   store.BlocklistStore
+	metrics Metrics
+}
+
+// newBlocklist returns the blocklist kept in the store.
+func newBlocklist(s store.BlocklistStore) (*Blocklist, error) {
+	blocked, err := s.GetBlockedPeers()
+	if err != nil {
+		return nil, err
+	}
+	b := &Blocklist{peers: make(map[string]store.BlockedPeer), store: s, metrics: NoopMetrics{}}
+	for _, bp := range blocked {
+		b.peers[bp.Key()] = bp
+	}
+	return b, nil
+}
+
+// Block blocks the peer by its address or, if it has one, its peer id. Blocking a peer which is already blocked
+// replaces its reason.
+func (b *Blocklist) Block(address types.Address, peerId string, reason string) (store.BlockedPeer, error) {
+	bp := store.BlockedPeer{Address: address, PeerId: peerId, Reason: reason, Blocked: time.Now().UTC()}
+	if peerId != "" {
+		// A peer is blocked by peer id alone, so that unblocking its peer id unblocks it
+		bp.Address = types.Address{}
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if err := b.store.SetBlockedPeer(bp); err != nil {
+		return store.BlockedPeer{}, err
+	}
+	b.peers[bp.Key()] = bp
+	return bp, nil
+}
+
+// Unblock unblocks the peer blocked by its address or, if peerId is not empty, its peer id. It returns false if the
+// peer was not blocked.
+func (b *Blocklist) Unblock(address types.Address, peerId string) (bool, error) {
+	key := store.BlockedPeer{Address: address, PeerId: peerId}.Key()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.peers[key]; !ok {
+		return false, nil
+	}
+	if err := b.store.RemoveBlockedPeer(key); err != nil {
+		return false, err
+	}
+	delete(b.peers, key)
+	return true, nil
+}
+
+// Peers returns the blocked peers, in the order they were blocked.
+func (b *Blocklist) Peers() []store.BlockedPeer {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	peers := make([]store.BlockedPeer, 0, len(b.peers))
+	for _, bp := range b.peers {
+		peers = append(peers, bp)
+	}
+	slices.SortFunc(peers, func(a, b store.BlockedPeer) int { return a.Blocked.Compare(b.Blocked) })
+	return peers
+}
+
+// BlocksAddress returns true if the peer with the address has been blocked.
+func (b *Blocklist) BlocksAddress(address types.Address) bool {
+	if address == (types.Address{}) {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.peers[address.String()]
+	return ok
+}
+
+// BlocksPeerId returns true if the peer with the peer id has been blocked.
+func (b *Blocklist) BlocksPeerId(peerId string) bool {
+	if peerId == "" {
+		return false
+	}
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	_, ok := b.peers[peerId]
+	return ok
+}
+
+// DropsMessage returns true, counting the message as dropped, if the message received from the peer with the address
+// and peer id should be dropped. peerId is empty if the message service does not identify peers by peer id.
+func (b *Blocklist) DropsMessage(from types.Address, peerId string) bool {
+	return b.dropsMessage(from, peerId, "message_service")
+}
+
+func (b *Blocklist) dropsMessage(from types.Address, peerId string, source string) bool {
+	if !b.BlocksAddress(from) && !b.BlocksPeerId(peerId) {
+		return false
+	}
+	b.metrics.IncCounter(BlockedMessagesMetric, map[string]string{"source": source})
+	return true
+}
+
+// CheckParticipants returns an error wrapping ErrPeerBlocked, counting the objective as refused, if any of the
+// participants has been blocked.
+func (b *Blocklist) CheckParticipants(participants []types.Address) error {
+	for _, p := range participants {
+		if b.BlocksAddress(p) {
+			b.metrics.IncCounter(BlockedObjectivesMetric, nil)
+			return fmt.Errorf("%w: %s", ErrPeerBlocked, p)
+		}
+	}
+	return nil
+}
+
+// Blocklist returns the engine's blocklist.
+func (e *Engine) Blocklist() *Blocklist {
+	return e.blocklist
+}
+
+// checkObjectivePeers returns an error wrapping ErrPeerBlocked if the objective funds a channel with a blocked
+// participant. Objectives which fund no new channel are not checked.
+func (e *Engine) checkObjectivePeers(o protocols.Objective) error {
+	switch o := o.(type) {
+	case *directfund.Objective:
+		return e.blocklist.CheckParticipants(o.C.Participants)
+	case *virtualfund.Objective:
+		return e.blocklist.CheckParticipants(o.V.Participants)
+	default:
+		return nil
+	}
+}
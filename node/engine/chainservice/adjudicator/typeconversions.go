@@ -22,11 +22,11 @@ func ConvertVariablePart(vp state.VariablePart) INitroTypesVariablePart {
 		AppData: vp.AppData,
 		TurnNum: big.NewInt(int64(vp.TurnNum)),
 		IsFinal: vp.IsFinal,
-		Outcome: convertOutcome(vp.Outcome),
+		Outcome: ConvertOutcome(vp.Outcome),
 	}
 }
 
-func convertOutcome(o outcome.Exit) []ExitFormatSingleAssetExit {
+func ConvertOutcome(o outcome.Exit) []ExitFormatSingleAssetExit {
 	e := make([]ExitFormatSingleAssetExit, len(o))
 	for i, sae := range o {
 		e[i].Asset = sae.Asset
@@ -63,6 +63,9 @@ func ConvertSignature(s nc.Signature) INitroTypesSignature {
 	return sig
 }
 
+// ConvertSignedStateToFixedPartAndSignedVariablePart converts the signed state to the adjudicator's types. The
+// signatures of participants which are contract wallets are passed through as their owners made them, for an
+// adjudicator which verifies them with ERC-1271.
 func ConvertSignedStateToFixedPartAndSignedVariablePart(s state.SignedState) (INitroTypesFixedPart, INitroTypesSignedVariablePart) {
 	fp := ConvertFixedPart(s.State().FixedPart())
 	svp := INitroTypesSignedVariablePart{
@@ -84,3 +87,14 @@ func ConvertSignedStatesToProof(ss []state.SignedState) []INitroTypesSignedVaria
 	}
 	return svps
 }
+
+// EncodeCheckpointCalldata returns the abi encoded calldata for a NitroAdjudicator checkpoint call
+// which registers the supplied signed state on chain. The state must be signed by every participant.
+func EncodeCheckpointCalldata(ss state.SignedState) ([]byte, error) {
+	naAbi, err := NitroAdjudicatorMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	fp, candidate := ConvertSignedStateToFixedPartAndSignedVariablePart(ss)
+	return naAbi.Pack("checkpoint", fp, []INitroTypesSignedVariablePart{}, candidate)
+}
@@ -0,0 +1,167 @@
+package chainservice
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/channel/state"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// AdjudicatorBinding is the layer between an EthChainService and the adjudicator contract it transacts with and
+// listens to. The EthChainService provides the event subscription, confirmation and transaction nonce machinery, and
+// delegates encoding calls and decoding logs to the binding.
+//
+// Integrators with a modified adjudicator (with extra events or methods) may supply their own binding, typically by
+// wrapping a NitroAdjudicatorBinding and overriding the methods which differ.
+type AdjudicatorBinding interface {
+	// Address returns the address of the adjudicator contract
+	Address() common.Address
+	// Topics returns the topics of the adjudicator events to subscribe to
+	Topics() []common.Hash
+	// Holdings returns the amount of the asset held by the adjudicator against the channel
+	Holdings(opts *bind.CallOpts, asset common.Address, channelId types.Destination) (*big.Int, error)
+	// Deposit deposits amount of the asset into the channel, given that expectedHeld is already held against it
+	Deposit(opts *bind.TransactOpts, asset common.Address, channelId types.Destination, expectedHeld, amount *big.Int) error
+	// Submit submits any transaction other than a deposit
+	Submit(opts *bind.TransactOpts, tx protocols.ChainTransaction) error
+	// ParseEvent decodes a log emitted by the adjudicator into an Event which the engine understands.
+	// A nil Event (and nil error) is returned for logs which should be ignored.
+	ParseEvent(ctx context.Context, chain ethereum.TransactionReader, l ethTypes.Log) (Event, error)
+}
+
+// AdjudicatorBindingFactory constructs an AdjudicatorBinding for the adjudicator deployed at address.
+type AdjudicatorBindingFactory func(address common.Address, backend bind.ContractBackend) (AdjudicatorBinding, error)
+
+// NitroAdjudicatorBinding is the AdjudicatorBinding for the standard NitroAdjudicator contract.
+type NitroAdjudicatorBinding struct {
+	na      *NitroAdjudicator.NitroAdjudicator
+	address common.Address
+}
+
+// NewNitroAdjudicatorBinding binds to the NitroAdjudicator deployed at address. It is an AdjudicatorBindingFactory.
+func NewNitroAdjudicatorBinding(address common.Address, backend bind.ContractBackend) (AdjudicatorBinding, error) {
+	na, err := NitroAdjudicator.NewNitroAdjudicator(address, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &NitroAdjudicatorBinding{na: na, address: address}, nil
+}
+
+func (b *NitroAdjudicatorBinding) Address() common.Address {
+	return b.address
+}
+
+func (b *NitroAdjudicatorBinding) Topics() []common.Hash {
+	return topicsToWatch
+}
+
+func (b *NitroAdjudicatorBinding) Holdings(opts *bind.CallOpts, asset common.Address, channelId types.Destination) (*big.Int, error) {
+	return b.na.Holdings(opts, asset, channelId)
+}
+
+func (b *NitroAdjudicatorBinding) Deposit(opts *bind.TransactOpts, asset common.Address, channelId types.Destination, expectedHeld, amount *big.Int) error {
+	_, err := b.na.Deposit(opts, asset, channelId, expectedHeld, amount)
+	return err
+}
+
+func (b *NitroAdjudicatorBinding) Submit(opts *bind.TransactOpts, tx protocols.ChainTransaction) error {
+	switch tx := tx.(type) {
+	case protocols.WithdrawAllTransaction:
+		signedState := tx.SignedState.State()
+		signatures := tx.SignedState.Signatures()
+		nitroFixedPart := NitroAdjudicator.INitroTypesFixedPart(NitroAdjudicator.ConvertFixedPart(signedState.FixedPart()))
+		nitroVariablePart := NitroAdjudicator.ConvertVariablePart(signedState.VariablePart())
+		nitroSignatures := []NitroAdjudicator.INitroTypesSignature{NitroAdjudicator.ConvertSignature(signatures[0]), NitroAdjudicator.ConvertSignature(signatures[1])}
+
+		candidate := NitroAdjudicator.INitroTypesSignedVariablePart{
+			VariablePart: nitroVariablePart,
+			Sigs:         nitroSignatures,
+		}
+		_, err := b.na.ConcludeAndTransferAllAssets(opts, nitroFixedPart, candidate)
+		return err
+	case protocols.ChallengeTransaction:
+		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
+		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
+		challengerSig := NitroAdjudicator.ConvertSignature(tx.ChallengerSig)
+		_, err := b.na.Challenge(opts, fp, proof, candidate, challengerSig)
+		return err
+	case protocols.CheckpointTransaction:
+		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
+		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
+		_, err := b.na.Checkpoint(opts, fp, proof, candidate)
+		return err
+	case protocols.TransferAllTransaction:
+		_, err := b.na.TransferAllAssets(opts, tx.ChannelId(), NitroAdjudicator.ConvertOutcome(tx.Outcome), tx.StateHash)
+		return err
+	default:
+		return fmt.Errorf("unexpected transaction type %T", tx)
+	}
+}
+
+func (b *NitroAdjudicatorBinding) ParseEvent(ctx context.Context, chain ethereum.TransactionReader, l ethTypes.Log) (Event, error) {
+	switch l.Topics[0] {
+	case depositedTopic:
+		nad, err := b.na.ParseDeposited(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseDeposited: %w", err)
+		}
+		return NewDepositedEvent(nad.Destination, l.BlockNumber, l.TxIndex, nad.Asset, nad.DestinationHoldings), nil
+
+	case allocationUpdatedTopic:
+		au, err := b.na.ParseAllocationUpdated(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseAllocationUpdated: %w", err)
+		}
+
+		tx, pending, err := chain.TransactionByHash(ctx, l.TxHash)
+		if pending {
+			return nil, fmt.Errorf("expected transaction to be part of the chain, but the transaction is pending")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error in TransactionByHash: %w", err)
+		}
+
+		assetAddress, err := assetAddressForIndex(b.na, tx, au.AssetIndex)
+		if err != nil {
+			return nil, fmt.Errorf("error in assetAddressForIndex: %w", err)
+		}
+		return NewAllocationUpdatedEvent(au.ChannelId, l.BlockNumber, l.TxIndex, assetAddress, au.FinalHoldings), nil
+
+	case concludedTopic:
+		ce, err := b.na.ParseConcluded(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseConcluded: %w", err)
+		}
+		return ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, blockNum: l.BlockNumber}}, nil
+
+	case challengeRegisteredTopic:
+		cr, err := b.na.ParseChallengeRegistered(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseChallengeRegistered: %w", err)
+		}
+		return NewChallengeRegisteredEvent(cr.ChannelId, l.BlockNumber, l.TxIndex, state.VariablePart{
+			AppData: cr.Candidate.VariablePart.AppData,
+			Outcome: NitroAdjudicator.ConvertBindingsExitToExit(cr.Candidate.VariablePart.Outcome),
+			TurnNum: cr.Candidate.VariablePart.TurnNum.Uint64(),
+			IsFinal: cr.Candidate.VariablePart.IsFinal,
+		}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs), cr.FinalizesAt.Uint64()), nil
+
+	case challengeClearedTopic:
+		cc, err := b.na.ParseChallengeCleared(l)
+		if err != nil {
+			return nil, fmt.Errorf("error in ParseChallengeCleared: %w", err)
+		}
+		return NewChallengeClearedEvent(cc.ChannelId, l.BlockNumber, l.TxIndex, cc.NewTurnNumRecord.Uint64()), nil
+
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,72 @@
+package chainservice
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// countingBinding stands in for the binding to a modified adjudicator, overriding some methods of the standard binding.
+type countingBinding struct {
+	AdjudicatorBinding
+	deposits atomic.Int32
+	parsed   atomic.Int32
+}
+
+func (b *countingBinding) Deposit(opts *bind.TransactOpts, asset common.Address, channelId types.Destination, expectedHeld, amount *big.Int) error {
+	b.deposits.Add(1)
+	return b.AdjudicatorBinding.Deposit(opts, asset, channelId, expectedHeld, amount)
+}
+
+func (b *countingBinding) ParseEvent(ctx context.Context, chain ethereum.TransactionReader, l ethTypes.Log) (Event, error) {
+	b.parsed.Add(1)
+	return b.AdjudicatorBinding.ParseEvent(ctx, chain, l)
+}
+
+func TestCustomAdjudicatorBinding(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nitroBinding, err := NewNitroAdjudicatorBinding(bindings.Adjudicator.Address, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binding := &countingBinding{AdjudicatorBinding: nitroBinding}
+
+	ecs, err := newEthChainService(sim, 0, binding, bindings.ConsensusApp.Address, bindings.VirtualPaymentApp.Address, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &SimulatedBackendChainService{EthChainService: ecs, sim: sim}
+	defer closeChainService(t, cs)
+
+	channelId := types.Destination(common.HexToHash("0x4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d"))
+	deposit := types.Funds{common.Address{}: big.NewInt(5)}
+	err = cs.SendTransaction(protocols.NewDepositTransaction(channelId, deposit))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := <-cs.EventFeed()
+	de, ok := event.(DepositedEvent)
+	if !ok {
+		t.Fatalf("expected a DepositedEvent, got %T", event)
+	}
+	if de.ChannelID() != channelId || de.NowHeld.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("unexpected event %v", de)
+	}
+	if binding.deposits.Load() != 1 || binding.parsed.Load() != 1 {
+		t.Fatalf("expected the custom binding to submit 1 deposit and parse 1 event, got %d and %d", binding.deposits.Load(), binding.parsed.Load())
+	}
+}
@@ -2,12 +2,14 @@
 package chainservice // import "github.com/statechannels/go-nitro/node/chainservice"
 
 import (
+	"context"
 	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -82,6 +84,7 @@ type ChallengeRegisteredEvent struct {
 	commonEvent
 	candidate           state.VariablePart
 	candidateSignatures []state.Signature
+	finalizesAt         uint64
 }
 
 // NewChallengeRegisteredEvent constructs a ChallengeRegisteredEvent
@@ -91,6 +94,7 @@ func NewChallengeRegisteredEvent(
 	txIndex uint,
 	variablePart state.VariablePart,
 	sigs []state.Signature,
+	finalizesAt uint64,
 ) ChallengeRegisteredEvent {
 	return ChallengeRegisteredEvent{
 		commonEvent: commonEvent{channelID: channelId, blockNum: blockNum, txIndex: txIndex},
@@ -100,9 +104,20 @@ func NewChallengeRegisteredEvent(
 			TurnNum: variablePart.TurnNum,
 			IsFinal: variablePart.IsFinal,
 		}, candidateSignatures: sigs,
+		finalizesAt: finalizesAt,
 	}
 }
 
+// FinalizesAt returns the time, in unix seconds, at which the challenge finalizes unless it is cleared.
+func (cr ChallengeRegisteredEvent) FinalizesAt() uint64 {
+	return cr.finalizesAt
+}
+
+// TurnNum returns the turn number of the state the challenge was registered with.
+func (cr ChallengeRegisteredEvent) TurnNum() uint64 {
+	return cr.candidate.TurnNum
+}
+
 // StateHash returns the statehash stored on chain at the time of the ChallengeRegistered Event firing.
 func (cr ChallengeRegisteredEvent) StateHash(fp state.FixedPart) (common.Hash, error) {
 	return state.StateFromFixedAndVariablePart(fp, cr.candidate).Hash()
@@ -114,11 +129,12 @@ func (cr ChallengeRegisteredEvent) Outcome() outcome.Exit {
 }
 
 // SignedState returns the signed state which will have been stored on chain in the adjudicator after the ChallengeRegistered Event fires.
-func (cr ChallengeRegisteredEvent) SignedState(fp state.FixedPart) (state.SignedState, error) {
+// Its signatures are verified with schemes.
+func (cr ChallengeRegisteredEvent) SignedState(fp state.FixedPart, schemes *crypto.Schemes) (state.SignedState, error) {
 	s := state.StateFromFixedAndVariablePart(fp, cr.candidate)
 	ss := state.NewSignedState(s)
 	for _, sig := range cr.candidateSignatures {
-		err := ss.AddSignature(sig)
+		err := ss.AddSignatureWith(schemes, sig)
 		if err != nil {
 			return state.SignedState{}, err
 		}
@@ -130,6 +146,27 @@ func (cr ChallengeRegisteredEvent) String() string {
 	return "CHALLENGE registered for Channel " + cr.channelID.String() + " at Block " + fmt.Sprint(cr.blockNum)
 }
 
+// ChallengeClearedEvent is an internal representation of the ChallengeCleared blockchain event, which is emitted when a
+// challenge is answered with a later supported state.
+type ChallengeClearedEvent struct {
+	commonEvent
+	newTurnNumRecord uint64
+}
+
+// NewChallengeClearedEvent constructs a ChallengeClearedEvent
+func NewChallengeClearedEvent(channelId types.Destination, blockNum uint64, txIndex uint, newTurnNumRecord uint64) ChallengeClearedEvent {
+	return ChallengeClearedEvent{commonEvent: commonEvent{channelID: channelId, blockNum: blockNum, txIndex: txIndex}, newTurnNumRecord: newTurnNumRecord}
+}
+
+// NewTurnNumRecord returns the turn number of the state with which the challenge was cleared.
+func (cc ChallengeClearedEvent) NewTurnNumRecord() uint64 {
+	return cc.newTurnNumRecord
+}
+
+func (cc ChallengeClearedEvent) String() string {
+	return "CHALLENGE cleared for Channel " + cc.channelID.String() + " at turn " + fmt.Sprint(cc.newTurnNumRecord) + " at Block " + fmt.Sprint(cc.blockNum)
+}
+
 func NewDepositedEvent(channelId types.Destination, blockNum uint64, txIndex uint, assetAddress common.Address, nowHeld *big.Int) DepositedEvent {
 	return DepositedEvent{commonEvent{channelId, blockNum, txIndex}, assetAddress, nowHeld}
 }
@@ -162,3 +199,49 @@ type ChainService interface {
 	// Close closes the ChainService
 	Close() error
 }
+
+// FundingWallet may optionally be implemented by a ChainService to report what the account it sends deposits from can
+// afford, so that a node can refuse to propose ledger channels it could not fund.
+type FundingWallet interface {
+	// WalletAddress returns the address of the funding account.
+	WalletAddress() types.Address
+	// WalletBalance returns the balance of the funding account in asset (the zero address for the chain's native token).
+	WalletBalance(ctx context.Context, asset types.Address) (*big.Int, error)
+	// DepositGasCost returns an estimate of the native token spent on gas to deposit asset (including any approval).
+	DepositGasCost(ctx context.Context, asset types.Address) (*big.Int, error)
+}
+
+// TokenMetadata may optionally be implemented by a ChainService to report the number of decimals of the assets held by
+// channels, so that their balances can be shown in whole tokens.
+type TokenMetadata interface {
+	// TokenDecimals returns the number of decimals of asset: one whole token is 10^decimals of its smallest unit.
+	TokenDecimals(ctx context.Context, asset types.Address) (uint8, error)
+}
+
+// ConnectionMonitor may optionally be implemented by a ChainService to report whether it is connected to the chain.
+type ConnectionMonitor interface {
+	// Connected returns false while the chain service cannot receive events from the chain.
+	Connected() bool
+}
+
+// TxStatus is what has become of a chain transaction which was submitted.
+type TxStatus int
+
+const (
+	TxPending  TxStatus = iota // The transaction is known to the chain, but has not been mined
+	TxMined                    // The transaction was mined, and succeeded
+	TxReverted                 // The transaction was mined, but reverted
+	TxDropped                  // The transaction is unknown to the chain: it was dropped, or never reached it
+)
+
+// TransactionTracker may optionally be implemented by a ChainService to report the chain transactions it submits, and
+// what has become of them, so that transactions dropped before they were mined (for instance while the node was
+// offline) can be found and resubmitted.
+type TransactionTracker interface {
+	// SendTrackedTransaction submits the transaction as SendTransaction does, returning the hashes of the chain
+	// transactions submitted for it. If fillNonceGap is true, they take the nonces following the last mined transaction
+	// of the account, rather than the last pending one, so that they take the place of dropped transactions.
+	SendTrackedTransaction(tx protocols.ChainTransaction, fillNonceGap bool) ([]common.Hash, error)
+	// TransactionStatus returns what has become of the chain transaction with the hash.
+	TransactionStatus(ctx context.Context, hash common.Hash) (TxStatus, error)
+}
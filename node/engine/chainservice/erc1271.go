@@ -0,0 +1,101 @@
+package chainservice
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ERC1271MagicValue is returned by an ERC-1271 wallet's isValidSignature method when the signature is valid.
+var ERC1271MagicValue = [4]byte{0x16, 0x26, 0xba, 0x7e}
+
+const erc1271AbiJson = `[{"type":"function","name":"isValidSignature","stateMutability":"view","inputs":[{"name":"hash","type":"bytes32"},{"name":"signature","type":"bytes"}],"outputs":[{"name":"magicValue","type":"bytes4"}]}]`
+
+var erc1271Abi, _ = abi.JSON(strings.NewReader(erc1271AbiJson))
+
+// SignatureValidator may optionally be implemented by a ChainService to verify the signatures of smart contract
+// wallets, which have no private key of their own.
+type SignatureValidator interface {
+	// IsContract returns true if there is code deployed at the address.
+	IsContract(ctx context.Context, address types.Address) (bool, error)
+	// IsValidSignature returns true if the ERC-1271 wallet accepts the signature on the hash.
+	IsValidSignature(ctx context.Context, wallet types.Address, hash common.Hash, signature []byte) (bool, error)
+}
+
+// IsContract returns true if there is code deployed at the address.
+func (ecs *EthChainService) IsContract(ctx context.Context, address types.Address) (bool, error) {
+	code, err := ecs.chain.CodeAt(ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+	return len(code) > 0, nil
+}
+
+// IsValidSignature calls isValidSignature on the ERC-1271 wallet, returning true if it returns the magic value.
+func (ecs *EthChainService) IsValidSignature(ctx context.Context, wallet types.Address, hash common.Hash, signature []byte) (bool, error) {
+	data, err := erc1271Abi.Pack("isValidSignature", hash, signature)
+	if err != nil {
+		return false, err
+	}
+	result, err := ecs.chain.CallContract(ctx, ethereum.CallMsg{To: &wallet, Data: data}, nil)
+	if err != nil {
+		// Wallets may revert, rather than return some other value, when the signature is invalid
+		ecs.logger.Debug("isValidSignature call failed", "wallet", wallet, "error", err)
+		return false, nil
+	}
+	// Compare the left-aligned bytes4 rather than unpacking, since some wallets return malformed data for invalid signatures
+	return len(result) >= 4 && bytes.Equal(result[:4], ERC1271MagicValue[:]), nil
+}
+
+// ERC1271Scheme is a crypto.SignatureScheme which verifies the signatures of smart contract wallets by calling their
+// ERC-1271 isValidSignature method through the chain service. The wallet is asked to verify the same digest that an
+// externally owned account signs (see crypto.EthereumSignedMessageDigest).
+type ERC1271Scheme struct {
+	validator SignatureValidator
+	timeout   time.Duration
+}
+
+// ErrCannotSignForContract is returned when asked to sign with an ERC1271Scheme: a contract wallet's signatures are
+// made by its owners, off the node.
+var ErrCannotSignForContract = errors.New("cannot sign on behalf of a contract wallet")
+
+// DEFAULT_SIGNATURE_VALIDATION_TIMEOUT bounds each call made to a wallet to verify a signature.
+const DEFAULT_SIGNATURE_VALIDATION_TIMEOUT = 10 * time.Second
+
+// NewERC1271Scheme returns an ERC1271Scheme which verifies signatures through the validator.
+func NewERC1271Scheme(validator SignatureValidator) ERC1271Scheme {
+	return ERC1271Scheme{validator: validator, timeout: DEFAULT_SIGNATURE_VALIDATION_TIMEOUT}
+}
+
+func (s ERC1271Scheme) Sign(message []byte, secretKey []byte) (nc.Signature, error) {
+	return nc.Signature{}, ErrCannotSignForContract
+}
+
+func (s ERC1271Scheme) Verify(message []byte, sig nc.Signature, signer types.Address) error {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	encoded := make([]byte, 0, 65)
+	encoded = append(encoded, sig.R...)
+	encoded = append(encoded, sig.S...)
+	encoded = append(encoded, sig.V)
+
+	valid, err := s.validator.IsValidSignature(ctx, signer, nc.EthereumSignedMessageDigest(message), encoded)
+	if err != nil {
+		return fmt.Errorf("could not verify the signature of contract wallet %s: %w", signer, err)
+	}
+	if !valid {
+		return nc.ErrInvalidSignature
+	}
+	return nil
+}
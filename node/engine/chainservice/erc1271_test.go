@@ -0,0 +1,40 @@
+package chainservice
+
+import (
+	"errors"
+	"testing"
+
+	nc "github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestERC1271Scheme(t *testing.T) {
+	chain := NewMockChain()
+	wallet := types.Address{'w'}
+	chain.DeployContractWallet(wallet, ta.Alice.Address())
+	scheme := NewERC1271Scheme(NewMockChainService(chain, ta.Alice.Address()))
+
+	message := []byte("state hash")
+	ownerSig, err := nc.SignEthereumMessage(message, ta.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scheme.Verify(message, ownerSig, wallet); err != nil {
+		t.Fatalf("expected the owner's signature to be valid, got %v", err)
+	}
+
+	otherSig, err := nc.SignEthereumMessage(message, ta.Bob.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := scheme.Verify(message, otherSig, wallet); !errors.Is(err, nc.ErrInvalidSignature) {
+		t.Fatalf("expected %v, got %v", nc.ErrInvalidSignature, err)
+	}
+	if err := scheme.Verify(message, ownerSig, types.Address{'x'}); !errors.Is(err, nc.ErrInvalidSignature) {
+		t.Fatalf("expected a signature for an address without a wallet to be invalid, got %v", err)
+	}
+	if _, err := scheme.Sign(message, ta.Alice.PrivateKey); !errors.Is(err, ErrCannotSignForContract) {
+		t.Fatalf("expected %v, got %v", ErrCannotSignForContract, err)
+	}
+}
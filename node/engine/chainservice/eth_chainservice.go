@@ -1,11 +1,15 @@
 package chainservice
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -13,7 +17,6 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	ethTypes "github.com/ethereum/go-ethereum/core/types"
 
-	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/logging"
 	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	Token "github.com/statechannels/go-nitro/node/engine/chainservice/erc20"
@@ -30,6 +33,18 @@ type ChainOpts struct {
 	NaAddress       common.Address
 	VpaAddress      common.Address
 	CaAddress       common.Address
+	// NewAdjudicatorBinding constructs the binding to the adjudicator at NaAddress. If nil, NewNitroAdjudicatorBinding is used.
+	NewAdjudicatorBinding AdjudicatorBindingFactory
+	// VirtualOnly runs the node without a connection to the chain (see VirtualOnlyChainService), ignoring the other options
+	// except for the app addresses.
+	VirtualOnly bool
+	// ChainId is the id of the chain used by a virtual-only node's counterparties
+	ChainId uint64
+	// PollInterval, if non-zero, makes the chain service poll for events with eth_getLogs at this interval, rather than
+	// subscribe to them. Polling suits providers which only serve http, or whose subscriptions are unreliable.
+	PollInterval time.Duration
+	// PollBatchSize is the most blocks a single eth_getLogs query of a poll may cover. It defaults to MAX_QUERY_BLOCK_RANGE.
+	PollBatchSize uint64
 }
 
 var (
@@ -59,13 +74,14 @@ type ethChain interface {
 	ethereum.TransactionReader
 	ethereum.ChainReader
 	ChainID(ctx context.Context) (*big.Int, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 }
 
 // eventTracker holds on to events in memory and dispatches an event after required number of confirmations
 type EthChainService struct {
 	chain                    ethChain
-	na                       *NitroAdjudicator.NitroAdjudicator
-	naAddress                common.Address
+	adjudicator              AdjudicatorBinding
 	consensusAppAddress      common.Address
 	virtualPaymentAppAddress common.Address
 	txSigner                 *bind.TransactOpts
@@ -77,6 +93,8 @@ type EthChainService struct {
 	eventTracker             *eventTracker
 	eventSub                 ethereum.Subscription
 	newBlockSub              ethereum.Subscription
+	poller                   *poller     // nil unless the chain service polls for events, rather than subscribing to them
+	disconnected             atomic.Bool // set while a subscription or poll is failing
 }
 
 // MAX_QUERY_BLOCK_RANGE is the maximum range of blocks we query for events at once.
@@ -92,6 +110,16 @@ const MAX_QUERY_BLOCK_RANGE = 2000
 // This has been reduced to 15 seconds to support local devnets with much shorter timeouts.
 const RESUB_INTERVAL = 15 * time.Second
 
+// DEPOSIT_GAS and APPROVE_GAS are generous estimates of the gas used by a deposit into the adjudicator and an ERC20
+// approval. They are only used to check that the funding account can afford a deposit before proposing a channel.
+const (
+	DEPOSIT_GAS = 100_000
+	APPROVE_GAS = 60_000
+)
+
+// NATIVE_DECIMALS is the number of decimals of the chain's native token.
+const NATIVE_DECIMALS = 18
+
 // REQUIRED_BLOCK_CONFIRMATIONS is how many blocks must be mined before an emitted event is processed
 const REQUIRED_BLOCK_CONFIRMATIONS = 2
 
@@ -118,26 +146,34 @@ func NewEthChainService(chainOpts ChainOpts) (ChainService, error) {
 		panic(err)
 	}
 
-	na, err := NitroAdjudicator.NewNitroAdjudicator(chainOpts.NaAddress, ethClient)
+	newBinding := chainOpts.NewAdjudicatorBinding
+	if newBinding == nil {
+		newBinding = NewNitroAdjudicatorBinding
+	}
+	adjudicator, err := newBinding(chainOpts.NaAddress, ethClient)
 	if err != nil {
 		panic(err)
 	}
 
-	return newEthChainService(ethClient, chainOpts.ChainStartBlock, na, chainOpts.NaAddress, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner)
+	if chainOpts.PollInterval > 0 {
+		return newPollingEthChainService(ethClient, chainOpts.ChainStartBlock, adjudicator, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner,
+			chainOpts.PollInterval, chainOpts.PollBatchSize)
+	}
+	return newEthChainService(ethClient, chainOpts.ChainStartBlock, adjudicator, chainOpts.CaAddress, chainOpts.VpaAddress, txSigner)
 }
 
-// newEthChainService constructs a chain service that submits transactions to a NitroAdjudicator
+// newEthChainService constructs a chain service that submits transactions to an adjudicator
 // and listens to events from an eventSource
-func newEthChainService(chain ethChain, startBlock uint64, na *NitroAdjudicator.NitroAdjudicator,
-	naAddress, caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts,
+func newEthChainService(chain ethChain, startBlock uint64, adjudicator AdjudicatorBinding,
+	caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts,
 ) (*EthChainService, error) {
 	ctx, cancelCtx := context.WithCancel(context.Background())
 
-	logger := logging.LoggerWithAddress(slog.Default(), txSigner.From)
+	logger := logging.LoggerWithAddress(logging.ModuleLogger(logging.CHAINSERVICE_MODULE), txSigner.From)
 	tracker := NewEventTracker(startBlock)
 
 	// Use a buffered channel so we don't have to worry about blocking on writing to the channel.
-	ecs := EthChainService{chain, na, naAddress, caAddress, vpaAddress, txSigner, make(chan Event, 10), logger, ctx, cancelCtx, &sync.WaitGroup{}, tracker, nil, nil}
+	ecs := EthChainService{chain, adjudicator, caAddress, vpaAddress, txSigner, make(chan Event, 10), logger, ctx, cancelCtx, &sync.WaitGroup{}, tracker, nil, nil, nil, atomic.Bool{}}
 	errChan, newBlockChan, eventChan, eventQuery, err := ecs.subscribeForLogs()
 	if err != nil {
 		return nil, err
@@ -182,8 +218,8 @@ func (ecs *EthChainService) checkForMissedEvents(startBlock uint64) error {
 		query := ethereum.FilterQuery{
 			FromBlock: big.NewInt(int64(currentStart)),
 			ToBlock:   big.NewInt(int64(currentEnd)),
-			Addresses: []common.Address{ecs.naAddress},
-			Topics:    [][]common.Hash{topicsToWatch},
+			Addresses: []common.Address{ecs.adjudicator.Address()},
+			Topics:    [][]common.Hash{ecs.adjudicator.Topics()},
 		}
 
 		// Fetch logs for the current chunk
@@ -237,128 +273,155 @@ func (ecs *EthChainService) defaultTxOpts() *bind.TransactOpts {
 
 // SendTransaction sends the transaction and blocks until it has been submitted.
 func (ecs *EthChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	_, err := ecs.sendTransaction(tx, false)
+	return err
+}
+
+// SendTrackedTransaction sends the transaction and blocks until it has been submitted, returning the hashes of the
+// chain transactions submitted for it.
+func (ecs *EthChainService) SendTrackedTransaction(tx protocols.ChainTransaction, fillNonceGap bool) ([]common.Hash, error) {
+	return ecs.sendTransaction(tx, fillNonceGap)
+}
+
+// sendTransaction submits the transaction, returning the hashes of the chain transactions signed for it. If
+// fillNonceGap is true, the first takes the nonce following the account's last mined transaction.
+func (ecs *EthChainService) sendTransaction(tx protocols.ChainTransaction, fillNonceGap bool) ([]common.Hash, error) {
+	hashes := []common.Hash{}
+	txOpts := func() *bind.TransactOpts {
+		opts := ecs.defaultTxOpts()
+		sign := opts.Signer
+		opts.Signer = func(from common.Address, tx *ethTypes.Transaction) (*ethTypes.Transaction, error) {
+			signed, err := sign(from, tx)
+			if err == nil {
+				hashes = append(hashes, signed.Hash())
+			}
+			return signed, err
+		}
+		return opts
+	}
+
 	switch tx := tx.(type) {
 	case protocols.DepositTransaction:
-		for tokenAddress, amount := range tx.Deposit {
-			txOpts := ecs.defaultTxOpts()
-			ethTokenAddress := common.Address{}
-			if tokenAddress == ethTokenAddress {
-				txOpts.Value = amount
-			} else {
-				tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
-				if err != nil {
-					return err
-				}
-				_, err = tokenTransactor.Approve(ecs.defaultTxOpts(), ecs.naAddress, amount)
-				if err != nil {
-					return err
-				}
-				// TODO: wait for the Approve tx to be mined before continuing
+		return hashes, ecs.deposit(tx, txOpts, fillNonceGap)
+	default:
+		opts := txOpts()
+		if fillNonceGap {
+			nonce, err := ecs.chain.NonceAt(ecs.ctx, ecs.txSigner.From, nil)
+			if err != nil {
+				return hashes, err
 			}
-			holdings, err := ecs.na.Holdings(&bind.CallOpts{}, tokenAddress, tx.ChannelId())
-			ecs.logger.Debug("existing holdings", "holdings", holdings)
+			opts.Nonce = new(big.Int).SetUint64(nonce)
+		}
+		return hashes, ecs.adjudicator.Submit(opts, tx)
+	}
+}
+
+// TransactionStatus returns what has become of the chain transaction with the hash.
+func (ecs *EthChainService) TransactionStatus(ctx context.Context, hash common.Hash) (TxStatus, error) {
+	receipt, err := ecs.chain.TransactionReceipt(ctx, hash)
+	if err == nil {
+		if receipt.Status == ethTypes.ReceiptStatusSuccessful {
+			return TxMined, nil
+		}
+		return TxReverted, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return 0, err
+	}
+	_, _, err = ecs.chain.TransactionByHash(ctx, hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return TxDropped, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return TxPending, nil
+}
 
+// deposit submits a deposit of each asset in the transaction. The adjudicator's holdings of the assets are read in
+// parallel, then the deposits (and any ERC20 approvals they need) are submitted with consecutive nonces reserved up front,
+// without waiting for any to be mined. Each asset's deposit is then confirmed independently by its own Deposited event.
+func (ecs *EthChainService) deposit(tx protocols.DepositTransaction, txOpts func() *bind.TransactOpts, fillNonceGap bool) error {
+	assets := make([]common.Address, 0, len(tx.Deposit))
+	for asset := range tx.Deposit {
+		assets = append(assets, asset)
+	}
+	slices.SortFunc(assets, func(a, b common.Address) int { return bytes.Compare(a[:], b[:]) })
+
+	holdings := make([]*big.Int, len(assets))
+	errs := make([]error, len(assets))
+	wg := sync.WaitGroup{}
+	for i, asset := range assets {
+		wg.Add(1)
+		go func(i int, asset common.Address) {
+			defer wg.Done()
+			holdings[i], errs[i] = ecs.adjudicator.Holdings(&bind.CallOpts{Context: ecs.ctx}, asset, tx.ChannelId())
+		}(i, asset)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	nonceAt := ecs.chain.PendingNonceAt
+	if fillNonceGap {
+		nonceAt = func(ctx context.Context, account common.Address) (uint64, error) {
+			return ecs.chain.NonceAt(ctx, account, nil)
+		}
+	}
+	nonce, err := nonceAt(ecs.ctx, ecs.txSigner.From)
+	if err != nil {
+		return err
+	}
+	nextTxOpts := func() *bind.TransactOpts {
+		txOpts := txOpts()
+		txOpts.Nonce = new(big.Int).SetUint64(nonce)
+		nonce++
+		return txOpts
+	}
+
+	ethTokenAddress := common.Address{}
+	for i, tokenAddress := range assets {
+		amount := tx.Deposit[tokenAddress]
+		if tokenAddress != ethTokenAddress {
+			tokenTransactor, err := Token.NewTokenTransactor(tokenAddress, ecs.chain)
 			if err != nil {
 				return err
 			}
-
-			_, err = ecs.na.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings, amount)
+			// The approval's nonce orders it before the deposit which relies on it
+			_, err = tokenTransactor.Approve(nextTxOpts(), ecs.adjudicator.Address(), amount)
 			if err != nil {
 				return err
 			}
 		}
-		return nil
-	case protocols.WithdrawAllTransaction:
-		signedState := tx.SignedState.State()
-		signatures := tx.SignedState.Signatures()
-		nitroFixedPart := NitroAdjudicator.INitroTypesFixedPart(NitroAdjudicator.ConvertFixedPart(signedState.FixedPart()))
-		nitroVariablePart := NitroAdjudicator.ConvertVariablePart(signedState.VariablePart())
-		nitroSignatures := []NitroAdjudicator.INitroTypesSignature{NitroAdjudicator.ConvertSignature(signatures[0]), NitroAdjudicator.ConvertSignature(signatures[1])}
-
-		candidate := NitroAdjudicator.INitroTypesSignedVariablePart{
-			VariablePart: nitroVariablePart,
-			Sigs:         nitroSignatures,
+
+		txOpts := nextTxOpts()
+		if tokenAddress == ethTokenAddress {
+			txOpts.Value = amount
+		}
+		ecs.logger.Debug("existing holdings", "asset", tokenAddress, "holdings", holdings[i])
+		err = ecs.adjudicator.Deposit(txOpts, tokenAddress, tx.ChannelId(), holdings[i], amount)
+		if err != nil {
+			return err
 		}
-		_, err := ecs.na.ConcludeAndTransferAllAssets(ecs.defaultTxOpts(), nitroFixedPart, candidate)
-		return err
-	case protocols.ChallengeTransaction:
-		fp, candidate := NitroAdjudicator.ConvertSignedStateToFixedPartAndSignedVariablePart(tx.Candidate)
-		proof := NitroAdjudicator.ConvertSignedStatesToProof(tx.Proof)
-		challengerSig := NitroAdjudicator.ConvertSignature(tx.ChallengerSig)
-		_, err := ecs.na.Challenge(ecs.defaultTxOpts(), fp, proof, candidate, challengerSig)
-		return err
-	default:
-		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
+	return nil
 }
 
 // dispatchChainEvents takes in a collection of event logs from the chain
 // and dispatches events to the out channel
 func (ecs *EthChainService) dispatchChainEvents(logs []ethTypes.Log) error {
 	for _, l := range logs {
-		switch l.Topics[0] {
-		case depositedTopic:
-			ecs.logger.Debug("Processing Deposited event")
-			nad, err := ecs.na.ParseDeposited(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseDeposited: %w", err)
-			}
-
-			event := NewDepositedEvent(nad.Destination, l.BlockNumber, l.TxIndex, nad.Asset, nad.DestinationHoldings)
-			ecs.out <- event
-
-		case allocationUpdatedTopic:
-			ecs.logger.Debug("Processing AllocationUpdated event")
-			au, err := ecs.na.ParseAllocationUpdated(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseAllocationUpdated: %w", err)
-			}
-
-			tx, pending, err := ecs.chain.TransactionByHash(ecs.ctx, l.TxHash)
-			if pending {
-				return fmt.Errorf("expected transaction to be part of the chain, but the transaction is pending")
-			}
-			if err != nil {
-				return fmt.Errorf("error in TransactionByHash: %w", err)
-			}
-
-			assetAddress, err := assetAddressForIndex(ecs.na, tx, au.AssetIndex)
-			if err != nil {
-				return fmt.Errorf("error in assetAddressForIndex: %w", err)
-			}
-			ecs.logger.Debug("assetAddress", "assetAddress", assetAddress)
-
-			event := NewAllocationUpdatedEvent(au.ChannelId, l.BlockNumber, l.TxIndex, assetAddress, au.FinalHoldings)
-			ecs.out <- event
-
-		case concludedTopic:
-			ecs.logger.Debug("Processing Concluded event")
-			ce, err := ecs.na.ParseConcluded(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseConcluded: %w", err)
-			}
-
-			event := ConcludedEvent{commonEvent: commonEvent{channelID: ce.ChannelId, blockNum: l.BlockNumber}}
-			ecs.out <- event
-
-		case challengeRegisteredTopic:
-			cr, err := ecs.na.ParseChallengeRegistered(l)
-			if err != nil {
-				return fmt.Errorf("error in ParseChallengeRegistered: %w", err)
-			}
-			event := NewChallengeRegisteredEvent(cr.ChannelId, l.BlockNumber, l.TxIndex, state.VariablePart{
-				AppData: cr.Candidate.VariablePart.AppData,
-				Outcome: NitroAdjudicator.ConvertBindingsExitToExit(cr.Candidate.VariablePart.Outcome),
-				TurnNum: cr.Candidate.VariablePart.TurnNum.Uint64(),
-				IsFinal: cr.Candidate.VariablePart.IsFinal,
-			}, NitroAdjudicator.ConvertBindingsSignaturesToSignatures(cr.Candidate.Sigs))
-			ecs.out <- event
-		case challengeClearedTopic:
-			ecs.logger.Info("Ignoring Challenge Cleared event")
-		default:
-			ecs.logger.Info("Ignoring unknown chain event topic", "topic", l.Topics[0].String())
-
+		event, err := ecs.adjudicator.ParseEvent(ecs.ctx, ecs.chain, l)
+		if err != nil {
+			return err
+		}
+		if event == nil {
+			ecs.logger.Info("Ignoring chain event topic", "topic", l.Topics[0].String())
+			continue
 		}
+		ecs.logger.Debug("Processing chain event", "event", event)
+		ecs.out <- event
 	}
 	return nil
 }
@@ -381,6 +444,7 @@ func (ecs *EthChainService) listenForEventLogs(errorChan chan<- error, eventChan
 
 				if err != nil {
 					ecs.logger.Warn("error in chain event subscription: " + err.Error())
+					ecs.disconnected.Store(true)
 					ecs.eventSub.Unsubscribe()
 				} else {
 					ecs.logger.Warn("chain event subscription closed")
@@ -405,6 +469,7 @@ func (ecs *EthChainService) listenForEventLogs(errorChan chan<- error, eventChan
 						return
 					}
 
+					ecs.disconnected.Store(false)
 					resubscribed = true
 					break
 				}
@@ -439,6 +504,7 @@ func (ecs *EthChainService) listenForNewBlocks(errorChan chan<- error, newBlockC
 		case err := <-ecs.newBlockSub.Err():
 			if err != nil {
 				ecs.logger.Warn("error in chain new block subscription: " + err.Error())
+				ecs.disconnected.Store(true)
 				ecs.newBlockSub.Unsubscribe()
 			} else {
 				ecs.logger.Warn("chain new block subscription closed")
@@ -454,6 +520,7 @@ func (ecs *EthChainService) listenForNewBlocks(errorChan chan<- error, newBlockC
 				}
 
 				ecs.newBlockSub = newBlockSub
+				ecs.disconnected.Store(false)
 				ecs.logger.Debug("resubscribed to chain new blocks")
 				break
 			}
@@ -492,7 +559,7 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 		// Ensure event & associated tx is still in the chain before adding to eventsToDispatch
 		oldBlock, err := ecs.chain.BlockByNumber(context.Background(), new(big.Int).SetUint64(chainEvent.BlockNumber))
 		if err != nil {
-			ecs.logger.Error("failed to fetch block: %v", err)
+			ecs.logger.Error("failed to fetch block", "error", err)
 			errorChan <- fmt.Errorf("failed to fetch block: %v", err)
 			return
 		}
@@ -518,8 +585,8 @@ func (ecs *EthChainService) updateEventTracker(errorChan chan<- error, blockNumb
 func (ecs *EthChainService) subscribeForLogs() (chan error, chan *ethTypes.Header, chan ethTypes.Log, ethereum.FilterQuery, error) {
 	// Subscribe to Adjudicator events
 	eventQuery := ethereum.FilterQuery{
-		Addresses: []common.Address{ecs.naAddress},
-		Topics:    [][]common.Hash{topicsToWatch},
+		Addresses: []common.Address{ecs.adjudicator.Address()},
+		Topics:    [][]common.Hash{ecs.adjudicator.Topics()},
 	}
 	eventChan := make(chan ethTypes.Log)
 	eventSub, err := ecs.chain.SubscribeFilterLogs(ecs.ctx, eventQuery, eventChan)
@@ -552,10 +619,57 @@ func (ecs *EthChainService) GetVirtualPaymentAppAddress() types.Address {
 	return ecs.virtualPaymentAppAddress
 }
 
+// Connected returns false while the chain service's subscriptions to the chain, or its polls of it, are failing.
+func (ecs *EthChainService) Connected() bool {
+	return !ecs.disconnected.Load()
+}
+
 func (ecs *EthChainService) GetChainId() (*big.Int, error) {
 	return ecs.chain.ChainID(ecs.ctx)
 }
 
+// WalletAddress returns the address of the transaction signer.
+func (ecs *EthChainService) WalletAddress() types.Address {
+	return ecs.txSigner.From
+}
+
+// WalletBalance returns the transaction signer's balance of asset.
+func (ecs *EthChainService) WalletBalance(ctx context.Context, asset types.Address) (*big.Int, error) {
+	if asset == (types.Address{}) {
+		return ecs.chain.BalanceAt(ctx, ecs.txSigner.From, nil)
+	}
+	token, err := Token.NewTokenCaller(asset, ecs.chain)
+	if err != nil {
+		return nil, err
+	}
+	return token.BalanceOf(&bind.CallOpts{Context: ctx}, ecs.txSigner.From)
+}
+
+// TokenDecimals returns the decimals of the ERC20 token at asset, or NATIVE_DECIMALS for the chain's native token.
+func (ecs *EthChainService) TokenDecimals(ctx context.Context, asset types.Address) (uint8, error) {
+	if asset == (types.Address{}) {
+		return NATIVE_DECIMALS, nil
+	}
+	token, err := Token.NewTokenCaller(asset, ecs.chain)
+	if err != nil {
+		return 0, err
+	}
+	return token.Decimals(&bind.CallOpts{Context: ctx})
+}
+
+// DepositGasCost estimates the cost of depositing asset at the suggested gas price.
+func (ecs *EthChainService) DepositGasCost(ctx context.Context, asset types.Address) (*big.Int, error) {
+	gasPrice, err := ecs.chain.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gas := int64(DEPOSIT_GAS)
+	if asset != (types.Address{}) {
+		gas += APPROVE_GAS
+	}
+	return new(big.Int).Mul(gasPrice, big.NewInt(gas)), nil
+}
+
 func (ecs *EthChainService) GetLastConfirmedBlockNum() uint64 {
 	var confirmedBlockNum uint64
 
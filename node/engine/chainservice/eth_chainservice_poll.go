@@ -0,0 +1,128 @@
+package chainservice
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+)
+
+// poller holds the state of a chain service which polls for events, rather than subscribing to them.
+type poller struct {
+	interval  time.Duration
+	batchSize uint64
+	nextBlock uint64 // the first block not yet queried for events
+}
+
+// newPollingEthChainService constructs a chain service which polls the chain for adjudicator events with eth_getLogs,
+// querying at most batchSize blocks at once, rather than subscribing to them. It needs no websocket connection.
+//
+// Only blocks with REQUIRED_BLOCK_CONFIRMATIONS confirmations are queried, so that events are not missed if the latest
+// blocks are reorganised between polls.
+func newPollingEthChainService(chain ethChain, startBlock uint64, adjudicator AdjudicatorBinding,
+	caAddress, vpaAddress common.Address, txSigner *bind.TransactOpts, interval time.Duration, batchSize uint64,
+) (*EthChainService, error) {
+	if batchSize == 0 {
+		batchSize = MAX_QUERY_BLOCK_RANGE
+	}
+	ctx, cancelCtx := context.WithCancel(context.Background())
+
+	logger := logging.LoggerWithAddress(logging.ModuleLogger(logging.CHAINSERVICE_MODULE), txSigner.From)
+	ecs := EthChainService{
+		chain:                    chain,
+		adjudicator:              adjudicator,
+		consensusAppAddress:      caAddress,
+		virtualPaymentAppAddress: vpaAddress,
+		txSigner:                 txSigner,
+		out:                      make(chan Event, 10),
+		logger:                   logger,
+		ctx:                      ctx,
+		cancel:                   cancelCtx,
+		wg:                       &sync.WaitGroup{},
+		eventTracker:             NewEventTracker(startBlock),
+		poller:                   &poller{interval: interval, batchSize: batchSize, nextBlock: startBlock},
+	}
+	logger.Info("polling for chain events", "interval", interval, "batchSize", batchSize, "startBlock", startBlock)
+
+	// Catch up with the events emitted while this node was offline before returning
+	if err := ecs.poll(); err != nil {
+		cancelCtx()
+		return nil, err
+	}
+
+	errChan := make(chan error)
+	ecs.wg.Add(2)
+	go ecs.pollForEvents(errChan)
+	go ecs.listenForErrors(errChan)
+
+	return &ecs, nil
+}
+
+// pollForEvents polls for events at the poll interval, until the chain service is closed. Failed polls are retried at
+// the next interval, since the blocks they cover are queried again.
+func (ecs *EthChainService) pollForEvents(errorChan chan<- error) {
+	defer ecs.wg.Done()
+	ticker := time.NewTicker(ecs.poller.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ecs.ctx.Done():
+			return
+		case <-ticker.C:
+			err := ecs.poll()
+			if err != nil {
+				ecs.logger.Warn("failed to poll for chain events, retrying", "error", err, "interval", ecs.poller.interval)
+			}
+			ecs.disconnected.Store(err != nil)
+		}
+	}
+}
+
+// poll queries the blocks confirmed since the last poll for events, in batches, and passes them to the event tracker.
+func (ecs *EthChainService) poll() error {
+	latest, err := ecs.chain.HeaderByNumber(ecs.ctx, nil)
+	if err != nil {
+		return fmt.Errorf("could not fetch the latest block: %w", err)
+	}
+	latestBlockNum := latest.Number.Uint64()
+	if latestBlockNum < REQUIRED_BLOCK_CONFIRMATIONS {
+		return nil
+	}
+	confirmedBlockNum := latestBlockNum - REQUIRED_BLOCK_CONFIRMATIONS
+
+	for ecs.poller.nextBlock <= confirmedBlockNum {
+		from := ecs.poller.nextBlock
+		to := min(from+ecs.poller.batchSize-1, confirmedBlockNum)
+		query := ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: []common.Address{ecs.adjudicator.Address()},
+			Topics:    [][]common.Hash{ecs.adjudicator.Topics()},
+		}
+		logs, err := ecs.chain.FilterLogs(ecs.ctx, query)
+		if err != nil {
+			return fmt.Errorf("could not query blocks %d to %d for events: %w", from, to, err)
+		}
+		ecs.logger.Debug("polled for chain events", "fromBlock", from, "toBlock", to, "numEvents", len(logs))
+
+		errChan := make(chan error, 1)
+		for i := range logs {
+			ecs.updateEventTracker(errChan, nil, &logs[i])
+		}
+		ecs.updateEventTracker(errChan, &latestBlockNum, nil)
+		select {
+		case err := <-errChan:
+			return err
+		default:
+		}
+		ecs.poller.nextBlock = to + 1
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package chainservice
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPollingChainService(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binding, err := NewNitroAdjudicatorBinding(bindings.Adjudicator.Address, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A batch size of 1 makes each poll query the blocks one at a time
+	ecs, err := newPollingEthChainService(sim, 0, binding, bindings.ConsensusApp.Address, bindings.VirtualPaymentApp.Address, ethAccounts[0],
+		10*time.Millisecond, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs := &SimulatedBackendChainService{EthChainService: ecs, sim: sim}
+	defer closeChainService(t, cs)
+
+	channelId := types.Destination(common.HexToHash("0x4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d"))
+	for i, amount := range []int64{5, 3} {
+		err = cs.SendTransaction(protocols.NewDepositTransaction(channelId, types.Funds{common.Address{}: big.NewInt(amount)}))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case event := <-cs.EventFeed():
+			de, ok := event.(DepositedEvent)
+			if !ok {
+				t.Fatalf("expected a DepositedEvent, got %T", event)
+			}
+			if want := []int64{5, 8}[i]; de.ChannelID() != channelId || de.NowHeld.Cmp(big.NewInt(want)) != 0 {
+				t.Fatalf("unexpected event %v", de)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for a polled event")
+		}
+	}
+
+	if cs.eventSub != nil || cs.newBlockSub != nil {
+		t.Fatal("expected a polling chain service not to subscribe")
+	}
+}
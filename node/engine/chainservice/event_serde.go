@@ -0,0 +1,80 @@
+package chainservice
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Kinds of chain event, as recorded by MarshalEvent.
+const (
+	depositedKind           = "Deposited"
+	allocationUpdatedKind   = "AllocationUpdated"
+	concludedKind           = "Concluded"
+	challengeRegisteredKind = "ChallengeRegistered"
+	challengeClearedKind    = "ChallengeCleared"
+)
+
+// jsonEvent is the serialization of every kind of chain event. Fields which do not apply to the Kind are omitted.
+type jsonEvent struct {
+	Kind                string
+	ChannelId           types.Destination
+	BlockNum            uint64
+	TxIndex             uint
+	Asset               common.Address      `json:",omitempty"`
+	Amount              *big.Int            `json:",omitempty"`
+	Candidate           *state.VariablePart `json:",omitempty"`
+	CandidateSignatures []state.Signature   `json:",omitempty"`
+	FinalizesAt         uint64              `json:",omitempty"`
+	NewTurnNumRecord    uint64              `json:",omitempty"`
+}
+
+// MarshalEvent serializes a chain event, such that UnmarshalEvent reconstructs it.
+func MarshalEvent(event Event) ([]byte, error) {
+	je := jsonEvent{ChannelId: event.ChannelID(), BlockNum: event.BlockNum(), TxIndex: event.TxIndex()}
+	switch e := event.(type) {
+	case DepositedEvent:
+		je.Kind, je.Asset, je.Amount = depositedKind, e.Asset, e.NowHeld
+	case AllocationUpdatedEvent:
+		je.Kind, je.Asset, je.Amount = allocationUpdatedKind, e.AssetAddress, e.AssetAmount
+	case ConcludedEvent:
+		je.Kind = concludedKind
+	case ChallengeRegisteredEvent:
+		je.Kind, je.Candidate, je.CandidateSignatures, je.FinalizesAt = challengeRegisteredKind, &e.candidate, e.candidateSignatures, e.finalizesAt
+	case ChallengeClearedEvent:
+		je.Kind, je.NewTurnNumRecord = challengeClearedKind, e.newTurnNumRecord
+	default:
+		return nil, fmt.Errorf("cannot marshal chain event of type %T", event)
+	}
+	return json.Marshal(je)
+}
+
+// UnmarshalEvent reconstructs a chain event serialized by MarshalEvent.
+func UnmarshalEvent(data []byte) (Event, error) {
+	var je jsonEvent
+	if err := json.Unmarshal(data, &je); err != nil {
+		return nil, err
+	}
+	ce := commonEvent{channelID: je.ChannelId, blockNum: je.BlockNum, txIndex: je.TxIndex}
+	switch je.Kind {
+	case depositedKind:
+		return DepositedEvent{ce, je.Asset, je.Amount}, nil
+	case allocationUpdatedKind:
+		return AllocationUpdatedEvent{ce, assetAndAmount{AssetAddress: je.Asset, AssetAmount: je.Amount}}, nil
+	case concludedKind:
+		return ConcludedEvent{ce}, nil
+	case challengeRegisteredKind:
+		if je.Candidate == nil {
+			return nil, fmt.Errorf("challenge registered event has no candidate")
+		}
+		return ChallengeRegisteredEvent{commonEvent: ce, candidate: *je.Candidate, candidateSignatures: je.CandidateSignatures, finalizesAt: je.FinalizesAt}, nil
+	case challengeClearedKind:
+		return ChallengeClearedEvent{commonEvent: ce, newTurnNumRecord: je.NewTurnNumRecord}, nil
+	default:
+		return nil, fmt.Errorf("unknown kind of chain event %q", je.Kind)
+	}
+}
@@ -0,0 +1,42 @@
+package chainservice
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestMarshalEvent(t *testing.T) {
+	id := types.Destination(common.HexToHash("4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d"))
+	asset := common.HexToAddress("0x01")
+	candidate := state.TestState.VariablePart()
+	sig, err := state.TestState.Sign(testactors.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []Event{
+		NewDepositedEvent(id, 1, 2, asset, big.NewInt(3)),
+		NewAllocationUpdatedEvent(id, 4, 5, asset, big.NewInt(6)),
+		ConcludedEvent{commonEvent{channelID: id, blockNum: 7}},
+		NewChallengeRegisteredEvent(id, 8, 9, candidate, []state.Signature{sig}, 10),
+		NewChallengeClearedEvent(id, 11, 12, 13),
+	}
+	for _, event := range events {
+		data, err := MarshalEvent(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := UnmarshalEvent(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(event, got, cmp.AllowUnexported(big.Int{}, commonEvent{}, DepositedEvent{}, AllocationUpdatedEvent{}, ConcludedEvent{}, ChallengeRegisteredEvent{}, ChallengeClearedEvent{})); diff != "" {
+			t.Errorf("%T does not survive marshalling: %s", event, diff)
+		}
+	}
+}
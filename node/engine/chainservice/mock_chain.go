@@ -3,6 +3,7 @@ package chainservice
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/internal/safesync"
@@ -20,6 +21,17 @@ type MockChain struct {
 	// out maps addresses to an Event channel. Given that MockChainServices only subscribe
 	// (and never unsubscribe) to events, this can be converted to a list.
 	out safesync.Map[chan Event]
+
+	// challenges holds when the challenge registered for each channel finalizes, in unix seconds
+	challenges map[types.Destination]uint64
+
+	walletsMu sync.Mutex
+	// wallets maps the address of each contract wallet to the owner whose signatures it accepts
+	wallets map[types.Address]types.Address
+
+	tokensMu sync.Mutex
+	// tokens maps the address of each ERC20 token deployed with DeployToken to its decimals
+	tokens map[types.Address]uint8
 }
 
 // NewMockChain creates a new MockChain
@@ -27,10 +39,44 @@ func NewMockChain() *MockChain {
 	chain := MockChain{}
 	chain.BlockNum = 1
 	chain.holdings = map[types.Destination]types.Funds{}
+	chain.challenges = map[types.Destination]uint64{}
 	chain.out = safesync.Map[chan Event]{}
+	chain.wallets = map[types.Address]types.Address{}
+	chain.tokens = map[types.Address]uint8{}
 	return &chain
 }
 
+// DeployContractWallet deploys an ERC-1271 wallet at the address, which accepts signatures made by the owner.
+func (mc *MockChain) DeployContractWallet(wallet, owner types.Address) {
+	mc.walletsMu.Lock()
+	defer mc.walletsMu.Unlock()
+	mc.wallets[wallet] = owner
+}
+
+// contractWalletOwner returns the owner of the contract wallet, and true, if one is deployed at the address.
+func (mc *MockChain) contractWalletOwner(wallet types.Address) (types.Address, bool) {
+	mc.walletsMu.Lock()
+	defer mc.walletsMu.Unlock()
+	owner, ok := mc.wallets[wallet]
+	return owner, ok
+}
+
+// DeployToken deploys an ERC20 token with the given decimals at the address. Deposits of any asset are accepted
+// whether or not it is deployed: deploying a token only lets MockChainService report its decimals.
+func (mc *MockChain) DeployToken(token types.Address, decimals uint8) {
+	mc.tokensMu.Lock()
+	defer mc.tokensMu.Unlock()
+	mc.tokens[token] = decimals
+}
+
+// tokenDecimals returns the decimals of the token, and true, if one is deployed at the address.
+func (mc *MockChain) tokenDecimals(token types.Address) (uint8, bool) {
+	mc.tokensMu.Lock()
+	defer mc.tokensMu.Unlock()
+	decimals, ok := mc.tokens[token]
+	return decimals, ok
+}
+
 // SubmitTransaction updates internal state and broadcasts events
 // unlike an ethereum blockchain, MockChain accepts go-nitro protocols.ChainTransaction
 func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
@@ -54,7 +100,29 @@ func (mc *MockChain) SubmitTransaction(tx protocols.ChainTransaction) error {
 			eventsToBroadcast = append(eventsToBroadcast, event)
 		}
 		mc.holdings[tx.ChannelId()] = types.Funds{}
+	case protocols.ChallengeTransaction:
+		finalizesAt := uint64(time.Now().Unix()) + uint64(tx.Candidate.State().ChallengeDuration)
+		mc.challenges[tx.ChannelId()] = finalizesAt
+		event := NewChallengeRegisteredEvent(tx.ChannelId(), mc.BlockNum, 0, tx.Candidate.State().VariablePart(), tx.Candidate.Signatures(), finalizesAt)
+		eventsToBroadcast = append(eventsToBroadcast, event)
+	case protocols.CheckpointTransaction:
+		if _, ok := mc.challenges[tx.ChannelId()]; ok {
+			delete(mc.challenges, tx.ChannelId())
+			eventsToBroadcast = append(eventsToBroadcast, NewChallengeClearedEvent(tx.ChannelId(), mc.BlockNum, 0, tx.Candidate.State().TurnNum))
+		}
+	case protocols.TransferAllTransaction:
+		finalizesAt, ok := mc.challenges[tx.ChannelId()]
+		if !ok || uint64(time.Now().Unix()) < finalizesAt {
+			mc.blockNumMu.Unlock()
+			return fmt.Errorf("channel %s has no finalized challenge", tx.ChannelId())
+		}
+		for assetAddress := range h {
+			event := NewAllocationUpdatedEvent(tx.ChannelId(), mc.BlockNum, 0, assetAddress, common.Big0)
+			eventsToBroadcast = append(eventsToBroadcast, event)
+		}
+		mc.holdings[tx.ChannelId()] = types.Funds{}
 	default:
+		mc.blockNumMu.Unlock()
 		return fmt.Errorf("unexpected transaction type %T", tx)
 	}
 	mc.blockNumMu.Unlock()
@@ -87,3 +155,74 @@ func (mc *MockChain) Close() error {
 	mc.out.Range(f)
 	return nil
 }
+
+// MockChainSnapshot is the state of a MockChain at some moment, which it can be restored to.
+type MockChainSnapshot struct {
+	blockNum   uint64
+	holdings   map[types.Destination]types.Funds
+	challenges map[types.Destination]uint64
+	wallets    map[types.Address]types.Address
+	tokens     map[types.Address]uint8
+}
+
+// Snapshot returns the chain's block number, holdings, challenges and deployed contracts, so that tests can restore
+// the chain to a funded baseline with Restore instead of repeating the transactions which funded it.
+func (mc *MockChain) Snapshot() MockChainSnapshot {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	mc.walletsMu.Lock()
+	defer mc.walletsMu.Unlock()
+	mc.tokensMu.Lock()
+	defer mc.tokensMu.Unlock()
+
+	s := MockChainSnapshot{
+		blockNum:   mc.BlockNum,
+		holdings:   make(map[types.Destination]types.Funds, len(mc.holdings)),
+		challenges: make(map[types.Destination]uint64, len(mc.challenges)),
+		wallets:    make(map[types.Address]types.Address, len(mc.wallets)),
+		tokens:     make(map[types.Address]uint8, len(mc.tokens)),
+	}
+	for id, h := range mc.holdings {
+		s.holdings[id] = h.Clone()
+	}
+	for id, finalizesAt := range mc.challenges {
+		s.challenges[id] = finalizesAt
+	}
+	for wallet, owner := range mc.wallets {
+		s.wallets[wallet] = owner
+	}
+	for token, decimals := range mc.tokens {
+		s.tokens[token] = decimals
+	}
+	return s
+}
+
+// Restore returns the chain to the snapshot. Subscriptions to events are kept, but no events are broadcast for the
+// changes: the stores of the nodes using the chain should be restored to the same moment, see store.RestoreDurableStores.
+// The snapshot is not modified, so it can be restored again.
+func (mc *MockChain) Restore(s MockChainSnapshot) {
+	mc.blockNumMu.Lock()
+	defer mc.blockNumMu.Unlock()
+	mc.walletsMu.Lock()
+	defer mc.walletsMu.Unlock()
+	mc.tokensMu.Lock()
+	defer mc.tokensMu.Unlock()
+
+	mc.BlockNum = s.blockNum
+	mc.holdings = make(map[types.Destination]types.Funds, len(s.holdings))
+	for id, h := range s.holdings {
+		mc.holdings[id] = h.Clone()
+	}
+	mc.challenges = make(map[types.Destination]uint64, len(s.challenges))
+	for id, finalizesAt := range s.challenges {
+		mc.challenges[id] = finalizesAt
+	}
+	mc.wallets = make(map[types.Address]types.Address, len(s.wallets))
+	for wallet, owner := range s.wallets {
+		mc.wallets[wallet] = owner
+	}
+	mc.tokens = make(map[types.Address]uint8, len(s.tokens))
+	for token, decimals := range s.tokens {
+		mc.tokens[token] = decimals
+	}
+}
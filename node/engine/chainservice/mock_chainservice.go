@@ -1,9 +1,13 @@
 package chainservice
 
 import (
+	"context"
+	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
@@ -12,11 +16,15 @@ import (
 type MockChainService struct {
 	chain     *MockChain
 	eventFeed <-chan Event
+	address   types.Address
+
+	walletMu       sync.Mutex
+	walletBalances map[types.Address]*big.Int
 }
 
 // NewMockChainService returns a new MockChainService.
 func NewMockChainService(chain *MockChain, address common.Address) *MockChainService {
-	mc := MockChainService{chain: chain}
+	mc := MockChainService{chain: chain, address: address}
 	mc.eventFeed = chain.SubscribeToEvents(address)
 	return &mc
 }
@@ -52,6 +60,65 @@ func (mc *MockChainService) GetLastConfirmedBlockNum() uint64 {
 	return blockNum
 }
 
+// SetWalletBalance sets the balance of asset reported by WalletBalance. Deposits do not reduce it.
+func (mc *MockChainService) SetWalletBalance(asset types.Address, balance *big.Int) {
+	mc.walletMu.Lock()
+	defer mc.walletMu.Unlock()
+	if mc.walletBalances == nil {
+		mc.walletBalances = make(map[types.Address]*big.Int)
+	}
+	mc.walletBalances[asset] = balance
+}
+
+// WalletAddress returns the address the service was constructed with.
+func (mc *MockChainService) WalletAddress() types.Address {
+	return mc.address
+}
+
+// WalletBalance returns the balance set with SetWalletBalance, or zero.
+func (mc *MockChainService) WalletBalance(_ context.Context, asset types.Address) (*big.Int, error) {
+	mc.walletMu.Lock()
+	defer mc.walletMu.Unlock()
+	if balance, ok := mc.walletBalances[asset]; ok {
+		return new(big.Int).Set(balance), nil
+	}
+	return big.NewInt(0), nil
+}
+
+// DepositGasCost returns zero, since the mock chain does not charge for gas.
+func (mc *MockChainService) DepositGasCost(_ context.Context, _ types.Address) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+// TokenDecimals returns the decimals of a token deployed with MockChain.DeployToken, and an error for any other asset
+// (including the native token), so that nodes on the mock chain only report decimals when a test asks for them.
+func (mc *MockChainService) TokenDecimals(_ context.Context, asset types.Address) (uint8, error) {
+	decimals, ok := mc.chain.tokenDecimals(asset)
+	if !ok {
+		return 0, fmt.Errorf("no token is deployed at %s", asset)
+	}
+	return decimals, nil
+}
+
+// IsContract returns true if a contract wallet has been deployed at the address with MockChain.DeployContractWallet.
+func (mc *MockChainService) IsContract(_ context.Context, address types.Address) (bool, error) {
+	_, ok := mc.chain.contractWalletOwner(address)
+	return ok, nil
+}
+
+// IsValidSignature returns true if the signature on the hash was made by the owner of the contract wallet.
+func (mc *MockChainService) IsValidSignature(_ context.Context, wallet types.Address, hash common.Hash, signature []byte) (bool, error) {
+	owner, ok := mc.chain.contractWalletOwner(wallet)
+	if !ok || len(signature) != 65 {
+		return false, nil
+	}
+	signer, err := nc.RecoverDigestSigner(hash, nc.SplitSignature(signature))
+	if err != nil {
+		return false, nil
+	}
+	return signer == owner, nil
+}
+
 func (mc *MockChainService) Close() error {
 	return nil
 }
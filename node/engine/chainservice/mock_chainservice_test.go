@@ -69,3 +69,33 @@ func checkReceivedEventIsValid(t *testing.T, receivedEvent Event, holdings types
 		t.Fatalf(`holdings mismatch: expected %v but got %v`, holdings[depositEvent.Asset], depositEvent.NowHeld)
 	}
 }
+
+func TestMockChainSnapshot(t *testing.T) {
+	a := types.Address(common.HexToAddress(`a`))
+	chain := NewMockChain()
+	chainService := NewMockChainService(chain, a)
+	eventFeed := chainService.EventFeed()
+
+	channelId := types.Destination(common.HexToHash(`4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d`))
+	deposit := types.Funds{common.HexToAddress("0x00"): big.NewInt(1)}
+	if err := chainService.SendTransaction(protocols.NewDepositTransaction(channelId, deposit)); err != nil {
+		t.Fatal(err)
+	}
+	<-eventFeed
+
+	snapshot := chain.Snapshot()
+	blockNum := chainService.GetLastConfirmedBlockNum()
+
+	// Changes made after the snapshot are undone by restoring it, as many times as it is restored
+	for i := 0; i < 2; i++ {
+		if err := chainService.SendTransaction(protocols.NewDepositTransaction(channelId, deposit)); err != nil {
+			t.Fatal(err)
+		}
+		checkReceivedEventIsValid(t, <-eventFeed, deposit.Add(deposit), channelId)
+
+		chain.Restore(snapshot)
+		if got := chainService.GetLastConfirmedBlockNum(); got != blockNum {
+			t.Fatalf("expected the block number %d to be restored, got %d", blockNum, got)
+		}
+	}
+}
@@ -62,8 +62,7 @@ func NewSimulatedBackendChainService(sim SimulatedChain, bindings Bindings,
 	txSigner *bind.TransactOpts,
 ) (ChainService, error) {
 	ethChainService, err := newEthChainService(sim, 0,
-		bindings.Adjudicator.Contract,
-		bindings.Adjudicator.Address,
+		&NitroAdjudicatorBinding{na: bindings.Adjudicator.Contract, address: bindings.Adjudicator.Address},
 		bindings.ConsensusApp.Address,
 		bindings.VirtualPaymentApp.Address,
 		txSigner)
@@ -76,15 +75,22 @@ func NewSimulatedBackendChainService(sim SimulatedChain, bindings Bindings,
 
 // SendTransaction sends the transaction and blocks until it has been mined.
 func (sbcs *SimulatedBackendChainService) SendTransaction(tx protocols.ChainTransaction) error {
-	err := sbcs.EthChainService.SendTransaction(tx)
+	_, err := sbcs.SendTrackedTransaction(tx, false)
+	return err
+}
+
+// SendTrackedTransaction sends the transaction and blocks until it has been mined, returning the hashes of the chain
+// transactions submitted for it.
+func (sbcs *SimulatedBackendChainService) SendTrackedTransaction(tx protocols.ChainTransaction, fillNonceGap bool) ([]common.Hash, error) {
+	hashes, err := sbcs.EthChainService.SendTrackedTransaction(tx, fillNonceGap)
 	if err != nil {
-		return err
+		return hashes, err
 	}
 	sbcs.sim.Commit()
 	// Mint two additional blocks to satisfy REQUIRED_BLOCK_CONFIRMATIONS.
 	sbcs.sim.Commit()
 	sbcs.sim.Commit()
-	return nil
+	return hashes, nil
 }
 
 // SetupSimulatedBackend creates a new SimulatedBackend with the supplied number of transacting accounts, deploys the Nitro Adjudicator and returns both.
@@ -2,6 +2,7 @@ package chainservice
 
 import (
 	"bytes"
+	"context"
 	"log/slog"
 	"math/big"
 	"testing"
@@ -104,7 +105,7 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	// Check that the received events matches the expected event
 	receivedEvent = <-out
 	crEvent := receivedEvent.(ChallengeRegisteredEvent)
-	expectedChallengeRegisteredEvent := NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures)
+	expectedChallengeRegisteredEvent := NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures, crEvent.finalizesAt)
 	if diff := cmp.Diff(expectedChallengeRegisteredEvent, crEvent, cmp.AllowUnexported(ChallengeRegisteredEvent{}, commonEvent{}, big.Int{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
@@ -190,7 +191,7 @@ func TestSimulatedBackendChainService(t *testing.T) {
 	// Check events from cs2 to ensure they match the expected values
 	receivedEvent = <-cs2.EventFeed()
 	crEvent = receivedEvent.(ChallengeRegisteredEvent)
-	expectedChallengeRegisteredEvent = NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures)
+	expectedChallengeRegisteredEvent = NewChallengeRegisteredEvent(concludeState.ChannelId(), challengeBlockNum, crEvent.TxIndex(), crEvent.candidate, crEvent.candidateSignatures, crEvent.finalizesAt)
 	if diff := cmp.Diff(expectedChallengeRegisteredEvent, crEvent, cmp.AllowUnexported(ChallengeRegisteredEvent{}, commonEvent{}, big.Int{})); diff != "" {
 		t.Fatalf("Received event did not match expectation; (-want +got):\n%s", diff)
 	}
@@ -235,3 +236,97 @@ func closeSimulatedChain(t *testing.T, chain SimulatedChain) {
 		t.Fatal(err)
 	}
 }
+
+func TestWalletBalance(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(2)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	wallet, ok := cs.(FundingWallet)
+	if !ok {
+		t.Fatal("expected the chain service to report wallet balances")
+	}
+	ctx := context.Background()
+
+	native, err := wallet.WalletBalance(ctx, types.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := new(big.Int).SetString("10000000000000000000", 10)
+	if native.Cmp(expected) != 0 {
+		t.Fatalf("expected native balance %s, got %s", expected, native)
+	}
+	token, err := wallet.WalletBalance(ctx, bindings.Token.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Cmp(big.NewInt(10_000_000)) != 0 {
+		t.Fatalf("expected token balance 10000000, got %s", token)
+	}
+
+	nativeGas, err := wallet.DepositGasCost(ctx, types.Address{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenGas, err := wallet.DepositGasCost(ctx, bindings.Token.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nativeGas.Sign() <= 0 || tokenGas.Cmp(nativeGas) <= 0 {
+		t.Fatalf("expected depositing tokens to cost more gas than a positive native deposit, got %s and %s", tokenGas, nativeGas)
+	}
+}
+
+func TestTransactionTracker(t *testing.T) {
+	sim, bindings, ethAccounts, err := SetupSimulatedBackend(1)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err := NewSimulatedBackendChainService(sim, bindings, ethAccounts[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cs.Close()
+
+	tracker, ok := cs.(TransactionTracker)
+	if !ok {
+		t.Fatal("expected the chain service to track transactions")
+	}
+	ctx := context.Background()
+
+	// A token deposit is submitted as an approval and a deposit
+	channelId := types.Destination(common.HexToHash("0x4ebd366d014a173765ba1e50f284c179ade31f20441bec41664712aac6cc461d"))
+	deposit := types.Funds{bindings.Token.Address: big.NewInt(5)}
+	hashes, err := tracker.SendTrackedTransaction(protocols.NewDepositTransaction(channelId, deposit), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hashes) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(hashes))
+	}
+	for _, hash := range hashes {
+		status, err := tracker.TransactionStatus(ctx, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != TxMined {
+			t.Errorf("expected transaction %s to be mined, got status %d", hash, status)
+		}
+	}
+
+	status, err := tracker.TransactionStatus(ctx, common.HexToHash("0x01"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != TxDropped {
+		t.Errorf("expected an unknown transaction to be dropped, got status %d", status)
+	}
+}
@@ -0,0 +1,70 @@
+package chainservice
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrVirtualOnly is returned when a virtual-only node is asked to submit a transaction to the chain.
+const ErrVirtualOnly = types.ConstError("virtual-only node cannot submit chain transactions")
+
+// VirtualOnlyChainService adheres to the ChainService interface for nodes which have no connection to a chain.
+// Such a node only pays and is paid through virtual channels, funded by ledger channels with a hub which are
+// funded externally (established out-of-band), so it never needs to observe or transact on the chain.
+type VirtualOnlyChainService struct {
+	chainId    *big.Int
+	caAddress  types.Address
+	vpaAddress types.Address
+	eventFeed  chan Event
+}
+
+// NewVirtualOnlyChainService returns a new VirtualOnlyChainService. The chain id and app addresses are those used by
+// the node's counterparties, so that the channels it proposes are compatible with theirs.
+func NewVirtualOnlyChainService(chainId *big.Int, caAddress, vpaAddress types.Address) *VirtualOnlyChainService {
+	return &VirtualOnlyChainService{
+		chainId:    new(big.Int).Set(chainId),
+		caAddress:  caAddress,
+		vpaAddress: vpaAddress,
+		eventFeed:  make(chan Event),
+	}
+}
+
+// IsVirtualOnly returns true if cs has no connection to a chain.
+func IsVirtualOnly(cs ChainService) bool {
+	_, ok := cs.(*VirtualOnlyChainService)
+	return ok
+}
+
+// SendTransaction refuses to submit the transaction.
+func (vs *VirtualOnlyChainService) SendTransaction(tx protocols.ChainTransaction) error {
+	return fmt.Errorf("channel %s: %w", tx.ChannelId(), ErrVirtualOnly)
+}
+
+func (vs *VirtualOnlyChainService) GetConsensusAppAddress() types.Address {
+	return vs.caAddress
+}
+
+func (vs *VirtualOnlyChainService) GetVirtualPaymentAppAddress() types.Address {
+	return vs.vpaAddress
+}
+
+// EventFeed returns a chan which never receives an event.
+func (vs *VirtualOnlyChainService) EventFeed() <-chan Event {
+	return vs.eventFeed
+}
+
+func (vs *VirtualOnlyChainService) GetChainId() (*big.Int, error) {
+	return new(big.Int).Set(vs.chainId), nil
+}
+
+// GetLastConfirmedBlockNum returns zero, since no blocks are ever seen.
+func (vs *VirtualOnlyChainService) GetLastConfirmedBlockNum() uint64 {
+	return 0
+}
+
+func (vs *VirtualOnlyChainService) Close() error {
+	return nil
+}
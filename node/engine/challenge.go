@@ -0,0 +1,130 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrChallengeRefused is returned when a challenge objective cannot be started for a channel.
+const ErrChallengeRefused = types.ConstError("challenge refused")
+
+// newChallengeObjective constructs a challenge objective for the request, taking the ledger channel out of use: a
+// Channel takes over its governance until the challenge is cleared.
+func (e *Engine) newChallengeObjective(request challenge.ObjectiveRequest) (*challenge.Objective, error) {
+	if _, forceMove := e.disputes.(ForceMoveDisputes); !forceMove {
+		return nil, fmt.Errorf("%w: channels are only challenged on chain with ForceMoveDisputes", ErrChallengeRefused)
+	}
+	if chainservice.IsVirtualOnly(e.chain) {
+		return nil, fmt.Errorf("%w: %w", ErrChallengeRefused, chainservice.ErrVirtualOnly)
+	}
+	co, err := challenge.NewObjective(request, e.store.GetConsensusChannelById)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrChallengeRefused, err)
+	}
+	err = e.store.DestroyConsensusChannel(request.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+	return &co, nil
+}
+
+// trackChallengeDeadline notes when the challenge of a challenge objective waiting for it to finalize does so, so that
+// the objective is cranked again then, and forgets it once the objective is no longer waiting.
+func (e *Engine) trackChallengeDeadline(o protocols.Objective, waitingFor protocols.WaitingFor) {
+	co, ok := o.(*challenge.Objective)
+	if !ok {
+		return
+	}
+	finalizesAt, registered := co.FinalizesAt()
+	if waitingFor != challenge.WaitingForFinalization || !registered {
+		delete(e.challengeDeadlines, o.Id())
+		return
+	}
+	e.challengeDeadlines[o.Id()] = finalizesAt
+}
+
+// crankFinalizedChallenges cranks the challenge objectives whose challenges have finalized, so that they withdraw the
+// channel's funds.
+func (e *Engine) crankFinalizedChallenges() (EngineEvent, error) {
+	outgoing := EngineEvent{}
+	now := e.now()
+	for id, finalizesAt := range e.challengeDeadlines {
+		if now.Before(finalizesAt) {
+			continue
+		}
+		delete(e.challengeDeadlines, id)
+
+		o, err := e.store.GetObjectiveById(id)
+		if err != nil {
+			return outgoing, err
+		}
+		if o.GetStatus() != protocols.Approved {
+			continue
+		}
+		e.logger.Info("Challenge has finalized, withdrawing the channel's funds", logging.WithObjectiveIdAttribute(id))
+		progress, err := e.attemptProgress(o)
+		if err != nil {
+			return outgoing, err
+		}
+		outgoing.Merge(progress)
+	}
+	return outgoing, nil
+}
+
+// restoreConsensusChannelIfChallengeCleared returns the ledger channel of a completed challenge objective whose challenge
+// was cleared to use as a ConsensusChannel.
+func (e *Engine) restoreConsensusChannelIfChallengeCleared(o protocols.Objective) error {
+	co, ok := o.(*challenge.Objective)
+	if !ok || !co.Cleared() {
+		return nil
+	}
+	c, err := co.CreateConsensusChannel()
+	if err != nil {
+		return fmt.Errorf("could not restore consensus channel for objective %s: %w", o.Id(), err)
+	}
+	err = e.store.SetConsensusChannel(c)
+	if err != nil {
+		return fmt.Errorf("could not store consensus channel for objective %s: %w", o.Id(), err)
+	}
+	return e.store.DestroyChannel(c.Id)
+}
+
+// answerChallenge answers a challenge registered by a counterparty with a state older than our latest supported state,
+// by checkpointing ours to clear it, if the DisputeAdapter is a DisputeResponder. Challenges with our latest state are
+// left to finalize.
+func (e *Engine) answerChallenge(event chainservice.ChallengeRegisteredEvent) error {
+	responder, ok := e.disputes.(DisputeResponder)
+	if !ok {
+		return nil
+	}
+	id := event.ChannelID()
+	var latest state.SignedState
+	if ledger, err := e.store.GetConsensusChannelById(id); err == nil {
+		latest = ledger.SupportedSignedState()
+	} else if c, ok := e.store.GetChannelById(id); ok {
+		if latest, err = c.LatestSupportedSignedState(); err != nil {
+			return nil
+		}
+	} else {
+		return nil
+	}
+	if latest.State().TurnNum <= event.TurnNum() {
+		return nil
+	}
+
+	e.logger.Warn("Answering a challenge with a later state", logging.WithChannelIdAttribute(id), "challenged", event.TurnNum(), "latest", latest.State().TurnNum)
+	txs, err := responder.Checkpoint(id, latest)
+	if err != nil {
+		return err
+	}
+	if len(txs) > 0 && chainservice.IsVirtualOnly(e.chain) {
+		return chainservice.ErrVirtualOnly
+	}
+	return e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: txs})
+}
@@ -0,0 +1,256 @@
+package engine
+
+import (
+	"sync"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ConcurrencyLimits bounds the number of objectives proposed by peers which may be in progress at once.
+// A zero limit is unlimited.
+type ConcurrencyLimits struct {
+	// PerPeer is the maximum number of in-progress objectives proposed by any one peer
+	PerPeer int
+	// Global is the maximum number of in-progress objectives proposed by all peers together
+	Global int
+	// QueueExcess queues objectives which exceed a limit until an in-progress objective completes, rather than rejecting them
+	QueueExcess bool
+}
+
+// ConcurrencyPolicy may optionally be implemented by a PolicyMaker to limit the number of objectives proposed by peers
+// which are in progress at once, so that a single peer cannot overwhelm us. An objective is attributed to the peer whose
+// message first proposed it to us. Objectives we propose ourselves are not limited.
+// If the PolicyMaker does not implement it, approved objectives are never limited.
+type ConcurrencyPolicy interface {
+	ConcurrencyLimits() ConcurrencyLimits
+}
+
+// ConcurrencyStats reports on objectives proposed by peers, and how they have been limited.
+type ConcurrencyStats struct {
+	// InProgress is the number of approved objectives proposed by each peer which have not yet completed
+	InProgress map[types.Address]int
+	// Queued is the number of objectives currently waiting for an in-progress objective to complete
+	Queued int
+	// TotalQueued is the number of objectives which have been queued since the engine started
+	TotalQueued uint64
+	// TotalRejected is the number of objectives which have been rejected for exceeding a limit since the engine started
+	TotalRejected uint64
+}
+
+// admission is the outcome of checking an objective against the ConcurrencyLimits.
+type admission int
+
+const (
+	startObjective admission = iota
+	queueObjective
+	rejectObjective
+)
+
+// queuedObjective is an objective waiting for capacity to become available.
+type queuedObjective struct {
+	id   protocols.ObjectiveId
+	peer types.Address
+	exit bool // whether the objective exits a channel, so that it starts ahead of objectives which fund channels
+}
+
+// concurrency tracks objectives proposed by peers, which may be read from outside the engine's run loop.
+type concurrency struct {
+	mu            sync.Mutex
+	inProgress    map[protocols.ObjectiveId]types.Address
+	queue         []queuedObjective
+	totalQueued   uint64
+	totalRejected uint64
+}
+
+func newConcurrency() *concurrency {
+	return &concurrency{inProgress: make(map[protocols.ObjectiveId]types.Address)}
+}
+
+// hasCapacity returns true if another objective proposed by peer would be within the limits.
+// The caller must hold the lock.
+func (c *concurrency) hasCapacity(peer types.Address, limits ConcurrencyLimits) bool {
+	if limits.Global > 0 && len(c.inProgress) >= limits.Global {
+		return false
+	}
+	if limits.PerPeer > 0 {
+		count := 0
+		for _, p := range c.inProgress {
+			if p == peer {
+				count++
+			}
+		}
+		if count >= limits.PerPeer {
+			return false
+		}
+	}
+	return true
+}
+
+// isQueued returns true if the objective is waiting for capacity to become available.
+func (c *concurrency) isQueued(id protocols.ObjectiveId) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, q := range c.queue {
+		if q.id == id {
+			return true
+		}
+	}
+	return false
+}
+
+// concurrencyLimits returns the limits of the policymaker, if it implements ConcurrencyPolicy.
+func (e *Engine) concurrencyLimits() (ConcurrencyLimits, bool) {
+	cp, ok := e.policymaker.(ConcurrencyPolicy)
+	if !ok {
+		return ConcurrencyLimits{}, false
+	}
+	return cp.ConcurrencyLimits(), true
+}
+
+// admitObjective decides whether an objective proposed by peer, which the policymaker has approved, may begin.
+// Admitted objectives count towards the limits until they complete. While exits are prioritized, they are admitted
+// regardless of the limits.
+func (e *Engine) admitObjective(objective protocols.Objective, peer types.Address) admission {
+	limits, ok := e.concurrencyLimits()
+	if !ok {
+		return startObjective
+	}
+	id := objective.Id()
+	exit := isExitObjective(objective)
+	prioritized := exit && e.PrioritizingExits()
+
+	e.concurrency.mu.Lock()
+	defer e.concurrency.mu.Unlock()
+
+	// Earlier objectives in the queue take priority over a new one
+	if prioritized || (len(e.concurrency.queue) == 0 && e.concurrency.hasCapacity(peer, limits)) {
+		e.concurrency.inProgress[id] = peer
+		return startObjective
+	}
+	if limits.QueueExcess {
+		e.concurrency.queue = append(e.concurrency.queue, queuedObjective{id, peer, exit})
+		e.concurrency.totalQueued++
+		return queueObjective
+	}
+	e.concurrency.totalRejected++
+	return rejectObjective
+}
+
+// approve approves the objective, destroying the consensus channel of a direct defund or ledger top-up objective so that
+// it cannot be used (a Channel will now take over governance).
+func (e *Engine) approve(objective protocols.Objective) (protocols.Objective, error) {
+	objective = objective.Approve()
+
+	switch o := objective.(type) {
+	case *directdefund.Objective:
+		err := e.store.DestroyConsensusChannel(o.C.Id)
+		if err != nil {
+			return nil, err
+		}
+	case *ledgertopup.Objective:
+		err := e.store.DestroyConsensusChannel(o.C.Id)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return objective, nil
+}
+
+// releaseObjectives frees the capacity held by the completed objectives, and begins any queued objectives which are now
+// within the limits. Queued exits begin ahead of queued funding, and regardless of the limits while exits are
+// prioritized.
+func (e *Engine) releaseObjectives(completed []protocols.Objective) (EngineEvent, error) {
+	limits, ok := e.concurrencyLimits()
+	if !ok {
+		return EngineEvent{}, nil
+	}
+	prioritized := e.PrioritizingExits()
+
+	e.concurrency.mu.Lock()
+	for _, o := range completed {
+		delete(e.concurrency.inProgress, o.Id())
+	}
+	admitted := []protocols.ObjectiveId{}
+	remaining := []queuedObjective{}
+	for _, q := range exitsFirst(e.concurrency.queue) {
+		if (q.exit && prioritized) || e.concurrency.hasCapacity(q.peer, limits) {
+			e.concurrency.inProgress[q.id] = q.peer
+			admitted = append(admitted, q.id)
+		} else {
+			remaining = append(remaining, q)
+		}
+	}
+	e.concurrency.queue = remaining
+	e.concurrency.mu.Unlock()
+
+	outgoing := EngineEvent{}
+	for _, id := range admitted {
+		objective, err := e.store.GetObjectiveById(id)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		if objective.GetStatus() != protocols.Unapproved {
+			// The objective was rejected by a peer while it was queued
+			e.concurrency.mu.Lock()
+			delete(e.concurrency.inProgress, id)
+			e.concurrency.mu.Unlock()
+			continue
+		}
+
+		e.logger.Info("Starting queued objective", logging.WithObjectiveIdAttribute(id))
+		objective, err = e.approve(objective)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		progressEvent, err := e.attemptProgress(objective)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		outgoing.Merge(progressEvent)
+	}
+
+	// Objectives which completed as soon as they started free capacity in turn
+	if len(outgoing.CompletedObjectives) > 0 {
+		more, err := e.releaseObjectives(outgoing.CompletedObjectives)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		outgoing.Merge(more)
+	}
+	return outgoing, nil
+}
+
+// exitsFirst returns the queue reordered so that exits come before other objectives, each in the order they were queued.
+func exitsFirst(queue []queuedObjective) []queuedObjective {
+	ordered := make([]queuedObjective, 0, len(queue))
+	for _, exits := range []bool{true, false} {
+		for _, q := range queue {
+			if q.exit == exits {
+				ordered = append(ordered, q)
+			}
+		}
+	}
+	return ordered
+}
+
+// ConcurrencyStats reports on the objectives proposed by peers which are in progress or queued.
+func (e *Engine) ConcurrencyStats() ConcurrencyStats {
+	e.concurrency.mu.Lock()
+	defer e.concurrency.mu.Unlock()
+
+	stats := ConcurrencyStats{
+		InProgress:    make(map[types.Address]int),
+		Queued:        len(e.concurrency.queue),
+		TotalQueued:   e.concurrency.totalQueued,
+		TotalRejected: e.concurrency.totalRejected,
+	}
+	for _, peer := range e.concurrency.inProgress {
+		stats.InProgress[peer]++
+	}
+	return stats
+}
@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// contractWalletDetectionTimeout bounds the call made to the chain to check whether a participant is a contract wallet.
+const contractWalletDetectionTimeout = 10 * time.Second
+
+// contractWallets detects which channel participants are smart contract wallets, whose state signatures must be
+// verified with ERC-1271 through the chain service rather than recovered.
+//
+// Participants are checked off the engine's run loop, and the result of each check is cached. Until a participant's
+// check completes, its signatures are verified with a walletDetection, which waits for it.
+type contractWallets struct {
+	// validator is nil if the chain service cannot verify the signatures of contract wallets
+	validator chainservice.SignatureValidator
+	scheme    chainservice.ERC1271Scheme
+	// schemes is the engine's registry, in which the scheme of each contract wallet is set
+	schemes *nc.Schemes
+	timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu sync.Mutex
+	// detections holds the check of each participant we have checked, or are checking
+	detections map[types.Address]*walletDetection
+}
+
+func newContractWallets(chain chainservice.ChainService, schemes *nc.Schemes) *contractWallets {
+	ctx, cancel := context.WithCancel(context.Background())
+	cw := &contractWallets{
+		schemes:    schemes,
+		timeout:    contractWalletDetectionTimeout,
+		ctx:        ctx,
+		cancel:     cancel,
+		detections: make(map[types.Address]*walletDetection),
+	}
+	if validator, ok := chain.(chainservice.SignatureValidator); ok {
+		cw.validator = validator
+		cw.scheme = chainservice.NewERC1271Scheme(validator)
+	}
+	return cw
+}
+
+// walletDetection is the signature scheme of a participant while we check whether it is a contract wallet.
+type walletDetection struct {
+	done chan struct{}
+	// scheme is the scheme of the participant, set once the check completes without error
+	scheme nc.SignatureScheme
+	err    error
+}
+
+func (d *walletDetection) Sign(message []byte, secretKey []byte) (nc.Signature, error) {
+	<-d.done
+	if d.err != nil {
+		return nc.Signature{}, d.err
+	}
+	return d.scheme.Sign(message, secretKey)
+}
+
+// Verify waits for the check of the signer, which is bounded by contractWalletDetectionTimeout, then verifies the
+// signature with the signer's scheme.
+func (d *walletDetection) Verify(message []byte, sig nc.Signature, signer types.Address) error {
+	<-d.done
+	if d.err != nil {
+		return fmt.Errorf("could not check whether %s is a contract wallet: %w", signer, d.err)
+	}
+	return d.scheme.Verify(message, sig, signer)
+}
+
+// detectContractWallets starts checking whether each of the participants not yet checked is a contract wallet. Once
+// the check completes, the signatures of contract wallets are verified with ERC-1271. We may be a contract wallet
+// ourselves, signing with the key of an owner.
+func (e *Engine) detectContractWallets(participants []types.Address) {
+	cw := e.contractWallets
+	if cw.validator == nil {
+		return
+	}
+	for _, p := range participants {
+		cw.detect(p, e.logger)
+	}
+}
+
+// detect starts checking whether the participant is a contract wallet, unless it has been checked already.
+func (cw *contractWallets) detect(p types.Address, logger *slog.Logger) {
+	cw.mu.Lock()
+	defer cw.mu.Unlock()
+	if _, ok := cw.detections[p]; ok {
+		return
+	}
+	d := &walletDetection{done: make(chan struct{})}
+	cw.detections[p] = d
+	cw.schemes.SetSignerScheme(p, d)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(cw.ctx, cw.timeout)
+		defer cancel()
+		isContract, err := cw.validator.IsContract(ctx, p)
+
+		cw.mu.Lock()
+		defer cw.mu.Unlock()
+		switch {
+		case err != nil:
+			// The participant is checked again when it next joins an objective. Until then its signatures are recovered,
+			// so those of a contract wallet are refused.
+			logger.Warn("Could not check whether participant is a contract wallet", "participant", p, "error", err)
+			d.err = err
+			delete(cw.detections, p)
+			cw.schemes.SetSignerScheme(p, nil)
+		case isContract:
+			logger.Info("Verifying the signatures of contract wallet with ERC-1271", "wallet", p)
+			d.scheme = cw.scheme
+			cw.schemes.SetSignerScheme(p, cw.scheme)
+		default:
+			d.scheme = cw.schemes.Default()
+			cw.schemes.SetSignerScheme(p, nil)
+		}
+		close(d.done)
+	}()
+}
+
+// close abandons the checks in progress.
+func (cw *contractWallets) close() {
+	cw.cancel()
+}
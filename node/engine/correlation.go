@@ -0,0 +1,50 @@
+package engine
+
+import (
+	"log/slog"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// correlate records that the objective is part of the flow with the correlation id, unless it is already part of one.
+// The objective's log lines, and the messages it sends, carry the id until it completes.
+func (e *Engine) correlate(id protocols.ObjectiveId, correlationId string) {
+	if correlationId == "" {
+		return
+	}
+	if _, ok := e.correlationIds[id]; !ok {
+		e.correlationIds[id] = correlationId
+	}
+}
+
+// objectiveLogger returns the engine's logger, with the objective's id and the correlation id of its flow, if any.
+func (e *Engine) objectiveLogger(id protocols.ObjectiveId) *slog.Logger {
+	return logging.LoggerWithCorrelationId(e.logger, e.correlationIds[id]).With(logging.WithObjectiveIdAttribute(id))
+}
+
+// correlateMessages sets the correlation id of the objective's flow on the messages it sends.
+func (e *Engine) correlateMessages(id protocols.ObjectiveId, sideEffects *protocols.SideEffects) {
+	sideEffects.MessagesToSend = correlatedMessages(sideEffects.MessagesToSend, e.correlationIds[id])
+}
+
+// correlatedMessages sets the correlation id on the messages, unless it is empty.
+func correlatedMessages(msgs []protocols.Message, correlationId string) []protocols.Message {
+	if correlationId == "" {
+		return msgs
+	}
+	for i := range msgs {
+		msgs[i].CorrelationId = correlationId
+	}
+	return msgs
+}
+
+// forgetCorrelations drops the correlation ids of the objectives which have finished.
+func (e *Engine) forgetCorrelations(res EngineEvent) {
+	for _, o := range res.CompletedObjectives {
+		delete(e.correlationIds, o.Id())
+	}
+	for _, id := range res.FailedObjectives {
+		delete(e.correlationIds, id)
+	}
+}
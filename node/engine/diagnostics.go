@@ -0,0 +1,61 @@
+package engine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// recentChainEventsCapacity is the number of chain events remembered for diagnostics.
+const recentChainEventsCapacity = 20
+
+// ChainEventRecord summarises a chain event handled by the engine.
+type ChainEventRecord struct {
+	Type      string
+	ChannelId types.Destination
+	BlockNum  uint64
+	Handled   time.Time
+}
+
+// diagnostics holds information about the engine's recent activity, which may be read from outside the engine's run loop.
+type diagnostics struct {
+	mu          sync.Mutex
+	chainEvents []ChainEventRecord
+}
+
+// recordChainEvent remembers the chain event, forgetting the oldest if recentChainEventsCapacity is reached.
+func (d *diagnostics) recordChainEvent(event chainservice.Event) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.chainEvents = append(d.chainEvents, ChainEventRecord{
+		Type:      fmt.Sprintf("%T", event),
+		ChannelId: event.ChannelID(),
+		BlockNum:  event.BlockNum(),
+		Handled:   time.Now(),
+	})
+	if len(d.chainEvents) > recentChainEventsCapacity {
+		d.chainEvents = d.chainEvents[len(d.chainEvents)-recentChainEventsCapacity:]
+	}
+}
+
+// RecentChainEvents returns the most recent chain events handled by the engine, oldest first.
+func (e *Engine) RecentChainEvents() []ChainEventRecord {
+	e.diagnostics.mu.Lock()
+	defer e.diagnostics.mu.Unlock()
+
+	return append([]ChainEventRecord{}, e.diagnostics.chainEvents...)
+}
+
+// QueueDepths returns the number of items waiting in each of the engine's buffered inbound queues.
+func (e *Engine) QueueDepths() map[string]int {
+	return map[string]int{
+		"messages":        len(e.fromMsg),
+		"chainEvents":     len(e.fromChain),
+		"ledgerProposals": len(e.fromLedger),
+		"signRequests":    len(e.signRequests),
+	}
+}
@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// DisputeAdapter raises the disputes the engine makes over our channels, when it challenges a ledger channel to
+// reclaim an expired guarantee or on request. The default adapter, ForceMoveDisputes, registers a ForceMove challenge
+// with the adjudicator. Alternative mechanisms, such as an optimistic arbitration service or a dispute contract on an
+// L2, are plugged in with WithDisputeAdapter, leaving the objectives unchanged.
+type DisputeAdapter interface {
+	// Challenge disputes the channel with the given id, asking for the candidate (its latest supported state) to be
+	// enforced. signer signs on our behalf. It returns the transactions for the engine to submit to its chain
+	// service, which are none if the adapter raises the dispute itself. Transactions of types not declared in package
+	// protocols must be submitted by a chainservice.AdjudicatorBinding which understands them.
+	Challenge(id types.Destination, candidate state.SignedState, signer state.Signer) ([]protocols.ChainTransaction, error)
+}
+
+// DisputeResponder is a DisputeAdapter which can answer a dispute raised by a counterparty. The engine answers
+// counterparties' challenges which were registered with a state older than its latest supported state automatically,
+// if its DisputeAdapter implements DisputeResponder.
+type DisputeResponder interface {
+	// Checkpoint answers the dispute over the channel with the given id with the candidate, its latest supported state.
+	// It returns the transactions for the engine to submit to its chain service.
+	Checkpoint(id types.Destination, candidate state.SignedState) ([]protocols.ChainTransaction, error)
+}
+
+// ForceMoveDisputes is the DisputeAdapter challenging channels through the ForceMove protocol of the adjudicator.
+type ForceMoveDisputes struct{}
+
+func (ForceMoveDisputes) Challenge(id types.Destination, candidate state.SignedState, signer state.Signer) ([]protocols.ChainTransaction, error) {
+	challengerSig, err := signer.SignChallenge(candidate.State())
+	if err != nil {
+		return nil, err
+	}
+	return []protocols.ChainTransaction{protocols.NewChallengeTransaction(id, candidate, []state.SignedState{}, challengerSig)}, nil
+}
+
+// Option configures an Engine constructed with New.
+type Option func(*Engine)
+
+// WithDisputeAdapter makes the engine raise disputes with the adapter rather than ForceMoveDisputes.
+func WithDisputeAdapter(adapter DisputeAdapter) Option {
+	return func(e *Engine) {
+		e.disputes = adapter
+	}
+}
+
+// Checkpoint clears a ForceMove challenge with the later candidate state.
+func (ForceMoveDisputes) Checkpoint(id types.Destination, candidate state.SignedState) ([]protocols.ChainTransaction, error) {
+	return []protocols.ChainTransaction{protocols.NewCheckpointTransaction(id, candidate, []state.SignedState{})}, nil
+}
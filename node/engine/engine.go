@@ -3,18 +3,20 @@ package engine // import "github.com/statechannels/go-nitro/node/engine"
 
 import (
 	"context"
-	"crypto/sha256"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/ethereum/go-ethereum/crypto/secp256k1"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	nc "github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
 	"github.com/statechannels/go-nitro/node/engine/messageservice"
@@ -23,8 +25,10 @@ import (
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -50,11 +54,23 @@ func (e *ErrGetObjective) Error() string {
 	return fmt.Sprintf("unexpected error getting/creating objective %s: %v", e.objectiveId, e.wrappedError)
 }
 
+// ErrObjectiveTypeRefused is wrapped by the errors returned when a registered objective type refuses to construct or
+// update an objective. The engine survives them, as they are raised by code outside go-nitro.
+const ErrObjectiveTypeRefused = types.ConstError("objective type refused")
+
 // nonFatalErrors is a list of errors for which the engine should not panic
 var nonFatalErrors = []error{
 	&ErrGetObjective{},
 	store.ErrLoadVouchers,
 	directfund.ErrLedgerChannelExists,
+	chainservice.ErrVirtualOnly,
+	outcome.ErrMalformedOutcome,
+	ErrObjectiveTypeRefused,
+	ErrChallengeRefused,
+	ErrTopUpRefused,
+	ErrFundingDeferred,
+	payments.ErrSigningFailed,
+	payments.ErrWrongSigner,
 }
 
 // Engine is the imperative part of the core business logic of a go-nitro Node
@@ -62,8 +78,11 @@ type Engine struct {
 	// inbound go channels
 
 	// From API
-	ObjectiveRequestsFromAPI chan protocols.ObjectiveRequest
-	PaymentRequestsFromAPI   chan PaymentRequest
+	ObjectiveRequestsFromAPI chan APIRequest[protocols.ObjectiveRequest]
+	PaymentRequestsFromAPI   chan APIRequest[PaymentRequest]
+	QuoteRequestsFromAPI     chan APIRequest[QuoteRequest]
+	ProbeRequestsFromAPI     chan APIRequest[ProbeRequest]
+	ChallengeRequestsFromAPI chan APIRequest[ChallengeRequest]
 
 	fromChain    <-chan chainservice.Event
 	fromMsg      <-chan protocols.Message
@@ -78,16 +97,100 @@ type Engine struct {
 	store       store.Store // A Store for persisting and restoring important data
 	policymaker PolicyMaker // A PolicyMaker decides whether to approve or reject objectives
 	logger      *slog.Logger
-	vm          *payments.VoucherManager
+	vm          payments.VoucherManagerApi
+	paymentIds  *payments.PaymentIds
+
+	// awaitingDepositDepth holds directfund objectives which are waiting for a prior deposit to be buried deeply enough,
+	// so that they can be cranked again as new blocks are confirmed
+	awaitingDepositDepth map[protocols.ObjectiveId]struct{}
+
+	// fundingDeadlines holds the time by which each virtualfund objective waiting for ledger guarantees must be funded,
+	// if a ProposalTimeoutPolicy applies
+	fundingDeadlines map[protocols.ObjectiveId]time.Time
+
+	// reclaimStarted holds the time at which we started to reclaim the expired guarantees funding each virtual channel
+	reclaimStarted map[types.Destination]time.Time
+	// challengedLedgers holds the ledger channels we have challenged to reclaim expired guarantees
+	challengedLedgers map[types.Destination]struct{}
+	// challengeDeadlines holds when the challenge of each challenge objective waiting for it to finalize does so
+	challengeDeadlines map[protocols.ObjectiveId]time.Time
+	// disputes raises our challenges
+	disputes DisputeAdapter
+
+	diagnostics *diagnostics
+
+	// concurrency tracks objectives proposed by peers, if a ConcurrencyPolicy applies
+	concurrency *concurrency
+	// draining is set while the node is winding down, when exits may be prioritized (see PriorityPolicy)
+	draining *atomic.Bool
+	// peerHealth tracks how responsive and reliable each peer has been
+	peerHealth *peerHealth
+	// metrics receives measurements of the engine, such as the duration of each crank
+	metrics Metrics
+	// correlationIds holds the correlation id of the flow each running objective is part of, if any
+	correlationIds map[protocols.ObjectiveId]string
+	// paymentTimer measures the latency of payments made and received
+	paymentTimer *paymentTimer
+	// schemes verifies the signatures on the states of our channels. It is the engine's own, since the participants
+	// whose signatures are verified with a scheme of their own, such as contract wallets, depend on the engine's chain.
+	schemes *nc.Schemes
+	// contractWallets tracks which channel participants are smart contract wallets
+	contractWallets *contractWallets
+	// blocklist holds the peers whose messages we drop
+	blocklist *Blocklist
+	// signer makes our signatures: with the channel secret key of the store, unless WithSigner supplies another
+	signer Signer
+
+	// eventLog records the inputs of the engine, if WithEventLog was given
+	eventLog *eventLog
+	// clock tells the time, which is fixed at stepTime while an input is handled (see recordInput)
+	clock    func() time.Time
+	stepTime time.Time
 
 	wg     *sync.WaitGroup
 	cancel context.CancelFunc
 }
 
+// APIRequest is an envelope for a request from the API, carrying the context of the caller.
+// If the context is done before the engine handles the request, the request is abandoned.
+type APIRequest[T any] struct {
+	Ctx     context.Context
+	Request T
+}
+
+// NewAPIRequest wraps the request in an envelope with the given context.
+func NewAPIRequest[T any](ctx context.Context, request T) APIRequest[T] {
+	return APIRequest[T]{Ctx: ctx, Request: request}
+}
+
 // PaymentRequest represents a request from the API to make a payment using a channel
 type PaymentRequest struct {
 	ChannelId types.Destination
 	Amount    *big.Int
+	PaymentId string    // If set, the payment is made at most once: a repeated request resends the original voucher
+	Context   string    // Carried alongside the voucher, for the payee to correlate the payment with
+	Expiry    time.Time // If set, the time after which the payee refuses the voucher
+	Enqueued  time.Time // When the request was made, from which its latency is measured
+	// CorrelationId identifies the flow the payment is part of, in our logs and the payee's
+	CorrelationId string
+}
+
+// QuoteRequest represents a request from the API to ask an intermediary for a quote to route a virtual channel
+type QuoteRequest struct {
+	Intermediary types.Address
+	Request      protocols.QuoteRequest
+}
+
+// ProbeRequest represents a request from the API to ask a counterparty whether it would fund a channel with us
+type ProbeRequest struct {
+	Counterparty types.Address
+	Request      protocols.ProbeRequest
+}
+
+// ChallengeRequest represents a request from the API to challenge a ledger channel on chain
+type ChallengeRequest struct {
+	LedgerId types.Destination
+	Result   chan<- error // Receives the error from submitting the challenge, or nil. It should be buffered.
 }
 
 // EngineEvent is a struct that contains a list of changes caused by handling a message/chain event/api event
@@ -103,6 +206,12 @@ type EngineEvent struct {
 	LedgerChannelUpdates []query.LedgerChannelInfo
 	// PaymentChannelUpdates contains channel info for payment channels that have been updated
 	PaymentChannelUpdates []query.PaymentChannelInfo
+	// ReceivedQuotes are quotes we've received from intermediaries in response to our quote requests
+	ReceivedQuotes []protocols.Quote
+	// ReceivedProbes are probes we've received from counterparties in response to our probe requests
+	ReceivedProbes []protocols.Probe
+	// ChallengedChannels are channels of ours which a counterparty has challenged on chain
+	ChallengedChannels []types.Destination
 }
 
 // IsEmpty returns true if the EngineEvent contains no changes
@@ -111,7 +220,10 @@ func (ee *EngineEvent) IsEmpty() bool {
 		len(ee.FailedObjectives) == 0 &&
 		len(ee.ReceivedVouchers) == 0 &&
 		len(ee.LedgerChannelUpdates) == 0 &&
-		len(ee.PaymentChannelUpdates) == 0
+		len(ee.PaymentChannelUpdates) == 0 &&
+		len(ee.ReceivedQuotes) == 0 &&
+		len(ee.ReceivedProbes) == 0 &&
+		len(ee.ChallengedChannels) == 0
 }
 
 func (ee *EngineEvent) Merge(other EngineEvent) {
@@ -120,6 +232,9 @@ func (ee *EngineEvent) Merge(other EngineEvent) {
 	ee.ReceivedVouchers = append(ee.ReceivedVouchers, other.ReceivedVouchers...)
 	ee.LedgerChannelUpdates = append(ee.LedgerChannelUpdates, other.LedgerChannelUpdates...)
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, other.PaymentChannelUpdates...)
+	ee.ReceivedQuotes = append(ee.ReceivedQuotes, other.ReceivedQuotes...)
+	ee.ReceivedProbes = append(ee.ReceivedProbes, other.ReceivedProbes...)
+	ee.ChallengedChannels = append(ee.ChallengedChannels, other.ChallengedChannels...)
 }
 
 type CompletedObjectiveEvent struct {
@@ -130,15 +245,32 @@ type CompletedObjectiveEvent struct {
 type Response struct{}
 
 // NewEngine is the constructor for an Engine
-func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent)) Engine {
+func New(vm payments.VoucherManagerApi, paymentIds *payments.PaymentIds, msg messageservice.MessageService, chain chainservice.ChainService, store store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent), opts ...Option) Engine {
+	e := newEngine(vm, paymentIds, msg, chain, store, policymaker, eventHandler, opts...)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+
+	e.wg.Add(1)
+	go e.run(ctx)
+
+	return e
+}
+
+// newEngine constructs an Engine without starting it.
+func newEngine(vm payments.VoucherManagerApi, paymentIds *payments.PaymentIds, msg messageservice.MessageService, chain chainservice.ChainService, s store.Store, policymaker PolicyMaker, eventHandler func(EngineEvent), opts ...Option) Engine {
 	e := Engine{}
-	e.logger = logging.LoggerWithAddress(slog.Default(), *store.GetAddress())
-	e.store = store
+	e.logger = logging.LoggerWithAddress(logging.ModuleLogger(logging.ENGINE_MODULE), *s.GetAddress())
+	e.schemes = nc.NewSchemes(nc.EthereumScheme{})
+	e.store = store.NewSchemesStore(s, e.schemes)
 
 	e.fromLedger = make(chan consensus_channel.Proposal, 100)
 	// bind to inbound chans
-	e.ObjectiveRequestsFromAPI = make(chan protocols.ObjectiveRequest)
-	e.PaymentRequestsFromAPI = make(chan PaymentRequest)
+	e.ObjectiveRequestsFromAPI = make(chan APIRequest[protocols.ObjectiveRequest])
+	e.PaymentRequestsFromAPI = make(chan APIRequest[PaymentRequest])
+	e.QuoteRequestsFromAPI = make(chan APIRequest[QuoteRequest])
+	e.ProbeRequestsFromAPI = make(chan APIRequest[ProbeRequest])
+	e.ChallengeRequestsFromAPI = make(chan APIRequest[ChallengeRequest])
 
 	e.fromChain = chain.EventFeed()
 	e.fromMsg = msg.P2PMessages()
@@ -152,23 +284,48 @@ func New(vm *payments.VoucherManager, msg messageservice.MessageService, chain c
 	e.policymaker = policymaker
 
 	e.vm = vm
+	e.paymentIds = paymentIds
+
+	e.awaitingDepositDepth = make(map[protocols.ObjectiveId]struct{})
+	e.fundingDeadlines = make(map[protocols.ObjectiveId]time.Time)
+	e.reclaimStarted = make(map[types.Destination]time.Time)
+	e.challengedLedgers = make(map[types.Destination]struct{})
+	e.challengeDeadlines = make(map[protocols.ObjectiveId]time.Time)
+	e.diagnostics = &diagnostics{}
+	e.concurrency = newConcurrency()
+	e.draining = &atomic.Bool{}
+	e.peerHealth = newPeerHealth()
+	e.metrics = NoopMetrics{}
+	e.correlationIds = make(map[protocols.ObjectiveId]string)
+	e.paymentTimer = newPaymentTimer()
+	e.contractWallets = newContractWallets(chain, e.schemes)
+	e.disputes = ForceMoveDisputes{}
+	e.clock = time.Now
+	blocklist, err := newBlocklist(s)
+	e.checkError(err)
+	e.blocklist = blocklist
+	for _, opt := range opts {
+		opt(&e)
+	}
+	if e.signer == nil {
+		key := s.GetChannelSecretKey()
+		if key == nil {
+			panic("the store holds no channel secret key, so the engine must be given a signer")
+		}
+		e.signer = KeySigner(*key)
+	}
 
 	e.logger.Info("Constructed Engine")
 
 	e.wg = &sync.WaitGroup{}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	e.cancel = cancel
-
-	e.wg.Add(1)
-	go e.run(ctx)
-
 	return e
 }
 
 func (e *Engine) Close() error {
 	e.cancel()
 	e.wg.Wait()
+	e.contractWallets.close()
 	if err := e.msg.Close(); err != nil {
 		return err
 	}
@@ -179,49 +336,149 @@ func (e *Engine) Close() error {
 // run kicks of an infinite loop that waits for communications on the supplied channels, and handles them accordingly
 // The loop exits when the context is cancelled.
 func (e *Engine) run(ctx context.Context) {
+	deadlineTicker := time.NewTicker(time.Second)
+	defer deadlineTicker.Stop()
+
+	var collectionTicker <-chan time.Time
+	if interval := e.collectionInterval(); interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		collectionTicker = ticker.C
+	}
+
+	e.recordStart()
+
+	// Transactions dropped while the node was offline are resubmitted before the objectives which await them resume
+	err := e.reconcilePendingTransactions(ctx)
+	e.checkError(err)
+
+	res, err := e.resumeObjectives()
+	e.checkError(err)
+	if !res.IsEmpty() {
+		e.eventHandler(res)
+	}
+
 	for {
 		var res EngineEvent
 		var err error
 
 		blockTicker := time.NewTicker(15 * time.Second)
 
+		e.recordQueueDepths()
+
 		select {
 
 		case or := <-e.ObjectiveRequestsFromAPI:
-			res, err = e.handleObjectiveRequest(or)
+			res, err = e.handleObjectiveRequest(or.Ctx, or.Request)
 		case pr := <-e.PaymentRequestsFromAPI:
-			res, err = e.handlePaymentRequest(pr)
+			if e.isAbandoned(pr.Ctx, "payment request") {
+				continue
+			}
+			e.recordInput(PaymentRequestEvent, dataOf(pr.Request))
+			res, err = e.handlePaymentRequest(pr.Request)
+		case qr := <-e.QuoteRequestsFromAPI:
+			if e.isAbandoned(qr.Ctx, "quote request") {
+				continue
+			}
+			err = e.handleQuoteRequest(qr.Request)
+		case pr := <-e.ProbeRequestsFromAPI:
+			if e.isAbandoned(pr.Ctx, "probe request") {
+				continue
+			}
+			err = e.handleProbeRequest(pr.Request)
+		case cr := <-e.ChallengeRequestsFromAPI:
+			if e.isAbandoned(cr.Ctx, "challenge request") {
+				continue
+			}
+			e.recordInput(ChallengeRequestEvent, dataOf(loggedChallengeRequest{LedgerId: cr.Request.LedgerId}))
+			e.handleChallengeRequest(cr.Request)
 		case chainEvent := <-e.fromChain:
+			e.recordChainEvent(chainEvent)
+			e.recordChainEventLag(chainEvent)
 			res, err = e.handleChainEvent(chainEvent)
 		case message := <-e.fromMsg:
-			res, err = e.handleMessage(message)
+			e.recordInput(MessageEvent, dataOf(message))
+			res, err = e.handleSequencedMessage(message)
 		case proposal := <-e.fromLedger:
+			e.recordInput(ProposalEvent, nil)
 			res, err = e.handleProposal(proposal)
 		case signReq := <-e.signRequests:
 			err = e.handleSignRequest(signReq)
 		case <-blockTicker.C:
 			blockNum := e.chain.GetLastConfirmedBlockNum()
-			err = e.store.SetLastBlockNumSeen(blockNum)
+			e.recordInput(BlockEvent, dataOf(loggedBlock{BlockNum: blockNum}))
+			res, err = e.handleBlock(blockNum)
+		case <-deadlineTicker.C:
+			e.recordInput(DeadlineEvent, nil)
+			res, err = e.handleDeadlines()
+		case <-collectionTicker:
+			e.recordInput(CollectionEvent, nil)
+			err = e.collectObjectives()
 		case <-ctx.Done():
 			e.wg.Done()
 			return
 		}
 
+		res, err = e.completeStep(res, err)
+
 		// Handle errors
 		e.checkError(err)
 
+		e.peerHealth.recordOutcomes(res.CompletedObjectives, *e.store.GetAddress())
+
 		// Only send out an event if there are changes
 		if !res.IsEmpty() {
 
 			for _, obj := range res.CompletedObjectives {
-				e.logger.Info("Objective is complete & returned to API", logging.WithObjectiveIdAttribute(obj.Id()))
+				e.objectiveLogger(obj.Id()).Info("Objective is complete & returned to API")
 			}
+			e.forgetCorrelations(res)
 			e.eventHandler(res)
 		}
 
 	}
 }
 
+// handleBlock records the confirmed block number, and cranks the objectives waiting for deposits to be buried deeply
+// enough.
+func (e *Engine) handleBlock(blockNum uint64) (EngineEvent, error) {
+	if err := e.store.SetLastBlockNumSeen(blockNum); err != nil {
+		return EngineEvent{}, err
+	}
+	return e.crankObjectivesAwaitingDepositDepth()
+}
+
+// handleDeadlines withdraws expired objectives, reclaims expired guarantees and cranks finalized challenges.
+func (e *Engine) handleDeadlines() (EngineEvent, error) {
+	res, err := e.withdrawExpiredObjectives()
+	if err == nil {
+		var reclaimed EngineEvent
+		reclaimed, err = e.reclaimExpiredGuarantees()
+		res.Merge(reclaimed)
+	}
+	if err == nil {
+		var finalized EngineEvent
+		finalized, err = e.crankFinalizedChallenges()
+		res.Merge(finalized)
+	}
+	return res, err
+}
+
+// completeStep does the work which follows the handling of every input, given the changes it caused.
+func (e *Engine) completeStep(res EngineEvent, err error) (EngineEvent, error) {
+	if err == nil {
+		err = e.forgetPendingTransactions(res.CompletedObjectives)
+	}
+
+	// Completed objectives may free capacity for objectives waiting on concurrency limits
+	if err == nil {
+		var started EngineEvent
+		started, err = e.releaseObjectives(res.CompletedObjectives)
+		res.Merge(started)
+	}
+	return res, err
+}
+
 // handleProposal handles a Proposal returned to the engine from
 // a running ledger channel by pulling its corresponding objective
 // from the store and attempting progress.
@@ -229,6 +486,10 @@ func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (EngineEven
 	id := getProposalObjectiveId(proposal)
 
 	obj, err := e.store.GetObjectiveById(id)
+	if errors.Is(err, store.ErrObjectiveCollected) {
+		e.logger.Info("Ignoring proposal for collected objective", logging.WithObjectiveIdAttribute(id))
+		return EngineEvent{}, nil
+	}
 	if err != nil {
 		return EngineEvent{}, err
 	}
@@ -236,6 +497,9 @@ func (e *Engine) handleProposal(proposal consensus_channel.Proposal) (EngineEven
 		e.logger.Info("Ignoring proposal for completed objective", logging.WithObjectiveIdAttribute(id))
 		return EngineEvent{}, nil
 	}
+	if e.concurrency.isQueued(id) {
+		return EngineEvent{}, nil
+	}
 	return e.attemptProgress(obj)
 }
 
@@ -245,9 +509,7 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 		return err
 	}
 
-	hash := sha256.Sum256(recordDataBytes) // Hash the data before signing it
-	secretKey := e.store.GetChannelSecretKey()
-	signature, err := secp256k1.Sign(hash[:], *secretKey)
+	signature, err := e.signer.SignPeerRecord(recordDataBytes)
 	if err != nil {
 		return err
 	}
@@ -256,6 +518,29 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 	return nil
 }
 
+// handleSequencedMessage handles the message, unless it has been processed already, in which case it is discarded.
+// The message is recorded as processed once it has been handled successfully.
+func (e *Engine) handleSequencedMessage(message protocols.Message) (EngineEvent, error) {
+	if message.Seq == 0 {
+		return e.handleMessage(message)
+	}
+
+	seq, err := e.store.GetMessageSequence(message.From)
+	if err != nil {
+		return EngineEvent{}, err
+	}
+	if seq.Processed(message.Epoch, message.Seq) {
+		e.logger.Info("Discarding message which has already been processed", "from", message.From, "seq", message.Seq)
+		return EngineEvent{}, nil
+	}
+
+	res, err := e.handleMessage(message)
+	if err != nil {
+		return res, err
+	}
+	return res, e.store.SetMessageSequence(message.From, seq.Record(message.Epoch, message.Seq))
+}
+
 // handleMessage handles a Message from a peer go-nitro Wallet.
 // It:
 //   - reads an objective from the store,
@@ -263,35 +548,77 @@ func (e *Engine) handleSignRequest(sigReq p2pms.SignatureRequest) error {
 //   - attempts progress on the target Objective,
 //   - attempts progress on related objectives which may have become unblocked.
 func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
+	received := time.Now()
+	if e.blocklist.dropsMessage(message.From, "", "engine") {
+		e.logger.Debug("Dropping message from a blocked peer", "peer", message.From)
+		return EngineEvent{}, nil
+	}
 	e.logMessage(message, Incoming)
+	e.peerHealth.recordReceived(message, received)
 	allCompleted := EngineEvent{}
 
 	for _, payload := range message.ObjectivePayloads {
 
 		objective, err := e.getOrCreateObjective(payload)
+		if errors.Is(err, store.ErrObjectiveCollected) {
+			e.logger.Info("Ignoring payload for collected objective", logging.WithObjectiveIdAttribute(payload.ObjectiveId))
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
+		e.correlate(objective.Id(), message.CorrelationId)
 
 		if objective.GetStatus() == protocols.Unapproved {
-			e.logger.Info("Policymaker for objective", "policy-maker", e.policymaker, logging.WithObjectiveIdAttribute(objective.Id()))
-			if e.policymaker.ShouldApprove(objective) {
-				objective = objective.Approve()
-
-				ddfo, ok := objective.(*directdefund.Objective)
-				if ok {
-					// If we just approved a direct defund objective, destroy the consensus channel to prevent it being used (a Channel will now take over governance)
-					err := e.store.DestroyConsensusChannel(ddfo.C.Id)
-					if err != nil {
-						return EngineEvent{}, err
-					}
+			if e.concurrency.isQueued(objective.Id()) {
+				// Record the payload so that the objective is up to date when it is started
+				updated, err := objective.Update(payload)
+				if err != nil {
+					return EngineEvent{}, err
 				}
-			} else {
+				err = e.store.SetObjective(updated)
+				if err != nil {
+					return EngineEvent{}, err
+				}
+				continue
+			}
+
+			e.logger.Info("Policymaker for objective", "policy-maker", fmt.Sprintf("%T", e.policymaker), logging.WithObjectiveIdAttribute(objective.Id()))
+			decision := rejectObjective
+			if err := e.checkObjectiveAssets(objective); err != nil {
+				e.logger.Warn("Rejecting objective", "error", err, logging.WithObjectiveIdAttribute(objective.Id()), "peer", message.From)
+			} else if err := e.checkObjectivePeers(objective); err != nil {
+				e.logger.Warn("Rejecting objective", "error", err, logging.WithObjectiveIdAttribute(objective.Id()), "peer", message.From)
+			} else if isFundingObjective(objective) && e.PrioritizingExits() {
+				e.logger.Warn("Rejecting objective", "error", ErrFundingDeferred, logging.WithObjectiveIdAttribute(objective.Id()), "peer", message.From)
+			} else if e.policymaker.ShouldApprove(objective) {
+				decision = e.admitObjective(objective, message.From)
+			}
+
+			switch decision {
+			case startObjective:
+				objective, err = e.approve(objective)
+				if err != nil {
+					return EngineEvent{}, err
+				}
+			case queueObjective:
+				e.logger.Info("Queueing objective until concurrency limits allow", logging.WithObjectiveIdAttribute(objective.Id()), "peer", message.From)
+				updated, err := objective.Update(payload)
+				if err != nil {
+					return EngineEvent{}, err
+				}
+				err = e.store.SetObjective(updated)
+				if err != nil {
+					return EngineEvent{}, err
+				}
+				continue
+			case rejectObjective:
 				objective, sideEffects := objective.Reject()
 				err = e.store.SetObjective(objective)
 				if err != nil {
 					return EngineEvent{}, err
 				}
+				e.correlateMessages(objective.Id(), &sideEffects)
 
 				allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
 
@@ -313,6 +640,16 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		}
 
 		updatedObjective, err := objective.Update(payload)
+		if errors.Is(err, outcome.ErrMalformedOutcome) || errors.Is(err, ledgertopup.ErrUnexpectedState) {
+			// The peer proposed a state we must not sign, so the objective cannot progress
+			e.logger.Warn("Rejecting objective with a malformed state", "error", err, logging.WithObjectiveIdAttribute(objective.Id()), "peer", message.From)
+			rejected, err := e.reject(objective)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, rejected)
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -332,9 +669,26 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 	for _, entry := range message.LedgerProposals { // The ledger protocol requires us to process these proposals in turnNum order.
 		// Here we rely on the sender having packed them into the message in that order, and do not apply any checks or sorting of our own.
+		withdrawalEvent, isWithdrawal, err := e.handleWithdrawal(entry)
+		if errors.Is(err, virtualfund.ErrWithdrawalOfCompletedObjective) {
+			e.logger.Warn("Refusing withdrawal of guarantee", "target", entry.Proposal.Target(), "err", err)
+			continue
+		}
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		if isWithdrawal {
+			allCompleted.Merge(withdrawalEvent)
+			continue
+		}
+
 		id := getProposalObjectiveId(entry.Proposal)
 
 		o, err := e.store.GetObjectiveById(id)
+		if errors.Is(err, store.ErrObjectiveCollected) {
+			e.logger.Info("Ignoring proposal for collected objective", logging.WithObjectiveIdAttribute(id))
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -353,6 +707,16 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			return EngineEvent{}, err
 		}
 
+		if o.GetStatus() == protocols.Rejected || e.concurrency.isQueued(id) {
+			// The proposal may precede the withdrawal of its guarantee, or the objective may be waiting for
+			// concurrency limits to allow it to start, so we record it without making progress
+			err = e.store.SetObjective(updatedObjective)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			continue
+		}
+
 		progressEvent, err := e.attemptProgress(updatedObjective)
 		if err != nil {
 			return EngineEvent{}, err
@@ -364,6 +728,10 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 
 	for _, entry := range message.RejectedObjectives {
 		objective, err := e.store.GetObjectiveById(entry)
+		if errors.Is(err, store.ErrObjectiveCollected) {
+			e.logger.Info("Ignoring rejection of collected objective", logging.WithObjectiveIdAttribute(entry))
+			continue
+		}
 		if err != nil {
 			return EngineEvent{}, err
 		}
@@ -373,6 +741,21 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 			continue
 		}
 
+		// If a virtualfund objective is rejected while we wait for guarantees, withdraw the guarantees we proposed
+		// so that the ledger capacity is released.
+		if vfo, ok := objective.(*virtualfund.Objective); ok && vfo.Status == protocols.Approved {
+			withdrawn, sideEffects, err := vfo.Withdraw(e.signer)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			err = e.executeSideEffects(sideEffects)
+			if err != nil {
+				return EngineEvent{}, err
+			}
+			objective = withdrawn
+			delete(e.fundingDeadlines, objective.Id())
+		}
+
 		// we are rejecting due to a counterparty message notifying us of their rejection. We
 		// do not need to send a message back to that counterparty, and furthermore we assume that
 		// counterparty has already notified all other interested parties. We can therefore ignore the side effects
@@ -381,19 +764,40 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 		if err != nil {
 			return EngineEvent{}, err
 		}
+		err = e.restoreConsensusChannelAfterTopUp(objective)
+		if err != nil {
+			return EngineEvent{}, err
+		}
 
 		allCompleted.CompletedObjectives = append(allCompleted.CompletedObjectives, objective)
 	}
 
+	exhausted := []types.Destination{}
 	for _, voucher := range message.Payments {
 
 		// TODO: return the amount we paid?
-		_, _, err := e.vm.Receive(voucher)
+		_, delta, err := e.vm.Receive(voucher)
+		if errors.Is(err, payments.ErrVoucherExpired) {
+			e.logger.Warn("Ignoring expired voucher", logging.WithChannelIdAttribute(voucher.ChannelId), "expiry", voucher.Expiry, "peer", message.From)
+			continue
+		}
+		if errors.Is(err, payments.ErrVoucherReplayed) || errors.Is(err, payments.ErrVoucherNonceRequired) {
+			e.logger.Warn("Ignoring voucher without a fresh nonce", logging.WithChannelIdAttribute(voucher.ChannelId), "nonce", voucher.Nonce, "peer", message.From, "error", err)
+			continue
+		}
 
 		allCompleted.ReceivedVouchers = append(allCompleted.ReceivedVouchers, voucher)
 		if err != nil {
 			return EngineEvent{}, fmt.Errorf("error accepting payment voucher: %w", err)
 		}
+		if delta.Sign() > 0 {
+			logging.LoggerWithCorrelationId(e.logger, message.CorrelationId).Debug("Received payment", logging.WithChannelIdAttribute(voucher.ChannelId), "amount", delta)
+			e.paymentTimer.recordIncoming(voucher, received, time.Now())
+			err = e.store.AppendActivity(store.ActivityRecord{Time: e.now(), Kind: store.PaymentReceived, ChannelId: voucher.ChannelId, Amount: delta})
+			if err != nil {
+				return EngineEvent{}, err
+			}
+		}
 		c, ok := e.store.GetChannelById(voucher.ChannelId)
 		if !ok {
 			return EngineEvent{}, fmt.Errorf("could not fetch channel for voucher %+v", voucher)
@@ -411,9 +815,47 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 				return EngineEvent{}, err
 			}
 			allCompleted.PaymentChannelUpdates = append(allCompleted.PaymentChannelUpdates, info)
+
+			if e.shouldAutoDefund(c.Id, remaining) && !slices.Contains(exhausted, c.Id) {
+				exhausted = append(exhausted, c.Id)
+			}
+		}
+
+	}
+
+	for _, channelId := range exhausted {
+		e.logger.Info("Closing exhausted payment channel", logging.WithChannelIdAttribute(channelId))
+		defunding, err := e.handleObjectiveRequest(context.Background(), virtualdefund.NewObjectiveRequest(channelId))
+		if err != nil {
+			// The channel can still be closed on request
+			e.logger.Error("Could not close exhausted payment channel", logging.WithChannelIdAttribute(channelId), "error", err)
+			continue
 		}
+		allCompleted.Merge(defunding)
+	}
+
+	for _, request := range message.QuoteRequests {
+		quote := e.quote(message.From, request)
+		err := e.executeSideEffects(protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateQuoteMessage(quote, message.From)}})
+		if err != nil {
+			return EngineEvent{}, err
+		}
+	}
 
+	for _, quote := range message.Quotes {
+		// The quote is attributed to the sender of the message, regardless of what it claims.
+		quote.Intermediary = message.From
+		allCompleted.ReceivedQuotes = append(allCompleted.ReceivedQuotes, quote)
 	}
+
+	for _, request := range message.ProbeRequests {
+		probe := e.probe(message.From, request)
+		err := e.executeSideEffects(protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateProbeMessage(probe, message.From)}})
+		if err != nil {
+			return EngineEvent{}, err
+		}
+	}
+	allCompleted.ReceivedProbes = append(allCompleted.ReceivedProbes, message.Probes...)
 	return allCompleted, nil
 }
 
@@ -424,17 +866,25 @@ func (e *Engine) handleMessage(message protocols.Message) (EngineEvent, error) {
 //   - attempts progress.
 func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, error) {
 	e.logger.Info("Handling chain event", "blockNum", chainEvent.BlockNum(), "event", chainEvent)
+	e.diagnostics.recordChainEvent(chainEvent)
 	err := e.store.SetLastBlockNumSeen(chainEvent.BlockNum())
 	if err != nil {
 		return EngineEvent{}, err
 	}
 
+	detected := EngineEvent{ChallengedChannels: e.challengesAgainstUs(chainEvent)}
+	if len(detected.ChallengedChannels) > 0 {
+		if err := e.answerChallenge(chainEvent.(chainservice.ChallengeRegisteredEvent)); err != nil {
+			e.logger.Error("Could not answer a challenge", logging.WithChannelIdAttribute(chainEvent.ChannelID()), "error", err)
+		}
+	}
+
 	c, ok := e.store.GetChannelById(chainEvent.ChannelID())
 	if !ok {
 		// TODO: Right now the chain service returns chain events for ALL channels even those we aren't involved in
 		// for now we can ignore channels we aren't involved in
 		// in the future the chain service should allow us to register for specific channels
-		return EngineEvent{}, nil
+		return detected, nil
 	}
 
 	updatedChannel, err := c.UpdateWithChainEvent(chainEvent)
@@ -450,14 +900,47 @@ func (e *Engine) handleChainEvent(chainEvent chainservice.Event) (EngineEvent, e
 	objective, ok := e.store.GetObjectiveByChannelId(chainEvent.ChannelID())
 
 	if ok {
-		return e.attemptProgress(objective)
+		progress, err := e.attemptProgress(objective)
+		progress.Merge(detected)
+		return progress, err
+	}
+	return detected, nil
+}
+
+// challengesAgainstUs returns the id of the channel the event challenges, if it is a challenge registered by a
+// counterparty against a channel of ours (including a ledger channel, which is not held as a channel in the store).
+func (e *Engine) challengesAgainstUs(chainEvent chainservice.Event) []types.Destination {
+	if _, ok := chainEvent.(chainservice.ChallengeRegisteredEvent); !ok {
+		return nil
+	}
+	id := chainEvent.ChannelID()
+	if _, ours := e.challengedLedgers[id]; ours {
+		return nil
+	}
+	if o, ok := e.store.GetObjectiveByChannelId(id); ok && challenge.IsChallengeObjective(o.Id()) {
+		return nil
+	}
+	_, isChannel := e.store.GetChannelById(id)
+	_, err := e.store.GetConsensusChannelById(id)
+	if !isChannel && err != nil {
+		return nil
+	}
+	e.logger.Warn("A counterparty has challenged one of our channels", logging.WithChannelIdAttribute(id))
+	return []types.Destination{id}
+}
+
+// isAbandoned returns true (and logs) if the caller which made an API request has since cancelled it, or its deadline has passed.
+func (e *Engine) isAbandoned(ctx context.Context, description string) bool {
+	if ctx == nil || ctx.Err() == nil {
+		return false
 	}
-	return EngineEvent{}, nil
+	e.logger.Info("Abandoning "+description+" as the caller is no longer waiting", "error", ctx.Err())
+	return true
 }
 
 // handleObjectiveRequest handles an ObjectiveRequest (triggered by a client API call).
-// It will attempt to spawn a new, approved objective.
-func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEvent, error) {
+// It will attempt to spawn a new, approved objective, unless ctx is done in which case the objective is failed without being committed.
+func (e *Engine) handleObjectiveRequest(ctx context.Context, or protocols.ObjectiveRequest) (EngineEvent, error) {
 	myAddress := *e.store.GetAddress()
 
 	chainId, err := e.chain.GetChainId()
@@ -467,8 +950,19 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 
 	objectiveId := or.Id(myAddress, chainId)
 	failedEngineEvent := EngineEvent{FailedObjectives: []protocols.ObjectiveId{objectiveId}}
-	e.logger.Info("handling new objective request", logging.WithObjectiveIdAttribute(objectiveId))
+	e.correlate(objectiveId, logging.CorrelationId(ctx))
+	e.objectiveLogger(objectiveId).Info("handling new objective request")
 	defer or.SignalObjectiveStarted()
+	abandoned := e.isAbandoned(ctx, "objective request")
+	e.recordObjectiveRequest(objectiveId, or, abandoned)
+	if abandoned {
+		return failedEngineEvent, nil
+	}
+	if isFundingRequest(or) {
+		if err := e.CheckFundingAllowed(); err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: could not start objective %s: %w", objectiveId, err)
+		}
+	}
 	switch request := or.(type) {
 
 	case virtualfund.ObjectiveRequest:
@@ -476,6 +970,8 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create virtualfund objective for %+v: %w", request, err)
 		}
+		e.detectContractWallets(vfo.V.Participants)
+		vfo.SetGuaranteeExpiry(e.guaranteeExpiry())
 		// Only Alice or Bob care about registering the objective and keeping track of vouchers
 		lastParticipant := uint(len(vfo.V.Participants) - 1)
 		if vfo.MyRole == lastParticipant || vfo.MyRole == payments.PAYER_INDEX {
@@ -488,6 +984,9 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("could not register channel with payment/receipt manager: %w", err)
 		}
+		if err := e.recordMemo(&vfo); err != nil {
+			return failedEngineEvent, err
+		}
 		return e.attemptProgress(&vfo)
 
 	case virtualdefund.ObjectiveRequest:
@@ -510,6 +1009,10 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		if err != nil {
 			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create directfund objective for %+v: %w", request, err)
 		}
+		e.detectContractWallets(dfo.C.Participants)
+		if err := e.recordMemo(&dfo); err != nil {
+			return failedEngineEvent, err
+		}
 		return e.attemptProgress(&dfo)
 
 	case directdefund.ObjectiveRequest:
@@ -524,26 +1027,52 @@ func (e *Engine) handleObjectiveRequest(or protocols.ObjectiveRequest) (EngineEv
 		}
 		return e.attemptProgress(&ddfo)
 
+	case challenge.ObjectiveRequest:
+		co, err := e.newChallengeObjective(request)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create challenge objective for %+v: %w", request, err)
+		}
+		return e.attemptProgress(co)
+
+	case ledgertopup.ObjectiveRequest:
+		tuo, err := e.newLedgerTopUpObjective(request)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create ledger top-up objective for %+v: %w", request, err)
+		}
+		return e.attemptProgress(tuo)
+
 	default:
-		return failedEngineEvent, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
+		t, ok := protocols.LookupObjectiveType(objectiveId)
+		if !ok {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Unknown objective type %T", request)
+		}
+		if existing, err := e.store.GetObjectiveById(objectiveId); err == nil {
+			if t.ApplyRequest == nil {
+				return failedEngineEvent, fmt.Errorf("handleAPIEvent: Objective %s already exists: %w", objectiveId, ErrObjectiveTypeRefused)
+			}
+			o, err := t.ApplyRequest(existing, request, myAddress)
+			if err != nil {
+				return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not apply %+v to objective %s: %w: %w", request, objectiveId, ErrObjectiveTypeRefused, err)
+			}
+			return e.attemptProgress(o)
+		}
+		o, err := t.ConstructFromRequest(request, myAddress, chainId)
+		if err != nil {
+			return failedEngineEvent, fmt.Errorf("handleAPIEvent: Could not create %s objective for %+v: %w: %w", t.Prefix, request, ErrObjectiveTypeRefused, err)
+		}
+		return e.attemptProgress(o)
 	}
 }
 
 // handlePaymentRequest handles an PaymentRequest (triggered by a client API call).
 // It prepares and dispatches a payment message to the counterparty.
 func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, error) {
+	cranked := time.Now()
 	ee := EngineEvent{}
 	if (request == PaymentRequest{}) {
 		return ee, fmt.Errorf("handleAPIEvent: Empty payment request")
 	}
 	cId := request.ChannelId
-	voucher, err := e.vm.Pay(
-		cId,
-		request.Amount,
-		*e.store.GetChannelSecretKey())
-	if err != nil {
-		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
-	}
 	c, ok := e.store.GetChannelById(cId)
 	if !ok {
 		return ee, fmt.Errorf("handleAPIEvent: Could not get channel from the store %s", cId)
@@ -552,25 +1081,121 @@ func (e *Engine) handlePaymentRequest(request PaymentRequest) (EngineEvent, erro
 	if payer != *e.store.GetAddress() {
 		return ee, fmt.Errorf("handleAPIEvent: Not the sender in channel %s", cId)
 	}
+
+	var voucher payments.Voucher
+	var err error
+	if request.PaymentId == "" {
+		voucher, err = e.vm.PayWithExpiry(cId, request.Amount, request.Expiry, e.signer)
+	} else {
+		var paid bool
+		voucher, paid, err = e.paymentIds.PayWithExpiry(e.vm, cId, request.Amount, request.PaymentId, request.Expiry, e.signer)
+		if err == nil && !paid {
+			// The payment was made before. Its voucher is resent in case the payee never received it.
+			e.logger.Debug("Resending voucher for repeated payment", "paymentId", request.PaymentId, logging.WithChannelIdAttribute(cId))
+			voucher.Context = request.Context
+			if !request.Expiry.IsZero() && voucher.Expiry != uint64(request.Expiry.Unix()) {
+				if err := voucher.SetExpiry(request.Expiry, e.signer, payer); err != nil {
+					return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
+				}
+			}
+			se := protocols.SideEffects{MessagesToSend: correlatedMessages(protocols.CreateVoucherMessage(voucher, payee), request.CorrelationId)}
+			return ee, e.executeSideEffects(se)
+		}
+	}
+	if err != nil {
+		return ee, fmt.Errorf("handleAPIEvent: Error making payment: %w", err)
+	}
+	err = e.store.AppendActivity(store.ActivityRecord{Time: e.now(), Kind: store.PaymentSent, ChannelId: cId, Amount: request.Amount})
+	if err != nil {
+		return ee, err
+	}
 	info, err := query.GetPaymentChannelInfo(cId, e.store, e.vm)
 	if err != nil {
 		return ee, fmt.Errorf("handleAPIEvent: Error querying channel info: %w", err)
 	}
 	ee.PaymentChannelUpdates = append(ee.PaymentChannelUpdates, info)
 
-	se := protocols.SideEffects{MessagesToSend: protocols.CreateVoucherMessage(voucher, payee)}
+	voucher.Context = request.Context
+	e.paymentTimer.recordCranked(voucher, request.Enqueued, cranked)
+	e.metrics.IncCounter(VouchersSentMetric, nil)
+	logging.LoggerWithCorrelationId(e.logger, request.CorrelationId).Debug("Sending payment", logging.WithChannelIdAttribute(cId), "amount", request.Amount)
+	se := protocols.SideEffects{MessagesToSend: correlatedMessages(protocols.CreateVoucherMessage(voucher, payee), request.CorrelationId)}
 	return ee, e.executeSideEffects(se)
 }
 
+// WithSigner makes the engine sign with signer, rather than the channel secret key of its store, which then need not
+// hold one. The signer must sign for the store's address.
+func WithSigner(signer Signer) Option {
+	return func(e *Engine) {
+		e.signer = signer
+	}
+}
+
+// handleQuoteRequest handles a QuoteRequest (triggered by a client API call).
+// It dispatches the request to the intermediary, whose quote will later arrive as a message.
+func (e *Engine) handleQuoteRequest(request QuoteRequest) error {
+	se := protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateQuoteRequestMessage(request.Request, request.Intermediary)}}
+	return e.executeSideEffects(se)
+}
+
+// quote computes our response, as an intermediary, to a quote request received from requester.
+// We can route the virtual channel if we have a ledger channel with both the requester and the counterparty,
+// and our balance in the ledger channel with the counterparty covers the requested amount.
+func (e *Engine) quote(requester types.Address, request protocols.QuoteRequest) protocols.Quote {
+	q := protocols.Quote{
+		RequestId:    request.Id,
+		Intermediary: *e.store.GetAddress(),
+		CounterParty: request.CounterParty,
+		Asset:        request.Asset,
+		Amount:       request.Amount,
+		Fee:          big.NewInt(0),
+	}
+
+	if request.Amount == nil || request.Amount.Sign() < 0 {
+		q.Reason = "invalid amount"
+		return q
+	}
+	if _, ok := e.store.GetConsensusChannel(requester); !ok {
+		q.Reason = "no ledger channel with requester"
+		return q
+	}
+	ledger, ok := e.store.GetConsensusChannel(request.CounterParty)
+	if !ok {
+		q.Reason = "no ledger channel with counterparty"
+		return q
+	}
+
+	vars := ledger.ConsensusVars()
+	funds := vars.Outcome.AsOutcome().TotalAllocatedFor(types.AddressToDestination(*e.store.GetAddress()))
+	available, ok := funds[request.Asset]
+	if !ok || available.Cmp(request.Amount) < 0 {
+		q.Reason = "insufficient capacity in ledger channel with counterparty"
+		return q
+	}
+
+	if fq, ok := e.policymaker.(FeeQuoter); ok {
+		if fee := fq.QuoteFee(requester, request); fee != nil {
+			q.Fee = fee
+		}
+	}
+	q.CanRoute = true
+	return q
+}
+
 // sendMessages sends out the messages and records the metrics.
 func (e *Engine) sendMessages(msgs []protocols.Message) {
 	for _, message := range msgs {
 		message.From = *e.store.GetAddress()
+		e.peerHealth.recordSent(message, time.Now())
 		err := e.msg.Send(message)
 		if err != nil {
 			e.logger.Error(err.Error())
 			panic(err)
 		}
+		sent := time.Now()
+		for _, voucher := range message.Payments {
+			e.paymentTimer.recordSent(voucher, sent)
+		}
 		e.logMessage(message, Outgoing)
 	}
 	e.wg.Done()
@@ -578,6 +1203,16 @@ func (e *Engine) sendMessages(msgs []protocols.Message) {
 
 // executeSideEffects executes the SideEffects declared by cranking an Objective or handling a payment request.
 func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
+	// Number the messages before sending them, so that the recipients can discard any they have already processed
+	for i := range sideEffects.MessagesToSend {
+		message := &sideEffects.MessagesToSend[i]
+		epoch, seq, err := e.store.NextMessageSeq(message.To)
+		if err != nil {
+			return err
+		}
+		message.Epoch, message.Seq = epoch, seq
+	}
+
 	e.wg.Add(1)
 	// Send messages in a go routine so that we don't block on message delivery
 	go e.sendMessages(sideEffects.MessagesToSend)
@@ -585,7 +1220,7 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 	for _, tx := range sideEffects.TransactionsToSubmit {
 		e.logger.Info("Sending chain transaction", "channel", tx.ChannelId().String())
 
-		err := e.chain.SendTransaction(tx)
+		err := e.submitTransaction(tx)
 		if err != nil {
 			return err
 		}
@@ -596,23 +1231,75 @@ func (e *Engine) executeSideEffects(sideEffects protocols.SideEffects) error {
 	return nil
 }
 
+// shouldAutoDefund returns true if the policymaker wants the payment channel, which has remaining funds left, to be
+// closed automatically, and nothing is already closing it.
+func (e *Engine) shouldAutoDefund(channelId types.Destination, remaining *big.Int) bool {
+	adp, ok := e.policymaker.(AutoDefundPolicy)
+	if !ok {
+		return false
+	}
+	threshold, enabled := adp.AutoDefundThreshold()
+	if !enabled || remaining.Cmp(threshold) > 0 {
+		return false
+	}
+	_, owned := e.store.GetObjectiveByChannelId(channelId)
+	return !owned
+}
+
+// fundsExternally returns true if the ledger channel being opened by dfo is funded out-of-band: either because we are
+// virtual-only, or because the policymaker says so for the counterparty.
+func (e *Engine) fundsExternally(dfo *directfund.Objective) bool {
+	if chainservice.IsVirtualOnly(e.chain) {
+		return true
+	}
+	efp, ok := e.policymaker.(ExternalFundingPolicy)
+	if !ok {
+		return false
+	}
+	for i, p := range dfo.C.Participants {
+		if i != int(dfo.C.MyIndex) && efp.FundsExternally(p) {
+			return true
+		}
+	}
+	return false
+}
+
 // attemptProgress takes a "live" objective in memory and performs the following actions:
 //
-//  1. It pulls the secret key from the store
-//  2. It cranks the objective with that key
-//  3. It commits the cranked objective to the store
-//  4. It executes any side effects that were declared during cranking
-//  5. It updates progress metadata in the store
+//  1. It cranks the objective with the engine's signer
+//  2. It commits the cranked objective to the store
+//  3. It executes any side effects that were declared during cranking
+//  4. It updates progress metadata in the store
 func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing EngineEvent, err error) {
-	secretKey := e.store.GetChannelSecretKey()
+	store.UseSchemes(objective, e.schemes)
 	var crankedObjective protocols.Objective
 	var sideEffects protocols.SideEffects
 	var waitingFor protocols.WaitingFor
 
-	crankedObjective, sideEffects, waitingFor, err = objective.Crank(secretKey)
+	if dfo, ok := objective.(*directfund.Objective); ok {
+		if dsp, ok := e.policymaker.(DepositSafetyPolicy); ok {
+			dfo.SetDepositSafetyDepth(dsp.RequiredDepositDepth(), e.chain.GetLastConfirmedBlockNum())
+		}
+		if e.fundsExternally(dfo) {
+			dfo.SetExternallyFunded()
+		}
+	}
+
+	started := time.Now()
+	crankedObjective, sideEffects, waitingFor, err = objective.Crank(e.signer)
+	e.metrics.RecordDuration(ObjectiveCrankMetric, time.Since(started), map[string]string{"protocol": objectiveProtocol(objective.Id())})
 	if err != nil {
 		return
 	}
+	e.correlateMessages(objective.Id(), &sideEffects)
+
+	if waitingFor == directfund.WaitingForPriorDepositDepth {
+		e.awaitingDepositDepth[crankedObjective.Id()] = struct{}{}
+	} else {
+		delete(e.awaitingDepositDepth, crankedObjective.Id())
+	}
+	e.trackFundingDeadline(crankedObjective, waitingFor)
+	e.trackChallengeDeadline(crankedObjective, waitingFor)
 
 	err = e.store.SetObjective(crankedObjective)
 	if err != nil {
@@ -625,13 +1312,17 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 	}
 	outgoing.Merge(notifEvents)
 
-	e.logger.Info("Objective cranked", logging.WithObjectiveIdAttribute(objective.Id()), "waiting-for", string(waitingFor))
+	e.objectiveLogger(objective.Id()).Info("Objective cranked", "waiting-for", string(waitingFor))
 
 	// If our protocol is waiting for nothing then we know the objective is complete
 	// TODO: If attemptProgress is called on a completed objective CompletedObjectives would include that objective id
 	// Probably should have a better check that only adds it to CompletedObjectives if it was completed in this crank
 	if waitingFor == "WaitingForNothing" {
 		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, crankedObjective)
+		err = e.recordChannelActivity(crankedObjective)
+		if err != nil {
+			return
+		}
 		err = e.store.ReleaseChannelFromOwnership(crankedObjective.OwnsChannel())
 		if err != nil {
 			return
@@ -640,11 +1331,294 @@ func (e *Engine) attemptProgress(objective protocols.Objective) (outgoing Engine
 		if err != nil {
 			return
 		}
+		err = e.restoreConsensusChannelIfChallengeCleared(crankedObjective)
+		if err != nil {
+			return
+		}
+		err = e.restoreConsensusChannelAfterTopUp(crankedObjective)
+		if err != nil {
+			return
+		}
 	}
 	err = e.executeSideEffects(sideEffects)
 	return
 }
 
+// crankObjectivesAwaitingDepositDepth attempts progress on any objectives waiting for a prior deposit to be buried
+// deeply enough for us to deposit safely.
+func (e *Engine) crankObjectivesAwaitingDepositDepth() (EngineEvent, error) {
+	outgoing := EngineEvent{}
+	for id := range e.awaitingDepositDepth {
+		objective, err := e.store.GetObjectiveById(id)
+		if err != nil {
+			// The objective is gone, such as when it has been collected, so there is nothing left to crank
+			e.logger.Warn("Objective awaiting deposit depth not found", "error", err, logging.WithObjectiveIdAttribute(id))
+			delete(e.awaitingDepositDepth, id)
+			continue
+		}
+		progressEvent, err := e.attemptProgress(objective)
+		if err != nil {
+			return outgoing, err
+		}
+		outgoing.Merge(progressEvent)
+	}
+	return outgoing, nil
+}
+
+// resumeObjectives is called when the engine starts. It recovers from a restart which may have interrupted message
+// delivery to or from our peers by:
+//   - detecting which participants are contract wallets, since that is not persisted,
+//   - re-sending the latest state we signed for each channel owned by an in-progress objective,
+//   - re-sending any ledger proposals we lead which are still awaiting a countersignature,
+//   - rebuilding the set of directfund objectives awaiting a prior deposit's depth, which is not persisted,
+//   - cranking each in-progress objective.
+//
+// Peers treat the re-sent states and proposals as duplicates if they have already received them.
+func (e *Engine) resumeObjectives() (EngineEvent, error) {
+	outgoing := EngineEvent{}
+	sideEffects := protocols.SideEffects{}
+
+	channels, err := e.store.GetChannelsByParticipant(*e.store.GetAddress())
+	if err != nil {
+		return outgoing, err
+	}
+
+	objectives := []protocols.Objective{}
+	for _, c := range channels {
+		e.detectContractWallets(c.Participants)
+		o, ok := e.store.GetObjectiveByChannelId(c.Id)
+		if !ok || o.GetStatus() != protocols.Approved {
+			continue
+		}
+		objectives = append(objectives, o)
+
+		messages, err := e.resendLatestSignedState(o, c)
+		if err != nil {
+			return outgoing, err
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	ledgers, err := e.store.GetAllConsensusChannels()
+	if err != nil {
+		return outgoing, err
+	}
+	for _, ledger := range ledgers {
+		e.detectContractWallets(ledger.Participants())
+		if !ledger.IsLeader() || len(ledger.ProposalQueue()) == 0 {
+			continue
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, protocols.CreateSignedProposalMessage(ledger.Follower(), ledger.ProposalQueue()...))
+	}
+
+	if len(objectives) > 0 || len(sideEffects.MessagesToSend) > 0 {
+		e.logger.Info("Resuming objectives", "objectives", len(objectives), "messages", len(sideEffects.MessagesToSend))
+	}
+
+	err = e.executeSideEffects(sideEffects)
+	if err != nil {
+		return outgoing, err
+	}
+
+	for _, o := range objectives {
+		// Each directfund objective is checked for a prior deposit's depth on the next block, and dropped from the set
+		// once cranking finds it is not waiting on one
+		if _, ok := o.(*directfund.Objective); ok {
+			e.awaitingDepositDepth[o.Id()] = struct{}{}
+		}
+	}
+	for _, o := range objectives {
+		progressEvent, err := e.attemptProgress(o)
+		if err != nil {
+			return outgoing, err
+		}
+		outgoing.Merge(progressEvent)
+	}
+
+	return outgoing, nil
+}
+
+// resendLatestSignedState returns messages carrying the latest state we signed for the channel c, owned by the objective o,
+// addressed to the other channel participants.
+func (e *Engine) resendLatestSignedState(o protocols.Objective, c *channel.Channel) ([]protocols.Message, error) {
+	var payloadType protocols.PayloadType
+	switch o.(type) {
+	case *directfund.Objective:
+		payloadType = directfund.SignedStatePayload
+	case *directdefund.Objective:
+		payloadType = directdefund.SignedStatePayload
+	case *virtualfund.Objective:
+		payloadType = virtualfund.SignedStatePayload
+	case *virtualdefund.Objective:
+		payloadType = virtualdefund.SignedStatePayload
+	default:
+		return []protocols.Message{}, nil
+	}
+
+	ss, err := c.LatestSignedByMe()
+	if err != nil {
+		// We have not yet signed anything, so there is nothing to re-send
+		return []protocols.Message{}, nil
+	}
+
+	recipients := []types.Address{}
+	for i, p := range c.Participants {
+		if uint(i) != c.MyIndex {
+			recipients = append(recipients, p)
+		}
+	}
+
+	return protocols.CreateObjectivePayloadMessage(o.Id(), ss, payloadType, recipients...)
+}
+
+// trackFundingDeadline starts the clock on a virtualfund objective which has begun waiting for ledger guarantees, if a
+// ProposalTimeoutPolicy applies, and stops it once the objective is no longer waiting.
+func (e *Engine) trackFundingDeadline(o protocols.Objective, waitingFor protocols.WaitingFor) {
+	ptp, ok := e.policymaker.(ProposalTimeoutPolicy)
+	if !ok || ptp.ProposalTimeout() == 0 {
+		return
+	}
+	if _, isVfo := o.(*virtualfund.Objective); !isVfo || waitingFor != virtualfund.WaitingForCompleteFunding {
+		delete(e.fundingDeadlines, o.Id())
+		return
+	}
+	if _, started := e.fundingDeadlines[o.Id()]; !started {
+		e.fundingDeadlines[o.Id()] = e.now().Add(ptp.ProposalTimeout())
+	}
+}
+
+// withdrawExpiredObjectives rejects any virtualfund objectives whose ledger guarantees have not been countersigned
+// by their deadline, withdrawing the guarantees we proposed.
+func (e *Engine) withdrawExpiredObjectives() (EngineEvent, error) {
+	outgoing := EngineEvent{}
+	now := e.now()
+	for id, deadline := range e.fundingDeadlines {
+		if now.Before(deadline) {
+			continue
+		}
+		delete(e.fundingDeadlines, id)
+
+		o, err := e.store.GetObjectiveById(id)
+		if err != nil {
+			return outgoing, err
+		}
+		vfo, ok := o.(*virtualfund.Objective)
+		if !ok || vfo.Status != protocols.Approved {
+			continue
+		}
+		e.logger.Warn("Ledger guarantees were not countersigned in time, withdrawing", logging.WithObjectiveIdAttribute(id))
+
+		withdrawn, sideEffects, err := vfo.Withdraw(e.signer)
+		if err != nil {
+			return outgoing, err
+		}
+		rejected, rejectionSideEffects := withdrawn.Reject()
+		sideEffects.Merge(rejectionSideEffects)
+
+		err = e.store.SetObjective(rejected)
+		if err != nil {
+			return outgoing, err
+		}
+		err = e.store.ReleaseChannelFromOwnership(rejected.OwnsChannel())
+		if err != nil {
+			return outgoing, err
+		}
+		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, rejected)
+
+		err = e.executeSideEffects(sideEffects)
+		if err != nil {
+			return outgoing, err
+		}
+	}
+	return outgoing, nil
+}
+
+// reject rejects the objective, notifying its peers, and releases the channel it owns.
+func (e *Engine) reject(objective protocols.Objective) (protocols.Objective, error) {
+	rejected, sideEffects := objective.Reject()
+	err := e.store.SetObjective(rejected)
+	if err != nil {
+		return rejected, err
+	}
+	e.correlateMessages(objective.Id(), &sideEffects)
+	err = e.store.ReleaseChannelFromOwnership(rejected.OwnsChannel())
+	if err != nil {
+		return rejected, err
+	}
+	err = e.restoreConsensusChannelAfterTopUp(rejected)
+	if err != nil {
+		return rejected, err
+	}
+	return rejected, e.executeSideEffects(sideEffects)
+}
+
+// handleWithdrawal passes a proposal withdrawing a guarantee to the virtualfund objective which proposed it.
+// ok is false if the proposal is not a withdrawal, ie. there is no virtualfund objective for its target, or the
+// objective is completed and the proposal defunds its channel.
+func (e *Engine) handleWithdrawal(sp consensus_channel.SignedProposal) (outgoing EngineEvent, ok bool, err error) {
+	if sp.Proposal.Type() != consensus_channel.RemoveProposal {
+		return EngineEvent{}, false, nil
+	}
+	o, err := e.store.GetObjectiveById(protocols.ObjectiveId(virtualfund.ObjectivePrefix + sp.Proposal.Target().String()))
+	if err != nil {
+		return EngineEvent{}, false, nil
+	}
+	vfo, isVfo := o.(*virtualfund.Objective)
+	if !isVfo {
+		return EngineEvent{}, false, nil
+	}
+	if vfo.Status == protocols.Completed {
+		if _, err := e.store.GetObjectiveById(protocols.ObjectiveId(virtualdefund.ObjectivePrefix + sp.Proposal.Target().String())); err == nil {
+			return EngineEvent{}, false, nil
+		}
+		return EngineEvent{}, true, virtualfund.ErrWithdrawalOfCompletedObjective
+	}
+
+	updated, sideEffects, err := vfo.ReceiveWithdrawal(sp, e.signer)
+	if err != nil {
+		return EngineEvent{}, true, err
+	}
+	err = e.store.SetObjective(updated)
+	if err != nil {
+		return EngineEvent{}, true, err
+	}
+	if updated.Status == protocols.Rejected && vfo.Status != protocols.Rejected {
+		delete(e.fundingDeadlines, updated.Id())
+		err = e.store.ReleaseChannelFromOwnership(updated.OwnsChannel())
+		if err != nil {
+			return EngineEvent{}, true, err
+		}
+		outgoing.CompletedObjectives = append(outgoing.CompletedObjectives, updated)
+	}
+	return outgoing, true, e.executeSideEffects(sideEffects)
+}
+
+// recordChannelActivity records the opening or closing of a channel by a completed objective.
+func (e *Engine) recordChannelActivity(o protocols.Objective) error {
+	if o.GetStatus() != protocols.Completed {
+		return nil
+	}
+	r := store.ActivityRecord{Time: e.now(), ObjectiveId: o.Id()}
+	switch obj := o.(type) {
+	case *directfund.Objective:
+		r.Kind, r.ChannelId = store.ChannelOpened, obj.C.Id
+	case *virtualfund.Objective:
+		r.Kind, r.ChannelId, r.Fee = store.ChannelOpened, obj.V.Id, obj.Fee()
+	case *directdefund.Objective:
+		r.Kind, r.ChannelId = store.ChannelClosed, obj.C.Id
+	case *virtualdefund.Objective:
+		r.Kind, r.ChannelId = store.ChannelClosed, obj.VId()
+	case *challenge.Objective:
+		if obj.Cleared() {
+			return nil
+		}
+		r.Kind, r.ChannelId = store.ChannelClosed, obj.C.Id
+	default:
+		return nil
+	}
+	return e.store.AppendActivity(r)
+}
+
 // generateNotifications takes an objective and constructs notifications for any related channels for that objective.
 func (e *Engine) generateNotifications(o protocols.Objective) (EngineEvent, error) {
 	outgoing := EngineEvent{}
@@ -697,7 +1671,7 @@ func (e Engine) registerPaymentChannel(vfo virtualfund.Objective) error {
 	// TODO: Assumes one asset for now
 	startingBalance.Set(postfund.Outcome[0].Allocations[0].Amount)
 
-	return e.vm.Register(vfo.V.Id, payments.GetPayer(postfund.Participants), payments.GetPayee(postfund.Participants), startingBalance)
+	return e.vm.Register(vfo.V.Id, postfund.Outcome[0].Asset, payments.GetPayer(postfund.Participants), payments.GetPayee(postfund.Participants), startingBalance)
 }
 
 // spawnConsensusChannelIfDirectFundObjective will attempt to create and store a ConsensusChannel derived from the supplied Objective if it is a directfund.Objective.
@@ -735,20 +1709,39 @@ func (e *Engine) getOrCreateObjective(p protocols.ObjectivePayload) (protocols.O
 		if err != nil {
 			return nil, fmt.Errorf("error constructing objective from message: %w", err)
 		}
+		store.UseSchemes(newObj, e.schemes)
 
 		err = e.store.SetObjective(newObj)
 		if err != nil {
 			return nil, fmt.Errorf("error setting objective in store: %w", err)
 		}
+		if err := e.recordMemo(newObj); err != nil {
+			return nil, err
+		}
 		e.logger.Info("Created new objective from message", "id", id)
 
 		return newObj, nil
 
+	} else if errors.Is(err, store.ErrObjectiveCollected) {
+		return nil, err
 	} else {
 		return nil, &ErrGetObjective{err, id}
 	}
 }
 
+// recordMemo records the memo of the objective, if it carries one, against the channel it funds, so that queries about
+// the channel include it.
+func (e *Engine) recordMemo(o protocols.Objective) error {
+	mc, ok := o.(protocols.MemoCarrier)
+	if !ok || mc.Memo() == "" {
+		return nil
+	}
+	if err := e.store.SetChannelMemo(o.OwnsChannel(), mc.Memo()); err != nil {
+		return fmt.Errorf("could not record the memo of objective %s: %w", o.Id(), err)
+	}
+	return nil
+}
+
 // constructObjectiveFromMessage Constructs a new objective (of the appropriate concrete type) from the supplied payload.
 func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p protocols.ObjectivePayload) (protocols.Objective, error) {
 	e.logger.Info("Constructing objective from message", logging.WithObjectiveIdAttribute(id))
@@ -756,12 +1749,18 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 	case directfund.IsDirectFundObjective(id):
 
 		dfo, err := directfund.ConstructFromPayload(false, p, *e.store.GetAddress())
-		return &dfo, err
+		if err != nil {
+			return &dfo, err
+		}
+		e.detectContractWallets(dfo.C.Participants)
+		return &dfo, nil
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo, err := virtualfund.ConstructObjectiveFromPayload(p, false, *e.store.GetAddress(), e.store.GetConsensusChannel)
 		if err != nil {
 			return &virtualfund.Objective{}, fromMsgErr(id, err)
 		}
+		vfo.SetGuaranteeExpiry(e.guaranteeExpiry())
+		e.detectContractWallets(vfo.V.Participants)
 		err = e.registerPaymentChannel(vfo)
 		if err != nil {
 			return &virtualfund.Objective{}, fmt.Errorf("could not register channel with payment/receipt manager.\n\ttarget channel: %s\n\terr: %w", id, err)
@@ -792,9 +1791,23 @@ func (e *Engine) constructObjectiveFromMessage(id protocols.ObjectiveId, p proto
 			return &directdefund.Objective{}, fromMsgErr(id, err)
 		}
 		return &ddfo, nil
+	case ledgertopup.IsLedgerTopUpObjective(id):
+		tuo, err := ledgertopup.ConstructObjectiveFromPayload(p, *e.store.GetAddress(), e.store.GetConsensusChannelById)
+		if err != nil {
+			return &ledgertopup.Objective{}, fromMsgErr(id, fmt.Errorf("%w: %w", ErrTopUpRefused, err))
+		}
+		return &tuo, nil
 
 	default:
-		return &directfund.Objective{}, errors.New("cannot handle unimplemented objective type")
+		t, ok := protocols.LookupObjectiveType(id)
+		if !ok {
+			return &directfund.Objective{}, errors.New("cannot handle unimplemented objective type")
+		}
+		o, err := t.ConstructFromPayload(p, *e.store.GetAddress())
+		if err != nil {
+			return nil, fromMsgErr(id, fmt.Errorf("%w: %w", ErrObjectiveTypeRefused, err))
+		}
+		return o, nil
 	}
 }
 
@@ -847,10 +1860,11 @@ const (
 
 // logMessage logs a message to the engine's logger
 func (e *Engine) logMessage(msg protocols.Message, direction messageDirection) {
+	logger := logging.LoggerWithCorrelationId(e.logger, msg.CorrelationId)
 	if direction == Incoming {
-		e.logger.Debug("Received message", "msg", msg.Summarize())
+		logger.Debug("Received message", "msg", msg.Summarize())
 	} else {
-		e.logger.Debug("Sent message", "msg", msg.Summarize())
+		logger.Debug("Sent message", "msg", msg.Summarize())
 	}
 }
 
@@ -858,12 +1872,18 @@ func (e *Engine) checkError(err error) {
 	if err != nil {
 		e.logger.Error("error in run loop", "err", err)
 
-		for _, nonFatalError := range nonFatalErrors {
-			if errors.Is(err, nonFatalError) {
-				return
-			}
+		if isFatal(err) {
+			panic(err)
 		}
+	}
+}
 
-		panic(err)
+// isFatal returns true if the engine cannot survive the error.
+func isFatal(err error) bool {
+	for _, nonFatalError := range nonFatalErrors {
+		if errors.Is(err, nonFatalError) {
+			return false
+		}
 	}
+	return true
 }
@@ -0,0 +1,232 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// EventKind is the kind of an input recorded in an event log.
+type EventKind string
+
+const (
+	StartEvent            EventKind = "start"             // The engine started. It is the first entry of every log.
+	ObjectiveRequestEvent EventKind = "objective_request" // An objective was requested through the API
+	PaymentRequestEvent   EventKind = "payment_request"   // A payment was requested through the API
+	ChallengeRequestEvent EventKind = "challenge_request" // A challenge of a ledger channel was requested through the API
+	MessageEvent          EventKind = "message"           // A message was received from a peer
+	ChainEvent            EventKind = "chain_event"       // An event was received from the chain
+	ProposalEvent         EventKind = "proposal"          // A ledger proposal queued by an earlier input was handled
+	BlockEvent            EventKind = "block"             // A block was confirmed
+	DeadlineEvent         EventKind = "deadline"          // Expired objectives, guarantees and challenges were checked for
+	CollectionEvent       EventKind = "collection"        // Terminal objectives were collected
+)
+
+// EventLogEntry is an input to the engine, as recorded in an event log. Entries are numbered by Seq, in the order the
+// engine handled them, and carry the time at which they were handled. Data is the input, encoded according to Kind.
+// In an encrypted log, Data is recorded encrypted as Sealed, but for the StartEvent's.
+type EventLogEntry struct {
+	Seq    uint64
+	Time   time.Time
+	Kind   EventKind
+	Data   json.RawMessage `json:",omitempty"`
+	Sealed string          `json:",omitempty"`
+}
+
+// EventLogStart is the data of the StartEvent, describing the node which recorded the log.
+type EventLogStart struct {
+	Address                  types.Address
+	ChainId                  *big.Int
+	ConsensusAppAddress      types.Address
+	VirtualPaymentAppAddress types.Address
+	// Salt is the salt of the key which encrypts the data of the other entries, if the log is encrypted
+	Salt []byte `json:",omitempty"`
+}
+
+// sealedEntryName is the name which the data of the entry is encrypted under, so that it cannot be moved to another.
+func sealedEntryName(kind EventKind, seq uint64) string {
+	return fmt.Sprintf("%s/%d", kind, seq)
+}
+
+// loggedObjectiveRequest is the data of an ObjectiveRequestEvent. The type of the request is told by the prefix of the
+// objective id.
+type loggedObjectiveRequest struct {
+	Id        protocols.ObjectiveId
+	Abandoned bool // Whether the caller had stopped waiting before the request was handled
+	Request   json.RawMessage
+}
+
+// loggedChallengeRequest is the data of a ChallengeRequestEvent.
+type loggedChallengeRequest struct {
+	LedgerId types.Destination
+}
+
+// loggedBlock is the data of a BlockEvent.
+type loggedBlock struct {
+	BlockNum uint64
+}
+
+// eventLog writes the inputs of the engine, one json EventLogEntry per line.
+type eventLog struct {
+	enc    *json.Encoder
+	cipher *store.RecordCipher // encrypts the data of the entries, if the log is encrypted
+	seq    uint64
+}
+
+// WithEventLog makes the engine record every input which may change its state (requests made through the API,
+// messages from peers, chain events and the passing of time) to w, so that Replay can reconstruct the state of the
+// node step by step. The log includes every message and payment, and is meant for debugging: it grows without bound.
+//
+// If cipher is not nil, the data of the entries is encrypted with it, and its salt recorded in the StartEvent, so that
+// the log can only be replayed with the key it was recorded with.
+func WithEventLog(w io.Writer, cipher *store.RecordCipher) Option {
+	return func(e *Engine) {
+		e.eventLog = &eventLog{enc: json.NewEncoder(w), cipher: cipher}
+	}
+}
+
+// recordInput records the input in the event log, if there is one, and fixes the engine's clock at the time the input
+// is handled, so that handling it again during a replay takes the same decisions. The data of the input, if it has any,
+// is only encoded when there is a log.
+func (e *Engine) recordInput(kind EventKind, data func() (any, error)) {
+	e.stepTime = e.clock()
+	if e.eventLog == nil {
+		return
+	}
+	entry := EventLogEntry{Seq: e.eventLog.seq, Time: e.stepTime, Kind: kind}
+	if data != nil {
+		d, err := data()
+		if err == nil {
+			entry.Data, err = json.Marshal(d)
+		}
+		if err == nil && e.eventLog.cipher != nil && kind != StartEvent {
+			entry.Sealed, err = e.eventLog.cipher.Seal(sealedEntryName(kind, entry.Seq), string(entry.Data))
+			entry.Data = nil
+		}
+		if err != nil {
+			e.logger.Warn("Could not record an input in the event log", "kind", kind, "error", err)
+			return
+		}
+	}
+	if err := e.eventLog.enc.Encode(entry); err != nil {
+		e.logger.Warn("Could not record an input in the event log", "kind", kind, "error", err)
+		return
+	}
+	e.eventLog.seq++
+}
+
+// recordStart records the StartEvent.
+func (e *Engine) recordStart() {
+	if e.eventLog == nil {
+		return
+	}
+	e.recordInput(StartEvent, func() (any, error) {
+		chainId, err := e.chain.GetChainId()
+		start := EventLogStart{
+			Address:                  *e.store.GetAddress(),
+			ChainId:                  chainId,
+			ConsensusAppAddress:      e.chain.GetConsensusAppAddress(),
+			VirtualPaymentAppAddress: e.chain.GetVirtualPaymentAppAddress(),
+		}
+		if e.eventLog.cipher != nil {
+			start.Salt = e.eventLog.cipher.Salt
+		}
+		return start, err
+	})
+	// The clock runs freely until the first input is handled
+	e.stepTime = time.Time{}
+}
+
+// recordObjectiveRequest records an objective request, with whether its caller had abandoned it.
+func (e *Engine) recordObjectiveRequest(id protocols.ObjectiveId, or protocols.ObjectiveRequest, abandoned bool) {
+	e.recordInput(ObjectiveRequestEvent, func() (any, error) {
+		request, err := json.Marshal(or)
+		return loggedObjectiveRequest{Id: id, Abandoned: abandoned, Request: request}, err
+	})
+}
+
+// recordChainEvent records an event received from the chain.
+func (e *Engine) recordChainEvent(event chainservice.Event) {
+	e.recordInput(ChainEvent, func() (any, error) {
+		encoded, err := chainservice.MarshalEvent(event)
+		return json.RawMessage(encoded), err
+	})
+}
+
+// now returns the time at which the input being handled was recorded, so that decisions which depend on the time are
+// taken alike when the input is replayed.
+func (e *Engine) now() time.Time {
+	if e.stepTime.IsZero() {
+		return e.clock()
+	}
+	return e.stepTime
+}
+
+// dataOf returns a function returning the value, for recordInput.
+func dataOf(v any) func() (any, error) {
+	return func() (any, error) { return v, nil }
+}
+
+// decodeObjectiveRequest reconstructs an objective request recorded in an event log.
+func decodeObjectiveRequest(lr loggedObjectiveRequest) (protocols.ObjectiveRequest, error) {
+	id := string(lr.Id)
+	switch {
+	case strings.HasPrefix(id, directfund.ObjectivePrefix):
+		var r directfund.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		request := directfund.NewObjectiveRequest(r.CounterParty, r.ChallengeDuration, r.Outcome, r.Nonce, r.AppDefinition)
+		request.AppData = r.AppData
+		return request, nil
+	case strings.HasPrefix(id, directdefund.ObjectivePrefix):
+		var r directdefund.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		return directdefund.NewObjectiveRequest(r.ChannelId), nil
+	case strings.HasPrefix(id, virtualfund.ObjectivePrefix):
+		var r virtualfund.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		return virtualfund.NewObjectiveRequest(r.Intermediaries, r.CounterParty, r.ChallengeDuration, r.Outcome, r.Nonce, r.AppDefinition), nil
+	case strings.HasPrefix(id, virtualdefund.ObjectivePrefix):
+		var r virtualdefund.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		return virtualdefund.NewObjectiveRequest(r.ChannelId), nil
+	case strings.HasPrefix(id, challenge.ObjectivePrefix):
+		var r challenge.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		return challenge.NewObjectiveRequest(r.ChannelId), nil
+	case strings.HasPrefix(id, ledgertopup.ObjectivePrefix):
+		var r ledgertopup.ObjectiveRequest
+		if err := json.Unmarshal(lr.Request, &r); err != nil {
+			return nil, err
+		}
+		return ledgertopup.NewObjectiveRequest(r.ChannelId, r.Amount, r.Nonce), nil
+	}
+	t, ok := protocols.LookupObjectiveType(lr.Id)
+	if !ok || t.DecodeRequest == nil {
+		return nil, fmt.Errorf("cannot decode the request for objective %s", lr.Id)
+	}
+	return t.DecodeRequest(lr.Request)
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// collectionInterval returns how often objectives should be collected, if the policymaker implements ObjectiveCollectionPolicy.
+// Zero means objectives are never collected.
+func (e *Engine) collectionInterval() time.Duration {
+	if cp, ok := e.policymaker.(ObjectiveCollectionPolicy); ok {
+		return cp.CollectionInterval()
+	}
+	return 0
+}
+
+// collectObjectives replaces each objective which has completed or been rejected, and which is no longer referenced,
+// with a compact summary, deleting its crank state.
+func (e *Engine) collectObjectives() error {
+	ids, err := e.store.GetTerminalObjectiveIds()
+	if err != nil || len(ids) == 0 {
+		return err
+	}
+	ledgers, err := e.store.GetAllConsensusChannels()
+	if err != nil {
+		return err
+	}
+
+	collected := 0
+	for _, id := range ids {
+		o, err := e.store.GetObjectiveById(id)
+		if o == nil {
+			return err
+		}
+		// An error populating the channels of a terminal objective is expected if they have since been destroyed,
+		// and does not prevent the objective being collected.
+
+		if e.references(o, ledgers) > 0 {
+			continue
+		}
+		err = e.store.CollectObjective(store.ObjectiveSummary{
+			Id:        id,
+			Status:    o.GetStatus(),
+			ChannelId: o.OwnsChannel(),
+			Collected: e.now(),
+		})
+		if err != nil {
+			return err
+		}
+		collected++
+	}
+
+	if collected > 0 {
+		e.logger.Info("Collected objectives", "collected", collected, "retained", len(ids)-collected)
+	}
+	return nil
+}
+
+// references counts what still refers to the terminal objective:
+//   - the channel it funded, while that channel remains open, and
+//   - each ledger channel which still holds, or has a proposal for, a guarantee for the virtual channel it funded.
+//
+// An objective is only collected once it has no references.
+func (e *Engine) references(o protocols.Objective, ledgers []*consensus_channel.ConsensusChannel) int {
+	refs := 0
+	switch o.(type) {
+	case *directfund.Objective, *virtualfund.Objective:
+		if o.GetStatus() == protocols.Completed && e.isOpen(o.OwnsChannel()) {
+			refs++
+		}
+	}
+
+	if _, ok := o.(*virtualfund.Objective); ok {
+		target := o.OwnsChannel()
+		for _, l := range ledgers {
+			if guaranteesTarget(l, target) {
+				refs++
+			}
+		}
+	}
+	return refs
+}
+
+// isOpen returns true if the channel is in the store and has not been finalized.
+func (e *Engine) isOpen(id types.Destination) bool {
+	if c, ok := e.store.GetChannelById(id); ok {
+		return !c.FinalCompleted()
+	}
+	_, err := e.store.GetConsensusChannelById(id)
+	return err == nil
+}
+
+// guaranteesTarget returns true if the ledger channel funds the target, or has a proposal concerning it.
+func guaranteesTarget(l *consensus_channel.ConsensusChannel, target types.Destination) bool {
+	for _, funded := range l.FundingTargets() {
+		if funded == target {
+			return true
+		}
+	}
+	for _, sp := range l.ProposalQueue() {
+		if sp.Proposal.Target() == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// guaranteeExpiry returns the expiry of the guarantees proposed by a virtual funding objective starting now, as a unix
+// time in seconds, if the policymaker implements GuaranteeExpiryPolicy. Zero means the guarantees never expire.
+func (e *Engine) guaranteeExpiry() uint64 {
+	gep, ok := e.policymaker.(GuaranteeExpiryPolicy)
+	if !ok || gep.GuaranteeLifetime() == 0 {
+		return 0
+	}
+	return uint64(e.now().Add(gep.GuaranteeLifetime()).Unix())
+}
+
+// offChainReclaimTimeout returns how long we try to reclaim an expired guarantee off-chain before challenging its ledger
+// channel, if the policymaker implements GuaranteeExpiryPolicy. Zero means we never challenge.
+func (e *Engine) offChainReclaimTimeout() time.Duration {
+	if gep, ok := e.policymaker.(GuaranteeExpiryPolicy); ok {
+		return gep.OffChainReclaimTimeout()
+	}
+	return 0
+}
+
+// reclaimExpiredGuarantees reclaims the funds held by expired guarantees in our ledger channels, which fund virtual
+// channels we are an intermediary of. The virtual channel is first defunded off-chain. If that has not completed within
+// the OffChainReclaimTimeout, each ledger channel still holding an expired guarantee for it is challenged on chain with
+// its latest supported state.
+func (e *Engine) reclaimExpiredGuarantees() (EngineEvent, error) {
+	outgoing := EngineEvent{}
+	ledgers, err := e.store.GetAllConsensusChannels()
+	if err != nil {
+		return outgoing, err
+	}
+
+	now := e.now()
+	expired := map[types.Destination]struct{}{}
+	for _, ledger := range ledgers {
+		for _, g := range ledger.ExpiredGuarantees(now) {
+			vId := g.Target()
+			if !e.isIntermediary(vId) {
+				continue
+			}
+			expired[vId] = struct{}{}
+
+			started, reclaiming := e.reclaimStarted[vId]
+			if !reclaiming {
+				o, owned := e.store.GetObjectiveByChannelId(vId)
+				if owned && !virtualdefund.IsVirtualDefundObjective(o.Id()) {
+					// The virtual channel is still being funded
+					continue
+				}
+				e.reclaimStarted[vId] = now
+				if owned {
+					// The virtual channel is already being defunded
+					continue
+				}
+
+				e.logger.Warn("Guarantee has expired, reclaiming it by defunding the virtual channel", logging.WithChannelIdAttribute(vId), "ledger", ledger.Id)
+				defunding, err := e.handleObjectiveRequest(context.Background(), virtualdefund.NewObjectiveRequest(vId))
+				if err != nil {
+					e.logger.Error("Could not defund the virtual channel of an expired guarantee", logging.WithChannelIdAttribute(vId), "error", err)
+					continue
+				}
+				outgoing.Merge(defunding)
+				continue
+			}
+
+			timeout := e.offChainReclaimTimeout()
+			if timeout == 0 || now.Before(started.Add(timeout)) {
+				continue
+			}
+			if _, challenged := e.challengedLedgers[ledger.Id]; challenged {
+				continue
+			}
+			e.challengedLedgers[ledger.Id] = struct{}{}
+
+			e.logger.Warn("Expired guarantee was not reclaimed off-chain in time, challenging the ledger channel", logging.WithChannelIdAttribute(vId), "ledger", ledger.Id)
+			if err := e.challengeLedger(ledger); err != nil {
+				// Challenges are not retried, since the chain is unlikely to accept the same transaction later
+				e.logger.Error("Could not challenge the ledger channel of an expired guarantee", "ledger", ledger.Id, "error", err)
+			}
+		}
+	}
+
+	// Forget reclamations which have completed
+	for vId := range e.reclaimStarted {
+		if _, ok := expired[vId]; !ok {
+			delete(e.reclaimStarted, vId)
+		}
+	}
+	return outgoing, nil
+}
+
+// isIntermediary returns true if we are an intermediary of the virtual channel with the given id.
+func (e *Engine) isIntermediary(vId types.Destination) bool {
+	c, ok := e.store.GetChannelById(vId)
+	return ok && c.MyIndex != 0 && int(c.MyIndex) != len(c.Participants)-1
+}
+
+// handleChallengeRequest handles a ChallengeRequest (triggered by a client API call), challenging the ledger channel
+// with its latest supported state. The ledger channel may be being defunded, in which case it is held as a channel
+// rather than a consensus channel. A ledger channel which we have already challenged is not challenged again.
+func (e *Engine) handleChallengeRequest(request ChallengeRequest) {
+	id := request.LedgerId
+	if _, challenged := e.challengedLedgers[id]; challenged {
+		request.Result <- nil
+		return
+	}
+	var candidate state.SignedState
+	if ledger, err := e.store.GetConsensusChannelById(id); err == nil {
+		candidate = ledger.SupportedSignedState()
+	} else if c, ok := e.store.GetChannelById(id); ok {
+		if candidate, err = c.LatestSupportedSignedState(); err != nil {
+			request.Result <- err
+			return
+		}
+	} else {
+		request.Result <- err
+		return
+	}
+	e.challengedLedgers[id] = struct{}{}
+
+	e.logger.Warn("Challenging the ledger channel on request", "ledger", id)
+	if err := e.challenge(id, candidate); err != nil {
+		delete(e.challengedLedgers, id)
+		request.Result <- err
+		return
+	}
+	request.Result <- nil
+}
+
+// challengeLedger registers a challenge for the ledger channel on chain, with its latest supported state.
+func (e *Engine) challengeLedger(ledger *consensus_channel.ConsensusChannel) error {
+	return e.challenge(ledger.Id, ledger.SupportedSignedState())
+}
+
+// challenge disputes the channel with the given id through the engine's DisputeAdapter, with the candidate state.
+func (e *Engine) challenge(id types.Destination, candidate state.SignedState) error {
+	txs, err := e.disputes.Challenge(id, candidate, e.signer)
+	if err != nil {
+		return err
+	}
+	if len(txs) > 0 && chainservice.IsVirtualOnly(e.chain) {
+		return chainservice.ErrVirtualOnly
+	}
+	return e.executeSideEffects(protocols.SideEffects{TransactionsToSubmit: txs})
+}
@@ -0,0 +1,55 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrTopUpRefused is returned when a ledger top-up objective cannot be started for a channel.
+const ErrTopUpRefused = types.ConstError("ledger top-up refused")
+
+// newLedgerTopUpObjective constructs a ledger top-up objective for the request, taking the ledger channel out of use:
+// a Channel takes over its governance until the top-up ends.
+func (e *Engine) newLedgerTopUpObjective(request ledgertopup.ObjectiveRequest) (*ledgertopup.Objective, error) {
+	if chainservice.IsVirtualOnly(e.chain) {
+		return nil, fmt.Errorf("%w: %w", ErrTopUpRefused, chainservice.ErrVirtualOnly)
+	}
+	o, err := ledgertopup.NewObjective(request, *e.store.GetAddress(), e.store.GetConsensusChannelById)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTopUpRefused, err)
+	}
+	err = e.store.DestroyConsensusChannel(request.ChannelId)
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// restoreConsensusChannelAfterTopUp returns the ledger channel of a ledger top-up objective which has completed or been
+// rejected to use as a ConsensusChannel, with the latest state its participants have agreed.
+func (e *Engine) restoreConsensusChannelAfterTopUp(o protocols.Objective) error {
+	tuo, ok := o.(*ledgertopup.Objective)
+	if !ok {
+		return nil
+	}
+	if status := tuo.GetStatus(); status != protocols.Completed && status != protocols.Rejected {
+		return nil
+	}
+	if _, err := e.store.GetConsensusChannelById(tuo.C.Id); err == nil {
+		// The top-up was rejected before the ledger was taken out of use
+		return nil
+	}
+	c, err := tuo.CreateConsensusChannel()
+	if err != nil {
+		return fmt.Errorf("could not restore consensus channel for objective %s: %w", o.Id(), err)
+	}
+	err = e.store.SetConsensusChannel(c)
+	if err != nil {
+		return fmt.Errorf("could not store consensus channel for objective %s: %w", o.Id(), err)
+	}
+	return e.store.DestroyChannel(c.Id)
+}
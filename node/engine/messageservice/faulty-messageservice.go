@@ -0,0 +1,182 @@
+package messageservice
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Faults describes the faults injected into messages by a FaultyMessageService.
+// The zero value injects no faults.
+type Faults struct {
+	// DropRate is the probability, between 0 and 1, that a message we send is lost
+	DropRate float64
+	// DuplicateRate is the probability, between 0 and 1, that a message we send is delivered twice
+	DuplicateRate float64
+	// MinDelay and MaxDelay bound the delay, chosen uniformly at random, before a message we send is delivered
+	MinDelay time.Duration
+	MaxDelay time.Duration
+	// Partitioned lists the peers we are partitioned from: no messages are sent to or received from them
+	Partitioned []types.Address
+}
+
+// Validate returns an error if the faults are not well formed.
+func (f Faults) Validate() error {
+	if f.DropRate < 0 || f.DropRate > 1 || f.DuplicateRate < 0 || f.DuplicateRate > 1 {
+		return errors.New("fault rates must be between 0 and 1")
+	}
+	if f.MinDelay < 0 || f.MaxDelay < f.MinDelay {
+		return errors.New("fault delays must satisfy 0 <= MinDelay <= MaxDelay")
+	}
+	return nil
+}
+
+// FaultInjector is implemented by message services into which faults may be injected.
+type FaultInjector interface {
+	// SetFaults replaces the faults injected into messages
+	SetFaults(Faults) error
+	// Faults returns the faults currently injected into messages
+	Faults() Faults
+}
+
+// FaultyMessageService wraps a MessageService, injecting faults into the messages it sends and receives.
+// It is intended for testing the robustness of the protocols.
+type FaultyMessageService struct {
+	inner  MessageService
+	out    chan protocols.Message
+	logger *slog.Logger
+
+	mu     sync.RWMutex
+	faults Faults
+
+	quit chan struct{}
+	wg   *sync.WaitGroup
+}
+
+// NewFaultyMessageService returns a FaultyMessageService wrapping inner, which initially injects no faults.
+func NewFaultyMessageService(inner MessageService) *FaultyMessageService {
+	f := &FaultyMessageService{
+		inner:  inner,
+		out:    make(chan protocols.Message, 5),
+		logger: logging.ModuleLogger(logging.MESSAGESERVICE_MODULE),
+		quit:   make(chan struct{}),
+		wg:     &sync.WaitGroup{},
+	}
+
+	f.wg.Add(1)
+	go f.receive()
+	return f
+}
+
+// SetFaults replaces the faults injected into messages.
+func (f *FaultyMessageService) SetFaults(faults Faults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.faults = faults
+	f.faults.Partitioned = slices.Clone(faults.Partitioned)
+	return nil
+}
+
+// Faults returns the faults currently injected into messages.
+func (f *FaultyMessageService) Faults() Faults {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	faults := f.faults
+	faults.Partitioned = slices.Clone(f.faults.Partitioned)
+	return faults
+}
+
+func (f *FaultyMessageService) P2PMessages() <-chan protocols.Message {
+	return f.out
+}
+
+func (f *FaultyMessageService) SignRequests() <-chan p2pms.SignatureRequest {
+	return f.inner.SignRequests()
+}
+
+// Send sends the message, unless it is lost to a partition or dropped. It may be delayed or duplicated.
+func (f *FaultyMessageService) Send(msg protocols.Message) error {
+	faults := f.Faults()
+	if slices.Contains(faults.Partitioned, msg.To) {
+		f.logger.Debug("Fault injection: message lost to partition", "to", msg.To)
+		return nil
+	}
+	if rand.Float64() < faults.DropRate {
+		f.logger.Debug("Fault injection: message dropped", "to", msg.To)
+		return nil
+	}
+
+	copies := 1
+	if rand.Float64() < faults.DuplicateRate {
+		f.logger.Debug("Fault injection: message duplicated", "to", msg.To)
+		copies = 2
+	}
+
+	for i := 0; i < copies; i++ {
+		delay := faults.MinDelay
+		if faults.MaxDelay > faults.MinDelay {
+			delay += time.Duration(rand.Int63n(int64(faults.MaxDelay - faults.MinDelay)))
+		}
+		if delay == 0 {
+			if err := f.inner.Send(msg); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f.wg.Add(1)
+		go func() {
+			defer f.wg.Done()
+			select {
+			case <-time.After(delay):
+				if err := f.inner.Send(msg); err != nil {
+					f.logger.Error("Fault injection: could not send delayed message", "to", msg.To, "error", err)
+				}
+			case <-f.quit:
+			}
+		}()
+	}
+	return nil
+}
+
+// receive forwards the messages received by the inner message service, unless they are from a partitioned peer.
+func (f *FaultyMessageService) receive() {
+	defer f.wg.Done()
+	for {
+		select {
+		case msg, ok := <-f.inner.P2PMessages():
+			if !ok {
+				return
+			}
+			if slices.Contains(f.Faults().Partitioned, msg.From) {
+				f.logger.Debug("Fault injection: message lost to partition", "from", msg.From)
+				continue
+			}
+			select {
+			case f.out <- msg:
+			case <-f.quit:
+				return
+			}
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// Close discards any delayed messages and closes the inner message service.
+func (f *FaultyMessageService) Close() error {
+	close(f.quit)
+	f.wg.Wait()
+	return f.inner.Close()
+}
@@ -0,0 +1,94 @@
+package messageservice
+
+import (
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestFaultyMessageService(t *testing.T) {
+	broker := NewBroker()
+	carol, dave := types.Address{'c'}, types.Address{'d'}
+	carolMS := NewFaultyMessageService(NewTestMessageService(carol, broker, 0))
+	defer carolMS.Close()
+	daveMS := NewTestMessageService(dave, broker, 0)
+
+	toDave := protocols.CreateSignedProposalMessage(dave, consensus_channel.SignedProposal{TurnNum: 1})
+	toDave.From = carol
+	toCarol := protocols.CreateSignedProposalMessage(carol, consensus_channel.SignedProposal{TurnNum: 2})
+	toCarol.From = dave
+
+	expectMessages := func(out <-chan protocols.Message, n int, within time.Duration) {
+		t.Helper()
+		timeout := time.After(within)
+		for i := 0; i < n; i++ {
+			select {
+			case <-out:
+			case <-timeout:
+				t.Fatalf("expected %d messages, received %d", n, i)
+			}
+		}
+		select {
+		case <-out:
+			t.Fatalf("expected only %d messages", n)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+
+	// Without faults, messages are delivered once
+	if err := carolMS.Send(toDave); err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(daveMS.P2PMessages(), 1, time.Second)
+
+	if err := carolMS.SetFaults(Faults{DropRate: 2}); err == nil {
+		t.Fatal("expected an invalid drop rate to be rejected")
+	}
+
+	// Dropped messages are not delivered
+	if err := carolMS.SetFaults(Faults{DropRate: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := carolMS.Send(toDave); err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(daveMS.P2PMessages(), 0, 0)
+
+	// Duplicated messages are delivered twice, after the delay
+	if err := carolMS.SetFaults(Faults{DuplicateRate: 1, MinDelay: 100 * time.Millisecond, MaxDelay: 200 * time.Millisecond}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := carolMS.Send(toDave); err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(daveMS.P2PMessages(), 2, time.Second)
+	if time.Since(start) < 100*time.Millisecond {
+		t.Fatal("expected the messages to be delayed")
+	}
+
+	// Partitioned peers exchange no messages in either direction
+	if err := carolMS.SetFaults(Faults{Partitioned: []types.Address{dave}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := carolMS.Send(toDave); err != nil {
+		t.Fatal(err)
+	}
+	if err := daveMS.Send(toCarol); err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(daveMS.P2PMessages(), 0, 0)
+	expectMessages(carolMS.P2PMessages(), 0, 0)
+
+	// Once the partition heals, messages flow again
+	if err := carolMS.SetFaults(Faults{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := daveMS.Send(toCarol); err != nil {
+		t.Fatal(err)
+	}
+	expectMessages(carolMS.P2PMessages(), 1, time.Second)
+}
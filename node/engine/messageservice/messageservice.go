@@ -16,3 +16,10 @@ type MessageService interface {
 	// Close closes the message service
 	Close() error
 }
+
+// BlocklistingService may optionally be implemented by a MessageService to drop messages from blocked peers as they are
+// received, so that peers may be blocked by the identity their transport gives them (such as a libp2p peer id), as
+// well as by address. Messages from peers blocked by address are dropped by the engine regardless.
+type BlocklistingService interface {
+	SetBlocklist(p2pms.Blocklist)
+}
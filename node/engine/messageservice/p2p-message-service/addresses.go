@@ -0,0 +1,78 @@
+package p2pms
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+)
+
+// wssComponent is appended to the multiaddress of a tcp port which serves secure websockets
+var wssComponent = multiaddr.StringCast("/tls/ws")
+
+// ipMultiaddr returns the multiaddress of the tcp port at ip, which may be an IPv4 or an IPv6 address.
+func ipMultiaddr(ip net.IP, port int) (multiaddr.Multiaddr, error) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", ip4, port))
+	}
+	return multiaddr.NewMultiaddr(fmt.Sprintf("/ip6/%s/tcp/%d", ip, port))
+}
+
+// listenAddrs returns the multiaddresses to listen on the tcp port of every interface of the family.
+func listenAddrs(family netaddr.Family, port int) []string {
+	addrs := []string{}
+	if family.HasIPv4() {
+		addrs = append(addrs, fmt.Sprintf("/ip4/%s/tcp/%d", net.IPv4zero, port))
+	}
+	if family.HasIPv6() {
+		addrs = append(addrs, fmt.Sprintf("/ip6/%s/tcp/%d", net.IPv6unspecified, port))
+	}
+	return addrs
+}
+
+// publicAddrs returns the multiaddresses of the tcp port at the public IPs, given as a comma separated list so that a
+// dual stack node may give an address of each version. IPs which are not of the family are skipped, and returned as
+// an error along with any which cannot be parsed.
+func publicAddrs(publicIps string, family netaddr.Family, port int) ([]multiaddr.Multiaddr, error) {
+	addrs := []multiaddr.Multiaddr{}
+	skipped := []string{}
+	for _, s := range strings.Split(publicIps, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		ip := net.ParseIP(strings.Trim(s, "[]"))
+		if ip == nil || !family.Includes(ip) {
+			skipped = append(skipped, s)
+			continue
+		}
+		addr, err := ipMultiaddr(ip, port)
+		if err != nil {
+			return nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	if len(skipped) > 0 {
+		return addrs, fmt.Errorf("public ips %s are not %s addresses", strings.Join(skipped, ", "), family)
+	}
+	return addrs, nil
+}
+
+// isIPv6 returns whether the multiaddress is of an IPv6 address.
+func isIPv6(addr multiaddr.Multiaddr) bool {
+	protocols := addr.Protocols()
+	return len(protocols) > 0 && protocols[0].Code == multiaddr.P_IP6
+}
+
+// preferredAddr returns the first of the addresses of the IP version the family prefers, or the first address if there
+// is none of that version.
+func preferredAddr(addrs []multiaddr.Multiaddr, family netaddr.Family) multiaddr.Multiaddr {
+	for _, addr := range addrs {
+		if isIPv6(addr) == family.PrefersIPv6() {
+			return addr
+		}
+	}
+	return addrs[0]
+}
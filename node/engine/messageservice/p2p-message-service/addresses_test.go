@@ -0,0 +1,71 @@
+package p2pms
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/multiformats/go-multiaddr"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+)
+
+func TestListenAddrs(t *testing.T) {
+	testCases := map[netaddr.Family][]string{
+		netaddr.DualStack: {"/ip4/0.0.0.0/tcp/3005", "/ip6/::/tcp/3005"},
+		netaddr.IPv4:      {"/ip4/0.0.0.0/tcp/3005"},
+		netaddr.IPv6:      {"/ip6/::/tcp/3005"},
+	}
+	for family, want := range testCases {
+		if got := listenAddrs(family, 3005); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v, got %v", family, want, got)
+		}
+	}
+}
+
+func TestPublicAddrs(t *testing.T) {
+	strs := func(addrs []multiaddr.Multiaddr) []string {
+		s := []string{}
+		for _, a := range addrs {
+			s = append(s, a.String())
+		}
+		return s
+	}
+
+	addrs, err := publicAddrs("203.0.113.7, [2001:db8::7]", netaddr.DualStack, 3005)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"/ip4/203.0.113.7/tcp/3005", "/ip6/2001:db8::7/tcp/3005"}
+	if !reflect.DeepEqual(strs(addrs), want) {
+		t.Errorf("expected %v, got %v", want, strs(addrs))
+	}
+
+	// Addresses outside the family are skipped and reported
+	addrs, err = publicAddrs("203.0.113.7,2001:db8::7", netaddr.IPv6, 3005)
+	if err == nil {
+		t.Error("expected an error for the IPv4 address")
+	}
+	want = []string{"/ip6/2001:db8::7/tcp/3005"}
+	if !reflect.DeepEqual(strs(addrs), want) {
+		t.Errorf("expected %v, got %v", want, strs(addrs))
+	}
+
+	if _, err := publicAddrs("not-an-ip", netaddr.DualStack, 3005); err == nil {
+		t.Error("expected an error for an unparseable address")
+	}
+}
+
+func TestPreferredAddr(t *testing.T) {
+	addrs := []multiaddr.Multiaddr{
+		multiaddr.StringCast("/ip4/203.0.113.7/tcp/3005"),
+		multiaddr.StringCast("/ip6/2001:db8::7/tcp/3005"),
+	}
+	if got := preferredAddr(addrs, netaddr.DualStack); got != addrs[0] {
+		t.Errorf("expected the IPv4 address, got %s", got)
+	}
+	if got := preferredAddr(addrs, netaddr.DualStackIPv6); got != addrs[1] {
+		t.Errorf("expected the IPv6 address, got %s", got)
+	}
+	if got := preferredAddr(addrs[:1], netaddr.IPv6); got != addrs[0] {
+		t.Errorf("expected the only address, got %s", got)
+	}
+}
@@ -3,11 +3,13 @@ package p2pms
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/libp2p/go-libp2p"
@@ -18,8 +20,10 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	"github.com/libp2p/go-libp2p/p2p/transport/websocket"
 	"github.com/multiformats/go-multiaddr"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/netaddr"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
@@ -42,12 +46,32 @@ const (
 	BOOTSTRAP_SLEEP_DURATION = 100 * time.Millisecond // how often we check for bootpeers in Peerstore
 )
 
+// ErrWssWithoutTLS is the error of a message service configured with a wss port but no TLS config.
+const ErrWssWithoutTLS = types.ConstError("a wss port requires a tls config")
+
+// Blocklist decides whether messages received from a peer are dropped.
+type Blocklist interface {
+	// DropsMessage returns true if a message from the peer with the address and libp2p peer id should be dropped.
+	DropsMessage(from types.Address, peerId string) bool
+}
+
 type MessageOpts struct {
+	// PkBytes is the secret key of the node's libp2p identity, which is usually its channel secret key. If it is nil, a
+	// key is generated, so that the node's peer id changes each time it starts.
 	PkBytes   []byte
 	Port      int
 	BootPeers []string
-	PublicIp  string
-	SCAddr    types.Address
+	// PublicIp is the IP address advertised to peers. A dual stack node may give an IPv4 and an IPv6 address, separated
+	// by a comma.
+	PublicIp string
+	SCAddr   types.Address
+	// AddressFamily is the IP versions to listen on. The zero value listens on both, preferring IPv4.
+	AddressFamily netaddr.Family
+	// WssPort, if not zero, is the port of a secure websocket (wss) listener, alongside the tcp listener on Port, for
+	// peers which can only reach the node through TLS. It requires TLSConfig.
+	WssPort int
+	// TLSConfig is the config the wss listener serves TLS with.
+	TLSConfig *tls.Config
 }
 
 // P2PMessageService is a rudimentary message service that uses TCP to send and receive messages.
@@ -61,6 +85,7 @@ type P2PMessageService struct {
 	p2pHost     host.Host
 	dht         *dht.IpfsDHT
 	newPeerInfo chan basicPeerInfo
+	blocklist   atomic.Value // holds the Blocklist, if one has been set
 	logger      *slog.Logger
 
 	MultiAddr string
@@ -75,27 +100,52 @@ func NewMessageService(opts MessageOpts) *P2PMessageService {
 		newPeerInfo:     make(chan basicPeerInfo, BUFFER_SIZE),
 		peers:           &safesync.Map[peer.ID]{},
 		scAddr:          opts.SCAddr,
-		logger:          logging.LoggerWithAddress(slog.Default(), opts.SCAddr),
+		logger:          logging.LoggerWithAddress(logging.ModuleLogger(logging.MESSAGESERVICE_MODULE), opts.SCAddr),
 	}
 
-	addressFactory := func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
-		extMultiAddr, err := multiaddr.NewMultiaddr(fmt.Sprintf("/ip4/%s/tcp/%d", opts.PublicIp, opts.Port))
+	family := opts.AddressFamily
+	if family == "" {
+		family = netaddr.DualStack
+	}
+	extMultiAddrs, err := publicAddrs(opts.PublicIp, family, opts.Port)
+	if err != nil {
+		ms.logger.Error("failed to create publicIp multiaddress", "err", err)
+	}
+	listenMultiAddrs := listenAddrs(family, opts.Port)
+	if opts.WssPort != 0 {
+		if opts.TLSConfig == nil {
+			ms.checkError(ErrWssWithoutTLS)
+		}
+		wssAddrs, err := publicAddrs(opts.PublicIp, family, opts.WssPort)
 		if err != nil {
-			ms.logger.Error("failed to create publicIp multiaddress", "err", err)
-			return addrs
+			ms.logger.Error("failed to create publicIp wss multiaddress", "err", err)
+		}
+		for _, addr := range wssAddrs {
+			extMultiAddrs = append(extMultiAddrs, addr.Encapsulate(wssComponent))
+		}
+		for _, addr := range listenAddrs(family, opts.WssPort) {
+			listenMultiAddrs = append(listenMultiAddrs, addr+wssComponent.String())
 		}
-		addrs = append(addrs, extMultiAddr)
-		return addrs
+	}
+	addressFactory := func(addrs []multiaddr.Multiaddr) []multiaddr.Multiaddr {
+		return append(addrs, extMultiAddrs...)
 	}
 
-	privateKey, err := p2pcrypto.UnmarshalSecp256k1PrivateKey(opts.PkBytes)
+	var privateKey p2pcrypto.PrivKey
+	if opts.PkBytes != nil {
+		privateKey, err = p2pcrypto.UnmarshalSecp256k1PrivateKey(opts.PkBytes)
+	} else {
+		privateKey, _, err = p2pcrypto.GenerateSecp256k1Key(rand.Reader)
+	}
 	ms.checkError(err)
 
 	options := []libp2p.Option{
 		libp2p.Identity(privateKey),
 		libp2p.AddrsFactory(addressFactory),
-		libp2p.ListenAddrStrings(fmt.Sprintf("/ip4/%s/tcp/%d", "0.0.0.0", opts.Port)),
+		libp2p.ListenAddrStrings(listenMultiAddrs...),
 		libp2p.Transport(tcp.NewTCPTransport),
+		// Peers' wss addresses can be dialled whether or not this node listens for wss
+		libp2p.Transport(websocket.New, websocket.WithTLSConfig(opts.TLSConfig)),
 		libp2p.NATPortMap(),
 		libp2p.EnableNATService(),
 		libp2p.DefaultMuxers,
@@ -114,7 +164,7 @@ func NewMessageService(opts MessageOpts) *P2PMessageService {
 	addrs, err := peer.AddrInfoToP2pAddrs(&peerInfo)
 	ms.checkError(err)
 
-	ms.MultiAddr = addrs[0].String()
+	ms.MultiAddr = preferredAddr(addrs, family).String()
 	ms.logger.Info("libp2p node initialized", "multiaddrs", addrs)
 
 	err = ms.setupDht(opts.BootPeers)
@@ -272,9 +322,19 @@ func (ms *P2PMessageService) msgStreamHandler(stream network.Stream) {
 		ms.logger.Error("error deserializing message", "err", err)
 		return
 	}
+	if blocklist, ok := ms.blocklist.Load().(Blocklist); ok && blocklist.DropsMessage(m.From, stream.Conn().RemotePeer().String()) {
+		ms.logger.Debug("dropping message from a blocked peer", "from", m.From, "peerId", stream.Conn().RemotePeer())
+		return
+	}
+	logging.LoggerWithCorrelationId(ms.logger, m.CorrelationId).Debug("received message", "from", m.From, "peerId", stream.Conn().RemotePeer())
 	ms.toEngine <- m
 }
 
+// SetBlocklist drops the messages received from peers which the blocklist blocks, by address or by libp2p peer id.
+func (ms *P2PMessageService) SetBlocklist(blocklist Blocklist) {
+	ms.blocklist.Store(blocklist)
+}
+
 func (ms *P2PMessageService) getPeerIdFromDht(scaddr string) (peer.ID, error) {
 	recordBytes, err := ms.dht.GetValue(context.Background(), DHT_RECORD_PREFIX+scaddr)
 	if err != nil {
@@ -305,19 +365,20 @@ func (ms *P2PMessageService) Send(msg protocols.Message) error {
 	if err != nil {
 		return err
 	}
+	logger := logging.LoggerWithCorrelationId(ms.logger, msg.CorrelationId)
 
 	// First try to get peerId from local "peers" map. If the address is not found there,
 	// query the dht to retrieve the peerId, then store in local map for next time
 	peerId, ok := ms.peers.Load(msg.To.String())
 	if !ok {
-		ms.logger.Warn("did not find scAddr in local peers map, fetching from DHT", "scAddr", msg.To.String())
+		logger.Warn("did not find scAddr in local peers map, fetching from DHT", "scAddr", msg.To.String())
 		peerId, err = ms.getPeerIdFromDht(msg.To.String())
 		if err != nil {
-			ms.logger.Error("did not find scAddr in DHT", "scAddr", msg.To.String())
+			logger.Error("did not find scAddr in DHT", "scAddr", msg.To.String())
 			return err
 		}
 	} else {
-		ms.logger.Debug("found scAddr in local cache", "scAddr", msg.To.String(), "peerId", peerId)
+		logger.Debug("found scAddr in local cache", "scAddr", msg.To.String(), "peerId", peerId)
 	}
 
 	for i := 0; i < NUM_CONNECT_ATTEMPTS; i++ {
@@ -331,12 +392,16 @@ func (ms *P2PMessageService) Send(msg protocols.Message) error {
 
 			writer.Flush()
 			s.Close()
+			logger.Debug("sent message", "to", msg.To.String(), "peerId", peerId)
 			return nil
 		}
 
-		ms.logger.Warn("error opening stream", "err", err, "attempt", i, "to", msg.To.String())
+		logger.Warn("error opening stream", "err", err, "attempt", i, "to", msg.To.String())
 		time.Sleep(RETRY_SLEEP_DURATION)
 	}
+
+	// The peer may have restarted with a new identity or address, so the next send should consult the DHT again
+	ms.peers.Delete(msg.To.String())
 	return nil
 }
 
@@ -0,0 +1,86 @@
+package engine
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// Metrics receives measurements of the node, such as the latency of payments, for an application to export to its
+// monitoring system. Implementations must be safe for concurrent use.
+type Metrics interface {
+	// IncCounter adds one to the named counter.
+	IncCounter(name string, labels map[string]string)
+	// RecordDuration records an observation of the named duration.
+	RecordDuration(name string, d time.Duration, labels map[string]string)
+	// SetGauge sets the named gauge to value.
+	SetGauge(name string, value float64, labels map[string]string)
+}
+
+// NoopMetrics discards every measurement. It is the Metrics of an engine constructed without WithMetrics.
+type NoopMetrics struct{}
+
+func (NoopMetrics) IncCounter(string, map[string]string) {}
+
+func (NoopMetrics) RecordDuration(string, time.Duration, map[string]string) {}
+
+func (NoopMetrics) SetGauge(string, float64, map[string]string) {}
+
+const (
+	// PaymentLatencyMetric is the name of the duration recorded for each stage of a payment, labelled by "stage".
+	PaymentLatencyMetric = "payment_latency"
+	// ObjectiveCrankMetric is the name of the duration recorded for each crank of an objective, labelled by the
+	// "protocol" of the objective.
+	ObjectiveCrankMetric = "objective_crank"
+	// QueueDepthMetric is the name of the gauge of the inputs waiting to be handled by the engine, labelled by "queue":
+	// one of "messages", "chain_events", "proposals" or "sign_requests".
+	QueueDepthMetric = "queue_depth"
+	// ChainEventLagMetric is the name of the gauge of how many blocks the last chain event handled was behind the last
+	// confirmed block.
+	ChainEventLagMetric = "chain_event_lag"
+	// VouchersSentMetric is the name of the counter of vouchers sent to pay through our payment channels.
+	VouchersSentMetric = "vouchers_sent"
+)
+
+// WithMetrics makes the engine record measurements with metrics.
+func WithMetrics(metrics Metrics) Option {
+	return func(e *Engine) {
+		e.metrics = metrics
+		e.paymentTimer.metrics = metrics
+		e.blocklist.metrics = metrics
+	}
+}
+
+// recordQueueDepths sets the gauges of the inputs waiting to be handled.
+func (e *Engine) recordQueueDepths() {
+	e.metrics.SetGauge(QueueDepthMetric, float64(len(e.fromMsg)), map[string]string{"queue": "messages"})
+	e.metrics.SetGauge(QueueDepthMetric, float64(len(e.fromChain)), map[string]string{"queue": "chain_events"})
+	e.metrics.SetGauge(QueueDepthMetric, float64(len(e.fromLedger)), map[string]string{"queue": "proposals"})
+	e.metrics.SetGauge(QueueDepthMetric, float64(len(e.signRequests)), map[string]string{"queue": "sign_requests"})
+}
+
+// recordChainEventLag sets the gauge of how far behind the last confirmed block the chain event is.
+func (e *Engine) recordChainEventLag(event chainservice.Event) {
+	lag := uint64(0)
+	if confirmed := e.chain.GetLastConfirmedBlockNum(); confirmed > event.BlockNum() {
+		lag = confirmed - event.BlockNum()
+	}
+	e.metrics.SetGauge(ChainEventLagMetric, float64(lag), nil)
+}
+
+// objectiveProtocol returns the name of the protocol of the objective, which prefixes its id.
+func objectiveProtocol(id protocols.ObjectiveId) string {
+	protocol, _, _ := strings.Cut(string(id), "-")
+	return protocol
+}
+
+// WithLogger makes the engine log with logger rather than the engine's module logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(e *Engine) {
+		e.logger = logging.LoggerWithAddress(logger, *e.store.GetAddress())
+	}
+}
@@ -0,0 +1,178 @@
+package engine
+
+import (
+	"math"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// PaymentStage is a stretch of a payment's path through the node, between two of the hops timed by PaymentTiming.
+type PaymentStage string
+
+const (
+	PaymentQueued   PaymentStage = "queued"   // From the API enqueuing an outgoing payment to the engine handling it
+	PaymentSending  PaymentStage = "sending"  // From the engine handling an outgoing payment to its voucher being sent
+	PaymentOutgoing PaymentStage = "outgoing" // From the API enqueuing an outgoing payment to its voucher being sent
+	PaymentIncoming PaymentStage = "incoming" // From a peer's voucher being received to it being registered
+)
+
+// paymentLatencySamples is the number of the most recent latencies of each stage from which percentiles are computed.
+const paymentLatencySamples = 1024
+
+// LatencyPercentiles summarises the latencies measured for a stage of payments.
+type LatencyPercentiles struct {
+	Count int // The number of latencies the percentiles are computed from
+	P50   time.Duration
+	P99   time.Duration
+}
+
+// PaymentTiming records when a payment reached each hop through this node. An outgoing payment is Enqueued by the API,
+// Cranked by the engine and Sent to the payee; an incoming payment is Received from the payer and Registered with the
+// voucher manager. The hops of the other direction are zero.
+type PaymentTiming struct {
+	ChannelId  types.Destination
+	Amount     *big.Int // The amount of the voucher: the total paid through the channel
+	Enqueued   time.Time
+	Cranked    time.Time
+	Sent       time.Time
+	Received   time.Time
+	Registered time.Time
+}
+
+// paymentTimer measures the latency of payments through the engine, which may be read from outside its run loop.
+type paymentTimer struct {
+	mu sync.Mutex
+	// sending holds the timings of outgoing payments whose vouchers have not yet been sent, keyed by voucherKey
+	sending map[string]PaymentTiming
+	// samples holds the most recent latencies of each stage, as a ring buffer of paymentLatencySamples
+	samples map[PaymentStage][]time.Duration
+	next    map[PaymentStage]int
+	// recent holds the timings of the most recent payments, up to recentCapacity, which is zero unless enabled
+	recent         []PaymentTiming
+	recentCapacity int
+	metrics        Metrics
+}
+
+func newPaymentTimer() *paymentTimer {
+	return &paymentTimer{
+		sending: make(map[string]PaymentTiming),
+		samples: make(map[PaymentStage][]time.Duration),
+		next:    make(map[PaymentStage]int),
+		metrics: NoopMetrics{},
+	}
+}
+
+func voucherKey(channelId types.Destination, amount *big.Int) string {
+	return channelId.String() + ":" + amount.String()
+}
+
+// recordCranked notes that the engine has handled an outgoing payment, whose voucher is yet to be sent.
+func (pt *paymentTimer) recordCranked(voucher payments.Voucher, enqueued, cranked time.Time) {
+	if enqueued.IsZero() {
+		enqueued = cranked
+	}
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.sending[voucherKey(voucher.ChannelId, voucher.Amount)] = PaymentTiming{
+		ChannelId: voucher.ChannelId,
+		Amount:    new(big.Int).Set(voucher.Amount),
+		Enqueued:  enqueued,
+		Cranked:   cranked,
+	}
+}
+
+// recordSent notes that the voucher of an outgoing payment has been sent. Vouchers which were not recorded as cranked,
+// such as those resent for repeated payments, are ignored.
+func (pt *paymentTimer) recordSent(voucher payments.Voucher, sent time.Time) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	key := voucherKey(voucher.ChannelId, voucher.Amount)
+	timing, ok := pt.sending[key]
+	if !ok {
+		return
+	}
+	delete(pt.sending, key)
+	timing.Sent = sent
+	pt.sample(PaymentQueued, timing.Cranked.Sub(timing.Enqueued))
+	pt.sample(PaymentSending, timing.Sent.Sub(timing.Cranked))
+	pt.sample(PaymentOutgoing, timing.Sent.Sub(timing.Enqueued))
+	pt.remember(timing)
+}
+
+// recordIncoming notes that a peer's voucher was received, and has been registered.
+func (pt *paymentTimer) recordIncoming(voucher payments.Voucher, received, registered time.Time) {
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.sample(PaymentIncoming, registered.Sub(received))
+	pt.remember(PaymentTiming{ChannelId: voucher.ChannelId, Amount: new(big.Int).Set(voucher.Amount), Received: received, Registered: registered})
+}
+
+// sample adds the latency to the stage's samples, overwriting the oldest once paymentLatencySamples are held.
+// It must be called with pt.mu held.
+func (pt *paymentTimer) sample(stage PaymentStage, latency time.Duration) {
+	pt.metrics.RecordDuration(PaymentLatencyMetric, latency, map[string]string{"stage": string(stage)})
+	if len(pt.samples[stage]) < paymentLatencySamples {
+		pt.samples[stage] = append(pt.samples[stage], latency)
+		return
+	}
+	pt.samples[stage][pt.next[stage]] = latency
+	pt.next[stage] = (pt.next[stage] + 1) % paymentLatencySamples
+}
+
+// remember keeps the timing, if recent timings are being kept. It must be called with pt.mu held.
+func (pt *paymentTimer) remember(timing PaymentTiming) {
+	if pt.recentCapacity == 0 {
+		return
+	}
+	pt.recent = append(pt.recent, timing)
+	if len(pt.recent) > pt.recentCapacity {
+		pt.recent = pt.recent[len(pt.recent)-pt.recentCapacity:]
+	}
+}
+
+// percentile returns the pth percentile (0 < p <= 1) of the sorted latencies, by the nearest rank.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+// PaymentLatencies returns the median and 99th percentile latency of each stage of the most recent payments made or
+// received through the engine. Stages with no payments are omitted.
+func (e *Engine) PaymentLatencies() map[PaymentStage]LatencyPercentiles {
+	pt := e.paymentTimer
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+
+	latencies := make(map[PaymentStage]LatencyPercentiles)
+	for stage, samples := range pt.samples {
+		sorted := slices.Clone(samples)
+		slices.Sort(sorted)
+		latencies[stage] = LatencyPercentiles{Count: len(sorted), P50: percentile(sorted, 0.5), P99: percentile(sorted, 0.99)}
+	}
+	return latencies
+}
+
+// RecordPaymentTimings keeps the timings of up to capacity of the most recent payments, for RecentPaymentTimings.
+// A capacity of zero stops keeping them.
+func (e *Engine) RecordPaymentTimings(capacity int) {
+	pt := e.paymentTimer
+	pt.mu.Lock()
+	defer pt.mu.Unlock()
+	pt.recentCapacity = max(capacity, 0)
+	if len(pt.recent) > pt.recentCapacity {
+		pt.recent = pt.recent[len(pt.recent)-pt.recentCapacity:]
+	}
+}
+
+// RecentPaymentTimings returns the timings of the most recent payments made or received through the engine, oldest
+// first, if RecordPaymentTimings has been called.
+func (e *Engine) RecentPaymentTimings() []PaymentTiming {
+	e.paymentTimer.mu.Lock()
+	defer e.paymentTimer.mu.Unlock()
+	return slices.Clone(e.paymentTimer.recent)
+}
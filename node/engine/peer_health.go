@@ -0,0 +1,163 @@
+package engine
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// unansweredMessageTimeout is how long we wait for a reply to a message before giving up on measuring its round trip.
+const unansweredMessageTimeout = time.Minute
+
+// PeerStats reports how responsive and reliable a peer has been since the engine started.
+type PeerStats struct {
+	// RoundTrip is the smoothed time between sending the peer a message and receiving its reply, or zero if no round
+	// trip has been measured
+	RoundTrip time.Duration
+	// Succeeded is the number of virtual channels the peer has funded as an intermediary for us
+	Succeeded uint64
+	// Failed is the number of virtual channels which the peer was asked to fund as an intermediary, but which were
+	// rejected or timed out
+	Failed uint64
+}
+
+// SuccessRate estimates the probability that the peer successfully funds a virtual channel as an intermediary.
+// Peers we know little about are given the benefit of the doubt, tending towards 1/2.
+func (s PeerStats) SuccessRate() float64 {
+	return float64(s.Succeeded+1) / float64(s.Succeeded+s.Failed+2)
+}
+
+// Healthier returns true if s describes a healthier peer than other: one more likely to succeed, or as likely to
+// succeed and quicker to reply. A peer with a measured round trip is preferred over one without.
+func (s PeerStats) Healthier(other PeerStats) bool {
+	if s.SuccessRate() != other.SuccessRate() {
+		return s.SuccessRate() > other.SuccessRate()
+	}
+	if (s.RoundTrip == 0) != (other.RoundTrip == 0) {
+		return s.RoundTrip != 0
+	}
+	return s.RoundTrip < other.RoundTrip
+}
+
+// awaitedReply identifies a reply we expect from a peer: to an objective payload or to a quote request.
+type awaitedReply struct {
+	peer types.Address
+	key  string
+}
+
+// peerHealth tracks the PeerStats of each peer, which may be read from outside the engine's run loop.
+type peerHealth struct {
+	mu       sync.Mutex
+	stats    map[types.Address]PeerStats
+	awaiting map[awaitedReply]time.Time
+}
+
+func newPeerHealth() *peerHealth {
+	return &peerHealth{stats: make(map[types.Address]PeerStats), awaiting: make(map[awaitedReply]time.Time)}
+}
+
+// repliesTo returns the replies which the message, sent or received, awaits or answers.
+func repliesTo(peer types.Address, message protocols.Message) []awaitedReply {
+	replies := []awaitedReply{}
+	for _, payload := range message.ObjectivePayloads {
+		replies = append(replies, awaitedReply{peer, string(payload.ObjectiveId)})
+	}
+	for _, request := range message.QuoteRequests {
+		replies = append(replies, awaitedReply{peer, "quote-" + strconv.FormatUint(request.Id, 10)})
+	}
+	for _, quote := range message.Quotes {
+		replies = append(replies, awaitedReply{peer, "quote-" + strconv.FormatUint(quote.RequestId, 10)})
+	}
+	for _, request := range message.ProbeRequests {
+		replies = append(replies, awaitedReply{peer, "probe-" + strconv.FormatUint(request.Id, 10)})
+	}
+	for _, probe := range message.Probes {
+		replies = append(replies, awaitedReply{peer, "probe-" + strconv.FormatUint(probe.RequestId, 10)})
+	}
+	return replies
+}
+
+// recordSent notes that we have sent the message, so that the round trip to its recipient may be measured when they reply.
+func (ph *peerHealth) recordSent(message protocols.Message, at time.Time) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	for reply, sent := range ph.awaiting {
+		if at.Sub(sent) > unansweredMessageTimeout {
+			delete(ph.awaiting, reply)
+		}
+	}
+	for _, reply := range repliesTo(message.To, message) {
+		if _, ok := ph.awaiting[reply]; !ok {
+			ph.awaiting[reply] = at
+		}
+	}
+}
+
+// recordReceived measures the round trip to the sender of the message, if it replies to a message we sent.
+func (ph *peerHealth) recordReceived(message protocols.Message, at time.Time) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	for _, reply := range repliesTo(message.From, message) {
+		sent, ok := ph.awaiting[reply]
+		if !ok {
+			continue
+		}
+		delete(ph.awaiting, reply)
+
+		sample := at.Sub(sent)
+		stats := ph.stats[message.From]
+		if stats.RoundTrip == 0 {
+			stats.RoundTrip = sample
+		} else {
+			// An exponentially weighted moving average, as used for TCP's smoothed round trip time
+			stats.RoundTrip = (7*stats.RoundTrip + sample) / 8
+		}
+		ph.stats[message.From] = stats
+	}
+}
+
+// recordOutcomes counts virtual funding objectives which have completed or been rejected towards the success rate of
+// their intermediaries.
+func (ph *peerHealth) recordOutcomes(objectives []protocols.Objective, me types.Address) {
+	ph.mu.Lock()
+	defer ph.mu.Unlock()
+
+	for _, o := range objectives {
+		vfo, ok := o.(*virtualfund.Objective)
+		if !ok {
+			continue
+		}
+		participants := vfo.V.Participants
+		for _, intermediary := range participants[1 : len(participants)-1] {
+			if intermediary == me {
+				continue
+			}
+			stats := ph.stats[intermediary]
+			switch vfo.GetStatus() {
+			case protocols.Completed:
+				stats.Succeeded++
+			case protocols.Rejected:
+				stats.Failed++
+			}
+			ph.stats[intermediary] = stats
+		}
+	}
+}
+
+// PeerStats returns the stats of every peer we have measured.
+func (e *Engine) PeerStats() map[types.Address]PeerStats {
+	e.peerHealth.mu.Lock()
+	defer e.peerHealth.mu.Unlock()
+
+	stats := make(map[types.Address]PeerStats, len(e.peerHealth.stats))
+	for peer, s := range e.peerHealth.stats {
+		stats[peer] = s
+	}
+	return stats
+}
@@ -0,0 +1,120 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+)
+
+// submitTransaction submits the transaction to the chain. If the chain service is a chainservice.TransactionTracker,
+// deposits and conclusions are recorded in the store, with the hashes of the chain transactions submitted for them, until
+// the objective which submitted them completes, so that they can be reconciled with the chain when the node restarts.
+func (e *Engine) submitTransaction(tx protocols.ChainTransaction) error {
+	tracker, ok := e.chain.(chainservice.TransactionTracker)
+	if !ok {
+		return e.chain.SendTransaction(tx)
+	}
+	pt, ok := store.NewPendingTransaction(tx)
+	if !ok {
+		return e.chain.SendTransaction(tx)
+	}
+	return e.submitPendingTransaction(tracker, pt, false)
+}
+
+// submitPendingTransaction submits the recorded transaction, and records the hashes of the chain transactions submitted for it.
+func (e *Engine) submitPendingTransaction(tracker chainservice.TransactionTracker, pt store.PendingTransaction, fillNonceGap bool) error {
+	hashes, err := tracker.SendTrackedTransaction(pt.Transaction(), fillNonceGap)
+	if len(hashes) > 0 {
+		pt.TxHashes, pt.Submitted = hashes, time.Now()
+		if setErr := e.store.SetPendingTransaction(pt); setErr != nil {
+			return setErr
+		}
+	}
+	return err
+}
+
+// reconcilePendingTransactions looks up what has become of the transactions recorded as pending when the node stopped.
+// Transactions which were mined, and those of objectives which are no longer running, are forgotten. Transactions
+// which were dropped before any part of them was mined are resubmitted. If nothing else is pending, the first takes
+// the nonce following the last mined transaction, filling any gap left by the dropped transactions.
+//
+// A deposit of several assets is submitted as several chain transactions. If only some of them were mined, the rest
+// are not resubmitted, since the assets they deposit cannot be told apart; the objective's funding deadline applies.
+func (e *Engine) reconcilePendingTransactions(ctx context.Context) error {
+	tracker, ok := e.chain.(chainservice.TransactionTracker)
+	if !ok {
+		return nil
+	}
+	pending, err := e.store.GetPendingTransactions()
+	if err != nil {
+		return err
+	}
+
+	dropped := []store.PendingTransaction{}
+	stillPending := false
+	for _, pt := range pending {
+		o, owned := e.store.GetObjectiveByChannelId(pt.ChannelId)
+		if !owned || o.GetStatus() != protocols.Approved {
+			if err := e.store.RemovePendingTransaction(pt.ChannelId); err != nil {
+				return err
+			}
+			continue
+		}
+
+		counts := map[chainservice.TxStatus]int{}
+		var lookupErr error
+		for _, hash := range pt.TxHashes {
+			status, err := tracker.TransactionStatus(ctx, hash)
+			if err != nil {
+				lookupErr = err
+				break
+			}
+			counts[status]++
+		}
+
+		switch {
+		case lookupErr != nil:
+			e.logger.Warn("Could not look up a pending chain transaction", "channel", pt.ChannelId, "error", lookupErr)
+			stillPending = true
+		case counts[chainservice.TxPending] > 0:
+			stillPending = true
+		case counts[chainservice.TxDropped] == len(pt.TxHashes):
+			dropped = append(dropped, pt)
+		default:
+			if counts[chainservice.TxDropped] > 0 {
+				e.logger.Warn("A chain transaction was only partly mined, and will not be resubmitted", "channel", pt.ChannelId, "submitted", pt.Submitted)
+			}
+			if counts[chainservice.TxReverted] > 0 {
+				e.logger.Warn("A chain transaction reverted", "channel", pt.ChannelId, "submitted", pt.Submitted)
+			}
+			if err := e.store.RemovePendingTransaction(pt.ChannelId); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, pt := range dropped {
+		e.logger.Info("Resubmitting a dropped chain transaction", "channel", pt.ChannelId, "submitted", pt.Submitted, "fillNonceGap", !stillPending)
+		if err := e.submitPendingTransaction(tracker, pt, !stillPending); err != nil {
+			return err
+		}
+		stillPending = true
+	}
+	return nil
+}
+
+// forgetPendingTransactions removes the records of the transactions submitted by the objectives, which have completed.
+func (e *Engine) forgetPendingTransactions(objectives []protocols.Objective) error {
+	if _, ok := e.chain.(chainservice.TransactionTracker); !ok {
+		return nil
+	}
+	for _, o := range objectives {
+		if err := e.store.RemovePendingTransaction(o.OwnsChannel()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
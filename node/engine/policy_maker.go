@@ -1,16 +1,246 @@
 package engine
 
-import "github.com/statechannels/go-nitro/protocols"
+import (
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
 
 // PolicyMaker is used to decide whether to approve or reject an objective
 type PolicyMaker interface {
 	ShouldApprove(o protocols.Objective) bool
 }
 
+// FeeQuoter may optionally be implemented by a PolicyMaker to decide the fee an intermediary
+// quotes for routing a virtual channel. If the PolicyMaker does not implement it, a zero fee is quoted.
+type FeeQuoter interface {
+	QuoteFee(requester types.Address, request protocols.QuoteRequest) *big.Int
+}
+
+// DepositSafetyPolicy may optionally be implemented by a PolicyMaker to require that a counterparty's deposit, which must
+// precede ours when directly funding a channel, is buried under a number of confirmed blocks before we deposit.
+// If the PolicyMaker does not implement it (or the depth is zero), we deposit as soon as the prior deposit is seen.
+type DepositSafetyPolicy interface {
+	RequiredDepositDepth() uint64
+}
+
+// ProposalTimeoutPolicy may optionally be implemented by a PolicyMaker to limit how long a virtual funding objective
+// may wait for ledger guarantees to be countersigned. Once the timeout passes, the guarantees we proposed are withdrawn
+// and the objective is rejected, so that ledger capacity is not held hostage by unresponsive peers.
+// If the PolicyMaker does not implement it (or the timeout is zero), objectives wait indefinitely.
+type ProposalTimeoutPolicy interface {
+	ProposalTimeout() time.Duration
+}
+
+// ObjectiveCollectionPolicy may optionally be implemented by a PolicyMaker to periodically collect objectives which have
+// completed or been rejected, replacing them in the store with compact summaries once nothing refers to them.
+// If the PolicyMaker does not implement it (or the interval is zero), objectives are kept indefinitely.
+type ObjectiveCollectionPolicy interface {
+	CollectionInterval() time.Duration
+}
+
+// ExternalFundingPolicy may optionally be implemented by a PolicyMaker to treat ledger channels with some counterparties
+// as funded externally (by a relationship established out-of-band), so that they are opened without any deposits.
+// This allows a hub to serve virtual-only nodes, which have no connection to the chain.
+// If the PolicyMaker does not implement it, ledger channels are funded on chain (unless we are virtual-only ourselves).
+type ExternalFundingPolicy interface {
+	FundsExternally(counterparty types.Address) bool
+}
+
+// AutoDefundPolicy may optionally be implemented by a PolicyMaker to close payment channels we are paid through once
+// their remaining funds fall to a threshold, freeing the ledger capacity which they hold with intermediaries.
+// If the PolicyMaker does not implement it (or returns false), payment channels are only closed on request.
+type AutoDefundPolicy interface {
+	// AutoDefundThreshold returns the remaining funds at or below which a payment channel is closed, and whether
+	// payment channels should be closed automatically at all.
+	AutoDefundThreshold() (*big.Int, bool)
+}
+
+// GuaranteeExpiryPolicy may optionally be implemented by a PolicyMaker to give the ledger guarantees we propose for
+// virtual channels an expiry. Whenever a guarantee in one of our ledger channels has expired (whoever proposed it) and
+// we are an intermediary of the virtual channel it funds, we reclaim the guaranteed funds: first off-chain, by defunding
+// the virtual channel, then, if that does not complete in time, on chain, by challenging the ledger channel.
+// If the PolicyMaker does not implement it (or the lifetime is zero), the guarantees we propose never expire.
+type GuaranteeExpiryPolicy interface {
+	// GuaranteeLifetime returns how long after a virtual funding objective starts its guarantees expire.
+	GuaranteeLifetime() time.Duration
+	// OffChainReclaimTimeout returns how long we try to reclaim an expired guarantee off-chain before challenging the
+	// ledger channel on chain. Zero means we never challenge.
+	OffChainReclaimTimeout() time.Duration
+}
+
+// AssetPolicy may optionally be implemented by a PolicyMaker to limit the assets which the outcomes of channels we fund
+// may hold. Funding objectives proposed by peers for channels holding other assets are rejected, so that a hub is not
+// exposed to junk tokens. The native token is the zero address.
+// If the PolicyMaker does not implement it, any asset is accepted.
+type AssetPolicy interface {
+	AllowsAsset(asset types.Address) bool
+}
+
+// ChannelSizePolicy may optionally be implemented by a PolicyMaker to tell counterparties which probe us (see
+// protocols.ProbeRequest) whether we would fund a channel of the proposed size with them. deposit is the counterparty's
+// deposit and ourDeposit is ours. It only answers probes: a PolicyMaker which limits the size of channels should also
+// refuse such objectives in ShouldApprove.
+// If the PolicyMaker does not implement it, channels of any size are accepted.
+type ChannelSizePolicy interface {
+	AcceptsChannelSize(counterparty types.Address, asset types.Address, deposit *big.Int, ourDeposit *big.Int) bool
+}
+
+// PolicyCaps are the limits of a policy which may be changed while the node runs.
+type PolicyCaps struct {
+	DepositSafetyDepth      uint64
+	CountersignatureTimeout time.Duration
+	MaxObjectivesPerPeer    int
+	MaxObjectives           int
+	QueueExcessObjectives   bool
+	AutoDefund              bool
+	AutoDefundAt            *big.Int
+	PrioritizeExitsDraining bool
+	PrioritizeExitsPressure bool
+}
+
+// AdjustablePolicy may optionally be implemented by a PolicyMaker whose caps may be changed while the node runs.
+// The engine reads the caps each time it applies them, so a change takes effect immediately.
+type AdjustablePolicy interface {
+	Caps() PolicyCaps
+	SetCaps(PolicyCaps)
+}
+
 // PermissivePolicy is a policy maker that decides to approve every unapproved objective
-type PermissivePolicy struct{}
+type PermissivePolicy struct {
+	mu sync.RWMutex // guards the fields which are PolicyCaps, as they may be adjusted while the engine reads them
+
+	// DepositSafetyDepth is the number of confirmed blocks a counterparty's prior deposit must be buried under before we deposit
+	DepositSafetyDepth uint64
+	// CountersignatureTimeout is how long a virtual funding objective may wait for ledger guarantees to be countersigned
+	CountersignatureTimeout time.Duration
+	// MaxObjectivesPerPeer is the maximum number of objectives proposed by any one peer which may be in progress at once
+	MaxObjectivesPerPeer int
+	// MaxObjectives is the maximum number of objectives proposed by all peers which may be in progress at once
+	MaxObjectives int
+	// QueueExcessObjectives queues objectives over MaxObjectivesPerPeer or MaxObjectives, rather than rejecting them
+	QueueExcessObjectives bool
+	// ObjectiveCollectionInterval is how often completed and rejected objectives are collected
+	ObjectiveCollectionInterval time.Duration
+	// ExternallyFundedPeers are the counterparties whose ledger channels with us are funded externally
+	ExternallyFundedPeers []types.Address
+	// AutoDefund closes payment channels we are paid through once their remaining funds are at most AutoDefundAt
+	AutoDefund   bool
+	AutoDefundAt *big.Int // nil is treated as zero
+	// GuaranteeExpiry is how long after a virtual funding objective starts the ledger guarantees we propose expire
+	GuaranteeExpiry time.Duration
+	// ReclaimTimeout is how long we try to reclaim an expired guarantee off-chain before challenging its ledger channel
+	ReclaimTimeout time.Duration
+	// PrioritizeExitsDraining prioritizes exits over funding while the node is draining (see ExitPriority)
+	PrioritizeExitsDraining bool
+	// PrioritizeExitsPressure prioritizes exits over funding while objectives proposed by peers are queued, or
+	// MaxObjectives is reached
+	PrioritizeExitsPressure bool
+	// AllowedAssets are the only assets the outcomes of channels we fund may hold, the native token being the zero
+	// address. If empty, any asset is allowed.
+	AllowedAssets []types.Address
+}
 
 // ShouldApprove decides to approve o if it is currently unapproved
 func (pp *PermissivePolicy) ShouldApprove(o protocols.Objective) bool {
 	return o.GetStatus() == protocols.Unapproved
 }
+
+// RequiredDepositDepth returns the configured DepositSafetyDepth
+func (pp *PermissivePolicy) RequiredDepositDepth() uint64 {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return pp.DepositSafetyDepth
+}
+
+// ProposalTimeout returns the configured CountersignatureTimeout
+func (pp *PermissivePolicy) ProposalTimeout() time.Duration {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return pp.CountersignatureTimeout
+}
+
+// ConcurrencyLimits returns the configured MaxObjectivesPerPeer, MaxObjectives and QueueExcessObjectives
+func (pp *PermissivePolicy) ConcurrencyLimits() ConcurrencyLimits {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return ConcurrencyLimits{PerPeer: pp.MaxObjectivesPerPeer, Global: pp.MaxObjectives, QueueExcess: pp.QueueExcessObjectives}
+}
+
+// CollectionInterval returns the configured ObjectiveCollectionInterval
+func (pp *PermissivePolicy) CollectionInterval() time.Duration {
+	return pp.ObjectiveCollectionInterval
+}
+
+// FundsExternally returns true if the counterparty is one of the configured ExternallyFundedPeers
+func (pp *PermissivePolicy) FundsExternally(counterparty types.Address) bool {
+	return slices.Contains(pp.ExternallyFundedPeers, counterparty)
+}
+
+// AutoDefundThreshold returns the configured AutoDefundAt, if AutoDefund is set
+func (pp *PermissivePolicy) AutoDefundThreshold() (*big.Int, bool) {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	if pp.AutoDefundAt == nil {
+		return big.NewInt(0), pp.AutoDefund
+	}
+	return pp.AutoDefundAt, pp.AutoDefund
+}
+
+// GuaranteeLifetime returns the configured GuaranteeExpiry
+func (pp *PermissivePolicy) GuaranteeLifetime() time.Duration {
+	return pp.GuaranteeExpiry
+}
+
+// OffChainReclaimTimeout returns the configured ReclaimTimeout
+func (pp *PermissivePolicy) OffChainReclaimTimeout() time.Duration {
+	return pp.ReclaimTimeout
+}
+
+// AllowsAsset returns true if the asset is one of the configured AllowedAssets, or if none are configured
+func (pp *PermissivePolicy) AllowsAsset(asset types.Address) bool {
+	return len(pp.AllowedAssets) == 0 || slices.Contains(pp.AllowedAssets, asset)
+}
+
+// ExitPriority returns the configured PrioritizeExitsDraining and PrioritizeExitsPressure
+func (pp *PermissivePolicy) ExitPriority() ExitPriority {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return ExitPriority{WhileDraining: pp.PrioritizeExitsDraining, UnderPressure: pp.PrioritizeExitsPressure}
+}
+
+// Caps returns the configured caps
+func (pp *PermissivePolicy) Caps() PolicyCaps {
+	pp.mu.RLock()
+	defer pp.mu.RUnlock()
+	return PolicyCaps{
+		DepositSafetyDepth:      pp.DepositSafetyDepth,
+		CountersignatureTimeout: pp.CountersignatureTimeout,
+		MaxObjectivesPerPeer:    pp.MaxObjectivesPerPeer,
+		MaxObjectives:           pp.MaxObjectives,
+		QueueExcessObjectives:   pp.QueueExcessObjectives,
+		AutoDefund:              pp.AutoDefund,
+		AutoDefundAt:            pp.AutoDefundAt,
+		PrioritizeExitsDraining: pp.PrioritizeExitsDraining,
+		PrioritizeExitsPressure: pp.PrioritizeExitsPressure,
+	}
+}
+
+// SetCaps replaces the configured caps
+func (pp *PermissivePolicy) SetCaps(caps PolicyCaps) {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.DepositSafetyDepth = caps.DepositSafetyDepth
+	pp.CountersignatureTimeout = caps.CountersignatureTimeout
+	pp.MaxObjectivesPerPeer = caps.MaxObjectivesPerPeer
+	pp.MaxObjectives = caps.MaxObjectives
+	pp.QueueExcessObjectives = caps.QueueExcessObjectives
+	pp.AutoDefund = caps.AutoDefund
+	pp.AutoDefundAt = caps.AutoDefundAt
+	pp.PrioritizeExitsDraining = caps.PrioritizeExitsDraining
+	pp.PrioritizeExitsPressure = caps.PrioritizeExitsPressure
+}
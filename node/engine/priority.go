@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrFundingDeferred is returned when a channel is requested, or its funds topped up, while exits are prioritized.
+const ErrFundingDeferred = types.ConstError("funding deferred while exits are prioritized")
+
+// ExitPriority decides when objectives which exit channels (defunds, challenges and withdrawals) are prioritized over
+// objectives which fund them. While exits are prioritized, we refuse to fund new channels, whether we are asked to by
+// the API or proposed them by peers, and exits proposed by peers start at once, regardless of the ConcurrencyLimits.
+// Funding objectives already queued wait until exits are no longer prioritized.
+type ExitPriority struct {
+	// WhileDraining prioritizes exits while the engine is draining (see Engine.SetDraining), such as while closing all
+	// channels
+	WhileDraining bool
+	// UnderPressure prioritizes exits while objectives proposed by peers are queued, or the global ConcurrencyLimits
+	// are reached
+	UnderPressure bool
+}
+
+// PriorityPolicy may optionally be implemented by a PolicyMaker to prioritize exits over funding, so that exits always
+// make progress while the node is draining or short of capacity.
+// If the PolicyMaker does not implement it, exits are never prioritized.
+type PriorityPolicy interface {
+	ExitPriority() ExitPriority
+}
+
+// isExitObjective returns true if the objective exits a channel, releasing the funds it holds.
+func isExitObjective(o protocols.Objective) bool {
+	switch o.(type) {
+	case *directdefund.Objective, *virtualdefund.Objective, *challenge.Objective:
+		return true
+	}
+	return false
+}
+
+// isFundingObjective returns true if the objective funds a channel, locking up more funds.
+func isFundingObjective(o protocols.Objective) bool {
+	switch o.(type) {
+	case *directfund.Objective, *virtualfund.Objective, *ledgertopup.Objective:
+		return true
+	}
+	return false
+}
+
+// isFundingRequest returns true if the request is for an objective which funds a channel.
+func isFundingRequest(or protocols.ObjectiveRequest) bool {
+	switch or.(type) {
+	case directfund.ObjectiveRequest, virtualfund.ObjectiveRequest, ledgertopup.ObjectiveRequest:
+		return true
+	}
+	return false
+}
+
+// SetDraining marks the engine as draining, or no longer draining. While it is draining, exits are prioritized if the
+// policymaker's ExitPriority says so.
+func (e *Engine) SetDraining(draining bool) {
+	e.draining.Store(draining)
+}
+
+// Draining returns true if the engine is draining.
+func (e *Engine) Draining() bool {
+	return e.draining.Load()
+}
+
+// PrioritizingExits returns true if exits are currently prioritized over funding (see ExitPriority).
+func (e *Engine) PrioritizingExits() bool {
+	pp, ok := e.policymaker.(PriorityPolicy)
+	if !ok {
+		return false
+	}
+	priority := pp.ExitPriority()
+	if priority.WhileDraining && e.Draining() {
+		return true
+	}
+	return priority.UnderPressure && e.underPressure()
+}
+
+// underPressure returns true if objectives proposed by peers are queued, or the global concurrency limit is reached.
+func (e *Engine) underPressure() bool {
+	limits, ok := e.concurrencyLimits()
+	if !ok {
+		return false
+	}
+	e.concurrency.mu.Lock()
+	defer e.concurrency.mu.Unlock()
+	return len(e.concurrency.queue) > 0 || (limits.Global > 0 && len(e.concurrency.inProgress) >= limits.Global)
+}
+
+// CheckFundingAllowed returns ErrFundingDeferred if exits are prioritized, so that new channels should not be funded.
+func (e *Engine) CheckFundingAllowed() error {
+	if e.PrioritizingExits() {
+		return ErrFundingDeferred
+	}
+	return nil
+}
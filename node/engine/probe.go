@@ -0,0 +1,40 @@
+package engine
+
+import (
+	"slices"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// handleProbeRequest handles a ProbeRequest (triggered by a client API call).
+// It dispatches the request to the counterparty, whose probe will later arrive as a message.
+func (e *Engine) handleProbeRequest(request ProbeRequest) error {
+	se := protocols.SideEffects{MessagesToSend: []protocols.Message{protocols.CreateProbeRequestMessage(request.Request, request.Counterparty)}}
+	return e.executeSideEffects(se)
+}
+
+// probe computes our response to a probe request received from requester. We accept if we speak a version of the wire
+// protocol the requester speaks, our AssetPolicy allows the asset and our ChannelSizePolicy accepts the deposits.
+func (e *Engine) probe(requester types.Address, request protocols.ProbeRequest) protocols.Probe {
+	p := protocols.Probe{RequestId: request.Id, Versions: protocols.SupportedProtocolVersions}
+
+	if request.Deposit == nil || request.CounterDeposit == nil || request.Deposit.Sign() < 0 || request.CounterDeposit.Sign() < 0 {
+		p.Refusal, p.Reason = protocols.ProbeInvalidRequest, "deposits must not be negative"
+		return p
+	}
+	if !slices.ContainsFunc(request.Versions, func(v uint32) bool { return slices.Contains(protocols.SupportedProtocolVersions, v) }) {
+		p.Refusal, p.Reason = protocols.ProbeUnsupportedVersion, "no protocol version in common"
+		return p
+	}
+	if ap, ok := e.policymaker.(AssetPolicy); ok && !ap.AllowsAsset(request.Asset) {
+		p.Refusal, p.Reason = protocols.ProbeAssetNotAccepted, "asset "+request.Asset.String()+" is not accepted"
+		return p
+	}
+	if sp, ok := e.policymaker.(ChannelSizePolicy); ok && !sp.AcceptsChannelSize(requester, request.Asset, request.Deposit, request.CounterDeposit) {
+		p.Refusal, p.Reason = protocols.ProbeChannelSizeRefused, "channel size is not accepted"
+		return p
+	}
+	p.Accepts = true
+	return p
+}
@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ReplayStep reports the replay of an entry of an event log.
+type ReplayStep struct {
+	Entry EventLogEntry
+	// Event holds the changes caused by handling the entry
+	Event EngineEvent
+	// Err is the error handling the entry, if any. Replay stops at the first error which the engine cannot survive.
+	Err error
+}
+
+// ErrEventLogEncrypted is returned by Replay for an encrypted event log, when no key is supplied.
+const ErrEventLogEncrypted = types.ConstError("the event log is encrypted, but no encryption key was supplied")
+
+// Replay reconstructs, in s, the state of the node which recorded the event log (see WithEventLog), by handling the
+// recorded inputs in order. s must hold the key of that node, and should be empty, as the log is expected to have been
+// recorded since the node's store was created. Messages and transactions are discarded rather than sent.
+//
+// keys supplies the key of an encrypted log, which is that of the store of the node which recorded it. It may be nil
+// for a log which is not encrypted.
+//
+// onStep, if given, is called after each entry is handled, and may return false to stop the replay, such as to inspect
+// s at the step a bug is triggered.
+func Replay(log io.Reader, keys store.StoreKeySource, s store.Store, policymaker PolicyMaker, onStep func(ReplayStep) bool) error {
+	dec := json.NewDecoder(log)
+	var first EventLogEntry
+	if err := dec.Decode(&first); err != nil {
+		return fmt.Errorf("could not read the event log: %w", err)
+	}
+	if first.Kind != StartEvent {
+		return fmt.Errorf("the event log does not begin with a %s entry", StartEvent)
+	}
+	var start EventLogStart
+	if err := json.Unmarshal(first.Data, &start); err != nil {
+		return fmt.Errorf("could not read the %s entry: %w", StartEvent, err)
+	}
+	if start.Address != *s.GetAddress() {
+		return fmt.Errorf("the event log was recorded by %s, not by the owner of the store %s", start.Address, *s.GetAddress())
+	}
+	cipher, err := logCipher(start, keys)
+	if err != nil {
+		return err
+	}
+
+	services := &replayServices{start: start}
+	vm := payments.NewShardedVoucherManager(start.Address, s)
+	e := newEngine(vm, payments.NewPaymentIds(s), services, services, s, policymaker, func(EngineEvent) {})
+	defer e.wg.Wait()
+
+	last := first.Seq
+	for {
+		var entry EventLogEntry
+		err := dec.Decode(&entry)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not read the event log after entry %d: %w", last, err)
+		}
+		last = entry.Seq
+		if entry.Kind == StartEvent {
+			// Each run of the node appended to the log is encrypted with a key of its own
+			var restart EventLogStart
+			if err := json.Unmarshal(entry.Data, &restart); err != nil {
+				return fmt.Errorf("could not read %s entry %d: %w", StartEvent, entry.Seq, err)
+			}
+			if cipher, err = logCipher(restart, keys); err != nil {
+				return err
+			}
+		}
+		if entry.Sealed != "" {
+			if cipher == nil {
+				return fmt.Errorf("entry %d is encrypted, but its run of the event log has no salt", entry.Seq)
+			}
+			data, err := cipher.Open(sealedEntryName(entry.Kind, entry.Seq), entry.Sealed)
+			if err != nil {
+				return fmt.Errorf("could not decrypt entry %d, which may have been recorded with another key: %w", entry.Seq, err)
+			}
+			entry.Data = json.RawMessage(data)
+		}
+
+		e.clock = func() time.Time { return entry.Time }
+		res, err := e.replayEntry(entry, services)
+		res, err = e.completeStep(res, err)
+		if onStep != nil && !onStep(ReplayStep{Entry: entry, Event: res, Err: err}) {
+			return nil
+		}
+		if err != nil && isFatal(err) {
+			return fmt.Errorf("entry %d (%s): %w", entry.Seq, entry.Kind, err)
+		}
+	}
+}
+
+// logCipher returns the cipher of the entries following the start entry, or nil if they are not encrypted.
+func logCipher(start EventLogStart, keys store.StoreKeySource) (*store.RecordCipher, error) {
+	if start.Salt == nil {
+		return nil, nil
+	}
+	if keys == nil {
+		return nil, ErrEventLogEncrypted
+	}
+	cipher, err := store.NewRecordCipher(keys, start.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive the key of the event log: %w", err)
+	}
+	return cipher, nil
+}
+
+// replayEntry handles the input recorded in the entry, as the run loop handled it.
+func (e *Engine) replayEntry(entry EventLogEntry, services *replayServices) (EngineEvent, error) {
+	switch entry.Kind {
+	case StartEvent:
+		// The node was restarted, and appended to the log: it resumed its objectives before handling any input
+		e.recordInput(StartEvent, nil)
+		return e.resumeObjectives()
+	case ObjectiveRequestEvent:
+		var lr loggedObjectiveRequest
+		if err := json.Unmarshal(entry.Data, &lr); err != nil {
+			return EngineEvent{}, err
+		}
+		or, err := decodeObjectiveRequest(lr)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		if lr.Abandoned {
+			cancel()
+		}
+		defer cancel()
+		return e.handleObjectiveRequest(ctx, or)
+	case PaymentRequestEvent:
+		var request PaymentRequest
+		if err := json.Unmarshal(entry.Data, &request); err != nil {
+			return EngineEvent{}, err
+		}
+		e.recordInput(PaymentRequestEvent, nil)
+		return e.handlePaymentRequest(request)
+	case ChallengeRequestEvent:
+		var request loggedChallengeRequest
+		if err := json.Unmarshal(entry.Data, &request); err != nil {
+			return EngineEvent{}, err
+		}
+		e.recordInput(ChallengeRequestEvent, nil)
+		e.handleChallengeRequest(ChallengeRequest{LedgerId: request.LedgerId, Result: make(chan error, 1)})
+		return EngineEvent{}, nil
+	case MessageEvent:
+		var message protocols.Message
+		if err := json.Unmarshal(entry.Data, &message); err != nil {
+			return EngineEvent{}, err
+		}
+		e.recordInput(MessageEvent, nil)
+		return e.handleSequencedMessage(message)
+	case ChainEvent:
+		event, err := chainservice.UnmarshalEvent(entry.Data)
+		if err != nil {
+			return EngineEvent{}, err
+		}
+		e.recordInput(ChainEvent, nil)
+		return e.handleChainEvent(event)
+	case ProposalEvent:
+		e.recordInput(ProposalEvent, nil)
+		select {
+		case proposal := <-e.fromLedger:
+			return e.handleProposal(proposal)
+		default:
+			return EngineEvent{}, fmt.Errorf("no ledger proposal is queued to be handled")
+		}
+	case BlockEvent:
+		var block loggedBlock
+		if err := json.Unmarshal(entry.Data, &block); err != nil {
+			return EngineEvent{}, err
+		}
+		services.lastBlockNum = block.BlockNum
+		e.recordInput(BlockEvent, nil)
+		return e.handleBlock(block.BlockNum)
+	case DeadlineEvent:
+		e.recordInput(DeadlineEvent, nil)
+		return e.handleDeadlines()
+	case CollectionEvent:
+		e.recordInput(CollectionEvent, nil)
+		return EngineEvent{}, e.collectObjectives()
+	default:
+		return EngineEvent{}, fmt.Errorf("unknown kind of event %q", entry.Kind)
+	}
+}
+
+// replayServices stand in for the message service and chain service of a replayed engine. They deliver nothing, and
+// discard what is sent.
+type replayServices struct {
+	start        EventLogStart
+	lastBlockNum uint64
+}
+
+func (rs *replayServices) P2PMessages() <-chan protocols.Message {
+	return nil
+}
+
+func (rs *replayServices) SignRequests() <-chan p2pms.SignatureRequest {
+	return nil
+}
+
+func (rs *replayServices) Send(protocols.Message) error {
+	return nil
+}
+
+func (rs *replayServices) EventFeed() <-chan chainservice.Event {
+	return nil
+}
+
+func (rs *replayServices) SendTransaction(protocols.ChainTransaction) error {
+	return nil
+}
+
+func (rs *replayServices) GetConsensusAppAddress() types.Address {
+	return rs.start.ConsensusAppAddress
+}
+
+func (rs *replayServices) GetVirtualPaymentAppAddress() types.Address {
+	return rs.start.VirtualPaymentAppAddress
+}
+
+func (rs *replayServices) GetChainId() (*big.Int, error) {
+	return new(big.Int).Set(rs.start.ChainId), nil
+}
+
+func (rs *replayServices) GetLastConfirmedBlockNum() uint64 {
+	return rs.lastBlockNum
+}
+
+func (rs *replayServices) Close() error {
+	return nil
+}
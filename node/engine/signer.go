@@ -0,0 +1,54 @@
+package engine
+
+import (
+	"crypto/sha256"
+
+	"github.com/ethereum/go-ethereum/crypto/secp256k1"
+	"github.com/statechannels/go-nitro/channel/state"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Signer makes every signature of the node's channel key: on the states of its channels and the challenges which
+// register them, on the vouchers it pays with and the channel snapshots it issues, and on the record which advertises
+// its peer id. The key may be held outside the node, by a remote signing service (see package remotesigner), in which
+// case the node's store need not hold it.
+type Signer interface {
+	state.Signer
+	payments.VoucherSigner
+	payments.SnapshotSigner
+	// Address returns the address of the key.
+	Address() types.Address
+	// SignPeerRecord returns the secp256k1 signature, in the 65 byte [R || S || V] format, over the sha256 hash of the
+	// encoded record which advertises the node's peer id.
+	SignPeerRecord(record []byte) ([]byte, error)
+}
+
+// KeySigner is a Signer which signs with a secret key held in memory, as the channel secret key of a store.
+type KeySigner []byte
+
+func (sk KeySigner) Address() types.Address {
+	return nc.GetAddressFromSecretKeyBytes(sk)
+}
+
+func (sk KeySigner) SignState(s state.State) (state.Signature, error) {
+	return state.KeySigner(sk).SignState(s)
+}
+
+func (sk KeySigner) SignChallenge(s state.State) (state.Signature, error) {
+	return state.KeySigner(sk).SignChallenge(s)
+}
+
+func (sk KeySigner) SignVoucher(v payments.Voucher) (state.Signature, error) {
+	return payments.KeySigner(sk).SignVoucher(v)
+}
+
+func (sk KeySigner) SignSnapshot(cs payments.ChannelSnapshot) (state.Signature, error) {
+	return payments.KeySigner(sk).SignSnapshot(cs)
+}
+
+func (sk KeySigner) SignPeerRecord(record []byte) ([]byte, error) {
+	hash := sha256.Sum256(record)
+	return secp256k1.Sign(hash[:], sk)
+}
@@ -1,12 +1,16 @@
 package store
 
 import (
+	"crypto/cipher"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -14,8 +18,10 @@ import (
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -27,8 +33,24 @@ type DurableStore struct {
 	channels           *buntdb.DB
 	consensusChannels  *buntdb.DB
 	channelToObjective *buntdb.DB
+	summaries          *buntdb.DB
 	vouchers           *buntdb.DB
+	paymentRecords     *buntdb.DB
+	balanceSnapshots   *buntdb.DB
+	activity           *buntdb.DB
+	activitySeq        *atomic.Uint64
+	channelTags        *buntdb.DB
+	channelMemos       *buntdb.DB
+	config             *buntdb.DB
+	pendingTxs         *buntdb.DB
+	blockedPeers       *buntdb.DB
 	lastBlockNumSeen   *buntdb.DB
+	messageSequences   *buntdb.DB
+	quarantine         *buntdb.DB // records set aside by CheckIntegrity as corrupt
+	encryption         *buntdb.DB // the salt of the store's encryption, if it is encrypted
+	epoch              uint64
+	aead               cipher.AEAD // encrypts the records of the sealedTables, if the store is encrypted
+	sealed             []string    // the tables whose records, including those written before they were encrypted, have all been encrypted
 
 	key     string // the signing key of the store's engine
 	address string // the (Ethereum) address associated to the signing key
@@ -38,9 +60,20 @@ type DurableStore struct {
 // NewDurableStore creates a new DurableStore that uses the given folder to store its data
 // It will create the folder if it does not exist
 func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, error) {
+	return NewEncryptedDurableStore(key, folder, config, nil)
+}
+
+// NewEncryptedDurableStore is like NewDurableStore, but encrypts the store's records of channels, payments, balances,
+// activity, tags and memos at rest with AES-GCM, using the key from keys. The records of a store which was not encrypted
+// before are encrypted when it is opened. An encrypted store cannot be opened without keys, nor with keys supplying a different key.
+func NewEncryptedDurableStore(key []byte, folder string, config buntdb.Config, keys StoreKeySource) (Store, error) {
+	return openDurableStore(key, crypto.GetAddressFromSecretKeyBytes(key), folder, config, keys)
+}
+
+// openDurableStore opens the DurableStore of the address, holding its key unless key is nil.
+func openDurableStore(key []byte, me types.Address, folder string, config buntdb.Config, keys StoreKeySource) (Store, error) {
 	ps := DurableStore{}
 
-	me := crypto.GetAddressFromSecretKeyBytes(key)
 	dataFolder := filepath.Join(folder, me.String())
 
 	err := os.MkdirAll(dataFolder, os.ModePerm)
@@ -49,7 +82,7 @@ func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, er
 	}
 
 	ps.key = common.Bytes2Hex(key)
-	ps.address = crypto.GetAddressFromSecretKeyBytes(key).String()
+	ps.address = me.String()
 	ps.folder = folder
 
 	ps.objectives, err = ps.openDB("objectives", config)
@@ -68,16 +101,85 @@ func NewDurableStore(key []byte, folder string, config buntdb.Config) (Store, er
 	if err != nil {
 		return nil, err
 	}
+	ps.summaries, err = ps.openDB("objective_summaries", config)
+	if err != nil {
+		return nil, err
+	}
 	ps.vouchers, err = ps.openDB("vouchers", config)
 	if err != nil {
 		return nil, err
 	}
+	ps.paymentRecords, err = ps.openDB("payment_records", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.balanceSnapshots, err = ps.openDB("balance_snapshots", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.activity, err = ps.openDB("activity", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.activitySeq = &atomic.Uint64{}
+
+	ps.channelTags, err = ps.openDB("channel_tags", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.channelMemos, err = ps.openDB("channel_memos", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.config, err = ps.openDB("config", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.pendingTxs, err = ps.openDB("pending_transactions", config)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.blockedPeers, err = ps.openDB("blocked_peers", config)
+	if err != nil {
+		return nil, err
+	}
 
 	ps.lastBlockNumSeen, err = ps.openDB("lastBlockNumSeen", config)
 	if err != nil {
 		return nil, err
 	}
 
+	ps.messageSequences, err = ps.openDB("message_sequences", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.quarantine, err = ps.openDB("quarantine", config)
+	if err != nil {
+		return nil, err
+	}
+	ps.encryption, err = ps.openDB("encryption", config)
+	if err != nil {
+		return nil, err
+	}
+	if err := ps.setUpEncryption(keys); err != nil {
+		return nil, errors.Join(err, ps.Close())
+	}
+	if ps.aead != nil && len(ps.sealed) != len(sealedTables) {
+		if err := ps.sealPlaintextRecords(); err != nil {
+			return nil, errors.Join(err, ps.Close())
+		}
+	}
+	ps.epoch, err = ps.loadEpoch()
+	if err != nil {
+		return nil, err
+	}
+
 	return &ps, nil
 }
 
@@ -110,6 +212,54 @@ func (ds *DurableStore) Close() error {
 	if err != nil {
 		return err
 	}
+	err = ds.summaries.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.balanceSnapshots.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.activity.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.paymentRecords.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.channelTags.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.channelMemos.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.config.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.pendingTxs.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.blockedPeers.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.messageSequences.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.encryption.Close()
+	if err != nil {
+		return err
+	}
+	err = ds.quarantine.Close()
+	if err != nil {
+		return err
+	}
 	return ds.vouchers.Close()
 }
 
@@ -119,6 +269,9 @@ func (ds *DurableStore) GetAddress() *types.Address {
 }
 
 func (ds *DurableStore) GetChannelSecretKey() *[]byte {
+	if ds.key == "" {
+		return nil
+	}
 	val := common.Hex2Bytes(ds.key)
 	return &val
 }
@@ -130,6 +283,10 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 		if err != nil {
 			return err
 		}
+		objJSON, err = ds.unseal("objectives", string(id), objJSON)
+		if err != nil {
+			return err
+		}
 
 		obj, err = decodeObjective(id, []byte(objJSON))
 		if err != nil {
@@ -144,6 +301,9 @@ func (ds *DurableStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Ob
 		return nil
 	})
 	if err != nil && errors.Is(err, buntdb.ErrNotFound) {
+		if _, err := ds.GetObjectiveSummary(id); err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrObjectiveCollected, id)
+		}
 		return nil, ErrNoSuchObjective
 	}
 
@@ -156,9 +316,13 @@ func (ds *DurableStore) SetObjective(obj protocols.Objective) error {
 	if err != nil {
 		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
 	}
+	sealed, err := ds.seal("objectives", string(obj.Id()), string(objJSON))
+	if err != nil {
+		return fmt.Errorf("error setting objective %s: %w", obj.Id(), err)
+	}
 
 	err = ds.objectives.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(string(obj.Id()), string(objJSON), nil)
+		_, _, err := tx.Set(string(obj.Id()), sealed, nil)
 		return err
 	})
 
@@ -248,15 +412,88 @@ func (ds *DurableStore) SetLastBlockNumSeen(blockNumber uint64) error {
 	})
 }
 
+// loadEpoch returns the epoch of the store, which is chosen when the store is first opened.
+func (ds *DurableStore) loadEpoch() (uint64, error) {
+	var epoch uint64
+	err := ds.messageSequences.Update(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(epochKey)
+		if errors.Is(err, buntdb.ErrNotFound) {
+			epoch = newEpoch()
+			_, _, err = tx.Set(epochKey, strconv.FormatUint(epoch, 10), nil)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		epoch, err = strconv.ParseUint(val, 10, 64)
+		return err
+	})
+	return epoch, err
+}
+
+// NextMessageSeq returns the epoch of the store and the number of the next message to peer.
+func (ds *DurableStore) NextMessageSeq(peer types.Address) (uint64, uint64, error) {
+	var seq uint64
+	err := ds.messageSequences.Update(func(tx *buntdb.Tx) error {
+		key := outgoingSeqKeyPrefix + peer.String()
+		val, err := tx.Get(key)
+		if err != nil && !errors.Is(err, buntdb.ErrNotFound) {
+			return err
+		}
+		if err == nil {
+			seq, err = strconv.ParseUint(val, 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+		seq++
+		_, _, err = tx.Set(key, strconv.FormatUint(seq, 10), nil)
+		return err
+	})
+	return ds.epoch, seq, err
+}
+
+// GetMessageSequence returns the record of which of peer's messages have been processed.
+func (ds *DurableStore) GetMessageSequence(peer types.Address) (MessageSequence, error) {
+	var ms MessageSequence
+	err := ds.messageSequences.View(func(tx *buntdb.Tx) error {
+		val, err := tx.Get(incomingSeqKeyPrefix + peer.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(val), &ms)
+	})
+	return ms, err
+}
+
+// SetMessageSequence sets the record of which of peer's messages have been processed.
+func (ds *DurableStore) SetMessageSequence(peer types.Address, ms MessageSequence) error {
+	msJSON, err := json.Marshal(ms)
+	if err != nil {
+		return err
+	}
+	return ds.messageSequences.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(incomingSeqKeyPrefix+peer.String(), string(msJSON), nil)
+		return err
+	})
+}
+
 // SetChannel sets the channel in the store.
 func (ds *DurableStore) SetChannel(ch *channel.Channel) error {
 	chJSON, err := ch.MarshalJSON()
 	if err != nil {
 		return err
 	}
+	sealed, err := ds.seal("channels", ch.Id.String(), string(chJSON))
+	if err != nil {
+		return err
+	}
 
 	err = ds.channels.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
+		_, _, err := tx.Set(ch.Id.String(), sealed, nil)
 		return err
 	})
 	return err
@@ -279,9 +516,13 @@ func (ps *DurableStore) SetConsensusChannel(ch *consensus_channel.ConsensusChann
 	if err != nil {
 		return err
 	}
+	sealed, err := ps.seal("consensus_channels", ch.Id.String(), string(chJSON))
+	if err != nil {
+		return err
+	}
 
 	err = ps.consensusChannels.Update(func(tx *buntdb.Tx) error {
-		_, _, err := tx.Set(ch.Id.String(), string(chJSON), nil)
+		_, _, err := tx.Set(ch.Id.String(), sealed, nil)
 		return err
 	})
 
@@ -318,6 +559,12 @@ func (ds *DurableStore) getChannelById(id types.Destination) (channel.Channel, e
 	if errors.Is(err, buntdb.ErrNotFound) {
 		return channel.Channel{}, ErrNoSuchChannel
 	}
+	if err == nil {
+		chJSON, err = ds.unseal("channels", id.String(), chJSON)
+	}
+	if err != nil {
+		return channel.Channel{}, err
+	}
 	var ch channel.Channel
 	err = ch.UnmarshalJSON([]byte(chJSON))
 
@@ -338,6 +585,10 @@ func (ds *DurableStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Ch
 
 	txError := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
+			chJSON, err = ds.unseal("channels", key, chJSON)
+			if err != nil {
+				return false
+			}
 			var ch channel.Channel
 			err = json.Unmarshal([]byte(chJSON), &ch)
 			if err != nil {
@@ -373,6 +624,10 @@ func (ds *DurableStore) GetChannelsByAppDefinition(appDef types.Address) ([]*cha
 	var unmarshErr error
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
+			chJSON, unmarshErr = ds.unseal("channels", key, chJSON)
+			if unmarshErr != nil {
+				return false
+			}
 			var ch channel.Channel
 			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
 			if unmarshErr != nil {
@@ -400,8 +655,12 @@ func (ds *DurableStore) GetChannelsByParticipant(participant types.Address) ([]*
 	toReturn := []*channel.Channel{}
 	err := ds.channels.View(func(tx *buntdb.Tx) error {
 		err := tx.Ascend("", func(key, chJSON string) bool {
+			chJSON, err := ds.unseal("channels", key, chJSON)
+			if err != nil {
+				return true // channel not found, continue looking
+			}
 			var ch channel.Channel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err = json.Unmarshal([]byte(chJSON), &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -430,6 +689,10 @@ func (ds *DurableStore) GetAllConsensusChannels() ([]*consensus_channel.Consensu
 		return tx.Ascend("", func(key, chJSON string) bool {
 			var ch consensus_channel.ConsensusChannel
 
+			chJSON, unmarshErr = ds.unseal("consensus_channels", key, chJSON)
+			if unmarshErr != nil {
+				return false
+			}
 			unmarshErr = json.Unmarshal([]byte(chJSON), &ch)
 			if unmarshErr != nil {
 				return false
@@ -457,6 +720,10 @@ func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *
 		if errors.Is(err, buntdb.ErrNotFound) {
 			return ErrNoSuchChannel
 		}
+		chJSON, err = ds.unseal("consensus_channels", id.String(), chJSON)
+		if err != nil {
+			return err
+		}
 
 		ch = &consensus_channel.ConsensusChannel{}
 		err = ch.UnmarshalJSON([]byte(chJSON))
@@ -477,8 +744,12 @@ func (ds *DurableStore) GetConsensusChannelById(id types.Destination) (channel *
 func (ps *DurableStore) GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool) {
 	err := ps.consensusChannels.View(func(tx *buntdb.Tx) error {
 		return tx.Ascend("", func(key, chJSON string) bool {
+			chJSON, err := ps.unseal("consensus_channels", key, chJSON)
+			if err != nil {
+				return true // channel not found, continue looking
+			}
 			var ch consensus_channel.ConsensusChannel
-			err := json.Unmarshal([]byte(chJSON), &ch)
+			err = json.Unmarshal([]byte(chJSON), &ch)
 			if err != nil {
 				return true // channel not found, continue looking
 			}
@@ -543,6 +814,24 @@ func (ds *DurableStore) populateChannelData(obj protocols.Objective) error {
 
 		o.C = &ch
 
+		return nil
+	case *challenge.Objective:
+		ch, err := ds.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *ledgertopup.Objective:
+		ch, err := ds.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
 		return nil
 	case *virtualfund.Objective:
 		v, err := ds.getChannelById(o.V.Id)
@@ -604,24 +893,139 @@ func (ds *DurableStore) populateChannelData(obj protocols.Objective) error {
 		}
 		return nil
 	default:
+		// Registered objective types are stored whole
+		if _, ok := protocols.LookupObjectiveType(id); ok {
+			return nil
+		}
 		return fmt.Errorf("objective %s did not correctly represent a known Objective type", id)
 	}
 }
 
+// ReleaseChannelFromOwnership releases the channel from being owned by any objective. Releasing a channel which is not
+// owned (for example, because the objective completed as soon as it was approved) is not an error.
 func (ds *DurableStore) ReleaseChannelFromOwnership(channelId types.Destination) error {
 	return ds.channelToObjective.Update(func(tx *buntdb.Tx) error {
 		_, err := tx.Delete(channelId.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+func (ds *DurableStore) GetTerminalObjectiveIds() ([]protocols.ObjectiveId, error) {
+	ids := []protocols.ObjectiveId{}
+	err := ds.objectives.View(func(tx *buntdb.Tx) error {
+		var decodeErr error
+		err := tx.Ascend("", func(key, objJSON string) bool {
+			var terminal bool
+			objJSON, decodeErr = ds.unseal("objectives", key, objJSON)
+			if decodeErr != nil {
+				return false
+			}
+			terminal, decodeErr = isTerminal([]byte(objJSON))
+			if decodeErr != nil {
+				decodeErr = fmt.Errorf("error decoding objective %s: %w", key, decodeErr)
+				return false
+			}
+			if terminal {
+				ids = append(ids, protocols.ObjectiveId(key))
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		return decodeErr
+	})
+	return ids, err
+}
+
+func (ds *DurableStore) GetObjectiveIdsByPrefix(prefix string) ([]protocols.ObjectiveId, error) {
+	ids := []protocols.ObjectiveId{}
+	err := ds.objectives.View(func(tx *buntdb.Tx) error {
+		return tx.AscendGreaterOrEqual("", prefix, func(key, _ string) bool {
+			if !strings.HasPrefix(key, prefix) {
+				return false
+			}
+			ids = append(ids, protocols.ObjectiveId(key))
+			return true
+		})
+	})
+	return ids, err
+}
+
+func (ds *DurableStore) CollectObjective(summary ObjectiveSummary) error {
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	err = ds.summaries.Update(func(tx *buntdb.Tx) error {
+		sealed, err := ds.seal("objective_summaries", string(summary.Id), string(summaryJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(string(summary.Id), sealed, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = ds.objectives.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(string(summary.Id))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return ds.channelToObjective.Update(func(tx *buntdb.Tx) error {
+		owner, err := tx.Get(summary.ChannelId.String())
+		if errors.Is(err, buntdb.ErrNotFound) || owner != string(summary.Id) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Delete(summary.ChannelId.String())
 		return err
 	})
 }
 
+func (ds *DurableStore) GetObjectiveSummary(id protocols.ObjectiveId) (ObjectiveSummary, error) {
+	var summary ObjectiveSummary
+	err := ds.summaries.View(func(tx *buntdb.Tx) error {
+		summaryJSON, err := tx.Get(string(id))
+		if err != nil {
+			return err
+		}
+		summaryJSON, err = ds.unseal("objective_summaries", string(id), summaryJSON)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(summaryJSON), &summary)
+	})
+	if errors.Is(err, buntdb.ErrNotFound) {
+		return ObjectiveSummary{}, fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
+	}
+	return summary, err
+}
+
 func (ds *DurableStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
 	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
 		vJSON, err := json.Marshal(v)
 		if err != nil {
 			return err
 		}
-		_, _, err = tx.Set(channelId.String(), string(vJSON), nil)
+		sealed, err := ds.seal("vouchers", channelId.String(), string(vJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(channelId.String(), sealed, nil)
 
 		return err
 	})
@@ -634,6 +1038,10 @@ func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (*payments.V
 		if err != nil {
 			return fmt.Errorf("channelId %s: %w", channelId.String(), ErrLoadVouchers)
 		}
+		vJSON, err = ds.unseal("vouchers", channelId.String(), vJSON)
+		if err != nil {
+			return err
+		}
 		return json.Unmarshal([]byte(vJSON), v)
 	})
 	if err != nil {
@@ -643,8 +1051,382 @@ func (ds *DurableStore) GetVoucherInfo(channelId types.Destination) (*payments.V
 }
 
 func (ds *DurableStore) RemoveVoucherInfo(channelId types.Destination) error {
-	return ds.vouchers.Update(func(tx *buntdb.Tx) error {
+	err := ds.vouchers.Update(func(tx *buntdb.Tx) error {
 		_, err := tx.Delete(channelId.String())
 		return err
 	})
+	if err != nil {
+		return err
+	}
+
+	return ds.paymentRecords.Update(func(tx *buntdb.Tx) error {
+		prefix := paymentRecordKey(channelId, "")
+		keys := []string{}
+		err := tx.AscendGreaterOrEqual("", prefix, func(key, _ string) bool {
+			if !strings.HasPrefix(key, prefix) {
+				return false
+			}
+			keys = append(keys, key)
+			return true
+		})
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if _, err := tx.Delete(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (ds *DurableStore) GetPaymentRecord(channelId types.Destination, paymentId string) (payments.PaymentRecord, bool, error) {
+	r := payments.PaymentRecord{}
+	found := false
+	err := ds.paymentRecords.View(func(tx *buntdb.Tx) error {
+		rJSON, err := tx.Get(paymentRecordKey(channelId, paymentId))
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+		rJSON, err = ds.unseal("payment_records", paymentRecordKey(channelId, paymentId), rJSON)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(rJSON), &r)
+	})
+	if err != nil {
+		return payments.PaymentRecord{}, false, err
+	}
+	return r, found, nil
+}
+
+func (ds *DurableStore) SetPaymentRecord(channelId types.Destination, paymentId string, r payments.PaymentRecord) error {
+	return ds.paymentRecords.Update(func(tx *buntdb.Tx) error {
+		rJSON, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		sealed, err := ds.seal("payment_records", paymentRecordKey(channelId, paymentId), string(rJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(paymentRecordKey(channelId, paymentId), sealed, nil)
+		return err
+	})
+}
+
+func (ds *DurableStore) SetBalanceSnapshot(bs BalanceSnapshot) error {
+	return ds.balanceSnapshots.Update(func(tx *buntdb.Tx) error {
+		bsJSON, err := json.Marshal(bs)
+		if err != nil {
+			return err
+		}
+		sealed, err := ds.seal("balance_snapshots", bs.key(), string(bsJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(bs.key(), sealed, nil)
+		return err
+	})
+}
+
+func (ds *DurableStore) GetBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	snapshots := []BalanceSnapshot{}
+	var unmarshErr error
+	err := ds.balanceSnapshots.View(func(tx *buntdb.Tx) error {
+		return tx.AscendRange("", timeKey(from), timeKey(to), func(key, stored string) bool {
+			var bsJSON string
+			bsJSON, unmarshErr = ds.unseal("balance_snapshots", key, stored)
+			if unmarshErr != nil {
+				return false
+			}
+			bs := BalanceSnapshot{}
+			unmarshErr = json.Unmarshal([]byte(bsJSON), &bs)
+			if unmarshErr != nil {
+				return false
+			}
+			snapshots = append(snapshots, bs)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshErr != nil {
+		return nil, unmarshErr
+	}
+	return snapshots, nil
+}
+
+func (ds *DurableStore) AppendActivity(r ActivityRecord) error {
+	return ds.activity.Update(func(tx *buntdb.Tx) error {
+		rJSON, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		key := activityKey(r, ds.activitySeq.Add(1))
+		sealed, err := ds.seal("activity", key, string(rJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(key, sealed, nil)
+		return err
+	})
+}
+
+func (ds *DurableStore) GetActivity(from, to time.Time, fn func(ActivityRecord) bool) error {
+	var unmarshErr error
+	err := ds.activity.View(func(tx *buntdb.Tx) error {
+		return tx.AscendRange("", timeKey(from), timeKey(to), func(key, stored string) bool {
+			var rJSON string
+			rJSON, unmarshErr = ds.unseal("activity", key, stored)
+			if unmarshErr != nil {
+				return false
+			}
+			r := ActivityRecord{}
+			unmarshErr = json.Unmarshal([]byte(rJSON), &r)
+			if unmarshErr != nil {
+				return false
+			}
+			return fn(r)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return unmarshErr
+}
+
+// SetChannelTags replaces the tags of the channel.
+func (ds *DurableStore) SetChannelTags(id types.Destination, tags map[string]string) error {
+	return ds.channelTags.Update(func(tx *buntdb.Tx) error {
+		if len(tags) == 0 {
+			_, err := tx.Delete(id.String())
+			if errors.Is(err, buntdb.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			return err
+		}
+		sealed, err := ds.seal("channel_tags", id.String(), string(tagsJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(id.String(), sealed, nil)
+		return err
+	})
+}
+
+// GetChannelTags returns the tags of the channel, or nil if it has none.
+func (ds *DurableStore) GetChannelTags(id types.Destination) (map[string]string, error) {
+	var tags map[string]string
+	err := ds.channelTags.View(func(tx *buntdb.Tx) error {
+		tagsJSON, err := tx.Get(id.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		tagsJSON, err = ds.unseal("channel_tags", id.String(), tagsJSON)
+		if err != nil {
+			return err
+		}
+		return json.Unmarshal([]byte(tagsJSON), &tags)
+	})
+	return tags, err
+}
+
+// FindChannelsByTag returns the ids of the channels tagged with the key and value.
+func (ds *DurableStore) FindChannelsByTag(key, value string) ([]types.Destination, error) {
+	ids := []types.Destination{}
+	var unmarshErr error
+	err := ds.channelTags.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(id, stored string) bool {
+			var tagsJSON string
+			tagsJSON, unmarshErr = ds.unseal("channel_tags", id, stored)
+			if unmarshErr != nil {
+				return false
+			}
+			var tags map[string]string
+			unmarshErr = json.Unmarshal([]byte(tagsJSON), &tags)
+			if unmarshErr != nil {
+				return false
+			}
+			if v, ok := tags[key]; ok && v == value {
+				ids = append(ids, types.Destination(common.HexToHash(id)))
+			}
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if unmarshErr != nil {
+		return nil, unmarshErr
+	}
+	return ids, nil
+}
+
+// SetChannelMemo replaces the memo of the channel.
+func (ds *DurableStore) SetChannelMemo(id types.Destination, memo string) error {
+	return ds.channelMemos.Update(func(tx *buntdb.Tx) error {
+		if memo == "" {
+			_, err := tx.Delete(id.String())
+			if errors.Is(err, buntdb.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+		sealed, err := ds.seal("channel_memos", id.String(), memo)
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(id.String(), sealed, nil)
+		return err
+	})
+}
+
+// GetChannelMemo returns the memo of the channel, or "" if it has none.
+func (ds *DurableStore) GetChannelMemo(id types.Destination) (string, error) {
+	var memo string
+	err := ds.channelMemos.View(func(tx *buntdb.Tx) error {
+		stored, err := tx.Get(id.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		memo, err = ds.unseal("channel_memos", id.String(), stored)
+		return err
+	})
+	return memo, err
+}
+
+// SetConfigValue records a setting changed while the node runs.
+func (ds *DurableStore) SetConfigValue(key, value string) error {
+	return ds.config.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(key, value, nil)
+		return err
+	})
+}
+
+// GetConfigValues returns the settings changed while the node runs.
+func (ds *DurableStore) GetConfigValues() (map[string]string, error) {
+	values := map[string]string{}
+	err := ds.config.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, value string) bool {
+			values[key] = value
+			return true
+		})
+	})
+	return values, err
+}
+
+// SetPendingTransaction records a chain transaction which may not yet have been mined.
+func (ds *DurableStore) SetPendingTransaction(pt PendingTransaction) error {
+	ptJSON, err := json.Marshal(pt)
+	if err != nil {
+		return err
+	}
+	return ds.pendingTxs.Update(func(tx *buntdb.Tx) error {
+		sealed, err := ds.seal("pending_transactions", pt.ChannelId.String(), string(ptJSON))
+		if err != nil {
+			return err
+		}
+		_, _, err = tx.Set(pt.ChannelId.String(), sealed, nil)
+		return err
+	})
+}
+
+// GetPendingTransactions returns the chain transactions which may not yet have been mined.
+func (ds *DurableStore) GetPendingTransactions() ([]PendingTransaction, error) {
+	pending := []PendingTransaction{}
+	var unmarshErr error
+	err := ds.pendingTxs.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, stored string) bool {
+			var ptJSON string
+			ptJSON, unmarshErr = ds.unseal("pending_transactions", key, stored)
+			if unmarshErr != nil {
+				return false
+			}
+			pt := PendingTransaction{}
+			unmarshErr = json.Unmarshal([]byte(ptJSON), &pt)
+			if unmarshErr != nil {
+				unmarshErr = fmt.Errorf("error unmarshaling pending transaction %s: %w", key, unmarshErr)
+				return false
+			}
+			pending = append(pending, pt)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pending, unmarshErr
+}
+
+// RemovePendingTransaction removes the record of the channel's pending transaction, if any.
+func (ds *DurableStore) RemovePendingTransaction(channelId types.Destination) error {
+	return ds.pendingTxs.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(channelId.String())
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
+}
+
+// SetBlockedPeer records the peer as blocked.
+func (ds *DurableStore) SetBlockedPeer(bp BlockedPeer) error {
+	bpJSON, err := json.Marshal(bp)
+	if err != nil {
+		return err
+	}
+	return ds.blockedPeers.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(bp.Key(), string(bpJSON), nil)
+		return err
+	})
+}
+
+// GetBlockedPeers returns the peers which have been blocked.
+func (ds *DurableStore) GetBlockedPeers() ([]BlockedPeer, error) {
+	blocked := []BlockedPeer{}
+	var unmarshErr error
+	err := ds.blockedPeers.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, bpJSON string) bool {
+			bp := BlockedPeer{}
+			unmarshErr = json.Unmarshal([]byte(bpJSON), &bp)
+			if unmarshErr != nil {
+				unmarshErr = fmt.Errorf("error unmarshaling blocked peer %s: %w", key, unmarshErr)
+				return false
+			}
+			blocked = append(blocked, bp)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blocked, unmarshErr
+}
+
+// RemoveBlockedPeer removes the record of the peer blocked under the key, if any.
+func (ds *DurableStore) RemoveBlockedPeer(key string) error {
+	return ds.blockedPeers.Update(func(tx *buntdb.Tx) error {
+		_, err := tx.Delete(key)
+		if errors.Is(err, buntdb.ErrNotFound) {
+			return nil
+		}
+		return err
+	})
 }
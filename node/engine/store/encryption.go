@@ -0,0 +1,342 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	ErrStoreEncrypted = types.ConstError("store: durable store is encrypted, but no encryption key was supplied")
+	ErrWrongStoreKey  = types.ConstError("store: wrong durable store encryption key")
+	ErrUnsealedRecord = types.ConstError("store: record of an encrypted durable store is not encrypted")
+)
+
+// StoreKeySource supplies the key which encrypts the records of a durable store at rest: its objectives and their
+// summaries, channels, vouchers and payments, balance snapshots, activity, channel tags and memos, and pending
+// transactions.
+type StoreKeySource interface {
+	// StoreKey returns the 32 byte AES-256 key of the store, given the random salt generated when the store was first
+	// encrypted.
+	StoreKey(salt []byte) ([]byte, error)
+}
+
+// PassphraseKey derives the key of a store from a passphrase, with scrypt.
+type PassphraseKey string
+
+func (p PassphraseKey) StoreKey(salt []byte) ([]byte, error) {
+	if p == "" {
+		return nil, fmt.Errorf("the store passphrase is empty")
+	}
+	return scrypt.Key([]byte(p), salt, 1<<15, 8, 1, 32)
+}
+
+// StaticKey is the key of a store, supplied directly, such as a data key decrypted by an external KMS. It is used as
+// is, whatever the salt.
+type StaticKey []byte
+
+func (k StaticKey) StoreKey(salt []byte) ([]byte, error) {
+	if len(k) != 32 {
+		return nil, fmt.Errorf("store key has %d bytes, expected 32", len(k))
+	}
+	return k, nil
+}
+
+// CommandKey is a shell command which prints the key of a store, hex encoded, such as a KMS client's command to
+// decrypt a data key. It is run once, when the store is opened, with the salt hex encoded in STORE_KEY_SALT.
+type CommandKey string
+
+func (c CommandKey) StoreKey(salt []byte) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", string(c))
+	cmd.Env = append(cmd.Environ(), "STORE_KEY_SALT="+hex.EncodeToString(salt))
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("store key command failed: %w", err)
+	}
+	key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(string(out)), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("store key command did not print a hex encoded key: %w", err)
+	}
+	return StaticKey(key).StoreKey(salt)
+}
+
+// NewStoreKeySource returns the source of a store's key configured by a passphrase or a key command, or nil if neither
+// is set, as when they are given as command line flags.
+func NewStoreKeySource(passphrase, command string) (StoreKeySource, error) {
+	switch {
+	case passphrase != "" && command != "":
+		return nil, fmt.Errorf("a store passphrase and key command cannot both be given")
+	case passphrase != "":
+		return PassphraseKey(passphrase), nil
+	case command != "":
+		return CommandKey(command), nil
+	default:
+		return nil, nil
+	}
+}
+
+const (
+	// sealedPrefix marks an encrypted record. Unencrypted records are JSON objects, so never start with it.
+	sealedPrefix = "sealed:"
+
+	saltKey     = "salt"
+	keyCheckKey = "check"
+	// keyCheckValue is encrypted as the key check while records written before the store was encrypted may remain, and
+	// sealedCheckValue once they have all been encrypted. The check is authenticated, so the state of the store cannot
+	// be changed without its key.
+	keyCheckValue    = "go-nitro"
+	sealedCheckValue = "go-nitro/sealed/2"
+	// legacySealedCheckValue records that the records of legacySealedTables have all been encrypted, as stores sealed
+	// before the other tables were encrypted do.
+	legacySealedCheckValue = "go-nitro/sealed"
+)
+
+// sealedTables are the tables whose records are encrypted, if the store is.
+var sealedTables = []string{
+	"objectives", "channels", "consensus_channels", "objective_summaries", "vouchers", "payment_records",
+	"balance_snapshots", "activity", "channel_tags", "channel_memos", "pending_transactions",
+}
+
+var legacySealedTables = []string{"objectives", "channels", "consensus_channels", "vouchers", "payment_records", "activity", "channel_memos"}
+
+// tablesSealedBy are the tables whose records have all been encrypted, by the value of the key check recording it.
+var tablesSealedBy = map[string][]string{
+	keyCheckValue:          nil,
+	legacySealedCheckValue: legacySealedTables,
+	sealedCheckValue:       sealedTables,
+}
+
+// setUpEncryption reads the salt of the store's encryption from its encryption database, generating one if the store
+// is being encrypted for the first time, and checks that keys derives the key the store was encrypted with.
+//
+// Without keys, it checks that the store has never been encrypted.
+func (ds *DurableStore) setUpEncryption(keys StoreKeySource) error {
+	return ds.encryption.Update(func(tx *buntdb.Tx) error {
+		rawSalt, err := tx.Get(saltKey)
+		if errors.Is(err, buntdb.ErrNotFound) {
+			if keys == nil {
+				return nil
+			}
+			salt := make([]byte, 16)
+			if _, err := rand.Read(salt); err != nil {
+				return err
+			}
+			if ds.aead, err = newStoreCipher(keys, salt); err != nil {
+				return err
+			}
+			check, err := ds.seal("encryption", keyCheckKey, keyCheckValue)
+			if err != nil {
+				return err
+			}
+			if _, _, err := tx.Set(saltKey, hex.EncodeToString(salt), nil); err != nil {
+				return err
+			}
+			_, _, err = tx.Set(keyCheckKey, check, nil)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+		if keys == nil {
+			return ErrStoreEncrypted
+		}
+
+		salt, err := hex.DecodeString(rawSalt)
+		if err != nil {
+			return fmt.Errorf("invalid store salt: %w", err)
+		}
+		if ds.aead, err = newStoreCipher(keys, salt); err != nil {
+			return err
+		}
+		check, err := tx.Get(keyCheckKey)
+		if err != nil {
+			return err
+		}
+		value, err := ds.unseal("encryption", keyCheckKey, check)
+		sealed, ok := tablesSealedBy[value]
+		if err != nil || !ok {
+			return ErrWrongStoreKey
+		}
+		ds.sealed = sealed
+		return nil
+	})
+}
+
+// sealPlaintextRecords encrypts the records of the encrypted tables which are not encrypted, as those written before
+// the store was encrypted, or before their table was encrypted, are. Once they are all encrypted, the key check records that they are, so that a record
+// which is not encrypted is refused from then on, rather than being encrypted when the store is next opened.
+func (ds *DurableStore) sealPlaintextRecords() error {
+	tables := []struct {
+		name string
+		db   *buntdb.DB
+	}{
+		{"objectives", ds.objectives},
+		{"channels", ds.channels},
+		{"consensus_channels", ds.consensusChannels},
+		{"objective_summaries", ds.summaries},
+		{"vouchers", ds.vouchers},
+		{"payment_records", ds.paymentRecords},
+		{"balance_snapshots", ds.balanceSnapshots},
+		{"activity", ds.activity},
+		{"channel_tags", ds.channelTags},
+		{"channel_memos", ds.channelMemos},
+		{"pending_transactions", ds.pendingTxs},
+	}
+	for _, t := range tables {
+		if slices.Contains(ds.sealed, t.name) {
+			continue
+		}
+		plaintext := map[string]string{}
+		err := t.db.Update(func(tx *buntdb.Tx) error {
+			err := tx.Ascend("", func(key, value string) bool {
+				if !strings.HasPrefix(value, sealedPrefix) {
+					plaintext[key] = value
+				}
+				return true
+			})
+			if err != nil {
+				return err
+			}
+			for key, value := range plaintext {
+				sealed, err := ds.seal(t.name, key, value)
+				if err != nil {
+					return err
+				}
+				if _, _, err := tx.Set(key, sealed, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == nil && len(plaintext) > 0 {
+			// The database's file is an append-only log, which holds the records as they were until it is rewritten
+			err = t.db.Shrink()
+		}
+		if err != nil {
+			return fmt.Errorf("could not encrypt %s: %w", t.name, err)
+		}
+	}
+	check, err := ds.seal("encryption", keyCheckKey, sealedCheckValue)
+	if err != nil {
+		return err
+	}
+	err = ds.encryption.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(keyCheckKey, check, nil)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	ds.sealed = sealedTables
+	return nil
+}
+
+func newStoreCipher(keys StoreKeySource, salt []byte) (cipher.AEAD, error) {
+	key, err := keys.StoreKey(salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal returns the value to store for the record with the key in the table: the value encrypted with AES-GCM if the
+// store is encrypted, or the value itself if not. The table and key are authenticated, so that an encrypted value
+// cannot be passed off as another record's.
+func (ds *DurableStore) seal(table, key, value string) (string, error) {
+	if ds.aead == nil {
+		return value, nil
+	}
+	return sealWith(ds.aead, table+"/"+key, value)
+}
+
+// unseal returns the value of the record with the key in the table, given the value stored for it by seal. Once the
+// records of an encrypted store have all been encrypted, a record of an encrypted table which is not is refused, as
+// it was not written by the store.
+func (ds *DurableStore) unseal(table, key, stored string) (string, error) {
+	if !strings.HasPrefix(stored, sealedPrefix) {
+		if slices.Contains(ds.sealed, table) {
+			return "", fmt.Errorf("%w: %s/%s", ErrUnsealedRecord, table, key)
+		}
+		return stored, nil
+	}
+	if ds.aead == nil {
+		return "", ErrStoreEncrypted
+	}
+	return openWith(ds.aead, table+"/"+key, stored)
+}
+
+// sealWith encrypts the value of the record with the name, authenticating the name, and marks it with sealedPrefix.
+func sealWith(aead cipher.AEAD, name, value string) (string, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(name))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openWith decrypts the value of the record with the name, sealed by sealWith.
+func openWith(aead cipher.AEAD, name, stored string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(stored, sealedPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted record %s", name)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, []byte(name))
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt record %s: %w", name, err)
+	}
+	return string(value), nil
+}
+
+// RecordCipher encrypts records kept outside of a durable store, such as the entries of the engine's event log, with
+// a key from the same StoreKeySource as the store. The key is derived with a salt of its own, which must be kept with
+// the records, in the clear, to decrypt them.
+type RecordCipher struct {
+	Salt []byte
+	aead cipher.AEAD
+}
+
+// NewRecordCipher returns a RecordCipher with the key which keys supplies for the salt. A nil salt generates a new one.
+func NewRecordCipher(keys StoreKeySource, salt []byte) (*RecordCipher, error) {
+	if salt == nil {
+		salt = make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, err
+		}
+	}
+	aead, err := newStoreCipher(keys, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &RecordCipher{Salt: salt, aead: aead}, nil
+}
+
+// Seal encrypts the value of the record with the name. The name is authenticated, so that an encrypted value cannot be
+// passed off as another record's.
+func (c *RecordCipher) Seal(name, value string) (string, error) {
+	return sealWith(c.aead, name, value)
+}
+
+// Open decrypts the value of the record with the name, given the value returned by Seal for it.
+func (c *RecordCipher) Open(name, sealed string) (string, error) {
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", fmt.Errorf("record %s is not encrypted", name)
+	}
+	return openWith(c.aead, name, sealed)
+}
@@ -0,0 +1,235 @@
+package store_test
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+func TestEncryptedDurableStore(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	// Records written before the store is encrypted are encrypted when it is opened with a key
+	s, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dfo := td.Objectives.Directfund.GenericDFO()
+	if err := s.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	channelId := types.Destination{1}
+	const memo = "invoice 42"
+	if err := s.(*store.DurableStore).SetChannelMemo(channelId, memo); err != nil {
+		t.Fatal(err)
+	}
+	const tag = "acme-corp"
+	if err := s.(*store.DurableStore).SetChannelTags(channelId, map[string]string{"customer": tag}); err != nil {
+		t.Fatal(err)
+	}
+	balance := big.NewInt(987654321)
+	snapshot := store.BalanceSnapshot{ChannelId: channelId, Time: time.Now(), AssetAddress: ta.Irene.Address(), Me: ta.Alice.Address(), Them: ta.Bob.Address(), MyBalance: balance, TheirBalance: balance}
+	if err := s.(*store.DurableStore).SetBalanceSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	passphrase := store.PassphraseKey("correct horse battery staple")
+	s, err = store.NewEncryptedDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voucherInfo := payments.VoucherInfo{ChannelPayer: ta.Alice.Address(), ChannelPayee: ta.Bob.Address(), StartingBalance: big.NewInt(10), LargestVoucher: payments.Voucher{ChannelId: channelId, Amount: big.NewInt(3)}}
+	if err := s.SetVoucherInfo(channelId, voucherInfo); err != nil {
+		t.Fatal(err)
+	}
+	activity := store.ActivityRecord{Time: time.Now(), Kind: store.PaymentReceived, ChannelId: channelId, Amount: big.NewInt(3)}
+	if err := s.(*store.DurableStore).AppendActivity(activity); err != nil {
+		t.Fatal(err)
+	}
+	summary := store.ObjectiveSummary{Id: "DirectFunding-" + protocols.ObjectiveId(channelId.String()), Status: protocols.Completed, ChannelId: channelId, Collected: time.Now()}
+	if err := s.CollectObjective(summary); err != nil {
+		t.Fatal(err)
+	}
+	pending := store.PendingTransaction{ChannelId: channelId, Deposit: types.Funds{ta.Irene.Address(): balance}, Submitted: time.Now()}
+	if err := s.(*store.DurableStore).SetPendingTransaction(pending); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	plaintexts := []string{memo, tag, balance.String()}
+	for _, a := range []types.Address{ta.Alice.Address(), ta.Bob.Address(), ta.Irene.Address()} {
+		plaintexts = append(plaintexts, strings.ToLower(a.Hex()[2:]))
+	}
+	for _, table := range []string{
+		"objectives", "channels", "objective_summaries", "vouchers", "balance_snapshots", "activity", "channel_tags",
+		"channel_memos", "pending_transactions",
+	} {
+		files, err := filepath.Glob(filepath.Join(dataFolder, table+"_*.db"))
+		if err != nil || len(files) != 1 {
+			t.Fatalf("expected a %s database, got %v: %v", table, files, err)
+		}
+		contents, err := os.ReadFile(files[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(contents), `":`) {
+			t.Errorf("expected the %s database to hold no plaintext", table)
+		}
+		for _, plaintext := range plaintexts {
+			if strings.Contains(strings.ToLower(string(contents)), plaintext) {
+				t.Errorf("expected the %s database not to hold %s in plaintext", table, plaintext)
+			}
+		}
+	}
+
+	if _, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}); !errors.Is(err, store.ErrStoreEncrypted) {
+		t.Fatalf("expected %v, got %v", store.ErrStoreEncrypted, err)
+	}
+	if _, err := store.NewEncryptedDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, store.PassphraseKey("wrong")); !errors.Is(err, store.ErrWrongStoreKey) {
+		t.Fatalf("expected %v, got %v", store.ErrWrongStoreKey, err)
+	}
+
+	s, err = store.NewEncryptedDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	got, err := s.GetObjectiveById(dfo.Id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := compareObjectives(got, &dfo); diff != "" {
+		t.Errorf("objective mismatch (-want +got):\n%s", diff)
+	}
+	gotVoucherInfo, err := s.GetVoucherInfo(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotVoucherInfo.LargestVoucher.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected a voucher for 3, got %s", gotVoucherInfo.LargestVoucher.Amount)
+	}
+	gotMemo, err := s.(*store.DurableStore).GetChannelMemo(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotMemo != memo {
+		t.Errorf("expected memo %q, got %q", memo, gotMemo)
+	}
+	var gotActivity []store.ActivityRecord
+	err = s.(*store.DurableStore).GetActivity(activity.Time.Add(-time.Second), activity.Time.Add(time.Second), func(r store.ActivityRecord) bool {
+		gotActivity = append(gotActivity, r)
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotActivity) != 1 || gotActivity[0].Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected the payment's activity record, got %+v", gotActivity)
+	}
+	gotTags, err := s.(*store.DurableStore).GetChannelTags(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotTags["customer"] != tag {
+		t.Errorf("expected the channel to be tagged %q, got %v", tag, gotTags)
+	}
+	gotSnapshots, err := s.(*store.DurableStore).GetBalanceSnapshots(snapshot.Time.Add(-time.Second), snapshot.Time.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotSnapshots) != 1 || gotSnapshots[0].MyBalance.Cmp(balance) != 0 {
+		t.Errorf("expected the balance snapshot, got %+v", gotSnapshots)
+	}
+	gotSummary, err := s.GetObjectiveSummary(summary.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotSummary.ChannelId != channelId {
+		t.Errorf("expected the summary of the objective on %s, got %+v", channelId, gotSummary)
+	}
+	gotPending, err := s.(*store.DurableStore).GetPendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gotPending) != 1 || gotPending[0].Deposit[ta.Irene.Address()].Cmp(balance) != 0 {
+		t.Errorf("expected the pending deposit, got %+v", gotPending)
+	}
+
+	report, err := s.(*store.DurableStore).CheckIntegrity(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("expected no integrity problems, got %+v", report.Problems)
+	}
+}
+
+func TestEncryptedDurableStoreRefusesPlaintextRecords(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	passphrase := store.PassphraseKey("correct horse battery staple")
+	s, err := store.NewEncryptedDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.(*store.DurableStore).SetChannelMemo(types.Destination{1}, "invoice 42"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A record written to the database's file by anyone but the store is not encrypted, so is refused, rather than
+	// being encrypted when the store is next opened
+	files, err := filepath.Glob(filepath.Join(dataFolder, "channel_memos_*.db"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("expected a channel_memos database, got %v: %v", files, err)
+	}
+	db, err := buntdb.Open(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	planted := types.Destination{2}
+	err = db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(planted.String(), "pay mallory", nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = store.NewEncryptedDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{}, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.(*store.DurableStore).GetChannelMemo(planted); !errors.Is(err, store.ErrUnsealedRecord) {
+		t.Fatalf("expected %v, got %v", store.ErrUnsealedRecord, err)
+	}
+	if memo, err := s.(*store.DurableStore).GetChannelMemo(types.Destination{1}); err != nil || memo != "invoice 42" {
+		t.Fatalf("expected the memo written by the store, got %q: %v", memo, err)
+	}
+}
@@ -0,0 +1,117 @@
+package store
+
+import (
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrorReportingStore is a Store which reports the mutations which fail, before returning their errors.
+type ErrorReportingStore struct {
+	Store
+	onError func(op MutationOp, err error)
+}
+
+// NewErrorReportingStore returns a store which makes its mutations to s, calling onError with each mutation which fails.
+func NewErrorReportingStore(s Store, onError func(op MutationOp, err error)) *ErrorReportingStore {
+	return &ErrorReportingStore{Store: s, onError: onError}
+}
+
+// report calls onError if err is not nil, and returns err.
+func (es *ErrorReportingStore) report(op MutationOp, err error) error {
+	if err != nil {
+		es.onError(op, err)
+	}
+	return err
+}
+
+func (es *ErrorReportingStore) SetObjective(obj protocols.Objective) error {
+	return es.report(SetObjectiveOp, es.Store.SetObjective(obj))
+}
+
+func (es *ErrorReportingStore) SetChannel(ch *channel.Channel) error {
+	return es.report(SetChannelOp, es.Store.SetChannel(ch))
+}
+
+func (es *ErrorReportingStore) DestroyChannel(id types.Destination) error {
+	return es.report(DestroyChannelOp, es.Store.DestroyChannel(id))
+}
+
+func (es *ErrorReportingStore) ReleaseChannelFromOwnership(id types.Destination) error {
+	return es.report(ReleaseChannelOp, es.Store.ReleaseChannelFromOwnership(id))
+}
+
+func (es *ErrorReportingStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	return es.report(SetConsensusChannelOp, es.Store.SetConsensusChannel(ch))
+}
+
+func (es *ErrorReportingStore) DestroyConsensusChannel(id types.Destination) error {
+	return es.report(DestroyConsensusChannelOp, es.Store.DestroyConsensusChannel(id))
+}
+
+func (es *ErrorReportingStore) SetLastBlockNumSeen(blockNum uint64) error {
+	return es.report(SetLastBlockNumSeenOp, es.Store.SetLastBlockNumSeen(blockNum))
+}
+
+func (es *ErrorReportingStore) CollectObjective(summary ObjectiveSummary) error {
+	return es.report(CollectObjectiveOp, es.Store.CollectObjective(summary))
+}
+
+func (es *ErrorReportingStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return es.report(SetVoucherInfoOp, es.Store.SetVoucherInfo(channelId, v))
+}
+
+func (es *ErrorReportingStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return es.report(RemoveVoucherInfoOp, es.Store.RemoveVoucherInfo(channelId))
+}
+
+func (es *ErrorReportingStore) SetPaymentRecord(channelId types.Destination, paymentId string, r payments.PaymentRecord) error {
+	return es.report(SetPaymentRecordOp, es.Store.SetPaymentRecord(channelId, paymentId, r))
+}
+
+func (es *ErrorReportingStore) SetBalanceSnapshot(bs BalanceSnapshot) error {
+	return es.report(SetBalanceSnapshotOp, es.Store.SetBalanceSnapshot(bs))
+}
+
+func (es *ErrorReportingStore) AppendActivity(r ActivityRecord) error {
+	return es.report(AppendActivityOp, es.Store.AppendActivity(r))
+}
+
+func (es *ErrorReportingStore) NextMessageSeq(peer types.Address) (epoch uint64, seq uint64, err error) {
+	epoch, seq, err = es.Store.NextMessageSeq(peer)
+	return epoch, seq, es.report(NextMessageSeqOp, err)
+}
+
+func (es *ErrorReportingStore) SetMessageSequence(peer types.Address, ms MessageSequence) error {
+	return es.report(SetMessageSequenceOp, es.Store.SetMessageSequence(peer, ms))
+}
+
+func (es *ErrorReportingStore) SetChannelTags(id types.Destination, tags map[string]string) error {
+	return es.report(SetChannelTagsOp, es.Store.SetChannelTags(id, tags))
+}
+
+func (es *ErrorReportingStore) SetChannelMemo(id types.Destination, memo string) error {
+	return es.report(SetChannelMemoOp, es.Store.SetChannelMemo(id, memo))
+}
+
+func (es *ErrorReportingStore) SetConfigValue(key, value string) error {
+	return es.report(SetConfigValueOp, es.Store.SetConfigValue(key, value))
+}
+
+func (es *ErrorReportingStore) SetPendingTransaction(pt PendingTransaction) error {
+	return es.report(SetPendingTxOp, es.Store.SetPendingTransaction(pt))
+}
+
+func (es *ErrorReportingStore) RemovePendingTransaction(channelId types.Destination) error {
+	return es.report(RemovePendingTxOp, es.Store.RemovePendingTransaction(channelId))
+}
+
+func (es *ErrorReportingStore) SetBlockedPeer(bp BlockedPeer) error {
+	return es.report(SetBlockedPeerOp, es.Store.SetBlockedPeer(bp))
+}
+
+func (es *ErrorReportingStore) RemoveBlockedPeer(key string) error {
+	return es.report(RemoveBlockedPeerOp, es.Store.RemoveBlockedPeer(key))
+}
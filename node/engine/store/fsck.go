@@ -0,0 +1,224 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/tidwall/buntdb"
+)
+
+// IntegrityProblem describes a record of a durable store which failed an integrity check.
+type IntegrityProblem struct {
+	Table   string // The database holding the record, such as "objectives"
+	Key     string
+	Problem string
+	Repair  string // What was done about the problem, if the check was asked to repair it
+}
+
+// IntegrityReport is the outcome of an integrity check of a durable store.
+type IntegrityReport struct {
+	Checked  int // The number of records checked
+	Problems []IntegrityProblem
+}
+
+// QuarantinedRecord is a record which an integrity check set aside, because it was corrupt.
+type QuarantinedRecord struct {
+	Table       string
+	Key         string
+	Value       string
+	Problem     string
+	Quarantined time.Time
+}
+
+// CheckDurableStore opens the durable store of key in folder, checks its integrity, and closes it. The node using the
+// store must not be running. If the store is encrypted, keys must supply its key.
+func CheckDurableStore(key []byte, folder string, repair bool, keys StoreKeySource) (IntegrityReport, error) {
+	s, err := NewEncryptedDurableStore(key, folder, buntdb.Config{}, keys)
+	if err != nil {
+		return IntegrityReport{}, err
+	}
+	report, err := s.(*DurableStore).CheckIntegrity(repair)
+	return report, errors.Join(err, s.Close())
+}
+
+// CheckIntegrity checks that each record of the store can be decoded, that the channels the objectives refer to exist,
+// that the consensus state of each ledger channel is signed by both participants, and that no voucher pays more than
+// its channel holds. If repair is set, corrupt records are quarantined (see QuarantinedRecords), and references to
+// objectives which do not exist are removed.
+//
+// Records are checked in order of dependency, so that when repairing, objectives which refer to quarantined channels
+// are themselves quarantined.
+func (ds *DurableStore) CheckIntegrity(repair bool) (IntegrityReport, error) {
+	ic := integrityCheck{ds: ds, repair: repair}
+	checks := []struct {
+		table  string
+		db     *buntdb.DB
+		check  func(key, value string) error
+		remove bool // whether the repair removes the record, rather than quarantining it
+	}{
+		{"channels", ds.channels, checkChannelRecord, false},
+		{"consensus_channels", ds.consensusChannels, checkConsensusChannelRecord, false},
+		{"objectives", ds.objectives, ds.checkObjectiveRecord, false},
+		{"channel_to_objective", ds.channelToObjective, ds.checkChannelOwnerRecord, true},
+		{"vouchers", ds.vouchers, checkVoucherRecord, false},
+	}
+	for _, c := range checks {
+		if err := ic.checkTable(c.table, c.db, c.check, c.remove); err != nil {
+			return ic.report, err
+		}
+	}
+	return ic.report, nil
+}
+
+// QuarantinedRecords returns the records which integrity checks have quarantined.
+func (ds *DurableStore) QuarantinedRecords() ([]QuarantinedRecord, error) {
+	records := []QuarantinedRecord{}
+	var unmarshErr error
+	err := ds.quarantine.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, value string) bool {
+			var r QuarantinedRecord
+			unmarshErr = json.Unmarshal([]byte(value), &r)
+			if unmarshErr != nil {
+				return false
+			}
+			records = append(records, r)
+			return true
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, unmarshErr
+}
+
+type integrityCheck struct {
+	ds     *DurableStore
+	repair bool
+	report IntegrityReport
+}
+
+// checkTable runs check on each record of the database, reporting (and, if repairing, quarantining or removing) those
+// which fail it.
+func (ic *integrityCheck) checkTable(table string, db *buntdb.DB, check func(key, value string) error, remove bool) error {
+	type record struct{ key, value string }
+	records := []record{}
+	err := db.View(func(tx *buntdb.Tx) error {
+		return tx.Ascend("", func(key, value string) bool {
+			records = append(records, record{key, value})
+			return true
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range records {
+		ic.report.Checked++
+		value, checkErr := ic.ds.unseal(table, r.key, r.value)
+		if checkErr == nil {
+			checkErr = check(r.key, value)
+		}
+		if checkErr == nil {
+			continue
+		}
+		problem := IntegrityProblem{Table: table, Key: r.key, Problem: checkErr.Error()}
+		if ic.repair {
+			if remove {
+				problem.Repair = "removed"
+			} else {
+				problem.Repair = "quarantined"
+				if err := ic.ds.quarantineRecord(table, r.key, r.value, problem.Problem); err != nil {
+					return err
+				}
+			}
+			err := db.Update(func(tx *buntdb.Tx) error {
+				_, err := tx.Delete(r.key)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+		ic.report.Problems = append(ic.report.Problems, problem)
+	}
+	return nil
+}
+
+// quarantineRecord sets the record aside in the quarantine database.
+func (ds *DurableStore) quarantineRecord(table, key, value, problem string) error {
+	rJSON, err := json.Marshal(QuarantinedRecord{Table: table, Key: key, Value: value, Problem: problem, Quarantined: time.Now()})
+	if err != nil {
+		return err
+	}
+	return ds.quarantine.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(table+"/"+key, string(rJSON), nil)
+		return err
+	})
+}
+
+func checkChannelRecord(key, value string) error {
+	var ch channel.Channel
+	if err := json.Unmarshal([]byte(value), &ch); err != nil {
+		return fmt.Errorf("cannot decode channel: %w", err)
+	}
+	if ch.Id.String() != key {
+		return fmt.Errorf("channel %s is stored under the wrong key", ch.Id)
+	}
+	return nil
+}
+
+func checkConsensusChannelRecord(key, value string) error {
+	var ch consensus_channel.ConsensusChannel
+	if err := json.Unmarshal([]byte(value), &ch); err != nil {
+		return fmt.Errorf("cannot decode consensus channel: %w", err)
+	}
+	if ch.Id.String() != key || ch.FixedPart().ChannelId() != ch.Id {
+		return fmt.Errorf("consensus channel %s is stored under the wrong key, or does not match its fixed part", ch.Id)
+	}
+	if !ch.SupportedSignedState().HasAllSignatures() {
+		return fmt.Errorf("consensus state (turn %d) is not signed by both participants", ch.ConsensusTurnNum())
+	}
+	return nil
+}
+
+func (ds *DurableStore) checkObjectiveRecord(key, value string) error {
+	obj, err := decodeObjective(protocols.ObjectiveId(key), []byte(value))
+	if err != nil {
+		return fmt.Errorf("cannot decode objective: %w", err)
+	}
+	if err := ds.populateChannelData(obj); err != nil {
+		return fmt.Errorf("objective refers to a missing or corrupt channel: %w", err)
+	}
+	return nil
+}
+
+func (ds *DurableStore) checkChannelOwnerRecord(key, value string) error {
+	err := ds.objectives.View(func(tx *buntdb.Tx) error {
+		_, err := tx.Get(value)
+		return err
+	})
+	if errors.Is(err, buntdb.ErrNotFound) {
+		return fmt.Errorf("channel is owned by objective %s, which does not exist", value)
+	}
+	return err
+}
+
+func checkVoucherRecord(key, value string) error {
+	var v payments.VoucherInfo
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return fmt.Errorf("cannot decode voucher info: %w", err)
+	}
+	if v.StartingBalance == nil {
+		return fmt.Errorf("voucher info has no starting balance")
+	}
+	if paid := v.LargestVoucher.Amount; paid != nil && (paid.Sign() < 0 || paid.Cmp(v.StartingBalance) > 0) {
+		return fmt.Errorf("voucher pays %s, outside the channel's capacity of %s", paid, v.StartingBalance)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package store_test
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+func TestCheckIntegrity(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	s, err := store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An objective whose channel has gone missing
+	dfo := td.Objectives.Directfund.GenericDFO()
+	if err := s.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DestroyChannel(dfo.C.Id); err != nil {
+		t.Fatal(err)
+	}
+	// A voucher which is sound, and one which pays more than its channel holds
+	for i, paid := range []int64{5, 11} {
+		v := payments.VoucherInfo{
+			ChannelPayer:    ta.Alice.Address(),
+			ChannelPayee:    ta.Bob.Address(),
+			StartingBalance: big.NewInt(10),
+			LargestVoucher:  payments.Voucher{ChannelId: types.Destination{byte(i)}, Amount: big.NewInt(paid)},
+		}
+		if err := s.SetVoucherInfo(types.Destination{byte(i)}, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A channel record which cannot be decoded
+	channels, err := buntdb.Open(fmt.Sprintf("%s/channels_%s.db", dataFolder, ta.Alice.Address().String()[2:7]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = channels.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set(common.Hash{1}.Hex(), "{not json", nil)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := channels.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each corrupt record is reported, and quarantined if repairing
+	for _, repair := range []bool{false, true} {
+		report, err := store.CheckDurableStore(ta.Alice.PrivateKey, dataFolder, repair, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := []string{}
+		for _, p := range report.Problems {
+			got = append(got, p.Table+":"+p.Repair)
+		}
+		wantRepair := ""
+		if repair {
+			wantRepair = "quarantined"
+		}
+		want := []string{"channels:" + wantRepair, "objectives:" + wantRepair, "vouchers:" + wantRepair}
+		if !slices.Equal(got, want) {
+			t.Errorf("repair %v: expected problems %v, got %+v", repair, want, report.Problems)
+		}
+	}
+
+	report, err := store.CheckDurableStore(ta.Alice.PrivateKey, dataFolder, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) != 0 {
+		t.Errorf("expected no problems after repair, got %+v", report.Problems)
+	}
+
+	s, err = store.NewDurableStore(ta.Alice.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	quarantined, err := s.(*store.DurableStore).QuarantinedRecords()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(quarantined) != 3 {
+		t.Errorf("expected 3 records to be quarantined, got %+v", quarantined)
+	}
+	if _, err := s.GetVoucherInfo(types.Destination{0}); err != nil {
+		t.Errorf("expected the sound voucher to be kept: %v", err)
+	}
+}
@@ -0,0 +1,311 @@
+package store
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Metrics receives the measurements of an InstrumentedStore. It is satisfied by engine.Metrics, and so by the metrics
+// a node is constructed with.
+type Metrics interface {
+	IncCounter(name string, labels map[string]string)
+	RecordDuration(name string, d time.Duration, labels map[string]string)
+	SetGauge(name string, value float64, labels map[string]string)
+}
+
+// The names of the measurements an InstrumentedStore records, each labelled with the "op" it measures, such as
+// "set_channel" or "get_all_consensus_channels".
+const (
+	// OperationDurationMetric is the name of the duration recorded for each store operation.
+	OperationDurationMetric = "store_operation"
+	// OperationErrorsMetric is the name of the counter of store operations which returned an error. Lookups of records
+	// which do not exist count among them, for the operations which report those as errors.
+	OperationErrorsMetric = "store_operation_errors"
+	// OperationResultsMetric is the name of the gauge of the number of records returned by the last operation which
+	// lists records, such as every consensus channel.
+	OperationResultsMetric = "store_operation_results"
+)
+
+// InstrumentedStore is a Store which records the duration of each operation, and the errors and sizes of their
+// results, and logs operations which take longer than a threshold.
+type InstrumentedStore struct {
+	Store
+	metrics       Metrics
+	logger        *slog.Logger
+	slowThreshold time.Duration
+}
+
+// NewInstrumentedStore returns a store which makes its operations to s, recording them with metrics. Operations
+// taking slowThreshold or longer are logged as warnings; a slowThreshold of zero logs none.
+func NewInstrumentedStore(s Store, metrics Metrics, slowThreshold time.Duration) *InstrumentedStore {
+	return &InstrumentedStore{
+		Store:         s,
+		metrics:       metrics,
+		logger:        logging.LoggerWithAddress(logging.ModuleLogger(logging.STORE_MODULE), *s.GetAddress()),
+		slowThreshold: slowThreshold,
+	}
+}
+
+// observe records the operation, which began at start and failed if err is not nil, and returns err.
+func (is *InstrumentedStore) observe(op string, start time.Time, err error) error {
+	d := time.Since(start)
+	labels := map[string]string{"op": op}
+	is.metrics.RecordDuration(OperationDurationMetric, d, labels)
+	if err != nil {
+		is.metrics.IncCounter(OperationErrorsMetric, labels)
+	}
+	if is.slowThreshold > 0 && d >= is.slowThreshold {
+		is.logger.Warn("Slow store operation", "op", op, "duration", d, "error", err)
+	}
+	return err
+}
+
+// observeList records the operation as observe does, along with the number of records it returned.
+func (is *InstrumentedStore) observeList(op string, start time.Time, n int, err error) error {
+	if err == nil {
+		is.metrics.SetGauge(OperationResultsMetric, float64(n), map[string]string{"op": op})
+	}
+	return is.observe(op, start, err)
+}
+
+func (is *InstrumentedStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Objective, error) {
+	start := time.Now()
+	o, err := is.Store.GetObjectiveById(id)
+	return o, is.observe("get_objective_by_id", start, err)
+}
+
+func (is *InstrumentedStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
+	defer is.observe("get_objective_by_channel_id", time.Now(), nil)
+	return is.Store.GetObjectiveByChannelId(channelId)
+}
+
+func (is *InstrumentedStore) SetObjective(obj protocols.Objective) error {
+	return is.observe(string(SetObjectiveOp), time.Now(), is.Store.SetObjective(obj))
+}
+
+func (is *InstrumentedStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Channel, error) {
+	start := time.Now()
+	channels, err := is.Store.GetChannelsByIds(ids)
+	return channels, is.observeList("get_channels_by_ids", start, len(channels), err)
+}
+
+func (is *InstrumentedStore) GetChannelById(id types.Destination) (*channel.Channel, bool) {
+	defer is.observe("get_channel_by_id", time.Now(), nil)
+	return is.Store.GetChannelById(id)
+}
+
+func (is *InstrumentedStore) GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) {
+	start := time.Now()
+	channels, err := is.Store.GetChannelsByParticipant(participant)
+	return channels, is.observeList("get_channels_by_participant", start, len(channels), err)
+}
+
+func (is *InstrumentedStore) SetChannel(ch *channel.Channel) error {
+	return is.observe(string(SetChannelOp), time.Now(), is.Store.SetChannel(ch))
+}
+
+func (is *InstrumentedStore) DestroyChannel(id types.Destination) error {
+	return is.observe(string(DestroyChannelOp), time.Now(), is.Store.DestroyChannel(id))
+}
+
+func (is *InstrumentedStore) GetChannelsByAppDefinition(appDef types.Address) ([]*channel.Channel, error) {
+	start := time.Now()
+	channels, err := is.Store.GetChannelsByAppDefinition(appDef)
+	return channels, is.observeList("get_channels_by_app_definition", start, len(channels), err)
+}
+
+func (is *InstrumentedStore) ReleaseChannelFromOwnership(id types.Destination) error {
+	return is.observe(string(ReleaseChannelOp), time.Now(), is.Store.ReleaseChannelFromOwnership(id))
+}
+
+func (is *InstrumentedStore) GetLastBlockNumSeen() (uint64, error) {
+	start := time.Now()
+	blockNum, err := is.Store.GetLastBlockNumSeen()
+	return blockNum, is.observe("get_last_block_num_seen", start, err)
+}
+
+func (is *InstrumentedStore) SetLastBlockNumSeen(blockNum uint64) error {
+	return is.observe(string(SetLastBlockNumSeenOp), time.Now(), is.Store.SetLastBlockNumSeen(blockNum))
+}
+
+func (is *InstrumentedStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
+	start := time.Now()
+	channels, err := is.Store.GetAllConsensusChannels()
+	return channels, is.observeList("get_all_consensus_channels", start, len(channels), err)
+}
+
+func (is *InstrumentedStore) GetConsensusChannel(counterparty types.Address) (*consensus_channel.ConsensusChannel, bool) {
+	defer is.observe("get_consensus_channel", time.Now(), nil)
+	return is.Store.GetConsensusChannel(counterparty)
+}
+
+func (is *InstrumentedStore) GetConsensusChannelById(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+	start := time.Now()
+	c, err := is.Store.GetConsensusChannelById(id)
+	return c, is.observe("get_consensus_channel_by_id", start, err)
+}
+
+func (is *InstrumentedStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	return is.observe(string(SetConsensusChannelOp), time.Now(), is.Store.SetConsensusChannel(ch))
+}
+
+func (is *InstrumentedStore) DestroyConsensusChannel(id types.Destination) error {
+	return is.observe(string(DestroyConsensusChannelOp), time.Now(), is.Store.DestroyConsensusChannel(id))
+}
+
+func (is *InstrumentedStore) GetTerminalObjectiveIds() ([]protocols.ObjectiveId, error) {
+	start := time.Now()
+	ids, err := is.Store.GetTerminalObjectiveIds()
+	return ids, is.observeList("get_terminal_objective_ids", start, len(ids), err)
+}
+
+func (is *InstrumentedStore) GetObjectiveIdsByPrefix(prefix string) ([]protocols.ObjectiveId, error) {
+	start := time.Now()
+	ids, err := is.Store.GetObjectiveIdsByPrefix(prefix)
+	return ids, is.observeList("get_objective_ids_by_prefix", start, len(ids), err)
+}
+
+func (is *InstrumentedStore) CollectObjective(summary ObjectiveSummary) error {
+	return is.observe(string(CollectObjectiveOp), time.Now(), is.Store.CollectObjective(summary))
+}
+
+func (is *InstrumentedStore) GetObjectiveSummary(id protocols.ObjectiveId) (ObjectiveSummary, error) {
+	start := time.Now()
+	summary, err := is.Store.GetObjectiveSummary(id)
+	return summary, is.observe("get_objective_summary", start, err)
+}
+
+func (is *InstrumentedStore) SetBalanceSnapshot(bs BalanceSnapshot) error {
+	return is.observe(string(SetBalanceSnapshotOp), time.Now(), is.Store.SetBalanceSnapshot(bs))
+}
+
+func (is *InstrumentedStore) GetBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	start := time.Now()
+	snapshots, err := is.Store.GetBalanceSnapshots(from, to)
+	return snapshots, is.observeList("get_balance_snapshots", start, len(snapshots), err)
+}
+
+func (is *InstrumentedStore) AppendActivity(r ActivityRecord) error {
+	return is.observe(string(AppendActivityOp), time.Now(), is.Store.AppendActivity(r))
+}
+
+func (is *InstrumentedStore) GetActivity(from, to time.Time, fn func(ActivityRecord) bool) error {
+	start := time.Now()
+	n := 0
+	err := is.Store.GetActivity(from, to, func(r ActivityRecord) bool {
+		n++
+		return fn(r)
+	})
+	return is.observeList("get_activity", start, n, err)
+}
+
+func (is *InstrumentedStore) NextMessageSeq(peer types.Address) (epoch uint64, seq uint64, err error) {
+	start := time.Now()
+	epoch, seq, err = is.Store.NextMessageSeq(peer)
+	return epoch, seq, is.observe(string(NextMessageSeqOp), start, err)
+}
+
+func (is *InstrumentedStore) GetMessageSequence(peer types.Address) (MessageSequence, error) {
+	start := time.Now()
+	ms, err := is.Store.GetMessageSequence(peer)
+	return ms, is.observe("get_message_sequence", start, err)
+}
+
+func (is *InstrumentedStore) SetMessageSequence(peer types.Address, ms MessageSequence) error {
+	return is.observe(string(SetMessageSequenceOp), time.Now(), is.Store.SetMessageSequence(peer, ms))
+}
+
+func (is *InstrumentedStore) SetChannelTags(id types.Destination, tags map[string]string) error {
+	return is.observe(string(SetChannelTagsOp), time.Now(), is.Store.SetChannelTags(id, tags))
+}
+
+func (is *InstrumentedStore) GetChannelTags(id types.Destination) (map[string]string, error) {
+	start := time.Now()
+	tags, err := is.Store.GetChannelTags(id)
+	return tags, is.observe("get_channel_tags", start, err)
+}
+
+func (is *InstrumentedStore) SetChannelMemo(id types.Destination, memo string) error {
+	return is.observe(string(SetChannelMemoOp), time.Now(), is.Store.SetChannelMemo(id, memo))
+}
+
+func (is *InstrumentedStore) GetChannelMemo(id types.Destination) (string, error) {
+	start := time.Now()
+	memo, err := is.Store.GetChannelMemo(id)
+	return memo, is.observe("get_channel_memo", start, err)
+}
+
+func (is *InstrumentedStore) FindChannelsByTag(key, value string) ([]types.Destination, error) {
+	start := time.Now()
+	ids, err := is.Store.FindChannelsByTag(key, value)
+	return ids, is.observeList("find_channels_by_tag", start, len(ids), err)
+}
+
+func (is *InstrumentedStore) SetConfigValue(key, value string) error {
+	return is.observe(string(SetConfigValueOp), time.Now(), is.Store.SetConfigValue(key, value))
+}
+
+func (is *InstrumentedStore) GetConfigValues() (map[string]string, error) {
+	start := time.Now()
+	values, err := is.Store.GetConfigValues()
+	return values, is.observe("get_config_values", start, err)
+}
+
+func (is *InstrumentedStore) SetPendingTransaction(pt PendingTransaction) error {
+	return is.observe(string(SetPendingTxOp), time.Now(), is.Store.SetPendingTransaction(pt))
+}
+
+func (is *InstrumentedStore) RemovePendingTransaction(channelId types.Destination) error {
+	return is.observe(string(RemovePendingTxOp), time.Now(), is.Store.RemovePendingTransaction(channelId))
+}
+
+func (is *InstrumentedStore) GetPendingTransactions() ([]PendingTransaction, error) {
+	start := time.Now()
+	pending, err := is.Store.GetPendingTransactions()
+	return pending, is.observeList("get_pending_transactions", start, len(pending), err)
+}
+
+func (is *InstrumentedStore) SetBlockedPeer(bp BlockedPeer) error {
+	return is.observe(string(SetBlockedPeerOp), time.Now(), is.Store.SetBlockedPeer(bp))
+}
+
+func (is *InstrumentedStore) RemoveBlockedPeer(key string) error {
+	return is.observe(string(RemoveBlockedPeerOp), time.Now(), is.Store.RemoveBlockedPeer(key))
+}
+
+func (is *InstrumentedStore) GetBlockedPeers() ([]BlockedPeer, error) {
+	start := time.Now()
+	peers, err := is.Store.GetBlockedPeers()
+	return peers, is.observeList("get_blocked_peers", start, len(peers), err)
+}
+
+func (is *InstrumentedStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return is.observe(string(SetVoucherInfoOp), time.Now(), is.Store.SetVoucherInfo(channelId, v))
+}
+
+func (is *InstrumentedStore) GetVoucherInfo(channelId types.Destination) (*payments.VoucherInfo, error) {
+	start := time.Now()
+	v, err := is.Store.GetVoucherInfo(channelId)
+	return v, is.observe("get_voucher_info", start, err)
+}
+
+func (is *InstrumentedStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return is.observe(string(RemoveVoucherInfoOp), time.Now(), is.Store.RemoveVoucherInfo(channelId))
+}
+
+func (is *InstrumentedStore) SetPaymentRecord(channelId types.Destination, paymentId string, r payments.PaymentRecord) error {
+	return is.observe(string(SetPaymentRecordOp), time.Now(), is.Store.SetPaymentRecord(channelId, paymentId, r))
+}
+
+func (is *InstrumentedStore) GetPaymentRecord(channelId types.Destination, paymentId string) (payments.PaymentRecord, bool, error) {
+	start := time.Now()
+	r, ok, err := is.Store.GetPaymentRecord(channelId, paymentId)
+	return r, ok, is.observe("get_payment_record", start, err)
+}
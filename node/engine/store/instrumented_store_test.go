@@ -0,0 +1,98 @@
+package store_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// recordingMetrics records the measurements it receives, keyed by metric name and "op" label.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	counters  map[string]int
+	durations map[string]int
+	gauges    map[string]float64
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: map[string]int{}, durations: map[string]int{}, gauges: map[string]float64{}}
+}
+
+func (m *recordingMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+"/"+labels["op"]]++
+}
+
+func (m *recordingMetrics) RecordDuration(name string, d time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations[name+"/"+labels["op"]]++
+}
+
+func (m *recordingMetrics) SetGauge(name string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[name+"/"+labels["op"]] = value
+}
+
+// slowStore is a store whose GetBlockedPeers takes longer than the slow threshold of the test.
+type slowStore struct {
+	store.Store
+}
+
+func (s slowStore) GetBlockedPeers() ([]store.BlockedPeer, error) {
+	time.Sleep(20 * time.Millisecond)
+	return s.Store.GetBlockedPeers()
+}
+
+func TestInstrumentedStore(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	metrics := newRecordingMetrics()
+	s := store.NewInstrumentedStore(slowStore{store.NewMemStore(sk)}, metrics, 10*time.Millisecond)
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	if err := s.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetObjectiveById(dfo.Id()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetConsensusChannelById(types.Destination{1}); err == nil {
+		t.Fatal("expected the lookup of a missing consensus channel to fail")
+	}
+	if _, _, err := s.NextMessageSeq(ta.Bob.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetChannelsByParticipant(ta.Alice.Address()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetBlockedPeers(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range []string{"set_objective", "get_objective_by_id", "get_consensus_channel_by_id", "next_message_seq", "get_channels_by_participant", "get_blocked_peers"} {
+		if metrics.durations[store.OperationDurationMetric+"/"+op] != 1 {
+			t.Errorf("expected the duration of %s to be recorded once, got %d", op, metrics.durations[store.OperationDurationMetric+"/"+op])
+		}
+	}
+	if n := metrics.counters[store.OperationErrorsMetric+"/get_consensus_channel_by_id"]; n != 1 {
+		t.Errorf("expected the failed lookup to be counted, got %d", n)
+	}
+	if n := metrics.counters[store.OperationErrorsMetric+"/set_objective"]; n != 0 {
+		t.Errorf("expected no errors of set_objective to be counted, got %d", n)
+	}
+	// The objective's channel, whose participants include alice, is stored with it
+	if n, ok := metrics.gauges[store.OperationResultsMetric+"/get_channels_by_participant"]; !ok || n != 1 {
+		t.Errorf("expected the one channel of alice to be measured, got %v", n)
+	}
+	if n, ok := metrics.gauges[store.OperationResultsMetric+"/get_blocked_peers"]; !ok || n != 0 {
+		t.Errorf("expected the lack of blocked peers to be measured, got %v", n)
+	}
+}
@@ -3,7 +3,13 @@ package store
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
@@ -12,8 +18,10 @@ import (
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/types"
@@ -24,29 +32,67 @@ type blockData struct {
 	mu       sync.Mutex
 }
 
+type messageSequences struct {
+	epoch    uint64
+	outgoing map[types.Address]uint64
+	incoming map[types.Address]MessageSequence
+	mu       sync.Mutex
+}
+
 type MemStore struct {
 	objectives         safesync.Map[[]byte]
 	channels           safesync.Map[[]byte]
 	consensusChannels  safesync.Map[[]byte]
 	channelToObjective safesync.Map[protocols.ObjectiveId]
+	summaries          safesync.Map[ObjectiveSummary]
 	vouchers           safesync.Map[[]byte]
+	paymentRecords     safesync.Map[[]byte]
+	balanceSnapshots   safesync.Map[[]byte]
+	activity           safesync.Map[[]byte]
+	activitySeq        *atomic.Uint64
+	channelTags        safesync.Map[map[string]string]
+	channelMemos       safesync.Map[string]
+	config             safesync.Map[string]
+	pendingTxs         safesync.Map[PendingTransaction]
+	blockedPeers       safesync.Map[BlockedPeer]
 	lastBlockSeen      blockData
+	messageSequences   *messageSequences
 
 	key     string // the signing key of the store's engine
 	address string // the (Ethereum) address associated to the signing key
 }
 
 func NewMemStore(key []byte) Store {
+	return newMemStore(key, crypto.GetAddressFromSecretKeyBytes(key))
+}
+
+// newMemStore returns a MemStore for the address, holding its key unless key is nil.
+func newMemStore(key []byte, address types.Address) Store {
 	ms := MemStore{}
 	ms.key = common.Bytes2Hex(key)
-	ms.address = crypto.GetAddressFromSecretKeyBytes(key).String()
+	ms.address = address.String()
 
 	ms.objectives = safesync.Map[[]byte]{}
 	ms.channels = safesync.Map[[]byte]{}
 	ms.consensusChannels = safesync.Map[[]byte]{}
 	ms.channelToObjective = safesync.Map[protocols.ObjectiveId]{}
+	ms.summaries = safesync.Map[ObjectiveSummary]{}
 	ms.vouchers = safesync.Map[[]byte]{}
+	ms.paymentRecords = safesync.Map[[]byte]{}
+	ms.balanceSnapshots = safesync.Map[[]byte]{}
+	ms.activity = safesync.Map[[]byte]{}
+	ms.activitySeq = &atomic.Uint64{}
+	ms.channelTags = safesync.Map[map[string]string]{}
+	ms.channelMemos = safesync.Map[string]{}
+	ms.config = safesync.Map[string]{}
+	ms.pendingTxs = safesync.Map[PendingTransaction]{}
+	ms.blockedPeers = safesync.Map[BlockedPeer]{}
 	ms.lastBlockSeen = blockData{}
+	ms.messageSequences = &messageSequences{
+		epoch:    newEpoch(),
+		outgoing: make(map[types.Address]uint64),
+		incoming: make(map[types.Address]MessageSequence),
+	}
 	return &ms
 }
 
@@ -61,6 +107,9 @@ func (ms *MemStore) GetAddress() *types.Address {
 }
 
 func (ms *MemStore) GetChannelSecretKey() *[]byte {
+	if ms.key == "" {
+		return nil
+	}
 	val := common.Hex2Bytes(ms.key)
 	return &val
 }
@@ -71,6 +120,9 @@ func (ms *MemStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Object
 
 	// return immediately if no such objective exists
 	if !ok {
+		if _, collected := ms.summaries.Load(string(id)); collected {
+			return nil, fmt.Errorf("%w: %s", ErrObjectiveCollected, id)
+		}
 		return nil, fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
 	}
 
@@ -149,6 +201,29 @@ func (ms *MemStore) GetLastBlockNumSeen() (uint64, error) {
 	return lastBlockNumSeen, nil
 }
 
+// NextMessageSeq returns the epoch of the store and the number of the next message to peer.
+func (ms *MemStore) NextMessageSeq(peer types.Address) (uint64, uint64, error) {
+	ms.messageSequences.mu.Lock()
+	defer ms.messageSequences.mu.Unlock()
+	ms.messageSequences.outgoing[peer]++
+	return ms.messageSequences.epoch, ms.messageSequences.outgoing[peer], nil
+}
+
+// GetMessageSequence returns the record of which of peer's messages have been processed.
+func (ms *MemStore) GetMessageSequence(peer types.Address) (MessageSequence, error) {
+	ms.messageSequences.mu.Lock()
+	defer ms.messageSequences.mu.Unlock()
+	return ms.messageSequences.incoming[peer], nil
+}
+
+// SetMessageSequence sets the record of which of peer's messages have been processed.
+func (ms *MemStore) SetMessageSequence(peer types.Address, seq MessageSequence) error {
+	ms.messageSequences.mu.Lock()
+	defer ms.messageSequences.mu.Unlock()
+	ms.messageSequences.incoming[peer] = seq
+	return nil
+}
+
 // SetChannel sets the channel in the store.
 func (ms *MemStore) SetChannel(ch *channel.Channel) error {
 	chJSON, err := ch.MarshalJSON()
@@ -390,6 +465,24 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 
 		o.C = &ch
 
+		return nil
+	case *challenge.Objective:
+		ch, err := ms.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
+		return nil
+	case *ledgertopup.Objective:
+		ch, err := ms.getChannelById(o.C.Id)
+		if err != nil {
+			return fmt.Errorf("error retrieving channel data for objective %s: %w", id, err)
+		}
+
+		o.C = &ch
+
 		return nil
 	case *virtualfund.Objective:
 		v, err := ms.getChannelById(o.V.Id)
@@ -451,6 +544,10 @@ func (ms *MemStore) populateChannelData(obj protocols.Objective) error {
 		}
 		return nil
 	default:
+		// Registered objective types are stored whole
+		if _, ok := protocols.LookupObjectiveType(id); ok {
+			return nil
+		}
 		return fmt.Errorf("objective %s did not correctly represent a known Objective type", id)
 	}
 }
@@ -468,6 +565,14 @@ func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective
 		ddfo := directdefund.Objective{}
 		err := ddfo.UnmarshalJSON(data)
 		return &ddfo, err
+	case challenge.IsChallengeObjective(id):
+		co := challenge.Objective{}
+		err := co.UnmarshalJSON(data)
+		return &co, err
+	case ledgertopup.IsLedgerTopUpObjective(id):
+		tuo := ledgertopup.Objective{}
+		err := tuo.UnmarshalJSON(data)
+		return &tuo, err
 	case virtualfund.IsVirtualFundObjective(id):
 		vfo := virtualfund.Objective{}
 		err := vfo.UnmarshalJSON(data)
@@ -477,6 +582,9 @@ func decodeObjective(id protocols.ObjectiveId, data []byte) (protocols.Objective
 		err := dvfo.UnmarshalJSON(data)
 		return &dvfo, err
 	default:
+		if t, ok := protocols.LookupObjectiveType(id); ok {
+			return t.Decode(data)
+		}
 		return nil, fmt.Errorf("objective id %s does not correspond to a known Objective type", id)
 
 	}
@@ -487,6 +595,52 @@ func (ms *MemStore) ReleaseChannelFromOwnership(channelId types.Destination) err
 	return nil
 }
 
+func (ms *MemStore) GetTerminalObjectiveIds() ([]protocols.ObjectiveId, error) {
+	ids := []protocols.ObjectiveId{}
+	var err error
+	ms.objectives.Range(func(key string, objJSON []byte) bool {
+		var terminal bool
+		terminal, err = isTerminal(objJSON)
+		if err != nil {
+			err = fmt.Errorf("error decoding objective %s: %w", key, err)
+			return false
+		}
+		if terminal {
+			ids = append(ids, protocols.ObjectiveId(key))
+		}
+		return true
+	})
+	return ids, err
+}
+
+func (ms *MemStore) GetObjectiveIdsByPrefix(prefix string) ([]protocols.ObjectiveId, error) {
+	ids := []protocols.ObjectiveId{}
+	ms.objectives.Range(func(key string, objJSON []byte) bool {
+		if strings.HasPrefix(key, prefix) {
+			ids = append(ids, protocols.ObjectiveId(key))
+		}
+		return true
+	})
+	return ids, nil
+}
+
+func (ms *MemStore) CollectObjective(summary ObjectiveSummary) error {
+	ms.summaries.Store(string(summary.Id), summary)
+	ms.objectives.Delete(string(summary.Id))
+	if owner, ok := ms.channelToObjective.Load(summary.ChannelId.String()); ok && owner == summary.Id {
+		ms.channelToObjective.Delete(summary.ChannelId.String())
+	}
+	return nil
+}
+
+func (ms *MemStore) GetObjectiveSummary(id protocols.ObjectiveId) (ObjectiveSummary, error) {
+	summary, ok := ms.summaries.Load(string(id))
+	if !ok {
+		return ObjectiveSummary{}, fmt.Errorf("%w: %s", ErrNoSuchObjective, id)
+	}
+	return summary, nil
+}
+
 func (ms *MemStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
 	jsonData, err := json.Marshal(v)
 	if err != nil {
@@ -512,6 +666,106 @@ func (ms *MemStore) GetVoucherInfo(channelId types.Destination) (*payments.Vouch
 
 func (ms *MemStore) RemoveVoucherInfo(channelId types.Destination) error {
 	ms.vouchers.Delete(channelId.String())
+
+	prefix := paymentRecordKey(channelId, "")
+	ms.paymentRecords.Range(func(key string, _ []byte) bool {
+		if strings.HasPrefix(key, prefix) {
+			ms.paymentRecords.Delete(key)
+		}
+		return true
+	})
+	return nil
+}
+
+func (ms *MemStore) GetPaymentRecord(channelId types.Destination, paymentId string) (payments.PaymentRecord, bool, error) {
+	data, ok := ms.paymentRecords.Load(paymentRecordKey(channelId, paymentId))
+	if !ok {
+		return payments.PaymentRecord{}, false, nil
+	}
+	r := payments.PaymentRecord{}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return payments.PaymentRecord{}, false, err
+	}
+	return r, true, nil
+}
+
+func (ms *MemStore) SetPaymentRecord(channelId types.Destination, paymentId string, r payments.PaymentRecord) error {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ms.paymentRecords.Store(paymentRecordKey(channelId, paymentId), jsonData)
+	return nil
+}
+
+func (ms *MemStore) SetBalanceSnapshot(bs BalanceSnapshot) error {
+	jsonData, err := json.Marshal(bs)
+	if err != nil {
+		return err
+	}
+	ms.balanceSnapshots.Store(bs.key(), jsonData)
+	return nil
+}
+
+func (ms *MemStore) GetBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) {
+	lower, upper := timeKey(from), timeKey(to)
+	keys := []string{}
+	ms.balanceSnapshots.Range(func(key string, _ []byte) bool {
+		if key >= lower && key < upper {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	sort.Strings(keys)
+
+	snapshots := make([]BalanceSnapshot, 0, len(keys))
+	for _, key := range keys {
+		data, ok := ms.balanceSnapshots.Load(key)
+		if !ok {
+			continue
+		}
+		bs := BalanceSnapshot{}
+		if err := json.Unmarshal(data, &bs); err != nil {
+			return nil, fmt.Errorf("error unmarshaling balance snapshot %s: %w", key, err)
+		}
+		snapshots = append(snapshots, bs)
+	}
+	return snapshots, nil
+}
+
+func (ms *MemStore) AppendActivity(r ActivityRecord) error {
+	jsonData, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	ms.activity.Store(activityKey(r, ms.activitySeq.Add(1)), jsonData)
+	return nil
+}
+
+func (ms *MemStore) GetActivity(from, to time.Time, fn func(ActivityRecord) bool) error {
+	lower, upper := timeKey(from), timeKey(to)
+	keys := []string{}
+	ms.activity.Range(func(key string, _ []byte) bool {
+		if key >= lower && key < upper {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data, ok := ms.activity.Load(key)
+		if !ok {
+			continue
+		}
+		r := ActivityRecord{}
+		if err := json.Unmarshal(data, &r); err != nil {
+			return fmt.Errorf("error unmarshaling activity record %s: %w", key, err)
+		}
+		if !fn(r) {
+			return nil
+		}
+	}
 	return nil
 }
 
@@ -524,3 +778,109 @@ func contains[T types.Destination | protocols.ObjectiveId](col []T, item T) bool
 	}
 	return false
 }
+
+// SetChannelTags replaces the tags of the channel.
+func (ms *MemStore) SetChannelTags(id types.Destination, tags map[string]string) error {
+	if len(tags) == 0 {
+		ms.channelTags.Delete(id.String())
+		return nil
+	}
+	ms.channelTags.Store(id.String(), maps.Clone(tags))
+	return nil
+}
+
+// GetChannelTags returns the tags of the channel, or nil if it has none.
+func (ms *MemStore) GetChannelTags(id types.Destination) (map[string]string, error) {
+	tags, _ := ms.channelTags.Load(id.String())
+	return maps.Clone(tags), nil
+}
+
+// FindChannelsByTag returns the ids of the channels tagged with the key and value.
+func (ms *MemStore) FindChannelsByTag(key, value string) ([]types.Destination, error) {
+	ids := []types.Destination{}
+	ms.channelTags.Range(func(id string, tags map[string]string) bool {
+		if v, ok := tags[key]; ok && v == value {
+			ids = append(ids, types.Destination(common.HexToHash(id)))
+		}
+		return true
+	})
+	return ids, nil
+}
+
+// SetChannelMemo replaces the memo of the channel.
+func (ms *MemStore) SetChannelMemo(id types.Destination, memo string) error {
+	if memo == "" {
+		ms.channelMemos.Delete(id.String())
+		return nil
+	}
+	ms.channelMemos.Store(id.String(), memo)
+	return nil
+}
+
+// GetChannelMemo returns the memo of the channel, or "" if it has none.
+func (ms *MemStore) GetChannelMemo(id types.Destination) (string, error) {
+	memo, _ := ms.channelMemos.Load(id.String())
+	return memo, nil
+}
+
+// SetConfigValue records a setting changed while the node runs.
+func (ms *MemStore) SetConfigValue(key, value string) error {
+	ms.config.Store(key, value)
+	return nil
+}
+
+// GetConfigValues returns the settings changed while the node runs.
+func (ms *MemStore) GetConfigValues() (map[string]string, error) {
+	values := map[string]string{}
+	ms.config.Range(func(key string, value string) bool {
+		values[key] = value
+		return true
+	})
+	return values, nil
+}
+
+// SetPendingTransaction records a chain transaction which may not yet have been mined.
+func (ms *MemStore) SetPendingTransaction(pt PendingTransaction) error {
+	pt.TxHashes = slices.Clone(pt.TxHashes)
+	ms.pendingTxs.Store(pt.ChannelId.String(), pt)
+	return nil
+}
+
+// GetPendingTransactions returns the chain transactions which may not yet have been mined.
+func (ms *MemStore) GetPendingTransactions() ([]PendingTransaction, error) {
+	pending := []PendingTransaction{}
+	ms.pendingTxs.Range(func(_ string, pt PendingTransaction) bool {
+		pt.TxHashes = slices.Clone(pt.TxHashes)
+		pending = append(pending, pt)
+		return true
+	})
+	return pending, nil
+}
+
+// RemovePendingTransaction removes the record of the channel's pending transaction, if any.
+func (ms *MemStore) RemovePendingTransaction(channelId types.Destination) error {
+	ms.pendingTxs.Delete(channelId.String())
+	return nil
+}
+
+// SetBlockedPeer records the peer as blocked.
+func (ms *MemStore) SetBlockedPeer(bp BlockedPeer) error {
+	ms.blockedPeers.Store(bp.Key(), bp)
+	return nil
+}
+
+// GetBlockedPeers returns the peers which have been blocked.
+func (ms *MemStore) GetBlockedPeers() ([]BlockedPeer, error) {
+	blocked := []BlockedPeer{}
+	ms.blockedPeers.Range(func(_ string, bp BlockedPeer) bool {
+		blocked = append(blocked, bp)
+		return true
+	})
+	return blocked, nil
+}
+
+// RemoveBlockedPeer removes the record of the peer blocked under the key, if any.
+func (ms *MemStore) RemoveBlockedPeer(key string) error {
+	ms.blockedPeers.Delete(key)
+	return nil
+}
@@ -0,0 +1,202 @@
+package store_test
+
+import (
+	"database/sql"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/channel"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// postgresDsn returns the database to test the postgres store against, skipping the test if there is none. The
+// database is emptied of the store's tables, so it must not be one which is in use.
+func postgresDsn(t *testing.T) string {
+	t.Helper()
+	dsn := os.Getenv("NITRO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("NITRO_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`DO $$ DECLARE t text; BEGIN
+		FOR t IN SELECT tablename FROM pg_tables WHERE schemaname = current_schema() AND tablename LIKE 'nitro\_%' LOOP
+			EXECUTE 'DROP TABLE ' || quote_ident(t) || ' CASCADE';
+		END LOOP;
+	END $$`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dsn
+}
+
+func TestPostgresStoreUnreachable(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	if _, err := store.NewPostgresStore(pk, "postgres://nitro@127.0.0.1:1/nitro?sslmode=disable&connect_timeout=1"); err == nil {
+		t.Fatal("expected a store without a database to fail to open")
+	}
+}
+
+func TestPostgresStore(t *testing.T) {
+	dsn := postgresDsn(t)
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	s, err := store.NewPostgresStore(pk, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Objectives are kept with their channels, and own their channel once approved
+	dfo := td.Objectives.Directfund.GenericDFO()
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	vfo.Status = protocols.Completed
+	for _, want := range []protocols.Objective{&dfo, &vfo} {
+		if err := s.SetObjective(want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetObjectiveById(want.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Errorf("expected no diff between set and retrieved objective, but found:\n%s", diff)
+		}
+	}
+	if _, ok := s.GetObjectiveByChannelId(dfo.C.Id); ok {
+		t.Error("when an unapproved objective is stored, the objective should not own the channel")
+	}
+	dfo.Status = protocols.Approved
+	if err := s.SetObjective(&dfo); err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := s.GetObjectiveByChannelId(dfo.C.Id); !ok || got.Id() != dfo.Id() {
+		t.Errorf("expected the approved objective to own its channel, got %v", got)
+	}
+	participants, err := s.GetChannelsByParticipant(dfo.C.Participants[0])
+	if err != nil || len(participants) == 0 {
+		t.Errorf("expected to find the objective's channel by participant, got %v (%v)", participants, err)
+	}
+
+	// Terminal objectives may be collected
+	ids, err := s.GetTerminalObjectiveIds()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]protocols.ObjectiveId{vfo.Id()}, ids); diff != "" {
+		t.Fatalf("unexpected terminal objectives (-want +got):\n%s", diff)
+	}
+	summary := store.ObjectiveSummary{Id: vfo.Id(), Status: vfo.Status, ChannelId: vfo.OwnsChannel(), Collected: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := s.CollectObjective(summary); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetObjectiveById(vfo.Id()); !errors.Is(err, store.ErrObjectiveCollected) {
+		t.Fatalf("expected %v, got %v", store.ErrObjectiveCollected, err)
+	}
+
+	// Vouchers are removed with the records of their payments
+	channelId := dfo.C.Id
+	if err := s.SetVoucherInfo(channelId, payments.VoucherInfo{ChannelPayer: ta.Alice.Address(), ChannelPayee: ta.Bob.Address(), StartingBalance: big.NewInt(10), LargestVoucher: payments.Voucher{ChannelId: channelId, Amount: big.NewInt(3)}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SetPaymentRecord(channelId, "order-1", payments.PaymentRecord{Amount: big.NewInt(3)}); err != nil {
+		t.Fatal(err)
+	}
+	v, err := s.GetVoucherInfo(channelId)
+	if err != nil || v.LargestVoucher.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected the voucher info to be kept, got %+v (%v)", v, err)
+	}
+	if err := s.RemoveVoucherInfo(channelId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.GetVoucherInfo(channelId); !errors.Is(err, store.ErrLoadVouchers) {
+		t.Errorf("expected %v, got %v", store.ErrLoadVouchers, err)
+	}
+	if _, ok, _ := s.GetPaymentRecord(channelId, "order-1"); ok {
+		t.Error("expected the payment record to be removed with the voucher info")
+	}
+
+	if err := s.SetLastBlockNumSeen(15); err != nil {
+		t.Fatal(err)
+	}
+	blocked := store.BlockedPeer{Address: ta.Bob.Address(), Reason: "spam", Blocked: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	if err := s.SetBlockedPeer(blocked); err != nil {
+		t.Fatal(err)
+	}
+	epoch, _, err := s.NextMessageSeq(ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The store outlasts the node, and belongs to it alone
+	reopened, err := store.NewPostgresStore(pk, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if got, err := reopened.GetLastBlockNumSeen(); err != nil || got != 15 {
+		t.Errorf("expected block 15 to be kept, got %d (%v)", got, err)
+	}
+	reopenedEpoch, seq, err := reopened.NextMessageSeq(ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopenedEpoch != epoch || seq != 2 {
+		t.Errorf("expected epoch %d and message number 2 after reopening, got %d and %d", epoch, reopenedEpoch, seq)
+	}
+	if got, err := reopened.GetBlockedPeers(); err != nil || !cmp.Equal([]store.BlockedPeer{blocked}, got) {
+		t.Errorf("expected the blocklist to be kept, got %v (%v)", got, err)
+	}
+	if ch, ok := reopened.GetChannelById(channelId); !ok || ch.Id != channelId {
+		t.Errorf("expected channel %s to be kept, got %v", channelId, ch)
+	}
+	if _, err := store.NewPostgresStore(ta.Bob.PrivateKey, dsn); !errors.Is(err, store.ErrWrongNode) {
+		t.Errorf("expected %v, got %v", store.ErrWrongNode, err)
+	}
+}
+
+func TestPostgresStoreChannels(t *testing.T) {
+	dsn := postgresDsn(t)
+	s, err := store.NewPostgresStore(ta.Alice.PrivateKey, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	a := td.Objectives.Directfund.GenericDFO().C
+	b := td.Objectives.Virtualfund.GenericVFO().V.Channel
+	for _, ch := range []*channel.Channel{a, &b} {
+		if err := s.SetChannel(ch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := s.GetChannelsByIds([]types.Destination{a.Id, b.Id})
+	if err != nil || len(got) != 2 {
+		t.Fatalf("expected both channels, got %v (%v)", got, err)
+	}
+	byApp, err := s.GetChannelsByAppDefinition(a.AppDefinition)
+	if err != nil || len(byApp) == 0 {
+		t.Errorf("expected to find channels by app definition, got %v (%v)", byApp, err)
+	}
+	if err := s.DestroyChannel(a.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.GetChannelById(a.Id); ok {
+		t.Error("expected the destroyed channel to be gone")
+	}
+}
@@ -0,0 +1,739 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// MutationOp names the Store method which made a Mutation.
+type MutationOp string
+
+const (
+	SetObjectiveOp            MutationOp = "set_objective"
+	SetChannelOp              MutationOp = "set_channel"
+	DestroyChannelOp          MutationOp = "destroy_channel"
+	ReleaseChannelOp          MutationOp = "release_channel"
+	SetConsensusChannelOp     MutationOp = "set_consensus_channel"
+	DestroyConsensusChannelOp MutationOp = "destroy_consensus_channel"
+	SetLastBlockNumSeenOp     MutationOp = "set_last_block_num_seen"
+	CollectObjectiveOp        MutationOp = "collect_objective"
+	SetVoucherInfoOp          MutationOp = "set_voucher_info"
+	RemoveVoucherInfoOp       MutationOp = "remove_voucher_info"
+	SetPaymentRecordOp        MutationOp = "set_payment_record"
+	SetBalanceSnapshotOp      MutationOp = "set_balance_snapshot"
+	AppendActivityOp          MutationOp = "append_activity"
+	NextMessageSeqOp          MutationOp = "next_message_seq"
+	SetMessageSequenceOp      MutationOp = "set_message_sequence"
+	SetChannelTagsOp          MutationOp = "set_channel_tags"
+	SetChannelMemoOp          MutationOp = "set_channel_memo"
+	SetConfigValueOp          MutationOp = "set_config_value"
+	SetPendingTxOp            MutationOp = "set_pending_transaction"
+	RemovePendingTxOp         MutationOp = "remove_pending_transaction"
+	SetBlockedPeerOp          MutationOp = "set_blocked_peer"
+	RemoveBlockedPeerOp       MutationOp = "remove_blocked_peer"
+)
+
+// Mutation is a change made to a store, which is replicated to a standby by calling the same Store method on its store.
+// Mutations are numbered consecutively, from 1, within the stream of mutations made by a store since it was opened.
+type Mutation struct {
+	Seq  uint64
+	Op   MutationOp
+	Data json.RawMessage
+}
+
+// Replicator sends mutations to a standby.
+type Replicator interface {
+	// Replicate sends the mutations, which are in order, from the stream. It returns the number of the last mutation
+	// from the stream which the standby has applied.
+	Replicate(ctx context.Context, stream uint64, mutations []Mutation) (applied uint64, err error)
+}
+
+// ReplicationMode sets when a store mutation replicated to a standby returns.
+type ReplicationMode string
+
+const (
+	// SyncReplication returns once the standby has acknowledged the mutation, so that a failover loses no mutation
+	// the node has acted on. If the standby does not acknowledge it within the replication timeout, the mutation fails
+	// with ErrUnreplicatedMutation: it has been made to the store, and is still replicated, but the caller must not act
+	// on it.
+	SyncReplication ReplicationMode = "sync"
+	// SyncWithFallbackReplication returns once the standby has acknowledged the mutation, as SyncReplication does,
+	// while the standby keeps up. If the standby does not acknowledge it within the replication timeout, the mutation
+	// returns without an error, and mutations are replicated asynchronously until the standby catches up: a failover
+	// meanwhile may lose the latest mutations, as with AsyncReplication.
+	SyncWithFallbackReplication ReplicationMode = "sync-fallback"
+	// AsyncReplication returns at once, and replicates the mutation in the background, so that a failover may lose
+	// the latest mutations.
+	AsyncReplication ReplicationMode = "async"
+)
+
+// ReplicationOpts configures the replication of a store's mutations to a standby.
+type ReplicationOpts struct {
+	Replicator Replicator
+	Mode       ReplicationMode
+	// Timeout bounds how long a synchronous mutation waits for the standby's acknowledgement. It defaults to DEFAULT_REPLICATION_TIMEOUT.
+	Timeout time.Duration
+}
+
+const (
+	DEFAULT_REPLICATION_TIMEOUT = 5 * time.Second
+	// ErrUnreplicatedMutation is returned by a synchronous mutation which the standby did not acknowledge in time.
+	ErrUnreplicatedMutation = types.ConstError("the standby did not acknowledge the mutation")
+	// replicationBatchSize bounds the number of mutations sent to the standby at once
+	replicationBatchSize = 256
+	// replicationRetryInterval is how long to wait before resending mutations the standby did not acknowledge
+	replicationRetryInterval = time.Second
+)
+
+// ReplicatingStore is a Store which replicates its mutations to a standby, whatever the backend of either store.
+type ReplicatingStore struct {
+	Store
+	opts   ReplicationOpts
+	logger *slog.Logger
+
+	// writeMu serializes mutations, so that they are applied to the standby in the order they were applied to Store
+	writeMu sync.Mutex
+
+	stream  uint64 // identifies the mutations made since the store was opened
+	mu      sync.Mutex
+	acked   *sync.Cond
+	pending []Mutation // mutations the standby has not yet acknowledged, in order
+	nextSeq uint64
+	applied uint64 // the number of the last mutation the standby has acknowledged
+	// lagging is set while the standby is unreachable or behind, when SyncWithFallbackReplication mutations do not wait
+	// for it
+	lagging bool
+	closed  bool
+
+	wake chan struct{}
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewReplicatingStore returns a store which makes its mutations to s, and replicates them according to opts.
+func NewReplicatingStore(s Store, opts ReplicationOpts) (*ReplicatingStore, error) {
+	if opts.Replicator == nil {
+		return nil, errors.New("replication requires a replicator")
+	}
+	switch opts.Mode {
+	case "":
+		opts.Mode = SyncReplication
+	case SyncReplication, SyncWithFallbackReplication, AsyncReplication:
+	default:
+		return nil, fmt.Errorf("unknown replication mode %q", opts.Mode)
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DEFAULT_REPLICATION_TIMEOUT
+	}
+
+	rs := &ReplicatingStore{
+		Store:   s,
+		opts:    opts,
+		logger:  logging.LoggerWithAddress(logging.ModuleLogger(logging.CLUSTER_MODULE), *s.GetAddress()),
+		stream:  newEpoch(),
+		nextSeq: 1,
+		wake:    make(chan struct{}, 1),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	rs.acked = sync.NewCond(&rs.mu)
+	go rs.send()
+	return rs, nil
+}
+
+// mutate applies a mutation with apply, then replicates it.
+func (rs *ReplicatingStore) mutate(op MutationOp, data any, apply func() error) error {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("could not encode %s mutation: %w", op, err)
+	}
+
+	rs.writeMu.Lock()
+	if err := apply(); err != nil {
+		rs.writeMu.Unlock()
+		return err
+	}
+	rs.mu.Lock()
+	seq := rs.nextSeq
+	rs.nextSeq++
+	rs.pending = append(rs.pending, Mutation{Seq: seq, Op: op, Data: encoded})
+	rs.mu.Unlock()
+	rs.writeMu.Unlock()
+
+	select {
+	case rs.wake <- struct{}{}:
+	default:
+	}
+
+	switch rs.opts.Mode {
+	case SyncReplication:
+		if !rs.awaitAck(seq, false) {
+			return fmt.Errorf("%w: %s mutation %d", ErrUnreplicatedMutation, op, seq)
+		}
+	case SyncWithFallbackReplication:
+		rs.awaitAck(seq, true)
+	}
+	return nil
+}
+
+// awaitAck waits until the standby acknowledges the mutation numbered seq, or the replication timeout passes, and
+// reports whether it was acknowledged. With fallback, it does not wait while the standby is lagging, and a timeout
+// marks the standby as lagging.
+func (rs *ReplicatingStore) awaitAck(seq uint64, fallback bool) bool {
+	timedOut := false
+	timer := time.AfterFunc(rs.opts.Timeout, func() {
+		rs.mu.Lock()
+		timedOut = true
+		rs.mu.Unlock()
+		rs.acked.Broadcast()
+	})
+	defer timer.Stop()
+
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for rs.applied < seq && !timedOut && !rs.closed && !(fallback && rs.lagging) {
+		rs.acked.Wait()
+	}
+	if rs.applied >= seq {
+		return true
+	}
+	if !fallback {
+		rs.logger.Warn("Standby did not acknowledge a mutation in time", "seq", seq)
+	} else if !rs.lagging {
+		rs.logger.Warn("Standby did not acknowledge a mutation in time: replicating asynchronously until it catches up", "seq", seq)
+		rs.lagging = true
+	}
+	return false
+}
+
+// send sends pending mutations to the standby until the store is closed.
+func (rs *ReplicatingStore) send() {
+	defer close(rs.done)
+	for {
+		rs.mu.Lock()
+		batch := rs.pending[:min(len(rs.pending), replicationBatchSize)]
+		rs.mu.Unlock()
+
+		if len(batch) == 0 {
+			select {
+			case <-rs.wake:
+				continue
+			case <-rs.quit:
+				return
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), rs.opts.Timeout)
+		applied, err := rs.opts.Replicator.Replicate(ctx, rs.stream, batch)
+		cancel()
+		if err != nil {
+			rs.logger.Warn("Could not replicate mutations to the standby", "from", batch[0].Seq, "error", err)
+			rs.mu.Lock()
+			rs.lagging = true
+			rs.mu.Unlock()
+			rs.acked.Broadcast()
+			select {
+			case <-time.After(replicationRetryInterval):
+				continue
+			case <-rs.quit:
+				return
+			}
+		}
+
+		rs.mu.Lock()
+		progressed := applied > rs.applied
+		if progressed {
+			rs.applied = applied
+		}
+		// Drop the acknowledged mutations
+		for len(rs.pending) > 0 && rs.pending[0].Seq <= rs.applied {
+			rs.pending = rs.pending[1:]
+		}
+		if rs.lagging && len(rs.pending) == 0 {
+			rs.logger.Info("Standby has caught up")
+			rs.lagging = false
+		}
+		rs.mu.Unlock()
+		rs.acked.Broadcast()
+
+		if !progressed {
+			// The standby applied none of the batch, so wait before resending it
+			select {
+			case <-time.After(replicationRetryInterval):
+			case <-rs.quit:
+				return
+			}
+		}
+	}
+}
+
+// Close waits up to the replication timeout for pending mutations to be replicated, then closes the store.
+func (rs *ReplicatingStore) Close() error {
+	deadline := time.Now().Add(rs.opts.Timeout)
+	for {
+		rs.mu.Lock()
+		remaining := len(rs.pending)
+		rs.mu.Unlock()
+		if remaining == 0 || time.Now().After(deadline) {
+			if remaining > 0 {
+				rs.logger.Warn("Closing with mutations the standby has not acknowledged", "mutations", remaining)
+			}
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	close(rs.quit)
+	<-rs.done
+	rs.mu.Lock()
+	rs.closed = true
+	rs.mu.Unlock()
+	rs.acked.Broadcast()
+	return rs.Store.Close()
+}
+
+// objectiveMutation carries an objective, with the channels it relates to, since the objective's own encoding only
+// identifies them.
+type objectiveMutation struct {
+	Id                protocols.ObjectiveId
+	Objective         json.RawMessage
+	Channels          []*channel.Channel
+	ConsensusChannels []*consensus_channel.ConsensusChannel
+}
+
+func (rs *ReplicatingStore) SetObjective(obj protocols.Objective) error {
+	objJSON, err := obj.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	m := objectiveMutation{Id: obj.Id(), Objective: objJSON}
+	for _, rel := range obj.Related() {
+		switch ch := rel.(type) {
+		case *channel.VirtualChannel:
+			m.Channels = append(m.Channels, &ch.Channel)
+		case *channel.Channel:
+			m.Channels = append(m.Channels, ch)
+		case *consensus_channel.ConsensusChannel:
+			m.ConsensusChannels = append(m.ConsensusChannels, ch)
+		}
+	}
+	return rs.mutate(SetObjectiveOp, m, func() error { return rs.Store.SetObjective(obj) })
+}
+
+func (rs *ReplicatingStore) SetChannel(ch *channel.Channel) error {
+	return rs.mutate(SetChannelOp, ch, func() error { return rs.Store.SetChannel(ch) })
+}
+
+func (rs *ReplicatingStore) DestroyChannel(id types.Destination) error {
+	return rs.mutate(DestroyChannelOp, id, func() error { return rs.Store.DestroyChannel(id) })
+}
+
+func (rs *ReplicatingStore) ReleaseChannelFromOwnership(id types.Destination) error {
+	return rs.mutate(ReleaseChannelOp, id, func() error { return rs.Store.ReleaseChannelFromOwnership(id) })
+}
+
+func (rs *ReplicatingStore) SetConsensusChannel(ch *consensus_channel.ConsensusChannel) error {
+	return rs.mutate(SetConsensusChannelOp, ch, func() error { return rs.Store.SetConsensusChannel(ch) })
+}
+
+func (rs *ReplicatingStore) DestroyConsensusChannel(id types.Destination) error {
+	return rs.mutate(DestroyConsensusChannelOp, id, func() error { return rs.Store.DestroyConsensusChannel(id) })
+}
+
+func (rs *ReplicatingStore) SetLastBlockNumSeen(blockNum uint64) error {
+	return rs.mutate(SetLastBlockNumSeenOp, blockNum, func() error { return rs.Store.SetLastBlockNumSeen(blockNum) })
+}
+
+func (rs *ReplicatingStore) CollectObjective(summary ObjectiveSummary) error {
+	return rs.mutate(CollectObjectiveOp, summary, func() error { return rs.Store.CollectObjective(summary) })
+}
+
+type voucherInfoMutation struct {
+	ChannelId types.Destination
+	Info      payments.VoucherInfo
+}
+
+func (rs *ReplicatingStore) SetVoucherInfo(channelId types.Destination, v payments.VoucherInfo) error {
+	return rs.mutate(SetVoucherInfoOp, voucherInfoMutation{channelId, v}, func() error { return rs.Store.SetVoucherInfo(channelId, v) })
+}
+
+func (rs *ReplicatingStore) RemoveVoucherInfo(channelId types.Destination) error {
+	return rs.mutate(RemoveVoucherInfoOp, channelId, func() error { return rs.Store.RemoveVoucherInfo(channelId) })
+}
+
+type paymentRecordMutation struct {
+	ChannelId types.Destination
+	PaymentId string
+	Record    payments.PaymentRecord
+}
+
+func (rs *ReplicatingStore) SetPaymentRecord(channelId types.Destination, paymentId string, r payments.PaymentRecord) error {
+	return rs.mutate(SetPaymentRecordOp, paymentRecordMutation{channelId, paymentId, r}, func() error { return rs.Store.SetPaymentRecord(channelId, paymentId, r) })
+}
+
+func (rs *ReplicatingStore) SetBalanceSnapshot(bs BalanceSnapshot) error {
+	return rs.mutate(SetBalanceSnapshotOp, bs, func() error { return rs.Store.SetBalanceSnapshot(bs) })
+}
+
+func (rs *ReplicatingStore) AppendActivity(r ActivityRecord) error {
+	return rs.mutate(AppendActivityOp, r, func() error { return rs.Store.AppendActivity(r) })
+}
+
+// NextMessageSeq numbers the next message to peer, replicating the numbering so that the standby does not reuse it.
+func (rs *ReplicatingStore) NextMessageSeq(peer types.Address) (epoch uint64, seq uint64, err error) {
+	err = rs.mutate(NextMessageSeqOp, peer, func() error {
+		epoch, seq, err = rs.Store.NextMessageSeq(peer)
+		return err
+	})
+	return epoch, seq, err
+}
+
+type messageSequenceMutation struct {
+	Peer     types.Address
+	Sequence MessageSequence
+}
+
+func (rs *ReplicatingStore) SetMessageSequence(peer types.Address, ms MessageSequence) error {
+	return rs.mutate(SetMessageSequenceOp, messageSequenceMutation{peer, ms}, func() error { return rs.Store.SetMessageSequence(peer, ms) })
+}
+
+type channelTagsMutation struct {
+	ChannelId types.Destination
+	Tags      map[string]string
+}
+
+func (rs *ReplicatingStore) SetChannelTags(id types.Destination, tags map[string]string) error {
+	return rs.mutate(SetChannelTagsOp, channelTagsMutation{id, tags}, func() error { return rs.Store.SetChannelTags(id, tags) })
+}
+
+type channelMemoMutation struct {
+	ChannelId types.Destination
+	Memo      string
+}
+
+func (rs *ReplicatingStore) SetChannelMemo(id types.Destination, memo string) error {
+	return rs.mutate(SetChannelMemoOp, channelMemoMutation{id, memo}, func() error { return rs.Store.SetChannelMemo(id, memo) })
+}
+
+type configValueMutation struct {
+	Key   string
+	Value string
+}
+
+func (rs *ReplicatingStore) SetConfigValue(key, value string) error {
+	return rs.mutate(SetConfigValueOp, configValueMutation{key, value}, func() error { return rs.Store.SetConfigValue(key, value) })
+}
+
+func (rs *ReplicatingStore) SetPendingTransaction(pt PendingTransaction) error {
+	return rs.mutate(SetPendingTxOp, pt, func() error { return rs.Store.SetPendingTransaction(pt) })
+}
+
+func (rs *ReplicatingStore) RemovePendingTransaction(channelId types.Destination) error {
+	return rs.mutate(RemovePendingTxOp, channelId, func() error { return rs.Store.RemovePendingTransaction(channelId) })
+}
+
+func (rs *ReplicatingStore) SetBlockedPeer(bp BlockedPeer) error {
+	return rs.mutate(SetBlockedPeerOp, bp, func() error { return rs.Store.SetBlockedPeer(bp) })
+}
+
+func (rs *ReplicatingStore) RemoveBlockedPeer(key string) error {
+	return rs.mutate(RemoveBlockedPeerOp, key, func() error { return rs.Store.RemoveBlockedPeer(key) })
+}
+
+const (
+	// ErrReplicaOutOfSync is returned by a Replica given a mutation which does not follow the last one it applied.
+	ErrReplicaOutOfSync = types.ConstError("replica is out of sync with the active store")
+	// ErrStaleStream is returned by a Replica given mutations from a stream older than the one it follows.
+	ErrStaleStream = types.ConstError("replica follows a newer stream of mutations")
+)
+
+// Replica applies the mutations replicated from an active store to a standby's store.
+type Replica struct {
+	store Store
+
+	mu      sync.Mutex
+	stream  uint64
+	applied uint64
+}
+
+// NewReplica returns a Replica which applies mutations to s. The standby's store should begin as a copy of the active
+// store (or both should begin empty).
+func NewReplica(s Store) *Replica {
+	return &Replica{store: s}
+}
+
+// Applied returns the stream the replica is following, and the number of the last mutation applied from it.
+func (r *Replica) Applied() (stream uint64, applied uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stream, r.applied
+}
+
+// Apply applies the mutations, which are in order, from the stream, returning the number of the last mutation
+// applied. Mutations which have already been applied are skipped. A new stream, from an active store which has been
+// reopened, is followed from its first mutation, but an older stream is refused, so that mutations replayed from it
+// cannot undo later ones.
+func (r *Replica) Apply(stream uint64, mutations []Mutation) (uint64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stream < r.stream {
+		return 0, fmt.Errorf("%w: stream %d is older than stream %d", ErrStaleStream, stream, r.stream)
+	}
+	if stream != r.stream {
+		r.stream, r.applied = stream, 0
+	}
+	for _, m := range mutations {
+		if m.Seq <= r.applied {
+			continue
+		}
+		if m.Seq != r.applied+1 {
+			return r.applied, fmt.Errorf("%w: expected mutation %d, received %d", ErrReplicaOutOfSync, r.applied+1, m.Seq)
+		}
+		if err := r.apply(m); err != nil {
+			return r.applied, fmt.Errorf("could not apply %s mutation %d: %w", m.Op, m.Seq, err)
+		}
+		r.applied = m.Seq
+	}
+	return r.applied, nil
+}
+
+func (r *Replica) apply(m Mutation) error {
+	s := r.store
+	switch m.Op {
+	case SetObjectiveOp:
+		om := objectiveMutation{}
+		if err := json.Unmarshal(m.Data, &om); err != nil {
+			return err
+		}
+		for _, ch := range om.Channels {
+			if err := s.SetChannel(ch); err != nil {
+				return err
+			}
+		}
+		for _, ch := range om.ConsensusChannels {
+			if err := s.SetConsensusChannel(ch); err != nil {
+				return err
+			}
+		}
+		obj, err := decodeObjective(om.Id, om.Objective)
+		if err != nil {
+			return err
+		}
+		if err := populateFromStore(s, obj); err != nil {
+			return err
+		}
+		return s.SetObjective(obj)
+	case SetChannelOp:
+		ch := &channel.Channel{}
+		if err := json.Unmarshal(m.Data, ch); err != nil {
+			return err
+		}
+		return s.SetChannel(ch)
+	case DestroyChannelOp:
+		return applyWithId(m, s.DestroyChannel)
+	case ReleaseChannelOp:
+		return applyWithId(m, s.ReleaseChannelFromOwnership)
+	case SetConsensusChannelOp:
+		ch := &consensus_channel.ConsensusChannel{}
+		if err := json.Unmarshal(m.Data, ch); err != nil {
+			return err
+		}
+		return s.SetConsensusChannel(ch)
+	case DestroyConsensusChannelOp:
+		return applyWithId(m, s.DestroyConsensusChannel)
+	case SetLastBlockNumSeenOp:
+		var blockNum uint64
+		if err := json.Unmarshal(m.Data, &blockNum); err != nil {
+			return err
+		}
+		return s.SetLastBlockNumSeen(blockNum)
+	case CollectObjectiveOp:
+		summary := ObjectiveSummary{}
+		if err := json.Unmarshal(m.Data, &summary); err != nil {
+			return err
+		}
+		return s.CollectObjective(summary)
+	case SetVoucherInfoOp:
+		vm := voucherInfoMutation{}
+		if err := json.Unmarshal(m.Data, &vm); err != nil {
+			return err
+		}
+		return s.SetVoucherInfo(vm.ChannelId, vm.Info)
+	case RemoveVoucherInfoOp:
+		return applyWithId(m, s.RemoveVoucherInfo)
+	case SetPaymentRecordOp:
+		pm := paymentRecordMutation{}
+		if err := json.Unmarshal(m.Data, &pm); err != nil {
+			return err
+		}
+		return s.SetPaymentRecord(pm.ChannelId, pm.PaymentId, pm.Record)
+	case SetBalanceSnapshotOp:
+		bs := BalanceSnapshot{}
+		if err := json.Unmarshal(m.Data, &bs); err != nil {
+			return err
+		}
+		return s.SetBalanceSnapshot(bs)
+	case AppendActivityOp:
+		ar := ActivityRecord{}
+		if err := json.Unmarshal(m.Data, &ar); err != nil {
+			return err
+		}
+		return s.AppendActivity(ar)
+	case NextMessageSeqOp:
+		var peer types.Address
+		if err := json.Unmarshal(m.Data, &peer); err != nil {
+			return err
+		}
+		_, _, err := s.NextMessageSeq(peer)
+		return err
+	case SetMessageSequenceOp:
+		mm := messageSequenceMutation{}
+		if err := json.Unmarshal(m.Data, &mm); err != nil {
+			return err
+		}
+		return s.SetMessageSequence(mm.Peer, mm.Sequence)
+	case SetChannelTagsOp:
+		tm := channelTagsMutation{}
+		if err := json.Unmarshal(m.Data, &tm); err != nil {
+			return err
+		}
+		return s.SetChannelTags(tm.ChannelId, tm.Tags)
+	case SetChannelMemoOp:
+		mm := channelMemoMutation{}
+		if err := json.Unmarshal(m.Data, &mm); err != nil {
+			return err
+		}
+		return s.SetChannelMemo(mm.ChannelId, mm.Memo)
+	case SetConfigValueOp:
+		cm := configValueMutation{}
+		if err := json.Unmarshal(m.Data, &cm); err != nil {
+			return err
+		}
+		return s.SetConfigValue(cm.Key, cm.Value)
+	case SetPendingTxOp:
+		pt := PendingTransaction{}
+		if err := json.Unmarshal(m.Data, &pt); err != nil {
+			return err
+		}
+		return s.SetPendingTransaction(pt)
+	case RemovePendingTxOp:
+		return applyWithId(m, s.RemovePendingTransaction)
+	case SetBlockedPeerOp:
+		bp := BlockedPeer{}
+		if err := json.Unmarshal(m.Data, &bp); err != nil {
+			return err
+		}
+		return s.SetBlockedPeer(bp)
+	case RemoveBlockedPeerOp:
+		var key string
+		if err := json.Unmarshal(m.Data, &key); err != nil {
+			return err
+		}
+		return s.RemoveBlockedPeer(key)
+	default:
+		return fmt.Errorf("unknown mutation %q", m.Op)
+	}
+}
+
+// applyWithId applies a mutation whose only argument is a channel id.
+func applyWithId(m Mutation, apply func(types.Destination) error) error {
+	var id types.Destination
+	if err := json.Unmarshal(m.Data, &id); err != nil {
+		return err
+	}
+	return apply(id)
+}
+
+// populateFromStore attaches the channels held by s to the decoded objective, in place of the channel pointers which
+// only identify them.
+func populateFromStore(s Store, obj protocols.Objective) error {
+	getChannel := func(id types.Destination) (*channel.Channel, error) {
+		ch, ok := s.GetChannelById(id)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrNoSuchChannel, id)
+		}
+		return ch, nil
+	}
+
+	switch o := obj.(type) {
+	case *directfund.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return err
+		}
+		o.C = ch
+	case *directdefund.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return err
+		}
+		o.C = ch
+	case *challenge.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return err
+		}
+		o.C = ch
+	case *ledgertopup.Objective:
+		ch, err := getChannel(o.C.Id)
+		if err != nil {
+			return err
+		}
+		o.C = ch
+	case *virtualfund.Objective:
+		v, err := getChannel(o.V.Id)
+		if err != nil {
+			return err
+		}
+		o.V = &channel.VirtualChannel{Channel: *v}
+		for _, ledger := range []*virtualfund.Connection{o.ToMyLeft, o.ToMyRight} {
+			if ledger == nil || ledger.Channel == nil || ledger.Channel.Id.IsZero() {
+				continue
+			}
+			ch, err := s.GetConsensusChannelById(ledger.Channel.Id)
+			if err != nil {
+				return err
+			}
+			ledger.Channel = ch
+		}
+	case *virtualdefund.Objective:
+		v, err := getChannel(o.V.Id)
+		if err != nil {
+			return err
+		}
+		o.V = &channel.VirtualChannel{Channel: *v}
+		if o.ToMyLeft != nil && !o.ToMyLeft.Id.IsZero() {
+			if o.ToMyLeft, err = s.GetConsensusChannelById(o.ToMyLeft.Id); err != nil {
+				return err
+			}
+		}
+		if o.ToMyRight != nil && !o.ToMyRight.Id.IsZero() {
+			if o.ToMyRight, err = s.GetConsensusChannelById(o.ToMyRight.Id); err != nil {
+				return err
+			}
+		}
+	default:
+		if _, ok := protocols.LookupObjectiveType(obj.Id()); !ok {
+			return fmt.Errorf("objective %s is not of a known type", obj.Id())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// MaxReplicationRequestBytes bounds the body of a request to a ReplicationHandler.
+const MaxReplicationRequestBytes = 64 << 20
+
+// replicationSalt salts the derivation of the key which seals replication requests and responses from the secret
+// shared by the members of a cluster.
+var replicationSalt = []byte("go-nitro/replication")
+
+// replicationRequest is the body of a request to replicate mutations to a standby over http.
+type replicationRequest struct {
+	Stream    uint64
+	Mutations []Mutation
+}
+
+// replicationResponse acknowledges the mutations a standby has applied.
+type replicationResponse struct {
+	Applied uint64
+	Error   string `json:",omitempty"`
+}
+
+// newReplicationCipher returns the cipher which seals replication requests and responses, with a key derived from the
+// secret shared by the members of a cluster. Sealing both authenticates them, so that only a member may replicate
+// mutations to a standby, and keeps the records they carry confidential.
+func newReplicationCipher(secret string) (cipher.AEAD, error) {
+	if secret == "" {
+		return nil, errors.New("replication requires a secret shared by the members of the cluster")
+	}
+	return newStoreCipher(PassphraseKey(secret), replicationSalt)
+}
+
+// Headers of a request to a ReplicationHandler, naming the stream it is from and the number of its first mutation (or
+// zero, if it has none). They are authenticated by sealing the request under replicationRequestName.
+const (
+	replicationStreamHeader = "X-Replication-Stream"
+	replicationSeqHeader    = "X-Replication-Seq"
+)
+
+// replicationRequestName authenticates a request as one from the stream, carrying mutations from seq, so that a sealed
+// request cannot be replayed as one from another stream or position in it.
+func replicationRequestName(stream, seq uint64) string {
+	return "replication/request/" + strconv.FormatUint(stream, 10) + "/" + strconv.FormatUint(seq, 10)
+}
+
+// replicationResponseName authenticates a response as one to a request from the stream, carrying mutations from seq.
+func replicationResponseName(stream, seq uint64) string {
+	return "replication/response/" + strconv.FormatUint(stream, 10) + "/" + strconv.FormatUint(seq, 10)
+}
+
+// firstSeq returns the number of the first of the mutations, or zero if there are none.
+func firstSeq(mutations []Mutation) uint64 {
+	if len(mutations) == 0 {
+		return 0
+	}
+	return mutations[0].Seq
+}
+
+// HTTPReplicator is a Replicator which posts mutations to a standby serving a ReplicationHandler.
+type HTTPReplicator struct {
+	url    string
+	client *http.Client
+	aead   cipher.AEAD
+}
+
+// NewHTTPReplicator returns a Replicator which posts mutations to the url, sealed with the secret shared by the members
+// of the cluster.
+func NewHTTPReplicator(url string, secret string) (*HTTPReplicator, error) {
+	aead, err := newReplicationCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPReplicator{url: url, client: &http.Client{}, aead: aead}, nil
+}
+
+func (hr *HTTPReplicator) Replicate(ctx context.Context, stream uint64, mutations []Mutation) (uint64, error) {
+	body, err := json.Marshal(replicationRequest{Stream: stream, Mutations: mutations})
+	if err != nil {
+		return 0, err
+	}
+	seq := firstSeq(mutations)
+	sealed, err := sealWith(hr.aead, replicationRequestName(stream, seq), string(body))
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hr.url, bytes.NewReader([]byte(sealed)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set(replicationStreamHeader, strconv.FormatUint(stream, 10))
+	req.Header.Set(replicationSeqHeader, strconv.FormatUint(seq, 10))
+	resp, err := hr.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, MaxReplicationRequestBytes))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected response from standby (%s): %s", resp.Status, bytes.TrimSpace(respBody))
+	}
+	opened, err := openWith(hr.aead, replicationResponseName(stream, seq), string(respBody))
+	if err != nil {
+		return 0, fmt.Errorf("unauthenticated response from standby: %w", err)
+	}
+	ack := replicationResponse{}
+	if err := json.Unmarshal([]byte(opened), &ack); err != nil {
+		return 0, fmt.Errorf("unexpected response from standby (%s): %w", resp.Status, err)
+	}
+	if ack.Error != "" {
+		return ack.Applied, errors.New(ack.Error)
+	}
+	return ack.Applied, nil
+}
+
+// NewReplicationHandler returns an http.Handler through which a standby receives mutations, and applies them to replica.
+// Only requests sealed with the secret shared by the members of the cluster are accepted.
+func NewReplicationHandler(replica *Replica, secret string) (http.Handler, error) {
+	aead, err := newReplicationCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, MaxReplicationRequestBytes))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		stream, err := strconv.ParseUint(r.Header.Get(replicationStreamHeader), 10, 64)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		seq, err := strconv.ParseUint(r.Header.Get(replicationSeqHeader), 10, 64)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		opened, err := openWith(aead, replicationRequestName(stream, seq), string(body))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		req := replicationRequest{}
+		if err := json.Unmarshal([]byte(opened), &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.Stream != stream || firstSeq(req.Mutations) != seq {
+			http.Error(w, "the request does not match its headers", http.StatusBadRequest)
+			return
+		}
+
+		resp := replicationResponse{}
+		applied, err := replica.Apply(req.Stream, req.Mutations)
+		resp.Applied = applied
+		if err != nil {
+			resp.Error = err.Error()
+		}
+
+		encoded, err := json.Marshal(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sealed, err := sealWith(aead, replicationResponseName(stream, seq), string(encoded))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = w.Write([]byte(sealed))
+	}), nil
+}
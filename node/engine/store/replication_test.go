@@ -0,0 +1,282 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	td "github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const replicationSecret = "shared by the members of the cluster"
+
+// failingReplicator is a Replicator for an unreachable standby.
+type failingReplicator struct{}
+
+func (failingReplicator) Replicate(ctx context.Context, stream uint64, mutations []store.Mutation) (uint64, error) {
+	return 0, context.DeadlineExceeded
+}
+
+// silentReplicator is a Replicator for a standby which is reachable, but never applies a mutation.
+type silentReplicator struct{}
+
+func (silentReplicator) Replicate(ctx context.Context, stream uint64, mutations []store.Mutation) (uint64, error) {
+	return 0, nil
+}
+
+func TestReplication(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	standby := store.NewMemStore(sk)
+	replica := store.NewReplica(standby)
+	handler, err := store.NewReplicationHandler(replica, replicationSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	replicator, err := store.NewHTTPReplicator(server.URL, replicationSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	active, err := store.NewReplicatingStore(store.NewMemStore(sk), store.ReplicationOpts{
+		Replicator: replicator,
+		Mode:       store.SyncReplication,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dfo := td.Objectives.Directfund.GenericDFO()
+	vfo := td.Objectives.Virtualfund.GenericVFO()
+	for _, o := range []protocols.Objective{&dfo, &vfo} {
+		if err := active.SetObjective(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	channelId := types.Destination{1}
+	voucherInfo := payments.VoucherInfo{ChannelPayer: ta.Alice.Address(), ChannelPayee: ta.Bob.Address(), StartingBalance: big.NewInt(10), LargestVoucher: payments.Voucher{ChannelId: channelId, Amount: big.NewInt(3)}}
+	if err := active.SetVoucherInfo(channelId, voucherInfo); err != nil {
+		t.Fatal(err)
+	}
+	if err := active.SetChannelTags(channelId, map[string]string{"order": "42"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := active.SetLastBlockNumSeen(7); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := active.NextMessageSeq(ta.Bob.Address()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Synchronous mutations have been applied to the standby by the time they return
+	for _, want := range []protocols.Objective{&dfo, &vfo} {
+		got, err := standby.GetObjectiveById(want.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := compareObjectives(got, want); diff != "" {
+			t.Errorf("expected the replicated objective to match, but found:\n%s", diff)
+		}
+	}
+	gotVoucherInfo, err := standby.GetVoucherInfo(channelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotVoucherInfo.LargestVoucher.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected the replicated voucher amount to be 3, got %s", gotVoucherInfo.LargestVoucher.Amount)
+	}
+	if tags, _ := standby.GetChannelTags(channelId); tags["order"] != "42" {
+		t.Errorf("expected the replicated tags, got %v", tags)
+	}
+	if blockNum, _ := standby.GetLastBlockNumSeen(); blockNum != 7 {
+		t.Errorf("expected the replicated block number to be 7, got %d", blockNum)
+	}
+	// The standby does not reuse the message numbers of the active store
+	if _, seq, _ := standby.NextMessageSeq(ta.Bob.Address()); seq != 2 {
+		t.Errorf("expected the standby's next message to bob to be numbered 2, got %d", seq)
+	}
+	if err := active.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A reopened active store starts a new stream, which the replica follows
+	reopened, err := store.NewReplicatingStore(store.NewMemStore(sk), store.ReplicationOpts{
+		Replicator: replicator,
+		Mode:       store.SyncReplication,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if err := reopened.RemoveVoucherInfo(channelId); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := standby.GetVoucherInfo(channelId); err == nil {
+		t.Error("expected the voucher info to have been removed from the standby")
+	}
+	if _, applied := replica.Applied(); applied != 1 {
+		t.Errorf("expected the replica to have applied one mutation from the new stream, got %d", applied)
+	}
+}
+
+func TestSyncReplicationFailsWithoutAck(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	active, err := store.NewReplicatingStore(store.NewMemStore(sk), store.ReplicationOpts{
+		Replicator: silentReplicator{},
+		Mode:       store.SyncReplication,
+		Timeout:    50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer active.Close()
+
+	// Each mutation the standby does not acknowledge fails, however many have failed before
+	for i := uint64(1); i <= 2; i++ {
+		if err := active.SetLastBlockNumSeen(i); !errors.Is(err, store.ErrUnreplicatedMutation) {
+			t.Errorf("expected an unacknowledged mutation to fail with %v, got %v", store.ErrUnreplicatedMutation, err)
+		}
+	}
+
+	unreachable, err := store.NewReplicatingStore(store.NewMemStore(sk), store.ReplicationOpts{
+		Replicator: failingReplicator{},
+		Mode:       store.SyncReplication,
+		Timeout:    50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unreachable.Close()
+	if err := unreachable.SetLastBlockNumSeen(1); !errors.Is(err, store.ErrUnreplicatedMutation) {
+		t.Errorf("expected a mutation replicated to an unreachable standby to fail with %v, got %v", store.ErrUnreplicatedMutation, err)
+	}
+}
+
+func TestSyncWithFallbackReplicationToUnreachableStandby(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	active, err := store.NewReplicatingStore(store.NewMemStore(sk), store.ReplicationOpts{
+		Replicator: failingReplicator{},
+		Mode:       store.SyncWithFallbackReplication,
+		Timeout:    50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer active.Close()
+
+	// Mutations succeed once the standby is found to be unreachable, without waiting for each to time out
+	start := time.Now()
+	for i := uint64(1); i <= 10; i++ {
+		if err := active.SetLastBlockNumSeen(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected mutations not to wait for an unreachable standby, took %s", elapsed)
+	}
+	if blockNum, _ := active.GetLastBlockNumSeen(); blockNum != 10 {
+		t.Errorf("expected the block number to be 10, got %d", blockNum)
+	}
+}
+
+func TestReplicationRequiresSecret(t *testing.T) {
+	sk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+	standby := store.NewMemStore(sk)
+	replica := store.NewReplica(standby)
+	handler, err := store.NewReplicationHandler(replica, replicationSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	mutation := store.Mutation{Seq: 1, Op: store.SetLastBlockNumSeenOp, Data: []byte("7")}
+
+	// Mutations posted in the clear, or sealed with another secret, are refused
+	resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"Stream":1,"Mutations":[{"Seq":1,"Op":"set_last_block_num_seen","Data":7}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unsealed mutations to be refused, got %s", resp.Status)
+	}
+	impostor, err := store.NewHTTPReplicator(server.URL, "guessed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := impostor.Replicate(context.Background(), 1, []store.Mutation{mutation}); err == nil {
+		t.Error("expected mutations sealed with another secret to be refused")
+	}
+	if blockNum, _ := standby.GetLastBlockNumSeen(); blockNum != 0 {
+		t.Errorf("expected no mutation to be applied, got block number %d", blockNum)
+	}
+
+	// Mutations replayed from an older stream cannot undo those of the current stream
+	replicator, err := store.NewHTTPReplicator(server.URL, replicationSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replicator.Replicate(context.Background(), 2, []store.Mutation{mutation}); err != nil {
+		t.Fatal(err)
+	}
+	stale := store.Mutation{Seq: 1, Op: store.SetLastBlockNumSeenOp, Data: []byte("3")}
+	if _, err := replicator.Replicate(context.Background(), 1, []store.Mutation{stale}); err == nil {
+		t.Error("expected mutations from an older stream to be refused")
+	}
+	if blockNum, _ := standby.GetLastBlockNumSeen(); blockNum != 7 {
+		t.Errorf("expected the block number to be 7, got %d", blockNum)
+	}
+
+	// A sealed request cannot be replayed as one from another stream
+	var captured []byte
+	var capturedHeader http.Header
+	recorder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		captured, _ = io.ReadAll(r.Body)
+		capturedHeader = r.Header.Clone()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer recorder.Close()
+	eavesdropped, err := store.NewHTTPReplicator(recorder.URL, replicationSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rollback := store.Mutation{Seq: 1, Op: store.SetLastBlockNumSeenOp, Data: []byte("5")}
+	_, _ = eavesdropped.Replicate(context.Background(), 2, []store.Mutation{rollback})
+	replayed, err := http.NewRequest(http.MethodPost, server.URL, bytes.NewReader(captured))
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed.Header = capturedHeader
+	replayed.Header.Set("X-Replication-Stream", "3")
+	resp, err = http.DefaultClient.Do(replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected a request replayed as from another stream to be refused, got %s", resp.Status)
+	}
+	if blockNum, _ := standby.GetLastBlockNumSeen(); blockNum != 7 {
+		t.Errorf("expected the block number to be 7, got %d", blockNum)
+	}
+
+	if _, err := store.NewReplicationHandler(replica, ""); err == nil {
+		t.Error("expected a handler without a secret to be refused")
+	}
+}
@@ -0,0 +1,111 @@
+package store
+
+import (
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// SchemesStore is a Store which sets the signature schemes of the channels it reads, and of the channels of the
+// objectives it reads, so that the signatures added to them are verified with the schemes of the engine holding them.
+type SchemesStore struct {
+	Store
+	schemes *crypto.Schemes
+}
+
+// NewSchemesStore returns a store which reads from s, verifying the signatures of the channels it reads with schemes.
+func NewSchemesStore(s Store, schemes *crypto.Schemes) *SchemesStore {
+	return &SchemesStore{Store: s, schemes: schemes}
+}
+
+// UseSchemes sets the signature schemes of each of the objective's channels which has been populated.
+func UseSchemes(obj protocols.Objective, schemes *crypto.Schemes) {
+	for _, rel := range obj.Related() {
+		switch c := rel.(type) {
+		case *channel.Channel:
+			if c != nil {
+				c.Schemes = schemes
+			}
+		case *channel.VirtualChannel:
+			if c != nil {
+				c.Schemes = schemes
+			}
+		case *consensus_channel.ConsensusChannel:
+			if c != nil {
+				c.Schemes = schemes
+			}
+		}
+	}
+}
+
+func (ss *SchemesStore) GetObjectiveById(id protocols.ObjectiveId) (protocols.Objective, error) {
+	obj, err := ss.Store.GetObjectiveById(id)
+	if obj != nil {
+		UseSchemes(obj, ss.schemes)
+	}
+	return obj, err
+}
+
+func (ss *SchemesStore) GetObjectiveByChannelId(channelId types.Destination) (protocols.Objective, bool) {
+	obj, ok := ss.Store.GetObjectiveByChannelId(channelId)
+	if obj != nil {
+		UseSchemes(obj, ss.schemes)
+	}
+	return obj, ok
+}
+
+func (ss *SchemesStore) useChannelSchemes(chs []*channel.Channel) []*channel.Channel {
+	for _, c := range chs {
+		c.Schemes = ss.schemes
+	}
+	return chs
+}
+
+func (ss *SchemesStore) GetChannelsByIds(ids []types.Destination) ([]*channel.Channel, error) {
+	chs, err := ss.Store.GetChannelsByIds(ids)
+	return ss.useChannelSchemes(chs), err
+}
+
+func (ss *SchemesStore) GetChannelById(id types.Destination) (*channel.Channel, bool) {
+	c, ok := ss.Store.GetChannelById(id)
+	if c != nil {
+		c.Schemes = ss.schemes
+	}
+	return c, ok
+}
+
+func (ss *SchemesStore) GetChannelsByParticipant(participant types.Address) ([]*channel.Channel, error) {
+	chs, err := ss.Store.GetChannelsByParticipant(participant)
+	return ss.useChannelSchemes(chs), err
+}
+
+func (ss *SchemesStore) GetChannelsByAppDefinition(appDef types.Address) ([]*channel.Channel, error) {
+	chs, err := ss.Store.GetChannelsByAppDefinition(appDef)
+	return ss.useChannelSchemes(chs), err
+}
+
+func (ss *SchemesStore) GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error) {
+	chs, err := ss.Store.GetAllConsensusChannels()
+	for _, c := range chs {
+		c.Schemes = ss.schemes
+	}
+	return chs, err
+}
+
+func (ss *SchemesStore) GetConsensusChannel(counterparty types.Address) (*consensus_channel.ConsensusChannel, bool) {
+	c, ok := ss.Store.GetConsensusChannel(counterparty)
+	if c != nil {
+		c.Schemes = ss.schemes
+	}
+	return c, ok
+}
+
+func (ss *SchemesStore) GetConsensusChannelById(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+	c, err := ss.Store.GetConsensusChannelById(id)
+	if c != nil {
+		c.Schemes = ss.schemes
+	}
+	return c, err
+}
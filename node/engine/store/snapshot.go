@@ -0,0 +1,81 @@
+package store
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// durableStoreFiles returns the paths of the database files of the durable stores in the folder
+func durableStoreFiles(folder string) ([]string, error) {
+	return filepath.Glob(filepath.Join(folder, "*.db"))
+}
+
+// SnapshotDurableStores copies the database files of every DurableStore using the folder into snapshotFolder, replacing
+// any snapshot already there. The stores must be closed, so that their files are consistent.
+//
+// With chainservice.MockChain's Snapshot, it lets integration tests fund channels once and restore each test case
+// to that baseline with RestoreDurableStores, instead of repeating the funding.
+func SnapshotDurableStores(folder, snapshotFolder string) error {
+	return replaceDurableStoreFiles(folder, snapshotFolder)
+}
+
+// RestoreDurableStores replaces the database files in the folder with those in snapshotFolder, taken with
+// SnapshotDurableStores. The stores using the folder must be closed, and are restored when they are next opened.
+// The snapshot is not modified, so it can be restored again.
+func RestoreDurableStores(snapshotFolder, folder string) error {
+	files, err := durableStoreFiles(snapshotFolder)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no durable store snapshot in %s", snapshotFolder)
+	}
+	return replaceDurableStoreFiles(snapshotFolder, folder)
+}
+
+// replaceDurableStoreFiles removes the database files in dst, and copies those in src into it
+func replaceDurableStoreFiles(src, dst string) error {
+	if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+		return err
+	}
+	old, err := durableStoreFiles(dst)
+	if err != nil {
+		return err
+	}
+	for _, file := range old {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+
+	files, err := durableStoreFiles(src)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := copyFile(file, filepath.Join(dst, filepath.Base(file))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
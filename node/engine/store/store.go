@@ -2,12 +2,20 @@
 package store // import "github.com/statechannels/go-nitro/node/engine/store"
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
 	"log/slog"
+	"math"
+	"math/big"
 	"path/filepath"
+	"slices"
+	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
@@ -16,15 +24,19 @@ import (
 )
 
 const (
-	ErrNoSuchObjective  = types.ConstError("store: no such objective")
-	ErrNoSuchChannel    = types.ConstError("store: failed to find required channel data")
-	ErrLoadVouchers     = types.ConstError("store: could not load vouchers")
-	lastBlockNumSeenKey = "lastBlockNumSeen"
+	ErrNoSuchObjective    = types.ConstError("store: no such objective")
+	ErrNoSuchChannel      = types.ConstError("store: failed to find required channel data")
+	ErrLoadVouchers       = types.ConstError("store: could not load vouchers")
+	ErrObjectiveCollected = types.ConstError("store: objective has been collected")
+	lastBlockNumSeenKey   = "lastBlockNumSeen"
+	epochKey              = "epoch"
+	outgoingSeqKeyPrefix  = "out-"
+	incomingSeqKeyPrefix  = "in-"
 )
 
 // Store is responsible for persisting objectives, objective metadata, states, signatures, private keys and blockchain data
 type Store interface {
-	GetChannelSecretKey() *[]byte                                                 // Get a pointer to a secret key for signing channel updates
+	GetChannelSecretKey() *[]byte                                                 // Get a pointer to a secret key for signing channel updates, or nil if the store was opened without one
 	GetAddress() *types.Address                                                   // Get the (Ethereum) address associated with the ChannelSecretKey
 	GetObjectiveById(protocols.ObjectiveId) (protocols.Objective, error)          // Read an existing objective
 	GetObjectiveByChannelId(types.Destination) (obj protocols.Objective, ok bool) // Get the objective that currently owns the channel with the supplied ChannelId
@@ -40,10 +52,269 @@ type Store interface {
 	SetLastBlockNumSeen(uint64) error
 
 	ConsensusChannelStore
+	ObjectiveSummaryStore
+	BalanceSnapshotStore
+	ActivityStore
+	MessageSequenceStore
+	ChannelTagStore
+	ChannelMemoStore
+	ConfigStore
+	PendingTransactionStore
+	BlocklistStore
 	payments.VoucherStore
+	payments.PaymentIdStore
 	io.Closer
 }
 
+// BalanceSnapshot records our balance, and that of our counterparty, in a channel at a point in time.
+type BalanceSnapshot struct {
+	ChannelId    types.Destination
+	Time         time.Time
+	AssetAddress types.Address
+	Me           types.Address
+	Them         types.Address
+	MyBalance    *big.Int
+	TheirBalance *big.Int
+}
+
+// key returns a key for the snapshot which sorts by time, then channel id.
+func (bs BalanceSnapshot) key() string {
+	return timeKey(bs.Time) + bs.ChannelId.String()
+}
+
+// paymentRecordKey returns a key for the record of the payment with the given id on the channel, which sorts with the
+// records of the channel's other payments.
+func paymentRecordKey(channelId types.Destination, paymentId string) string {
+	return channelId.String() + "/" + paymentId
+}
+
+// timeKey returns a zero-padded representation of t which sorts lexically in time order. Times before the Unix epoch
+// are offset to be non-negative and marked with a prefix which sorts before any digit, so that they sort in order before
+// later times, whose keys are unchanged. Times which cannot be represented in Unix nanoseconds (before 1678 or after
+// 2262) are clamped to the earliest or latest that can.
+func timeKey(t time.Time) string {
+	switch {
+	case t.Before(minKeyTime):
+		t = minKeyTime
+	case t.After(maxKeyTime):
+		t = maxKeyTime
+	}
+	ns := t.UnixNano()
+	if ns < 0 {
+		return fmt.Sprintf("-%019d", uint64(ns-math.MinInt64))
+	}
+	return fmt.Sprintf("%020d", ns)
+}
+
+// minKeyTime and maxKeyTime bound the times timeKey distinguishes.
+var (
+	minKeyTime = time.Unix(0, math.MinInt64)
+	maxKeyTime = time.Unix(0, math.MaxInt64)
+)
+
+// ActivityKind is the kind of an ActivityRecord
+type ActivityKind string
+
+const (
+	ChannelOpened   ActivityKind = "channel_opened"
+	ChannelClosed   ActivityKind = "channel_closed"
+	PaymentSent     ActivityKind = "payment_sent"
+	PaymentReceived ActivityKind = "payment_received"
+)
+
+// ActivityRecord records a payment, or the opening or closing of a channel.
+type ActivityRecord struct {
+	Time        time.Time
+	Kind        ActivityKind
+	ChannelId   types.Destination
+	ObjectiveId protocols.ObjectiveId // The objective which opened or closed the channel, if any
+	Amount      *big.Int              // The amount paid, if the record is for a payment
+	Fee         *big.Int              // The fee quoted to route the channel, if the record is for the opening of a channel routed by quote
+}
+
+// activityKey returns a key for the record which sorts by time. seq disambiguates records made at the same instant.
+func activityKey(r ActivityRecord, seq uint64) string {
+	return fmt.Sprintf("%s%020d", timeKey(r.Time), seq)
+}
+
+type ActivityStore interface {
+	AppendActivity(ActivityRecord) error
+	// GetActivity calls fn with each record made at or after from and before to, in time order, until fn returns false.
+	GetActivity(from, to time.Time, fn func(ActivityRecord) bool) error
+}
+
+type BalanceSnapshotStore interface {
+	SetBalanceSnapshot(BalanceSnapshot) error
+	GetBalanceSnapshots(from, to time.Time) ([]BalanceSnapshot, error) // Returns the snapshots taken at or after from and before to, in time order
+}
+
+// ObjectiveSummary is a compact record of an objective which has completed or been rejected.
+// It takes the place of the objective in the store once the objective has been collected.
+type ObjectiveSummary struct {
+	Id        protocols.ObjectiveId
+	Status    protocols.ObjectiveStatus
+	ChannelId types.Destination // The channel the objective owned
+	Collected time.Time
+}
+
+type ObjectiveSummaryStore interface {
+	// GetTerminalObjectiveIds returns the ids of objectives which have completed or been rejected, but have not been collected
+	GetTerminalObjectiveIds() ([]protocols.ObjectiveId, error)
+	// GetObjectiveIdsByPrefix returns the ids of the objectives, which have not been collected, whose ids start with prefix
+	GetObjectiveIdsByPrefix(prefix string) ([]protocols.ObjectiveId, error)
+	// CollectObjective deletes the objective summarised by the summary, keeping the summary in its place.
+	// Once collected, GetObjectiveById returns ErrObjectiveCollected for the objective.
+	CollectObjective(ObjectiveSummary) error
+	GetObjectiveSummary(id protocols.ObjectiveId) (ObjectiveSummary, error)
+}
+
+// isTerminal returns true if the encoded objective has completed or been rejected.
+func isTerminal(objJSON []byte) (bool, error) {
+	var status struct{ Status protocols.ObjectiveStatus }
+	if err := json.Unmarshal(objJSON, &status); err != nil {
+		return false, err
+	}
+	return status.Status == protocols.Completed || status.Status == protocols.Rejected, nil
+}
+
+// maxProcessedOutOfOrder bounds the number of messages from a peer which may be processed ahead of a missing one.
+// Beyond it, the missing message is presumed lost, and will be discarded if it ever arrives.
+const maxProcessedOutOfOrder = 1024
+
+// MessageSequence records which of the messages numbered by a peer have been processed.
+type MessageSequence struct {
+	Epoch   uint64   // The epoch of the peer's store, which numbered the messages
+	Through uint64   // Every message numbered up to and including Through has been processed
+	Above   []uint64 // The messages numbered above Through which have been processed, in ascending order
+}
+
+// Processed returns true if the message numbered seq by the peer's store in epoch has been processed.
+func (ms MessageSequence) Processed(epoch, seq uint64) bool {
+	if epoch != ms.Epoch {
+		return false
+	}
+	_, found := slices.BinarySearch(ms.Above, seq)
+	return seq <= ms.Through || found
+}
+
+// Record returns a copy of ms which records the message numbered seq by the peer's store in epoch as processed.
+// A new epoch means that the peer's store has been replaced, so messages from previous epochs are forgotten.
+func (ms MessageSequence) Record(epoch, seq uint64) MessageSequence {
+	if epoch != ms.Epoch {
+		ms = MessageSequence{Epoch: epoch}
+	}
+	if ms.Processed(epoch, seq) {
+		return ms
+	}
+	i, _ := slices.BinarySearch(ms.Above, seq)
+	ms.Above = slices.Insert(slices.Clone(ms.Above), i, seq)
+
+	for len(ms.Above) > maxProcessedOutOfOrder {
+		ms.Through, ms.Above = ms.Above[0], ms.Above[1:]
+	}
+	for len(ms.Above) > 0 && ms.Above[0] == ms.Through+1 {
+		ms.Through, ms.Above = ms.Above[0], ms.Above[1:]
+	}
+	return ms
+}
+
+// newEpoch returns an epoch for a new store, distinct from those of the stores it may replace.
+func newEpoch() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+// MessageSequenceStore numbers the messages we send to each peer, and records which of each peer's messages have been
+// processed, so that messages are processed exactly once, including across restarts.
+type MessageSequenceStore interface {
+	// NextMessageSeq returns the epoch of the store and the number of the next message to peer.
+	// The number is persisted before it is returned, so it is never reused by the store.
+	NextMessageSeq(peer types.Address) (epoch uint64, seq uint64, err error)
+	GetMessageSequence(peer types.Address) (MessageSequence, error)
+	SetMessageSequence(peer types.Address, ms MessageSequence) error
+}
+
+// ChannelTagStore holds the tags (such as an order or customer id) which applications attach to channels. Tags are
+// opaque to the node, and are never shared with peers.
+type ChannelTagStore interface {
+	SetChannelTags(id types.Destination, tags map[string]string) error // Replaces the channel's tags. Empty tags remove them.
+	GetChannelTags(id types.Destination) (map[string]string, error)    // Returns nil if the channel has no tags
+	FindChannelsByTag(key, value string) ([]types.Destination, error)  // Returns the channels tagged with the key and value
+}
+
+// ChannelMemoStore holds the memos with which channels were proposed (see protocols.MemoCarrier).
+type ChannelMemoStore interface {
+	SetChannelMemo(id types.Destination, memo string) error // Replaces the channel's memo. An empty memo removes it.
+	GetChannelMemo(id types.Destination) (string, error)    // Returns "" if the channel has no memo
+}
+
+// ConfigStore holds the settings changed while the node runs, so that they outlast a restart.
+type ConfigStore interface {
+	SetConfigValue(key, value string) error
+	GetConfigValues() (map[string]string, error) // Returns every setting which has been changed, keyed by name
+}
+
+// PendingTransaction records a chain transaction we submitted for a channel, until it is known to have been mined, so
+// that it can be resubmitted if it was dropped, including across restarts. Only deposits and conclusions are recorded.
+type PendingTransaction struct {
+	ChannelId  types.Destination
+	Deposit    types.Funds        `json:",omitempty"` // The deposit, if the transaction deposits into the channel
+	Conclusion *state.SignedState `json:",omitempty"` // The final state, if the transaction concludes the channel and withdraws its funds
+	TxHashes   []common.Hash      // The hashes of the chain transactions submitted for it. Empty until it has been submitted.
+	Submitted  time.Time
+}
+
+// NewPendingTransaction returns a record of the transaction, or false if the transaction is not of a kind which is recorded.
+func NewPendingTransaction(tx protocols.ChainTransaction) (PendingTransaction, bool) {
+	switch tx := tx.(type) {
+	case protocols.DepositTransaction:
+		return PendingTransaction{ChannelId: tx.ChannelId(), Deposit: tx.Deposit}, true
+	case protocols.WithdrawAllTransaction:
+		return PendingTransaction{ChannelId: tx.ChannelId(), Conclusion: &tx.SignedState}, true
+	default:
+		return PendingTransaction{}, false
+	}
+}
+
+// Transaction returns the recorded transaction, to be resubmitted.
+func (pt PendingTransaction) Transaction() protocols.ChainTransaction {
+	if pt.Conclusion != nil {
+		return protocols.NewWithdrawAllTransaction(pt.ChannelId, *pt.Conclusion)
+	}
+	return protocols.NewDepositTransaction(pt.ChannelId, pt.Deposit)
+}
+
+// PendingTransactionStore holds a record of each chain transaction which may not yet have been mined. A channel has at
+// most one pending transaction.
+type PendingTransactionStore interface {
+	SetPendingTransaction(PendingTransaction) error // Replaces any record for the same channel
+	GetPendingTransactions() ([]PendingTransaction, error)
+	RemovePendingTransaction(channelId types.Destination) error
+}
+
+// BlockedPeer records a peer whose messages are dropped, and whose objectives are refused. A peer is blocked by its
+// address or, for message services which identify peers otherwise (such as by libp2p peer id), by its peer id.
+type BlockedPeer struct {
+	Address types.Address // The zero address if the peer is blocked by its peer id
+	PeerId  string        `json:",omitempty"`
+	Reason  string        `json:",omitempty"`
+	Blocked time.Time
+}
+
+// Key returns the key under which the peer is blocked: its peer id, if it is blocked by peer id, or else its address.
+func (bp BlockedPeer) Key() string {
+	if bp.PeerId != "" {
+		return bp.PeerId
+	}
+	return bp.Address.String()
+}
+
+// BlocklistStore holds the peers which have been blocked, so that they stay blocked across restarts.
+type BlocklistStore interface {
+	SetBlockedPeer(BlockedPeer) error // Replaces any record with the same key
+	GetBlockedPeers() ([]BlockedPeer, error)
+	RemoveBlockedPeer(key string) error
+}
+
 type ConsensusChannelStore interface {
 	GetAllConsensusChannels() ([]*consensus_channel.ConsensusChannel, error)
 	GetConsensusChannel(counterparty types.Address) (channel *consensus_channel.ConsensusChannel, ok bool)
@@ -53,32 +324,62 @@ type ConsensusChannelStore interface {
 }
 
 type StoreOpts struct {
-	PkBytes            []byte
+	PkBytes []byte
+	// Address, used if PkBytes is nil, opens a store for that address without its key, for a node whose signatures are
+	// made by a Signer holding the key elsewhere (see node.WithSigner)
+	Address            types.Address
 	UseDurableStore    bool
 	DurableStoreFolder string
 	BuntDbConfig       buntdb.Config
+	// PostgresDsn, if set, keeps the store in the PostgreSQL database it names (see NewPostgresStore), rather than in
+	// the durable store folder or in memory
+	PostgresDsn string
+	// Replication, if set, replicates the store's mutations to a standby
+	Replication *ReplicationOpts
+	// Encryption, if set, encrypts the records of the durable store which hold channels, payments, balances, activity,
+	// tags and memos at rest, with the key it supplies (see NewEncryptedDurableStore). It has no effect on the other
+	// stores.
+	Encryption StoreKeySource
 }
 
 func NewStore(options StoreOpts) (Store, error) {
-	if options.PkBytes == nil {
-		panic("pk must be provided to Store")
+	if options.PkBytes == nil && options.Address == (types.Address{}) {
+		panic("pk or address must be provided to Store")
+	}
+	me := options.Address
+	if options.PkBytes != nil {
+		me = crypto.GetAddressFromSecretKeyBytes(options.PkBytes)
 	}
 
 	var ourStore Store
 	var err error
 
-	if options.UseDurableStore {
-		me := crypto.GetAddressFromSecretKeyBytes(options.PkBytes)
+	if options.PostgresDsn != "" {
+		slog.Info("Initialising postgres store...")
+		ourStore, err = openPostgresStore(options.PkBytes, me, options.PostgresDsn)
+		if err != nil {
+			return nil, err
+		}
+	} else if options.UseDurableStore {
 		dataFolder := filepath.Join(options.DurableStoreFolder, me.String())
 
 		slog.Info("Initialising durable store...", "dataFolder", dataFolder)
-		ourStore, err = NewDurableStore(options.PkBytes, dataFolder, buntdb.Config{})
+		ourStore, err = openDurableStore(options.PkBytes, me, dataFolder, buntdb.Config{}, options.Encryption)
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		slog.Info("Initialising mem store...")
-		ourStore = NewMemStore(options.PkBytes)
+		ourStore = newMemStore(options.PkBytes, me)
+	}
+
+	if options.Replication != nil {
+		slog.Info("Replicating store mutations to standby", "mode", options.Replication.Mode)
+		rs, err := NewReplicatingStore(ourStore, *options.Replication)
+		if err != nil {
+			return nil, err
+		}
+		return rs, nil
 	}
 
 	return ourStore, nil
@@ -1,9 +1,13 @@
 package store_test
 
 import (
+	"bytes"
+	"errors"
 	"math"
 	"math/big"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
@@ -163,7 +167,8 @@ func TestConsensusChannelStore(t *testing.T) {
 		fp,
 		0,
 		*outcome,
-		[2]state.Signature{aliceSig, bobsSig})
+		[2]state.Signature{aliceSig, bobsSig},
+		nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -171,7 +176,7 @@ func TestConsensusChannelStore(t *testing.T) {
 	// Generate a new proposal so we test that the proposal queue is being fetched properly
 	proposedGuarantee := cc.NewGuarantee(big.NewInt(1), types.Destination{2}, left.AsAllocation().Destination, right.AsAllocation().Destination)
 	proposal := cc.NewAddProposal(leader.Id, proposedGuarantee, big.NewInt(1))
-	_, err = leader.Propose(proposal, ta.Alice.PrivateKey)
+	_, err = leader.Propose(proposal, state.KeySigner(ta.Alice.PrivateKey))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -304,3 +309,409 @@ func TestBigNumberStorage(t *testing.T) {
 		}
 	}
 }
+
+func TestBalanceSnapshots(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memStore := store.NewMemStore(pk)
+
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	snapshot := func(id types.Destination, hours int) store.BalanceSnapshot {
+		return store.BalanceSnapshot{
+			ChannelId:    id,
+			Time:         start.Add(time.Duration(hours) * time.Hour),
+			Me:           ta.Alice.Address(),
+			Them:         ta.Bob.Address(),
+			MyBalance:    big.NewInt(int64(100 - hours)),
+			TheirBalance: big.NewInt(int64(hours)),
+		}
+	}
+	a, b := types.Destination{'a'}, types.Destination{'b'}
+	snapshots := []store.BalanceSnapshot{snapshot(b, 1), snapshot(a, 0), snapshot(a, 2), snapshot(a, 1), snapshot(a, 24)}
+
+	for _, s := range []store.Store{durableStore, memStore} {
+		for _, bs := range snapshots {
+			if err := s.SetBalanceSnapshot(bs); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		got, err := s.GetBalanceSnapshots(start.Add(time.Hour), start.Add(24*time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []store.BalanceSnapshot{snapshot(a, 1), snapshot(b, 1), snapshot(a, 2)}
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(big.Int{})); diff != "" {
+			t.Errorf("unexpected snapshots (-want +got):\n%s", diff)
+		}
+
+		// Snapshots before the Unix epoch sort in time order, before later ones
+		early := []store.BalanceSnapshot{snapshot(a, -550000), snapshot(a, -500000), snapshot(b, -500000)}
+		for _, bs := range early {
+			if err := s.SetBalanceSnapshot(bs); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err = s.GetBalanceSnapshots(time.Time{}, start.Add(time.Hour))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = []store.BalanceSnapshot{snapshot(a, -550000), snapshot(a, -500000), snapshot(b, -500000), snapshot(a, 0)}
+		if diff := cmp.Diff(want, got, cmp.AllowUnexported(big.Int{})); diff != "" {
+			t.Errorf("unexpected snapshots before the epoch (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestCollectObjective(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memStore := store.NewMemStore(pk)
+
+	for _, s := range []store.Store{durableStore, memStore} {
+		approved := td.Objectives.Directfund.GenericDFO()
+		approved.Status = protocols.Approved
+		completed := td.Objectives.Virtualfund.GenericVFO()
+		completed.Status = protocols.Completed
+
+		for _, o := range []protocols.Objective{&approved, &completed} {
+			if err := s.SetObjective(o); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		ids, err := s.GetTerminalObjectiveIds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]protocols.ObjectiveId{completed.Id()}, ids); diff != "" {
+			t.Fatalf("unexpected terminal objectives (-want +got):\n%s", diff)
+		}
+
+		summary := store.ObjectiveSummary{
+			Id:        completed.Id(),
+			Status:    completed.Status,
+			ChannelId: completed.OwnsChannel(),
+			Collected: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		if err := s.CollectObjective(summary); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.GetObjectiveById(completed.Id()); !errors.Is(err, store.ErrObjectiveCollected) {
+			t.Fatalf("expected %v, got %v", store.ErrObjectiveCollected, err)
+		}
+		got, err := s.GetObjectiveSummary(completed.Id())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(summary, got); diff != "" {
+			t.Fatalf("unexpected summary (-want +got):\n%s", diff)
+		}
+		ids, err = s.GetTerminalObjectiveIds()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(ids) != 0 {
+			t.Fatalf("expected no terminal objectives after collection, got %v", ids)
+		}
+
+		if _, err := s.GetObjectiveById(approved.Id()); err != nil {
+			t.Fatalf("expected the approved objective to be kept, got %v", err)
+		}
+		if _, err := s.GetObjectiveSummary(approved.Id()); !errors.Is(err, store.ErrNoSuchObjective) {
+			t.Fatalf("expected %v, got %v", store.ErrNoSuchObjective, err)
+		}
+	}
+}
+
+func TestMessageSequence(t *testing.T) {
+	ms := store.MessageSequence{}
+	for _, seq := range []uint64{1, 3, 4, 2, 6} {
+		if ms.Processed(7, seq) {
+			t.Fatalf("expected message %d not to have been processed", seq)
+		}
+		ms = ms.Record(7, seq)
+	}
+	want := store.MessageSequence{Epoch: 7, Through: 4, Above: []uint64{6}}
+	if diff := cmp.Diff(want, ms); diff != "" {
+		t.Fatalf("unexpected sequence (-want +got):\n%s", diff)
+	}
+	for _, seq := range []uint64{1, 2, 3, 4, 6} {
+		if !ms.Processed(7, seq) {
+			t.Fatalf("expected message %d to have been processed", seq)
+		}
+	}
+	if ms.Processed(7, 5) || ms.Processed(8, 1) {
+		t.Fatal("expected message 5, and messages from a new epoch, not to have been processed")
+	}
+
+	// A new epoch forgets the previous one
+	ms = ms.Record(8, 2)
+	want = store.MessageSequence{Epoch: 8, Through: 0, Above: []uint64{2}}
+	if diff := cmp.Diff(want, ms); diff != "" {
+		t.Fatalf("unexpected sequence (-want +got):\n%s", diff)
+	}
+
+	// A message which never arrives is eventually given up on
+	for seq := uint64(3); seq < 2000; seq++ {
+		ms = ms.Record(8, seq)
+	}
+	if !ms.Processed(8, 1) || len(ms.Above) != 0 || ms.Through != 1999 {
+		t.Fatalf("expected the missing message to be given up on, got %+v", ms)
+	}
+}
+
+func TestMessageSequenceStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memStore := store.NewMemStore(pk)
+
+	processed := store.MessageSequence{Epoch: 3, Through: 2, Above: []uint64{4}}
+	for _, s := range []store.Store{durableStore, memStore} {
+		for want := uint64(1); want <= 2; want++ {
+			_, seq, err := s.NextMessageSeq(ta.Bob.Address())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if seq != want {
+				t.Fatalf("expected message number %d, got %d", want, seq)
+			}
+		}
+		if err := s.SetMessageSequence(ta.Bob.Address(), processed); err != nil {
+			t.Fatal(err)
+		}
+		got, err := s.GetMessageSequence(ta.Bob.Address())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(processed, got); diff != "" {
+			t.Fatalf("unexpected sequence (-want +got):\n%s", diff)
+		}
+		if got, _ := s.GetMessageSequence(ta.Irene.Address()); got.Epoch != 0 || got.Through != 0 {
+			t.Fatalf("expected no sequence for a new peer, got %+v", got)
+		}
+	}
+
+	// The durable store keeps its epoch and message numbers when reopened
+	epoch, _, err := durableStore.NextMessageSeq(ta.Alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := durableStore.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	reopenedEpoch, seq, err := reopened.NextMessageSeq(ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopenedEpoch != epoch || seq != 3 {
+		t.Fatalf("expected epoch %d and message number 3 after reopening, got %d and %d", epoch, reopenedEpoch, seq)
+	}
+	got, err := reopened.GetMessageSequence(ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(processed, got); diff != "" {
+		t.Fatalf("unexpected sequence after reopening (-want +got):\n%s", diff)
+	}
+}
+
+func TestChannelTagStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+	memStore := store.NewMemStore(pk)
+
+	a, b, c := types.Destination{'a'}, types.Destination{'b'}, types.Destination{'c'}
+	for _, s := range []store.Store{durableStore, memStore} {
+		if err := s.SetChannelTags(a, map[string]string{"customerId": "1", "orderId": "x"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetChannelTags(b, map[string]string{"customerId": "1"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.SetChannelTags(c, map[string]string{"customerId": "2"}); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetChannelTags(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(map[string]string{"customerId": "1", "orderId": "x"}, got); diff != "" {
+			t.Errorf("unexpected tags (-want +got):\n%s", diff)
+		}
+
+		found, err := s.FindChannelsByTag("customerId", "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.SortFunc(found, func(x, y types.Destination) int { return bytes.Compare(x[:], y[:]) })
+		if diff := cmp.Diff([]types.Destination{a, b}, found); diff != "" {
+			t.Errorf("unexpected channels (-want +got):\n%s", diff)
+		}
+
+		// Empty tags remove the channel's tags
+		if err := s.SetChannelTags(a, nil); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.GetChannelTags(a); got != nil {
+			t.Errorf("expected no tags, got %v", got)
+		}
+		if found, _ := s.FindChannelsByTag("orderId", "x"); len(found) != 0 {
+			t.Errorf("expected no channels, got %v", found)
+		}
+	}
+}
+
+func TestChannelMemoStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+	memStore := store.NewMemStore(pk)
+
+	a, b := types.Destination{'a'}, types.Destination{'b'}
+	for _, s := range []store.Store{durableStore, memStore} {
+		if err := s.SetChannelMemo(a, "CDN traffic for site X"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := s.GetChannelMemo(a); err != nil || got != "CDN traffic for site X" {
+			t.Errorf("expected the memo, got %q (%v)", got, err)
+		}
+		if got, err := s.GetChannelMemo(b); err != nil || got != "" {
+			t.Errorf("expected no memo, got %q (%v)", got, err)
+		}
+
+		// An empty memo removes the channel's memo
+		if err := s.SetChannelMemo(a, ""); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := s.GetChannelMemo(a); got != "" {
+			t.Errorf("expected no memo, got %q", got)
+		}
+	}
+}
+
+func TestPendingTransactionStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+	memStore := store.NewMemStore(pk)
+
+	a, b := types.Destination{'a'}, types.Destination{'b'}
+	deposit, ok := store.NewPendingTransaction(protocols.NewDepositTransaction(a, types.Funds{common.Address{}: big.NewInt(5)}))
+	if !ok {
+		t.Fatal("expected a deposit to be recorded")
+	}
+	deposit.TxHashes = []common.Hash{{1}, {2}}
+	conclusion, ok := store.NewPendingTransaction(protocols.NewWithdrawAllTransaction(b, state.NewSignedState(state.TestState)))
+	if !ok {
+		t.Fatal("expected a conclusion to be recorded")
+	}
+	conclusion.TxHashes = []common.Hash{{3}}
+	if _, ok := store.NewPendingTransaction(protocols.NewChallengeTransaction(a, state.NewSignedState(state.TestState), nil, state.Signature{})); ok {
+		t.Fatal("expected a challenge not to be recorded")
+	}
+
+	for _, s := range []store.Store{durableStore, memStore} {
+		for _, pt := range []store.PendingTransaction{deposit, conclusion} {
+			if err := s.SetPendingTransaction(pt); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.RemovePendingTransaction(b); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetPendingTransactions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]store.PendingTransaction{deposit}, got, cmp.AllowUnexported(big.Int{})); diff != "" {
+			t.Errorf("unexpected pending transactions (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff(protocols.ChainTransaction(protocols.NewDepositTransaction(a, deposit.Deposit)), got[0].Transaction(),
+			cmp.AllowUnexported(big.Int{}, protocols.ChainTransactionBase{})); diff != "" {
+			t.Errorf("unexpected transaction (-want +got):\n%s", diff)
+		}
+	}
+}
+
+func TestBlocklistStore(t *testing.T) {
+	pk := common.Hex2Bytes(`2af069c584758f9ec47c4224a8becc1983f28acfbe837bd7710b70f9fc6d5e44`)
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	durableStore, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer durableStore.Close()
+	memStore := store.NewMemStore(pk)
+
+	byAddress := store.BlockedPeer{Address: common.HexToAddress("0x0b"), Reason: "spam", Blocked: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}
+	byPeerId := store.BlockedPeer{PeerId: "16Uiu2HAmJ", Blocked: time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)}
+	for _, s := range []store.Store{durableStore, memStore} {
+		for _, bp := range []store.BlockedPeer{byAddress, byPeerId} {
+			if err := s.SetBlockedPeer(bp); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.RemoveBlockedPeer(byPeerId.Key()); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.RemoveBlockedPeer("unknown"); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := s.GetBlockedPeers()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff([]store.BlockedPeer{byAddress}, got); diff != "" {
+			t.Errorf("unexpected blocked peers (-want +got):\n%s", diff)
+		}
+	}
+}
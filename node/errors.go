@@ -0,0 +1,124 @@
+package node
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/escrow"
+	"github.com/statechannels/go-nitro/protocols/subscription"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Errors returned by Node methods. Callers should test for them with errors.Is, as they are usually wrapped with more detail.
+const (
+	ErrChannelNotFound      = types.ConstError("channel not found")
+	ErrInsufficientFunds    = payments.ErrInsufficientFunds
+	ErrObjectiveRejected    = types.ConstError("objective rejected")
+	ErrPeerUnreachable      = types.ConstError("peer unreachable")
+	ErrWalletBalanceLow     = types.ConstError("funding wallet balance too low")
+	ErrInvalidChannelTags   = types.ConstError("invalid channel tags")
+	ErrInvalidChannelMemo   = types.ConstError("invalid channel memo")
+	ErrNoFaultInjection     = types.ConstError("message service does not support fault injection")
+	ErrInvalidConfig        = types.ConstError("invalid configuration")
+	ErrCloseAllInProgress   = types.ConstError("already closing all channels")
+	ErrUnknownObjectiveType = types.ConstError("unknown objective type")
+	ErrSubscriptionNotFound = types.ConstError("subscription not found")
+	ErrEscrowNotFound       = types.ConstError("escrow not found")
+	ErrInvalidBatch         = types.ConstError("invalid batch of channels")
+	ErrBatchNotFound        = types.ConstError("batch not found")
+	ErrCounterpartyRefused  = types.ConstError("counterparty refused the channel")
+	ErrNoDefaultHub         = types.ConstError("no default hub is enabled")
+)
+
+// ErrVoucherExpired is returned by ReceiveVoucher when the voucher's expiry has passed.
+const ErrVoucherExpired = payments.ErrVoucherExpired
+
+// ErrVoucherReplayed is returned by ReceiveVoucher when the voucher's nonce is not greater than that of the largest
+// voucher received on its channel, and ErrVoucherNonceRequired when replay protection is enabled and it has no nonce.
+const (
+	ErrVoucherReplayed      = payments.ErrVoucherReplayed
+	ErrVoucherNonceRequired = payments.ErrVoucherNonceRequired
+)
+
+// ErrAssetNotAllowed is wrapped by the engine.AssetNotAllowedError returned when creating a channel whose outcome holds an
+// asset the node's policy does not allow.
+const ErrAssetNotAllowed = engine.ErrAssetNotAllowed
+
+// ErrWithdrawalRefused is returned by WithdrawFromLedgerChannel for a ledger channel which funds payment channels, or
+// whose balance does not cover the amount.
+const ErrWithdrawalRefused = types.ConstError("ledger withdrawal refused")
+
+// ErrPeerBlocked is returned when creating a channel with a peer which has been blocked (see BlockPeer).
+const ErrPeerBlocked = engine.ErrPeerBlocked
+
+// ErrFundingDeferred is returned when creating a channel, or adding funds to a ledger channel, while exits are
+// prioritized over funding (see engine.ExitPriority).
+const ErrFundingDeferred = engine.ErrFundingDeferred
+
+// ErrInvalidSubscription is returned by CreateSubscription when the terms of the subscription describe no payments.
+const ErrInvalidSubscription = subscription.ErrInvalidTerms
+
+// ErrSubscriptionNotActive is returned by CancelSubscription when the subscription has already ended.
+const ErrSubscriptionNotActive = subscription.ErrNotActive
+
+// ErrInvalidEscrow is returned by CreateEscrow when the terms of the escrow describe no payment.
+const ErrInvalidEscrow = escrow.ErrInvalidTerms
+
+// ErrEscrowNotOpen is returned when an escrow can no longer take a result, or be disputed or expired.
+const ErrEscrowNotOpen = escrow.ErrNotOpen
+
+// ErrResultMismatch is returned by SubmitEscrowResult when the hash of the result does not match the commitment.
+const ErrResultMismatch = escrow.ErrResultMismatch
+
+// ErrLedgerChannelExists is returned by CreateLedgerChannel when we already have a ledger channel with the counterparty.
+var ErrLedgerChannelExists = directfund.ErrLedgerChannelExists
+
+// WalletBalanceError is returned by CreateLedgerChannel when the funding wallet cannot afford our deposit of an asset
+// (plus the gas to deposit every asset, for the native token). It wraps ErrWalletBalanceLow.
+type WalletBalanceError struct {
+	Asset     types.Address
+	Required  *big.Int
+	Available *big.Int
+}
+
+func (e *WalletBalanceError) Error() string {
+	return fmt.Sprintf("%s: asset %s requires %s, wallet holds %s", ErrWalletBalanceLow, e.Asset, e.Required, e.Available)
+}
+
+func (e *WalletBalanceError) Unwrap() error {
+	return ErrWalletBalanceLow
+}
+
+// ProbeRefusedError is returned when a probed counterparty would not fund the proposed channel. It wraps
+// ErrCounterpartyRefused.
+type ProbeRefusedError struct {
+	Counterparty types.Address
+	Refusal      protocols.ProbeRefusal
+	Reason       string
+}
+
+func (e *ProbeRefusedError) Error() string {
+	return fmt.Sprintf("%s: %s refused with %s: %s", ErrCounterpartyRefused, e.Counterparty, e.Refusal, e.Reason)
+}
+
+func (e *ProbeRefusedError) Unwrap() error {
+	return ErrCounterpartyRefused
+}
+
+// channelNotFound returns an error reporting that no channel with the given id is known to the node.
+func channelNotFound(id types.Destination) error {
+	return fmt.Errorf("%w: %s", ErrChannelNotFound, id)
+}
+
+// channelExists returns true if the store holds a channel or a consensus channel with the given id.
+func (n *Node) channelExists(id types.Destination) bool {
+	if _, ok := n.store.GetChannelById(id); ok {
+		return true
+	}
+	_, err := n.store.GetConsensusChannelById(id)
+	return err == nil
+}
@@ -0,0 +1,265 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/escrow"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// CreateEscrow opens an escrow paying amount through the payment channel for a result whose keccak256 hash is
+// commitment, delivered by the payee within timeout. We must be the payer of the channel, and have enough left in it
+// to pay the amount. The amount is paid once the result is delivered and found to match the commitment; if no result
+// is delivered in time the escrow expires.
+func (n *Node) CreateEscrow(ctx context.Context, channelId types.Destination, amount *big.Int, commitment types.Bytes32, timeout time.Duration) (query.EscrowInfo, error) {
+	info, err := n.GetPaymentChannel(channelId)
+	if err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if info.Status != query.Open {
+		return query.EscrowInfo{}, fmt.Errorf("%w: channel %s is %s", ErrInvalidEscrow, channelId, info.Status)
+	}
+	if info.Balance.Payer != *n.Address {
+		return query.EscrowInfo{}, fmt.Errorf("%w: we do not pay through channel %s", ErrInvalidEscrow, channelId)
+	}
+	if amount != nil && info.Balance.RemainingFunds.ToInt().Cmp(amount) < 0 {
+		return query.EscrowInfo{}, fmt.Errorf("%w: channel %s has %s left", ErrInsufficientFunds, channelId, info.Balance.RemainingFunds.ToInt())
+	}
+
+	terms := escrow.Terms{
+		ChannelId:  channelId,
+		Payer:      info.Balance.Payer,
+		Payee:      info.Balance.Payee,
+		Amount:     amount,
+		Commitment: commitment,
+		Deadline:   time.Now().Add(timeout),
+		Nonce:      rand.Uint64(),
+	}
+	if err := terms.Validate(); err != nil {
+		return query.EscrowInfo{}, err
+	}
+
+	request := escrow.NewObjectiveRequest(terms)
+	if err := n.submitObjectiveRequest(ctx, request); err != nil {
+		return query.EscrowInfo{}, err
+	}
+	id := request.Id(*n.Address, n.chainId)
+	created, err := n.GetEscrow(id)
+	if err != nil {
+		return query.EscrowInfo{}, fmt.Errorf("could not open escrow %s: %w", id, err)
+	}
+	n.expireEscrow(id, terms.Deadline)
+	return created, nil
+}
+
+// GetEscrow returns the escrow with the given id, which we pay or are paid by.
+func (n *Node) GetEscrow(id protocols.ObjectiveId) (query.EscrowInfo, error) {
+	e, err := n.getEscrow(id)
+	if err != nil {
+		return query.EscrowInfo{}, err
+	}
+	return n.escrowInfo(e)
+}
+
+// SubmitEscrowResult delivers the result of the escrow with the given id to its payer, whose node releases the payment
+// if the result matches the commitment. We must be the payee, and the result must be delivered by the deadline.
+func (n *Node) SubmitEscrowResult(ctx context.Context, id protocols.ObjectiveId, result []byte) (query.EscrowInfo, error) {
+	e, err := n.getEscrow(id)
+	if err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if time.Now().After(e.Terms.Deadline) {
+		return query.EscrowInfo{}, fmt.Errorf("%w: the deadline of %s has passed", ErrEscrowNotOpen, id)
+	}
+	if _, err := e.DeliverResult(result); err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if err := n.submitObjectiveRequest(ctx, escrow.NewResultRequest(id, result)); err != nil {
+		return query.EscrowInfo{}, err
+	}
+	return n.GetEscrow(id)
+}
+
+// DisputeEscrow disputes the escrow with the given id, whose result we delivered but which has not been released, and
+// closes its payment channel. The payments already made through the channel are then settled by the on-chain payment
+// app: it enforces vouchers, so the escrowed amount is not paid unless the payer released it.
+func (n *Node) DisputeEscrow(ctx context.Context, id protocols.ObjectiveId, reason string) (query.EscrowInfo, error) {
+	e, err := n.getEscrow(id)
+	if err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if _, err := e.Conclude(escrow.Disputed, reason); err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if err := n.submitObjectiveRequest(ctx, escrow.NewConcludeRequest(id, escrow.Disputed, reason)); err != nil {
+		return query.EscrowInfo{}, err
+	}
+	if _, err := n.ClosePaymentChannelContext(ctx, e.Terms.ChannelId); err != nil {
+		return query.EscrowInfo{}, fmt.Errorf("could not close channel %s of disputed escrow %s: %w", e.Terms.ChannelId, id, err)
+	}
+	return n.GetEscrow(id)
+}
+
+// getEscrow returns the objective of the escrow with the given id.
+func (n *Node) getEscrow(id protocols.ObjectiveId) (*escrow.Objective, error) {
+	if !escrow.IsEscrowObjective(id) {
+		return nil, fmt.Errorf("%w: %s", ErrEscrowNotFound, id)
+	}
+	o, err := n.store.GetObjectiveById(id)
+	if errors.Is(err, store.ErrNoSuchObjective) {
+		return nil, fmt.Errorf("%w: %s", ErrEscrowNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	e, ok := o.(*escrow.Objective)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEscrowNotFound, id)
+	}
+	return e, nil
+}
+
+// escrowInfo describes the escrow.
+func (n *Node) escrowInfo(e *escrow.Objective) (query.EscrowInfo, error) {
+	info := query.EscrowInfo{
+		ID:         e.Id(),
+		ChannelId:  e.Terms.ChannelId,
+		Payer:      e.Terms.Payer,
+		Payee:      e.Terms.Payee,
+		Amount:     (*hexutil.Big)(e.Terms.Amount),
+		Commitment: e.Terms.Commitment,
+		Deadline:   e.Terms.Deadline,
+		Result:     e.Result,
+		Reason:     e.Reason,
+	}
+	switch {
+	case e.Status == protocols.Unapproved:
+		info.Status = query.EscrowProposed
+	case e.Status == protocols.Rejected:
+		info.Status = query.EscrowRejected
+	case e.Concluded():
+		info.Status = query.EscrowStatus(e.Outcome)
+	case e.Result != nil:
+		info.Status = query.EscrowDelivered
+	default:
+		info.Status = query.EscrowOpen
+	}
+
+	if e.Releases() {
+		_, paid, err := n.store.GetPaymentRecord(e.Terms.ChannelId, string(e.Id()))
+		if err != nil {
+			return query.EscrowInfo{}, err
+		}
+		info.Paid = paid
+	}
+	return info, nil
+}
+
+// resumeEscrows pays the escrows we released but had not paid, and resumes waiting for the deadlines of the open
+// escrows we pay, after a restart.
+func (n *Node) resumeEscrows() error {
+	ids, err := n.store.GetObjectiveIdsByPrefix(escrow.ObjectivePrefix)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		o, err := n.store.GetObjectiveById(id)
+		if err != nil {
+			return err
+		}
+		e, ok := o.(*escrow.Objective)
+		if !ok || !e.IsPayer() {
+			continue
+		}
+		switch {
+		case e.Releases():
+			if _, paid, err := n.store.GetPaymentRecord(e.Terms.ChannelId, string(id)); err == nil && !paid {
+				n.releaseEscrow(id, e.Terms)
+			}
+		case e.Status == protocols.Approved && !e.Concluded():
+			n.expireEscrow(id, e.Terms.Deadline)
+		}
+	}
+	return nil
+}
+
+// runEscrowTask runs the task in the background, with a context cancelled when the node is closed.
+func (n *Node) runEscrowTask(task func(ctx context.Context)) {
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopBackgroundTasks:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		task(ctx)
+	}()
+}
+
+// releaseEscrow pays the released escrow in the background. The payment id is the escrow id, so that it is not paid
+// twice.
+func (n *Node) releaseEscrow(id protocols.ObjectiveId, terms escrow.Terms) {
+	n.runEscrowTask(func(ctx context.Context) {
+		err := n.PayWithOptions(ctx, terms.ChannelId, terms.Amount, PayOptions{PaymentId: string(id), Context: string(id)})
+		if err != nil {
+			if ctx.Err() == nil {
+				n.logger.Error("Could not pay released escrow", "escrow", id, "error", err)
+			}
+			return
+		}
+
+		// The engine abandons a payment whose context is done before it is handled, so wait for it to be recorded
+		ticker := time.NewTicker(20 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			if _, paid, err := n.store.GetPaymentRecord(terms.ChannelId, string(id)); err != nil || paid {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+}
+
+// expireEscrow expires the escrow at its deadline in the background, unless a result has been delivered by then.
+func (n *Node) expireEscrow(id protocols.ObjectiveId, deadline time.Time) {
+	const reason = "no result was delivered by the deadline"
+	n.runEscrowTask(func(ctx context.Context) {
+		select {
+		case <-time.After(time.Until(deadline)):
+		case <-n.ObjectiveCompleteChan(id):
+			return
+		case <-ctx.Done():
+			return
+		}
+		e, err := n.getEscrow(id)
+		if err == nil {
+			if _, err = e.Conclude(escrow.Expired, reason); errors.Is(err, ErrEscrowNotOpen) {
+				return // A result was delivered in time
+			}
+		}
+		if err == nil {
+			err = n.submitObjectiveRequest(ctx, escrow.NewConcludeRequest(id, escrow.Expired, reason))
+		}
+		if err != nil && ctx.Err() == nil {
+			n.logger.Error("Could not expire escrow", "escrow", id, "error", err)
+		}
+	})
+}
@@ -0,0 +1,140 @@
+// Package faucet requests testnet funds for the funding wallet of a node, so that developers can open their first
+// channels without first acquiring tokens by hand. It must never be used on a chain holding real value.
+package faucet // import "github.com/statechannels/go-nitro/node/faucet"
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	ethTypes "github.com/ethereum/go-ethereum/core/types"
+	chainutils "github.com/statechannels/go-nitro/node/engine/chainservice/utils"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Faucet gives funds to an account on a testnet.
+type Faucet interface {
+	// Drip requests funds for the account. The funds may arrive some time after Drip returns.
+	Drip(ctx context.Context, account types.Address) error
+}
+
+// Testnet is a chain whose faucet is known.
+type Testnet struct {
+	Name    string
+	ChainId uint64
+	// DevAccountKey is the private key of an account which the chain prefunds, if it is a local development chain
+	DevAccountKey string
+	// FaucetUrl is the url of a faucet which accepts the requests sent by an HTTPFaucet
+	FaucetUrl string
+}
+
+// anvilDevAccountKey is the key of the first account prefunded by anvil and hardhat. It is widely published, so must
+// only ever hold test funds.
+const anvilDevAccountKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+// KnownTestnets are the testnets whose faucets are known, keyed by chain id. Other testnets may be used by giving the
+// url of their faucet explicitly.
+var KnownTestnets = map[uint64]Testnet{
+	1337:  {Name: "local anvil", ChainId: 1337, DevAccountKey: anvilDevAccountKey},
+	31337: {Name: "local hardhat", ChainId: 31337, DevAccountKey: anvilDevAccountKey},
+}
+
+// DEFAULT_DRIP is the amount of native token, in wei, a DevAccountFaucet gives.
+var DEFAULT_DRIP = big.NewInt(1_000_000_000_000_000_000)
+
+// New returns the faucet of the testnet, connecting to the chain at chainUrl if the faucet is a development account.
+func New(ctx context.Context, testnet Testnet, chainUrl, chainAuthToken string) (Faucet, error) {
+	switch {
+	case testnet.DevAccountKey != "":
+		client, txSigner, err := chainutils.ConnectToChain(ctx, chainUrl, chainAuthToken, common.Hex2Bytes(testnet.DevAccountKey))
+		if err != nil {
+			return nil, err
+		}
+		return NewDevAccountFaucet(client, txSigner, DEFAULT_DRIP), nil
+	case testnet.FaucetUrl != "":
+		return NewHTTPFaucet(testnet.FaucetUrl), nil
+	default:
+		return nil, fmt.Errorf("no faucet is known for %s", testnet.Name)
+	}
+}
+
+// TransferBackend is the part of a chain client a DevAccountFaucet uses to send native tokens.
+type TransferBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *ethTypes.Transaction) error
+}
+
+// DevAccountFaucet gives native tokens from an account prefunded by a local development chain.
+type DevAccountFaucet struct {
+	backend  TransferBackend
+	txSigner *bind.TransactOpts
+	amount   *big.Int
+}
+
+// NewDevAccountFaucet returns a faucet which sends amount of native token from the account of txSigner with each drip.
+func NewDevAccountFaucet(backend TransferBackend, txSigner *bind.TransactOpts, amount *big.Int) *DevAccountFaucet {
+	return &DevAccountFaucet{backend: backend, txSigner: txSigner, amount: amount}
+}
+
+// transferGas is the gas used by a transfer of native token to an account which is not a contract.
+const transferGas = 21_000
+
+// Drip sends native token to the account.
+func (df *DevAccountFaucet) Drip(ctx context.Context, account types.Address) error {
+	nonce, err := df.backend.PendingNonceAt(ctx, df.txSigner.From)
+	if err != nil {
+		return err
+	}
+	gasPrice, err := df.backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return err
+	}
+	tx := ethTypes.NewTx(&ethTypes.LegacyTx{Nonce: nonce, GasPrice: gasPrice, Gas: transferGas, To: &account, Value: df.amount})
+	signedTx, err := df.txSigner.Signer(df.txSigner.From, tx)
+	if err != nil {
+		return err
+	}
+	return df.backend.SendTransaction(ctx, signedTx)
+}
+
+// HTTPFaucet requests funds from a faucet with a POST of a json object holding the account's address, as
+// {"address": "0x..."}. Any 2xx response is taken to mean the request was accepted.
+type HTTPFaucet struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPFaucet returns a faucet which requests funds from url.
+func NewHTTPFaucet(url string) *HTTPFaucet {
+	return &HTTPFaucet{url: url, client: &http.Client{}}
+}
+
+// Drip requests funds for the account.
+func (hf *HTTPFaucet) Drip(ctx context.Context, account types.Address) error {
+	body, err := json.Marshal(map[string]string{"address": account.Hex()})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hf.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := hf.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("faucet refused the request (%s): %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestHTTPFaucet(t *testing.T) {
+	account := common.HexToAddress("0x111A00868581f73AB42FEEF67D235Ca09ca1E8db")
+	var requested string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Error(err)
+		}
+		requested = body["address"]
+		if requested != account.Hex() {
+			http.Error(w, "rate limited", http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	f := NewHTTPFaucet(server.URL)
+	if err := f.Drip(context.Background(), account); err != nil {
+		t.Fatal(err)
+	}
+	if requested != account.Hex() {
+		t.Errorf("expected funds to be requested for %s, got %s", account.Hex(), requested)
+	}
+	if err := f.Drip(context.Background(), types.Address{}); err == nil {
+		t.Error("expected the refusal of the faucet to be reported")
+	}
+}
+
+func TestDevAccountFaucet(t *testing.T) {
+	sim, _, accounts, err := chainservice.SetupSimulatedBackend(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sim.Close()
+
+	account := common.HexToAddress("0x111A00868581f73AB42FEEF67D235Ca09ca1E8db")
+	amount := big.NewInt(1_000_000)
+	f := NewDevAccountFaucet(sim, accounts[0], amount)
+	if err := f.Drip(context.Background(), account); err != nil {
+		t.Fatal(err)
+	}
+	sim.Commit()
+
+	balance, err := sim.BalanceAt(context.Background(), account, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(amount) != 0 {
+		t.Errorf("expected a balance of %s, got %s", amount, balance)
+	}
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/pricefeed"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// fiatPrices holds the prices most recently fetched from a price feed.
+type fiatPrices struct {
+	mu     sync.RWMutex
+	prices map[types.Address]pricefeed.Price
+	asOf   time.Time
+}
+
+func (fp *fiatPrices) set(prices map[types.Address]pricefeed.Price, asOf time.Time) {
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	fp.prices, fp.asOf = prices, asOf
+}
+
+func (fp *fiatPrices) price(asset types.Address) (pricefeed.Price, time.Time, bool) {
+	fp.mu.RLock()
+	defer fp.mu.RUnlock()
+	p, ok := fp.prices[asset]
+	return p, fp.asOf, ok
+}
+
+// valuePaymentChannel returns the info with a fiat valuation of its balance, if there is a price for its asset.
+func (fp *fiatPrices) valuePaymentChannel(info query.PaymentChannelInfo) query.PaymentChannelInfo {
+	if p, asOf, ok := fp.price(info.Balance.AssetAddress); ok {
+		return info.WithFiat(p, asOf)
+	}
+	return info
+}
+
+// valueLedgerChannel returns the info with a fiat valuation of its balance, if there is a price for its asset.
+func (fp *fiatPrices) valueLedgerChannel(info query.LedgerChannelInfo) query.LedgerChannelInfo {
+	if p, asOf, ok := fp.price(info.Balance.AssetAddress); ok {
+		return info.WithFiat(p, asOf)
+	}
+	return info
+}
+
+// EnableFiatValuation starts fetching prices from feed now and once every interval, so that the payment and ledger
+// channel infos returned by the node include approximate valuations of their balances in the fiat currency of each
+// asset's price. If a fetch fails, the previous prices are kept.
+func (n *Node) EnableFiatValuation(feed pricefeed.Feed, interval time.Duration) {
+	refresh := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		prices, err := feed.Prices(ctx)
+		if err != nil {
+			n.logger.Error("failed to fetch fiat prices", "error", err)
+			return
+		}
+		n.fiatPrices.set(prices, time.Now())
+	}
+	refresh()
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				refresh()
+			case <-n.stopBackgroundTasks:
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,204 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// DefaultBatchConcurrency is how many channels of a batch are opened at once, unless told otherwise.
+	DefaultBatchConcurrency = 10
+	// DefaultBatchChannelTimeout is how long each channel of a batch is given to be funded, unless told otherwise.
+	DefaultBatchChannelTimeout = 5 * time.Minute
+)
+
+// LedgerFundingTemplate describes how each ledger channel of a batch is funded: we deposit MyDeposit of the Asset, and
+// the counterparty deposits TheirDeposit.
+type LedgerFundingTemplate struct {
+	Asset             types.Address
+	MyDeposit         *big.Int
+	TheirDeposit      *big.Int
+	ChallengeDuration uint32
+	// Tags are attached to each channel of the batch
+	Tags map[string]string
+}
+
+// outcome returns the outcome of the ledger channel with the counterparty.
+func (t LedgerFundingTemplate) outcome(me, counterparty types.Address) outcome.Exit {
+	return outcome.Exit{outcome.SingleAssetExit{
+		Asset: t.Asset,
+		Allocations: outcome.Allocations{
+			{Destination: types.AddressToDestination(me), Amount: t.MyDeposit},
+			{Destination: types.AddressToDestination(counterparty), Amount: t.TheirDeposit},
+		},
+	}}
+}
+
+// BatchOptions configures CreateLedgerChannels.
+type BatchOptions struct {
+	// Concurrency is how many channels are opened at once. Zero means DefaultBatchConcurrency.
+	Concurrency int
+	// Timeout is how long each channel is given to be funded, once created. Zero means DefaultBatchChannelTimeout.
+	Timeout time.Duration
+	// Probe probes each counterparty (see ProbeCounterparty) before creating its channel, so that counterparties which
+	// would not fund the channel fail fast.
+	Probe bool
+}
+
+// ledgerBatches tracks the progress of the batches started by CreateLedgerChannels.
+type ledgerBatches struct {
+	mu      sync.Mutex
+	batches map[string]*query.LedgerChannelBatchInfo
+}
+
+// update applies change to the progress of the ith channel of the batch.
+func (lb *ledgerBatches) update(id string, i int, change func(*query.BatchChannelInfo)) {
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	change(&lb.batches[id].Channels[i])
+}
+
+// snapshot returns a copy of the progress of the batch, which it is safe to read while the batch continues.
+// It must be called with lb.mu held.
+func (lb *ledgerBatches) snapshot(id string) query.LedgerChannelBatchInfo {
+	info := *lb.batches[id]
+	info.Channels = slices.Clone(info.Channels)
+	return info
+}
+
+// CreateLedgerChannels opens a ledger channel with each of the counterparties, funded as described by the template,
+// for operators onboarding many nodes to a hub. At most opts.Concurrency channels are created and funded at once.
+// A counterparty with which we already have a ledger channel fails, without affecting the rest of the batch.
+//
+// The batch continues in the background. CreateLedgerChannels returns its initial progress, listing the counterparties;
+// LedgerChannelBatchProgress reports how far it has got.
+func (n *Node) CreateLedgerChannels(counterparties []types.Address, template LedgerFundingTemplate, opts BatchOptions) (query.LedgerChannelBatchInfo, error) {
+	if len(counterparties) == 0 {
+		return query.LedgerChannelBatchInfo{}, fmt.Errorf("%w: no counterparties", ErrInvalidBatch)
+	}
+	if template.MyDeposit == nil || template.TheirDeposit == nil || template.MyDeposit.Sign() < 0 || template.TheirDeposit.Sign() < 0 {
+		return query.LedgerChannelBatchInfo{}, fmt.Errorf("%w: deposits must not be negative", ErrInvalidBatch)
+	}
+	if err := validateChannelTags(template.Tags); err != nil {
+		return query.LedgerChannelBatchInfo{}, err
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBatchConcurrency
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = DefaultBatchChannelTimeout
+	}
+
+	channels := make([]query.BatchChannelInfo, 0, len(counterparties))
+	for _, c := range counterparties {
+		if c == *n.Address || slices.ContainsFunc(channels, func(b query.BatchChannelInfo) bool { return b.Counterparty == c }) {
+			return query.LedgerChannelBatchInfo{}, fmt.Errorf("%w: counterparty %s is repeated or is us", ErrInvalidBatch, c)
+		}
+		channels = append(channels, query.BatchChannelInfo{Counterparty: c, Status: query.BatchChannelPending})
+	}
+
+	id := strconv.FormatUint(rand.Uint64(), 16)
+	lb := n.ledgerBatches
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	lb.batches[id] = &query.LedgerChannelBatchInfo{ID: id, Started: time.Now(), Channels: channels}
+	n.logger.Info("Opening a batch of ledger channels", "batch", id, "channels", len(channels), "concurrency", opts.Concurrency)
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopBackgroundTasks:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		n.openBatch(ctx, id, counterparties, template, opts)
+	}()
+	return lb.snapshot(id), nil
+}
+
+// LedgerChannelBatchProgress returns the progress of the batch of ledger channels with the given id.
+func (n *Node) LedgerChannelBatchProgress(id string) (query.LedgerChannelBatchInfo, error) {
+	lb := n.ledgerBatches
+	lb.mu.Lock()
+	defer lb.mu.Unlock()
+	if _, ok := lb.batches[id]; !ok {
+		return query.LedgerChannelBatchInfo{}, fmt.Errorf("%w: %s", ErrBatchNotFound, id)
+	}
+	return lb.snapshot(id), nil
+}
+
+// openBatch opens the channels of the batch, opts.Concurrency at a time.
+func (n *Node) openBatch(ctx context.Context, id string, counterparties []types.Address, template LedgerFundingTemplate, opts BatchOptions) {
+	slots := make(chan struct{}, opts.Concurrency)
+	wg := sync.WaitGroup{}
+	for i, c := range counterparties {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			n.batchChannelFailed(id, i, ctx.Err())
+			continue
+		}
+		wg.Add(1)
+		go func(i int, c types.Address) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			n.openBatchChannel(ctx, id, i, c, template, opts)
+		}(i, c)
+	}
+	wg.Wait()
+
+	lb := n.ledgerBatches
+	lb.mu.Lock()
+	lb.batches[id].Finished = time.Now()
+	lb.mu.Unlock()
+	n.logger.Info("Finished opening a batch of ledger channels", "batch", id)
+}
+
+// openBatchChannel opens the ith channel of the batch, with the counterparty, waiting up to opts.Timeout for it to be funded.
+func (n *Node) openBatchChannel(ctx context.Context, id string, i int, counterparty types.Address, template LedgerFundingTemplate, opts BatchOptions) {
+	o := template.outcome(*n.Address, counterparty)
+	response, err := n.CreateLedgerChannelWithOptions(ctx, counterparty, template.ChallengeDuration, o, CreateChannelOptions{Tags: template.Tags, Probe: opts.Probe})
+	if err != nil {
+		n.batchChannelFailed(id, i, err)
+		return
+	}
+	n.ledgerBatches.update(id, i, func(info *query.BatchChannelInfo) {
+		info.ChannelId = response.ChannelId
+		info.ObjectiveId = response.Id
+		info.Status = query.BatchChannelFunding
+	})
+
+	waitCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+	if err := n.WaitForObjective(waitCtx, response.Id); err != nil {
+		n.batchChannelFailed(id, i, err)
+		return
+	}
+	n.ledgerBatches.update(id, i, func(info *query.BatchChannelInfo) { info.Status = query.BatchChannelFunded })
+}
+
+// batchChannelFailed records that the ith channel of the batch could not be opened.
+func (n *Node) batchChannelFailed(id string, i int, err error) {
+	n.ledgerBatches.update(id, i, func(info *query.BatchChannelInfo) {
+		n.logger.Error("Could not open a ledger channel of a batch", "batch", id, "counterparty", info.Counterparty, "error", err)
+		info.Status = query.BatchChannelFailed
+		info.Error = err.Error()
+	})
+}
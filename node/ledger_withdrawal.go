@@ -0,0 +1,116 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"math/rand"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// WithdrawFromLedgerChannel withdraws amount of the ledger channel's asset from our balance in the ledger channel with
+// the given id on chain. The adjudicator only pays out of channels which have been concluded, so the ledger channel is
+// closed, paying both balances out, and replaced by a ledger channel with the same counterparty which allocates amount
+// less to us. Both participants deposit their balances into the new channel, which is funded once the old channel has
+// closed. Its tags are those of the old channel.
+//
+// Ledger channels which fund payment channels cannot be withdrawn from; their payment channels must be closed first.
+// The withdrawal is refused with ErrWithdrawalRefused if the ledger channel funds payment channels, or if our balance
+// does not cover amount.
+func (n *Node) WithdrawFromLedgerChannel(channelId types.Destination, amount *big.Int) (query.LedgerWithdrawalInfo, error) {
+	return n.WithdrawFromLedgerChannelContext(context.Background(), channelId, amount)
+}
+
+// WithdrawFromLedgerChannelContext is like WithdrawFromLedgerChannel, but abandons the request if ctx is done before the ledger channel's close is started.
+func (n *Node) WithdrawFromLedgerChannelContext(ctx context.Context, channelId types.Destination, amount *big.Int) (query.LedgerWithdrawalInfo, error) {
+	ledger, err := n.store.GetConsensusChannelById(channelId)
+	if err != nil {
+		return query.LedgerWithdrawalInfo{}, channelNotFound(channelId)
+	}
+	if len(ledger.FundingTargets()) != 0 || len(ledger.ProposalQueue()) != 0 {
+		return query.LedgerWithdrawalInfo{}, fmt.Errorf("%w: ledger channel %s funds payment channels", ErrWithdrawalRefused, channelId)
+	}
+	counterparty := ledger.Leader()
+	if ledger.IsLeader() {
+		counterparty = ledger.Follower()
+	}
+	vars := ledger.ConsensusVars()
+	remaining, err := withdrawnOutcome(vars.Outcome.AsOutcome(), *n.Address, counterparty, amount)
+	if err != nil {
+		return query.LedgerWithdrawalInfo{}, err
+	}
+
+	fundRequest := directfund.NewObjectiveRequest(counterparty, ledger.FixedPart().ChallengeDuration, remaining, rand.Uint64(), n.engine.GetConsensusAppAddress())
+	fundResponse := fundRequest.Response(*n.Address, n.chainId)
+	closeRequest := directdefund.NewObjectiveRequest(channelId)
+	closeId := closeRequest.Id(*n.Address, n.chainId)
+	tags, err := n.store.GetChannelTags(channelId)
+	if err != nil {
+		return query.LedgerWithdrawalInfo{}, err
+	}
+
+	if err := n.submitObjectiveRequest(ctx, closeRequest); err != nil {
+		return query.LedgerWithdrawalInfo{}, err
+	}
+	n.logger.Info("Withdrawing from ledger channel", "channel", channelId, "amount", amount, "replacement", fundResponse.ChannelId)
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopBackgroundTasks:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		n.reopenLedgerChannel(ctx, closeId, fundRequest, fundResponse.ChannelId, tags)
+	}()
+	return query.LedgerWithdrawalInfo{CloseObjectiveId: closeId, FundObjectiveId: fundResponse.Id, ChannelId: fundResponse.ChannelId}, nil
+}
+
+// reopenLedgerChannel funds the ledger channel which replaces the one being closed by the objective with id closeId,
+// once it has closed.
+func (n *Node) reopenLedgerChannel(ctx context.Context, closeId protocols.ObjectiveId, fundRequest directfund.ObjectiveRequest, channelId types.Destination, tags map[string]string) {
+	if err := n.WaitForObjective(ctx, closeId); err != nil {
+		n.logger.Error("Ledger withdrawal abandoned, as the ledger channel did not close", "objective", closeId, "error", err)
+		return
+	}
+	if err := n.submitTaggedObjectiveRequest(ctx, fundRequest, channelId, tags); err != nil {
+		n.logger.Error("Ledger withdrawal could not fund the replacement ledger channel", "channel", channelId, "error", err)
+	}
+}
+
+// withdrawnOutcome returns the outcome of a ledger channel between us and counterparty, proposed by us, which allocates
+// the assets of the ledger channel with the given outcome to each of us, less amount to us.
+func withdrawnOutcome(ledger outcome.Exit, us, counterparty types.Address, amount *big.Int) (outcome.Exit, error) {
+	if len(ledger) != 1 {
+		return nil, fmt.Errorf("%w: a ledger channel holds a single asset", ErrWithdrawalRefused)
+	}
+	ours := ledger.TotalAllocatedFor(types.AddressToDestination(us))[ledger[0].Asset]
+	if ours == nil || ours.Cmp(amount) < 0 {
+		return nil, fmt.Errorf("%w: our balance of %v does not cover %s", ErrWithdrawalRefused, ours, amount)
+	}
+	theirs := ledger.TotalAllocatedFor(types.AddressToDestination(counterparty))[ledger[0].Asset]
+	if theirs == nil {
+		theirs = new(big.Int)
+	}
+
+	// The proposer of a ledger channel is its leader, whose balance comes first
+	return outcome.Exit{{
+		Asset:         ledger[0].Asset,
+		AssetMetadata: ledger[0].AssetMetadata,
+		Allocations: outcome.Allocations{
+			{Destination: types.AddressToDestination(us), Amount: new(big.Int).Sub(ours, amount)},
+			{Destination: types.AddressToDestination(counterparty), Amount: new(big.Int).Set(theirs)},
+		},
+	}}, nil
+}
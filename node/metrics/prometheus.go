@@ -0,0 +1,122 @@
+// Package metrics exports the measurements of a node, such as the duration of objective cranks and the latency of
+// payments, to Prometheus.
+package metrics // import "github.com/statechannels/go-nitro/node/metrics"
+
+import (
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the histograms of durations: from half a millisecond, which a
+// crank may take, to about 16 seconds, which a payment routed over a slow network may.
+var durationBuckets = prometheus.ExponentialBuckets(0.0005, 2, 16)
+
+// Prometheus is a node.Metrics which exports each measurement as a Prometheus metric: counters as <namespace>_<name>_total,
+// durations as histograms in seconds named <namespace>_<name>_seconds, and gauges as <namespace>_<name>.
+//
+// A metric is registered the first time it is recorded, with the names of the labels it was recorded with. Later
+// measurements of the metric with other labels are dropped.
+type Prometheus struct {
+	namespace string
+	registry  *prometheus.Registry
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	histograms map[string]*prometheus.HistogramVec
+	gauges     map[string]*prometheus.GaugeVec
+}
+
+// NewPrometheus returns a Prometheus exporting metrics named within namespace, alongside the metrics of the process
+// and the Go runtime.
+func NewPrometheus(namespace string) *Prometheus {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	return &Prometheus{
+		namespace:  namespace,
+		registry:   registry,
+		counters:   make(map[string]*prometheus.CounterVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+		gauges:     make(map[string]*prometheus.GaugeVec),
+	}
+}
+
+// Handler serves the metrics in the Prometheus exposition format, for a /metrics endpoint.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *Prometheus) IncCounter(name string, labels map[string]string) {
+	p.mu.Lock()
+	c, ok := p.counters[name]
+	if !ok {
+		c = prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: p.namespace, Name: name + "_total"}, labelNames(labels))
+		p.register(name, c)
+		p.counters[name] = c
+	}
+	p.mu.Unlock()
+
+	if counter, err := c.GetMetricWith(labels); err != nil {
+		slog.Debug("Dropping measurement", "metric", name, "error", err)
+	} else {
+		counter.Inc()
+	}
+}
+
+func (p *Prometheus) RecordDuration(name string, d time.Duration, labels map[string]string) {
+	p.mu.Lock()
+	h, ok := p.histograms[name]
+	if !ok {
+		h = prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: p.namespace, Name: name + "_seconds", Buckets: durationBuckets}, labelNames(labels))
+		p.register(name, h)
+		p.histograms[name] = h
+	}
+	p.mu.Unlock()
+
+	if histogram, err := h.GetMetricWith(labels); err != nil {
+		slog.Debug("Dropping measurement", "metric", name, "error", err)
+	} else {
+		histogram.Observe(d.Seconds())
+	}
+}
+
+func (p *Prometheus) SetGauge(name string, value float64, labels map[string]string) {
+	p.mu.Lock()
+	g, ok := p.gauges[name]
+	if !ok {
+		g = prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: p.namespace, Name: name}, labelNames(labels))
+		p.register(name, g)
+		p.gauges[name] = g
+	}
+	p.mu.Unlock()
+
+	if gauge, err := g.GetMetricWith(labels); err != nil {
+		slog.Debug("Dropping measurement", "metric", name, "error", err)
+	} else {
+		gauge.Set(value)
+	}
+}
+
+// register registers the collector of the named metric. A collector which cannot be registered, such as one whose name
+// is taken by a metric of another type, is still returned to the caller, and its measurements are not exported.
+func (p *Prometheus) register(name string, c prometheus.Collector) {
+	if err := p.registry.Register(c); err != nil {
+		slog.Warn("Could not register metric", "metric", name, "error", err)
+	}
+}
+
+// labelNames returns the names of the labels, sorted so that they are the same for every measurement of a metric.
+func labelNames(labels map[string]string) []string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
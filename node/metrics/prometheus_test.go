@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheus(t *testing.T) {
+	p := NewPrometheus("nitro")
+	p.IncCounter("objectives_completed", map[string]string{"protocol": "DirectFunding"})
+	p.IncCounter("objectives_completed", map[string]string{"protocol": "DirectFunding"})
+	p.RecordDuration("objective_crank", 3*time.Millisecond, map[string]string{"protocol": "VirtualFund"})
+	p.SetGauge("queue_depth", 4, map[string]string{"queue": "messages"})
+	// A measurement with other labels than the metric was first recorded with is dropped
+	p.IncCounter("objectives_completed", map[string]string{"stage": "incoming"})
+
+	server := httptest.NewServer(p.Handler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		`nitro_objectives_completed_total{protocol="DirectFunding"} 2`,
+		`nitro_objective_crank_seconds_count{protocol="VirtualFund"} 1`,
+		`nitro_queue_depth{queue="messages"} 4`,
+		`go_goroutines`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected the metrics to contain %s, got\n%s", want, body)
+		}
+	}
+	if strings.Contains(string(body), "incoming") {
+		t.Errorf("expected the measurement with other labels to be dropped, got\n%s", body)
+	}
+}
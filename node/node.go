@@ -2,13 +2,20 @@
 package node // import "github.com/statechannels/go-nitro/node"
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/big"
 	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/node/engine"
 	"github.com/statechannels/go-nitro/node/engine/chainservice"
@@ -18,8 +25,11 @@ import (
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/challenge"
 	"github.com/statechannels/go-nitro/protocols/directdefund"
 	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/protocols/escrow"
+	"github.com/statechannels/go-nitro/protocols/ledgertopup"
 	"github.com/statechannels/go-nitro/protocols/virtualdefund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rand"
@@ -36,42 +46,189 @@ type Node struct {
 	completedObjectives       *safesync.Map[chan struct{}]
 	failedObjectives          chan protocols.ObjectiveId
 	receivedVouchers          chan payments.Voucher
+	voucherUpdates            chan payments.Voucher // This is only used by the RPC server
+	pendingQuotes             *safesync.Map[pendingQuote]
+	pendingProbes             *safesync.Map[chan protocols.Probe]
+	stopBackgroundTasks       chan struct{} // Closed to stop periodic tasks, such as balance snapshots
+	backgroundTasksWg         *sync.WaitGroup
+	balanceSnapshots          *balanceSnapshots
 	chainId                   *big.Int
 	store                     store.Store
-	vm                        *payments.VoucherManager
+	vm                        payments.VoucherManagerApi
+	paymentIds                *payments.PaymentIds
+	signer                    Signer
+	debugConfig               map[string]string
+	fiatPrices                *fiatPrices
+	tokenDecimals             *tokenDecimals
+	duplicateRequests         *duplicateRequests
+	channelCache              *channelCache
+	channelReuse              *channelReuse
+	windDown                  *windDown
+	ledgerBatches             *ledgerBatches
+	alerting                  *alerting
+	walletBalanceCheck        *walletBalanceCheck
+	capacityEvents            *capacityEvents
+	defaultHub                *defaultHub                  // nil unless EnableDefaultHub has been called
+	faultInjector             messageservice.FaultInjector // nil unless the message service supports fault injection
+	policy                    engine.AdjustablePolicy      // nil unless the policy maker's caps may be changed while the node runs
+	configMu                  *sync.Mutex                  // serializes changes to the configuration
+	logger                    *slog.Logger
+	metrics                   engine.Metrics
 }
 
-// New is the constructor for a Node. It accepts a messaging service, a chain service, and a store as injected dependencies.
-func New(messageService messageservice.MessageService, chainservice chainservice.ChainService, store store.Store, policymaker engine.PolicyMaker) Node {
+// New is the constructor for a Node. Its subsystems are supplied with options, and default to:
+//
+//   - an in-memory store, under a newly generated key (WithStore)
+//   - a simulated chain, shared by the nodes of the process (WithChainService)
+//   - a simulated network connecting the nodes of the process (WithMessageService)
+//   - a policy approving every objective (WithPolicy)
+//
+// so that New() alone constructs a node which can fund channels with, and pay, other such nodes.
+//
+// A nil chain service makes the node virtual-only. A virtual-only node has no connection to the chain, and only pays
+// and is paid through virtual channels funded by ledger channels which a hub funds externally. To propose channels
+// using particular app definitions, supply a chainservice.VirtualOnlyChainService instead.
+func New(opts ...Option) Node {
+	o := options{buffers: defaultBufferSizes, slowStore: DefaultSlowStoreThreshold}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	// The engine logs with its module logger, whose level may be set on its own, unless a logger is supplied
+	engineOpts := []engine.Option{}
+	if o.logger != nil {
+		engineOpts = append(engineOpts, engine.WithLogger(o.logger))
+	}
+	o.withDefaults()
+	engineOpts = append(engineOpts, engine.WithMetrics(o.metrics))
+	store, cs, messageService, policymaker := o.store, o.chainService, o.messageService, o.policy
+
 	n := Node{}
 	n.Address = store.GetAddress()
+	n.logger = o.logger
+	n.metrics = o.metrics
+	n.alerting = &alerting{logger: o.logger}
+	store = reportStoreErrors(store, n.alerting)
+	store = instrumentStore(store, n.metrics, o.slowStore)
 
-	chainId, err := chainservice.GetChainId()
+	if cs == nil {
+		cs = chainservice.NewVirtualOnlyChainService(big.NewInt(0), types.Address{}, types.Address{})
+	}
+	chainId, err := cs.GetChainId()
 	if err != nil {
 		panic(err)
 	}
 	n.chainId = chainId
 	n.store = store
-	n.vm = payments.NewVoucherManager(*store.GetAddress(), store)
+	vm := o.vm
+	if vm == nil {
+		sharded := payments.NewShardedVoucherManager(*store.GetAddress(), store)
+		if o.replayProtect {
+			sharded.EnableReplayProtection()
+		}
+		vm = sharded
+	}
+	n.vm = newSerializedReceipts(vm)
 
-	n.engine = engine.New(n.vm, messageService, chainservice, store, policymaker, n.handleEngineEvent)
+	n.paymentIds = payments.NewPaymentIds(store)
+
+	if o.disputes != nil {
+		engineOpts = append(engineOpts, engine.WithDisputeAdapter(o.disputes))
+	}
+	if o.eventLog != nil {
+		engineOpts = append(engineOpts, engine.WithEventLog(o.eventLog, o.eventLogCipher))
+	}
+	n.signer = o.signer
+	if n.signer == nil {
+		key := store.GetChannelSecretKey()
+		if key == nil {
+			panic("the store holds no channel secret key, so the node must be given a signer with WithSigner")
+		}
+		n.signer = engine.KeySigner(*key)
+	}
+	engineOpts = append(engineOpts, engine.WithSigner(n.signer))
+	n.engine = engine.New(n.vm, n.paymentIds, messageService, cs, store, policymaker, n.handleEngineEvent, engineOpts...)
 	n.completedObjectives = &safesync.Map[chan struct{}]{}
-	n.completedObjectivesForRPC = make(chan protocols.ObjectiveId, 100)
+	n.completedObjectivesForRPC = make(chan protocols.ObjectiveId, o.buffers.Objectives)
+
+	n.failedObjectives = make(chan protocols.ObjectiveId, o.buffers.Objectives)
+	n.receivedVouchers = make(chan payments.Voucher, o.buffers.Vouchers)
+	n.voucherUpdates = make(chan payments.Voucher, o.buffers.Vouchers)
+	n.pendingQuotes = &safesync.Map[pendingQuote]{}
+	n.pendingProbes = &safesync.Map[chan protocols.Probe]{}
+	n.stopBackgroundTasks = make(chan struct{})
+	n.backgroundTasksWg = &sync.WaitGroup{}
+	n.balanceSnapshots = &balanceSnapshots{}
+	n.fiatPrices = &fiatPrices{}
+	n.tokenDecimals = &tokenDecimals{decimals: map[types.Address]uint8{}}
+	n.duplicateRequests = &duplicateRequests{requests: make(map[requestKey]*request)}
+	n.channelCache = newChannelCache()
+	n.channelReuse = &channelReuse{pending: make(map[reuseKey]pendingChannel)}
+	n.windDown = &windDown{}
+	n.ledgerBatches = &ledgerBatches{batches: map[string]*query.LedgerChannelBatchInfo{}}
+	n.walletBalanceCheck = &walletBalanceCheck{}
+	n.capacityEvents = &capacityEvents{events: make(chan query.LedgerCapacityInfo, o.buffers.Objectives)}
+	if wallet, ok := cs.(chainservice.FundingWallet); ok {
+		n.walletBalanceCheck.wallet = wallet
+	}
+	if tm, ok := cs.(chainservice.TokenMetadata); ok {
+		n.tokenDecimals.metadata = tm
+	}
+	if cm, ok := cs.(chainservice.ConnectionMonitor); ok {
+		n.alerting.chain = cm
+	}
 
-	n.failedObjectives = make(chan protocols.ObjectiveId, 100)
-	// Using a larger buffer since payments can be sent frequently.
-	n.receivedVouchers = make(chan payments.Voucher, 1000)
+	if fi, ok := messageService.(messageservice.FaultInjector); ok {
+		n.faultInjector = fi
+	}
+	if bs, ok := messageService.(messageservice.BlocklistingService); ok {
+		bs.SetBlocklist(n.engine.Blocklist())
+	}
+
+	if ap, ok := policymaker.(engine.AdjustablePolicy); ok {
+		n.policy = ap
+	}
+	n.configMu = &sync.Mutex{}
+	if err := n.restoreConfig(); err != nil {
+		n.logger.Error("Could not restore configuration changes", "error", err)
+	}
 
 	n.channelNotifier = notifier.NewChannelNotifier(store, n.vm)
 
+	if err := n.resumeSubscriptions(); err != nil {
+		n.logger.Error("Could not resume subscriptions", "error", err)
+	}
+	if err := n.resumeEscrows(); err != nil {
+		n.logger.Error("Could not resume escrows", "error", err)
+	}
+
 	return n
 }
 
+// instrumentStore returns a store which makes its operations to s, reporting their durations to metrics and logging
+// those which take slowThreshold or longer.
+func instrumentStore(s store.Store, metrics Metrics, slowThreshold time.Duration) store.Store {
+	return store.NewInstrumentedStore(s, metrics, slowThreshold)
+}
+
+// objectiveProtocol returns the protocol of the objective, the prefix of its id, with which its metrics are labelled.
+func objectiveProtocol(id protocols.ObjectiveId) string {
+	protocol, _, _ := strings.Cut(string(id), "-")
+	return protocol
+}
+
 // handleEngineEvents dispatches events to the necessary node chan.
 func (n *Node) handleEngineEvent(update engine.EngineEvent) {
+	n.channelCache.invalidateUpdated(update)
+	n.alertChallenges(update.ChallengedChannels)
+
 	for _, completed := range update.CompletedObjectives {
 		d, _ := n.completedObjectives.LoadOrStore(string(completed.Id()), make(chan struct{}))
 		close(d)
+		n.metrics.IncCounter(ObjectivesCompletedMetric, map[string]string{"protocol": objectiveProtocol(completed.Id())})
+
+		if e, ok := completed.(*escrow.Objective); ok && e.Releases() {
+			n.releaseEscrow(e.Id(), e.Terms)
+		}
 
 		// use a nonblocking send to the RPC Client in case no one is listening
 		select {
@@ -81,17 +238,51 @@ func (n *Node) handleEngineEvent(update engine.EngineEvent) {
 	}
 
 	for _, erred := range update.FailedObjectives {
+		n.metrics.IncCounter(ObjectivesFailedMetric, map[string]string{"protocol": objectiveProtocol(erred)})
 		n.failedObjectives <- erred
 	}
 
 	for _, payment := range update.ReceivedVouchers {
+		n.metrics.IncCounter(VouchersReceivedMetric, nil)
 		n.receivedVouchers <- payment
+
+		// use a nonblocking send to the RPC Client in case no one is listening
+		select {
+		case n.voucherUpdates <- payment:
+		default:
+		}
+	}
+
+	for _, quote := range update.ReceivedQuotes {
+		if waiting, ok := n.pendingQuotes.Load(strconv.FormatUint(quote.RequestId, 10)); ok {
+			// A quote is only accepted from the intermediary it was requested from
+			if quote.Intermediary != waiting.intermediary {
+				n.logger.Warn("Dropping quote from a peer it was not requested from", "from", quote.Intermediary, "intermediary", waiting.intermediary, "request-id", quote.RequestId)
+				continue
+			}
+			// use a nonblocking send in case a quote for this request has already been received
+			select {
+			case waiting.response <- quote:
+			default:
+			}
+		}
+	}
+
+	for _, probe := range update.ReceivedProbes {
+		if waiting, ok := n.pendingProbes.Load(strconv.FormatUint(probe.RequestId, 10)); ok {
+			// use a nonblocking send in case a probe for this request has already been received
+			select {
+			case waiting <- probe:
+			default:
+			}
+		}
 	}
 
 	for _, updated := range update.LedgerChannelUpdates {
 
 		err := n.channelNotifier.NotifyLedgerUpdated(updated)
 		n.handleError(err)
+		n.checkCapacity(updated.ID)
 	}
 	for _, updated := range update.PaymentChannelUpdates {
 
@@ -102,6 +293,11 @@ func (n *Node) handleEngineEvent(update engine.EngineEvent) {
 
 // Begin API
 
+// ChainId returns the id of the chain the node uses
+func (n *Node) ChainId() *big.Int {
+	return new(big.Int).Set(n.chainId)
+}
+
 // Version returns the go-nitro version
 func (n *Node) Version() string {
 	info, _ := debug.ReadBuildInfo()
@@ -166,34 +362,160 @@ func (n *Node) ReceivedVouchers() <-chan payments.Voucher {
 	return n.receivedVouchers
 }
 
+// VoucherUpdates returns a chan that receives a voucher, with its context, every time we receive a payment voucher.
+// Vouchers are dropped if the chan is not drained. Not suitable for multiple subscribers.
+func (n *Node) VoucherUpdates() <-chan payments.Voucher {
+	return n.voucherUpdates
+}
+
 // CreateVoucher creates and returns a voucher for the given channelId which increments the redeemable balance by amount.
 // It is the responsibility of the caller to send the voucher to the payee.
 func (n *Node) CreateVoucher(channelId types.Destination, amount *big.Int) (payments.Voucher, error) {
-	voucher, err := n.vm.Pay(channelId, amount, *n.store.GetChannelSecretKey())
+	return n.createVoucher(channelId, amount, time.Time{})
+}
+
+// createVoucher is like CreateVoucher, but the voucher expires at expiry, unless it is zero.
+func (n *Node) createVoucher(channelId types.Destination, amount *big.Int, expiry time.Time) (payments.Voucher, error) {
+	voucher, err := n.vm.PayWithExpiry(channelId, amount, expiry, n.signer)
+	if errors.Is(err, payments.ErrChannelNotRegistered) {
+		return payments.Voucher{}, channelNotFound(channelId)
+	}
 	if err != nil {
 		return payments.Voucher{}, err
 	}
-	info, err := n.GetPaymentChannel(channelId)
+	return voucher, n.recordVoucherCreated(channelId, amount)
+}
+
+// CreateVoucherWithId is like CreateVoucher, but creates a voucher at most once for the given payment id: if a voucher
+// was already created on the channel with the same id, that voucher is returned instead. This lets applications retry
+// payments (after a timeout, say) without paying twice. An empty id is ignored.
+func (n *Node) CreateVoucherWithId(channelId types.Destination, amount *big.Int, paymentId string) (payments.Voucher, error) {
+	return n.createVoucherWithId(channelId, amount, paymentId, time.Time{})
+}
+
+// createVoucherWithId is like CreateVoucherWithId, but the voucher expires at expiry, unless it is zero. A voucher
+// created before for the payment id is signed again if its expiry differs.
+func (n *Node) createVoucherWithId(channelId types.Destination, amount *big.Int, paymentId string, expiry time.Time) (payments.Voucher, error) {
+	if paymentId == "" {
+		return n.createVoucher(channelId, amount, expiry)
+	}
+	voucher, paid, err := n.paymentIds.PayWithExpiry(n.vm, channelId, amount, paymentId, expiry, n.signer)
+	if errors.Is(err, payments.ErrChannelNotRegistered) {
+		return payments.Voucher{}, channelNotFound(channelId)
+	}
 	if err != nil {
 		return voucher, err
 	}
-	err = n.channelNotifier.NotifyPaymentUpdated(info)
+	if !paid {
+		if !expiry.IsZero() && voucher.Expiry != uint64(expiry.Unix()) {
+			return voucher, voucher.SetExpiry(expiry, n.signer, *n.Address)
+		}
+		return voucher, nil
+	}
+	return voucher, n.recordVoucherCreated(channelId, amount)
+}
+
+// CreateVoucherWithExpiry is like CreateVoucherWithId, but the voucher is refused by the payee after expiry. A voucher
+// returned again for a repeated payment id is given the new expiry. Expiring vouchers cannot be used to dispute the
+// channel on chain.
+func (n *Node) CreateVoucherWithExpiry(channelId types.Destination, amount *big.Int, paymentId string, expiry time.Time) (payments.Voucher, error) {
+	return n.createVoucherWithId(channelId, amount, paymentId, expiry)
+}
+
+// recordVoucherCreated records the payment of amount on the channel, and notifies listeners of the channel's update.
+func (n *Node) recordVoucherCreated(channelId types.Destination, amount *big.Int) error {
+	n.channelCache.invalidate(channelId)
+	err := n.store.AppendActivity(store.ActivityRecord{Time: time.Now(), Kind: store.PaymentSent, ChannelId: channelId, Amount: amount})
+	if err != nil {
+		return err
+	}
+	info, err := n.GetPaymentChannel(channelId)
 	if err != nil {
-		return voucher, err
+		return err
 	}
-	return voucher, nil
+	return n.channelNotifier.NotifyPaymentUpdated(info)
 }
 
 // ReceiveVoucher receives a voucher and returns the amount that was paid.
 // It can be used to add a voucher that was sent outside of the go-nitro system.
+//
+// It is safe to call concurrently, including for the same channel, as when several payment proxies share the node: the
+// vouchers of a channel are received one at a time, though concurrent calls are not necessarily received in the order
+// they are made. Since each voucher carries the total paid on the channel, the order does not change the Total. The
+// summary's Delta is the exact amount by which the caller's own voucher increased the amount received, so a voucher
+// received by several callers is credited only to whichever of them is received first, and the Deltas of all the calls
+// on a channel sum to its Total.
 func (c *Node) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummary, error) {
 	total, delta, err := c.vm.Receive(v)
+	c.channelCache.invalidate(v.ChannelId)
+	if errors.Is(err, payments.ErrChannelNotRegistered) {
+		return payments.ReceiveVoucherSummary{}, channelNotFound(v.ChannelId)
+	}
+	if err == nil && delta.Sign() > 0 {
+		err = c.store.AppendActivity(store.ActivityRecord{Time: time.Now(), Kind: store.PaymentReceived, ChannelId: v.ChannelId, Amount: delta})
+	}
 	return payments.ReceiveVoucherSummary{Total: total, Delta: delta}, err
 }
 
+// submitObjectiveRequest sends the objective request to the engine and waits for the engine to start the objective.
+// If ctx is done before the engine commits the objective, the objective is abandoned and ctx.Err() is returned.
+func (n *Node) submitObjectiveRequest(ctx context.Context, or protocols.ObjectiveRequest) error {
+	select {
+	case n.engine.ObjectiveRequestsFromAPI <- engine.NewAPIRequest(ctx, or):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	or.WaitForObjectiveToStart()
+
+	if ctx.Err() != nil {
+		// The engine may have committed the objective before noticing the cancellation
+		if _, err := query.GetObjectiveStatus(or.Id(*n.Address, n.chainId), n.store); err != nil {
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
 // CreatePaymentChannel creates a virtual channel with the counterParty using ledger channels
 // with the supplied intermediaries.
 func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	return n.CreatePaymentChannelContext(context.Background(), Intermediaries, CounterParty, ChallengeDuration, Outcome)
+}
+
+// CreatePaymentChannelContext is like CreatePaymentChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) CreatePaymentChannelContext(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	return n.CreatePaymentChannelWithOptions(ctx, Intermediaries, CounterParty, ChallengeDuration, Outcome, CreateChannelOptions{})
+}
+
+// CreatePaymentChannelForced is like CreatePaymentChannelContext, but creates a channel even if an equivalent request
+// was made recently (see EnableDuplicateRequestDetection).
+func (n *Node) CreatePaymentChannelForced(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	return n.CreatePaymentChannelWithOptions(ctx, Intermediaries, CounterParty, ChallengeDuration, Outcome, CreateChannelOptions{Force: true})
+}
+
+// CreatePaymentChannelWithOptions is like CreatePaymentChannelContext, with the given options.
+func (n *Node) CreatePaymentChannelWithOptions(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit, opts CreateChannelOptions) (virtualfund.ObjectiveResponse, error) {
+	if err := validateChannelTags(opts.Tags); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	if err := validateChannelMemo(opts.Memo); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	key, err := newRequestKey("payment", CounterParty, Outcome, opts.Tags)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	return createOnce(ctx, n, key, opts.Force, func() (virtualfund.ObjectiveResponse, protocols.ObjectiveId, error) {
+		response, err := n.submitPaymentChannel(ctx, Intermediaries, CounterParty, ChallengeDuration, Outcome, opts)
+		return response, response.Id, err
+	})
+}
+
+func (n *Node) submitPaymentChannel(ctx context.Context, Intermediaries []types.Address, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit, opts CreateChannelOptions) (virtualfund.ObjectiveResponse, error) {
+	Intermediaries, err := n.routeThroughDefaultHub(ctx, Intermediaries, CounterParty)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
 	objectiveRequest := virtualfund.NewObjectiveRequest(
 		Intermediaries,
 		CounterParty,
@@ -202,27 +524,87 @@ func (n *Node) CreatePaymentChannel(Intermediaries []types.Address, CounterParty
 		rand.Uint64(),
 		n.engine.GetVirtualPaymentAppAddress(),
 	)
+	objectiveRequest.Memo = opts.Memo
+	objectiveRequest.Fee = opts.routingFee
+	response := objectiveRequest.Response(*n.Address)
+	if err := n.engine.CheckOutcomeAssets(response.ChannelId, Outcome); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	if err := n.engine.Blocklist().CheckParticipants(append([]types.Address{CounterParty}, Intermediaries...)); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	if err := n.engine.CheckFundingAllowed(); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	if opts.Probe {
+		if err := n.probeOutcome(ctx, CounterParty, Outcome); err != nil {
+			return virtualfund.ObjectiveResponse{}, err
+		}
+	}
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-
-	objectiveRequest.WaitForObjectiveToStart()
-	return objectiveRequest.Response(*n.Address), nil
+	// Send the event to the engine, with the channel tagged so that every notification about it includes the tags
+	if err := n.submitTaggedObjectiveRequest(ctx, objectiveRequest, response.ChannelId, opts.Tags); err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	return response, nil
 }
 
 // ClosePaymentChannel attempts to close and defund the given virtually funded channel.
 func (n *Node) ClosePaymentChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	return n.ClosePaymentChannelContext(context.Background(), channelId)
+}
+
+// ClosePaymentChannelContext is like ClosePaymentChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) ClosePaymentChannelContext(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
+	if _, ok := n.store.GetChannelById(channelId); !ok {
+		return "", channelNotFound(channelId)
+	}
 	objectiveRequest := virtualdefund.NewObjectiveRequest(channelId)
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.submitObjectiveRequest(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
 // CreateLedgerChannel creates a directly funded ledger channel with the given counterparty.
 // The channel will run under full consensus rules (it is not possible to provide a custom AppDefinition or AppData).
 func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	return n.CreateLedgerChannelContext(context.Background(), Counterparty, ChallengeDuration, outcome)
+}
+
+// CreateLedgerChannelContext is like CreateLedgerChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) CreateLedgerChannelContext(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	return n.CreateLedgerChannelWithOptions(ctx, Counterparty, ChallengeDuration, outcome, CreateChannelOptions{})
+}
+
+// CreateLedgerChannelForced is like CreateLedgerChannelContext, but does not return the response to an equivalent
+// request made recently (see EnableDuplicateRequestDetection). It still fails if a ledger channel with the
+// counterparty exists.
+func (n *Node) CreateLedgerChannelForced(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
+	return n.CreateLedgerChannelWithOptions(ctx, Counterparty, ChallengeDuration, outcome, CreateChannelOptions{Force: true})
+}
+
+// CreateLedgerChannelWithOptions is like CreateLedgerChannelContext, with the given options.
+func (n *Node) CreateLedgerChannelWithOptions(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, opts CreateChannelOptions) (directfund.ObjectiveResponse, error) {
+	if err := validateChannelTags(opts.Tags); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	if err := validateChannelMemo(opts.Memo); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	key, err := newRequestKey("ledger", Counterparty, outcome, opts.Tags)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	return createOnce(ctx, n, key, opts.Force, func() (directfund.ObjectiveResponse, protocols.ObjectiveId, error) {
+		response, err := n.submitLedgerChannel(ctx, Counterparty, ChallengeDuration, outcome, opts)
+		return response, response.Id, err
+	})
+}
+
+func (n *Node) submitLedgerChannel(ctx context.Context, Counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, opts CreateChannelOptions) (directfund.ObjectiveResponse, error) {
 	objectiveRequest := directfund.NewObjectiveRequest(
 		Counterparty,
 		ChallengeDuration,
@@ -231,55 +613,396 @@ func (n *Node) CreateLedgerChannel(Counterparty types.Address, ChallengeDuration
 		n.engine.GetConsensusAppAddress(),
 		// Appdata implicitly zero
 	)
+	objectiveRequest.Memo = opts.Memo
 
 	// Check store to see if there is an existing channel with this counterparty
 	channelExists, err := directfund.ChannelsExistWithCounterparty(Counterparty, n.store.GetChannelsByParticipant, n.store.GetConsensusChannel)
 	if err != nil {
-		slog.Error("direct fund error", "error", err)
+		n.logger.Error("direct fund error", "error", err)
 		return directfund.ObjectiveResponse{}, fmt.Errorf("counterparty check failed: %w", err)
 	}
 	if channelExists {
-		slog.Error("directfund: channel already exists", "error", directfund.ErrLedgerChannelExists)
+		n.logger.Error("directfund: channel already exists", "error", directfund.ErrLedgerChannelExists)
 
-		return directfund.ObjectiveResponse{}, fmt.Errorf("counterparty %s: %w", Counterparty, directfund.ErrLedgerChannelExists)
+		return directfund.ObjectiveResponse{}, fmt.Errorf("counterparty %s: %w", Counterparty, ErrLedgerChannelExists)
 	}
 
-	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
-	return objectiveRequest.Response(*n.Address, n.chainId), nil
+	response := objectiveRequest.Response(*n.Address, n.chainId)
+	if err := n.engine.CheckOutcomeAssets(response.ChannelId, outcome); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	if err := n.engine.Blocklist().CheckParticipants([]types.Address{Counterparty}); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	if err := n.engine.CheckFundingAllowed(); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+
+	if err := n.checkWalletBalance(ctx, outcome); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	if opts.Probe {
+		if err := n.probeOutcome(ctx, Counterparty, outcome); err != nil {
+			return directfund.ObjectiveResponse{}, err
+		}
+	}
+
+	// Send the event to the engine, with the channel tagged so that every notification about it includes the tags
+	if err := n.submitTaggedObjectiveRequest(ctx, objectiveRequest, response.ChannelId, opts.Tags); err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	return response, nil
 }
 
 // CloseLedgerChannel attempts to close and defund the given directly funded channel.
 func (n *Node) CloseLedgerChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	return n.CloseLedgerChannelContext(context.Background(), channelId)
+}
+
+// CloseLedgerChannelContext is like CloseLedgerChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) CloseLedgerChannelContext(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
+	if !n.channelExists(channelId) {
+		return "", channelNotFound(channelId)
+	}
 	objectiveRequest := directdefund.NewObjectiveRequest(channelId)
 
 	// Send the event to the engine
-	n.engine.ObjectiveRequestsFromAPI <- objectiveRequest
-	objectiveRequest.WaitForObjectiveToStart()
+	if err := n.submitObjectiveRequest(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// AddFundsToLedgerChannel deposits amount of the ledger channel's asset into the ledger channel with the given id on
+// chain, and credits it to us in the channel's outcome once the counterparty has seen the deposit. This adds capacity
+// to the channel without closing and reopening it.
+//
+// The ledger channel cannot fund new virtual channels while the objective is in progress, and is refused if it has
+// proposals waiting to be countersigned.
+func (n *Node) AddFundsToLedgerChannel(channelId types.Destination, amount *big.Int) (protocols.ObjectiveId, error) {
+	return n.AddFundsToLedgerChannelContext(context.Background(), channelId, amount)
+}
+
+// AddFundsToLedgerChannelContext is like AddFundsToLedgerChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) AddFundsToLedgerChannelContext(ctx context.Context, channelId types.Destination, amount *big.Int) (protocols.ObjectiveId, error) {
+	if !n.channelExists(channelId) {
+		return "", channelNotFound(channelId)
+	}
+	if err := n.engine.CheckFundingAllowed(); err != nil {
+		return "", err
+	}
+	objectiveRequest := ledgertopup.NewObjectiveRequest(channelId, amount, rand.Uint64())
+
+	// Send the event to the engine
+	if err := n.submitObjectiveRequest(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
 	return objectiveRequest.Id(*n.Address, n.chainId), nil
 }
 
+// ChallengeChannel exits the ledger channel with the given id unilaterally, for when the counterparty is unresponsive.
+// The channel is challenged on chain with its latest supported state through the adjudicator's ForceMove protocol, and
+// its funds are withdrawn once the challenge times out. The objective completes without withdrawing them if the
+// counterparty answers the challenge, after which the channel may be closed with CloseLedgerChannel.
+//
+// Ledger channels which fund virtual channels cannot be challenged; their virtual channels must be closed first.
+func (n *Node) ChallengeChannel(channelId types.Destination) (protocols.ObjectiveId, error) {
+	return n.ChallengeChannelContext(context.Background(), channelId)
+}
+
+// ChallengeChannelContext is like ChallengeChannel, but abandons the request if ctx is done before the objective is started.
+func (n *Node) ChallengeChannelContext(ctx context.Context, channelId types.Destination) (protocols.ObjectiveId, error) {
+	if !n.channelExists(channelId) {
+		return "", channelNotFound(channelId)
+	}
+	objectiveRequest := challenge.NewObjectiveRequest(channelId)
+
+	// Send the event to the engine
+	if err := n.submitObjectiveRequest(ctx, objectiveRequest); err != nil {
+		return "", err
+	}
+	return objectiveRequest.Id(*n.Address, n.chainId), nil
+}
+
+// CreateObjective starts an objective of a type registered with protocols.RegisterObjectiveType.
+func (n *Node) CreateObjective(ctx context.Context, request protocols.ObjectiveRequest) (protocols.ObjectiveId, error) {
+	id := request.Id(*n.Address, n.chainId)
+	if _, ok := protocols.LookupObjectiveType(id); !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownObjectiveType, id)
+	}
+	if err := n.submitObjectiveRequest(ctx, request); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
 // Pay will send a signed voucher to the payee that they can redeem for the given amount.
 func (n *Node) Pay(channelId types.Destination, amount *big.Int) {
+	_ = n.PayContext(context.Background(), channelId, amount)
+}
+
+// PayContext is like Pay, but abandons the payment if ctx is done before the engine handles it.
+func (n *Node) PayContext(ctx context.Context, channelId types.Destination, amount *big.Int) error {
+	return n.PayWithOptions(ctx, channelId, amount, PayOptions{})
+}
+
+// PayWithId is like PayContext, but makes the payment at most once for the given payment id: if a payment was already
+// made on the channel with the same id, its voucher is resent to the payee instead. This lets applications retry
+// payments (after a timeout, say) without paying twice. An empty id is ignored.
+func (n *Node) PayWithId(ctx context.Context, channelId types.Destination, amount *big.Int, paymentId string) error {
+	return n.PayWithOptions(ctx, channelId, amount, PayOptions{PaymentId: paymentId})
+}
+
+// PayOptions configure a payment.
+type PayOptions struct {
+	// PaymentId, if set, makes the payment at most once (see PayWithId)
+	PaymentId string
+	// Context is carried alongside the voucher, so that the payee's application can correlate the payment with, say,
+	// the request it pays for. It is at most payments.MaxVoucherContextLength bytes long.
+	Context string
+	// Expiry, if set, is the time after which the payee refuses the voucher. It is signed over with second precision.
+	// Expiring vouchers cannot be used to dispute the channel on chain.
+	Expiry time.Time
+}
+
+// PayWithOptions is like PayContext, with the payment configured by opts.
+func (n *Node) PayWithOptions(ctx context.Context, channelId types.Destination, amount *big.Int, opts PayOptions) error {
+	if len(opts.Context) > payments.MaxVoucherContextLength {
+		return fmt.Errorf("voucher context of %d bytes exceeds the limit of %d bytes", len(opts.Context), payments.MaxVoucherContextLength)
+	}
+	paymentId := opts.PaymentId
+	if !n.vm.ChannelRegistered(channelId) {
+		return channelNotFound(channelId)
+	}
+	repeated := false
+	if paymentId != "" {
+		_, ok, err := n.store.GetPaymentRecord(channelId, paymentId)
+		if err != nil {
+			return err
+		}
+		repeated = ok
+	}
+	if !repeated {
+		remaining, err := n.vm.Remaining(channelId)
+		if err != nil {
+			return err
+		}
+		if types.Gt(amount, remaining) {
+			return fmt.Errorf("paying %s with %s remaining in channel %s: %w", amount, remaining, channelId, ErrInsufficientFunds)
+		}
+	}
+
 	// Send the event to the engine
-	n.engine.PaymentRequestsFromAPI <- engine.PaymentRequest{ChannelId: channelId, Amount: amount}
+	request := engine.PaymentRequest{ChannelId: channelId, Amount: amount, PaymentId: paymentId, Context: opts.Context, Expiry: opts.Expiry, Enqueued: time.Now(), CorrelationId: logging.CorrelationId(ctx)}
+	select {
+	case n.engine.PaymentRequestsFromAPI <- engine.NewAPIRequest(ctx, request):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitForObjective blocks until the objective with the given id is complete, or ctx is done.
+// It returns ErrObjectiveRejected if the objective was rejected rather than completed successfully.
+func (n *Node) WaitForObjective(ctx context.Context, id protocols.ObjectiveId) error {
+	select {
+	case <-n.ObjectiveCompleteChan(id):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	status, err := query.GetObjectiveStatus(id, n.store)
+	if err != nil {
+		return err
+	}
+	if status == protocols.Rejected {
+		return fmt.Errorf("objective %s: %w", id, ErrObjectiveRejected)
+	}
+	return nil
+}
+
+// QuoteTimeout is how long GetQuote waits for an intermediary to respond.
+const QuoteTimeout = 10 * time.Second
+
+// GetQuote asks the intermediary what it would charge to route a virtual channel, funded with amount of asset,
+// to counterParty. It blocks until the intermediary responds or QuoteTimeout elapses.
+func (n *Node) GetQuote(intermediary types.Address, counterParty types.Address, asset types.Address, amount *big.Int) (protocols.Quote, error) {
+	return n.GetQuoteContext(context.Background(), intermediary, counterParty, asset, amount)
+}
+
+// GetQuoteContext is like GetQuote, but gives up waiting for the quote if ctx is done.
+func (n *Node) GetQuoteContext(ctx context.Context, intermediary types.Address, counterParty types.Address, asset types.Address, amount *big.Int) (protocols.Quote, error) {
+	request := protocols.QuoteRequest{Id: rand.Uint64(), CounterParty: counterParty, Asset: asset, Amount: amount}
+	key := strconv.FormatUint(request.Id, 10)
+
+	response := make(chan protocols.Quote, 1)
+	n.pendingQuotes.Store(key, pendingQuote{intermediary: intermediary, response: response})
+	defer n.pendingQuotes.Delete(key)
+
+	// Send the event to the engine
+	select {
+	case n.engine.QuoteRequestsFromAPI <- engine.NewAPIRequest(ctx, engine.QuoteRequest{Intermediary: intermediary, Request: request}):
+	case <-ctx.Done():
+		return protocols.Quote{}, ctx.Err()
+	}
+
+	select {
+	case quote := <-response:
+		return quote, nil
+	case <-ctx.Done():
+		return protocols.Quote{}, ctx.Err()
+	case <-time.After(QuoteTimeout):
+		return protocols.Quote{}, fmt.Errorf("timed out waiting for a quote from %s: %w", intermediary, ErrPeerUnreachable)
+	}
+}
+
+// pendingQuote is a quote request awaiting a response from the intermediary it was sent to.
+type pendingQuote struct {
+	intermediary types.Address
+	response     chan protocols.Quote
 }
 
 // GetPaymentChannel returns the payment channel with the given id.
 // If no ledger channel exists with the given id an error is returned.
 func (n *Node) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo, error) {
-	return query.GetPaymentChannelInfo(id, n.store, n.vm)
+	info, err := n.channelCache.get(id, func() (query.PaymentChannelInfo, error) {
+		if _, ok := n.store.GetChannelById(id); !ok {
+			return query.PaymentChannelInfo{}, channelNotFound(id)
+		}
+		return query.GetPaymentChannelInfo(id, n.store, n.vm)
+	})
+	if err != nil {
+		return query.PaymentChannelInfo{}, err
+	}
+	return n.fiatPrices.valuePaymentChannel(n.tokenDecimals.describePaymentChannel(info)), nil
 }
 
 // GetPaymentChannelsByLedger returns all active payment channels that are funded by the given ledger channel.
 func (n *Node) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
-	return query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
+	infos, err := query.GetPaymentChannelsByLedger(ledgerId, n.store, n.vm)
+	for i := range infos {
+		infos[i] = n.fiatPrices.valuePaymentChannel(n.tokenDecimals.describePaymentChannel(infos[i]))
+	}
+	return infos, err
 }
 
 // GetAllLedgerChannels returns all ledger channels.
 func (n *Node) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
-	return query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress())
+	infos, err := query.GetAllLedgerChannels(n.store, n.engine.GetConsensusAppAddress())
+	for i := range infos {
+		infos[i] = n.fiatPrices.valueLedgerChannel(infos[i])
+	}
+	return infos, err
+}
+
+// ListLedgerChannels returns a page of the ledger channels selected by the filter, ordered by id.
+func (n *Node) ListLedgerChannels(filter query.ChannelFilter, req query.PageRequest) (query.LedgerChannelPage, error) {
+	infos, err := n.GetAllLedgerChannels()
+	if err != nil {
+		return query.LedgerChannelPage{}, err
+	}
+	return query.PageLedgerChannels(infos, filter, req), nil
+}
+
+// ListPaymentChannelsByLedger returns a page of the active payment channels funded by the given ledger channel which are
+// selected by the filter, ordered by id.
+func (n *Node) ListPaymentChannelsByLedger(ledgerId types.Destination, filter query.ChannelFilter, req query.PageRequest) (query.PaymentChannelPage, error) {
+	infos, err := n.GetPaymentChannelsByLedger(ledgerId)
+	if err != nil {
+		return query.PaymentChannelPage{}, err
+	}
+	return query.PagePaymentChannels(infos, filter, req), nil
+}
+
+// GetSignedState returns the latest supported signed state of the channel with the given id,
+// including an abi encoding of the state that is ready for on-chain submission.
+func (n *Node) GetSignedState(id types.Destination) (query.SignedStateInfo, error) {
+	if !n.channelExists(id) {
+		return query.SignedStateInfo{}, channelNotFound(id)
+	}
+	return query.GetSignedStateInfo(id, n.store)
+}
+
+// GetChannelSnapshot returns a signed snapshot of the payment channel with the given id, containing its latest supported
+// state and the largest voucher paid on it. The snapshot can be verified by a third party with ChannelSnapshot.Verify.
+func (n *Node) GetChannelSnapshot(id types.Destination) (payments.ChannelSnapshot, error) {
+	c, ok := n.store.GetChannelById(id)
+	if !ok {
+		return payments.ChannelSnapshot{}, channelNotFound(id)
+	}
+	ss, err := c.LatestSupportedSignedState()
+	if err != nil {
+		return payments.ChannelSnapshot{}, fmt.Errorf("channel %s: %w", id, err)
+	}
+	vInfo, err := n.store.GetVoucherInfo(id)
+	if err != nil {
+		return payments.ChannelSnapshot{}, channelNotFound(id)
+	}
+	return payments.NewChannelSnapshot(ss, vInfo.LargestVoucher, time.Now(), n.signer)
+}
+
+// balanceSnapshots tracks the periodic recording of balance snapshots started by EnableBalanceSnapshots.
+type balanceSnapshots struct {
+	mu   sync.Mutex
+	stop chan struct{} // closed to stop the recording, or nil if it has not been started
+}
+
+// EnableBalanceSnapshots starts recording a snapshot of the balance of every open channel once every interval,
+// so that historic balances can later be retrieved with GetBalanceHistory. Calling it again replaces the interval.
+func (n *Node) EnableBalanceSnapshots(interval time.Duration) {
+	bs := n.balanceSnapshots
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	if bs.stop != nil {
+		close(bs.stop)
+	}
+	stop := make(chan struct{})
+	bs.stop = stop
+
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				if err := n.SnapshotBalances(now); err != nil {
+					n.logger.Error("failed to snapshot balances", "error", err)
+				}
+			case <-stop:
+				return
+			case <-n.stopBackgroundTasks:
+				return
+			}
+		}
+	}()
+}
+
+// SnapshotBalances records a snapshot of the balance of every open channel, timestamped with the given time.
+func (n *Node) SnapshotBalances(at time.Time) error {
+	snapshots, err := query.CurrentBalanceSnapshots(n.store, n.vm, n.engine.GetConsensusAppAddress(), n.engine.GetVirtualPaymentAppAddress(), at)
+	if err != nil {
+		return err
+	}
+	for _, bs := range snapshots {
+		if err := n.store.SetBalanceSnapshot(bs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetBalanceHistory returns the balance snapshots of the given channel taken at or after from and before to.
+// If id is the zero destination, snapshots of every channel are returned.
+func (n *Node) GetBalanceHistory(id types.Destination, from, to time.Time) ([]query.BalanceSnapshotInfo, error) {
+	return query.GetBalanceHistory(id, from, to, n.store)
+}
+
+// ExportActivity writes a record of every payment, and every channel opening and closing, made at or after from and before to,
+// to w in the given format (query.CsvExportFormat or query.JsonlExportFormat).
+func (n *Node) ExportActivity(w io.Writer, format string, from, to time.Time) error {
+	return query.ExportActivity(n.store, w, format, from, to)
 }
 
 // GetLastBlockNum returns last confirmed blockNum read from store
@@ -287,14 +1010,30 @@ func (n *Node) GetLastBlockNum() (uint64, error) {
 	return n.store.GetLastBlockNumSeen()
 }
 
+// ConcurrencyStats reports how many objectives proposed by peers are in progress or queued,
+// and how many have been queued or rejected because of the policy's concurrency limits.
+func (n *Node) ConcurrencyStats() engine.ConcurrencyStats {
+	return n.engine.ConcurrencyStats()
+}
+
 // GetLedgerChannel returns the ledger channel with the given id.
 // If no ledger channel exists with the given id an error is returned.
 func (n *Node) GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo, error) {
-	return query.GetLedgerChannelInfo(id, n.store)
+	if !n.channelExists(id) {
+		return query.LedgerChannelInfo{}, channelNotFound(id)
+	}
+	info, err := query.GetLedgerChannelInfo(id, n.store)
+	if err != nil {
+		return query.LedgerChannelInfo{}, err
+	}
+	return n.fiatPrices.valueLedgerChannel(info), nil
 }
 
 // Close stops the node from responding to any input.
 func (n *Node) Close() error {
+	close(n.stopBackgroundTasks)
+	n.backgroundTasksWg.Wait()
+
 	if err := n.engine.Close(); err != nil {
 		return err
 	}
@@ -305,6 +1044,8 @@ func (n *Node) Close() error {
 	// If there are blocking consumers (for or select channel statements) on any channel for which the node is a producer,
 	// those channels need to be closed.
 	close(n.completedObjectivesForRPC)
+	close(n.voucherUpdates)
+	close(n.capacityEvents.events)
 
 	return n.store.Close()
 }
@@ -314,7 +1055,7 @@ func (n *Node) Close() error {
 func (n *Node) handleError(err error) {
 	if err != nil {
 
-		slog.Error("Error in nitro node", "error", err)
+		n.logger.Error("Error in nitro node", "error", err)
 
 		<-time.After(1000 * time.Millisecond) // We wait for a bit so the previous log line has time to complete
 
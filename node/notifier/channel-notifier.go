@@ -15,11 +15,11 @@ type ChannelNotifier struct {
 	ledgerListeners  *safesync.Map[*ledgerChannelListeners]
 	paymentListeners *safesync.Map[*paymentChannelListeners]
 	store            store.Store
-	vm               *payments.VoucherManager
+	vm               payments.VoucherManagerApi
 }
 
 // NewChannelNotifier constructs a channel notifier using the provided store.
-func NewChannelNotifier(store store.Store, vm *payments.VoucherManager) *ChannelNotifier {
+func NewChannelNotifier(store store.Store, vm payments.VoucherManagerApi) *ChannelNotifier {
 	return &ChannelNotifier{
 		ledgerListeners:  &safesync.Map[*ledgerChannelListeners]{},
 		paymentListeners: &safesync.Map[*paymentChannelListeners]{},
@@ -55,6 +55,15 @@ func (cn *ChannelNotifier) RegisterForPaymentChannelUpdates(cId types.Destinatio
 // NotifyLedgerUpdated notifies all listeners of a ledger channel update.
 // It should be called whenever a ledger channel is updated.
 func (cn *ChannelNotifier) NotifyLedgerUpdated(info query.LedgerChannelInfo) error {
+	tags, err := cn.store.GetChannelTags(info.ID)
+	if err != nil {
+		return err
+	}
+	info.Tags = tags
+	if info.Memo, err = cn.store.GetChannelMemo(info.ID); err != nil {
+		return err
+	}
+
 	li, _ := cn.ledgerListeners.LoadOrStore(info.ID.String(), newLedgerChannelListeners())
 	li.Notify(info)
 	allLi, _ := cn.ledgerListeners.LoadOrStore(ALL_NOTIFICATIONS, newLedgerChannelListeners())
@@ -66,6 +75,15 @@ func (cn *ChannelNotifier) NotifyLedgerUpdated(info query.LedgerChannelInfo) err
 // NotifyPaymentUpdated notifies all listeners of a payment channel update.
 // It should be called whenever a payment channel is updated.
 func (cn *ChannelNotifier) NotifyPaymentUpdated(info query.PaymentChannelInfo) error {
+	tags, err := cn.store.GetChannelTags(info.ID)
+	if err != nil {
+		return err
+	}
+	info.Tags = tags
+	if info.Memo, err = cn.store.GetChannelMemo(info.ID); err != nil {
+		return err
+	}
+
 	li, _ := cn.paymentListeners.LoadOrStore(info.ID.String(), newPaymentChannelListeners())
 	li.Notify(info)
 
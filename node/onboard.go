@@ -0,0 +1,121 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/faucet"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrFaucetTimeout is returned by Onboard when the funds requested from the faucet do not arrive in time.
+const ErrFaucetTimeout = types.ConstError("faucet funds did not arrive")
+
+// DEFAULT_FAUCET_TIMEOUT is how long Onboard waits for the funds requested from a faucet to arrive, by default.
+const DEFAULT_FAUCET_TIMEOUT = 2 * time.Minute
+
+// faucetPollInterval is how often Onboard checks whether the funds requested from a faucet have arrived.
+const faucetPollInterval = time.Second
+
+// OnboardOptions configure Onboard.
+type OnboardOptions struct {
+	// Faucet gives funds to the funding wallet, if it cannot afford our deposit
+	Faucet faucet.Faucet
+	// Hub is the intermediary to open a ledger channel with
+	Hub types.Address
+	// Deposit is the amount of native token we deposit into the ledger channel
+	Deposit *big.Int
+	// HubDeposit is the amount of native token the hub deposits into the ledger channel. It defaults to Deposit.
+	HubDeposit *big.Int
+	// ChallengeDuration is the challenge duration of the ledger channel
+	ChallengeDuration uint32
+	// FaucetTimeout is how long to wait for the faucet's funds to arrive. It defaults to DEFAULT_FAUCET_TIMEOUT.
+	FaucetTimeout time.Duration
+}
+
+// Onboard gets a developer going on a testnet with a single call: if the funding wallet cannot afford our deposit, it
+// requests funds from the faucet and waits for them to arrive, then it opens a ledger channel with the hub and waits
+// for the channel to be funded. It must only be used on testnets.
+func (n *Node) Onboard(ctx context.Context, opts OnboardOptions) (directfund.ObjectiveResponse, error) {
+	wallet := n.walletBalanceCheck.wallet
+	if wallet == nil {
+		return directfund.ObjectiveResponse{}, ErrWalletBalanceUnavailable
+	}
+	if opts.Deposit == nil || opts.Deposit.Sign() <= 0 {
+		return directfund.ObjectiveResponse{}, fmt.Errorf("a positive deposit is required")
+	}
+	hubDeposit := opts.HubDeposit
+	if hubDeposit == nil {
+		hubDeposit = opts.Deposit
+	}
+	native := types.Address{}
+
+	gas, err := wallet.DepositGasCost(ctx, native)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, fmt.Errorf("could not estimate cost of depositing: %w", err)
+	}
+	required := new(big.Int).Add(opts.Deposit, gas)
+	balance, err := wallet.WalletBalance(ctx, native)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, fmt.Errorf("could not read wallet balance: %w", err)
+	}
+	if balance.Cmp(required) < 0 {
+		if opts.Faucet == nil {
+			return directfund.ObjectiveResponse{}, &WalletBalanceError{Asset: native, Required: required, Available: balance}
+		}
+		if err := n.awaitFaucet(ctx, opts.Faucet, wallet.WalletAddress(), required, opts.FaucetTimeout); err != nil {
+			return directfund.ObjectiveResponse{}, err
+		}
+	}
+
+	o := outcome.Exit{outcome.SingleAssetExit{
+		Asset: native,
+		Allocations: outcome.Allocations{
+			{Destination: types.AddressToDestination(*n.Address), Amount: opts.Deposit},
+			{Destination: types.AddressToDestination(opts.Hub), Amount: hubDeposit},
+		},
+	}}
+	response, err := n.CreateLedgerChannelContext(ctx, opts.Hub, opts.ChallengeDuration, o)
+	if err != nil {
+		return directfund.ObjectiveResponse{}, err
+	}
+	n.logger.Info("Opening a ledger channel with the hub", "hub", opts.Hub, "channel", response.ChannelId)
+	return response, n.WaitForObjective(ctx, response.Id)
+}
+
+// awaitFaucet requests funds for account from the faucet, and waits until the account's balance reaches required.
+func (n *Node) awaitFaucet(ctx context.Context, f faucet.Faucet, account types.Address, required *big.Int, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = DEFAULT_FAUCET_TIMEOUT
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	n.logger.Info("Requesting funds from the faucet", "account", account)
+	if err := f.Drip(ctx, account); err != nil {
+		return fmt.Errorf("could not request funds from the faucet: %w", err)
+	}
+
+	ticker := time.NewTicker(faucetPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ErrFaucetTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+		balance, err := n.walletBalanceCheck.wallet.WalletBalance(ctx, types.Address{})
+		if err != nil {
+			n.logger.Warn("Could not read wallet balance", "error", err)
+			continue
+		}
+		if balance.Cmp(required) >= 0 {
+			n.logger.Info("Received funds from the faucet", "account", account, "balance", balance)
+			return nil
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package node
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+)
+
+// The subsystems a Node depends on. Each is an interface, so that applications embedding a node can substitute fakes
+// for them in their own tests. Each is supplied to New with an option, and has a default suited to quick starts and
+// tests: see New.
+type (
+	// ChainService submits transactions to the chain and reports the events which concern our channels.
+	ChainService = chainservice.ChainService
+	// MessageService exchanges messages with peers.
+	MessageService = messageservice.MessageService
+	// Store persists channels, objectives and vouchers.
+	Store = store.Store
+	// PolicyMaker decides which objectives to approve, and may implement the optional policies described in package engine.
+	PolicyMaker = engine.PolicyMaker
+	// VoucherManager tracks the payments made through payment channels.
+	VoucherManager = payments.VoucherManagerApi
+	// DisputeAdapter raises the node's challenges, through the adjudicator's ForceMove protocol unless one is supplied
+	// with WithDisputeAdapter.
+	DisputeAdapter = engine.DisputeAdapter
+	// Metrics receives the node's measurements, such as counts of completed objectives and the latency of payments.
+	Metrics = engine.Metrics
+	// Signer makes the node's signatures, with the channel secret key of its store unless one is supplied with
+	// WithSigner.
+	Signer = engine.Signer
+)
+
+// The names of the counters the node increments with its Metrics. The objective counters are labelled with the
+// "protocol" of the objective, such as "DirectFunding".
+const (
+	ObjectivesCompletedMetric = "objectives_completed"
+	ObjectivesFailedMetric    = "objectives_failed"
+	VouchersReceivedMetric    = "vouchers_received"
+)
+
+// BufferSizes are the capacities of the channels through which the node reports to the application. A node whose
+// application reads its reports slowly, such as one receiving many payments, may need larger buffers so that the
+// engine is not held up.
+type BufferSizes struct {
+	// Objectives buffers the ids of completed and failed objectives. It defaults to 100.
+	Objectives int
+	// Vouchers buffers received vouchers. It defaults to 1000, as payments may be received frequently.
+	Vouchers int
+}
+
+var defaultBufferSizes = BufferSizes{Objectives: 100, Vouchers: 1000}
+
+// DefaultSlowStoreThreshold is the duration of a store operation above which it is logged as slow, unless another is
+// set with WithSlowStoreThreshold.
+const DefaultSlowStoreThreshold = 100 * time.Millisecond
+
+// Option configures a Node constructed with New.
+type Option func(*options)
+
+type options struct {
+	store          Store
+	chainService   ChainService
+	chainSet       bool // whether WithChainService was supplied, as a nil chain service makes the node virtual-only
+	messageService MessageService
+	policy         PolicyMaker
+	metrics        Metrics
+	logger         *slog.Logger
+	buffers        BufferSizes
+	vm             VoucherManager
+	disputes       DisputeAdapter
+	signer         Signer
+	replayProtect  bool
+	eventLog       io.Writer
+	eventLogCipher *store.RecordCipher
+	slowStore      time.Duration
+}
+
+// WithStore makes the node persist its state in s. Without it, the node keeps its state in memory under a newly
+// generated key.
+func WithStore(s Store) Option {
+	return func(o *options) {
+		o.store = s
+	}
+}
+
+// WithChainService makes the node use cs to reach the chain. A nil cs makes the node virtual-only: see New. Without
+// this option, the node uses a simulated chain shared by the nodes of the process.
+func WithChainService(cs ChainService) Option {
+	return func(o *options) {
+		o.chainService = cs
+		o.chainSet = true
+	}
+}
+
+// WithMessageService makes the node exchange messages with peers through ms. Without it, the node exchanges messages
+// with the other nodes of the process constructed without one.
+func WithMessageService(ms MessageService) Option {
+	return func(o *options) {
+		o.messageService = ms
+	}
+}
+
+// WithPolicy makes the node approve objectives with policy. Without it, the node approves every objective.
+func WithPolicy(policy PolicyMaker) Option {
+	return func(o *options) {
+		o.policy = policy
+	}
+}
+
+// WithMetrics makes the node report its measurements to metrics. Without it, they are discarded.
+func WithMetrics(metrics Metrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// WithLogger makes the node and its engine log with logger rather than the default logger.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithBufferSizes sets the capacities of the node's report channels. Sizes which are not positive keep their defaults.
+func WithBufferSizes(sizes BufferSizes) Option {
+	return func(o *options) {
+		if sizes.Objectives > 0 {
+			o.buffers.Objectives = sizes.Objectives
+		}
+		if sizes.Vouchers > 0 {
+			o.buffers.Vouchers = sizes.Vouchers
+		}
+	}
+}
+
+// WithVoucherManager makes the node track payments with vm rather than a payments.ShardedVoucherManager backed by its
+// store. The node receives the vouchers of each channel one at a time, so vm need not be safe for concurrent Receive
+// calls on a channel.
+func WithVoucherManager(vm VoucherManager) Option {
+	return func(o *options) {
+		o.vm = vm
+	}
+}
+
+// WithDisputeAdapter makes the node raise disputes over its channels with adapter, rather than with a ForceMove
+// challenge registered with the adjudicator.
+func WithDisputeAdapter(adapter DisputeAdapter) Option {
+	return func(o *options) {
+		o.disputes = adapter
+	}
+}
+
+// WithSigner makes the node sign its states, vouchers and other messages with signer, such as a remotesigner.Client,
+// rather than the channel secret key of its store, so that the store need not hold the key (see store.StoreOpts). The
+// signer must sign with the key of the node's address.
+func WithSigner(signer Signer) Option {
+	return func(o *options) {
+		o.signer = signer
+	}
+}
+
+// WithVoucherReplayProtection makes the node's voucher manager give the vouchers it pays with increasing nonces, and
+// refuse vouchers received without one, so that a voucher captured in transit cannot be replayed. Its payees must
+// support nonces. It has no effect on a voucher manager supplied with WithVoucherManager, which must be configured
+// itself.
+func WithVoucherReplayProtection() Option {
+	return func(o *options) {
+		o.replayProtect = true
+	}
+}
+
+// WithEventLog makes the node's engine record its inputs to w, so that engine.Replay can reconstruct the node's state
+// step by step. It is meant for debugging: the log grows without bound, and holds every message and payment. If cipher
+// is not nil, the inputs are encrypted with it (see engine.WithEventLog).
+func WithEventLog(w io.Writer, cipher *store.RecordCipher) Option {
+	return func(o *options) {
+		o.eventLog = w
+		o.eventLogCipher = cipher
+	}
+}
+
+// WithSlowStoreThreshold makes the node log the store operations which take threshold or longer, rather than
+// DefaultSlowStoreThreshold. A threshold of zero logs none. The duration of every operation is reported to the
+// node's metrics regardless.
+func WithSlowStoreThreshold(threshold time.Duration) Option {
+	return func(o *options) {
+		o.slowStore = threshold
+	}
+}
+
+// The simulated chain and network which nodes constructed without a chain service or message service share, so that
+// the nodes of a quick start can fund channels with and pay each other.
+var (
+	defaultsOnce  sync.Once
+	defaultChain  *chainservice.MockChain
+	defaultBroker messageservice.Broker
+)
+
+// withDefaults fills in the subsystems which were not supplied.
+func (o *options) withDefaults() {
+	if o.store == nil {
+		key, _ := crypto.GeneratePrivateKeyAndAddress()
+		o.store = store.NewMemStore(key)
+	}
+	if o.chainService == nil && !o.chainSet || o.messageService == nil {
+		defaultsOnce.Do(func() {
+			defaultChain = chainservice.NewMockChain()
+			defaultBroker = messageservice.NewBroker()
+		})
+	}
+	address := *o.store.GetAddress()
+	if o.chainService == nil && !o.chainSet {
+		o.chainService = chainservice.NewMockChainService(defaultChain, address)
+	}
+	if o.messageService == nil {
+		o.messageService = messageservice.NewTestMessageService(address, defaultBroker, 0)
+	}
+	if o.policy == nil {
+		o.policy = &engine.PermissivePolicy{}
+	}
+	if o.metrics == nil {
+		o.metrics = engine.NoopMetrics{}
+	}
+	if o.logger == nil {
+		o.logger = slog.Default()
+	}
+}
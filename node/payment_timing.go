@@ -0,0 +1,22 @@
+package node
+
+import "github.com/statechannels/go-nitro/node/engine"
+
+// PaymentLatencies returns the median and 99th percentile latency of each stage of the most recent payments made or
+// received by the node: from the API to the engine, from the engine to the payee, and from a payer's voucher arriving
+// to it being registered.
+func (n *Node) PaymentLatencies() map[engine.PaymentStage]engine.LatencyPercentiles {
+	return n.engine.PaymentLatencies()
+}
+
+// EnablePaymentTimings keeps the timing of each hop of up to capacity of the most recent payments, for
+// RecentPaymentTimings and debug bundles.
+func (n *Node) EnablePaymentTimings(capacity int) {
+	n.engine.RecordPaymentTimings(capacity)
+}
+
+// RecentPaymentTimings returns the timing of each hop of the most recent payments made or received by the node,
+// oldest first, if EnablePaymentTimings has been called.
+func (n *Node) RecentPaymentTimings() []engine.PaymentTiming {
+	return n.engine.RecentPaymentTimings()
+}
@@ -0,0 +1,79 @@
+// Package pricefeed provides approximate fiat prices of assets, so that balances can be displayed in a fiat currency.
+package pricefeed // import "github.com/statechannels/go-nitro/node/pricefeed"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Price is the approximate price of an asset in a fiat currency.
+type Price struct {
+	Currency string  // The fiat currency, e.g. "USD"
+	PerToken float64 // The price of one whole token
+	Decimals uint8   // The number of decimals of the asset: one whole token is 10^Decimals of the asset's smallest unit
+}
+
+// Value returns the approximate value of amount of the asset's smallest unit.
+func (p Price) Value(amount *big.Int) float64 {
+	if amount == nil {
+		return 0
+	}
+	unit := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(p.Decimals)), nil))
+	tokens, _ := new(big.Float).Quo(new(big.Float).SetInt(amount), unit).Float64()
+	return tokens * p.PerToken
+}
+
+// Feed is a source of prices.
+type Feed interface {
+	// Prices returns the current prices of the assets which the feed prices, keyed by asset address.
+	Prices(ctx context.Context) (map[types.Address]Price, error)
+}
+
+// StaticFeed always returns the same prices. It is useful for testing, and for assets pegged to a fiat currency.
+type StaticFeed map[types.Address]Price
+
+// Prices returns the static prices.
+func (f StaticFeed) Prices(context.Context) (map[types.Address]Price, error) {
+	return f, nil
+}
+
+// HTTPFeed fetches prices from an HTTP endpoint, which responds to a GET request with a JSON object mapping asset
+// addresses to Prices.
+type HTTPFeed struct {
+	Url    string
+	Client *http.Client // The client used to reach the endpoint. If nil, a client with a 10 second timeout is used.
+}
+
+var defaultFeedClient = &http.Client{Timeout: 10 * time.Second}
+
+// Prices fetches the current prices from the endpoint.
+func (f *HTTPFeed) Prices(ctx context.Context) (map[types.Address]Price, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client := f.Client
+	if client == nil {
+		client = defaultFeedClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach price feed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price feed responded with status %d", resp.StatusCode)
+	}
+
+	prices := map[types.Address]Price{}
+	if err := json.NewDecoder(resp.Body).Decode(&prices); err != nil {
+		return nil, fmt.Errorf("could not decode price feed response: %w", err)
+	}
+	return prices, nil
+}
@@ -0,0 +1,50 @@
+package pricefeed
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestPriceValue(t *testing.T) {
+	eth := Price{Currency: "USD", PerToken: 2000, Decimals: 18}
+	halfEth, _ := new(big.Int).SetString("500000000000000000", 10)
+	if got := eth.Value(halfEth); got != 1000 {
+		t.Fatalf("expected half an ETH to be worth 1000 USD, got %v", got)
+	}
+	if got := eth.Value(nil); got != 0 {
+		t.Fatalf("expected no amount to be worth nothing, got %v", got)
+	}
+}
+
+func TestHTTPFeed(t *testing.T) {
+	token := types.Address{'t'}
+	want := map[types.Address]Price{
+		{}:    {Currency: "USD", PerToken: 2000, Decimals: 18},
+		token: {Currency: "USD", PerToken: 1, Decimals: 6},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(want); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	got, err := (&HTTPFeed{Url: server.URL}).Prices(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[token] != want[token] || got[types.Address{}] != want[types.Address{}] {
+		t.Fatalf("expected prices %v, got %v", want, got)
+	}
+
+	server.Close()
+	if _, err := (&HTTPFeed{Url: server.URL}).Prices(context.Background()); err == nil {
+		t.Fatal("expected an error when the feed is unreachable")
+	}
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ProbeTimeout is how long ProbeCounterparty waits for the counterparty to respond.
+const ProbeTimeout = 10 * time.Second
+
+// ProbeCounterparty asks the counterparty whether it would fund a channel with us holding asset, to which we deposit
+// deposit and it deposits counterDeposit: whether it speaks a version of the wire protocol we speak, accepts the asset
+// and accepts the size of the channel. It blocks until the counterparty responds, ProbeTimeout elapses or ctx is done.
+//
+// The probe is returned whether or not the counterparty accepts. The same check is made by creating a channel with
+// CreateChannelOptions.Probe set.
+func (n *Node) ProbeCounterparty(ctx context.Context, counterparty types.Address, asset types.Address, deposit *big.Int, counterDeposit *big.Int) (protocols.Probe, error) {
+	request := protocols.ProbeRequest{
+		Id:             rand.Uint64(),
+		Versions:       protocols.SupportedProtocolVersions,
+		Asset:          asset,
+		Deposit:        deposit,
+		CounterDeposit: counterDeposit,
+	}
+	key := strconv.FormatUint(request.Id, 10)
+
+	response := make(chan protocols.Probe, 1)
+	n.pendingProbes.Store(key, response)
+	defer n.pendingProbes.Delete(key)
+
+	// Send the event to the engine
+	select {
+	case n.engine.ProbeRequestsFromAPI <- engine.NewAPIRequest(ctx, engine.ProbeRequest{Counterparty: counterparty, Request: request}):
+	case <-ctx.Done():
+		return protocols.Probe{}, ctx.Err()
+	}
+
+	select {
+	case probe := <-response:
+		// A counterparty which only speaks newer versions accepts nothing we could send it
+		if probe.Accepts && !slices.ContainsFunc(probe.Versions, func(v uint32) bool { return slices.Contains(protocols.SupportedProtocolVersions, v) }) {
+			probe.Accepts = false
+			probe.Refusal, probe.Reason = protocols.ProbeUnsupportedVersion, "no protocol version in common"
+		}
+		return probe, nil
+	case <-ctx.Done():
+		return protocols.Probe{}, ctx.Err()
+	case <-time.After(ProbeTimeout):
+		return protocols.Probe{}, fmt.Errorf("timed out waiting for a probe from %s: %w", counterparty, ErrPeerUnreachable)
+	}
+}
+
+// probeOutcome probes the counterparty for each asset of the outcome of a channel we propose, returning a
+// *ProbeRefusedError if it would not fund the channel.
+func (n *Node) probeOutcome(ctx context.Context, counterparty types.Address, o outcome.Exit) error {
+	for _, sae := range o {
+		deposit := sae.TotalAllocatedFor(types.AddressToDestination(*n.Address))
+		counterDeposit := sae.TotalAllocatedFor(types.AddressToDestination(counterparty))
+		probe, err := n.ProbeCounterparty(ctx, counterparty, sae.Asset, deposit, counterDeposit)
+		if err != nil {
+			return err
+		}
+		if !probe.Accepts {
+			n.logger.Warn("Counterparty refused a probe", "counterparty", counterparty, "asset", sae.Asset, "refusal", probe.Refusal, "reason", probe.Reason)
+			return &ProbeRefusedError{Counterparty: counterparty, Refusal: probe.Refusal, Reason: probe.Reason}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/store"
+)
+
+// Formats supported by ExportActivity
+const (
+	CsvExportFormat   = "csv"
+	JsonlExportFormat = "jsonl"
+)
+
+// ActivityExportRecord is a single line of an activity export
+type ActivityExportRecord struct {
+	Time        time.Time `json:"time"`
+	Kind        string    `json:"kind"`
+	ChannelId   string    `json:"channelId"`
+	ObjectiveId string    `json:"objectiveId,omitempty"`
+	Amount      string    `json:"amount,omitempty"`
+	Fee         string    `json:"fee,omitempty"`
+}
+
+var activityCsvHeader = []string{"time", "kind", "channel_id", "objective_id", "amount", "fee"}
+
+func toExportRecord(r store.ActivityRecord) ActivityExportRecord {
+	er := ActivityExportRecord{
+		Time:        r.Time.UTC(),
+		Kind:        string(r.Kind),
+		ChannelId:   r.ChannelId.String(),
+		ObjectiveId: string(r.ObjectiveId),
+	}
+	if r.Amount != nil {
+		er.Amount = r.Amount.String()
+	}
+	if r.Fee != nil {
+		er.Fee = r.Fee.String()
+	}
+	return er
+}
+
+// ExportActivity writes every activity record (payments and channel openings and closings, with the fees quoted to route them) made at or after from and before to,
+// in time order, to w in the given format. Records are written as they are read from the store, so large ranges are not held in memory.
+func ExportActivity(s store.Store, w io.Writer, format string, from, to time.Time) error {
+	var write func(ActivityExportRecord) error
+	var flush func() error
+
+	switch format {
+	case CsvExportFormat:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(activityCsvHeader); err != nil {
+			return err
+		}
+		write = func(er ActivityExportRecord) error {
+			return cw.Write([]string{er.Time.Format(time.RFC3339Nano), er.Kind, er.ChannelId, er.ObjectiveId, er.Amount, er.Fee})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	case JsonlExportFormat:
+		enc := json.NewEncoder(w)
+		write = func(er ActivityExportRecord) error { return enc.Encode(er) }
+		flush = func() error { return nil }
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	var writeErr error
+	err := s.GetActivity(from, to, func(r store.ActivityRecord) bool {
+		writeErr = write(toExportRecord(r))
+		return writeErr == nil
+	})
+	if err != nil {
+		return err
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	return flush()
+}
@@ -0,0 +1,100 @@
+package query
+
+import (
+	"bytes"
+	"slices"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Limits on the number of channels in a page.
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// ChannelFilter selects channels. Unset fields match every channel.
+type ChannelFilter struct {
+	Status       ChannelStatus  `json:",omitempty"`
+	Counterparty types.Address  // The zero address matches every counterparty
+	Asset        *types.Address `json:",omitempty"` // A pointer, as the zero address is the native asset
+}
+
+// PageRequest requests a page of channels, ordered by id. Cursor is the NextCursor of the previous page, or the zero
+// destination for the first page. Limit is the most channels in the page: DefaultPageLimit if zero, and at most
+// MaxPageLimit.
+type PageRequest struct {
+	Limit  uint
+	Cursor types.Destination
+}
+
+// LedgerChannelPage is a page of ledger channels. NextCursor requests the next page, and is the zero destination on the
+// last page.
+type LedgerChannelPage struct {
+	Channels   []LedgerChannelInfo
+	NextCursor types.Destination
+}
+
+// PaymentChannelPage is a page of payment channels. NextCursor requests the next page, and is the zero destination on
+// the last page.
+type PaymentChannelPage struct {
+	Channels   []PaymentChannelInfo
+	NextCursor types.Destination
+}
+
+// MatchLedger returns whether the ledger channel is selected by the filter.
+func (f ChannelFilter) MatchLedger(l LedgerChannelInfo) bool {
+	return f.match(l.Status, l.Balance.AssetAddress, l.Balance.Them)
+}
+
+// MatchPayment returns whether the payment channel is selected by the filter. Either the payer or the payee may be the
+// counterparty.
+func (f ChannelFilter) MatchPayment(p PaymentChannelInfo) bool {
+	return f.match(p.Status, p.Balance.AssetAddress, p.Balance.Payer, p.Balance.Payee)
+}
+
+func (f ChannelFilter) match(status ChannelStatus, asset types.Address, counterparties ...types.Address) bool {
+	if f.Status != "" && f.Status != status {
+		return false
+	}
+	if f.Asset != nil && *f.Asset != asset {
+		return false
+	}
+	return (f.Counterparty == types.Address{}) || slices.Contains(counterparties, f.Counterparty)
+}
+
+// PageLedgerChannels returns the page of the ledger channels selected by the filter.
+func PageLedgerChannels(infos []LedgerChannelInfo, filter ChannelFilter, req PageRequest) LedgerChannelPage {
+	channels, next := page(infos, filter.MatchLedger, func(l LedgerChannelInfo) types.Destination { return l.ID }, req)
+	return LedgerChannelPage{Channels: channels, NextCursor: next}
+}
+
+// PagePaymentChannels returns the page of the payment channels selected by the filter.
+func PagePaymentChannels(infos []PaymentChannelInfo, filter ChannelFilter, req PageRequest) PaymentChannelPage {
+	channels, next := page(infos, filter.MatchPayment, func(p PaymentChannelInfo) types.Destination { return p.ID }, req)
+	return PaymentChannelPage{Channels: channels, NextCursor: next}
+}
+
+// page orders the matching items by id, and returns those after the cursor, up to the limit, along with the cursor of
+// the next page. Ordering by id keeps pages stable as channels are opened and closed between requests.
+func page[T any](items []T, match func(T) bool, id func(T) types.Destination, req PageRequest) ([]T, types.Destination) {
+	limit := req.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	matching := []T{}
+	for _, item := range items {
+		if match(item) && (req.Cursor == types.Destination{} || bytes.Compare(id(item).Bytes(), req.Cursor.Bytes()) > 0) {
+			matching = append(matching, item)
+		}
+	}
+	slices.SortFunc(matching, func(a, b T) int {
+		return bytes.Compare(id(a).Bytes(), id(b).Bytes())
+	})
+	if uint(len(matching)) <= limit {
+		return matching, types.Destination{}
+	}
+	return matching[:limit], id(matching[limit-1])
+}
@@ -4,12 +4,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
 	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
@@ -102,9 +104,26 @@ func GetVirtualFundObjective(id types.Destination, store store.Store) (*virtualf
 	return o.(*virtualfund.Objective), true
 }
 
+// GetObjectiveStatus returns the status of the objective with the given id,
+// using its summary if the objective has been collected.
+func GetObjectiveStatus(id protocols.ObjectiveId, s store.Store) (protocols.ObjectiveStatus, error) {
+	o, err := s.GetObjectiveById(id)
+	if errors.Is(err, store.ErrObjectiveCollected) {
+		summary, err := s.GetObjectiveSummary(id)
+		if err != nil {
+			return 0, err
+		}
+		return summary.Status, nil
+	}
+	if o == nil {
+		return 0, err
+	}
+	return o.GetStatus(), nil
+}
+
 // GetVoucherBalance returns the amount paid and remaining for a given channel based on vouchers received.
 // If not vouchers are received for the channel, it returns 0 for paid and remaining.
-func GetVoucherBalance(id types.Destination, vm *payments.VoucherManager) (paid, remaining *big.Int, err error) {
+func GetVoucherBalance(id types.Destination, vm payments.VoucherManagerApi) (paid, remaining *big.Int, err error) {
 	paid, remaining = big.NewInt(0), big.NewInt(0)
 
 	if noVouchers := !vm.ChannelRegistered(id); noVouchers {
@@ -125,7 +144,7 @@ func GetVoucherBalance(id types.Destination, vm *payments.VoucherManager) (paid,
 
 // GetPaymentChannelInfo returns the PaymentChannelInfo for the given channel
 // It does this by querying the provided store and voucher manager
-func GetPaymentChannelInfo(id types.Destination, store store.Store, vm *payments.VoucherManager) (PaymentChannelInfo, error) {
+func GetPaymentChannelInfo(id types.Destination, store store.Store, vm payments.VoucherManagerApi) (PaymentChannelInfo, error) {
 	if (id == types.Destination{}) {
 		return PaymentChannelInfo{}, errors.New("a valid channel id must be provided")
 	}
@@ -138,7 +157,12 @@ func GetPaymentChannelInfo(id types.Destination, store store.Store, vm *payments
 			return PaymentChannelInfo{}, err
 		}
 
-		return ConstructPaymentInfo(c, paid, remaining)
+		info, err := ConstructPaymentInfo(c, paid, remaining)
+		if err != nil {
+			return PaymentChannelInfo{}, err
+		}
+		info.Tags, info.Memo, err = channelMetadata(store, id)
+		return info, err
 	}
 	return PaymentChannelInfo{}, fmt.Errorf("could not find channel with id %v", id)
 }
@@ -157,6 +181,9 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 
 	for _, con := range allConsensus {
 		lInfo, err := ConstructLedgerInfoFromConsensus(con, myAddress)
+		if err == nil {
+			lInfo.Tags, lInfo.Memo, err = channelMetadata(store, con.Id)
+		}
 		if err != nil {
 			failedConstructions = append(failedConstructions, fmt.Sprintf("%v: %v", con.Id, err))
 			continue
@@ -172,6 +199,9 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 		if err != nil {
 			return []LedgerChannelInfo{}, err
 		}
+		if l.Tags, l.Memo, err = channelMetadata(store, c.Id); err != nil {
+			return []LedgerChannelInfo{}, err
+		}
 		toReturn = append(toReturn, l)
 	}
 	err = nil
@@ -183,7 +213,7 @@ func GetAllLedgerChannels(store store.Store, consensusAppDefinition types.Addres
 }
 
 // GetPaymentChannelsByLedger returns a `PaymentChannelInfo` for each active payment channel funded by the given ledger channel.
-func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm *payments.VoucherManager) ([]PaymentChannelInfo, error) {
+func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm payments.VoucherManagerApi) ([]PaymentChannelInfo, error) {
 	// If a ledger channel is actively funding payment channels it must be in the form of a consensus channel
 	con, err := s.GetConsensusChannelById(ledgerId)
 	// If the ledger channel is not a consensus channel we know that there are no payment channels funded by it
@@ -212,6 +242,9 @@ func GetPaymentChannelsByLedger(ledgerId types.Destination, s store.Store, vm *p
 		if err != nil {
 			return []PaymentChannelInfo{}, err
 		}
+		if info.Tags, info.Memo, err = channelMetadata(s, p.Id); err != nil {
+			return []PaymentChannelInfo{}, err
+		}
 		toReturn = append(toReturn, info)
 	}
 	return toReturn, nil
@@ -223,16 +256,68 @@ func GetLedgerChannelInfo(id types.Destination, store store.Store) (LedgerChanne
 	c, ok := store.GetChannelById(id)
 	myAddress := *store.GetAddress()
 
+	var info LedgerChannelInfo
+	var err error
 	if ok {
-		return ConstructLedgerInfoFromChannel(c, myAddress)
+		info, err = ConstructLedgerInfoFromChannel(c, myAddress)
+	} else {
+		con, conErr := store.GetConsensusChannelById(id)
+		if conErr != nil {
+			return LedgerChannelInfo{}, conErr
+		}
+		info, err = ConstructLedgerInfoFromConsensus(con, myAddress)
 	}
-
-	con, err := store.GetConsensusChannelById(id)
 	if err != nil {
 		return LedgerChannelInfo{}, err
 	}
 
-	return ConstructLedgerInfoFromConsensus(con, myAddress)
+	info.Tags, info.Memo, err = channelMetadata(store, id)
+	return info, err
+}
+
+// channelMetadata returns the tags and memo of the channel, which are held alongside it in the store.
+func channelMetadata(s store.Store, id types.Destination) (tags map[string]string, memo string, err error) {
+	if tags, err = s.GetChannelTags(id); err != nil {
+		return nil, "", err
+	}
+	memo, err = s.GetChannelMemo(id)
+	return tags, memo, err
+}
+
+// GetSignedStateInfo returns the SignedStateInfo for the latest supported state of the given channel.
+// Both ledger and payment channels are supported.
+func GetSignedStateInfo(id types.Destination, store store.Store) (SignedStateInfo, error) {
+	var ss state.SignedState
+	if c, ok := store.GetChannelById(id); ok {
+		var err error
+		ss, err = c.LatestSupportedSignedState()
+		if err != nil {
+			return SignedStateInfo{}, fmt.Errorf("channel %s: %w", id, err)
+		}
+	} else {
+		con, err := store.GetConsensusChannelById(id)
+		if err != nil {
+			return SignedStateInfo{}, fmt.Errorf("could not find channel with id %v: %w", id, err)
+		}
+		ss = con.SupportedSignedState()
+	}
+	return ConstructSignedStateInfo(ss)
+}
+
+// ConstructSignedStateInfo constructs a SignedStateInfo from the given signed state.
+func ConstructSignedStateInfo(ss state.SignedState) (SignedStateInfo, error) {
+	calldata, err := NitroAdjudicator.EncodeCheckpointCalldata(ss)
+	if err != nil {
+		return SignedStateInfo{}, fmt.Errorf("failed to abi encode signed state: %w", err)
+	}
+	s := ss.State()
+	return SignedStateInfo{
+		ID:                 s.ChannelId(),
+		FixedPart:          s.FixedPart(),
+		VariablePart:       s.VariablePart(),
+		Signatures:         ss.Signatures(),
+		CheckpointCalldata: calldata,
+	}, nil
 }
 
 func ConstructLedgerInfoFromConsensus(con *consensus_channel.ConsensusChannel, myAddress types.Address) (LedgerChannelInfo, error) {
@@ -291,3 +376,78 @@ func ConstructPaymentInfo(c *channel.Channel, paid, remaining *big.Int) (Payment
 		Balance: balance,
 	}, nil
 }
+
+// CurrentBalanceSnapshots returns a snapshot, taken at the given time, of the balances of every ledger and payment channel which is not complete.
+func CurrentBalanceSnapshots(s store.Store, vm payments.VoucherManagerApi, consensusAppDefinition, virtualPaymentAppDefinition types.Address, at time.Time) ([]store.BalanceSnapshot, error) {
+	snapshots := []store.BalanceSnapshot{}
+
+	ledgers, err := GetAllLedgerChannels(s, consensusAppDefinition)
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range ledgers {
+		if l.Status == Complete {
+			continue
+		}
+		snapshots = append(snapshots, store.BalanceSnapshot{
+			ChannelId:    l.ID,
+			Time:         at,
+			AssetAddress: l.Balance.AssetAddress,
+			Me:           l.Balance.Me,
+			Them:         l.Balance.Them,
+			MyBalance:    l.Balance.MyBalance.ToInt(),
+			TheirBalance: l.Balance.TheirBalance.ToInt(),
+		})
+	}
+
+	paymentChannels, err := s.GetChannelsByAppDefinition(virtualPaymentAppDefinition)
+	if err != nil {
+		return nil, err
+	}
+	me := *s.GetAddress()
+	for _, c := range paymentChannels {
+		info, err := GetPaymentChannelInfo(c.Id, s, vm)
+		if err != nil {
+			return nil, err
+		}
+		// Intermediaries hold no balance in payment channels, so only payers and payees record snapshots.
+		if info.Status == Complete || (info.Balance.Payer != me && info.Balance.Payee != me) {
+			continue
+		}
+		snapshot := store.BalanceSnapshot{ChannelId: info.ID, Time: at, AssetAddress: info.Balance.AssetAddress}
+		if info.Balance.Payer == me {
+			snapshot.Me, snapshot.Them = info.Balance.Payer, info.Balance.Payee
+			snapshot.MyBalance, snapshot.TheirBalance = info.Balance.RemainingFunds.ToInt(), info.Balance.PaidSoFar.ToInt()
+		} else {
+			snapshot.Me, snapshot.Them = info.Balance.Payee, info.Balance.Payer
+			snapshot.MyBalance, snapshot.TheirBalance = info.Balance.PaidSoFar.ToInt(), info.Balance.RemainingFunds.ToInt()
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+// GetBalanceHistory returns the balance snapshots taken at or after from and before to, in time order.
+// If id is the zero destination, snapshots for every channel are returned.
+func GetBalanceHistory(id types.Destination, from, to time.Time, s store.Store) ([]BalanceSnapshotInfo, error) {
+	snapshots, err := s.GetBalanceSnapshots(from, to)
+	if err != nil {
+		return nil, err
+	}
+	history := []BalanceSnapshotInfo{}
+	for _, bs := range snapshots {
+		if (id != types.Destination{}) && bs.ChannelId != id {
+			continue
+		}
+		history = append(history, BalanceSnapshotInfo{
+			ID:           bs.ChannelId,
+			Time:         bs.Time,
+			AssetAddress: bs.AssetAddress,
+			Me:           bs.Me,
+			Them:         bs.Them,
+			MyBalance:    (*hexutil.Big)(bs.MyBalance),
+			TheirBalance: (*hexutil.Big)(bs.TheirBalance),
+		})
+	}
+	return history, nil
+}
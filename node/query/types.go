@@ -1,7 +1,13 @@
 package query
 
 import (
+	"maps"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/node/pricefeed"
+	"github.com/statechannels/go-nitro/protocols"
 	"github.com/statechannels/go-nitro/types"
 )
 
@@ -15,9 +21,13 @@ const (
 	Complete ChannelStatus = "Complete"
 )
 
-// PaymentChannelBalance contains the balance of a uni-directional payment channel
+// PaymentChannelBalance contains the balance of a uni-directional payment channel. Amounts are in the smallest unit of
+// the asset, which is the chain's native token if AssetAddress is the zero address, and an ERC20 token otherwise.
 type PaymentChannelBalance struct {
-	AssetAddress   types.Address
+	AssetAddress types.Address
+	// Decimals is the number of decimals of the asset: one whole token is 10^Decimals of its smallest unit. It is only
+	// included if the node's chain service reports it.
+	Decimals       *uint8 `json:",omitempty"`
 	Payee          types.Address
 	Payer          types.Address
 	PaidSoFar      *hexutil.Big
@@ -29,6 +39,9 @@ type PaymentChannelInfo struct {
 	ID      types.Destination
 	Status  ChannelStatus
 	Balance PaymentChannelBalance
+	Fiat    *PaymentChannelFiatBalance `json:",omitempty"` // Only included if the node has a price for the asset
+	Tags    map[string]string          `json:",omitempty"` // The tags attached to the channel when it was created
+	Memo    string                     `json:",omitempty"` // The proposer's description of why the channel was requested
 }
 
 // LedgerChannelInfo contains balance and status info about a ledger channel
@@ -36,6 +49,207 @@ type LedgerChannelInfo struct {
 	ID      types.Destination
 	Status  ChannelStatus
 	Balance LedgerChannelBalance
+	Fiat    *LedgerChannelFiatBalance `json:",omitempty"` // Only included if the node has a price for the asset
+	Tags    map[string]string         `json:",omitempty"` // The tags attached to the channel when it was created
+	Memo    string                    `json:",omitempty"` // The proposer's description of why the channel was requested
+}
+
+// FiatValuation describes how a balance was valued in a fiat currency.
+type FiatValuation struct {
+	Currency string
+	// AsOf is when the price used was fetched
+	AsOf time.Time
+	// Indicative is always true. It flags the valuation as approximate: it is for display only, and is not used by
+	// the node for anything.
+	Indicative bool
+}
+
+// PaymentChannelFiatBalance is an approximate valuation of a PaymentChannelBalance in a fiat currency.
+type PaymentChannelFiatBalance struct {
+	FiatValuation
+	PaidSoFar      float64
+	RemainingFunds float64
+}
+
+// LedgerChannelFiatBalance is an approximate valuation of a LedgerChannelBalance in a fiat currency.
+type LedgerChannelFiatBalance struct {
+	FiatValuation
+	MyBalance    float64
+	TheirBalance float64
+}
+
+// WithFiat returns a copy of the info including a valuation of its balance at price, fetched at asOf.
+func (pci PaymentChannelInfo) WithFiat(price pricefeed.Price, asOf time.Time) PaymentChannelInfo {
+	pci.Fiat = &PaymentChannelFiatBalance{
+		FiatValuation:  FiatValuation{price.Currency, asOf, true},
+		PaidSoFar:      price.Value(pci.Balance.PaidSoFar.ToInt()),
+		RemainingFunds: price.Value(pci.Balance.RemainingFunds.ToInt()),
+	}
+	return pci
+}
+
+// WithFiat returns a copy of the info including a valuation of its balance at price, fetched at asOf.
+func (li LedgerChannelInfo) WithFiat(price pricefeed.Price, asOf time.Time) LedgerChannelInfo {
+	li.Fiat = &LedgerChannelFiatBalance{
+		FiatValuation: FiatValuation{price.Currency, asOf, true},
+		MyBalance:     price.Value(li.Balance.MyBalance.ToInt()),
+		TheirBalance:  price.Value(li.Balance.TheirBalance.ToInt()),
+	}
+	return li
+}
+
+// SignedStateInfo contains the latest supported state of a channel and the signatures supporting it.
+// CheckpointCalldata is the abi encoded calldata for a NitroAdjudicator checkpoint call with the state,
+// which can be submitted on chain as is.
+type SignedStateInfo struct {
+	ID                 types.Destination
+	FixedPart          state.FixedPart
+	VariablePart       state.VariablePart
+	Signatures         []state.Signature
+	CheckpointCalldata hexutil.Bytes
+}
+
+// BalanceSnapshotInfo contains the balance of a channel at a point in time
+type BalanceSnapshotInfo struct {
+	ID           types.Destination
+	Time         time.Time
+	AssetAddress types.Address
+	Me           types.Address
+	Them         types.Address
+	MyBalance    *hexutil.Big
+	TheirBalance *hexutil.Big
+}
+
+// CloseStatus is the progress made closing a channel while winding down a node.
+type CloseStatus string
+
+const (
+	ClosePending    CloseStatus = "Pending"    // The channel has yet to be closed
+	CloseStarted    CloseStatus = "Closing"    // A cooperative close of the channel is under way
+	CloseComplete   CloseStatus = "Closed"     // The channel was closed cooperatively
+	CloseChallenged CloseStatus = "Challenged" // The channel was not closed cooperatively in time, so it was challenged on chain
+	CloseFailed     CloseStatus = "Failed"     // The channel could not be closed, as described by the Error
+)
+
+// ChannelCloseInfo reports the progress made closing a channel while winding down a node.
+type ChannelCloseInfo struct {
+	ID          types.Destination
+	Ledger      bool                  // Whether the channel is a ledger channel, rather than a payment channel
+	ObjectiveId protocols.ObjectiveId `json:",omitempty"` // The objective closing the channel, once it has started
+	Status      CloseStatus
+	Error       string `json:",omitempty"`
+}
+
+// CloseAllInfo reports the progress of winding down a node, by closing every channel which was open when it started.
+type CloseAllInfo struct {
+	Started  time.Time
+	Finished time.Time // Zero until every channel is closed, challenged or has failed to close
+	Channels []ChannelCloseInfo
+}
+
+// BatchChannelStatus is the progress made opening one of a batch of ledger channels.
+type BatchChannelStatus string
+
+const (
+	BatchChannelPending BatchChannelStatus = "Pending" // The channel has yet to be created
+	BatchChannelFunding BatchChannelStatus = "Funding" // The channel has been created, and is being funded
+	BatchChannelFunded  BatchChannelStatus = "Funded"  // The channel is funded and open
+	BatchChannelFailed  BatchChannelStatus = "Failed"  // The channel could not be opened, as described by the Error
+)
+
+// BatchChannelInfo reports the progress made opening a ledger channel with one of the counterparties of a batch.
+type BatchChannelInfo struct {
+	Counterparty types.Address
+	ChannelId    types.Destination     // The channel, once it has been created
+	ObjectiveId  protocols.ObjectiveId `json:",omitempty"` // The objective funding the channel, once it has started
+	Status       BatchChannelStatus
+	Error        string `json:",omitempty"`
+}
+
+// LedgerChannelBatchInfo reports the progress of opening a batch of ledger channels, one with each counterparty.
+type LedgerChannelBatchInfo struct {
+	ID       string
+	Started  time.Time
+	Finished time.Time // Zero until every channel is funded or has failed
+	Channels []BatchChannelInfo
+}
+
+// LedgerWithdrawalInfo identifies the objectives which withdraw from a ledger channel, by closing it and funding another
+// with the same counterparty which allocates the amount withdrawn less to us.
+type LedgerWithdrawalInfo struct {
+	CloseObjectiveId protocols.ObjectiveId
+	FundObjectiveId  protocols.ObjectiveId
+	ChannelId        types.Destination // The ledger channel which replaces the one closed
+}
+
+// SubscriptionStatus is the state of a subscription paid through a payment channel.
+type SubscriptionStatus string
+
+const (
+	SubscriptionProposed  SubscriptionStatus = "Proposed"  // The subscription awaits approval by the payee's node
+	SubscriptionActive    SubscriptionStatus = "Active"    // Payments are made as they fall due
+	SubscriptionEnded     SubscriptionStatus = "Ended"     // Every payment up to the cap has been made
+	SubscriptionCancelled SubscriptionStatus = "Cancelled" // The subscription was cancelled, as described by the Reason
+	SubscriptionRejected  SubscriptionStatus = "Rejected"  // The payee rejected the subscription
+)
+
+// SubscriptionInfo describes a subscription: Amount paid through the payment channel every Interval, up to Cap.
+type SubscriptionInfo struct {
+	ID          protocols.ObjectiveId
+	ChannelId   types.Destination
+	Payer       types.Address
+	Payee       types.Address
+	Amount      *hexutil.Big
+	Interval    time.Duration
+	Cap         *hexutil.Big
+	Started     time.Time
+	Status      SubscriptionStatus
+	Paid        *hexutil.Big // The amount paid so far. Only the payer's node knows it: it is nil on the payee's node.
+	NextPayment time.Time    // Zero once the subscription is over
+	CancelledBy types.Address
+	Reason      string `json:",omitempty"`
+}
+
+// EscrowStatus is the state of an escrow paid through a payment channel.
+type EscrowStatus string
+
+const (
+	EscrowProposed  EscrowStatus = "Proposed"  // The escrow awaits approval by the payee's node
+	EscrowOpen      EscrowStatus = "Open"      // The escrow awaits a result
+	EscrowDelivered EscrowStatus = "Delivered" // The payee has delivered a result, which awaits release by the payer's node
+	EscrowReleased  EscrowStatus = "Released"  // The result matched the commitment, and the amount is paid
+	EscrowRefused   EscrowStatus = "Refused"   // The result did not match the commitment, and nothing is paid
+	EscrowExpired   EscrowStatus = "Expired"   // No result was delivered by the deadline, and nothing is paid
+	EscrowDisputed  EscrowStatus = "Disputed"  // The payee disputed the escrow, closing the payment channel
+	EscrowRejected  EscrowStatus = "Rejected"  // The payee rejected the escrow
+)
+
+// EscrowInfo describes an escrow: Amount paid through the payment channel for a result whose keccak256 hash is
+// Commitment, delivered by Deadline.
+type EscrowInfo struct {
+	ID         protocols.ObjectiveId
+	ChannelId  types.Destination
+	Payer      types.Address
+	Payee      types.Address
+	Amount     *hexutil.Big
+	Commitment types.Bytes32
+	Deadline   time.Time
+	Status     EscrowStatus
+	Result     hexutil.Bytes `json:",omitempty"`
+	Paid       bool          // Whether the payer's node has paid the released amount. It is false on the payee's node.
+	Reason     string        `json:",omitempty"`
+}
+
+// LedgerCapacityInfo reports that our free capacity in a ledger channel, the fraction of its funds of an Asset which
+// we may commit to new payment channels, has crossed a Threshold: downwards if Below, else upwards.
+type LedgerCapacityInfo struct {
+	ID        types.Destination
+	Asset     types.Address
+	Free      *hexutil.Big // Our balance, which is not locked in the guarantees of payment channels
+	Locked    *hexutil.Big // The amount locked in the guarantees of payment channels, by either participant
+	Total     *hexutil.Big // The channel's funds
+	Threshold float64
+	Below     bool
 }
 
 // LedgerChannelBalance contains the balance of a ledger channel
@@ -58,12 +272,12 @@ func (lcb LedgerChannelBalance) Equal(other LedgerChannelBalance) bool {
 
 // Equal returns true if the other LedgerChannelInfo is equal to this one
 func (li LedgerChannelInfo) Equal(other LedgerChannelInfo) bool {
-	return li.ID == other.ID && li.Status == other.Status && li.Balance.Equal(other.Balance)
+	return li.ID == other.ID && li.Status == other.Status && li.Balance.Equal(other.Balance) && maps.Equal(li.Tags, other.Tags) && li.Memo == other.Memo
 }
 
 // Equal returns true if the other PaymentChannelInfo is equal to this one
 func (pci PaymentChannelInfo) Equal(other PaymentChannelInfo) bool {
-	return pci.ID == other.ID && pci.Status == other.Status && pci.Balance.Equal(other.Balance)
+	return pci.ID == other.ID && pci.Status == other.Status && pci.Balance.Equal(other.Balance) && maps.Equal(pci.Tags, other.Tags) && pci.Memo == other.Memo
 }
 
 // Equal returns true if the other PaymentChannelBalance is equal to this one
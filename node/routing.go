@@ -0,0 +1,96 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrNoRoute is returned when no intermediary is able to route a virtual channel.
+const ErrNoRoute = types.ConstError("no intermediary can route the payment channel")
+
+// PeerStats returns how responsive and reliable each peer we have exchanged messages with has been.
+func (n *Node) PeerStats() map[types.Address]engine.PeerStats {
+	return n.engine.PeerStats()
+}
+
+// SelectIntermediary chooses an intermediary to route a virtual channel, funded with amount of asset, to counterParty.
+// Each peer we have a ledger channel with is asked for a quote, and the healthiest of those able to route the channel
+// (see engine.PeerStats.Healthier) is chosen, preferring the lowest fee between equally healthy peers.
+// Peers which do not quote within QuoteTimeout (or before ctx is done) are not considered.
+func (n *Node) SelectIntermediary(ctx context.Context, counterParty types.Address, asset types.Address, amount *big.Int) (protocols.Quote, error) {
+	ledgers, err := n.store.GetAllConsensusChannels()
+	if err != nil {
+		return protocols.Quote{}, err
+	}
+	candidates := []types.Address{}
+	for _, ledger := range ledgers {
+		for _, p := range ledger.Participants() {
+			if p != *n.Address && p != counterParty {
+				candidates = append(candidates, p)
+			}
+		}
+	}
+
+	quotes := make([]protocols.Quote, len(candidates))
+	wg := sync.WaitGroup{}
+	for i, candidate := range candidates {
+		wg.Add(1)
+		go func(i int, candidate types.Address) {
+			defer wg.Done()
+			quote, err := n.GetQuoteContext(ctx, candidate, counterParty, asset, amount)
+			if err == nil {
+				quotes[i] = quote
+			}
+		}(i, candidate)
+	}
+	wg.Wait()
+
+	stats := n.PeerStats()
+	var best *protocols.Quote
+	for i := range quotes {
+		q := &quotes[i]
+		if !q.CanRoute {
+			continue
+		}
+		if best == nil || betterRoute(*q, stats[q.Intermediary], *best, stats[best.Intermediary]) {
+			best = q
+		}
+	}
+	if best == nil {
+		return protocols.Quote{}, fmt.Errorf("%w: to %s among %d candidates", ErrNoRoute, counterParty, len(candidates))
+	}
+	return *best, nil
+}
+
+// betterRoute returns true if routing through the intermediary quoting q is better than routing through the one
+// quoting other.
+func betterRoute(q protocols.Quote, stats engine.PeerStats, other protocols.Quote, otherStats engine.PeerStats) bool {
+	if stats.Healthier(otherStats) {
+		return true
+	}
+	if otherStats.Healthier(stats) {
+		return false
+	}
+	return q.Fee.Cmp(other.Fee) < 0
+}
+
+// CreatePaymentChannelAutoRoute is like CreatePaymentChannel, but chooses the intermediary with SelectIntermediary.
+// The virtual channel is assumed to be funded by the first allocation of the first asset of the outcome.
+func (n *Node) CreatePaymentChannelAutoRoute(ctx context.Context, CounterParty types.Address, ChallengeDuration uint32, Outcome outcome.Exit) (virtualfund.ObjectiveResponse, error) {
+	if len(Outcome) == 0 || len(Outcome[0].Allocations) == 0 {
+		return virtualfund.ObjectiveResponse{}, fmt.Errorf("outcome has no allocations to route")
+	}
+	quote, err := n.SelectIntermediary(ctx, CounterParty, Outcome[0].Asset, Outcome[0].Allocations[0].Amount)
+	if err != nil {
+		return virtualfund.ObjectiveResponse{}, err
+	}
+	return n.CreatePaymentChannelWithOptions(ctx, []types.Address{quote.Intermediary}, CounterParty, ChallengeDuration, Outcome, CreateChannelOptions{routingFee: quote.Fee})
+}
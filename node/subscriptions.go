@@ -0,0 +1,226 @@
+package node
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/subscription"
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// CreateSubscription starts a subscription paying amount through the payment channel every interval, until limit has
+// been paid. We must be the payer of the channel. The first payment is made straight away, and the rest as they fall
+// due, until the subscription is cancelled by either party.
+func (n *Node) CreateSubscription(ctx context.Context, channelId types.Destination, amount *big.Int, interval time.Duration, limit *big.Int) (query.SubscriptionInfo, error) {
+	info, err := n.GetPaymentChannel(channelId)
+	if err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+	if info.Status != query.Open {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: channel %s is %s", ErrInvalidSubscription, channelId, info.Status)
+	}
+	if info.Balance.Payer != *n.Address {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: we do not pay through channel %s", ErrInvalidSubscription, channelId)
+	}
+
+	terms := subscription.Terms{
+		ChannelId: channelId,
+		Payer:     info.Balance.Payer,
+		Payee:     info.Balance.Payee,
+		Amount:    amount,
+		Interval:  interval,
+		Cap:       limit,
+		Start:     time.Now(),
+		Nonce:     rand.Uint64(),
+	}
+	if err := terms.Validate(); err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+
+	request := subscription.NewObjectiveRequest(terms)
+	if err := n.submitObjectiveRequest(ctx, request); err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+	id := request.Id(*n.Address, n.chainId)
+	started, err := n.GetSubscription(id)
+	if err != nil {
+		return query.SubscriptionInfo{}, fmt.Errorf("could not start subscription %s: %w", id, err)
+	}
+	n.startSubscription(id, terms)
+	return started, nil
+}
+
+// GetSubscription returns the subscription with the given id, which we pay or are paid by.
+func (n *Node) GetSubscription(id protocols.ObjectiveId) (query.SubscriptionInfo, error) {
+	if !subscription.IsSubscriptionObjective(id) {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	o, err := n.store.GetObjectiveById(id)
+	if errors.Is(err, store.ErrNoSuchObjective) {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	if err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+	s, ok := o.(*subscription.Objective)
+	if !ok {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, id)
+	}
+	return n.subscriptionInfo(s)
+}
+
+// CancelSubscription ends the subscription with the given id, on behalf of its payer or payee. No further payments
+// are made, and the other party is told the reason.
+func (n *Node) CancelSubscription(ctx context.Context, id protocols.ObjectiveId, reason string) (query.SubscriptionInfo, error) {
+	info, err := n.GetSubscription(id)
+	if err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+	if info.Status != query.SubscriptionActive {
+		return query.SubscriptionInfo{}, fmt.Errorf("%w: %s is %s", ErrSubscriptionNotActive, id, info.Status)
+	}
+	if err := n.submitObjectiveRequest(ctx, subscription.NewCancelRequest(id, reason)); err != nil {
+		return query.SubscriptionInfo{}, err
+	}
+	return n.GetSubscription(id)
+}
+
+// subscriptionInfo describes the subscription.
+func (n *Node) subscriptionInfo(s *subscription.Objective) (query.SubscriptionInfo, error) {
+	info := query.SubscriptionInfo{
+		ID:          s.Id(),
+		ChannelId:   s.Terms.ChannelId,
+		Payer:       s.Terms.Payer,
+		Payee:       s.Terms.Payee,
+		Amount:      (*hexutil.Big)(s.Terms.Amount),
+		Interval:    s.Terms.Interval,
+		Cap:         (*hexutil.Big)(s.Terms.Cap),
+		Started:     s.Terms.Start,
+		CancelledBy: s.CancelledBy,
+		Reason:      s.Reason,
+	}
+	switch {
+	case s.Status == protocols.Unapproved:
+		info.Status = query.SubscriptionProposed
+	case s.Status == protocols.Rejected:
+		info.Status = query.SubscriptionRejected
+	case s.Cancelled() && s.Reason == subscription.ReasonCapReached:
+		info.Status = query.SubscriptionEnded
+	case s.Cancelled():
+		info.Status = query.SubscriptionCancelled
+	default:
+		info.Status = query.SubscriptionActive
+		if due := s.Terms.PaymentsDue(time.Now()); due < s.Terms.Payments() {
+			info.NextPayment = s.Terms.PaymentTime(due)
+		}
+	}
+
+	if s.IsPayer() {
+		paid, err := n.subscriptionPayments(s.Id(), s.Terms)
+		if err != nil {
+			return query.SubscriptionInfo{}, err
+		}
+		info.Paid = (*hexutil.Big)(new(big.Int).Mul(s.Terms.Amount, new(big.Int).SetUint64(paid)))
+	}
+	return info, nil
+}
+
+// subscriptionPayments returns the number of payments we have made for the subscription. They are made in turn, so it
+// is the number of the first payment we have no record of.
+func (n *Node) subscriptionPayments(id protocols.ObjectiveId, terms subscription.Terms) (uint64, error) {
+	var i uint64
+	for ; i < terms.Payments(); i++ {
+		_, ok, err := n.store.GetPaymentRecord(terms.ChannelId, subscription.PaymentId(id, i))
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			break
+		}
+	}
+	return i, nil
+}
+
+// resumeSubscriptions resumes paying the active subscriptions we pay, after a restart.
+func (n *Node) resumeSubscriptions() error {
+	ids, err := n.store.GetObjectiveIdsByPrefix(subscription.ObjectivePrefix)
+	if err != nil {
+		return err
+	}
+	for _, id := range ids {
+		o, err := n.store.GetObjectiveById(id)
+		if err != nil {
+			return err
+		}
+		s, ok := o.(*subscription.Objective)
+		if ok && s.IsPayer() && s.Status == protocols.Approved && !s.Cancelled() {
+			n.startSubscription(id, s.Terms)
+		}
+	}
+	return nil
+}
+
+// startSubscription pays the subscription in the background.
+func (n *Node) startSubscription(id protocols.ObjectiveId, terms subscription.Terms) {
+	n.backgroundTasksWg.Add(1)
+	go func() {
+		defer n.backgroundTasksWg.Done()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			select {
+			case <-n.stopBackgroundTasks:
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+		n.paySubscription(ctx, id, terms)
+	}()
+}
+
+// paySubscription makes each payment of the subscription as it falls due, until the subscription is cancelled or every
+// payment up to its cap has been made, when it ends the subscription. Each payment has its own payment id, so that
+// payments made before a restart are not made again. A payment which fails cancels the subscription.
+func (n *Node) paySubscription(ctx context.Context, id protocols.ObjectiveId, terms subscription.Terms) {
+	cancelled := n.ObjectiveCompleteChan(id)
+	for i := uint64(0); i < terms.Payments(); i++ {
+		select {
+		case <-time.After(time.Until(terms.PaymentTime(i))):
+		case <-cancelled:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		paymentId := subscription.PaymentId(id, i)
+		if _, paid, err := n.store.GetPaymentRecord(terms.ChannelId, paymentId); err == nil && paid {
+			continue
+		}
+		err := n.PayWithOptions(ctx, terms.ChannelId, terms.Amount, PayOptions{PaymentId: paymentId, Context: string(id)})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			n.logger.Warn("Cancelling subscription after a failed payment", "subscription", id, "payment", i, "error", err)
+			n.endSubscription(ctx, id, fmt.Sprintf("payment failed: %v", err))
+			return
+		}
+	}
+	n.endSubscription(ctx, id, subscription.ReasonCapReached)
+}
+
+// endSubscription cancels the subscription, unless it has already been cancelled.
+func (n *Node) endSubscription(ctx context.Context, id protocols.ObjectiveId, reason string) {
+	_, err := n.CancelSubscription(ctx, id, reason)
+	if err != nil && !errors.Is(err, ErrSubscriptionNotActive) {
+		n.logger.Error("Could not end subscription", "subscription", id, "reason", reason, "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// Limits on the tags attached to a channel, which keep them from bloating the store and every response about the channel.
+const (
+	MaxChannelTags      = 16
+	MaxChannelTagLength = 256 // The maximum length, in bytes, of a tag's key or value
+)
+
+// CreateChannelOptions are optional settings for a request to create a channel.
+type CreateChannelOptions struct {
+	// Force creates the channel even if an equivalent request was made recently (see EnableDuplicateRequestDetection)
+	Force bool
+	// Tags are attached to the channel, and included in every query response and notification about it, so that
+	// applications can relate channels to their own records (such as an order or customer id) without keeping a
+	// mapping of their own. Tags are only held by this node: they are never shared with peers.
+	Tags map[string]string
+	// Memo describes why the channel is requested, such as "CDN traffic for site X". Unlike tags, it is sent to the
+	// counterparty (and any intermediaries) with the proposal, for their policy makers to consider, and is included in
+	// their query responses about the channel. It is never put on chain. It may be at most protocols.MaxMemoLength bytes.
+	Memo string
+	// Probe asks the counterparty whether it would fund the channel (see ProbeCounterparty) before the objective is
+	// created, failing with a *ProbeRefusedError if it would not, rather than waiting on a channel which never opens.
+	Probe bool
+
+	routingFee *big.Int // the fee quoted by the intermediary the channel is routed through, if it was chosen by quote
+}
+
+func validateChannelTags(tags map[string]string) error {
+	if len(tags) > MaxChannelTags {
+		return fmt.Errorf("%w: %d tags exceeds the maximum of %d", ErrInvalidChannelTags, len(tags), MaxChannelTags)
+	}
+	for k, v := range tags {
+		if k == "" {
+			return fmt.Errorf("%w: empty key", ErrInvalidChannelTags)
+		}
+		if len(k) > MaxChannelTagLength || len(v) > MaxChannelTagLength {
+			return fmt.Errorf("%w: tag %q exceeds the maximum length of %d bytes", ErrInvalidChannelTags, k, MaxChannelTagLength)
+		}
+	}
+	return nil
+}
+
+func validateChannelMemo(memo string) error {
+	if len(memo) > protocols.MaxMemoLength {
+		return fmt.Errorf("%w: the memo exceeds the maximum length of %d bytes", ErrInvalidChannelMemo, protocols.MaxMemoLength)
+	}
+	return nil
+}
+
+// submitTaggedObjectiveRequest tags the channel the request creates, then submits the request. The tags are removed if
+// the request could not be submitted.
+func (n *Node) submitTaggedObjectiveRequest(ctx context.Context, or protocols.ObjectiveRequest, channelId types.Destination, tags map[string]string) error {
+	if len(tags) > 0 {
+		if err := n.store.SetChannelTags(channelId, tags); err != nil {
+			return err
+		}
+	}
+	if err := n.submitObjectiveRequest(ctx, or); err != nil {
+		if len(tags) > 0 {
+			_ = n.store.SetChannelTags(channelId, nil)
+		}
+		return err
+	}
+	return nil
+}
+
+// FindChannelsByTag returns the ids of the ledger and payment channels which were created with the given tag.
+func (n *Node) FindChannelsByTag(key, value string) ([]types.Destination, error) {
+	return n.store.FindChannelsByTag(key, value)
+}
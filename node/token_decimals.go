@@ -0,0 +1,55 @@
+package node
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// tokenDecimalsTimeout bounds how long a query waits for the chain service to report the decimals of an asset.
+const tokenDecimalsTimeout = 5 * time.Second
+
+// tokenDecimals caches the decimals of the assets held by channels, as reported by the chain service. Decimals cannot
+// change once a token is deployed, so they are fetched at most once for each asset.
+type tokenDecimals struct {
+	metadata chainservice.TokenMetadata // nil if the chain service does not report decimals
+
+	mu       sync.RWMutex
+	decimals map[types.Address]uint8
+}
+
+// of returns the decimals of asset, and false if they are not known.
+func (td *tokenDecimals) of(asset types.Address) (uint8, bool) {
+	if td.metadata == nil {
+		return 0, false
+	}
+	td.mu.RLock()
+	d, ok := td.decimals[asset]
+	td.mu.RUnlock()
+	if ok {
+		return d, true
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenDecimalsTimeout)
+	defer cancel()
+	d, err := td.metadata.TokenDecimals(ctx, asset)
+	if err != nil {
+		return 0, false
+	}
+	td.mu.Lock()
+	defer td.mu.Unlock()
+	td.decimals[asset] = d
+	return d, true
+}
+
+// describePaymentChannel returns the info with the decimals of its asset, if they are known.
+func (td *tokenDecimals) describePaymentChannel(info query.PaymentChannelInfo) query.PaymentChannelInfo {
+	if d, ok := td.of(info.Balance.AssetAddress); ok {
+		info.Balance.Decimals = &d
+	}
+	return info
+}
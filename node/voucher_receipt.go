@@ -0,0 +1,40 @@
+package node
+
+import (
+	"math/big"
+	"sync"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// receiptLocks is the number of locks a serializedReceipts spreads channels over.
+const receiptLocks = 64
+
+// serializedReceipts wraps the node's voucher manager so that the vouchers of each channel are received one at a time,
+// whether they arrive from peers or through ReceiveVoucher. A service may then run several payment proxies against the
+// node, each receiving vouchers for the same channels: each voucher is credited exactly once, to whichever caller
+// received it first, and every caller learns the exact amount its own voucher added. Voucher managers need not be safe
+// for concurrent receipt on a channel themselves.
+type serializedReceipts struct {
+	payments.VoucherManagerApi
+	locks [receiptLocks]sync.Mutex
+}
+
+func newSerializedReceipts(vm payments.VoucherManagerApi) *serializedReceipts {
+	return &serializedReceipts{VoucherManagerApi: vm}
+}
+
+// Receive receives the voucher while no other voucher for its channel is being received. Waiting receipts are not
+// necessarily made in the order they arrived.
+func (s *serializedReceipts) Receive(voucher payments.Voucher) (total *big.Int, delta *big.Int, err error) {
+	lock := s.lock(voucher.ChannelId)
+	lock.Lock()
+	defer lock.Unlock()
+	return s.VoucherManagerApi.Receive(voucher)
+}
+
+func (s *serializedReceipts) lock(channelId types.Destination) *sync.Mutex {
+	// Channel ids are hashes, so any byte is uniformly distributed
+	return &s.locks[int(channelId[len(channelId)-1])%receiptLocks]
+}
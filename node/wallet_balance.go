@@ -0,0 +1,71 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrWalletBalanceUnavailable is returned by EnableWalletBalanceCheck when the chain service cannot report the funding
+// wallet's balances.
+const ErrWalletBalanceUnavailable = types.ConstError("chain service does not report wallet balances")
+
+// walletBalanceCheck checks that the funding wallet can afford our deposit into a ledger channel before it is proposed.
+type walletBalanceCheck struct {
+	enabled atomic.Bool
+	wallet  chainservice.FundingWallet // nil if the chain service does not implement it
+}
+
+// EnableWalletBalanceCheck makes CreateLedgerChannel check, before proposing a channel, that the chain service's
+// funding wallet holds our deposit of each asset in the outcome, plus the gas to make the deposits. If it does not, a
+// *WalletBalanceError is returned rather than creating an objective which would stall when depositing.
+func (n *Node) EnableWalletBalanceCheck() error {
+	if n.walletBalanceCheck.wallet == nil {
+		return ErrWalletBalanceUnavailable
+	}
+	n.walletBalanceCheck.enabled.Store(true)
+	return nil
+}
+
+// checkWalletBalance returns a *WalletBalanceError if the check is enabled and the funding wallet cannot afford our
+// deposits into a ledger channel with the given outcome.
+func (n *Node) checkWalletBalance(ctx context.Context, o outcome.Exit) error {
+	if !n.walletBalanceCheck.enabled.Load() {
+		return nil
+	}
+	wallet := n.walletBalanceCheck.wallet
+
+	required := o.TotalAllocatedFor(types.AddressToDestination(*n.Address))
+	gas := big.NewInt(0)
+	for asset, amount := range required {
+		if amount.Sign() == 0 {
+			continue
+		}
+		cost, err := wallet.DepositGasCost(ctx, asset)
+		if err != nil {
+			return fmt.Errorf("could not estimate cost of depositing %s: %w", asset, err)
+		}
+		gas.Add(gas, cost)
+	}
+	native := types.Address{}
+	required = required.Add(types.Funds{native: gas})
+
+	for asset, amount := range required {
+		if amount.Sign() == 0 {
+			continue
+		}
+		balance, err := wallet.WalletBalance(ctx, asset)
+		if err != nil {
+			return fmt.Errorf("could not read wallet balance of %s: %w", asset, err)
+		}
+		if balance.Cmp(amount) < 0 {
+			return &WalletBalanceError{Asset: asset, Required: amount, Available: balance}
+		}
+	}
+	return nil
+}
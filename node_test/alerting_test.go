@@ -0,0 +1,128 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/alerts"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// recordingSink records the alerts sent to it.
+type recordingSink struct {
+	mu     sync.Mutex
+	alerts []alerts.Alert
+}
+
+func (rs *recordingSink) Send(_ context.Context, a alerts.Alert) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.alerts = append(rs.alerts, a)
+	return nil
+}
+
+// find returns the first alert of the kind sent to the sink.
+func (rs *recordingSink) find(kind alerts.Kind) (alerts.Alert, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, a := range rs.alerts {
+		if a.Kind == kind {
+			return a, true
+		}
+	}
+	return alerts.Alert{}, false
+}
+
+// disconnectableChainService is a mock chain service which reports whether it is connected to the chain.
+type disconnectableChainService struct {
+	*chainservice.MockChainService
+	disconnected atomic.Bool
+}
+
+func (dcs *disconnectableChainService) Connected() bool {
+	return !dcs.disconnected.Load()
+}
+
+// awaitAlert waits for an alert of the kind to be sent to the sink.
+func awaitAlert(t *testing.T, sink *recordingSink, kind alerts.Kind) alerts.Alert {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for time.Now().Before(deadline) {
+		if a, ok := sink.find(kind); ok {
+			return a
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for a %s alert", kind)
+	return alerts.Alert{}
+}
+
+// TestAlerts checks that stuck objectives, a lost connection to the chain, and ledger channels whose guarantees lock
+// more than the limit are alerted.
+func TestAlerts(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	aliceChain := &disconnectableChainService{MockChainService: chainservice.NewMockChainService(chain, ta.Alice.Address())}
+	alice, _ := setupNode(ta.Alice.PrivateKey, aliceChain, broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	sink := &recordingSink{}
+	alice.EnableAlerts(node.AlertOptions{
+		Sinks:                   []alerts.Sink{sink},
+		CheckInterval:           10 * time.Millisecond,
+		StuckObjectiveThreshold: 100 * time.Millisecond,
+		MaxLedgerExposure:       big.NewInt(0),
+	})
+
+	// The guarantee for the payment channel locks funds in the ledger channel with Irene
+	exposure := awaitAlert(t, sink, alerts.LedgerExposure)
+	if exposure.Severity != alerts.Warning {
+		t.Errorf("expected a warning, got %+v", exposure)
+	}
+
+	// Ivan's deposit is dropped, so the ledger channel proposed to him is never funded
+	ivan, _ := setupNode(ta.Ivan.PrivateKey, newDroppingChainService(chain, ta.Ivan.Address(), true), broker, 0, dataFolder)
+	defer closeNode(t, &ivan)
+	stuck, err := alice.CreateLedgerChannel(ta.Ivan.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Ivan.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := awaitAlert(t, sink, alerts.ObjectiveStuck); a.Subject != string(stuck.Id) {
+		t.Errorf("expected objective %s to be alerted as stuck, got %+v", stuck.Id, a)
+	}
+
+	if _, ok := sink.find(alerts.ChainDisconnected); ok {
+		t.Fatal("expected no alert while the chain service is connected")
+	}
+	aliceChain.disconnected.Store(true)
+	if a := awaitAlert(t, sink, alerts.ChainDisconnected); a.Severity != alerts.Critical {
+		t.Errorf("expected a critical alert, got %+v", a)
+	}
+}
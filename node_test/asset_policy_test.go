@@ -0,0 +1,54 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestAssetPolicy checks that a node only accepts objectives for channels holding the assets it allows, and refuses to
+// create channels holding other assets.
+func TestAssetPolicy(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	junk := common.HexToAddress("0x000000000000000000000000000000000000dead")
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder,
+		&engine.PermissivePolicy{AllowedAssets: []types.Address{{}}})
+	defer closeNode(t, &irene)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	rejected, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), junk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, rejected.Id); !errors.Is(err, node.ErrObjectiveRejected) {
+		t.Fatalf("expected the objective for a channel holding a junk token to be rejected, got %v", err)
+	}
+
+	_, err = irene.CreateLedgerChannel(ta.Bob.Address(), 0, initialLedgerOutcome(ta.Irene.Address(), ta.Bob.Address(), junk))
+	var assetErr *engine.AssetNotAllowedError
+	if !errors.As(err, &assetErr) || !errors.Is(err, node.ErrAssetNotAllowed) || assetErr.Asset != junk {
+		t.Fatalf("expected creating a channel holding a junk token to fail with an AssetNotAllowedError, got %v", err)
+	}
+
+	// The native token is allowed
+	openLedgerChannel(t, bob, irene, types.Address{})
+}
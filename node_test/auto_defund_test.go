@@ -0,0 +1,74 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestAutoDefund checks that a payee configured to do so closes a payment channel once its remaining funds fall to
+// the threshold, without either party requesting it.
+func TestAutoDefund(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	policy := &engine.PermissivePolicy{AutoDefund: true, AutoDefundAt: big.NewInt(100)}
+	bob, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder, policy)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	// A payment leaving more than the threshold does not close the channel
+	alice.Pay(response.ChannelId, big.NewInt(virtualChannelDeposit-200))
+	<-bob.ReceivedVouchers()
+	channel, err := bob.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel.Status != query.Open {
+		t.Fatalf("expected channel to remain open, got status %s", channel.Status)
+	}
+
+	alice.Pay(response.ChannelId, big.NewInt(150))
+	<-bob.ReceivedVouchers()
+
+	closeId := protocols.ObjectiveId(virtualdefund.ObjectivePrefix + response.ChannelId.String())
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+
+	for _, n := range []node.Node{alice, bob} {
+		channel, err := n.GetPaymentChannel(response.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if channel.Status != query.Complete {
+			t.Fatalf("expected channel to be closed, got status %s", channel.Status)
+		}
+		if channel.Balance.PaidSoFar.ToInt().Cmp(big.NewInt(virtualChannelDeposit-50)) != 0 {
+			t.Fatalf("expected %d to have been paid, got %v", virtualChannelDeposit-50, channel.Balance.PaidSoFar)
+		}
+	}
+}
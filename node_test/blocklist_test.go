@@ -0,0 +1,59 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestBlockPeer checks that channels with a blocked peer are refused, that peers stay blocked when the node restarts,
+// and that an unblocked peer may fund channels again.
+func TestBlockPeer(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	if _, err := alice.BlockPeer(ta.Irene.Address(), "", "spam"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	outcome := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{})
+	if _, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, outcome); !errors.Is(err, node.ErrPeerBlocked) {
+		t.Fatalf("expected a channel with a blocked peer to be refused with ErrPeerBlocked, got %v", err)
+	}
+	closeNode(t, &alice)
+
+	alice, _ = setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	blocked := alice.BlockedPeers()
+	if len(blocked) != 1 || blocked[0].Address != ta.Irene.Address() || blocked[0].Reason != "spam" {
+		t.Fatalf("expected irene to stay blocked after restarting, got %+v", blocked)
+	}
+
+	if unblocked, err := alice.UnblockPeer(ta.Irene.Address(), ""); err != nil || !unblocked {
+		t.Fatalf("expected irene to be unblocked, got %t (%v)", unblocked, err)
+	}
+	if unblocked, err := alice.UnblockPeer(ta.Irene.Address(), ""); err != nil || unblocked {
+		t.Fatalf("expected unblocking irene again to do nothing, got %t (%v)", unblocked, err)
+	}
+	response, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, response.Id); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,83 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestCapacityEvents checks that a node reports when its free capacity in a ledger channel falls below a threshold, as
+// funds are locked in a payment channel, and when it rises above the threshold again, as the payment channel is closed.
+func TestCapacityEvents(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	if err := alice.EnableCapacityEvents(0.3, 1.5); !errors.Is(err, node.ErrInvalidConfig) {
+		t.Fatalf("expected a threshold above 1 to be refused with ErrInvalidConfig, got %v", err)
+	}
+
+	ledger := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	// Alice holds half the funds of the ledger channel, which is below 0.6 but above 0.3
+	if err := alice.EnableCapacityEvents(0.3, 0.6); err != nil {
+		t.Fatal(err)
+	}
+	expectCapacityEvent(t, alice, ledger, 0.6, true)
+
+	// Locking 3/5 of alice's funds leaves her a fifth of the ledger channel
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	o := testdata.Outcomes.Create(ta.Alice.Address(), ta.Bob.Address(), 3_000_000, 0, types.Address{})
+	response, err := alice.CreatePaymentChannelContext(ctx, []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, response.Id); err != nil {
+		t.Fatal(err)
+	}
+	expectCapacityEvent(t, alice, ledger, 0.3, true)
+
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, closeId); err != nil {
+		t.Fatal(err)
+	}
+	expectCapacityEvent(t, alice, ledger, 0.3, false)
+}
+
+// expectCapacityEvent waits for the node to report that its free capacity in the ledger channel crossed the threshold.
+func expectCapacityEvent(t *testing.T, n node.Node, ledger types.Destination, threshold float64, below bool) query.LedgerCapacityInfo {
+	t.Helper()
+	select {
+	case info := <-n.CapacityEvents():
+		if info.ID != ledger || info.Threshold != threshold || info.Below != below {
+			t.Fatalf("expected ledger %s to cross %v with below=%t, got %+v", ledger, threshold, below, info)
+		}
+		return info
+	case <-time.After(defaultTimeout):
+		t.Fatalf("timed out waiting for ledger %s to cross %v", ledger, threshold)
+	}
+	return query.LedgerCapacityInfo{}
+}
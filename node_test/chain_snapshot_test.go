@@ -0,0 +1,67 @@
+package node_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+)
+
+// TestChainAndStoreSnapshot checks that nodes can be returned to a funded baseline, by restoring snapshots of the
+// mock chain and their stores, for each of several test cases which close the channel they share.
+func TestChainAndStoreSnapshot(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	snapshotFolder := filepath.Join(dataFolder, "snapshot")
+	defer os.RemoveAll(snapshotFolder)
+
+	open := func() (node.Node, node.Node) {
+		broker := messageservice.NewBroker()
+		alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+		irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+		return alice, irene
+	}
+
+	alice, irene := open()
+	ledgerId := openLedgerChannel(t, alice, irene, common.Address{})
+	closeNode(t, &alice)
+	closeNode(t, &irene)
+
+	chainSnapshot := chain.Snapshot()
+	if err := store.SnapshotDurableStores(dataFolder, snapshotFolder); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		chain.Restore(chainSnapshot)
+		if err := store.RestoreDurableStores(snapshotFolder, dataFolder); err != nil {
+			t.Fatal(err)
+		}
+
+		alice, irene := open()
+		ledger, err := alice.GetLedgerChannel(ledgerId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ledger.Status != query.Open {
+			t.Fatalf("test case %d: expected the restored ledger channel to be %s, got %s", i, query.Open, ledger.Status)
+		}
+
+		closeLedgerChannel(t, alice, irene, ledgerId)
+		closeNode(t, &alice)
+		closeNode(t, &irene)
+	}
+
+	if err := store.RestoreDurableStores(t.TempDir(), dataFolder); err == nil {
+		t.Fatal("expected an error restoring from a folder without a snapshot")
+	}
+}
@@ -0,0 +1,122 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	NitroAdjudicator "github.com/statechannels/go-nitro/node/engine/chainservice/adjudicator"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestChallengeChannel checks that a ledger channel is exited unilaterally once its counterparty has gone offline.
+func TestChallengeChannel(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+
+	// The channel's challenge finalizes a second after it is registered
+	response, err := alice.CreateLedgerChannel(ta.Bob.Address(), 1, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{response.Id})
+	closeNode(t, &bob)
+
+	events := chain.SubscribeToEvents(types.Address{})
+	id, err := alice.ChallengeChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-alice.ObjectiveCompleteChan(id):
+	case <-time.After(10 * time.Second):
+		t.Fatal("expected the challenge to complete")
+	}
+
+	registered, withdrawn := false, false
+	for len(events) > 0 {
+		switch event := (<-events).(type) {
+		case chainservice.ChallengeRegisteredEvent:
+			registered = registered || event.ChannelID() == response.ChannelId
+		case chainservice.AllocationUpdatedEvent:
+			withdrawn = withdrawn || event.ChannelID() == response.ChannelId && event.AssetAmount.Sign() == 0
+		}
+	}
+	if !registered || !withdrawn {
+		t.Errorf("expected the channel to be challenged and its funds withdrawn, got challenged %t and withdrawn %t", registered, withdrawn)
+	}
+}
+
+// TestStaleChallengeAnswered checks that a challenge registered with an old state is cleared with the latest state.
+func TestStaleChallengeAnswered(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, aliceStore := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	response, err := alice.CreateLedgerChannel(ta.Bob.Address(), 60, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{response.Id})
+	ledger, err := aliceStore.GetConsensusChannelById(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := ledger.SupportedSignedState().State()
+
+	// The channel is challenged with its prefund state, signed by both participants
+	stale := latest.Clone()
+	stale.TurnNum = 0
+	candidate := state.NewSignedState(stale)
+	for _, pk := range [][]byte{ta.Alice.PrivateKey, ta.Bob.PrivateKey} {
+		sig, err := stale.Sign(pk)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := candidate.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	challengerSig, err := NitroAdjudicator.SignChallengeMessage(stale, ta.Alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := chain.SubscribeToEvents(types.Address{})
+	if err := chain.SubmitTransaction(protocols.NewChallengeTransaction(response.ChannelId, candidate, []state.SignedState{}, challengerSig)); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if cleared, ok := event.(chainservice.ChallengeClearedEvent); ok && cleared.ChannelID() == response.ChannelId {
+				if cleared.NewTurnNumRecord() != latest.TurnNum {
+					t.Errorf("expected the challenge to be cleared with turn %d, got %d", latest.TurnNum, cleared.NewTurnNumRecord())
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the stale challenge to be cleared")
+		}
+	}
+}
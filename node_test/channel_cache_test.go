@@ -0,0 +1,85 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestChannelCache checks that cached payment channel info serves repeated lookups, and is invalidated when payments
+// update the channel.
+func TestChannelCache(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	// The ttl outlasts the test, so that only invalidation refreshes the info
+	alice.EnableChannelCache(10, time.Hour)
+	bob.EnableChannelCache(10, time.Hour)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	for i := 0; i < 3; i++ {
+		if _, err := alice.GetPaymentChannel(payment.ChannelId); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if stats := alice.ChannelCacheStats(); stats.Entries != 1 || stats.Hits < 2 || stats.HitRate <= 0 {
+		t.Errorf("expected repeated lookups to be served by the cache, got %+v", stats)
+	}
+
+	// Payments made through the engine, and vouchers created and received directly, invalidate the cached info
+	if err := alice.PayContext(context.Background(), payment.ChannelId, big.NewInt(5)); err != nil {
+		t.Fatal(err)
+	}
+	waitForPaidSoFar(t, bob, payment.ChannelId, big.NewInt(5))
+	voucher, err := alice.CreateVoucher(payment.ChannelId, big.NewInt(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ReceiveVoucher(voucher); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, n := range []node.Node{alice, bob} {
+		info, err := n.GetPaymentChannel(payment.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Balance.PaidSoFar.ToInt().Cmp(big.NewInt(12)) != 0 {
+			t.Errorf("%s: expected 12 to have been paid, got %s", n.Address, info.Balance.PaidSoFar.ToInt())
+		}
+	}
+
+	// Changing the info returned does not change the cached info
+	info, _ := alice.GetPaymentChannel(payment.ChannelId)
+	info.Balance.PaidSoFar.ToInt().SetInt64(0)
+	if info, _ := alice.GetPaymentChannel(payment.ChannelId); info.Balance.PaidSoFar.ToInt().Cmp(big.NewInt(12)) != 0 {
+		t.Errorf("expected the cached info to be unchanged, got %s paid", info.Balance.PaidSoFar.ToInt())
+	}
+}
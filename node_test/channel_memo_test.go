@@ -0,0 +1,108 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// memoPolicy approves every objective, recording the memos it is shown
+type memoPolicy struct {
+	mu    sync.Mutex
+	memos map[protocols.ObjectiveId]string
+}
+
+func (p *memoPolicy) ShouldApprove(o protocols.Objective) bool {
+	if mc, ok := o.(protocols.MemoCarrier); ok {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.memos[o.Id()] = mc.Memo()
+	}
+	return true
+}
+
+func (p *memoPolicy) memo(id protocols.ObjectiveId) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.memos[id]
+}
+
+// TestChannelMemo checks that the memo given when a channel is created is shown to the policy makers of the
+// counterparty and intermediaries, and included in every participant's query responses.
+func TestChannelMemo(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	irenePolicy := &memoPolicy{memos: map[protocols.ObjectiveId]string{}}
+	bobPolicy := &memoPolicy{memos: map[protocols.ObjectiveId]string{}}
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder, bobPolicy)
+	defer closeNode(t, &bob)
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder, irenePolicy)
+	defer closeNode(t, &irene)
+
+	ledgerMemo := "CDN traffic for site X"
+	ledgerOutcome := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{})
+	ledger, err := alice.CreateLedgerChannelWithOptions(context.Background(), ta.Irene.Address(), 0, ledgerOutcome, node.CreateChannelOptions{Memo: ledgerMemo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, irene, nil, []protocols.ObjectiveId{ledger.Id})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	if memo := irenePolicy.memo(ledger.Id); memo != ledgerMemo {
+		t.Errorf("expected the counterparty's policy maker to be shown the memo %q, got %q", ledgerMemo, memo)
+	}
+	for _, n := range []node.Node{alice, irene} {
+		info, err := n.GetLedgerChannel(ledger.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Memo != ledgerMemo {
+			t.Errorf("expected %s to report the memo %q, got %q", n.Address, ledgerMemo, info.Memo)
+		}
+	}
+
+	paymentMemo := "video stream 42"
+	payment, err := alice.CreatePaymentChannelWithOptions(context.Background(), []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}), node.CreateChannelOptions{Memo: paymentMemo})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	for name, policy := range map[string]*memoPolicy{"intermediary": irenePolicy, "counterparty": bobPolicy} {
+		if memo := policy.memo(payment.Id); memo != paymentMemo {
+			t.Errorf("expected the %s's policy maker to be shown the memo %q, got %q", name, paymentMemo, memo)
+		}
+	}
+	for _, n := range []node.Node{alice, bob} {
+		info, err := n.GetPaymentChannel(payment.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Memo != paymentMemo {
+			t.Errorf("expected %s to report the memo %q, got %q", n.Address, paymentMemo, info.Memo)
+		}
+	}
+
+	_, err = alice.CreatePaymentChannelWithOptions(context.Background(), []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}), node.CreateChannelOptions{Memo: strings.Repeat("x", protocols.MaxMemoLength+1)})
+	if !errors.Is(err, node.ErrInvalidChannelMemo) {
+		t.Fatalf("expected %v, got %v", node.ErrInvalidChannelMemo, err)
+	}
+}
@@ -0,0 +1,103 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestGetOrCreatePaymentChannel checks that an open payment channel with enough remaining funds is reused, that
+// concurrent calls create a single channel, and that a channel is created once the open one runs low.
+func TestGetOrCreatePaymentChannel(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	paymentOutcome := initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{})
+	getOrCreate := func() (virtualfund.ObjectiveResponse, bool) {
+		t.Helper()
+		response, created, err := alice.GetOrCreatePaymentChannel(context.Background(), intermediaries, ta.Bob.Address(), 0, paymentOutcome)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return response, created
+	}
+
+	// Concurrent calls create a single channel, which the others wait on
+	responses := make([]virtualfund.ObjectiveResponse, 3)
+	created := make([]bool, 3)
+	wg := sync.WaitGroup{}
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, c, err := alice.GetOrCreatePaymentChannel(context.Background(), intermediaries, ta.Bob.Address(), 0, paymentOutcome)
+			if err != nil {
+				t.Error(err)
+			}
+			responses[i], created[i] = response, c
+		}(i)
+	}
+	wg.Wait()
+	creations := 0
+	for i, response := range responses {
+		if response != responses[0] {
+			t.Fatalf("expected concurrent calls to return %+v, got %+v", responses[0], response)
+		}
+		if created[i] {
+			creations++
+		}
+	}
+	if creations != 1 {
+		t.Fatalf("expected a single channel to be created, got %d", creations)
+	}
+	first := responses[0]
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{first.Id})
+
+	// The open channel is reused while it has enough remaining funds
+	reused, c := getOrCreate()
+	if c || reused.ChannelId != first.ChannelId || reused.Id != "" {
+		t.Fatalf("expected the open channel %s to be reused, got %+v (created %v)", first.ChannelId, reused, c)
+	}
+	info, ok, err := alice.FindPaymentChannel(ta.Bob.Address(), types.Address{}, big.NewInt(virtualChannelDeposit))
+	if err != nil || !ok || info.ID != first.ChannelId {
+		t.Fatalf("expected to find channel %s, got %+v (found %v, error %v)", first.ChannelId, info, ok, err)
+	}
+	if _, ok, _ := alice.FindPaymentChannel(ta.Bob.Address(), types.Address{1}, big.NewInt(0)); ok {
+		t.Error("expected no channel to be found for another asset")
+	}
+
+	// Once it cannot pay what the outcome allocates, another channel is created
+	if err := alice.PayContext(context.Background(), first.ChannelId, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	waitForPaidSoFar(t, bob, first.ChannelId, big.NewInt(1))
+	second, c := getOrCreate()
+	if !c || second.ChannelId == first.ChannelId {
+		t.Fatalf("expected a new channel to be created, got %+v (created %v)", second, c)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{second.Id})
+}
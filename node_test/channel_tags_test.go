@@ -0,0 +1,101 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestChannelTags checks that the tags attached to channels when they are created are included in query responses and
+// notifications, and can be searched for.
+func TestChannelTags(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerTags := map[string]string{"customerId": "c-1", "kind": "hub"}
+	ledgerOutcome := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{})
+	ledger, err := alice.CreateLedgerChannelWithOptions(context.Background(), ta.Irene.Address(), 0, ledgerOutcome, node.CreateChannelOptions{Tags: ledgerTags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, irene, nil, []protocols.ObjectiveId{ledger.Id})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	ledgerInfo, err := alice.GetLedgerChannel(ledger.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(ledgerTags, ledgerInfo.Tags); diff != "" {
+		t.Errorf("unexpected ledger channel tags (-want +got):\n%s", diff)
+	}
+	// Tags are only held by the node which attached them
+	if ireneInfo, _ := irene.GetLedgerChannel(ledger.ChannelId); ireneInfo.Tags != nil {
+		t.Errorf("expected the counterparty to hold no tags, got %v", ireneInfo.Tags)
+	}
+
+	paymentTags := map[string]string{"customerId": "c-1", "orderId": "o-7"}
+	payment, err := alice.CreatePaymentChannelWithOptions(context.Background(), []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}), node.CreateChannelOptions{Tags: paymentTags})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	updates := alice.PaymentChannelUpdatedChan(payment.ChannelId)
+	alice.Pay(payment.ChannelId, big.NewInt(1))
+	select {
+	case update := <-updates:
+		if diff := cmp.Diff(paymentTags, update.Tags); diff != "" {
+			t.Errorf("unexpected payment channel notification tags (-want +got):\n%s", diff)
+		}
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for a payment channel notification")
+	}
+
+	found, err := alice.FindChannelsByTag("customerId", "c-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []types.Destination{ledger.ChannelId, payment.ChannelId}
+	byId := func(a, b types.Destination) int { return slices.Compare(a[:], b[:]) }
+	slices.SortFunc(want, byId)
+	slices.SortFunc(found, byId)
+	if diff := cmp.Diff(want, found); diff != "" {
+		t.Errorf("unexpected tagged channels (-want +got):\n%s", diff)
+	}
+	if found, _ := alice.FindChannelsByTag("orderId", "o-8"); len(found) != 0 {
+		t.Errorf("expected no channels tagged with orderId o-8, got %v", found)
+	}
+
+	tooMany := map[string]string{}
+	for i := 0; i <= node.MaxChannelTags; i++ {
+		tooMany[string(rune('a'+i))] = ""
+	}
+	_, err = alice.CreatePaymentChannelWithOptions(context.Background(), []types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}), node.CreateChannelOptions{Tags: tooMany})
+	if !errors.Is(err, node.ErrInvalidChannelTags) {
+		t.Fatalf("expected %v, got %v", node.ErrInvalidChannelTags, err)
+	}
+}
@@ -0,0 +1,125 @@
+package node_test
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForCloseAll waits for the node to finish closing all its channels, returning the final progress.
+func waitForCloseAll(t *testing.T, n node.Node) query.CloseAllInfo {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for time.Now().Before(deadline) {
+		if progress := n.CloseAllProgress(); !progress.Finished.IsZero() {
+			return progress
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for all channels to be closed")
+	return query.CloseAllInfo{}
+}
+
+// TestCloseAllChannels checks that every open payment channel, and then every open ledger channel, is closed.
+func TestCloseAllChannels(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	started, err := alice.CloseAllChannels(node.CloseAllOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []query.ChannelCloseInfo{
+		{ID: response.ChannelId, Status: query.ClosePending},
+		{ID: ledgerId, Ledger: true, Status: query.ClosePending},
+	}
+	if len(started.Channels) != len(want) || started.Channels[0] != want[0] || started.Channels[1] != want[1] {
+		t.Fatalf("expected channels %+v to be closed, got %+v", want, started.Channels)
+	}
+
+	progress := waitForCloseAll(t, alice)
+	for _, c := range progress.Channels {
+		if c.Status != query.CloseComplete || c.ObjectiveId == "" {
+			t.Errorf("expected channel %s to be closed, got %+v", c.ID, c)
+		}
+	}
+	ledger, err := alice.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Status != query.Complete {
+		t.Errorf("expected the ledger channel to be closed, got status %s", ledger.Status)
+	}
+
+	// Once finished, there is nothing left to close
+	again, err := alice.CloseAllChannels(node.CloseAllOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.Channels) != 0 {
+		t.Errorf("expected no channels left to close, got %+v", again.Channels)
+	}
+}
+
+// TestCloseAllChannelsChallengesUnresponsivePeers checks that a ledger channel whose peer does not respond is
+// challenged, and that a wind-down cannot be started while one is under way.
+func TestCloseAllChannelsChallengesUnresponsivePeers(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	vanished := &atomic.Bool{}
+	irene := setupVanishingNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, dataFolder, &engine.PermissivePolicy{}, vanished)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, irene, types.Address{})
+	vanished.Store(true)
+
+	if _, err := alice.CloseAllChannels(node.CloseAllOptions{Timeout: time.Second, Challenge: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := alice.CloseAllChannels(node.CloseAllOptions{}); !errors.Is(err, node.ErrCloseAllInProgress) {
+		t.Errorf("expected ErrCloseAllInProgress, got %v", err)
+	}
+
+	progress := waitForCloseAll(t, alice)
+	if len(progress.Channels) != 1 || progress.Channels[0].ID != ledgerId || progress.Channels[0].Status != query.CloseChallenged {
+		t.Fatalf("expected the ledger channel %s to be challenged, got %+v", ledgerId, progress.Channels)
+	}
+	if progress.Channels[0].Error == "" {
+		t.Error("expected the reason the cooperative close failed to be reported")
+	}
+}
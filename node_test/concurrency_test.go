@@ -0,0 +1,132 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// heldMessageService delivers messages as normal, except while the test holds its gate,
+// when sending blocks until the gate is released.
+type heldMessageService struct {
+	messageservice.TestMessageService
+	gate *sync.RWMutex
+}
+
+func (hms heldMessageService) Send(msg protocols.Message) error {
+	hms.gate.RLock()
+	defer hms.gate.RUnlock()
+	return hms.TestMessageService.Send(msg)
+}
+
+// TestObjectiveConcurrencyLimits checks that an intermediary whose objective limit is reached rejects, or queues,
+// objectives proposed by peers until an in-progress objective completes.
+func TestObjectiveConcurrencyLimits(t *testing.T) {
+	for _, queueExcess := range []bool{false, true} {
+		name := "reject"
+		if queueExcess {
+			name = "queue"
+		}
+		t.Run(name, func(t *testing.T) {
+			chain := chainservice.NewMockChain()
+			broker := messageservice.NewBroker()
+
+			dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+			defer cleanup()
+
+			alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+			defer closeNode(t, &alice)
+			ivan, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder)
+			defer closeNode(t, &ivan)
+
+			gate := &sync.RWMutex{}
+			storeB, err := store.NewDurableStore(ta.Bob.PrivateKey, dataFolder, buntdb.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			held := heldMessageService{messageservice.NewTestMessageService(ta.Bob.Address(), broker, 0), gate}
+			bob := node.New(node.WithMessageService(held), node.WithChainService(chainservice.NewMockChainService(chain, ta.Bob.Address())), node.WithStore(storeB), node.WithPolicy(&engine.PermissivePolicy{}))
+			defer closeNode(t, &bob)
+
+			storeI, err := store.NewDurableStore(ta.Irene.PrivateKey, dataFolder, buntdb.Config{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			policy := &engine.PermissivePolicy{MaxObjectives: 1, QueueExcessObjectives: queueExcess}
+			irene := node.New(node.WithMessageService(messageservice.NewTestMessageService(ta.Irene.Address(), broker, 0)), node.WithChainService(chainservice.NewMockChainService(chain, ta.Irene.Address())), node.WithStore(storeI), node.WithPolicy(policy))
+			defer closeNode(t, &irene)
+
+			openLedgerChannel(t, alice, irene, types.Address{})
+			openLedgerChannel(t, irene, bob, types.Address{})
+
+			// Hold Bob's messages, so that the virtual funding objective remains in progress for Irene
+			gate.Lock()
+			response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			waitForConcurrencyStats(t, irene, func(s engine.ConcurrencyStats) bool { return s.InProgress[ta.Alice.Address()] == 1 })
+
+			ledger, err := ivan.CreateLedgerChannel(ta.Irene.Address(), 0, initialLedgerOutcome(ta.Ivan.Address(), ta.Irene.Address(), types.Address{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+			defer cancel()
+			if !queueExcess {
+				err = ivan.WaitForObjective(ctx, ledger.Id)
+				if !errors.Is(err, node.ErrObjectiveRejected) {
+					t.Fatalf("expected ivan's objective to be rejected, got %v", err)
+				}
+				gate.Unlock()
+				waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+				stats := irene.ConcurrencyStats()
+				if stats.TotalRejected != 1 || stats.TotalQueued != 0 {
+					t.Fatalf("unexpected concurrency stats %+v", stats)
+				}
+				return
+			}
+
+			waitForConcurrencyStats(t, irene, func(s engine.ConcurrencyStats) bool { return s.Queued == 1 })
+			gate.Unlock()
+
+			waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+			if err := ivan.WaitForObjective(ctx, ledger.Id); err != nil {
+				t.Fatal(err)
+			}
+			<-irene.ObjectiveCompleteChan(ledger.Id)
+
+			stats := irene.ConcurrencyStats()
+			if stats.TotalQueued != 1 || stats.TotalRejected != 0 || stats.Queued != 0 || len(stats.InProgress) != 0 {
+				t.Fatalf("unexpected concurrency stats %+v", stats)
+			}
+		})
+	}
+}
+
+// waitForConcurrencyStats polls the node's concurrency stats until they satisfy the condition, or fails the test.
+func waitForConcurrencyStats(t *testing.T, n node.Node, condition func(engine.ConcurrencyStats) bool) {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for !condition(n.ConcurrencyStats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for concurrency stats, got %+v", n.ConcurrencyStats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
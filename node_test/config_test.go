@@ -0,0 +1,75 @@
+package node_test
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+)
+
+// TestSetConfig checks that settings changed while a node runs take effect, are rejected together if any is invalid,
+// and are restored when the node restarts.
+func TestSetConfig(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	engineLevel := logging.Levels()[logging.ENGINE_MODULE]
+	defer func() { _ = logging.SetLevel(logging.ENGINE_MODULE, engineLevel) }()
+
+	policy := &engine.PermissivePolicy{MaxObjectives: 5}
+	alice, _ := setupNodeWithPolicy(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder, policy)
+
+	if got := alice.Config()[node.MaxObjectivesSetting]; got != "5" {
+		t.Fatalf("expected maxobjectives of 5, got %s", got)
+	}
+
+	_, err := alice.SetConfig(map[string]string{node.MaxObjectivesSetting: "2", "nosuchsetting": "1"})
+	if !errors.Is(err, node.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	_, err = alice.SetConfig(map[string]string{node.MaxObjectivesSetting: "2", node.QueueExcessObjectivesSetting: "maybe"})
+	if !errors.Is(err, node.ErrInvalidConfig) {
+		t.Fatalf("expected ErrInvalidConfig, got %v", err)
+	}
+	if limits := policy.ConcurrencyLimits(); limits.Global != 5 || limits.QueueExcess {
+		t.Fatalf("expected an invalid change to change nothing, got %+v", limits)
+	}
+
+	config, err := alice.SetConfig(map[string]string{
+		node.MaxObjectivesSetting:                          "2",
+		node.QueueExcessObjectivesSetting:                  "true",
+		node.LogLevelSetting + "." + logging.ENGINE_MODULE: "debug",
+		node.CountersignatureTimeoutSetting:                "1m",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config[node.MaxObjectivesSetting] != "2" || config[node.CountersignatureTimeoutSetting] != "1m0s" {
+		t.Errorf("unexpected config %v", config)
+	}
+	if limits := policy.ConcurrencyLimits(); limits.Global != 2 || !limits.QueueExcess {
+		t.Errorf("expected the policy's limits to change, got %+v", limits)
+	}
+	if level := logging.Levels()[logging.ENGINE_MODULE]; level != slog.LevelDebug {
+		t.Errorf("expected the engine to log at debug, got %s", level)
+	}
+	closeNode(t, &alice)
+
+	restartedPolicy := &engine.PermissivePolicy{MaxObjectives: 5}
+	alice, _ = setupNodeWithPolicy(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder, restartedPolicy)
+	defer closeNode(t, &alice)
+	if limits := restartedPolicy.ConcurrencyLimits(); limits.Global != 2 || !limits.QueueExcess {
+		t.Errorf("expected the changed limits to be restored, got %+v", limits)
+	}
+	if timeout := restartedPolicy.ProposalTimeout(); timeout.Minutes() != 1 {
+		t.Errorf("expected the changed timeout to be restored, got %s", timeout)
+	}
+}
@@ -0,0 +1,41 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestCancelledContextAbandonsObjective(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, storeA := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	outcome := initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{})
+	response, err := nodeA.CreateLedgerChannelContext(ctx, *nodeB.Address, 0, outcome)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v (response %+v)", err, response)
+	}
+	if channels, _ := storeA.GetChannelsByParticipant(*nodeB.Address); len(channels) != 0 {
+		t.Errorf("expected no channel to be created, got %d", len(channels))
+	}
+
+	// A live context should still result in a ledger channel
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+}
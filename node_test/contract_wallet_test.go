@@ -0,0 +1,135 @@
+package node_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// walletStore is a store for a node whose address is a contract wallet, which signs with the key of the wallet's owner.
+type walletStore struct {
+	store.Store
+	wallet types.Address
+}
+
+func (ws walletStore) GetAddress() *types.Address {
+	wallet := ws.wallet
+	return &wallet
+}
+
+// slowWalletChain is a chain service which is slow to tell whether an address is a contract, and counts how often it
+// is asked.
+type slowWalletChain struct {
+	*chainservice.MockChainService
+	delay time.Duration
+
+	mu     sync.Mutex
+	checks map[types.Address]int
+}
+
+func (c *slowWalletChain) IsContract(ctx context.Context, address types.Address) (bool, error) {
+	c.mu.Lock()
+	c.checks[address]++
+	c.mu.Unlock()
+	select {
+	case <-time.After(c.delay):
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	return c.MockChainService.IsContract(ctx, address)
+}
+
+// TestContractWalletLedgerChannel checks that a ledger channel can be opened and closed with a counterparty whose
+// address is an ERC-1271 contract wallet.
+func TestContractWalletLedgerChannel(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	// The wallet is owned by bob, who signs on its behalf
+	wallet := types.Address{'w', 'a', 'l', 'l', 'e', 't'}
+	chain.DeployContractWallet(wallet, ta.Bob.Address())
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+
+	bobStore, err := store.NewDurableStore(ta.Bob.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletNode := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(wallet, broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, wallet)),
+		node.WithStore(walletStore{bobStore, wallet}),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+	)
+	defer closeNode(t, &walletNode)
+
+	ledgerId := openLedgerChannel(t, alice, walletNode, types.Address{})
+	checkLedgerChannel(t, ledgerId, initialLedgerOutcome(*alice.Address, wallet, types.Address{}), query.Open, alice, walletNode)
+
+	closeLedgerChannel(t, alice, walletNode, ledgerId)
+	checkLedgerChannel(t, ledgerId, initialLedgerOutcome(*alice.Address, wallet, types.Address{}), query.Complete, alice, walletNode)
+}
+
+// TestContractWalletDetectionIsCached checks that a node which is slow to detect contract wallets still opens channels
+// with them, and checks each participant only once, though it joins several channels.
+func TestContractWalletDetectionIsCached(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	wallet := types.Address{'w', 'a', 'l', 'l', 'e', 't'}
+	chain.DeployContractWallet(wallet, ta.Bob.Address())
+
+	aliceChain := &slowWalletChain{
+		MockChainService: chainservice.NewMockChainService(chain, ta.Alice.Address()),
+		delay:            200 * time.Millisecond,
+		checks:           map[types.Address]int{},
+	}
+	alice, _ := setupNode(ta.Alice.PrivateKey, aliceChain, broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+
+	bobStore, err := store.NewDurableStore(ta.Bob.PrivateKey, dataFolder, buntdb.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletNode := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(wallet, broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, wallet)),
+		node.WithStore(walletStore{bobStore, wallet}),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+	)
+	defer closeNode(t, &walletNode)
+
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, walletNode, types.Address{})
+	checkLedgerChannel(t, ledgerId, initialLedgerOutcome(*alice.Address, wallet, types.Address{}), query.Open, alice, walletNode)
+	openLedgerChannel(t, alice, irene, types.Address{})
+
+	aliceChain.mu.Lock()
+	defer aliceChain.mu.Unlock()
+	for _, p := range []types.Address{*alice.Address, wallet, *irene.Address} {
+		if checks := aliceChain.checks[p]; checks != 1 {
+			t.Errorf("expected %s to be checked once, got %d checks", p, checks)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package node_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// loggedCorrelationIds returns the number of records the json logs hold for each correlation id.
+func loggedCorrelationIds(t *testing.T, logs string) map[string]int {
+	counts := make(map[string]int)
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		record := make(map[string]any)
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		if id, ok := record[logging.CORRELATION_ID_LOG_KEY].(string); ok {
+			counts[id]++
+		}
+	}
+	return counts
+}
+
+// TestCorrelationIds checks that the correlation id of the context an objective or payment is requested with is logged
+// by both the node making the request and its counterparty.
+func TestCorrelationIds(t *testing.T) {
+	aliceLogs, bobLogs := &syncBuffer{}, &syncBuffer{}
+	debugLogger := func(logs *syncBuffer) *slog.Logger {
+		return slog.New(slog.NewJSONHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	alice := node.New(node.WithLogger(debugLogger(aliceLogs)))
+	defer closeNode(t, &alice)
+	bob := node.New(node.WithLogger(debugLogger(bobLogs)))
+	defer closeNode(t, &bob)
+	irene := node.New()
+	defer closeNode(t, &irene)
+
+	ctx := node.ContextWithCorrelationId(context.Background(), "fund-alice-irene")
+	ledger, err := alice.CreateLedgerChannelWithOptions(ctx, *irene.Address, 0, initialLedgerOutcome(*alice.Address, *irene.Address, types.Address{}), node.CreateChannelOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-alice.ObjectiveCompleteChan(ledger.Id)
+	<-irene.ObjectiveCompleteChan(ledger.Id)
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{*irene.Address}, *bob.Address, 0, initialPaymentOutcome(*alice.Address, *bob.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	ctx = node.ContextWithCorrelationId(context.Background(), "pay-bob")
+	if err := alice.PayWithOptions(ctx, response.ChannelId, big.NewInt(1), node.PayOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-bob.ReceivedVouchers():
+	case <-time.After(defaultTimeout):
+		t.Fatal("timed out waiting for the voucher")
+	}
+
+	aliceIds, bobIds := loggedCorrelationIds(t, aliceLogs.String()), loggedCorrelationIds(t, bobLogs.String())
+	if aliceIds["fund-alice-irene"] == 0 || aliceIds["pay-bob"] == 0 {
+		t.Errorf("expected alice to log the correlation ids of her requests, got %v", aliceIds)
+	}
+	if bobIds["pay-bob"] == 0 {
+		t.Errorf("expected bob to log the correlation id of alice's payment, got %v", bobIds)
+	}
+	if bobIds["fund-alice-irene"] != 0 {
+		t.Errorf("expected bob not to log the correlation id of a flow bob is not part of, got %v", bobIds)
+	}
+	if len(aliceIds) != 2 {
+		t.Errorf("expected alice to log only the correlation ids of her requests, got %v", aliceIds)
+	}
+}
@@ -0,0 +1,100 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// uncountersigningMessageService drops every message to leader which countersigns the addition of a guarantee, so
+// that the leader never sees its guarantees countersigned. Other messages, including countersigned withdrawals, are sent.
+type uncountersigningMessageService struct {
+	messageservice.TestMessageService
+	leader types.Address
+}
+
+func (u uncountersigningMessageService) Send(msg protocols.Message) error {
+	if msg.To == u.leader {
+		for _, sp := range msg.LedgerProposals {
+			if sp.Proposal.Type() == consensus_channel.AddProposal {
+				return nil
+			}
+		}
+	}
+	return u.TestMessageService.Send(msg)
+}
+
+func setupUncountersigningNode(pk []byte, chain chainservice.ChainService, broker messageservice.Broker, dataFolder string, leader types.Address) (node.Node, store.Store) {
+	ms := uncountersigningMessageService{messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(pk), broker, 0), leader}
+	s, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		panic(err)
+	}
+	return node.New(node.WithMessageService(ms), node.WithChainService(chain), node.WithStore(s), node.WithPolicy(&engine.PermissivePolicy{})), s
+}
+
+// TestUncountersignedGuaranteeWithdrawnAlongRoute checks that when the guarantees for a virtual channel are never
+// countersigned, they are withdrawn from every ledger channel along its route once the countersignature timeout passes,
+// releasing the ledger capacity.
+func TestUncountersignedGuaranteeWithdrawnAlongRoute(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	// Only Alice times out, so Irene must pass her withdrawal on to Bob
+	policy := &engine.PermissivePolicy{CountersignatureTimeout: 500 * time.Millisecond}
+	alice, aliceStore := setupNodeWithPolicy(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder, policy)
+	defer closeNode(t, &alice)
+	irene, ireneStore := setupUncountersigningNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, dataFolder, ta.Alice.Address())
+	defer closeNode(t, &irene)
+	bob, bobStore := setupUncountersigningNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, dataFolder, ta.Irene.Address())
+	defer closeNode(t, &bob)
+
+	aliceLedger := openLedgerChannel(t, alice, irene, types.Address{})
+	bobLedger := openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	for _, s := range []store.Store{aliceStore, ireneStore, bobStore} {
+		o, err := s.GetObjectiveById(response.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if o.GetStatus() != protocols.Rejected {
+			t.Errorf("expected %s to reject the objective, got status %v", s.GetAddress(), o.GetStatus())
+		}
+	}
+
+	released := func(s store.Store, ledger types.Destination) bool {
+		c, err := s.GetConsensusChannelById(ledger)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return !c.IncludesTarget(response.ChannelId) && len(c.ProposalQueue()) == 0
+	}
+	deadline := time.Now().Add(defaultTimeout)
+	for !(released(aliceStore, aliceLedger) && released(ireneStore, aliceLedger) && released(ireneStore, bobLedger) && released(bobStore, bobLedger)) {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for the guarantees to be withdrawn from both ledger channels")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
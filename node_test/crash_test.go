@@ -49,7 +49,7 @@ func TestCrashTolerance(t *testing.T) {
 		t.Fatal(err)
 	}
 	messageserviceA := messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)
-	nodeA := node.New(messageserviceA, chainA, storeA, &engine.PermissivePolicy{})
+	nodeA := node.New(node.WithMessageService(messageserviceA), node.WithChainService(chainA), node.WithStore(storeA), node.WithPolicy(&engine.PermissivePolicy{}))
 
 	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainB, broker, 0, dataFolder)
 	defer closeNode(t, &nodeB)
@@ -73,9 +73,9 @@ func TestCrashTolerance(t *testing.T) {
 			t.Fatal(err)
 		}
 		anotherClientA := node.New(
-			anotherMessageserviceA,
-			anotherChainA,
-			anotherStoreA, &engine.PermissivePolicy{})
+			node.WithMessageService(anotherMessageserviceA),
+			node.WithChainService(anotherChainA),
+			node.WithStore(anotherStoreA), node.WithPolicy(&engine.PermissivePolicy{}))
 		defer closeNode(t, &anotherClientA)
 
 		closeLedgerChannel(t, anotherClientA, nodeB, channelId)
@@ -0,0 +1,187 @@
+package node_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// pingPrefix identifies ping objectives, a toy application-specific protocol in which the proposer sends a ping to a
+// counterparty, and completes once the counterparty replies with a pong.
+const pingPrefix = "Ping-"
+
+const (
+	pingPayload protocols.PayloadType = "Ping"
+	pongPayload protocols.PayloadType = "Pong"
+)
+
+type pingRequest struct {
+	Counterparty     types.Address
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+func (r pingRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return protocols.ObjectiveId(fmt.Sprintf("%s%s-%d", pingPrefix, myAddress, r.Nonce))
+}
+
+func (r pingRequest) WaitForObjectiveToStart() { <-r.objectiveStarted }
+
+func (r pingRequest) SignalObjectiveStarted() { close(r.objectiveStarted) }
+
+type pingObjective struct {
+	ObjectiveId protocols.ObjectiveId
+	Status      protocols.ObjectiveStatus
+	Peer        types.Address
+	Proposer    bool
+	Received    bool // whether the ping, or pong, from the peer has been received
+	Sent        bool
+}
+
+func (o *pingObjective) Id() protocols.ObjectiveId { return o.ObjectiveId }
+
+func (o *pingObjective) Approve() protocols.Objective {
+	updated := *o
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+func (o *pingObjective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := *o
+	updated.Status = protocols.Rejected
+	return &updated, protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.ObjectiveId, o.Peer)}
+}
+
+func (o *pingObjective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	updated := *o
+	updated.Received = true
+	return &updated, nil
+}
+
+func (o *pingObjective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := *o
+	sideEffects := protocols.SideEffects{}
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, "WaitingForApproval", nil
+	}
+
+	if !updated.Sent && (updated.Proposer || updated.Received) {
+		payloadType := pingPayload
+		if !updated.Proposer {
+			payloadType = pongPayload
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.ObjectiveId, struct{}{}, payloadType, updated.Peer)
+		if err != nil {
+			return o, sideEffects, "", err
+		}
+		sideEffects.MessagesToSend = messages
+		updated.Sent = true
+	}
+	if !updated.Received {
+		return &updated, sideEffects, "WaitingForPeer", nil
+	}
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, "WaitingForNothing", nil
+}
+
+func (o *pingObjective) Related() []protocols.Storable { return []protocols.Storable{} }
+
+func (o *pingObjective) MarshalJSON() ([]byte, error) {
+	type plain pingObjective
+	return json.Marshal((*plain)(o))
+}
+
+func (o *pingObjective) UnmarshalJSON(data []byte) error {
+	type plain pingObjective
+	return json.Unmarshal(data, (*plain)(o))
+}
+
+// OwnsChannel returns a destination derived from the objective id, as ping objectives do not fund a channel.
+func (o *pingObjective) OwnsChannel() types.Destination {
+	return types.Destination(crypto.Keccak256Hash([]byte(o.ObjectiveId)))
+}
+
+func (o *pingObjective) GetStatus() protocols.ObjectiveStatus { return o.Status }
+
+func init() {
+	err := protocols.RegisterObjectiveType(protocols.ObjectiveType{
+		Prefix: pingPrefix,
+		Decode: func(data []byte) (protocols.Objective, error) {
+			o := &pingObjective{}
+			return o, o.UnmarshalJSON(data)
+		},
+		ConstructFromPayload: func(p protocols.ObjectivePayload, myAddress types.Address) (protocols.Objective, error) {
+			proposer, _, found := strings.Cut(strings.TrimPrefix(string(p.ObjectiveId), pingPrefix), "-")
+			if !found || !common.IsHexAddress(proposer) {
+				return nil, fmt.Errorf("malformed ping objective id %s", p.ObjectiveId)
+			}
+			return &pingObjective{ObjectiveId: p.ObjectiveId, Peer: common.HexToAddress(proposer)}, nil
+		},
+		ConstructFromRequest: func(request protocols.ObjectiveRequest, myAddress types.Address, chainId *big.Int) (protocols.Objective, error) {
+			r := request.(pingRequest)
+			return &pingObjective{ObjectiveId: r.Id(myAddress, chainId), Status: protocols.Approved, Peer: r.Counterparty, Proposer: true}, nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestCustomObjectiveType(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, aliceStore := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, bobStore := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	id, err := alice.CreateObjective(context.Background(), pingRequest{Counterparty: ta.Bob.Address(), Nonce: 1, objectiveStarted: make(chan struct{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{id})
+
+	// The stores decode the objective with the registered type
+	for _, s := range []store.Store{aliceStore, bobStore} {
+		o, err := s.GetObjectiveById(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ping, ok := o.(*pingObjective)
+		if !ok {
+			t.Fatalf("expected a ping objective, got %T", o)
+		}
+		if ping.Status != protocols.Completed || !ping.Sent || !ping.Received {
+			t.Errorf("expected a completed exchange, got %+v", ping)
+		}
+	}
+
+	// Types may not take over the ids of built-in objectives
+	err = protocols.RegisterObjectiveType(protocols.ObjectiveType{Prefix: "Direct", Decode: func([]byte) (protocols.Objective, error) { return nil, nil },
+		ConstructFromPayload: func(protocols.ObjectivePayload, types.Address) (protocols.Objective, error) { return nil, nil },
+		ConstructFromRequest: func(protocols.ObjectiveRequest, types.Address, *big.Int) (protocols.Objective, error) {
+			return nil, nil
+		},
+	})
+	if err == nil {
+		t.Error("expected a type overlapping the built-in types to be refused")
+	}
+}
@@ -0,0 +1,103 @@
+package node_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+)
+
+func TestDebugHandler(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chainservice.NewMockChain(), ta.Alice.Address()), messageservice.NewBroker(), 0, dataFolder)
+	defer closeNode(t, &alice)
+
+	authorize := func(token string) error {
+		if token != "admin-token" {
+			return errors.New("not an admin token")
+		}
+		return nil
+	}
+	server := httptest.NewServer(node.NewDebugHandler(&alice, authorize))
+	defer server.Close()
+
+	get := func(path, token string) (int, []byte) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, server.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode, body
+	}
+
+	for _, token := range []string{"", "wrong-token"} {
+		if status, _ := get("/debug/goroutines", token); status != http.StatusUnauthorized {
+			t.Errorf("expected a request with token %q to be refused, got status %d", token, status)
+		}
+	}
+
+	status, body := get("/debug/goroutines", "admin-token")
+	if status != http.StatusOK || !strings.Contains(string(body), "goroutine") {
+		t.Errorf("expected a goroutine dump, got status %d", status)
+	}
+
+	status, body = get("/debug/runtime", "admin-token")
+	var stats node.RuntimeStats
+	if err := json.Unmarshal(body, &stats); status != http.StatusOK || err != nil || stats.Goroutines == 0 {
+		t.Errorf("expected runtime stats, got status %d and %s", status, body)
+	}
+
+	status, body = get("/debug/engine", "admin-token")
+	var snapshot node.EngineSnapshot
+	if err := json.Unmarshal(body, &snapshot); status != http.StatusOK || err != nil || len(snapshot.Queues) == 0 {
+		t.Errorf("expected a snapshot of the engine's queues, got status %d and %s", status, body)
+	}
+
+	if status, body = get("/debug/pprof/heap", "admin-token"); status != http.StatusOK || len(body) == 0 {
+		t.Errorf("expected a heap profile, got status %d", status)
+	}
+	if status, _ = get("/debug/pprof/nonexistent", "admin-token"); status != http.StatusNotFound {
+		t.Errorf("expected an unknown profile not to be found, got status %d", status)
+	}
+
+	// A CPU profile may not run for longer than the cap, nor alongside another
+	if status, _ = get(fmt.Sprintf("/debug/pprof/profile?seconds=%d", node.MaxCPUProfileSeconds+1), "admin-token"); status != http.StatusBadRequest {
+		t.Errorf("expected a profile longer than the cap to be refused, got status %d", status)
+	}
+	profiled := make(chan int, 1)
+	go func() {
+		status, _ := get("/debug/pprof/profile?seconds=1", "admin-token")
+		profiled <- status
+	}()
+	time.Sleep(200 * time.Millisecond)
+	if status, _ = get("/debug/pprof/profile?seconds=1", "admin-token"); status != http.StatusConflict {
+		t.Errorf("expected a second concurrent profile to be refused, got status %d", status)
+	}
+	if status := <-profiled; status != http.StatusOK {
+		t.Errorf("expected a CPU profile, got status %d", status)
+	}
+}
@@ -0,0 +1,76 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestDefaultHub checks that a node opens a ledger channel with its default hub on startup, or lazily on the first
+// payment, and routes payment channels through the hub without being told to.
+func TestDefaultHub(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	if _, err := alice.DefaultHubLedger(ctx); !errors.Is(err, node.ErrNoDefaultHub) {
+		t.Fatalf("expected ErrNoDefaultHub, got %v", err)
+	}
+
+	deposit := big.NewInt(ledgerChannelDeposit)
+	funding := node.LedgerFundingTemplate{MyDeposit: deposit, TheirDeposit: deposit}
+	if err := alice.EnableDefaultHub(node.DefaultHubOptions{Hub: ta.Alice.Address(), Funding: funding}); !errors.Is(err, node.ErrInvalidConfig) {
+		t.Fatalf("expected a node to refuse itself as its default hub, got %v", err)
+	}
+	if err := alice.EnableDefaultHub(node.DefaultHubOptions{Hub: ta.Irene.Address(), Funding: funding}); err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.EnableDefaultHub(node.DefaultHubOptions{Hub: ta.Irene.Address(), Funding: funding, Lazy: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger, err := alice.DefaultHubLedger(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Status != query.Open {
+		t.Errorf("expected the ledger channel with the default hub to be open, got %s", ledger.Status)
+	}
+	if ledgers, err := bob.GetAllLedgerChannels(); err != nil || len(ledgers) != 0 {
+		t.Fatalf("expected a lazy default hub to open no ledger channel before it is needed, got %v (%v)", ledgers, err)
+	}
+	// A lazy default hub opens its ledger channel once it is asked for
+	if _, err := bob.DefaultHubLedger(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := alice.CreatePaymentChannelContext(ctx, nil, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, response.Id); err != nil {
+		t.Fatal(err)
+	}
+	if ch, err := alice.GetPaymentChannel(response.ChannelId); err != nil || ch.Status != query.Open {
+		t.Fatalf("expected the payment channel routed through the default hub to be open, got %+v (%v)", ch, err)
+	}
+}
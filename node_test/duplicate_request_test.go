@@ -0,0 +1,97 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestDuplicateRequestDetection checks that retried requests to create channels return the original response rather
+// than creating more channels, unless they are forced.
+func TestDuplicateRequestDetection(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	alice.EnableDuplicateRequestDetection(time.Minute)
+
+	// Concurrent requests create a single ledger channel
+	ledgerOutcome := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{})
+	responses := make([]directfund.ObjectiveResponse, 3)
+	wg := sync.WaitGroup{}
+	for i := range responses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, ledgerOutcome)
+			if err != nil {
+				t.Error(err)
+			}
+			responses[i] = response
+		}(i)
+	}
+	wg.Wait()
+	for _, response := range responses[1:] {
+		if response != responses[0] {
+			t.Fatalf("expected duplicate requests to return %+v, got %+v", responses[0], response)
+		}
+	}
+	waitForObjectives(t, alice, irene, nil, []protocols.ObjectiveId{responses[0].Id})
+
+	// A retry once the channel is open returns the same channel
+	retry, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, ledgerOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if retry != responses[0] {
+		t.Fatalf("expected retry to return %+v, got %+v", responses[0], retry)
+	}
+	// A forced request is not deduplicated, but a second ledger channel with Irene is still refused
+	_, err = alice.CreateLedgerChannelForced(context.Background(), ta.Irene.Address(), 0, ledgerOutcome)
+	if !errors.Is(err, node.ErrLedgerChannelExists) {
+		t.Fatalf("expected %v, got %v", node.ErrLedgerChannelExists, err)
+	}
+
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	paymentOutcome := initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{})
+	first, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, paymentOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, paymentOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second != first {
+		t.Fatalf("expected duplicate request to return %+v, got %+v", first, second)
+	}
+	forced, err := alice.CreatePaymentChannelForced(context.Background(), intermediaries, ta.Bob.Address(), 0, paymentOutcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forced.ChannelId == first.ChannelId {
+		t.Fatal("expected a forced request to create another channel")
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{first.Id, forced.Id})
+}
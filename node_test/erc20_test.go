@@ -0,0 +1,106 @@
+package node_test
+
+import (
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/statechannels/go-nitro/internal/logging"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestERC20 checks that ledger and payment channels holding an ERC20 token are funded, paid through and defunded on
+// chain, and that the token's decimals are reported with payment channel balances.
+func TestERC20(t *testing.T) {
+	logging.SetupDefaultFileLogger("test_erc20.log", slog.LevelDebug)
+
+	sim, bindings, ethAccounts, err := chainservice.SetupSimulatedBackend(3)
+	defer closeSimulatedChain(t, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := bindings.Token.Address
+
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	setup := func(pk []byte, account int) node.Node {
+		cs, err := chainservice.NewSimulatedBackendChainService(sim, bindings, ethAccounts[account])
+		if err != nil {
+			t.Fatal(err)
+		}
+		n, _ := setupNode(pk, cs, broker, 0, dataFolder)
+		return n
+	}
+	alice := setup(ta.Alice.PrivateKey, 0)
+	defer closeNode(t, &alice)
+	irene := setup(ta.Irene.PrivateKey, 1)
+	defer closeNode(t, &irene)
+	bob := setup(ta.Bob.PrivateKey, 2)
+	defer closeNode(t, &bob)
+
+	ledgerA := openLedgerChannel(t, alice, irene, token)
+	ledgerB := openLedgerChannel(t, irene, bob, token)
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	const paid = 7
+	alice.Pay(response.ChannelId, big.NewInt(paid))
+	<-bob.ReceivedVouchers()
+
+	decimals, err := bindings.Token.Contract.Decimals(&bind.CallOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []node.Node{alice, bob} {
+		info, err := n.GetPaymentChannel(response.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Balance.AssetAddress != token {
+			t.Errorf("expected the payment channel to hold %s, got %s", token, info.Balance.AssetAddress)
+		}
+		if info.Balance.Decimals == nil || *info.Balance.Decimals != decimals {
+			t.Errorf("expected the balance to report %d decimals, got %v", decimals, info.Balance.Decimals)
+		}
+		if info.Balance.PaidSoFar.ToInt().Cmp(big.NewInt(paid)) != 0 {
+			t.Errorf("expected %d to have been paid, got %s", paid, info.Balance.PaidSoFar.ToInt())
+		}
+	}
+
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+	closeLedgerChannel(t, alice, irene, ledgerA)
+	closeLedgerChannel(t, irene, bob, ledgerB)
+
+	// Defunding pays out the token to each participant's address
+	want := map[types.Address]int64{
+		ta.Alice.Address(): ledgerChannelDeposit - paid,
+		ta.Irene.Address(): 2 * ledgerChannelDeposit,
+		ta.Bob.Address():   ledgerChannelDeposit + paid,
+	}
+	for address, amount := range want {
+		balance, err := bindings.Token.Contract.BalanceOf(&bind.CallOpts{}, address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.Cmp(big.NewInt(amount)) != 0 {
+			t.Errorf("expected %s to hold %d of the token once the channels are defunded, got %s", address, amount, balance)
+		}
+	}
+}
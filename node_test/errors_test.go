@@ -0,0 +1,62 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestNodeErrors(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	nodeA, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeA)
+	nodeB, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &nodeB)
+
+	unknown := types.Destination{1}
+	if _, err := nodeA.GetPaymentChannel(unknown); !errors.Is(err, node.ErrChannelNotFound) {
+		t.Errorf("GetPaymentChannel: expected %v, got %v", node.ErrChannelNotFound, err)
+	}
+	if _, err := nodeA.GetLedgerChannel(unknown); !errors.Is(err, node.ErrChannelNotFound) {
+		t.Errorf("GetLedgerChannel: expected %v, got %v", node.ErrChannelNotFound, err)
+	}
+	if _, err := nodeA.ClosePaymentChannel(unknown); !errors.Is(err, node.ErrChannelNotFound) {
+		t.Errorf("ClosePaymentChannel: expected %v, got %v", node.ErrChannelNotFound, err)
+	}
+	if err := nodeA.PayContext(context.Background(), unknown, big.NewInt(1)); !errors.Is(err, node.ErrChannelNotFound) {
+		t.Errorf("PayContext: expected %v, got %v", node.ErrChannelNotFound, err)
+	}
+
+	openLedgerChannel(t, nodeA, nodeB, types.Address{})
+	_, err := nodeA.CreateLedgerChannel(*nodeB.Address, 0, initialLedgerOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if !errors.Is(err, node.ErrLedgerChannelExists) {
+		t.Errorf("CreateLedgerChannel: expected %v, got %v", node.ErrLedgerChannelExists, err)
+	}
+
+	response, err := nodeA.CreatePaymentChannel(nil, *nodeB.Address, 0, initialPaymentOutcome(*nodeA.Address, *nodeB.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.WaitForObjective(context.Background(), response.Id); err != nil {
+		t.Fatal(err)
+	}
+	if err := nodeA.PayContext(context.Background(), response.ChannelId, big.NewInt(virtualChannelDeposit+1)); !errors.Is(err, node.ErrInsufficientFunds) {
+		t.Errorf("PayContext: expected %v, got %v", node.ErrInsufficientFunds, err)
+	}
+	if _, err := nodeA.CreateVoucher(response.ChannelId, big.NewInt(virtualChannelDeposit+1)); !errors.Is(err, node.ErrInsufficientFunds) {
+		t.Errorf("CreateVoucher: expected %v, got %v", node.ErrInsufficientFunds, err)
+	}
+}
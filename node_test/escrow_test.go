@@ -0,0 +1,109 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForEscrow waits for the node's view of the escrow to reach the status, returning it.
+func waitForEscrow(t *testing.T, n node.Node, id protocols.ObjectiveId, status query.EscrowStatus) query.EscrowInfo {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	var info query.EscrowInfo
+	var err error
+	for time.Now().Before(deadline) {
+		info, err = n.GetEscrow(id)
+		if err == nil && info.Status == status {
+			return info
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for escrow %s to be %s: got %+v, %v", id, status, info, err)
+	return query.EscrowInfo{}
+}
+
+func TestEscrow(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	ctx := context.Background()
+	result := []byte("inference output")
+	commitment := crypto.Keccak256Hash(result)
+
+	t.Run("a matching result releases the payment", func(t *testing.T) {
+		opened, err := alice.CreateEscrow(ctx, response.ChannelId, big.NewInt(7), commitment, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForEscrow(t, bob, opened.ID, query.EscrowOpen)
+
+		if _, err := bob.SubmitEscrowResult(ctx, opened.ID, []byte("something else")); !errors.Is(err, node.ErrResultMismatch) {
+			t.Errorf("expected a mismatched result to be refused, got %v", err)
+		}
+		if _, err := bob.SubmitEscrowResult(ctx, opened.ID, result); err != nil {
+			t.Fatal(err)
+		}
+
+		released := waitForEscrow(t, alice, opened.ID, query.EscrowReleased)
+		if string(released.Result) != string(result) {
+			t.Errorf("expected the payer to receive the result, got %q", released.Result)
+		}
+		waitForEscrow(t, bob, opened.ID, query.EscrowReleased)
+		waitForPaidSoFar(t, bob, response.ChannelId, big.NewInt(7))
+
+		if _, err := bob.DisputeEscrow(ctx, opened.ID, ""); !errors.Is(err, node.ErrEscrowNotOpen) {
+			t.Errorf("expected a released escrow not to be disputed, got %v", err)
+		}
+	})
+
+	t.Run("an escrow without a result expires", func(t *testing.T) {
+		opened, err := alice.CreateEscrow(ctx, response.ChannelId, big.NewInt(7), commitment, 100*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForEscrow(t, alice, opened.ID, query.EscrowExpired)
+		waitForEscrow(t, bob, opened.ID, query.EscrowExpired)
+
+		if _, err := bob.SubmitEscrowResult(ctx, opened.ID, result); !errors.Is(err, node.ErrEscrowNotOpen) {
+			t.Errorf("expected a result to be refused after the deadline, got %v", err)
+		}
+		waitForPaidSoFar(t, bob, response.ChannelId, big.NewInt(7))
+	})
+
+	t.Run("only the payer opens an escrow", func(t *testing.T) {
+		_, err := bob.CreateEscrow(ctx, response.ChannelId, big.NewInt(7), commitment, time.Hour)
+		if !errors.Is(err, node.ErrInvalidEscrow) {
+			t.Errorf("expected the payee's escrow to be refused, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,70 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestExitPriorityWhileDraining checks that a draining node refuses to fund channels, whether asked to by the API or
+// by a peer, while defunding its channels as normal, and funds channels again once it stops draining.
+func TestExitPriorityWhileDraining(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	policy := func() *engine.PermissivePolicy { return &engine.PermissivePolicy{PrioritizeExitsDraining: true} }
+	alice, _ := setupNodeWithPolicy(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder, policy())
+	defer closeNode(t, &alice)
+	bob, _ := setupNodeWithPolicy(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder, policy())
+	defer closeNode(t, &bob)
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder, policy())
+	defer closeNode(t, &irene)
+	ivan, _ := setupNodeWithPolicy(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 0, dataFolder, policy())
+	defer closeNode(t, &ivan)
+
+	ledgerId := openLedgerChannel(t, alice, bob, types.Address{})
+
+	alice.SetDraining(true)
+	bob.SetDraining(true)
+	if !alice.Draining() {
+		t.Fatal("expected alice to be draining")
+	}
+
+	_, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{}))
+	if !errors.Is(err, node.ErrFundingDeferred) {
+		t.Fatalf("expected the ledger channel to be deferred, got %v", err)
+	}
+	_, err = alice.AddFundsToLedgerChannel(ledgerId, big.NewInt(1))
+	if !errors.Is(err, node.ErrFundingDeferred) {
+		t.Fatalf("expected the top-up to be deferred, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	proposed, err := irene.CreateLedgerChannel(ta.Alice.Address(), 0, initialLedgerOutcome(ta.Irene.Address(), ta.Alice.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := irene.WaitForObjective(ctx, proposed.Id); !errors.Is(err, node.ErrObjectiveRejected) {
+		t.Fatalf("expected irene's objective to be rejected, got %v", err)
+	}
+
+	// Exits proceed while both nodes drain
+	closeLedgerChannel(t, alice, bob, ledgerId)
+
+	alice.SetDraining(false)
+	openLedgerChannel(t, ivan, alice, types.Address{})
+}
@@ -0,0 +1,85 @@
+package node_test
+
+import (
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/pricefeed"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestFiatValuation(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	// Without a price feed, no valuation is included
+	ledger, err := alice.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Fiat != nil {
+		t.Fatalf("expected no fiat valuation, got %+v", ledger.Fiat)
+	}
+
+	// A unit of the native asset is worth 2 EUR
+	before := time.Now()
+	alice.EnableFiatValuation(pricefeed.StaticFeed{{}: {Currency: "EUR", PerToken: 2, Decimals: 0}}, time.Hour)
+
+	ledger, err = alice.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	myBalance := float64(ledger.Balance.MyBalance.ToInt().Int64())
+	if f := ledger.Fiat; f == nil || f.Currency != "EUR" || !f.Indicative || f.AsOf.Before(before) || f.MyBalance != 2*myBalance {
+		t.Fatalf("expected an indicative valuation of %v EUR, got %+v", 2*myBalance, f)
+	}
+
+	ledgers, err := alice.GetAllLedgerChannels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, l := range ledgers {
+		if l.Fiat == nil {
+			t.Fatalf("expected channel %s to be valued", l.ID)
+		}
+	}
+
+	paymentChannel, err := alice.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := paymentChannel.Fiat; f == nil || f.PaidSoFar != 0 || f.RemainingFunds != 2*virtualChannelDeposit {
+		t.Fatalf("expected remaining funds worth %v EUR, got %+v", 2*virtualChannelDeposit, f)
+	}
+	paymentChannels, err := alice.GetPaymentChannelsByLedger(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paymentChannels) != 1 || paymentChannels[0].Fiat == nil {
+		t.Fatalf("expected the payment channel to be valued, got %+v", paymentChannels)
+	}
+}
@@ -0,0 +1,112 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directfund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestObjectiveCollection checks that objectives are only collected once the channels they concern are closed,
+// and that the nodes keep working once they have been.
+func TestObjectiveCollection(t *testing.T) {
+	const interval = 20 * time.Millisecond
+
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	setup := func(pk []byte, address types.Address) (node.Node, store.Store) {
+		return setupNodeWithPolicy(pk, chainservice.NewMockChainService(chain, address), broker, 0, dataFolder, &engine.PermissivePolicy{ObjectiveCollectionInterval: interval})
+	}
+	alice, storeA := setup(ta.Alice.PrivateKey, ta.Alice.Address())
+	defer closeNode(t, &alice)
+	bob, storeB := setup(ta.Bob.PrivateKey, ta.Bob.Address())
+	defer closeNode(t, &bob)
+	irene, storeI := setup(ta.Irene.PrivateKey, ta.Irene.Address())
+	defer closeNode(t, &irene)
+
+	ledgerA := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	response, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	// Objectives which funded open channels must be kept
+	time.Sleep(5 * interval)
+	ledgerFundId := protocols.ObjectiveId(directfund.ObjectivePrefix + ledgerA.String())
+	for _, s := range []store.Store{storeA, storeB, storeI} {
+		if _, err := s.GetObjectiveById(response.Id); err != nil {
+			t.Fatalf("expected objective %s to be kept while its channel is open, got %v", response.Id, err)
+		}
+	}
+	for _, s := range []store.Store{storeA, storeI} {
+		if _, err := s.GetObjectiveById(ledgerFundId); err != nil {
+			t.Fatalf("expected objective %s to be kept while its channel is open, got %v", ledgerFundId, err)
+		}
+	}
+
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+
+	for _, s := range []store.Store{storeA, storeB, storeI} {
+		for _, id := range []protocols.ObjectiveId{response.Id, closeId} {
+			waitForCollection(t, s, id)
+		}
+	}
+
+	// Collected objectives can still be waited on and queried
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+	if err := alice.WaitForObjective(ctx, closeId); err != nil {
+		t.Fatal(err)
+	}
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 1, 1), query.Complete, alice, bob)
+
+	// The nodes can still fund new virtual channels
+	second, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{second.Id})
+}
+
+// waitForCollection polls the store until the objective has been collected, or fails the test.
+func waitForCollection(t *testing.T, s store.Store, id protocols.ObjectiveId) {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		_, err := s.GetObjectiveById(id)
+		if errors.Is(err, store.ErrObjectiveCollected) {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for objective %s to be collected, got %v", id, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,124 @@
+package node_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualdefund"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// vanishingMessageService drops every message it sends once vanished is set.
+type vanishingMessageService struct {
+	messageservice.TestMessageService
+	vanished *atomic.Bool
+}
+
+func (v vanishingMessageService) Send(msg protocols.Message) error {
+	if v.vanished.Load() {
+		return nil
+	}
+	return v.TestMessageService.Send(msg)
+}
+
+func setupVanishingNode(pk []byte, chain chainservice.ChainService, broker messageservice.Broker, dataFolder string, policy engine.PolicyMaker, vanished *atomic.Bool) node.Node {
+	ms := vanishingMessageService{messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(pk), broker, 0), vanished}
+	s, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		panic(err)
+	}
+	return node.New(node.WithMessageService(ms), node.WithChainService(chain), node.WithStore(s), node.WithPolicy(policy))
+}
+
+// TestExpiredGuaranteeReclaimedOffChain checks that an intermediary defunds a virtual channel whose guarantee has
+// expired, without either party requesting it.
+func TestExpiredGuaranteeReclaimedOffChain(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	policy := &engine.PermissivePolicy{GuaranteeExpiry: time.Second}
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder, policy)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	closeId := protocols.ObjectiveId(virtualdefund.ObjectivePrefix + response.ChannelId.String())
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+
+	channel, err := bob.GetPaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channel.Status != query.Complete {
+		t.Fatalf("expected channel to be closed, got status %s", channel.Status)
+	}
+}
+
+// TestExpiredGuaranteeReclaimedOnChain checks that an intermediary challenges its ledger channel with a payer who has
+// vanished, once a guarantee has expired and could not be reclaimed off-chain.
+func TestExpiredGuaranteeReclaimedOnChain(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	vanished := &atomic.Bool{}
+	policy := &engine.PermissivePolicy{GuaranteeExpiry: time.Second, ReclaimTimeout: time.Second}
+	alice := setupVanishingNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, dataFolder, policy, vanished)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder, policy)
+	defer closeNode(t, &irene)
+
+	ledgerA := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	events := chain.SubscribeToEvents(types.Address{})
+	vanished.Store(true)
+
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-events:
+			if challenge, ok := event.(chainservice.ChallengeRegisteredEvent); ok && challenge.ChannelID() == ledgerA {
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the ledger channel with alice to be challenged")
+		}
+	}
+}
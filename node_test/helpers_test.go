@@ -10,6 +10,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/internal/logging"
@@ -29,6 +30,11 @@ import (
 
 // setupNode is a helper function that constructs a nitro node and returns the new node and its store.
 func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, meanMessageDelay time.Duration, dataFolder string) (node.Node, store.Store) {
+	return setupNodeWithPolicy(pk, chain, msgBroker, meanMessageDelay, dataFolder, &engine.PermissivePolicy{})
+}
+
+// setupNodeWithPolicy is like setupNode, but constructs the node with the given policy maker.
+func setupNodeWithPolicy(pk []byte, chain chainservice.ChainService, msgBroker messageservice.Broker, meanMessageDelay time.Duration, dataFolder string, policy engine.PolicyMaker) (node.Node, store.Store) {
 	myAddress := crypto.GetAddressFromSecretKeyBytes(pk)
 
 	messageservice := messageservice.NewTestMessageService(myAddress, msgBroker, meanMessageDelay)
@@ -36,7 +42,7 @@ func setupNode(pk []byte, chain chainservice.ChainService, msgBroker messageserv
 	if err != nil {
 		panic(err)
 	}
-	return node.New(messageservice, chain, storeA, &engine.PermissivePolicy{}), storeA
+	return node.New(node.WithMessageService(messageservice), node.WithChainService(chain), node.WithStore(storeA), node.WithPolicy(policy)), storeA
 }
 
 func closeNode(t *testing.T, node *node.Node) {
@@ -120,7 +126,7 @@ func setupIntegrationNode(tc TestCase, tp TestParticipant, si sharedTestInfrastr
 	messageService, multiAddr := setupMessageService(tc, tp, si, bootPeers)
 	cs := setupChainService(tc, tp, si)
 	store := setupStore(tc, tp, si, dataFolder)
-	n := node.New(messageService, cs, store, &engine.PermissivePolicy{})
+	n := node.New(node.WithMessageService(messageService), node.WithChainService(cs), node.WithStore(store), node.WithPolicy(&engine.PermissivePolicy{}))
 	return n, messageService, multiAddr
 }
 
@@ -238,7 +244,8 @@ func checkPaymentChannel(t *testing.T, id types.Destination, o outcome.Exit, sta
 		if err != nil {
 			t.Fatal(err)
 		}
-		if diff := cmp.Diff(expected, ledger, cmp.AllowUnexported(big.Int{})); diff != "" {
+		// Only some chain services report the decimals of the asset
+		if diff := cmp.Diff(expected, ledger, cmp.AllowUnexported(big.Int{}), cmpopts.IgnoreFields(query.PaymentChannelBalance{}, "Decimals")); diff != "" {
 			panic(fmt.Errorf("payment channel diff mismatch (-want +got):\n%s", diff))
 		}
 	}
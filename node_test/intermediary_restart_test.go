@@ -0,0 +1,143 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/virtualfund"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// droppingMessageService receives messages as normal, but silently drops every message it is asked to send.
+// It simulates a node which crashes before its outgoing messages are delivered.
+type droppingMessageService struct {
+	messageservice.TestMessageService
+}
+
+func (dms droppingMessageService) Send(msg protocols.Message) error {
+	return nil
+}
+
+// TestVirtualChannelSurvivesIntermediaryRestart checks that a virtual channel remains usable
+// (for payments and for closing) after the intermediary funding it is restarted.
+func TestVirtualChannelSurvivesIntermediaryRestart(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+
+	ledgerA := openLedgerChannel(t, alice, irene, types.Address{})
+	ledgerB := openLedgerChannel(t, irene, bob, types.Address{})
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	response, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	closeNode(t, &irene)
+	irene, _ = setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 2, 1), query.Complete, alice, bob)
+	checkLedgerChannel(t, ledgerA, finalAliceLedger(ta.Irene.Address(), types.Address{}, 2, 1, 1), query.Open, alice, irene)
+	checkLedgerChannel(t, ledgerB, finalBobLedger(ta.Irene.Address(), types.Address{}, 2, 1, 1), query.Open, irene, bob)
+
+	// The restarted intermediary should also be able to fund new virtual channels
+	second, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{second.Id})
+}
+
+// TestIntermediaryResumesObjectivesAfterRestart checks that an intermediary which restarts before its messages are
+// delivered re-sends them on startup, so that the virtual channel it was funding can still be opened.
+func TestIntermediaryResumesObjectivesAfterRestart(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	closeNode(t, &irene)
+
+	// Restart Irene with a message service which loses everything she sends
+	storeI, err := store.NewDurableStore(ta.Irene.PrivateKey, dataFolder, buntdb.Config{SyncPolicy: buntdb.Always})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dropping := droppingMessageService{messageservice.NewTestMessageService(ta.Irene.Address(), broker, 0)}
+	irene = node.New(node.WithMessageService(dropping), node.WithChainService(chainservice.NewMockChainService(chain, ta.Irene.Address())), node.WithStore(storeI), node.WithPolicy(&engine.PermissivePolicy{}))
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	response, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for Irene to sign the prefund state, which neither Alice nor Bob will receive
+	for signed := false; !signed; {
+		time.Sleep(10 * time.Millisecond)
+		o, err := storeI.GetObjectiveById(response.Id)
+		if err != nil {
+			continue
+		}
+		signed = o.(*virtualfund.Objective).V.PreFundSignedByMe()
+	}
+
+	closeNode(t, &irene)
+	irene, _ = setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 1, 1), query.Complete, alice, bob)
+}
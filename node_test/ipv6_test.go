@@ -0,0 +1,118 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	grpctrans "github.com/statechannels/go-nitro/rpc/transport/grpc"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
+	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
+)
+
+// TestIPv6 checks that a node listening only on IPv6 advertises an IPv6 message service address, and serves rpc over
+// each transport at an IPv6 url.
+func TestIPv6(t *testing.T) {
+	l, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skip("IPv6 loopback is unavailable:", err)
+	}
+	l.Close()
+	logging.SetupDefaultFileLogger("test_ipv6.log", slog.LevelDebug)
+
+	testCases := []struct {
+		connectionType transport.TransportType
+		msgPort        int
+		rpcPort        int
+	}{
+		{transport.Http, 3305, 4305},
+		{transport.Grpc, 3306, 4306},
+		{transport.Nats, 3307, 4307},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.connectionType), func(t *testing.T) {
+			chain := chainservice.NewMockChain()
+			defer chain.Close()
+
+			ourStore := store.NewMemStore(ta.Alice.PrivateKey)
+			messageService := p2pms.NewMessageService(p2pms.MessageOpts{
+				PkBytes:       ta.Alice.PrivateKey,
+				Port:          tc.msgPort,
+				PublicIp:      "::1",
+				SCAddr:        ta.Alice.Address(),
+				AddressFamily: netaddr.IPv6,
+			})
+			if !strings.HasPrefix(messageService.MultiAddr, "/ip6/") {
+				t.Errorf("expected an IPv6 message service address, got %s", messageService.MultiAddr)
+			}
+
+			n := node.New(
+				node.WithMessageService(messageService),
+				node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+				node.WithStore(ourStore),
+				node.WithPolicy(&engine.PermissivePolicy{}))
+
+			cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+			if err != nil {
+				t.Fatal(err)
+			}
+			rpcServer, err := interRpc.InitializeRpcServer(&n, tc.rpcPort, netaddr.IPv6, tc.connectionType, transport.CertificateConfig(&cert), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rpcServer.Close()
+			if !strings.Contains(rpcServer.Url(), "[::1]") {
+				t.Errorf("expected the rpc url to be at the IPv6 loopback address, got %s", rpcServer.Url())
+			}
+
+			var clientConnection transport.Requester
+			switch tc.connectionType {
+			case transport.Http:
+				clientConnection, err = http.NewHttpTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+			case transport.Grpc:
+				var creds credentials.TransportCredentials
+				creds, err = credentials.NewClientTLSFromFile("../tls/statechannels.org.pem", "")
+				if err != nil {
+					t.Fatal(err)
+				}
+				clientConnection, err = grpctrans.NewGrpcTransportAsClient(rpcServer.Url(), grpc.WithTransportCredentials(creds))
+			case transport.Nats:
+				clientConnection, err = natstrans.NewNatsTransportAsClient(rpcServer.Url())
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Creating the client fetches the node's address over the transport
+			rpcClient, err := rpc.NewRpcClient(clientConnection)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rpcClient.Close()
+			address, err := rpcClient.Address()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address != ta.Alice.Address() {
+				t.Errorf("expected address %s, got %s", ta.Alice.Address(), address)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForBatch waits for the batch of ledger channels to finish, returning its progress.
+func waitForBatch(t *testing.T, n node.Node, id string) query.LedgerChannelBatchInfo {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for time.Now().Before(deadline) {
+		info, err := n.LedgerChannelBatchProgress(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !info.Finished.IsZero() {
+			return info
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for batch %s to finish", id)
+	return query.LedgerChannelBatchInfo{}
+}
+
+func TestCreateLedgerChannels(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	template := node.LedgerFundingTemplate{MyDeposit: big.NewInt(100), TheirDeposit: big.NewInt(0), Tags: map[string]string{"fleet": "edge"}}
+	edges := []types.Address{ta.Alice.Address(), ta.Bob.Address()}
+
+	started, err := irene.CreateLedgerChannels(edges, template, node.BatchOptions{Concurrency: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(started.Channels) != 2 || started.Channels[1].Status != query.BatchChannelPending {
+		t.Fatalf("expected the batch to list both counterparties as pending, got %+v", started.Channels)
+	}
+
+	finished := waitForBatch(t, irene, started.ID)
+	for _, c := range finished.Channels {
+		if c.Status != query.BatchChannelFunded {
+			t.Fatalf("expected the channel with %s to be funded, got %s: %s", c.Counterparty, c.Status, c.Error)
+		}
+		ledger, err := irene.GetLedgerChannel(c.ChannelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ledger.Status != query.Open || ledger.Balance.MyBalance.ToInt().Cmp(big.NewInt(100)) != 0 {
+			t.Errorf("expected an open channel funded from the template, got %+v", ledger)
+		}
+	}
+
+	// Counterparties we already have a channel with fail, without failing the rest of the batch
+	repeated, err := irene.CreateLedgerChannels(edges[:1], template, node.BatchOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	failed := waitForBatch(t, irene, repeated.ID).Channels[0]
+	if failed.Status != query.BatchChannelFailed || !strings.Contains(failed.Error, node.ErrLedgerChannelExists.Error()) {
+		t.Errorf("expected the channel to fail as it exists, got %+v", failed)
+	}
+
+	if _, err := irene.CreateLedgerChannels([]types.Address{ta.Alice.Address(), ta.Alice.Address()}, template, node.BatchOptions{}); !errors.Is(err, node.ErrInvalidBatch) {
+		t.Errorf("expected a batch repeating a counterparty to be refused, got %v", err)
+	}
+	if _, err := irene.LedgerChannelBatchProgress("unknown"); !errors.Is(err, node.ErrBatchNotFound) {
+		t.Errorf("expected an unknown batch not to be found, got %v", err)
+	}
+}
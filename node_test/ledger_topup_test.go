@@ -0,0 +1,75 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestAddFundsToLedgerChannel checks that either participant of a ledger channel can add funds to it, and that the
+// channel remains usable afterwards.
+func TestAddFundsToLedgerChannel(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	response, err := alice.CreateLedgerChannel(ta.Bob.Address(), 60, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{response.Id})
+
+	topUp := func(depositor, counterparty node.Node, amount int64) {
+		t.Helper()
+		id, err := depositor.AddFundsToLedgerChannel(response.ChannelId, big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, n := range []node.Node{depositor, counterparty} {
+			select {
+			case <-n.ObjectiveCompleteChan(id):
+			case <-time.After(10 * time.Second):
+				t.Fatalf("expected the top-up %s to complete", id)
+			}
+		}
+	}
+	topUp(alice, bob, 50)
+	topUp(bob, alice, 20)
+
+	ledger, err := alice.GetLedgerChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(ledgerChannelDeposit + 50); ledger.Balance.MyBalance.ToInt().Cmp(want) != 0 {
+		t.Errorf("expected alice to have %v, got %v", want, ledger.Balance.MyBalance)
+	}
+	if want := big.NewInt(ledgerChannelDeposit + 20); ledger.Balance.TheirBalance.ToInt().Cmp(want) != 0 {
+		t.Errorf("expected bob to have %v, got %v", want, ledger.Balance.TheirBalance)
+	}
+
+	if _, err := alice.AddFundsToLedgerChannel(types.Destination{1}, big.NewInt(1)); err == nil {
+		t.Error("expected a top-up of an unknown channel to be refused")
+	}
+
+	// The topped up ledger channel can be closed as usual
+	id, err := alice.CloseLedgerChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{id})
+}
@@ -0,0 +1,91 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestWithdrawFromLedgerChannel checks that either participant of a ledger channel can withdraw part of its balance
+// from it, by closing it and funding a replacement, and that the replacement is usable afterwards.
+func TestWithdrawFromLedgerChannel(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	ledgerId := openLedgerChannel(t, alice, bob, types.Address{})
+
+	withdraw := func(withdrawer, counterparty node.Node, ledgerId types.Destination, amount int64) types.Destination {
+		t.Helper()
+		withdrawal, err := withdrawer.WithdrawFromLedgerChannel(ledgerId, big.NewInt(amount))
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForObjectives(t, withdrawer, counterparty, nil, []protocols.ObjectiveId{withdrawal.CloseObjectiveId, withdrawal.FundObjectiveId})
+		return withdrawal.ChannelId
+	}
+	replacement := withdraw(alice, bob, ledgerId, 30)
+	replacement = withdraw(bob, alice, replacement, 10)
+
+	closed, err := alice.GetLedgerChannel(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if closed.Status != query.Complete {
+		t.Errorf("expected the ledger channel withdrawn from to be closed, got %s", closed.Status)
+	}
+	ledger, err := alice.GetLedgerChannel(replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := big.NewInt(ledgerChannelDeposit - 30); ledger.Balance.MyBalance.ToInt().Cmp(want) != 0 {
+		t.Errorf("expected alice to have %v, got %v", want, ledger.Balance.MyBalance)
+	}
+	if want := big.NewInt(ledgerChannelDeposit - 10); ledger.Balance.TheirBalance.ToInt().Cmp(want) != 0 {
+		t.Errorf("expected bob to have %v, got %v", want, ledger.Balance.TheirBalance)
+	}
+
+	if _, err := alice.WithdrawFromLedgerChannel(types.Destination{1}, big.NewInt(1)); !errors.Is(err, node.ErrChannelNotFound) {
+		t.Errorf("expected a withdrawal from an unknown channel to fail with %v, got %v", node.ErrChannelNotFound, err)
+	}
+	if _, err := alice.WithdrawFromLedgerChannel(replacement, big.NewInt(ledgerChannelDeposit)); !errors.Is(err, node.ErrWithdrawalRefused) {
+		t.Errorf("expected a withdrawal exceeding our balance to fail with %v, got %v", node.ErrWithdrawalRefused, err)
+	}
+
+	// The replacement funds payment channels, which must be closed before it is withdrawn from
+	payment, err := alice.CreatePaymentChannel([]types.Address{}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{payment.Id})
+	if _, err := alice.WithdrawFromLedgerChannel(replacement, big.NewInt(1)); !errors.Is(err, node.ErrWithdrawalRefused) {
+		t.Errorf("expected a withdrawal from a ledger channel funding a payment channel to fail with %v, got %v", node.ErrWithdrawalRefused, err)
+	}
+	closing, err := alice.ClosePaymentChannel(payment.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{closing})
+
+	id, err := alice.CloseLedgerChannel(replacement)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{id})
+}
@@ -0,0 +1,86 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// setupFaultyNode is like setupNode, but faults may be injected into the node's messages.
+func setupFaultyNode(pk []byte, chain chainservice.ChainService, broker messageservice.Broker, dataFolder string) node.Node {
+	ms := messageservice.NewFaultyMessageService(messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(pk), broker, 0))
+	s, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		panic(err)
+	}
+	return node.New(node.WithMessageService(ms), node.WithChainService(chain), node.WithStore(s), node.WithPolicy(&engine.PermissivePolicy{}))
+}
+
+// TestMessageFaults checks that channels are opened, paid through and closed when every message is duplicated and
+// delivered after a random delay, which may reorder messages.
+func TestMessageFaults(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice := setupFaultyNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, dataFolder)
+	defer closeNode(t, &alice)
+	bob := setupFaultyNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, dataFolder)
+	defer closeNode(t, &bob)
+	irene := setupFaultyNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, dataFolder)
+	defer closeNode(t, &irene)
+
+	faults := messageservice.Faults{DuplicateRate: 1, MaxDelay: 50 * time.Millisecond}
+	for _, n := range []*node.Node{&alice, &bob, &irene} {
+		if err := n.SetMessageFaults(faults); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+	closeId, err := alice.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 1, 1), query.Complete, alice, bob)
+}
+
+// TestMessageFaultsUnsupported checks that faults cannot be injected into a node without a fault injecting message service.
+func TestMessageFaultsUnsupported(t *testing.T) {
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chainservice.NewMockChain(), ta.Alice.Address()), messageservice.NewBroker(), 0, dataFolder)
+	defer closeNode(t, &alice)
+
+	if err := alice.SetMessageFaults(messageservice.Faults{DropRate: 1}); !errors.Is(err, node.ErrNoFaultInjection) {
+		t.Fatalf("expected %v, got %v", node.ErrNoFaultInjection, err)
+	}
+}
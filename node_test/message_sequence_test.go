@@ -0,0 +1,86 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/tidwall/buntdb"
+)
+
+// duplicatingMessageService delivers every message it sends twice.
+type duplicatingMessageService struct {
+	messageservice.TestMessageService
+}
+
+func (d duplicatingMessageService) Send(msg protocols.Message) error {
+	if err := d.TestMessageService.Send(msg); err != nil {
+		return err
+	}
+	return d.TestMessageService.Send(msg)
+}
+
+func setupDuplicatingNode(pk []byte, chain chainservice.ChainService, broker messageservice.Broker, dataFolder string) node.Node {
+	ms := duplicatingMessageService{messageservice.NewTestMessageService(crypto.GetAddressFromSecretKeyBytes(pk), broker, 0)}
+	s, err := store.NewDurableStore(pk, dataFolder, buntdb.Config{})
+	if err != nil {
+		panic(err)
+	}
+	return node.New(node.WithMessageService(ms), node.WithChainService(chain), node.WithStore(s), node.WithPolicy(&engine.PermissivePolicy{}))
+}
+
+// TestDuplicateMessagesDiscarded checks that channels are funded, paid and defunded as usual when every message is
+// delivered twice, and that each payment is received once.
+func TestDuplicateMessagesDiscarded(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice := setupDuplicatingNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, dataFolder)
+	defer closeNode(t, &alice)
+	bob := setupDuplicatingNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, dataFolder)
+	defer closeNode(t, &bob)
+	irene := setupDuplicatingNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerA := openLedgerChannel(t, alice, irene, common.Address{})
+	ledgerB := openLedgerChannel(t, irene, bob, common.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+	select {
+	case v := <-bob.ReceivedVouchers():
+		t.Fatalf("expected the payment to be received once, but received %v again", v.Amount)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	closeId, err := bob.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 1, 1), query.Complete, alice, bob)
+
+	closeLedgerChannel(t, alice, irene, ledgerA)
+	closeLedgerChannel(t, irene, bob, ledgerB)
+}
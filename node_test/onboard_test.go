@@ -0,0 +1,65 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// mockFaucet credits the wallet of a MockChainService when it drips.
+type mockFaucet struct {
+	wallet  *chainservice.MockChainService
+	amount  *big.Int
+	dripped []types.Address
+}
+
+func (mf *mockFaucet) Drip(_ context.Context, account types.Address) error {
+	mf.dripped = append(mf.dripped, account)
+	mf.wallet.SetWalletBalance(types.Address{}, mf.amount)
+	return nil
+}
+
+// TestOnboard checks that Onboard requests funds from the faucet when the funding wallet cannot afford the deposit,
+// then opens a ledger channel with the hub.
+func TestOnboard(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chainServiceA := chainservice.NewMockChainService(chain, ta.Alice.Address())
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	f := &mockFaucet{wallet: chainServiceA, amount: big.NewInt(ledgerChannelDeposit)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	response, err := alice.Onboard(ctx, node.OnboardOptions{Faucet: f, Hub: ta.Irene.Address(), Deposit: big.NewInt(ledgerChannelDeposit)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.dripped) != 1 || f.dripped[0] != ta.Alice.Address() {
+		t.Errorf("expected one drip to the funding wallet, got %v", f.dripped)
+	}
+
+	ledger, err := alice.GetLedgerChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Status != query.Open {
+		t.Errorf("expected the ledger channel with the hub to be open, got %s", ledger.Status)
+	}
+
+}
@@ -0,0 +1,317 @@
+package node_test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/state"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/payments/remotesigner"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// countingVoucherManager stands in for an application's fake voucher manager.
+type countingVoucherManager struct {
+	node.VoucherManager
+	registered atomic.Int32
+	paid       atomic.Int32
+}
+
+func (vm *countingVoucherManager) Register(channelId types.Destination, asset common.Address, payer common.Address, payee common.Address, startingBalance *big.Int) error {
+	vm.registered.Add(1)
+	return vm.VoucherManager.Register(channelId, asset, payer, payee, startingBalance)
+}
+
+func (vm *countingVoucherManager) Pay(channelId types.Destination, amount *big.Int, signer payments.VoucherSigner) (payments.Voucher, error) {
+	vm.paid.Add(1)
+	return vm.VoucherManager.Pay(channelId, amount, signer)
+}
+
+func (vm *countingVoucherManager) PayWithExpiry(channelId types.Destination, amount *big.Int, expiry time.Time, signer payments.VoucherSigner) (payments.Voucher, error) {
+	vm.paid.Add(1)
+	return vm.VoucherManager.PayWithExpiry(channelId, amount, expiry, signer)
+}
+
+// TestWithVoucherManager checks that a node uses a voucher manager supplied as an option.
+func TestWithVoucherManager(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	newNode := func(actor ta.Actor, opts ...node.Option) node.Node {
+		var s node.Store = store.NewMemStore(actor.PrivateKey)
+		var cs node.ChainService = chainservice.NewMockChainService(chain, actor.Address())
+		var ms node.MessageService = messageservice.NewTestMessageService(actor.Address(), broker, 0)
+		var pm node.PolicyMaker = &engine.PermissivePolicy{}
+		return node.New(append([]node.Option{node.WithMessageService(ms), node.WithChainService(cs), node.WithStore(s), node.WithPolicy(pm)}, opts...)...)
+	}
+
+	aliceStore := store.NewMemStore(ta.Alice.PrivateKey)
+	vm := &countingVoucherManager{VoucherManager: payments.NewVoucherManager(ta.Alice.Address(), aliceStore)}
+	alice := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+		node.WithStore(aliceStore),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+		node.WithVoucherManager(vm),
+	)
+	defer closeNode(t, &alice)
+	bob := newNode(ta.Bob)
+	defer closeNode(t, &bob)
+	irene := newNode(ta.Irene)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	if vm.registered.Load() != 1 || vm.paid.Load() != 1 {
+		t.Fatalf("expected the supplied voucher manager to register 1 channel and make 1 payment, got %d and %d", vm.registered.Load(), vm.paid.Load())
+	}
+}
+
+// TestWithSigner checks that a node whose store does not hold its key makes every signature with a signer supplied as
+// an option, and that a signer refusing to sign a voucher fails the payment without stopping the node.
+func TestWithSigner(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	handler, err := remotesigner.NewHandler(engine.KeySigner(ta.Alice.PrivateKey), "secret", remotesigner.Policy{
+		Vouchers: remotesigner.SpendingLimit(big.NewInt(3)),
+		States:   remotesigner.AnyState,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingService := httptest.NewServer(handler)
+	defer signingService.Close()
+	signer, err := remotesigner.NewClient(signingService.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newNode := func(actor ta.Actor, s node.Store, opts ...node.Option) node.Node {
+		var cs node.ChainService = chainservice.NewMockChainService(chain, actor.Address())
+		var ms node.MessageService = messageservice.NewTestMessageService(actor.Address(), broker, 0)
+		var pm node.PolicyMaker = &engine.PermissivePolicy{}
+		return node.New(append([]node.Option{node.WithMessageService(ms), node.WithChainService(cs), node.WithStore(s), node.WithPolicy(pm)}, opts...)...)
+	}
+	keyless, err := store.NewStore(store.StoreOpts{Address: ta.Alice.Address()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keyless.GetChannelSecretKey() != nil {
+		t.Fatal("expected the store not to hold alice's key")
+	}
+	alice := newNode(ta.Alice, keyless, node.WithSigner(signer))
+	defer closeNode(t, &alice)
+	bob := newNode(ta.Bob, store.NewMemStore(ta.Bob.PrivateKey))
+	defer closeNode(t, &bob)
+	irene := newNode(ta.Irene, store.NewMemStore(ta.Irene.PrivateKey))
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(2))
+	if received := <-bob.ReceivedVouchers(); received.Amount.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("expected bob to be paid 2, got %s", received.Amount)
+	}
+
+	// The signing service refuses to pay more than 3 in total, so this payment is not made
+	alice.Pay(response.ChannelId, big.NewInt(2))
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	if received := <-bob.ReceivedVouchers(); received.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Fatalf("expected bob to be paid 3 in total, got %s", received.Amount)
+	}
+}
+
+// arbitrationDisputes stands in for an adapter raising disputes with an arbitration service rather than on chain.
+type arbitrationDisputes struct {
+	raised chan types.Destination
+}
+
+func (a *arbitrationDisputes) Challenge(id types.Destination, candidate state.SignedState, signer state.Signer) ([]protocols.ChainTransaction, error) {
+	a.raised <- id
+	return nil, nil
+}
+
+// TestWithDisputeAdapter checks that a node raises its challenges with a dispute adapter supplied as an option.
+func TestWithDisputeAdapter(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	disputes := &arbitrationDisputes{raised: make(chan types.Destination, 1)}
+	alice := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+		node.WithStore(store.NewMemStore(ta.Alice.PrivateKey)),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+		node.WithDisputeAdapter(disputes),
+	)
+	defer closeNode(t, &alice)
+	bob := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(ta.Bob.Address(), broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Bob.Address())),
+		node.WithStore(store.NewMemStore(ta.Bob.PrivateKey)),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+	)
+	defer closeNode(t, &bob)
+
+	ledgerId := openLedgerChannel(t, alice, bob, types.Address{})
+	if err := alice.ChallengeLedgerChannel(context.Background(), ledgerId); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case id := <-disputes.raised:
+		if id != ledgerId {
+			t.Errorf("expected a dispute over ledger channel %s, got %s", ledgerId, id)
+		}
+	default:
+		t.Fatal("expected the supplied dispute adapter to raise the challenge")
+	}
+}
+
+// recordingMetrics stands in for an application's metrics exporter.
+type recordingMetrics struct {
+	mu        sync.Mutex
+	counters  map[string]int
+	durations map[string]int
+	gauges    map[string]float64
+}
+
+func newRecordingMetrics() *recordingMetrics {
+	return &recordingMetrics{counters: map[string]int{}, durations: map[string]int{}, gauges: map[string]float64{}}
+}
+
+func (m *recordingMetrics) IncCounter(name string, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counters[name+labels["protocol"]]++
+}
+
+func (m *recordingMetrics) RecordDuration(name string, d time.Duration, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.durations[name+"/"+labels["stage"]]++
+}
+
+func (m *recordingMetrics) SetGauge(name string, value float64, labels map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.gauges[name+"/"+labels["queue"]] = value
+}
+
+func (m *recordingMetrics) count(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counters[name]
+}
+
+func (m *recordingMetrics) observations(name string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.durations[name]
+}
+
+func (m *recordingMetrics) gauge(name string) (float64, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.gauges[name]
+	return v, ok
+}
+
+// syncBuffer is a buffer a logger may write to while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestNewWithDefaults checks that nodes constructed without any subsystems can fund channels with and pay each other,
+// reporting to the metrics and logger supplied to them.
+func TestNewWithDefaults(t *testing.T) {
+	aliceMetrics, bobMetrics := newRecordingMetrics(), newRecordingMetrics()
+	logs := &syncBuffer{}
+	alice := node.New(node.WithMetrics(aliceMetrics), node.WithLogger(slog.New(slog.NewJSONHandler(logs, nil))))
+	defer closeNode(t, &alice)
+	bob := node.New(node.WithMetrics(bobMetrics), node.WithBufferSizes(node.BufferSizes{Vouchers: 1}))
+	defer closeNode(t, &bob)
+	irene := node.New()
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{*irene.Address}, *bob.Address, 0, initialPaymentOutcome(*alice.Address, *bob.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	if got := aliceMetrics.count(node.ObjectivesCompletedMetric + "DirectFunding"); got != 1 {
+		t.Errorf("expected alice to count 1 completed direct funding objective, got %d", got)
+	}
+	if got := aliceMetrics.count(node.ObjectivesCompletedMetric + "VirtualFund"); got != 1 {
+		t.Errorf("expected alice to count 1 completed virtual funding objective, got %d", got)
+	}
+	if got := aliceMetrics.observations(engine.PaymentLatencyMetric + "/" + string(engine.PaymentOutgoing)); got != 1 {
+		t.Errorf("expected alice to observe the latency of 1 outgoing payment, got %d", got)
+	}
+	if got := bobMetrics.count(node.VouchersReceivedMetric); got != 1 {
+		t.Errorf("expected bob to count 1 received voucher, got %d", got)
+	}
+	if got := aliceMetrics.count(engine.VouchersSentMetric); got != 1 {
+		t.Errorf("expected alice to count 1 sent voucher, got %d", got)
+	}
+	if got := aliceMetrics.observations(engine.ObjectiveCrankMetric + "/"); got == 0 {
+		t.Error("expected alice to observe the duration of her objectives' cranks")
+	}
+	if _, ok := aliceMetrics.gauge(engine.QueueDepthMetric + "/messages"); !ok {
+		t.Error("expected alice to gauge the depth of her message queue")
+	}
+	if !strings.Contains(logs.String(), alice.Address.String()) {
+		t.Errorf("expected alice's engine to log to the supplied logger, got %q", logs.String())
+	}
+}
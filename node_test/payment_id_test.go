@@ -0,0 +1,143 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestPaymentIds checks that payments retried with the same payment id are only made once.
+func TestPaymentIds(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	// Retrying a payment resends the original voucher, which the payee has already received
+	for i := 0; i < 3; i++ {
+		if err := alice.PayWithId(context.Background(), payment.ChannelId, big.NewInt(5), "order-1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := alice.PayWithId(context.Background(), payment.ChannelId, big.NewInt(7), "order-2"); err != nil {
+		t.Fatal(err)
+	}
+	waitForPaidSoFar(t, bob, payment.ChannelId, big.NewInt(12))
+
+	// Retrying voucher creation returns the original voucher
+	first, err := alice.CreateVoucherWithId(payment.ChannelId, big.NewInt(3), "order-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retried, err := alice.CreateVoucherWithId(payment.ChannelId, big.NewInt(3), "order-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !retried.Equal(&first) {
+		t.Errorf("expected the retried voucher %+v to equal the original %+v", retried, first)
+	}
+	if first.Amount.Cmp(big.NewInt(15)) != 0 {
+		t.Errorf("expected a voucher for 15, got %s", first.Amount)
+	}
+
+	_, err = alice.CreateVoucherWithId(payment.ChannelId, big.NewInt(4), "order-3")
+	if !errors.Is(err, payments.ErrPaymentIdReused) {
+		t.Errorf("expected %v, got %v", payments.ErrPaymentIdReused, err)
+	}
+}
+
+// TestPaymentContext checks that the context of a payment reaches the payee alongside its voucher.
+func TestPaymentContext(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	tooLong := strings.Repeat("x", payments.MaxVoucherContextLength+1)
+	if err := alice.PayWithOptions(context.Background(), payment.ChannelId, big.NewInt(5), node.PayOptions{Context: tooLong}); err == nil {
+		t.Fatal("expected a payment with an oversized context to be refused")
+	}
+
+	opts := node.PayOptions{PaymentId: "order-1", Context: "GET /video/1 #42"}
+	if err := alice.PayWithOptions(context.Background(), payment.ChannelId, big.NewInt(5), opts); err != nil {
+		t.Fatal(err)
+	}
+	for _, received := range []<-chan payments.Voucher{bob.ReceivedVouchers(), bob.VoucherUpdates()} {
+		select {
+		case v := <-received:
+			if v.Context != opts.Context || v.Amount.Cmp(big.NewInt(5)) != 0 {
+				t.Errorf("expected a voucher for 5 with context %q, got %+v", opts.Context, v)
+			}
+		case <-time.After(defaultTimeout):
+			t.Fatal("timed out waiting for the voucher")
+		}
+	}
+}
+
+// waitForPaidSoFar waits until the node's view of the payment channel has paid amount so far.
+func waitForPaidSoFar(t *testing.T, n node.Node, channelId types.Destination, amount *big.Int) {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		info, err := n.GetPaymentChannel(channelId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paid := (*big.Int)(info.Balance.PaidSoFar)
+		if paid.Cmp(amount) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s paid so far, got %s", amount, paid)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
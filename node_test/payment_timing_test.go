@@ -0,0 +1,92 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// awaitPaymentLatencies waits for the node to have measured count latencies of the stage.
+func awaitPaymentLatencies(t *testing.T, n node.Node, stage engine.PaymentStage, count int) engine.LatencyPercentiles {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	for time.Now().Before(deadline) {
+		if l := n.PaymentLatencies()[stage]; l.Count == count {
+			return l
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s: timed out waiting for %d %s payment latencies, got %+v", n.Address, count, stage, n.PaymentLatencies())
+	return engine.LatencyPercentiles{}
+}
+
+// TestPaymentTimings checks that the latency of each stage of a payment is measured by the payer and the payee, and
+// that the timing of each hop is kept for recent payments when enabled.
+func TestPaymentTimings(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	alice.EnablePaymentTimings(2)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	for i := 1; i <= 3; i++ {
+		if err := alice.PayContext(context.Background(), payment.ChannelId, big.NewInt(1)); err != nil {
+			t.Fatal(err)
+		}
+		waitForPaidSoFar(t, bob, payment.ChannelId, big.NewInt(int64(i)))
+	}
+
+	for _, stage := range []engine.PaymentStage{engine.PaymentQueued, engine.PaymentSending, engine.PaymentOutgoing} {
+		l := awaitPaymentLatencies(t, alice, stage, 3)
+		if l.P50 < 0 || l.P99 < l.P50 {
+			t.Errorf("expected ordered, non-negative %s latencies, got %+v", stage, l)
+		}
+	}
+	awaitPaymentLatencies(t, bob, engine.PaymentIncoming, 3)
+	if _, ok := alice.PaymentLatencies()[engine.PaymentIncoming]; ok {
+		t.Error("expected the payer to measure no incoming payments")
+	}
+
+	// Only the most recent payments are kept, and only by the node which enabled it
+	timings := alice.RecentPaymentTimings()
+	if len(timings) != 2 {
+		t.Fatalf("expected the timings of the 2 most recent payments, got %+v", timings)
+	}
+	last := timings[1]
+	if last.ChannelId != payment.ChannelId || last.Amount.Cmp(big.NewInt(3)) != 0 {
+		t.Errorf("expected the last timing to be of the voucher for 3, got %+v", last)
+	}
+	if last.Enqueued.IsZero() || last.Cranked.Before(last.Enqueued) || last.Sent.Before(last.Cranked) || !last.Received.IsZero() {
+		t.Errorf("expected the hops of an outgoing payment in order, got %+v", last)
+	}
+	if len(bob.RecentPaymentTimings()) != 0 {
+		t.Errorf("expected no timings to be kept by the payee, got %+v", bob.RecentPaymentTimings())
+	}
+}
@@ -126,6 +126,25 @@ func TestPaymentProxy(t *testing.T) {
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/resource/params?channelId=%s&amount=%d&signature=%s&otherParam=2", proxyAddress, voucher.ChannelId, voucher.Amount, voucher.Signature.ToHexString()))
 	checkResponse(t, resp, smallResponse, http.StatusOK)
 
+	// A voucher may be carried in the X-Nitro-Voucher header instead, in either encoding, to pay for a POST
+	for _, encode := range []func(payments.Voucher) (string, error){paymentproxy.EncodeVoucherHeader, paymentproxy.EncodeCompactVoucher} {
+		voucher = createVoucher(t, aliceClient, paymentChannel, 5)
+		header, err := encode(voucher)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("POST", fmt.Sprintf("http://%s/resource", proxyAddress), strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(paymentproxy.VOUCHER_HEADER, header)
+		resp, err = http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkResponse(t, resp, smallResponse, http.StatusOK)
+	}
+
 	// It should properly handle a request to a non existent endpoint
 	voucher = createVoucher(t, aliceClient, paymentChannel, 5)
 	resp = performGetRequest(t, "", fmt.Sprintf("http://%s/badpath?channelId=%s&amount=%d&signature=%s", proxyAddress, voucher.ChannelId, voucher.Amount.Uint64(), voucher.Signature.ToHexString()))
@@ -325,6 +344,9 @@ func runDestinationServer(t *testing.T, port uint) (destUrl string, cleanup func
 				t.Fatalf("Expected no voucher information to be passed along, but got %s", p)
 			}
 		}
+		if r.Header.Get(paymentproxy.VOUCHER_HEADER) != "" {
+			t.Fatalf("Expected the %s header to be stripped", paymentproxy.VOUCHER_HEADER)
+		}
 
 		if r.URL.Path == "/file" {
 			http.ServeFile(w, r, testFileName)
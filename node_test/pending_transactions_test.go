@@ -0,0 +1,101 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// droppingChainService tracks the transactions it submits to a mock chain, and drops them while drop is set, as a
+// provider might drop transactions from its mempool.
+type droppingChainService struct {
+	*chainservice.MockChainService
+	drop bool
+
+	mu     sync.Mutex
+	mined  map[common.Hash]bool
+	nextTx int64
+}
+
+func newDroppingChainService(chain *chainservice.MockChain, address types.Address, drop bool) *droppingChainService {
+	return &droppingChainService{MockChainService: chainservice.NewMockChainService(chain, address), drop: drop, mined: map[common.Hash]bool{}}
+}
+
+func (dcs *droppingChainService) SendTrackedTransaction(tx protocols.ChainTransaction, _ bool) ([]common.Hash, error) {
+	dcs.mu.Lock()
+	dcs.nextTx++
+	hash := common.BigToHash(big.NewInt(dcs.nextTx))
+	dcs.mined[hash] = !dcs.drop
+	dcs.mu.Unlock()
+	if dcs.drop {
+		return []common.Hash{hash}, nil
+	}
+	return []common.Hash{hash}, dcs.MockChainService.SendTransaction(tx)
+}
+
+func (dcs *droppingChainService) TransactionStatus(_ context.Context, hash common.Hash) (chainservice.TxStatus, error) {
+	dcs.mu.Lock()
+	defer dcs.mu.Unlock()
+	if dcs.mined[hash] {
+		return chainservice.TxMined, nil
+	}
+	return chainservice.TxDropped, nil
+}
+
+// TestDroppedDepositResubmittedOnRestart checks that a deposit dropped before it was mined is resubmitted when the node
+// restarts, so that the ledger channel it funds is opened without intervention.
+func TestDroppedDepositResubmittedOnRestart(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+	broker := messageservice.NewBroker()
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, aliceStore := setupNode(ta.Alice.PrivateKey, newDroppingChainService(chain, ta.Alice.Address(), true), broker, 0, dataFolder)
+	bob, _ := setupNode(ta.Bob.PrivateKey, newDroppingChainService(chain, ta.Bob.Address(), false), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	response, err := alice.CreateLedgerChannel(*bob.Address, 0, initialLedgerOutcome(*alice.Address, *bob.Address, types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice's deposit is recorded as pending, but never reaches the chain
+	deadline := time.Now().Add(defaultTimeout)
+	for {
+		pending, err := aliceStore.GetPendingTransactions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pending) == 1 && len(pending[0].TxHashes) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for alice's deposit to be submitted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	closeNode(t, &alice)
+
+	alice, aliceStore = setupNode(ta.Alice.PrivateKey, newDroppingChainService(chain, ta.Alice.Address(), false), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	waitForObjectives(t, alice, bob, nil, []protocols.ObjectiveId{response.Id})
+
+	pending, err := aliceStore.GetPendingTransactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected the completed objective's transactions to be forgotten, got %+v", pending)
+	}
+}
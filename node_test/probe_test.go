@@ -0,0 +1,88 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// channelSizePolicy accepts channels whose deposits total at most max.
+type channelSizePolicy struct {
+	*engine.PermissivePolicy
+	max *big.Int
+}
+
+func (p channelSizePolicy) AcceptsChannelSize(counterparty types.Address, asset types.Address, deposit *big.Int, ourDeposit *big.Int) bool {
+	return new(big.Int).Add(deposit, ourDeposit).Cmp(p.max) <= 0
+}
+
+func TestProbeCounterparty(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	junk := common.HexToAddress("0x000000000000000000000000000000000000dead")
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder,
+		channelSizePolicy{&engine.PermissivePolicy{AllowedAssets: []types.Address{{}}}, big.NewInt(2 * ledgerChannelDeposit)})
+	defer closeNode(t, &irene)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	probes := []struct {
+		asset   types.Address
+		deposit int64
+		refusal protocols.ProbeRefusal
+	}{
+		{types.Address{}, 1000, ""},
+		{junk, 1000, protocols.ProbeAssetNotAccepted},
+		{types.Address{}, ledgerChannelDeposit + 1, protocols.ProbeChannelSizeRefused},
+	}
+	for _, p := range probes {
+		probe, err := alice.ProbeCounterparty(ctx, ta.Irene.Address(), p.asset, big.NewInt(p.deposit), big.NewInt(p.deposit))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if probe.Accepts != (p.refusal == "") || probe.Refusal != p.refusal {
+			t.Errorf("expected a probe of %d of asset %s to be refused with %q, got %+v", p.deposit, p.asset, p.refusal, probe)
+		}
+		if len(probe.Versions) == 0 || probe.Versions[0] != protocols.ProtocolVersion {
+			t.Errorf("expected the probe to list the supported versions, got %v", probe.Versions)
+		}
+	}
+
+	// A channel the counterparty would refuse fails fast, without creating an objective
+	oversized := testdata.Outcomes.Create(ta.Alice.Address(), ta.Irene.Address(), ledgerChannelDeposit+1, ledgerChannelDeposit+1, types.Address{})
+	_, err := alice.CreateLedgerChannelWithOptions(ctx, ta.Irene.Address(), 0, oversized, node.CreateChannelOptions{Probe: true})
+	var refused *node.ProbeRefusedError
+	if !errors.As(err, &refused) || !errors.Is(err, node.ErrCounterpartyRefused) || refused.Refusal != protocols.ProbeChannelSizeRefused {
+		t.Fatalf("expected creating an oversized channel to fail with a ProbeRefusedError, got %v", err)
+	}
+	if ledgers, err := alice.GetAllLedgerChannels(); err != nil || len(ledgers) != 0 {
+		t.Fatalf("expected no ledger channel to have been created, got %v (%v)", ledgers, err)
+	}
+
+	response, err := alice.CreateLedgerChannelWithOptions(ctx, ta.Irene.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{}), node.CreateChannelOptions{Probe: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.WaitForObjective(ctx, response.Id); err != nil {
+		t.Fatal(err)
+	}
+}
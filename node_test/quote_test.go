@@ -0,0 +1,69 @@
+package node_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestQuoteFromAnotherPeerIsIgnored(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	// Irene and Bob are played by the test, so that Bob can answer the request sent to Irene before she does
+	irene := messageservice.NewTestMessageService(ta.Irene.Address(), broker, 0)
+	bob := messageservice.NewTestMessageService(ta.Bob.Address(), broker, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	type result struct {
+		quote protocols.Quote
+		err   error
+	}
+	quoted := make(chan result, 1)
+	go func() {
+		quote, err := alice.GetQuoteContext(ctx, ta.Irene.Address(), ta.Bob.Address(), types.Address{}, big.NewInt(100))
+		quoted <- result{quote, err}
+	}()
+
+	var request protocols.QuoteRequest
+	select {
+	case msg := <-irene.P2PMessages():
+		if len(msg.QuoteRequests) != 1 {
+			t.Fatalf("expected a quote request, got %+v", msg)
+		}
+		request = msg.QuoteRequests[0]
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for the quote request")
+	}
+
+	spoofed := protocols.Quote{RequestId: request.Id, Intermediary: ta.Irene.Address(), Fee: big.NewInt(0), CanRoute: true}
+	if err := bob.Send(protocols.Message{To: ta.Alice.Address(), From: ta.Bob.Address(), Quotes: []protocols.Quote{spoofed}}); err != nil {
+		t.Fatal(err)
+	}
+	genuine := protocols.Quote{RequestId: request.Id, Intermediary: ta.Irene.Address(), Fee: big.NewInt(5), CanRoute: true}
+	if err := irene.Send(protocols.Message{To: ta.Alice.Address(), From: ta.Irene.Address(), Quotes: []protocols.Quote{genuine}}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := <-quoted
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	if r.quote.Intermediary != ta.Irene.Address() || r.quote.Fee.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("expected Irene's quote, got %+v", r.quote)
+	}
+}
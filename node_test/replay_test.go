@@ -0,0 +1,161 @@
+package node_test
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestReplay checks that replaying the event log of a node against a fresh store reconstructs the node's channels.
+func TestReplay(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	var log bytes.Buffer
+	// A memory store remains readable once the node is closed
+	storeA := store.NewMemStore(ta.Alice.PrivateKey)
+	alice := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+		node.WithStore(storeA),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+		node.WithEventLog(&log, nil),
+	)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	<-bob.ReceivedVouchers()
+
+	// Closing the node stops the engine, so that the log is complete
+	closeNode(t, &alice)
+
+	replayed := store.NewMemStore(ta.Alice.PrivateKey)
+	steps := 0
+	err = engine.Replay(bytes.NewReader(log.Bytes()), nil, replayed, &engine.PermissivePolicy{}, func(step engine.ReplayStep) bool {
+		steps++
+		if step.Err != nil {
+			t.Errorf("entry %d (%s) failed to replay: %v", step.Entry.Seq, step.Entry.Kind, step.Err)
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if steps == 0 {
+		t.Fatal("expected the event log to hold inputs")
+	}
+
+	wantLedger, err := storeA.GetConsensusChannelById(ledgerId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gotLedger, err := replayed.GetConsensusChannelById(ledgerId)
+	if err != nil {
+		t.Fatalf("expected the replay to reconstruct ledger channel %s: %v", ledgerId, err)
+	}
+	if diff := cmp.Diff(wantLedger.ConsensusVars().AsState(wantLedger.FixedPart()), gotLedger.ConsensusVars().AsState(gotLedger.FixedPart()), cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Errorf("replayed ledger channel differs: %s", diff)
+	}
+
+	wantPayment, ok := storeA.GetChannelById(response.ChannelId)
+	if !ok {
+		t.Fatalf("expected alice to hold payment channel %s", response.ChannelId)
+	}
+	gotPayment, ok := replayed.GetChannelById(response.ChannelId)
+	if !ok {
+		t.Fatalf("expected the replay to reconstruct payment channel %s", response.ChannelId)
+	}
+	want, err := wantPayment.LatestSupportedState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := gotPayment.LatestSupportedState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, got, cmp.AllowUnexported(big.Int{})); diff != "" {
+		t.Errorf("replayed payment channel differs: %s", diff)
+	}
+
+	// A log is only replayed against the store of the node which recorded it
+	if err := engine.Replay(bytes.NewReader(log.Bytes()), nil, store.NewMemStore(ta.Bob.PrivateKey), &engine.PermissivePolicy{}, nil); err == nil {
+		t.Error("expected a log recorded by alice to be rejected for bob's store")
+	}
+}
+
+// TestReplayEncryptedLog checks that an event log recorded with the store's key holds no plaintext inputs, and is only
+// replayed with that key.
+func TestReplayEncryptedLog(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	keys := store.StaticKey(bytes.Repeat([]byte{7}, 32))
+	cipher, err := store.NewRecordCipher(keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var log bytes.Buffer
+	storeA := store.NewMemStore(ta.Alice.PrivateKey)
+	alice := node.New(
+		node.WithMessageService(messageservice.NewTestMessageService(ta.Alice.Address(), broker, 0)),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+		node.WithStore(storeA),
+		node.WithPolicy(&engine.PermissivePolicy{}),
+		node.WithEventLog(&log, cipher),
+	)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	ledgerId := openLedgerChannel(t, alice, irene, types.Address{})
+	closeNode(t, &alice)
+
+	if bytes.Contains(log.Bytes(), []byte(strings.ToLower(ta.Irene.Address().Hex()[2:]))) {
+		t.Error("expected the event log to hold no plaintext inputs")
+	}
+
+	if err := engine.Replay(bytes.NewReader(log.Bytes()), nil, store.NewMemStore(ta.Alice.PrivateKey), &engine.PermissivePolicy{}, nil); !errors.Is(err, engine.ErrEventLogEncrypted) {
+		t.Errorf("expected %v, got %v", engine.ErrEventLogEncrypted, err)
+	}
+	wrong := store.StaticKey(bytes.Repeat([]byte{8}, 32))
+	if err := engine.Replay(bytes.NewReader(log.Bytes()), wrong, store.NewMemStore(ta.Alice.PrivateKey), &engine.PermissivePolicy{}, nil); err == nil {
+		t.Error("expected the event log not to be replayed with the wrong key")
+	}
+
+	replayed := store.NewMemStore(ta.Alice.PrivateKey)
+	if err := engine.Replay(bytes.NewReader(log.Bytes()), keys, replayed, &engine.PermissivePolicy{}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := replayed.GetConsensusChannelById(ledgerId); err != nil {
+		t.Errorf("expected the replay to reconstruct ledger channel %s: %v", ledgerId, err)
+	}
+}
@@ -0,0 +1,86 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestIntermediarySelection checks that automatic route selection prefers the more responsive of two intermediaries
+// able to route a payment channel, and that the chosen intermediary's success is recorded.
+func TestIntermediarySelection(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	// Ivan is slow to send messages
+	ivan, _ := setupNode(ta.Ivan.PrivateKey, chainservice.NewMockChainService(chain, ta.Ivan.Address()), broker, 200*time.Millisecond, dataFolder)
+	defer closeNode(t, &ivan)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, alice, ivan, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	openLedgerChannel(t, ivan, bob, types.Address{})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		quote, err := alice.SelectIntermediary(ctx, ta.Bob.Address(), types.Address{}, big.NewInt(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if quote.Intermediary != ta.Irene.Address() {
+			t.Fatalf("expected the more responsive intermediary %s to be selected, got %s", ta.Irene.Address(), quote.Intermediary)
+		}
+	}
+	stats := alice.PeerStats()
+	if stats[ta.Irene.Address()].RoundTrip == 0 || stats[ta.Ivan.Address()].RoundTrip <= stats[ta.Irene.Address()].RoundTrip {
+		t.Fatalf("expected Ivan's round trip to exceed Irene's, got %v and %v", stats[ta.Ivan.Address()].RoundTrip, stats[ta.Irene.Address()].RoundTrip)
+	}
+
+	response, err := alice.CreatePaymentChannelAutoRoute(ctx, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	stats = alice.PeerStats()
+	if stats[ta.Irene.Address()].Succeeded != 1 || stats[ta.Ivan.Address()].Succeeded != 0 {
+		t.Fatalf("expected the payment channel to be routed through Irene, got stats %+v", stats)
+	}
+
+	// The opening of the channel is recorded with the fee Irene quoted to route it
+	export := &strings.Builder{}
+	if err := alice.ExportActivity(export, query.JsonlExportFormat, time.Now().Add(-time.Hour), time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	checkActivityExport(t, export.String(), map[string]query.ActivityExportRecord{
+		"channel_opened " + response.ChannelId.String(): {Fee: "0"},
+	})
+
+	// No intermediary has a ledger channel with a stranger
+	stranger := types.Address{1}
+	_, err = alice.SelectIntermediary(ctx, stranger, types.Address{}, big.NewInt(1))
+	if !errors.Is(err, node.ErrNoRoute) {
+		t.Fatalf("expected %v, got %v", node.ErrNoRoute, err)
+	}
+}
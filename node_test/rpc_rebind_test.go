@@ -0,0 +1,103 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestRpcServerRebind checks that the rpc server can move to another port while it runs, keeping the connections of
+// its clients open.
+func TestRpcServerRebind(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	certFile, keyFile := "../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem"
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcServer, err := interRpc.InitializeRpcServer(&alice, 4206, netaddr.DualStack, transport.Http, transport.CertificateConfig(&cert), nil, rpc.WithCertDir("../tls"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcServer.Close()
+
+	connect := func(url string) rpc.RpcClientApi {
+		t.Helper()
+		clientConnection, err := http.NewHttpTransportAsClient(url, 10*time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client, err := rpc.NewRpcClient(clientConnection)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client
+	}
+	previousUrl := rpcServer.Url()
+	client := connect(previousUrl)
+	defer client.Close()
+
+	// Only files within the certificate folder may be read
+	if _, err := client.RebindRpcServer(4207, "../go.mod", keyFile); err == nil {
+		t.Fatal("expected a certificate outside the certificate folder to be refused")
+	}
+
+	url, err := client.RebindRpcServer(4207, certFile, "statechannels.org_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(url, ":4207/") || url != rpcServer.Url() {
+		t.Fatalf("expected the server to move to port 4207, got %s", url)
+	}
+	if _, err := http.NewHttpTransportAsClient(previousUrl, time.Millisecond); err == nil {
+		t.Fatal("expected the previous port to refuse new connections")
+	}
+
+	// A port which is in use cannot be bound, and leaves the server where it is
+	occupied, err := net.Listen("tcp", ":4208")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer occupied.Close()
+	if _, err := client.RebindRpcServer(4208, "", ""); err == nil {
+		t.Fatal("expected rebinding to a port in use to fail")
+	}
+
+	moved := connect(url)
+	defer moved.Close()
+	ledger, err := moved.CreateLedgerChannel(ta.Bob.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The subscription made on the previous port still receives notifications
+	for _, c := range []rpc.RpcClientApi{moved, client} {
+		select {
+		case <-c.ObjectiveCompleteChan(ledger.Id):
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the ledger channel to be created")
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestRpcServerSwapNode checks that an rpc client keeps working across a restart of the node behind the rpc server,
+// over the same connection.
+func TestRpcServerSwapNode(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+
+	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rpcServer, err := interRpc.InitializeRpcServer(&alice, 4205, netaddr.DualStack, transport.Http, transport.CertificateConfig(&cert), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rpcServer.Close()
+
+	clientConnection, err := http.NewHttpTransportAsClient(rpcServer.Url(), 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := rpc.NewRpcClient(clientConnection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	ledger, err := client.CreateLedgerChannel(ta.Bob.Address(), 0, initialLedgerOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-client.ObjectiveCompleteChan(ledger.Id)
+
+	// A request made while the node is restarting waits for the restarted node
+	swapping := make(chan struct{})
+	queried := make(chan error)
+	err = rpcServer.SwapNode(func(current *node.Node) (*node.Node, error) {
+		go func() {
+			close(swapping)
+			_, err := client.GetLedgerChannel(ledger.ChannelId)
+			queried <- err
+		}()
+		<-swapping
+		time.Sleep(50 * time.Millisecond) // give the request time to reach the server
+
+		if err := current.Close(); err != nil {
+			return nil, err
+		}
+		restarted, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+		return &restarted, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-queried; err != nil {
+		t.Fatalf("expected the request made during the swap to succeed, got %v", err)
+	}
+
+	// Notifications from the restarted node reach the client
+	closeId, err := client.CloseLedgerChannel(ledger.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-client.ObjectiveCompleteChan(closeId):
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the restarted node to notify the client")
+	}
+
+	info, err := client.GetLedgerChannel(ledger.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != query.Complete {
+		t.Fatalf("expected ledger channel to be complete, got %s", info.Status)
+	}
+
+	// A node with another address cannot be swapped in
+	err = rpcServer.SwapNode(func(current *node.Node) (*node.Node, error) {
+		return &bob, nil
+	})
+	if err == nil {
+		t.Fatal("expected swapping in a node with a different address to fail")
+	}
+}
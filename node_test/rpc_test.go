@@ -1,20 +1,29 @@
 package node_test
 
 import (
+	"archive/zip"
+	"bytes"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log/slog"
 	"math/big"
+	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/go-cmp/cmp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/netaddr"
 	interRpc "github.com/statechannels/go-nitro/internal/rpc"
 	ta "github.com/statechannels/go-nitro/internal/testactors"
 	"github.com/statechannels/go-nitro/internal/testdata"
@@ -28,7 +37,9 @@ import (
 	"github.com/statechannels/go-nitro/protocols/directfund"
 	"github.com/statechannels/go-nitro/protocols/virtualfund"
 	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/serde"
 	"github.com/statechannels/go-nitro/rpc/transport"
+	grpctrans "github.com/statechannels/go-nitro/rpc/transport/grpc"
 	"github.com/statechannels/go-nitro/rpc/transport/http"
 	natstrans "github.com/statechannels/go-nitro/rpc/transport/nats"
 	"github.com/statechannels/go-nitro/types"
@@ -52,6 +63,10 @@ func TestRpcWithHttp(t *testing.T) {
 	}
 }
 
+func TestRpcWithGrpc(t *testing.T) {
+	executeNRpcTestWrapper(t, transport.Grpc, 3, false)
+}
+
 func TestRPCWithManualVoucherExchange(t *testing.T) {
 	executeNRpcTestWrapper(t, transport.Http, 4, true)
 	executeNRpcTestWrapper(t, transport.Nats, 4, true)
@@ -144,6 +159,17 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		}
 	}
 
+	slog.Info("Verify that each rpc client negotiates the latest api version")
+	for i := 0; i < n; i++ {
+		version, err := clients[i].Version()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if clients[i].ApiVersion() != serde.ApiV2 || version.ApiVersion != serde.ApiV2 {
+			t.Fatalf("expected api version %s, got %s serving %s", serde.ApiV2, version.ApiVersion, clients[i].ApiVersion())
+		}
+	}
+
 	waitForPeerInfoExchange(msgServices...)
 	slog.Info("Peer exchange complete")
 
@@ -204,6 +230,100 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 
 	t.Log("Ledger channels queried")
 
+	// assert the latest supported signed state of a ledger channel can be exported
+	{
+		signedState, err := clients[0].GetSignedState(ledgerChannels[0].ChannelId)
+		checkError(t, err, "client.GetSignedState")
+		checkSignedStateInfo(t, ledgerChannels[0].ChannelId, signedState)
+
+		stateHash, err := clients[0].ComputeStateHash(signedState.ID, signedState.VariablePart)
+		checkError(t, err, "client.ComputeStateHash")
+		expectedHash, err := state.StateFromFixedAndVariablePart(signedState.FixedPart, signedState.VariablePart).Hash()
+		checkError(t, err, "state.Hash")
+		if stateHash != expectedHash {
+			t.Errorf("expected state hash %s, got %s", expectedHash, stateHash)
+		}
+	}
+
+	// assert a debug bundle describes the node and its ledger channels
+	{
+		bundle, err := clients[0].GetDebugBundle()
+		checkError(t, err, "client.GetDebugBundle")
+		checkDebugBundle(t, bundle, ledgerChannels[0].ChannelId)
+	}
+
+	// assert streaming the ledger channels one per page yields the same channels as fetching them all at once
+	{
+		all, err := clients[1].GetAllLedgerChannels()
+		checkError(t, err, "client.GetAllLedgerChannels")
+		pages, err := clients[1].StreamAllLedgerChannels(1)
+		checkError(t, err, "client.StreamAllLedgerChannels")
+
+		streamed := []query.LedgerChannelInfo{}
+		for page := range pages {
+			if len(page) != 1 {
+				t.Errorf("expected a page of 1 ledger channel, got %d", len(page))
+			}
+			streamed = append(streamed, page...)
+		}
+		byId := func(a, b query.LedgerChannelInfo) int { return strings.Compare(a.ID.String(), b.ID.String()) }
+		slices.SortFunc(all, byId)
+		slices.SortFunc(streamed, byId)
+		if diff := cmp.Diff(all, streamed, cmp.AllowUnexported(big.Int{})); diff != "" {
+			t.Errorf("streamed ledger channels differ from all ledger channels: %v", diff)
+		}
+	}
+
+	// assert paging through the ledger channels one at a time yields every channel, and that filters select channels
+	{
+		all, err := clients[1].GetAllLedgerChannels()
+		checkError(t, err, "client.GetAllLedgerChannels")
+		paged := []query.LedgerChannelInfo{}
+		page := query.PageRequest{Limit: 1}
+		for {
+			p, err := clients[1].ListLedgerChannels(query.ChannelFilter{}, page)
+			checkError(t, err, "client.ListLedgerChannels")
+			paged = append(paged, p.Channels...)
+			if (p.NextCursor == types.Destination{}) {
+				break
+			}
+			page.Cursor = p.NextCursor
+		}
+		if len(paged) != len(all) {
+			t.Errorf("expected to page through %d ledger channels, got %d", len(all), len(paged))
+		}
+
+		withAlice, err := clients[1].ListLedgerChannels(query.ChannelFilter{Counterparty: actors[0].Address(), Status: query.Open}, query.PageRequest{})
+		checkError(t, err, "client.ListLedgerChannels")
+		if len(withAlice.Channels) != 1 || withAlice.Channels[0].ID != ledgerChannels[0].ChannelId {
+			t.Errorf("expected only the ledger channel with %s, got %+v", actors[0].Address(), withAlice.Channels)
+		}
+		otherAsset := types.Address{1}
+		withOtherAsset, err := clients[1].ListLedgerChannels(query.ChannelFilter{Asset: &otherAsset}, query.PageRequest{})
+		checkError(t, err, "client.ListLedgerChannels")
+		if len(withOtherAsset.Channels) != 0 {
+			t.Errorf("expected no ledger channels of asset %s, got %+v", otherAsset, withOtherAsset.Channels)
+		}
+		if _, err := clients[1].ListLedgerChannels(query.ChannelFilter{}, query.PageRequest{Limit: query.MaxPageLimit + 1}); err == nil {
+			t.Error("expected a page exceeding query.MaxPageLimit to be refused")
+		}
+	}
+
+	// assert the first intermediary quotes for routing to its right hand neighbour only when it has the capacity
+	if n > 2 {
+		quote, err := clients[0].GetQuote(actors[1].Address(), actors[2].Address(), types.Address{}, 100)
+		checkError(t, err, "client.GetQuote")
+		if !quote.CanRoute || quote.Intermediary != actors[1].Address() || quote.Fee.Sign() != 0 {
+			t.Errorf("expected a zero fee quote from %s, got %+v", actors[1].Address(), quote)
+		}
+
+		quote, err = clients[0].GetQuote(actors[1].Address(), actors[2].Address(), types.Address{}, 101)
+		checkError(t, err, "client.GetQuote")
+		if quote.CanRoute {
+			t.Errorf("expected quote for an amount exceeding capacity to be refused, got %+v", quote)
+		}
+	}
+
 	//////////////////////////////////////////////////////////////////
 	// create virtual channel, execute payment, close virtual channel
 	//////////////////////////////////////////////////////////////////
@@ -256,6 +376,13 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 			channelsByLedger, err := client.GetPaymentChannelsByLedger(ledgerChannels[i].ChannelId)
 			checkError(t, err, "client.GetPaymentChannelsByLedger")
 			checkQueryInfoCollection(t, expectedVirtualChannel, 1, channelsByLedger)
+
+			listed, err := client.ListPaymentChannelsByLedger(ledgerChannels[i].ChannelId, query.ChannelFilter{Counterparty: bob.Address()}, query.PageRequest{Limit: 1})
+			checkError(t, err, "client.ListPaymentChannelsByLedger")
+			checkQueryInfoCollection(t, expectedVirtualChannel, 1, listed.Channels)
+			if (listed.NextCursor != types.Destination{}) {
+				t.Errorf("expected the only payment channel to be on the last page, got cursor %s", listed.NextCursor)
+			}
 		}
 	}
 
@@ -285,9 +412,28 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 		if rxVoucher.Delta.Cmp(big.NewInt(0)) != 0 {
 			t.Errorf("adding the same voucher should result in a delta of 0, got %d", rxVoucher.Delta)
 		}
+
+		// assert bob can hand a third party a snapshot proving he has been paid
+		snapshot, err := bobClient.GetChannelSnapshot(vabCreateResponse.ChannelId)
+		checkError(t, err, "bobClient.GetChannelSnapshot")
+		issuer, err := snapshot.Verify()
+		checkError(t, err, "snapshot.Verify")
+		if issuer != bob.Address() || snapshot.Paid().Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("expected a snapshot issued by %s with 1 paid, got one issued by %s with %d paid", bob.Address(), issuer, snapshot.Paid())
+		}
 	} else {
-		_, err = aliceClient.Pay(vabCreateResponse.ChannelId, 1)
-		checkError(t, err, "aliceClient.Pay")
+		_, err = aliceClient.PayWithContext(vabCreateResponse.ChannelId, 1, "", "request-1")
+		checkError(t, err, "aliceClient.PayWithContext")
+
+		// assert bob's application is told of the payment, with the context to correlate it with
+		select {
+		case v := <-bobClient.ReceivedVouchersChan(vabCreateResponse.ChannelId):
+			if v.Context != "request-1" || v.Amount.Cmp(big.NewInt(1)) != 0 {
+				t.Errorf("expected a voucher for 1 with context request-1, got %+v", v)
+			}
+		case <-time.After(defaultTimeout):
+			t.Fatal("timed out waiting for bob to receive the voucher")
+		}
 	}
 
 	t.Log("Vouchers sent/received")
@@ -307,6 +453,27 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 
 	t.Log("Ledger/virtual channels closed")
 
+	// assert alice's activity export records the channels she opened and closed, and the payment she made
+	{
+		export := &strings.Builder{}
+		err := aliceClient.StreamActivityExport(export, query.JsonlExportFormat, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		checkError(t, err, "aliceClient.StreamActivityExport")
+		checkActivityExport(t, export.String(), map[string]query.ActivityExportRecord{
+			"channel_opened " + aliceLedger.ChannelId.String():       {},
+			"channel_opened " + vabCreateResponse.ChannelId.String(): {},
+			"payment_sent " + vabCreateResponse.ChannelId.String():   {Amount: "1"},
+			"channel_closed " + vabCreateResponse.ChannelId.String(): {},
+			"channel_closed " + aliceLedger.ChannelId.String():       {},
+		})
+	}
+	{
+		export, err := bobClient.ExportActivity(query.JsonlExportFormat, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+		checkError(t, err, "bobClient.ExportActivity")
+		checkActivityExport(t, export.Data, map[string]query.ActivityExportRecord{
+			"payment_received " + vabCreateResponse.ChannelId.String(): {Amount: "1"},
+		})
+	}
+
 	//////////////////////////
 	// perform wrap-up checks
 	//////////////////////////
@@ -387,6 +554,71 @@ func executeNRpcTest(t *testing.T, connectionType transport.TransportType, n int
 	checkNotifications(t, "bobVirtual", requiredVCNotifs, optionalVCNotifs, bobVirtualNotifs, defaultTimeout)
 }
 
+// checkActivityExport checks that the jsonl activity export contains a record for each expected "<kind> <channelId>" key,
+// with the expected amount and fee.
+func checkActivityExport(t *testing.T, data string, expected map[string]query.ActivityExportRecord) {
+	t.Helper()
+	found := map[string]query.ActivityExportRecord{}
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		r := query.ActivityExportRecord{}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("could not parse activity export line %q: %v", line, err)
+		}
+		found[r.Kind+" "+r.ChannelId] = r
+	}
+	for key, want := range expected {
+		got, ok := found[key]
+		if !ok {
+			t.Errorf("expected activity export to contain %s, got %s", key, data)
+			continue
+		}
+		if got.Amount != want.Amount {
+			t.Errorf("%s: expected amount %q, got %q", key, want.Amount, got.Amount)
+		}
+		if got.Fee != want.Fee {
+			t.Errorf("%s: expected fee %q, got %q", key, want.Fee, got.Fee)
+		}
+	}
+}
+
+// checkDebugBundle checks that the debug bundle is a zip archive containing each expected file,
+// and that its channel summaries include the given ledger channel.
+func checkDebugBundle(t *testing.T, bundle []byte, ledgerId types.Destination) {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	if err != nil {
+		t.Fatalf("could not read debug bundle: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		r, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+	}
+	for _, name := range []string{"info.json", "config.json", "channels.json", "objectives.json", "queues.json", "chain_events.json", "logs.txt"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("expected debug bundle to contain %s", name)
+		}
+	}
+	channels := node.DebugChannels{}
+	if err := json.Unmarshal([]byte(files["channels.json"]), &channels); err != nil {
+		t.Fatalf("could not parse channels.json: %v", err)
+	}
+	found := false
+	for _, l := range channels.LedgerChannels {
+		found = found || l.ID == ledgerId
+	}
+	if !found {
+		t.Errorf("expected channels.json to contain ledger channel %s, got %s", ledgerId, files["channels.json"])
+	}
+}
+
 // setupNitroNodeWithRPCClient is a helper function that spins up a Nitro Node RPC Server and returns an RPC client connected to it.
 func setupNitroNodeWithRPCClient(
 	t *testing.T,
@@ -417,28 +649,17 @@ func setupNitroNodeWithRPCClient(
 	})
 
 	node := node.New(
-		messageService,
-		chain,
-		ourStore,
-		&engine.PermissivePolicy{})
-
-	var useNats bool
-	switch connectionType {
-	case transport.Nats:
-		useNats = true
-	case transport.Http:
-		useNats = false
-	default:
-		err = fmt.Errorf("unknown connection type %v", connectionType)
-		panic(err)
-	}
+		node.WithMessageService(messageService),
+		node.WithChainService(chain),
+		node.WithStore(ourStore),
+		node.WithPolicy(&engine.PermissivePolicy{}))
 
 	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
 	if err != nil {
 		panic(err)
 	}
 
-	rpcServer, err := interRpc.InitializeRpcServer(&node, rpcPort, useNats, &cert)
+	rpcServer, err := interRpc.InitializeRpcServer(&node, rpcPort, netaddr.DualStack, connectionType, transport.CertificateConfig(&cert), nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -457,6 +678,16 @@ func setupNitroNodeWithRPCClient(
 		if err != nil {
 			panic(err)
 		}
+	case transport.Grpc:
+
+		creds, err := credentials.NewClientTLSFromFile("../tls/statechannels.org.pem", "")
+		if err != nil {
+			panic(err)
+		}
+		clientConnection, err = grpctrans.NewGrpcTransportAsClient(rpcServer.Url(), grpc.WithTransportCredentials(creds))
+		if err != nil {
+			panic(err)
+		}
 	default:
 		err = fmt.Errorf("unknown connection type %v", connectionType)
 		panic(err)
@@ -498,6 +729,32 @@ func checkQueryInfo[T channelInfo](t *testing.T, expected T, fetched T) {
 	}
 }
 
+// checkSignedStateInfo checks that the signed state info is for the expected channel and is signed by every participant.
+func checkSignedStateInfo(t *testing.T, expectedId types.Destination, info query.SignedStateInfo) {
+	if info.ID != expectedId {
+		t.Fatalf("expected signed state for channel %s, got %s", expectedId, info.ID)
+	}
+	if len(info.CheckpointCalldata) == 0 {
+		t.Fatalf("expected checkpoint calldata for channel %s", expectedId)
+	}
+	s := state.StateFromFixedAndVariablePart(info.FixedPart, info.VariablePart)
+	if s.ChannelId() != expectedId {
+		t.Fatalf("signed state does not hash to channel id %s", expectedId)
+	}
+	if len(info.Signatures) != len(info.FixedPart.Participants) {
+		t.Fatalf("expected %d signatures, got %d", len(info.FixedPart.Participants), len(info.Signatures))
+	}
+	for i, sig := range info.Signatures {
+		signer, err := s.RecoverSigner(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != info.FixedPart.Participants[i] {
+			t.Fatalf("expected signature from %s, got %s", info.FixedPart.Participants[i], signer)
+		}
+	}
+}
+
 func checkQueryInfoCollection[T channelInfo](t *testing.T, expected T, expectedLength int, fetched []T) {
 	if len(fetched) != expectedLength {
 		t.Fatalf("expected %d channel infos, got %d", expectedLength, len(fetched))
@@ -0,0 +1,118 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// waitForSubscription waits for the node's view of the subscription to reach the status, returning it.
+func waitForSubscription(t *testing.T, n node.Node, id protocols.ObjectiveId, status query.SubscriptionStatus) query.SubscriptionInfo {
+	t.Helper()
+	deadline := time.Now().Add(defaultTimeout)
+	var info query.SubscriptionInfo
+	var err error
+	for time.Now().Before(deadline) {
+		info, err = n.GetSubscription(id)
+		if err == nil && info.Status == status {
+			return info
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for subscription %s to be %s: got %+v, %v", id, status, info, err)
+	return query.SubscriptionInfo{}
+}
+
+func TestSubscription(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+	response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+	ctx := context.Background()
+
+	t.Run("payments are made until the cap is reached", func(t *testing.T) {
+		started, err := alice.CreateSubscription(ctx, response.ChannelId, big.NewInt(10), 100*time.Millisecond, big.NewInt(35))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if started.Status != query.SubscriptionActive {
+			t.Fatalf("expected an active subscription, got %s", started.Status)
+		}
+
+		ended := waitForSubscription(t, alice, started.ID, query.SubscriptionEnded)
+		if ended.Paid.ToInt().Cmp(big.NewInt(30)) != 0 {
+			t.Errorf("expected 30 to be paid, got %s", ended.Paid.ToInt())
+		}
+		if !ended.NextPayment.IsZero() {
+			t.Errorf("expected no further payments, got one due at %s", ended.NextPayment)
+		}
+
+		payee := waitForSubscription(t, bob, started.ID, query.SubscriptionEnded)
+		if payee.CancelledBy != ta.Alice.Address() || payee.Paid != nil {
+			t.Errorf("expected the payee to see the payer end the subscription, got %+v", payee)
+		}
+		waitForPaidSoFar(t, bob, response.ChannelId, big.NewInt(30))
+	})
+
+	t.Run("the payee can cancel", func(t *testing.T) {
+		started, err := alice.CreateSubscription(ctx, response.ChannelId, big.NewInt(5), time.Hour, big.NewInt(50))
+		if err != nil {
+			t.Fatal(err)
+		}
+		waitForSubscription(t, bob, started.ID, query.SubscriptionActive)
+
+		cancelled, err := bob.CancelSubscription(ctx, started.ID, "no longer offered")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cancelled.Status != query.SubscriptionCancelled {
+			t.Fatalf("expected the subscription to be cancelled, got %s", cancelled.Status)
+		}
+
+		payer := waitForSubscription(t, alice, started.ID, query.SubscriptionCancelled)
+		if payer.CancelledBy != ta.Bob.Address() || payer.Reason != "no longer offered" {
+			t.Errorf("expected the payer to see the payee's cancellation, got %+v", payer)
+		}
+		if payer.Paid.ToInt().Cmp(big.NewInt(5)) != 0 {
+			t.Errorf("expected only the first payment to be made, got %s", payer.Paid.ToInt())
+		}
+
+		if _, err := alice.CancelSubscription(ctx, started.ID, ""); !errors.Is(err, node.ErrSubscriptionNotActive) {
+			t.Errorf("expected a cancelled subscription not to be cancelled again, got %v", err)
+		}
+	})
+
+	t.Run("only the payer can subscribe", func(t *testing.T) {
+		_, err := bob.CreateSubscription(ctx, response.ChannelId, big.NewInt(5), time.Hour, big.NewInt(50))
+		if !errors.Is(err, node.ErrInvalidSubscription) {
+			t.Errorf("expected the payee's subscription to be refused, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package node_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	interRpc "github.com/statechannels/go-nitro/internal/rpc"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	p2pms "github.com/statechannels/go-nitro/node/engine/messageservice/p2p-message-service"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/rpc/transport"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
+)
+
+func loadTestCertificate(t *testing.T) *tls.Config {
+	cert, err := tls.LoadX509KeyPair("../tls/statechannels.org.pem", "../tls/statechannels.org_key.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return transport.CertificateConfig(&cert)
+}
+
+// TestRpcTls checks that the http transport serves plaintext or TLS as configured, and that clients choose between them
+// with the scheme of the url, trusting the roots in their TLS config.
+func TestRpcTls(t *testing.T) {
+	pem, err := os.ReadFile("../tls/statechannels.org.pem")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted := x509.NewCertPool()
+	trusted.AppendCertsFromPEM(pem)
+
+	testCases := []struct {
+		name      string
+		rpcPort   int
+		serverTls *tls.Config
+		scheme    string
+		clientTls *tls.Config
+		fails     bool
+	}{
+		{"plaintext", 4310, nil, "http://", nil, false},
+		{"trusted certificate", 4311, loadTestCertificate(t), "https://", &tls.Config{RootCAs: trusted}, false},
+		{"untrusted certificate", 4312, loadTestCertificate(t), "https://", &tls.Config{RootCAs: x509.NewCertPool()}, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := chainservice.NewMockChain()
+			defer chain.Close()
+			n := node.New(
+				node.WithMessageService(messageservice.NewTestMessageService(ta.Alice.Address(), messageservice.NewBroker(), 0)),
+				node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+				node.WithStore(store.NewMemStore(ta.Alice.PrivateKey)),
+				node.WithPolicy(&engine.PermissivePolicy{}))
+
+			rpcServer, err := interRpc.InitializeRpcServer(&n, tc.rpcPort, netaddr.DualStack, transport.Http, tc.serverTls, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rpcServer.Close()
+
+			clientConnection, err := http.NewHttpTransportAsClientWithTLSConfig(tc.scheme+rpcServer.Url(), time.Millisecond, tc.clientTls)
+			if tc.fails {
+				if err == nil {
+					clientConnection.Close()
+					t.Fatal("expected the connection to fail")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			rpcClient, err := rpc.NewRpcClient(clientConnection)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer rpcClient.Close()
+			address, err := rpcClient.Address()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if address != ta.Alice.Address() {
+				t.Errorf("expected address %s, got %s", ta.Alice.Address(), address)
+			}
+		})
+	}
+}
+
+// TestMessageServiceWss checks that nodes can fund a channel when one only reaches the other through its secure
+// websocket listener.
+func TestMessageServiceWss(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	defer chain.Close()
+
+	aliceMs := p2pms.NewMessageService(p2pms.MessageOpts{
+		PkBytes:   ta.Alice.PrivateKey,
+		Port:      3310,
+		WssPort:   3311,
+		TLSConfig: loadTestCertificate(t),
+		PublicIp:  "127.0.0.1",
+		SCAddr:    ta.Alice.Address(),
+	})
+	bobMs := p2pms.NewMessageService(p2pms.MessageOpts{
+		PkBytes:   ta.Bob.PrivateKey,
+		Port:      3312,
+		PublicIp:  "127.0.0.1",
+		SCAddr:    ta.Bob.Address(),
+		BootPeers: []string{fmt.Sprintf("/ip4/127.0.0.1/tcp/3311/tls/ws/p2p/%s", aliceMs.Id())},
+	})
+
+	alice := node.New(
+		node.WithMessageService(aliceMs),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Alice.Address())),
+		node.WithStore(store.NewMemStore(ta.Alice.PrivateKey)),
+		node.WithPolicy(&engine.PermissivePolicy{}))
+	defer closeNode(t, &alice)
+	bob := node.New(
+		node.WithMessageService(bobMs),
+		node.WithChainService(chainservice.NewMockChainService(chain, ta.Bob.Address())),
+		node.WithStore(store.NewMemStore(ta.Bob.PrivateKey)),
+		node.WithPolicy(&engine.PermissivePolicy{}))
+	defer closeNode(t, &bob)
+
+	waitForPeerInfoExchange(aliceMs, bobMs)
+	openLedgerChannel(t, bob, alice, common.Address{})
+}
@@ -0,0 +1,64 @@
+package node_test
+
+import (
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVirtualOnlyNodes checks that nodes without a chain service can pay each other through a hub which funds its
+// ledger channels with them externally, without any transaction reaching the chain.
+func TestVirtualOnlyNodes(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, nil, broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, nil, broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	hubPolicy := &engine.PermissivePolicy{ExternallyFundedPeers: []types.Address{ta.Alice.Address(), ta.Bob.Address()}}
+	irene, _ := setupNodeWithPolicy(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder, hubPolicy)
+	defer closeNode(t, &irene)
+
+	ledgerA := openLedgerChannel(t, alice, irene, types.Address{})
+	ledgerB := openLedgerChannel(t, irene, bob, types.Address{})
+
+	intermediaries := []types.Address{ta.Irene.Address()}
+	response, err := alice.CreatePaymentChannel(intermediaries, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+	alice.Pay(response.ChannelId, big.NewInt(1))
+	voucher := <-bob.ReceivedVouchers()
+	if voucher.Amount.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("expected a voucher for 1, got %v", voucher.Amount)
+	}
+
+	closeId, err := bob.ClosePaymentChannel(response.ChannelId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{closeId})
+	checkPaymentChannel(t, response.ChannelId, finalPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}, 1, 1), query.Complete, alice, bob)
+
+	closeLedgerChannel(t, alice, irene, ledgerA)
+	closeLedgerChannel(t, irene, bob, ledgerB)
+
+	if chain.BlockNum != 1 {
+		t.Fatalf("expected no transactions to be submitted, but the chain is at block %d", chain.BlockNum)
+	}
+}
@@ -0,0 +1,71 @@
+package node_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVoucherExpiry checks that the payee accepts vouchers before their expiry, and refuses them after it.
+func TestVoucherExpiry(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainservice.NewMockChainService(chain, ta.Alice.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	bob, _ := setupNode(ta.Bob.PrivateKey, chainservice.NewMockChainService(chain, ta.Bob.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &bob)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	// An expired voucher sent to the payee is ignored, and a later one makes up for it
+	if err := alice.PayWithOptions(context.Background(), payment.ChannelId, big.NewInt(5), node.PayOptions{Expiry: time.Now().Add(-time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := alice.PayWithOptions(context.Background(), payment.ChannelId, big.NewInt(2), node.PayOptions{Expiry: time.Now().Add(time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	waitForPaidSoFar(t, bob, payment.ChannelId, big.NewInt(7))
+
+	expired, err := alice.CreateVoucherWithExpiry(payment.ChannelId, big.NewInt(3), "", time.Now().Add(-time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bob.ReceiveVoucher(expired); !errors.Is(err, node.ErrVoucherExpired) {
+		t.Errorf("expected %v, got %v", node.ErrVoucherExpired, err)
+	}
+	valid, err := alice.CreateVoucherWithExpiry(payment.ChannelId, big.NewInt(3), "", time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	summary, err := bob.ReceiveVoucher(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total.Cmp(big.NewInt(13)) != 0 {
+		t.Errorf("expected 13 received in total, got %s", summary.Total)
+	}
+}
@@ -0,0 +1,104 @@
+package node_test
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestConcurrentVoucherReceipt checks that vouchers received concurrently for one channel, as by several payment proxies
+// sharing a node, are each credited exactly once, whether or not the node's voucher manager serializes receipts itself.
+func TestConcurrentVoucherReceipt(t *testing.T) {
+	const vouchers, proxies = 50, 8
+
+	for _, tc := range []struct {
+		name string
+		vm   func(s store.Store) []node.Option
+	}{
+		{"default voucher manager", func(store.Store) []node.Option { return nil }},
+		{"unsynchronized voucher manager", func(s store.Store) []node.Option {
+			return []node.Option{node.WithVoucherManager(payments.NewVoucherManager(ta.Bob.Address(), s))}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			chain := chainservice.NewMockChain()
+			broker := messageservice.NewBroker()
+
+			newNode := func(actor ta.Actor, opts func(store.Store) []node.Option) node.Node {
+				s := store.NewMemStore(actor.PrivateKey)
+				return node.New(append([]node.Option{
+					node.WithMessageService(messageservice.NewTestMessageService(actor.Address(), broker, 0)),
+					node.WithChainService(chainservice.NewMockChainService(chain, actor.Address())),
+					node.WithStore(s),
+					node.WithPolicy(&engine.PermissivePolicy{}),
+				}, opts(s)...)...)
+			}
+			noOpts := func(store.Store) []node.Option { return nil }
+			alice := newNode(ta.Alice, noOpts)
+			defer closeNode(t, &alice)
+			bob := newNode(ta.Bob, tc.vm)
+			defer closeNode(t, &bob)
+			irene := newNode(ta.Irene, noOpts)
+			defer closeNode(t, &irene)
+
+			openLedgerChannel(t, alice, irene, types.Address{})
+			openLedgerChannel(t, irene, bob, types.Address{})
+			response, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0, initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{response.Id})
+
+			created := []payments.Voucher{}
+			for i := 0; i < vouchers; i++ {
+				v, err := alice.CreateVoucher(response.ChannelId, big.NewInt(1))
+				if err != nil {
+					t.Fatal(err)
+				}
+				created = append(created, v)
+			}
+
+			// Every proxy receives every voucher, in the same order, so that they contend for each voucher
+			deltas := make(chan *big.Int, vouchers*proxies)
+			wg := sync.WaitGroup{}
+			for p := 0; p < proxies; p++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for _, v := range created {
+						summary, err := bob.ReceiveVoucher(v)
+						if err != nil {
+							t.Error(err)
+							return
+						}
+						if summary.Total.Cmp(v.Amount) < 0 {
+							t.Errorf("expected a total of at least %s after receiving the voucher, got %s", v.Amount, summary.Total)
+						}
+						deltas <- summary.Delta
+					}
+				}()
+			}
+			wg.Wait()
+			close(deltas)
+
+			sum := big.NewInt(0)
+			for d := range deltas {
+				sum.Add(sum, d)
+			}
+			if sum.Cmp(big.NewInt(vouchers)) != 0 {
+				t.Errorf("expected the deltas to sum to %d, got %s", vouchers, sum)
+			}
+			waitForPaidSoFar(t, bob, response.ChannelId, big.NewInt(vouchers))
+		})
+	}
+}
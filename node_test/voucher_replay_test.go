@@ -0,0 +1,72 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/node/engine/store"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestVoucherReplayProtection checks that nodes with replay protection pay with vouchers carrying increasing nonces,
+// and refuse a voucher received a second time.
+func TestVoucherReplayProtection(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	newNode := func(actor ta.Actor) node.Node {
+		return node.New(
+			node.WithMessageService(messageservice.NewTestMessageService(actor.Address(), broker, 0)),
+			node.WithChainService(chainservice.NewMockChainService(chain, actor.Address())),
+			node.WithStore(store.NewMemStore(actor.PrivateKey)),
+			node.WithPolicy(&engine.PermissivePolicy{}),
+			node.WithVoucherReplayProtection(),
+		)
+	}
+	alice := newNode(ta.Alice)
+	defer closeNode(t, &alice)
+	bob := newNode(ta.Bob)
+	defer closeNode(t, &bob)
+	irene := newNode(ta.Irene)
+	defer closeNode(t, &irene)
+
+	openLedgerChannel(t, alice, irene, types.Address{})
+	openLedgerChannel(t, irene, bob, types.Address{})
+
+	payment, err := alice.CreatePaymentChannel([]types.Address{ta.Irene.Address()}, ta.Bob.Address(), 0,
+		initialPaymentOutcome(ta.Alice.Address(), ta.Bob.Address(), types.Address{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, bob, []node.Node{irene}, []protocols.ObjectiveId{payment.Id})
+
+	alice.Pay(payment.ChannelId, big.NewInt(2))
+	if received := <-bob.ReceivedVouchers(); received.Nonce != 1 {
+		t.Fatalf("expected the voucher to have nonce 1, got %d", received.Nonce)
+	}
+
+	voucher, err := alice.CreateVoucher(payment.ChannelId, big.NewInt(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if voucher.Nonce != 2 {
+		t.Fatalf("expected the voucher to have nonce 2, got %d", voucher.Nonce)
+	}
+	summary, err := bob.ReceiveVoucher(voucher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Total.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("expected 5 received in total, got %s", summary.Total)
+	}
+	if _, err := bob.ReceiveVoucher(voucher); !errors.Is(err, node.ErrVoucherReplayed) {
+		t.Errorf("expected %v, got %v", node.ErrVoucherReplayed, err)
+	}
+}
@@ -0,0 +1,51 @@
+package node_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testhelpers"
+	"github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// TestWalletBalanceCheck checks that a ledger channel is only proposed if the funding wallet can afford our deposit.
+func TestWalletBalanceCheck(t *testing.T) {
+	chain := chainservice.NewMockChain()
+	broker := messageservice.NewBroker()
+
+	dataFolder, cleanup := testhelpers.GenerateTempStoreFolder()
+	defer cleanup()
+
+	chainServiceA := chainservice.NewMockChainService(chain, ta.Alice.Address())
+	alice, _ := setupNode(ta.Alice.PrivateKey, chainServiceA, broker, 0, dataFolder)
+	defer closeNode(t, &alice)
+	irene, _ := setupNode(ta.Irene.PrivateKey, chainservice.NewMockChainService(chain, ta.Irene.Address()), broker, 0, dataFolder)
+	defer closeNode(t, &irene)
+	if err := alice.EnableWalletBalanceCheck(); err != nil {
+		t.Fatal(err)
+	}
+
+	outcome := initialLedgerOutcome(ta.Alice.Address(), ta.Irene.Address(), types.Address{})
+	chainServiceA.SetWalletBalance(types.Address{}, big.NewInt(ledgerChannelDeposit-1))
+	_, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, outcome)
+	var balanceErr *node.WalletBalanceError
+	if !errors.As(err, &balanceErr) || !errors.Is(err, node.ErrWalletBalanceLow) {
+		t.Fatalf("expected a %T, got %v", balanceErr, err)
+	}
+	if balanceErr.Required.Cmp(big.NewInt(ledgerChannelDeposit)) != 0 || balanceErr.Available.Cmp(big.NewInt(ledgerChannelDeposit-1)) != 0 {
+		t.Fatalf("expected %d to be required and %d available, got %+v", ledgerChannelDeposit, ledgerChannelDeposit-1, balanceErr)
+	}
+
+	chainServiceA.SetWalletBalance(types.Address{}, big.NewInt(ledgerChannelDeposit))
+	response, err := alice.CreateLedgerChannel(ta.Irene.Address(), 0, outcome)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForObjectives(t, alice, irene, nil, []protocols.ObjectiveId{response.Id})
+}
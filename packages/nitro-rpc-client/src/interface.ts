@@ -1,5 +1,6 @@
 import {
   ChannelStatus,
+  ComputeChannelIdPayload,
   LedgerChannelInfo,
   ObjectiveResponse,
   PaymentChannelInfo,
@@ -149,6 +150,13 @@ export interface RpcClientApi
    * @returns The address of the wallet connected to the RPC server
    */
   GetAddress(): Promise<string>;
+  /**
+   * ComputeChannelId queries the RPC server for the id of the channel with the given fixed part, without creating it.
+   *
+   * @param fixedPart - The participants, nonce, app definition and challenge duration of the channel
+   * @returns The id of the channel
+   */
+  ComputeChannelId(fixedPart: ComputeChannelIdPayload): Promise<string>;
   /**
    * Close closes the RPC client and stops listening for notifications.
    */
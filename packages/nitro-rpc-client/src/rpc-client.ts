@@ -11,6 +11,7 @@ import {
   Voucher,
   ReceiveVoucherResult,
   ChannelStatus,
+  ComputeChannelIdPayload,
   LedgerChannelUpdatedNotification,
   PaymentChannelUpdatedNotification,
 } from "./types";
@@ -194,6 +195,12 @@ export class NitroRpcClient implements RpcClientApi {
     return this.myAddress;
   }
 
+  public async ComputeChannelId(
+    fixedPart: ComputeChannelIdPayload
+  ): Promise<string> {
+    return this.sendRequest("compute_channel_id", fixedPart);
+  }
+
   public async GetLedgerChannel(channelId: string): Promise<LedgerChannelInfo> {
     return this.sendRequest("get_ledger_channel", { Id: channelId });
   }
@@ -216,8 +223,8 @@ export class NitroRpcClient implements RpcClientApi {
     });
   }
 
-  private async getAuthToken(): Promise<string> {
-    return this.sendRequest("get_auth_token", {});
+  private async getAuthToken(apiKey?: string): Promise<string> {
+    return this.sendRequest("get_auth_token", apiKey ? { ApiKey: apiKey } : {});
   }
 
   private async sendRequest<K extends RequestMethod>(
@@ -241,14 +248,18 @@ export class NitroRpcClient implements RpcClientApi {
    * Creates an RPC client that uses HTTP/WS as the transport.
    *
    * @param url - The URL of the HTTP/WS server
+   * @param apiKey - The api key to present, if the server requires one
    * @returns A NitroRpcClient that uses WS as the transport
    */
   public static async CreateHttpNitroClient(
-    url: string
+    url: string,
+    apiKey?: string
   ): Promise<NitroRpcClient> {
     const transport = await HttpTransport.createTransport(url);
     const rpcClient = new NitroRpcClient(transport);
-    rpcClient.authToken = await rpcClient.getAuthToken();
+    rpcClient.authToken = await rpcClient.getAuthToken(apiKey);
+    // The server only accepts subscriptions presenting an auth token
+    await transport.subscribe(rpcClient.authToken);
     return rpcClient;
   }
 }
@@ -82,6 +82,7 @@ describe("create_ledger_channel", () => {
       result: {
         Id: "123",
         ChannelId: "456",
+        Nonce: 789,
       },
     };
 
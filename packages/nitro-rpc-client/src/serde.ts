@@ -36,6 +36,7 @@ const objectiveSchema = {
   properties: {
     Id: { type: "string" },
     ChannelId: { type: "string" },
+    Nonce: { type: "float64" },
   },
 } as const;
 type ObjectiveSchemaType = JTDDataType<typeof objectiveSchema>;
@@ -57,6 +58,17 @@ const ledgerChannelSchema = {
       },
     },
   },
+  optionalProperties: {
+    Fiat: {
+      properties: {
+        Currency: { type: "string" },
+        AsOf: { type: "timestamp" },
+        Indicative: { type: "boolean" },
+        MyBalance: { type: "float64" },
+        TheirBalance: { type: "float64" },
+      },
+    },
+  },
 } as const;
 type LedgerChannelSchemaType = JTDDataType<typeof ledgerChannelSchema>;
 
@@ -72,6 +84,20 @@ const paymentChannelSchema = {
         PaidSoFar: { type: "string" },
         RemainingFunds: { type: "string" },
       },
+      optionalProperties: {
+        Decimals: { type: "uint8" },
+      },
+    },
+  },
+  optionalProperties: {
+    Fiat: {
+      properties: {
+        Currency: { type: "string" },
+        AsOf: { type: "timestamp" },
+        Indicative: { type: "boolean" },
+        PaidSoFar: { type: "float64" },
+        RemainingFunds: { type: "float64" },
+      },
     },
   },
 } as const;
@@ -93,7 +119,7 @@ type PaymentChannelsSchemaType = JTDDataType<typeof paymentChannelsSchema>;
 
 const paymentSchema = {
   properties: {
-    Amount: { type: "uint32" },
+    Amount: { type: "string" },
     Channel: { type: "string" },
   },
 } as const;
@@ -167,6 +193,7 @@ export function getAndValidateResult<T extends RequestMethod>(
     case "close_ledger_channel":
     case "version":
     case "get_address":
+    case "compute_channel_id":
     case "close_payment_channel":
       return validateAndConvertResult(
         stringSchema,
@@ -202,7 +229,10 @@ export function getAndValidateResult<T extends RequestMethod>(
       return validateAndConvertResult(
         paymentSchema,
         result,
-        (result: PaymentSchemaType) => result
+        (result: PaymentSchemaType) => ({
+          ...result,
+          Amount: Number(result.Amount),
+        })
       );
     case "receive_voucher":
       return validateAndConvertResult(
@@ -12,26 +12,44 @@ import {
 } from "../types";
 import { getAndValidateNotification } from "../serde";
 
-import { Transport } from ".";
-
 export class HttpTransport {
   Notifications: EventEmitter<NotificationMethod, NotificationParams>;
 
-  public static async createTransport(server: string): Promise<Transport> {
+  public static async createTransport(server: string): Promise<HttpTransport> {
+    return new HttpTransport(server);
+  }
+
+  /**
+   * Opens the websocket connection for notifications, presenting the auth token, which the server requires.
+   * Browsers cannot set the headers of a websocket, so the token is sent as a query parameter.
+   *
+   * @param authToken - The auth token issued by the server
+   */
+  public async subscribe(authToken: string): Promise<void> {
+    const url = new URL(`wss://${this.server}/subscribe`);
+    url.searchParams.set("authtoken", authToken);
     // eslint-disable-next-line new-cap
-    const ws = new w3cwebsocket(`wss://${server}/subscribe`);
+    const ws = new w3cwebsocket(url.toString());
+
+    // Wait for onopen to fire so we know the connection is ready, and fail if the server refuses it
+    await new Promise<void>((resolve, reject) => {
+      ws.onopen = () => resolve();
+      ws.onerror = (e) => {
+        console.error("Error with websocket connection to server: " + e);
+        reject(e);
+      };
+    });
+
+    ws.onmessage = (event) => {
+      const data = JSON.parse(event.data.toString());
+      const validatedResult = getAndValidateNotification(
+        data.params.payload,
+        data.method
+      );
 
-    // throw any websocket errors so we don't fail silently
-    ws.onerror = (e) => {
-      console.error("Error with websocket connection to server: " + e);
-      throw e;
+      this.Notifications.emit(data.method, validatedResult);
     };
-
-    // Wait for onopen to fire so we know the connection is ready
-    await new Promise<void>((resolve) => (ws.onopen = () => resolve()));
-
-    const transport = new HttpTransport(ws, server);
-    return transport;
+    this.ws = ws;
   }
 
   public async sendRequest<K extends RequestMethod>(
@@ -45,27 +63,16 @@ export class HttpTransport {
   }
 
   public async Close(): Promise<void> {
-    this.ws.close(1000);
+    this.ws?.close(1000);
   }
 
-  private ws: w3cwebsocket;
+  private ws: w3cwebsocket | undefined;
 
   private server: string;
 
-  private constructor(ws: w3cwebsocket, server: string) {
-    this.ws = ws;
+  private constructor(server: string) {
     this.server = server;
-
     this.Notifications = new EventEmitter();
-    this.ws.onmessage = (event) => {
-      const data = JSON.parse(event.data.toString());
-      const validatedResult = getAndValidateNotification(
-        data.params.payload,
-        data.method
-      );
-
-      this.Notifications.emit(data.method, validatedResult);
-    };
   }
 }
 
@@ -11,8 +11,9 @@ import {
 
 import { Transport } from ".";
 
-const NITRO_REQUEST_TOPIC = "nitro-request";
-const NITRO_NOTIFICATION_TOPIC = "nitro-notify";
+const NITRO_REQUEST_TOPIC = "nitro-request/api/v1";
+// Each node publishes its notifications to the topic nitro.notify.<address>
+const NITRO_NOTIFICATION_TOPIC_PREFIX = "nitro.notify";
 
 export class NatsTransport {
   private natsConn: NatsConnection;
@@ -26,7 +27,21 @@ export class NatsTransport {
 
   public static async createTransport(server: string): Promise<Transport> {
     const natConn = await connect({ servers: server });
-    const natsSub = natConn.subscribe(NITRO_NOTIFICATION_TOPIC);
+    const addressResponse = await natConn.request(
+      NITRO_REQUEST_TOPIC,
+      JSONCodec().encode({
+        jsonrpc: "2.0",
+        id: Date.now(),
+        method: "get_address",
+        params: {},
+      })
+    );
+    const { result: address } = JSONCodec().decode(addressResponse.data) as {
+      result: string;
+    };
+    const natsSub = natConn.subscribe(
+      `${NITRO_NOTIFICATION_TOPIC_PREFIX}.${address}`
+    );
     const transport = new NatsTransport(natConn, natsSub);
 
     // Start listening for messages without blocking
@@ -37,6 +37,9 @@ export type DirectFundPayload = {
   Nonce: number;
   AppDefinition: string;
   AppData: string;
+  Force?: boolean;
+  Tags?: Record<string, string>;
+  Memo?: string;
 };
 export type VirtualFundPayload = {
   Intermediaries: string[];
@@ -45,11 +48,16 @@ export type VirtualFundPayload = {
   Outcome: Outcome;
   Nonce: number;
   AppDefinition: string;
+  Force?: boolean;
+  Tags?: Record<string, string>;
+  Memo?: string;
 };
 export type PaymentPayload = {
   // todo: this should be a bigint
   Amount: number;
   Channel: string;
+  PaymentId?: string;
+  Context?: string;
 };
 
 export type Voucher = {
@@ -58,6 +66,13 @@ export type Voucher = {
   Amount: number;
 
   Signature: string;
+  Context?: string;
+};
+export type ComputeChannelIdPayload = {
+  Participants: string[];
+  ChannelNonce: number;
+  AppDefinition: string;
+  ChallengeDuration: number;
 };
 type GetChannelRequest = {
   Id: string;
@@ -72,6 +87,7 @@ export type DefundObjectiveRequest = {
 export type ObjectiveResponse = {
   Id: string;
   ChannelId: string;
+  Nonce: number;
 };
 export type ReceiveVoucherResult = {
   Total: bigint;
@@ -83,7 +99,7 @@ export type ReceiveVoucherResult = {
  */
 export type GetAuthTokenRequest = JsonRpcRequest<
   "get_auth_token",
-  Record<string, never>
+  { ApiKey?: string }
 >;
 export type GetAddressRequest = JsonRpcRequest<
   "get_address",
@@ -115,6 +131,11 @@ export type GetPaymentChannelsByLedgerRequest = JsonRpcRequest<
   GetByLedgerRequest
 >;
 
+export type ComputeChannelIdRequest = JsonRpcRequest<
+  "compute_channel_id",
+  ComputeChannelIdPayload
+>;
+
 export type VersionRequest = JsonRpcRequest<"version", Record<string, never>>;
 export type DirectDefundRequest = JsonRpcRequest<
   "close_ledger_channel",
@@ -142,6 +163,7 @@ export type GetLedgerChannelResponse = JsonRpcResponse<LedgerChannelInfo>;
 export type VirtualFundResponse = JsonRpcResponse<ObjectiveResponse>;
 export type VersionResponse = JsonRpcResponse<string>;
 export type GetAddressResponse = JsonRpcResponse<string>;
+export type ComputeChannelIdResponse = JsonRpcResponse<string>;
 export type DirectFundResponse = JsonRpcResponse<ObjectiveResponse>;
 export type DirectDefundResponse = JsonRpcResponse<string>;
 export type VirtualDefundResponse = JsonRpcResponse<string>;
@@ -162,6 +184,7 @@ export type RPCRequestAndResponses = {
   version: [VersionRequest, VersionResponse];
   create_payment_channel: [VirtualFundRequest, VirtualFundResponse];
   get_address: [GetAddressRequest, GetAddressResponse];
+  compute_channel_id: [ComputeChannelIdRequest, ComputeChannelIdResponse];
   get_ledger_channel: [GetLedgerChannelRequest, GetLedgerChannelResponse];
   get_payment_channel: [GetPaymentChannelRequest, GetPaymentChannelResponse];
   pay: [PaymentRequest, PaymentResponse];
@@ -216,6 +239,29 @@ export type LedgerChannelInfo = {
   ID: string;
   Status: ChannelStatus;
   Balance: LedgerChannelBalance;
+  Fiat?: LedgerChannelFiatBalance;
+  Tags?: Record<string, string>;
+  Memo?: string;
+};
+
+/**
+ * An approximate valuation of a balance in a fiat currency, included when the node has a price feed.
+ * It is indicative only.
+ */
+export type FiatValuation = {
+  Currency: string;
+  AsOf: string;
+  Indicative: boolean;
+};
+
+export type LedgerChannelFiatBalance = FiatValuation & {
+  MyBalance: number;
+  TheirBalance: number;
+};
+
+export type PaymentChannelFiatBalance = FiatValuation & {
+  PaidSoFar: number;
+  RemainingFunds: number;
 };
 
 export type LedgerChannelBalance = {
@@ -228,6 +274,7 @@ export type LedgerChannelBalance = {
 
 export type PaymentChannelBalance = {
   AssetAddress: string;
+  Decimals?: number;
   Payee: string;
   Payer: string;
   PaidSoFar: bigint;
@@ -238,6 +285,9 @@ export type PaymentChannelInfo = {
   ID: string;
   Status: ChannelStatus;
   Balance: PaymentChannelBalance;
+  Fiat?: PaymentChannelFiatBalance;
+  Tags?: Record<string, string>;
+  Memo?: string;
 };
 
 export type Outcome = SingleAssetOutcome[];
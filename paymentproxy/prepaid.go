@@ -0,0 +1,108 @@
+package paymentproxy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// PREPAID_BALANCE_HEADER reports the prepaid balance left after a request was charged, in prepaid mode.
+	PREPAID_BALANCE_HEADER = "Nitro-Prepaid-Balance"
+	// INVOICE_HEADER asks the client to top up its prepaid balance with a voucher paying the given amount more. It is only
+	// set once the balance is low.
+	INVOICE_HEADER = "Nitro-Invoice"
+	// PREPAID_TOKEN_HEADER carries the token of a channel's prepaid account, issued in response to each top up.
+	PREPAID_TOKEN_HEADER = "Nitro-Prepaid-Token"
+	// PREPAID_TOKEN_PARAM carries the token of the prepaid account a request without a voucher is charged to.
+	PREPAID_TOKEN_PARAM = "prepaid"
+)
+
+// prepaidAccounts holds the prepaid balance of each payment channel paying the proxy. Clients top up their balance with
+// occasional large vouchers, and each request is debited from it, so that most requests carry no voucher to verify.
+//
+// A request without a voucher is only charged to an account if it carries the account's token, which is issued to the
+// client paying the top ups, so that knowing a channel's id is not enough to spend its balance.
+//
+// Balances are held in memory. Funds paid through a channel before its account was opened, such as before the proxy
+// restarted, are treated as spent.
+type prepaidAccounts struct {
+	lowBalance *big.Int // Requests leaving less than this are invoiced for a top up
+	topUp      *big.Int // The amount invoiced
+	key        []byte   // Signs the tokens of the accounts
+
+	mu       sync.Mutex
+	balances map[types.Destination]*big.Int
+}
+
+func newPrepaidAccounts(lowBalance, topUp *big.Int) *prepaidAccounts {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+	return &prepaidAccounts{lowBalance: lowBalance, topUp: topUp, key: key, balances: make(map[types.Destination]*big.Int)}
+}
+
+func (pa *prepaidAccounts) mac(channelId types.Destination) []byte {
+	m := hmac.New(sha256.New, pa.key)
+	m.Write(channelId[:])
+	return m.Sum(nil)
+}
+
+// token returns the token of the channel's account.
+func (pa *prepaidAccounts) token(channelId types.Destination) string {
+	return base64.RawURLEncoding.EncodeToString(append(channelId.Bytes(), pa.mac(channelId)...))
+}
+
+// channel returns the channel whose account the token was issued for.
+func (pa *prepaidAccounts) channel(token string) (types.Destination, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != 32+sha256.Size {
+		return types.Destination{}, fmt.Errorf("malformed prepaid token")
+	}
+	channelId := types.Destination(raw[:32])
+	if !hmac.Equal(raw[32:], pa.mac(channelId)) {
+		return types.Destination{}, fmt.Errorf("invalid prepaid token")
+	}
+	return channelId, nil
+}
+
+// credit adds the payment made by a voucher to the channel's balance, opening its account if need be.
+func (pa *prepaidAccounts) credit(channelId types.Destination, s payments.ReceiveVoucherSummary) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	balance, ok := pa.balances[channelId]
+	if !ok {
+		balance = big.NewInt(0)
+		pa.balances[channelId] = balance
+	}
+	balance.Add(balance, s.Delta)
+}
+
+// debit charges cost to the channel's balance, returning what is left. The balance is left untouched if it cannot
+// cover the cost.
+func (pa *prepaidAccounts) debit(channelId types.Destination, cost *big.Int) (*big.Int, error) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	balance, ok := pa.balances[channelId]
+	if !ok {
+		return nil, fmt.Errorf("channel %s has no prepaid balance, a voucher is required", channelId)
+	}
+	if balance.Cmp(cost) < 0 {
+		return nil, fmt.Errorf("payment of %d attoFIL required, the prepaid balance is only %d attoFIL", cost, balance)
+	}
+	balance.Sub(balance, cost)
+	return new(big.Int).Set(balance), nil
+}
+
+// isLow returns true if a client with the remaining balance should be invoiced for a top up.
+func (pa *prepaidAccounts) isLow(remaining *big.Int) bool {
+	return remaining.Cmp(pa.lowBalance) < 0
+}
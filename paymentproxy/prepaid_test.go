@@ -0,0 +1,134 @@
+package paymentproxy
+
+import (
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// receivingClient is a nitro client which accepts every voucher, paying the increase over the last voucher received.
+type receivingClient struct {
+	rpc.RpcClientApi
+	calls int
+	paid  map[types.Destination]*big.Int
+}
+
+func (c *receivingClient) ReceiveVoucher(v payments.Voucher) (payments.ReceiveVoucherSummary, error) {
+	c.calls++
+	paid, ok := c.paid[v.ChannelId]
+	if !ok {
+		paid = big.NewInt(0)
+	}
+	c.paid[v.ChannelId] = v.Amount
+	return payments.ReceiveVoucherSummary{Total: v.Amount, Delta: new(big.Int).Sub(v.Amount, paid)}, nil
+}
+
+func TestChargePrepaid(t *testing.T) {
+	client := &receivingClient{paid: map[types.Destination]*big.Int{}}
+	p := &PaymentProxy{nitroClient: client}
+	p.EnablePrepaidAccounts(big.NewInt(50), big.NewInt(100))
+	channelId := types.Destination{1}
+
+	charge := func(voucherAmount int64, cost int64) (http.Header, error) {
+		t.Helper()
+		v := payments.Voucher{ChannelId: channelId}
+		if voucherAmount > 0 {
+			v.Amount = big.NewInt(voucherAmount)
+		}
+		r := &http.Response{Header: http.Header{}}
+		err := p.chargePrepaid(r, v, big.NewInt(cost))
+		return r.Header, err
+	}
+
+	// A channel without a prepaid balance must pay with a voucher
+	if _, err := charge(0, 10); !errors.Is(err, ErrPayment) {
+		t.Fatalf("expected a payment error, got %v", err)
+	}
+
+	// A top up is credited, and requests are debited without a voucher
+	header, err := charge(100, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(PREPAID_BALANCE_HEADER); got != "90" {
+		t.Errorf("expected a balance of 90, got %s", got)
+	}
+	if header.Get(INVOICE_HEADER) != "" {
+		t.Error("expected no invoice while the balance is high")
+	}
+	for i := 0; i < 4; i++ {
+		if header, err = charge(0, 10); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if client.calls != 1 {
+		t.Errorf("expected only the top up to be received by the node, got %d vouchers", client.calls)
+	}
+
+	// Once the balance is low, a top up is invoiced
+	if got := header.Get(PREPAID_BALANCE_HEADER); got != "50" {
+		t.Errorf("expected a balance of 50, got %s", got)
+	}
+	if header, err = charge(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(INVOICE_HEADER); got != "100" {
+		t.Errorf("expected a top up of 100 to be invoiced, got %q", got)
+	}
+
+	// A request costing more than the balance is refused, leaving the balance untouched
+	if _, err := charge(0, 41); !errors.Is(err, ErrPayment) {
+		t.Fatalf("expected a payment error, got %v", err)
+	}
+	if header, err = charge(200, 41); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get(PREPAID_BALANCE_HEADER); got != "99" {
+		t.Errorf("expected a balance of 99, got %s", got)
+	}
+}
+
+func TestPrepaidAccountsRequireToken(t *testing.T) {
+	client := &receivingClient{paid: map[types.Destination]*big.Int{}}
+	p := &PaymentProxy{nitroClient: client, sources: AllVoucherSources}
+	p.EnablePrepaidAccounts(big.NewInt(50), big.NewInt(100))
+	alice, mallory := types.Destination{1}, types.Destination{2}
+
+	// Alice tops up her account, and is issued its token
+	r := &http.Response{Header: http.Header{}}
+	if err := p.chargePrepaid(r, payments.Voucher{ChannelId: alice, Amount: big.NewInt(100)}, big.NewInt(10)); err != nil {
+		t.Fatal(err)
+	}
+	token := r.Header.Get(PREPAID_TOKEN_HEADER)
+	if token == "" {
+		t.Fatal("expected a top up to issue the account's token")
+	}
+
+	// Knowing the id of Alice's channel is not enough to spend her balance
+	if _, err := p.requestVoucher(httptest.NewRequest(http.MethodGet, "/file?channelId="+alice.String(), nil)); err == nil {
+		t.Fatal("expected a request without a voucher or token to be refused")
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := base64.RawURLEncoding.EncodeToString(append(mallory.Bytes(), raw[32:]...))
+	if _, err := p.requestVoucher(httptest.NewRequest(http.MethodGet, "/file?prepaid="+forged, nil)); err == nil {
+		t.Fatal("expected a token issued for another channel to be refused")
+	}
+
+	v, err := p.requestVoucher(httptest.NewRequest(http.MethodGet, "/file?prepaid="+token, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.ChannelId != alice || v.Amount != nil {
+		t.Errorf("expected a charge to Alice's account, got %+v", v)
+	}
+}
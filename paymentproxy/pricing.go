@@ -0,0 +1,147 @@
+package paymentproxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// PriceOracle computes the amount the proxy charges for a request.
+type PriceOracle interface {
+	// Price returns the amount to charge for serving r, with a response body of responseLength bytes.
+	// r has had its voucher removed.
+	Price(ctx context.Context, r *http.Request, responseLength uint64) (*big.Int, error)
+}
+
+// PerBytePrice charges a static amount per byte of the response body. It is the proxy's default pricing.
+type PerBytePrice uint64
+
+// Price returns the cost of responseLength bytes.
+func (p PerBytePrice) Price(_ context.Context, _ *http.Request, responseLength uint64) (*big.Int, error) {
+	return new(big.Int).Mul(new(big.Int).SetUint64(uint64(p)), new(big.Int).SetUint64(responseLength)), nil
+}
+
+// PriceRequest is the body of the request which an HTTPPriceOracle makes to price a proxied request.
+type PriceRequest struct {
+	Method         string
+	Path           string
+	Query          string
+	ResponseLength uint64
+}
+
+// PriceResponse is the body of an HTTP price oracle's response.
+type PriceResponse struct {
+	Price *big.Int
+}
+
+// HTTPPriceOracle prices requests by POSTing a PriceRequest to an external oracle, which replies with a PriceResponse.
+type HTTPPriceOracle struct {
+	Url    string
+	Client *http.Client // The client used to reach the oracle. If nil, a client with a 5 second timeout is used.
+}
+
+var defaultOracleClient = &http.Client{Timeout: 5 * time.Second}
+
+// Price asks the oracle for the price of r.
+func (o *HTTPPriceOracle) Price(ctx context.Context, r *http.Request, responseLength uint64) (*big.Int, error) {
+	body, err := json.Marshal(PriceRequest{r.Method, r.URL.Path, r.URL.RawQuery, responseLength})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.Url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := o.Client
+	if client == nil {
+		client = defaultOracleClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not reach price oracle: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price oracle responded with status %d", resp.StatusCode)
+	}
+
+	var pr PriceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&pr); err != nil {
+		return nil, fmt.Errorf("could not decode price oracle response: %w", err)
+	}
+	if pr.Price == nil || pr.Price.Sign() < 0 {
+		return nil, fmt.Errorf("price oracle responded with invalid price %v", pr.Price)
+	}
+	return pr.Price, nil
+}
+
+// maxCachedPrices bounds the number of prices a CachedPriceOracle remembers.
+const maxCachedPrices = 10_000
+
+type priceKey struct {
+	method, path, query string
+	responseLength      uint64
+}
+
+type cachedPrice struct {
+	price   *big.Int
+	expires time.Time
+}
+
+// CachedPriceOracle remembers the prices computed by another oracle for a while, and falls back to a static price
+// when that oracle fails.
+type CachedPriceOracle struct {
+	oracle   PriceOracle
+	ttl      time.Duration
+	fallback PriceOracle
+
+	mu    sync.Mutex
+	cache map[priceKey]cachedPrice
+}
+
+// NewCachedPriceOracle returns a CachedPriceOracle which remembers prices computed by oracle for ttl, and uses fallback
+// to price requests when oracle fails.
+func NewCachedPriceOracle(oracle PriceOracle, ttl time.Duration, fallback PriceOracle) *CachedPriceOracle {
+	return &CachedPriceOracle{oracle: oracle, ttl: ttl, fallback: fallback, cache: make(map[priceKey]cachedPrice)}
+}
+
+// Price returns the cached price of identical requests, if it has not expired, or else asks the oracle.
+func (c *CachedPriceOracle) Price(ctx context.Context, r *http.Request, responseLength uint64) (*big.Int, error) {
+	key := priceKey{r.Method, r.URL.Path, r.URL.RawQuery, responseLength}
+	now := time.Now()
+
+	c.mu.Lock()
+	cached, ok := c.cache[key]
+	c.mu.Unlock()
+	if ok && now.Before(cached.expires) {
+		return new(big.Int).Set(cached.price), nil
+	}
+
+	price, err := c.oracle.Price(ctx, r, responseLength)
+	if err != nil {
+		slog.Warn("Price oracle failed, using fallback price", "error", err)
+		return c.fallback.Price(ctx, r, responseLength)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.cache) >= maxCachedPrices {
+		for k, v := range c.cache {
+			if !now.Before(v.expires) {
+				delete(c.cache, k)
+			}
+		}
+	}
+	if len(c.cache) < maxCachedPrices {
+		c.cache[key] = cachedPrice{new(big.Int).Set(price), now.Add(c.ttl)}
+	}
+	return price, nil
+}
@@ -0,0 +1,66 @@
+package paymentproxy
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCachedHTTPPriceOracle(t *testing.T) {
+	var calls atomic.Int32
+	var failing atomic.Bool
+	oracleServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var pr PriceRequest
+		if err := json.NewDecoder(r.Body).Decode(&pr); err != nil {
+			t.Error(err)
+		}
+		// Charge more for queries
+		price := big.NewInt(int64(pr.ResponseLength))
+		if pr.Query != "" {
+			price.Mul(price, big.NewInt(10))
+		}
+		if err := json.NewEncoder(w).Encode(PriceResponse{price}); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer oracleServer.Close()
+
+	oracle := NewCachedPriceOracle(&HTTPPriceOracle{Url: oracleServer.URL}, time.Hour, PerBytePrice(1000))
+	price := func(target string, length uint64) int64 {
+		r := httptest.NewRequest(http.MethodGet, target, nil)
+		p, err := oracle.Price(context.Background(), r, length)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p.Int64()
+	}
+
+	if got := price("/resource", 5); got != 5 {
+		t.Fatalf("expected a price of 5, got %d", got)
+	}
+	if got := price("/resource?q=complex", 5); got != 50 {
+		t.Fatalf("expected a price of 50, got %d", got)
+	}
+	if got := price("/resource", 5); got != 5 || calls.Load() != 2 {
+		t.Fatalf("expected the cached price of 5 without calling the oracle, got %d after %d calls", got, calls.Load())
+	}
+
+	// When the oracle fails, requests it has not priced use the fallback price
+	failing.Store(true)
+	if got := price("/other", 5); got != 5000 {
+		t.Fatalf("expected the fallback price of 5000, got %d", got)
+	}
+	if got := price("/resource", 5); got != 5 {
+		t.Fatalf("expected the cached price of 5, got %d", got)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -25,6 +27,8 @@ const (
 	AMOUNT_VOUCHER_PARAM     = "amount"
 	CHANNEL_ID_VOUCHER_PARAM = "channelId"
 	SIGNATURE_VOUCHER_PARAM  = "signature"
+	EXPIRY_VOUCHER_PARAM     = "expiry" // Optional: the unix time in seconds after which the voucher is refused
+	NONCE_VOUCHER_PARAM      = "nonce"  // Optional: the nonce which protects the voucher from being replayed
 
 	VOUCHER_CONTEXT_ARG contextKey = "voucher"
 
@@ -40,18 +44,23 @@ func createPaymentError(err error) error {
 type PaymentProxy struct {
 	server       *http.Server
 	nitroClient  rpc.RpcClientApi
-	costPerByte  uint64
+	pricing      PriceOracle
+	prepaid      *prepaidAccounts // nil unless prepaid mode is enabled
+	sessions     *sessions        // nil unless session tokens are enabled
+	streams      *streams         // nil unless streaming is enabled
+	sources      VoucherSources
+	stop         chan struct{}
 	reverseProxy *httputil.ReverseProxy
 
 	destinationUrl            *url.URL
 	certFilePath, certKeyPath string
 }
 
-// NewPaymentProxy creates a new PaymentProxy.
-func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL string, costPerByte uint64, certFilePath, certKeyPath string) *PaymentProxy {
-	server := &http.Server{Addr: proxyAddress}
-
-	nitroClient, err := rpc.NewHttpRpcClient(nitroEndpoint)
+// NewPaymentProxy creates a new PaymentProxy, which charges costPerByte for each byte of a response until another
+// PriceOracle is set with SetPriceOracle. The opts configure its client of the nitro node, such as with the api key
+// it presents.
+func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL string, costPerByte uint64, certFilePath, certKeyPath string, opts ...rpc.ClientOption) *PaymentProxy {
+	nitroClient, err := rpc.NewHttpRpcClient(nitroEndpoint, opts...)
 	if err != nil {
 		panic(err)
 	}
@@ -59,13 +68,21 @@ func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL s
 	if err != nil {
 		panic(err)
 	}
+	return newPaymentProxy(proxyAddress, nitroClient, destinationUrl, costPerByte, certFilePath, certKeyPath)
+}
+
+// newPaymentProxy creates a new PaymentProxy which redeems vouchers with the nitroClient.
+func newPaymentProxy(proxyAddress string, nitroClient rpc.RpcClientApi, destinationUrl *url.URL, costPerByte uint64, certFilePath, certKeyPath string) *PaymentProxy {
+	server := &http.Server{Addr: proxyAddress}
 
 	p := &PaymentProxy{
 		server:         server,
 		nitroClient:    nitroClient,
-		costPerByte:    costPerByte,
+		pricing:        PerBytePrice(costPerByte),
+		sources:        AllVoucherSources,
 		destinationUrl: destinationUrl,
 		reverseProxy:   &httputil.ReverseProxy{},
+		stop:           make(chan struct{}),
 		certFilePath:   certFilePath,
 		certKeyPath:    certKeyPath,
 	}
@@ -79,9 +96,48 @@ func NewPaymentProxy(proxyAddress string, nitroEndpoint string, destinationURL s
 	return p
 }
 
+// SetPriceOracle sets the oracle which computes the amount charged for each request. It must be called before Start.
+func (p *PaymentProxy) SetPriceOracle(oracle PriceOracle) {
+	p.pricing = oracle
+}
+
+// SetVoucherSources sets where in a request the proxy accepts a voucher from. Both the query params and the
+// X-Nitro-Voucher header are accepted until it is called. It must be called before Start.
+func (p *PaymentProxy) SetVoucherSources(sources VoucherSources) {
+	p.sources = sources
+}
+
+// EnablePrepaidAccounts switches the proxy to prepaid mode. Rather than paying for each request with a voucher, clients
+// top up a prepaid balance for their payment channel with occasional larger vouchers, and each request is debited from
+// it. A request may carry a voucher, to top up the balance before it is charged, or the token of the account, which
+// is issued in the PREPAID_TOKEN_HEADER of the response to each top up and presented in the PREPAID_TOKEN_PARAM.
+// Each response reports the balance left, and invoices a top up of topUp once it is less than lowBalance.
+// It must be called before Start.
+func (p *PaymentProxy) EnablePrepaidAccounts(lowBalance, topUp *big.Int) {
+	p.prepaid = newPrepaidAccounts(lowBalance, topUp)
+}
+
+// EnableSessions makes the proxy issue a session token in response to each request paid with a voucher. The token may
+// be presented in place of a voucher, for up to cfg.MaxRequests requests within cfg.Lifetime: the cost of those
+// requests is owed, and must be paid by the next voucher on the channel. Sessions are not used in prepaid mode.
+// It must be called before Start.
+func (p *PaymentProxy) EnableSessions(cfg SessionConfig) {
+	p.sessions = newSessions(cfg)
+}
+
+// EnableStreaming lets clients pay for a response as it is streamed, rather than up front, by adding the stream query
+// param to a request paid with a voucher. The response is written cfg.ChunkSize bytes at a time, each chunk only once
+// the vouchers received for the stream cover the price of the response up to its end. Further vouchers are sent to
+// STREAM_PAYMENT_PATH with the id of the stream, reported in the STREAM_ID_HEADER of the response; while the stream
+// waits for one, it is paused. Ranges and chunked responses are charged for the bytes actually sent.
+// Streams are not used in prepaid mode. It must be called before Start.
+func (p *PaymentProxy) EnableStreaming(cfg StreamConfig) {
+	p.streams = newStreams(cfg)
+}
+
 // ServeHTTP is the main entry point for the payment proxy server.
-// It is responsible for parsing the voucher from the query params and moving it to the request header
-// It then delegates to the reverse proxy to handle rewriting the request and sending it to the destination
+// It is responsible for parsing the voucher from the query params or the X-Nitro-Voucher header and moving it to the
+// request context. It then delegates to the reverse proxy to handle rewriting the request and sending it to the destination
 func (p *PaymentProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// If the request is a health check, return a 200 OK
 	if r.URL.Path == "/health" {
@@ -100,7 +156,30 @@ func (p *PaymentProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	v, err := parseVoucher(r.URL.Query())
+	if p.streams != nil && p.prepaid == nil {
+		if r.URL.Path == STREAM_PAYMENT_PATH {
+			p.handleStreamPayment(w, r)
+			return
+		}
+		if r.URL.Query().Has(STREAM_PARAM) {
+			p.serveStream(w, r)
+			return
+		}
+	}
+
+	if token := r.URL.Query().Get(SESSION_TOKEN_PARAM); token != "" && p.sessions != nil && p.prepaid == nil {
+		channelId, err := p.sessions.use(token, time.Now())
+		if err != nil {
+			p.handleError(w, r, createPaymentError(err))
+			return
+		}
+		removeVoucher(r)
+		r = r.WithContext(context.WithValue(r.Context(), SESSION_CONTEXT_ARG, channelId))
+		p.reverseProxy.ServeHTTP(w, r)
+		return
+	}
+
+	v, err := p.requestVoucher(r)
 	if err != nil {
 		p.handleError(w, r, createPaymentError(fmt.Errorf("could not parse voucher: %w", err)))
 		return
@@ -114,6 +193,33 @@ func (p *PaymentProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	p.reverseProxy.ServeHTTP(w, r)
 }
 
+// requestVoucher returns the voucher the request pays with, from wherever the proxy accepts it. In prepaid mode, a
+// request without a voucher is charged to the prepaid balance of the account whose token it carries, and the voucher
+// returned has no amount.
+func (p *PaymentProxy) requestVoucher(r *http.Request) (payments.Voucher, error) {
+	if header := r.Header.Get(VOUCHER_HEADER); header != "" {
+		if p.sources&VoucherHeader == 0 {
+			return payments.Voucher{}, fmt.Errorf("vouchers are not accepted in the %s header", VOUCHER_HEADER)
+		}
+		return parseVoucherHeader(header)
+	}
+
+	params := r.URL.Query()
+	if p.prepaid != nil && params.Get(SIGNATURE_VOUCHER_PARAM) == "" {
+		// The request is charged to a prepaid balance, without a voucher, if it shows that it may spend it
+		token := params.Get(PREPAID_TOKEN_PARAM)
+		if token == "" {
+			return payments.Voucher{}, fmt.Errorf("missing voucher or prepaid token")
+		}
+		channelId, err := p.prepaid.channel(token)
+		return payments.Voucher{ChannelId: channelId}, err
+	}
+	if p.sources&VoucherQueryParams == 0 {
+		return payments.Voucher{}, fmt.Errorf("vouchers are not accepted in query params")
+	}
+	return parseVoucher(params)
+}
+
 // handleDestinationResponse modifies the response before it is sent back to the client
 // It is responsible for parsing the voucher from the request header and redeeming it with the Nitro client
 // It will check the voucher amount against the cost (response size * cost per byte)
@@ -124,6 +230,9 @@ func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 	if r.Request.Method == "OPTIONS" {
 		return nil
 	}
+	if st, ok := r.Request.Context().Value(STREAM_CONTEXT_ARG).(*stream); ok {
+		return p.openStream(r, st)
+	}
 
 	contentLength := uint64(0)
 	// If the Content-Length header is set, use that
@@ -138,13 +247,26 @@ func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 		}
 	}
 
+	// The price is computed before the voucher is redeemed, so that a voucher is not spent on a request we fail to price
+	cost, err := p.pricing.Price(r.Request.Context(), r.Request, contentLength)
+	if err != nil {
+		return fmt.Errorf("could not price request: %w", err)
+	}
+
+	slog.Debug("Request cost", "response-length", contentLength, "cost", cost)
+
+	if channelId, ok := r.Request.Context().Value(SESSION_CONTEXT_ARG).(types.Destination); ok {
+		r.Header.Set(OWED_HEADER, p.sessions.charge(channelId, cost).String())
+		return nil
+	}
+
 	v, ok := r.Request.Context().Value(VOUCHER_CONTEXT_ARG).(payments.Voucher)
 	if !ok {
 		return createPaymentError(fmt.Errorf("could not fetch voucher from context"))
 	}
-	cost := p.costPerByte * contentLength
-
-	slog.Debug("Request cost", "cost-per-byte", p.costPerByte, "response-length", contentLength, "cost", cost)
+	if p.prepaid != nil {
+		return p.chargePrepaid(r, v, cost)
+	}
 
 	s, err := p.nitroClient.ReceiveVoucher(v)
 	if err != nil {
@@ -152,16 +274,48 @@ func (p *PaymentProxy) handleDestinationResponse(r *http.Response) error {
 	}
 	slog.Debug("Received voucher", "delta", s.Delta.Uint64())
 
+	if p.sessions != nil {
+		if err := p.sessions.settle(v.ChannelId, s.Delta, cost); err != nil {
+			return createPaymentError(err)
+		}
+		r.Header.Set(SESSION_TOKEN_HEADER, p.sessions.issue(v.ChannelId, time.Now()))
+		return nil
+	}
+
 	// s.Delta is amount our balance increases by adding this voucher
 	// AKA the payment amount we received in the request for this file
-	if cost > s.Delta.Uint64() {
-		return createPaymentError(fmt.Errorf("payment of %d attoFIL required, the voucher only resulted in a payment of %d attoFIL", cost, s.Delta.Uint64()))
+	if cost.Cmp(s.Delta) > 0 {
+		return createPaymentError(fmt.Errorf("payment of %d attoFIL required, the voucher only resulted in a payment of %d attoFIL", cost, s.Delta))
 	}
 	slog.Debug("Destination request", "url", r.Request.URL.String())
 
 	return nil
 }
 
+// chargePrepaid debits the cost of the request from the prepaid balance of the voucher's channel, after topping the
+// balance up with the voucher if the request carries one. The balance left is reported in the response headers.
+func (p *PaymentProxy) chargePrepaid(r *http.Response, v payments.Voucher, cost *big.Int) error {
+	if v.Amount != nil {
+		s, err := p.nitroClient.ReceiveVoucher(v)
+		if err != nil {
+			return createPaymentError(fmt.Errorf("error processing voucher %w", err))
+		}
+		slog.Debug("Received prepaid top up", "channel", v.ChannelId, "delta", s.Delta.Uint64())
+		p.prepaid.credit(v.ChannelId, s)
+		r.Header.Set(PREPAID_TOKEN_HEADER, p.prepaid.token(v.ChannelId))
+	}
+
+	remaining, err := p.prepaid.debit(v.ChannelId, cost)
+	if err != nil {
+		return createPaymentError(err)
+	}
+	r.Header.Set(PREPAID_BALANCE_HEADER, remaining.String())
+	if p.prepaid.isLow(remaining) {
+		r.Header.Set(INVOICE_HEADER, p.prepaid.topUp.String())
+	}
+	return nil
+}
+
 // handleError is responsible for logging the error and returning the appropriate HTTP status code
 func (p *PaymentProxy) handleError(w http.ResponseWriter, r *http.Request, err error) {
 	enableCors(w.Header())
@@ -176,6 +330,21 @@ func (p *PaymentProxy) handleError(w http.ResponseWriter, r *http.Request, err e
 
 // Start starts the proxy server in a goroutine.
 func (p *PaymentProxy) Start() error {
+	if p.sessions != nil && p.prepaid == nil && p.sessions.cfg.ReconcileInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(p.sessions.cfg.ReconcileInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case now := <-ticker.C:
+					p.sessions.reconcile(p.nitroClient, now)
+				case <-p.stop:
+					return
+				}
+			}
+		}()
+	}
+
 	go func() {
 		if p.certFilePath != "" && p.certKeyPath != "" {
 			if err := p.server.ListenAndServeTLS(p.certFilePath, p.certKeyPath); err != http.ErrServerClosed {
@@ -195,6 +364,7 @@ func (p *PaymentProxy) Start() error {
 // Stop stops the proxy server and closes everything.
 func (p *PaymentProxy) Stop() error {
 	slog.Info("Stopping a payment proxy", "address", p.server.Addr)
+	close(p.stop)
 
 	err := p.server.Shutdown(context.Background())
 	if err != nil {
@@ -227,16 +397,37 @@ func parseVoucher(params url.Values) (payments.Voucher, error) {
 		Amount:    amount,
 		Signature: crypto.SplitSignature(hexutil.MustDecode(rawSignature)),
 	}
+	if rawExpiry := params.Get(EXPIRY_VOUCHER_PARAM); rawExpiry != "" {
+		expiry, err := strconv.ParseUint(rawExpiry, 10, 64)
+		if err != nil {
+			return payments.Voucher{}, fmt.Errorf("invalid expiry: %w", err)
+		}
+		v.Expiry = expiry
+	}
+	if rawNonce := params.Get(NONCE_VOUCHER_PARAM); rawNonce != "" {
+		nonce, err := strconv.ParseUint(rawNonce, 10, 64)
+		if err != nil {
+			return payments.Voucher{}, fmt.Errorf("invalid nonce: %w", err)
+		}
+		v.Nonce = nonce
+	}
 	return v, nil
 }
 
-// removeVoucherParams removes the voucher parameters from the request URL
+// removeVoucher removes the voucher, session token, prepaid token and stream parameters from the request URL, and the voucher header
 func removeVoucher(r *http.Request) {
+	r.Header.Del(VOUCHER_HEADER)
+
 	queryParams := r.URL.Query()
 
 	queryParams.Del(CHANNEL_ID_VOUCHER_PARAM)
 	queryParams.Del(AMOUNT_VOUCHER_PARAM)
 	queryParams.Del(SIGNATURE_VOUCHER_PARAM)
+	queryParams.Del(EXPIRY_VOUCHER_PARAM)
+	queryParams.Del(NONCE_VOUCHER_PARAM)
+	queryParams.Del(SESSION_TOKEN_PARAM)
+	queryParams.Del(PREPAID_TOKEN_PARAM)
+	queryParams.Del(STREAM_PARAM)
 
 	r.URL.RawQuery = queryParams.Encode()
 }
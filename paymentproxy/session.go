@@ -0,0 +1,192 @@
+package paymentproxy
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// SESSION_TOKEN_PARAM carries a session token in place of a voucher.
+	SESSION_TOKEN_PARAM = "session"
+	// SESSION_TOKEN_HEADER carries the session token issued in response to a request paid with a voucher.
+	SESSION_TOKEN_HEADER = "Nitro-Session-Token"
+	// OWED_HEADER reports the amount owed for the requests made with session tokens, which the next voucher must pay.
+	OWED_HEADER = "Nitro-Owed"
+
+	SESSION_CONTEXT_ARG contextKey = "session"
+)
+
+// SessionConfig configures the session tokens issued by a proxy.
+type SessionConfig struct {
+	// Key signs the tokens. If empty, a random key is used, so tokens do not survive a restart.
+	Key []byte
+	// MaxRequests is the number of requests a token may be presented for.
+	MaxRequests int
+	// Lifetime is how long a token may be presented for.
+	Lifetime time.Duration
+	// ReconcileInterval is how often the amount owed for each channel is checked against the channel's remaining funds.
+	// Sessions for channels which cannot pay what they owe are revoked.
+	ReconcileInterval time.Duration
+}
+
+type session struct {
+	channelId types.Destination
+	expires   time.Time
+	requests  int
+}
+
+// sessions issues and tracks session tokens. Requests made with a token are not paid for when they are made: their
+// cost is owed by the token's channel, and must be paid by the next voucher on it.
+type sessions struct {
+	cfg SessionConfig
+
+	mu     sync.Mutex
+	active map[uint64]*session
+	owed   map[types.Destination]*big.Int
+}
+
+func newSessions(cfg SessionConfig) *sessions {
+	if len(cfg.Key) == 0 {
+		cfg.Key = make([]byte, 32)
+		if _, err := rand.Read(cfg.Key); err != nil {
+			panic(err)
+		}
+	}
+	return &sessions{cfg: cfg, active: make(map[uint64]*session), owed: make(map[types.Destination]*big.Int)}
+}
+
+// sessionPayloadLength is the length of a token's payload: its session id, expiry and channel id.
+const sessionPayloadLength = 8 + 8 + 32
+
+func (s *sessions) mac(payload []byte) []byte {
+	m := hmac.New(sha256.New, s.cfg.Key)
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+// issue starts a session for the channel, returning its token.
+func (s *sessions) issue(channelId types.Destination, now time.Time) string {
+	var idBytes [8]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		panic(err)
+	}
+	id := binary.BigEndian.Uint64(idBytes[:])
+	expires := now.Add(s.cfg.Lifetime)
+
+	payload := make([]byte, 0, sessionPayloadLength)
+	payload = binary.BigEndian.AppendUint64(payload, id)
+	payload = binary.BigEndian.AppendUint64(payload, uint64(expires.Unix()))
+	payload = append(payload, channelId[:]...)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active[id] = &session{channelId: channelId, expires: expires}
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(payload)...))
+}
+
+// use counts a request made with the token, returning the channel which owes its cost.
+func (s *sessions) use(token string, now time.Time) (types.Destination, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) != sessionPayloadLength+sha256.Size {
+		return types.Destination{}, fmt.Errorf("malformed session token")
+	}
+	payload, mac := raw[:sessionPayloadLength], raw[sessionPayloadLength:]
+	if !hmac.Equal(mac, s.mac(payload)) {
+		return types.Destination{}, fmt.Errorf("invalid session token")
+	}
+	id := binary.BigEndian.Uint64(payload)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, ok := s.active[id]
+	if !ok || !now.Before(session.expires) || session.requests >= s.cfg.MaxRequests {
+		delete(s.active, id)
+		return types.Destination{}, fmt.Errorf("session has ended, a voucher is required")
+	}
+	session.requests++
+	return session.channelId, nil
+}
+
+// charge adds the cost of a request made with a session token to what the channel owes, returning the total owed.
+func (s *sessions) charge(channelId types.Destination, cost *big.Int) *big.Int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owed, ok := s.owed[channelId]
+	if !ok {
+		owed = big.NewInt(0)
+		s.owed[channelId] = owed
+	}
+	owed.Add(owed, cost)
+	return new(big.Int).Set(owed)
+}
+
+// settle applies a voucher's payment to what the channel owes and the cost of the request it pays for. If it does not
+// cover both, what it does pay is deducted from what is owed, and an error is returned.
+func (s *sessions) settle(channelId types.Destination, delta, cost *big.Int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	owed, ok := s.owed[channelId]
+	if !ok {
+		owed = big.NewInt(0)
+	}
+	required := new(big.Int).Add(owed, cost)
+	if delta.Cmp(required) < 0 {
+		if ok {
+			owed.Sub(owed, delta)
+			if owed.Sign() < 0 {
+				owed.SetInt64(0)
+			}
+		}
+		return fmt.Errorf("payment of %d attoFIL required, including %d attoFIL owed, the voucher only resulted in a payment of %d attoFIL", required, new(big.Int).Sub(required, cost), delta)
+	}
+	delete(s.owed, channelId)
+	return nil
+}
+
+// reconcile checks what each channel owes against its remaining funds, revoking the sessions of channels which cannot
+// pay. Expired sessions are forgotten.
+func (s *sessions) reconcile(client rpc.RpcClientApi, now time.Time) {
+	s.mu.Lock()
+	owing := make(map[types.Destination]*big.Int, len(s.owed))
+	for id, owed := range s.owed {
+		owing[id] = new(big.Int).Set(owed)
+	}
+	for id, session := range s.active {
+		if !now.Before(session.expires) {
+			delete(s.active, id)
+		}
+	}
+	s.mu.Unlock()
+
+	for channelId, owed := range owing {
+		info, err := client.GetPaymentChannel(channelId)
+		if err == nil && info.Status == query.Open && info.Balance.RemainingFunds.ToInt().Cmp(owed) >= 0 {
+			continue
+		}
+		slog.Warn("Revoking sessions of a channel which cannot pay what it owes", "channel", channelId, "owed", owed, "error", err)
+		s.revoke(channelId)
+	}
+}
+
+// revoke ends every session of the channel.
+func (s *sessions) revoke(channelId types.Destination) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, session := range s.active {
+		if session.channelId == channelId {
+			delete(s.active, id)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package paymentproxy
+
+import (
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/rpc"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// channelClient is a nitro client which reports a payment channel with the given remaining funds.
+type channelClient struct {
+	rpc.RpcClientApi
+	remaining int64
+}
+
+func (c channelClient) GetPaymentChannel(id types.Destination) (query.PaymentChannelInfo, error) {
+	return query.PaymentChannelInfo{ID: id, Status: query.Open, Balance: query.PaymentChannelBalance{
+		RemainingFunds: (*hexutil.Big)(big.NewInt(c.remaining)),
+	}}, nil
+}
+
+func TestSessionTokens(t *testing.T) {
+	s := newSessions(SessionConfig{MaxRequests: 2, Lifetime: time.Minute})
+	channelId := types.Destination{1}
+	now := time.Now()
+
+	token := s.issue(channelId, now)
+	for i := 0; i < 2; i++ {
+		got, err := s.use(token, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != channelId {
+			t.Fatalf("expected the token to be for channel %s, got %s", channelId, got)
+		}
+	}
+	if _, err := s.use(token, now); err == nil {
+		t.Error("expected a token to cover at most MaxRequests requests")
+	}
+
+	if _, err := s.use(s.issue(channelId, now), now.Add(time.Minute)); err == nil {
+		t.Error("expected an expired token to be refused")
+	}
+
+	// Tokens which were not issued by the proxy are refused
+	raw, _ := base64.RawURLEncoding.DecodeString(s.issue(channelId, now))
+	raw[len(raw)-1] ^= 1
+	if _, err := s.use(base64.RawURLEncoding.EncodeToString(raw), now); err == nil {
+		t.Error("expected a forged token to be refused")
+	}
+	other := newSessions(SessionConfig{MaxRequests: 2, Lifetime: time.Minute})
+	if _, err := s.use(other.issue(channelId, now), now); err == nil {
+		t.Error("expected a token signed with another key to be refused")
+	}
+}
+
+func TestSessionSettlement(t *testing.T) {
+	s := newSessions(SessionConfig{MaxRequests: 10, Lifetime: time.Minute})
+	channelId := types.Destination{1}
+
+	s.charge(channelId, big.NewInt(10))
+	if owed := s.charge(channelId, big.NewInt(5)); owed.Cmp(big.NewInt(15)) != 0 {
+		t.Fatalf("expected 15 to be owed, got %s", owed)
+	}
+
+	// The next voucher must pay what is owed as well as its own request
+	err := s.settle(channelId, big.NewInt(12), big.NewInt(5))
+	if err == nil || !strings.Contains(err.Error(), "20 attoFIL required") {
+		t.Fatalf("expected a voucher paying less than is owed to be refused, got %v", err)
+	}
+	if err := s.settle(channelId, big.NewInt(8), big.NewInt(5)); err != nil {
+		t.Fatalf("expected the voucher to pay the 3 still owed and its request, got %v", err)
+	}
+	if err := s.settle(channelId, big.NewInt(5), big.NewInt(5)); err != nil {
+		t.Fatalf("expected nothing to be owed once settled, got %v", err)
+	}
+}
+
+func TestSessionReconciliation(t *testing.T) {
+	s := newSessions(SessionConfig{MaxRequests: 10, Lifetime: time.Minute})
+	channelId := types.Destination{1}
+	now := time.Now()
+	token := s.issue(channelId, now)
+	s.charge(channelId, big.NewInt(50))
+
+	// Sessions continue while the channel can pay what it owes
+	s.reconcile(channelClient{remaining: 50}, now)
+	if _, err := s.use(token, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// and are revoked once it cannot
+	s.reconcile(channelClient{remaining: 49}, now)
+	if _, err := s.use(token, now); err == nil {
+		t.Error("expected the session of a channel which cannot pay what it owes to be revoked")
+	}
+}
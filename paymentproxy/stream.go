@@ -0,0 +1,278 @@
+package paymentproxy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// STREAM_PARAM asks for the response to be paid for as it is streamed, rather than up front.
+	STREAM_PARAM = "stream"
+	// STREAM_ID_PARAM identifies the stream a voucher sent to STREAM_PAYMENT_PATH pays for.
+	STREAM_ID_PARAM = "streamId"
+	// STREAM_PAYMENT_PATH is the side channel which vouchers paying for streams are sent to, as they would be sent with
+	// a request, while the stream is served.
+	STREAM_PAYMENT_PATH = "/stream/pay"
+
+	// STREAM_ID_HEADER reports the id of a stream, which vouchers paying for it must be sent with.
+	STREAM_ID_HEADER = "Nitro-Stream-Id"
+	// STREAM_CHUNK_HEADER reports the number of bytes of a stream which are paid for at a time.
+	STREAM_CHUNK_HEADER = "Nitro-Stream-Chunk"
+	// STREAM_CHUNK_PRICE_HEADER reports the price of the first chunk of a stream.
+	STREAM_CHUNK_PRICE_HEADER = "Nitro-Stream-Chunk-Price"
+	// STREAM_PAID_HEADER reports the total paid for a stream, in response to a voucher sent to STREAM_PAYMENT_PATH.
+	STREAM_PAID_HEADER = "Nitro-Stream-Paid"
+
+	STREAM_CONTEXT_ARG contextKey = "stream"
+
+	ErrStreamPaymentTimeout = types.ConstError("stream was not paid for in time")
+)
+
+// StreamConfig configures the streams served by a proxy.
+type StreamConfig struct {
+	// ChunkSize is the number of bytes of a response which are paid for at a time. It must be positive.
+	ChunkSize uint64
+	// PaymentTimeout is how long a stream is paused waiting to be paid for before it is aborted, leaving the client
+	// with a truncated response.
+	PaymentTimeout time.Duration
+}
+
+// stream is a response which is paid for as it is written. The cumulative price of the bytes written must be covered
+// by the vouchers received for it, chunk by chunk: once it is not, the stream pauses until another voucher arrives.
+type stream struct {
+	id        string
+	channelId types.Destination
+	request   *http.Request // the request the stream responds to, which its price depends on
+
+	mu      sync.Mutex
+	open    bool     // whether the voucher the request was made with has been received, after which the body is metered
+	paid    *big.Int // the total received for the stream
+	sent    uint64   // the number of bytes written
+	allowed uint64   // the number of bytes paid for
+	credit  chan struct{}
+}
+
+// streams tracks the streams being served, so that the vouchers paying for them can be credited.
+type streams struct {
+	cfg StreamConfig
+
+	mu     sync.Mutex
+	active map[string]*stream
+}
+
+func newStreams(cfg StreamConfig) *streams {
+	return &streams{cfg: cfg, active: make(map[string]*stream)}
+}
+
+// start begins a stream paid for through the channel.
+func (s *streams) start(channelId types.Destination) *stream {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		panic(err)
+	}
+	st := &stream{
+		id:        hex.EncodeToString(idBytes),
+		channelId: channelId,
+		paid:      big.NewInt(0),
+		credit:    make(chan struct{}, 1),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.active[st.id] = st
+	return st
+}
+
+// end forgets the stream once it has been served. Anything paid for it beyond what was written is not refunded.
+func (s *streams) end(st *stream) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, st.id)
+}
+
+// get returns the stream with the id, if it is being served.
+func (s *streams) get(id string) (*stream, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.active[id]
+	return st, ok
+}
+
+// pay credits a payment made for the stream, resuming it if it is paused. It returns the total paid.
+func (st *stream) pay(delta *big.Int) *big.Int {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.paid.Add(st.paid, delta)
+	select {
+	case st.credit <- struct{}{}:
+	default:
+	}
+	return new(big.Int).Set(st.paid)
+}
+
+// meteredWriter writes the body of a stream, writing no more than has been paid for.
+type meteredWriter struct {
+	http.ResponseWriter
+	stream  *stream
+	pricing PriceOracle
+	cfg     StreamConfig
+}
+
+func (mw *meteredWriter) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		allowance, err := mw.allowance()
+		if err != nil {
+			return written, err
+		}
+		n, err := mw.ResponseWriter.Write(b[written : written+int(min(allowance, uint64(len(b)-written)))])
+		written += n
+		mw.stream.mu.Lock()
+		mw.stream.sent += uint64(n)
+		mw.stream.mu.Unlock()
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+// allowance returns the number of bytes which may be written now. If none may, it waits for the stream to be paid for,
+// up to the PaymentTimeout.
+func (mw *meteredWriter) allowance() (uint64, error) {
+	st := mw.stream
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if !st.open {
+		// The response failed before it was paid for, so an error is being written
+		return ^uint64(0), nil
+	}
+
+	for st.sent >= st.allowed {
+		next := st.allowed + mw.cfg.ChunkSize
+		price, err := mw.pricing.Price(st.request.Context(), st.request, next)
+		if err != nil {
+			return 0, fmt.Errorf("could not price stream: %w", err)
+		}
+		if st.paid.Cmp(price) >= 0 {
+			st.allowed = next
+			break
+		}
+
+		// The client is sent what it has paid for, so that it can pay for more
+		slog.Debug("Pausing stream until it is paid for", "stream", st.id, "sent", st.sent, "price", price)
+		st.mu.Unlock()
+		err = mw.waitForCredit()
+		st.mu.Lock()
+		if err != nil {
+			return 0, err
+		}
+	}
+	return st.allowed - st.sent, nil
+}
+
+// waitForCredit flushes what has been written, then waits for a payment for the stream.
+func (mw *meteredWriter) waitForCredit() error {
+	if err := http.NewResponseController(mw.ResponseWriter).Flush(); err != nil {
+		return err
+	}
+	timer := time.NewTimer(mw.cfg.PaymentTimeout)
+	defer timer.Stop()
+	select {
+	case <-mw.stream.credit:
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: stream %s", ErrStreamPaymentTimeout, mw.stream.id)
+	case <-mw.stream.request.Context().Done():
+		return context.Cause(mw.stream.request.Context())
+	}
+}
+
+func (mw *meteredWriter) Unwrap() http.ResponseWriter {
+	return mw.ResponseWriter
+}
+
+// serveStream proxies the request, metering its response so that it is paid for as it is written. The request must
+// carry a voucher, which identifies the channel paying for the stream and is received once the response arrives.
+func (p *PaymentProxy) serveStream(w http.ResponseWriter, r *http.Request) {
+	v, err := p.requestVoucher(r)
+	if err != nil {
+		p.handleError(w, r, createPaymentError(fmt.Errorf("could not parse voucher: %w", err)))
+		return
+	}
+	removeVoucher(r)
+
+	st := p.streams.start(v.ChannelId)
+	defer p.streams.end(st)
+	// The voucher is received once the response arrives, as for other requests
+	r = r.WithContext(context.WithValue(context.WithValue(r.Context(), VOUCHER_CONTEXT_ARG, v), STREAM_CONTEXT_ARG, st))
+	st.request = r
+
+	p.reverseProxy.ServeHTTP(&meteredWriter{ResponseWriter: w, stream: st, pricing: p.pricing, cfg: p.streams.cfg}, r)
+}
+
+// openStream receives the voucher a stream's request was made with, and reports how the stream is to be paid for in
+// the response headers. The body is metered from then on.
+func (p *PaymentProxy) openStream(r *http.Response, st *stream) error {
+	v, ok := r.Request.Context().Value(VOUCHER_CONTEXT_ARG).(payments.Voucher)
+	if !ok {
+		return createPaymentError(fmt.Errorf("could not fetch voucher from context"))
+	}
+	chunkPrice, err := p.pricing.Price(r.Request.Context(), r.Request, p.streams.cfg.ChunkSize)
+	if err != nil {
+		return fmt.Errorf("could not price request: %w", err)
+	}
+	s, err := p.nitroClient.ReceiveVoucher(v)
+	if err != nil {
+		return createPaymentError(fmt.Errorf("error processing voucher %w", err))
+	}
+	st.pay(s.Delta)
+
+	r.Header.Set(STREAM_ID_HEADER, st.id)
+	r.Header.Set(STREAM_CHUNK_HEADER, strconv.FormatUint(p.streams.cfg.ChunkSize, 10))
+	r.Header.Set(STREAM_CHUNK_PRICE_HEADER, chunkPrice.String())
+
+	st.mu.Lock()
+	st.open = true
+	st.mu.Unlock()
+	return nil
+}
+
+// handleStreamPayment receives a voucher paying for a stream, sent to STREAM_PAYMENT_PATH, resuming the stream if it
+// is paused. The total paid for the stream is reported in the response headers.
+func (p *PaymentProxy) handleStreamPayment(w http.ResponseWriter, r *http.Request) {
+	st, ok := p.streams.get(r.URL.Query().Get(STREAM_ID_PARAM))
+	if !ok {
+		p.handleError(w, r, createPaymentError(fmt.Errorf("unknown stream %q", r.URL.Query().Get(STREAM_ID_PARAM))))
+		return
+	}
+	v, err := p.requestVoucher(r)
+	if err != nil {
+		p.handleError(w, r, createPaymentError(fmt.Errorf("could not parse voucher: %w", err)))
+		return
+	}
+	if v.ChannelId != st.channelId {
+		p.handleError(w, r, createPaymentError(fmt.Errorf("stream %s is paid for through channel %s", st.id, st.channelId)))
+		return
+	}
+	s, err := p.nitroClient.ReceiveVoucher(v)
+	if err != nil {
+		p.handleError(w, r, createPaymentError(fmt.Errorf("error processing voucher %w", err)))
+		return
+	}
+
+	enableCors(w.Header())
+	w.Header().Set(STREAM_PAID_HEADER, st.pay(s.Delta).String())
+	w.WriteHeader(http.StatusOK)
+}
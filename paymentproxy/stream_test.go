@@ -0,0 +1,132 @@
+package paymentproxy
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestStreaming(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100)
+	destination := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+	defer destination.Close()
+	destinationUrl, err := url.Parse(destination.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := &receivingClient{paid: map[types.Destination]*big.Int{}}
+	p := newPaymentProxy("", client, destinationUrl, 1, "", "")
+	p.EnableStreaming(StreamConfig{ChunkSize: 10, PaymentTimeout: 200 * time.Millisecond})
+	proxy := httptest.NewServer(p)
+	defer proxy.Close()
+
+	voucherHeader := func(channelId types.Destination, amount int64) string {
+		t.Helper()
+		v := payments.Voucher{ChannelId: channelId, Amount: big.NewInt(amount)}
+		if err := v.Sign(testactors.Alice.PrivateKey); err != nil {
+			t.Fatal(err)
+		}
+		header, err := EncodeCompactVoucher(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return header
+	}
+	request := func(path string, voucher string, rangeHeader string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodGet, proxy.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(VOUCHER_HEADER, voucher)
+		if rangeHeader != "" {
+			req.Header.Set("Range", rangeHeader)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	t.Run("paid as it streams", func(t *testing.T) {
+		channelId := types.Destination{1}
+		resp := request("/file?stream", voucherHeader(channelId, 30), "")
+		defer resp.Body.Close()
+		if got := resp.Header.Get(STREAM_CHUNK_HEADER); got != "10" {
+			t.Errorf("expected chunks of 10 bytes, got %q", got)
+		}
+		if got := resp.Header.Get(STREAM_CHUNK_PRICE_HEADER); got != "10" {
+			t.Errorf("expected a chunk price of 10, got %q", got)
+		}
+
+		// The stream pauses once the 30 bytes paid for have been sent
+		paidFor := make([]byte, 30)
+		if _, err := io.ReadFull(resp.Body, paidFor); err != nil {
+			t.Fatal(err)
+		}
+
+		pay := request(STREAM_PAYMENT_PATH+"?"+STREAM_ID_PARAM+"="+resp.Header.Get(STREAM_ID_HEADER), voucherHeader(channelId, 100), "")
+		pay.Body.Close()
+		if pay.StatusCode != http.StatusOK {
+			t.Fatalf("expected the payment to be accepted, got status %d", pay.StatusCode)
+		}
+		if got := pay.Header.Get(STREAM_PAID_HEADER); got != "100" {
+			t.Errorf("expected 100 to have been paid, got %q", got)
+		}
+
+		rest, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rest) != 70 {
+			t.Errorf("expected the remaining 70 bytes, got %d", len(rest))
+		}
+	})
+
+	t.Run("aborted when unpaid", func(t *testing.T) {
+		resp := request("/file?stream", voucherHeader(types.Destination{2}, 20), "")
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err == nil {
+			t.Fatal("expected the stream to be truncated")
+		}
+		if len(body) != 20 {
+			t.Errorf("expected the 20 bytes paid for, got %d", len(body))
+		}
+	})
+
+	t.Run("range", func(t *testing.T) {
+		resp := request("/file?stream", voucherHeader(types.Destination{3}, 30), "bytes=50-79")
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusPartialContent || len(body) != 30 {
+			t.Errorf("expected 30 bytes of partial content, got status %d with %d bytes", resp.StatusCode, len(body))
+		}
+	})
+
+	t.Run("unknown stream", func(t *testing.T) {
+		pay := request(STREAM_PAYMENT_PATH+"?"+STREAM_ID_PARAM+"=unknown", voucherHeader(types.Destination{1}, 200), "")
+		defer pay.Body.Close()
+		body, _ := io.ReadAll(pay.Body)
+		if pay.StatusCode != http.StatusPaymentRequired || !strings.Contains(string(body), "unknown stream") {
+			t.Errorf("expected the payment to be refused, got status %d: %s", pay.StatusCode, body)
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package paymentproxy
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// VOUCHER_HEADER carries a voucher in place of the voucher query params, so that requests with a body or with urls
+// which must stay cacheable can be paid for. It holds either a base64 encoding of the voucher's JSON, or the compact
+// hex encoding returned by EncodeCompactVoucher.
+const VOUCHER_HEADER = "X-Nitro-Voucher"
+
+// compactVoucherLength is the length in bytes of a compact voucher: the channel id, the amount as a 32 byte big endian
+// integer, then the [R||S||V] signature.
+const compactVoucherLength = 32 + 32 + 65
+
+// VoucherSources is the set of places in a request the proxy accepts a voucher from.
+type VoucherSources uint8
+
+const (
+	VoucherQueryParams VoucherSources = 1 << iota // The channelId, amount and signature query params
+	VoucherHeader                                 // The X-Nitro-Voucher header
+
+	AllVoucherSources = VoucherQueryParams | VoucherHeader
+)
+
+// ParseVoucherSources parses a comma separated list of voucher sources: "query" and "header".
+func ParseVoucherSources(s string) (VoucherSources, error) {
+	var sources VoucherSources
+	for _, name := range strings.Split(s, ",") {
+		switch strings.TrimSpace(name) {
+		case "query":
+			sources |= VoucherQueryParams
+		case "header":
+			sources |= VoucherHeader
+		default:
+			return 0, fmt.Errorf("unknown voucher source %q: it must be query or header", name)
+		}
+	}
+	return sources, nil
+}
+
+// EncodeVoucherHeader returns the value of the X-Nitro-Voucher header carrying v, as base64 encoded JSON.
+func EncodeVoucherHeader(v payments.Voucher) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b), nil
+}
+
+// EncodeCompactVoucher returns the value of the X-Nitro-Voucher header carrying v in its compact hex encoding, which
+// does not carry the voucher's context. Expiring vouchers and vouchers with a nonce cannot be encoded compactly.
+func EncodeCompactVoucher(v payments.Voucher) (string, error) {
+	if v.Amount == nil || v.Amount.Sign() < 0 || v.Amount.BitLen() > 256 {
+		return "", fmt.Errorf("amount %v cannot be encoded", v.Amount)
+	}
+	if v.Expiry != 0 {
+		return "", fmt.Errorf("expiring vouchers cannot be encoded compactly")
+	}
+	if v.Nonce != 0 {
+		return "", fmt.Errorf("vouchers with a nonce cannot be encoded compactly")
+	}
+	b := make([]byte, 0, compactVoucherLength)
+	b = append(b, v.ChannelId.Bytes()...)
+	b = append(b, common.LeftPadBytes(v.Amount.Bytes(), 32)...)
+	b = append(b, common.LeftPadBytes(v.Signature.R, 32)...)
+	b = append(b, common.LeftPadBytes(v.Signature.S, 32)...)
+	b = append(b, v.Signature.V)
+	return hexutil.Encode(b), nil
+}
+
+// parseVoucherHeader parses the voucher carried by the X-Nitro-Voucher header, in either encoding.
+func parseVoucherHeader(header string) (payments.Voucher, error) {
+	if strings.HasPrefix(header, "0x") {
+		b, err := hexutil.Decode(header)
+		if err != nil {
+			return payments.Voucher{}, fmt.Errorf("invalid compact voucher: %w", err)
+		}
+		if len(b) != compactVoucherLength {
+			return payments.Voucher{}, fmt.Errorf("compact voucher has %d bytes, expected %d", len(b), compactVoucherLength)
+		}
+		return payments.Voucher{
+			ChannelId: types.Destination(common.BytesToHash(b[:32])),
+			Amount:    new(big.Int).SetBytes(b[32:64]),
+			Signature: crypto.SplitSignature(b[64:]),
+		}, nil
+	}
+
+	b, err := base64.StdEncoding.DecodeString(header)
+	if err != nil {
+		return payments.Voucher{}, fmt.Errorf("invalid base64 voucher: %w", err)
+	}
+	var v payments.Voucher
+	if err := json.Unmarshal(b, &v); err != nil {
+		return payments.Voucher{}, fmt.Errorf("invalid voucher JSON: %w", err)
+	}
+	if v.Amount == nil {
+		return payments.Voucher{}, fmt.Errorf("missing amount")
+	}
+	return v, nil
+}
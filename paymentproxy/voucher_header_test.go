@@ -0,0 +1,98 @@
+package paymentproxy
+
+import (
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestVoucherHeader(t *testing.T) {
+	v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(1234)}
+	if err := v.Sign(testactors.Alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	jsonHeader, err := EncodeVoucherHeader(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	compactHeader, err := EncodeCompactVoucher(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, header := range map[string]string{"json": jsonHeader, "compact": compactHeader} {
+		got, err := parseVoucherHeader(header)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !got.Equal(&v) {
+			t.Errorf("%s: expected %v, got %v", name, v, got)
+		}
+	}
+
+	for _, header := range []string{"0x1234", "not base64!", "e30="} {
+		if _, err := parseVoucherHeader(header); err == nil {
+			t.Errorf("expected %q to be rejected", header)
+		}
+	}
+}
+
+func TestRequestVoucherSources(t *testing.T) {
+	v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(5)}
+	if err := v.Sign(testactors.Alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+	header, err := EncodeCompactVoucher(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := "/resource?channelId=" + v.ChannelId.String() + "&amount=5&signature=" + v.Signature.ToHexString()
+
+	testCases := []struct {
+		sources        VoucherSources
+		headerAccepted bool
+		queryAccepted  bool
+	}{
+		{AllVoucherSources, true, true},
+		{VoucherHeader, true, false},
+		{VoucherQueryParams, false, true},
+	}
+	for _, tc := range testCases {
+		p := &PaymentProxy{sources: tc.sources}
+
+		r := httptest.NewRequest("POST", "/resource", nil)
+		r.Header.Set(VOUCHER_HEADER, header)
+		got, err := p.requestVoucher(r)
+		if (err == nil) != tc.headerAccepted {
+			t.Errorf("sources %d: expected the header to be accepted: %t, got error %v", tc.sources, tc.headerAccepted, err)
+		}
+		if err == nil && !got.Equal(&v) {
+			t.Errorf("sources %d: expected %v, got %v", tc.sources, v, got)
+		}
+
+		_, err = p.requestVoucher(httptest.NewRequest("GET", query, nil))
+		if (err == nil) != tc.queryAccepted {
+			t.Errorf("sources %d: expected query params to be accepted: %t, got error %v", tc.sources, tc.queryAccepted, err)
+		}
+	}
+}
+
+func TestParseVoucherSources(t *testing.T) {
+	for s, want := range map[string]VoucherSources{"query": VoucherQueryParams, "header": VoucherHeader, "query, header": AllVoucherSources} {
+		got, err := ParseVoucherSources(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("expected %q to parse as %d, got %d", s, want, got)
+		}
+	}
+	if _, err := ParseVoucherSources("cookie"); err == nil {
+		t.Error("expected an unknown source to be rejected")
+	}
+}
@@ -0,0 +1,74 @@
+package payments
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrPaymentIdReused is returned when a payment id is reused for a payment of a different amount.
+const ErrPaymentIdReused = types.ConstError("payment id already used for a different amount")
+
+// PaymentRecord records a payment made with a client-supplied payment id.
+type PaymentRecord struct {
+	Amount  *big.Int // The amount paid
+	Voucher Voucher  // The voucher created for the payment
+}
+
+// PaymentIdStore holds the records of the payments made on each channel with client-supplied payment ids.
+// The records of a channel should be removed along with its voucher info.
+type PaymentIdStore interface {
+	GetPaymentRecord(channelId types.Destination, paymentId string) (r PaymentRecord, ok bool, err error)
+	SetPaymentRecord(channelId types.Destination, paymentId string, r PaymentRecord) error
+}
+
+// paymentIdLocks is the number of locks a PaymentIds spreads channels over.
+const paymentIdLocks = 64
+
+// PaymentIds makes payments identified by client-supplied payment ids at most once, so that applications may retry
+// payments (after a timeout, say) without paying twice.
+type PaymentIds struct {
+	store PaymentIdStore
+	locks [paymentIdLocks]sync.Mutex
+}
+
+// NewPaymentIds creates a PaymentIds which records payments in the store.
+func NewPaymentIds(store PaymentIdStore) *PaymentIds {
+	return &PaymentIds{store: store}
+}
+
+// Pay pays amount on the channel with vm, and records the voucher against paymentId. If a payment with paymentId has
+// already been made on the channel, its voucher is returned instead and paid is false.
+func (p *PaymentIds) Pay(vm VoucherManagerApi, channelId types.Destination, amount *big.Int, paymentId string, signer VoucherSigner) (v Voucher, paid bool, err error) {
+	return p.PayWithExpiry(vm, channelId, amount, paymentId, time.Time{}, signer)
+}
+
+// PayWithExpiry is like Pay, but a new voucher expires at expiry, unless it is zero. The voucher of a payment made
+// before is returned as it was made.
+func (p *PaymentIds) PayWithExpiry(vm VoucherManagerApi, channelId types.Destination, amount *big.Int, paymentId string, expiry time.Time, signer VoucherSigner) (v Voucher, paid bool, err error) {
+	// Channel ids are hashes, so any byte is uniformly distributed
+	lock := &p.locks[int(channelId[len(channelId)-1])%paymentIdLocks]
+	lock.Lock()
+	defer lock.Unlock()
+
+	r, ok, err := p.store.GetPaymentRecord(channelId, paymentId)
+	if err != nil {
+		return Voucher{}, false, err
+	}
+	if ok {
+		if r.Amount.Cmp(amount) != 0 {
+			return Voucher{}, false, fmt.Errorf("%w: payment %s paid %s, not %s", ErrPaymentIdReused, paymentId, r.Amount, amount)
+		}
+		return r.Voucher, false, nil
+	}
+
+	v, err = vm.PayWithExpiry(channelId, amount, expiry, signer)
+	if err != nil {
+		return Voucher{}, false, err
+	}
+	err = p.store.SetPaymentRecord(channelId, paymentId, PaymentRecord{Amount: new(big.Int).Set(amount), Voucher: v.clone()})
+	return v, true, err
+}
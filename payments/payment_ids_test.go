@@ -0,0 +1,84 @@
+package payments
+
+import (
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// simplePaymentIdStore is a PaymentIdStore which holds the records in memory.
+type simplePaymentIdStore struct {
+	mu      sync.Mutex
+	records map[types.Destination]map[string]PaymentRecord
+}
+
+func (s *simplePaymentIdStore) GetPaymentRecord(channelId types.Destination, paymentId string) (PaymentRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.records[channelId][paymentId]
+	return r, ok, nil
+}
+
+func (s *simplePaymentIdStore) SetPaymentRecord(channelId types.Destination, paymentId string, r PaymentRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.records[channelId] == nil {
+		s.records[channelId] = map[string]PaymentRecord{}
+	}
+	s.records[channelId][paymentId] = r
+	return nil
+}
+
+func TestPaymentIds(t *testing.T) {
+	channelId := types.Destination{1}
+	vm := NewShardedVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	Ok(t, vm.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(100)))
+	ids := NewPaymentIds(&simplePaymentIdStore{records: map[types.Destination]map[string]PaymentRecord{}})
+
+	// Concurrent payments with the same id pay once, and all return the same voucher
+	const retries = 8
+	vouchers := make([]Voucher, retries)
+	payments := make(chan bool, retries)
+	wg := sync.WaitGroup{}
+	for i := range vouchers {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			v, paid, err := ids.Pay(vm, channelId, big.NewInt(5), "order-1", KeySigner(testactors.Alice.PrivateKey))
+			if err != nil {
+				t.Error(err)
+			}
+			vouchers[i] = v
+			payments <- paid
+		}(i)
+	}
+	wg.Wait()
+	close(payments)
+	made := 0
+	for paid := range payments {
+		if paid {
+			made++
+		}
+	}
+	Equals(t, 1, made)
+	for _, v := range vouchers[1:] {
+		Assert(t, v.Equal(&vouchers[0]), "expected every retry to return the original voucher")
+	}
+	paid, err := vm.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(5), paid)
+
+	// A different id pays again
+	v, ok, err := ids.Pay(vm, channelId, big.NewInt(5), "order-2", KeySigner(testactors.Alice.PrivateKey))
+	Ok(t, err)
+	Assert(t, ok, "expected a payment with a new id to be made")
+	Equals(t, big.NewInt(10), v.Amount)
+
+	// Reusing an id for a different amount fails
+	_, _, err = ids.Pay(vm, channelId, big.NewInt(6), "order-1", KeySigner(testactors.Alice.PrivateKey))
+	Assert(t, errors.Is(err, ErrPaymentIdReused), "expected ErrPaymentIdReused, got %v", err)
+}
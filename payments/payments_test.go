@@ -1,12 +1,14 @@
 package payments
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"path/filepath"
 	"reflect"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/statechannels/go-nitro/internal/safesync"
 	"github.com/statechannels/go-nitro/internal/testactors"
@@ -53,6 +55,16 @@ func (svs *simpleVoucherStore) RemoveVoucherInfo(channelId types.Destination) er
 }
 
 func TestPaymentManager(t *testing.T) {
+	testPaymentManager(t, func(me types.Address, store VoucherStore) VoucherManagerApi { return NewVoucherManager(me, store) })
+}
+
+func TestShardedPaymentManager(t *testing.T) {
+	testPaymentManager(t, func(me types.Address, store VoucherStore) VoucherManagerApi {
+		return NewShardedVoucherManager(me, store)
+	})
+}
+
+func testPaymentManager(t *testing.T, newManager func(types.Address, VoucherStore) VoucherManagerApi) {
 	testVoucher := func(cId types.Destination, amount *big.Int, actor testactors.Actor) Voucher {
 		payment := &big.Int{}
 		payment.Set(amount)
@@ -84,15 +96,19 @@ func TestPaymentManager(t *testing.T) {
 	}
 
 	// Happy path: Payment manager can register channels and make payments
-	paymentMgr := NewVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	paymentMgr := newManager(testactors.Alice.Address(), newSimpleVoucherStore())
 
-	_, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	_, err := paymentMgr.Pay(channelId, payment, KeySigner(testactors.Alice.PrivateKey))
 	Assert(t, err != nil, "channel must be registered to make payments")
 
-	Ok(t, paymentMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+	token := types.Address{'t'}
+	Ok(t, paymentMgr.Register(channelId, token, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
 	Equals(t, startingBalance, getBalance(paymentMgr))
+	asset, err := paymentMgr.Asset(channelId)
+	Ok(t, err)
+	Equals(t, token, asset)
 
-	firstVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	firstVoucher, err := paymentMgr.Pay(channelId, payment, KeySigner(testactors.Alice.PrivateKey))
 	Ok(t, err)
 	Equals(t, testVoucher(channelId, payment, testactors.Alice), firstVoucher)
 	Equals(t, onePaymentMade, getBalance(paymentMgr))
@@ -102,12 +118,12 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, testactors.Alice.Address(), signer)
 
 	// Happy path: receipt manager can receive vouchers
-	receiptMgr := NewVoucherManager(testactors.Bob.Address(), newSimpleVoucherStore())
+	receiptMgr := newManager(testactors.Bob.Address(), newSimpleVoucherStore())
 
 	_, _, err = receiptMgr.Receive(firstVoucher)
 	Assert(t, err != nil, "channel must be registered to receive vouchers")
 
-	_ = receiptMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
+	_ = receiptMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
 	Equals(t, startingBalance, getBalance(receiptMgr))
 
 	received, delta, err := receiptMgr.Receive(firstVoucher)
@@ -123,7 +139,7 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, onePaymentMade, getBalance(receiptMgr))
 
 	// paying twice returns a larger voucher
-	secondVoucher, err := paymentMgr.Pay(channelId, payment, testactors.Alice.PrivateKey)
+	secondVoucher, err := paymentMgr.Pay(channelId, payment, KeySigner(testactors.Alice.PrivateKey))
 	Ok(t, err)
 	Equals(t, testVoucher(channelId, doublePayment, testactors.Alice), secondVoucher)
 	Equals(t, twoPaymentsMade, getBalance(paymentMgr))
@@ -136,11 +152,11 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
 
 	// re-registering a channel doesn't reset its balance
-	err = paymentMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
+	err = paymentMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
 	Assert(t, err != nil, "expected register to fail")
 	Equals(t, twoPaymentsMade, getBalance(paymentMgr))
 
-	err = receiptMgr.Register(channelId, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
+	err = receiptMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit)
 	Assert(t, err != nil, "expected register to fail")
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
 
@@ -152,9 +168,9 @@ func TestPaymentManager(t *testing.T) {
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
 
 	// Only the payer can sign vouchers
-	err = receiptMgr.Register(anotherChannelId, testactors.Bob.Address(), testactors.Alice.Address(), deposit)
+	err = receiptMgr.Register(anotherChannelId, types.Address{}, testactors.Bob.Address(), testactors.Alice.Address(), deposit)
 	Ok(t, err)
-	_, err = paymentMgr.Pay(anotherChannelId, triplePayment, testactors.Bob.PrivateKey)
+	_, err = paymentMgr.Pay(anotherChannelId, triplePayment, KeySigner(testactors.Bob.PrivateKey))
 	Assert(t, err != nil, "only payer can sign vouchers")
 
 	// Receiving a voucher for an unknown channel fails
@@ -173,6 +189,98 @@ func TestPaymentManager(t *testing.T) {
 	_, _, err = receiptMgr.Receive(voucher)
 	Assert(t, err != nil, "expected an error")
 	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
+
+	// Receiving an expired voucher fails
+	expired := Voucher{ChannelId: channelId, Amount: big.NewInt(0).Set(triplePayment), Expiry: uint64(time.Now().Add(-time.Minute).Unix())}
+	Ok(t, expired.Sign(testactors.Alice.PrivateKey))
+	_, _, err = receiptMgr.Receive(expired)
+	Assert(t, errors.Is(err, ErrVoucherExpired), "expected the voucher to have expired, got %v", err)
+	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
+
+	// The expiry is signed over, so cannot be extended
+	expired.Expiry = uint64(time.Now().Add(time.Minute).Unix())
+	_, _, err = receiptMgr.Receive(expired)
+	Assert(t, err != nil, "expected an error")
+	Equals(t, twoPaymentsMade, getBalance(receiptMgr))
+
+	// Receiving an unexpired voucher succeeds
+	Ok(t, expired.Sign(testactors.Alice.PrivateKey))
+	received, delta, err = receiptMgr.Receive(expired)
+	Ok(t, err)
+	Equals(t, triplePayment, received)
+	Equals(t, payment, delta)
+
+	// An expiring voucher is signed once, over its expiry
+	expiry := time.Now().Add(time.Minute)
+	expirySigner := &countingSigner{KeySigner: KeySigner(testactors.Alice.PrivateKey)}
+	expiring, err := paymentMgr.PayWithExpiry(channelId, payment, expiry, expirySigner)
+	Ok(t, err)
+	Equals(t, int64(1), expirySigner.signed.Load())
+	Equals(t, uint64(expiry.Unix()), expiring.Expiry)
+	signer, err = expiring.RecoverSigner()
+	Ok(t, err)
+	Equals(t, testactors.Alice.Address(), signer)
+}
+
+func TestReplayProtection(t *testing.T) {
+	t.Run("VoucherManager", func(t *testing.T) {
+		testReplayProtection(t, func(me types.Address) VoucherManagerApi {
+			vm := NewVoucherManager(me, newSimpleVoucherStore())
+			vm.EnableReplayProtection()
+			return vm
+		})
+	})
+	t.Run("ShardedVoucherManager", func(t *testing.T) {
+		testReplayProtection(t, func(me types.Address) VoucherManagerApi {
+			vm := NewShardedVoucherManager(me, newSimpleVoucherStore())
+			vm.EnableReplayProtection()
+			return vm
+		})
+	})
+}
+
+func testReplayProtection(t *testing.T, newManager func(types.Address) VoucherManagerApi) {
+	channelId := types.Destination{1}
+	deposit, payment := big.NewInt(1000), big.NewInt(20)
+	paymentMgr, receiptMgr := newManager(testactors.Alice.Address()), newManager(testactors.Bob.Address())
+	Ok(t, paymentMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+	Ok(t, receiptMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+
+	// Payments carry increasing nonces
+	firstVoucher, err := paymentMgr.Pay(channelId, payment, KeySigner(testactors.Alice.PrivateKey))
+	Ok(t, err)
+	secondVoucher, err := paymentMgr.Pay(channelId, payment, KeySigner(testactors.Alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, uint64(1), firstVoucher.Nonce)
+	Equals(t, uint64(2), secondVoucher.Nonce)
+
+	_, delta, err := receiptMgr.Receive(firstVoucher)
+	Ok(t, err)
+	Equals(t, payment, delta)
+	_, delta, err = receiptMgr.Receive(secondVoucher)
+	Ok(t, err)
+	Equals(t, payment, delta)
+
+	// Vouchers received before are refused
+	_, _, err = receiptMgr.Receive(secondVoucher)
+	Assert(t, errors.Is(err, ErrVoucherReplayed), "expected the voucher to be replayed, got %v", err)
+	_, _, err = receiptMgr.Receive(firstVoucher)
+	Assert(t, errors.Is(err, ErrVoucherReplayed), "expected the voucher to be replayed, got %v", err)
+
+	// The nonce is signed over, so cannot be increased
+	replayed := secondVoucher
+	replayed.Nonce, replayed.Amount = 3, big.NewInt(60)
+	_, _, err = receiptMgr.Receive(replayed)
+	Assert(t, err != nil, "expected an error")
+
+	// Vouchers without a nonce are refused
+	unprotected := Voucher{ChannelId: channelId, Amount: big.NewInt(60)}
+	Ok(t, unprotected.Sign(testactors.Alice.PrivateKey))
+	_, _, err = receiptMgr.Receive(unprotected)
+	Assert(t, errors.Is(err, ErrVoucherNonceRequired), "expected the voucher to be refused, got %v", err)
+	paid, err := receiptMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(40), paid)
 }
 
 // TODO: This is a copy of the test helpers from github.com/statechannels/go-nitro/internal/testactors
@@ -0,0 +1,200 @@
+package remotesigner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// The signing service is served over gRPC as the service nitro.remotesigner.Signer, whose unary method Sign takes a
+// SignRequest and returns a SignResponse, and whose unary method Address takes an empty request and returns an
+// AddressResponse. Messages are encoded as json (with the content subtype "json"), as for the HTTP service, so that no
+// generated code is needed. The token is sent as "authorization: Bearer <token>" metadata.
+const (
+	grpcServiceName   = "nitro.remotesigner.Signer"
+	grpcSignMethod    = "/" + grpcServiceName + "/Sign"
+	grpcAddressMethod = "/" + grpcServiceName + "/Address"
+	authorizationKey  = "authorization"
+)
+
+// jsonCodec encodes gRPC messages as json.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+var grpcServiceDesc = grpc.ServiceDesc{
+	ServiceName: grpcServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Sign",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			var req SignRequest
+			if err := dec(&req); err != nil {
+				return nil, err
+			}
+			return srv.(*service).signGrpc(ctx, req)
+		},
+	}, {
+		MethodName: "Address",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+			var req struct{}
+			if err := dec(&req); err != nil {
+				return nil, err
+			}
+			return srv.(*service).addressGrpc(ctx)
+		},
+	}},
+}
+
+// authorizedGrpc returns true if the call presents the service's token.
+func (s *service) authorizedGrpc(ctx context.Context) bool {
+	presented := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md.Get(authorizationKey)) > 0 {
+		presented, _ = strings.CutPrefix(md.Get(authorizationKey)[0], "Bearer ")
+	}
+	return s.authorized(presented)
+}
+
+// signGrpc serves a Sign call.
+func (s *service) signGrpc(ctx context.Context, req SignRequest) (*SignResponse, error) {
+	if !s.authorizedGrpc(ctx) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	signed, err := s.sign(req)
+	switch {
+	case errors.Is(err, ErrRefused):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, errMalformed):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &signed, nil
+}
+
+// addressGrpc serves an Address call.
+func (s *service) addressGrpc(ctx context.Context) (*AddressResponse, error) {
+	if !s.authorizedGrpc(ctx) {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return &AddressResponse{Address: s.signer.Address()}, nil
+}
+
+// NewGrpcServer returns a gRPC server of a signing service, which signs the requests it is sent with signer, if policy
+// allows. Calls must present token as a bearer token. The server is configured with opts, such as credentials, and
+// must be started with Serve.
+func NewGrpcServer(signer engine.Signer, token string, policy Policy, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	s, err := newService(signer, token, policy)
+	if err != nil {
+		return nil, err
+	}
+	server := grpc.NewServer(append(opts, grpc.ForceServerCodec(jsonCodec{}))...)
+	server.RegisterService(&grpcServiceDesc, s)
+	return server, nil
+}
+
+// GrpcClient is an engine.Signer which asks a signing service, served by a GrpcServer, to sign over gRPC.
+type GrpcClient struct {
+	conn    *grpc.ClientConn
+	token   string
+	address types.Address
+}
+
+// NewGrpcClient returns a GrpcClient of the signing service at target (as host:port), presenting token as a bearer
+// token. The connection is configured with opts, which must include transport credentials. It asks the service for the
+// address of its key, so the service must be reachable.
+func NewGrpcClient(target string, token string, opts ...grpc.DialOption) (*GrpcClient, error) {
+	conn, err := grpc.Dial(target, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c := &GrpcClient{conn: conn, token: token}
+
+	var addressed AddressResponse
+	if err := c.invoke(grpcAddressMethod, &struct{}{}, &addressed); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("could not get the address of the signing service's key: %w", err)
+	}
+	c.address = addressed.Address
+	return c, nil
+}
+
+// Address returns the address of the signing service's key.
+func (c *GrpcClient) Address() types.Address {
+	return c.address
+}
+
+// SignState asks the signing service to sign the state.
+func (c *GrpcClient) SignState(s state.State) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: StateKind, State: &s})
+}
+
+// SignChallenge asks the signing service to sign the challenge message of the state.
+func (c *GrpcClient) SignChallenge(s state.State) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: ChallengeKind, State: &s})
+}
+
+// SignVoucher asks the signing service to sign the voucher.
+func (c *GrpcClient) SignVoucher(v payments.Voucher) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: VoucherKind, ChannelId: v.ChannelId, Amount: v.Amount, Expiry: v.Expiry, Nonce: v.Nonce})
+}
+
+// SignSnapshot asks the signing service to sign the channel snapshot.
+func (c *GrpcClient) SignSnapshot(cs payments.ChannelSnapshot) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: SnapshotKind, Snapshot: &cs})
+}
+
+// SignPeerRecord asks the signing service to sign the record which advertises the node's peer id.
+func (c *GrpcClient) SignPeerRecord(record []byte) ([]byte, error) {
+	var signed SignResponse
+	if err := c.invoke(grpcSignMethod, &SignRequest{Kind: PeerRecordKind, PeerRecord: record}, &signed); err != nil {
+		return nil, err
+	}
+	return signed.PeerRecordSignature, nil
+}
+
+// sign asks the signing service to sign the request.
+func (c *GrpcClient) sign(req SignRequest) (state.Signature, error) {
+	var signed SignResponse
+	if err := c.invoke(grpcSignMethod, &req, &signed); err != nil {
+		return state.Signature{}, err
+	}
+	return signed.Signature, nil
+}
+
+// invoke calls the method of the signing service.
+func (c *GrpcClient) invoke(method string, req any, response any) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+c.token)
+
+	err := c.conn.Invoke(ctx, method, req, response, grpc.ForceCodec(jsonCodec{}))
+	if status.Code(err) == codes.PermissionDenied {
+		return fmt.Errorf("%w: %s", ErrRefused, strings.TrimPrefix(status.Convert(err).Message(), ErrRefused.Error()+": "))
+	}
+	if err != nil {
+		return fmt.Errorf("signing service failed: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the signing service.
+func (c *GrpcClient) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,350 @@
+// Package remotesigner delegates the signatures of a node's channel key to a remote service over HTTP or gRPC, so that
+// the process holding channel state need not hold the key. The service, served by a Handler or a GrpcServer, holds the
+// key, and signs what the node asks it to sign if its Policy allows: the states of the node's channels and the
+// challenges which register them, the vouchers it pays with (such as within a SpendingLimit), the channel snapshots it
+// issues, and the record which advertises its peer id.
+//
+// A Client or a GrpcClient is a node.Signer. A node given one with node.WithSigner runs with a store opened for its
+// address, without the key (see store.StoreOpts).
+package remotesigner
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// ErrRefused is returned by a client when the signing service refuses to sign, as its policy does not allow it.
+	ErrRefused = types.ConstError("signing refused")
+	// errMalformed is returned by the signing service for requests it cannot make sense of
+	errMalformed = types.ConstError("malformed sign request")
+)
+
+// Kind is the kind of message the signing service is asked to sign.
+type Kind string
+
+const (
+	VoucherKind    Kind = "voucher"
+	StateKind      Kind = "state"
+	ChallengeKind  Kind = "challenge"
+	SnapshotKind   Kind = "snapshot"
+	PeerRecordKind Kind = "peer_record"
+)
+
+// SignRequest asks the signing service to sign a message of the given kind, which is carried by the fields for that
+// kind.
+type SignRequest struct {
+	// Kind is the kind of message to sign. It defaults to a voucher.
+	Kind Kind `json:",omitempty"`
+	// ChannelId, Amount, Expiry and Nonce are those of a voucher.
+	ChannelId types.Destination
+	Amount    *big.Int `json:",omitempty"`
+	Expiry    uint64   `json:",omitempty"`
+	Nonce     uint64   `json:",omitempty"`
+	// State is the state to sign, or to sign a challenge with.
+	State *state.State `json:",omitempty"`
+	// Snapshot is the channel snapshot to sign.
+	Snapshot *payments.ChannelSnapshot `json:",omitempty"`
+	// PeerRecord is the encoded record which advertises the node's peer id.
+	PeerRecord []byte `json:",omitempty"`
+}
+
+// SignResponse is the signature the signing service made. The signature on a peer record is returned as
+// PeerRecordSignature, in the format of engine.Signer.SignPeerRecord.
+type SignResponse struct {
+	Signature           state.Signature
+	PeerRecordSignature []byte `json:",omitempty"`
+}
+
+// AddressResponse is the address of the signing service's key.
+type AddressResponse struct {
+	Address types.Address
+}
+
+// VoucherPolicy decides whether the signing service signs a voucher, returning an error to refuse it.
+type VoucherPolicy func(v payments.Voucher) error
+
+// StatePolicy decides whether the signing service signs a state, or a challenge registering it on chain, returning an
+// error to refuse it. A state may move the funds of its channel, so that a client of a service which signs every
+// state can spend them, whatever its VoucherPolicy.
+type StatePolicy func(s state.State) error
+
+// Policy decides what the signing service signs. Neither of its policies may be nil: a service which is to sign every
+// state must be given AnyState, say.
+//
+// Channel snapshots are signed if they are consistent (see payments.ChannelSnapshot.Verify) and issued by the service's
+// key, and peer records if they are well formed.
+type Policy struct {
+	Vouchers VoucherPolicy
+	States   StatePolicy
+}
+
+// SpendingLimit is a VoucherPolicy which refuses to sign vouchers paying more than limit in total on any channel.
+func SpendingLimit(limit *big.Int) VoucherPolicy {
+	return func(v payments.Voucher) error {
+		if v.Amount.Cmp(limit) > 0 {
+			return fmt.Errorf("voucher pays %s, more than the limit of %s", v.Amount, limit)
+		}
+		return nil
+	}
+}
+
+// AnyState is a StatePolicy which signs every state.
+func AnyState(s state.State) error {
+	return nil
+}
+
+// service signs for the Handler and the GrpcServer.
+type service struct {
+	signer engine.Signer
+	token  string
+	policy Policy
+}
+
+// newService returns a service signing with signer, for clients presenting token, if policy allows.
+func newService(signer engine.Signer, token string, policy Policy) (*service, error) {
+	if token == "" {
+		return nil, errors.New("the signing service requires a token")
+	}
+	if policy.Vouchers == nil || policy.States == nil {
+		return nil, errors.New("the signing service requires a voucher policy and a state policy")
+	}
+	return &service{signer: signer, token: token, policy: policy}, nil
+}
+
+// authorized returns true if the presented token is the service's token.
+func (s *service) authorized(presented string) bool {
+	return subtle.ConstantTimeCompare([]byte(presented), []byte(s.token)) == 1
+}
+
+// sign signs the requested message, if the policy allows. The error wraps ErrRefused if the policy refuses it, and
+// errMalformed if the request is malformed.
+func (s *service) sign(req SignRequest) (SignResponse, error) {
+	refuse := func(reason error, attrs ...any) (SignResponse, error) {
+		slog.Warn("Refusing to sign", append([]any{"kind", req.Kind, "error", reason}, attrs...)...)
+		return SignResponse{}, fmt.Errorf("%w: %w", ErrRefused, reason)
+	}
+
+	switch req.Kind {
+	case VoucherKind, "":
+		if req.Amount == nil {
+			return SignResponse{}, errMalformed
+		}
+		v := payments.Voucher{ChannelId: req.ChannelId, Amount: req.Amount, Expiry: req.Expiry, Nonce: req.Nonce}
+		if err := s.policy.Vouchers(v); err != nil {
+			return refuse(err, "channel", v.ChannelId, "amount", v.Amount)
+		}
+		sig, err := s.signer.SignVoucher(v)
+		return SignResponse{Signature: sig}, err
+	case StateKind, ChallengeKind:
+		if req.State == nil {
+			return SignResponse{}, errMalformed
+		}
+		if err := s.policy.States(*req.State); err != nil {
+			return refuse(err, "channel", req.State.ChannelId(), "turnNum", req.State.TurnNum)
+		}
+		sign := s.signer.SignState
+		if req.Kind == ChallengeKind {
+			sign = s.signer.SignChallenge
+		}
+		sig, err := sign(*req.State)
+		return SignResponse{Signature: sig}, err
+	case SnapshotKind:
+		if req.Snapshot == nil {
+			return SignResponse{}, errMalformed
+		}
+		cs := *req.Snapshot
+		sig, err := s.signer.SignSnapshot(cs)
+		if err != nil {
+			return SignResponse{}, err
+		}
+		cs.Signature = sig
+		if issuer, err := cs.Verify(); err != nil {
+			return refuse(err)
+		} else if issuer != s.signer.Address() {
+			return refuse(fmt.Errorf("snapshot would be issued by %s", issuer))
+		}
+		return SignResponse{Signature: sig}, nil
+	case PeerRecordKind:
+		// The record may advertise a contract wallet the key signs for, rather than the key's own address
+		var record struct{ SCAddr, PeerID string }
+		if err := json.Unmarshal(req.PeerRecord, &record); err != nil || !common.IsHexAddress(record.SCAddr) || record.PeerID == "" {
+			return SignResponse{}, errMalformed
+		}
+		sig, err := s.signer.SignPeerRecord(req.PeerRecord)
+		return SignResponse{PeerRecordSignature: sig}, err
+	default:
+		return SignResponse{}, fmt.Errorf("%w: unknown kind %q", errMalformed, req.Kind)
+	}
+}
+
+// handler serves requests to sign over HTTP.
+type handler struct {
+	*service
+}
+
+// NewHandler returns the http.Handler of a signing service, which signs the requests POSTed to it with signer, if
+// policy allows, and answers a GET with the address of signer's key. Requests must present token as a bearer token.
+func NewHandler(signer engine.Signer, token string, policy Policy) (http.Handler, error) {
+	s, err := newService(signer, token, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &handler{s}, nil
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	presented, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !h.authorized(presented) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	var response any
+	switch r.Method {
+	case http.MethodGet:
+		response = AddressResponse{Address: h.signer.Address()}
+	case http.MethodPost:
+		var req SignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, errMalformed.Error(), http.StatusBadRequest)
+			return
+		}
+		signed, err := h.sign(req)
+		switch {
+		case errors.Is(err, ErrRefused):
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		case errors.Is(err, errMalformed):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		response = signed
+	default:
+		http.Error(w, "requests must be POSTed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Error("Could not write response", "error", err)
+	}
+}
+
+// Client is an engine.Signer which asks a signing service, served by a Handler, to sign over HTTP.
+type Client struct {
+	url        string
+	token      string
+	httpClient *http.Client
+	address    types.Address
+}
+
+// NewClient returns a Client of the signing service at url, presenting token as a bearer token. It asks the service
+// for the address of its key, so the service must be reachable.
+func NewClient(url string, token string) (*Client, error) {
+	c := &Client{url: url, token: token, httpClient: &http.Client{Timeout: 10 * time.Second}}
+	var addressed AddressResponse
+	if err := c.do(http.MethodGet, nil, &addressed); err != nil {
+		return nil, fmt.Errorf("could not get the address of the signing service's key: %w", err)
+	}
+	c.address = addressed.Address
+	return c, nil
+}
+
+// Address returns the address of the signing service's key.
+func (c *Client) Address() types.Address {
+	return c.address
+}
+
+// SignState asks the signing service to sign the state.
+func (c *Client) SignState(s state.State) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: StateKind, State: &s})
+}
+
+// SignChallenge asks the signing service to sign the challenge message of the state.
+func (c *Client) SignChallenge(s state.State) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: ChallengeKind, State: &s})
+}
+
+// SignVoucher asks the signing service to sign the voucher.
+func (c *Client) SignVoucher(v payments.Voucher) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: VoucherKind, ChannelId: v.ChannelId, Amount: v.Amount, Expiry: v.Expiry, Nonce: v.Nonce})
+}
+
+// SignSnapshot asks the signing service to sign the channel snapshot.
+func (c *Client) SignSnapshot(cs payments.ChannelSnapshot) (state.Signature, error) {
+	return c.sign(SignRequest{Kind: SnapshotKind, Snapshot: &cs})
+}
+
+// SignPeerRecord asks the signing service to sign the record which advertises the node's peer id.
+func (c *Client) SignPeerRecord(record []byte) ([]byte, error) {
+	var signed SignResponse
+	if err := c.do(http.MethodPost, SignRequest{Kind: PeerRecordKind, PeerRecord: record}, &signed); err != nil {
+		return nil, err
+	}
+	return signed.PeerRecordSignature, nil
+}
+
+// sign asks the signing service to sign the request.
+func (c *Client) sign(req SignRequest) (state.Signature, error) {
+	var signed SignResponse
+	if err := c.do(http.MethodPost, req, &signed); err != nil {
+		return state.Signature{}, err
+	}
+	return signed.Signature, nil
+}
+
+// do sends a request to the signing service, with body encoded if it is not nil, and decodes the response into
+// response.
+func (c *Client) do(method string, body any, response any) error {
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, c.url, bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach the signing service: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("%w: %s", ErrRefused, bytes.TrimPrefix(bytes.TrimSpace(message), []byte(ErrRefused.Error()+": ")))
+		}
+		return fmt.Errorf("signing service returned status %d: %s", resp.StatusCode, bytes.TrimSpace(message))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
+		return fmt.Errorf("could not decode the response of the signing service: %w", err)
+	}
+	return nil
+}
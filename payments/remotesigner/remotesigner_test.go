@@ -0,0 +1,173 @@
+package remotesigner
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	nc "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/payments"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// testPolicy signs vouchers up to a limit of 10, and states up to turn 5.
+var testPolicy = Policy{
+	Vouchers: SpendingLimit(big.NewInt(10)),
+	States: func(s state.State) error {
+		if s.TurnNum > 5 {
+			return errors.New("turn is too late")
+		}
+		return nil
+	},
+}
+
+func TestRemoteSigner(t *testing.T) {
+	handler, err := NewHandler(engine.KeySigner(testactors.Alice.PrivateKey), "secret", testPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client, err := NewClient(server.URL, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(server.URL, "wrong"); err == nil {
+		t.Error("expected a client presenting the wrong token to be unauthorized")
+	}
+	testRemoteSigner(t, client)
+}
+
+func TestGrpcRemoteSigner(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server, err := NewGrpcServer(engine.KeySigner(testactors.Alice.PrivateKey), "secret", testPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Stop()
+
+	client, err := NewGrpcClient(listener.Addr().String(), "secret", grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := NewGrpcClient(listener.Addr().String(), "wrong", grpc.WithTransportCredentials(insecure.NewCredentials())); err == nil {
+		t.Error("expected a client presenting the wrong token to be unauthorized")
+	}
+	testRemoteSigner(t, client)
+}
+
+func TestSigningServiceRequiresTokenAndPolicy(t *testing.T) {
+	signer := engine.KeySigner(testactors.Alice.PrivateKey)
+	for name, tc := range map[string]struct {
+		token  string
+		policy Policy
+	}{
+		"without a token":          {"", testPolicy},
+		"without a voucher policy": {"secret", Policy{States: AnyState}},
+		"without a state policy":   {"secret", Policy{Vouchers: SpendingLimit(big.NewInt(10))}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := NewHandler(signer, tc.token, tc.policy); err == nil {
+				t.Error("expected the handler to be refused")
+			}
+			if _, err := NewGrpcServer(signer, tc.token, tc.policy); err == nil {
+				t.Error("expected the gRPC server to be refused")
+			}
+		})
+	}
+}
+
+// testRemoteSigner tests a client of a signing service which signs with alice's key under testPolicy.
+func testRemoteSigner(t *testing.T, client engine.Signer) {
+	if client.Address() != testactors.Alice.Address() {
+		t.Fatalf("expected the client to sign for alice, got %s", client.Address())
+	}
+
+	t.Run("signs vouchers within the limit", func(t *testing.T) {
+		v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(10)}
+		if err := v.SignWith(client, testactors.Alice.Address()); err != nil {
+			t.Fatal(err)
+		}
+		signer, err := v.RecoverSigner()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer != testactors.Alice.Address() {
+			t.Errorf("expected the voucher to be signed by alice, got %s", signer)
+		}
+	})
+
+	t.Run("refuses vouchers over the limit", func(t *testing.T) {
+		v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(11)}
+		err := v.SignWith(client, testactors.Alice.Address())
+		if !errors.Is(err, ErrRefused) || !errors.Is(err, payments.ErrSigningFailed) {
+			t.Errorf("expected the voucher to be refused, got %v", err)
+		}
+	})
+
+	t.Run("checks the voucher signer", func(t *testing.T) {
+		v := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(1)}
+		err := v.SignWith(client, testactors.Bob.Address())
+		if !errors.Is(err, payments.ErrWrongSigner) {
+			t.Errorf("expected the signature to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("signs states and challenges the policy allows", func(t *testing.T) {
+		s := state.TestState.Clone()
+		sig, err := client.SignState(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer, _ := s.RecoverSigner(sig); signer != testactors.Alice.Address() {
+			t.Errorf("expected the state to be signed by alice, got %s", signer)
+		}
+
+		sig, err = client.SignChallenge(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash, err := s.ChallengeHash()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if signer, _ := nc.RecoverEthereumMessageSigner(hash[:], sig); signer != testactors.Alice.Address() {
+			t.Errorf("expected the challenge to be signed by alice, got %s", signer)
+		}
+	})
+
+	t.Run("refuses states the policy does not allow", func(t *testing.T) {
+		s := state.TestState.Clone()
+		s.TurnNum = 6
+		if _, err := client.SignState(s); !errors.Is(err, ErrRefused) {
+			t.Errorf("expected the state to be refused, got %v", err)
+		}
+		if _, err := client.SignChallenge(s); !errors.Is(err, ErrRefused) {
+			t.Errorf("expected the challenge to be refused, got %v", err)
+		}
+	})
+
+	t.Run("signs well formed peer records", func(t *testing.T) {
+		if _, err := client.SignPeerRecord([]byte(`{"PeerID":"peer"}`)); err == nil {
+			t.Error("expected a malformed peer record to be refused")
+		}
+		record := []byte(`{"SCAddr":"` + testactors.Alice.Address().String() + `","PeerID":"peer","Timestamp":1}`)
+		if _, err := client.SignPeerRecord(record); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -16,7 +16,7 @@ func TestSerde(t *testing.T) {
 		R: common.Hex2Bytes(`704b3afcc6e702102ca1af3f73cf3b37f3007f368c40e8b81ca823a65740a053`),
 		S: common.Hex2Bytes(`14040ad4c598dbb055a50430142a13518e1330b79d24eed86fcbdff1a7a95589`),
 		V: byte(0),
-	}}
+	}, "", 0, 0}
 
 	someVoucherJson := `{"ChannelId":"0x0100000000000000000000000000000000000000000000000000000000000000","Amount":2,"Signature":"0x704b3afcc6e702102ca1af3f73cf3b37f3007f368c40e8b81ca823a65740a05314040ad4c598dbb055a50430142a13518e1330b79d24eed86fcbdff1a7a9558900"}`
 
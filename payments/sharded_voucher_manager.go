@@ -0,0 +1,332 @@
+package payments
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// voucherShards is the number of shards a ShardedVoucherManager spreads channels over.
+const voucherShards = 64
+
+// ShardedVoucherManager receives and generates vouchers like VoucherManager, but keeps the voucher state of each channel
+// in memory, writing it through to its store. Channels are spread over shards by id, so that looking up different
+// channels rarely contends, and the state of each channel is replaced with a compare-and-swap rather than under a
+// lock. Payments on different channels therefore proceed in parallel, and vouchers received concurrently on one
+// channel never block each other (although they may have to retry). Payments on one channel are made one at a time, so
+// that each voucher is signed once.
+//
+// The state of a channel is read from the store when the channel is first used, so the store's voucher information
+// must not be modified by anything else while the manager is in use.
+type ShardedVoucherManager struct {
+	store  VoucherStore
+	me     common.Address
+	nonces bool
+	shards [voucherShards]voucherShard
+}
+
+var _ VoucherManagerApi = &ShardedVoucherManager{}
+
+type voucherShard struct {
+	mu       sync.RWMutex
+	channels map[types.Destination]*channelVouchers
+}
+
+// channelVouchers holds the voucher state of a channel. The VoucherInfo it points to is never modified, only
+// replaced. It points to nil once the channel has been removed.
+type channelVouchers struct {
+	info atomic.Pointer[VoucherInfo]
+	// payMu serializes the payments made on the channel, so that each voucher is signed once: a voucher signed for a
+	// state which a concurrent payment replaced would have to be signed again, which a remote signer enforcing a
+	// spending limit would count twice.
+	payMu sync.Mutex
+}
+
+// NewShardedVoucherManager creates a new sharded voucher manager
+func NewShardedVoucherManager(me types.Address, store VoucherStore) *ShardedVoucherManager {
+	vm := &ShardedVoucherManager{store: store, me: me}
+	for i := range vm.shards {
+		vm.shards[i].channels = make(map[types.Destination]*channelVouchers)
+	}
+	return vm
+}
+
+// EnableReplayProtection makes the manager give the vouchers it pays with increasing nonces, and refuse received
+// vouchers without one with ErrVoucherNonceRequired. Payees must support nonces to accept its vouchers. It must be
+// called before the manager is used.
+func (vm *ShardedVoucherManager) EnableReplayProtection() {
+	vm.nonces = true
+}
+
+func (vm *ShardedVoucherManager) shard(channelId types.Destination) *voucherShard {
+	// Channel ids are hashes, so any byte is uniformly distributed
+	return &vm.shards[int(channelId[len(channelId)-1])%voucherShards]
+}
+
+// channel returns the voucher state of the channel, loading it from the store if it is not in memory.
+func (vm *ShardedVoucherManager) channel(channelId types.Destination) (*channelVouchers, error) {
+	s := vm.shard(channelId)
+	s.mu.RLock()
+	c, ok := s.channels[channelId]
+	s.mu.RUnlock()
+	if ok {
+		return c, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c, ok := s.channels[channelId]; ok {
+		return c, nil
+	}
+	info, err := vm.store.GetVoucherInfo(channelId)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
+	}
+	c = &channelVouchers{}
+	c.info.Store(info)
+	s.channels[channelId] = c
+	return c, nil
+}
+
+// info returns the current voucher state of the channel.
+func (vm *ShardedVoucherManager) info(channelId types.Destination) (*VoucherInfo, error) {
+	c, err := vm.channel(channelId)
+	if err != nil {
+		return nil, err
+	}
+	info := c.info.Load()
+	if info == nil {
+		return nil, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	return info, nil
+}
+
+// update replaces the channel's voucher state with the one computed by next from the current state, retrying if
+// another update replaces the state first. If next returns nil, the state is left unchanged.
+func (vm *ShardedVoucherManager) update(channelId types.Destination, next func(current *VoucherInfo) (*VoucherInfo, error)) error {
+	c, err := vm.channel(channelId)
+	if err != nil {
+		return err
+	}
+	for {
+		current := c.info.Load()
+		if current == nil {
+			return fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+		}
+		updated, err := next(current)
+		if err != nil || updated == nil {
+			return err
+		}
+		if c.info.CompareAndSwap(current, updated) {
+			return vm.commit(channelId, c, current, updated)
+		}
+	}
+}
+
+// commit persists the channel's voucher state, which has replaced current in memory. If it cannot be persisted, the
+// state in memory is rolled back to current; or, if a concurrent update has already replaced it, the channel is
+// dropped from memory, so that its state is next read from the store, which holds the latest state written.
+func (vm *ShardedVoucherManager) commit(channelId types.Destination, c *channelVouchers, current, updated *VoucherInfo) error {
+	err := vm.persist(channelId, c, updated)
+	if err == nil {
+		return nil
+	}
+	if !c.info.CompareAndSwap(updated, current) {
+		s := vm.shard(channelId)
+		s.mu.Lock()
+		if s.channels[channelId] == c {
+			delete(s.channels, channelId)
+		}
+		s.mu.Unlock()
+	}
+	return err
+}
+
+// persist writes the channel's voucher state to the store. Since updates are not serialized, a concurrent update may
+// have written a later state first: so once written, the state is rewritten until the store holds the current state.
+func (vm *ShardedVoucherManager) persist(channelId types.Destination, c *channelVouchers, written *VoucherInfo) error {
+	for {
+		if err := vm.store.SetVoucherInfo(channelId, *written); err != nil {
+			return err
+		}
+		current := c.info.Load()
+		if current == written {
+			return nil
+		}
+		if current == nil {
+			// The channel was removed while we were writing. Remove may have failed to find our write, so we repeat it.
+			_ = vm.store.RemoveVoucherInfo(channelId)
+			return nil
+		}
+		written = current
+	}
+}
+
+// Register registers a channel for use, given the asset it holds, its payer, payee and starting balance
+func (vm *ShardedVoucherManager) Register(channelId types.Destination, asset common.Address, payer common.Address, payee common.Address, startingBalance *big.Int) error {
+	s := vm.shard(channelId)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.channels[channelId]; ok {
+		return fmt.Errorf("channel already registered")
+	}
+	if v, _ := vm.store.GetVoucherInfo(channelId); v != nil {
+		return fmt.Errorf("channel already registered")
+	}
+
+	info := &VoucherInfo{payer, payee, big.NewInt(0).Set(startingBalance), Voucher{ChannelId: channelId, Amount: big.NewInt(0)}, asset}
+	if err := vm.store.SetVoucherInfo(channelId, *info); err != nil {
+		return err
+	}
+	c := &channelVouchers{}
+	c.info.Store(info)
+	s.channels[channelId] = c
+	return nil
+}
+
+// Remove deletes the channel's status
+func (vm *ShardedVoucherManager) Remove(channelId types.Destination) error {
+	s := vm.shard(channelId)
+	s.mu.Lock()
+	c, ok := s.channels[channelId]
+	delete(s.channels, channelId)
+	s.mu.Unlock()
+
+	if ok {
+		c.info.Store(nil)
+	}
+	return vm.store.RemoveVoucherInfo(channelId)
+}
+
+// Pay will deduct amount from balance and add it to paid, returning a signed voucher for the
+// total amount paid.
+func (vm *ShardedVoucherManager) Pay(channelId types.Destination, amount *big.Int, signer VoucherSigner) (Voucher, error) {
+	return vm.PayWithExpiry(channelId, amount, time.Time{}, signer)
+}
+
+// PayWithExpiry is like Pay, but the voucher expires at expiry, unless it is zero.
+func (vm *ShardedVoucherManager) PayWithExpiry(channelId types.Destination, amount *big.Int, expiry time.Time, signer VoucherSigner) (Voucher, error) {
+	c, err := vm.channel(channelId)
+	if err != nil {
+		return Voucher{}, err
+	}
+	c.payMu.Lock()
+	defer c.payMu.Unlock()
+
+	current := c.info.Load()
+	if current == nil {
+		return Voucher{}, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	if types.Gt(amount, current.Remaining()) {
+		return Voucher{}, fmt.Errorf("unable to pay amount: %w", ErrInsufficientFunds)
+	}
+	if current.ChannelPayer != vm.me {
+		return Voucher{}, fmt.Errorf("can only sign vouchers if we're the payer")
+	}
+
+	voucher := Voucher{Amount: big.NewInt(0).Add(current.LargestVoucher.Amount, amount), ChannelId: channelId}
+	if !expiry.IsZero() {
+		voucher.Expiry = uint64(expiry.Unix())
+	}
+	if vm.nonces {
+		voucher.Nonce = current.LargestVoucher.Nonce + 1
+	}
+	if err := voucher.SignWith(signer, vm.me); err != nil {
+		return Voucher{}, err
+	}
+	updated := *current
+	updated.LargestVoucher = voucher.clone()
+	// Only payments replace the payer's state, so the state can only have been replaced by the channel's removal
+	if !c.info.CompareAndSwap(current, &updated) {
+		return Voucher{}, fmt.Errorf("%w: %s", ErrChannelNotRegistered, channelId)
+	}
+	if err := vm.commit(channelId, c, current, &updated); err != nil {
+		return Voucher{}, err
+	}
+	return voucher, nil
+}
+
+// Receive validates the incoming voucher, and returns the total amount received so far as well as the amount received from the voucher
+func (vm *ShardedVoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.Int, err error) {
+	var signer *common.Address
+	err = vm.update(voucher.ChannelId, func(current *VoucherInfo) (*VoucherInfo, error) {
+		// We only care about vouchers when we are the recipient of the payment
+		if current.ChannelPayee != vm.me {
+			return nil, fmt.Errorf("can only receive vouchers if we're the payee")
+		}
+		if voucher.Expired(time.Now()) {
+			return nil, fmt.Errorf("%w at %d", ErrVoucherExpired, voucher.Expiry)
+		}
+		if err := voucher.checkNonce(current.LargestVoucher, vm.nonces); err != nil {
+			return nil, err
+		}
+		if types.Gt(voucher.Amount, current.StartingBalance) {
+			return nil, fmt.Errorf("channel has %w", ErrInsufficientFunds)
+		}
+
+		largest := current.LargestVoucher.Amount
+		if !types.Gt(voucher.Amount, largest) {
+			total, delta = big.NewInt(0).Set(largest), big.NewInt(0)
+			return nil, nil
+		}
+
+		if signer == nil {
+			recovered, err := voucher.RecoverSigner()
+			if err != nil {
+				return nil, err
+			}
+			signer = &recovered
+		}
+		if *signer != current.ChannelPayer {
+			return nil, fmt.Errorf("wrong signer: %+v, %+v", *signer, current.ChannelPayer)
+		}
+
+		total, delta = big.NewInt(0).Set(voucher.Amount), big.NewInt(0).Sub(voucher.Amount, largest)
+		updated := *current
+		updated.LargestVoucher = voucher.clone()
+		return &updated, nil
+	})
+	if err != nil {
+		return &big.Int{}, &big.Int{}, err
+	}
+	return total, delta, nil
+}
+
+// ChannelRegistered returns  whether a channel has been registered with the voucher manager or not
+func (vm *ShardedVoucherManager) ChannelRegistered(channelId types.Destination) bool {
+	_, err := vm.info(channelId)
+	return err == nil
+}
+
+// Paid returns the total amount paid so far on a channel
+func (vm *ShardedVoucherManager) Paid(chanId types.Destination) (*big.Int, error) {
+	info, err := vm.info(chanId)
+	if err != nil {
+		return &big.Int{}, err
+	}
+	return big.NewInt(0).Set(info.LargestVoucher.Amount), nil
+}
+
+// Remaining returns the remaining amount of funds in the channel
+func (vm *ShardedVoucherManager) Remaining(chanId types.Destination) (*big.Int, error) {
+	info, err := vm.info(chanId)
+	if err != nil {
+		return &big.Int{}, err
+	}
+	return info.Remaining(), nil
+}
+
+// Asset returns the asset the channel's payments are made in
+func (vm *ShardedVoucherManager) Asset(chanId types.Destination) (common.Address, error) {
+	info, err := vm.info(chanId)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return info.Asset, nil
+}
@@ -0,0 +1,247 @@
+package payments
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestShardedVoucherManagerConcurrentPayments(t *testing.T) {
+	const payers, payments = 8, 50
+	channelId := types.Destination{1}
+	deposit := big.NewInt(payers * payments)
+
+	store := newSimpleVoucherStore()
+	paymentMgr := NewShardedVoucherManager(testactors.Alice.Address(), store)
+	receiptMgr := NewShardedVoucherManager(testactors.Bob.Address(), newSimpleVoucherStore())
+	Ok(t, paymentMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+	Ok(t, receiptMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), deposit))
+
+	vouchers := make(chan Voucher, payers*payments)
+	wg := sync.WaitGroup{}
+	for i := 0; i < payers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < payments; j++ {
+				v, err := paymentMgr.Pay(channelId, big.NewInt(1), KeySigner(testactors.Alice.PrivateKey))
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				vouchers <- v
+			}
+		}()
+	}
+	wg.Wait()
+	close(vouchers)
+
+	// Every payment was made exactly once, so the deposit is exhausted
+	_, err := paymentMgr.Pay(channelId, big.NewInt(1), KeySigner(testactors.Alice.PrivateKey))
+	Assert(t, err != nil, "expected the channel to be exhausted")
+	stored, err := store.GetVoucherInfo(channelId)
+	Ok(t, err)
+	Equals(t, deposit, stored.Paid())
+
+	// Receiving the vouchers concurrently credits each amount exactly once
+	received := make(chan *big.Int, payers*payments)
+	for v := range vouchers {
+		wg.Add(1)
+		go func(v Voucher) {
+			defer wg.Done()
+			_, delta, err := receiptMgr.Receive(v)
+			if err != nil {
+				t.Error(err)
+			}
+			received <- delta
+		}(v)
+	}
+	wg.Wait()
+	close(received)
+	sum := big.NewInt(0)
+	for delta := range received {
+		sum.Add(sum, delta)
+	}
+	Equals(t, deposit, sum)
+	paid, err := receiptMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, deposit, paid)
+}
+
+func TestShardedVoucherManagerLoadsFromStore(t *testing.T) {
+	channelId := types.Destination{1}
+	store := newSimpleVoucherStore()
+
+	first := NewShardedVoucherManager(testactors.Alice.Address(), store)
+	Ok(t, first.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(10)))
+	_, err := first.Pay(channelId, big.NewInt(3), KeySigner(testactors.Alice.PrivateKey))
+	Ok(t, err)
+
+	// A manager using the same store, as after a restart, sees the payment
+	second := NewShardedVoucherManager(testactors.Alice.Address(), store)
+	remaining, err := second.Remaining(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(7), remaining)
+
+	Ok(t, second.Remove(channelId))
+	Assert(t, !second.ChannelRegistered(channelId), "expected the channel to be removed")
+	_, err = store.GetVoucherInfo(channelId)
+	Assert(t, err != nil, "expected the channel to be removed from the store")
+}
+
+// countingSigner signs vouchers with a key, counting the vouchers it signs.
+type countingSigner struct {
+	KeySigner
+	signed atomic.Int64
+}
+
+func (cs *countingSigner) SignVoucher(v Voucher) (state.Signature, error) {
+	cs.signed.Add(1)
+	return cs.KeySigner.SignVoucher(v)
+}
+
+func TestShardedVoucherManagerSignsEachPaymentOnce(t *testing.T) {
+	const payers, payments = 8, 20
+	channelId := types.Destination{1}
+	paymentMgr := NewShardedVoucherManager(testactors.Alice.Address(), newSimpleVoucherStore())
+	Ok(t, paymentMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(payers*payments)))
+
+	signer := &countingSigner{KeySigner: KeySigner(testactors.Alice.PrivateKey)}
+	wg := sync.WaitGroup{}
+	for i := 0; i < payers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < payments; j++ {
+				if _, err := paymentMgr.Pay(channelId, big.NewInt(1), signer); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	Equals(t, int64(payers*payments), signer.signed.Load())
+}
+
+// failingVoucherStore fails to write voucher information while failing is set.
+type failingVoucherStore struct {
+	VoucherStore
+	failing atomic.Bool
+}
+
+func (s *failingVoucherStore) SetVoucherInfo(channelId types.Destination, v VoucherInfo) error {
+	if s.failing.Load() {
+		return fmt.Errorf("store unavailable")
+	}
+	return s.VoucherStore.SetVoucherInfo(channelId, v)
+}
+
+func TestShardedVoucherManagerRollsBackUnpersistedPayments(t *testing.T) {
+	channelId := types.Destination{1}
+	store := &failingVoucherStore{VoucherStore: newSimpleVoucherStore()}
+	paymentMgr := NewShardedVoucherManager(testactors.Alice.Address(), store)
+	Ok(t, paymentMgr.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(10)))
+
+	store.failing.Store(true)
+	_, err := paymentMgr.Pay(channelId, big.NewInt(3), KeySigner(testactors.Alice.PrivateKey))
+	Assert(t, err != nil, "expected the payment to fail")
+	paid, err := paymentMgr.Paid(channelId)
+	Ok(t, err)
+	Equals(t, big.NewInt(0), paid)
+
+	store.failing.Store(false)
+	v, err := paymentMgr.Pay(channelId, big.NewInt(3), KeySigner(testactors.Alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, big.NewInt(3), v.Amount)
+}
+
+// jsonVoucherStore mimics the stores in the store package: it serializes voucher information, under a single lock.
+type jsonVoucherStore struct {
+	mu       sync.RWMutex
+	vouchers map[types.Destination][]byte
+}
+
+func (s *jsonVoucherStore) SetVoucherInfo(channelId types.Destination, v VoucherInfo) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vouchers[channelId] = data
+	return nil
+}
+
+func (s *jsonVoucherStore) GetVoucherInfo(channelId types.Destination) (*VoucherInfo, error) {
+	s.mu.RLock()
+	data, ok := s.vouchers[channelId]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("channelId %s not found", channelId)
+	}
+	v := &VoucherInfo{}
+	return v, json.Unmarshal(data, v)
+}
+
+func (s *jsonVoucherStore) RemoveVoucherInfo(channelId types.Destination) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.vouchers, channelId)
+	return nil
+}
+
+// benchmarkReceive receives pre-signed vouchers, in parallel, on 256 channels. Receiving a new voucher is dominated by
+// recovering its signer, which neither manager does under a lock, so the vouchers are received repeatedly (as when
+// clients retry) to measure the cost of reading and updating the voucher state of each channel.
+func benchmarkReceive(b *testing.B, newManager func(types.Address, VoucherStore) VoucherManagerApi) {
+	const channels = 256
+	const vouchersPerChannel = 64
+	m := newManager(testactors.Bob.Address(), &jsonVoucherStore{vouchers: make(map[types.Destination][]byte)})
+
+	vouchers := make([][]Voucher, channels)
+	for i := range vouchers {
+		channelId := types.Destination{byte(i), byte(i >> 8), 1}
+		Ok(b, m.Register(channelId, types.Address{}, testactors.Alice.Address(), testactors.Bob.Address(), big.NewInt(1<<62)))
+		for j := 1; j <= vouchersPerChannel; j++ {
+			v := Voucher{ChannelId: channelId, Amount: big.NewInt(int64(j))}
+			Ok(b, v.Sign(testactors.Alice.PrivateKey))
+			vouchers[i] = append(vouchers[i], v)
+		}
+	}
+
+	var next sync.Mutex
+	channel := 0
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		next.Lock()
+		mine := vouchers[channel%channels]
+		channel++
+		next.Unlock()
+		i := 0
+		for pb.Next() {
+			if _, _, err := m.Receive(mine[i%len(mine)]); err != nil {
+				b.Error(err)
+				return
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkVoucherManagerReceive(b *testing.B) {
+	benchmarkReceive(b, func(me types.Address, store VoucherStore) VoucherManagerApi { return NewVoucherManager(me, store) })
+}
+
+func BenchmarkShardedVoucherManagerReceive(b *testing.B) {
+	benchmarkReceive(b, func(me types.Address, store VoucherStore) VoucherManagerApi {
+		return NewShardedVoucherManager(me, store)
+	})
+}
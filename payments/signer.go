@@ -0,0 +1,70 @@
+package payments
+
+import (
+	"fmt"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	nitroCrypto "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	// ErrSigningFailed is wrapped by the errors returned when a VoucherSigner cannot sign a voucher, as when a remote
+	// signer is unreachable or refuses to sign it.
+	ErrSigningFailed = types.ConstError("could not sign voucher")
+	// ErrWrongSigner is returned when a voucher is signed by someone other than the payer of its channel, as when a
+	// remote signer holds the wrong key.
+	ErrWrongSigner = types.ConstError("voucher signed by the wrong key")
+)
+
+// VoucherSigner signs the vouchers we pay with. It lets the key vouchers are signed with be held outside the process
+// holding channel state, by a service which may enforce its own policy (such as a spending limit) before signing it:
+// see package remotesigner.
+type VoucherSigner interface {
+	// SignVoucher returns the payer's signature over the hash of the voucher.
+	SignVoucher(v Voucher) (state.Signature, error)
+}
+
+// SnapshotSigner signs the channel snapshots we issue.
+type SnapshotSigner interface {
+	// SignSnapshot returns the issuer's signature over the hash of the snapshot. The snapshot's own Signature is
+	// ignored.
+	SignSnapshot(cs ChannelSnapshot) (state.Signature, error)
+}
+
+// KeySigner signs vouchers and channel snapshots with a secret key held in memory.
+type KeySigner []byte
+
+// SignVoucher signs the voucher with the key.
+func (pk KeySigner) SignVoucher(v Voucher) (state.Signature, error) {
+	if err := v.Sign(pk); err != nil {
+		return state.Signature{}, err
+	}
+	return v.Signature, nil
+}
+
+// SignSnapshot signs the snapshot with the key.
+func (pk KeySigner) SignSnapshot(cs ChannelSnapshot) (state.Signature, error) {
+	hash, err := cs.hash()
+	if err != nil {
+		return state.Signature{}, err
+	}
+	return nitroCrypto.Sign(hash.Bytes(), pk)
+}
+
+// SignWith signs the voucher with the signer, checking that the signature is the payer's.
+func (v *Voucher) SignWith(signer VoucherSigner, payer types.Address) error {
+	sig, err := signer.SignVoucher(*v)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrSigningFailed, err)
+	}
+	v.Signature = sig
+	recovered, err := v.RecoverSigner()
+	if err != nil {
+		return err
+	}
+	if recovered != payer {
+		return fmt.Errorf("%w: %s, not the payer %s", ErrWrongSigner, recovered, payer)
+	}
+	return nil
+}
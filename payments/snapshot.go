@@ -0,0 +1,125 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	nitroAbi "github.com/statechannels/go-nitro/abi"
+	"github.com/statechannels/go-nitro/channel/state"
+	nitroCrypto "github.com/statechannels/go-nitro/crypto"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// A ChannelSnapshot proves the standing of a payment channel to a third party, such as an auditor, without access to
+// either participant's node. It contains the latest supported state of the channel, signed by every participant, and
+// the largest voucher signed by the payer. The snapshot is itself signed by the participant which issued it.
+type ChannelSnapshot struct {
+	State     state.SignedState
+	Voucher   Voucher
+	IssuedAt  time.Time
+	Signature state.Signature
+}
+
+// NewChannelSnapshot returns a ChannelSnapshot of the given supported state and voucher, signed by signer.
+func NewChannelSnapshot(ss state.SignedState, voucher Voucher, issuedAt time.Time, signer SnapshotSigner) (ChannelSnapshot, error) {
+	cs := ChannelSnapshot{State: ss, Voucher: voucher, IssuedAt: issuedAt.UTC().Truncate(time.Second)}
+	sig, err := signer.SignSnapshot(cs)
+	if err != nil {
+		return ChannelSnapshot{}, err
+	}
+	cs.Signature = sig
+	return cs, nil
+}
+
+// hash returns the hash signed by the issuer, which commits to the state, the voucher and the time of issue.
+func (cs ChannelSnapshot) hash() (types.Bytes32, error) {
+	stateHash, err := cs.State.State().Hash()
+	if err != nil {
+		return types.Bytes32{}, err
+	}
+	voucherHash, err := cs.Voucher.Hash()
+	if err != nil {
+		return types.Bytes32{}, err
+	}
+	encoded, err := abi.Arguments{
+		{Type: nitroAbi.Bytes32},
+		{Type: nitroAbi.Bytes32},
+		{Type: nitroAbi.Uint256},
+	}.Pack(stateHash, voucherHash, big.NewInt(cs.IssuedAt.Unix()))
+	if err != nil {
+		return types.Bytes32{}, fmt.Errorf("failed to encode channel snapshot: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// Paid returns the amount the payer has paid to the payee, according to the snapshot's voucher.
+func (cs ChannelSnapshot) Paid() *big.Int {
+	if cs.Voucher.Amount == nil {
+		return big.NewInt(0)
+	}
+	return new(big.Int).Set(cs.Voucher.Amount)
+}
+
+// Remaining returns the amount the payer may still pay to the payee, according to the snapshot.
+func (cs ChannelSnapshot) Remaining() *big.Int {
+	return new(big.Int).Sub(cs.startingBalance(), cs.Paid())
+}
+
+// startingBalance returns the payer's allocation in the snapshot's state.
+func (cs ChannelSnapshot) startingBalance() *big.Int {
+	outcome := cs.State.State().Outcome
+	if len(outcome) == 0 || len(outcome[0].Allocations) == 0 {
+		return big.NewInt(0)
+	}
+	return outcome[0].Allocations[0].Amount
+}
+
+// Verify checks that the snapshot is internally consistent and correctly signed:
+//   - the state is signed by every participant,
+//   - the voucher is for the same channel, signed by the payer, and does not exceed the payer's balance,
+//   - the snapshot is signed by one of the participants, whose address is returned.
+func (cs ChannelSnapshot) Verify() (issuer types.Address, err error) {
+	s := cs.State.State()
+	if len(s.Participants) < 2 {
+		return types.Address{}, errors.New("state has fewer than two participants")
+	}
+	sigs := cs.State.Signatures()
+	for i, p := range s.Participants {
+		if err := s.VerifySignature(sigs[i], p); err != nil {
+			return types.Address{}, fmt.Errorf("state is not signed by participant %d (%s)", i, p)
+		}
+	}
+
+	if cs.Voucher.ChannelId != s.ChannelId() {
+		return types.Address{}, fmt.Errorf("voucher is for channel %s, not %s", cs.Voucher.ChannelId, s.ChannelId())
+	}
+	if cs.Paid().Sign() > 0 {
+		payer := s.Participants[0]
+		signer, err := cs.Voucher.RecoverSigner()
+		if err != nil || signer != payer {
+			return types.Address{}, fmt.Errorf("voucher is not signed by the payer %s", payer)
+		}
+	}
+	if cs.Remaining().Sign() < 0 {
+		return types.Address{}, fmt.Errorf("voucher amount %s exceeds the payer's balance %s", cs.Paid(), cs.startingBalance())
+	}
+
+	hash, err := cs.hash()
+	if err != nil {
+		return types.Address{}, err
+	}
+	issuer, err = nitroCrypto.RecoverSigner(hash[:], cs.Signature)
+	if err != nil {
+		return types.Address{}, err
+	}
+	for _, p := range s.Participants {
+		if p == issuer {
+			return issuer, nil
+		}
+	}
+	return types.Address{}, fmt.Errorf("snapshot is signed by %s, who is not a participant", issuer)
+}
@@ -0,0 +1,83 @@
+package payments
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestChannelSnapshot(t *testing.T) {
+	alice, bob := testactors.Alice, testactors.Bob
+
+	s := state.State{
+		Participants:      []types.Address{alice.Address(), bob.Address()},
+		ChannelNonce:      1,
+		ChallengeDuration: 60,
+		Outcome: outcome.Exit{{
+			Allocations: outcome.Allocations{
+				{Destination: alice.Destination(), Amount: big.NewInt(10)},
+				{Destination: bob.Destination(), Amount: big.NewInt(0)},
+			},
+		}},
+		TurnNum: 1,
+	}
+	ss := state.NewSignedState(s)
+	for _, actor := range []testactors.Actor{alice, bob} {
+		sig, err := s.Sign(actor.PrivateKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ss.AddSignature(sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	voucher := Voucher{ChannelId: s.ChannelId(), Amount: big.NewInt(3)}
+	if err := voucher.Sign(alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := NewChannelSnapshot(ss, voucher, time.Now(), KeySigner(bob.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer, err := snapshot.Verify()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issuer != bob.Address() {
+		t.Errorf("expected the snapshot to be issued by %s, got %s", bob.Address(), issuer)
+	}
+	if snapshot.Paid().Cmp(big.NewInt(3)) != 0 || snapshot.Remaining().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("expected 3 paid and 7 remaining, got %s and %s", snapshot.Paid(), snapshot.Remaining())
+	}
+
+	tampered := snapshot
+	tampered.Voucher.Amount = big.NewInt(4)
+	if _, err := tampered.Verify(); err == nil {
+		t.Error("expected a snapshot with a tampered voucher to fail verification")
+	}
+
+	backdated := snapshot
+	backdated.IssuedAt = snapshot.IssuedAt.Add(-time.Hour)
+	if _, err := backdated.Verify(); err == nil {
+		t.Error("expected a snapshot with a tampered issue time to fail verification")
+	}
+
+	overpaid := Voucher{ChannelId: s.ChannelId(), Amount: big.NewInt(11)}
+	if err := overpaid.Sign(alice.PrivateKey); err != nil {
+		t.Fatal(err)
+	}
+	overpaidSnapshot, err := NewChannelSnapshot(ss, overpaid, time.Now(), KeySigner(bob.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := overpaidSnapshot.Verify(); err == nil {
+		t.Error("expected a snapshot with a voucher exceeding the payer's balance to fail verification")
+	}
+}
@@ -3,11 +3,20 @@ package payments
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/types"
 )
 
+const (
+	ErrChannelNotRegistered = types.ConstError("channel not registered")
+	ErrInsufficientFunds    = types.ConstError("insufficient funds")
+	ErrVoucherExpired       = types.ConstError("voucher expired")
+	ErrVoucherReplayed      = types.ConstError("voucher replayed")
+	ErrVoucherNonceRequired = types.ConstError("voucher has no nonce")
+)
+
 // VoucherStore is an interface for storing voucher information that the voucher manager expects.
 // To avoid import cycles, this interface is defined in the payments package, but implemented in the store package.
 type VoucherStore interface {
@@ -16,22 +25,57 @@ type VoucherStore interface {
 	RemoveVoucherInfo(channelId types.Destination) error
 }
 
-// VoucherInfo stores the status of payments for a given payment channel.
+// VoucherManagerApi is the interface through which a node's engine, queries and notifications track the payments made
+// through payment channels. VoucherManager implements it; applications may substitute a fake in their tests.
+type VoucherManagerApi interface {
+	// Register registers a channel for use, given the asset it holds, its payer, payee and starting balance
+	Register(channelId types.Destination, asset common.Address, payer common.Address, payee common.Address, startingBalance *big.Int) error
+	// Remove deletes the channel's status
+	Remove(channelId types.Destination) error
+	// Pay deducts amount from the channel's balance, returning a voucher for the total amount paid, signed by signer
+	Pay(channelId types.Destination, amount *big.Int, signer VoucherSigner) (Voucher, error)
+	// PayWithExpiry is like Pay, but the voucher expires at expiry, unless it is zero. The expiry is signed with the
+	// voucher, so the voucher is signed once.
+	PayWithExpiry(channelId types.Destination, amount *big.Int, expiry time.Time, signer VoucherSigner) (Voucher, error)
+	// Receive validates the incoming voucher, and returns the total amount received so far and the amount received from the voucher.
+	// Expired vouchers are refused with ErrVoucherExpired, and vouchers whose nonce is not greater than the nonce of
+	// the largest voucher received with ErrVoucherReplayed.
+	Receive(voucher Voucher) (total *big.Int, delta *big.Int, err error)
+	// ChannelRegistered returns whether a channel has been registered
+	ChannelRegistered(channelId types.Destination) bool
+	// Paid returns the total amount paid so far on a channel
+	Paid(chanId types.Destination) (*big.Int, error)
+	// Remaining returns the remaining amount of funds in the channel
+	Remaining(chanId types.Destination) (*big.Int, error)
+	// Asset returns the asset the channel's payments are made in, the zero address being the chain's native token
+	Asset(chanId types.Destination) (common.Address, error)
+}
+
+var _ VoucherManagerApi = &VoucherManager{}
+
 // VoucherManager receives and generates vouchers. It is responsible for storing vouchers.
 type VoucherManager struct {
-	store VoucherStore
-	me    common.Address
+	store  VoucherStore
+	me     common.Address
+	nonces bool
 }
 
 // NewVoucherManager creates a new voucher manager
 func NewVoucherManager(me types.Address, store VoucherStore) *VoucherManager {
-	return &VoucherManager{store, me}
+	return &VoucherManager{store: store, me: me}
 }
 
-// Register registers a channel for use, given the payer, payee and starting balance of the channel
-func (vm *VoucherManager) Register(channelId types.Destination, payer common.Address, payee common.Address, startingBalance *big.Int) error {
+// EnableReplayProtection makes the manager give the vouchers it pays with increasing nonces, and refuse received
+// vouchers without one with ErrVoucherNonceRequired. Payees must support nonces to accept its vouchers. It must be
+// called before the manager is used.
+func (vm *VoucherManager) EnableReplayProtection() {
+	vm.nonces = true
+}
+
+// Register registers a channel for use, given the asset it holds, its payer, payee and starting balance
+func (vm *VoucherManager) Register(channelId types.Destination, asset common.Address, payer common.Address, payee common.Address, startingBalance *big.Int) error {
 	voucher := Voucher{ChannelId: channelId, Amount: big.NewInt(0)}
-	data := VoucherInfo{payer, payee, big.NewInt(0).Set(startingBalance), voucher}
+	data := VoucherInfo{payer, payee, big.NewInt(0).Set(startingBalance), voucher, asset}
 
 	if v, _ := vm.store.GetVoucherInfo(channelId); v != nil {
 		return fmt.Errorf("channel already registered")
@@ -50,14 +94,19 @@ func (vm *VoucherManager) Remove(channelId types.Destination) error {
 
 // Pay will deduct amount from balance and add it to paid, returning a signed voucher for the
 // total amount paid.
-func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk []byte) (Voucher, error) {
+func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, signer VoucherSigner) (Voucher, error) {
+	return vm.PayWithExpiry(channelId, amount, time.Time{}, signer)
+}
+
+// PayWithExpiry is like Pay, but the voucher expires at expiry, unless it is zero.
+func (vm *VoucherManager) PayWithExpiry(channelId types.Destination, amount *big.Int, expiry time.Time, signer VoucherSigner) (Voucher, error) {
 	vInfo, err := vm.store.GetVoucherInfo(channelId)
 	if err != nil {
-		return Voucher{}, fmt.Errorf("channel not registered: %w", err)
+		return Voucher{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 
 	if types.Gt(amount, vInfo.Remaining()) {
-		return Voucher{}, fmt.Errorf("unable to pay amount: insufficient funds")
+		return Voucher{}, fmt.Errorf("unable to pay amount: %w", ErrInsufficientFunds)
 	}
 
 	if vInfo.ChannelPayer != vm.me {
@@ -65,10 +114,16 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 	}
 	newAmount := big.NewInt(0).Add(vInfo.LargestVoucher.Amount, amount)
 	voucher := Voucher{Amount: big.NewInt(0).Set(newAmount), ChannelId: channelId}
+	if !expiry.IsZero() {
+		voucher.Expiry = uint64(expiry.Unix())
+	}
+	if vm.nonces {
+		voucher.Nonce = vInfo.LargestVoucher.Nonce + 1
+	}
 
 	vInfo.LargestVoucher = voucher
 
-	if err := voucher.Sign(pk); err != nil {
+	if err := voucher.SignWith(signer, vm.me); err != nil {
 		return voucher, err
 	}
 
@@ -83,7 +138,7 @@ func (vm *VoucherManager) Pay(channelId types.Destination, amount *big.Int, pk [
 func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.Int, err error) {
 	vInfo, err := vm.store.GetVoucherInfo(voucher.ChannelId)
 	if err != nil {
-		return &big.Int{}, &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 
 	// We only care about vouchers when we are the recipient of the payment
@@ -91,8 +146,15 @@ func (vm *VoucherManager) Receive(voucher Voucher) (total *big.Int, delta *big.I
 		return &big.Int{}, &big.Int{}, fmt.Errorf("can only receive vouchers if we're the payee")
 	}
 
+	if voucher.Expired(time.Now()) {
+		return &big.Int{}, &big.Int{}, fmt.Errorf("%w at %d", ErrVoucherExpired, voucher.Expiry)
+	}
+	if err := voucher.checkNonce(vInfo.LargestVoucher, vm.nonces); err != nil {
+		return &big.Int{}, &big.Int{}, err
+	}
+
 	if types.Gt(voucher.Amount, vInfo.StartingBalance) {
-		return &big.Int{}, &big.Int{}, fmt.Errorf("channel has insufficient funds")
+		return &big.Int{}, &big.Int{}, fmt.Errorf("channel has %w", ErrInsufficientFunds)
 	}
 
 	total = vInfo.LargestVoucher.Amount
@@ -130,7 +192,7 @@ func (vm *VoucherManager) ChannelRegistered(channelId types.Destination) bool {
 func (vm *VoucherManager) Paid(chanId types.Destination) (*big.Int, error) {
 	v, err := vm.store.GetVoucherInfo(chanId)
 	if err != nil {
-		return &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 	return v.LargestVoucher.Amount, nil
 }
@@ -139,8 +201,17 @@ func (vm *VoucherManager) Paid(chanId types.Destination) (*big.Int, error) {
 func (vm *VoucherManager) Remaining(chanId types.Destination) (*big.Int, error) {
 	v, err := vm.store.GetVoucherInfo(chanId)
 	if err != nil {
-		return &big.Int{}, fmt.Errorf("channel not registered: %w", err)
+		return &big.Int{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
 	}
 	remaining := big.NewInt(0).Sub(v.StartingBalance, v.LargestVoucher.Amount)
 	return remaining, nil
 }
+
+// Asset returns the asset the channel's payments are made in
+func (vm *VoucherManager) Asset(chanId types.Destination) (common.Address, error) {
+	v, err := vm.store.GetVoucherInfo(chanId)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %w", ErrChannelNotRegistered, err)
+	}
+	return v.Asset, nil
+}
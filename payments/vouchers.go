@@ -3,6 +3,7 @@ package payments
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -26,8 +27,22 @@ type Voucher struct {
 	ChannelId types.Destination
 	Amount    *big.Int
 	Signature state.Signature
+	// Context is application data carried alongside the voucher, such as the id of the request the payment authorizes,
+	// so that the payee can correlate the payment with it. It is not signed, so must only be used for correlation.
+	Context string `json:",omitempty"`
+	// Expiry, if set, is the unix time in seconds after which the voucher is refused by the payee. It is signed, so
+	// bounds the window in which the voucher may be redeemed. Expiring vouchers are signed over a different hash than
+	// the virtual payment app checks on chain, so only vouchers without an expiry can be used in a dispute.
+	Expiry uint64 `json:",omitempty"`
+	// Nonce, if set, is greater than the nonce of any voucher the payer signed before on the channel. It is signed, so
+	// that a payee with replay protection enabled can refuse a voucher it has seen before, rather than treating it as
+	// a payment of nothing. Vouchers with a nonce, like expiring vouchers, cannot be used in a dispute.
+	Nonce uint64 `json:",omitempty"`
 }
 
+// MaxVoucherContextLength is the length, in bytes, of the longest context a voucher may carry.
+const MaxVoucherContextLength = 1024
+
 // VoucherInfo contains the largest voucher we've received on a channel.
 // As well as details about the balance and who the payee/payer is.
 type VoucherInfo struct {
@@ -35,6 +50,9 @@ type VoucherInfo struct {
 	ChannelPayee    common.Address
 	StartingBalance *big.Int
 	LargestVoucher  Voucher
+	// Asset is the asset the channel holds, in which the amounts of its vouchers are denominated. It is the zero
+	// address, the chain's native token, for channels registered before the asset was recorded.
+	Asset common.Address
 }
 
 type ReceiveVoucherSummary struct {
@@ -43,10 +61,53 @@ type ReceiveVoucherSummary struct {
 }
 
 func (v *Voucher) Hash() (types.Bytes32, error) {
+	switch {
+	case v.Nonce != 0:
+		return ComputeNoncedVoucherHash(v.ChannelId, v.Amount, v.Expiry, v.Nonce)
+	case v.Expiry != 0:
+		return ComputeExpiringVoucherHash(v.ChannelId, v.Amount, v.Expiry)
+	default:
+		return ComputeVoucherHash(v.ChannelId, v.Amount)
+	}
+}
+
+// ComputeVoucherHash returns the hash of a voucher for amount on the channel, derived as the virtual payment app
+// derives it. It is the hash which the payer signs.
+func ComputeVoucherHash(channelId types.Destination, amount *big.Int) (types.Bytes32, error) {
+	encoded, err := abi.Arguments{
+		{Type: nitroAbi.Destination},
+		{Type: nitroAbi.Uint256},
+	}.Pack(channelId, amount)
+	if err != nil {
+		return types.Bytes32{}, fmt.Errorf("failed to encode voucher: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// ComputeExpiringVoucherHash returns the hash of a voucher for amount on the channel which expires at the unix time
+// expiry, in seconds. It is the hash which the payer signs.
+func ComputeExpiringVoucherHash(channelId types.Destination, amount *big.Int, expiry uint64) (types.Bytes32, error) {
+	encoded, err := abi.Arguments{
+		{Type: nitroAbi.Destination},
+		{Type: nitroAbi.Uint256},
+		{Type: nitroAbi.Uint256},
+	}.Pack(channelId, amount, new(big.Int).SetUint64(expiry))
+	if err != nil {
+		return types.Bytes32{}, fmt.Errorf("failed to encode voucher: %w", err)
+	}
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// ComputeNoncedVoucherHash returns the hash of a voucher for amount on the channel with the nonce, which expires at the
+// unix time expiry, in seconds, unless expiry is zero. It is the hash which the payer signs. It encodes one more word
+// than the hash of an expiring voucher, so the two can never collide.
+func ComputeNoncedVoucherHash(channelId types.Destination, amount *big.Int, expiry uint64, nonce uint64) (types.Bytes32, error) {
 	encoded, err := abi.Arguments{
 		{Type: nitroAbi.Destination},
 		{Type: nitroAbi.Uint256},
-	}.Pack(v.ChannelId, v.Amount)
+		{Type: nitroAbi.Uint256},
+		{Type: nitroAbi.Uint256},
+	}.Pack(channelId, amount, new(big.Int).SetUint64(expiry), new(big.Int).SetUint64(nonce))
 	if err != nil {
 		return types.Bytes32{}, fmt.Errorf("failed to encode voucher: %w", err)
 	}
@@ -59,7 +120,7 @@ func (v *Voucher) Sign(pk []byte) error {
 		return err
 	}
 
-	sig, err := nitroCrypto.SignEthereumMessage(hash.Bytes(), pk)
+	sig, err := nitroCrypto.Sign(hash.Bytes(), pk)
 	if err != nil {
 		return err
 	}
@@ -74,12 +135,44 @@ func (v *Voucher) RecoverSigner() (types.Address, error) {
 	if error != nil {
 		return types.Address{}, error
 	}
-	return nitroCrypto.RecoverEthereumMessageSigner(h[:], v.Signature)
+	return nitroCrypto.RecoverSigner(h[:], v.Signature)
 }
 
-// Equal returns true if the two vouchers have the same channel id, amount and signatures
+// Equal returns true if the two vouchers have the same channel id, amount, expiry, nonce and signatures
 func (v *Voucher) Equal(other *Voucher) bool {
-	return v.ChannelId == other.ChannelId && v.Amount.Cmp(other.Amount) == 0 && v.Signature.Equal(other.Signature)
+	return v.ChannelId == other.ChannelId && v.Amount.Cmp(other.Amount) == 0 && v.Expiry == other.Expiry && v.Nonce == other.Nonce && v.Signature.Equal(other.Signature)
+}
+
+// SetExpiry sets the voucher to expire at the given time, and signs it again with the payer's signer since the expiry
+// is signed over.
+func (v *Voucher) SetExpiry(expiry time.Time, signer VoucherSigner, payer types.Address) error {
+	v.Expiry = uint64(expiry.Unix())
+	return v.SignWith(signer, payer)
+}
+
+// Expired returns true if the voucher has an expiry which is before now.
+func (v *Voucher) Expired(now time.Time) bool {
+	return v.Expiry != 0 && now.Unix() > int64(v.Expiry)
+}
+
+// checkNonce checks the nonce of a voucher received on a channel whose largest voucher is largest. A voucher with a
+// nonce must have a greater nonce than the largest, and, if nonces are required, every voucher must have one.
+func (v *Voucher) checkNonce(largest Voucher, required bool) error {
+	if v.Nonce == 0 {
+		if required {
+			return ErrVoucherNonceRequired
+		}
+		return nil
+	}
+	if v.Nonce <= largest.Nonce {
+		return fmt.Errorf("%w: nonce %d is not greater than %d", ErrVoucherReplayed, v.Nonce, largest.Nonce)
+	}
+	return nil
+}
+
+// clone returns a copy of the voucher which shares no amount with it.
+func (v *Voucher) clone() Voucher {
+	return Voucher{ChannelId: v.ChannelId, Amount: big.NewInt(0).Set(v.Amount), Signature: v.Signature, Context: v.Context, Expiry: v.Expiry, Nonce: v.Nonce}
 }
 
 // Paid is the amount of funds that already have been used as payments
@@ -0,0 +1,256 @@
+// Package challenge implements an on-chain protocol to exit a directly-funded channel unilaterally, for when a
+// counterparty is unresponsive. The channel is challenged with its latest supported state through the adjudicator's
+// ForceMove protocol. Once the challenge has finalized, the channel's funds are transferred out according to the
+// outcome of that state.
+//
+// If the counterparty answers the challenge with a later supported state, the challenge is cleared, and the objective
+// completes without transferring the funds: the counterparty is responsive again, so the channel may be defunded
+// cooperatively.
+package challenge // import "github.com/statechannels/go-nitro/protocols/challenge"
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForChallenge    protocols.WaitingFor = "WaitingForChallenge"    // For the challenge to be registered on chain
+	WaitingForFinalization protocols.WaitingFor = "WaitingForFinalization" // For the challenge to time out
+	WaitingForWithdraw     protocols.WaitingFor = "WaitingForWithdraw"
+	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing" // Finished
+)
+
+const ObjectivePrefix = "Challenge-"
+
+const (
+	ErrNotEmpty        = types.ConstError("ledger channel has running guarantees")
+	ErrUnexpectedEvent = types.ConstError("challenge objectives are not updated with payloads")
+)
+
+// Objective challenges a channel on chain, and withdraws its funds once the challenge has finalized.
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+
+	// Whether the challenge transaction has been declared as a side effect in a previous crank
+	challengeSubmitted bool
+	// Whether the challenge has been seen registered on chain
+	challengeRegistered bool
+	// Whether the transfer transaction has been declared as a side effect in a previous crank
+	transferSubmitted bool
+	// Whether the challenge was cleared by a later state
+	cleared bool
+}
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// NewObjective initiates an approved Objective to challenge the ledger channel of the request. Ledger channels which
+// fund virtual channels may not be challenged, since their guarantees would only move the funds to the virtual channels.
+func NewObjective(request ObjectiveRequest, getConsensusChannel GetConsensusChannel) (Objective, error) {
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+	if len(cc.FundingTargets()) != 0 {
+		return Objective{}, ErrNotEmpty
+	}
+
+	c, err := directdefund.CreateChannelFromConsensusChannel(*cc)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+	return Objective{Status: protocols.Approved, C: c}, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + o.C.Id.String())
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+// Reject rejects the objective. Challenges are raised by us alone, so no peer is notified.
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	return &updated, protocols.SideEffects{}
+}
+
+// OwnsChannel returns the channel being challenged.
+func (o Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// Update returns an error: a challenge progresses with chain events, which update its channel, rather than payloads.
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	return o, ErrUnexpectedEvent
+}
+
+// Cleared returns true if the challenge was cleared by a later state, leaving the channel's funds on chain.
+func (o *Objective) Cleared() bool {
+	return o.cleared
+}
+
+// FinalizesAt returns when the challenge finalizes, and true, once it has been registered on chain.
+func (o *Objective) FinalizesAt() (time.Time, bool) {
+	if o.C.OnChain.FinalizesAt == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(o.C.OnChain.FinalizesAt), 0), true
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	if !updated.challengeSubmitted {
+		candidate, err := updated.C.LatestSupportedSignedState()
+		if err != nil {
+			return &updated, sideEffects, WaitingForChallenge, fmt.Errorf("could not find a supported state to challenge with: %w", err)
+		}
+		challengerSig, err := signer.SignChallenge(candidate.State())
+		if err != nil {
+			return &updated, sideEffects, WaitingForChallenge, fmt.Errorf("could not sign the challenge: %w", err)
+		}
+		challenge := protocols.NewChallengeTransaction(updated.C.Id, candidate, []state.SignedState{}, challengerSig)
+		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, challenge)
+		updated.challengeSubmitted = true
+		return &updated, sideEffects, WaitingForChallenge, nil
+	}
+
+	finalizesAt, registered := updated.FinalizesAt()
+	if !registered {
+		if updated.challengeRegistered {
+			// The counterparty answered the challenge, so the channel is open again
+			updated.cleared = true
+			updated.Status = protocols.Completed
+			return &updated, sideEffects, WaitingForNothing, nil
+		}
+		return &updated, sideEffects, WaitingForChallenge, nil
+	}
+	updated.challengeRegistered = true
+	if time.Now().Before(finalizesAt) {
+		return &updated, sideEffects, WaitingForFinalization, nil
+	}
+
+	// Withdrawal of funds
+	if updated.C.OnChain.Holdings.IsNonZero() {
+		if !updated.transferSubmitted {
+			transferAll := protocols.NewTransferAllTransaction(updated.C.Id, updated.C.OnChain.Outcome, updated.C.OnChain.StateHash)
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, transferAll)
+			updated.transferSubmitted = true
+		}
+		return &updated, sideEffects, WaitingForWithdraw, nil
+	}
+
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the latest supported state of the channel, so that a ledger
+// channel whose challenge was cleared may be used again.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	supported, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("could not find a supported state for channel %s: %w", o.C.Id, err)
+	}
+	leaderSig, err := supported.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := supported.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	if len(supported.State().Outcome) != 1 {
+		return nil, fmt.Errorf("a consensus channel only supports a single asset")
+	}
+	outcome, err := consensus_channel.FromExit(supported.State().Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, supported.State().TurnNum, outcome, signatures, o.C.Schemes)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, supported.State().TurnNum, outcome, signatures, o.C.Schemes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	return &con, nil
+}
+
+// IsChallengeObjective inspects a objective id and returns true if the objective id is for a challenge objective.
+func IsChallengeObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := *o
+	clone.C = o.C.Clone()
+	return clone
+}
+
+// ObjectiveRequest represents a request to create a new challenge objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return protocols.ObjectiveId(ObjectivePrefix + r.ChannelId.String())
+}
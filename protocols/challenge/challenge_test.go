@@ -0,0 +1,147 @@
+package challenge
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/internal/testdata"
+	"github.com/statechannels/go-nitro/node/engine/chainservice"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var alice = testactors.Alice
+
+// newTestObjective returns a challenge Objective constructed with a MockConsensusChannel.
+func newTestObjective(t *testing.T) Objective {
+	t.Helper()
+	cc, _ := testdata.Channels.MockConsensusChannel(alice.Address())
+	getConsensusChannel := func(id types.Destination) (*consensus_channel.ConsensusChannel, error) {
+		return cc, nil
+	}
+	o, err := NewObjective(NewObjectiveRequest(cc.Id), getConsensusChannel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return o
+}
+
+// crank cranks the objective, failing the test on error.
+func crank(t *testing.T, o protocols.Objective) (*Objective, protocols.SideEffects, protocols.WaitingFor) {
+	t.Helper()
+	updated, se, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return updated.(*Objective), se, waitingFor
+}
+
+// registerChallenge updates the objective's channel with the challenge registered by its first crank.
+func registerChallenge(t *testing.T, o *Objective, tx protocols.ChainTransaction, finalizesAt time.Time) {
+	t.Helper()
+	challenge := tx.(protocols.ChallengeTransaction)
+	event := chainservice.NewChallengeRegisteredEvent(o.C.Id, 10, 0, challenge.Candidate.State().VariablePart(), challenge.Candidate.Signatures(), uint64(finalizesAt.Unix()))
+	if _, err := o.C.UpdateWithChainEvent(event); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCrank(t *testing.T) {
+	o := newTestObjective(t)
+
+	// The channel is challenged with its latest supported state, once
+	updated, se, waitingFor := crank(t, &o)
+	if waitingFor != WaitingForChallenge || len(se.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a challenge transaction while waiting for the challenge, got %v and %+v", waitingFor, se)
+	}
+	challengeTx, ok := se.TransactionsToSubmit[0].(protocols.ChallengeTransaction)
+	if !ok {
+		t.Fatalf("expected a ChallengeTransaction, got %T", se.TransactionsToSubmit[0])
+	}
+	if supported, _ := o.C.LatestSupportedState(); challengeTx.Candidate.State().TurnNum != supported.TurnNum {
+		t.Errorf("expected the challenge to be raised with turn %d, got %d", supported.TurnNum, challengeTx.Candidate.State().TurnNum)
+	}
+	updated, se, waitingFor = crank(t, updated)
+	if waitingFor != WaitingForChallenge || len(se.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected no further transaction while waiting for the challenge, got %v and %+v", waitingFor, se)
+	}
+
+	// The objective waits for the challenge to finalize
+	registerChallenge(t, updated, challengeTx, time.Now().Add(time.Hour))
+	updated, _, waitingFor = crank(t, updated)
+	if waitingFor != WaitingForFinalization {
+		t.Fatalf("expected to wait for the challenge to finalize, got %v", waitingFor)
+	}
+
+	// Once it has, the funds are transferred out according to the challenged state, once
+	updated.C.OnChain.FinalizesAt = uint64(time.Now().Add(-time.Second).Unix())
+	updated, se, waitingFor = crank(t, updated)
+	if waitingFor != WaitingForWithdraw || len(se.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected a transfer transaction while waiting for the withdrawal, got %v and %+v", waitingFor, se)
+	}
+	transfer, ok := se.TransactionsToSubmit[0].(protocols.TransferAllTransaction)
+	if !ok {
+		t.Fatalf("expected a TransferAllTransaction, got %T", se.TransactionsToSubmit[0])
+	}
+	if transfer.StateHash != updated.C.OnChain.StateHash {
+		t.Errorf("expected the transfer to name the challenged state %s, got %s", updated.C.OnChain.StateHash, transfer.StateHash)
+	}
+	updated, se, _ = crank(t, updated)
+	if len(se.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected the transfer not to be resubmitted, got %+v", se)
+	}
+
+	event := chainservice.NewAllocationUpdatedEvent(updated.C.Id, 11, 0, common.HexToAddress("0x00"), big.NewInt(0))
+	if _, err := updated.C.UpdateWithChainEvent(event); err != nil {
+		t.Fatal(err)
+	}
+	updated, _, waitingFor = crank(t, updated)
+	if waitingFor != WaitingForNothing || updated.Status != protocols.Completed || updated.Cleared() {
+		t.Fatalf("expected the objective to complete once the funds were withdrawn, got %v with status %v", waitingFor, updated.Status)
+	}
+}
+
+func TestCrankCleared(t *testing.T) {
+	o := newTestObjective(t)
+	updated, se, _ := crank(t, &o)
+	registerChallenge(t, updated, se.TransactionsToSubmit[0], time.Now().Add(time.Hour))
+	updated, _, _ = crank(t, updated)
+
+	if _, err := updated.C.UpdateWithChainEvent(chainservice.NewChallengeClearedEvent(updated.C.Id, 11, 0, 5)); err != nil {
+		t.Fatal(err)
+	}
+	updated, se, waitingFor := crank(t, updated)
+	if waitingFor != WaitingForNothing || !updated.Cleared() || len(se.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected the objective to complete without withdrawing once the challenge was cleared, got %v and %+v", waitingFor, se)
+	}
+
+	ledger, err := updated.CreateConsensusChannel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ledger.Id != o.C.Id || !ledger.OnChainFunding.Equal(o.C.OnChain.Holdings) {
+		t.Errorf("expected the ledger channel to be restored with its funding, got %+v", ledger)
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	o := newTestObjective(t)
+	updated, _, _ := crank(t, &o)
+
+	data, err := updated.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Objective{}
+	if err := got.UnmarshalJSON(data); err != nil {
+		t.Fatal(err)
+	}
+	if got.Id() != updated.Id() || got.Status != protocols.Approved || !got.challengeSubmitted {
+		t.Errorf("expected %+v to survive a round trip, got %+v", updated, got)
+	}
+}
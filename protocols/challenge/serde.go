@@ -0,0 +1,61 @@
+package challenge
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the challenge.Objective's channel pointer with
+// the channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status              protocols.ObjectiveStatus
+	C                   types.Destination
+	ChallengeSubmitted  bool
+	ChallengeRegistered bool
+	TransferSubmitted   bool
+	Cleared             bool
+}
+
+// MarshalJSON returns a JSON representation of the challenge Objective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.challengeSubmitted,
+		o.challengeRegistered,
+		o.transferSubmitted,
+		o.cleared,
+	})
+}
+
+// UnmarshalJSON populates the calling challenge Objective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonO jsonObjective
+	err := json.Unmarshal(data, &jsonO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+
+	o.Status = jsonO.Status
+	o.C.Id = jsonO.C
+	o.challengeSubmitted = jsonO.ChallengeSubmitted
+	o.challengeRegistered = jsonO.ChallengeRegistered
+	o.transferSubmitted = jsonO.TransferSubmitted
+	o.cleared = jsonO.Cleared
+
+	return nil
+}
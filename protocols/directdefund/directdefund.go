@@ -213,13 +213,16 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 	}
 
 	updated := o.clone()
+	if err := updated.C.ValidateProposedState(ss.State()); err != nil {
+		return o, err
+	}
 	updated.C.AddSignedState(ss)
 
 	return &updated, nil
 }
 
 // Crank inspects the extended state and declares a list of Effects to be executed
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -240,7 +243,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 			stateToSign.TurnNum += 1
 			stateToSign.IsFinal = true
 		}
-		ss, err := updated.C.SignAndAddState(stateToSign, secretKey)
+		ss, err := updated.C.SignAndAddState(stateToSign, signer)
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForFinalization, fmt.Errorf("could not sign final state %w", err)
 		}
@@ -288,6 +291,7 @@ func CreateChannelFromConsensusChannel(cc consensus_channel.ConsensusChannel) (*
 	if err != nil {
 		return &channel.Channel{}, err
 	}
+	c.Schemes = cc.Schemes
 	c.AddSignedState(cc.SupportedSignedState())
 	c.OnChain.Holdings = cc.OnChainFunding
 
@@ -137,7 +137,7 @@ func TestCrankAlice(t *testing.T) {
 	o, _ := newTestObjective()
 
 	// The first crank. Alice is expected to create and sign a final state
-	updated, se, wf, err := o.Crank(&alice.PrivateKey)
+	updated, se, wf, err := o.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -173,7 +173,7 @@ func TestCrankAlice(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	updated, se, wf, err = updated.Crank(&alice.PrivateKey)
+	updated, se, wf, err = updated.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -193,7 +193,7 @@ func TestCrankAlice(t *testing.T) {
 
 	// The third crank. Alice is expected to enter the terminal state of the defunding protocol.
 	updated.(*Objective).C.OnChain.Holdings = types.Funds{}
-	_, se, wf, err = updated.Crank(&alice.PrivateKey)
+	_, se, wf, err = updated.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -215,8 +215,11 @@ func TestCrankBob(t *testing.T) {
 	o, _ := newTestObjective()
 	o.C.MyIndex = 1
 
-	// Update the objective with Alice's final state
+	// Update the objective with Alice's final state, which keeps the outcome of the consensus state
+	supported, err := o.C.LatestSupportedState()
+	testhelpers.Ok(t, err)
 	finalState := testState.Clone()
+	finalState.Outcome = supported.Outcome.Clone()
 	finalState.TurnNum = 2
 	finalState.IsFinal = true
 	finalStateSignedByAlice, _ := signedTestState(finalState, []bool{true, false})
@@ -230,7 +233,7 @@ func TestCrankBob(t *testing.T) {
 	}
 
 	// The first crank. Bob is expected to create and sign a final state
-	updated, se, wf, err := updated.Crank(&bob.PrivateKey)
+	updated, se, wf, err := updated.Crank(state.KeySigner(bob.PrivateKey))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -255,7 +258,7 @@ func TestCrankBob(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	updated, se, wf, err = updated.Crank(&bob.PrivateKey)
+	updated, se, wf, err = updated.Crank(state.KeySigner(bob.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -281,7 +284,7 @@ func TestCrankBob(t *testing.T) {
 		t.Error(err)
 	}
 
-	_, se, wf, err = updated.Crank(&bob.PrivateKey)
+	_, se, wf, err = updated.Crank(state.KeySigner(bob.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
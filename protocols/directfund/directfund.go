@@ -19,11 +19,12 @@ import (
 var ErrLedgerChannelExists error = errors.New("directfund: ledger channel already exists")
 
 const (
-	WaitingForCompletePrefund  protocols.WaitingFor = "WaitingForCompletePrefund"
-	WaitingForMyTurnToFund     protocols.WaitingFor = "WaitingForMyTurnToFund"
-	WaitingForCompleteFunding  protocols.WaitingFor = "WaitingForCompleteFunding"
-	WaitingForCompletePostFund protocols.WaitingFor = "WaitingForCompletePostFund"
-	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing" // Finished
+	WaitingForCompletePrefund   protocols.WaitingFor = "WaitingForCompletePrefund"
+	WaitingForMyTurnToFund      protocols.WaitingFor = "WaitingForMyTurnToFund"
+	WaitingForPriorDepositDepth protocols.WaitingFor = "WaitingForPriorDepositDepth"
+	WaitingForCompleteFunding   protocols.WaitingFor = "WaitingForCompleteFunding"
+	WaitingForCompletePostFund  protocols.WaitingFor = "WaitingForCompletePostFund"
+	WaitingForNothing           protocols.WaitingFor = "WaitingForNothing" // Finished
 )
 
 const (
@@ -44,7 +45,14 @@ type Objective struct {
 	myDepositSafetyThreshold types.Funds // if the on chain holdings are equal to this amount it is safe for me to deposit
 	myDepositTarget          types.Funds // I want to get the on chain holdings up to this much
 	fullyFundedThreshold     types.Funds // if the on chain holdings are equal
-	transactionSubmitted     bool        // whether a transition for the objective has been submitted or not
+	transactionSubmitted     bool        // whether deposits of every asset I must deposit have been submitted
+	submittedDeposits        types.Funds // the deposits I have submitted, by asset
+
+	depositSafetyDepth uint64 // the number of confirmed blocks a prior deposit must be buried under before it is safe for me to deposit
+	latestBlockNum     uint64 // the latest confirmed block number, against which depositSafetyDepth is measured
+	externallyFunded   bool   // whether the channel is funded out-of-band, so that no deposits are made or awaited
+
+	memo string // the proposer's description of why the channel is being funded
 }
 
 // GetChannelByIdFunction specifies a function that can be used to retrieve channels from a store.
@@ -82,7 +90,7 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 		return Objective{}, fmt.Errorf("could not create new objective: %w", err)
 	}
 	objective, err := ConstructFromPayload(preApprove,
-		protocols.ObjectivePayload{ObjectiveId: request.Id(myAddress, chainId), PayloadData: b, Type: SignedStatePayload},
+		protocols.ObjectivePayload{ObjectiveId: request.Id(myAddress, chainId), PayloadData: b, Type: SignedStatePayload, Memo: request.Memo},
 		myAddress,
 	)
 	if err != nil {
@@ -91,7 +99,8 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 	return objective, nil
 }
 
-// ChannelsExistWithCounterparty returns true if a channel or consensus_channel exists with the counterparty
+// ChannelsExistWithCounterparty returns true if a channel or consensus_channel exists with the counterparty. Channels
+// which have been closed, with a supported final state, do not count.
 func ChannelsExistWithCounterparty(counterparty types.Address, getChannels GetChannelsByParticipantFunction, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) (bool, error) {
 	// check for any channels that may be in the process of direct funding
 	channels, err := getChannels(counterparty)
@@ -100,7 +109,7 @@ func ChannelsExistWithCounterparty(counterparty types.Address, getChannels GetCh
 	}
 	for _, c := range channels {
 		// We only want to find directly funded channels that would have two participants
-		if len(c.Participants) == 2 {
+		if len(c.Participants) == 2 && !c.FinalCompleted() {
 			return true, nil
 		}
 	}
@@ -119,6 +128,9 @@ func ConstructFromPayload(
 ) (Objective, error) {
 	var err error
 
+	if len(op.Memo) > protocols.MaxMemoLength {
+		return Objective{}, protocols.ErrMemoTooLong
+	}
 	initialSignedState, err := getSignedStatePayload(op.PayloadData)
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
@@ -134,6 +146,9 @@ func ConstructFromPayload(
 	if initialState.IsFinal {
 		return Objective{}, errors.New("attempted to initiate new direct-funding objective with IsFinal == true")
 	}
+	if err := initialState.Outcome.Validate(); err != nil {
+		return Objective{}, err
+	}
 
 	init := Objective{}
 
@@ -172,6 +187,8 @@ func ConstructFromPayload(
 		types.AddressToDestination(myAddress),
 	)
 	init.myDepositTarget = init.myDepositSafetyThreshold.Add(myAllocatedAmount)
+	init.submittedDeposits = types.Funds{}
+	init.memo = op.Memo
 
 	return init, nil
 }
@@ -186,6 +203,11 @@ func (dfo *Objective) GetStatus() protocols.ObjectiveStatus {
 	return dfo.Status
 }
 
+// Memo returns the proposer's description of why the channel is being funded, or "" if it gave none.
+func (dfo *Objective) Memo() string {
+	return dfo.memo
+}
+
 // CreateConsensusChannel creates a ConsensusChannel from the Objective by extracting signatures and a single asset outcome from the post fund state.
 func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
 	ledger := dfo.C
@@ -215,7 +237,7 @@ func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChan
 	}
 
 	if ledger.MyIndex == uint(consensus_channel.Leader) {
-		con, err := consensus_channel.NewLeaderChannel(ledger.FixedPart, turnNum, outcome, signatures)
+		con, err := consensus_channel.NewLeaderChannel(ledger.FixedPart, turnNum, outcome, signatures, ledger.Schemes)
 		con.OnChainFunding = ledger.OnChain.Holdings.Clone() // Copy OnChain.Holdings so we don't lose this information
 		if err != nil {
 			return nil, fmt.Errorf("could not create consensus channel as leader: %w", err)
@@ -223,7 +245,7 @@ func (dfo *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChan
 		return &con, nil
 
 	} else {
-		con, err := consensus_channel.NewFollowerChannel(ledger.FixedPart, turnNum, outcome, signatures)
+		con, err := consensus_channel.NewFollowerChannel(ledger.FixedPart, turnNum, outcome, signatures, ledger.Schemes)
 		con.OnChainFunding = ledger.OnChain.Holdings.Clone() // Copy OnChain.Holdings so we don't lose this information
 		if err != nil {
 			return nil, fmt.Errorf("could not create consensus channel as follower: %w", err)
@@ -270,6 +292,9 @@ func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, e
 			return o, fmt.Errorf("could not get signed state payload: %w", err)
 		}
 	}
+	if err := updated.C.ValidateProposedState(ss.State()); err != nil {
+		return o, err
+	}
 	updated.C.AddSignedState(ss)
 	return &updated, nil
 }
@@ -287,7 +312,7 @@ func (o *Objective) otherParticipants() []types.Address {
 // Crank inspects the extended state and declares a list of Effects to be executed
 // It's like a state machine transition function where the finite / enumerable state is returned (computed from the extended state)
 // rather than being independent of the extended state; and where there is only one type of event ("the crank") with no data on it at all
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -298,7 +323,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	// Prefunding
 	if !updated.C.PreFundSignedByMe() {
-		ss, err := updated.C.SignAndAddPrefund(secretKey)
+		ss, err := updated.C.SignAndAddPrefund(signer)
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForCompletePrefund, fmt.Errorf("could not sign prefund %w", err)
 		}
@@ -306,6 +331,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForCompletePrefund, fmt.Errorf("could not create payload message %w", err)
 		}
+		protocols.AttachMemo(messages, updated.memo)
 		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
 	}
 
@@ -314,28 +340,18 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	// Funding
-	fundingComplete := updated.fundingComplete() // note all information stored in state (since there are no real events)
-	amountToDeposit := updated.amountToDeposit()
-	safeToDeposit := updated.safeToDeposit()
-
-	if !fundingComplete && !safeToDeposit {
-		return &updated, sideEffects, WaitingForMyTurnToFund, nil
-	}
-
-	if !fundingComplete && safeToDeposit && amountToDeposit.IsNonZero() && !updated.transactionSubmitted {
-		deposit := protocols.NewDepositTransaction(updated.C.Id, amountToDeposit)
-		updated.transactionSubmitted = true
-		sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, deposit)
-	}
-
-	if !fundingComplete {
-		return &updated, sideEffects, WaitingForCompleteFunding, nil
+	if !updated.externallyFunded && !updated.fundingComplete() { // note all information stored in state (since there are no real events)
+		deposit, waitingFor := updated.dueDeposits()
+		if deposit.IsNonZero() {
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, protocols.NewDepositTransaction(updated.C.Id, deposit))
+		}
+		return &updated, sideEffects, waitingFor, nil
 	}
 
 	// Postfunding
 	if !updated.C.PostFundSignedByMe() {
 
-		ss, err := updated.C.SignAndAddPostfund(secretKey)
+		ss, err := updated.C.SignAndAddPostfund(signer)
 		if err != nil {
 			return &updated, protocols.SideEffects{}, WaitingForCompletePostFund, fmt.Errorf("could not sign postfund %w", err)
 		}
@@ -378,37 +394,92 @@ func (o *Objective) fundingComplete() bool {
 	return true
 }
 
-// safeToDeposit returns true if the recorded OnChainHoldings are greater than or equal to the threshold for safety.
-func (o *Objective) safeToDeposit() bool {
-	for asset, safetyThreshold := range o.myDepositSafetyThreshold {
+// SetDepositSafetyDepth requires any deposit which must precede mine to have been buried under depth confirmed blocks
+// before I deposit, given that latestBlockNum is the latest confirmed block. This guards against counterparties
+// whose deposits never land or are reorganized away after I have deposited.
+func (o *Objective) SetDepositSafetyDepth(depth uint64, latestBlockNum uint64) {
+	o.depositSafetyDepth = depth
+	o.latestBlockNum = latestBlockNum
+}
 
-		chainHolding, ok := o.C.OnChain.Holdings[asset]
+// SetExternallyFunded declares that the channel is funded out-of-band (for example by a hub for a node with no
+// connection to the chain), so that the objective proceeds to postfunding without making or awaiting any deposits.
+func (o *Objective) SetExternallyFunded() {
+	o.externallyFunded = true
+}
 
-		if !ok {
-			return false // nil chainHolding for asset
+// dueDeposits returns the deposits I should now submit, recording them as submitted, along with what the objective
+// is then waiting for. Each asset is considered independently, so that a deposit of one asset is submitted as soon as
+// it is safe, without waiting on the funding of any other asset.
+func (o *Objective) dueDeposits() (types.Funds, protocols.WaitingFor) {
+	if o.transactionSubmitted {
+		return types.Funds{}, WaitingForCompleteFunding
+	}
+
+	due := types.Funds{}
+	waitingForTurn, waitingForDepth := false, false
+	for asset, target := range o.myDepositTarget {
+		if _, submitted := o.submittedDeposits[asset]; submitted {
+			continue
+		}
+		holding := o.holding(asset)
+		if !types.Gt(target, holding) {
+			continue
+		}
+		if safetyThreshold, ok := o.myDepositSafetyThreshold[asset]; ok && types.Gt(safetyThreshold, holding) {
+			waitingForTurn = true
+			continue
+		}
+		if !o.priorDepositBuried(asset) {
+			waitingForDepth = true
+			continue
 		}
+		due[asset] = big.NewInt(0).Sub(target, holding)
+	}
 
-		if types.Gt(safetyThreshold, chainHolding) {
-			return false
+	if len(due) > 0 {
+		if o.submittedDeposits == nil {
+			o.submittedDeposits = types.Funds{}
 		}
+		for asset, amount := range due {
+			o.submittedDeposits[asset] = amount
+		}
+		o.transactionSubmitted = !waitingForTurn && !waitingForDepth
 	}
 
-	return true
+	switch {
+	case waitingForDepth:
+		return due, WaitingForPriorDepositDepth
+	case waitingForTurn && len(o.submittedDeposits) == 0:
+		return due, WaitingForMyTurnToFund
+	default:
+		return due, WaitingForCompleteFunding
+	}
 }
 
-// amountToDeposit computes the appropriate amount to deposit given the current recorded OnChainHoldings
-func (o *Objective) amountToDeposit() types.Funds {
-	deposits := make(types.Funds, len(o.C.OnChain.Holdings))
-
-	for asset, target := range o.myDepositTarget {
-		holding, ok := o.C.OnChain.Holdings[asset]
-		if !ok {
-			holding = big.NewInt(0)
-		}
-		deposits[asset] = big.NewInt(0).Sub(target, holding)
+// holding returns the recorded OnChainHoldings of the asset
+func (o *Objective) holding(asset types.Address) *big.Int {
+	if holding, ok := o.C.OnChain.Holdings[asset]; ok {
+		return holding
 	}
+	return big.NewInt(0)
+}
 
-	return deposits
+// priorDepositBuried returns true if the deposit of the asset which must precede mine (if any) was recorded at least
+// depositSafetyDepth blocks before the latest confirmed block. It is always true if I have nothing of the asset to
+// deposit, as I then risk nothing by proceeding.
+func (o *Objective) priorDepositBuried(asset types.Address) bool {
+	if o.depositSafetyDepth == 0 {
+		return true
+	}
+	threshold, ok := o.myDepositSafetyThreshold[asset]
+	if !ok || threshold.Sign() == 0 {
+		return true
+	}
+	if target, ok := o.myDepositTarget[asset]; !ok || !types.Gt(target, threshold) {
+		return true
+	}
+	return o.C.LastChainUpdate.BlockNum+o.depositSafetyDepth <= o.latestBlockNum
 }
 
 // clone returns a deep copy of the receiver.
@@ -423,6 +494,11 @@ func (o *Objective) clone() Objective {
 	clone.myDepositTarget = o.myDepositTarget.Clone()
 	clone.fullyFundedThreshold = o.fullyFundedThreshold.Clone()
 	clone.transactionSubmitted = o.transactionSubmitted
+	clone.submittedDeposits = o.submittedDeposits.Clone()
+	clone.depositSafetyDepth = o.depositSafetyDepth
+	clone.latestBlockNum = o.latestBlockNum
+	clone.externallyFunded = o.externallyFunded
+	clone.memo = o.memo
 	return clone
 }
 
@@ -439,7 +515,10 @@ type ObjectiveRequest struct {
 	AppDefinition     types.Address
 	AppData           types.Bytes
 	Nonce             uint64
-	objectiveStarted  chan struct{}
+	// Memo, if set, describes why the channel is requested. It is sent to the counterparty with the proposal, but is
+	// not part of the channel's state.
+	Memo             string `json:",omitempty"`
+	objectiveStarted chan struct{}
 }
 
 // NewObjectiveRequest creates a new ObjectiveRequest.
@@ -483,9 +562,11 @@ func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocol
 }
 
 // ObjectiveResponse is the type returned across the API in response to the ObjectiveRequest.
+// It includes the channel nonce used, which determines the ChannelId.
 type ObjectiveResponse struct {
 	Id        protocols.ObjectiveId
 	ChannelId types.Destination
+	Nonce     uint64
 }
 
 // Response computes and returns the appropriate response from the request.
@@ -502,6 +583,7 @@ func (r ObjectiveRequest) Response(myAddress types.Address, chainId *big.Int) Ob
 	return ObjectiveResponse{
 		Id:        protocols.ObjectiveId(ObjectivePrefix + channelId.String()),
 		ChannelId: channelId,
+		Nonce:     r.Nonce,
 	}
 }
 
@@ -2,6 +2,7 @@ package directfund
 
 import (
 	"encoding/json"
+	"errors"
 	"math/big"
 	"testing"
 
@@ -170,6 +171,38 @@ func TestUpdate(t *testing.T) {
 	}
 }
 
+func TestUpdateRefusesMalformedState(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	testhelpers.Ok(t, err)
+	s, err := ConstructFromPayload(false, op, testState.Participants[0])
+	testhelpers.Ok(t, err)
+
+	// A postfund state which allocates more than the prefund state
+	inflated := s.C.PreFundState().Clone()
+	inflated.TurnNum = channel.PostFundTurnNum
+	inflated.Outcome[0].Allocations[0].Amount = big.NewInt(0).Add(inflated.Outcome[0].Allocations[0].Amount, big.NewInt(1))
+	sig, err := inflated.Sign(bob.PrivateKey)
+	testhelpers.Ok(t, err)
+	ss := state.NewSignedState(inflated)
+	testhelpers.Ok(t, ss.AddSignature(sig))
+	op, err = protocols.CreateObjectivePayload(s.Id(), SignedStatePayload, ss)
+	testhelpers.Ok(t, err)
+
+	if _, err := s.Update(op); !errors.Is(err, outcome.ErrMalformedOutcome) {
+		t.Fatalf("expected the inflated state to be refused as malformed, got %v", err)
+	}
+
+	// A prefund state allocating a negative amount cannot start an objective
+	negative := testState.Clone()
+	negative.Outcome[0].Allocations[0].Amount = big.NewInt(-1)
+	op, err = protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(negative))
+	testhelpers.Ok(t, err)
+	if _, err := ConstructFromPayload(false, op, testState.Participants[0]); !errors.Is(err, outcome.ErrMalformedOutcome) {
+		t.Fatalf("expected the negative prefund state to be refused as malformed, got %v", err)
+	}
+}
+
 func compareSideEffect(a, b protocols.SideEffects) string {
 	return cmp.Diff(a, b, cmp.AllowUnexported(a, state.SignedState{}, consensus_channel.Add{}, consensus_channel.Guarantee{}, consensus_channel.Remove{}, protocols.Message{}, payments.Voucher{}))
 }
@@ -210,7 +243,7 @@ func TestCrank(t *testing.T) {
 	// END test data preparation
 
 	// Assert that cranking an unapproved objective returns an error
-	if _, _, _, err := s.Crank(&alice.PrivateKey); err == nil {
+	if _, _, _, err := s.Crank(state.KeySigner(alice.PrivateKey)); err == nil {
 		t.Error(`Expected error when cranking unapproved objective, but got nil`)
 	}
 
@@ -223,7 +256,7 @@ func TestCrank(t *testing.T) {
 	//  - what side effects are declared.
 
 	// Initial Crank
-	_, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
+	_, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -240,7 +273,7 @@ func TestCrank(t *testing.T) {
 	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByBobOnPreFund)
 
 	// Cranking should move us to the next waiting point
-	_, _, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, _, waitingFor, err = o.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -250,7 +283,7 @@ func TestCrank(t *testing.T) {
 
 	// Manually make the first "deposit"
 	o.C.OnChain.Holdings[testState.Outcome[0].Asset] = testState.Outcome[0].Allocations[0].Amount
-	updated, sideEffects, waitingFor, err := o.Crank(&alice.PrivateKey)
+	updated, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
 
 	if !updated.(*Objective).transactionSubmitted {
 		t.Fatalf("Expected transactionSubmitted flag to be set to true")
@@ -269,7 +302,7 @@ func TestCrank(t *testing.T) {
 	// Manually make the second "deposit"
 	totalAmountAllocated := testState.Outcome[0].TotalAllocated()
 	o.C.OnChain.Holdings[testState.Outcome[0].Asset] = totalAmountAllocated
-	_, sideEffects, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, sideEffects, waitingFor, err = o.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -286,7 +319,7 @@ func TestCrank(t *testing.T) {
 
 	// This should be the final crank
 	o.C.OnChain.Holdings[testState.Outcome[0].Asset] = totalAmountAllocated
-	_, _, waitingFor, err = o.Crank(&alice.PrivateKey)
+	_, _, waitingFor, err = o.Crank(state.KeySigner(alice.PrivateKey))
 	if err != nil {
 		t.Error(err)
 	}
@@ -295,6 +328,151 @@ func TestCrank(t *testing.T) {
 	}
 }
 
+func TestCrankWithDepositSafetyDepth(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	testhelpers.Ok(t, err)
+
+	s, _ := ConstructFromPayload(false, op, testState.Participants[0])
+	o := s.Approve().(*Objective)
+
+	correctSignatureByAliceOnPreFund, _ := o.C.PreFundState().Sign(alice.PrivateKey)
+	correctSignatureByBobOnPreFund, _ := o.C.PreFundState().Sign(bob.PrivateKey)
+	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByAliceOnPreFund)
+	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByBobOnPreFund)
+
+	// Bob's deposit is seen at block 10
+	o.C.OnChain.Holdings[testState.Outcome[0].Asset] = testState.Outcome[0].Allocations[0].Amount
+	o.C.LastChainUpdate.BlockNum = 10
+
+	// Until Bob's deposit is buried under 5 blocks, we should not deposit
+	o.SetDepositSafetyDepth(5, 14)
+	updated, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForPriorDepositDepth {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForPriorDepositDepth, waitingFor)
+	}
+	if len(sideEffects.TransactionsToSubmit) != 0 || updated.(*Objective).transactionSubmitted {
+		t.Fatalf("Expected no deposit to be submitted, got %v", sideEffects.TransactionsToSubmit)
+	}
+
+	// Once it is, we should
+	o.SetDepositSafetyDepth(5, 15)
+	updated, sideEffects, waitingFor, err = o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	if waitingFor != WaitingForCompleteFunding {
+		t.Fatalf(`WaitingFor: expected %v, got %v`, WaitingForCompleteFunding, waitingFor)
+	}
+	if len(sideEffects.TransactionsToSubmit) != 1 || !updated.(*Objective).transactionSubmitted {
+		t.Fatalf("Expected a deposit to be submitted, got %v", sideEffects.TransactionsToSubmit)
+	}
+}
+
+func TestCrankWithDepositSafetyDepthAndNothingToDeposit(t *testing.T) {
+	nothingToDeposit := testState.Clone()
+	nothingToDeposit.Outcome[0].Allocations[1].Amount = big.NewInt(0) // Alice's allocation
+	id := protocols.ObjectiveId(ObjectivePrefix + nothingToDeposit.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(nothingToDeposit))
+	testhelpers.Ok(t, err)
+
+	s, _ := ConstructFromPayload(false, op, nothingToDeposit.Participants[0])
+	o := s.Approve().(*Objective)
+
+	correctSignatureByAliceOnPreFund, _ := o.C.PreFundState().Sign(alice.PrivateKey)
+	correctSignatureByBobOnPreFund, _ := o.C.PreFundState().Sign(bob.PrivateKey)
+	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByAliceOnPreFund)
+	o.C.AddStateWithSignature(o.C.PreFundState(), correctSignatureByBobOnPreFund)
+
+	// Bob's deposit is seen at block 10, but as Alice has nothing to deposit, she need not wait for it to be buried
+	o.C.OnChain.Holdings[nothingToDeposit.Outcome[0].Asset] = nothingToDeposit.Outcome[0].Allocations[0].Amount
+	o.C.LastChainUpdate.BlockNum = 10
+	o.SetDepositSafetyDepth(5, 10)
+	if !o.priorDepositBuried(nothingToDeposit.Outcome[0].Asset) {
+		t.Fatal("expected no wait for the prior deposit's depth with nothing to deposit")
+	}
+	_, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	if waitingFor == WaitingForPriorDepositDepth {
+		t.Fatalf(`WaitingFor: expected not to wait for %v`, WaitingForPriorDepositDepth)
+	}
+	if len(sideEffects.TransactionsToSubmit) != 0 {
+		t.Fatalf("Expected no deposit to be submitted, got %v", sideEffects.TransactionsToSubmit)
+	}
+}
+
+func TestCrankDepositsAssetsIndependently(t *testing.T) {
+	token := common.HexToAddress("0x1a")
+	multiAssetState := testState.Clone()
+	multiAssetState.Outcome = outcome.Exit{
+		outcome.SingleAssetExit{
+			Asset: types.Address{},
+			Allocations: outcome.Allocations{
+				outcome.Allocation{Destination: alice.Destination(), Amount: big.NewInt(5)}, // Alice deposits first
+				outcome.Allocation{Destination: bob.Destination(), Amount: big.NewInt(5)},
+			},
+		},
+		outcome.SingleAssetExit{
+			Asset: token,
+			Allocations: outcome.Allocations{
+				outcome.Allocation{Destination: bob.Destination(), Amount: big.NewInt(3)}, // Bob deposits first
+				outcome.Allocation{Destination: alice.Destination(), Amount: big.NewInt(7)},
+			},
+		},
+	}
+
+	id := protocols.ObjectiveId(ObjectivePrefix + multiAssetState.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(multiAssetState))
+	testhelpers.Ok(t, err)
+
+	s, _ := ConstructFromPayload(false, op, alice.Address())
+	o := s.Approve().(*Objective)
+	for _, pk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+		sig, _ := o.C.PreFundState().Sign(pk)
+		o.C.AddStateWithSignature(o.C.PreFundState(), sig)
+	}
+
+	// Alice deposits ether straight away, without waiting for Bob's deposit of the token
+	updated, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteFunding, waitingFor)
+	testhelpers.Equals(t, []protocols.ChainTransaction{protocols.NewDepositTransaction(o.C.Id, types.Funds{types.Address{}: big.NewInt(5)})}, sideEffects.TransactionsToSubmit)
+
+	// Once Bob's token deposit lands, Alice deposits the token, and only the token
+	o = updated.(*Objective)
+	o.C.OnChain.Holdings[token] = big.NewInt(3)
+	updated, sideEffects, waitingFor, err = o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompleteFunding, waitingFor)
+	testhelpers.Equals(t, []protocols.ChainTransaction{protocols.NewDepositTransaction(o.C.Id, types.Funds{token: big.NewInt(7)})}, sideEffects.TransactionsToSubmit)
+
+	// Nothing more is deposited while waiting for the deposits to land
+	o = updated.(*Objective)
+	_, sideEffects, _, err = o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, 0, len(sideEffects.TransactionsToSubmit))
+}
+
+func TestCrankExternallyFunded(t *testing.T) {
+	id := protocols.ObjectiveId(ObjectivePrefix + testState.ChannelId().String())
+	op, err := protocols.CreateObjectivePayload(id, SignedStatePayload, state.NewSignedState(testState))
+	testhelpers.Ok(t, err)
+
+	s, _ := ConstructFromPayload(false, op, alice.Address())
+	o := s.Approve().(*Objective)
+	o.SetExternallyFunded()
+	for _, pk := range [][]byte{alice.PrivateKey, bob.PrivateKey} {
+		sig, _ := o.C.PreFundState().Sign(pk)
+		o.C.AddStateWithSignature(o.C.PreFundState(), sig)
+	}
+
+	// Alice signs the postfund state without depositing, or waiting for any deposit
+	_, sideEffects, waitingFor, err := o.Crank(state.KeySigner(alice.PrivateKey))
+	testhelpers.Ok(t, err)
+	testhelpers.Equals(t, WaitingForCompletePostFund, waitingFor)
+	testhelpers.Equals(t, 0, len(sideEffects.TransactionsToSubmit))
+	testhelpers.Equals(t, 1, len(sideEffects.MessagesToSend))
+}
+
 func TestClone(t *testing.T) {
 	compareObjectives := func(a, b protocols.Objective) string {
 		return cmp.Diff(&a, &b, cmp.AllowUnexported(Objective{}, channel.Channel{}, big.Int{}, state.SignedState{}))
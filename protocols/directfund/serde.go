@@ -18,6 +18,8 @@ type jsonObjective struct {
 	MyDepositTarget          types.Funds
 	FullyFundedThreshold     types.Funds
 	TransactionSumbmitted    bool
+	SubmittedDeposits        types.Funds
+	Memo                     string `json:",omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the DirectFundObjective
@@ -31,6 +33,8 @@ func (o Objective) MarshalJSON() ([]byte, error) {
 		o.myDepositTarget,
 		o.fullyFundedThreshold,
 		o.transactionSubmitted,
+		o.submittedDeposits,
+		o.memo,
 	}
 	return json.Marshal(jsonDFO)
 }
@@ -58,6 +62,11 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	o.myDepositTarget = jsonDFO.MyDepositTarget
 	o.myDepositSafetyThreshold = jsonDFO.MyDepositSafetyThreshold
 	o.transactionSubmitted = jsonDFO.TransactionSumbmitted
+	o.submittedDeposits = jsonDFO.SubmittedDeposits
+	o.memo = jsonDFO.Memo
+	if o.submittedDeposits == nil {
+		o.submittedDeposits = types.Funds{}
+	}
 
 	return nil
 }
@@ -0,0 +1,460 @@
+// Package escrow implements an off-chain protocol for payments conditioned on a result, as bought from a compute or
+// inference marketplace. The payer agrees to pay an amount through a payment channel for a result whose keccak256 hash
+// matches a commitment, and the payee delivers the result before a deadline. The payer's node checks the result
+// against the commitment, and releases the payment if it matches.
+//
+// The payment is made with a voucher on the payment channel, once the escrow is released. If the payer does not
+// release it, the payee may dispute the escrow, closing the payment channel so that the payments already made through
+// it are settled by the on-chain payment app. The escrowed amount itself is only ever paid by the payer's release: the
+// on-chain app enforces vouchers, not commitments.
+package escrow // import "github.com/statechannels/go-nitro/protocols/escrow"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForResult  protocols.WaitingFor = "WaitingForResult"
+	WaitingForRelease protocols.WaitingFor = "WaitingForRelease"
+	WaitingForNothing protocols.WaitingFor = "WaitingForNothing" // Finished
+)
+
+const (
+	TermsPayload   protocols.PayloadType = "EscrowTerms"
+	ResultPayload  protocols.PayloadType = "EscrowResult"
+	OutcomePayload protocols.PayloadType = "EscrowOutcome"
+)
+
+const ObjectivePrefix = "Escrow-"
+
+const (
+	ErrInvalidTerms      = types.ConstError("invalid escrow terms")
+	ErrNotOpen           = types.ConstError("escrow is not open")
+	ErrResultMismatch    = types.ConstError("result does not match the commitment")
+	ErrUnexpectedPayee   = types.ConstError("escrow is not payable to us")
+	ErrUnexpectedRequest = types.ConstError("unexpected request for an escrow")
+)
+
+// Outcome is how an escrow was concluded.
+type Outcome string
+
+const (
+	Released Outcome = "Released" // The payer's node found the result to match the commitment, and pays the amount
+	Refused  Outcome = "Refused"  // The result did not match the commitment, so nothing is paid
+	Expired  Outcome = "Expired"  // No result was delivered before the deadline, so nothing is paid
+	Disputed Outcome = "Disputed" // The payee delivered a result which was not released, and closes the payment channel
+)
+
+// Terms are the terms of an escrow: Amount is paid through the payment channel for a result whose keccak256 hash is
+// Commitment, delivered before Deadline.
+type Terms struct {
+	ChannelId  types.Destination
+	Payer      types.Address
+	Payee      types.Address
+	Amount     *big.Int
+	Commitment types.Bytes32
+	Deadline   time.Time
+	Nonce      uint64
+}
+
+// Validate returns an error if the terms do not describe a payment.
+func (t Terms) Validate() error {
+	switch {
+	case t.Amount == nil || t.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTerms)
+	case t.Commitment == types.Bytes32{}:
+		return fmt.Errorf("%w: a commitment is required", ErrInvalidTerms)
+	case t.Deadline.IsZero():
+		return fmt.Errorf("%w: a deadline is required", ErrInvalidTerms)
+	case t.Payer == t.Payee:
+		return fmt.Errorf("%w: payer and payee must differ", ErrInvalidTerms)
+	}
+	return nil
+}
+
+// Matches returns true if the result is committed to by the terms.
+func (t Terms) Matches(result []byte) bool {
+	return crypto.Keccak256Hash(result) == t.Commitment
+}
+
+// Objective is an escrow shared by its payer and payee. It completes when the escrow is concluded.
+type Objective struct {
+	Status    protocols.ObjectiveStatus
+	Terms     Terms
+	MyAddress types.Address
+
+	// Whether the terms have been sent to the payee
+	Proposed bool
+	// The result delivered by the payee, and whether it has been sent to the payer
+	Result     []byte
+	ResultSent bool
+	// How the escrow was concluded, by which party and why, and whether the other party has been told
+	Outcome     Outcome
+	OutcomeBy   types.Address
+	Reason      string
+	OutcomeSent bool
+}
+
+// conclusion is the payload of an OutcomePayload message.
+type conclusion struct {
+	Outcome Outcome
+	Reason  string
+}
+
+// NewObjective creates an approved objective for the escrow described by the request, as its payer.
+func NewObjective(request ObjectiveRequest, myAddress types.Address) (Objective, error) {
+	if request.Terms.Payer != myAddress {
+		return Objective{}, fmt.Errorf("%w: we are not the payer", ErrInvalidTerms)
+	}
+	if err := request.Terms.Validate(); err != nil {
+		return Objective{}, err
+	}
+	return Objective{Status: protocols.Approved, Terms: request.Terms, MyAddress: myAddress}, nil
+}
+
+// ConstructObjectiveFromPayload creates an unapproved objective, as the payee, for the escrow proposed in the payload.
+func ConstructObjectiveFromPayload(p protocols.ObjectivePayload, myAddress types.Address) (Objective, error) {
+	if p.Type != TermsPayload {
+		return Objective{}, fmt.Errorf("expected a %s payload, got %s", TermsPayload, p.Type)
+	}
+	var terms Terms
+	if err := json.Unmarshal(p.PayloadData, &terms); err != nil {
+		return Objective{}, fmt.Errorf("could not unmarshal escrow terms: %w", err)
+	}
+	if err := terms.Validate(); err != nil {
+		return Objective{}, err
+	}
+	if terms.Payee != myAddress {
+		return Objective{}, ErrUnexpectedPayee
+	}
+	o := Objective{Status: protocols.Unapproved, Terms: terms, MyAddress: myAddress}
+	if o.Id() != p.ObjectiveId {
+		return Objective{}, fmt.Errorf("escrow terms do not match objective %s", p.ObjectiveId)
+	}
+	return o, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.Terms.ChannelId, o.Terms.Nonce)
+}
+
+func objectiveId(channelId types.Destination, nonce uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(fmt.Sprintf("%s%s-%d", ObjectivePrefix, channelId, nonce))
+}
+
+// IsEscrowObjective inspects a objective id and returns true if the objective id is for an escrow objective.
+func IsEscrowObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// IsPayer returns true if we pay the escrow.
+func (o *Objective) IsPayer() bool {
+	return o.MyAddress == o.Terms.Payer
+}
+
+// Concluded returns true if the escrow has an outcome.
+func (o *Objective) Concluded() bool {
+	return o.Outcome != ""
+}
+
+// Releases returns true if we pay the escrow, and have released it.
+func (o *Objective) Releases() bool {
+	return o.IsPayer() && o.Outcome == Released
+}
+
+func (o *Objective) peer() types.Address {
+	if o.IsPayer() {
+		return o.Terms.Payee
+	}
+	return o.Terms.Payer
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), o.peer())}
+	return &updated, sideEffects
+}
+
+// DeliverResult returns an updated objective holding the result, to be sent to the payer. Only the payee delivers a
+// result, which must match the commitment.
+func (o *Objective) DeliverResult(result []byte) (*Objective, error) {
+	if o.Status != protocols.Approved || o.Concluded() || o.Result != nil || o.IsPayer() {
+		return nil, fmt.Errorf("%w: %s cannot take a result", ErrNotOpen, o.Id())
+	}
+	if !o.Terms.Matches(result) {
+		return nil, ErrResultMismatch
+	}
+	updated := o.clone()
+	updated.Result = append([]byte{}, result...)
+	return &updated, nil
+}
+
+// Conclude returns an updated objective concluding the escrow with the outcome on our behalf. The payer may let an
+// escrow without a result expire, and the payee may dispute an escrow whose result has not been released.
+func (o *Objective) Conclude(outcome Outcome, reason string) (*Objective, error) {
+	if o.Status != protocols.Approved || o.Concluded() {
+		return nil, fmt.Errorf("%w: %s", ErrNotOpen, o.Id())
+	}
+	switch {
+	case outcome == Expired && o.IsPayer() && o.Result == nil:
+	case outcome == Disputed && !o.IsPayer() && o.Result != nil:
+	default:
+		return nil, fmt.Errorf("%w: %s cannot be %s", ErrNotOpen, o.Id(), outcome)
+	}
+	updated := o.clone()
+	updated.Outcome, updated.OutcomeBy, updated.Reason = outcome, o.MyAddress, reason
+	return &updated, nil
+}
+
+// Update receives a result (as the payer), checking it against the commitment, or the outcome concluded by the other
+// party. The terms sent by the payer, from which the payee's objective was constructed, are ignored.
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	updated := o.clone()
+	switch p.Type {
+	case TermsPayload:
+	case ResultPayload:
+		if !o.IsPayer() {
+			return o, fmt.Errorf("unexpected result for escrow %s", o.Id())
+		}
+		var result []byte
+		if err := json.Unmarshal(p.PayloadData, &result); err != nil {
+			return o, fmt.Errorf("could not unmarshal escrow result: %w", err)
+		}
+		if updated.Concluded() {
+			break
+		}
+		updated.Result = result
+		updated.OutcomeBy = o.MyAddress
+		if o.Terms.Matches(result) {
+			updated.Outcome = Released
+		} else {
+			updated.Outcome, updated.Reason = Refused, ErrResultMismatch.Error()
+		}
+	case OutcomePayload:
+		var c conclusion
+		if err := json.Unmarshal(p.PayloadData, &c); err != nil {
+			return o, fmt.Errorf("could not unmarshal escrow outcome: %w", err)
+		}
+		if !updated.Concluded() {
+			updated.Outcome, updated.OutcomeBy, updated.Reason = c.Outcome, o.peer(), c.Reason
+		}
+	default:
+		return o, fmt.Errorf("unknown payload type %s", p.Type)
+	}
+	return &updated, nil
+}
+
+// Crank sends the terms to the payee and the result to the payer, and tells the other party of the outcome when we
+// conclude the escrow.
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	send := func(payload any, payloadType protocols.PayloadType) error {
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), payload, payloadType, updated.peer())
+		if err != nil {
+			return fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+		return nil
+	}
+
+	if updated.IsPayer() && !updated.Proposed {
+		if err := send(updated.Terms, TermsPayload); err != nil {
+			return o, protocols.SideEffects{}, WaitingForNothing, err
+		}
+		updated.Proposed = true
+	}
+	if !updated.IsPayer() && updated.Result != nil && !updated.ResultSent && !updated.Concluded() {
+		if err := send(updated.Result, ResultPayload); err != nil {
+			return o, protocols.SideEffects{}, WaitingForNothing, err
+		}
+		updated.ResultSent = true
+	}
+
+	if !updated.Concluded() {
+		if updated.Result == nil {
+			return &updated, sideEffects, WaitingForResult, nil
+		}
+		return &updated, sideEffects, WaitingForRelease, nil
+	}
+
+	if updated.OutcomeBy == updated.MyAddress && !updated.OutcomeSent {
+		if err := send(conclusion{Outcome: updated.Outcome, Reason: updated.Reason}, OutcomePayload); err != nil {
+			return o, protocols.SideEffects{}, WaitingForNothing, err
+		}
+		updated.OutcomeSent = true
+	}
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// Related returns no channels: the objective does not update the payment channel.
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{}
+}
+
+// OwnsChannel returns a destination derived from the objective id. An escrow does not own its payment channel, which
+// is paid through and closed as usual while the escrow is open.
+func (o *Objective) OwnsChannel() types.Destination {
+	return types.Destination(crypto.Keccak256Hash([]byte(o.Id())))
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) clone() Objective {
+	clone := *o
+	clone.Terms.Amount = new(big.Int).Set(o.Terms.Amount)
+	if o.Result != nil {
+		clone.Result = append([]byte{}, o.Result...)
+	}
+	return clone
+}
+
+// jsonObjective replicates Objective, so that it can be marshalled without recursing into MarshalJSON.
+type jsonObjective Objective
+
+// MarshalJSON returns a JSON representation of the Objective
+func (o *Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*jsonObjective)(o))
+}
+
+// UnmarshalJSON populates the receiver with the json-encoded data
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*jsonObjective)(o))
+}
+
+// ObjectiveRequest is a request, by the payer, to open an escrow.
+type ObjectiveRequest struct {
+	Terms            Terms
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(terms Terms) ObjectiveRequest {
+	return ObjectiveRequest{Terms: terms, objectiveStarted: make(chan struct{})}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.Terms.ChannelId, r.Terms.Nonce)
+}
+
+// UpdateRequest is a request to deliver the result of an open escrow (if Result is set) or to conclude it with the
+// Outcome.
+type UpdateRequest struct {
+	EscrowId         protocols.ObjectiveId
+	Result           []byte
+	Outcome          Outcome
+	Reason           string
+	objectiveStarted chan struct{}
+}
+
+// NewResultRequest creates a request to deliver the result of the escrow.
+func NewResultRequest(id protocols.ObjectiveId, result []byte) UpdateRequest {
+	return UpdateRequest{EscrowId: id, Result: result, objectiveStarted: make(chan struct{})}
+}
+
+// NewConcludeRequest creates a request to conclude the escrow with the outcome.
+func NewConcludeRequest(id protocols.ObjectiveId, outcome Outcome, reason string) UpdateRequest {
+	return UpdateRequest{EscrowId: id, Outcome: outcome, Reason: reason, objectiveStarted: make(chan struct{})}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the update has been applied.
+func (r UpdateRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the update has been applied
+func (r UpdateRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the id of the escrow to be updated.
+func (r UpdateRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return r.EscrowId
+}
+
+func init() {
+	err := protocols.RegisterObjectiveType(protocols.ObjectiveType{
+		Prefix: ObjectivePrefix,
+		Decode: func(data []byte) (protocols.Objective, error) {
+			o := &Objective{}
+			return o, o.UnmarshalJSON(data)
+		},
+		ConstructFromPayload: func(p protocols.ObjectivePayload, myAddress types.Address) (protocols.Objective, error) {
+			o, err := ConstructObjectiveFromPayload(p, myAddress)
+			return &o, err
+		},
+		ConstructFromRequest: func(request protocols.ObjectiveRequest, myAddress types.Address, chainId *big.Int) (protocols.Objective, error) {
+			r, ok := request.(ObjectiveRequest)
+			if !ok {
+				return nil, fmt.Errorf("%w: %T", ErrUnexpectedRequest, request)
+			}
+			o, err := NewObjective(r, myAddress)
+			return &o, err
+		},
+		ApplyRequest: func(o protocols.Objective, request protocols.ObjectiveRequest, myAddress types.Address) (protocols.Objective, error) {
+			r, ok := request.(UpdateRequest)
+			if !ok {
+				return nil, fmt.Errorf("%w: %T", ErrUnexpectedRequest, request)
+			}
+			e, ok := o.(*Objective)
+			if !ok {
+				return nil, errors.New("objective is not an escrow")
+			}
+			var updated *Objective
+			var err error
+			if r.Result != nil {
+				updated, err = e.DeliverResult(r.Result)
+			} else {
+				updated, err = e.Conclude(r.Outcome, r.Reason)
+			}
+			if err != nil {
+				return nil, err
+			}
+			return updated, nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
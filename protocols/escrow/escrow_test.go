@@ -0,0 +1,149 @@
+package escrow
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var result = []byte("inference output")
+
+func testTerms() Terms {
+	return Terms{
+		ChannelId:  types.Destination{1},
+		Payer:      ta.Alice.Address(),
+		Payee:      ta.Bob.Address(),
+		Amount:     big.NewInt(10),
+		Commitment: crypto.Keccak256Hash(result),
+		Deadline:   time.Unix(1000, 0),
+		Nonce:      1,
+	}
+}
+
+// open returns the payer's and payee's objectives for an escrow proposed by the payer and approved by the payee.
+func open(t *testing.T) (*Objective, *Objective) {
+	t.Helper()
+	payer, err := NewObjective(NewObjectiveRequest(testTerms()), ta.Alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cranked, sideEffects, waitingFor, err := payer.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waitingFor != WaitingForResult || len(sideEffects.MessagesToSend) != 1 {
+		t.Fatalf("expected the terms to be sent while waiting for a result, got %s and %d messages", waitingFor, len(sideEffects.MessagesToSend))
+	}
+	payee, err := ConstructObjectiveFromPayload(sideEffects.MessagesToSend[0].ObjectivePayloads[0], ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cranked.(*Objective), payee.Approve().(*Objective)
+}
+
+// deliver delivers the result to the payer, returning the payer's objective once cranked and the messages it sends.
+func deliver(t *testing.T, payer, payee *Objective, result []byte) (*Objective, []protocols.Message) {
+	t.Helper()
+	payee.Result = result
+	_, sideEffects, waitingFor, err := payee.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waitingFor != WaitingForRelease || len(sideEffects.MessagesToSend) != 1 {
+		t.Fatalf("expected the result to be sent while waiting for release, got %s and %d messages", waitingFor, len(sideEffects.MessagesToSend))
+	}
+	updated, err := payer.Update(sideEffects.MessagesToSend[0].ObjectivePayloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cranked, sideEffects, _, err := updated.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cranked.(*Objective), sideEffects.MessagesToSend
+}
+
+func TestRelease(t *testing.T) {
+	payer, payee := open(t)
+	if _, err := payee.DeliverResult([]byte("something else")); !errors.Is(err, ErrResultMismatch) {
+		t.Errorf("expected the payee's node to refuse a result not matching the commitment, got %v", err)
+	}
+	delivered, err := payee.DeliverResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	released, messages := deliver(t, payer, delivered, delivered.Result)
+	if released.Status != protocols.Completed || released.Outcome != Released || !released.Releases() {
+		t.Fatalf("expected the payer to release the escrow, got %+v", released)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("expected the release to be sent to the payee, got %d messages", len(messages))
+	}
+	updated, err := delivered.Update(messages[0].ObjectivePayloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := updated.(*Objective); e.Outcome != Released || e.OutcomeBy != ta.Alice.Address() || e.Releases() {
+		t.Errorf("expected the payee to see the release, got %+v", e)
+	}
+}
+
+func TestRefusal(t *testing.T) {
+	payer, payee := open(t)
+
+	// A payee's node which skipped the local check has its result refused
+	refused, _ := deliver(t, payer, payee, []byte("something else"))
+	if refused.Status != protocols.Completed || refused.Outcome != Refused || refused.Releases() {
+		t.Errorf("expected the payer to refuse a mismatched result, got %+v", refused)
+	}
+	if _, err := refused.Conclude(Expired, ""); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("expected a concluded escrow not to be concluded again, got %v", err)
+	}
+}
+
+func TestConclude(t *testing.T) {
+	payer, payee := open(t)
+	if _, err := payee.Conclude(Disputed, ""); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("expected the payee not to dispute before delivering a result, got %v", err)
+	}
+	if _, err := payer.Conclude(Disputed, ""); !errors.Is(err, ErrNotOpen) {
+		t.Errorf("expected the payer not to dispute, got %v", err)
+	}
+
+	expired, err := payer.Conclude(Expired, "too late")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, sideEffects, waitingFor, err := expired.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waitingFor != WaitingForNothing || len(sideEffects.MessagesToSend) != 1 {
+		t.Fatalf("expected the expiry to complete the escrow and be sent, got %s and %d messages", waitingFor, len(sideEffects.MessagesToSend))
+	}
+
+	delivered, err := payee.DeliverResult(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	disputed, err := delivered.Conclude(Disputed, "not released")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each party keeps the outcome it concluded first
+	updated, err := disputed.Update(sideEffects.MessagesToSend[0].ObjectivePayloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := updated.(*Objective); e.Outcome != Disputed || e.Reason != "not released" {
+		t.Errorf("expected the payee's dispute to stand, got %+v", e)
+	}
+}
@@ -5,14 +5,19 @@ import (
 	"errors"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/crypto"
 	"github.com/statechannels/go-nitro/types"
 )
 
 var ErrNotApproved = errors.New("objective not approved")
 
+// ErrMemoTooLong is returned when an objective's memo exceeds MaxMemoLength.
+var ErrMemoTooLong = errors.New("memo is too long")
+
 // ChainTransaction defines the interface that every transaction must implement
 type ChainTransaction interface {
 	ChannelId() types.Destination
@@ -66,6 +71,29 @@ func NewChallengeTransaction(
 	}
 }
 
+// CheckpointTransaction answers a challenge registered for a channel with a later supported state, clearing it.
+type CheckpointTransaction struct {
+	ChainTransaction
+	Candidate state.SignedState
+	Proof     []state.SignedState
+}
+
+func NewCheckpointTransaction(channelId types.Destination, candidate state.SignedState, proof []state.SignedState) CheckpointTransaction {
+	return CheckpointTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Candidate: candidate, Proof: proof}
+}
+
+// TransferAllTransaction pays out every asset held for a channel whose challenge has finalized, according to the
+// outcome of the state it was challenged with.
+type TransferAllTransaction struct {
+	ChainTransaction
+	Outcome   outcome.Exit
+	StateHash common.Hash
+}
+
+func NewTransferAllTransaction(channelId types.Destination, outcome outcome.Exit, stateHash common.Hash) TransferAllTransaction {
+	return TransferAllTransaction{ChainTransaction: ChainTransactionBase{channelId: channelId}, Outcome: outcome, StateHash: stateHash}
+}
+
 // SideEffects are effects to be executed by an imperative shell
 type SideEffects struct {
 	MessagesToSend       []Message
@@ -100,10 +128,10 @@ type Storable interface {
 type Objective interface {
 	Id() ObjectiveId
 
-	Approve() Objective                                                  // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Reject() (Objective, SideEffects)                                    // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Update(payload ObjectivePayload) (Objective, error)                  // returns an updated Objective (a copy, no mutation allowed), does not declare effects
-	Crank(secretKey *[]byte) (Objective, SideEffects, WaitingFor, error) // does *not* accept an event, but *does* accept a pointer to a signing key; declare side effects; return an updated Objective
+	Approve() Objective                                                    // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Reject() (Objective, SideEffects)                                      // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Update(payload ObjectivePayload) (Objective, error)                    // returns an updated Objective (a copy, no mutation allowed), does not declare effects
+	Crank(signer state.Signer) (Objective, SideEffects, WaitingFor, error) // does *not* accept an event, but *does* accept a signer of our states; declare side effects; return an updated Objective
 
 	// Related returns a slice of related objects that need to be stored along with the objective
 	Related() []Storable
@@ -115,6 +143,17 @@ type Objective interface {
 	GetStatus() ObjectiveStatus
 }
 
+// MaxMemoLength is the maximum length, in bytes, of an objective's memo. Objectives with longer memos are refused.
+const MaxMemoLength = 256
+
+// MemoCarrier is an Objective which carries a memo from its proposer, describing why it was proposed. A PolicyMaker
+// may use the memo in deciding whether to approve the objective.
+type MemoCarrier interface {
+	Objective
+	// Memo returns the memo, or "" if the proposer gave none.
+	Memo() string
+}
+
 // ProposalReceiver is an Objective that receives proposals.
 type ProposalReceiver interface {
 	Objective
@@ -0,0 +1,425 @@
+// Package ledgertopup implements a protocol to deposit additional collateral into a running ledger channel, so that
+// a ledger channel which has run out of capacity need not be closed and reopened.
+//
+// The depositor proposes the top-up, and the counterparty accepts it. The ledger is taken out of use as a
+// ConsensusChannel for the duration of the objective: a Channel governs it until the top-up completes. Once the
+// counterparty has accepted, the depositor deposits the amount on chain. Each participant then signs the state which
+// follows the ledger's consensus state, allocating the amount to the depositor, as soon as it sees the deposit on
+// chain. The new state becomes the ledger's consensus state once it is signed by both.
+//
+// The deposit precedes any signature on the new state, so that the counterparty never supports an outcome allocating
+// more than the channel holds. The depositor bears the risk of the counterparty never signing it, as with the
+// deposits of a directly funded channel.
+package ledgertopup // import "github.com/statechannels/go-nitro/protocols/ledgertopup"
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/protocols/directdefund"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForAcceptance       protocols.WaitingFor = "WaitingForAcceptance"       // For the counterparty to accept the top-up
+	WaitingForDeposit          protocols.WaitingFor = "WaitingForDeposit"          // For the deposit to be seen on chain
+	WaitingForCountersignature protocols.WaitingFor = "WaitingForCountersignature" // For the counterparty to sign the new state
+	WaitingForNothing          protocols.WaitingFor = "WaitingForNothing"          // Finished
+)
+
+const (
+	ProposalPayload    protocols.PayloadType = "LedgerTopUpProposal"
+	AcceptancePayload  protocols.PayloadType = "LedgerTopUpAcceptance"
+	SignedStatePayload protocols.PayloadType = "SignedStatePayload"
+)
+
+const ObjectivePrefix = "LedgerTopUp-"
+
+const (
+	ErrInvalidTerms      = types.ConstError("invalid ledger top-up")
+	ErrLedgerBusy        = types.ConstError("ledger channel has proposals outstanding")
+	ErrUnexpectedState   = types.ConstError("signed state is not the top-up state")
+	ErrUnexpectedPayload = types.ConstError("unexpected payload for a ledger top-up")
+)
+
+// Terms are the terms of a top-up: Depositor deposits Amount of the ledger's asset into the ledger channel, and is
+// allocated it in the state following the consensus state with turn number TurnNum.
+type Terms struct {
+	ChannelId types.Destination
+	Depositor types.Address
+	Amount    *big.Int
+	TurnNum   uint64
+	Nonce     uint64
+}
+
+// Objective tops up a ledger channel.
+type Objective struct {
+	Status protocols.ObjectiveStatus
+	C      *channel.Channel
+	Terms  Terms
+
+	// Whether the depositor has sent the proposal to the counterparty
+	proposed bool
+	// Whether the counterparty has accepted the top-up
+	accepted bool
+	// Whether the deposit transaction has been declared as a side effect in a previous crank
+	depositSubmitted bool
+}
+
+// GetConsensusChannel describes functions which return a ConsensusChannel ledger channel for a channel id.
+type GetConsensusChannel func(channelId types.Destination) (ledger *consensus_channel.ConsensusChannel, err error)
+
+// NewObjective initiates an approved Objective for us to top up the ledger channel of the request.
+func NewObjective(request ObjectiveRequest, myAddress types.Address, getConsensusChannel GetConsensusChannel) (Objective, error) {
+	cc, err := getConsensusChannel(request.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", request.ChannelId, err)
+	}
+	terms := Terms{
+		ChannelId: request.ChannelId,
+		Depositor: myAddress,
+		Amount:    request.Amount,
+		TurnNum:   cc.ConsensusTurnNum(),
+		Nonce:     request.Nonce,
+	}
+	o, err := newObjective(terms, myAddress, cc)
+	if err != nil {
+		return Objective{}, err
+	}
+	o.Status = protocols.Approved
+	return o, nil
+}
+
+// ConstructObjectiveFromPayload constructs an unapproved Objective from the depositor's proposal.
+func ConstructObjectiveFromPayload(p protocols.ObjectivePayload, myAddress types.Address, getConsensusChannel GetConsensusChannel) (Objective, error) {
+	if p.Type != ProposalPayload {
+		return Objective{}, fmt.Errorf("%w: %s", ErrUnexpectedPayload, p.Type)
+	}
+	var terms Terms
+	if err := json.Unmarshal(p.PayloadData, &terms); err != nil {
+		return Objective{}, fmt.Errorf("could not unmarshal the top-up terms: %w", err)
+	}
+	if terms.Depositor == myAddress {
+		return Objective{}, fmt.Errorf("%w: we are not the depositor of a top-up proposed to us", ErrInvalidTerms)
+	}
+	cc, err := getConsensusChannel(terms.ChannelId)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not find channel %s; %w", terms.ChannelId, err)
+	}
+	if cc.ConsensusTurnNum() != terms.TurnNum {
+		return Objective{}, fmt.Errorf("%w: the top-up follows turn %d, but our consensus turn is %d", ErrInvalidTerms, terms.TurnNum, cc.ConsensusTurnNum())
+	}
+	o, err := newObjective(terms, myAddress, cc)
+	if err != nil {
+		return Objective{}, err
+	}
+	if o.Id() != p.ObjectiveId {
+		return Objective{}, fmt.Errorf("top-up terms do not match objective %s", p.ObjectiveId)
+	}
+	o.Status = protocols.Unapproved
+	return o, nil
+}
+
+// newObjective returns an Objective for the terms, with a Channel taking over governance of the ledger channel.
+func newObjective(terms Terms, myAddress types.Address, cc *consensus_channel.ConsensusChannel) (Objective, error) {
+	if terms.Amount == nil || terms.Amount.Sign() <= 0 {
+		return Objective{}, fmt.Errorf("%w: amount must be positive", ErrInvalidTerms)
+	}
+	participants := cc.Participants()
+	if terms.Depositor != participants[0] && terms.Depositor != participants[1] {
+		return Objective{}, fmt.Errorf("%w: depositor %s is not a participant", ErrInvalidTerms, terms.Depositor)
+	}
+	if len(cc.ProposalQueue()) != 0 {
+		return Objective{}, ErrLedgerBusy
+	}
+
+	c, err := directdefund.CreateChannelFromConsensusChannel(*cc)
+	if err != nil {
+		return Objective{}, fmt.Errorf("could not create Channel from ConsensusChannel; %w", err)
+	}
+	// The holdings are updated by chain events while the Channel governs the ledger
+	c.OnChain.Holdings = cc.OnChainFunding.Clone()
+	return Objective{C: c, Terms: terms}, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.Terms.ChannelId, o.Terms.Nonce)
+}
+
+func objectiveId(channelId types.Destination, nonce uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(fmt.Sprintf("%s%s-%d", ObjectivePrefix, channelId, nonce))
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), o.counterparty())}
+	return &updated, sideEffects
+}
+
+// OwnsChannel returns the ledger channel being topped up.
+func (o *Objective) OwnsChannel() types.Destination {
+	return o.C.Id
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{o.C}
+}
+
+// IsDepositor returns true if we make the deposit.
+func (o *Objective) IsDepositor() bool {
+	return o.C.Participants[o.C.MyIndex] == o.Terms.Depositor
+}
+
+// Update receives an ObjectivePayload from the counterparty: its acceptance of the top-up, or its signature on the
+// new state.
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+
+	updated := o.clone()
+	switch p.Type {
+	case ProposalPayload:
+		// The proposal the objective was constructed from
+	case AcceptancePayload:
+		if !o.IsDepositor() {
+			return o, fmt.Errorf("%w: only the depositor is sent an acceptance", ErrUnexpectedPayload)
+		}
+		updated.accepted = true
+	case SignedStatePayload:
+		var ss state.SignedState
+		if err := json.Unmarshal(p.PayloadData, &ss); err != nil {
+			return o, fmt.Errorf("could not unmarshal signed state: %w", err)
+		}
+		expected, err := o.topUpState()
+		if err != nil {
+			return o, err
+		}
+		if !ss.State().Equal(expected) {
+			return o, ErrUnexpectedState
+		}
+		if !updated.C.AddSignedState(ss) {
+			return o, fmt.Errorf("could not add the signed state to channel %s", o.C.Id)
+		}
+	default:
+		return o, fmt.Errorf("%w: %s", ErrUnexpectedPayload, p.Type)
+	}
+	return &updated, nil
+}
+
+// Crank inspects the extended state and declares a list of Effects to be executed
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	// Agreement
+	if !updated.accepted {
+		payloadType := AcceptancePayload
+		if updated.IsDepositor() {
+			if updated.proposed {
+				return &updated, sideEffects, WaitingForAcceptance, nil
+			}
+			payloadType = ProposalPayload
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), updated.Terms, payloadType, updated.counterparty())
+		if err != nil {
+			return &updated, sideEffects, WaitingForAcceptance, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+		if updated.IsDepositor() {
+			updated.proposed = true
+			return &updated, sideEffects, WaitingForAcceptance, nil
+		}
+		updated.accepted = true
+	}
+
+	topUp, err := updated.topUpState()
+	if err != nil {
+		return &updated, sideEffects, WaitingForDeposit, err
+	}
+
+	// Deposit
+	asset := topUp.Outcome[0].Asset
+	target := topUp.Outcome.TotalAllocated()[asset]
+	holding := updated.holding(asset)
+	if types.Gt(target, holding) {
+		if updated.IsDepositor() && !updated.depositSubmitted {
+			deposit := types.Funds{asset: new(big.Int).Sub(target, holding)}
+			sideEffects.TransactionsToSubmit = append(sideEffects.TransactionsToSubmit, protocols.NewDepositTransaction(updated.C.Id, deposit))
+			updated.depositSubmitted = true
+		}
+		return &updated, sideEffects, WaitingForDeposit, nil
+	}
+
+	// Signing the new state
+	if !updated.signedByMe(topUp.TurnNum) {
+		ss, err := updated.C.SignAndAddState(topUp, signer)
+		if err != nil {
+			return &updated, sideEffects, WaitingForCountersignature, fmt.Errorf("could not sign the top-up state %w", err)
+		}
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), ss, SignedStatePayload, updated.counterparty())
+		if err != nil {
+			return &updated, sideEffects, WaitingForCountersignature, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+	}
+
+	if updated.C.OffChain.LatestSupportedStateTurnNum != topUp.TurnNum {
+		return &updated, sideEffects, WaitingForCountersignature, nil
+	}
+
+	// Completion
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// topUpState returns the state which follows the consensus state the top-up was agreed on, allocating the amount to
+// the depositor.
+func (o *Objective) topUpState() (state.State, error) {
+	base, ok := o.C.OffChain.SignedStateForTurnNum[o.Terms.TurnNum]
+	if !ok {
+		return state.State{}, fmt.Errorf("no consensus state with turn number %d for channel %s", o.Terms.TurnNum, o.C.Id)
+	}
+	s := base.State().Clone()
+	s.TurnNum++
+	if len(s.Outcome) != 1 {
+		return state.State{}, fmt.Errorf("a ledger channel only supports a single asset")
+	}
+	depositor := types.AddressToDestination(o.Terms.Depositor)
+	for i, a := range s.Outcome[0].Allocations {
+		if a.Destination == depositor {
+			s.Outcome[0].Allocations[i].Amount = new(big.Int).Add(a.Amount, o.Terms.Amount)
+			return s, nil
+		}
+	}
+	return state.State{}, fmt.Errorf("channel %s has no allocation to the depositor %s", o.C.Id, o.Terms.Depositor)
+}
+
+// holding returns the recorded OnChainHoldings of the asset
+func (o *Objective) holding(asset types.Address) *big.Int {
+	if holding, ok := o.C.OnChain.Holdings[asset]; ok {
+		return holding
+	}
+	return big.NewInt(0)
+}
+
+// signedByMe returns true if we have signed the state with the turn number.
+func (o *Objective) signedByMe(turnNum uint64) bool {
+	ss, ok := o.C.OffChain.SignedStateForTurnNum[turnNum]
+	return ok && ss.HasSignatureForParticipant(o.C.MyIndex)
+}
+
+// counterparty returns the other participant of the ledger channel.
+func (o *Objective) counterparty() types.Address {
+	return o.C.Participants[1-o.C.MyIndex]
+}
+
+// CreateConsensusChannel creates a ConsensusChannel from the latest supported state of the channel, for the ledger
+// channel to be used again once the top-up has completed or been rejected.
+func (o *Objective) CreateConsensusChannel() (*consensus_channel.ConsensusChannel, error) {
+	supported, err := o.C.LatestSupportedSignedState()
+	if err != nil {
+		return nil, fmt.Errorf("could not find a supported state for channel %s: %w", o.C.Id, err)
+	}
+	leaderSig, err := supported.GetParticipantSignature(uint(consensus_channel.Leader))
+	if err != nil {
+		return nil, fmt.Errorf("could not get leader signature: %w", err)
+	}
+	followerSig, err := supported.GetParticipantSignature(uint(consensus_channel.Follower))
+	if err != nil {
+		return nil, fmt.Errorf("could not get follower signature: %w", err)
+	}
+	signatures := [2]state.Signature{leaderSig, followerSig}
+
+	if len(supported.State().Outcome) != 1 {
+		return nil, fmt.Errorf("a consensus channel only supports a single asset")
+	}
+	outcome, err := consensus_channel.FromExit(supported.State().Outcome[0])
+	if err != nil {
+		return nil, fmt.Errorf("could not create ledger outcome from channel exit: %w", err)
+	}
+
+	var con consensus_channel.ConsensusChannel
+	if o.C.MyIndex == uint(consensus_channel.Leader) {
+		con, err = consensus_channel.NewLeaderChannel(o.C.FixedPart, supported.State().TurnNum, outcome, signatures, o.C.Schemes)
+	} else {
+		con, err = consensus_channel.NewFollowerChannel(o.C.FixedPart, supported.State().TurnNum, outcome, signatures, o.C.Schemes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not create consensus channel: %w", err)
+	}
+	con.OnChainFunding = o.C.OnChain.Holdings.Clone()
+	return &con, nil
+}
+
+// IsLedgerTopUpObjective inspects a objective id and returns true if the objective id is for a ledger top-up objective.
+func IsLedgerTopUpObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// clone returns a deep copy of the receiver.
+func (o *Objective) clone() Objective {
+	clone := *o
+	clone.C = o.C.Clone()
+	if o.Terms.Amount != nil {
+		clone.Terms.Amount = new(big.Int).Set(o.Terms.Amount)
+	}
+	return clone
+}
+
+// ObjectiveRequest represents a request to create a new ledger top-up objective.
+type ObjectiveRequest struct {
+	ChannelId        types.Destination
+	Amount           *big.Int
+	Nonce            uint64
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(channelId types.Destination, amount *big.Int, nonce uint64) ObjectiveRequest {
+	return ObjectiveRequest{
+		ChannelId:        channelId,
+		Amount:           amount,
+		Nonce:            nonce,
+		objectiveStarted: make(chan struct{}),
+	}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.ChannelId, r.Nonce)
+}
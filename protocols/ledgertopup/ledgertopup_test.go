@@ -0,0 +1,216 @@
+package ledgertopup
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/statechannels/go-nitro/channel/consensus_channel"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+var (
+	alice = testactors.Alice
+	bob   = testactors.Bob
+	asset = types.Address{}
+)
+
+// testLedgers returns alice's (leader's) and bob's (follower's) views of a ledger channel in which each has 5, with
+// a lookup for each.
+func testLedgers(t *testing.T) (GetConsensusChannel, GetConsensusChannel) {
+	t.Helper()
+	fp := state.FixedPart{Participants: []types.Address{alice.Address(), bob.Address()}, ChannelNonce: 1, ChallengeDuration: 60}
+	outcome := consensus_channel.NewLedgerOutcome(asset,
+		consensus_channel.NewBalance(alice.Destination(), big.NewInt(5)),
+		consensus_channel.NewBalance(bob.Destination(), big.NewInt(5)),
+		nil)
+	s := consensus_channel.Vars{TurnNum: 1, Outcome: *outcome}.AsState(fp)
+	aliceSig, err := s.Sign(alice.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bobSig, err := s.Sign(bob.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigs := [2]state.Signature{aliceSig, bobSig}
+
+	leader, err := consensus_channel.NewLeaderChannel(fp, 1, *outcome, sigs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	follower, err := consensus_channel.NewFollowerChannel(fp, 1, *outcome, sigs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leader.OnChainFunding = types.Funds{asset: big.NewInt(10)}
+	follower.OnChainFunding = types.Funds{asset: big.NewInt(10)}
+
+	return func(types.Destination) (*consensus_channel.ConsensusChannel, error) { return &leader, nil },
+		func(types.Destination) (*consensus_channel.ConsensusChannel, error) { return &follower, nil }
+}
+
+// crank cranks the objective, failing the test on error.
+func crank(t *testing.T, o protocols.Objective, sk []byte) (*Objective, protocols.SideEffects, protocols.WaitingFor) {
+	t.Helper()
+	updated, se, waitingFor, err := o.Crank(state.KeySigner(sk))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return updated.(*Objective), se, waitingFor
+}
+
+// deliver updates the objective with the single payload of the messages, failing the test on error.
+func deliver(t *testing.T, o *Objective, messages []protocols.Message) *Objective {
+	t.Helper()
+	if len(messages) != 1 || len(messages[0].ObjectivePayloads) != 1 {
+		t.Fatalf("expected a single payload, got %+v", messages)
+	}
+	updated, err := o.Update(messages[0].ObjectivePayloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return updated.(*Objective)
+}
+
+func TestTopUp(t *testing.T) {
+	aliceLedger, bobLedger := testLedgers(t)
+	cc, _ := aliceLedger(types.Destination{})
+
+	depositor, err := NewObjective(NewObjectiveRequest(cc.Id, big.NewInt(3), 1), alice.Address(), aliceLedger)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice proposes the top-up, and waits for bob to accept it
+	updated, se, waitingFor := crank(t, &depositor, alice.PrivateKey)
+	if waitingFor != WaitingForAcceptance || len(se.TransactionsToSubmit) != 0 {
+		t.Fatalf("expected to wait for acceptance without depositing, got %v and %+v", waitingFor, se)
+	}
+	counterparty, err := ConstructObjectiveFromPayload(se.MessagesToSend[0].ObjectivePayloads[0], bob.Address(), bobLedger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counterparty.IsDepositor() || counterparty.Status != protocols.Unapproved {
+		t.Fatalf("expected bob's objective to be unapproved, and not deposit, got %+v", counterparty)
+	}
+	if _, se, _ = crank(t, updated, alice.PrivateKey); len(se.MessagesToSend) != 0 {
+		t.Errorf("expected the proposal to be sent once, got %+v", se.MessagesToSend)
+	}
+
+	// Bob accepts, and waits for the deposit
+	bobs, se, waitingFor := crank(t, counterparty.Approve(), bob.PrivateKey)
+	if waitingFor != WaitingForDeposit {
+		t.Fatalf("expected bob to wait for the deposit, got %v", waitingFor)
+	}
+
+	// Alice deposits, once
+	alices := deliver(t, updated, se.MessagesToSend)
+	alices, se, waitingFor = crank(t, alices, alice.PrivateKey)
+	if waitingFor != WaitingForDeposit || len(se.TransactionsToSubmit) != 1 {
+		t.Fatalf("expected alice to deposit, got %v and %+v", waitingFor, se)
+	}
+	deposit := se.TransactionsToSubmit[0].(protocols.DepositTransaction)
+	if !types.Equal(deposit.Deposit[asset], big.NewInt(3)) {
+		t.Errorf("expected a deposit of 3, got %v", deposit.Deposit)
+	}
+	if alices, se, _ = crank(t, alices, alice.PrivateKey); len(se.TransactionsToSubmit) != 0 {
+		t.Errorf("expected the deposit to be submitted once, got %+v", se.TransactionsToSubmit)
+	}
+
+	// Once each sees the deposit, each signs the new state
+	alices.C.OnChain.Holdings[asset] = big.NewInt(13)
+	bobs.C.OnChain.Holdings[asset] = big.NewInt(13)
+	alices, se, waitingFor = crank(t, alices, alice.PrivateKey)
+	if waitingFor != WaitingForCountersignature {
+		t.Fatalf("expected alice to wait for bob's signature, got %v", waitingFor)
+	}
+	bobs = deliver(t, bobs, se.MessagesToSend)
+	bobs, se, waitingFor = crank(t, bobs, bob.PrivateKey)
+	if waitingFor != WaitingForNothing || bobs.Status != protocols.Completed {
+		t.Fatalf("expected bob's objective to complete, got %v", waitingFor)
+	}
+	alices = deliver(t, alices, se.MessagesToSend)
+	alices, _, waitingFor = crank(t, alices, alice.PrivateKey)
+	if waitingFor != WaitingForNothing || alices.Status != protocols.Completed {
+		t.Fatalf("expected alice's objective to complete, got %v", waitingFor)
+	}
+
+	// The new state is the consensus state of the restored ledger
+	for _, o := range []*Objective{alices, bobs} {
+		ledger, err := o.CreateConsensusChannel()
+		if err != nil {
+			t.Fatal(err)
+		}
+		vars := ledger.ConsensusVars()
+		leader := vars.Outcome.Leader()
+		if vars.TurnNum != 2 || !leader.Equal(consensus_channel.NewBalance(alice.Destination(), big.NewInt(8))) {
+			t.Errorf("expected alice to have 8 at turn 2, got %+v at turn %d", leader, vars.TurnNum)
+		}
+		if !types.Equal(ledger.OnChainFunding[asset], big.NewInt(13)) {
+			t.Errorf("expected the ledger to hold 13, got %v", ledger.OnChainFunding)
+		}
+	}
+}
+
+func TestTopUpRefused(t *testing.T) {
+	aliceLedger, bobLedger := testLedgers(t)
+	cc, _ := aliceLedger(types.Destination{})
+
+	if _, err := NewObjective(NewObjectiveRequest(cc.Id, big.NewInt(0), 1), alice.Address(), aliceLedger); !errors.Is(err, ErrInvalidTerms) {
+		t.Errorf("expected a top-up of nothing to be refused, got %v", err)
+	}
+
+	depositor, err := NewObjective(NewObjectiveRequest(cc.Id, big.NewInt(3), 1), alice.Address(), aliceLedger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, se, _ := crank(t, &depositor, alice.PrivateKey)
+	proposal := se.MessagesToSend[0].ObjectivePayloads[0]
+
+	if _, err := ConstructObjectiveFromPayload(proposal, alice.Address(), bobLedger); !errors.Is(err, ErrInvalidTerms) {
+		t.Errorf("expected the depositor's own proposal to be refused, got %v", err)
+	}
+
+	// A top-up following another consensus turn than ours is refused
+	stale := depositor.Terms
+	stale.TurnNum = 0
+	staleProposal, err := protocols.CreateObjectivePayload(objectiveId(stale.ChannelId, stale.Nonce), ProposalPayload, stale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConstructObjectiveFromPayload(staleProposal, bob.Address(), bobLedger); !errors.Is(err, ErrInvalidTerms) {
+		t.Errorf("expected a top-up following a stale turn to be refused, got %v", err)
+	}
+
+	// A ledger with guarantees being proposed cannot be topped up
+	guarantee := consensus_channel.NewGuarantee(big.NewInt(1), types.Destination{9}, alice.Destination(), bob.Destination())
+	if _, err := cc.Propose(consensus_channel.NewAddProposal(cc.Id, guarantee, big.NewInt(1)), state.KeySigner(alice.PrivateKey)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewObjective(NewObjectiveRequest(cc.Id, big.NewInt(3), 2), alice.Address(), aliceLedger); !errors.Is(err, ErrLedgerBusy) {
+		t.Errorf("expected a busy ledger to be refused, got %v", err)
+	}
+
+	// A state other than the top-up state is refused
+	counterparty, err := ConstructObjectiveFromPayload(proposal, bob.Address(), bobLedger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := counterparty.topUpState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Outcome[0].Allocations[1].Amount = big.NewInt(8)
+	ss := state.NewSignedState(s)
+	payload, err := protocols.CreateObjectivePayload(counterparty.Id(), SignedStatePayload, ss)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := counterparty.Update(payload); !errors.Is(err, ErrUnexpectedState) {
+		t.Errorf("expected a state other than the top-up state to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package ledgertopup
+
+import (
+	"encoding/json"
+
+	"github.com/statechannels/go-nitro/channel"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// jsonObjective replaces the ledgertopup.Objective's channel pointer with
+// the channel's ID, making jsonObjective suitable for serialization
+type jsonObjective struct {
+	Status           protocols.ObjectiveStatus
+	C                types.Destination
+	Terms            Terms
+	Proposed         bool
+	Accepted         bool
+	DepositSubmitted bool
+}
+
+// MarshalJSON returns a JSON representation of the ledger top-up Objective
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonObjective{
+		o.Status,
+		o.C.Id,
+		o.Terms,
+		o.proposed,
+		o.accepted,
+		o.depositSubmitted,
+	})
+}
+
+// UnmarshalJSON populates the calling ledger top-up Objective with the
+// json-encoded data
+// NOTE: Marshal -> Unmarshal is a lossy process. All channel data
+// (other than Id) from the field C is discarded
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var jsonO jsonObjective
+	err := json.Unmarshal(data, &jsonO)
+	if err != nil {
+		return err
+	}
+
+	o.C = &channel.Channel{}
+
+	o.Status = jsonO.Status
+	o.C.Id = jsonO.C
+	o.Terms = jsonO.Terms
+	o.proposed = jsonO.Proposed
+	o.accepted = jsonO.Accepted
+	o.depositSubmitted = jsonO.DepositSubmitted
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/big"
 
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
 	"github.com/statechannels/go-nitro/payments"
@@ -19,6 +20,9 @@ type ObjectivePayload struct {
 	// Type is the type of the payload the message contains.
 	// This is useful when a protocol wants to handle different types of payloads.
 	Type PayloadType
+	// Memo is the proposer's description of why the objective was proposed, such as "CDN traffic for site X". It is not
+	// part of any state, and so is never signed or put on chain.
+	Memo string `json:",omitempty"`
 }
 
 type PayloadType string
@@ -49,6 +53,87 @@ type Message struct {
 	Payments []payments.Voucher
 	// RejectedObjectives is a collection of objectives that have been rejected.
 	RejectedObjectives []ObjectiveId
+	// QuoteRequests is a collection of requests asking the recipient to quote for routing a virtual channel.
+	QuoteRequests []QuoteRequest `json:",omitempty"`
+	// Quotes is a collection of responses to previously sent quote requests.
+	Quotes []Quote `json:",omitempty"`
+	// ProbeRequests is a collection of requests asking the recipient whether it would fund a channel with the sender.
+	ProbeRequests []ProbeRequest `json:",omitempty"`
+	// Probes is a collection of responses to previously sent probe requests.
+	Probes []Probe `json:",omitempty"`
+	// Epoch identifies the sender's store, which numbered the message with Seq.
+	Epoch uint64 `json:",omitempty"`
+	// Seq numbers the message among those sent by From to To, starting from 1, so that the recipient can discard
+	// messages it has already processed. Messages without a Seq are always processed.
+	Seq uint64 `json:",omitempty"`
+	// CorrelationId identifies the flow, such as funding a channel or making a payment, the message is part of. The
+	// recipient logs it, and sends it with the messages of the same flow, so that the flow can be followed through the
+	// logs of every node involved. It is metadata, which does not affect how the message is handled.
+	CorrelationId string `json:",omitempty"`
+}
+
+// QuoteRequest asks an intermediary what it would charge to route a virtual channel,
+// funded with Amount of Asset, to CounterParty.
+type QuoteRequest struct {
+	Id           uint64
+	CounterParty types.Address
+	Asset        types.Address
+	Amount       *big.Int
+}
+
+// Quote is an intermediary's response to a QuoteRequest.
+type Quote struct {
+	// RequestId is the id of the QuoteRequest being responded to.
+	RequestId    uint64
+	Intermediary types.Address
+	CounterParty types.Address
+	Asset        types.Address
+	Amount       *big.Int
+	// Fee is the amount the intermediary would charge to route the virtual channel.
+	Fee *big.Int
+	// CanRoute is false if the intermediary is currently unable to route the virtual channel, in which case Reason explains why.
+	CanRoute bool
+	Reason   string
+}
+
+// ProtocolVersion is the version of the wire protocol spoken by this node. It is advertised in probes, so that peers
+// running incompatible versions find out before funding a channel together.
+const ProtocolVersion uint32 = 1
+
+// SupportedProtocolVersions are the versions of the wire protocol this node can speak, oldest first.
+var SupportedProtocolVersions = []uint32{ProtocolVersion}
+
+// ProbeRequest asks the recipient whether it would fund a channel with the sender, holding Asset, to which the sender
+// deposits Deposit and the recipient CounterDeposit.
+type ProbeRequest struct {
+	Id uint64
+	// Versions are the versions of the wire protocol the sender speaks.
+	Versions       []uint32
+	Asset          types.Address
+	Deposit        *big.Int
+	CounterDeposit *big.Int
+}
+
+// ProbeRefusal identifies why the recipient of a ProbeRequest would not fund the channel.
+type ProbeRefusal string
+
+const (
+	ProbeUnsupportedVersion ProbeRefusal = "unsupported_version"
+	ProbeAssetNotAccepted   ProbeRefusal = "asset_not_accepted"
+	ProbeChannelSizeRefused ProbeRefusal = "channel_size_refused"
+	ProbeInvalidRequest     ProbeRefusal = "invalid_request"
+)
+
+// Probe is the response to a ProbeRequest.
+type Probe struct {
+	// RequestId is the id of the ProbeRequest being responded to.
+	RequestId uint64
+	// Versions are the versions of the wire protocol the responder speaks.
+	Versions []uint32
+	// Accepts is false if the responder would not fund the channel, in which case Refusal says why and Reason explains.
+	Accepts bool
+	Refusal ProbeRefusal `json:",omitempty"`
+	Reason  string       `json:",omitempty"`
 }
 
 // Serialize serializes the message into a string.
@@ -103,6 +188,15 @@ func CreateObjectivePayloadMessage(id ObjectiveId, p interface{}, payloadType Pa
 	return messages, nil
 }
 
+// AttachMemo sets the memo of every objective payload in the messages.
+func AttachMemo(messages []Message, memo string) {
+	for i := range messages {
+		for j := range messages[i].ObjectivePayloads {
+			messages[i].ObjectivePayloads[j].Memo = memo
+		}
+	}
+}
+
 // CreateSignedProposalMessage returns a signed proposal message addressed to the counterparty in the given ledger
 // It contains the provided signed proposals and any proposals in the proposal queue.
 func CreateRejectionNoticeMessage(oId ObjectiveId, recipients ...types.Address) []Message {
@@ -133,6 +227,26 @@ func CreateVoucherMessage(voucher payments.Voucher, recipients ...types.Address)
 	return messages
 }
 
+// CreateQuoteRequestMessage returns a message for the recipient containing the quote request.
+func CreateQuoteRequestMessage(request QuoteRequest, recipient types.Address) Message {
+	return Message{To: recipient, QuoteRequests: []QuoteRequest{request}}
+}
+
+// CreateQuoteMessage returns a message for the recipient containing the quote.
+func CreateQuoteMessage(quote Quote, recipient types.Address) Message {
+	return Message{To: recipient, Quotes: []Quote{quote}}
+}
+
+// CreateProbeRequestMessage returns a message for the recipient containing the probe request.
+func CreateProbeRequestMessage(request ProbeRequest, recipient types.Address) Message {
+	return Message{To: recipient, ProbeRequests: []ProbeRequest{request}}
+}
+
+// CreateProbeMessage returns a message for the recipient containing the probe.
+func CreateProbeMessage(probe Probe, recipient types.Address) Message {
+	return Message{To: recipient, Probes: []Probe{probe}}
+}
+
 // DeserializeMessage deserializes the passed string into a protocols.Message.
 func DeserializeMessage(s string) (Message, error) {
 	msg := Message{}
@@ -152,6 +266,10 @@ type MessageSummary struct {
 	Payments []PaymentSummary
 	// RejectedObjectives is a collection of objectives that have been rejected.
 	RejectedObjectives []string
+
+	QuoteSummaries []QuoteSummary
+
+	CorrelationId string `json:",omitempty"`
 }
 
 // ObjectivePayloadSummary is a summary of an objective payload suitable for logging.
@@ -175,11 +293,19 @@ type PaymentSummary struct {
 	ChannelId string
 }
 
+// QuoteSummary is a summary of a quote request or quote suitable for logging.
+type QuoteSummary struct {
+	RequestId    uint64
+	CounterParty string
+	IsResponse   bool
+}
+
 // Summarize returns a MessageSummary for the message that is suitable for logging
 func (m Message) Summarize() MessageSummary {
 	s := MessageSummary{}
 	s.To = m.To.String()[0:8]
 	s.From = m.From.String()[0:8]
+	s.CorrelationId = m.CorrelationId
 
 	s.PayloadSummaries = make([]ObjectivePayloadSummary, len(m.ObjectivePayloads))
 	for i, p := range m.ObjectivePayloads {
@@ -209,9 +335,17 @@ func (m Message) Summarize() MessageSummary {
 	for i, o := range m.RejectedObjectives {
 		s.RejectedObjectives[i] = string(o)
 	}
+
+	s.QuoteSummaries = make([]QuoteSummary, 0, len(m.QuoteRequests)+len(m.Quotes))
+	for _, q := range m.QuoteRequests {
+		s.QuoteSummaries = append(s.QuoteSummaries, QuoteSummary{RequestId: q.Id, CounterParty: q.CounterParty.String()})
+	}
+	for _, q := range m.Quotes {
+		s.QuoteSummaries = append(s.QuoteSummaries, QuoteSummary{RequestId: q.RequestId, CounterParty: q.CounterParty.String(), IsResponse: true})
+	}
 	return s
 }
 
 type Summary interface {
-	ObjectivePayloadSummary | ProposalSummary | PaymentSummary | string
+	ObjectivePayloadSummary | ProposalSummary | PaymentSummary | QuoteSummary | string
 }
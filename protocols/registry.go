@@ -0,0 +1,92 @@
+package protocols
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ObjectiveType describes an application-specific objective type, such as an auction or a subscription, which go-nitro
+// can store, route messages to and start on request without knowing its concrete type.
+//
+// Objectives of a registered type are stored as the JSON of their MarshalJSON, which must include their Status, and
+// are not attached to the stored channels they relate to when they are loaded: Decode must return an objective which
+// can be cranked as it is. Their Related channels are stored, so they must be of the channel types go-nitro stores.
+type ObjectiveType struct {
+	// Prefix starts the id of every objective of the type, such as "Auction-". It must not overlap the prefix of
+	// another type.
+	Prefix string
+	// Decode deserializes an objective of the type from the output of its MarshalJSON.
+	Decode func(data []byte) (Objective, error)
+	// ConstructFromPayload constructs an unapproved objective from the first payload received for it from a peer.
+	ConstructFromPayload func(p ObjectivePayload, myAddress types.Address) (Objective, error)
+	// ConstructFromRequest constructs an approved objective from a request made through the node's API.
+	ConstructFromRequest func(request ObjectiveRequest, myAddress types.Address, chainId *big.Int) (Objective, error)
+	// ApplyRequest applies a request made through the node's API to an existing objective of the type, such as a request
+	// to cancel it, returning the updated objective. It is optional: without it, a request for an objective which exists
+	// fails.
+	ApplyRequest func(o Objective, request ObjectiveRequest, myAddress types.Address) (Objective, error)
+	// DecodeRequest deserializes a request for an objective of the type, as made through the rpc api. It is optional:
+	// without it, objectives of the type cannot be requested over rpc.
+	DecodeRequest func(data []byte) (ObjectiveRequest, error)
+}
+
+// builtinPrefixes are the id prefixes of the objectives implemented by go-nitro.
+var builtinPrefixes = []string{"DirectFunding-", "DirectDefunding-", "VirtualFund-", "VirtualDefund-", "LedgerTopUp-"}
+
+var objectiveTypes = struct {
+	sync.RWMutex
+	byPrefix map[string]ObjectiveType
+}{byPrefix: make(map[string]ObjectiveType)}
+
+// RegisterObjectiveType registers an application-specific objective type. Types should be registered before any node
+// is started, usually from an init function, so that objectives stored by an earlier run can be loaded.
+func RegisterObjectiveType(t ObjectiveType) error {
+	if t.Prefix == "" {
+		return fmt.Errorf("objective type must have an id prefix")
+	}
+	if t.Decode == nil || t.ConstructFromPayload == nil || t.ConstructFromRequest == nil {
+		return fmt.Errorf("objective type %s must be able to decode and construct its objectives", t.Prefix)
+	}
+
+	objectiveTypes.Lock()
+	defer objectiveTypes.Unlock()
+	overlaps := func(other string) bool {
+		return strings.HasPrefix(t.Prefix, other) || strings.HasPrefix(other, t.Prefix)
+	}
+	for _, p := range builtinPrefixes {
+		if overlaps(p) {
+			return fmt.Errorf("objective type %s overlaps the built-in type %s", t.Prefix, p)
+		}
+	}
+	for p := range objectiveTypes.byPrefix {
+		if overlaps(p) {
+			return fmt.Errorf("objective type %s overlaps the registered type %s", t.Prefix, p)
+		}
+	}
+	objectiveTypes.byPrefix[t.Prefix] = t
+	return nil
+}
+
+// LookupObjectiveType returns the registered type of the objective with the given id.
+func LookupObjectiveType(id ObjectiveId) (ObjectiveType, bool) {
+	objectiveTypes.RLock()
+	defer objectiveTypes.RUnlock()
+	for p, t := range objectiveTypes.byPrefix {
+		if strings.HasPrefix(string(id), p) {
+			return t, true
+		}
+	}
+	return ObjectiveType{}, false
+}
+
+// ObjectiveTypeByPrefix returns the registered type with the given id prefix.
+func ObjectiveTypeByPrefix(prefix string) (ObjectiveType, bool) {
+	objectiveTypes.RLock()
+	defer objectiveTypes.RUnlock()
+	t, ok := objectiveTypes.byPrefix[prefix]
+	return t, ok
+}
@@ -0,0 +1,393 @@
+// Package subscription implements an off-chain protocol for recurring payments through a payment channel. The payer
+// proposes the terms of a subscription (an amount paid every interval, up to a cap) to the payee, and its node pays
+// each payment as it falls due, until the cap is reached or either party cancels the subscription.
+//
+// The objective only records the terms and the end of the subscription: payments are made with vouchers on the payment
+// channel, like any other.
+package subscription // import "github.com/statechannels/go-nitro/protocols/subscription"
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/statechannels/go-nitro/channel/state"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+const (
+	WaitingForCancellation protocols.WaitingFor = "WaitingForCancellation" // The subscription is active
+	WaitingForNothing      protocols.WaitingFor = "WaitingForNothing"      // Finished
+)
+
+const (
+	TermsPayload  protocols.PayloadType = "SubscriptionTerms"
+	CancelPayload protocols.PayloadType = "SubscriptionCancellation"
+)
+
+const ObjectivePrefix = "Subscription-"
+
+// ReasonCapReached is the reason a subscription is ended with once every payment up to its cap has been made.
+const ReasonCapReached = "cap reached"
+
+const (
+	ErrInvalidTerms      = types.ConstError("invalid subscription terms")
+	ErrNotActive         = types.ConstError("subscription is not active")
+	ErrUnexpectedPayee   = types.ConstError("subscription is not payable to us")
+	ErrUnexpectedRequest = types.ConstError("unexpected request for a subscription")
+)
+
+// Terms are the terms of a subscription: Amount is paid through the payment channel every Interval, starting at Start,
+// until Cap has been paid.
+type Terms struct {
+	ChannelId types.Destination
+	Payer     types.Address
+	Payee     types.Address
+	Amount    *big.Int
+	Interval  time.Duration
+	Cap       *big.Int
+	Start     time.Time
+	Nonce     uint64
+}
+
+// Validate returns an error if the terms do not describe at least one payment.
+func (t Terms) Validate() error {
+	switch {
+	case t.Amount == nil || t.Amount.Sign() <= 0:
+		return fmt.Errorf("%w: amount must be positive", ErrInvalidTerms)
+	case t.Interval <= 0:
+		return fmt.Errorf("%w: interval must be positive", ErrInvalidTerms)
+	case t.Cap == nil || t.Cap.Cmp(t.Amount) < 0:
+		return fmt.Errorf("%w: cap must cover at least one payment", ErrInvalidTerms)
+	case t.Payer == t.Payee:
+		return fmt.Errorf("%w: payer and payee must differ", ErrInvalidTerms)
+	}
+	return nil
+}
+
+// Payments returns the number of payments made under the terms before the cap is reached.
+func (t Terms) Payments() uint64 {
+	return new(big.Int).Quo(t.Cap, t.Amount).Uint64()
+}
+
+// PaymentTime returns when the payment numbered i (from 0) falls due.
+func (t Terms) PaymentTime(i uint64) time.Time {
+	return t.Start.Add(time.Duration(i) * t.Interval)
+}
+
+// PaymentsDue returns the number of payments which have fallen due by the given time.
+func (t Terms) PaymentsDue(at time.Time) uint64 {
+	if at.Before(t.Start) {
+		return 0
+	}
+	due := uint64(at.Sub(t.Start)/t.Interval) + 1
+	return min(due, t.Payments())
+}
+
+// PaymentId returns the payment id of the payment numbered i (from 0) of the subscription, which makes each payment
+// at most once.
+func PaymentId(id protocols.ObjectiveId, i uint64) string {
+	return fmt.Sprintf("%s/%d", id, i)
+}
+
+// Objective is a subscription shared by its payer and payee. It completes when the subscription ends.
+type Objective struct {
+	Status    protocols.ObjectiveStatus
+	Terms     Terms
+	MyAddress types.Address
+
+	// Whether the terms have been sent to the payee
+	Proposed bool
+	// The party which ended the subscription, and why
+	CancelledBy types.Address
+	Reason      string
+	// Whether the cancellation has been sent to the other party
+	CancelSent bool
+}
+
+// cancellation is the payload of a CancelPayload message.
+type cancellation struct {
+	Reason string
+}
+
+// NewObjective creates an approved objective for the subscription described by the request, as its payer.
+func NewObjective(request ObjectiveRequest, myAddress types.Address) (Objective, error) {
+	if request.Terms.Payer != myAddress {
+		return Objective{}, fmt.Errorf("%w: we are not the payer", ErrInvalidTerms)
+	}
+	if err := request.Terms.Validate(); err != nil {
+		return Objective{}, err
+	}
+	return Objective{Status: protocols.Approved, Terms: request.Terms, MyAddress: myAddress}, nil
+}
+
+// ConstructObjectiveFromPayload creates an unapproved objective, as the payee, for the subscription proposed in the
+// payload.
+func ConstructObjectiveFromPayload(p protocols.ObjectivePayload, myAddress types.Address) (Objective, error) {
+	if p.Type != TermsPayload {
+		return Objective{}, fmt.Errorf("expected a %s payload, got %s", TermsPayload, p.Type)
+	}
+	var terms Terms
+	if err := json.Unmarshal(p.PayloadData, &terms); err != nil {
+		return Objective{}, fmt.Errorf("could not unmarshal subscription terms: %w", err)
+	}
+	if err := terms.Validate(); err != nil {
+		return Objective{}, err
+	}
+	if terms.Payee != myAddress {
+		return Objective{}, ErrUnexpectedPayee
+	}
+	o := Objective{Status: protocols.Unapproved, Terms: terms, MyAddress: myAddress}
+	if o.Id() != p.ObjectiveId {
+		return Objective{}, fmt.Errorf("subscription terms do not match objective %s", p.ObjectiveId)
+	}
+	return o, nil
+}
+
+// Id returns the unique id of the objective
+func (o *Objective) Id() protocols.ObjectiveId {
+	return objectiveId(o.Terms.ChannelId, o.Terms.Nonce)
+}
+
+func objectiveId(channelId types.Destination, nonce uint64) protocols.ObjectiveId {
+	return protocols.ObjectiveId(fmt.Sprintf("%s%s-%d", ObjectivePrefix, channelId, nonce))
+}
+
+// IsSubscriptionObjective inspects a objective id and returns true if the objective id is for a subscription objective.
+func IsSubscriptionObjective(id protocols.ObjectiveId) bool {
+	return strings.HasPrefix(string(id), ObjectivePrefix)
+}
+
+// IsPayer returns true if we pay the subscription.
+func (o *Objective) IsPayer() bool {
+	return o.MyAddress == o.Terms.Payer
+}
+
+// Cancelled returns true if the subscription has been ended by either party.
+func (o *Objective) Cancelled() bool {
+	return o.CancelledBy != types.Address{}
+}
+
+func (o *Objective) peer() types.Address {
+	if o.IsPayer() {
+		return o.Terms.Payee
+	}
+	return o.Terms.Payer
+}
+
+func (o *Objective) Approve() protocols.Objective {
+	updated := o.clone()
+	updated.Status = protocols.Approved
+	return &updated
+}
+
+func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
+	updated := o.clone()
+	updated.Status = protocols.Rejected
+	sideEffects := protocols.SideEffects{MessagesToSend: protocols.CreateRejectionNoticeMessage(o.Id(), o.peer())}
+	return &updated, sideEffects
+}
+
+// Cancel returns an updated objective ending the subscription, with the given reason, on our behalf.
+func (o *Objective) Cancel(reason string) (*Objective, error) {
+	if o.Status != protocols.Approved || o.Cancelled() {
+		return nil, fmt.Errorf("%w: %s", ErrNotActive, o.Id())
+	}
+	updated := o.clone()
+	updated.CancelledBy = o.MyAddress
+	updated.Reason = reason
+	return &updated, nil
+}
+
+// Update receives a cancellation from the other party. The terms sent by the payer, from which the payee's objective
+// was constructed, are ignored.
+func (o *Objective) Update(p protocols.ObjectivePayload) (protocols.Objective, error) {
+	if o.Id() != p.ObjectiveId {
+		return o, fmt.Errorf("event and objective Ids do not match: %s and %s respectively", string(p.ObjectiveId), string(o.Id()))
+	}
+	updated := o.clone()
+	switch p.Type {
+	case TermsPayload:
+	case CancelPayload:
+		var c cancellation
+		if err := json.Unmarshal(p.PayloadData, &c); err != nil {
+			return o, fmt.Errorf("could not unmarshal subscription cancellation: %w", err)
+		}
+		if !updated.Cancelled() {
+			updated.CancelledBy = o.peer()
+			updated.Reason = c.Reason
+		}
+	default:
+		return o, fmt.Errorf("unknown payload type %s", p.Type)
+	}
+	return &updated, nil
+}
+
+// Crank sends the terms to the payee, and waits for the subscription to be cancelled, telling the other party if we
+// cancelled it.
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+	updated := o.clone()
+	sideEffects := protocols.SideEffects{}
+
+	if updated.Status != protocols.Approved {
+		return &updated, sideEffects, WaitingForNothing, protocols.ErrNotApproved
+	}
+
+	if updated.IsPayer() && !updated.Proposed {
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), updated.Terms, TermsPayload, updated.Terms.Payee)
+		if err != nil {
+			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+		updated.Proposed = true
+	}
+
+	if !updated.Cancelled() {
+		return &updated, sideEffects, WaitingForCancellation, nil
+	}
+
+	if updated.CancelledBy == updated.MyAddress && !updated.CancelSent {
+		messages, err := protocols.CreateObjectivePayloadMessage(updated.Id(), cancellation{Reason: updated.Reason}, CancelPayload, updated.peer())
+		if err != nil {
+			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("could not create payload message %w", err)
+		}
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
+		updated.CancelSent = true
+	}
+	updated.Status = protocols.Completed
+	return &updated, sideEffects, WaitingForNothing, nil
+}
+
+// Related returns no channels: the objective does not update the payment channel.
+func (o *Objective) Related() []protocols.Storable {
+	return []protocols.Storable{}
+}
+
+// OwnsChannel returns a destination derived from the objective id. A subscription does not own its payment channel,
+// which is paid through and closed as usual while the subscription is active.
+func (o *Objective) OwnsChannel() types.Destination {
+	return types.Destination(crypto.Keccak256Hash([]byte(o.Id())))
+}
+
+// GetStatus returns the status of the objective.
+func (o *Objective) GetStatus() protocols.ObjectiveStatus {
+	return o.Status
+}
+
+func (o *Objective) clone() Objective {
+	clone := *o
+	clone.Terms.Amount = new(big.Int).Set(o.Terms.Amount)
+	clone.Terms.Cap = new(big.Int).Set(o.Terms.Cap)
+	return clone
+}
+
+// jsonObjective replicates Objective, so that it can be marshalled without recursing into MarshalJSON.
+type jsonObjective Objective
+
+// MarshalJSON returns a JSON representation of the Objective
+func (o *Objective) MarshalJSON() ([]byte, error) {
+	return json.Marshal((*jsonObjective)(o))
+}
+
+// UnmarshalJSON populates the receiver with the json-encoded data
+func (o *Objective) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, (*jsonObjective)(o))
+}
+
+// ObjectiveRequest is a request, by the payer, to start a subscription.
+type ObjectiveRequest struct {
+	Terms            Terms
+	objectiveStarted chan struct{}
+}
+
+// NewObjectiveRequest creates a new ObjectiveRequest.
+func NewObjectiveRequest(terms Terms) ObjectiveRequest {
+	return ObjectiveRequest{Terms: terms, objectiveStarted: make(chan struct{})}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the objective has been started.
+func (r ObjectiveRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the objective starts
+func (r ObjectiveRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the objective id for the request.
+func (r ObjectiveRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return objectiveId(r.Terms.ChannelId, r.Terms.Nonce)
+}
+
+// CancelRequest is a request, by either party, to end an active subscription.
+type CancelRequest struct {
+	SubscriptionId   protocols.ObjectiveId
+	Reason           string
+	objectiveStarted chan struct{}
+}
+
+// NewCancelRequest creates a new CancelRequest.
+func NewCancelRequest(id protocols.ObjectiveId, reason string) CancelRequest {
+	return CancelRequest{SubscriptionId: id, Reason: reason, objectiveStarted: make(chan struct{})}
+}
+
+// SignalObjectiveStarted is used by the engine to signal the cancellation has been started.
+func (r CancelRequest) SignalObjectiveStarted() {
+	close(r.objectiveStarted)
+}
+
+// WaitForObjectiveToStart blocks until the cancellation starts
+func (r CancelRequest) WaitForObjectiveToStart() {
+	<-r.objectiveStarted
+}
+
+// Id returns the id of the subscription to be cancelled.
+func (r CancelRequest) Id(myAddress types.Address, chainId *big.Int) protocols.ObjectiveId {
+	return r.SubscriptionId
+}
+
+func init() {
+	err := protocols.RegisterObjectiveType(protocols.ObjectiveType{
+		Prefix: ObjectivePrefix,
+		Decode: func(data []byte) (protocols.Objective, error) {
+			o := &Objective{}
+			return o, o.UnmarshalJSON(data)
+		},
+		ConstructFromPayload: func(p protocols.ObjectivePayload, myAddress types.Address) (protocols.Objective, error) {
+			o, err := ConstructObjectiveFromPayload(p, myAddress)
+			return &o, err
+		},
+		ConstructFromRequest: func(request protocols.ObjectiveRequest, myAddress types.Address, chainId *big.Int) (protocols.Objective, error) {
+			r, ok := request.(ObjectiveRequest)
+			if !ok {
+				return nil, fmt.Errorf("%w: %T", ErrUnexpectedRequest, request)
+			}
+			o, err := NewObjective(r, myAddress)
+			return &o, err
+		},
+		ApplyRequest: func(o protocols.Objective, request protocols.ObjectiveRequest, myAddress types.Address) (protocols.Objective, error) {
+			r, ok := request.(CancelRequest)
+			if !ok {
+				return nil, fmt.Errorf("%w: %T", ErrUnexpectedRequest, request)
+			}
+			s, ok := o.(*Objective)
+			if !ok {
+				return nil, errors.New("objective is not a subscription")
+			}
+			cancelled, err := s.Cancel(r.Reason)
+			if err != nil {
+				return nil, err
+			}
+			return cancelled, nil
+		},
+	})
+	if err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,102 @@
+package subscription
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	ta "github.com/statechannels/go-nitro/internal/testactors"
+	"github.com/statechannels/go-nitro/protocols"
+	"github.com/statechannels/go-nitro/types"
+)
+
+func testTerms() Terms {
+	return Terms{
+		ChannelId: types.Destination{1},
+		Payer:     ta.Alice.Address(),
+		Payee:     ta.Bob.Address(),
+		Amount:    big.NewInt(10),
+		Interval:  time.Minute,
+		Cap:       big.NewInt(35),
+		Start:     time.Unix(1000, 0),
+		Nonce:     1,
+	}
+}
+
+func TestTermsSchedule(t *testing.T) {
+	terms := testTerms()
+	if got := terms.Payments(); got != 3 {
+		t.Fatalf("expected 3 payments within the cap, got %d", got)
+	}
+	for _, tc := range []struct {
+		at   time.Duration
+		want uint64
+	}{{-time.Second, 0}, {0, 1}, {59 * time.Second, 1}, {time.Minute, 2}, {time.Hour, 3}} {
+		if got := terms.PaymentsDue(terms.Start.Add(tc.at)); got != tc.want {
+			t.Errorf("expected %d payments due %s after the start, got %d", tc.want, tc.at, got)
+		}
+	}
+
+	invalid := testTerms()
+	invalid.Cap = big.NewInt(5)
+	if err := invalid.Validate(); !errors.Is(err, ErrInvalidTerms) {
+		t.Errorf("expected a cap below the amount to be invalid, got %v", err)
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	payer, err := NewObjective(NewObjectiveRequest(testTerms()), ta.Alice.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The payer proposes the terms to the payee
+	cranked, sideEffects, waitingFor, err := payer.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waitingFor != WaitingForCancellation || len(sideEffects.MessagesToSend) != 1 {
+		t.Fatalf("expected the terms to be sent while the subscription is active, got %s and %d messages", waitingFor, len(sideEffects.MessagesToSend))
+	}
+	payee, err := ConstructObjectiveFromPayload(sideEffects.MessagesToSend[0].ObjectivePayloads[0], ta.Bob.Address())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConstructObjectiveFromPayload(sideEffects.MessagesToSend[0].ObjectivePayloads[0], ta.Irene.Address()); !errors.Is(err, ErrUnexpectedPayee) {
+		t.Errorf("expected a subscription payable to someone else to be refused, got %v", err)
+	}
+
+	// The payee cancels, telling the payer why
+	approved := payee.Approve().(*Objective)
+	cancelled, err := approved.Cancel("no longer offered")
+	if err != nil {
+		t.Fatal(err)
+	}
+	completed, sideEffects, waitingFor, err := cancelled.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waitingFor != WaitingForNothing || completed.GetStatus() != protocols.Completed || len(sideEffects.MessagesToSend) != 1 {
+		t.Fatalf("expected the cancellation to complete the subscription and be sent, got %s and %d messages", waitingFor, len(sideEffects.MessagesToSend))
+	}
+
+	updated, err := cranked.Update(sideEffects.MessagesToSend[0].ObjectivePayloads[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ended, sideEffects, _, err := updated.Crank(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := ended.(*Objective)
+	if s.Status != protocols.Completed || s.CancelledBy != ta.Bob.Address() || s.Reason != "no longer offered" {
+		t.Errorf("expected the payer to see the payee's cancellation, got %+v", s)
+	}
+	if len(sideEffects.MessagesToSend) != 0 {
+		t.Error("expected the payer not to send the cancellation back")
+	}
+	if _, err := s.Cancel(""); !errors.Is(err, ErrNotActive) {
+		t.Errorf("expected an ended subscription not to be cancelled again, got %v", err)
+	}
+}
@@ -318,7 +318,7 @@ func (o *Objective) hasFinalStateFromAlice() bool {
 }
 
 // Crank inspects the extended state and declares a list of Effects to be executed.
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 	sideEffects := protocols.SideEffects{}
 
@@ -351,7 +351,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 			s = updated.finalState()
 		}
 		// Sign and store:
-		ss, err := updated.V.SignAndAddState(s, secretKey)
+		ss, err := updated.V.SignAndAddState(s, signer)
 		if err != nil {
 			return &updated, sideEffects, WaitingForNothing, fmt.Errorf("could not sign final state: %w", err)
 		}
@@ -369,7 +369,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isAlice() && !updated.leftHasDefunded() {
-		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyLeft, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyLeft, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -377,7 +377,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isBob() && !updated.rightHasDefunded() {
-		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyRight, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerToRemoveGuarantee(updated.ToMyRight, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -414,7 +414,7 @@ func (o *Objective) ledgerProposal(ledger *consensus_channel.ConsensusChannel) c
 }
 
 // updateLedgerToRemoveGuarantee updates the ledger channel to remove the guarantee that funds V.
-func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.ConsensusChannel, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.ConsensusChannel, signer state.Signer) (protocols.SideEffects, error) {
 	var sideEffects protocols.SideEffects
 
 	proposed := ledger.HasRemovalBeenProposed(o.VId())
@@ -424,7 +424,7 @@ func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.Cons
 			return protocols.SideEffects{}, nil
 		}
 
-		_, err := ledger.Propose(o.ledgerProposal(ledger), *sk)
+		_, err := ledger.Propose(o.ledgerProposal(ledger), signer)
 		if err != nil {
 			return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 		}
@@ -440,7 +440,7 @@ func (o *Objective) updateLedgerToRemoveGuarantee(ledger *consensus_channel.Cons
 		// If the proposal is next in the queue we accept it
 		proposedNext := ledger.HasRemovalBeenProposedNext(o.VId())
 		if proposedNext {
-			sp, err := ledger.SignNextProposal(o.ledgerProposal(ledger), *sk)
+			sp, err := ledger.SignNextProposal(o.ledgerProposal(ledger), signer)
 			if err != nil {
 				return protocols.SideEffects{}, fmt.Errorf("could not sign proposal: %w", err)
 			}
@@ -524,6 +524,9 @@ func (o *Objective) Update(op protocols.ObjectivePayload) (protocols.Objective,
 			return &Objective{}, err
 		}
 		updated := o.clone()
+		if err := updated.V.ValidateProposedState(ss.State()); err != nil {
+			return o, err
+		}
 		err = validateFinalOutcome(updated.V.FixedPart, updated.initialOutcome(), ss.State().Outcome[0], o.V.Participants[o.MyRole], updated.MinimumPaymentAmount)
 		if err != nil {
 			return o, fmt.Errorf("outcome failed validation %w", err)
@@ -113,7 +113,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 		virtualDefund, err := NewObjective(request, true, my.Address(), ourPaymentAmount, getChannel, getConsensusChannel)
 		testhelpers.Ok(t, err)
 
-		updatedObj, se, waitingFor, err := virtualDefund.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err := virtualDefund.Crank(state.KeySigner(my.PrivateKey))
 		testhelpers.Ok(t, err)
 		updated := updatedObj.(*Objective)
 
@@ -129,7 +129,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 			err = ss.AddSignature(aliceSig)
 			testhelpers.Ok(t, err)
 			updated.V.AddSignedState(ss)
-			updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+			updatedObj, se, waitingFor, err = updated.Crank(state.KeySigner(my.PrivateKey))
 			testhelpers.Ok(t, err)
 			updated = updatedObj.(*Objective)
 		}
@@ -151,7 +151,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 		}
 		updated.V.AddSignedState(ss)
 
-		updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err = updated.Crank(state.KeySigner(my.PrivateKey))
 		updated = updatedObj.(*Objective)
 		testhelpers.Ok(t, err)
 
@@ -172,7 +172,7 @@ func testCrankAs(my ta.Actor) func(t *testing.T) {
 			updated = updatedObj.(*Objective)
 		}
 
-		updatedObj, se, waitingFor, err = updated.Crank(&my.PrivateKey)
+		updatedObj, se, waitingFor, err = updated.Crank(state.KeySigner(my.PrivateKey))
 		updated = updatedObj.(*Objective)
 		testhelpers.Ok(t, err)
 
@@ -3,6 +3,7 @@ package virtualfund
 import (
 	"encoding/json"
 	"fmt"
+	"math/big"
 
 	"github.com/statechannels/go-nitro/channel"
 	"github.com/statechannels/go-nitro/channel/consensus_channel"
@@ -71,6 +72,9 @@ type jsonObjective struct {
 
 	A0 types.Funds
 	B0 types.Funds
+
+	Memo string   `json:",omitempty"`
+	Fee  *big.Int `json:",omitempty"`
 }
 
 // MarshalJSON returns a JSON representation of the VirtualFundObjective
@@ -110,6 +114,8 @@ func (o Objective) MarshalJSON() ([]byte, error) {
 		o.MyRole,
 		o.a0,
 		o.b0,
+		o.memo,
+		o.fee,
 	}
 	return json.Marshal(jsonVFO)
 }
@@ -158,6 +164,8 @@ func (o *Objective) UnmarshalJSON(data []byte) error {
 	o.MyRole = jsonVFO.MyRole
 	o.a0 = jsonVFO.A0
 	o.b0 = jsonVFO.B0
+	o.memo = jsonVFO.Memo
+	o.fee = jsonVFO.Fee
 
 	return nil
 }
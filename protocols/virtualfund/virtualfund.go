@@ -30,6 +30,8 @@ const (
 
 const ObjectivePrefix = "VirtualFund-"
 
+var ErrWithdrawalOfCompletedObjective = errors.New("virtualfund: cannot withdraw the guarantee of a completed objective")
+
 // GuaranteeInfo contains the information used to generate the expected guarantees.
 type GuaranteeInfo struct {
 	Left                 types.Destination
@@ -37,6 +39,9 @@ type GuaranteeInfo struct {
 	LeftAmount           types.Funds
 	RightAmount          types.Funds
 	GuaranteeDestination types.Destination
+	// Expiry is the unix time (in seconds) at which the guarantee expires, or zero if it never expires.
+	// It is chosen by the leader of the ledger channel, and adopted by the follower when it accepts the guarantee.
+	Expiry uint64 `json:",omitempty"`
 }
 type Connection struct {
 	Channel       *consensus_channel.ConsensusChannel
@@ -114,7 +119,7 @@ func (c *Connection) getExpectedGuarantee() consensus_channel.Guarantee {
 	left := c.GuaranteeInfo.Left
 	right := c.GuaranteeInfo.Right
 
-	return consensus_channel.NewGuarantee(amount, target, left, right)
+	return consensus_channel.NewGuarantee(amount, target, left, right).WithExpiry(c.GuaranteeInfo.Expiry)
 }
 
 // Objective is a cache of data computed by reading from the store. It stores (potentially) infinite data.
@@ -130,10 +135,16 @@ type Objective struct {
 
 	a0 types.Funds // Initial balance for Alice
 	b0 types.Funds // Initial balance for Bob
+
+	memo string   // the proposer's description of why the channel is being funded
+	fee  *big.Int // the fee the intermediary quoted to route the channel, if it was routed by quote
 }
 
 // NewObjective creates a new virtual funding objective from a given request.
 func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Address, chainId *big.Int, getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction) (Objective, error) {
+	if len(request.Memo) > protocols.MaxMemoLength {
+		return Objective{}, protocols.ErrMemoTooLong
+	}
 	var rightCC *consensus_channel.ConsensusChannel
 	ok := false
 
@@ -166,6 +177,8 @@ func NewObjective(request ObjectiveRequest, preApprove bool, myAddress types.Add
 	if err != nil {
 		return Objective{}, fmt.Errorf("error creating objective: %w", err)
 	}
+	objective.memo = request.Memo
+	objective.fee = request.Fee
 	return objective, nil
 }
 
@@ -300,6 +313,110 @@ func (o *Objective) Reject() (protocols.Objective, protocols.SideEffects) {
 	return &updated, sideEffects
 }
 
+// Withdraw returns a copy of the objective in which, on every ledger channel we lead, the guarantee for V (whether
+// countersigned or not) is withdrawn by proposing its removal with each participant's deposit returned in full.
+// It is used to release ledger capacity held by an objective which is being abandoned; the caller is responsible
+// for rejecting the objective.
+func (o *Objective) Withdraw(signer state.Signer) (*Objective, protocols.SideEffects, error) {
+	updated := o.clone()
+	sideEffects := protocols.SideEffects{}
+
+	for _, c := range []*Connection{updated.ToMyLeft, updated.ToMyRight} {
+		if c == nil || !c.Channel.IsLeader() || c.Channel.HasRemovalBeenProposed(o.V.Id) {
+			continue
+		}
+		g := c.getExpectedGuarantee()
+		proposed, err := c.Channel.IsProposed(g)
+		if err != nil {
+			return o, protocols.SideEffects{}, err
+		}
+		if !proposed && !c.Channel.Includes(g) {
+			continue
+		}
+
+		withdrawal := consensus_channel.NewRemoveProposal(c.Channel.Id, o.V.Id, c.expectedProposal().ToAdd.LeftDeposit)
+		if _, err := c.Channel.Propose(withdrawal, signer); err != nil {
+			return o, protocols.SideEffects{}, fmt.Errorf("could not withdraw guarantee: %w", err)
+		}
+		message := protocols.CreateSignedProposalMessage(c.Channel.Follower(), c.Channel.ProposalQueue()...)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+	}
+
+	return &updated, sideEffects, nil
+}
+
+// ReceiveWithdrawal receives a signed proposal withdrawing the guarantee for V from a ledger channel, as made by
+// the leader of that channel via Withdraw. As the follower, we countersign the withdrawal and reject the objective
+// (if it is not already rejected). As the leader, we record the countersignature.
+//
+// An intermediary which rejects the objective this way also withdraws the guarantee it leads on its other connection,
+// and notifies the other participants of the rejection, so that ledger capacity is released along the whole route.
+//
+// A withdrawal of a completed objective is refused with ErrWithdrawalOfCompletedObjective, since V may already be in use.
+func (o *Objective) ReceiveWithdrawal(sp consensus_channel.SignedProposal, signer state.Signer) (*Objective, protocols.SideEffects, error) {
+	if sp.Proposal.Type() != consensus_channel.RemoveProposal || sp.Proposal.Target() != o.V.Id {
+		return o, protocols.SideEffects{}, fmt.Errorf("signed proposal is not a withdrawal of the guarantee for %s", o.V.Id)
+	}
+	if o.Status == protocols.Completed {
+		return o, protocols.SideEffects{}, ErrWithdrawalOfCompletedObjective
+	}
+
+	updated := o.clone()
+
+	var c *Connection
+	switch {
+	case updated.ToMyLeft != nil && sp.Proposal.LedgerID == updated.ToMyLeft.Channel.Id:
+		c = updated.ToMyLeft
+	case updated.ToMyRight != nil && sp.Proposal.LedgerID == updated.ToMyRight.Channel.Id:
+		c = updated.ToMyRight
+	default:
+		return o, protocols.SideEffects{}, fmt.Errorf("signed proposal is not addressed to a known ledger connection")
+	}
+
+	err := c.Channel.Receive(sp)
+	if err != nil && !errors.Is(err, consensus_channel.ErrInvalidTurnNum) {
+		return o, protocols.SideEffects{}, fmt.Errorf("could not receive withdrawal: %w", err)
+	}
+
+	sideEffects := protocols.SideEffects{}
+	if c.Channel.IsFollower() && c.Channel.HasRemovalBeenProposed(o.V.Id) {
+		countersigned, err := c.Channel.SignWithdrawal(c.expectedProposalFromLeader(), signer)
+		if errors.Is(err, consensus_channel.ErrNoWithdrawal) {
+			// The withdrawal is queued behind other proposals, which must be handled first
+			return &updated, protocols.SideEffects{}, nil
+		}
+		if err != nil {
+			return o, protocols.SideEffects{}, fmt.Errorf("could not countersign withdrawal: %w", err)
+		}
+		message := protocols.CreateSignedProposalMessage(c.Channel.Leader(), countersigned)
+		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, message)
+		if updated.Status == protocols.Rejected {
+			return &updated, sideEffects, nil
+		}
+
+		withdrawn, withdrawalSideEffects, err := updated.Withdraw(signer)
+		if err != nil {
+			return o, protocols.SideEffects{}, err
+		}
+		rejected, rejectionSideEffects := withdrawn.Reject()
+		sideEffects.Merge(withdrawalSideEffects)
+		sideEffects.Merge(rejectionSideEffects)
+		return rejected.(*Objective), sideEffects, nil
+	}
+
+	return &updated, sideEffects, nil
+}
+
+// SetGuaranteeExpiry sets the expiry (a unix time in seconds, or zero for none) of the guarantees for V which we
+// propose, ie. those on the ledger channels we lead. It must be called before the guarantees are proposed.
+func (o *Objective) SetGuaranteeExpiry(expiry uint64) {
+	for _, c := range []*Connection{o.ToMyLeft, o.ToMyRight} {
+		if c != nil && c.Channel.IsLeader() {
+			c.GuaranteeInfo.Expiry = expiry
+		}
+	}
+}
+
 // OwnsChannel returns the channel that the objective is funding.
 func (o *Objective) OwnsChannel() types.Destination {
 	return o.V.Id
@@ -310,6 +427,16 @@ func (o *Objective) GetStatus() protocols.ObjectiveStatus {
 	return o.Status
 }
 
+// Memo returns the proposer's description of why the channel is being funded, or "" if it gave none.
+func (o *Objective) Memo() string {
+	return o.memo
+}
+
+// Fee returns the fee the intermediary quoted to route the channel, or nil if the channel was not routed by quote.
+func (o *Objective) Fee() *big.Int {
+	return o.fee
+}
+
 func (o *Objective) otherParticipants() []types.Address {
 	otherParticipants := make([]types.Address, 0)
 	for i, p := range o.V.Participants {
@@ -384,6 +511,9 @@ func (o *Objective) Update(raw protocols.ObjectivePayload) (protocols.Objective,
 	updated := o.clone()
 
 	if ss := payload; len(ss.Signatures()) != 0 {
+		if err := updated.V.ValidateProposedState(ss.State()); err != nil {
+			return o, err
+		}
 		updated.V.AddSignedState(*ss)
 	}
 
@@ -393,7 +523,7 @@ func (o *Objective) Update(raw protocols.ObjectivePayload) (protocols.Objective,
 // Crank inspects the extended state and declares a list of Effects to be executed
 // It's like a state machine transition function where the finite / enumerable state is returned (computed from the extended state)
 // rather than being independent of the extended state; and where there is only one type of event ("the crank") with no data on it at all.
-func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
+func (o *Objective) Crank(signer state.Signer) (protocols.Objective, protocols.SideEffects, protocols.WaitingFor, error) {
 	updated := o.clone()
 
 	sideEffects := protocols.SideEffects{}
@@ -405,7 +535,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	// Prefunding
 
 	if !updated.V.PreFundSignedByMe() {
-		ss, err := updated.V.SignAndAddPrefund(secretKey)
+		ss, err := updated.V.SignAndAddPrefund(signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, err
 		}
@@ -414,6 +544,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, err
 		}
+		protocols.AttachMemo(messages, updated.memo)
 
 		sideEffects.MessagesToSend = append(sideEffects.MessagesToSend, messages...)
 	}
@@ -426,7 +557,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	if !updated.isAlice() && !updated.ToMyLeft.IsFundingTheTarget() {
 
-		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyLeft, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyLeft, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -434,7 +565,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 	}
 
 	if !updated.isBob() && !updated.ToMyRight.IsFundingTheTarget() {
-		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyRight, secretKey)
+		ledgerSideEffects, err := updated.updateLedgerWithGuarantee(*updated.ToMyRight, signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, fmt.Errorf("error updating ledger funding: %w", err)
 		}
@@ -447,7 +578,7 @@ func (o *Objective) Crank(secretKey *[]byte) (protocols.Objective, protocols.Sid
 
 	// Postfunding
 	if !updated.V.PostFundSignedByMe() {
-		ss, err := updated.V.SignAndAddPostfund(secretKey)
+		ss, err := updated.V.SignAndAddPostfund(signer)
 		if err != nil {
 			return o, protocols.SideEffects{}, WaitingForNothing, err
 		}
@@ -533,6 +664,8 @@ func (o *Objective) clone() Objective {
 
 	clone.a0 = o.a0
 	clone.b0 = o.b0
+	clone.memo = o.memo
+	clone.fee = o.fee
 	return clone
 }
 
@@ -558,11 +691,18 @@ func ConstructObjectiveFromPayload(
 	myAddress types.Address,
 	getTwoPartyConsensusLedger GetTwoPartyConsensusLedgerFunction,
 ) (Objective, error) {
+	if len(p.Memo) > protocols.MaxMemoLength {
+		return Objective{}, protocols.ErrMemoTooLong
+	}
 	initialState, err := getSignedStatePayload(p.PayloadData)
 	if err != nil {
 		return Objective{}, fmt.Errorf("could not get signed state payload: %w", err)
 	}
 
+	if err := initialState.State().Outcome.Validate(); err != nil {
+		return Objective{}, err
+	}
+
 	participants := initialState.State().Participants
 
 	var leftC *consensus_channel.ConsensusChannel
@@ -613,13 +753,18 @@ func ConstructObjectiveFromPayload(
 		}
 	}
 
-	return constructFromState(
+	objective, err := constructFromState(
 		preapprove,
 		initialState.State(),
 		myAddress,
 		leftC,
 		rightC,
 	)
+	if err != nil {
+		return Objective{}, err
+	}
+	objective.memo = p.Memo
+	return objective, nil
 }
 
 // IsVirtualFundObjective inspects a objective id and returns true if the objective id is for a virtual fund objective.
@@ -640,8 +785,22 @@ func (c *Connection) expectedProposal() consensus_channel.Proposal {
 	return proposal
 }
 
+// expectedProposalFromLeader returns the expected proposal, with the guarantee expiry chosen by the leader of the
+// ledger channel in the proposal it queued (if any). As the follower we accept any expiry, since the guarantee may be
+// reclaimed (cooperatively, or on chain) whether or not it has expired; the expiry only determines when that happens.
+func (c *Connection) expectedProposalFromLeader() consensus_channel.Proposal {
+	proposal := c.expectedProposal()
+	for _, sp := range c.Channel.ProposalQueue() {
+		if sp.Proposal.Type() == consensus_channel.AddProposal && sp.Proposal.Target() == proposal.Target() {
+			proposal.ToAdd.Guarantee = proposal.ToAdd.Guarantee.WithExpiry(sp.Proposal.ToAdd.Expiry())
+			break
+		}
+	}
+	return proposal
+}
+
 // proposeLedgerUpdate will propose a ledger update to the channel by crafting a new state
-func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) proposeLedgerUpdate(connection Connection, signer state.Signer) (protocols.SideEffects, error) {
 	ledger := connection.Channel
 
 	if !ledger.IsLeader() {
@@ -650,7 +809,7 @@ func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (prot
 
 	sideEffects := protocols.SideEffects{}
 
-	_, err := ledger.Propose(connection.expectedProposal(), *sk)
+	_, err := ledger.Propose(connection.expectedProposal(), signer)
 	if err != nil {
 		return protocols.SideEffects{}, err
 	}
@@ -667,9 +826,9 @@ func (o *Objective) proposeLedgerUpdate(connection Connection, sk *[]byte) (prot
 }
 
 // acceptLedgerUpdate checks for a ledger state proposal and accepts that proposal if it satisfies the expected guarantee.
-func (o *Objective) acceptLedgerUpdate(c Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) acceptLedgerUpdate(c Connection, signer state.Signer) (protocols.SideEffects, error) {
 	ledger := c.Channel
-	sp, err := ledger.SignNextProposal(c.expectedProposal(), *sk)
+	sp, err := ledger.SignNextProposal(c.expectedProposalFromLeader(), signer)
 	if err != nil {
 		return protocols.SideEffects{}, fmt.Errorf("no proposed state found for ledger channel %w", err)
 	}
@@ -690,7 +849,7 @@ func (o *Objective) acceptLedgerUpdate(c Connection, sk *[]byte) (protocols.Side
 // updateLedgerWithGuarantee updates the ledger channel funding to include the guarantee.
 // If the user is the proposer a new ledger state will be created and signed.
 // If the user is the follower then they will sign a ledger state proposal if it satisfies their expected guarantees.
-func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[]byte) (protocols.SideEffects, error) {
+func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, signer state.Signer) (protocols.SideEffects, error) {
 	ledger := ledgerConnection.Channel
 
 	var sideEffects protocols.SideEffects
@@ -704,7 +863,7 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 		if proposed {
 			return protocols.SideEffects{}, nil
 		}
-		se, err := o.proposeLedgerUpdate(ledgerConnection, sk)
+		se, err := o.proposeLedgerUpdate(ledgerConnection, signer)
 		if err != nil {
 			return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 		}
@@ -717,7 +876,7 @@ func (o *Objective) updateLedgerWithGuarantee(ledgerConnection Connection, sk *[
 		proposedNext, _ := ledger.IsProposedNext(g)
 		if proposedNext {
 
-			se, err := o.acceptLedgerUpdate(ledgerConnection, sk)
+			se, err := o.acceptLedgerUpdate(ledgerConnection, signer)
 			if err != nil {
 				return protocols.SideEffects{}, fmt.Errorf("error proposing ledger update: %w", err)
 			}
@@ -737,7 +896,13 @@ type ObjectiveRequest struct {
 	Outcome           outcome.Exit
 	Nonce             uint64
 	AppDefinition     types.Address
-	objectiveStarted  chan struct{}
+	// Memo, if set, describes why the channel is requested. It is sent to the counterparty and intermediaries with the
+	// proposal, but is not part of the channel's state.
+	Memo string `json:",omitempty"`
+	// Fee, if set, is the fee the intermediary quoted to route the channel. It is recorded with the channel's opening,
+	// but is not sent to peers.
+	Fee              *big.Int `json:",omitempty"`
+	objectiveStarted chan struct{}
 }
 
 // NewObjectiveRequest creates a new ObjectiveRequest.
@@ -776,9 +941,11 @@ func (r ObjectiveRequest) WaitForObjectiveToStart() {
 }
 
 // ObjectiveResponse is the type returned across the API in response to the ObjectiveRequest.
+// It includes the channel nonce used, which determines the ChannelId.
 type ObjectiveResponse struct {
 	Id        protocols.ObjectiveId
 	ChannelId types.Destination
+	Nonce     uint64
 }
 
 // Response computes and returns the appropriate response from the request.
@@ -788,6 +955,7 @@ func (r ObjectiveRequest) Response(myAddress types.Address) ObjectiveResponse {
 	return ObjectiveResponse{
 		Id:        protocols.ObjectiveId(ObjectivePrefix + channelId.String()),
 		ChannelId: channelId,
+		Nonce:     r.Nonce,
 	}
 }
 
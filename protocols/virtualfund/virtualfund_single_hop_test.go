@@ -224,7 +224,7 @@ func TestCrankAsAlice(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), ledgers[my.Destination()].left, ledgers[my.Destination()].right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(state.KeySigner(my.PrivateKey))
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -236,7 +236,7 @@ func TestCrankAsAlice(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -261,7 +261,7 @@ func TestCrankAsAlice(t *testing.T) {
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
 	// TODO: Check that ledger channel is updated as expected
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	p := consensus_channel.NewAddProposal(o.ToMyRight.Channel.Id, o.ToMyRight.getExpectedGuarantee(), big.NewInt(6))
@@ -272,7 +272,7 @@ func TestCrankAsAlice(t *testing.T) {
 
 	// Check idempotency
 	emptySideEffects := protocols.SideEffects{}
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -285,7 +285,7 @@ func TestCrankAsAlice(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -307,7 +307,7 @@ func TestCrankAsBob(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), ledgers[my.Destination()].left, ledgers[my.Destination()].right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(state.KeySigner(my.PrivateKey))
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -319,7 +319,7 @@ func TestCrankAsBob(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -345,7 +345,7 @@ func TestCrankAsBob(t *testing.T) {
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
 	// TODO: Check that ledger channel is updated as expected
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	emptySideEffects := protocols.SideEffects{}
@@ -354,7 +354,7 @@ func TestCrankAsBob(t *testing.T) {
 	Equals(t, waitingFor, WaitingForCompleteFunding)
 
 	// Check idempotency
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -368,7 +368,7 @@ func TestCrankAsBob(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -393,7 +393,7 @@ func TestCrankAsP1(t *testing.T) {
 		s, _     = constructFromState(false, vPreFund, my.Address(), left, right)
 	)
 	// Assert that cranking an unapproved objective returns an error
-	_, _, _, err := s.Crank(&my.PrivateKey)
+	_, _, _, err := s.Crank(state.KeySigner(my.PrivateKey))
 	Assert(t, err != nil, `Expected error when cranking unapproved objective, but got nil`)
 
 	// Approve the objective, so that the rest of the test cases can run.
@@ -405,7 +405,7 @@ func TestCrankAsP1(t *testing.T) {
 	// need to remember to convert the result back to a virtualfund.Objective struct
 
 	// Initial Crank
-	oObj, effects, waitingFor, err := o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err := o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	expectedSignedState := state.NewSignedState(o.V.PreFundState())
@@ -428,7 +428,7 @@ func TestCrankAsP1(t *testing.T) {
 	assertSupportedPrefund(o, t)
 
 	// Cranking should move us to the next waiting point, update the ledger channel, and alter the extended state to reflect that
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	p := consensus_channel.NewAddProposal(o.ToMyLeft.Channel.Id, o.ToMyLeft.getExpectedGuarantee(), big.NewInt(6))
@@ -439,7 +439,7 @@ func TestCrankAsP1(t *testing.T) {
 
 	// Check idempotency
 	emptySideEffects := protocols.SideEffects{}
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 	Ok(t, err)
 	Equals(t, effects, emptySideEffects)
@@ -453,7 +453,7 @@ func TestCrankAsP1(t *testing.T) {
 	o = oObj.(*Objective)
 	Ok(t, err)
 
-	oObj, effects, waitingFor, err = o.Crank(&my.PrivateKey)
+	oObj, effects, waitingFor, err = o.Crank(state.KeySigner(my.PrivateKey))
 	o = oObj.(*Objective)
 
 	postFS := state.NewSignedState(o.V.PostFundState())
@@ -469,6 +469,64 @@ func TestCrankAsP1(t *testing.T) {
 
 // assertSupportedPrefund checks that all three participants have signed the prefund. It
 // is used to manually inspect the objective after Update receives counterparty signatures.
+// TestWithdraw tests that Alice can withdraw a guarantee which P1 has not countersigned,
+// and that P1 countersigns the withdrawal
+func TestWithdraw(t *testing.T) {
+	td := newTestData()
+	aliceLedgers := td.leaderLedgers[alice.Destination()]
+	p1Ledgers := td.followerLedgers[p1.Destination()]
+
+	s, _ := constructFromState(false, td.vPreFund, alice.Address(), aliceLedgers.left, aliceLedgers.right)
+	a := s.Approve().(*Objective)
+	c := cloneAndSignSetupStateByPeers(*a.V, alice.Role, true)
+	a.V = c
+
+	s, _ = constructFromState(false, td.vPreFund, p1.Address(), p1Ledgers.left, p1Ledgers.right)
+	p := s.Approve().(*Objective)
+
+	// Alice proposes the guarantee, which P1 receives but never countersigns
+	oObj, _, waitingFor, err := a.Crank(state.KeySigner(alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, waitingFor, WaitingForCompleteFunding)
+	a = oObj.(*Objective)
+	proposedAdd := a.ToMyRight.Channel.ProposalQueue()[0]
+	pObj, err := p.ReceiveProposal(proposedAdd)
+	Ok(t, err)
+	p = pObj.(*Objective)
+
+	// Alice withdraws the guarantee
+	a, effects, err := a.Withdraw(state.KeySigner(alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, len(effects.MessagesToSend), 1)
+	sent := effects.MessagesToSend[0]
+	Equals(t, sent.To, p1.Address())
+	Equals(t, len(sent.LedgerProposals), 2)
+	withdrawal := sent.LedgerProposals[1]
+	Equals(t, withdrawal.Proposal.Type(), consensus_channel.RemoveProposal)
+
+	// Withdrawing again is a no-op
+	_, effects, err = a.Withdraw(state.KeySigner(alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, len(effects.MessagesToSend), 0)
+
+	// P1 countersigns the withdrawal, rejecting its objective and notifying Alice and Bob
+	p, effects, err = p.ReceiveWithdrawal(withdrawal, state.KeySigner(p1.PrivateKey))
+	Ok(t, err)
+	Equals(t, p.Status, protocols.Rejected)
+	Equals(t, len(effects.MessagesToSend), 3)
+	countersigned := effects.MessagesToSend[0].LedgerProposals[0]
+	for _, m := range effects.MessagesToSend[1:] {
+		Equals(t, m.RejectedObjectives, []protocols.ObjectiveId{p.Id()})
+	}
+
+	// Alice receives the countersignature, leaving the ledger without the guarantee
+	a, _, err = a.ReceiveWithdrawal(countersigned, state.KeySigner(alice.PrivateKey))
+	Ok(t, err)
+	Equals(t, len(a.ToMyRight.Channel.ProposalQueue()), 0)
+	Equals(t, a.ToMyRight.Channel.ConsensusTurnNum(), withdrawal.TurnNum)
+	Assert(t, !a.ToMyRight.Channel.Includes(a.ToMyRight.getExpectedGuarantee()), "expected the guarantee to be withdrawn")
+}
+
 func assertSupportedPrefund(o *Objective, t *testing.T) {
 	if !o.V.OffChain.SignedStateForTurnNum[0].HasSignatureForParticipant(alice.Role) {
 		t.Fatal(`Objective prefund state not signed by alice`)
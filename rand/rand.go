@@ -25,3 +25,7 @@ func Int63n(i int64) int64 {
 func Int63() int64 {
 	return getRandGenerator().Int63()
 }
+
+func Float64() float64 {
+	return getRandGenerator().Float64()
+}
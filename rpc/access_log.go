@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+// unauthenticatedCaller is logged as the caller of requests without a valid auth token
+const unauthenticatedCaller = "unauthenticated"
+
+// AuditedMethods are the methods which move or commit funds, or change the node's configuration. By default, every
+// request to them is access logged.
+var AuditedMethods = []serde.RequestMethod{
+	serde.CreateLedgerChannelRequestMethod,
+	serde.CreateLedgerChannelsBatchMethod,
+	serde.CloseLedgerChannelRequestMethod,
+	serde.WithdrawFromLedgerChannelMethod,
+	serde.CreatePaymentChannelRequestMethod,
+	serde.GetOrCreatePaymentChannelMethod,
+	serde.ClosePaymentChannelRequestMethod,
+	serde.CloseAllChannelsMethod,
+	serde.CreateObjectiveMethod,
+	serde.CreateSubscriptionMethod,
+	serde.CancelSubscriptionMethod,
+	serde.CreateEscrowMethod,
+	serde.SubmitEscrowResultMethod,
+	serde.DisputeEscrowMethod,
+	serde.PayRequestMethod,
+	serde.CreateVoucherRequestMethod,
+	serde.ReceiveVoucherRequestMethod,
+	serde.SetConfigMethod,
+	serde.SetApiKeyMethod,
+	serde.RemoveApiKeyMethod,
+}
+
+// AccessLogConfig describes which requests are access logged, and where.
+type AccessLogConfig struct {
+	// Logger receives one record per logged request. It should be separate from the application logger.
+	Logger *slog.Logger
+	// SampleRate is the fraction, between 0 and 1, of successful requests to methods other than AlwaysLog which are logged.
+	SampleRate float64
+	// AlwaysLog lists the methods whose requests are always logged. Failed requests are always logged, whatever the method.
+	AlwaysLog []serde.RequestMethod
+	// Auth, if set, is the AuthConfig of the rpc server, with which the caller of each request is identified
+	Auth *AuthConfig
+}
+
+// accessLogResponder is a transport.Responder which access logs the requests handled by the handlers registered with it.
+type accessLogResponder struct {
+	transport.Responder
+	config AccessLogConfig
+	auth   *authenticator
+}
+
+// NewAccessLogResponder returns a transport.Responder which records the method, id, caller, latency, result code and
+// payload sizes of the requests handled by t in an access log, as described by config.
+// The caller is the subject of the request's auth token, or the name of its api key.
+func NewAccessLogResponder(t transport.Responder, config AccessLogConfig) transport.Responder {
+	auth := &authenticator{secret: rpcPK}
+	if config.Auth != nil {
+		// An invalid config is refused by the rpc server, so callers are only misidentified if the server never starts
+		if a, err := newAuthenticator(*config.Auth); err == nil {
+			auth = a
+		}
+	}
+	return &accessLogResponder{Responder: t, config: config, auth: auth}
+}
+
+// RegisterRequestHandler registers the handler with the underlying transport, wrapped so that its requests are logged
+func (a *accessLogResponder) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+	return a.Responder.RegisterRequestHandler(apiVersion, func(requestData []byte) []byte {
+		start := time.Now()
+		responseData := handler(requestData)
+		a.log(apiVersion, requestData, responseData, time.Since(start))
+		return responseData
+	})
+}
+
+// Handle serves handler with the underlying transport, if it can serve other endpoints. Its requests are not logged.
+func (a *accessLogResponder) Handle(path string, handler http.Handler) error {
+	hs, ok := a.Responder.(transport.HandlerServer)
+	if !ok {
+		return fmt.Errorf("the rpc transport cannot serve %s", path)
+	}
+	return hs.Handle(path, handler)
+}
+
+// Rebind rebinds the underlying transport, if it can be rebound.
+func (a *accessLogResponder) Rebind(port string, cert *tls.Certificate) error {
+	r, ok := a.Responder.(transport.Rebinder)
+	if !ok {
+		return serde.RebindUnsupportedError
+	}
+	return r.Rebind(port, cert)
+}
+
+// SetNotificationAddress directs the notifications of the underlying transport to the node's topic, if it publishes
+// notifications to a topic of each node.
+func (a *accessLogResponder) SetNotificationAddress(address string) {
+	if n, ok := a.Responder.(transport.AddressedNotifier); ok {
+		n.SetNotificationAddress(address)
+	}
+}
+
+func (a *accessLogResponder) log(apiVersion string, requestData []byte, responseData []byte, latency time.Duration) {
+	// Malformed requests are logged with whatever could be parsed
+	var request struct {
+		Id     serde.RequestId `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			AuthToken string `json:"authtoken"`
+		} `json:"params"`
+	}
+	_ = json.Unmarshal(requestData, &request)
+
+	var response struct {
+		Error *serde.JsonRpcError `json:"error"`
+	}
+	_ = json.Unmarshal(responseData, &response)
+	code := int64(0)
+	if response.Error != nil {
+		code = response.Error.Code
+	}
+
+	method := serde.RequestMethod(request.Method)
+	if code == 0 && !slices.Contains(a.config.AlwaysLog, method) && rand.Float64() >= a.config.SampleRate {
+		return
+	}
+
+	a.config.Logger.Info("rpc request",
+		"api-version", apiVersion,
+		"method", method,
+		"id", request.Id,
+		"caller", a.auth.subject(request.Params.AuthToken),
+		"latency", latency,
+		"code", code,
+		"request-bytes", len(requestData),
+		"response-bytes", len(responseData),
+	)
+}
@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLog(t *testing.T) {
+	buf := &bytes.Buffer{}
+	newServer := func(sampleRate float64) *mockResponder {
+		mockResponder := &mockResponder{}
+		config := AccessLogConfig{
+			Logger:     slog.New(slog.NewJSONHandler(buf, nil)),
+			SampleRate: sampleRate,
+			AlwaysLog:  AuditedMethods,
+		}
+		_, err := newRpcServerWithoutNotifications(&nitro.Node{}, NewAccessLogResponder(mockResponder, config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder
+	}
+	send := func(responder *mockResponder, method serde.RequestMethod, authToken string, payload any) {
+		request := map[string]any{
+			"jsonrpc": "2.0",
+			"id":      7,
+			"method":  method,
+			"params":  map[string]any{"authtoken": authToken, "payload": payload},
+		}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responder.Handler(jsonRequest)
+	}
+	records := func() []map[string]any {
+		var rs []map[string]any
+		dec := json.NewDecoder(buf)
+		for dec.More() {
+			r := map[string]any{}
+			if err := dec.Decode(&r); err != nil {
+				t.Fatal(err)
+			}
+			rs = append(rs, r)
+		}
+		return rs
+	}
+
+	authToken, err := generateAuthToken(rpcPK, "auditor", allPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	computeChannelId := serde.ComputeChannelIdRequest{Participants: []types.Address{{1}, {2}}}
+
+	// Successful requests to methods which are not always logged are sampled
+	responder := newServer(0)
+	send(responder, serde.ComputeChannelIdMethod, "", computeChannelId)
+	assert.Empty(t, records())
+
+	responder = newServer(1)
+	send(responder, serde.ComputeChannelIdMethod, "", computeChannelId)
+	logged := records()
+	assert.Len(t, logged, 1)
+	assert.Equal(t, string(serde.ComputeChannelIdMethod), logged[0]["method"])
+	assert.Equal(t, unauthenticatedCaller, logged[0]["caller"])
+	assert.EqualValues(t, 0, logged[0]["code"])
+
+	// Failed requests, and requests to audited methods, are always logged, with the caller named by the auth token
+	responder = newServer(0)
+	send(responder, serde.PayRequestMethod, authToken, serde.PaymentRequest{Amount: serde.NewQuantity(1)})
+	logged = records()
+	assert.Len(t, logged, 1)
+	assert.Equal(t, string(serde.PayRequestMethod), logged[0]["method"])
+	assert.Equal(t, "auditor", logged[0]["caller"])
+	assert.EqualValues(t, 7, logged[0]["id"])
+	assert.EqualValues(t, serde.InvalidParamsError.Code, logged[0]["code"])
+	assert.NotZero(t, logged[0]["request-bytes"])
+}
@@ -1,27 +1,104 @@
 package rpc
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
 )
 
-// todo: the private key should not be hardcoded
+// rpcPK signs the auth tokens of servers which are not configured with api keys, and so issue a token to any client
 var rpcPK = []byte("rpcPK")
 
+// DefaultTokenTtl is how long an auth token is valid for, unless configured otherwise
+const DefaultTokenTtl = 7 * 24 * time.Hour
+
 type permission string
 
 const permissionKey = "perm"
 const (
-	permNone permission = "none"
-	permRead permission = "read"
-	permSign permission = "sign"
+	permNone  permission = "none"
+	permRead  permission = "read"
+	permSign  permission = "sign"
+	permAdmin permission = "admin"
+)
+
+const (
+	// methodsKey is the claim listing the methods an auth token is restricted to, if it is
+	methodsKey = "methods"
+	// credentialKey is the claim identifying the api key an auth token was issued for, as it was when the token was
+	// issued, so that the token is revoked when the key is replaced or removed
+	credentialKey = "cred"
 )
 
 var allPermissions = []permission{permRead, permSign}
 
+// Scope is the access granted to the holder of an api key.
+type Scope string
+
+const (
+	// ReadScope permits the methods which query the node, such as get_ledger_channel
+	ReadScope Scope = "read"
+	// SignScope permits the methods which query the node and those which sign states or move funds, such as pay and
+	// create_ledger_channel, but not those which operate the node
+	SignScope Scope = "sign"
+	// AdminScope permits every method, including those which operate the node, such as set_config, set_log_level,
+	// set_message_faults, get_debug_bundle, rebind_rpc_server, block_peer, unblock_peer and close_all_channels, and
+	// those which manage the api keys of the server, such as set_api_key
+	AdminScope Scope = "admin"
+)
+
+// permissions returns the permissions of the scope
+func (s Scope) permissions() ([]permission, error) {
+	switch s {
+	case ReadScope:
+		return []permission{permRead}, nil
+	case SignScope:
+		return allPermissions, nil
+	case AdminScope:
+		return append(slices.Clone(allPermissions), permAdmin), nil
+	default:
+		return nil, fmt.Errorf("unknown scope %q: expected %q, %q or %q", s, ReadScope, SignScope, AdminScope)
+	}
+}
+
+// ApiKey is a secret which a client presents to the rpc server for access to the methods its scope permits.
+type ApiKey struct {
+	// Name identifies the client, as the subject of its auth tokens and in the access log
+	Name string
+	// Secret is presented by the client, either to get_auth_token or in place of an auth token
+	Secret string
+	Scope  Scope
+	// Methods, if not empty, are the only methods the key permits of those its scope permits, such as receive_voucher
+	// and get_payment_channel for a billing service. Methods which need no permission, such as version, are permitted
+	// whatever its methods.
+	Methods []string
+}
+
+// AuthConfig describes how the rpc server authenticates its clients.
+//
+// Without api keys, the server issues an auth token permitting every method to any client which asks for one, so
+// anyone who can reach the rpc port can move the node's funds. With api keys, a token is only issued to a client
+// presenting one of the keys, and only permits the methods of the key's scope.
+type AuthConfig struct {
+	// ApiKeys are the keys which clients may present
+	ApiKeys []ApiKey
+	// TokenSecret signs the auth tokens which the server issues. JWTs signed with it elsewhere, which carry a "perm"
+	// claim listing "read", "sign" and/or "admin", and optionally a "methods" claim restricting them to the methods it
+	// lists, are also accepted. It is required if there are api keys.
+	TokenSecret []byte
+	// TokenTtl is how long after it is issued an auth token is valid for. Zero means DefaultTokenTtl.
+	TokenTtl time.Duration
+}
+
 var (
 	errInvalidSigningMethod = errors.New("invalid signing method")
 	errInvalidToken         = errors.New("invalid token")
@@ -29,41 +106,340 @@ var (
 	errInvalidPermissions   = errors.New("token has invalid permissions")
 	errInvalidPermission    = errors.New("token has an invalid permission")
 	errMissingPermission    = errors.New("token is missing permission")
+	errInvalidApiKey        = errors.New("invalid api key")
+	errInvalidMethods       = errors.New("token has invalid methods")
+	errMethodNotPermitted   = errors.New("method is not permitted")
+	errRevokedToken         = errors.New("token has been revoked")
+	errUnknownApiKey        = errors.New("unknown api key")
+	errLastApiKey           = errors.New("the last api key cannot be removed")
 )
 
+// unrestrictedMethods need no permission, so are permitted whatever the methods of an api key
+var unrestrictedMethods = []serde.RequestMethod{
+	serde.GetAuthTokenMethod,
+	serde.GetAddressMethod,
+	serde.VersionMethod,
+	serde.NegotiateVersionMethod,
+	serde.ComputeChannelIdMethod,
+	serde.ComputeStateHashMethod,
+	serde.ComputeVoucherHashMethod,
+}
+
 var invalidIAtFormat = "invalid issued at: %w"
 
-// generateAuthToken generates a JWT token that a client uses to authenticate with the server for restricted endpoints
-// subject is the identifier of the client for which the token is generated
-func generateAuthToken(subject string, p []permission) (string, error) {
+// authenticator issues and checks the auth tokens of an rpc server, as configured by an AuthConfig. Its api keys may
+// be changed while the server runs, with setApiKey and removeApiKey.
+type authenticator struct {
+	secret []byte
+	ttl    time.Duration
+
+	mu   sync.RWMutex
+	keys []ApiKey
+	// credentials maps the name of each api key to the credential id which the tokens issued for it carry
+	credentials map[string]string
+}
+
+// newAuthenticator returns an authenticator for the config, or an error if the config is invalid
+func newAuthenticator(config AuthConfig) (*authenticator, error) {
+	a := &authenticator{keys: config.ApiKeys, secret: config.TokenSecret, ttl: config.TokenTtl, credentials: map[string]string{}}
+	if a.ttl == 0 {
+		a.ttl = DefaultTokenTtl
+	}
+	if len(a.secret) == 0 {
+		if len(a.keys) > 0 {
+			return nil, errors.New("a token secret is required to authenticate with api keys")
+		}
+		a.secret = rpcPK
+	}
+
+	if err := validateApiKeys(a.keys); err != nil {
+		return nil, err
+	}
+	for _, key := range a.keys {
+		a.credentials[key.Name] = newRandomId()
+	}
+	return a, nil
+}
+
+// validateApiKeys checks that each key has a name, a secret and a known scope, and that no two keys share a name
+func validateApiKeys(keys []ApiKey) error {
+	names, secrets := map[string]bool{}, map[string]string{}
+	for _, key := range keys {
+		if key.Name == "" || key.Secret == "" {
+			return errors.New("api keys require a name and a secret")
+		}
+		if names[key.Name] {
+			return fmt.Errorf("duplicate api key name %s", key.Name)
+		}
+		names[key.Name] = true
+		// A secret shared by keys would be matched to either of them, so its holders could not be told apart
+		if other, ok := secrets[key.Secret]; ok {
+			return fmt.Errorf("api keys %s and %s have the same secret", other, key.Name)
+		}
+		secrets[key.Secret] = key.Name
+		if _, err := key.Scope.permissions(); err != nil {
+			return fmt.Errorf("api key %s: %w", key.Name, err)
+		}
+		if slices.Contains(key.Methods, "") {
+			return fmt.Errorf("api key %s: empty method", key.Name)
+		}
+	}
+	return nil
+}
+
+// newRandomId returns a random id, such as that of an api key, which the tokens issued for it carry, or that of a stream
+func newRandomId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+
+// apiKeys returns the api keys of the server
+func (a *authenticator) apiKeys() []ApiKey {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return slices.Clone(a.keys)
+}
+
+// setApiKey adds the key, or replaces the key with the same name, revoking the tokens issued for it. Keys may only be
+// added to a server configured with api keys, as any client is issued a token by a server without them.
+func (a *authenticator) setApiKey(key ApiKey) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.keys) == 0 {
+		return errors.New("api keys cannot be added to a server configured without them")
+	}
+	keys := slices.DeleteFunc(slices.Clone(a.keys), func(k ApiKey) bool { return k.Name == key.Name })
+	keys = append(keys, key)
+	if err := validateApiKeys(keys); err != nil {
+		return err
+	}
+	a.keys = keys
+	a.credentials[key.Name] = newRandomId()
+	return nil
+}
+
+// removeApiKey removes the key with the name, revoking the tokens issued for it. The last key cannot be removed, as
+// any client would then be issued a token.
+func (a *authenticator) removeApiKey(name string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	i := slices.IndexFunc(a.keys, func(k ApiKey) bool { return k.Name == name })
+	if i < 0 {
+		return fmt.Errorf("%w %s", errUnknownApiKey, name)
+	}
+	if len(a.keys) == 1 {
+		return errLastApiKey
+	}
+	a.keys = slices.Delete(slices.Clone(a.keys), i, i+1)
+	delete(a.credentials, name)
+	return nil
+}
+
+// apiKey returns the api key with the secret, if there is one
+func (a *authenticator) apiKey(secret string) (ApiKey, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	found, match := ApiKey{}, false
+	// Every key is compared, in constant time, so as not to reveal how much of a secret was guessed
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare([]byte(key.Secret), []byte(secret)) == 1 {
+			found, match = key, true
+		}
+	}
+	return found, match
+}
+
+// issueToken returns an auth token for the client presenting apiKey. subject identifies the client when there are no
+// api keys, in which case any client is issued a token with every permission.
+func (a *authenticator) issueToken(apiKey string, subject string) (string, error) {
+	a.mu.RLock()
+	keyless := len(a.keys) == 0
+	a.mu.RUnlock()
+	if keyless {
+		p, _ := AdminScope.permissions()
+		return generateAuthToken(a.secret, subject, p)
+	}
+	key, ok := a.apiKey(apiKey)
+	if !ok {
+		return "", errInvalidApiKey
+	}
+	p, _ := key.Scope.permissions()
+	a.mu.RLock()
+	credential := a.credentials[key.Name]
+	a.mu.RUnlock()
+	claims := jwt.MapClaims{credentialKey: credential}
+	if len(key.Methods) > 0 {
+		claims[methodsKey] = key.Methods
+	}
+	return generateAuthToken(a.secret, key.Name, p, claims)
+}
+
+// authorize checks that the token, which is an auth token or an api key, has the required permission
+func (a *authenticator) authorize(token string, required permission) error {
+	if required == permNone {
+		return nil
+	}
+	if key, ok := a.apiKey(token); ok {
+		p, _ := key.Scope.permissions()
+		for _, pp := range p {
+			if pp == required {
+				return nil
+			}
+		}
+		return errMissingPermission
+	}
+	return checkTokenValidity(a.secret, token, required, a.ttl)
+}
+
+// authorizeMethod checks that the token, which is an auth token or an api key, permits the method, if it is restricted
+// to some methods, and that it has not been revoked. Whether it has the permission the method requires is checked by
+// authorize.
+func (a *authenticator) authorizeMethod(token string, method serde.RequestMethod) error {
+	if token == "" || slices.Contains(unrestrictedMethods, method) {
+		return nil
+	}
+	if key, ok := a.apiKey(token); ok {
+		return permitsMethod(key.Methods, method)
+	}
+
+	parsed, err := parseToken(a.secret, token)
+	if err != nil {
+		return err
+	}
+	claims := parsed.Claims.(jwt.MapClaims)
+	if err := a.checkCredential(claims); err != nil {
+		return err
+	}
+	var methods []string
+	if raw, ok := claims[methodsKey]; ok {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return errInvalidMethods
+		}
+		for _, m := range list {
+			sm, ok := m.(string)
+			if !ok {
+				return errInvalidMethods
+			}
+			methods = append(methods, sm)
+		}
+	}
+	return permitsMethod(methods, method)
+}
+
+// checkCredential checks that the api key the claims were issued for, if any, has not been replaced or removed since
+func (a *authenticator) checkCredential(claims jwt.MapClaims) error {
+	credential, ok := claims[credentialKey]
+	if !ok {
+		return nil
+	}
+	subject, _ := claims.GetSubject()
+	a.mu.RLock()
+	current, exists := a.credentials[subject]
+	a.mu.RUnlock()
+	if !exists || credential != current {
+		return errRevokedToken
+	}
+	return nil
+}
+
+// authorizeSubscription checks that the token, which is an auth token or an api key, may subscribe to notifications.
+// That requires the read permission, whatever methods the token is restricted to, as every client subscribes.
+func (a *authenticator) authorizeSubscription(token string) error {
+	if token == "" {
+		return errInvalidToken
+	}
+	if err := a.authorize(token, permRead); err != nil {
+		return err
+	}
+	if _, ok := a.apiKey(token); ok {
+		return nil
+	}
+	parsed, err := parseToken(a.secret, token)
+	if err != nil {
+		return err
+	}
+	return a.checkCredential(parsed.Claims.(jwt.MapClaims))
+}
+
+// permitsMethod checks that the method is one of methods, unless there are none, which permits every method
+func permitsMethod(methods []string, method serde.RequestMethod) error {
+	if len(methods) > 0 && !slices.Contains(methods, string(method)) {
+		return fmt.Errorf("%w: %s", errMethodNotPermitted, method)
+	}
+	return nil
+}
+
+// subject returns the name of the client presenting the token, which is an auth token or an api key, or
+// unauthenticatedCaller if the token is missing or invalid
+func (a *authenticator) subject(token string) string {
+	if token == "" {
+		return unauthenticatedCaller
+	}
+	if key, ok := a.apiKey(token); ok {
+		return key.Name
+	}
+	parsed, err := parseToken(a.secret, token)
+	if err != nil {
+		return unauthenticatedCaller
+	}
+	subject, err := parsed.Claims.GetSubject()
+	if err != nil || subject == "" {
+		return unauthenticatedCaller
+	}
+	return subject
+}
+
+// generateAuthToken generates a JWT token, signed with secret, that a client uses to authenticate with the server for
+// restricted endpoints
+// subject is the identifier of the client for which the token is generated, and extra holds any further claims
+func generateAuthToken(secret []byte, subject string, p []permission, extra ...jwt.MapClaims) (string, error) {
 	token := jwt.New(jwt.SigningMethodHS256)
 	claims := token.Claims.(jwt.MapClaims)
+	for _, e := range extra {
+		for k, v := range e {
+			claims[k] = v
+		}
+	}
 	claims[permissionKey] = p
 	// the keys are defined by https://datatracker.ietf.org/doc/html/rfc7519
 	claims["iat"] = time.Now().Unix()
 	claims["sub"] = subject
-	return token.SignedString(rpcPK)
+	return token.SignedString(secret)
 }
 
-// checkTokenValidity takes a JWT token, verifies that the token is valid and that the token contains the required permission
-func checkTokenValidity(tokenString string, requiredPermission permission, validDuration time.Duration) error {
-	if requiredPermission == permNone {
-		return nil
-	}
-
+// parseToken parses the JWT token, checking that it is signed with secret
+func parseToken(secret []byte, tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		_, ok := token.Method.(*jwt.SigningMethodHMAC)
 		if !ok {
 			return nil, errInvalidSigningMethod
 		}
-		return rpcPK, nil
+		return secret, nil
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if !token.Valid {
-		return errInvalidToken
+		return nil, errInvalidToken
+	}
+	return token, nil
+}
+
+// checkTokenValidity takes a JWT token, verifies that the token is signed with secret and valid, and that the token
+// contains the required permission
+func checkTokenValidity(secret []byte, tokenString string, requiredPermission permission, validDuration time.Duration) error {
+	if requiredPermission == permNone {
+		return nil
+	}
+
+	token, err := parseToken(secret, tokenString)
+	if err != nil {
+		return err
 	}
 
 	claims := token.Claims.(jwt.MapClaims)
@@ -4,40 +4,228 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
 )
 
 func TestValidAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	token, err := generateAuthToken(rpcPK, "1", allPermissions)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Hour)
+	err = checkTokenValidity(rpcPK, token, permSign, time.Hour)
 	if err != nil {
 		t.Fatal(err)
 	}
 }
 
 func TestAuthTokenMissingPermission(t *testing.T) {
-	token, err := generateAuthToken("1", []permission{permRead})
+	token, err := generateAuthToken(rpcPK, "1", []permission{permRead})
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Hour)
+	err = checkTokenValidity(rpcPK, token, permSign, time.Hour)
 	if !errors.Is(err, errMissingPermission) {
 		t.Fatal("expected errMissingPermission, got", err)
 	}
 }
 
 func TestExpiredAuthToken(t *testing.T) {
-	token, err := generateAuthToken("1", allPermissions)
+	token, err := generateAuthToken(rpcPK, "1", allPermissions)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	err = checkTokenValidity(token, permSign, time.Duration(0))
+	err = checkTokenValidity(rpcPK, token, permSign, time.Duration(0))
 	if !errors.Is(err, errExpiredToken) {
 		t.Fatal("expected errExpiredToken, got", err)
 	}
 }
+
+func TestApiKeyScopes(t *testing.T) {
+	auth, err := newAuthenticator(AuthConfig{
+		ApiKeys: []ApiKey{
+			{Name: "dashboard", Secret: "read-secret", Scope: ReadScope},
+			{Name: "wallet", Secret: "sign-secret", Scope: SignScope},
+		},
+		TokenSecret: []byte("token-secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := auth.issueToken("", "anyone"); !errors.Is(err, errInvalidApiKey) {
+		t.Fatal("expected errInvalidApiKey without an api key, got", err)
+	}
+	if _, err := auth.issueToken("guess", "anyone"); !errors.Is(err, errInvalidApiKey) {
+		t.Fatal("expected errInvalidApiKey for an unknown api key, got", err)
+	}
+
+	readToken, err := auth.issueToken("read-secret", "anyone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(readToken, permRead); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(readToken, permSign); !errors.Is(err, errMissingPermission) {
+		t.Fatal("expected errMissingPermission, got", err)
+	}
+	if subject := auth.subject(readToken); subject != "dashboard" {
+		t.Fatal("expected the token to name its api key, got", subject)
+	}
+
+	signToken, err := auth.issueToken("sign-secret", "anyone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(signToken, permSign); err != nil {
+		t.Fatal(err)
+	}
+	// Operating the node, such as setting its log level, needs an admin key
+	if err := auth.authorize(signToken, permAdmin); !errors.Is(err, errMissingPermission) {
+		t.Fatal("expected errMissingPermission, got", err)
+	}
+
+	// An api key may be presented in place of a token
+	if err := auth.authorize("sign-secret", permSign); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize("read-secret", permSign); !errors.Is(err, errMissingPermission) {
+		t.Fatal("expected errMissingPermission, got", err)
+	}
+	if subject := auth.subject("read-secret"); subject != "dashboard" {
+		t.Fatal("expected the api key's name, got", subject)
+	}
+
+	// Tokens which are not signed with the token secret are refused, such as those of servers without api keys
+	forged, err := generateAuthToken(rpcPK, "wallet", allPermissions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(forged, permSign); err == nil {
+		t.Fatal("expected a token signed with another secret to be refused")
+	}
+	if subject := auth.subject(forged); subject != unauthenticatedCaller {
+		t.Fatal("expected a forged token to be unauthenticated, got", subject)
+	}
+}
+
+func TestApiKeyMethods(t *testing.T) {
+	auth, err := newAuthenticator(AuthConfig{
+		ApiKeys: []ApiKey{
+			{Name: "billing", Secret: "billing-secret", Scope: ReadScope, Methods: []string{"receive_voucher", "get_payment_channel"}},
+			{Name: "operator", Secret: "admin-secret", Scope: AdminScope},
+		},
+		TokenSecret: []byte("token-secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	billingToken, err := auth.issueToken("billing-secret", "anyone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{billingToken, "billing-secret"} {
+		if err := auth.authorizeMethod(token, serde.ReceiveVoucherRequestMethod); err != nil {
+			t.Fatal(err)
+		}
+		if err := auth.authorizeMethod(token, serde.GetLedgerChannelRequestMethod); !errors.Is(err, errMethodNotPermitted) {
+			t.Fatal("expected errMethodNotPermitted, got", err)
+		}
+		// Methods which need no permission are permitted whatever the key's methods
+		if err := auth.authorizeMethod(token, serde.VersionMethod); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	adminToken, err := auth.issueToken("admin-secret", "anyone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorize(adminToken, permAdmin); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(adminToken, serde.GetLedgerChannelRequestMethod); err != nil {
+		t.Fatal(err)
+	}
+
+	// Replacing a key revokes the tokens issued for it, but not the tokens of other keys
+	if err := auth.setApiKey(ApiKey{Name: "billing", Secret: "new-secret", Scope: ReadScope, Methods: []string{"receive_voucher"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(billingToken, serde.ReceiveVoucherRequestMethod); !errors.Is(err, errRevokedToken) {
+		t.Fatal("expected errRevokedToken, got", err)
+	}
+	if err := auth.authorizeMethod(adminToken, serde.ReceiveVoucherRequestMethod); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.issueToken("billing-secret", "anyone"); !errors.Is(err, errInvalidApiKey) {
+		t.Fatal("expected the replaced secret to be refused, got", err)
+	}
+	billingToken, err = auth.issueToken("new-secret", "anyone")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(billingToken, serde.GetPaymentChannelRequestMethod); !errors.Is(err, errMethodNotPermitted) {
+		t.Fatal("expected errMethodNotPermitted, got", err)
+	}
+
+	if err := auth.setApiKey(ApiKey{Name: "other", Secret: "s", Scope: "root"}); err == nil {
+		t.Fatal("expected a key with an unknown scope to be refused")
+	}
+	if err := auth.setApiKey(ApiKey{Name: "other", Secret: "new-secret", Scope: ReadScope}); err == nil {
+		t.Fatal("expected a key with another key's secret to be refused")
+	}
+
+	// Removing a key revokes the tokens issued for it, and the last key cannot be removed
+	if err := auth.removeApiKey("billing"); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(billingToken, serde.ReceiveVoucherRequestMethod); !errors.Is(err, errRevokedToken) {
+		t.Fatal("expected errRevokedToken, got", err)
+	}
+	if err := auth.removeApiKey("billing"); !errors.Is(err, errUnknownApiKey) {
+		t.Fatal("expected errUnknownApiKey, got", err)
+	}
+	if err := auth.removeApiKey("operator"); !errors.Is(err, errLastApiKey) {
+		t.Fatal("expected errLastApiKey, got", err)
+	}
+
+	// JWTs signed elsewhere may be restricted to some methods by their claims
+	external, err := generateAuthToken([]byte("token-secret"), "service", []permission{permRead}, jwt.MapClaims{methodsKey: []string{"get_payment_channel"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(external, serde.GetPaymentChannelRequestMethod); err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.authorizeMethod(external, serde.ReceiveVoucherRequestMethod); !errors.Is(err, errMethodNotPermitted) {
+		t.Fatal("expected errMethodNotPermitted, got", err)
+	}
+}
+
+func TestInvalidAuthConfig(t *testing.T) {
+	for name, config := range map[string]AuthConfig{
+		"no token secret": {ApiKeys: []ApiKey{{Name: "a", Secret: "s", Scope: ReadScope}}},
+		"no secret":       {ApiKeys: []ApiKey{{Name: "a", Scope: ReadScope}}, TokenSecret: []byte("t")},
+		"unknown scope":   {ApiKeys: []ApiKey{{Name: "a", Secret: "s", Scope: "root"}}, TokenSecret: []byte("t")},
+		"duplicate name": {
+			ApiKeys:     []ApiKey{{Name: "a", Secret: "s", Scope: ReadScope}, {Name: "a", Secret: "r", Scope: SignScope}},
+			TokenSecret: []byte("t"),
+		},
+		"duplicate secret": {
+			ApiKeys:     []ApiKey{{Name: "a", Secret: "s", Scope: ReadScope}, {Name: "b", Secret: "s", Scope: SignScope}},
+			TokenSecret: []byte("t"),
+		},
+	} {
+		if _, err := newAuthenticator(config); err == nil {
+			t.Errorf("%s: expected the config to be refused", name)
+		}
+	}
+}
@@ -2,14 +2,19 @@ package rpc
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"math/big"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/channel/state/outcome"
 	"github.com/statechannels/go-nitro/internal/logging"
 	"github.com/statechannels/go-nitro/internal/safesync"
@@ -32,10 +37,23 @@ type RpcClientApi interface {
 	// Address returns the address of the nitro node
 	Address() (common.Address, error)
 
+	// ApiVersion returns the version of the rpc api negotiated with the server
+	ApiVersion() string
+
+	// Version returns the version of the nitro node, and of the rpc api serving the client
+	Version() (serde.VersionResponse, error)
+
 	// CreateVoucher creates a voucher for the given channelId and amount and returns it.
 	// It is the responsibility of the caller to send the voucher to the payee.
 	CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error)
 
+	// CreateVoucherWithId is like CreateVoucher, but creates a voucher at most once for the payment id: retries return
+	// the voucher created by the first request.
+	CreateVoucherWithId(chId types.Destination, amount uint64, paymentId string) (payments.Voucher, error)
+
+	// CreateVoucherWithExpiry is like CreateVoucherWithId, but the payee refuses the voucher after expiry.
+	CreateVoucherWithExpiry(chId types.Destination, amount uint64, paymentId string, expiry time.Time) (payments.Voucher, error)
+
 	// ReceiveVoucher receives a voucher and adds it to the go-nitro store.
 	// It returns the total amount received so far and the amount received from the voucher supplied.
 	// It can be used to add a voucher that was sent outside of the go-nitro system.
@@ -47,27 +65,212 @@ type RpcClientApi interface {
 	// CreatePaymentChannel creates a new virtual payment channel with the specified intermediaries, counterparty, ChallengeDuration, and outcome
 	CreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (virtualfund.ObjectiveResponse, error)
 
+	// GetOrCreatePaymentChannel reuses an open payment channel to the counterparty with enough remaining funds to pay what
+	// the outcome allocates to us, or else creates one with the specified intermediaries, ChallengeDuration, and outcome.
+	// It requires v2 of the rpc api.
+	GetOrCreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (serde.GetOrCreatePaymentChannelResponse, error)
+
+	// CreateTaggedPaymentChannel is like CreatePaymentChannel, but attaches the tags to the channel
+	CreateTaggedPaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, tags map[string]string) (virtualfund.ObjectiveResponse, error)
+
+	// CreatePaymentChannelWithMemo is like CreatePaymentChannel, but sends the memo, describing why the channel is
+	// requested, to the counterparty and intermediaries
+	CreatePaymentChannelWithMemo(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, memo string) (virtualfund.ObjectiveResponse, error)
+
 	// ClosePaymentChannel attempts to close the payment channel with the specified channelId
 	ClosePaymentChannel(id types.Destination) (protocols.ObjectiveId, error)
 
+	// CloseAllChannels starts closing every open payment and ledger channel of the node, waiting up to timeout for each
+	// stage of cooperative closes and, if challenge is set, challenging ledger channels which are not closed in time.
+	// It returns the channels to be closed. It requires v2 of the rpc api.
+	CloseAllChannels(timeout time.Duration, challenge bool) (query.CloseAllInfo, error)
+
+	// GetCloseAllProgress returns the progress of the latest CloseAllChannels. It requires v2 of the rpc api.
+	GetCloseAllProgress() (query.CloseAllInfo, error)
+
+	// CreateLedgerChannels starts opening a ledger channel with each of the counterparties, funded as described by the
+	// request, returning the initial progress of the batch. It requires v2 of the rpc api.
+	CreateLedgerChannels(req serde.CreateLedgerChannelsBatchRequest) (query.LedgerChannelBatchInfo, error)
+
+	// GetLedgerChannelsBatch returns the progress of the batch of ledger channels with the given id. It requires v2 of
+	// the rpc api.
+	GetLedgerChannelsBatch(id string) (query.LedgerChannelBatchInfo, error)
+
+	// ProbeCounterparty asks the counterparty whether it would fund a channel holding the asset, to which the node
+	// deposits deposit and the counterparty counterDeposit. It requires v2 of the rpc api.
+	ProbeCounterparty(counterparty types.Address, asset types.Address, deposit *big.Int, counterDeposit *big.Int) (protocols.Probe, error)
+
+	// CreateSubscription starts paying amount through the payment channel every interval, until limit has been paid.
+	// It requires v2 of the rpc api.
+	CreateSubscription(channelId types.Destination, amount uint64, interval time.Duration, limit uint64) (query.SubscriptionInfo, error)
+
+	// GetSubscription returns the subscription with the given id. It requires v2 of the rpc api.
+	GetSubscription(id protocols.ObjectiveId) (query.SubscriptionInfo, error)
+
+	// CancelSubscription ends the subscription with the given id, telling the other party the reason. It requires v2 of
+	// the rpc api.
+	CancelSubscription(id protocols.ObjectiveId, reason string) (query.SubscriptionInfo, error)
+
+	// CreateEscrow opens an escrow paying amount through the payment channel for a result whose keccak256 hash is
+	// commitment, delivered within timeout. It requires v2 of the rpc api.
+	CreateEscrow(channelId types.Destination, amount uint64, commitment types.Bytes32, timeout time.Duration) (query.EscrowInfo, error)
+
+	// GetEscrow returns the escrow with the given id. It requires v2 of the rpc api.
+	GetEscrow(id protocols.ObjectiveId) (query.EscrowInfo, error)
+
+	// SubmitEscrowResult delivers the result of the escrow with the given id to its payer. It requires v2 of the rpc api.
+	SubmitEscrowResult(id protocols.ObjectiveId, result []byte) (query.EscrowInfo, error)
+
+	// DisputeEscrow disputes the escrow with the given id, whose result was not released, closing its payment channel.
+	// It requires v2 of the rpc api.
+	DisputeEscrow(id protocols.ObjectiveId, reason string) (query.EscrowInfo, error)
+
+	// CreateObjective starts an objective of an application-specific type, identified by the id prefix it was registered
+	// with on the node. The request is marshalled to JSON for the type to decode. It requires v2 of the rpc api.
+	CreateObjective(objectiveType string, request any) (protocols.ObjectiveId, error)
+
 	// GetLedgerChannel returns the ledger channel information for the given channelId
 	GetLedgerChannel(id types.Destination) (query.LedgerChannelInfo, error)
 
 	// GetAllLedgerChannels returns information about all ledger channels
 	GetAllLedgerChannels() ([]query.LedgerChannelInfo, error)
 
+	// StreamAllLedgerChannels returns information about all ledger channels, as a channel which receives them in
+	// pages of at most pageSize (or serde.DefaultStreamPageSize if pageSize is zero) and is closed after the last page.
+	// The returned channel must be drained, since notifications are not processed while a page is waiting to be received.
+	StreamAllLedgerChannels(pageSize uint64) (<-chan []query.LedgerChannelInfo, error)
+
 	// GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 	GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error)
 
+	// ListLedgerChannels returns a page of the ledger channels selected by the filter, ordered by id. The NextCursor of
+	// the page requests the next one. It requires v2 of the rpc api.
+	ListLedgerChannels(filter query.ChannelFilter, page query.PageRequest) (query.LedgerChannelPage, error)
+
+	// ListPaymentChannelsByLedger returns a page of the active payment channels funded by the ledger channel which are
+	// selected by the filter, ordered by id. It requires v2 of the rpc api.
+	ListPaymentChannelsByLedger(ledgerId types.Destination, filter query.ChannelFilter, page query.PageRequest) (query.PaymentChannelPage, error)
+
+	// GetSignedState returns the latest supported signed state for the given channelId, in both json and abi encoded form
+	GetSignedState(id types.Destination) (query.SignedStateInfo, error)
+
+	// GetChannelSnapshot returns a signed snapshot of the payment channel's latest supported state and largest voucher,
+	// which a third party can verify with ChannelSnapshot.Verify
+	GetChannelSnapshot(id types.Destination) (payments.ChannelSnapshot, error)
+
+	// GetBalanceHistory returns the balance snapshots of the given channel (or every channel, if id is the zero destination) taken in [from, to)
+	GetBalanceHistory(id types.Destination, from, to time.Time) ([]query.BalanceSnapshotInfo, error)
+
+	// ExportActivity returns an export, in csv or jsonl format, of the payments and channel openings and closings in [from, to)
+	ExportActivity(format string, from, to time.Time) (serde.ExportActivityResponse, error)
+
+	// StreamActivityExport writes an export, as ExportActivity returns, to w as it is streamed by the node, so that
+	// neither holds large ranges in memory. It returns once the export is complete. It requires v2 of the rpc api.
+	StreamActivityExport(w io.Writer, format string, from, to time.Time) error
+
+	// GetLogLevels returns the log level of each module of the node
+	GetLogLevels() (serde.LogLevelsResponse, error)
+
+	// SetLogLevel sets the log level of a module of the node. An empty module sets the default level.
+	SetLogLevel(module string, level string) (serde.LogLevelsResponse, error)
+
+	// RebindRpcServer moves the rpc server to listen on port, serving the TLS certificate and key in the files at the
+	// filepaths on the node's host, and returns its new url. The files must be within the node's certificate folder
+	// (tlscertdir). A zero port keeps the current port, and empty filepaths the current certificate. The client's open connections, such as its subscription to notifications, are kept, but
+	// new connections must be made to the new url. It requires v2 of the rpc api, and the http transport.
+	RebindRpcServer(port uint, tlsCertFilepath, tlsKeyFilepath string) (string, error)
+
+	// GetMessageFaults returns the faults injected into the messages the node sends and receives
+	GetMessageFaults() (serde.MessageFaults, error)
+
+	// SetMessageFaults injects faults into the messages the node sends and receives, for testing. The node must have been
+	// started with a fault injecting message service.
+	SetMessageFaults(faults serde.MessageFaults) (serde.MessageFaults, error)
+
+	// GetConfig returns the settings of the node which may be changed while it runs
+	GetConfig() (serde.ConfigResponse, error)
+
+	// SetConfig changes settings of the node while it runs. The changes persist when the node restarts.
+	SetConfig(settings map[string]string) (serde.ConfigResponse, error)
+
+	// GetDebugBundle returns a zip archive of diagnostic information about the node, for support requests
+	GetDebugBundle() ([]byte, error)
+
+	// GetPeerStats returns how responsive and reliable each peer of the node has been, healthiest first
+	GetPeerStats() (serde.GetPeerStatsResponse, error)
+
+	// BlockPeer blocks the peer with the address or, if peerId is not empty, the peer id, returning the blocked peers.
+	// Messages from a blocked peer are dropped and channels with it are refused. It requires v2 of the rpc api.
+	BlockPeer(address types.Address, peerId string, reason string) (serde.GetBlockedPeersResponse, error)
+
+	// UnblockPeer unblocks the peer blocked by its address or, if peerId is not empty, its peer id, returning the peers
+	// which remain blocked. It requires v2 of the rpc api.
+	UnblockPeer(address types.Address, peerId string) (serde.GetBlockedPeersResponse, error)
+
+	// GetBlockedPeers returns the blocked peers, in the order they were blocked. It requires v2 of the rpc api.
+	GetBlockedPeers() (serde.GetBlockedPeersResponse, error)
+
+	// GetApiKeys returns the api keys of the rpc server, without their secrets. It requires v2 of the rpc api, and an
+	// admin scoped api key.
+	GetApiKeys() (serde.GetApiKeysResponse, error)
+
+	// SetApiKey adds an api key to the rpc server, or replaces the key with the same name, returning its api keys. The
+	// auth tokens issued for a replaced key are revoked. It requires v2 of the rpc api, and an admin scoped api key.
+	SetApiKey(key serde.SetApiKeyRequest) (serde.GetApiKeysResponse, error)
+
+	// RemoveApiKey removes the api key with the name from the rpc server, revoking the auth tokens issued for it, and
+	// returns the remaining api keys. It requires v2 of the rpc api, and an admin scoped api key.
+	RemoveApiKey(name string) (serde.GetApiKeysResponse, error)
+
+	// GetPaymentLatency returns the median and 99th percentile latency of each stage of the node's recent payments. It requires v2 of the rpc api.
+	GetPaymentLatency() (serde.GetPaymentLatencyResponse, error)
+
+	// GetQuote asks the intermediary what it would charge to route a payment channel of the given size to the counterparty
+	GetQuote(intermediary types.Address, counterparty types.Address, asset types.Address, amount uint64) (protocols.Quote, error)
+
 	// CreateLedgerChannel creates a new ledger channel with the specified counterparty, ChallengeDuration, and outcome
 	CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error)
 
+	// CreateTaggedLedgerChannel is like CreateLedgerChannel, but attaches the tags to the channel
+	CreateTaggedLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, tags map[string]string) (directfund.ObjectiveResponse, error)
+
+	// CreateLedgerChannelWithMemo is like CreateLedgerChannel, but sends the memo, describing why the channel is
+	// requested, to the counterparty
+	CreateLedgerChannelWithMemo(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, memo string) (directfund.ObjectiveResponse, error)
+
+	// FindChannelsByTag returns the ids of the channels created with the given tag
+	FindChannelsByTag(key string, value string) ([]types.Destination, error)
+
+	// ComputeChannelId returns the id of the channel with the given fixed part, without creating it
+	ComputeChannelId(fixedPart state.FixedPart) (types.Destination, error)
+
+	// ComputeStateHash returns the hash of the state of the channel with the given variable part. It requires v2 of the rpc api.
+	ComputeStateHash(channelId types.Destination, variablePart state.VariablePart) (types.Bytes32, error)
+
+	// ComputeVoucherHash returns the hash of a voucher for amount on the channel. It requires v2 of the rpc api.
+	ComputeVoucherHash(channelId types.Destination, amount *big.Int) (types.Bytes32, error)
+
 	// CloseLedgerChannel attempts to close the ledger channel with the specified channelId
 	CloseLedgerChannel(id types.Destination) (protocols.ObjectiveId, error)
 
+	// WithdrawFromLedgerChannel withdraws amount of our balance in the ledger channel on chain, by closing the channel and
+	// funding another with the same counterparty. It requires v2 of the rpc api.
+	WithdrawFromLedgerChannel(id types.Destination, amount *big.Int) (query.LedgerWithdrawalInfo, error)
+
 	// Pay uses the specified channel to pay the specified amount
 	Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error)
 
+	// SetSpendLimits enforces the limits on the payments made through the client, replacing any limits already set.
+	// Payments which would exceed them fail with ErrSpendLimitExceeded, without a request being made to the node.
+	SetSpendLimits(limits SpendLimits)
+
+	// PayWithId is like Pay, but pays at most once for the payment id: retries resend the voucher of the first payment
+	PayWithId(id types.Destination, amount uint64, paymentId string) (serde.PaymentRequest, error)
+
+	// PayWithContext is like PayWithId, but also carries the context alongside the voucher, so that the payee can
+	// correlate the payment with, say, the request it pays for. An empty payment id is ignored.
+	PayWithContext(id types.Destination, amount uint64, paymentId string, context string) (serde.PaymentRequest, error)
+
 	// Close shuts down the RpcClient and closes the underlying transport
 	Close() error
 
@@ -79,6 +282,13 @@ type RpcClientApi interface {
 
 	// PaymentChannelUpdatesChan returns a channel that receives payment channel updates for the given payment channel id
 	PaymentChannelUpdatesChan(paymentChannelId types.Destination) <-chan query.PaymentChannelInfo
+
+	// ReceivedVouchersChan returns a channel that receives each voucher, with its context, received on the given payment channel
+	ReceivedVouchersChan(paymentChannelId types.Destination) <-chan payments.Voucher
+
+	// LedgerCapacityChan returns a channel that receives an event whenever the node's free capacity in the given ledger
+	// channel crosses one of its capacity thresholds (see node.EnableCapacityEvents)
+	LedgerCapacityChan(ledgerChannelId types.Destination) <-chan query.LedgerCapacityInfo
 }
 
 // rpcClient is the implementation
@@ -87,11 +297,19 @@ type rpcClient struct {
 	completedObjectives   *safesync.Map[chan struct{}]
 	ledgerChannelUpdates  *safesync.Map[chan query.LedgerChannelInfo]
 	paymentChannelUpdates *safesync.Map[chan query.PaymentChannelInfo]
+	receivedVouchers      *safesync.Map[chan payments.Voucher]
+	capacityEvents        *safesync.Map[chan query.LedgerCapacityInfo]
+	ledgerChannelStreams  *safesync.Map[chan []query.LedgerChannelInfo]
+	activityExports       *safesync.Map[chan serde.ActivityExportChunkInfo]
+	ctx                   context.Context // ctx is cancelled when the client is closed
 	cancel                context.CancelFunc
 	routineTracker        *sync.WaitGroup
 	nodeAddress           common.Address
+	apiVersion            string
 	logger                *slog.Logger
 	authToken             string
+	spendLimitsMu         sync.Mutex
+	spendLimits           *spendLimiter // nil unless SetSpendLimits has been called
 }
 
 // response includes a payload or an error.
@@ -100,18 +318,53 @@ type response[T serde.ResponsePayload] struct {
 	Error   error
 }
 
+// ClientOption configures an RpcClient.
+type ClientOption func(*clientOptions)
+
+type clientOptions struct {
+	apiKey    string
+	tlsConfig *tls.Config
+}
+
+// WithApiKey presents the api key to the server for an auth token. It is required if the server is configured with
+// api keys.
+func WithApiKey(apiKey string) ClientOption {
+	return func(o *clientOptions) {
+		o.apiKey = apiKey
+	}
+}
+
+// WithTLSConfig connects NewHttpRpcClient to a TLS server with the config, such as one which trusts a private
+// certificate authority, instead of with the system's trusted roots.
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(o *clientOptions) {
+		o.tlsConfig = config
+	}
+}
+
 // NewRpcClient creates a new RpcClient
-func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
+func NewRpcClient(trans transport.Requester, opts ...ClientOption) (RpcClientApi, error) {
+	o := clientOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	c := &rpcClient{
 		transport:             trans,
 		completedObjectives:   &safesync.Map[chan struct{}]{},
 		ledgerChannelUpdates:  &safesync.Map[chan query.LedgerChannelInfo]{},
 		paymentChannelUpdates: &safesync.Map[chan query.PaymentChannelInfo]{},
+		receivedVouchers:      &safesync.Map[chan payments.Voucher]{},
+		capacityEvents:        &safesync.Map[chan query.LedgerCapacityInfo]{},
+		ledgerChannelStreams:  &safesync.Map[chan []query.LedgerChannelInfo]{},
+		activityExports:       &safesync.Map[chan serde.ActivityExportChunkInfo]{},
+		ctx:                   ctx,
 		cancel:                cancel,
 		routineTracker:        &sync.WaitGroup{},
 		nodeAddress:           common.Address{},
-		logger:                slog.Default(),
+		apiVersion:            serde.ApiV1,
+		logger:                logging.ModuleLogger(logging.RPC_MODULE),
 	}
 
 	// Retrieve the address and set it on the rpcClient
@@ -124,26 +377,45 @@ func NewRpcClient(trans transport.Requester) (RpcClientApi, error) {
 	// Update the logger so we output the address
 	c.logger = logging.LoggerWithAddress(c.logger, c.nodeAddress)
 
-	notificationChan, err := c.transport.Subscribe()
+	if err := c.negotiateApiVersion(); err != nil {
+		return nil, err
+	}
+
+	authToken, err := WaitForRequestNoAuth[serde.AuthRequest, string](c, serde.GetAuthTokenMethod, serde.AuthRequest{ApiKey: o.apiKey})
+	if err != nil {
+		return nil, err
+	}
+	c.authToken = authToken
+
+	// The token is presented to transports which authorize subscriptions
+	var notificationChan <-chan []byte
+	if s, ok := c.transport.(transport.TokenSubscriber); ok {
+		notificationChan, err = s.SubscribeWithToken(c.authToken)
+	} else if s, ok := c.transport.(transport.AddressedSubscriber); ok {
+		notificationChan, err = s.SubscribeToAddress(c.nodeAddress.String())
+	} else {
+		notificationChan, err = c.transport.Subscribe()
+	}
 	if err != nil {
 		return nil, err
 	}
 	c.routineTracker.Add(1)
 	go c.subscribeToNotifications(ctx, notificationChan)
 
-	authToken, err := WaitForRequestNoAuth[serde.NoPayloadRequest, string](c, serde.GetAuthTokenMethod, serde.NoPayloadRequest{})
-	c.authToken = authToken
-
-	return c, err
+	return c, nil
 }
 
 // NewHttpRpcClient creates a new rpcClient using an http transport
-func NewHttpRpcClient(rpcServerUrl string) (RpcClientApi, error) {
-	transport, err := http.NewHttpTransportAsClient(rpcServerUrl, 10*time.Millisecond)
+func NewHttpRpcClient(rpcServerUrl string, opts ...ClientOption) (RpcClientApi, error) {
+	o := clientOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	transport, err := http.NewHttpTransportAsClientWithTLSConfig(rpcServerUrl, 10*time.Millisecond, o.tlsConfig)
 	if err != nil {
 		return nil, err
 	}
-	return NewRpcClient(transport)
+	return NewRpcClient(transport, opts...)
 }
 
 // Address returns the address of the the nitro node
@@ -151,11 +423,61 @@ func (rc *rpcClient) Address() (common.Address, error) {
 	return rc.nodeAddress, nil
 }
 
+// negotiateApiVersion offers the server the versions of the rpc api the client speaks, and directs subsequent requests
+// to the version it selects. Servers which predate negotiation only serve v1, which the client then keeps using.
+func (rc *rpcClient) negotiateApiVersion() error {
+	req := serde.NegotiateVersionRequest{Versions: serde.SupportedApiVersions}
+	version, err := WaitForRequestNoAuth[serde.NegotiateVersionRequest, string](rc, serde.NegotiateVersionMethod, req)
+	var rpcErr serde.JsonRpcError
+	if errors.As(err, &rpcErr) && rpcErr.Code == serde.MethodNotFoundError.Code {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not negotiate an api version: %w", err)
+	}
+	rc.transport.UseApiVersion(version)
+	rc.apiVersion = version
+	rc.logger.Debug("Negotiated rpc api version", "version", version)
+	return nil
+}
+
+// ApiVersion returns the version of the rpc api negotiated with the server
+func (rc *rpcClient) ApiVersion() string {
+	return rc.apiVersion
+}
+
+// Version returns the version of the nitro node, and of the rpc api serving the client
+func (rc *rpcClient) Version() (serde.VersionResponse, error) {
+	if rc.apiVersion == serde.ApiV1 {
+		version, err := WaitForRequestNoAuth[serde.NoPayloadRequest, string](rc, serde.VersionMethod, serde.NoPayloadRequest{})
+		return serde.VersionResponse{Version: version, ApiVersion: serde.ApiV1}, err
+	}
+	return WaitForRequestNoAuth[serde.NoPayloadRequest, serde.VersionResponse](rc, serde.VersionMethod, serde.NoPayloadRequest{})
+}
+
 // CreateVoucher creates a voucher for the given channelId and amount and returns it.
 // It is the responsibility of the caller to send the voucher to the payee.
 func (rc *rpcClient) CreateVoucher(chId types.Destination, amount uint64) (payments.Voucher, error) {
-	req := serde.PaymentRequest{Channel: chId, Amount: amount}
-	return waitForAuthorizedRequest[serde.PaymentRequest, payments.Voucher](rc, serde.CreateVoucherRequestMethod, req)
+	req := serde.PaymentRequest{Channel: chId, Amount: serde.NewQuantity(amount)}
+	return spendLimited(rc, chId, amount, func() (payments.Voucher, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, payments.Voucher](rc, serde.CreateVoucherRequestMethod, req)
+	})
+}
+
+// CreateVoucherWithId creates a voucher for the payment id, or returns the voucher already created for it
+func (rc *rpcClient) CreateVoucherWithId(chId types.Destination, amount uint64, paymentId string) (payments.Voucher, error) {
+	req := serde.PaymentRequest{Channel: chId, Amount: serde.NewQuantity(amount), PaymentId: paymentId}
+	return spendLimited(rc, chId, amount, func() (payments.Voucher, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, payments.Voucher](rc, serde.CreateVoucherRequestMethod, req)
+	})
+}
+
+// CreateVoucherWithExpiry creates a voucher which the payee refuses after expiry
+func (rc *rpcClient) CreateVoucherWithExpiry(chId types.Destination, amount uint64, paymentId string, expiry time.Time) (payments.Voucher, error) {
+	req := serde.PaymentRequest{Channel: chId, Amount: serde.NewQuantity(amount), PaymentId: paymentId, Expiry: uint64(expiry.Unix())}
+	return spendLimited(rc, chId, amount, func() (payments.Voucher, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, payments.Voucher](rc, serde.CreateVoucherRequestMethod, req)
+	})
 }
 
 // ReceiveVoucher receives a voucher and adds it to the go-nitro store.
@@ -184,6 +506,47 @@ func (rc *rpcClient) CreatePaymentChannel(intermediaries []types.Address, counte
 	return waitForAuthorizedRequest[virtualfund.ObjectiveRequest, virtualfund.ObjectiveResponse](rc, serde.CreatePaymentChannelRequestMethod, objReq)
 }
 
+// GetOrCreatePaymentChannel reuses or creates a virtual payment channel
+func (rc *rpcClient) GetOrCreatePaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (serde.GetOrCreatePaymentChannelResponse, error) {
+	objReq := virtualfund.NewObjectiveRequest(
+		intermediaries,
+		counterparty,
+		ChallengeDuration,
+		outcome,
+		rand.Uint64(),
+		common.Address{})
+
+	return waitForAuthorizedRequest[virtualfund.ObjectiveRequest, serde.GetOrCreatePaymentChannelResponse](rc, serde.GetOrCreatePaymentChannelMethod, objReq)
+}
+
+// CreateTaggedPaymentChannel creates a new virtual payment channel with the given tags
+func (rc *rpcClient) CreateTaggedPaymentChannel(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, tags map[string]string) (virtualfund.ObjectiveResponse, error) {
+	objReq := virtualfund.NewObjectiveRequest(
+		intermediaries,
+		counterparty,
+		100,
+		outcome,
+		rand.Uint64(),
+		common.Address{})
+	req := serde.CreatePaymentChannelRequest{ObjectiveRequest: objReq, Tags: tags}
+
+	return waitForAuthorizedRequest[serde.CreatePaymentChannelRequest, virtualfund.ObjectiveResponse](rc, serde.CreatePaymentChannelRequestMethod, req)
+}
+
+// CreatePaymentChannelWithMemo creates a new virtual payment channel, sending the memo with the proposal
+func (rc *rpcClient) CreatePaymentChannelWithMemo(intermediaries []types.Address, counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, memo string) (virtualfund.ObjectiveResponse, error) {
+	objReq := virtualfund.NewObjectiveRequest(
+		intermediaries,
+		counterparty,
+		ChallengeDuration,
+		outcome,
+		rand.Uint64(),
+		common.Address{})
+	objReq.Memo = memo
+
+	return waitForAuthorizedRequest[virtualfund.ObjectiveRequest, virtualfund.ObjectiveResponse](rc, serde.CreatePaymentChannelRequestMethod, objReq)
+}
+
 // ClosePaymentChannel attempts to close the payment channel with supplied id
 func (rc *rpcClient) ClosePaymentChannel(id types.Destination) (protocols.ObjectiveId, error) {
 	objReq := virtualdefund.NewObjectiveRequest(
@@ -203,11 +566,299 @@ func (rc *rpcClient) GetAllLedgerChannels() ([]query.LedgerChannelInfo, error) {
 	return waitForAuthorizedRequest[serde.NoPayloadRequest, []query.LedgerChannelInfo](rc, serde.GetAllLedgerChannelsMethod, struct{}{})
 }
 
+// StreamAllLedgerChannels returns a channel which receives all ledger channels, page by page
+func (rc *rpcClient) StreamAllLedgerChannels(pageSize uint64) (<-chan []query.LedgerChannelInfo, error) {
+	rc.routineTracker.Add(1)
+	defer rc.routineTracker.Done()
+
+	req := serde.StreamRequest{PageSize: pageSize, Subscription: rc.subscriptionId()}
+	res, err := waitForAuthorizedRequest[serde.StreamRequest, serde.StreamResponse](rc, serde.StreamAllLedgerChannelsMethod, req)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan []query.LedgerChannelInfo)
+	if res.Pages == 0 {
+		close(out)
+		return out, nil
+	}
+
+	// Pages may have arrived before the response, in which case they are waiting for us. They are passed on until the
+	// last page, when the stream is forgotten.
+	pages, _ := rc.ledgerChannelStreams.LoadOrStore(res.StreamId, make(chan []query.LedgerChannelInfo, 16))
+	rc.routineTracker.Add(1)
+	go func() {
+		defer rc.routineTracker.Done()
+		defer close(out)
+		defer rc.ledgerChannelStreams.Delete(res.StreamId)
+		for page := range pages {
+			select {
+			case out <- page:
+			case <-rc.ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
 // GetPaymentChannelsByLedger returns all active payment channels for a given ledger channel
 func (rc *rpcClient) GetPaymentChannelsByLedger(ledgerId types.Destination) ([]query.PaymentChannelInfo, error) {
 	return waitForAuthorizedRequest[serde.GetPaymentChannelsByLedgerRequest, []query.PaymentChannelInfo](rc, serde.GetPaymentChannelsByLedgerMethod, serde.GetPaymentChannelsByLedgerRequest{LedgerId: ledgerId})
 }
 
+// ListLedgerChannels returns a page of the ledger channels selected by the filter
+func (rc *rpcClient) ListLedgerChannels(filter query.ChannelFilter, page query.PageRequest) (query.LedgerChannelPage, error) {
+	req := serde.ListLedgerChannelsRequest{Filter: filter, Page: page}
+	return waitForAuthorizedRequest[serde.ListLedgerChannelsRequest, query.LedgerChannelPage](rc, serde.ListLedgerChannelsMethod, req)
+}
+
+// ListPaymentChannelsByLedger returns a page of the active payment channels for a given ledger channel selected by the filter
+func (rc *rpcClient) ListPaymentChannelsByLedger(ledgerId types.Destination, filter query.ChannelFilter, page query.PageRequest) (query.PaymentChannelPage, error) {
+	req := serde.ListPaymentChannelsByLedgerRequest{LedgerId: ledgerId, Filter: filter, Page: page}
+	return waitForAuthorizedRequest[serde.ListPaymentChannelsByLedgerRequest, query.PaymentChannelPage](rc, serde.ListPaymentChannelsByLedgerMethod, req)
+}
+
+// GetSignedState returns the latest supported signed state for a channel
+func (rc *rpcClient) GetSignedState(id types.Destination) (query.SignedStateInfo, error) {
+	req := serde.GetSignedStateRequest{Id: id}
+
+	return waitForAuthorizedRequest[serde.GetSignedStateRequest, query.SignedStateInfo](rc, serde.GetSignedStateMethod, req)
+}
+
+// GetChannelSnapshot returns a signed snapshot of the payment channel
+func (rc *rpcClient) GetChannelSnapshot(id types.Destination) (payments.ChannelSnapshot, error) {
+	req := serde.GetChannelSnapshotRequest{Id: id}
+
+	return waitForAuthorizedRequest[serde.GetChannelSnapshotRequest, payments.ChannelSnapshot](rc, serde.GetChannelSnapshotMethod, req)
+}
+
+// GetBalanceHistory returns historic balance snapshots
+func (rc *rpcClient) GetBalanceHistory(id types.Destination, from, to time.Time) ([]query.BalanceSnapshotInfo, error) {
+	req := serde.GetBalanceHistoryRequest{Id: id, From: from, To: to}
+
+	return waitForAuthorizedRequest[serde.GetBalanceHistoryRequest, serde.GetBalanceHistoryResponse](rc, serde.GetBalanceHistoryMethod, req)
+}
+
+// ExportActivity returns an export of the node's activity
+func (rc *rpcClient) ExportActivity(format string, from, to time.Time) (serde.ExportActivityResponse, error) {
+	req := serde.ExportActivityRequest{From: from, To: to, Format: format}
+
+	return waitForAuthorizedRequest[serde.ExportActivityRequest, serde.ExportActivityResponse](rc, serde.ExportActivityMethod, req)
+}
+
+// StreamActivityExport writes an export of the node's activity to w, chunk by chunk
+func (rc *rpcClient) StreamActivityExport(w io.Writer, format string, from, to time.Time) error {
+	rc.routineTracker.Add(1)
+	defer rc.routineTracker.Done()
+
+	req := serde.StreamActivityExportRequest{From: from, To: to, Format: format, Subscription: rc.subscriptionId()}
+	res, err := waitForAuthorizedRequest[serde.StreamActivityExportRequest, serde.ExportActivityResponse](rc, serde.StreamActivityExportMethod, req)
+	if err != nil {
+		return err
+	}
+
+	// Chunks may have arrived before the response, in which case they are waiting for us
+	chunks, _ := rc.activityExports.LoadOrStore(res.StreamId, make(chan serde.ActivityExportChunkInfo, 16))
+	defer rc.activityExports.Delete(res.StreamId)
+
+	// Every chunk is read, even once w fails, so that the chunks still to arrive do not block other notifications
+	var writeErr error
+	for chunk := range chunks {
+		if writeErr == nil {
+			_, writeErr = io.WriteString(w, chunk.Data)
+		}
+		if chunk.Last {
+			break
+		}
+	}
+	return writeErr
+}
+
+// subscriptionId returns the id of the client's subscription to notifications, to which the server sends the streams
+// the client requests
+func (rc *rpcClient) subscriptionId() string {
+	if s, ok := rc.transport.(transport.IdentifiedSubscriber); ok {
+		return s.SubscriptionId()
+	}
+	return ""
+}
+
+// GetDebugBundle returns a diagnostic bundle for the node
+func (rc *rpcClient) GetDebugBundle() ([]byte, error) {
+	res, err := waitForAuthorizedRequest[serde.NoPayloadRequest, serde.DebugBundleResponse](rc, serde.GetDebugBundleMethod, serde.NoPayloadRequest{})
+	return res.Data, err
+}
+
+// GetLogLevels returns the log level of each module of the node
+func (rc *rpcClient) GetLogLevels() (serde.LogLevelsResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.LogLevelsResponse](rc, serde.GetLogLevelsMethod, serde.NoPayloadRequest{})
+}
+
+// SetLogLevel sets the log level of a module of the node
+func (rc *rpcClient) SetLogLevel(module string, level string) (serde.LogLevelsResponse, error) {
+	req := serde.SetLogLevelRequest{Module: module, Level: level}
+
+	return waitForAuthorizedRequest[serde.SetLogLevelRequest, serde.LogLevelsResponse](rc, serde.SetLogLevelMethod, req)
+}
+
+// RebindRpcServer moves the rpc server to another port or certificate
+func (rc *rpcClient) RebindRpcServer(port uint, tlsCertFilepath, tlsKeyFilepath string) (string, error) {
+	req := serde.RebindRpcServerRequest{Port: port, TlsCertFilepath: tlsCertFilepath, TlsKeyFilepath: tlsKeyFilepath}
+
+	return waitForAuthorizedRequest[serde.RebindRpcServerRequest, string](rc, serde.RebindRpcServerMethod, req)
+}
+
+// GetMessageFaults returns the faults injected into the node's messages
+func (rc *rpcClient) GetMessageFaults() (serde.MessageFaults, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.MessageFaults](rc, serde.GetMessageFaultsMethod, serde.NoPayloadRequest{})
+}
+
+// SetMessageFaults injects faults into the node's messages
+func (rc *rpcClient) SetMessageFaults(faults serde.MessageFaults) (serde.MessageFaults, error) {
+	return waitForAuthorizedRequest[serde.MessageFaults, serde.MessageFaults](rc, serde.SetMessageFaultsMethod, faults)
+}
+
+// GetConfig returns the settings of the node which may be changed while it runs
+func (rc *rpcClient) GetConfig() (serde.ConfigResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.ConfigResponse](rc, serde.GetConfigMethod, serde.NoPayloadRequest{})
+}
+
+// SetConfig changes settings of the node while it runs
+func (rc *rpcClient) SetConfig(settings map[string]string) (serde.ConfigResponse, error) {
+	req := serde.SetConfigRequest{Settings: settings}
+	return waitForAuthorizedRequest[serde.SetConfigRequest, serde.ConfigResponse](rc, serde.SetConfigMethod, req)
+}
+
+// GetPeerStats returns the stats of each peer of the node, healthiest first
+func (rc *rpcClient) GetPeerStats() (serde.GetPeerStatsResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.GetPeerStatsResponse](rc, serde.GetPeerStatsMethod, serde.NoPayloadRequest{})
+}
+
+// CloseAllChannels starts closing every open channel of the node
+func (rc *rpcClient) CloseAllChannels(timeout time.Duration, challenge bool) (query.CloseAllInfo, error) {
+	req := serde.CloseAllChannelsRequest{Timeout: timeout, Challenge: challenge}
+	return waitForAuthorizedRequest[serde.CloseAllChannelsRequest, query.CloseAllInfo](rc, serde.CloseAllChannelsMethod, req)
+}
+
+// GetCloseAllProgress returns the progress of closing every open channel of the node
+func (rc *rpcClient) GetCloseAllProgress() (query.CloseAllInfo, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, query.CloseAllInfo](rc, serde.GetCloseAllProgressMethod, serde.NoPayloadRequest{})
+}
+
+// CreateLedgerChannels starts opening a batch of ledger channels
+func (rc *rpcClient) CreateLedgerChannels(req serde.CreateLedgerChannelsBatchRequest) (query.LedgerChannelBatchInfo, error) {
+	return waitForAuthorizedRequest[serde.CreateLedgerChannelsBatchRequest, query.LedgerChannelBatchInfo](rc, serde.CreateLedgerChannelsBatchMethod, req)
+}
+
+// GetLedgerChannelsBatch returns the progress of a batch of ledger channels
+func (rc *rpcClient) GetLedgerChannelsBatch(id string) (query.LedgerChannelBatchInfo, error) {
+	req := serde.GetLedgerChannelsBatchRequest{Id: id}
+	return waitForAuthorizedRequest[serde.GetLedgerChannelsBatchRequest, query.LedgerChannelBatchInfo](rc, serde.GetLedgerChannelsBatchMethod, req)
+}
+
+// BlockPeer blocks a peer of the node
+func (rc *rpcClient) BlockPeer(address types.Address, peerId string, reason string) (serde.GetBlockedPeersResponse, error) {
+	req := serde.BlockPeerRequest{Address: address, PeerId: peerId, Reason: reason}
+	return waitForAuthorizedRequest[serde.BlockPeerRequest, serde.GetBlockedPeersResponse](rc, serde.BlockPeerMethod, req)
+}
+
+// UnblockPeer unblocks a peer of the node
+func (rc *rpcClient) UnblockPeer(address types.Address, peerId string) (serde.GetBlockedPeersResponse, error) {
+	req := serde.UnblockPeerRequest{Address: address, PeerId: peerId}
+	return waitForAuthorizedRequest[serde.UnblockPeerRequest, serde.GetBlockedPeersResponse](rc, serde.UnblockPeerMethod, req)
+}
+
+// GetBlockedPeers returns the blocked peers of the node
+func (rc *rpcClient) GetBlockedPeers() (serde.GetBlockedPeersResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.GetBlockedPeersResponse](rc, serde.GetBlockedPeersMethod, serde.NoPayloadRequest{})
+}
+
+// GetApiKeys returns the api keys of the rpc server
+func (rc *rpcClient) GetApiKeys() (serde.GetApiKeysResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.GetApiKeysResponse](rc, serde.GetApiKeysMethod, serde.NoPayloadRequest{})
+}
+
+// SetApiKey adds or replaces an api key of the rpc server
+func (rc *rpcClient) SetApiKey(key serde.SetApiKeyRequest) (serde.GetApiKeysResponse, error) {
+	return waitForAuthorizedRequest[serde.SetApiKeyRequest, serde.GetApiKeysResponse](rc, serde.SetApiKeyMethod, key)
+}
+
+// RemoveApiKey removes an api key of the rpc server
+func (rc *rpcClient) RemoveApiKey(name string) (serde.GetApiKeysResponse, error) {
+	req := serde.RemoveApiKeyRequest{Name: name}
+	return waitForAuthorizedRequest[serde.RemoveApiKeyRequest, serde.GetApiKeysResponse](rc, serde.RemoveApiKeyMethod, req)
+}
+
+// ProbeCounterparty asks a counterparty whether it would fund a channel
+func (rc *rpcClient) ProbeCounterparty(counterparty types.Address, asset types.Address, deposit *big.Int, counterDeposit *big.Int) (protocols.Probe, error) {
+	req := serde.ProbeCounterpartyRequest{Counterparty: counterparty, Asset: asset, Deposit: (*serde.Quantity)(deposit), CounterDeposit: (*serde.Quantity)(counterDeposit)}
+	return waitForAuthorizedRequest[serde.ProbeCounterpartyRequest, protocols.Probe](rc, serde.ProbeCounterpartyMethod, req)
+}
+
+// CreateSubscription starts paying through the payment channel on a schedule
+func (rc *rpcClient) CreateSubscription(channelId types.Destination, amount uint64, interval time.Duration, limit uint64) (query.SubscriptionInfo, error) {
+	req := serde.CreateSubscriptionRequest{Channel: channelId, Amount: serde.NewQuantity(amount), Interval: interval, Cap: serde.NewQuantity(limit)}
+	return waitForAuthorizedRequest[serde.CreateSubscriptionRequest, query.SubscriptionInfo](rc, serde.CreateSubscriptionMethod, req)
+}
+
+// GetSubscription returns the subscription with the given id
+func (rc *rpcClient) GetSubscription(id protocols.ObjectiveId) (query.SubscriptionInfo, error) {
+	req := serde.GetSubscriptionRequest{Id: id}
+	return waitForAuthorizedRequest[serde.GetSubscriptionRequest, query.SubscriptionInfo](rc, serde.GetSubscriptionMethod, req)
+}
+
+// CancelSubscription ends the subscription with the given id
+func (rc *rpcClient) CancelSubscription(id protocols.ObjectiveId, reason string) (query.SubscriptionInfo, error) {
+	req := serde.CancelSubscriptionRequest{Id: id, Reason: reason}
+	return waitForAuthorizedRequest[serde.CancelSubscriptionRequest, query.SubscriptionInfo](rc, serde.CancelSubscriptionMethod, req)
+}
+
+// CreateEscrow opens an escrow paying through the payment channel for a committed result
+func (rc *rpcClient) CreateEscrow(channelId types.Destination, amount uint64, commitment types.Bytes32, timeout time.Duration) (query.EscrowInfo, error) {
+	req := serde.CreateEscrowRequest{Channel: channelId, Amount: serde.NewQuantity(amount), Commitment: commitment, Timeout: timeout}
+	return waitForAuthorizedRequest[serde.CreateEscrowRequest, query.EscrowInfo](rc, serde.CreateEscrowMethod, req)
+}
+
+// GetEscrow returns the escrow with the given id
+func (rc *rpcClient) GetEscrow(id protocols.ObjectiveId) (query.EscrowInfo, error) {
+	req := serde.GetEscrowRequest{Id: id}
+	return waitForAuthorizedRequest[serde.GetEscrowRequest, query.EscrowInfo](rc, serde.GetEscrowMethod, req)
+}
+
+// SubmitEscrowResult delivers the result of the escrow with the given id
+func (rc *rpcClient) SubmitEscrowResult(id protocols.ObjectiveId, result []byte) (query.EscrowInfo, error) {
+	req := serde.SubmitEscrowResultRequest{Id: id, Result: result}
+	return waitForAuthorizedRequest[serde.SubmitEscrowResultRequest, query.EscrowInfo](rc, serde.SubmitEscrowResultMethod, req)
+}
+
+// DisputeEscrow disputes the escrow with the given id
+func (rc *rpcClient) DisputeEscrow(id protocols.ObjectiveId, reason string) (query.EscrowInfo, error) {
+	req := serde.DisputeEscrowRequest{Id: id, Reason: reason}
+	return waitForAuthorizedRequest[serde.DisputeEscrowRequest, query.EscrowInfo](rc, serde.DisputeEscrowMethod, req)
+}
+
+// CreateObjective starts an objective of an application-specific type
+func (rc *rpcClient) CreateObjective(objectiveType string, request any) (protocols.ObjectiveId, error) {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return "", err
+	}
+	req := serde.CreateObjectiveRequest{Type: objectiveType, Request: data}
+	return waitForAuthorizedRequest[serde.CreateObjectiveRequest, protocols.ObjectiveId](rc, serde.CreateObjectiveMethod, req)
+}
+
+// GetPaymentLatency returns the latency of each stage of the node's recent payments, ordered by stage
+func (rc *rpcClient) GetPaymentLatency() (serde.GetPaymentLatencyResponse, error) {
+	return waitForAuthorizedRequest[serde.NoPayloadRequest, serde.GetPaymentLatencyResponse](rc, serde.GetPaymentLatencyMethod, serde.NoPayloadRequest{})
+}
+
+// GetQuote asks an intermediary for a quote to route a payment channel
+func (rc *rpcClient) GetQuote(intermediary types.Address, counterparty types.Address, asset types.Address, amount uint64) (protocols.Quote, error) {
+	req := serde.GetQuoteRequest{Intermediary: intermediary, CounterParty: counterparty, Asset: asset, Amount: serde.NewQuantity(amount)}
+
+	return waitForAuthorizedRequest[serde.GetQuoteRequest, protocols.Quote](rc, serde.GetQuoteMethod, req)
+}
+
 // CreateLedger creates a new ledger channel
 func (rc *rpcClient) CreateLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit) (directfund.ObjectiveResponse, error) {
 	objReq := directfund.NewObjectiveRequest(
@@ -220,6 +871,71 @@ func (rc *rpcClient) CreateLedgerChannel(counterparty types.Address, ChallengeDu
 	return waitForAuthorizedRequest[directfund.ObjectiveRequest, directfund.ObjectiveResponse](rc, serde.CreateLedgerChannelRequestMethod, objReq)
 }
 
+// CreateTaggedLedgerChannel creates a new ledger channel with the given tags
+func (rc *rpcClient) CreateTaggedLedgerChannel(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, tags map[string]string) (directfund.ObjectiveResponse, error) {
+	objReq := directfund.NewObjectiveRequest(
+		counterparty,
+		100,
+		outcome,
+		rand.Uint64(),
+		common.Address{})
+	req := serde.CreateLedgerChannelRequest{ObjectiveRequest: objReq, Tags: tags}
+
+	return waitForAuthorizedRequest[serde.CreateLedgerChannelRequest, directfund.ObjectiveResponse](rc, serde.CreateLedgerChannelRequestMethod, req)
+}
+
+// CreateLedgerChannelWithMemo creates a new ledger channel, sending the memo with the proposal
+func (rc *rpcClient) CreateLedgerChannelWithMemo(counterparty types.Address, ChallengeDuration uint32, outcome outcome.Exit, memo string) (directfund.ObjectiveResponse, error) {
+	objReq := directfund.NewObjectiveRequest(
+		counterparty,
+		ChallengeDuration,
+		outcome,
+		rand.Uint64(),
+		common.Address{})
+	objReq.Memo = memo
+
+	return waitForAuthorizedRequest[directfund.ObjectiveRequest, directfund.ObjectiveResponse](rc, serde.CreateLedgerChannelRequestMethod, objReq)
+}
+
+// FindChannelsByTag returns the ids of the channels with the given tag
+func (rc *rpcClient) FindChannelsByTag(key string, value string) ([]types.Destination, error) {
+	req := serde.FindChannelsByTagRequest{Key: key, Value: value}
+
+	return waitForAuthorizedRequest[serde.FindChannelsByTagRequest, serde.FindChannelsByTagResponse](rc, serde.FindChannelsByTagMethod, req)
+}
+
+// ComputeChannelId returns the id of the channel with the given fixed part
+func (rc *rpcClient) ComputeChannelId(fixedPart state.FixedPart) (types.Destination, error) {
+	req := serde.ComputeChannelIdRequest{
+		Participants:      fixedPart.Participants,
+		ChannelNonce:      fixedPart.ChannelNonce,
+		AppDefinition:     fixedPart.AppDefinition,
+		ChallengeDuration: fixedPart.ChallengeDuration,
+	}
+
+	return waitForAuthorizedRequest[serde.ComputeChannelIdRequest, types.Destination](rc, serde.ComputeChannelIdMethod, req)
+}
+
+// ComputeStateHash returns the hash of the state of the channel with the given variable part
+func (rc *rpcClient) ComputeStateHash(channelId types.Destination, variablePart state.VariablePart) (types.Bytes32, error) {
+	req := serde.ComputeStateHashRequest{
+		ChannelId: channelId,
+		AppData:   variablePart.AppData,
+		Outcome:   variablePart.Outcome,
+		TurnNum:   variablePart.TurnNum,
+		IsFinal:   variablePart.IsFinal,
+	}
+
+	return waitForAuthorizedRequest[serde.ComputeStateHashRequest, types.Bytes32](rc, serde.ComputeStateHashMethod, req)
+}
+
+// ComputeVoucherHash returns the hash of a voucher for amount on the channel
+func (rc *rpcClient) ComputeVoucherHash(channelId types.Destination, amount *big.Int) (types.Bytes32, error) {
+	req := serde.ComputeVoucherHashRequest{ChannelId: channelId, Amount: (*serde.Quantity)(amount)}
+
+	return waitForAuthorizedRequest[serde.ComputeVoucherHashRequest, types.Bytes32](rc, serde.ComputeVoucherHashMethod, req)
+}
+
 // CloseLedger closes a ledger channel
 func (rc *rpcClient) CloseLedgerChannel(id types.Destination) (protocols.ObjectiveId, error) {
 	objReq := directdefund.NewObjectiveRequest(id)
@@ -227,10 +943,42 @@ func (rc *rpcClient) CloseLedgerChannel(id types.Destination) (protocols.Objecti
 	return waitForAuthorizedRequest[directdefund.ObjectiveRequest, protocols.ObjectiveId](rc, serde.CloseLedgerChannelRequestMethod, objReq)
 }
 
+// WithdrawFromLedgerChannel withdraws part of our balance in a ledger channel
+func (rc *rpcClient) WithdrawFromLedgerChannel(id types.Destination, amount *big.Int) (query.LedgerWithdrawalInfo, error) {
+	req := serde.WithdrawFromLedgerChannelRequest{ChannelId: id, Amount: (*serde.Quantity)(amount)}
+
+	return waitForAuthorizedRequest[serde.WithdrawFromLedgerChannelRequest, query.LedgerWithdrawalInfo](rc, serde.WithdrawFromLedgerChannelMethod, req)
+}
+
 // Pay uses the specified channel to pay the specified amount
 func (rc *rpcClient) Pay(id types.Destination, amount uint64) (serde.PaymentRequest, error) {
-	pReq := serde.PaymentRequest{Amount: amount, Channel: id}
-	return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
+	pReq := serde.PaymentRequest{Amount: serde.NewQuantity(amount), Channel: id}
+	return spendLimited(rc, id, amount, func() (serde.PaymentRequest, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
+	})
+}
+
+// PayWithId pays at most once for the payment id
+func (rc *rpcClient) PayWithId(id types.Destination, amount uint64, paymentId string) (serde.PaymentRequest, error) {
+	pReq := serde.PaymentRequest{Amount: serde.NewQuantity(amount), Channel: id, PaymentId: paymentId}
+	return spendLimited(rc, id, amount, func() (serde.PaymentRequest, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
+	})
+}
+
+// PayWithContext pays, carrying the context alongside the voucher
+func (rc *rpcClient) PayWithContext(id types.Destination, amount uint64, paymentId string, context string) (serde.PaymentRequest, error) {
+	pReq := serde.PaymentRequest{Amount: serde.NewQuantity(amount), Channel: id, PaymentId: paymentId, Context: context}
+	return spendLimited(rc, id, amount, func() (serde.PaymentRequest, error) {
+		return waitForAuthorizedRequest[serde.PaymentRequest, serde.PaymentRequest](rc, serde.PayRequestMethod, pReq)
+	})
+}
+
+// SetSpendLimits enforces the limits on the payments made through the client
+func (rc *rpcClient) SetSpendLimits(limits SpendLimits) {
+	rc.spendLimitsMu.Lock()
+	defer rc.spendLimitsMu.Unlock()
+	rc.spendLimits = newSpendLimiter(limits, time.Now)
 }
 
 func (rc *rpcClient) Close() error {
@@ -281,6 +1029,71 @@ func (rc *rpcClient) subscribeToNotifications(ctx context.Context, notificationC
 				}
 				c, _ := rc.paymentChannelUpdates.LoadOrStore(string(rpcRequest.Params.Payload.ID.String()), make(chan query.PaymentChannelInfo, 100))
 				c <- rpcRequest.Params.Payload
+
+			case serde.VoucherReceived:
+				rpcRequest := serde.JsonRpcSpecificRequest[payments.Voucher]{}
+				err := json.Unmarshal(data, &rpcRequest)
+				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
+				if err != nil {
+					panic(err)
+				}
+				c, _ := rc.receivedVouchers.LoadOrStore(rpcRequest.Params.Payload.ChannelId.String(), make(chan payments.Voucher, 100))
+				// Vouchers are dropped, rather than blocking other notifications, if the application does not consume them
+				select {
+				case c <- rpcRequest.Params.Payload:
+				default:
+				}
+
+			case serde.LedgerCapacityCrossed:
+				rpcRequest := serde.JsonRpcSpecificRequest[query.LedgerCapacityInfo]{}
+				err := json.Unmarshal(data, &rpcRequest)
+				rc.logger.Debug("Received notification", "method", method, "data", rpcRequest)
+				if err != nil {
+					panic(err)
+				}
+				c, _ := rc.capacityEvents.LoadOrStore(rpcRequest.Params.Payload.ID.String(), make(chan query.LedgerCapacityInfo, 100))
+				// Events are dropped, rather than blocking other notifications, if the application does not consume them
+				select {
+				case c <- rpcRequest.Params.Payload:
+				default:
+				}
+
+			case serde.LedgerChannelsPage:
+				rpcRequest := serde.JsonRpcSpecificRequest[serde.LedgerChannelsPageInfo]{}
+				err := json.Unmarshal(data, &rpcRequest)
+				rc.logger.Debug("Received notification", "method", method, "streamId", rpcRequest.Params.Payload.StreamId, "page", rpcRequest.Params.Payload.Page)
+				if err != nil {
+					panic(err)
+				}
+				page := rpcRequest.Params.Payload
+				// The pages of a stream may arrive before the response which returns its id
+				c, _ := rc.ledgerChannelStreams.LoadOrStore(page.StreamId, make(chan []query.LedgerChannelInfo, 16))
+				select {
+				case c <- page.Channels:
+				case <-ctx.Done():
+					rc.routineTracker.Done()
+					return
+				}
+				if page.Page+1 >= page.Pages {
+					close(c)
+				}
+
+			case serde.ActivityExportChunk:
+				rpcRequest := serde.JsonRpcSpecificRequest[serde.ActivityExportChunkInfo]{}
+				err := json.Unmarshal(data, &rpcRequest)
+				rc.logger.Debug("Received notification", "method", method, "streamId", rpcRequest.Params.Payload.StreamId, "chunk", rpcRequest.Params.Payload.Chunk)
+				if err != nil {
+					panic(err)
+				}
+				chunk := rpcRequest.Params.Payload
+				// The chunks of a stream may arrive before the response which returns its id
+				c, _ := rc.activityExports.LoadOrStore(chunk.StreamId, make(chan serde.ActivityExportChunkInfo, 16))
+				select {
+				case c <- chunk:
+				case <-ctx.Done():
+					rc.routineTracker.Done()
+					return
+				}
 			}
 
 		}
@@ -305,6 +1118,20 @@ func (rc *rpcClient) PaymentChannelUpdatesChan(paymentChannelId types.Destinatio
 	return c
 }
 
+// ReceivedVouchersChan returns a chan that receives the vouchers received on the payment channel. Vouchers are dropped
+// if the chan is full.
+func (rc *rpcClient) ReceivedVouchersChan(paymentChannelId types.Destination) <-chan payments.Voucher {
+	c, _ := rc.receivedVouchers.LoadOrStore(paymentChannelId.String(), make(chan payments.Voucher, 100))
+	return c
+}
+
+// LedgerCapacityChan returns a chan that receives the capacity events of the ledger channel. Events are dropped if the
+// chan is full.
+func (rc *rpcClient) LedgerCapacityChan(ledgerChannelId types.Destination) <-chan query.LedgerCapacityInfo {
+	c, _ := rc.capacityEvents.LoadOrStore(ledgerChannelId.String(), make(chan query.LedgerCapacityInfo, 100))
+	return c
+}
+
 // WaitForRequestNoAuth calls waitForRequest with an empty auth token
 func WaitForRequestNoAuth[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClient, method serde.RequestMethod, requestData T) (U, error) {
 	return waitForRequest[T, U](rc, method, requestData, "")
@@ -335,8 +1162,7 @@ func waitForRequest[T serde.RequestPayload, U serde.ResponsePayload](rc *rpcClie
 func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transport.Requester, method serde.RequestMethod, reqPayload T,
 	authToken string, logger *slog.Logger, wg *sync.WaitGroup,
 ) (response[U], error) {
-	requestId := rand.Uint64()
-	message := serde.NewJsonRpcSpecificRequest(requestId, method, reqPayload, authToken)
+	message := serde.NewJsonRpcSpecificRequest(serde.NumberId(rand.Uint64()), method, reqPayload, authToken)
 	data, err := json.Marshal(message)
 	if err != nil {
 		return response[U]{}, err
@@ -355,7 +1181,7 @@ func sendRequest[T serde.RequestPayload, U serde.ResponsePayload](trans transpor
 	if err != nil {
 		return response[U]{}, err
 	} else if jsonResponse.Error != (serde.JsonRpcError{}) {
-		return response[U]{Error: jsonResponse.Error}, nil
+		return response[U]{Error: fromJsonRpcError(jsonResponse.Error)}, nil
 	}
 
 	// Now convert response.Result into the specific type for this request, and return that
@@ -0,0 +1,84 @@
+package rpc
+
+import (
+	"errors"
+
+	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/rpc/serde"
+)
+
+// nodeErrors pairs each typed error returned by the node with the json-rpc error used to report it.
+var nodeErrors = []struct {
+	err    error
+	rpcErr serde.JsonRpcError
+}{
+	{nitro.ErrChannelNotFound, serde.ChannelNotFoundError},
+	{nitro.ErrInsufficientFunds, serde.InsufficientFundsError},
+	{nitro.ErrObjectiveRejected, serde.ObjectiveRejectedError},
+	{nitro.ErrPeerUnreachable, serde.PeerUnreachableError},
+	{nitro.ErrLedgerChannelExists, serde.LedgerChannelExistsError},
+	{nitro.ErrInvalidChannelTags, serde.InvalidChannelTagsError},
+	{nitro.ErrInvalidChannelMemo, serde.InvalidChannelMemoError},
+	{nitro.ErrNoFaultInjection, serde.NoFaultInjectionError},
+	{nitro.ErrInvalidConfig, serde.InvalidConfigError},
+	{nitro.ErrAssetNotAllowed, serde.AssetNotAllowedError},
+	{nitro.ErrCloseAllInProgress, serde.CloseAllInProgressError},
+	{nitro.ErrUnknownObjectiveType, serde.UnknownObjectiveTypeError},
+	{nitro.ErrInvalidSubscription, serde.InvalidSubscriptionError},
+	{nitro.ErrSubscriptionNotFound, serde.SubscriptionNotFoundError},
+	{nitro.ErrSubscriptionNotActive, serde.SubscriptionEndedError},
+	{nitro.ErrInvalidEscrow, serde.InvalidEscrowError},
+	{nitro.ErrEscrowNotFound, serde.EscrowNotFoundError},
+	{nitro.ErrEscrowNotOpen, serde.EscrowNotOpenError},
+	{nitro.ErrResultMismatch, serde.ResultMismatchError},
+	{nitro.ErrInvalidBatch, serde.InvalidBatchError},
+	{nitro.ErrBatchNotFound, serde.BatchNotFoundError},
+	{nitro.ErrCounterpartyRefused, serde.CounterpartyRefusedError},
+	{nitro.ErrPeerBlocked, serde.PeerBlockedError},
+	{nitro.ErrVoucherExpired, serde.VoucherExpiredError},
+	{nitro.ErrWithdrawalRefused, serde.WithdrawalRefusedError},
+	{nitro.ErrFundingDeferred, serde.FundingDeferredError},
+	{nitro.ErrVoucherReplayed, serde.VoucherReplayedError},
+	{nitro.ErrVoucherNonceRequired, serde.VoucherNonceRequiredError},
+}
+
+// toJsonRpcError converts an error returned while processing a request into a json-rpc error.
+// The error message is preserved, and the code identifies any typed node error it wraps.
+func toJsonRpcError(err error) serde.JsonRpcError {
+	responseErr := serde.InternalServerError // default error
+	responseErr.Message = err.Error()
+
+	var jsonErr serde.JsonRpcError
+	if errors.As(err, &jsonErr) {
+		responseErr.Code = jsonErr.Code // overwrite default if error object is jsonrpc error
+		return responseErr
+	}
+	for _, ne := range nodeErrors {
+		if errors.Is(err, ne.err) {
+			responseErr.Code = ne.rpcErr.Code
+			break
+		}
+	}
+	return responseErr
+}
+
+// clientError is a json-rpc error received by the client. It also matches the typed node error which the code identifies,
+// so that callers may use errors.Is on it just as they would on an error returned by a node directly.
+type clientError struct {
+	serde.JsonRpcError
+	nodeErr error
+}
+
+func (e clientError) Unwrap() []error {
+	return []error{e.JsonRpcError, e.nodeErr}
+}
+
+// fromJsonRpcError converts a json-rpc error received by the client into an error.
+func fromJsonRpcError(rpcErr serde.JsonRpcError) error {
+	for _, ne := range nodeErrors {
+		if rpcErr.Code == ne.rpcErr.Code {
+			return clientError{rpcErr, ne.err}
+		}
+	}
+	return rpcErr
+}
@@ -1,8 +1,15 @@
 package serde
 
 import (
+	"encoding/json"
+	"slices"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 
+	"github.com/statechannels/go-nitro/channel/state/outcome"
+	"github.com/statechannels/go-nitro/node/engine/messageservice"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
@@ -30,14 +37,130 @@ const (
 	GetAllLedgerChannelsMethod        RequestMethod = "get_all_ledger_channels"
 	CreateVoucherRequestMethod        RequestMethod = "create_voucher"
 	ReceiveVoucherRequestMethod       RequestMethod = "receive_voucher"
+	GetSignedStateMethod              RequestMethod = "get_signed_state"
+	GetQuoteMethod                    RequestMethod = "get_quote"
+	GetLogLevelsMethod                RequestMethod = "get_log_levels"
+	SetLogLevelMethod                 RequestMethod = "set_log_level"
+	GetBalanceHistoryMethod           RequestMethod = "get_balance_history"
+	ExportActivityMethod              RequestMethod = "export_activity"
+	GetDebugBundleMethod              RequestMethod = "get_debug_bundle"
+	GetChannelSnapshotMethod          RequestMethod = "get_channel_snapshot"
+	GetPeerStatsMethod                RequestMethod = "get_peer_stats"
+	StreamAllLedgerChannelsMethod     RequestMethod = "stream_all_ledger_channels"
+	FindChannelsByTagMethod           RequestMethod = "find_channels_by_tag"
+	ComputeChannelIdMethod            RequestMethod = "compute_channel_id"
+	GetMessageFaultsMethod            RequestMethod = "get_message_faults"
+	SetMessageFaultsMethod            RequestMethod = "set_message_faults"
+	GetConfigMethod                   RequestMethod = "get_config"
+	SetConfigMethod                   RequestMethod = "set_config"
+	NegotiateVersionMethod            RequestMethod = "negotiate_version"
+	ComputeStateHashMethod            RequestMethod = "compute_state_hash"
+	ComputeVoucherHashMethod          RequestMethod = "compute_voucher_hash"
+	GetPaymentLatencyMethod           RequestMethod = "get_payment_latency"
+	GetOrCreatePaymentChannelMethod   RequestMethod = "get_or_create_payment_channel"
+	CloseAllChannelsMethod            RequestMethod = "close_all_channels"
+	GetCloseAllProgressMethod         RequestMethod = "get_close_all_progress"
+	CreateObjectiveMethod             RequestMethod = "create_objective"
+	CreateSubscriptionMethod          RequestMethod = "create_subscription"
+	GetSubscriptionMethod             RequestMethod = "get_subscription"
+	CancelSubscriptionMethod          RequestMethod = "cancel_subscription"
+	CreateEscrowMethod                RequestMethod = "create_escrow"
+	GetEscrowMethod                   RequestMethod = "get_escrow"
+	SubmitEscrowResultMethod          RequestMethod = "submit_escrow_result"
+	DisputeEscrowMethod               RequestMethod = "dispute_escrow"
+	CreateLedgerChannelsBatchMethod   RequestMethod = "create_ledger_channels_batch"
+	GetLedgerChannelsBatchMethod      RequestMethod = "get_ledger_channels_batch"
+	ProbeCounterpartyMethod           RequestMethod = "probe_counterparty"
+	BlockPeerMethod                   RequestMethod = "block_peer"
+	UnblockPeerMethod                 RequestMethod = "unblock_peer"
+	GetBlockedPeersMethod             RequestMethod = "get_blocked_peers"
+	ListLedgerChannelsMethod          RequestMethod = "list_ledger_channels"
+	ListPaymentChannelsByLedgerMethod RequestMethod = "list_payment_channels_by_ledger"
+	WithdrawFromLedgerChannelMethod   RequestMethod = "withdraw_from_ledger_channel"
+	RebindRpcServerMethod             RequestMethod = "rebind_rpc_server"
+	GetApiKeysMethod                  RequestMethod = "get_api_keys"
+	SetApiKeyMethod                   RequestMethod = "set_api_key"
+	RemoveApiKeyMethod                RequestMethod = "remove_api_key"
+	StreamActivityExportMethod        RequestMethod = "stream_activity_export"
+)
+
+// Versions of the rpc api. Each version is served at its own path (or topic), such as /api/v1, and keeps the surface it
+// was released with: later versions may add methods, or change the response of a method, without breaking clients of
+// earlier versions.
+//
+// In v2, the version method responds with a VersionResponse rather than the node's version alone, and the methods listed
+// in methodsSince are added.
+const (
+	ApiV1 = "v1"
+	ApiV2 = "v2"
 )
 
+// methodsSince maps each method added after v1 to the version of the rpc api which added it
+var methodsSince = map[RequestMethod]string{
+	ComputeStateHashMethod:            ApiV2,
+	ComputeVoucherHashMethod:          ApiV2,
+	GetPaymentLatencyMethod:           ApiV2,
+	GetOrCreatePaymentChannelMethod:   ApiV2,
+	CloseAllChannelsMethod:            ApiV2,
+	GetCloseAllProgressMethod:         ApiV2,
+	CreateObjectiveMethod:             ApiV2,
+	CreateSubscriptionMethod:          ApiV2,
+	GetSubscriptionMethod:             ApiV2,
+	CancelSubscriptionMethod:          ApiV2,
+	CreateEscrowMethod:                ApiV2,
+	GetEscrowMethod:                   ApiV2,
+	SubmitEscrowResultMethod:          ApiV2,
+	DisputeEscrowMethod:               ApiV2,
+	CreateLedgerChannelsBatchMethod:   ApiV2,
+	GetLedgerChannelsBatchMethod:      ApiV2,
+	ProbeCounterpartyMethod:           ApiV2,
+	BlockPeerMethod:                   ApiV2,
+	UnblockPeerMethod:                 ApiV2,
+	GetBlockedPeersMethod:             ApiV2,
+	ListLedgerChannelsMethod:          ApiV2,
+	ListPaymentChannelsByLedgerMethod: ApiV2,
+	WithdrawFromLedgerChannelMethod:   ApiV2,
+	RebindRpcServerMethod:             ApiV2,
+	GetApiKeysMethod:                  ApiV2,
+	SetApiKeyMethod:                   ApiV2,
+	RemoveApiKeyMethod:                ApiV2,
+	StreamActivityExportMethod:        ApiV2,
+}
+
+// MethodServed returns whether the method is part of the given version of the rpc api.
+func MethodServed(method RequestMethod, apiVersion string) bool {
+	since, ok := methodsSince[method]
+	if !ok {
+		return true
+	}
+	return slices.Index(SupportedApiVersions, apiVersion) >= slices.Index(SupportedApiVersions, since)
+}
+
+// SupportedApiVersions are the versions of the rpc api the server serves and the client speaks, oldest first.
+var SupportedApiVersions = []string{ApiV1, ApiV2}
+
+// SelectApiVersion returns the latest of the offered versions which is supported, or false if none is.
+func SelectApiVersion(offered []string) (string, bool) {
+	for i := len(SupportedApiVersions) - 1; i >= 0; i-- {
+		for _, version := range offered {
+			if version == SupportedApiVersions[i] {
+				return version, true
+			}
+		}
+	}
+	return "", false
+}
+
 type NotificationMethod string
 
 const (
 	ObjectiveCompleted    NotificationMethod = "objective_completed"
 	LedgerChannelUpdated  NotificationMethod = "ledger_channel_updated"
 	PaymentChannelUpdated NotificationMethod = "payment_channel_updated"
+	LedgerChannelsPage    NotificationMethod = "ledger_channels_page"
+	ActivityExportChunk   NotificationMethod = "activity_export_chunk"
+	VoucherReceived       NotificationMethod = "voucher_received"
+	LedgerCapacityCrossed NotificationMethod = "ledger_capacity_crossed"
 )
 
 type NotificationOrRequest interface {
@@ -46,12 +169,68 @@ type NotificationOrRequest interface {
 
 const JsonRpcVersion = "2.0"
 
+// CreateLedgerChannelRequest requests a ledger channel. If Force is set, the channel is created even if an equivalent
+// request was made recently. Tags are attached to the channel, and the request's Memo is sent to the counterparty.
+type CreateLedgerChannelRequest struct {
+	directfund.ObjectiveRequest
+	Force bool
+	Tags  map[string]string `json:",omitempty"`
+	Probe bool              `json:",omitempty"`
+}
+
+// CreatePaymentChannelRequest requests a payment channel. If Force is set, the channel is created even if an
+// equivalent request was made recently. Tags are attached to the channel, and the request's Memo is sent to the
+// counterparty and intermediaries.
+type CreatePaymentChannelRequest struct {
+	virtualfund.ObjectiveRequest
+	Force bool
+	Tags  map[string]string `json:",omitempty"`
+	Probe bool              `json:",omitempty"`
+}
+
+// ComputeStateHashRequest requests the hash of the state of the channel with the given variable part.
+type ComputeStateHashRequest struct {
+	ChannelId types.Destination
+	AppData   types.Bytes
+	Outcome   outcome.Exit
+	TurnNum   uint64
+	IsFinal   bool
+}
+
+// ComputeVoucherHashRequest requests the hash of a voucher for Amount on the channel.
+type ComputeVoucherHashRequest struct {
+	ChannelId types.Destination
+	Amount    *Quantity
+}
+
+// FindChannelsByTagRequest requests the ids of the channels tagged with the key and value.
+type FindChannelsByTagRequest struct {
+	Key   string
+	Value string
+}
+
+// ComputeChannelIdRequest requests the id of the channel with the given fixed part.
+type ComputeChannelIdRequest struct {
+	Participants      []types.Address
+	ChannelNonce      uint64
+	AppDefinition     types.Address
+	ChallengeDuration uint32
+}
+
 type AuthRequest struct {
 	Id string
+	// ApiKey is required if the server is configured with api keys, and determines the permissions of the token
+	ApiKey string `json:",omitempty"`
 }
 type PaymentRequest struct {
-	Amount  uint64
+	Amount  *Quantity
 	Channel types.Destination
+	// PaymentId, if set, makes the payment at most once: repeated requests with the same id do not pay again
+	PaymentId string `json:",omitempty"`
+	// Context, if set, is carried alongside the voucher for the payee to correlate the payment with
+	Context string `json:",omitempty"`
+	// Expiry, if set, is the unix time in seconds after which the payee refuses the voucher
+	Expiry uint64 `json:",omitempty"`
 }
 type GetPaymentChannelRequest struct {
 	Id types.Destination
@@ -62,6 +241,307 @@ type GetLedgerChannelRequest struct {
 type GetPaymentChannelsByLedgerRequest struct {
 	LedgerId types.Destination
 }
+type GetSignedStateRequest struct {
+	Id types.Destination
+}
+type GetChannelSnapshotRequest struct {
+	Id types.Destination
+}
+type GetQuoteRequest struct {
+	Intermediary types.Address
+	CounterParty types.Address
+	Asset        types.Address
+	Amount       *Quantity
+}
+
+// GetBalanceHistoryRequest requests the balance snapshots taken in [From, To).
+// If Id is the zero destination, snapshots of every channel are returned.
+type GetBalanceHistoryRequest struct {
+	Id   types.Destination
+	From time.Time
+	To   time.Time
+}
+
+// ExportActivityRequest requests an export, in the given Format ("csv" or "jsonl"), of the activity in [From, To).
+type ExportActivityRequest struct {
+	From   time.Time
+	To     time.Time
+	Format string
+}
+
+// StreamActivityExportRequest requests an export, as ExportActivityRequest does, streamed in chunks of at most
+// ChunkSize bytes (or DefaultExportChunkSize, if zero). The chunks are sent to the notification subscription with the
+// id Subscription alone, which must have been opened with the auth token of the request.
+type StreamActivityExportRequest struct {
+	From         time.Time
+	To           time.Time
+	Format       string
+	ChunkSize    uint64
+	Subscription string
+}
+
+// DefaultExportChunkSize is the number of bytes in each chunk of a streamed export, if the request does not specify it.
+const DefaultExportChunkSize = 32 * 1024
+
+// ExportActivityResponse contains an activity export. Data holds the entire file contents, unless the export is
+// streamed, in which case it is empty and the contents follow in ActivityExportChunk notifications carrying StreamId.
+type ExportActivityResponse struct {
+	Format   string
+	Data     string
+	StreamId string `json:",omitempty"`
+}
+
+// DebugBundleResponse contains a diagnostic bundle. Data holds the entire zip archive.
+type DebugBundleResponse struct {
+	Data []byte
+}
+
+// PeerStatsInfo reports how responsive and reliable a peer has been. RoundTrip is zero if it has not been measured.
+type PeerStatsInfo struct {
+	Peer        types.Address
+	RoundTrip   time.Duration
+	Succeeded   uint64
+	Failed      uint64
+	SuccessRate float64
+}
+
+// BlockedPeerInfo describes a blocked peer. Address is the zero address if the peer is blocked by its PeerId.
+type BlockedPeerInfo struct {
+	Address types.Address
+	PeerId  string `json:",omitempty"`
+	Reason  string `json:",omitempty"`
+	Blocked time.Time
+}
+
+// ApiKeyInfo describes an api key of the rpc server, without its secret.
+type ApiKeyInfo struct {
+	Name    string
+	Scope   string
+	Methods []string `json:",omitempty"`
+}
+
+// PaymentLatencyInfo reports the median and 99th percentile latency of a stage of the most recent payments through a
+// node, measured from Count payments.
+type PaymentLatencyInfo struct {
+	Stage string
+	Count int
+	P50   time.Duration
+	P99   time.Duration
+}
+
+// DefaultStreamPageSize is the number of results in each page of a streamed response, if the request does not specify it.
+const DefaultStreamPageSize = 100
+
+// StreamRequest requests that results are streamed in pages of at most PageSize (or DefaultStreamPageSize, if zero).
+// The pages are sent to the notification subscription with the id Subscription alone, which must have been opened with
+// the auth token of the request.
+type StreamRequest struct {
+	PageSize     uint64
+	Subscription string
+}
+
+// StreamResponse is the response to a stream request. The results follow in Pages notifications, each carrying the
+// StreamId the server generated for the stream. Total is the number of results in all of the pages.
+type StreamResponse struct {
+	StreamId string
+	Pages    uint64
+	Total    uint64
+}
+
+// LedgerChannelsPageInfo is a page of the ledger channels streamed with the id StreamId, which the server returned in
+// response to the request for them. Pages are numbered from zero, and sent in order.
+type LedgerChannelsPageInfo struct {
+	StreamId string
+	Page     uint64
+	Pages    uint64
+	Channels []query.LedgerChannelInfo
+}
+
+// ActivityExportChunkInfo is a chunk of the activity export streamed with the id StreamId, which the server returned in
+// response to the request for it. Chunks are numbered from zero, and sent in order. The stream ends with the chunk
+// marked Last.
+type ActivityExportChunkInfo struct {
+	StreamId string
+	Chunk    uint64
+	Data     string
+	Last     bool
+}
+
+// SetLogLevelRequest sets the log level of a module. An empty Module sets the default level.
+type SetLogLevelRequest struct {
+	Module string
+	Level  string
+}
+
+// NegotiateVersionRequest offers the versions of the rpc api a client speaks. The server selects the latest it serves.
+type NegotiateVersionRequest struct {
+	Versions []string
+}
+
+// VersionResponse is the response to the version method from v2 of the rpc api.
+type VersionResponse struct {
+	Version              string   // the version of the node
+	ApiVersion           string   // the version of the rpc api serving the request
+	SupportedApiVersions []string // the versions of the rpc api the server serves, oldest first
+}
+
+// GetOrCreatePaymentChannelResponse identifies the payment channel reused or created for a get_or_create_payment_channel
+// request. Id is empty if an open channel was reused; otherwise it is the objective creating the channel.
+type GetOrCreatePaymentChannelResponse struct {
+	virtualfund.ObjectiveResponse
+	Created bool // whether the channel was created by the request
+}
+
+// CloseAllChannelsRequest asks the node to close every open channel. Timeout is how long to wait for each stage of
+// cooperative closes (zero means the node's default), and Challenge makes ledger channels which are not closed in time
+// be challenged on chain.
+type CloseAllChannelsRequest struct {
+	Timeout   time.Duration
+	Challenge bool
+}
+
+// CreateObjectiveRequest asks the node to start an objective of an application-specific type, identified by the id
+// prefix it was registered with. Request is the JSON request for the objective, which the type decodes.
+type CreateObjectiveRequest struct {
+	Type    string
+	Request json.RawMessage
+}
+
+// CreateSubscriptionRequest asks the node to pay Amount through the payment channel every Interval, until Cap has been
+// paid.
+type CreateSubscriptionRequest struct {
+	Channel  types.Destination
+	Amount   *Quantity
+	Interval time.Duration
+	Cap      *Quantity
+}
+
+type GetSubscriptionRequest struct {
+	Id protocols.ObjectiveId
+}
+
+// CancelSubscriptionRequest asks the node to end a subscription, telling the other party the Reason.
+type CancelSubscriptionRequest struct {
+	Id     protocols.ObjectiveId
+	Reason string `json:",omitempty"`
+}
+
+// CreateEscrowRequest asks the node to pay Amount through the payment channel for a result whose keccak256 hash is
+// Commitment, delivered within Timeout.
+type CreateEscrowRequest struct {
+	Channel    types.Destination
+	Amount     *Quantity
+	Commitment types.Bytes32
+	Timeout    time.Duration
+}
+
+type GetEscrowRequest struct {
+	Id protocols.ObjectiveId
+}
+
+// SubmitEscrowResultRequest asks the node to deliver the Result of an escrow to its payer.
+type SubmitEscrowResultRequest struct {
+	Id     protocols.ObjectiveId
+	Result hexutil.Bytes
+}
+
+// DisputeEscrowRequest asks the node to dispute an escrow whose result was not released, closing its payment channel.
+type DisputeEscrowRequest struct {
+	Id     protocols.ObjectiveId
+	Reason string `json:",omitempty"`
+}
+
+// CreateLedgerChannelsBatchRequest asks the node to open a ledger channel with each of the Counterparties, depositing
+// MyDeposit of the Asset into each while the counterparty deposits TheirDeposit. At most Concurrency channels are
+// opened at once, and each is given Timeout to be funded; zero means the node's default.
+type CreateLedgerChannelsBatchRequest struct {
+	Counterparties    []types.Address
+	Asset             types.Address
+	MyDeposit         *Quantity
+	TheirDeposit      *Quantity
+	ChallengeDuration uint32
+	Tags              map[string]string `json:",omitempty"`
+	Concurrency       int               `json:",omitempty"`
+	Timeout           time.Duration     `json:",omitempty"`
+	Probe             bool              `json:",omitempty"`
+}
+
+type GetLedgerChannelsBatchRequest struct {
+	Id string
+}
+
+// ProbeCounterpartyRequest asks the counterparty whether it would fund a channel holding Asset, to which we deposit
+// Deposit and it deposits CounterDeposit.
+type ProbeCounterpartyRequest struct {
+	Counterparty   types.Address
+	Asset          types.Address
+	Deposit        *Quantity
+	CounterDeposit *Quantity
+}
+
+// BlockPeerRequest blocks the peer with the Address or, if PeerId is not empty, the peer which the node's message
+// service knows by PeerId (such as a libp2p peer id).
+type BlockPeerRequest struct {
+	Address types.Address
+	PeerId  string `json:",omitempty"`
+	Reason  string `json:",omitempty"`
+}
+
+// UnblockPeerRequest unblocks the peer blocked by its Address or, if PeerId is not empty, by its peer id.
+type UnblockPeerRequest struct {
+	Address types.Address
+	PeerId  string `json:",omitempty"`
+}
+
+// ListLedgerChannelsRequest requests a page of the ledger channels selected by the Filter.
+type ListLedgerChannelsRequest struct {
+	Filter query.ChannelFilter
+	Page   query.PageRequest
+}
+
+// ListPaymentChannelsByLedgerRequest requests a page of the active payment channels funded by the ledger channel which
+// are selected by the Filter.
+type ListPaymentChannelsByLedgerRequest struct {
+	LedgerId types.Destination
+	Filter   query.ChannelFilter
+	Page     query.PageRequest
+}
+
+// WithdrawFromLedgerChannelRequest requests that Amount of our balance in the ledger channel be withdrawn on chain.
+type WithdrawFromLedgerChannelRequest struct {
+	ChannelId types.Destination
+	Amount    *Quantity
+}
+
+// RebindRpcServerRequest moves the rpc server to listen on Port, serving the TLS certificate and key read from the
+// files at TlsCertFilepath and TlsKeyFilepath on the node's host. The files must be within the folder the server was
+// configured to read certificates from, and relative filepaths are relative to it. A zero Port keeps the current
+// port, and empty filepaths the current certificate.
+type RebindRpcServerRequest struct {
+	Port            uint
+	TlsCertFilepath string
+	TlsKeyFilepath  string
+}
+
+// SetApiKeyRequest adds an api key to the rpc server, or replaces the key with the same Name, revoking the auth tokens
+// issued for it. Scope is "read", "sign" or "admin", and Methods, if not empty, are the only methods the key permits of
+// those its scope permits.
+type SetApiKeyRequest struct {
+	Name    string
+	Secret  string
+	Scope   string
+	Methods []string `json:",omitempty"`
+}
+
+// RemoveApiKeyRequest removes the api key with the Name from the rpc server, revoking the auth tokens issued for it.
+type RemoveApiKeyRequest struct {
+	Name string
+}
+
+// SetConfigRequest changes settings of the node while it runs, keyed by name.
+type SetConfigRequest struct {
+	Settings map[string]string
+}
 
 type (
 	NoPayloadRequest = struct{}
@@ -72,11 +552,48 @@ type RequestPayload interface {
 		directdefund.ObjectiveRequest |
 		virtualfund.ObjectiveRequest |
 		virtualdefund.ObjectiveRequest |
+		CreateLedgerChannelRequest |
+		CreatePaymentChannelRequest |
 		AuthRequest |
 		PaymentRequest |
 		GetLedgerChannelRequest |
 		GetPaymentChannelRequest |
 		GetPaymentChannelsByLedgerRequest |
+		GetSignedStateRequest |
+		GetChannelSnapshotRequest |
+		GetQuoteRequest |
+		ProbeCounterpartyRequest |
+		BlockPeerRequest |
+		UnblockPeerRequest |
+		ListLedgerChannelsRequest |
+		ListPaymentChannelsByLedgerRequest |
+		WithdrawFromLedgerChannelRequest |
+		RebindRpcServerRequest |
+		SetApiKeyRequest |
+		RemoveApiKeyRequest |
+		SetLogLevelRequest |
+		GetBalanceHistoryRequest |
+		ExportActivityRequest |
+		StreamActivityExportRequest |
+		StreamRequest |
+		FindChannelsByTagRequest |
+		ComputeChannelIdRequest |
+		ComputeStateHashRequest |
+		ComputeVoucherHashRequest |
+		MessageFaults |
+		SetConfigRequest |
+		CloseAllChannelsRequest |
+		CreateObjectiveRequest |
+		CreateSubscriptionRequest |
+		GetSubscriptionRequest |
+		CancelSubscriptionRequest |
+		CreateEscrowRequest |
+		GetEscrowRequest |
+		SubmitEscrowResultRequest |
+		DisputeEscrowRequest |
+		CreateLedgerChannelsBatchRequest |
+		GetLedgerChannelsBatchRequest |
+		NegotiateVersionRequest |
 		NoPayloadRequest |
 		payments.Voucher
 }
@@ -84,7 +601,11 @@ type RequestPayload interface {
 type NotificationPayload interface {
 	protocols.ObjectiveId |
 		query.PaymentChannelInfo |
-		query.LedgerChannelInfo
+		query.LedgerChannelInfo |
+		LedgerChannelsPageInfo |
+		ActivityExportChunkInfo |
+		query.LedgerCapacityInfo |
+		payments.Voucher
 }
 
 type Params[T RequestPayload | NotificationPayload] struct {
@@ -94,14 +615,32 @@ type Params[T RequestPayload | NotificationPayload] struct {
 
 type JsonRpcSpecificRequest[T RequestPayload | NotificationPayload] struct {
 	Jsonrpc string    `json:"jsonrpc"`
-	Id      uint64    `json:"id"`
+	Id      RequestId `json:"id"`
 	Method  string    `json:"method"`
 	Params  Params[T] `json:"params"`
 }
 
+// MessageFaults describes the faults injected into the messages a node sends and receives, for testing.
+type MessageFaults = messageservice.Faults
+
 type (
 	GetAllLedgersResponse              = []query.LedgerChannelInfo
 	GetPaymentChannelsByLedgerResponse = []query.PaymentChannelInfo
+	// LogLevelsResponse maps each module to its log level. The default level is keyed by the empty string.
+	LogLevelsResponse         = map[string]string
+	GetBalanceHistoryResponse = []query.BalanceSnapshotInfo
+	// GetPeerStatsResponse lists the stats of each peer, healthiest first
+	GetPeerStatsResponse = []PeerStatsInfo
+	// GetPaymentLatencyResponse lists the latency of each stage of payments, ordered by stage
+	GetPaymentLatencyResponse = []PaymentLatencyInfo
+	// FindChannelsByTagResponse lists the ids of the tagged channels
+	FindChannelsByTagResponse = []types.Destination
+	// GetBlockedPeersResponse lists the blocked peers, in the order they were blocked
+	GetBlockedPeersResponse = []BlockedPeerInfo
+	// GetApiKeysResponse lists the api keys of the rpc server, without their secrets
+	GetApiKeysResponse = []ApiKeyInfo
+	// ConfigResponse maps each setting which may be changed while the node runs to its current value
+	ConfigResponse = map[string]string
 )
 
 type ResponsePayload interface {
@@ -111,21 +650,47 @@ type ResponsePayload interface {
 		PaymentRequest |
 		query.PaymentChannelInfo |
 		query.LedgerChannelInfo |
+		query.SignedStateInfo |
+		protocols.Quote |
+		protocols.Probe |
 		GetAllLedgersResponse |
 		GetPaymentChannelsByLedgerResponse |
+		LogLevelsResponse |
+		GetBalanceHistoryResponse |
+		GetPeerStatsResponse |
+		GetPaymentLatencyResponse |
+		FindChannelsByTagResponse |
+		GetBlockedPeersResponse |
+		GetApiKeysResponse |
+		query.LedgerChannelPage |
+		query.PaymentChannelPage |
+		types.Destination |
+		types.Bytes32 |
+		MessageFaults |
+		ExportActivityResponse |
+		DebugBundleResponse |
+		StreamResponse |
+		VersionResponse |
+		GetOrCreatePaymentChannelResponse |
+		query.CloseAllInfo |
+		query.SubscriptionInfo |
+		query.EscrowInfo |
+		query.LedgerChannelBatchInfo |
+		query.LedgerWithdrawalInfo |
 		payments.Voucher |
+		payments.ChannelSnapshot |
 		common.Address |
 		string |
 		payments.ReceiveVoucherSummary
 }
 
 type JsonRpcSuccessResponse[T ResponsePayload] struct {
-	Jsonrpc string `json:"jsonrpc"`
-	Id      uint64 `json:"id"`
-	Result  T      `json:"result"`
+	Jsonrpc string    `json:"jsonrpc"`
+	Id      RequestId `json:"id"`
+	Result  T         `json:"result"`
 }
 
-func NewJsonRpcSpecificRequest[T RequestPayload | NotificationPayload, U RequestMethod | NotificationMethod](requestId uint64, method U, objectiveRequest T, authToken string) *JsonRpcSpecificRequest[T] {
+func NewJsonRpcSpecificRequest[T RequestPayload | NotificationPayload, U RequestMethod | NotificationMethod](requestId RequestId, method U, objectiveRequest T, authToken string) *JsonRpcSpecificRequest[T] {
 	return &JsonRpcSpecificRequest[T]{
 		Jsonrpc: JsonRpcVersion,
 		Id:      requestId,
@@ -134,7 +699,7 @@ func NewJsonRpcSpecificRequest[T RequestPayload | NotificationPayload, U Request
 	}
 }
 
-func NewJsonRpcResponse[T ResponsePayload](requestId uint64, objectiveResponse T) *JsonRpcSuccessResponse[T] {
+func NewJsonRpcResponse[T ResponsePayload](requestId RequestId, objectiveResponse T) *JsonRpcSuccessResponse[T] {
 	return &JsonRpcSuccessResponse[T]{
 		Jsonrpc: JsonRpcVersion,
 		Id:      requestId,
@@ -144,21 +709,21 @@ func NewJsonRpcResponse[T ResponsePayload](requestId uint64, objectiveResponse T
 
 type JsonRpcGeneralRequest struct {
 	Jsonrpc string      `json:"jsonrpc"`
-	Id      uint64      `json:"id"`
+	Id      RequestId   `json:"id"`
 	Method  string      `json:"method"`
 	Params  interface{} `json:"params"`
 }
 
 type JsonRpcGeneralResponse struct {
 	Jsonrpc string       `json:"jsonrpc"`
-	Id      uint64       `json:"id"`
+	Id      RequestId    `json:"id"`
 	Error   JsonRpcError `json:"error"`
 	Result  interface{}  `json:"result"`
 }
 
 type JsonRpcErrorResponse struct {
 	Jsonrpc string       `json:"jsonrpc"`
-	Id      uint64       `json:"id"`
+	Id      RequestId    `json:"id"`
 	Error   JsonRpcError `json:"error"`
 }
 
@@ -172,7 +737,7 @@ func (e JsonRpcError) Error() string {
 	return e.Message
 }
 
-func NewJsonRpcErrorResponse(requestId uint64, error JsonRpcError) *JsonRpcErrorResponse {
+func NewJsonRpcErrorResponse(requestId RequestId, error JsonRpcError) *JsonRpcErrorResponse {
 	return &JsonRpcErrorResponse{
 		Jsonrpc: JsonRpcVersion,
 		Id:      requestId,
@@ -181,12 +746,43 @@ func NewJsonRpcErrorResponse(requestId uint64, error JsonRpcError) *JsonRpcError
 }
 
 var (
-	ParseError            = JsonRpcError{Code: -32700, Message: "Parse error"}
-	InvalidRequestError   = JsonRpcError{Code: -32600, Message: "Invalid Request"}
-	MethodNotFoundError   = JsonRpcError{Code: -32601, Message: "Method not found"}
-	InvalidParamsError    = JsonRpcError{Code: -32602, Message: "Invalid params"}
-	InternalServerError   = JsonRpcError{Code: -32603, Message: "Internal error"}
-	RequestUnmarshalError = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
-	ParamsUnmarshalError  = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
-	InvalidAuthTokenError = JsonRpcError{Code: -32008, Message: "Invalid auth token"}
+	ParseError                = JsonRpcError{Code: -32700, Message: "Parse error"}
+	InvalidRequestError       = JsonRpcError{Code: -32600, Message: "Invalid Request"}
+	MethodNotFoundError       = JsonRpcError{Code: -32601, Message: "Method not found"}
+	InvalidParamsError        = JsonRpcError{Code: -32602, Message: "Invalid params"}
+	InternalServerError       = JsonRpcError{Code: -32603, Message: "Internal error"}
+	RequestUnmarshalError     = JsonRpcError{Code: -32010, Message: "Could not unmarshal request object"}
+	ParamsUnmarshalError      = JsonRpcError{Code: -32009, Message: "Could not unmarshal params object"}
+	InvalidAuthTokenError     = JsonRpcError{Code: -32008, Message: "Invalid auth token"}
+	RequestLimitsError        = JsonRpcError{Code: -32007, Message: "Request exceeds limits"}
+	ChannelNotFoundError      = JsonRpcError{Code: -32011, Message: "Channel not found"}
+	InsufficientFundsError    = JsonRpcError{Code: -32012, Message: "Insufficient funds"}
+	ObjectiveRejectedError    = JsonRpcError{Code: -32013, Message: "Objective rejected"}
+	PeerUnreachableError      = JsonRpcError{Code: -32014, Message: "Peer unreachable"}
+	LedgerChannelExistsError  = JsonRpcError{Code: -32015, Message: "Ledger channel already exists"}
+	InvalidChannelTagsError   = JsonRpcError{Code: -32016, Message: "Invalid channel tags"}
+	NoFaultInjectionError     = JsonRpcError{Code: -32017, Message: "Fault injection not supported"}
+	InvalidConfigError        = JsonRpcError{Code: -32018, Message: "Invalid configuration"}
+	UnsupportedVersionError   = JsonRpcError{Code: -32019, Message: "Unsupported api version"}
+	AssetNotAllowedError      = JsonRpcError{Code: -32020, Message: "Asset not allowed"}
+	CloseAllInProgressError   = JsonRpcError{Code: -32021, Message: "Already closing all channels"}
+	UnknownObjectiveTypeError = JsonRpcError{Code: -32022, Message: "Unknown objective type"}
+	InvalidSubscriptionError  = JsonRpcError{Code: -32023, Message: "Invalid subscription"}
+	SubscriptionNotFoundError = JsonRpcError{Code: -32024, Message: "Subscription not found"}
+	SubscriptionEndedError    = JsonRpcError{Code: -32025, Message: "Subscription is not active"}
+	InvalidEscrowError        = JsonRpcError{Code: -32026, Message: "Invalid escrow"}
+	EscrowNotFoundError       = JsonRpcError{Code: -32027, Message: "Escrow not found"}
+	EscrowNotOpenError        = JsonRpcError{Code: -32028, Message: "Escrow is not open"}
+	ResultMismatchError       = JsonRpcError{Code: -32029, Message: "Result does not match the commitment"}
+	InvalidBatchError         = JsonRpcError{Code: -32030, Message: "Invalid batch of channels"}
+	BatchNotFoundError        = JsonRpcError{Code: -32031, Message: "Batch not found"}
+	CounterpartyRefusedError  = JsonRpcError{Code: -32032, Message: "Counterparty refused the channel"}
+	PeerBlockedError          = JsonRpcError{Code: -32033, Message: "Peer is blocked"}
+	VoucherExpiredError       = JsonRpcError{Code: -32034, Message: "Voucher expired"}
+	WithdrawalRefusedError    = JsonRpcError{Code: -32035, Message: "Ledger withdrawal refused"}
+	RebindUnsupportedError    = JsonRpcError{Code: -32036, Message: "The rpc transport cannot be rebound"}
+	InvalidChannelMemoError   = JsonRpcError{Code: -32037, Message: "Invalid channel memo"}
+	FundingDeferredError      = JsonRpcError{Code: -32038, Message: "Funding deferred while exits are prioritized"}
+	VoucherReplayedError      = JsonRpcError{Code: -32039, Message: "Voucher replayed"}
+	VoucherNonceRequiredError = JsonRpcError{Code: -32040, Message: "Voucher has no nonce"}
 )
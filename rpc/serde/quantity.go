@@ -0,0 +1,69 @@
+package serde
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrInvalidQuantity is returned when unmarshalling json which is not a valid Quantity.
+const ErrInvalidQuantity = types.ConstError("invalid quantity")
+
+// Quantity is an amount (of an asset, say) in a request or response. It is marshalled as a 0x-prefixed hex string, just
+// as the *hexutil.Big amounts of the query types are, so that amounts exceeding 64 bits survive javascript clients.
+//
+// When unmarshalled, a 0x-prefixed hex string or a json integer is accepted, since clients have sent amounts as
+// integers. Negative amounts, fractions, exponents and decimal strings are rejected, as are amounts exceeding 256 bits.
+type Quantity big.Int
+
+// NewQuantity returns the Quantity of the uint64.
+func NewQuantity(x uint64) *Quantity {
+	return (*Quantity)(new(big.Int).SetUint64(x))
+}
+
+// ToInt returns the quantity as a *big.Int, which is nil if q is.
+func (q *Quantity) ToInt() *big.Int {
+	return (*big.Int)(q)
+}
+
+func (q *Quantity) String() string {
+	return hexutil.EncodeBig(q.ToInt())
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (q Quantity) MarshalText() ([]byte, error) {
+	return hexutil.Big(q).MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (q *Quantity) UnmarshalJSON(input []byte) error {
+	if string(input) == "null" {
+		return nil
+	}
+	if len(input) > 0 && input[0] == '"' {
+		if string(input) == `""` {
+			// hexutil.Big takes the empty string to be zero
+			return fmt.Errorf("%w: empty string", ErrInvalidQuantity)
+		}
+		var h hexutil.Big
+		if err := h.UnmarshalJSON(input); err != nil {
+			return fmt.Errorf("%w %s: %v", ErrInvalidQuantity, input, err)
+		}
+		*q = Quantity(h)
+		return nil
+	}
+
+	// A json integer: only digits, without a sign, fraction or exponent
+	if len(input) == 0 || bytes.IndexFunc(input, func(r rune) bool { return r < '0' || r > '9' }) != -1 {
+		return fmt.Errorf("%w %s: expected a 0x-prefixed hex string or a non-negative integer", ErrInvalidQuantity, input)
+	}
+	x, ok := new(big.Int).SetString(string(input), 10)
+	if !ok || x.BitLen() > 256 {
+		return fmt.Errorf("%w %s: exceeds 256 bits", ErrInvalidQuantity, input)
+	}
+	*q = Quantity(*x)
+	return nil
+}
@@ -0,0 +1,188 @@
+package serde
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/go-cmp/cmp"
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// maxUint256 is the largest Quantity which may be unmarshalled.
+var maxUint256 = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+func TestQuantityRoundTrip(t *testing.T) {
+	for _, x := range []*big.Int{
+		big.NewInt(0),
+		big.NewInt(1),
+		big.NewInt(255),
+		new(big.Int).SetUint64(1<<63 + 1), // overflows an int64
+		new(big.Int).SetUint64(^uint64(0)),
+		new(big.Int).Lsh(big.NewInt(1), 64), // overflows a uint64
+		maxUint256,
+	} {
+		enc, err := json.Marshal((*Quantity)(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := `"` + hexutil.EncodeBig(x) + `"`; string(enc) != want {
+			t.Errorf("expected %s to marshal as %s, got %s", x, want, enc)
+		}
+		var got Quantity
+		if err := json.Unmarshal(enc, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.ToInt().Cmp(x) != 0 {
+			t.Errorf("expected %s to round trip, got %s", x, got.ToInt())
+		}
+	}
+}
+
+func TestQuantityUnmarshal(t *testing.T) {
+	accepted := map[string]*big.Int{
+		`"0x0"`:                big.NewInt(0),
+		`"0x2a"`:               big.NewInt(42),
+		`"0x2A"`:               big.NewInt(42),
+		`0`:                    big.NewInt(0),
+		`42`:                   big.NewInt(42),
+		`18446744073709551616`: new(big.Int).Lsh(big.NewInt(1), 64),
+		`"` + hexutil.EncodeBig(maxUint256) + `"`: maxUint256,
+		maxUint256.String():                       maxUint256,
+	}
+	for input, want := range accepted {
+		var got Quantity
+		if err := json.Unmarshal([]byte(input), &got); err != nil {
+			t.Errorf("expected %s to be accepted, got %v", input, err)
+			continue
+		}
+		if got.ToInt().Cmp(want) != 0 {
+			t.Errorf("expected %s to unmarshal as %s, got %s", input, want, got.ToInt())
+		}
+	}
+
+	rejected := []string{
+		`-1`,
+		`"-0x1"`,
+		`1.5`,
+		`1.0`,
+		`1e3`,
+		`"42"`, // decimal strings are not accepted
+		`"0x"`,
+		`"0x02a"`, // leading zeros
+		`"2a"`,
+		`""`,
+		`true`,
+		`{}`,
+		`[]`,
+		`"` + hexutil.EncodeBig(new(big.Int).Lsh(big.NewInt(1), 256)) + `"`,
+		new(big.Int).Lsh(big.NewInt(1), 256).String(),
+	}
+	for _, input := range rejected {
+		var got Quantity
+		err := json.Unmarshal([]byte(input), &got)
+		if err == nil {
+			t.Errorf("expected %s to be rejected, got %s", input, got.ToInt())
+			continue
+		}
+		if !errors.Is(err, ErrInvalidQuantity) {
+			// json itself rejects some of the input, such as a fraction, before it reaches the Quantity
+			var syntaxErr *json.SyntaxError
+			var typeErr *json.UnmarshalTypeError
+			if !errors.As(err, &syntaxErr) && !errors.As(err, &typeErr) {
+				t.Errorf("expected %s to be rejected as an invalid quantity, got %v", input, err)
+			}
+		}
+	}
+
+	// A missing or null quantity is left unset, for validation to reject where it is required
+	var req PaymentRequest
+	if err := json.Unmarshal([]byte(`{"Amount":null,"Channel":"0x01"}`), &req); err != nil || req.Amount != nil {
+		t.Errorf("expected a null amount to be left unset, got %v (%v)", req.Amount, err)
+	}
+}
+
+// TestRequestQuantitiesRoundTrip checks that each request with an amount round trips through json, and that the
+// amounts marshal just as the amounts of the query types do.
+func TestRequestQuantitiesRoundTrip(t *testing.T) {
+	large := (*Quantity)(new(big.Int).Lsh(big.NewInt(1), 70))
+	channel := types.Destination{1}
+
+	roundTrip := func(t *testing.T, v, got any) {
+		t.Helper()
+		enc, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(enc, got); err != nil {
+			t.Fatal(err)
+		}
+		comparer := cmp.Comparer(func(a, b *Quantity) bool { return a.ToInt().Cmp(b.ToInt()) == 0 })
+		if diff := cmp.Diff(v, got, comparer); diff != "" {
+			t.Errorf("round trip mismatch (-want +got):\n%s", diff)
+		}
+	}
+
+	t.Run("PaymentRequest", func(t *testing.T) {
+		roundTrip(t, &PaymentRequest{Amount: large, Channel: channel, PaymentId: "order-1"}, &PaymentRequest{})
+	})
+	t.Run("ComputeVoucherHashRequest", func(t *testing.T) {
+		roundTrip(t, &ComputeVoucherHashRequest{ChannelId: channel, Amount: large}, &ComputeVoucherHashRequest{})
+	})
+	t.Run("GetQuoteRequest", func(t *testing.T) {
+		roundTrip(t, &GetQuoteRequest{Intermediary: types.Address{1}, CounterParty: types.Address{2}, Amount: large}, &GetQuoteRequest{})
+	})
+	t.Run("CreateSubscriptionRequest", func(t *testing.T) {
+		roundTrip(t, &CreateSubscriptionRequest{Channel: channel, Amount: NewQuantity(5), Interval: time.Minute, Cap: large}, &CreateSubscriptionRequest{})
+	})
+	t.Run("CreateEscrowRequest", func(t *testing.T) {
+		roundTrip(t, &CreateEscrowRequest{Channel: channel, Amount: large, Timeout: time.Hour}, &CreateEscrowRequest{})
+	})
+	t.Run("CreateLedgerChannelsBatchRequest", func(t *testing.T) {
+		roundTrip(t, &CreateLedgerChannelsBatchRequest{Counterparties: []types.Address{{1}}, MyDeposit: large, TheirDeposit: NewQuantity(0)}, &CreateLedgerChannelsBatchRequest{})
+	})
+	t.Run("ProbeCounterpartyRequest", func(t *testing.T) {
+		roundTrip(t, &ProbeCounterpartyRequest{Counterparty: types.Address{1}, Deposit: large, CounterDeposit: NewQuantity(0)}, &ProbeCounterpartyRequest{})
+	})
+
+	t.Run("MatchesQueryTypes", func(t *testing.T) {
+		balance := query.PaymentChannelBalance{PaidSoFar: (*hexutil.Big)(large.ToInt()), RemainingFunds: (*hexutil.Big)(large.ToInt())}
+		fromQuery, err := json.Marshal(balance.PaidSoFar)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fromSerde, err := json.Marshal(large)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(fromQuery) != string(fromSerde) {
+			t.Errorf("expected amounts to marshal alike, got %s from query and %s from serde", fromQuery, fromSerde)
+		}
+	})
+}
+
+func TestValidateQuantities(t *testing.T) {
+	channel := types.Destination{1}
+	for name, err := range map[string]error{
+		"zero payment":       ValidatePaymentRequest(PaymentRequest{Amount: NewQuantity(0), Channel: channel}),
+		"missing payment":    ValidatePaymentRequest(PaymentRequest{Channel: channel}),
+		"negative payment":   ValidatePaymentRequest(PaymentRequest{Amount: (*Quantity)(big.NewInt(-1)), Channel: channel}),
+		"missing quote":      ValidateGetQuoteRequest(GetQuoteRequest{Intermediary: types.Address{1}, CounterParty: types.Address{2}}),
+		"missing cap":        ValidateCreateSubscriptionRequest(CreateSubscriptionRequest{Amount: NewQuantity(1)}),
+		"zero escrow":        ValidateCreateEscrowRequest(CreateEscrowRequest{Amount: NewQuantity(0)}),
+		"missing deposit":    ValidateCreateLedgerChannelsBatchRequest(CreateLedgerChannelsBatchRequest{MyDeposit: NewQuantity(1)}),
+		"negative probe":     ValidateProbeCounterpartyRequest(ProbeCounterpartyRequest{Counterparty: types.Address{1}, Deposit: (*Quantity)(big.NewInt(-1)), CounterDeposit: NewQuantity(0)}),
+		"missing hash input": ValidateComputeVoucherHashRequest(ComputeVoucherHashRequest{ChannelId: channel}),
+	} {
+		if !errors.Is(err, InvalidParamsError) {
+			t.Errorf("expected a request with a %s amount to be invalid, got %v", name, err)
+		}
+	}
+	if err := ValidatePaymentRequest(PaymentRequest{Amount: NewQuantity(1), Channel: channel}); err != nil {
+		t.Errorf("expected a payment of 1 to be valid, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package serde
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrInvalidRequestId is returned when unmarshalling json which is not a valid RequestId.
+const ErrInvalidRequestId = types.ConstError("invalid request id")
+
+// RequestId is the id of a json-rpc request, which its response echoes. The json-rpc spec allows a string, a number or
+// null, and clients use each: the id is kept as it was sent, so that it is echoed unchanged.
+//
+// When unmarshalled, a json string, an integer or null is accepted. Fractions and exponents, which the spec discourages,
+// are rejected, as are other json values. The zero RequestId is the null id, used in responses to requests whose id
+// could not be read.
+type RequestId struct {
+	// raw is the json of the id, or empty for null
+	raw string
+}
+
+// NumberId returns the RequestId of the integer.
+func NumberId(n uint64) RequestId {
+	return RequestId{raw: strconv.FormatUint(n, 10)}
+}
+
+// StringId returns the RequestId of the string.
+func StringId(s string) RequestId {
+	raw, _ := json.Marshal(s)
+	return RequestId{raw: string(raw)}
+}
+
+// IsNull returns whether the id is null.
+func (id RequestId) IsNull() bool {
+	return id.raw == ""
+}
+
+// Uint64 returns the id if it is an integer which fits in a uint64.
+func (id RequestId) Uint64() (uint64, bool) {
+	n, err := strconv.ParseUint(id.raw, 10, 64)
+	return n, err == nil
+}
+
+// String returns the id as it was sent, with strings unquoted, or "null".
+func (id RequestId) String() string {
+	if id.IsNull() {
+		return "null"
+	}
+	var s string
+	if json.Unmarshal([]byte(id.raw), &s) == nil {
+		return s
+	}
+	return id.raw
+}
+
+// MarshalJSON implements json.Marshaler.
+func (id RequestId) MarshalJSON() ([]byte, error) {
+	if id.IsNull() {
+		return []byte("null"), nil
+	}
+	return []byte(id.raw), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (id *RequestId) UnmarshalJSON(input []byte) error {
+	input = bytes.TrimSpace(input)
+	switch {
+	case string(input) == "null":
+		*id = RequestId{}
+		return nil
+	case len(input) > 0 && input[0] == '"':
+		var s string
+		if err := json.Unmarshal(input, &s); err != nil {
+			return fmt.Errorf("%w %s: %v", ErrInvalidRequestId, input, err)
+		}
+		*id = StringId(s)
+		return nil
+	}
+
+	// A json integer, which may be negative, but has no fraction or exponent
+	digits := bytes.TrimPrefix(input, []byte("-"))
+	if len(digits) == 0 || bytes.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' }) != -1 {
+		return fmt.Errorf("%w: %s", ErrInvalidRequestId, input)
+	}
+	*id = RequestId{raw: string(input)}
+	return nil
+}
@@ -0,0 +1,48 @@
+package serde
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestRequestIdRoundTrip(t *testing.T) {
+	for _, input := range []string{`"abc"`, `""`, `"with \"quotes\""`, `0`, `42`, `-7`, `18446744073709551615`, `123456789012345678901234567890`, `null`} {
+		var id RequestId
+		if err := json.Unmarshal([]byte(input), &id); err != nil {
+			t.Fatalf("expected %s to be accepted, got %v", input, err)
+		}
+		enc, err := json.Marshal(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(enc) != input {
+			t.Errorf("expected %s to be echoed unchanged, got %s", input, enc)
+		}
+	}
+
+	if id := NumberId(18446744073709551615); id.String() != "18446744073709551615" {
+		t.Errorf("expected the largest uint64 to be kept exactly, got %s", id)
+	}
+	if n, ok := NumberId(7).Uint64(); !ok || n != 7 {
+		t.Errorf("expected 7, got %d", n)
+	}
+	if _, ok := StringId("7").Uint64(); ok {
+		t.Error("expected a string id not to be a number")
+	}
+	if id := StringId("a-1"); id.String() != "a-1" {
+		t.Errorf("expected the string to be unquoted, got %s", id)
+	}
+	if !(RequestId{}).IsNull() || (RequestId{}).String() != "null" {
+		t.Error("expected the zero id to be null")
+	}
+}
+
+func TestRequestIdUnmarshalRejected(t *testing.T) {
+	for _, input := range []string{`1.5`, `1e3`, `true`, `{}`, `[1]`, `-`, `"unterminated`} {
+		var id RequestId
+		if err := id.UnmarshalJSON([]byte(input)); !errors.Is(err, ErrInvalidRequestId) {
+			t.Errorf("expected %s to be rejected, got %v", input, err)
+		}
+	}
+}
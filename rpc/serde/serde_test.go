@@ -14,7 +14,7 @@ import (
 
 var someRequest JsonRpcSpecificRequest[directfund.ObjectiveRequest] = JsonRpcSpecificRequest[directfund.ObjectiveRequest]{
 	Jsonrpc: JsonRpcVersion,
-	Id:      123,
+	Id:      NumberId(123),
 	Method:  "CreateLedgerChannel",
 	Params: Params[directfund.ObjectiveRequest]{
 		AuthToken: "",
@@ -52,7 +52,7 @@ func TestUnmarshalJSON(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	if diff := cmp.Diff(someRequest, got, cmpopts.IgnoreUnexported(directfund.ObjectiveRequest{})); diff != "" {
+	if diff := cmp.Diff(someRequest, got, cmpopts.IgnoreUnexported(directfund.ObjectiveRequest{}), cmp.AllowUnexported(RequestId{})); diff != "" {
 		t.Fatalf("TestUnmarshalJSON: mismatch (-want +got):\n%s", diff)
 	}
 }
@@ -0,0 +1,122 @@
+package serde
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrUnknownField is returned by CheckFieldNames for a json object member which is not a field of the value.
+const ErrUnknownField = types.ConstError("unknown field")
+
+var (
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// CheckFieldNames returns an error if the json has an object member which is not a field of v, the value it is
+// unmarshalled into. Unlike encoding/json, which ignores unknown members and matches names case-insensitively, a member
+// must be named exactly as its field is, so that a member such as "channelID" is not taken for the field "channelId".
+// Values of types which unmarshal themselves are not checked.
+func CheckFieldNames(data []byte, v any) error {
+	return checkFieldNames(data, reflect.TypeOf(v), "")
+}
+
+func checkFieldNames(data json.RawMessage, t reflect.Type, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(jsonUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var members map[string]json.RawMessage
+		if json.Unmarshal(data, &members) != nil {
+			return nil
+		}
+		fields := jsonFields(t)
+		for name, value := range members {
+			field, ok := fields[name]
+			if !ok {
+				for known := range fields {
+					if strings.EqualFold(name, known) {
+						return fmt.Errorf("%w %q, did you mean %q", ErrUnknownField, memberPath(path, name), known)
+					}
+				}
+				return fmt.Errorf("%w %q", ErrUnknownField, memberPath(path, name))
+			}
+			if err := checkFieldNames(value, field.Type, memberPath(path, name)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		var elements []json.RawMessage
+		if json.Unmarshal(data, &elements) != nil {
+			return nil
+		}
+		for i, element := range elements {
+			if err := checkFieldNames(element, t.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		var values map[string]json.RawMessage
+		if json.Unmarshal(data, &values) != nil {
+			return nil
+		}
+		for key, value := range values {
+			if err := checkFieldNames(value, t.Elem(), memberPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields returns the fields of the struct type by the names encoding/json gives them, including the fields of
+// embedded structs
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for n, f := range jsonFields(embedded) {
+					if _, ok := fields[n]; !ok {
+						fields[n] = f
+					}
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+func memberPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,43 @@
+package serde
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckFieldNames(t *testing.T) {
+	type inner struct {
+		Name   string `json:"name"`
+		Hidden string `json:"-"`
+	}
+	type embedded struct {
+		Tag string
+	}
+	type outer struct {
+		embedded
+		Items  []inner
+		ByKey  map[string]inner
+		Amount *Quantity
+	}
+
+	for _, input := range []string{
+		`{}`,
+		`{"Tag":"a","Items":[{"name":"x"}],"ByKey":{"k":{"name":"y"}},"Amount":"0x1"}`,
+		`{"Items":null}`,
+	} {
+		if err := CheckFieldNames([]byte(input), &outer{}); err != nil {
+			t.Errorf("expected %s to be accepted, got %v", input, err)
+		}
+	}
+
+	for _, input := range []string{
+		`{"tag":"a"}`,
+		`{"Items":[{"name":"x"},{"Name":"y"}]}`,
+		`{"ByKey":{"k":{"nam":"y"}}}`,
+		`{"Items":[{"Hidden":"x"}]}`,
+	} {
+		if err := CheckFieldNames([]byte(input), &outer{}); !errors.Is(err, ErrUnknownField) {
+			t.Errorf("expected %s to be refused with %v, got %v", input, ErrUnknownField, err)
+		}
+	}
+}
@@ -1,16 +1,86 @@
 package serde
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/statechannels/go-nitro/node/query"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/types"
 )
 
+// Limits applied to every request before it is unmarshalled, so that untrusted clients cannot exhaust the node's memory.
+const (
+	MaxRequestBytes   = 64 * 1024
+	MaxRequestDepth   = 32
+	MaxRequestStrings = 16 * 1024 // the maximum length of any single string (or object key) in a request
+)
+
+// RequestTooLargeError is the RequestLimitsError for a request exceeding MaxRequestBytes.
+var RequestTooLargeError = requestLimitsError(fmt.Sprintf("request exceeds %d bytes", MaxRequestBytes))
+
+// ValidateRequestLimits checks that the request data does not exceed MaxRequestBytes, MaxRequestDepth or MaxRequestStrings.
+// It returns RequestLimitsError, with the limit exceeded as its data, if it does.
+// Malformed json is not reported, and should be checked for separately.
+func ValidateRequestLimits(requestData []byte) error {
+	if len(requestData) > MaxRequestBytes {
+		return RequestTooLargeError
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(requestData))
+	depth := 0
+	for {
+		token, err := dec.Token()
+		if err != nil {
+			// Either the end of the request, or malformed json which is reported elsewhere
+			return nil
+		}
+
+		switch t := token.(type) {
+		case json.Delim:
+			if t == '{' || t == '[' {
+				depth++
+				if depth > MaxRequestDepth {
+					return requestLimitsError(fmt.Sprintf("request nesting exceeds a depth of %d", MaxRequestDepth))
+				}
+			} else {
+				depth--
+			}
+		case string:
+			if len(t) > MaxRequestStrings {
+				return requestLimitsError(fmt.Sprintf("request contains a string exceeding %d bytes", MaxRequestStrings))
+			}
+		}
+	}
+}
+
+func requestLimitsError(reason string) JsonRpcError {
+	err := RequestLimitsError
+	err.Data = reason
+	return err
+}
+
+// positive returns true if the quantity is set and greater than zero.
+func positive(q *Quantity) bool {
+	return q != nil && q.ToInt().Sign() > 0
+}
+
+// nonNegative returns true if the quantity is set and not less than zero.
+func nonNegative(q *Quantity) bool {
+	return q != nil && q.ToInt().Sign() >= 0
+}
+
 func ValidatePaymentRequest(req PaymentRequest) error {
-	if req.Amount == 0 {
+	if !positive(req.Amount) {
 		return InvalidParamsError
 	}
 	if (req.Channel == types.Destination{}) {
 		return InvalidParamsError
 	}
+	if len(req.Context) > payments.MaxVoucherContextLength {
+		return InvalidParamsError
+	}
 	return nil
 }
 
@@ -27,3 +97,170 @@ func ValidateGetPaymentChannelsByLedgerRequest(req GetPaymentChannelsByLedgerReq
 	}
 	return nil
 }
+
+func ValidateGetSignedStateRequest(req GetSignedStateRequest) error {
+	if (req.Id == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetChannelSnapshotRequest(req GetChannelSnapshotRequest) error {
+	if (req.Id == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateComputeChannelIdRequest(req ComputeChannelIdRequest) error {
+	if len(req.Participants) < 2 {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateComputeStateHashRequest(req ComputeStateHashRequest) error {
+	if (req.ChannelId == types.Destination{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateComputeVoucherHashRequest(req ComputeVoucherHashRequest) error {
+	if (req.ChannelId == types.Destination{}) || !nonNegative(req.Amount) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetQuoteRequest(req GetQuoteRequest) error {
+	if !positive(req.Amount) {
+		return InvalidParamsError
+	}
+	if (req.Intermediary == types.Address{}) || (req.CounterParty == types.Address{}) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateProbeCounterpartyRequest(req ProbeCounterpartyRequest) error {
+	if (req.Counterparty == types.Address{}) {
+		return InvalidParamsError
+	}
+	if !nonNegative(req.Deposit) || !nonNegative(req.CounterDeposit) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateBlockPeerRequest(req BlockPeerRequest) error {
+	if (req.Address == types.Address{}) && req.PeerId == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateSetApiKeyRequest(req SetApiKeyRequest) error {
+	if req.Name == "" || req.Secret == "" || req.Scope == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateRemoveApiKeyRequest(req RemoveApiKeyRequest) error {
+	if req.Name == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateUnblockPeerRequest(req UnblockPeerRequest) error {
+	if (req.Address == types.Address{}) && req.PeerId == "" {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+// validListing returns true if the filter selects a known status, and the page does not exceed query.MaxPageLimit.
+func validListing(filter query.ChannelFilter, page query.PageRequest) bool {
+	switch filter.Status {
+	case "", query.Proposed, query.Open, query.Closing, query.Complete:
+	default:
+		return false
+	}
+	return page.Limit <= query.MaxPageLimit
+}
+
+func ValidateListLedgerChannelsRequest(req ListLedgerChannelsRequest) error {
+	if !validListing(req.Filter, req.Page) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateListPaymentChannelsByLedgerRequest(req ListPaymentChannelsByLedgerRequest) error {
+	if (req.LedgerId == types.Destination{}) || !validListing(req.Filter, req.Page) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateWithdrawFromLedgerChannelRequest(req WithdrawFromLedgerChannelRequest) error {
+	if (req.ChannelId == types.Destination{}) || !positive(req.Amount) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateRebindRpcServerRequest(req RebindRpcServerRequest) error {
+	rotatesCert := req.TlsCertFilepath != "" || req.TlsKeyFilepath != ""
+	if req.Port > 65535 || (req.Port == 0 && !rotatesCert) || (rotatesCert && (req.TlsCertFilepath == "" || req.TlsKeyFilepath == "")) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateCreateSubscriptionRequest(req CreateSubscriptionRequest) error {
+	if !positive(req.Amount) || !positive(req.Cap) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateCreateEscrowRequest(req CreateEscrowRequest) error {
+	if !positive(req.Amount) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateCreateLedgerChannelsBatchRequest(req CreateLedgerChannelsBatchRequest) error {
+	if !nonNegative(req.MyDeposit) || !nonNegative(req.TheirDeposit) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateGetBalanceHistoryRequest(req GetBalanceHistoryRequest) error {
+	if !req.From.Before(req.To) {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateExportActivityRequest(req ExportActivityRequest) error {
+	if !req.From.Before(req.To) {
+		return InvalidParamsError
+	}
+	if req.Format != query.CsvExportFormat && req.Format != query.JsonlExportFormat {
+		return InvalidParamsError
+	}
+	return nil
+}
+
+func ValidateSetConfigRequest(req SetConfigRequest) error {
+	if len(req.Settings) == 0 {
+		return InvalidParamsError
+	}
+	return nil
+}
@@ -1,15 +1,25 @@
 package rpc
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"fmt"
 	"log/slog"
-	"math/big"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	"github.com/statechannels/go-nitro/internal/logging"
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/node/engine"
+	"github.com/statechannels/go-nitro/node/engine/store"
 	"github.com/statechannels/go-nitro/node/query"
 	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/protocols"
@@ -27,45 +37,207 @@ import (
 type RpcServer struct {
 	transport transport.Responder
 	node      *nitro.Node
-	logger    *slog.Logger
-	cancel    context.CancelFunc
-	wg        *sync.WaitGroup
+	// nodeMu is held for reading while a request is executed on the node, and for writing while the node is swapped
+	nodeMu        *sync.RWMutex
+	logger        *slog.Logger
+	cancel        context.CancelFunc
+	wg            *sync.WaitGroup
+	notifications bool
+	auth          *authenticator
+	// strictParams rejects requests whose params have members which are not fields of the method's request
+	strictParams bool
+	// certDir is the folder from which rebind_rpc_server may read a certificate and key, if it may read any
+	certDir string
+}
+
+// ServerOption configures an RpcServer.
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	auth         AuthConfig
+	strictParams bool
+	certDir      string
+}
+
+// WithAuth authenticates the clients of the server as config describes. By default, any client is issued an auth
+// token permitting every method.
+func WithAuth(config AuthConfig) ServerOption {
+	return func(o *serverOptions) {
+		o.auth = config
+	}
+}
+
+// WithStrictParams rejects requests whose params have a member which is not a field of the method's request, or which
+// differs from the field's name in case, with an invalid params error. By default, such members are ignored or matched
+// case-insensitively, which can hide typos in the field names used by clients.
+func WithStrictParams() ServerOption {
+	return func(o *serverOptions) {
+		o.strictParams = true
+	}
+}
+
+// WithCertDir permits rebind_rpc_server to replace the server's certificate with one read from files in dir, such as
+// the folder to which renewed certificates are written. By default, the certificate cannot be replaced over rpc, as
+// the files are read from the node's host at paths chosen by the client.
+func WithCertDir(dir string) ServerOption {
+	return func(o *serverOptions) {
+		o.certDir = dir
+	}
+}
+
+// applyServerOptions returns the options configured by opts
+func applyServerOptions(opts []ServerOption) serverOptions {
+	o := serverOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
 }
 
 func (rs *RpcServer) Url() string {
 	return rs.transport.Url()
 }
 
+// Handle serves handler at path alongside the rpc api, such as to export metrics. Only the http transport serves other
+// endpoints.
+func (rs *RpcServer) Handle(path string, handler http.Handler) error {
+	hs, ok := rs.transport.(transport.HandlerServer)
+	if !ok {
+		return fmt.Errorf("the rpc transport cannot serve %s: only the http transport serves other endpoints", path)
+	}
+	return hs.Handle(path, handler)
+}
+
+// Rebind moves the rpc server to listen on port, serving cert, without dropping the connections of its clients: the
+// new listener is bound before the old one is closed, and the connections accepted on the old port stay open. An
+// empty port keeps the current port, and a nil cert the current certificate. Only the http transport can be rebound.
+func (rs *RpcServer) Rebind(port string, cert *tls.Certificate) error {
+	r, ok := rs.transport.(transport.Rebinder)
+	if !ok {
+		return serde.RebindUnsupportedError
+	}
+	return r.Rebind(port, cert)
+}
+
+// certFilepath resolves the path of a certificate or key file which a client asked the server to read, returning an
+// error unless the file is within the server's certificate folder, once any symbolic links are followed.
+func (rs *RpcServer) certFilepath(path string) (string, error) {
+	if rs.certDir == "" {
+		return "", fmt.Errorf("%w: the rpc server was not configured with a certificate folder", serde.InvalidParamsError)
+	}
+	dir, err := filepath.EvalSymlinks(rs.certDir)
+	if err != nil {
+		return "", err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", serde.InvalidParamsError, err)
+	}
+	resolved, err = filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(dir, resolved); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%w: %s is not within the certificate folder", serde.InvalidParamsError, path)
+	}
+	return resolved, nil
+}
+
+// AuthorizeAdmin checks that the token, which is an auth token or an api key, may operate the node, as an admin key
+// and the tokens issued for one may. Endpoints served apart from the rpc api, such as the node's debug endpoint, use
+// it to share the server's auth.
+func (rs *RpcServer) AuthorizeAdmin(token string) error {
+	if err := rs.auth.authorize(token, permAdmin); err != nil {
+		return err
+	}
+	return rs.auth.authorizeMethod(token, serde.GetDebugBundleMethod)
+}
+
 func (rs *RpcServer) Address() *types.Address {
+	rs.nodeMu.RLock()
+	defer rs.nodeMu.RUnlock()
 	return rs.node.Address
 }
 
 func (rs *RpcServer) Close() error {
+	rs.nodeMu.Lock()
 	rs.cancel()
 	rs.wg.Wait()
+	rs.nodeMu.Unlock()
 
 	err := rs.transport.Close()
 	if err != nil {
 		return err
 	}
+	rs.nodeMu.Lock()
+	defer rs.nodeMu.Unlock()
 	return rs.node.Close()
 }
 
+// SwapNode replaces the node which requests are executed on with the node returned by restart, which is passed the
+// current node. For example, restart may close the current node and construct a new one from its recovered store.
+// The transport (and so every client connection) is kept open throughout: requests received during the swap wait
+// for it to complete, and are then executed on the new node.
+//
+// If restart returns an error, the current node is kept. restart should therefore only close the current node once
+// it is sure to succeed, or accept that requests will fail until a later swap succeeds.
+func (rs *RpcServer) SwapNode(restart func(current *nitro.Node) (*nitro.Node, error)) error {
+	rs.nodeMu.Lock()
+	defer rs.nodeMu.Unlock()
+
+	// Stop forwarding notifications from the current node
+	rs.cancel()
+	rs.wg.Wait()
+
+	rs.logger.Info("Swapping node")
+	restarted, err := restart(rs.node)
+	if err != nil {
+		rs.logger.Error("Could not swap node, keeping the current node", "error", err)
+		rs.startNotifications()
+		return err
+	}
+	if *restarted.Address != *rs.node.Address {
+		rs.startNotifications()
+		return fmt.Errorf("cannot swap node %s for node %s with a different address", rs.node.Address, restarted.Address)
+	}
+
+	rs.node = restarted
+	rs.startNotifications()
+	rs.logger.Info("Swapped node")
+	return nil
+}
+
 // newRpcServerWithoutNotifications creates a new rpc server without notifications enabled
-func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer, error) {
-	logger := slog.Default()
+func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Responder, opts ...ServerOption) (*RpcServer, error) {
+	o := applyServerOptions(opts)
+	auth, err := newAuthenticator(o.auth)
+	if err != nil {
+		return nil, err
+	}
+	logger := logging.ModuleLogger(logging.RPC_MODULE)
 	if hasNitroAddress := (nitroNode.Address != nil) && (nitroNode.Address != &types.Address{}); hasNitroAddress {
-		logger = logging.LoggerWithAddress(slog.Default(), *nitroNode.Address)
+		logger = logging.LoggerWithAddress(logger, *nitroNode.Address)
 	}
 	rs := &RpcServer{
-		transport: trans,
-		node:      nitroNode,
-		cancel:    func() {},
-		wg:        &sync.WaitGroup{},
-		logger:    logger,
+		transport:    trans,
+		node:         nitroNode,
+		nodeMu:       &sync.RWMutex{},
+		cancel:       func() {},
+		wg:           &sync.WaitGroup{},
+		logger:       logger,
+		auth:         auth,
+		strictParams: o.strictParams,
+		certDir:      o.certDir,
 	}
 
-	err := rs.registerHandlers()
+	err = rs.registerHandlers()
 	if err != nil {
 		return nil, err
 	}
@@ -73,16 +245,45 @@ func newRpcServerWithoutNotifications(nitroNode *nitro.Node, trans transport.Res
 	return rs, nil
 }
 
-func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder, opts ...ServerOption) (*RpcServer, error) {
+	o := applyServerOptions(opts)
+	auth, err := newAuthenticator(o.auth)
+	if err != nil {
+		return nil, err
+	}
 	rs := &RpcServer{
-		transport: trans,
-		node:      nitroNode,
-		cancel:    cancel,
-		wg:        &sync.WaitGroup{},
-		logger:    logging.LoggerWithAddress(slog.Default(), *nitroNode.Address),
+		transport:     trans,
+		node:          nitroNode,
+		nodeMu:        &sync.RWMutex{},
+		wg:            &sync.WaitGroup{},
+		logger:        logging.LoggerWithAddress(logging.ModuleLogger(logging.RPC_MODULE), *nitroNode.Address),
+		notifications: true,
+		auth:          auth,
+		strictParams:  o.strictParams,
+		certDir:       o.certDir,
+	}
+
+	if n, ok := trans.(transport.AddressedNotifier); ok {
+		n.SetNotificationAddress(nitroNode.Address.String())
+	}
+	rs.startNotifications()
+	err = rs.registerHandlers()
+	if err != nil {
+		return nil, err
 	}
 
+	return rs, nil
+}
+
+// startNotifications begins forwarding notifications from the node to the transport, if notifications are enabled.
+func (rs *RpcServer) startNotifications() {
+	if !rs.notifications {
+		rs.cancel = func() {}
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	rs.cancel = cancel
+
 	rs.wg.Add(1)
 
 	// The update channels are initialized syncronously.
@@ -91,41 +292,100 @@ func NewRpcServer(nitroNode *nitro.Node, trans transport.Responder) (*RpcServer,
 	completedObjChan := rs.node.CompletedObjectives()
 	ledgerUpdateChan := rs.node.LedgerUpdates()
 	paymentUpdateChan := rs.node.PaymentUpdates()
+	voucherUpdateChan := rs.node.VoucherUpdates()
+	capacityEventChan := rs.node.CapacityEvents()
 
-	go rs.sendNotifications(ctx, completedObjChan, ledgerUpdateChan, paymentUpdateChan)
-	err := rs.registerHandlers()
-	if err != nil {
-		return nil, err
-	}
+	go rs.sendNotifications(ctx, completedObjChan, ledgerUpdateChan, paymentUpdateChan, voucherUpdateChan, capacityEventChan)
+}
 
-	return rs, nil
+// registerHandlers registers a handler for each supported version of the rpc api, and authorizes the subscriptions of
+// the transport, if it authorizes them
+func (rs *RpcServer) registerHandlers() error {
+	for _, apiVersion := range serde.SupportedApiVersions {
+		if err := rs.transport.RegisterRequestHandler(apiVersion, rs.handler(apiVersion)); err != nil {
+			return err
+		}
+	}
+	// Notifications are only sent to clients presenting a token which permits reading from the node
+	if a, ok := rs.transport.(transport.SubscriptionAuthorizer); ok {
+		a.AuthorizeSubscriptions(rs.auth.authorizeSubscription)
+	}
+	return nil
 }
 
-// registerHandlers registers the handlers for the rpc server
-func (rs *RpcServer) registerHandlers() (err error) {
-	handlerV1 := func(requestData []byte) []byte {
+// handler returns the handler for requests to the given version of the rpc api
+func (rs *RpcServer) handler(apiVersion string) func([]byte) []byte {
+	return func(requestData []byte) (response []byte) {
+		if err := serde.ValidateRequestLimits(requestData); err != nil {
+			limitsErr := err.(serde.JsonRpcError)
+			rs.logger.Warn("request exceeds limits", "reason", limitsErr.Data)
+			return marshalResponse(serde.NewJsonRpcErrorResponse(requestIdOf(requestData), limitsErr))
+		}
+
 		if !json.Valid(requestData) {
 			rs.logger.Error("request is not valid json")
-			errRes := serde.NewJsonRpcErrorResponse(0, serde.ParseError)
+			errRes := serde.NewJsonRpcErrorResponse(serde.RequestId{}, serde.ParseError)
 			return marshalResponse(errRes)
 		}
 
-		jsonrpcReq, errRes := validateJsonrpcRequest(requestData)
-		rs.logger.Debug("Rpc server received request", "request", jsonrpcReq)
+		jsonrpcReq, notification, errRes := validateJsonrpcRequest(requestData)
+		// Each request starts a flow whose log lines, here, in the engine and at the peers it involves, carry the same
+		// correlation id
+		correlationId := logging.NewCorrelationId()
+		ctx := logging.ContextWithCorrelationId(context.Background(), correlationId)
+		rs.logger.Debug("Rpc server received request", "request", jsonrpcReq, logging.WithCorrelationIdAttribute(correlationId))
 		if errRes != nil {
 			rs.logger.Error("could not validate jsonrpc request")
 
 			return errRes
 		}
 
-		switch serde.RequestMethod(jsonrpcReq.Method) {
+		if notification {
+			// A notification is executed like any request, but its response is dropped
+			defer func() { response = nil }()
+		}
+
+		// Wait for any swap of the node to complete
+		rs.nodeMu.RLock()
+		defer rs.nodeMu.RUnlock()
+
+		method := serde.RequestMethod(jsonrpcReq.Method)
+		if !serde.MethodServed(method, apiVersion) {
+			errRes := serde.NewJsonRpcErrorResponse(jsonrpcReq.Id, serde.MethodNotFoundError)
+			return marshalResponse(errRes)
+		}
+
+		// Api keys may be restricted to some methods, whatever the permission each method requires, which is checked as
+		// its request is processed
+		if err := rs.auth.authorizeMethod(authToken(requestData), method); err != nil {
+			rs.logger.Warn(serde.InvalidAuthTokenError.Message, "method", method, "error", err)
+			return marshalResponse(serde.NewJsonRpcErrorResponse(jsonrpcReq.Id, serde.InvalidAuthTokenError))
+		}
+
+		switch method {
 		case serde.GetAuthTokenMethod:
 			return processRequest(rs, permNone, requestData, func(req serde.AuthRequest) (string, error) {
-				return generateAuthToken(req.Id, allPermissions)
+				token, err := rs.auth.issueToken(req.ApiKey, req.Id)
+				if err != nil {
+					rs.logger.Warn("refused an auth token", "error", err)
+					return "", serde.InvalidAuthTokenError
+				}
+				return token, nil
 			})
 		case serde.CreateVoucherRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req serde.PaymentRequest) (payments.Voucher, error) {
-				return rs.node.CreateVoucher(req.Channel, big.NewInt(int64(req.Amount)))
+				if req.Amount == nil || len(req.Context) > payments.MaxVoucherContextLength {
+					return payments.Voucher{}, serde.InvalidParamsError
+				}
+				var voucher payments.Voucher
+				var err error
+				if req.Expiry != 0 {
+					voucher, err = rs.node.CreateVoucherWithExpiry(req.Channel, req.Amount.ToInt(), req.PaymentId, time.Unix(int64(req.Expiry), 0))
+				} else {
+					voucher, err = rs.node.CreateVoucherWithId(req.Channel, req.Amount.ToInt(), req.PaymentId)
+				}
+				voucher.Context = req.Context
+				return voucher, err
 			})
 		case serde.ReceiveVoucherRequestMethod:
 			return processRequest(rs, permRead, requestData, func(req payments.Voucher) (payments.ReceiveVoucherSummary, error) {
@@ -136,32 +396,65 @@ func (rs *RpcServer) registerHandlers() (err error) {
 				return rs.node.Address.Hex(), nil
 			})
 		case serde.VersionMethod:
-			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
-				return rs.node.Version(), nil
+			if apiVersion == serde.ApiV1 {
+				return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (string, error) {
+					return rs.node.Version(), nil
+				})
+			}
+			return processRequest(rs, permNone, requestData, func(req serde.NoPayloadRequest) (serde.VersionResponse, error) {
+				return serde.VersionResponse{Version: rs.node.Version(), ApiVersion: apiVersion, SupportedApiVersions: serde.SupportedApiVersions}, nil
+			})
+		case serde.NegotiateVersionMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.NegotiateVersionRequest) (string, error) {
+				version, ok := serde.SelectApiVersion(req.Versions)
+				if !ok {
+					return "", serde.UnsupportedVersionError
+				}
+				return version, nil
 			})
 		case serde.CreateLedgerChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req directfund.ObjectiveRequest) (directfund.ObjectiveResponse, error) {
-				return rs.node.CreateLedgerChannel(req.CounterParty, req.ChallengeDuration, req.Outcome)
+			return processRequest(rs, permSign, requestData, func(req serde.CreateLedgerChannelRequest) (directfund.ObjectiveResponse, error) {
+				opts := nitro.CreateChannelOptions{Force: req.Force, Tags: req.Tags, Memo: req.Memo, Probe: req.Probe}
+				return rs.node.CreateLedgerChannelWithOptions(ctx, req.CounterParty, req.ChallengeDuration, req.Outcome, opts)
 			})
 		case serde.CloseLedgerChannelRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req directdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
-				return rs.node.CloseLedgerChannel(req.ChannelId)
+				return rs.node.CloseLedgerChannelContext(ctx, req.ChannelId)
+			})
+		case serde.WithdrawFromLedgerChannelMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.WithdrawFromLedgerChannelRequest) (query.LedgerWithdrawalInfo, error) {
+				if err := serde.ValidateWithdrawFromLedgerChannelRequest(req); err != nil {
+					return query.LedgerWithdrawalInfo{}, err
+				}
+				return rs.node.WithdrawFromLedgerChannelContext(ctx, req.ChannelId, req.Amount.ToInt())
 			})
 		case serde.CreatePaymentChannelRequestMethod:
-			return processRequest(rs, permSign, requestData, func(req virtualfund.ObjectiveRequest) (virtualfund.ObjectiveResponse, error) {
-				return rs.node.CreatePaymentChannel(req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
+			return processRequest(rs, permSign, requestData, func(req serde.CreatePaymentChannelRequest) (virtualfund.ObjectiveResponse, error) {
+				opts := nitro.CreateChannelOptions{Force: req.Force, Tags: req.Tags, Memo: req.Memo, Probe: req.Probe}
+				return rs.node.CreatePaymentChannelWithOptions(ctx, req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome, opts)
+			})
+		case serde.GetOrCreatePaymentChannelMethod:
+			return processRequest(rs, permSign, requestData, func(req virtualfund.ObjectiveRequest) (serde.GetOrCreatePaymentChannelResponse, error) {
+				response, created, err := rs.node.GetOrCreatePaymentChannel(ctx, req.Intermediaries, req.CounterParty, req.ChallengeDuration, req.Outcome)
+				return serde.GetOrCreatePaymentChannelResponse{ObjectiveResponse: response, Created: created}, err
 			})
 		case serde.ClosePaymentChannelRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req virtualdefund.ObjectiveRequest) (protocols.ObjectiveId, error) {
-				return rs.node.ClosePaymentChannel(req.ChannelId)
+				return rs.node.ClosePaymentChannelContext(ctx, req.ChannelId)
 			})
 		case serde.PayRequestMethod:
 			return processRequest(rs, permSign, requestData, func(req serde.PaymentRequest) (serde.PaymentRequest, error) {
 				if err := serde.ValidatePaymentRequest(req); err != nil {
 					return serde.PaymentRequest{}, err
 				}
-				rs.node.Pay(req.Channel, big.NewInt(int64(req.Amount)))
-				return req, nil
+				if req.PaymentId != "" || req.Context != "" || req.Expiry != 0 {
+					opts := nitro.PayOptions{PaymentId: req.PaymentId, Context: req.Context}
+					if req.Expiry != 0 {
+						opts.Expiry = time.Unix(int64(req.Expiry), 0)
+					}
+					return req, rs.node.PayWithOptions(ctx, req.Channel, req.Amount.ToInt(), opts)
+				}
+				return req, rs.node.PayContext(ctx, req.Channel, req.Amount.ToInt())
 			})
 		case serde.GetPaymentChannelRequestMethod:
 			return processRequest(rs, permRead, requestData, func(req serde.GetPaymentChannelRequest) (query.PaymentChannelInfo, error) {
@@ -178,6 +471,51 @@ func (rs *RpcServer) registerHandlers() (err error) {
 			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) ([]query.LedgerChannelInfo, error) {
 				return rs.node.GetAllLedgerChannels()
 			})
+		case serde.ListLedgerChannelsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.ListLedgerChannelsRequest) (query.LedgerChannelPage, error) {
+				if err := serde.ValidateListLedgerChannelsRequest(req); err != nil {
+					return query.LedgerChannelPage{}, err
+				}
+				return rs.node.ListLedgerChannels(req.Filter, req.Page)
+			})
+		case serde.StreamAllLedgerChannelsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.StreamRequest) (serde.StreamResponse, error) {
+				stream, err := rs.newStream(req.Subscription, authToken(requestData))
+				if err != nil {
+					return serde.StreamResponse{}, err
+				}
+				// The ledger channels are read while the node is held for the request, and sent once it is released
+				ledgers, err := rs.node.GetAllLedgerChannels()
+				if err != nil {
+					return serde.StreamResponse{}, err
+				}
+				return rs.streamLedgerChannels(stream, ledgers, req.PageSize), nil
+			})
+		case serde.FindChannelsByTagMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.FindChannelsByTagRequest) (serde.FindChannelsByTagResponse, error) {
+				return rs.node.FindChannelsByTag(req.Key, req.Value)
+			})
+		case serde.ComputeChannelIdMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.ComputeChannelIdRequest) (types.Destination, error) {
+				if err := serde.ValidateComputeChannelIdRequest(req); err != nil {
+					return types.Destination{}, err
+				}
+				return state.ComputeChannelId(req.Participants, req.ChannelNonce, req.AppDefinition, req.ChallengeDuration), nil
+			})
+		case serde.ComputeStateHashMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.ComputeStateHashRequest) (types.Bytes32, error) {
+				if err := serde.ValidateComputeStateHashRequest(req); err != nil {
+					return types.Bytes32{}, err
+				}
+				return state.ComputeStateHash(req.ChannelId, state.VariablePart{AppData: req.AppData, Outcome: req.Outcome, TurnNum: req.TurnNum, IsFinal: req.IsFinal})
+			})
+		case serde.ComputeVoucherHashMethod:
+			return processRequest(rs, permNone, requestData, func(req serde.ComputeVoucherHashRequest) (types.Bytes32, error) {
+				if err := serde.ValidateComputeVoucherHashRequest(req); err != nil {
+					return types.Bytes32{}, err
+				}
+				return payments.ComputeVoucherHash(req.ChannelId, req.Amount.ToInt())
+			})
 		case serde.GetPaymentChannelsByLedgerMethod:
 			return processRequest(rs, permRead, requestData, func(req serde.GetPaymentChannelsByLedgerRequest) ([]query.PaymentChannelInfo, error) {
 				if err := serde.ValidateGetPaymentChannelsByLedgerRequest(req); err != nil {
@@ -185,14 +523,373 @@ func (rs *RpcServer) registerHandlers() (err error) {
 				}
 				return rs.node.GetPaymentChannelsByLedger(req.LedgerId)
 			})
+		case serde.ListPaymentChannelsByLedgerMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.ListPaymentChannelsByLedgerRequest) (query.PaymentChannelPage, error) {
+				if err := serde.ValidateListPaymentChannelsByLedgerRequest(req); err != nil {
+					return query.PaymentChannelPage{}, err
+				}
+				return rs.node.ListPaymentChannelsByLedger(req.LedgerId, req.Filter, req.Page)
+			})
+		case serde.GetSignedStateMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetSignedStateRequest) (query.SignedStateInfo, error) {
+				if err := serde.ValidateGetSignedStateRequest(req); err != nil {
+					return query.SignedStateInfo{}, err
+				}
+				return rs.node.GetSignedState(req.Id)
+			})
+		case serde.GetChannelSnapshotMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.GetChannelSnapshotRequest) (payments.ChannelSnapshot, error) {
+				if err := serde.ValidateGetChannelSnapshotRequest(req); err != nil {
+					return payments.ChannelSnapshot{}, err
+				}
+				return rs.node.GetChannelSnapshot(req.Id)
+			})
+		case serde.GetLogLevelsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.LogLevelsResponse, error) {
+				return logLevels(), nil
+			})
+		case serde.SetLogLevelMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.SetLogLevelRequest) (serde.LogLevelsResponse, error) {
+				level, err := logging.ParseLevel(req.Level)
+				if err != nil {
+					return serde.LogLevelsResponse{}, serde.InvalidParamsError
+				}
+				if err := logging.SetLevel(req.Module, level); err != nil {
+					return serde.LogLevelsResponse{}, serde.InvalidParamsError
+				}
+				rs.logger.Info("log level changed", "module", req.Module, "level", level)
+				return logLevels(), nil
+			})
+		case serde.RebindRpcServerMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.RebindRpcServerRequest) (string, error) {
+				if err := serde.ValidateRebindRpcServerRequest(req); err != nil {
+					return "", err
+				}
+				var cert *tls.Certificate
+				if req.TlsCertFilepath != "" {
+					certFile, err := rs.certFilepath(req.TlsCertFilepath)
+					if err != nil {
+						return "", err
+					}
+					keyFile, err := rs.certFilepath(req.TlsKeyFilepath)
+					if err != nil {
+						return "", err
+					}
+					loaded, err := tls.LoadX509KeyPair(certFile, keyFile)
+					if err != nil {
+						return "", fmt.Errorf("%w: %w", serde.InvalidParamsError, err)
+					}
+					cert = &loaded
+				}
+				port := ""
+				if req.Port != 0 {
+					port = strconv.FormatUint(uint64(req.Port), 10)
+				}
+				if err := rs.Rebind(port, cert); err != nil {
+					return "", err
+				}
+				rs.logger.Warn("rpc server rebound", "url", rs.Url(), "certificateReplaced", cert != nil)
+				return rs.Url(), nil
+			})
+		case serde.GetMessageFaultsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.MessageFaults, error) {
+				return rs.node.MessageFaults()
+			})
+		case serde.SetMessageFaultsMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.MessageFaults) (serde.MessageFaults, error) {
+				if err := req.Validate(); err != nil {
+					return serde.MessageFaults{}, serde.InvalidParamsError
+				}
+				if err := rs.node.SetMessageFaults(req); err != nil {
+					return serde.MessageFaults{}, err
+				}
+				rs.logger.Warn("message faults changed", "faults", req)
+				return rs.node.MessageFaults()
+			})
+		case serde.GetConfigMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.ConfigResponse, error) {
+				return rs.node.Config(), nil
+			})
+		case serde.SetConfigMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.SetConfigRequest) (serde.ConfigResponse, error) {
+				if err := serde.ValidateSetConfigRequest(req); err != nil {
+					return serde.ConfigResponse{}, err
+				}
+				return rs.node.SetConfig(req.Settings)
+			})
+		case serde.GetBalanceHistoryMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetBalanceHistoryRequest) (serde.GetBalanceHistoryResponse, error) {
+				if err := serde.ValidateGetBalanceHistoryRequest(req); err != nil {
+					return serde.GetBalanceHistoryResponse{}, err
+				}
+				return rs.node.GetBalanceHistory(req.Id, req.From, req.To)
+			})
+		case serde.ExportActivityMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.ExportActivityRequest) (serde.ExportActivityResponse, error) {
+				if err := serde.ValidateExportActivityRequest(req); err != nil {
+					return serde.ExportActivityResponse{}, err
+				}
+				data := &cappedBuilder{max: maxUnstreamedExportBytes}
+				if err := rs.node.ExportActivity(data, req.Format, req.From, req.To); err != nil {
+					return serde.ExportActivityResponse{}, err
+				}
+				return serde.ExportActivityResponse{Format: req.Format, Data: data.String()}, nil
+			})
+		case serde.StreamActivityExportMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.StreamActivityExportRequest) (serde.ExportActivityResponse, error) {
+				if err := serde.ValidateExportActivityRequest(serde.ExportActivityRequest{From: req.From, To: req.To, Format: req.Format}); err != nil {
+					return serde.ExportActivityResponse{}, err
+				}
+				stream, err := rs.newStream(req.Subscription, authToken(requestData))
+				if err != nil {
+					return serde.ExportActivityResponse{}, err
+				}
+				// The export is read while the node is held for the request, and sent once it is released, so that a
+				// client reading it slowly does not hold up a swap of the node
+				data := &bytes.Buffer{}
+				if err := rs.node.ExportActivity(data, req.Format, req.From, req.To); err != nil {
+					return serde.ExportActivityResponse{}, err
+				}
+				rs.streamActivityExport(stream, data.Bytes(), req.ChunkSize)
+				return serde.ExportActivityResponse{Format: req.Format, StreamId: stream.id}, nil
+			})
+		case serde.GetDebugBundleMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.NoPayloadRequest) (serde.DebugBundleResponse, error) {
+				data := &bytes.Buffer{}
+				if err := rs.node.WriteDebugBundle(data); err != nil {
+					return serde.DebugBundleResponse{}, err
+				}
+				return serde.DebugBundleResponse{Data: data.Bytes()}, nil
+			})
+		case serde.GetQuoteMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetQuoteRequest) (protocols.Quote, error) {
+				if err := serde.ValidateGetQuoteRequest(req); err != nil {
+					return protocols.Quote{}, err
+				}
+				return rs.node.GetQuote(req.Intermediary, req.CounterParty, req.Asset, req.Amount.ToInt())
+			})
+		case serde.ProbeCounterpartyMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.ProbeCounterpartyRequest) (protocols.Probe, error) {
+				if err := serde.ValidateProbeCounterpartyRequest(req); err != nil {
+					return protocols.Probe{}, err
+				}
+				return rs.node.ProbeCounterparty(ctx, req.Counterparty, req.Asset, req.Deposit.ToInt(), req.CounterDeposit.ToInt())
+			})
+		case serde.BlockPeerMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.BlockPeerRequest) (serde.GetBlockedPeersResponse, error) {
+				if err := serde.ValidateBlockPeerRequest(req); err != nil {
+					return serde.GetBlockedPeersResponse{}, err
+				}
+				if _, err := rs.node.BlockPeer(req.Address, req.PeerId, req.Reason); err != nil {
+					return serde.GetBlockedPeersResponse{}, err
+				}
+				return blockedPeers(rs.node.BlockedPeers()), nil
+			})
+		case serde.UnblockPeerMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.UnblockPeerRequest) (serde.GetBlockedPeersResponse, error) {
+				if err := serde.ValidateUnblockPeerRequest(req); err != nil {
+					return serde.GetBlockedPeersResponse{}, err
+				}
+				if _, err := rs.node.UnblockPeer(req.Address, req.PeerId); err != nil {
+					return serde.GetBlockedPeersResponse{}, err
+				}
+				return blockedPeers(rs.node.BlockedPeers()), nil
+			})
+		case serde.GetApiKeysMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.NoPayloadRequest) (serde.GetApiKeysResponse, error) {
+				return apiKeys(rs.auth.apiKeys()), nil
+			})
+		case serde.SetApiKeyMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.SetApiKeyRequest) (serde.GetApiKeysResponse, error) {
+				if err := serde.ValidateSetApiKeyRequest(req); err != nil {
+					return serde.GetApiKeysResponse{}, err
+				}
+				key := ApiKey{Name: req.Name, Secret: req.Secret, Scope: Scope(req.Scope), Methods: req.Methods}
+				if err := rs.auth.setApiKey(key); err != nil {
+					return serde.GetApiKeysResponse{}, fmt.Errorf("%w: %w", serde.InvalidParamsError, err)
+				}
+				rs.logger.Info("Set api key", "name", req.Name, "scope", req.Scope, "methods", req.Methods)
+				return apiKeys(rs.auth.apiKeys()), nil
+			})
+		case serde.RemoveApiKeyMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.RemoveApiKeyRequest) (serde.GetApiKeysResponse, error) {
+				if err := serde.ValidateRemoveApiKeyRequest(req); err != nil {
+					return serde.GetApiKeysResponse{}, err
+				}
+				if err := rs.auth.removeApiKey(req.Name); err != nil {
+					return serde.GetApiKeysResponse{}, fmt.Errorf("%w: %w", serde.InvalidParamsError, err)
+				}
+				rs.logger.Info("Removed api key", "name", req.Name)
+				return apiKeys(rs.auth.apiKeys()), nil
+			})
+		case serde.GetBlockedPeersMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.GetBlockedPeersResponse, error) {
+				return blockedPeers(rs.node.BlockedPeers()), nil
+			})
+		case serde.GetPeerStatsMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.GetPeerStatsResponse, error) {
+				return peerStats(rs.node.PeerStats()), nil
+			})
+		case serde.GetPaymentLatencyMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (serde.GetPaymentLatencyResponse, error) {
+				return paymentLatency(rs.node.PaymentLatencies()), nil
+			})
+		case serde.CloseAllChannelsMethod:
+			return processRequest(rs, permAdmin, requestData, func(req serde.CloseAllChannelsRequest) (query.CloseAllInfo, error) {
+				return rs.node.CloseAllChannels(nitro.CloseAllOptions{Timeout: req.Timeout, Challenge: req.Challenge})
+			})
+		case serde.GetCloseAllProgressMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.NoPayloadRequest) (query.CloseAllInfo, error) {
+				return rs.node.CloseAllProgress(), nil
+			})
+		case serde.CreateLedgerChannelsBatchMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.CreateLedgerChannelsBatchRequest) (query.LedgerChannelBatchInfo, error) {
+				if err := serde.ValidateCreateLedgerChannelsBatchRequest(req); err != nil {
+					return query.LedgerChannelBatchInfo{}, err
+				}
+				template := nitro.LedgerFundingTemplate{
+					Asset:             req.Asset,
+					MyDeposit:         req.MyDeposit.ToInt(),
+					TheirDeposit:      req.TheirDeposit.ToInt(),
+					ChallengeDuration: req.ChallengeDuration,
+					Tags:              req.Tags,
+				}
+				return rs.node.CreateLedgerChannels(req.Counterparties, template, nitro.BatchOptions{Concurrency: req.Concurrency, Timeout: req.Timeout, Probe: req.Probe})
+			})
+		case serde.GetLedgerChannelsBatchMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetLedgerChannelsBatchRequest) (query.LedgerChannelBatchInfo, error) {
+				return rs.node.LedgerChannelBatchProgress(req.Id)
+			})
+		case serde.CreateSubscriptionMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.CreateSubscriptionRequest) (query.SubscriptionInfo, error) {
+				if err := serde.ValidateCreateSubscriptionRequest(req); err != nil {
+					return query.SubscriptionInfo{}, err
+				}
+				return rs.node.CreateSubscription(ctx, req.Channel, req.Amount.ToInt(), req.Interval, req.Cap.ToInt())
+			})
+		case serde.GetSubscriptionMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetSubscriptionRequest) (query.SubscriptionInfo, error) {
+				return rs.node.GetSubscription(req.Id)
+			})
+		case serde.CancelSubscriptionMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.CancelSubscriptionRequest) (query.SubscriptionInfo, error) {
+				return rs.node.CancelSubscription(ctx, req.Id, req.Reason)
+			})
+		case serde.CreateEscrowMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.CreateEscrowRequest) (query.EscrowInfo, error) {
+				if err := serde.ValidateCreateEscrowRequest(req); err != nil {
+					return query.EscrowInfo{}, err
+				}
+				return rs.node.CreateEscrow(ctx, req.Channel, req.Amount.ToInt(), req.Commitment, req.Timeout)
+			})
+		case serde.GetEscrowMethod:
+			return processRequest(rs, permRead, requestData, func(req serde.GetEscrowRequest) (query.EscrowInfo, error) {
+				return rs.node.GetEscrow(req.Id)
+			})
+		case serde.SubmitEscrowResultMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.SubmitEscrowResultRequest) (query.EscrowInfo, error) {
+				return rs.node.SubmitEscrowResult(ctx, req.Id, req.Result)
+			})
+		case serde.DisputeEscrowMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.DisputeEscrowRequest) (query.EscrowInfo, error) {
+				return rs.node.DisputeEscrow(ctx, req.Id, req.Reason)
+			})
+		case serde.CreateObjectiveMethod:
+			return processRequest(rs, permSign, requestData, func(req serde.CreateObjectiveRequest) (protocols.ObjectiveId, error) {
+				t, ok := protocols.ObjectiveTypeByPrefix(req.Type)
+				if !ok || t.DecodeRequest == nil {
+					return "", fmt.Errorf("%w: %s cannot be requested over rpc", nitro.ErrUnknownObjectiveType, req.Type)
+				}
+				objReq, err := t.DecodeRequest(req.Request)
+				if err != nil {
+					return "", serde.InvalidParamsError
+				}
+				return rs.node.CreateObjective(ctx, objReq)
+			})
 		default:
 			errRes := serde.NewJsonRpcErrorResponse(jsonrpcReq.Id, serde.MethodNotFoundError)
 			return marshalResponse(errRes)
 		}
 	}
+}
+
+// logLevels returns the current log level of each module
+func logLevels() serde.LogLevelsResponse {
+	levels := serde.LogLevelsResponse{}
+	for module, level := range logging.Levels() {
+		levels[module] = level.String()
+	}
+	return levels
+}
+
+// apiKeys lists the api keys, without their secrets
+func apiKeys(keys []ApiKey) serde.GetApiKeysResponse {
+	response := serde.GetApiKeysResponse{}
+	for _, key := range keys {
+		response = append(response, serde.ApiKeyInfo{Name: key.Name, Scope: string(key.Scope), Methods: key.Methods})
+	}
+	return response
+}
+
+// authToken returns the auth token of the request, if it has one
+func authToken(requestData []byte) string {
+	var request struct {
+		Params struct {
+			AuthToken string `json:"authtoken"`
+		} `json:"params"`
+	}
+	_ = json.Unmarshal(requestData, &request)
+	return request.Params.AuthToken
+}
+
+// blockedPeers lists the blocked peers, in the order they were blocked
+func blockedPeers(peers []store.BlockedPeer) serde.GetBlockedPeersResponse {
+	response := serde.GetBlockedPeersResponse{}
+	for _, bp := range peers {
+		response = append(response, serde.BlockedPeerInfo{Address: bp.Address, PeerId: bp.PeerId, Reason: bp.Reason, Blocked: bp.Blocked})
+	}
+	return response
+}
+
+// peerStats lists the stats of each peer, healthiest first
+func peerStats(stats map[types.Address]engine.PeerStats) serde.GetPeerStatsResponse {
+	peers := make([]types.Address, 0, len(stats))
+	for peer := range stats {
+		peers = append(peers, peer)
+	}
+	slices.SortFunc(peers, func(a, b types.Address) int {
+		switch {
+		case stats[a].Healthier(stats[b]):
+			return -1
+		case stats[b].Healthier(stats[a]):
+			return 1
+		default:
+			return bytes.Compare(a[:], b[:])
+		}
+	})
+
+	response := serde.GetPeerStatsResponse{}
+	for _, peer := range peers {
+		s := stats[peer]
+		response = append(response, serde.PeerStatsInfo{
+			Peer:        peer,
+			RoundTrip:   s.RoundTrip,
+			Succeeded:   s.Succeeded,
+			Failed:      s.Failed,
+			SuccessRate: s.SuccessRate(),
+		})
+	}
+	return response
+}
 
-	err = rs.transport.RegisterRequestHandler("v1", handlerV1)
-	return err
+// paymentLatency lists the latency of each stage of payments, ordered by stage
+func paymentLatency(latencies map[engine.PaymentStage]engine.LatencyPercentiles) serde.GetPaymentLatencyResponse {
+	response := serde.GetPaymentLatencyResponse{}
+	for stage, l := range latencies {
+		response = append(response, serde.PaymentLatencyInfo{Stage: string(stage), Count: l.Count, P50: l.P50, P99: l.P99})
+	}
+	slices.SortFunc(response, func(a, b serde.PaymentLatencyInfo) int { return strings.Compare(a.Stage, b.Stage) })
+	return response
 }
 
 func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServer, permission permission, requestData []byte, processPayload func(T) (U, error)) []byte {
@@ -204,8 +901,14 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.ParamsUnmarshalError)
 		return marshalResponse(response)
 	}
+	if rs.strictParams {
+		if err := serde.CheckFieldNames(requestData, &rpcRequest); err != nil {
+			response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, toJsonRpcError(fmt.Errorf("%w: %w", serde.InvalidParamsError, err)))
+			return marshalResponse(response)
+		}
+	}
 
-	err = checkTokenValidity(rpcRequest.Params.AuthToken, permission, 7*24*time.Hour)
+	err = rs.auth.authorize(rpcRequest.Params.AuthToken, permission)
 	if err != nil {
 		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, serde.InvalidAuthTokenError)
 		rs.logger.Warn(serde.InvalidAuthTokenError.Message)
@@ -215,14 +918,7 @@ func processRequest[T serde.RequestPayload, U serde.ResponsePayload](rs *RpcServ
 	payload := rpcRequest.Params.Payload
 	processedResponse, err := processPayload(payload)
 	if err != nil {
-		responseErr := serde.InternalServerError // default error
-		responseErr.Message = err.Error()
-
-		if jsonErr, ok := err.(serde.JsonRpcError); ok {
-			responseErr.Code = jsonErr.Code // overwrite default if error object is jsonrpc error
-		}
-
-		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, responseErr)
+		response := serde.NewJsonRpcErrorResponse(rpcRequest.Id, toJsonRpcError(err))
 		return marshalResponse(response)
 	}
 
@@ -239,47 +935,60 @@ func marshalResponse(response any) []byte {
 	return responseData
 }
 
-func validateJsonrpcRequest(requestData []byte) (serde.JsonRpcGeneralRequest, []byte) {
-	var request map[string]interface{}
+// requestIdOf returns the id of the request, or a null id if it cannot be parsed.
+func requestIdOf(requestData []byte) serde.RequestId {
+	var request struct {
+		Id serde.RequestId `json:"id"`
+	}
+	if err := json.Unmarshal(requestData, &request); err != nil {
+		return serde.RequestId{}
+	}
+	return request.Id
+}
+
+// validateJsonrpcRequest parses the request, returning it and whether it is a notification, to which no response is
+// sent, or the error response to send if it is not a valid json-rpc request.
+func validateJsonrpcRequest(requestData []byte) (serde.JsonRpcGeneralRequest, bool, []byte) {
+	var request map[string]json.RawMessage
 	vr := serde.JsonRpcGeneralRequest{}
 	err := json.Unmarshal(requestData, &request)
 	if err != nil {
-		errRes := serde.NewJsonRpcErrorResponse(0, serde.RequestUnmarshalError)
-		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+		errRes := serde.NewJsonRpcErrorResponse(serde.RequestId{}, serde.RequestUnmarshalError)
+		return serde.JsonRpcGeneralRequest{}, false, marshalResponse(errRes)
 	}
 
-	// jsonrpc spec says id can be a string, number.
-	// We only support numbers: https://github.com/statechannels/go-nitro/issues/1160
-	// When golang unmarshals JSON into an interface value, float64 is used for numbers.
-	requestId := request["id"]
-	fRequestId, ok := requestId.(float64)
-	if !ok || fRequestId != float64(uint64(fRequestId)) {
-		errRes := serde.NewJsonRpcErrorResponse(0, serde.InvalidRequestError)
-		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+	// The jsonrpc spec allows the id to be a string, a number or null. A request without an id is a notification.
+	rawId, hasId := request["id"]
+	if hasId {
+		if err := json.Unmarshal(rawId, &vr.Id); err != nil {
+			errRes := serde.NewJsonRpcErrorResponse(serde.RequestId{}, serde.InvalidRequestError)
+			return serde.JsonRpcGeneralRequest{}, false, marshalResponse(errRes)
+		}
 	}
-	vr.Id = uint64(fRequestId)
 
-	sJsonrpc, ok := request["jsonrpc"].(string)
-	if !ok || sJsonrpc != "2.0" {
+	var sJsonrpc string
+	if err := json.Unmarshal(request["jsonrpc"], &sJsonrpc); err != nil || sJsonrpc != "2.0" {
 		errRes := serde.NewJsonRpcErrorResponse(vr.Id, serde.InvalidRequestError)
-		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+		return serde.JsonRpcGeneralRequest{}, false, marshalResponse(errRes)
 	}
 
-	sMethod, ok := request["method"].(string)
-	if !ok {
+	if err := json.Unmarshal(request["method"], &vr.Method); err != nil {
 		errRes := serde.NewJsonRpcErrorResponse(vr.Id, serde.InvalidRequestError)
-		return serde.JsonRpcGeneralRequest{}, marshalResponse(errRes)
+		return serde.JsonRpcGeneralRequest{}, false, marshalResponse(errRes)
 	}
-	vr.Method = sMethod
 
-	vr.Params = request["params"]
-	return vr, nil
+	if params, ok := request["params"]; ok {
+		_ = json.Unmarshal(params, &vr.Params)
+	}
+	return vr, !hasId, nil
 }
 
 func (rs *RpcServer) sendNotifications(ctx context.Context,
 	completedObjChan <-chan protocols.ObjectiveId,
 	ledgerUpdatesChan <-chan query.LedgerChannelInfo,
 	paymentUpdatesChan <-chan query.PaymentChannelInfo,
+	voucherUpdatesChan <-chan payments.Voucher,
+	capacityEventsChan <-chan query.LedgerCapacityInfo,
 ) {
 	defer rs.wg.Done()
 	for {
@@ -314,14 +1023,141 @@ func (rs *RpcServer) sendNotifications(ctx context.Context,
 			if err != nil {
 				panic(err)
 			}
+		case voucher, ok := <-voucherUpdatesChan:
+			if !ok {
+				rs.logger.Warn("VoucherUpdates channel closed, exiting sendNotifications")
+				return
+			}
+			err := sendNotification(rs, serde.VoucherReceived, voucher)
+			if err != nil {
+				panic(err)
+			}
+		case capacityInfo, ok := <-capacityEventsChan:
+			if !ok {
+				rs.logger.Warn("CapacityEvents channel closed, exiting sendNotifications")
+				return
+			}
+			err := sendNotification(rs, serde.LedgerCapacityCrossed, capacityInfo)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+// streamLedgerChannels sends the ledger channels, in pages of at most pageSize, to the subscription of the stream. The
+// pages are sent after the response is returned, so that clients need not read the pages before they have the
+// response.
+func (rs *RpcServer) streamLedgerChannels(stream stream, ledgers []query.LedgerChannelInfo, pageSize uint64) serde.StreamResponse {
+	if pageSize == 0 {
+		pageSize = serde.DefaultStreamPageSize
+	}
+	total := uint64(len(ledgers))
+	pages := (total + pageSize - 1) / pageSize
+
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		for page := uint64(0); page < pages; page++ {
+			end := min((page+1)*pageSize, total)
+			info := serde.LedgerChannelsPageInfo{StreamId: stream.id, Page: page, Pages: pages, Channels: ledgers[page*pageSize : end]}
+			if err := sendStreamNotification(rs, stream, serde.LedgerChannelsPage, info); err != nil {
+				rs.logger.Error("Could not stream ledger channels", "streamId", stream.id, "error", err)
+				return
+			}
 		}
+	}()
+	return serde.StreamResponse{StreamId: stream.id, Pages: pages, Total: total}
+}
+
+// streamActivityExport sends the activity export, in chunks of at most size bytes (or serde.DefaultExportChunkSize, if
+// zero), to the subscription of the stream.
+func (rs *RpcServer) streamActivityExport(stream stream, export []byte, size uint64) {
+	if size == 0 {
+		size = serde.DefaultExportChunkSize
+	}
+	rs.wg.Add(1)
+	go func() {
+		defer rs.wg.Done()
+		chunks := exportChunks(export, int(size))
+		for i, data := range chunks {
+			chunk := serde.ActivityExportChunkInfo{StreamId: stream.id, Chunk: uint64(i), Data: string(data), Last: i == len(chunks)-1}
+			if err := sendStreamNotification(rs, stream, serde.ActivityExportChunk, chunk); err != nil {
+				rs.logger.Error("Could not stream activity export", "streamId", stream.id, "error", err)
+				return
+			}
+		}
+	}()
+}
+
+// exportChunks splits the export into chunks of size bytes, the last of which may be shorter. An empty export is a
+// single empty chunk, so that the stream still ends with a last chunk.
+func exportChunks(export []byte, size int) [][]byte {
+	chunks := [][]byte{}
+	for len(export) > size {
+		chunks = append(chunks, export[:size])
+		export = export[size:]
+	}
+	return append(chunks, export)
+}
+
+// stream is the destination of the notifications of a streamed response: the subscription of the client requesting
+// it, which only that client may receive.
+type stream struct {
+	// id is generated by the server, and carried by each notification of the stream
+	id           string
+	notifier     transport.SubscriptionNotifier
+	subscription string
+	token        string
+}
+
+// newStream returns a stream, with a new id, to the subscription of the client presenting token. It returns an error if
+// the transport cannot notify a single subscription, or the subscription was not opened with the token.
+func (rs *RpcServer) newStream(subscription string, token string) (stream, error) {
+	if !rs.notifications {
+		return stream{}, fmt.Errorf("streaming requires notifications")
+	}
+	notifier, ok := rs.transport.(transport.SubscriptionNotifier)
+	if !ok {
+		return stream{}, fmt.Errorf("streaming requires a transport which notifies a single subscription")
+	}
+	if !notifier.Subscribed(subscription, token) {
+		return stream{}, fmt.Errorf("%w: no subscription %q was opened with the auth token of the request", serde.InvalidParamsError, subscription)
+	}
+	return stream{id: newRandomId(), notifier: notifier, subscription: subscription, token: token}, nil
+}
+
+// maxUnstreamedExportBytes is the largest activity export returned in a single response. Larger exports must be
+// streamed, with stream_activity_export.
+const maxUnstreamedExportBytes = 4 * 1024 * 1024
+
+// cappedBuilder is a strings.Builder which refuses to grow beyond max bytes.
+type cappedBuilder struct {
+	strings.Builder
+	max int
+}
+
+func (cb *cappedBuilder) Write(p []byte) (int, error) {
+	if cb.Len()+len(p) > cb.max {
+		return 0, fmt.Errorf("%w: the export exceeds %d bytes, so must be streamed with %s", serde.InvalidParamsError, cb.max, serde.StreamActivityExportMethod)
+	}
+	return cb.Builder.Write(p)
+}
+
+// sendStreamNotification sends the notification to the subscription of the stream alone
+func sendStreamNotification[T serde.NotificationMethod, U serde.NotificationPayload](rs *RpcServer, stream stream, method T, payload U) error {
+	request := serde.NewJsonRpcSpecificRequest(serde.NumberId(rand.Uint64()), method, payload, "")
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
 	}
+	return stream.notifier.NotifySubscription(stream.subscription, stream.token, data)
 }
 
 func sendNotification[T serde.NotificationMethod, U serde.NotificationPayload](rs *RpcServer, method T, payload U) error {
 	rs.logger.Debug("Sending notification", "method", method, "payload", payload)
 
-	request := serde.NewJsonRpcSpecificRequest(rand.Uint64(), method, payload, "")
+	request := serde.NewJsonRpcSpecificRequest(serde.NumberId(rand.Uint64()), method, payload, "")
 	data, err := json.Marshal(request)
 	if err != nil {
 		return err
@@ -2,16 +2,24 @@ package rpc
 
 import (
 	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/statechannels/go-nitro/channel/state"
 	nitro "github.com/statechannels/go-nitro/node"
+	"github.com/statechannels/go-nitro/payments"
 	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport/http"
 	"github.com/statechannels/go-nitro/types"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockResponder struct {
-	Handler func([]byte) []byte
+	Handler  func([]byte) []byte            // the handler registered last
+	Handlers map[string]func([]byte) []byte // the handlers registered, keyed by api version
 }
 
 func (*mockResponder) Close() error {
@@ -24,6 +32,10 @@ func (*mockResponder) Url() string {
 
 func (m *mockResponder) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
 	m.Handler = handler
+	if m.Handlers == nil {
+		m.Handlers = map[string]func([]byte) []byte{}
+	}
+	m.Handlers[apiVersion] = handler
 	return nil
 }
 
@@ -53,7 +65,7 @@ func sendRequestAndExpectError(t *testing.T, request []byte, expectedError serde
 }
 
 func getAuthToken(t *testing.T) string {
-	request := serde.JsonRpcSpecificRequest[serde.NoPayloadRequest]{Jsonrpc: "2.0", Id: 1, Method: "get_auth_token"}
+	request := serde.JsonRpcSpecificRequest[serde.NoPayloadRequest]{Jsonrpc: "2.0", Id: serde.NumberId(1), Method: "get_auth_token"}
 	jsonRequest, err := json.Marshal(request)
 	if err != nil {
 		t.Error(err)
@@ -78,6 +90,264 @@ func getAuthToken(t *testing.T) string {
 	return jsonResponse.Result
 }
 
+func TestRpcApiKeys(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, WithAuth(AuthConfig{
+		ApiKeys:     []ApiKey{{Name: "dashboard", Secret: "read-secret", Scope: ReadScope}},
+		TokenSecret: []byte("token-secret"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method serde.RequestMethod, authToken string, payload any) []byte {
+		t.Helper()
+		request := map[string]any{
+			"jsonrpc": "2.0", "id": 1, "method": method, "params": map[string]any{"authtoken": authToken, "payload": payload},
+		}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handler(jsonRequest)
+	}
+	expectError := func(response []byte, expected serde.JsonRpcError) {
+		t.Helper()
+		jsonResponse := serde.JsonRpcErrorResponse{}
+		if err := json.Unmarshal(response, &jsonResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected, jsonResponse.Error)
+	}
+
+	// A token is only issued for a valid api key
+	expectError(send(serde.GetAuthTokenMethod, "", serde.AuthRequest{}), serde.InvalidAuthTokenError)
+	expectError(send(serde.GetAuthTokenMethod, "", serde.AuthRequest{ApiKey: "guess"}), serde.InvalidAuthTokenError)
+
+	tokenResponse := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(send(serde.GetAuthTokenMethod, "", serde.AuthRequest{ApiKey: "read-secret"}), &tokenResponse); err != nil {
+		t.Fatal(err)
+	}
+	readToken := tokenResponse.Result
+
+	// The token of a read scoped key permits queries, which here fail only for their missing params, but not payments
+	expectError(send(serde.GetPaymentChannelRequestMethod, readToken, serde.GetPaymentChannelRequest{}), serde.InvalidParamsError)
+	expectError(send(serde.PayRequestMethod, readToken, serde.PaymentRequest{Amount: serde.NewQuantity(1)}), serde.InvalidAuthTokenError)
+
+	// Tokens issued by servers without api keys are refused
+	expectError(send(serde.GetPaymentChannelRequestMethod, getAuthToken(t), serde.GetPaymentChannelRequest{}), serde.InvalidAuthTokenError)
+}
+
+func TestRpcSubscriptionsRequireToken(t *testing.T) {
+	server, err := http.NewHttpTransportAsServer("4321", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	_, err = newRpcServerWithoutNotifications(&nitro.Node{}, server, WithAuth(AuthConfig{
+		ApiKeys:     []ApiKey{{Name: "dashboard", Secret: "read-secret", Scope: ReadScope}},
+		TokenSecret: []byte("token-secret"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscribe := func(token string) error {
+		t.Helper()
+		client, err := http.NewHttpTransportAsClient("http://"+server.Url(), time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+		_, err = client.SubscribeWithToken(token)
+		return err
+	}
+
+	if err := subscribe(""); err == nil {
+		t.Error("expected a subscriber without a token to be refused")
+	}
+	if err := subscribe("guess"); err == nil {
+		t.Error("expected a subscriber with an invalid token to be refused")
+	}
+	if err := subscribe(getAuthToken(t)); err == nil {
+		t.Error("expected a subscriber with the token of another server to be refused")
+	}
+	if err := subscribe("read-secret"); err != nil {
+		t.Errorf("expected a subscriber with a read scoped api key to be admitted: %v", err)
+	}
+}
+
+func TestRpcStreamsRequireTheirSubscription(t *testing.T) {
+	server, err := http.NewHttpTransportAsServer("4322", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	rs, err := newRpcServerWithoutNotifications(&nitro.Node{}, server, WithAuth(AuthConfig{
+		ApiKeys: []ApiKey{
+			{Name: "dashboard", Secret: "dashboard-secret", Scope: ReadScope},
+			{Name: "billing", Secret: "billing-secret", Scope: ReadScope},
+		},
+		TokenSecret: []byte("token-secret"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rs.notifications = true
+
+	client, err := http.NewHttpTransportAsClient("http://"+server.Url(), time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	notifications, err := client.SubscribeWithToken("dashboard-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	subscription := client.SubscriptionId()
+
+	// A stream may only be sent to a subscription opened with the token of the request
+	if _, err := rs.newStream(subscription, "billing-secret"); err == nil {
+		t.Error("expected a stream to the subscription of another client to be refused")
+	}
+	if _, err := rs.newStream("guess", "dashboard-secret"); err == nil {
+		t.Error("expected a stream to an unknown subscription to be refused")
+	}
+	stream, err := rs.newStream(subscription, "dashboard-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := rs.newStream(subscription, "dashboard-secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stream.id == "" || stream.id == other.id {
+		t.Errorf("expected each stream to have its own id, got %q and %q", stream.id, other.id)
+	}
+
+	chunk := serde.ActivityExportChunkInfo{StreamId: stream.id, Data: "a", Last: true}
+	if err := sendStreamNotification(rs, stream, serde.ActivityExportChunk, chunk); err != nil {
+		t.Fatal(err)
+	}
+	received := serde.JsonRpcSpecificRequest[serde.ActivityExportChunkInfo]{}
+	if err := json.Unmarshal(<-notifications, &received); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, chunk, received.Params.Payload)
+}
+
+func TestRpcAdminMethods(t *testing.T) {
+	mockResponder := &mockResponder{}
+	rs, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, WithAuth(AuthConfig{
+		ApiKeys: []ApiKey{
+			{Name: "wallet", Secret: "sign-secret", Scope: SignScope},
+			{Name: "operator", Secret: "admin-secret", Scope: AdminScope},
+		},
+		TokenSecret: []byte("token-secret"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Endpoints sharing the server's auth, such as the debug endpoint, are only open to admins
+	if err := rs.AuthorizeAdmin("sign-secret"); err == nil {
+		t.Error("expected a sign scoped key not to be authorized as an admin")
+	}
+	if err := rs.AuthorizeAdmin("admin-secret"); err != nil {
+		t.Error(err)
+	}
+
+	// The methods which operate the node are refused to a sign scoped key before dispatch
+	for _, method := range []serde.RequestMethod{
+		serde.SetLogLevelMethod,
+		serde.SetConfigMethod,
+		serde.SetMessageFaultsMethod,
+		serde.GetDebugBundleMethod,
+		serde.RebindRpcServerMethod,
+		serde.BlockPeerMethod,
+		serde.UnblockPeerMethod,
+		serde.CloseAllChannelsMethod,
+	} {
+		request := map[string]any{
+			"jsonrpc": "2.0", "id": 1, "method": method, "params": map[string]any{"authtoken": "sign-secret", "payload": map[string]any{}},
+		}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		jsonResponse := serde.JsonRpcErrorResponse{}
+		if err := json.Unmarshal(mockResponder.Handler(jsonRequest), &jsonResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, serde.InvalidAuthTokenError, jsonResponse.Error, method)
+	}
+}
+
+func TestRpcApiKeyMethods(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, WithAuth(AuthConfig{
+		ApiKeys:     []ApiKey{{Name: "operator", Secret: "admin-secret", Scope: AdminScope}},
+		TokenSecret: []byte("token-secret"),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(method serde.RequestMethod, authToken string, payload any) []byte {
+		t.Helper()
+		request := map[string]any{
+			"jsonrpc": "2.0", "id": 1, "method": method, "params": map[string]any{"authtoken": authToken, "payload": payload},
+		}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handlers[serde.ApiV2](jsonRequest)
+	}
+	expectError := func(response []byte, expected serde.JsonRpcError) {
+		t.Helper()
+		jsonResponse := serde.JsonRpcErrorResponse{}
+		if err := json.Unmarshal(response, &jsonResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, expected.Code, jsonResponse.Error.Code)
+	}
+	expectKeys := func(response []byte, expected ...string) {
+		t.Helper()
+		keysResponse := serde.JsonRpcSuccessResponse[serde.GetApiKeysResponse]{}
+		if err := json.Unmarshal(response, &keysResponse); err != nil {
+			t.Fatal(err)
+		}
+		names := []string{}
+		for _, key := range keysResponse.Result {
+			names = append(names, key.Name)
+		}
+		assert.Equal(t, expected, names)
+	}
+
+	// An admin adds a billing service's key, which permits only the methods it needs
+	billing := serde.SetApiKeyRequest{Name: "billing", Secret: "billing-secret", Scope: "read", Methods: []string{"receive_voucher", "get_payment_channel"}}
+	expectKeys(send(serde.SetApiKeyMethod, "admin-secret", billing), "operator", "billing")
+	expectKeys(send(serde.GetApiKeysMethod, "admin-secret", serde.NoPayloadRequest{}), "operator", "billing")
+
+	tokenResponse := serde.JsonRpcSuccessResponse[string]{}
+	if err := json.Unmarshal(send(serde.GetAuthTokenMethod, "", serde.AuthRequest{ApiKey: "billing-secret"}), &tokenResponse); err != nil {
+		t.Fatal(err)
+	}
+	billingToken := tokenResponse.Result
+
+	// Its methods fail here only for their missing params, while others its scope permits are refused before dispatch
+	expectError(send(serde.GetPaymentChannelRequestMethod, billingToken, serde.GetPaymentChannelRequest{}), serde.InvalidParamsError)
+	expectError(send(serde.GetLedgerChannelRequestMethod, billingToken, serde.GetLedgerChannelRequest{}), serde.InvalidAuthTokenError)
+	expectError(send(serde.GetApiKeysMethod, billingToken, serde.NoPayloadRequest{}), serde.InvalidAuthTokenError)
+
+	// Only admins manage api keys
+	expectError(send(serde.SetApiKeyMethod, "billing-secret", billing), serde.InvalidAuthTokenError)
+	expectError(send(serde.SetApiKeyMethod, "admin-secret", serde.SetApiKeyRequest{Name: "x", Secret: "y", Scope: "root"}), serde.InvalidParamsError)
+
+	// Removing the key revokes its token
+	expectKeys(send(serde.RemoveApiKeyMethod, "admin-secret", serde.RemoveApiKeyRequest{Name: "billing"}), "operator")
+	expectError(send(serde.GetPaymentChannelRequestMethod, billingToken, serde.GetPaymentChannelRequest{}), serde.InvalidAuthTokenError)
+	expectError(send(serde.RemoveApiKeyMethod, "admin-secret", serde.RemoveApiKeyRequest{Name: "operator"}), serde.InvalidParamsError)
+}
+
 func TestRpcParseError(t *testing.T) {
 	request := []byte{}
 	sendRequestAndExpectError(t, request, serde.ParseError)
@@ -97,7 +367,7 @@ func TestRpcMissingRequiredFields(t *testing.T) {
 }
 
 func TestRpcWrongVersion(t *testing.T) {
-	request := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{Jsonrpc: "1.0", Id: 2, Method: "direct_fund"}
+	request := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{Jsonrpc: "1.0", Id: serde.NumberId(2), Method: "direct_fund"}
 	jsonRequest, err := json.Marshal(request)
 	if err != nil {
 		t.Error(err)
@@ -135,7 +405,7 @@ func TestRpcMissingMethod(t *testing.T) {
 }
 
 func TestRpcMethodNotFound(t *testing.T) {
-	request := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{Jsonrpc: "2.0", Id: 2, Method: "fake_method"}
+	request := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{Jsonrpc: "2.0", Id: serde.NumberId(2), Method: "fake_method"}
 	jsonRequest, err := json.Marshal(request)
 	if err != nil {
 		t.Error(err)
@@ -147,7 +417,7 @@ func TestRpcMethodNotFound(t *testing.T) {
 func TestRpcGetPaymentChannelMissingParam(t *testing.T) {
 	authToken := getAuthToken(t)
 	request := serde.JsonRpcSpecificRequest[serde.GetPaymentChannelRequest]{
-		Jsonrpc: "2.0", Id: 2, Method: "get_payment_channel", Params: serde.Params[serde.GetPaymentChannelRequest]{AuthToken: authToken},
+		Jsonrpc: "2.0", Id: serde.NumberId(2), Method: "get_payment_channel", Params: serde.Params[serde.GetPaymentChannelRequest]{AuthToken: authToken},
 	}
 	jsonRequest, err := json.Marshal(request)
 	if err != nil {
@@ -161,13 +431,13 @@ func TestRpcPayInvalidParam(t *testing.T) {
 	authToken := getAuthToken(t)
 
 	paymentRequest := serde.PaymentRequest{
-		Amount:  100,
+		Amount:  serde.NewQuantity(100),
 		Channel: types.Destination{},
 	}
 
 	request := serde.JsonRpcSpecificRequest[serde.PaymentRequest]{
 		Jsonrpc: "2.0",
-		Id:      2,
+		Id:      serde.NumberId(2),
 		Method:  "pay",
 		Params:  serde.Params[serde.PaymentRequest]{AuthToken: authToken, Payload: paymentRequest},
 	}
@@ -179,3 +449,281 @@ func TestRpcPayInvalidParam(t *testing.T) {
 	expectedError := serde.InvalidParamsError
 	sendRequestAndExpectError(t, jsonRequest, expectedError)
 }
+
+func TestRpcRequestLimits(t *testing.T) {
+	expectedError := func(reason string) serde.JsonRpcError {
+		err := serde.RequestLimitsError
+		err.Data = reason
+		return err
+	}
+
+	tooLarge := []byte(`{"jsonrpc":"2.0","id":1,"method":"version","params":"` + strings.Repeat("a", serde.MaxRequestBytes) + `"}`)
+	sendRequestAndExpectError(t, tooLarge, expectedError(fmt.Sprintf("request exceeds %d bytes", serde.MaxRequestBytes)))
+
+	tooDeep := []byte(`{"jsonrpc":"2.0","id":1,"method":"version","params":` + strings.Repeat("[", serde.MaxRequestDepth) + strings.Repeat("]", serde.MaxRequestDepth) + `}`)
+	sendRequestAndExpectError(t, tooDeep, expectedError(fmt.Sprintf("request nesting exceeds a depth of %d", serde.MaxRequestDepth)))
+
+	tooLong := []byte(`{"jsonrpc":"2.0","id":1,"method":"version","params":"` + strings.Repeat("a", serde.MaxRequestStrings+1) + `"}`)
+	sendRequestAndExpectError(t, tooLong, expectedError(fmt.Sprintf("request contains a string exceeding %d bytes", serde.MaxRequestStrings)))
+
+	// The error carries the id of the request
+	mockResponder := &mockResponder{}
+	if _, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder); err != nil {
+		t.Fatal(err)
+	}
+	response := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(mockResponder.Handler(tooDeep), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.NumberId(1), response.Id)
+}
+
+func TestRpcNodeErrors(t *testing.T) {
+	wrapped := fmt.Errorf("paying 10 with 5 remaining: %w", nitro.ErrInsufficientFunds)
+
+	rpcErr := toJsonRpcError(wrapped)
+	assert.Equal(t, serde.InsufficientFundsError.Code, rpcErr.Code)
+	assert.Equal(t, wrapped.Error(), rpcErr.Message)
+
+	clientErr := fromJsonRpcError(rpcErr)
+	assert.ErrorIs(t, clientErr, nitro.ErrInsufficientFunds)
+	assert.Equal(t, wrapped.Error(), clientErr.Error())
+	var asJsonRpcErr serde.JsonRpcError
+	assert.ErrorAs(t, clientErr, &asJsonRpcErr)
+
+	unknown := toJsonRpcError(fmt.Errorf("something else"))
+	assert.Equal(t, serde.InternalServerError.Code, unknown.Code)
+	assert.NotErrorIs(t, fromJsonRpcError(unknown), nitro.ErrChannelNotFound)
+}
+
+func TestRpcComputeChannelId(t *testing.T) {
+	fixedPart := state.FixedPart{
+		Participants:      []types.Address{{1}, {2}},
+		ChannelNonce:      37,
+		AppDefinition:     types.Address{3},
+		ChallengeDuration: 60,
+	}
+	payload := serde.ComputeChannelIdRequest{
+		Participants:      fixedPart.Participants,
+		ChannelNonce:      fixedPart.ChannelNonce,
+		AppDefinition:     fixedPart.AppDefinition,
+		ChallengeDuration: fixedPart.ChallengeDuration,
+	}
+	request := serde.JsonRpcSpecificRequest[serde.ComputeChannelIdRequest]{
+		Jsonrpc: "2.0",
+		Id:      serde.NumberId(2),
+		Method:  "compute_channel_id",
+		Params:  serde.Params[serde.ComputeChannelIdRequest]{Payload: payload},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockResponder := &mockResponder{}
+	_, err = newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jsonResponse := serde.JsonRpcSuccessResponse[types.Destination]{}
+	err = json.Unmarshal(mockResponder.Handler(jsonRequest), &jsonResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, fixedPart.ChannelId(), jsonResponse.Result)
+
+	request.Params.Payload.Participants = []types.Address{{1}}
+	jsonRequest, err = json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sendRequestAndExpectError(t, jsonRequest, serde.InvalidParamsError)
+}
+
+func TestRpcStrictParams(t *testing.T) {
+	fixedPart := state.FixedPart{Participants: []types.Address{{1}, {2}}, ChannelNonce: 37, AppDefinition: types.Address{3}, ChallengeDuration: 60}
+	send := func(strict bool, payload map[string]any) []byte {
+		t.Helper()
+		mockResponder := &mockResponder{}
+		opts := []ServerOption{}
+		if strict {
+			opts = append(opts, WithStrictParams())
+		}
+		if _, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder, opts...); err != nil {
+			t.Fatal(err)
+		}
+		request := map[string]any{"jsonrpc": "2.0", "id": 1, "method": serde.ComputeChannelIdMethod, "params": map[string]any{"payload": payload}}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handler(jsonRequest)
+	}
+	expectChannelId := func(response []byte) {
+		t.Helper()
+		jsonResponse := serde.JsonRpcSuccessResponse[types.Destination]{}
+		if err := json.Unmarshal(response, &jsonResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, fixedPart.ChannelId(), jsonResponse.Result)
+	}
+	expectInvalidParams := func(response []byte, member string) {
+		t.Helper()
+		jsonResponse := serde.JsonRpcErrorResponse{}
+		if err := json.Unmarshal(response, &jsonResponse); err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, serde.InvalidParamsError.Code, jsonResponse.Error.Code)
+		assert.Contains(t, jsonResponse.Error.Message, member)
+	}
+
+	exact := map[string]any{"Participants": fixedPart.Participants, "ChannelNonce": 37, "AppDefinition": fixedPart.AppDefinition, "ChallengeDuration": 60}
+	misnamed := map[string]any{"Participants": fixedPart.Participants, "channelNonce": 37, "AppDefinition": fixedPart.AppDefinition, "ChallengeDuration": 60}
+	unknown := map[string]any{"Participants": fixedPart.Participants, "ChannelNonce": 37, "AppDefinition": fixedPart.AppDefinition, "ChallengeDuration": 60, "Memo": "x"}
+
+	// By default, members are matched case-insensitively and unknown members are ignored
+	for _, payload := range []map[string]any{exact, misnamed, unknown} {
+		expectChannelId(send(false, payload))
+	}
+
+	expectChannelId(send(true, exact))
+	expectInvalidParams(send(true, misnamed), `"params.payload.channelNonce", did you mean "ChannelNonce"`)
+	expectInvalidParams(send(true, unknown), `"params.payload.Memo"`)
+}
+
+func TestRpcApiVersions(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(apiVersion string, method serde.RequestMethod, payload any) []byte {
+		request := serde.JsonRpcGeneralRequest{Jsonrpc: "2.0", Id: serde.NumberId(1), Method: string(method), Params: map[string]any{"payload": payload}}
+		jsonRequest, err := json.Marshal(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mockResponder.Handlers[apiVersion](jsonRequest)
+	}
+
+	// The server selects the latest version it shares with the client, whichever version the client asks
+	for _, apiVersion := range serde.SupportedApiVersions {
+		negotiated := serde.JsonRpcSuccessResponse[string]{}
+		err = json.Unmarshal(send(apiVersion, serde.NegotiateVersionMethod, serde.NegotiateVersionRequest{Versions: []string{"v1", "v2", "v9"}}), &negotiated)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, serde.ApiV2, negotiated.Result)
+	}
+	unsupported := serde.JsonRpcErrorResponse{}
+	err = json.Unmarshal(send(serde.ApiV1, serde.NegotiateVersionMethod, serde.NegotiateVersionRequest{Versions: []string{"v9"}}), &unsupported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.UnsupportedVersionError, unsupported.Error)
+
+	// v1 keeps responding to version with the node's version alone
+	v1Version := serde.JsonRpcSuccessResponse[string]{}
+	err = json.Unmarshal(send(serde.ApiV1, serde.VersionMethod, serde.NoPayloadRequest{}), &v1Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEmpty(t, v1Version.Result)
+
+	v2Version := serde.JsonRpcSuccessResponse[serde.VersionResponse]{}
+	err = json.Unmarshal(send(serde.ApiV2, serde.VersionMethod, serde.NoPayloadRequest{}), &v2Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.VersionResponse{Version: v1Version.Result, ApiVersion: serde.ApiV2, SupportedApiVersions: serde.SupportedApiVersions}, v2Version.Result)
+}
+
+func TestRpcRequestIds(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	send := func(request string) []byte {
+		return mockResponder.Handlers[serde.ApiV2]([]byte(request))
+	}
+
+	// Whatever the type of the id, the response echoes it unchanged
+	for _, id := range []string{`"ethers-1"`, `18446744073709551615`, `-3`, `null`} {
+		response := map[string]json.RawMessage{}
+		err := json.Unmarshal(send(`{"jsonrpc":"2.0","id":`+id+`,"method":"version","params":{"payload":{}}}`), &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, id, string(response["id"]))
+		assert.Contains(t, response, "result")
+	}
+
+	// A notification is not responded to
+	assert.Nil(t, send(`{"jsonrpc":"2.0","method":"version","params":{"payload":{}}}`))
+
+	// An id of another type is invalid, and is responded to with a null id
+	for _, id := range []string{`1.5`, `true`, `{"a":1}`} {
+		response := serde.JsonRpcErrorResponse{}
+		err := json.Unmarshal(send(`{"jsonrpc":"2.0","id":`+id+`,"method":"version"}`), &response)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, serde.InvalidRequestError, response.Error)
+		assert.True(t, response.Id.IsNull())
+	}
+
+	// An invalid request is responded to, even without an id
+	response := serde.JsonRpcErrorResponse{}
+	if err := json.Unmarshal(send(`{"jsonrpc":"1.0","method":"version"}`), &response); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.InvalidRequestError, response.Error)
+}
+
+func TestRpcComputeHashes(t *testing.T) {
+	mockResponder := &mockResponder{}
+	_, err := newRpcServerWithoutNotifications(&nitro.Node{}, mockResponder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	voucher := payments.Voucher{ChannelId: types.Destination{1}, Amount: big.NewInt(7)}
+	payload := serde.ComputeVoucherHashRequest{ChannelId: voucher.ChannelId, Amount: (*serde.Quantity)(voucher.Amount)}
+	request := serde.JsonRpcSpecificRequest[serde.ComputeVoucherHashRequest]{
+		Jsonrpc: "2.0",
+		Id:      serde.NumberId(2),
+		Method:  string(serde.ComputeVoucherHashMethod),
+		Params:  serde.Params[serde.ComputeVoucherHashRequest]{Payload: payload},
+	}
+	jsonRequest, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	jsonResponse := serde.JsonRpcSuccessResponse[types.Bytes32]{}
+	err = json.Unmarshal(mockResponder.Handlers[serde.ApiV2](jsonRequest), &jsonResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := voucher.Hash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, want, jsonResponse.Result)
+
+	// The method was added in v2, so is not found by clients of v1
+	errorResponse := serde.JsonRpcErrorResponse{}
+	err = json.Unmarshal(mockResponder.Handlers[serde.ApiV1](jsonRequest), &errorResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, serde.MethodNotFoundError, errorResponse.Error)
+}
+
+func TestExportChunks(t *testing.T) {
+	chunks := exportChunks([]byte("abcdefghij"), 4)
+	assert.Equal(t, [][]byte{[]byte("abcd"), []byte("efgh"), []byte("ij")}, chunks)
+
+	// An empty export is a single, empty, last chunk
+	assert.Equal(t, [][]byte{{}}, exportChunks([]byte{}, 4))
+}
@@ -0,0 +1,126 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/types"
+)
+
+// ErrSpendLimitExceeded is wrapped by the errors returned when a payment would exceed the client's SpendLimits.
+const ErrSpendLimitExceeded = types.ConstError("spend limit exceeded")
+
+// SpendLimits are guardrails on the payments made through a client, which protect an application from bugs that
+// could drain a channel, such as paying in a loop. They are enforced by the client, before a payment is requested from
+// the node. Payments made with Pay, PayWithId, PayWithContext, CreateVoucher and CreateVoucherWithId count towards
+// them, including retries for a payment id. A zero limit is not enforced.
+type SpendLimits struct {
+	// MaxPayment is the largest single payment.
+	MaxPayment uint64
+	// MaxPerMinute is the most which may be paid, across all channels, in a minute. It is enforced as a token bucket,
+	// which holds up to a minute's spend and is refilled continuously.
+	MaxPerMinute uint64
+	// MaxPerChannel is the most which may be paid into each payment channel through the client.
+	MaxPerChannel uint64
+}
+
+// spendLimiter enforces SpendLimits. Payments reserve their amount before they are requested, and the reservation is
+// refunded if the node refuses the request. It is kept if the request fails otherwise, as the payment may have been made.
+type spendLimiter struct {
+	limits SpendLimits
+	now    func() time.Time
+
+	mu       sync.Mutex
+	bucket   *big.Int // the amount which may be paid now under MaxPerMinute
+	refilled time.Time
+	spent    map[types.Destination]uint64
+}
+
+func newSpendLimiter(limits SpendLimits, now func() time.Time) *spendLimiter {
+	return &spendLimiter{
+		limits:   limits,
+		now:      now,
+		bucket:   new(big.Int).SetUint64(limits.MaxPerMinute),
+		refilled: now(),
+		spent:    make(map[types.Destination]uint64),
+	}
+}
+
+// refill adds to the bucket what has accrued since it was last refilled. It must be called with sl.mu held.
+func (sl *spendLimiter) refill() {
+	now := sl.now()
+	elapsed := now.Sub(sl.refilled)
+	if elapsed <= 0 {
+		return
+	}
+	sl.refilled = now
+
+	perMinute := new(big.Int).SetUint64(sl.limits.MaxPerMinute)
+	accrued := new(big.Int).Mul(perMinute, big.NewInt(int64(elapsed)))
+	accrued.Quo(accrued, big.NewInt(int64(time.Minute)))
+	sl.bucket.Add(sl.bucket, accrued)
+	if sl.bucket.Cmp(perMinute) > 0 {
+		sl.bucket.Set(perMinute)
+	}
+}
+
+// reserve checks that paying amount into the channel is within the limits, and counts it if so.
+func (sl *spendLimiter) reserve(chId types.Destination, amount uint64) error {
+	if sl.limits.MaxPayment > 0 && amount > sl.limits.MaxPayment {
+		return fmt.Errorf("%w: payment of %d exceeds the maximum single payment of %d", ErrSpendLimitExceeded, amount, sl.limits.MaxPayment)
+	}
+
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	if limit := sl.limits.MaxPerChannel; limit > 0 && (amount > limit || sl.spent[chId] > limit-amount) {
+		return fmt.Errorf("%w: payment of %d would take the total paid into channel %s to more than %d, having paid %d", ErrSpendLimitExceeded, amount, chId, limit, sl.spent[chId])
+	}
+	if sl.limits.MaxPerMinute > 0 {
+		sl.refill()
+		a := new(big.Int).SetUint64(amount)
+		if sl.bucket.Cmp(a) < 0 {
+			return fmt.Errorf("%w: payment of %d exceeds the %d left to spend of %d per minute", ErrSpendLimitExceeded, amount, sl.bucket, sl.limits.MaxPerMinute)
+		}
+		sl.bucket.Sub(sl.bucket, a)
+	}
+	sl.spent[chId] += amount
+	return nil
+}
+
+// refund returns a reservation for a payment which was not made.
+func (sl *spendLimiter) refund(chId types.Destination, amount uint64) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+	sl.spent[chId] -= amount
+	if sl.limits.MaxPerMinute > 0 {
+		sl.bucket.Add(sl.bucket, new(big.Int).SetUint64(amount))
+		if perMinute := new(big.Int).SetUint64(sl.limits.MaxPerMinute); sl.bucket.Cmp(perMinute) > 0 {
+			sl.bucket.Set(perMinute)
+		}
+	}
+}
+
+// spendLimited makes the payment request, if paying amount into the channel is within the client's spend limits.
+func spendLimited[T any](rc *rpcClient, chId types.Destination, amount uint64, request func() (T, error)) (T, error) {
+	rc.spendLimitsMu.Lock()
+	sl := rc.spendLimits
+	rc.spendLimitsMu.Unlock()
+	if sl == nil {
+		return request()
+	}
+
+	if err := sl.reserve(chId, amount); err != nil {
+		var zero T
+		return zero, err
+	}
+	result, err := request()
+	var rpcErr serde.JsonRpcError
+	if errors.As(err, &rpcErr) {
+		sl.refund(chId, amount)
+	}
+	return result, err
+}
@@ -0,0 +1,49 @@
+package rpc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/statechannels/go-nitro/types"
+)
+
+func TestSpendLimiter(t *testing.T) {
+	now := time.Now()
+	sl := newSpendLimiter(SpendLimits{MaxPayment: 50, MaxPerMinute: 120, MaxPerChannel: 150}, func() time.Time { return now })
+	a, b := types.Destination{1}, types.Destination{2}
+
+	reserve := func(chId types.Destination, amount uint64, allowed bool) {
+		t.Helper()
+		err := sl.reserve(chId, amount)
+		if allowed && err != nil {
+			t.Fatalf("expected a payment of %d to be allowed, got %v", amount, err)
+		}
+		if !allowed && !errors.Is(err, ErrSpendLimitExceeded) {
+			t.Fatalf("expected a payment of %d to exceed the limits, got %v", amount, err)
+		}
+	}
+
+	// A single payment may not exceed MaxPayment
+	reserve(a, 51, false)
+
+	// The minute's spend is shared by all channels
+	reserve(a, 50, true)
+	reserve(b, 50, true)
+	reserve(a, 30, false)
+	reserve(a, 20, true)
+
+	// The bucket refills continuously: half a minute later, half a minute's spend is available again
+	now = now.Add(30 * time.Second)
+	reserve(b, 50, true)
+	reserve(b, 20, false)
+	sl.refund(b, 50)
+	reserve(b, 50, true)
+
+	// Once a channel has been paid MaxPerChannel, it may not be paid any more
+	now = now.Add(time.Hour)
+	reserve(a, 50, true)
+	reserve(a, 40, false)
+	reserve(a, 30, true)
+	reserve(b, 40, true)
+}
@@ -0,0 +1,169 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+)
+
+// requestTimeout is how long a request is given to be answered.
+const requestTimeout = 10 * time.Second
+
+type clientGrpcTransport struct {
+	conn             *grpc.ClientConn
+	client           NitroClient
+	apiVersion       string
+	notificationChan chan []byte
+	cancelSubscribe  context.CancelFunc
+	subscriptionId   string
+	logger           *slog.Logger
+
+	wg *sync.WaitGroup
+}
+
+// NewGrpcTransportAsClient creates a transport sending requests to the gRPC server at the url. The dial options must
+// include the transport credentials to connect with, such as credentials.NewClientTLSFromCert or insecure.NewCredentials.
+func NewGrpcTransportAsClient(url string, opts ...grpc.DialOption) (*clientGrpcTransport, error) {
+	conn, err := grpc.Dial(url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &clientGrpcTransport{
+		conn:       conn,
+		client:     NewNitroClient(conn),
+		apiVersion: defaultApiVersion,
+		logger:     logging.ModuleLogger(logging.RPC_MODULE),
+		wg:         &sync.WaitGroup{},
+	}, nil
+}
+
+// Request sends the json-rpc request as a Call, and returns the json-rpc response to it.
+func (c *clientGrpcTransport) Request(data []byte) ([]byte, error) {
+	var request rpcRequest
+	if err := json.Unmarshal(data, &request); err != nil {
+		return nil, fmt.Errorf("could not parse request: %w", err)
+	}
+	method, ok := methodValue(request.Method)
+	if !ok {
+		return nil, fmt.Errorf("unknown method %s", request.Method)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if request.Params.AuthToken != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+request.Params.AuthToken)
+	}
+	resp, err := c.client.Call(ctx, &CallRequest{Method: method, Payload: request.Params.Payload, ApiVersion: c.apiVersion, Id: request.Id})
+	if err != nil {
+		return nil, err
+	}
+
+	response := rpcResponse{Jsonrpc: "2.0", Id: request.Id}
+	if rpcErr := resp.GetError(); rpcErr != nil {
+		response.Error = &rpcError{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data}
+	} else {
+		response.Result = resp.GetResult()
+		if len(response.Result) == 0 {
+			response.Result = json.RawMessage("null")
+		}
+	}
+	return json.Marshal(response)
+}
+
+func (c *clientGrpcTransport) UseApiVersion(version string) {
+	c.apiVersion = version
+}
+
+// Subscribe streams the notifications of the server, as json-rpc requests, without presenting a token, which servers
+// refuse unless they do not authorize subscriptions.
+func (c *clientGrpcTransport) Subscribe() (<-chan []byte, error) {
+	return c.SubscribeWithToken("")
+}
+
+// SubscribeWithToken streams the notifications of the server, as json-rpc requests, presenting the auth token as a
+// bearer token. It returns an error if the server refuses the token. Once subscribed, later calls return the same
+// channel.
+func (c *clientGrpcTransport) SubscribeWithToken(token string) (<-chan []byte, error) {
+	if c.notificationChan != nil {
+		return c.notificationChan, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	callCtx := ctx
+	if token != "" {
+		callCtx = metadata.AppendToOutgoingContext(ctx, authorizationKey, "Bearer "+token)
+	}
+	stream, err := c.client.Subscribe(callCtx, &SubscribeRequest{})
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The server sends its header once it admits the call, or ends the call without one if it refuses it
+	header, err := stream.Header()
+	if err == nil && len(header.Get(subscriptionKey)) == 0 {
+		_, err = stream.Recv()
+		if err == nil {
+			err = fmt.Errorf("the server sent a notification before admitting the subscription")
+		}
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	c.cancelSubscribe = cancel
+	c.subscriptionId = header.Get(subscriptionKey)[0]
+	c.notificationChan = make(chan []byte, 10)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		for {
+			notification, err := stream.Recv()
+			if err != nil {
+				c.logger.Debug("gRPC notification stream ended", "error", err)
+				return
+			}
+			data, err := json.Marshal(struct {
+				Jsonrpc string          `json:"jsonrpc"`
+				Id      uint64          `json:"id"`
+				Method  string          `json:"method"`
+				Params  json.RawMessage `json:"params"`
+			}{"2.0", 0, notification.Method, notification.Params})
+			if err != nil {
+				c.logger.Error("Could not encode a notification", "error", err)
+				continue
+			}
+			select {
+			case c.notificationChan <- data:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return c.notificationChan, nil
+}
+
+// SubscriptionId returns the id the server gave the transport's subscription, if it has subscribed.
+func (c *clientGrpcTransport) SubscriptionId() string {
+	return c.subscriptionId
+}
+
+func (c *clientGrpcTransport) Close() error {
+	if c.cancelSubscribe != nil {
+		c.cancelSubscribe()
+	}
+	err := c.conn.Close()
+	c.wg.Wait()
+	if c.notificationChan != nil {
+		close(c.notificationChan)
+	}
+	return err
+}
@@ -0,0 +1,846 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.30.0
+// 	protoc        (unknown)
+// source: nitro.proto
+
+package grpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Method is a method of the rpc api. The name of each value is the name of the json-rpc method in upper case, prefixed
+// with METHOD_.
+type Method int32
+
+const (
+	Method_METHOD_UNSPECIFIED                     Method = 0
+	Method_METHOD_GET_AUTH_TOKEN                  Method = 1
+	Method_METHOD_GET_ADDRESS                     Method = 2
+	Method_METHOD_VERSION                         Method = 3
+	Method_METHOD_CREATE_LEDGER_CHANNEL           Method = 4
+	Method_METHOD_CLOSE_LEDGER_CHANNEL            Method = 5
+	Method_METHOD_CREATE_PAYMENT_CHANNEL          Method = 6
+	Method_METHOD_CLOSE_PAYMENT_CHANNEL           Method = 7
+	Method_METHOD_PAY                             Method = 8
+	Method_METHOD_GET_PAYMENT_CHANNEL             Method = 9
+	Method_METHOD_GET_LEDGER_CHANNEL              Method = 10
+	Method_METHOD_GET_PAYMENT_CHANNELS_BY_LEDGER  Method = 11
+	Method_METHOD_GET_ALL_LEDGER_CHANNELS         Method = 12
+	Method_METHOD_CREATE_VOUCHER                  Method = 13
+	Method_METHOD_RECEIVE_VOUCHER                 Method = 14
+	Method_METHOD_GET_SIGNED_STATE                Method = 15
+	Method_METHOD_GET_QUOTE                       Method = 16
+	Method_METHOD_GET_LOG_LEVELS                  Method = 17
+	Method_METHOD_SET_LOG_LEVEL                   Method = 18
+	Method_METHOD_GET_BALANCE_HISTORY             Method = 19
+	Method_METHOD_EXPORT_ACTIVITY                 Method = 20
+	Method_METHOD_GET_DEBUG_BUNDLE                Method = 21
+	Method_METHOD_GET_CHANNEL_SNAPSHOT            Method = 22
+	Method_METHOD_GET_PEER_STATS                  Method = 23
+	Method_METHOD_STREAM_ALL_LEDGER_CHANNELS      Method = 24
+	Method_METHOD_FIND_CHANNELS_BY_TAG            Method = 25
+	Method_METHOD_COMPUTE_CHANNEL_ID              Method = 26
+	Method_METHOD_GET_MESSAGE_FAULTS              Method = 27
+	Method_METHOD_SET_MESSAGE_FAULTS              Method = 28
+	Method_METHOD_GET_CONFIG                      Method = 29
+	Method_METHOD_SET_CONFIG                      Method = 30
+	Method_METHOD_NEGOTIATE_VERSION               Method = 31
+	Method_METHOD_COMPUTE_STATE_HASH              Method = 32
+	Method_METHOD_COMPUTE_VOUCHER_HASH            Method = 33
+	Method_METHOD_GET_PAYMENT_LATENCY             Method = 34
+	Method_METHOD_GET_OR_CREATE_PAYMENT_CHANNEL   Method = 35
+	Method_METHOD_CLOSE_ALL_CHANNELS              Method = 36
+	Method_METHOD_GET_CLOSE_ALL_PROGRESS          Method = 37
+	Method_METHOD_CREATE_OBJECTIVE                Method = 38
+	Method_METHOD_CREATE_SUBSCRIPTION             Method = 39
+	Method_METHOD_GET_SUBSCRIPTION                Method = 40
+	Method_METHOD_CANCEL_SUBSCRIPTION             Method = 41
+	Method_METHOD_CREATE_ESCROW                   Method = 42
+	Method_METHOD_GET_ESCROW                      Method = 43
+	Method_METHOD_SUBMIT_ESCROW_RESULT            Method = 44
+	Method_METHOD_DISPUTE_ESCROW                  Method = 45
+	Method_METHOD_CREATE_LEDGER_CHANNELS_BATCH    Method = 46
+	Method_METHOD_GET_LEDGER_CHANNELS_BATCH       Method = 47
+	Method_METHOD_PROBE_COUNTERPARTY              Method = 48
+	Method_METHOD_BLOCK_PEER                      Method = 49
+	Method_METHOD_UNBLOCK_PEER                    Method = 50
+	Method_METHOD_GET_BLOCKED_PEERS               Method = 51
+	Method_METHOD_LIST_LEDGER_CHANNELS            Method = 52
+	Method_METHOD_LIST_PAYMENT_CHANNELS_BY_LEDGER Method = 53
+	Method_METHOD_WITHDRAW_FROM_LEDGER_CHANNEL    Method = 54
+	Method_METHOD_REBIND_RPC_SERVER               Method = 55
+	Method_METHOD_GET_API_KEYS                    Method = 56
+	Method_METHOD_SET_API_KEY                     Method = 57
+	Method_METHOD_REMOVE_API_KEY                  Method = 58
+	Method_METHOD_STREAM_ACTIVITY_EXPORT          Method = 59
+)
+
+// Enum value maps for Method.
+var (
+	Method_name = map[int32]string{
+		0:  "METHOD_UNSPECIFIED",
+		1:  "METHOD_GET_AUTH_TOKEN",
+		2:  "METHOD_GET_ADDRESS",
+		3:  "METHOD_VERSION",
+		4:  "METHOD_CREATE_LEDGER_CHANNEL",
+		5:  "METHOD_CLOSE_LEDGER_CHANNEL",
+		6:  "METHOD_CREATE_PAYMENT_CHANNEL",
+		7:  "METHOD_CLOSE_PAYMENT_CHANNEL",
+		8:  "METHOD_PAY",
+		9:  "METHOD_GET_PAYMENT_CHANNEL",
+		10: "METHOD_GET_LEDGER_CHANNEL",
+		11: "METHOD_GET_PAYMENT_CHANNELS_BY_LEDGER",
+		12: "METHOD_GET_ALL_LEDGER_CHANNELS",
+		13: "METHOD_CREATE_VOUCHER",
+		14: "METHOD_RECEIVE_VOUCHER",
+		15: "METHOD_GET_SIGNED_STATE",
+		16: "METHOD_GET_QUOTE",
+		17: "METHOD_GET_LOG_LEVELS",
+		18: "METHOD_SET_LOG_LEVEL",
+		19: "METHOD_GET_BALANCE_HISTORY",
+		20: "METHOD_EXPORT_ACTIVITY",
+		21: "METHOD_GET_DEBUG_BUNDLE",
+		22: "METHOD_GET_CHANNEL_SNAPSHOT",
+		23: "METHOD_GET_PEER_STATS",
+		24: "METHOD_STREAM_ALL_LEDGER_CHANNELS",
+		25: "METHOD_FIND_CHANNELS_BY_TAG",
+		26: "METHOD_COMPUTE_CHANNEL_ID",
+		27: "METHOD_GET_MESSAGE_FAULTS",
+		28: "METHOD_SET_MESSAGE_FAULTS",
+		29: "METHOD_GET_CONFIG",
+		30: "METHOD_SET_CONFIG",
+		31: "METHOD_NEGOTIATE_VERSION",
+		32: "METHOD_COMPUTE_STATE_HASH",
+		33: "METHOD_COMPUTE_VOUCHER_HASH",
+		34: "METHOD_GET_PAYMENT_LATENCY",
+		35: "METHOD_GET_OR_CREATE_PAYMENT_CHANNEL",
+		36: "METHOD_CLOSE_ALL_CHANNELS",
+		37: "METHOD_GET_CLOSE_ALL_PROGRESS",
+		38: "METHOD_CREATE_OBJECTIVE",
+		39: "METHOD_CREATE_SUBSCRIPTION",
+		40: "METHOD_GET_SUBSCRIPTION",
+		41: "METHOD_CANCEL_SUBSCRIPTION",
+		42: "METHOD_CREATE_ESCROW",
+		43: "METHOD_GET_ESCROW",
+		44: "METHOD_SUBMIT_ESCROW_RESULT",
+		45: "METHOD_DISPUTE_ESCROW",
+		46: "METHOD_CREATE_LEDGER_CHANNELS_BATCH",
+		47: "METHOD_GET_LEDGER_CHANNELS_BATCH",
+		48: "METHOD_PROBE_COUNTERPARTY",
+		49: "METHOD_BLOCK_PEER",
+		50: "METHOD_UNBLOCK_PEER",
+		51: "METHOD_GET_BLOCKED_PEERS",
+		52: "METHOD_LIST_LEDGER_CHANNELS",
+		53: "METHOD_LIST_PAYMENT_CHANNELS_BY_LEDGER",
+		54: "METHOD_WITHDRAW_FROM_LEDGER_CHANNEL",
+		55: "METHOD_REBIND_RPC_SERVER",
+		56: "METHOD_GET_API_KEYS",
+		57: "METHOD_SET_API_KEY",
+		58: "METHOD_REMOVE_API_KEY",
+		59: "METHOD_STREAM_ACTIVITY_EXPORT",
+	}
+	Method_value = map[string]int32{
+		"METHOD_UNSPECIFIED":                     0,
+		"METHOD_GET_AUTH_TOKEN":                  1,
+		"METHOD_GET_ADDRESS":                     2,
+		"METHOD_VERSION":                         3,
+		"METHOD_CREATE_LEDGER_CHANNEL":           4,
+		"METHOD_CLOSE_LEDGER_CHANNEL":            5,
+		"METHOD_CREATE_PAYMENT_CHANNEL":          6,
+		"METHOD_CLOSE_PAYMENT_CHANNEL":           7,
+		"METHOD_PAY":                             8,
+		"METHOD_GET_PAYMENT_CHANNEL":             9,
+		"METHOD_GET_LEDGER_CHANNEL":              10,
+		"METHOD_GET_PAYMENT_CHANNELS_BY_LEDGER":  11,
+		"METHOD_GET_ALL_LEDGER_CHANNELS":         12,
+		"METHOD_CREATE_VOUCHER":                  13,
+		"METHOD_RECEIVE_VOUCHER":                 14,
+		"METHOD_GET_SIGNED_STATE":                15,
+		"METHOD_GET_QUOTE":                       16,
+		"METHOD_GET_LOG_LEVELS":                  17,
+		"METHOD_SET_LOG_LEVEL":                   18,
+		"METHOD_GET_BALANCE_HISTORY":             19,
+		"METHOD_EXPORT_ACTIVITY":                 20,
+		"METHOD_GET_DEBUG_BUNDLE":                21,
+		"METHOD_GET_CHANNEL_SNAPSHOT":            22,
+		"METHOD_GET_PEER_STATS":                  23,
+		"METHOD_STREAM_ALL_LEDGER_CHANNELS":      24,
+		"METHOD_FIND_CHANNELS_BY_TAG":            25,
+		"METHOD_COMPUTE_CHANNEL_ID":              26,
+		"METHOD_GET_MESSAGE_FAULTS":              27,
+		"METHOD_SET_MESSAGE_FAULTS":              28,
+		"METHOD_GET_CONFIG":                      29,
+		"METHOD_SET_CONFIG":                      30,
+		"METHOD_NEGOTIATE_VERSION":               31,
+		"METHOD_COMPUTE_STATE_HASH":              32,
+		"METHOD_COMPUTE_VOUCHER_HASH":            33,
+		"METHOD_GET_PAYMENT_LATENCY":             34,
+		"METHOD_GET_OR_CREATE_PAYMENT_CHANNEL":   35,
+		"METHOD_CLOSE_ALL_CHANNELS":              36,
+		"METHOD_GET_CLOSE_ALL_PROGRESS":          37,
+		"METHOD_CREATE_OBJECTIVE":                38,
+		"METHOD_CREATE_SUBSCRIPTION":             39,
+		"METHOD_GET_SUBSCRIPTION":                40,
+		"METHOD_CANCEL_SUBSCRIPTION":             41,
+		"METHOD_CREATE_ESCROW":                   42,
+		"METHOD_GET_ESCROW":                      43,
+		"METHOD_SUBMIT_ESCROW_RESULT":            44,
+		"METHOD_DISPUTE_ESCROW":                  45,
+		"METHOD_CREATE_LEDGER_CHANNELS_BATCH":    46,
+		"METHOD_GET_LEDGER_CHANNELS_BATCH":       47,
+		"METHOD_PROBE_COUNTERPARTY":              48,
+		"METHOD_BLOCK_PEER":                      49,
+		"METHOD_UNBLOCK_PEER":                    50,
+		"METHOD_GET_BLOCKED_PEERS":               51,
+		"METHOD_LIST_LEDGER_CHANNELS":            52,
+		"METHOD_LIST_PAYMENT_CHANNELS_BY_LEDGER": 53,
+		"METHOD_WITHDRAW_FROM_LEDGER_CHANNEL":    54,
+		"METHOD_REBIND_RPC_SERVER":               55,
+		"METHOD_GET_API_KEYS":                    56,
+		"METHOD_SET_API_KEY":                     57,
+		"METHOD_REMOVE_API_KEY":                  58,
+		"METHOD_STREAM_ACTIVITY_EXPORT":          59,
+	}
+)
+
+func (x Method) Enum() *Method {
+	p := new(Method)
+	*p = x
+	return p
+}
+
+func (x Method) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Method) Descriptor() protoreflect.EnumDescriptor {
+	return file_nitro_proto_enumTypes[0].Descriptor()
+}
+
+func (Method) Type() protoreflect.EnumType {
+	return &file_nitro_proto_enumTypes[0]
+}
+
+func (x Method) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Method.Descriptor instead.
+func (Method) EnumDescriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{0}
+}
+
+type CallRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Method Method `protobuf:"varint,1,opt,name=method,proto3,enum=nitro.rpc.Method" json:"method,omitempty"`
+	// payload is the json encoding of the method's request payload. It may be empty for methods which take none.
+	Payload []byte `protobuf:"bytes,2,opt,name=payload,proto3" json:"payload,omitempty"`
+	// api_version is the version of the rpc api to call, such as "v2". It is v1 if empty.
+	ApiVersion string `protobuf:"bytes,3,opt,name=api_version,json=apiVersion,proto3" json:"api_version,omitempty"`
+	// id identifies the request to notifications streaming its results, such as the pages of stream_all_ledger_channels.
+	// The server picks one if it is zero.
+	Id uint64 `protobuf:"varint,4,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *CallRequest) Reset() {
+	*x = CallRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nitro_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallRequest) ProtoMessage() {}
+
+func (x *CallRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nitro_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallRequest.ProtoReflect.Descriptor instead.
+func (*CallRequest) Descriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CallRequest) GetMethod() Method {
+	if x != nil {
+		return x.Method
+	}
+	return Method_METHOD_UNSPECIFIED
+}
+
+func (x *CallRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *CallRequest) GetApiVersion() string {
+	if x != nil {
+		return x.ApiVersion
+	}
+	return ""
+}
+
+func (x *CallRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CallResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Outcome:
+	//	*CallResponse_Result
+	//	*CallResponse_Error
+	Outcome isCallResponse_Outcome `protobuf_oneof:"outcome"`
+}
+
+func (x *CallResponse) Reset() {
+	*x = CallResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nitro_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CallResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CallResponse) ProtoMessage() {}
+
+func (x *CallResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_nitro_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CallResponse.ProtoReflect.Descriptor instead.
+func (*CallResponse) Descriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{1}
+}
+
+func (m *CallResponse) GetOutcome() isCallResponse_Outcome {
+	if m != nil {
+		return m.Outcome
+	}
+	return nil
+}
+
+func (x *CallResponse) GetResult() []byte {
+	if x, ok := x.GetOutcome().(*CallResponse_Result); ok {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *CallResponse) GetError() *Error {
+	if x, ok := x.GetOutcome().(*CallResponse_Error); ok {
+		return x.Error
+	}
+	return nil
+}
+
+type isCallResponse_Outcome interface {
+	isCallResponse_Outcome()
+}
+
+type CallResponse_Result struct {
+	// result is the json encoding of the method's response payload.
+	Result []byte `protobuf:"bytes,1,opt,name=result,proto3,oneof"`
+}
+
+type CallResponse_Error struct {
+	Error *Error `protobuf:"bytes,2,opt,name=error,proto3,oneof"`
+}
+
+func (*CallResponse_Result) isCallResponse_Outcome() {}
+
+func (*CallResponse_Error) isCallResponse_Outcome() {}
+
+// Error is an error of the json-rpc api, which is returned in a CallResponse rather than as the status of the call so
+// that its code is preserved.
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Code    int64  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// data is the json encoding of any data describing the error.
+	Data []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nitro_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_nitro_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Error) GetCode() int64 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type SubscribeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nitro_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_nitro_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{3}
+}
+
+type Notification struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// method is the json-rpc notification method, such as objective_completed.
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// params is the json encoding of the notification's params.
+	Params []byte `protobuf:"bytes,2,opt,name=params,proto3" json:"params,omitempty"`
+}
+
+func (x *Notification) Reset() {
+	*x = Notification{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_nitro_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Notification) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Notification) ProtoMessage() {}
+
+func (x *Notification) ProtoReflect() protoreflect.Message {
+	mi := &file_nitro_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Notification.ProtoReflect.Descriptor instead.
+func (*Notification) Descriptor() ([]byte, []int) {
+	return file_nitro_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Notification) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *Notification) GetParams() []byte {
+	if x != nil {
+		return x.Params
+	}
+	return nil
+}
+
+var File_nitro_proto protoreflect.FileDescriptor
+
+var file_nitro_proto_rawDesc = []byte{
+	0x0a, 0x0b, 0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x6e,
+	0x69, 0x74, 0x72, 0x6f, 0x2e, 0x72, 0x70, 0x63, 0x22, 0x83, 0x01, 0x0a, 0x0b, 0x43, 0x61, 0x6c,
+	0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x29, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x6e, 0x69, 0x74, 0x72, 0x6f,
+	0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x52, 0x06, 0x6d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x70, 0x61, 0x79, 0x6c, 0x6f, 0x61, 0x64, 0x12, 0x1f, 0x0a,
+	0x0b, 0x61, 0x70, 0x69, 0x5f, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0a, 0x61, 0x70, 0x69, 0x56, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e,
+	0x0a, 0x02, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x02, 0x69, 0x64, 0x22, 0x5d,
+	0x0a, 0x0c, 0x43, 0x61, 0x6c, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x48, 0x00,
+	0x52, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x28, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x10, 0x2e, 0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e,
+	0x72, 0x70, 0x63, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x48, 0x00, 0x52, 0x05, 0x65, 0x72, 0x72,
+	0x6f, 0x72, 0x42, 0x09, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x63, 0x6f, 0x6d, 0x65, 0x22, 0x49, 0x0a,
+	0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x12, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x3e, 0x0a, 0x0c,
+	0x4e, 0x6f, 0x74, 0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06,
+	0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x73, 0x2a, 0xa4, 0x0e, 0x0a,
+	0x06, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12,
+	0x19, 0x0a, 0x15, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x41, 0x55,
+	0x54, 0x48, 0x5f, 0x54, 0x4f, 0x4b, 0x45, 0x4e, 0x10, 0x01, 0x12, 0x16, 0x0a, 0x12, 0x4d, 0x45,
+	0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x41, 0x44, 0x44, 0x52, 0x45, 0x53, 0x53,
+	0x10, 0x02, 0x12, 0x12, 0x0a, 0x0e, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x56, 0x45, 0x52,
+	0x53, 0x49, 0x4f, 0x4e, 0x10, 0x03, 0x12, 0x20, 0x0a, 0x1c, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44,
+	0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x43,
+	0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x04, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48,
+	0x4f, 0x44, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f,
+	0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x05, 0x12, 0x21, 0x0a, 0x1d, 0x4d, 0x45, 0x54,
+	0x48, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x41, 0x59, 0x4d, 0x45,
+	0x4e, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x06, 0x12, 0x20, 0x0a, 0x1c,
+	0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x5f, 0x50, 0x41, 0x59,
+	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x07, 0x12, 0x0e,
+	0x0a, 0x0a, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x50, 0x41, 0x59, 0x10, 0x08, 0x12, 0x1e,
+	0x0a, 0x1a, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x50, 0x41, 0x59,
+	0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x09, 0x12, 0x1d,
+	0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x4c, 0x45, 0x44,
+	0x47, 0x45, 0x52, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x0a, 0x12, 0x29, 0x0a,
+	0x25, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x50, 0x41, 0x59, 0x4d,
+	0x45, 0x4e, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x5f, 0x42, 0x59, 0x5f,
+	0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x10, 0x0b, 0x12, 0x22, 0x0a, 0x1e, 0x4d, 0x45, 0x54, 0x48,
+	0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x41, 0x4c, 0x4c, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45,
+	0x52, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x10, 0x0c, 0x12, 0x19, 0x0a, 0x15,
+	0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x56, 0x4f,
+	0x55, 0x43, 0x48, 0x45, 0x52, 0x10, 0x0d, 0x12, 0x1a, 0x0a, 0x16, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x52, 0x45, 0x43, 0x45, 0x49, 0x56, 0x45, 0x5f, 0x56, 0x4f, 0x55, 0x43, 0x48, 0x45,
+	0x52, 0x10, 0x0e, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45,
+	0x54, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x45, 0x44, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x10, 0x0f,
+	0x12, 0x14, 0x0a, 0x10, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x51,
+	0x55, 0x4f, 0x54, 0x45, 0x10, 0x10, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44,
+	0x5f, 0x47, 0x45, 0x54, 0x5f, 0x4c, 0x4f, 0x47, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x53, 0x10,
+	0x11, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x45, 0x54, 0x5f,
+	0x4c, 0x4f, 0x47, 0x5f, 0x4c, 0x45, 0x56, 0x45, 0x4c, 0x10, 0x12, 0x12, 0x1e, 0x0a, 0x1a, 0x4d,
+	0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x42, 0x41, 0x4c, 0x41, 0x4e, 0x43,
+	0x45, 0x5f, 0x48, 0x49, 0x53, 0x54, 0x4f, 0x52, 0x59, 0x10, 0x13, 0x12, 0x1a, 0x0a, 0x16, 0x4d,
+	0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x45, 0x58, 0x50, 0x4f, 0x52, 0x54, 0x5f, 0x41, 0x43, 0x54,
+	0x49, 0x56, 0x49, 0x54, 0x59, 0x10, 0x14, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x44, 0x45, 0x42, 0x55, 0x47, 0x5f, 0x42, 0x55, 0x4e, 0x44,
+	0x4c, 0x45, 0x10, 0x15, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47,
+	0x45, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x5f, 0x53, 0x4e, 0x41, 0x50, 0x53,
+	0x48, 0x4f, 0x54, 0x10, 0x16, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f,
+	0x47, 0x45, 0x54, 0x5f, 0x50, 0x45, 0x45, 0x52, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x53, 0x10, 0x17,
+	0x12, 0x25, 0x0a, 0x21, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41,
+	0x4d, 0x5f, 0x41, 0x4c, 0x4c, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x43, 0x48, 0x41,
+	0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x10, 0x18, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x46, 0x49, 0x4e, 0x44, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x5f,
+	0x42, 0x59, 0x5f, 0x54, 0x41, 0x47, 0x10, 0x19, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48,
+	0x4f, 0x44, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e,
+	0x45, 0x4c, 0x5f, 0x49, 0x44, 0x10, 0x1a, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x46, 0x41,
+	0x55, 0x4c, 0x54, 0x53, 0x10, 0x1b, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44,
+	0x5f, 0x53, 0x45, 0x54, 0x5f, 0x4d, 0x45, 0x53, 0x53, 0x41, 0x47, 0x45, 0x5f, 0x46, 0x41, 0x55,
+	0x4c, 0x54, 0x53, 0x10, 0x1c, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f,
+	0x47, 0x45, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x49, 0x47, 0x10, 0x1d, 0x12, 0x15, 0x0a, 0x11,
+	0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x45, 0x54, 0x5f, 0x43, 0x4f, 0x4e, 0x46, 0x49,
+	0x47, 0x10, 0x1e, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x4e, 0x45,
+	0x47, 0x4f, 0x54, 0x49, 0x41, 0x54, 0x45, 0x5f, 0x56, 0x45, 0x52, 0x53, 0x49, 0x4f, 0x4e, 0x10,
+	0x1f, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x4f, 0x4d, 0x50,
+	0x55, 0x54, 0x45, 0x5f, 0x53, 0x54, 0x41, 0x54, 0x45, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x10, 0x20,
+	0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55,
+	0x54, 0x45, 0x5f, 0x56, 0x4f, 0x55, 0x43, 0x48, 0x45, 0x52, 0x5f, 0x48, 0x41, 0x53, 0x48, 0x10,
+	0x21, 0x12, 0x1e, 0x0a, 0x1a, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f,
+	0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x4c, 0x41, 0x54, 0x45, 0x4e, 0x43, 0x59, 0x10,
+	0x22, 0x12, 0x28, 0x0a, 0x24, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f,
+	0x4f, 0x52, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e,
+	0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x23, 0x12, 0x1d, 0x0a, 0x19, 0x4d,
+	0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x5f, 0x41, 0x4c, 0x4c, 0x5f,
+	0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x10, 0x24, 0x12, 0x21, 0x0a, 0x1d, 0x4d, 0x45,
+	0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x5f, 0x41,
+	0x4c, 0x4c, 0x5f, 0x50, 0x52, 0x4f, 0x47, 0x52, 0x45, 0x53, 0x53, 0x10, 0x25, 0x12, 0x1b, 0x0a,
+	0x17, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x4f,
+	0x42, 0x4a, 0x45, 0x43, 0x54, 0x49, 0x56, 0x45, 0x10, 0x26, 0x12, 0x1e, 0x0a, 0x1a, 0x4d, 0x45,
+	0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x53, 0x55, 0x42, 0x53,
+	0x43, 0x52, 0x49, 0x50, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x27, 0x12, 0x1b, 0x0a, 0x17, 0x4d, 0x45,
+	0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52, 0x49,
+	0x50, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x28, 0x12, 0x1e, 0x0a, 0x1a, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x5f, 0x53, 0x55, 0x42, 0x53, 0x43, 0x52, 0x49,
+	0x50, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x29, 0x12, 0x18, 0x0a, 0x14, 0x4d, 0x45, 0x54, 0x48, 0x4f,
+	0x44, 0x5f, 0x43, 0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x45, 0x53, 0x43, 0x52, 0x4f, 0x57, 0x10,
+	0x2a, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f,
+	0x45, 0x53, 0x43, 0x52, 0x4f, 0x57, 0x10, 0x2b, 0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48,
+	0x4f, 0x44, 0x5f, 0x53, 0x55, 0x42, 0x4d, 0x49, 0x54, 0x5f, 0x45, 0x53, 0x43, 0x52, 0x4f, 0x57,
+	0x5f, 0x52, 0x45, 0x53, 0x55, 0x4c, 0x54, 0x10, 0x2c, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x45, 0x54,
+	0x48, 0x4f, 0x44, 0x5f, 0x44, 0x49, 0x53, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x45, 0x53, 0x43, 0x52,
+	0x4f, 0x57, 0x10, 0x2d, 0x12, 0x27, 0x0a, 0x23, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x43,
+	0x52, 0x45, 0x41, 0x54, 0x45, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x43, 0x48, 0x41,
+	0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x5f, 0x42, 0x41, 0x54, 0x43, 0x48, 0x10, 0x2e, 0x12, 0x24, 0x0a,
+	0x20, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54, 0x5f, 0x4c, 0x45, 0x44, 0x47,
+	0x45, 0x52, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x5f, 0x42, 0x41, 0x54, 0x43,
+	0x48, 0x10, 0x2f, 0x12, 0x1d, 0x0a, 0x19, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x50, 0x52,
+	0x4f, 0x42, 0x45, 0x5f, 0x43, 0x4f, 0x55, 0x4e, 0x54, 0x45, 0x52, 0x50, 0x41, 0x52, 0x54, 0x59,
+	0x10, 0x30, 0x12, 0x15, 0x0a, 0x11, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x42, 0x4c, 0x4f,
+	0x43, 0x4b, 0x5f, 0x50, 0x45, 0x45, 0x52, 0x10, 0x31, 0x12, 0x17, 0x0a, 0x13, 0x4d, 0x45, 0x54,
+	0x48, 0x4f, 0x44, 0x5f, 0x55, 0x4e, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x5f, 0x50, 0x45, 0x45, 0x52,
+	0x10, 0x32, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47, 0x45, 0x54,
+	0x5f, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x45, 0x44, 0x5f, 0x50, 0x45, 0x45, 0x52, 0x53, 0x10, 0x33,
+	0x12, 0x1f, 0x0a, 0x1b, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x4c, 0x49, 0x53, 0x54, 0x5f,
+	0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c, 0x53, 0x10,
+	0x34, 0x12, 0x2a, 0x0a, 0x26, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x4c, 0x49, 0x53, 0x54,
+	0x5f, 0x50, 0x41, 0x59, 0x4d, 0x45, 0x4e, 0x54, 0x5f, 0x43, 0x48, 0x41, 0x4e, 0x4e, 0x45, 0x4c,
+	0x53, 0x5f, 0x42, 0x59, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x10, 0x35, 0x12, 0x27, 0x0a,
+	0x23, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x57, 0x49, 0x54, 0x48, 0x44, 0x52, 0x41, 0x57,
+	0x5f, 0x46, 0x52, 0x4f, 0x4d, 0x5f, 0x4c, 0x45, 0x44, 0x47, 0x45, 0x52, 0x5f, 0x43, 0x48, 0x41,
+	0x4e, 0x4e, 0x45, 0x4c, 0x10, 0x36, 0x12, 0x1c, 0x0a, 0x18, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44,
+	0x5f, 0x52, 0x45, 0x42, 0x49, 0x4e, 0x44, 0x5f, 0x52, 0x50, 0x43, 0x5f, 0x53, 0x45, 0x52, 0x56,
+	0x45, 0x52, 0x10, 0x37, 0x12, 0x17, 0x0a, 0x13, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x47,
+	0x45, 0x54, 0x5f, 0x41, 0x50, 0x49, 0x5f, 0x4b, 0x45, 0x59, 0x53, 0x10, 0x38, 0x12, 0x16, 0x0a,
+	0x12, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x45, 0x54, 0x5f, 0x41, 0x50, 0x49, 0x5f,
+	0x4b, 0x45, 0x59, 0x10, 0x39, 0x12, 0x19, 0x0a, 0x15, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f,
+	0x52, 0x45, 0x4d, 0x4f, 0x56, 0x45, 0x5f, 0x41, 0x50, 0x49, 0x5f, 0x4b, 0x45, 0x59, 0x10, 0x3a,
+	0x12, 0x21, 0x0a, 0x1d, 0x4d, 0x45, 0x54, 0x48, 0x4f, 0x44, 0x5f, 0x53, 0x54, 0x52, 0x45, 0x41,
+	0x4d, 0x5f, 0x41, 0x43, 0x54, 0x49, 0x56, 0x49, 0x54, 0x59, 0x5f, 0x45, 0x58, 0x50, 0x4f, 0x52,
+	0x54, 0x10, 0x3b, 0x32, 0x85, 0x01, 0x0a, 0x05, 0x4e, 0x69, 0x74, 0x72, 0x6f, 0x12, 0x37, 0x0a,
+	0x04, 0x43, 0x61, 0x6c, 0x6c, 0x12, 0x16, 0x2e, 0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e, 0x72, 0x70,
+	0x63, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x17, 0x2e,
+	0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x43, 0x61, 0x6c, 0x6c, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x43, 0x0a, 0x09, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x12, 0x1b, 0x2e, 0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e, 0x72, 0x70, 0x63, 0x2e,
+	0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x17, 0x2e, 0x6e, 0x69, 0x74, 0x72, 0x6f, 0x2e, 0x72, 0x70, 0x63, 0x2e, 0x4e, 0x6f, 0x74,
+	0x69, 0x66, 0x69, 0x63, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x30, 0x01, 0x42, 0x36, 0x5a, 0x34, 0x67,
+	0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x63,
+	0x68, 0x61, 0x6e, 0x6e, 0x65, 0x6c, 0x73, 0x2f, 0x67, 0x6f, 0x2d, 0x6e, 0x69, 0x74, 0x72, 0x6f,
+	0x2f, 0x72, 0x70, 0x63, 0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x70, 0x6f, 0x72, 0x74, 0x2f, 0x67,
+	0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_nitro_proto_rawDescOnce sync.Once
+	file_nitro_proto_rawDescData = file_nitro_proto_rawDesc
+)
+
+func file_nitro_proto_rawDescGZIP() []byte {
+	file_nitro_proto_rawDescOnce.Do(func() {
+		file_nitro_proto_rawDescData = protoimpl.X.CompressGZIP(file_nitro_proto_rawDescData)
+	})
+	return file_nitro_proto_rawDescData
+}
+
+var file_nitro_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_nitro_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_nitro_proto_goTypes = []interface{}{
+	(Method)(0),              // 0: nitro.rpc.Method
+	(*CallRequest)(nil),      // 1: nitro.rpc.CallRequest
+	(*CallResponse)(nil),     // 2: nitro.rpc.CallResponse
+	(*Error)(nil),            // 3: nitro.rpc.Error
+	(*SubscribeRequest)(nil), // 4: nitro.rpc.SubscribeRequest
+	(*Notification)(nil),     // 5: nitro.rpc.Notification
+}
+var file_nitro_proto_depIdxs = []int32{
+	0, // 0: nitro.rpc.CallRequest.method:type_name -> nitro.rpc.Method
+	3, // 1: nitro.rpc.CallResponse.error:type_name -> nitro.rpc.Error
+	1, // 2: nitro.rpc.Nitro.Call:input_type -> nitro.rpc.CallRequest
+	4, // 3: nitro.rpc.Nitro.Subscribe:input_type -> nitro.rpc.SubscribeRequest
+	2, // 4: nitro.rpc.Nitro.Call:output_type -> nitro.rpc.CallResponse
+	5, // 5: nitro.rpc.Nitro.Subscribe:output_type -> nitro.rpc.Notification
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_nitro_proto_init() }
+func file_nitro_proto_init() {
+	if File_nitro_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_nitro_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CallRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nitro_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CallResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nitro_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nitro_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_nitro_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Notification); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_nitro_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*CallResponse_Result)(nil),
+		(*CallResponse_Error)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_nitro_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_nitro_proto_goTypes,
+		DependencyIndexes: file_nitro_proto_depIdxs,
+		EnumInfos:         file_nitro_proto_enumTypes,
+		MessageInfos:      file_nitro_proto_msgTypes,
+	}.Build()
+	File_nitro_proto = out.File
+	file_nitro_proto_rawDesc = nil
+	file_nitro_proto_goTypes = nil
+	file_nitro_proto_depIdxs = nil
+}
@@ -0,0 +1,178 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: nitro.proto
+
+package grpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Nitro_Call_FullMethodName      = "/nitro.rpc.Nitro/Call"
+	Nitro_Subscribe_FullMethodName = "/nitro.rpc.Nitro/Subscribe"
+)
+
+// NitroClient is the client API for Nitro service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type NitroClient interface {
+	// Call makes a request of the rpc api.
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+	// Subscribe streams the notifications sent by the node, until the call is cancelled.
+	Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Nitro_SubscribeClient, error)
+}
+
+type nitroClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNitroClient(cc grpc.ClientConnInterface) NitroClient {
+	return &nitroClient{cc}
+}
+
+func (c *nitroClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	err := c.cc.Invoke(ctx, Nitro_Call_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nitroClient) Subscribe(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (Nitro_SubscribeClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Nitro_ServiceDesc.Streams[0], Nitro_Subscribe_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &nitroSubscribeClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Nitro_SubscribeClient interface {
+	Recv() (*Notification, error)
+	grpc.ClientStream
+}
+
+type nitroSubscribeClient struct {
+	grpc.ClientStream
+}
+
+func (x *nitroSubscribeClient) Recv() (*Notification, error) {
+	m := new(Notification)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// NitroServer is the server API for Nitro service.
+// All implementations must embed UnimplementedNitroServer
+// for forward compatibility
+type NitroServer interface {
+	// Call makes a request of the rpc api.
+	Call(context.Context, *CallRequest) (*CallResponse, error)
+	// Subscribe streams the notifications sent by the node, until the call is cancelled.
+	Subscribe(*SubscribeRequest, Nitro_SubscribeServer) error
+	mustEmbedUnimplementedNitroServer()
+}
+
+// UnimplementedNitroServer must be embedded to have forward compatible implementations.
+type UnimplementedNitroServer struct {
+}
+
+func (UnimplementedNitroServer) Call(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Call not implemented")
+}
+func (UnimplementedNitroServer) Subscribe(*SubscribeRequest, Nitro_SubscribeServer) error {
+	return status.Errorf(codes.Unimplemented, "method Subscribe not implemented")
+}
+func (UnimplementedNitroServer) mustEmbedUnimplementedNitroServer() {}
+
+// UnsafeNitroServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NitroServer will
+// result in compilation errors.
+type UnsafeNitroServer interface {
+	mustEmbedUnimplementedNitroServer()
+}
+
+func RegisterNitroServer(s grpc.ServiceRegistrar, srv NitroServer) {
+	s.RegisterService(&Nitro_ServiceDesc, srv)
+}
+
+func _Nitro_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NitroServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Nitro_Call_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NitroServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Nitro_Subscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NitroServer).Subscribe(m, &nitroSubscribeServer{stream})
+}
+
+type Nitro_SubscribeServer interface {
+	Send(*Notification) error
+	grpc.ServerStream
+}
+
+type nitroSubscribeServer struct {
+	grpc.ServerStream
+}
+
+func (x *nitroSubscribeServer) Send(m *Notification) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Nitro_ServiceDesc is the grpc.ServiceDesc for Nitro service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Nitro_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nitro.rpc.Nitro",
+	HandlerType: (*NitroServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    _Nitro_Call_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Subscribe",
+			Handler:       _Nitro_Subscribe_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "nitro.proto",
+}
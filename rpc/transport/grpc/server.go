@@ -0,0 +1,322 @@
+// Package grpc is a transport serving the rpc api over gRPC, for services which are gRPC-native. Requests and
+// notifications keep their json-rpc payloads (see nitro.proto), so that the same RpcServer serves every transport.
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative nitro.proto
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/netaddr"
+	"github.com/statechannels/go-nitro/internal/safesync"
+	"github.com/statechannels/go-nitro/rpc/transport"
+)
+
+const (
+	defaultApiVersion = "v1"
+	// authorizationKey is the metadata key of the auth token, which is sent as "Bearer <token>".
+	authorizationKey = "authorization"
+	// subscriptionKey is the header metadata key with which the server admits a Subscribe call, before any notification,
+	// and which carries the id of the subscription.
+	subscriptionKey = "nitro-subscription"
+)
+
+// rpcRequest is the json-rpc request passed to the request handler.
+type rpcRequest struct {
+	Jsonrpc string    `json:"jsonrpc"`
+	Id      uint64    `json:"id"`
+	Method  string    `json:"method"`
+	Params  rpcParams `json:"params"`
+}
+
+type rpcParams struct {
+	AuthToken string          `json:"authtoken"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// rpcResponse is the json-rpc response returned by the request handler, which holds either a result or an error.
+type rpcResponse struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	Id      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int64           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// notificationListener receives notifications for a Subscribe call, made with token, until done is closed.
+type notificationListener struct {
+	notifications chan []byte
+	done          chan struct{}
+	token         string
+}
+
+type serverGrpcTransport struct {
+	UnimplementedNitroServer
+
+	grpcServer            *grpc.Server
+	requestHandlers       safesync.Map[func([]byte) []byte]
+	notificationListeners safesync.Map[notificationListener]
+	requestIds            atomic.Uint64
+	port                  string
+	family                netaddr.Family
+	closing               chan struct{}
+	logger                *slog.Logger
+
+	// authorizeMu guards authorizeSubscription, which admits Subscribe calls. Every call is refused while it is nil.
+	authorizeMu           sync.RWMutex
+	authorizeSubscription func(token string) error
+
+	wg *sync.WaitGroup
+}
+
+// NewGrpcTransportAsServer starts a gRPC server on the port, listening on both IPv4 and IPv6, and serving TLS if cert
+// is not nil. The server options are passed to the gRPC server, such as the interceptors to run on every call.
+func NewGrpcTransportAsServer(port string, cert *tls.Certificate, opts ...grpc.ServerOption) (*serverGrpcTransport, error) {
+	return NewGrpcTransportAsServerWithFamily(port, cert, netaddr.DualStack, opts...)
+}
+
+// NewGrpcTransportAsServerWithFamily is like NewGrpcTransportAsServer, but listens on the IP versions of the family.
+func NewGrpcTransportAsServerWithFamily(port string, cert *tls.Certificate, family netaddr.Family, opts ...grpc.ServerOption) (*serverGrpcTransport, error) {
+	return NewGrpcTransportAsServerWithTLSConfig(port, transport.CertificateConfig(cert), family, opts...)
+}
+
+// NewGrpcTransportAsServerWithTLSConfig is like NewGrpcTransportAsServerWithFamily, but serves TLS with the config if it
+// is not nil, such as the config of an autocert.Manager.
+func NewGrpcTransportAsServerWithTLSConfig(port string, tlsConfig *tls.Config, family netaddr.Family, opts ...grpc.ServerOption) (*serverGrpcTransport, error) {
+	transport := &serverGrpcTransport{port: port, family: family, closing: make(chan struct{}), logger: logging.ModuleLogger(logging.RPC_MODULE), wg: &sync.WaitGroup{}}
+
+	if tlsConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	transport.grpcServer = grpc.NewServer(opts...)
+	RegisterNitroServer(transport.grpcServer, transport)
+
+	listener, err := net.Listen(family.Network(), ":"+port)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.wg.Add(1)
+	go func() {
+		defer transport.wg.Done()
+		if err := transport.grpcServer.Serve(listener); err != nil {
+			transport.logger.Error("gRPC server stopped", "error", err)
+		}
+	}()
+	return transport, nil
+}
+
+// RegisterRequestHandler serves the handler to calls for the api version.
+func (t *serverGrpcTransport) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
+	if _, loaded := t.requestHandlers.LoadOrStore(apiVersion, handler); loaded {
+		return fmt.Errorf("a handler is already registered for api version %s", apiVersion)
+	}
+	return nil
+}
+
+// AuthorizeSubscriptions admits Subscribe calls whose bearer token authorize returns no error for.
+func (t *serverGrpcTransport) AuthorizeSubscriptions(authorize func(token string) error) {
+	t.authorizeMu.Lock()
+	defer t.authorizeMu.Unlock()
+	t.authorizeSubscription = authorize
+}
+
+// Notify sends the notification to every Subscribe call.
+func (t *serverGrpcTransport) Notify(data []byte) error {
+	t.notificationListeners.Range(func(key string, listener notificationListener) bool {
+		select {
+		case listener.notifications <- data:
+		case <-listener.done:
+		}
+		return true
+	})
+	return nil
+}
+
+// Subscribed returns whether the Subscribe call with the subscription id was made with the token.
+func (t *serverGrpcTransport) Subscribed(subscription string, token string) bool {
+	_, ok := t.subscription(subscription, token)
+	return ok
+}
+
+// subscription returns the listener of the Subscribe call with the subscription id, if it was made with the token
+func (t *serverGrpcTransport) subscription(subscription string, token string) (notificationListener, bool) {
+	listener, ok := t.notificationListeners.Load(subscription)
+	if !ok || subtle.ConstantTimeCompare([]byte(listener.token), []byte(token)) != 1 {
+		return notificationListener{}, false
+	}
+	return listener, true
+}
+
+// NotifySubscription sends the notification to the Subscribe call with the subscription id, if it was made with the
+// token.
+func (t *serverGrpcTransport) NotifySubscription(subscription string, token string, data []byte) error {
+	listener, ok := t.subscription(subscription, token)
+	if !ok {
+		return fmt.Errorf("no subscription %s was made with the token", subscription)
+	}
+	select {
+	case listener.notifications <- data:
+		return nil
+	case <-listener.done:
+		return fmt.Errorf("subscription %s has ended", subscription)
+	}
+}
+
+func (t *serverGrpcTransport) Close() error {
+	// Subscribe calls only end when their client cancels them, so they are ended first to let the server stop gracefully
+	close(t.closing)
+	t.grpcServer.GracefulStop()
+	t.wg.Wait()
+	return nil
+}
+
+func (t *serverGrpcTransport) Url() string {
+	return t.family.LoopbackAddress(t.port)
+}
+
+// Call passes the request to the handler of its api version, as a json-rpc request.
+func (t *serverGrpcTransport) Call(ctx context.Context, req *CallRequest) (*CallResponse, error) {
+	apiVersion := req.ApiVersion
+	if apiVersion == "" {
+		apiVersion = defaultApiVersion
+	}
+	handler, ok := t.requestHandlers.Load(apiVersion)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "unsupported api version %s", apiVersion)
+	}
+	method, ok := methodName(req.Method)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown method %v", req.Method)
+	}
+
+	payload := json.RawMessage(req.Payload)
+	if len(payload) == 0 {
+		payload = json.RawMessage("null")
+	}
+	id := req.Id
+	if id == 0 {
+		id = t.requestIds.Add(1)
+	}
+	request := rpcRequest{
+		Jsonrpc: "2.0",
+		Id:      id,
+		Method:  method,
+		Params:  rpcParams{AuthToken: authToken(ctx), Payload: payload},
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid payload: %v", err)
+	}
+
+	var response rpcResponse
+	if err := json.Unmarshal(handler(data), &response); err != nil {
+		return nil, status.Errorf(codes.Internal, "malformed response: %v", err)
+	}
+	if response.Error != nil {
+		return &CallResponse{Outcome: &CallResponse_Error{Error: &Error{
+			Code:    response.Error.Code,
+			Message: response.Error.Message,
+			Data:    response.Error.Data,
+		}}}, nil
+	}
+	return &CallResponse{Outcome: &CallResponse_Result{Result: response.Result}}, nil
+}
+
+// Subscribe streams notifications to the client until it cancels the call or the server closes. The call must carry a
+// bearer token which the authorizer of the transport admits.
+func (t *serverGrpcTransport) Subscribe(_ *SubscribeRequest, stream Nitro_SubscribeServer) error {
+	t.authorizeMu.RLock()
+	authorize := t.authorizeSubscription
+	t.authorizeMu.RUnlock()
+	if authorize == nil {
+		return status.Error(codes.Unauthenticated, "subscriptions are not authorized")
+	}
+	if err := authorize(authToken(stream.Context())); err != nil {
+		t.logger.Warn("Refused a notification subscription", "error", err)
+		return status.Errorf(codes.Unauthenticated, "subscription refused: %v", err)
+	}
+	listener := notificationListener{notifications: make(chan []byte), done: make(chan struct{}), token: authToken(stream.Context())}
+	key := transport.NewSubscriptionId()
+	// The header tells the client it was admitted, without waiting for a notification, and the id of its subscription
+	if err := stream.SendHeader(metadata.Pairs(subscriptionKey, key)); err != nil {
+		return err
+	}
+
+	t.notificationListeners.Store(key, listener)
+	t.logger.Debug("gRPC transport added a notification listener")
+	defer t.notificationListeners.Delete(key)
+	defer close(listener.done)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-t.closing:
+			return nil
+		case data := <-listener.notifications:
+			var notification rpcRequest
+			if err := json.Unmarshal(data, &notification); err != nil {
+				t.logger.Error("Could not parse a notification", "error", err)
+				continue
+			}
+			params, err := json.Marshal(notification.Params)
+			if err != nil {
+				return err
+			}
+			if err := stream.Send(&Notification{Method: notification.Method, Params: params}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// methodName returns the name of the json-rpc method.
+func methodName(m Method) (string, bool) {
+	name, ok := Method_name[int32(m)]
+	if !ok || m == Method_METHOD_UNSPECIFIED {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimPrefix(name, "METHOD_")), true
+}
+
+// methodValue returns the Method of the json-rpc method.
+func methodValue(name string) (Method, bool) {
+	value, ok := Method_value["METHOD_"+strings.ToUpper(name)]
+	return Method(value), ok && value != int32(Method_METHOD_UNSPECIFIED)
+}
+
+// authToken returns the bearer token sent in the metadata of the call, if any.
+func authToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	for _, value := range md.Get(authorizationKey) {
+		if token, ok := strings.CutPrefix(value, "Bearer "); ok {
+			return token
+		}
+	}
+	return ""
+}
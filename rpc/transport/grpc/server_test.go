@@ -0,0 +1,176 @@
+package grpc
+
+import (
+	"encoding/json"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// requestMethods returns the values of the serde.RequestMethod constants, read from the source of the serde package.
+func requestMethods(t *testing.T) []string {
+	t.Helper()
+	file, err := parser.ParseFile(token.NewFileSet(), "../../serde/jsonrpc.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := []string{}
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "RequestMethod" {
+				continue
+			}
+			for _, v := range value.Values {
+				method, err := strconv.Unquote(v.(*ast.BasicLit).Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				methods = append(methods, method)
+			}
+		}
+	}
+	return methods
+}
+
+func TestMethodsCoverRequestMethods(t *testing.T) {
+	methods := requestMethods(t)
+	if len(methods) == 0 {
+		t.Fatal("found no request methods")
+	}
+	for _, method := range methods {
+		value, ok := methodValue(method)
+		if !ok {
+			t.Errorf("nitro.proto has no Method for %s", method)
+			continue
+		}
+		if name, _ := methodName(value); name != method {
+			t.Errorf("expected %v to name %s, got %s", value, method, name)
+		}
+	}
+	// Every Method but METHOD_UNSPECIFIED is a request method
+	if len(Method_name) != len(methods)+1 {
+		t.Errorf("expected %d Methods, got %d", len(methods)+1, len(Method_name))
+	}
+}
+
+func TestCallAndSubscribe(t *testing.T) {
+	server, err := NewGrpcTransportAsServer("4320", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	// The handler echoes the auth token and payload of a request, and errors on a request without a token
+	err = server.RegisterRequestHandler("v1", func(data []byte) []byte {
+		var req rpcRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			t.Error(err)
+		}
+		resp := rpcResponse{Jsonrpc: "2.0", Id: req.Id}
+		if req.Params.AuthToken == "" {
+			resp.Error = &rpcError{Code: -32009, Message: "unauthorized"}
+		} else {
+			resp.Result, _ = json.Marshal(map[string]any{"method": req.Method, "token": req.Params.AuthToken, "payload": req.Params.Payload})
+		}
+		out, _ := json.Marshal(resp)
+		return out
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := server.RegisterRequestHandler("v1", func([]byte) []byte { return nil }); err == nil {
+		t.Error("expected a second handler for v1 to be refused")
+	}
+
+	client, err := NewGrpcTransportAsClient(server.Url(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	resp, err := client.Request([]byte(`{"jsonrpc":"2.0","id":7,"method":"pay","params":{"authtoken":"secret","payload":{"Amount":"0x5"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"jsonrpc":"2.0","id":7,"result":{"method":"pay","payload":{"Amount":"0x5"},"token":"secret"}}`
+	if string(resp) != want {
+		t.Errorf("expected %s, got %s", want, resp)
+	}
+
+	resp, err = client.Request([]byte(`{"jsonrpc":"2.0","id":8,"method":"version","params":{"authtoken":"","payload":{}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"jsonrpc":"2.0","id":8,"error":{"code":-32009,"message":"unauthorized"}}`; string(resp) != want {
+		t.Errorf("expected %s, got %s", want, resp)
+	}
+
+	if _, err := client.Request([]byte(`{"jsonrpc":"2.0","id":9,"method":"unknown","params":{}}`)); err == nil {
+		t.Error("expected a request for an unknown method to fail")
+	}
+	client.UseApiVersion("v9")
+	if _, err := client.Request([]byte(`{"jsonrpc":"2.0","id":10,"method":"version","params":{"authtoken":"secret"}}`)); err == nil {
+		t.Error("expected a request for an unserved api version to fail")
+	}
+
+	if _, err := client.Subscribe(); err == nil {
+		t.Fatal("expected a subscription to be refused before the server authorizes subscriptions")
+	}
+	server.AuthorizeSubscriptions(func(token string) error {
+		if token != "secret" {
+			return errors.New("unauthorized")
+		}
+		return nil
+	})
+	if _, err := client.Subscribe(); err == nil {
+		t.Fatal("expected a subscription without a token to be refused")
+	}
+	if _, err := client.SubscribeWithToken("guess"); err == nil {
+		t.Fatal("expected a subscription with an invalid token to be refused")
+	}
+	notifications, err := client.SubscribeWithToken("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A stream is only sent to a subscription made with the token of its request
+	subscription := client.SubscriptionId()
+	if !server.Subscribed(subscription, "secret") || server.Subscribed(subscription, "other") {
+		t.Error("expected the subscription to be addressed with the token it was made with alone")
+	}
+	if err := server.NotifySubscription(subscription, "other", []byte("{}")); err == nil {
+		t.Error("expected a notification with another token to be refused")
+	}
+	notification := `{"jsonrpc":"2.0","id":3,"method":"payment_channel_updated","params":{"authtoken":"","payload":{"ID":"0x01"}}}`
+	// The subscription may not have reached the server yet, so the notification is sent until it is received
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case got := <-notifications:
+			want := `{"jsonrpc":"2.0","id":0,"method":"payment_channel_updated","params":{"authtoken":"","payload":{"ID":"0x01"}}}`
+			if string(got) != want {
+				t.Errorf("expected %s, got %s", want, got)
+			}
+			return
+		case <-ticker.C:
+			if err := server.Notify([]byte(notification)); err != nil {
+				t.Fatal(err)
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for a notification")
+		}
+	}
+}
@@ -2,59 +2,69 @@ package http
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log/slog"
 	"math"
 	"net/http"
 	urlUtil "net/url"
+	"path"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/internal/logging"
 )
 
 type clientHttpTransport struct {
 	logger           *slog.Logger
 	notificationChan chan []byte
-	clientWebsocket  *websocket.Conn
-	url              string
-	wg               *sync.WaitGroup
+	// clientWebsocket receives notifications, once the transport has subscribed to them
+	clientWebsocket *websocket.Conn
+	// subscriptionId is the id the server gave the websocket
+	subscriptionId string
+	tlsConfig      *tls.Config
+	httpClient     *http.Client
+	url            string
+	secure         bool
+	wg             *sync.WaitGroup
 }
 
-// NewHttpTransportAsClient creates a transport that can be used to send http requests and a websocket connection for receiving notifications
+// NewHttpTransportAsClient creates a transport that can be used to send http requests, and which opens a websocket
+// connection for receiving notifications when it subscribes to them
 // Initialization will block for 10 retries until the server endpoint is ready
+//
+// The url may start with a scheme: http:// or ws:// connect in plaintext, and https:// or wss:// with TLS. A url
+// without a scheme connects with TLS.
 func NewHttpTransportAsClient(url string, retryTimeout time.Duration) (*clientHttpTransport, error) {
-	err := blockUntilHttpServerIsReady(url, retryTimeout)
-	if err != nil {
-		return nil, err
-	}
+	return NewHttpTransportAsClientWithTLSConfig(url, retryTimeout, nil)
+}
 
-	subscribeUrl, err := urlUtil.JoinPath("wss://", url, "subscribe")
-	if err != nil {
-		return nil, err
-	}
+// NewHttpTransportAsClientWithTLSConfig is like NewHttpTransportAsClient, but connects to TLS servers with the config,
+// such as one which trusts a private certificate authority. A nil config uses the system's trusted roots.
+func NewHttpTransportAsClientWithTLSConfig(url string, retryTimeout time.Duration, tlsConfig *tls.Config) (*clientHttpTransport, error) {
+	url, secure := splitScheme(url)
+	httpTransport := http.DefaultTransport.(*http.Transport).Clone()
+	httpTransport.TLSClientConfig = tlsConfig
+	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), tlsConfig: tlsConfig, httpClient: &http.Client{Transport: httpTransport}, url: url, secure: secure, wg: &sync.WaitGroup{}, logger: logging.ModuleLogger(logging.RPC_MODULE)}
 
-	conn, _, err := websocket.DefaultDialer.Dial(subscribeUrl, nil)
+	err := t.blockUntilHttpServerIsReady(retryTimeout)
 	if err != nil {
 		return nil, err
 	}
 
-	t := &clientHttpTransport{notificationChan: make(chan []byte, 10), clientWebsocket: conn, url: url, wg: &sync.WaitGroup{}, logger: slog.Default()}
-
-	t.wg.Add(1)
-	go t.readMessages()
-
 	return t, nil
 }
 
 func (t *clientHttpTransport) Request(data []byte) ([]byte, error) {
-	requestUrl, err := httpUrl(t.url)
+	requestUrl, err := t.httpUrl()
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.Post(requestUrl, "application/json", bytes.NewBuffer(data))
+	resp, err := t.httpClient.Post(requestUrl, "application/json", bytes.NewBuffer(data))
 	if err != nil {
 		return nil, err
 	}
@@ -66,17 +76,73 @@ func (t *clientHttpTransport) Request(data []byte) ([]byte, error) {
 	return body, nil
 }
 
+// UseApiVersion replaces the version at the end of the url, as in host:port/api/v1, with the given version. Urls which
+// do not end with a version are left as they are.
+func (t *clientHttpTransport) UseApiVersion(version string) {
+	prefix, current := path.Split(t.url)
+	if current != "" && strings.HasSuffix(prefix, apiPath+"/") {
+		t.url = prefix + version
+	}
+}
+
+// Subscribe opens the websocket connection for notifications without presenting a token, which servers refuse unless
+// they do not authorize subscriptions.
 func (t *clientHttpTransport) Subscribe() (<-chan []byte, error) {
+	return t.SubscribeWithToken("")
+}
+
+// SubscribeWithToken opens the websocket connection for notifications, presenting the auth token to the server. Once
+// it is open, later calls return the same channel.
+func (t *clientHttpTransport) SubscribeWithToken(token string) (<-chan []byte, error) {
+	if t.clientWebsocket != nil {
+		return t.notificationChan, nil
+	}
+
+	wsScheme := "ws://"
+	if t.secure {
+		wsScheme = "wss://"
+	}
+	subscribeUrl, err := urlUtil.JoinPath(wsScheme, t.url, "subscribe")
+	if err != nil {
+		return nil, err
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = t.tlsConfig
+	conn, resp, err := dialer.Dial(subscribeUrl, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("subscription refused: %w", err)
+		}
+		return nil, err
+	}
+	t.clientWebsocket = conn
+	t.subscriptionId = resp.Header.Get(subscriptionHeader)
+
+	t.wg.Add(1)
+	go t.readMessages()
+
 	return t.notificationChan, nil
 }
 
+// SubscriptionId returns the id the server gave the websocket, if the transport has subscribed.
+func (t *clientHttpTransport) SubscriptionId() string {
+	return t.subscriptionId
+}
+
 func (t *clientHttpTransport) Close() error {
-	// This will also cause the go-routine to unblock waiting on `ReadMessage` and thus serves as a signal to exit
-	err := t.clientWebsocket.Close()
-	if err != nil {
-		return err
+	if t.clientWebsocket != nil {
+		// This will also cause the go-routine to unblock waiting on `ReadMessage` and thus serves as a signal to exit
+		err := t.clientWebsocket.Close()
+		if err != nil {
+			return err
+		}
+		t.wg.Wait()
 	}
-	t.wg.Wait()
 
 	close(t.notificationChan)
 	return nil
@@ -97,9 +163,24 @@ func (t *clientHttpTransport) readMessages() {
 	}
 }
 
+// splitScheme removes the scheme from the start of the url, if it has one, and returns whether the scheme, or its
+// absence, calls for TLS
+func splitScheme(url string) (string, bool) {
+	for scheme, secure := range map[string]bool{"http://": false, "ws://": false, "https://": true, "wss://": true} {
+		if strings.HasPrefix(url, scheme) {
+			return strings.TrimPrefix(url, scheme), secure
+		}
+	}
+	return url, true
+}
+
 // httpUrl joins the http prefix with the server url
-func httpUrl(url string) (string, error) {
-	httpUrl, err := urlUtil.JoinPath("https://", url)
+func (t *clientHttpTransport) httpUrl() (string, error) {
+	scheme := "http://"
+	if t.secure {
+		scheme = "https://"
+	}
+	httpUrl, err := urlUtil.JoinPath(scheme, t.url)
 	if err != nil {
 		return "", err
 	}
@@ -107,12 +188,12 @@ func httpUrl(url string) (string, error) {
 }
 
 // blockUntilHttpServerIsReady pings the health endpoint until the server is ready
-func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
+func (t *clientHttpTransport) blockUntilHttpServerIsReady(retryTimeout time.Duration) error {
 	waitForServer := func(iteration int) {
 		time.Sleep(retryTimeout * time.Duration(math.Pow(2, float64(iteration))))
 	}
 
-	httpUrl, err := httpUrl(url)
+	httpUrl, err := t.httpUrl()
 	if err != nil {
 		return err
 	}
@@ -122,7 +203,7 @@ func blockUntilHttpServerIsReady(url string, retryTimeout time.Duration) error {
 	}
 	numAttempts := 10
 	for i := 0; i < numAttempts; i++ {
-		resp, err := http.Get(healthUrl)
+		resp, err := t.httpClient.Get(healthUrl)
 		if err != nil {
 			waitForServer(i)
 			continue
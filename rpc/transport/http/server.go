@@ -2,96 +2,145 @@ package http
 
 import (
 	"context"
+	"crypto/subtle"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"net/http"
+	urlUtil "net/url"
 	"path"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/statechannels/go-nitro/internal/logging"
+	"github.com/statechannels/go-nitro/internal/netaddr"
 	"github.com/statechannels/go-nitro/internal/safesync"
-	"github.com/statechannels/go-nitro/rand"
+	"github.com/statechannels/go-nitro/rpc/serde"
+	"github.com/statechannels/go-nitro/rpc/transport"
 )
 
 const (
-	httpServerAddress = "127.0.0.1:"
-	maxRequestSize    = 8192
-	apiVersionPath    = "/api/v1"
+	apiPath        = "/api"
+	apiVersionPath = apiPath + "/v1"
 )
 
+// subscriptionHeader is the header of the websocket upgrade response which carries the id of the subscription
+const subscriptionHeader = "Nitro-Subscription"
+
+// notificationListener receives notifications for a websocket, opened with token, until done is closed.
+type notificationListener struct {
+	notifications chan []byte
+	done          chan struct{}
+	token         string
+}
+
 type serverHttpTransport struct {
-	httpServer            *http.Server
-	requestHandlers       map[string]func([]byte) []byte
-	port                  string
-	notificationListeners safesync.Map[chan []byte]
+	httpServer      *http.Server
+	serveMux        *http.ServeMux
+	requestHandlers safesync.Map[func([]byte) []byte]
+	family          netaddr.Family
+
+	// tlsConfig is the TLS config the transport was started with, or nil if it serves plain http
+	tlsConfig *tls.Config
+
+	// mu guards the listener, its port and the certificate it serves, which change when the transport is rebound
+	mu       sync.Mutex
+	listener net.Listener
+	port     string
+	cert     *tls.Certificate
+
+	notificationListeners safesync.Map[notificationListener]
 	logger                *slog.Logger
 
+	// authorizeMu guards authorizeSubscription, which admits the clients subscribing to notifications. Every
+	// subscription is refused while it is nil.
+	authorizeMu           sync.RWMutex
+	authorizeSubscription func(token string) error
+
 	wg *sync.WaitGroup
 }
 
-// NewHttpTransportAsServer starts an http server
+// NewHttpTransportAsServer starts an http server, listening on both IPv4 and IPv6
 func NewHttpTransportAsServer(port string, cert *tls.Certificate) (*serverHttpTransport, error) {
-	transport := &serverHttpTransport{port: port, notificationListeners: safesync.Map[chan []byte]{}, logger: slog.Default()}
+	return NewHttpTransportAsServerWithFamily(port, cert, netaddr.DualStack)
+}
 
-	var serveMux http.ServeMux
+// NewHttpTransportAsServerWithFamily starts an http server, listening on the IP versions of the family
+func NewHttpTransportAsServerWithFamily(port string, cert *tls.Certificate, family netaddr.Family) (*serverHttpTransport, error) {
+	return NewHttpTransportAsServerWithTLSConfig(port, transport.CertificateConfig(cert), family)
+}
 
-	// Used to check if the server is ready
-	serveMux.HandleFunc(path.Join(apiVersionPath, "health"), func(w http.ResponseWriter, r *http.Request) {
-		_, err := w.Write([]byte("OK"))
-		if err != nil {
-			panic(err)
-		}
-	})
-	serveMux.HandleFunc(apiVersionPath, transport.request)
-	serveMux.HandleFunc(path.Join(apiVersionPath, "subscribe"), transport.subscribe)
+// NewHttpTransportAsServerWithTLSConfig starts an http server, listening on the IP versions of the family, and serving
+// TLS with the config if it is not nil. The config may get its certificates from an ACME provider, as the config of an
+// autocert.Manager does.
+func NewHttpTransportAsServerWithTLSConfig(port string, tlsConfig *tls.Config, family netaddr.Family) (*serverHttpTransport, error) {
+	transport := &serverHttpTransport{port: port, tlsConfig: tlsConfig, family: family, notificationListeners: safesync.Map[notificationListener]{}, logger: logging.ModuleLogger(logging.RPC_MODULE)}
+
+	// Each version of the api is routed when its handler is registered
+	transport.serveMux = http.NewServeMux()
 	transport.httpServer = &http.Server{
 		Addr:         ":" + port,
-		Handler:      &serveMux,
+		Handler:      transport.serveMux,
 		ReadTimeout:  time.Second * 10,
 		WriteTimeout: time.Second * 10,
 	}
 
-	transport.requestHandlers = make(map[string]func([]byte) []byte)
 	transport.wg = &sync.WaitGroup{}
 
-	transport.wg.Add(1)
-
-	var listener net.Listener
-	var err error
-
-	if cert == nil {
-		listener, err = net.Listen("tcp", ":"+transport.port)
-		if err != nil {
-			return nil, err
-		}
-	} else {
-		// Create a TLS config
-		tlsConfig := &tls.Config{
-			Certificates: []tls.Certificate{*cert},
-		}
-		// Create a new TLS listener
-		listener, err = tls.Listen("tcp", ":"+port, tlsConfig)
-		if err != nil {
-			return nil, err
-		}
+	listener, err := transport.listen(port, tlsConfig != nil)
+	if err != nil {
+		return nil, err
 	}
+	transport.listener = listener
 
+	transport.wg.Add(1)
 	go transport.serveHttp(listener)
 	return transport, nil
 }
 
+// listen listens on the port, serving TLS with the transport's current certificate if useTls is set
+func (t *serverHttpTransport) listen(port string, useTls bool) (net.Listener, error) {
+	if !useTls {
+		return net.Listen(t.family.Network(), ":"+port)
+	}
+	// The certificate is looked up for each handshake, so that it can be replaced while the listener runs
+	config := &tls.Config{}
+	if t.tlsConfig != nil {
+		config = t.tlsConfig.Clone()
+	}
+	config.GetCertificate = t.certificate
+	return tls.Listen(t.family.Network(), ":"+port, config)
+}
+
+// certificate returns the certificate the transport was last rebound with, or else the one its TLS config serves
+func (t *serverHttpTransport) certificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	t.mu.Lock()
+	cert := t.cert
+	t.mu.Unlock()
+	if cert != nil || t.tlsConfig == nil {
+		return cert, nil
+	}
+	if t.tlsConfig.GetCertificate != nil {
+		return t.tlsConfig.GetCertificate(hello)
+	}
+	if len(t.tlsConfig.Certificates) > 0 {
+		return &t.tlsConfig.Certificates[0], nil
+	}
+	return nil, errors.New("no tls certificate is configured")
+}
+
 func (t *serverHttpTransport) serveHttp(tcpListener net.Listener) {
 	defer t.wg.Done()
 
 	err := t.httpServer.Serve(tcpListener)
 
-	if err != nil && errors.Is(err, http.ErrServerClosed) {
+	if err != nil && (errors.Is(err, http.ErrServerClosed) || t.retired(tcpListener)) {
 		return
 	}
 	if err != nil {
@@ -99,19 +148,128 @@ func (t *serverHttpTransport) serveHttp(tcpListener net.Listener) {
 	}
 }
 
+// retired returns whether the listener has been replaced by rebinding the transport
+func (t *serverHttpTransport) retired(listener net.Listener) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return listener != t.listener
+}
+
+// Rebind listens on port, serving cert, before it stops listening on the previous port. Connections accepted on the
+// previous port, including notification subscriptions, are kept open. A plain http transport may start serving TLS
+// only on a new port.
+func (t *serverHttpTransport) Rebind(port string, cert *tls.Certificate) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if port == "" {
+		port = t.port
+	}
+	servesTls := t.tlsConfig != nil || t.cert != nil
+	useTls := cert != nil || servesTls
+	if port == t.port {
+		if useTls != servesTls {
+			return fmt.Errorf("cannot serve tls on port %s, which serves plain http", port)
+		}
+		if cert != nil {
+			t.cert = cert
+			t.logger.Info("Rpc transport certificate replaced")
+		}
+		return nil
+	}
+
+	listener, err := t.listen(port, useTls)
+	if err != nil {
+		return err
+	}
+	if cert != nil {
+		t.cert = cert
+	}
+	previous := t.listener
+	t.listener, t.port = listener, port
+	t.wg.Add(1)
+	go t.serveHttp(listener)
+
+	t.logger.Info("Rpc transport rebound", "port", port, "tls", useTls)
+	// Closing the listener stops new connections to the previous port, but leaves those it accepted open
+	return previous.Close()
+}
+
+// RegisterRequestHandler serves the handler at /api/<apiVersion>, along with the health and subscribe endpoints beneath it
 func (t *serverHttpTransport) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
-	t.requestHandlers[apiVersion] = handler
+	if _, loaded := t.requestHandlers.LoadOrStore(apiVersion, handler); loaded {
+		return fmt.Errorf("a handler is already registered for api version %s", apiVersion)
+	}
+	versionPath := path.Join(apiPath, apiVersion)
+
+	// Used to check if the server is ready
+	t.serveMux.HandleFunc(path.Join(versionPath, "health"), func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("OK"))
+		if err != nil {
+			panic(err)
+		}
+	})
+	t.serveMux.HandleFunc(versionPath, t.request)
+	t.serveMux.HandleFunc(path.Join(versionPath, "subscribe"), t.subscribe)
 	return nil
 }
 
+// Handle serves handler at path, alongside the api
+func (t *serverHttpTransport) Handle(path string, handler http.Handler) error {
+	t.serveMux.Handle(path, handler)
+	return nil
+}
+
+// AuthorizeSubscriptions admits subscriptions presenting a token which authorize returns no error for. The token is
+// sent as a bearer token in the Authorization header, or, by browsers, which cannot set the headers of a websocket, in
+// the authtoken query parameter.
+func (t *serverHttpTransport) AuthorizeSubscriptions(authorize func(token string) error) {
+	t.authorizeMu.Lock()
+	defer t.authorizeMu.Unlock()
+	t.authorizeSubscription = authorize
+}
+
 func (t *serverHttpTransport) Notify(data []byte) error {
-	t.notificationListeners.Range(func(key string, value chan []byte) bool {
-		value <- data
+	t.notificationListeners.Range(func(key string, listener notificationListener) bool {
+		select {
+		case listener.notifications <- data:
+		case <-listener.done:
+		}
 		return true
 	})
 	return nil
 }
 
+// Subscribed returns whether the websocket with the subscription id was opened with the token.
+func (t *serverHttpTransport) Subscribed(subscription string, token string) bool {
+	_, ok := t.subscription(subscription, token)
+	return ok
+}
+
+// subscription returns the listener of the websocket with the subscription id, if it was opened with the token
+func (t *serverHttpTransport) subscription(subscription string, token string) (notificationListener, bool) {
+	listener, ok := t.notificationListeners.Load(subscription)
+	if !ok || subtle.ConstantTimeCompare([]byte(listener.token), []byte(token)) != 1 {
+		return notificationListener{}, false
+	}
+	return listener, true
+}
+
+// NotifySubscription sends the notification to the websocket with the subscription id, if it was opened with the
+// token.
+func (t *serverHttpTransport) NotifySubscription(subscription string, token string, data []byte) error {
+	listener, ok := t.subscription(subscription, token)
+	if !ok {
+		return fmt.Errorf("no subscription %s was opened with the token", subscription)
+	}
+	select {
+	case listener.notifications <- data:
+		return nil
+	case <-listener.done:
+		return fmt.Errorf("subscription %s has ended", subscription)
+	}
+}
+
 func (t *serverHttpTransport) Close() error {
 	// This will cause the serveHttp and listenForClose goroutines to exit
 	err := t.httpServer.Shutdown(context.Background())
@@ -124,7 +282,9 @@ func (t *serverHttpTransport) Close() error {
 }
 
 func (t *serverHttpTransport) Url() string {
-	return httpServerAddress + t.port + apiVersionPath
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.family.LoopbackAddress(t.port) + apiVersionPath
 }
 
 func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
@@ -136,7 +296,7 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 	}
 
 	apiVersion := pathSegments[2] // first segment is an empty string
-	handler, ok := t.requestHandlers[apiVersion]
+	handler, ok := t.requestHandlers.Load(apiVersion)
 	if !ok {
 		http.Error(w, "Invalid API version", http.StatusBadRequest)
 		return
@@ -150,13 +310,29 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Headers", "*")
 	case "POST":
 		enableCors(&w)
-		body := http.MaxBytesReader(w, r.Body, maxRequestSize)
-		msg, err := io.ReadAll(body)
+		msg, err := io.ReadAll(http.MaxBytesReader(w, r.Body, serde.MaxRequestBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			// The truncated request cannot be parsed for its id, so the error is returned with a null id
+			response, err := json.Marshal(serde.NewJsonRpcErrorResponse(serde.RequestId{}, serde.RequestTooLargeError))
+			if err != nil {
+				panic(err)
+			}
+			w.WriteHeader(http.StatusRequestEntityTooLarge)
+			_, _ = w.Write(response)
+			return
+		}
 		if err != nil {
-			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
 			return
 		}
-		_, err = w.Write(handler(msg))
+		response := handler(msg)
+		if response == nil {
+			// The request was a json-rpc notification, which has no response
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		_, err = w.Write(response)
 		if err != nil {
 			panic(err)
 		}
@@ -165,22 +341,70 @@ func (t *serverHttpTransport) request(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-var upgrader = websocket.Upgrader{} // use default options
+// upgrader only accepts websockets from pages served by the host of the node, such as its ui, which may be served on
+// another port. Clients which are not browsers send no origin, and are accepted.
+var upgrader = websocket.Upgrader{CheckOrigin: sameHostOrigin}
+
+// sameHostOrigin returns whether the request has no origin, or an origin with the same hostname as the request
+func sameHostOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := urlUtil.Parse(origin)
+	if err != nil {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	return strings.EqualFold(u.Hostname(), strings.Trim(host, "[]"))
+}
+
+// subscriptionToken returns the token the subscribing client presents, in the Authorization header or the authtoken
+// query parameter
+func subscriptionToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("authtoken")
+}
+
+// authorize checks the token the subscribing client presents
+func (t *serverHttpTransport) authorize(r *http.Request) error {
+	t.authorizeMu.RLock()
+	authorize := t.authorizeSubscription
+	t.authorizeMu.RUnlock()
+	if authorize == nil {
+		return errors.New("subscriptions are not authorized")
+	}
+	return authorize(subscriptionToken(r))
+}
+
 func (t *serverHttpTransport) subscribe(w http.ResponseWriter, r *http.Request) {
-	// TODO: We currently allow requests from any origins. We should probably use a whitelist.
-	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
+	if err := t.authorize(r); err != nil {
+		t.logger.Warn("Refused a notification subscription", "remote", r.RemoteAddr, "error", err)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
 
-	c, err := upgrader.Upgrade(w, r, nil)
+	// The id is sent to the client with the upgrade, so that it can direct streams to the websocket
+	key := transport.NewSubscriptionId()
+	c, err := upgrader.Upgrade(w, r, http.Header{subscriptionHeader: []string{key}})
 	if err != nil {
-		panic(err)
+		// The upgrader has replied to the client with the error
+		t.logger.Warn("Could not upgrade a notification subscription", "remote", r.RemoteAddr, "error", err)
+		return
 	}
+	c.SetReadLimit(serde.MaxRequestBytes)
 
 	defer c.Close()
-	notificationChan := make(chan []byte)
-	key := strconv.Itoa(int(rand.Uint64()))
-	t.notificationListeners.Store(key, notificationChan)
+	listener := notificationListener{notifications: make(chan []byte), done: make(chan struct{}), token: subscriptionToken(r)}
+	t.notificationListeners.Store(key, listener)
 	t.logger.Debug("Websocket transport added a notification listener")
 	defer t.notificationListeners.Delete(key)
+	defer close(listener.done)
 
 	closeChan := make(chan error)
 
@@ -195,7 +419,7 @@ EventLoop:
 		select {
 		case err = <-closeChan:
 			break EventLoop
-		case notificationData := <-notificationChan:
+		case notificationData := <-listener.notifications:
 			err := c.WriteMessage(websocket.TextMessage, notificationData)
 			if err != nil {
 				break EventLoop
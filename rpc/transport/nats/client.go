@@ -10,6 +10,9 @@ import (
 type natsTransportClient struct {
 	natsTransport
 	notificationChan chan []byte
+	apiVersion       string
+	// inbox is the topic of the notifications sent to this client alone, which is the id of its subscription
+	inbox string
 }
 
 func NewNatsTransportAsClient(url string) (*natsTransportClient, error) {
@@ -19,12 +22,13 @@ func NewNatsTransportAsClient(url string) (*natsTransportClient, error) {
 	}
 	return &natsTransportClient{
 		natsTransport: *natsTransport,
+		apiVersion:    defaultApiVersion,
 	}, nil
 }
 
 func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
 	requestFn := func(data []byte) (*nats.Msg, error) {
-		return c.nc.Request(nitroRequestTopic+apiVersionPath, data, 10*time.Second)
+		return c.nc.Request(requestTopic(c.apiVersion), data, 10*time.Second)
 	}
 
 	numTries := 2
@@ -47,17 +51,45 @@ func (c *natsTransportClient) Request(data []byte) ([]byte, error) {
 	return nil, fmt.Errorf("received nill data for request %v with error %w", string(data), err)
 }
 
+func (c *natsTransportClient) UseApiVersion(version string) {
+	c.apiVersion = version
+}
+
+// Subscribe provides a notification channel for the notifications published to the topic shared by every node, by
+// servers which have not been given their node's address.
 func (c *natsTransportClient) Subscribe() (<-chan []byte, error) {
+	return c.subscribe(nitroNotificationTopic)
+}
+
+// SubscribeToAddress provides a notification channel for the notifications of the node with the address.
+func (c *natsTransportClient) SubscribeToAddress(address string) (<-chan []byte, error) {
+	return c.subscribe(notificationTopic(address))
+}
+
+func (c *natsTransportClient) subscribe(topic string) (<-chan []byte, error) {
 	if c.notificationChan != nil {
 		return c.notificationChan, nil
 	}
 	c.notificationChan = make(chan []byte)
-	subscription, err := c.nc.Subscribe(nitroNotificationTopic, func(msg *nats.Msg) {
-		c.notificationChan <- msg.Data
-	})
-	c.natsSubscriptions = append(c.natsSubscriptions, subscription)
+	inbox := c.nc.NewInbox()
+	for _, t := range []string{topic, inbox} {
+		subscription, err := c.nc.Subscribe(t, func(msg *nats.Msg) {
+			c.notificationChan <- msg.Data
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.natsSubscriptions = append(c.natsSubscriptions, subscription)
+	}
+	c.inbox = inbox
+
+	// Notifications published once the subscription is returned are not missed
+	return c.notificationChan, c.nc.Flush()
+}
 
-	return c.notificationChan, err
+// SubscriptionId returns the inbox of the client, once it has subscribed.
+func (c *natsTransportClient) SubscriptionId() string {
+	return c.inbox
 }
 
 func (c *natsTransportClient) Close() error {
@@ -65,6 +97,8 @@ func (c *natsTransportClient) Close() error {
 	if err != nil {
 		return err
 	}
-	close(c.notificationChan)
+	if c.notificationChan != nil {
+		close(c.notificationChan)
+	}
 	return nil
 }
@@ -1,16 +1,25 @@
 package nats
 
 import (
+	"fmt"
 	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
+	"github.com/statechannels/go-nitro/internal/netaddr"
 )
 
 const (
-	nitroRequestTopic      = "nitro-request"
+	nitroRequestTopic = "nitro-request"
+	// nitroNotificationTopic is the topic of the notifications of a server which has not been given its node's address
 	nitroNotificationTopic = "nitro-notify"
-	apiVersionPath         = "/api/v1"
+	// nitroAddressNotificationTopic is the prefix of the topic of the notifications of each node
+	nitroAddressNotificationTopic = "nitro.notify"
+	defaultApiVersion             = "v1"
 )
 
 type natsTransport struct {
@@ -20,7 +29,10 @@ type natsTransport struct {
 
 type natsTransportServer struct {
 	natsTransport
-	ns *server.Server
+	ns  *server.Server
+	url string
+	// notificationTopic holds the topic notifications are published to
+	notificationTopic atomic.Value
 }
 
 func newNatsTransport(url string) (*natsTransport, error) {
@@ -59,14 +71,26 @@ func (c *natsTransport) unsubscribeFromTopic(sub *nats.Subscription, retries uin
 }
 
 func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
+	return NewNatsTransportAsServerWithFamily(rpcPort, netaddr.DualStack)
+}
+
+// NewNatsTransportAsServerWithFamily starts a NATS server on the port. The server listens on a single socket, so it
+// cannot bind IPv4 and IPv6 separately: it listens on IPv6 (which most systems also accept IPv4 connections on) if the
+// family prefers IPv6, and on IPv4 otherwise.
+func NewNatsTransportAsServerWithFamily(rpcPort int, family netaddr.Family) (*natsTransportServer, error) {
 	opts := &server.Options{Port: rpcPort}
+	if family.PrefersIPv6() {
+		opts.Host = net.IPv6unspecified.String()
+	}
 	ns, err := server.NewServer(opts)
 	if err != nil {
 		return nil, err
 	}
 	ns.Start()
 
-	natsTransport, err := newNatsTransport(ns.ClientURL())
+	// The server's own client url does not bracket IPv6 hosts
+	url := "nats://" + family.LoopbackAddress(strconv.Itoa(rpcPort))
+	natsTransport, err := newNatsTransport(url)
 	if err != nil {
 		return nil, err
 	}
@@ -74,13 +98,19 @@ func NewNatsTransportAsServer(rpcPort int) (*natsTransportServer, error) {
 	con := &natsTransportServer{
 		natsTransport: *natsTransport,
 		ns:            ns,
+		url:           url,
 	}
+	con.notificationTopic.Store(nitroNotificationTopic)
 	return con, nil
 }
 
 func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler func([]byte) []byte) error {
-	sub, err := c.nc.Subscribe(nitroRequestTopic+"/api/"+apiVersion, func(msg *nats.Msg) {
+	sub, err := c.nc.Subscribe(requestTopic(apiVersion), func(msg *nats.Msg) {
 		responseData := handler(msg.Data)
+		if msg.Reply == "" {
+			// The request was published without awaiting a response, as json-rpc notifications may be
+			return
+		}
 		err := c.nc.Publish(msg.Reply, responseData)
 		if err != nil {
 			panic(err)
@@ -91,12 +121,45 @@ func (c *natsTransportServer) RegisterRequestHandler(apiVersion string, handler
 	return err
 }
 
+// requestTopic is the topic of requests to the given version of the rpc api
+func requestTopic(apiVersion string) string {
+	return nitroRequestTopic + "/api/" + apiVersion
+}
+
+// notificationTopic is the topic of the notifications of the node with the given address, as nitro.notify.<address>,
+// with the address in lower case so that checksummed and plain addresses share a topic
+func notificationTopic(address string) string {
+	return nitroAddressNotificationTopic + "." + strings.ToLower(address)
+}
+
+// SetNotificationAddress publishes subsequent notifications to the topic of the node with the address, rather than to
+// the topic shared by every node.
+func (c *natsTransportServer) SetNotificationAddress(address string) {
+	c.notificationTopic.Store(notificationTopic(address))
+}
+
+// Notify publishes the notification to the topic of the server's node, or to the shared topic if it has not been given
+// its node's address.
 func (c *natsTransportServer) Notify(data []byte) error {
-	return c.nc.Publish(nitroNotificationTopic, data)
+	return c.nc.Publish(c.notificationTopic.Load().(string), data)
+}
+
+// Subscribed returns whether the subscription id is the inbox of a client. The broker does not identify the clients
+// publishing to it, so the token is not checked: any client of the broker may read any topic.
+func (c *natsTransportServer) Subscribed(subscription string, token string) bool {
+	return strings.HasPrefix(subscription, nats.InboxPrefix)
+}
+
+// NotifySubscription publishes the notification to the inbox of a single client, which is its subscription id.
+func (c *natsTransportServer) NotifySubscription(subscription string, token string, data []byte) error {
+	if !c.Subscribed(subscription, token) {
+		return fmt.Errorf("subscription %s is not an inbox", subscription)
+	}
+	return c.nc.Publish(subscription, data)
 }
 
 func (c *natsTransportServer) Url() string {
-	return c.ns.ClientURL()
+	return c.url
 }
 
 func (c *natsTransportServer) Close() error {
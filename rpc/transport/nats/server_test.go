@@ -0,0 +1,55 @@
+package nats
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNotificationTopics checks that the notifications of a server given its node's address reach only the clients
+// subscribed to that address.
+func TestNotificationTopics(t *testing.T) {
+	server, err := NewNatsTransportAsServer(4330)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.SetNotificationAddress("0xA")
+
+	subscribe := func(subscribe func(*natsTransportClient) (<-chan []byte, error)) (*natsTransportClient, <-chan []byte) {
+		client, err := NewNatsTransportAsClient(server.Url())
+		if err != nil {
+			t.Fatal(err)
+		}
+		notifications, err := subscribe(client)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client, notifications
+	}
+	ours, ourNotifications := subscribe(func(c *natsTransportClient) (<-chan []byte, error) { return c.SubscribeToAddress("0xA") })
+	defer ours.Close()
+	theirs, theirNotifications := subscribe(func(c *natsTransportClient) (<-chan []byte, error) { return c.SubscribeToAddress("0xB") })
+	defer theirs.Close()
+	shared, sharedNotifications := subscribe(func(c *natsTransportClient) (<-chan []byte, error) { return c.Subscribe() })
+	defer shared.Close()
+
+	if err := server.Notify([]byte("notification")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case data := <-ourNotifications:
+		if string(data) != "notification" {
+			t.Errorf("expected the notification, got %s", data)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the node's subscriber to be notified")
+	}
+	select {
+	case data := <-theirNotifications:
+		t.Errorf("expected another node's subscriber not to be notified, got %s", data)
+	case data := <-sharedNotifications:
+		t.Errorf("expected the shared topic's subscriber not to be notified, got %s", data)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
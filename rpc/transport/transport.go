@@ -1,10 +1,18 @@
 package transport
 
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"net/http"
+)
+
 type TransportType string
 
 const (
 	Nats TransportType = "nats"
 	Http TransportType = "http"
+	Grpc TransportType = "grpc"
 )
 
 // Requester is a transport that can send requests and subscribe to notifications
@@ -17,6 +25,9 @@ type Requester interface {
 	// Subscribe provides a notification channel.
 	// If subscription to notifications fails, it returns an error.
 	Subscribe() (<-chan []byte, error)
+	// UseApiVersion directs subsequent requests to the given version of the rpc api.
+	// It must not be called concurrently with Request.
+	UseApiVersion(version string)
 }
 
 // Responder is a transport that can respond to requests and send notifications
@@ -32,3 +43,81 @@ type Responder interface {
 	// Notify sends notification data without expecting a response
 	Notify([]byte) error
 }
+
+// HandlerServer is a Responder served over http, which can serve other endpoints, such as metrics, alongside the api
+type HandlerServer interface {
+	// Handle serves handler at path.
+	// It returns an error if the responder cannot serve other endpoints
+	Handle(path string, handler http.Handler) error
+}
+
+// Rebinder is a Responder which can move to another port, or serve another TLS certificate, while it runs. The new
+// listener is bound before the old one is closed, so that the connections of clients are not dropped.
+type Rebinder interface {
+	// Rebind listens on port, serving cert, and stops listening on the previous port. An empty port keeps the current
+	// port, and a nil cert the current certificate. If the new listener cannot be bound, the responder is unchanged.
+	Rebind(port string, cert *tls.Certificate) error
+}
+
+// AddressedNotifier is a Responder which publishes the notifications of a node to a topic of its own, so that clients
+// of several nodes sharing a message broker receive only the notifications of the node they are connected to.
+type AddressedNotifier interface {
+	// SetNotificationAddress directs subsequent notifications to the topic of the node with the address.
+	SetNotificationAddress(address string)
+}
+
+// AddressedSubscriber is a Requester which can subscribe to the notifications an AddressedNotifier publishes for a node.
+type AddressedSubscriber interface {
+	// SubscribeToAddress provides a notification channel for the notifications of the node with the address.
+	// If subscription to notifications fails, it returns an error.
+	SubscribeToAddress(address string) (<-chan []byte, error)
+}
+
+// SubscriptionAuthorizer is a Responder which authenticates the clients subscribing to its notifications. It refuses
+// every subscription until it is given an authorizer.
+type SubscriptionAuthorizer interface {
+	// AuthorizeSubscriptions admits subscriptions presenting a token which authorize returns no error for, and refuses
+	// the rest.
+	AuthorizeSubscriptions(authorize func(token string) error)
+}
+
+// TokenSubscriber is a Requester which presents an auth token when it subscribes to notifications, as the clients of
+// a SubscriptionAuthorizer must.
+type TokenSubscriber interface {
+	// SubscribeWithToken provides a notification channel, presenting the token to the server.
+	// If the server refuses the token, or subscription otherwise fails, it returns an error.
+	SubscribeWithToken(token string) (<-chan []byte, error)
+}
+
+// SubscriptionNotifier is a Responder which can send notifications to a single subscription, rather than to every
+// subscriber, such as the pages of a stream, which only the client requesting it may receive.
+type SubscriptionNotifier interface {
+	// Subscribed returns whether there is a subscription with the id which was opened with the token.
+	Subscribed(subscription string, token string) bool
+	// NotifySubscription sends notification data to the subscription with the id, if it was opened with the token.
+	// It returns an error if there is no such subscription, or it has ended.
+	NotifySubscription(subscription string, token string, data []byte) error
+}
+
+// IdentifiedSubscriber is a Requester whose subscription has an id, by which a SubscriptionNotifier addresses it.
+type IdentifiedSubscriber interface {
+	// SubscriptionId returns the id of the requester's subscription, or an empty string if it has not subscribed.
+	SubscriptionId() string
+}
+
+// CertificateConfig returns a TLS config which serves the certificate, or nil, for a plaintext transport, if cert is nil.
+func CertificateConfig(cert *tls.Certificate) *tls.Config {
+	if cert == nil {
+		return nil
+	}
+	return &tls.Config{Certificates: []tls.Certificate{*cert}}
+}
+
+// NewSubscriptionId returns a random id for a subscription, which cannot be guessed by other clients.
+func NewSubscriptionId() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}